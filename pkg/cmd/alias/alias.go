@@ -0,0 +1,367 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alias
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/tools/clientcmd"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	aliasLong = templates.LongDesc(i18n.T(`
+		Manage the aliases of commands and resources.
+
+		A command alias stands for the beginning of a command line, such as
+		"get pods -o wide", and is expanded when it is given instead of a command.
+		The arguments following the alias are appended to the command line.
+
+		A resource alias stands for a resource, such as "deployments.apps", and is
+		expanded in the resource types and in the TYPE/NAME arguments of the
+		commands working on resources.
+
+		The aliases are stored in an extension of the preferences of the kubeconfig
+		file. Commands of kubectl can not be redefined by aliases.`))
+
+	aliasExample = templates.Examples(i18n.T(`
+		# Define the resource alias dep for the deployments of the apps group
+		kubectl alias set dep deployments.apps
+
+		# Define the command alias gp
+		kubectl alias set gp 'get pods -o wide'
+
+		# Use the aliases
+		kubectl gp -n kube-system
+		kubectl rollout restart dep/web
+
+		# List the aliases
+		kubectl alias list
+
+		# Remove the alias gp
+		kubectl alias remove gp`))
+)
+
+// NewCmdAlias returns the command managing the aliases.
+func NewCmdAlias(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "alias SUBCOMMAND",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Manage the aliases of commands and resources"),
+		Long:                  aliasLong,
+		Example:               aliasExample,
+		Run:                   cmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmd.AddCommand(NewCmdAliasList(f, streams))
+	cmd.AddCommand(NewCmdAliasSet(f, streams))
+	cmd.AddCommand(NewCmdAliasRemove(f, streams))
+	return cmd
+}
+
+// ListOptions holds the options of 'alias list'.
+type ListOptions struct {
+	NoHeaders bool
+
+	ConfigAccess clientcmd.ConfigAccess
+
+	genericiooptions.IOStreams
+}
+
+// NewCmdAliasList returns the command listing the aliases.
+func NewCmdAliasList(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &ListOptions{IOStreams: streams}
+
+	cmd := &cobra.Command{
+		Use:                   "list [--no-headers]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("List the aliases"),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "When using the default output format, don't print headers (default print headers).")
+	return cmd
+}
+
+// Complete completes the options of 'alias list'.
+func (o *ListOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmdutil.UsageErrorf(cmd, "unexpected args: %v", args)
+	}
+	o.ConfigAccess = f.ToRawKubeConfigLoader().ConfigAccess()
+	return nil
+}
+
+// Run lists the aliases.
+func (o *ListOptions) Run() error {
+	config, err := o.ConfigAccess.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+	aliases, err := cmdutil.LoadAliases(config)
+	if err != nil {
+		return err
+	}
+
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+	if !o.NoHeaders {
+		fmt.Fprintln(w, "NAME\tTYPE\tEXPANSION")
+	}
+	for _, name := range sortedNames(aliases.Commands) {
+		fmt.Fprintf(w, "%s\tcommand\t%s\n", name, aliases.Commands[name])
+	}
+	for _, name := range sortedNames(aliases.Resources) {
+		fmt.Fprintf(w, "%s\tresource\t%s\n", name, aliases.Resources[name])
+	}
+	return nil
+}
+
+// SetOptions holds the options of 'alias set'.
+type SetOptions struct {
+	Name      string
+	Expansion string
+	// Command is whether the alias stands for a command line, rather than
+	// for a resource.
+	Command bool
+
+	ConfigAccess clientcmd.ConfigAccess
+
+	genericiooptions.IOStreams
+}
+
+// NewCmdAliasSet returns the command setting an alias.
+func NewCmdAliasSet(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &SetOptions{IOStreams: streams}
+
+	cmd := &cobra.Command{
+		Use:                   "set NAME (RESOURCE | COMMAND_LINE)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Set an alias of a command or of a resource"),
+		Long: templates.LongDesc(i18n.T(`
+			Set an alias of a command or of a resource.
+
+			The alias stands for a command line when the expansion starts with a command
+			of kubectl, and for a resource otherwise. The expansion is quoted like a shell
+			command line, and can also be given as separate arguments after --. The
+			definition NAME=EXPANSION is accepted as well.`)),
+		Example: templates.Examples(i18n.T(`
+			# Define the resource alias dep
+			kubectl alias set dep deployments.apps
+
+			# Define the command alias gp
+			kubectl alias set gp -- get pods -o wide`)),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+// Complete completes the options of 'alias set'.
+func (o *SetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) == 1 && strings.Contains(args[0], "=") {
+		args = strings.SplitN(args[0], "=", 2)
+	}
+	if len(args) < 2 {
+		return cmdutil.UsageErrorf(cmd, "a name and an expansion are required")
+	}
+	o.Name = args[0]
+	if len(args) == 2 {
+		o.Expansion = args[1]
+	} else {
+		words := make([]string, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			words = append(words, quote(arg))
+		}
+		o.Expansion = strings.Join(words, " ")
+	}
+
+	words, err := shlex.Split(o.Expansion)
+	if err != nil {
+		return fmt.Errorf("invalid expansion %q: %v", o.Expansion, err)
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("empty expansions are not allowed")
+	}
+	root := cmd.Root()
+	if words[0] == root.Name() && len(words) > 1 {
+		for i := range words[1:] {
+			words[i+1] = quote(words[i+1])
+		}
+		o.Expansion = strings.Join(words[1:], " ")
+		words = words[1:]
+	}
+	if isCommand(root, words[0]) {
+		o.Command = true
+	} else if len(words) > 1 {
+		return fmt.Errorf("%q is not a command of %s, and a resource alias must be a single resource", words[0], root.Name())
+	}
+	if isCommand(root, o.Name) {
+		return fmt.Errorf("%q is a command of %s, and can not be redefined", o.Name, root.Name())
+	}
+	o.ConfigAccess = f.ToRawKubeConfigLoader().ConfigAccess()
+	return nil
+}
+
+// Validate validates the options of 'alias set'.
+func (o *SetOptions) Validate() error {
+	if len(o.Name) == 0 || strings.ContainsAny(o.Name, " \t/,=") || strings.HasPrefix(o.Name, "-") {
+		return fmt.Errorf("invalid alias name %q, must not be empty, start with a dash, or contain spaces, slashes, commas or equal signs", o.Name)
+	}
+	return nil
+}
+
+// Run sets the alias.
+func (o *SetOptions) Run() error {
+	config, err := o.ConfigAccess.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+	aliases, err := cmdutil.LoadAliases(config)
+	if err != nil {
+		return err
+	}
+
+	delete(aliases.Commands, o.Name)
+	delete(aliases.Resources, o.Name)
+	if o.Command {
+		aliases.Commands[o.Name] = strings.TrimSpace(o.Expansion)
+	} else {
+		aliases.Resources[o.Name] = strings.TrimSpace(o.Expansion)
+	}
+	if err := cmdutil.StoreAliases(config, aliases); err != nil {
+		return err
+	}
+	if err := clientcmd.ModifyConfig(o.ConfigAccess, *config, true); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Alias %q set.\n", o.Name)
+	return nil
+}
+
+// RemoveOptions holds the options of 'alias remove'.
+type RemoveOptions struct {
+	Names []string
+
+	ConfigAccess clientcmd.ConfigAccess
+
+	genericiooptions.IOStreams
+}
+
+// NewCmdAliasRemove returns the command removing aliases.
+func NewCmdAliasRemove(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &RemoveOptions{IOStreams: streams}
+
+	cmd := &cobra.Command{
+		Use:                   "remove NAME...",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Remove aliases"),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+// Complete completes the options of 'alias remove'.
+func (o *RemoveOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmdutil.UsageErrorf(cmd, "at least one alias name is required")
+	}
+	o.Names = args
+	o.ConfigAccess = f.ToRawKubeConfigLoader().ConfigAccess()
+	return nil
+}
+
+// Run removes the aliases.
+func (o *RemoveOptions) Run() error {
+	config, err := o.ConfigAccess.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+	aliases, err := cmdutil.LoadAliases(config)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range o.Names {
+		_, isCommand := aliases.Commands[name]
+		_, isResource := aliases.Resources[name]
+		if !isCommand && !isResource {
+			return fmt.Errorf("no alias exists with the name: %q", name)
+		}
+		delete(aliases.Commands, name)
+		delete(aliases.Resources, name)
+	}
+	if err := cmdutil.StoreAliases(config, aliases); err != nil {
+		return err
+	}
+	if err := clientcmd.ModifyConfig(o.ConfigAccess, *config, true); err != nil {
+		return err
+	}
+	for _, name := range o.Names {
+		fmt.Fprintf(o.Out, "Alias %q removed.\n", name)
+	}
+	return nil
+}
+
+// isCommand returns whether name is a command of the root command, or one
+// cobra adds to it.
+func isCommand(root *cobra.Command, name string) bool {
+	if name == "help" || name == cobra.ShellCompRequestCmd || name == cobra.ShellCompNoDescRequestCmd {
+		return true
+	}
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// quote quotes the argument for shlex.Split when it holds spaces or quotes.
+func quote(arg string) string {
+	if len(arg) > 0 && !strings.ContainsAny(arg, " \t\n'\"\\") {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'"'"'`) + "'"
+}
+
+func sortedNames(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alias
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func newRoot(tf *cmdtesting.TestFactory, streams genericiooptions.IOStreams) *cobra.Command {
+	root := &cobra.Command{Use: "kubectl"}
+	root.AddCommand(&cobra.Command{Use: "get", Run: func(*cobra.Command, []string) {}})
+	root.AddCommand(NewCmdAlias(tf, streams))
+	return root
+}
+
+func TestAlias(t *testing.T) {
+	tf := cmdtesting.NewTestFactory()
+	defer tf.Cleanup()
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	for _, args := range [][]string{
+		{"alias", "set", "dep", "deployments.apps"},
+		{"alias", "set", "gp", "--", "get", "pods", "-o", "custom-columns=NAME:.metadata.name, NODE:.spec.nodeName"},
+		{"alias", "set", "gn=kubectl get nodes"},
+		{"alias", "set", "svc", "services"},
+		{"alias", "remove", "svc"},
+		{"alias", "list"},
+	} {
+		root := newRoot(tf, streams)
+		root.SetArgs(args)
+		if err := root.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := `Alias "dep" set.
+Alias "gp" set.
+Alias "gn" set.
+Alias "svc" set.
+Alias "svc" removed.
+NAME   TYPE       EXPANSION
+gn     command    get nodes
+gp     command    get pods -o 'custom-columns=NAME:.metadata.name, NODE:.spec.nodeName'
+dep    resource   deployments.apps
+`
+	if buf.String() != expected {
+		t.Errorf("expected output:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	config, err := tf.ToRawKubeConfigLoader().ConfigAccess().GetStartingConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	aliases, err := cmdutil.LoadAliases(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := newRoot(tf, streams)
+	expanded, err := aliases.Expand(root, []string{"gp", "-n", "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := []string{"get", "pods", "-o", "custom-columns=NAME:.metadata.name, NODE:.spec.nodeName", "-n", "test"}, expanded; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+}
+
+func TestAliasSetInvalid(t *testing.T) {
+	tests := []struct {
+		args        []string
+		expectedErr string
+	}{
+		{
+			args:        []string{"get", "deployments.apps"},
+			expectedErr: `"get" is a command of kubectl, and can not be redefined`,
+		},
+		{
+			args:        []string{"dep", "deployments.apps", "web"},
+			expectedErr: `"deployments.apps" is not a command of kubectl, and a resource alias must be a single resource`,
+		},
+		{
+			args:        []string{"d/p", "deployments.apps"},
+			expectedErr: `invalid alias name "d/p", must not be empty, start with a dash, or contain spaces, slashes, commas or equal signs`,
+		},
+		{
+			args:        []string{"gp", "get 'pods"},
+			expectedErr: `invalid expansion "get 'pods": EOF found when expecting closing quote`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.expectedErr, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory()
+			defer tf.Cleanup()
+
+			streams := genericiooptions.NewTestIOStreamsDiscard()
+			root := newRoot(tf, streams)
+			cmd, _, err := root.Find([]string{"alias", "set"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			o := &SetOptions{IOStreams: streams}
+			err = o.Complete(tf, cmd, test.args)
+			if err == nil {
+				err = o.Validate()
+			}
+			if err == nil || err.Error() != test.expectedErr {
+				t.Errorf("expected error %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// expandAliases expands the aliases stored in the kubeconfig file, the one
+// of --kubeconfig or the default one, in the arguments of the root command.
+// It is called before cobra finds the command to run, so that aliases can
+// stand for commands. The arguments are returned as they are when the
+// kubeconfig file can not be loaded, leaving the error to the command.
+func expandAliases(cmd *cobra.Command, args []string) []string {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfigArg(args)
+	config, err := rules.Load()
+	if err != nil {
+		klog.V(4).Infof("not expanding the aliases: %v", err)
+		return args
+	}
+	aliases, err := cmdutil.LoadAliases(config)
+	if err != nil {
+		klog.V(4).Infof("not expanding the aliases: %v", err)
+		return args
+	}
+	if len(aliases.Commands) == 0 && len(aliases.Resources) == 0 {
+		return args
+	}
+	expanded, err := aliases.Expand(cmd, args)
+	if err != nil {
+		klog.V(4).Infof("not expanding the aliases: %v", err)
+		return args
+	}
+	klog.V(4).Infof("expanded the aliases of %q into %q", args, expanded)
+	return expanded
+}
+
+// kubeconfigArg returns the value of --kubeconfig in the arguments, if any.
+func kubeconfigArg(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return ""
+		case strings.HasPrefix(arg, "--"+clientcmd.RecommendedConfigPathFlag+"="):
+			return strings.TrimPrefix(arg, "--"+clientcmd.RecommendedConfigPathFlag+"=")
+		case arg == "--"+clientcmd.RecommendedConfigPathFlag && i+1 < len(args):
+			return args[i+1]
+		}
+	}
+	return ""
+}
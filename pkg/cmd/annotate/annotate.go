@@ -53,10 +53,12 @@ type AnnotateFlags struct {
 	// Common user flags
 	All            bool
 	AllNamespaces  bool
+	Atomic         bool
 	DryRunStrategy cmdutil.DryRunStrategy
 	FieldManager   string
 	FieldSelector  string
 	resource.FilenameOptions
+	FromFiles       []string
 	List            bool
 	Local           bool
 	OutputFormat    string
@@ -82,6 +84,7 @@ func NewAnnotateFlags(streams genericiooptions.IOStreams) *AnnotateFlags {
 type AnnotateOptions struct {
 	all           bool
 	allNamespaces bool
+	atomic        bool
 
 	builder        *resource.Builder
 	dryRunStrategy cmdutil.DryRunStrategy
@@ -98,6 +101,7 @@ type AnnotateOptions struct {
 	namespace      string
 	newAnnotations map[string]string
 	overwrite      bool
+	printPatch     bool
 
 	PrintObj printers.ResourcePrinterFunc
 
@@ -142,7 +146,16 @@ var (
 
     # Update pod 'foo' by removing an annotation named 'description' if it exists
     # Does not require the --overwrite flag
-    kubectl annotate pods foo description-`))
+    kubectl annotate pods foo description-
+
+    # Update secret 'tls' with the annotation 'ca.crt' read from the file ca.pem
+    kubectl annotate secret tls --from-file=ca.crt=ca.pem
+
+    # Print the patch that would update deployment 'web' instead of applying it
+    kubectl annotate deployment web owner=team-a -o patch
+
+    # Update all the deployments with the label 'app=web', or none of them if any update fails
+    kubectl annotate deployments -l app=web owner=team-a --atomic`))
 )
 
 // NewCmdAnnotate creates the `annotate` command
@@ -171,6 +184,9 @@ func NewCmdAnnotate(parent string, f cmdutil.Factory, streams genericiooptions.I
 // AddFlags registers flags for a cli.
 func (flags *AnnotateFlags) AddFlags(cmd *cobra.Command, ioStreams genericiooptions.IOStreams) {
 	flags.PrintFlags.AddFlags(cmd)
+	if flag := cmd.Flags().Lookup("output"); flag != nil {
+		flag.Usage += " Use 'patch' to print the JSON merge patch for each resource instead of applying it."
+	}
 	flags.RecordFlags.AddFlags(cmd)
 
 	cmdutil.AddDryRunFlag(cmd)
@@ -179,8 +195,10 @@ func (flags *AnnotateFlags) AddFlags(cmd *cobra.Command, ioStreams genericioopti
 	cmdutil.AddFilenameOptionFlags(cmd, &flags.FilenameOptions, usage)
 	cmdutil.AddFieldManagerFlagVar(cmd, &flags.FieldManager, "kubectl-annotate")
 	cmdutil.AddLabelSelectorFlagVar(cmd, &flags.Selector)
+	cmdutil.AddAtomicFlagVar(cmd, &flags.Atomic)
 
 	cmd.Flags().BoolVar(&flags.overwrite, "overwrite", flags.overwrite, "If true, allow annotations to be overwritten, otherwise reject annotation updates that overwrite existing annotations.")
+	cmd.Flags().StringArrayVar(&flags.FromFiles, "from-file", flags.FromFiles, "Set the annotation KEY to the content of the file at PATH, given as KEY=PATH. Useful for long values such as certificates or JSON documents.")
 	cmd.Flags().BoolVar(&flags.List, "list", flags.List, "If true, display the annotations for a given resource.")
 	cmd.Flags().BoolVar(&flags.Local, "local", flags.Local, "If true, annotation will NOT contact api-server but run locally.")
 	cmd.Flags().StringVar(&flags.FieldSelector, "field-selector", flags.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The server only supports a limited number of field queries per type.")
@@ -194,6 +212,7 @@ func (flags *AnnotateFlags) ToOptions(f cmdutil.Factory, cmd *cobra.Command, arg
 	options := &AnnotateOptions{
 		all:             flags.All,
 		allNamespaces:   flags.AllNamespaces,
+		atomic:          flags.Atomic,
 		FilenameOptions: flags.FilenameOptions,
 		fieldSelector:   flags.FieldSelector,
 		fieldManager:    flags.FieldManager,
@@ -219,6 +238,10 @@ func (flags *AnnotateFlags) ToOptions(f cmdutil.Factory, cmd *cobra.Command, arg
 		return nil, err
 	}
 
+	if flags.PrintFlags.OutputFormat != nil && *flags.PrintFlags.OutputFormat == "patch" {
+		options.printPatch = true
+		*flags.PrintFlags.OutputFormat = ""
+	}
 	cmdutil.PrintFlagsWithDryRunStrategy(flags.PrintFlags, options.dryRunStrategy)
 	printer, err := flags.PrintFlags.ToPrinter()
 	if err != nil {
@@ -246,11 +269,24 @@ func (flags *AnnotateFlags) ToOptions(f cmdutil.Factory, cmd *cobra.Command, arg
 	if err != nil {
 		return nil, err
 	}
+	fileAnnotations, err := cmdutil.ParseFilePairs(flags.FromFiles, "annotation")
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range fileAnnotations {
+		if _, found := options.newAnnotations[key]; found {
+			return nil, fmt.Errorf("annotation %q is given both as an argument and with --from-file", key)
+		}
+		options.newAnnotations[key] = value
+	}
 
 	// Checks the options and flags to see if there is sufficient information run the command.
-	if flags.List && len(flags.OutputFormat) > 0 {
+	if flags.List && (len(flags.OutputFormat) > 0 || options.printPatch) {
 		return nil, fmt.Errorf("--list and --output may not be specified together")
 	}
+	if flags.Atomic && (flags.Local || flags.List || options.printPatch || options.dryRunStrategy != cmdutil.DryRunNone) {
+		return nil, fmt.Errorf("--atomic cannot be used with --local, --list, --dry-run or -o patch")
+	}
 	if flags.All && len(flags.Selector) > 0 {
 		return nil, fmt.Errorf("cannot set --all and --selector at the same time")
 	}
@@ -318,7 +354,12 @@ func (o AnnotateOptions) RunAnnotate() error {
 		return fmt.Errorf("--resource-version may only be used with a single resource")
 	}
 
-	return r.Visit(func(info *resource.Info, err error) error {
+	var transaction *cmdutil.Transaction
+	if o.atomic {
+		transaction = &cmdutil.Transaction{}
+	}
+
+	change := func(info *resource.Info, err error) error {
 		if err != nil {
 			return err
 		}
@@ -326,6 +367,10 @@ func (o AnnotateOptions) RunAnnotate() error {
 		var outputObj runtime.Object
 		obj := info.Object
 
+		if o.printPatch {
+			return o.printAnnotationPatch(obj)
+		}
+
 		if o.dryRunStrategy == cmdutil.DryRunClient || o.local || o.list {
 			if err := o.updateAnnotations(obj); err != nil {
 				return err
@@ -381,6 +426,11 @@ func (o AnnotateOptions) RunAnnotate() error {
 			if err != nil {
 				return err
 			}
+			if transaction != nil {
+				if err := transaction.Record(helper, info, oldData, newData); err != nil {
+					return err
+				}
+			}
 		}
 
 		if o.list {
@@ -406,7 +456,44 @@ func (o AnnotateOptions) RunAnnotate() error {
 		}
 
 		return o.PrintObj(outputObj, o.Out)
-	})
+	}
+
+	if transaction != nil {
+		return transaction.Run(r, func(info *resource.Info) error {
+			return o.updateAnnotations(info.Object.DeepCopyObject())
+		}, change, o.ErrOut)
+	}
+	return r.Visit(change)
+}
+
+// printAnnotationPatch prints the JSON merge patch that updates the
+// annotations of obj.
+func (o AnnotateOptions) printAnnotationPatch(obj runtime.Object) error {
+	if len(o.resourceVersion) != 0 {
+		// ensure resourceVersion is always part of the patch by clearing it from the starting JSON
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		accessor.SetResourceVersion("")
+	}
+	oldData, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if err := o.updateAnnotations(obj); err != nil {
+		return err
+	}
+	newData, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	patchBytes, err := jsonpatch.CreateMergePatch(oldData, newData)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o.Out, "%s\n", patchBytes)
+	return err
 }
 
 // parseAnnotations retrieves new and remove annotations from annotation args
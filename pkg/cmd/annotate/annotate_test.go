@@ -20,6 +20,8 @@ import (
 	"bytes"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -669,6 +671,52 @@ func TestAnnotateLocal(t *testing.T) {
 	}
 }
 
+func TestAnnotateFromFilePatch(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	tf.UnstructuredClient = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: "testgroup", Version: "v1"},
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			t.Fatalf("unexpected request: %s %#v\n%#v", req.Method, req.URL, req)
+			return nil, nil
+		}),
+	}
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+	certFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(certFile, []byte("-----BEGIN CERTIFICATE-----\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	iostreams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdAnnotate("kubectl", tf, iostreams)
+	flags := NewAnnotateFlags(iostreams)
+	flags.Local = true
+	flags.Filenames = []string{"../../../testdata/controller.yaml"}
+	flags.FromFiles = []string{"ca.crt=" + certFile}
+	*flags.PrintFlags.OutputFormat = "patch"
+
+	options, err := flags.ToOptions(tf, cmd, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := options.RunAnnotate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"metadata":{"annotations":{"ca.crt":"-----BEGIN CERTIFICATE-----\n"}}}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected patch %q, got %q", expected, buf.String())
+	}
+
+	flags.List = true
+	*flags.PrintFlags.OutputFormat = "patch"
+	if _, err := flags.ToOptions(tf, cmd, nil); err == nil {
+		t.Errorf("expected --list and -o patch to be rejected")
+	}
+}
+
 func TestAnnotateMultipleObjects(t *testing.T) {
 	pods, _, _ := cmdtesting.TestData()
 
@@ -17,8 +17,11 @@ limitations under the License.
 package apiresources
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -32,6 +35,7 @@ import (
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/metadata"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -55,7 +59,13 @@ var (
 		kubectl api-resources --namespaced=false
 
 		# Print the supported API resources with a specific APIGroup
-		kubectl api-resources --api-group=rbac.authorization.k8s.io`)
+		kubectl api-resources --api-group=rbac.authorization.k8s.io
+
+		# Print the resources defined by custom resource definitions in the groups ending with example.com
+		kubectl api-resources --crd-only --group-regex='example\.com$'
+
+		# Print the resources that can be either listed or watched, as JSON
+		kubectl api-resources --verbs-any-of=list,watch -o json`)
 )
 
 // APIResourceOptions is the start of the data required to perform the operation.
@@ -69,11 +79,17 @@ type APIResourceOptions struct {
 	NoHeaders  bool
 	Cached     bool
 	Categories []string
+	VerbsAllOf []string
+	VerbsAnyOf []string
+	CRDOnly    bool
+	GroupRegex string
 
 	groupChanged bool
 	nsChanged    bool
+	groupRegex   *regexp.Regexp
 
 	discoveryClient discovery.CachedDiscoveryInterface
+	metadataClient  metadata.Interface
 
 	genericiooptions.IOStreams
 }
@@ -83,6 +99,10 @@ type groupResource struct {
 	APIGroup        string
 	APIGroupVersion string
 	APIResource     metav1.APIResource
+	// Source is built-in, aggregated, or CRD with the manager owning the
+	// custom resource definition, and <unknown> when it cannot be told. It is
+	// only set for the wide output and with --crd-only.
+	Source string
 }
 
 // NewAPIResourceOptions creates the options for APIResource
@@ -110,11 +130,15 @@ func NewCmdAPIResources(restClientGetter genericclioptions.RESTClientGetter, ioS
 	}
 
 	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "When using the default or custom-column output format, don't print headers (default print headers).")
-	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, `Output format. One of: (wide, name).`)
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, `Output format. One of: (wide, name, json).`)
 
 	cmd.Flags().StringVar(&o.APIGroup, "api-group", o.APIGroup, "Limit to resources in the specified API group.")
 	cmd.Flags().BoolVar(&o.Namespaced, "namespaced", o.Namespaced, "If false, non-namespaced resources will be returned, otherwise returning namespaced resources by default.")
 	cmd.Flags().StringSliceVar(&o.Verbs, "verbs", o.Verbs, "Limit to resources that support the specified verbs.")
+	cmd.Flags().StringSliceVar(&o.VerbsAllOf, "verbs-all-of", o.VerbsAllOf, "Limit to resources that support all the specified verbs. Same as --verbs.")
+	cmd.Flags().StringSliceVar(&o.VerbsAnyOf, "verbs-any-of", o.VerbsAnyOf, "Limit to resources that support at least one of the specified verbs.")
+	cmd.Flags().BoolVar(&o.CRDOnly, "crd-only", o.CRDOnly, "Limit to resources defined by custom resource definitions.")
+	cmd.Flags().StringVar(&o.GroupRegex, "group-regex", o.GroupRegex, "Limit to resources in the API groups matching the regular expression.")
 	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "If non-empty, sort list of resources using specified field. The field can be either 'name' or 'kind'.")
 	cmd.Flags().BoolVar(&o.Cached, "cached", o.Cached, "Use the cached list of resources if available.")
 	cmd.Flags().StringSliceVar(&o.Categories, "categories", o.Categories, "Limit to resources that belong to the specified categories.")
@@ -123,7 +147,7 @@ func NewCmdAPIResources(restClientGetter genericclioptions.RESTClientGetter, ioS
 
 // Validate checks to the APIResourceOptions to see if there is sufficient information run the command
 func (o *APIResourceOptions) Validate() error {
-	supportedOutputTypes := sets.NewString("", "wide", "name")
+	supportedOutputTypes := sets.NewString("", "wide", "name", "json")
 	if !supportedOutputTypes.Has(o.Output) {
 		return fmt.Errorf("--output %v is not available", o.Output)
 	}
@@ -133,6 +157,13 @@ func (o *APIResourceOptions) Validate() error {
 			return fmt.Errorf("--sort-by accepts only name or kind")
 		}
 	}
+	if len(o.GroupRegex) > 0 {
+		regex, err := regexp.Compile(o.GroupRegex)
+		if err != nil {
+			return fmt.Errorf("invalid --group-regex: %v", err)
+		}
+		o.groupRegex = regex
+	}
 	return nil
 }
 
@@ -148,6 +179,18 @@ func (o *APIResourceOptions) Complete(restClientGetter genericclioptions.RESTCli
 	}
 	o.discoveryClient = discoveryClient
 
+	// the metadata of the custom resource definitions and of the API services
+	// is listed to tell the resources apart
+	if o.CRDOnly || o.Output == "wide" {
+		config, err := restClientGetter.ToRESTConfig()
+		if err != nil {
+			return err
+		}
+		if o.metadataClient, err = newMetadataClient(config); err != nil {
+			return err
+		}
+	}
+
 	o.groupChanged = cmd.Flags().Changed("api-group")
 	o.nsChanged = cmd.Flags().Changed("namespaced")
 
@@ -188,6 +231,9 @@ func (o *APIResourceOptions) RunAPIResources() error {
 			if o.groupChanged && o.APIGroup != gv.Group {
 				continue
 			}
+			if o.groupRegex != nil && !o.groupRegex.MatchString(gv.Group) {
+				continue
+			}
 			// filter namespaced
 			if o.nsChanged && o.Namespaced != resource.Namespaced {
 				continue
@@ -196,6 +242,12 @@ func (o *APIResourceOptions) RunAPIResources() error {
 			if len(o.Verbs) > 0 && !sets.NewString(resource.Verbs...).HasAll(o.Verbs...) {
 				continue
 			}
+			if len(o.VerbsAllOf) > 0 && !sets.NewString(resource.Verbs...).HasAll(o.VerbsAllOf...) {
+				continue
+			}
+			if len(o.VerbsAnyOf) > 0 && !sets.NewString(resource.Verbs...).HasAny(o.VerbsAnyOf...) {
+				continue
+			}
 			// filter to resources that belong to the specified categories
 			if len(o.Categories) > 0 && !sets.NewString(resource.Categories...).HasAll(o.Categories...) {
 				continue
@@ -208,13 +260,54 @@ func (o *APIResourceOptions) RunAPIResources() error {
 		}
 	}
 
+	if o.metadataClient != nil {
+		sources, crdErr := o.crdSources()
+		if crdErr != nil {
+			if o.CRDOnly {
+				return crdErr
+			}
+			fmt.Fprintf(o.ErrOut, "Warning: unable to list the custom resource definitions: %v\n", crdErr)
+		}
+		var aggregated sets.Set[string]
+		var apiServiceErr error
+		if !o.CRDOnly {
+			if aggregated, apiServiceErr = o.aggregatedGroupVersions(); apiServiceErr != nil {
+				fmt.Fprintf(o.ErrOut, "Warning: unable to list the API services: %v\n", apiServiceErr)
+			}
+		}
+		filtered := []groupResource{}
+		for _, r := range resources {
+			source, isCRD := sources[schema.GroupResource{Group: r.APIGroup, Resource: r.APIResource.Name}]
+			switch {
+			case isCRD:
+				r.Source = source
+			case o.CRDOnly:
+				continue
+			case crdErr != nil:
+				r.Source = "<unknown>"
+			case aggregated.Has(r.APIGroupVersion):
+				r.Source = "aggregated"
+			case apiServiceErr != nil:
+				r.Source = "<unknown>"
+			default:
+				r.Source = "built-in"
+			}
+			filtered = append(filtered, r)
+		}
+		resources = filtered
+	}
+
+	sort.Stable(sortableResource{resources, o.SortBy})
+	if o.Output == "json" {
+		return printJSON(o.Out, resources, errs)
+	}
+
 	if o.NoHeaders == false && o.Output != "name" {
 		if err = printContextHeaders(w, o.Output); err != nil {
 			return err
 		}
 	}
 
-	sort.Stable(sortableResource{resources, o.SortBy})
 	for _, r := range resources {
 		switch o.Output {
 		case "name":
@@ -226,14 +319,15 @@ func (o *APIResourceOptions) RunAPIResources() error {
 				errs = append(errs, err)
 			}
 		case "wide":
-			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\t%v\t%v\n",
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\t%v\t%v\t%s\n",
 				r.APIResource.Name,
 				strings.Join(r.APIResource.ShortNames, ","),
 				r.APIGroupVersion,
 				r.APIResource.Namespaced,
 				r.APIResource.Kind,
 				strings.Join(r.APIResource.Verbs, ","),
-				strings.Join(r.APIResource.Categories, ",")); err != nil {
+				strings.Join(r.APIResource.Categories, ","),
+				r.Source); err != nil {
 				errs = append(errs, err)
 			}
 		case "":
@@ -257,12 +351,107 @@ func (o *APIResourceOptions) RunAPIResources() error {
 func printContextHeaders(out io.Writer, output string) error {
 	columnNames := []string{"NAME", "SHORTNAMES", "APIVERSION", "NAMESPACED", "KIND"}
 	if output == "wide" {
-		columnNames = append(columnNames, "VERBS", "CATEGORIES")
+		columnNames = append(columnNames, "VERBS", "CATEGORIES", "SOURCE")
 	}
 	_, err := fmt.Fprintf(out, "%s\n", strings.Join(columnNames, "\t"))
 	return err
 }
 
+var (
+	crdResource        = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+	apiServiceResource = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+)
+
+// autoManagedLabel is set on the API services of the groups served by the
+// API server itself, as opposed to the groups of aggregated API servers.
+const autoManagedLabel = "kube-aggregator.kubernetes.io/automanaged"
+
+// newMetadataClient returns the client listing the metadata of the custom
+// resource definitions and of the API services.
+var newMetadataClient = metadata.NewForConfig
+
+// crdSources returns the source of the resources defined by custom resource
+// definitions: CRD, followed by the manager owning the definition, which is
+// usually the controller or the tool that installed it. Only the metadata of
+// the definitions is listed, their names being <plural>.<group>.
+func (o *APIResourceOptions) crdSources() (map[schema.GroupResource]string, error) {
+	list, err := o.metadataClient.Resource(crdResource).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	sources := map[schema.GroupResource]string{}
+	for _, crd := range list.Items {
+		plural, group, ok := strings.Cut(crd.Name, ".")
+		if !ok {
+			continue
+		}
+		source := "CRD"
+		if manager := owningManager(crd.GetManagedFields()); len(manager) > 0 {
+			source = fmt.Sprintf("CRD (%s)", manager)
+		}
+		sources[schema.GroupResource{Group: group, Resource: plural}] = source
+	}
+	return sources, nil
+}
+
+// aggregatedGroupVersions returns the group versions served by aggregated API
+// servers, from the API services which are not managed by the API server. The
+// names of the API services are <version>.<group>.
+func (o *APIResourceOptions) aggregatedGroupVersions() (sets.Set[string], error) {
+	list, err := o.metadataClient.Resource(apiServiceResource).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	groupVersions := sets.New[string]()
+	for _, apiService := range list.Items {
+		if _, ok := apiService.Labels[autoManagedLabel]; ok {
+			continue
+		}
+		version, group, ok := strings.Cut(apiService.Name, ".")
+		if !ok {
+			continue
+		}
+		groupVersions.Insert(schema.GroupVersion{Group: group, Version: version}.String())
+	}
+	return groupVersions, nil
+}
+
+// owningManager returns the first manager applying the object, or the first
+// manager updating it when it was never applied.
+func owningManager(managedFields []metav1.ManagedFieldsEntry) string {
+	for _, entry := range managedFields {
+		if entry.Operation == metav1.ManagedFieldsOperationApply && len(entry.Subresource) == 0 {
+			return entry.Manager
+		}
+	}
+	for _, entry := range managedFields {
+		if entry.Operation == metav1.ManagedFieldsOperationUpdate && len(entry.Subresource) == 0 {
+			return entry.Manager
+		}
+	}
+	return ""
+}
+
+// printJSON prints the resources as APIResource objects, with their group and
+// version set.
+func printJSON(out io.Writer, resources []groupResource, errs []error) error {
+	items := []metav1.APIResource{}
+	for _, r := range resources {
+		resource := r.APIResource
+		gv, _ := schema.ParseGroupVersion(r.APIGroupVersion)
+		resource.Group, resource.Version = gv.Group, gv.Version
+		items = append(items, resource)
+	}
+	data, err := json.MarshalIndent(items, "", "    ")
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(out, string(data)); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.NewAggregate(errs)
+}
+
 type sortableResource struct {
 	resources []groupResource
 	sortBy    string
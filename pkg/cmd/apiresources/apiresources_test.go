@@ -17,15 +17,47 @@ limitations under the License.
 package apiresources
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/metadata"
+	fakemetadata "k8s.io/client-go/metadata/fake"
+	"k8s.io/client-go/rest"
+	clienttesting "k8s.io/client-go/testing"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 )
 
+// fakeMetadataClient returns a metadata client listing the given custom
+// resource definitions and API services.
+func fakeMetadataClient(t *testing.T, crds, apiServices []*v1.PartialObjectMetadata) *fakemetadata.FakeMetadataClient {
+	client := fakemetadata.NewSimpleMetadataClient(fakemetadata.NewTestScheme())
+	for _, crd := range crds {
+		if err := client.Tracker().Create(crdResource, crd, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, apiService := range apiServices {
+		if err := client.Tracker().Create(apiServiceResource, apiService, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return client
+}
+
+func setMetadataClient(client metadata.Interface) func() {
+	orig := newMetadataClient
+	newMetadataClient = func(*rest.Config) (metadata.Interface, error) {
+		return client, nil
+	}
+	return func() { newMetadataClient = orig }
+}
+
 func TestAPIResourcesComplete(t *testing.T) {
 	tf := cmdtesting.NewTestFactory()
 	defer tf.Cleanup()
@@ -75,6 +107,13 @@ func TestAPIResourcesValidate(t *testing.T) {
 			},
 			expectedError: "--sort-by accepts only name or kind",
 		},
+		{
+			name: "invalid group regex",
+			optionSetupFn: func(o *APIResourceOptions) {
+				o.GroupRegex = "("
+			},
+			expectedError: "invalid --group-regex: error parsing regexp: missing closing ): `(`",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -150,6 +189,14 @@ func TestAPIResourcesRun(t *testing.T) {
 	}
 	tf := cmdtesting.NewTestFactory().WithDiscoveryClient(dc)
 	defer tf.Cleanup()
+	crd := &v1.PartialObjectMetadata{ObjectMeta: v1.ObjectMeta{
+		Name: "bazzes.somegroup",
+		ManagedFields: []v1.ManagedFieldsEntry{
+			{Manager: "kube-apiserver", Operation: v1.ManagedFieldsOperationUpdate, Subresource: "status"},
+			{Manager: "helm", Operation: v1.ManagedFieldsOperationApply},
+		},
+	}}
+	defer setMetadataClient(fakeMetadataClient(t, []*v1.PartialObjectMetadata{crd}, nil))()
 
 	testCases := []struct {
 		name                  string
@@ -193,10 +240,10 @@ bazzes   b            somegroup/v1   true         Baz
 			commandSetupFn: func(cmd *cobra.Command) {
 				cmd.Flags().Set("output", "wide")
 			},
-			expectedOutput: `NAME     SHORTNAMES   APIVERSION     NAMESPACED   KIND   VERBS                    CATEGORIES
-bars                  v1             true         Bar    get,list,create          
-foos     f,fo         v1             false        Foo    get,list                 some-category
-bazzes   b            somegroup/v1   true         Baz    get,list,create,delete   some-category,another-category
+			expectedOutput: `NAME     SHORTNAMES   APIVERSION     NAMESPACED   KIND   VERBS                    CATEGORIES                       SOURCE
+bars                  v1             true         Bar    get,list,create                                           built-in
+foos     f,fo         v1             false        Foo    get,list                 some-category                    built-in
+bazzes   b            somegroup/v1   true         Baz    get,list,create,delete   some-category,another-category   CRD (helm)
 `,
 			expectedInvalidations: 1,
 		},
@@ -240,6 +287,72 @@ bazzes   b            somegroup/v1   true         Baz
 			},
 			expectedOutput: `NAME     SHORTNAMES   APIVERSION     NAMESPACED   KIND
 bazzes   b            somegroup/v1   true         Baz
+`,
+			expectedInvalidations: 1,
+		},
+		{
+			name: "all of verbs",
+			commandSetupFn: func(cmd *cobra.Command) {
+				cmd.Flags().Set("verbs-all-of", "list,create")
+			},
+			expectedOutput: `NAME     SHORTNAMES   APIVERSION     NAMESPACED   KIND
+bars                  v1             true         Bar
+bazzes   b            somegroup/v1   true         Baz
+`,
+			expectedInvalidations: 1,
+		},
+		{
+			name: "any of verbs",
+			commandSetupFn: func(cmd *cobra.Command) {
+				cmd.Flags().Set("verbs-any-of", "delete,create")
+			},
+			expectedOutput: `NAME     SHORTNAMES   APIVERSION     NAMESPACED   KIND
+bars                  v1             true         Bar
+bazzes   b            somegroup/v1   true         Baz
+`,
+			expectedInvalidations: 1,
+		},
+		{
+			name: "group regex",
+			commandSetupFn: func(cmd *cobra.Command) {
+				cmd.Flags().Set("group-regex", "^some")
+			},
+			expectedOutput: `NAME     SHORTNAMES   APIVERSION     NAMESPACED   KIND
+bazzes   b            somegroup/v1   true         Baz
+`,
+			expectedInvalidations: 1,
+		},
+		{
+			name: "crd only",
+			commandSetupFn: func(cmd *cobra.Command) {
+				cmd.Flags().Set("crd-only", "true")
+			},
+			expectedOutput: `NAME     SHORTNAMES   APIVERSION     NAMESPACED   KIND
+bazzes   b            somegroup/v1   true         Baz
+`,
+			expectedInvalidations: 1,
+		},
+		{
+			name: "output json",
+			commandSetupFn: func(cmd *cobra.Command) {
+				cmd.Flags().Set("output", "json")
+				cmd.Flags().Set("api-group", "")
+				cmd.Flags().Set("verbs", "create")
+			},
+			expectedOutput: `[
+    {
+        "name": "bars",
+        "singularName": "",
+        "namespaced": true,
+        "version": "v1",
+        "kind": "Bar",
+        "verbs": [
+            "get",
+            "list",
+            "create"
+        ]
+    }
+]
 `,
 			expectedInvalidations: 1,
 		},
@@ -322,3 +435,93 @@ bazzes   b            somegroup/v1   true         Baz
 		})
 	}
 }
+
+func TestAPIResourcesSource(t *testing.T) {
+	dc := cmdtesting.NewFakeCachedDiscoveryClient()
+	dc.PreferredResources = []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{{Name: "foos", Kind: "Foo", Verbs: []string{"get", "list"}}},
+		},
+		{
+			GroupVersion: "somegroup/v1",
+			APIResources: []v1.APIResource{{Name: "bazzes", Kind: "Baz", Verbs: []string{"get", "list"}}},
+		},
+		{
+			GroupVersion: "metrics.k8s.io/v1beta1",
+			APIResources: []v1.APIResource{{Name: "pods", Kind: "PodMetrics", Verbs: []string{"get", "list"}}},
+		},
+	}
+	tf := cmdtesting.NewTestFactory().WithDiscoveryClient(dc)
+	defer tf.Cleanup()
+
+	crds := []*v1.PartialObjectMetadata{{ObjectMeta: v1.ObjectMeta{Name: "bazzes.somegroup"}}}
+	apiServices := []*v1.PartialObjectMetadata{
+		{ObjectMeta: v1.ObjectMeta{Name: "v1.", Labels: map[string]string{autoManagedLabel: "onstart"}}},
+		{ObjectMeta: v1.ObjectMeta{Name: "v1.somegroup", Labels: map[string]string{autoManagedLabel: "true"}}},
+		{ObjectMeta: v1.ObjectMeta{Name: "v1beta1.metrics.k8s.io"}},
+	}
+
+	testCases := []struct {
+		name           string
+		failResource   string
+		expectedOutput string
+		expectedErrOut string
+	}{
+		{
+			name: "all sources",
+			expectedOutput: `foos     built-in
+pods     aggregated
+bazzes   CRD
+`,
+		},
+		{
+			name:         "custom resource definitions cannot be listed",
+			failResource: crdResource.Resource,
+			expectedOutput: `foos     <unknown>
+pods     <unknown>
+bazzes   <unknown>
+`,
+			expectedErrOut: "Warning: unable to list the custom resource definitions: forbidden\n",
+		},
+		{
+			name:         "API services cannot be listed",
+			failResource: apiServiceResource.Resource,
+			expectedOutput: `foos     <unknown>
+pods     <unknown>
+bazzes   CRD
+`,
+			expectedErrOut: "Warning: unable to list the API services: forbidden\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fakeMetadataClient(t, crds, apiServices)
+			if len(tc.failResource) > 0 {
+				client.PrependReactor("list", tc.failResource, func(clienttesting.Action) (bool, runtime.Object, error) {
+					return true, nil, fmt.Errorf("forbidden")
+				})
+			}
+			defer setMetadataClient(client)()
+
+			ioStreams, _, out, errOut := genericiooptions.NewTestIOStreams()
+			cmd := NewCmdAPIResources(tf, ioStreams)
+			cmd.Flags().Set("output", "wide")
+			cmd.Flags().Set("no-headers", "true")
+			cmd.Run(cmd, []string{})
+
+			var got string
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				fields := strings.Fields(line)
+				got += fmt.Sprintf("%-8s %s\n", fields[0], fields[len(fields)-1])
+			}
+			if got != tc.expectedOutput {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, tc.expectedOutput)
+			}
+			if errOut.String() != tc.expectedErrOut {
+				t.Errorf("unexpected error output: %q, expected: %q", errOut.String(), tc.expectedErrOut)
+			}
+		})
+	}
+}
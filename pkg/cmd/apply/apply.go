@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
@@ -48,7 +49,10 @@ import (
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util"
 	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/kustomize"
+	"k8s.io/kubectl/pkg/util/manifestsource"
 	"k8s.io/kubectl/pkg/util/openapi"
+	"k8s.io/kubectl/pkg/util/policy"
 	"k8s.io/kubectl/pkg/util/prune"
 	"k8s.io/kubectl/pkg/util/templates"
 	"k8s.io/kubectl/pkg/validation"
@@ -74,6 +78,13 @@ type ApplyFlags struct {
 
 	PruneAllowlist []string
 
+	OrderBy     string
+	WaveTimeout time.Duration
+	PolicyDir   string
+	Progress    string
+
+	Kustomize *kustomize.Options
+
 	genericiooptions.IOStreams
 }
 
@@ -109,6 +120,28 @@ type ApplyOptions struct {
 	Namespace        string
 	EnforceNamespace bool
 
+	// OrderBy is the order the objects are applied in, the order of the
+	// files or the waves of their apply-wave annotation.
+	OrderBy string
+	// WaveTimeout is how long the objects of a wave are waited for to be
+	// ready before applying the next wave, and the custom resource
+	// definitions being applied to be established.
+	WaveTimeout time.Duration
+	// Policies are the client-side policies the objects are checked
+	// against before being applied.
+	Policies []*policy.Policy
+	// ClientForMapping returns the client of the objects, which are mapped
+	// after being read.
+	ClientForMapping func(mapping *meta.RESTMapping) (resource.RESTClient, error)
+	// Progress is how the progress is reported instead of printing each
+	// object: none, tty or plain.
+	Progress string
+	progress *applyProgress
+
+	// Kustomize builds the kustomization of -k when enabled, instead of the
+	// resource builder.
+	Kustomize *kustomize.Options
+
 	genericiooptions.IOStreams
 
 	// Objects (and some denormalized data) which are to be
@@ -147,6 +180,28 @@ var (
 
 		JSON and YAML formats are accepted.
 
+		With --order-by=wave, the objects are applied in waves: the objects with the
+		same kubectl.kubernetes.io/apply-wave annotation, an integer defaulting to 0, form
+		a wave, and the waves are applied in increasing order. The namespaces and the
+		custom resource definitions of a wave are applied before its other objects. Each
+		wave is waited for to be ready before the next one is applied: custom resource
+		definitions established, namespaces active, workloads rolled out and objects
+		with a Ready condition ready.
+
+		The custom resources of the kinds defined by custom resource definitions applied
+		along with them are applied after the other objects, once the definitions are
+		established.
+
+		With --policy-dir, the objects are checked against the policies of the YAML and JSON
+		files of the directory before any of them is applied. A policy has a name, an action,
+		deny by default or warn, optional kinds and operations (apply, delete) it applies to,
+		and rules checking the values found at a JSONPath: required, matching a pattern or
+		not matching a forbidden pattern. For instance:
+
+		    name: no-latest-images
+		    kinds: [Pod, Deployment, StatefulSet, DaemonSet, Job, CronJob]
+		    rules: [{path: "{..containers[*].image}", forbiddenPattern: ":latest$"}]
+
 		Alpha Disclaimer: the --prune functionality is not yet complete. Do not use unless you are aware of what the current state is. See https://issues.k8s.io/34274.`))
 
 	applyExample = templates.Examples(i18n.T(`
@@ -156,12 +211,28 @@ var (
 		# Apply resources from a directory containing kustomization.yaml - e.g. dir/kustomization.yaml
 		kubectl apply -k dir/
 
+		# Apply a kustomization inflating its Helm charts, with its remote bases cached in the CI cache
+		kubectl apply -k dir/ --enable-helm --cache-dir=.ci-cache
+
+		# Apply the manifests in the directory manifests, enforcing the policies of the directory policies
+		kubectl apply -f manifests/ --policy-dir=policies/
+
 		# Apply the JSON passed into stdin to a pod
 		cat pod.json | kubectl apply -f -
 
+		# Apply a large stream of manifests, showing a summary of the objects applied instead of each object
+		helm template ./chart | kubectl apply -f - --progress=tty
+
 		# Apply the configuration from all files that end with '.json'
 		kubectl apply -f '*.json'
 
+		# Apply the manifests of an OCI artifact, and of a directory of a git repository at a tag
+		kubectl apply -f oci://registry.example.com/manifests/app:v1.2
+		kubectl apply -f 'git::https://github.com/org/repo//deploy?ref=v1.2'
+
+		# Apply the custom resource definitions and namespaces of a directory before the objects using them
+		kubectl apply -f ./manifests --order-by=wave
+
 		# Note: --prune is still in Alpha
 		# Apply the configuration in manifest.yaml that matches label app=nginx and delete all other resources that are not in the file and match label app=nginx
 		kubectl apply --prune -f manifest.yaml -l app=nginx
@@ -187,6 +258,11 @@ func NewApplyFlags(streams genericiooptions.IOStreams) *ApplyFlags {
 
 		Overwrite:    true,
 		OpenAPIPatch: true,
+		OrderBy:      orderByFile,
+		Progress:     progressNone,
+		WaveTimeout:  5 * time.Minute,
+
+		Kustomize: kustomize.NewOptions(),
 
 		IOStreams: streams,
 	}
@@ -229,12 +305,18 @@ func (flags *ApplyFlags) AddFlags(cmd *cobra.Command) {
 
 	cmdutil.AddValidateFlags(cmd)
 	cmdutil.AddDryRunFlag(cmd)
+	cmdutil.AddConfirmationFlag(cmd)
 	cmdutil.AddServerSideApplyFlags(cmd)
 	cmdutil.AddFieldManagerFlagVar(cmd, &flags.FieldManager, FieldManagerClientSideApply)
 	cmdutil.AddLabelSelectorFlagVar(cmd, &flags.Selector)
 	cmdutil.AddPruningFlags(cmd, &flags.Prune, &flags.PruneAllowlist, &flags.All, &flags.ApplySetRef)
 	cmd.Flags().BoolVar(&flags.Overwrite, "overwrite", flags.Overwrite, "Automatically resolve conflicts between the modified and live configuration by using values from the modified configuration")
 	cmd.Flags().BoolVar(&flags.OpenAPIPatch, "openapi-patch", flags.OpenAPIPatch, "If true, use openapi to calculate diff when the openapi presents and the resource can be found in the openapi spec. Otherwise, fall back to use baked-in types.")
+	cmd.Flags().StringVar(&flags.OrderBy, "order-by", flags.OrderBy, "The order the objects are applied in. One of: file, wave. With wave, the objects are applied in the waves of their "+ApplyWaveAnnotation+" annotation, waiting for each wave to be ready.")
+	cmd.Flags().DurationVar(&flags.WaveTimeout, "wave-timeout", flags.WaveTimeout, "How long to wait for the objects of a wave to be ready with --order-by=wave, and for the custom resource definitions being applied to be established before applying their custom resources.")
+	cmd.Flags().StringVar(&flags.Progress, "progress", flags.Progress, "How to report the progress instead of printing each object applied. One of: none, tty, plain. With tty, a summary of the objects applied is redrawn on the standard error, with plain it is printed periodically. The number of objects of each result is printed at the end.")
+	cmd.Flags().StringVar(&flags.PolicyDir, "policy-dir", flags.PolicyDir, "A directory of YAML or JSON policies the objects are checked against before being applied. The violations of deny policies fail the command, those of warn policies are printed as warnings.")
+	flags.Kustomize.AddFlags(cmd)
 }
 
 // ToOptions converts from CLI inputs to runtime inputs
@@ -279,6 +361,7 @@ func (flags *ApplyFlags) ToOptions(f cmdutil.Factory, cmd *cobra.Command, baseNa
 	if err != nil {
 		return nil, err
 	}
+	flags.Kustomize.Complete(cmd)
 
 	var openAPIV3Root openapi3.Root
 	if !cmdutil.OpenAPIV3Patch.IsDisabled() {
@@ -336,6 +419,10 @@ func (flags *ApplyFlags) ToOptions(f cmdutil.Factory, cmd *cobra.Command, baseNa
 			return nil, err
 		}
 	}
+	policies, err := policy.Load(flags.PolicyDir)
+	if err != nil {
+		return nil, err
+	}
 
 	o := &ApplyOptions{
 		// 	Store baseName for use in printing warnings / messages involving the base command name.
@@ -360,6 +447,12 @@ func (flags *ApplyFlags) ToOptions(f cmdutil.Factory, cmd *cobra.Command, baseNa
 		Recorder:            recorder,
 		Namespace:           namespace,
 		EnforceNamespace:    enforceNamespace,
+		OrderBy:             flags.OrderBy,
+		Progress:            flags.Progress,
+		WaveTimeout:         flags.WaveTimeout,
+		Policies:            policies,
+		ClientForMapping:    f.UnstructuredClientForMapping,
+		Kustomize:           flags.Kustomize,
 		Validator:           validator,
 		ValidationDirective: validationDirective,
 		Builder:             builder,
@@ -406,6 +499,25 @@ func (o *ApplyOptions) Validate() error {
 		return fmt.Errorf("cannot set --all and --selector at the same time")
 	}
 
+	switch o.OrderBy {
+	case "", orderByFile:
+	case orderByWave:
+	default:
+		return fmt.Errorf("invalid --order-by %q, must be one of: %s, %s", o.OrderBy, orderByFile, orderByWave)
+	}
+	if o.WaveTimeout <= 0 {
+		return fmt.Errorf("--wave-timeout must be positive")
+	}
+	switch o.Progress {
+	case "", progressNone:
+	case progressTTY, progressPlain:
+		if len(*o.PrintFlags.OutputFormat) > 0 {
+			return fmt.Errorf("--progress=%s can not be used with --output", o.Progress)
+		}
+	default:
+		return fmt.Errorf("invalid --progress %q, must be one of: %s, %s, %s", o.Progress, progressNone, progressTTY, progressPlain)
+	}
+
 	if o.ApplySet != nil {
 		if !o.Prune {
 			return fmt.Errorf("--applyset requires --prune")
@@ -461,17 +573,38 @@ func isIncompatibleServerError(err error) bool {
 func (o *ApplyOptions) GetObjects() ([]*resource.Info, error) {
 	var err error = nil
 	if !o.objectsCached {
+		// the objects are all read below, so the fetched sources are not needed afterwards
+		cleanup, sourceErr := manifestsource.Fetch(&o.DeleteOptions.FilenameOptions)
+		defer cleanup()
+		if sourceErr != nil {
+			return nil, sourceErr
+		}
+		filenameOptions := &o.DeleteOptions.FilenameOptions
+		if o.Kustomize != nil {
+			var removeBuild func()
+			filenameOptions, removeBuild, sourceErr = o.Kustomize.BuildFilenameOptions(filenameOptions)
+			defer removeBuild()
+			if sourceErr != nil {
+				return nil, sourceErr
+			}
+		}
+
+		// the objects are mapped below, so that the objects of the kinds
+		// defined by the custom resource definitions being applied can be
+		// mapped once these are established
 		r := o.Builder.
+			Local().
 			Unstructured().
 			Schema(o.Validator).
 			ContinueOnError().
 			NamespaceParam(o.Namespace).DefaultNamespace().
-			FilenameParam(o.EnforceNamespace, &o.DeleteOptions.FilenameOptions).
+			FilenameParam(o.EnforceNamespace, filenameOptions).
 			LabelSelectorParam(o.Selector).
 			Flatten().
 			Do()
 
 		o.objects, err = r.Infos()
+		o.objects, err = o.mapObjects(o.objects, err)
 
 		if o.ApplySet != nil {
 			if err := o.ApplySet.AddLabels(o.objects...); err != nil {
@@ -516,17 +649,31 @@ func (o *ApplyOptions) Run() error {
 		return fmt.Errorf("no objects passed to apply")
 	}
 
+	if o.Progress == progressTTY || o.Progress == progressPlain {
+		o.progress = newApplyProgress(o.Progress, len(infos), o.ErrOut)
+		o.ToPrinter = o.progress.toPrinter
+		defer o.progress.done(o.Out)
+	}
+
+	// nothing is applied when an object violates a policy
+	if err := policy.Check(o.Policies, policy.OperationApply, infos, o.ErrOut); err != nil {
+		return err
+	}
+
 	if o.ApplySet != nil {
-		if err := o.ApplySet.BeforeApply(infos, o.DryRunStrategy, o.ValidationDirective); err != nil {
+		if err := o.ApplySet.BeforeApply(mappedObjects(infos), o.DryRunStrategy, o.ValidationDirective); err != nil {
 			return err
 		}
 	}
 
-	// Iterate through all objects, applying each one.
-	for _, info := range infos {
-		if err := o.applyOneObject(info); err != nil {
-			errs = append(errs, err)
+	if o.OrderBy == orderByWave {
+		waves, err := groupInWaves(infos)
+		if err != nil {
+			return err
 		}
+		errs = append(errs, o.applyWaves(waves)...)
+	} else {
+		errs = append(errs, o.applyInOrder(infos)...)
 	}
 	// If any errors occurred during apply, then return error (or
 	// aggregate of errors).
@@ -547,7 +694,14 @@ func (o *ApplyOptions) Run() error {
 	return nil
 }
 
-func (o *ApplyOptions) applyOneObject(info *resource.Info) error {
+func (o *ApplyOptions) applyOneObject(info *resource.Info) (err error) {
+	if o.progress != nil {
+		defer func() {
+			if err != nil {
+				o.progress.fail()
+			}
+		}()
+	}
 	o.MarkNamespaceVisited(info)
 
 	if err := o.Recorder.Record(info.Object); err != nil {
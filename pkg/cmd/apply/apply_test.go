@@ -37,6 +37,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -269,6 +271,19 @@ func TestApplyFlagValidation(t *testing.T) {
 			enableAlphas: []cmdutil.FeatureGate{cmdutil.ApplySet},
 			expectedErr:  "--prune-allowlist is incompatible with --applyset",
 		},
+		{
+			args: [][]string{
+				{"progress", "fancy"},
+			},
+			expectedErr: `invalid --progress "fancy", must be one of: none, tty, plain`,
+		},
+		{
+			args: [][]string{
+				{"progress", "plain"},
+				{"output", "name"},
+			},
+			expectedErr: "--progress=plain can not be used with --output",
+		},
 	}
 
 	for i, test := range tests {
@@ -3450,3 +3465,321 @@ func TestApplySetDryRun(t *testing.T) {
 		require.Nil(t, serverSideData[pathSecret], "secret was created")
 	})
 }
+
+func TestGroupInWaves(t *testing.T) {
+	newInfo := func(apiVersion, kind, name, wave string) *resource.Info {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetName(name)
+		if len(wave) > 0 {
+			obj.SetAnnotations(map[string]string{ApplyWaveAnnotation: wave})
+		}
+		return &resource.Info{Name: name, Object: obj}
+	}
+	infos := []*resource.Info{
+		newInfo("v1", "ConfigMap", "config", ""),
+		newInfo("example.com/v1", "Widget", "widget", "1"),
+		newInfo("apiextensions.k8s.io/v1", "CustomResourceDefinition", "widgets.example.com", ""),
+		newInfo("v1", "Namespace", "prod", ""),
+		newInfo("batch/v1", "Job", "migrate", "-1"),
+		newInfo("v1", "Service", "web", "0"),
+	}
+	waves, err := groupInWaves(infos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := [][]string{}
+	for _, wave := range waves {
+		waveNames := []string{}
+		for _, info := range wave {
+			waveNames = append(waveNames, info.Name)
+		}
+		names = append(names, waveNames)
+	}
+	expected := [][]string{{"migrate"}, {"widgets.example.com", "prod"}, {"config", "web"}, {"widget"}}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Errorf("unexpected waves (-want +got):\n%s", diff)
+	}
+
+	_, err = groupInWaves([]*resource.Info{newInfo("v1", "ConfigMap", "config", "first")})
+	if err == nil || err.Error() != `invalid kubectl.kubernetes.io/apply-wave annotation "first" of configmap/config: must be an integer` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestIsReady(t *testing.T) {
+	mapper := testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)
+	tests := []struct {
+		name          string
+		obj           string
+		expectedReady bool
+	}{
+		{
+			name:          "active namespace",
+			obj:           `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "prod"}, "status": {"phase": "Active"}}`,
+			expectedReady: true,
+		},
+		{
+			name: "namespace without status",
+			obj:  `{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "prod"}}`,
+		},
+		{
+			name:          "established custom resource definition",
+			obj:           `{"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition", "metadata": {"name": "widgets.example.com"}, "status": {"conditions": [{"type": "Established", "status": "True"}]}}`,
+			expectedReady: true,
+		},
+		{
+			name: "custom resource definition not established",
+			obj:  `{"apiVersion": "apiextensions.k8s.io/v1", "kind": "CustomResourceDefinition", "metadata": {"name": "widgets.example.com"}, "status": {"conditions": [{"type": "Established", "status": "False"}]}}`,
+		},
+		{
+			name:          "rolled out deployment",
+			obj:           `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "generation": 1}, "spec": {"replicas": 1}, "status": {"observedGeneration": 1, "replicas": 1, "updatedReplicas": 1, "availableReplicas": 1}}`,
+			expectedReady: true,
+		},
+		{
+			name: "deployment rolling out",
+			obj:  `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web", "generation": 2}, "spec": {"replicas": 1}, "status": {"observedGeneration": 1, "replicas": 1, "updatedReplicas": 1, "availableReplicas": 1}}`,
+		},
+		{
+			name: "pod not ready",
+			obj:  `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "web"}, "status": {"conditions": [{"type": "Ready", "status": "False"}]}}`,
+		},
+		{
+			name:          "config map",
+			obj:           `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "config"}}`,
+			expectedReady: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON([]byte(test.obj)); err != nil {
+				t.Fatal(err)
+			}
+			gvk := obj.GroupVersionKind()
+			mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				mapping = &meta.RESTMapping{GroupVersionKind: gvk, Scope: meta.RESTScopeRoot}
+			}
+			ready, reason, err := isReady(obj, mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ready != test.expectedReady {
+				t.Errorf("expected ready %v, got %v (%s)", test.expectedReady, ready, reason)
+			}
+		})
+	}
+}
+
+func TestApplyWaves(t *testing.T) {
+	cmdtesting.InitTestErrorHandler(t)
+	defer func(interval time.Duration) { waveInterval = interval }(waveInterval)
+	waveInterval = 10 * time.Millisecond
+
+	manifest := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: prod
+  annotations:
+    kubectl.kubernetes.io/apply-wave: "1"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: prod
+`
+	filename := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(filename, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tf := cmdtesting.NewTestFactory()
+	defer tf.Cleanup()
+
+	created := []string{}
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch m := req.Method; m {
+			case "GET":
+				return &http.Response{StatusCode: http.StatusNotFound, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.StringBody("")}, nil
+			case "POST":
+				created = append(created, req.URL.Path)
+				return &http.Response{StatusCode: http.StatusCreated, Header: cmdtesting.DefaultHeader(), Body: req.Body}, nil
+			default:
+				t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+				return nil, nil
+			}
+		}),
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod"}}
+	tf.FakeDynamicClient = dynamicfakeclient.NewSimpleDynamicClient(scheme.Scheme, namespace, service)
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+	ioStreams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdApply("kubectl", tf, ioStreams)
+	cmd.Flags().Set("filename", filename)
+	cmd.Flags().Set("order-by", "wave")
+	cmd.Flags().Set("output", "name")
+	cmd.Run(cmd, []string{})
+
+	expected := []string{"/namespaces", "/namespaces/prod/services", "/namespaces/prod/configmaps"}
+	if diff := cmp.Diff(expected, created); diff != "" {
+		t.Errorf("unexpected creations (-want +got):\n%s", diff)
+	}
+	if e, a := "namespace/prod\nservice/web\nconfigmap/app\n", buf.String(); e != a {
+		t.Errorf("expected output %q, got %q", e, a)
+	}
+}
+
+// resettableMapper is a RESTMapper that learns the kinds of the custom
+// resource definitions once reset.
+type resettableMapper struct {
+	meta.RESTMapper
+	reset func() meta.RESTMapper
+}
+
+func (m *resettableMapper) Reset() {
+	m.RESTMapper = m.reset()
+}
+
+func TestApplyCustomResourceDefinitionFirst(t *testing.T) {
+	cmdtesting.InitTestErrorHandler(t)
+	defer func(interval time.Duration) { waveInterval = interval }(waveInterval)
+	waveInterval = 10 * time.Millisecond
+
+	manifest := `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gear
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+`
+	filename := filepath.Join(t.TempDir(), "manifest.yaml")
+	if err := os.WriteFile(filename, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	crdGVK := schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+	widgetGVK := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	newMapper := func(established bool) meta.RESTMapper {
+		mapper := meta.NewDefaultRESTMapper(nil)
+		mapper.Add(crdGVK, meta.RESTScopeRoot)
+		if established {
+			mapper.Add(widgetGVK, meta.RESTScopeNamespace)
+		}
+		return mapper
+	}
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	created := []string{}
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch m := req.Method; m {
+			case "GET":
+				return &http.Response{StatusCode: http.StatusNotFound, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.StringBody("")}, nil
+			case "POST":
+				created = append(created, req.URL.Path)
+				return &http.Response{StatusCode: http.StatusCreated, Header: cmdtesting.DefaultHeader(), Body: req.Body}, nil
+			default:
+				t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+				return nil, nil
+			}
+		}),
+	}
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "widgets.example.com"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Established", "status": "True"}},
+		},
+	}}
+	tf.FakeDynamicClient = dynamicfakeclient.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}: "CustomResourceDefinitionList"}, crd)
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+	ioStreams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := &cobra.Command{}
+	flags := NewApplyFlags(ioStreams)
+	flags.AddFlags(cmd)
+	cmd.Flags().Set("filename", filename)
+	cmd.Flags().Set("output", "name")
+	o, err := flags.ToOptions(tf, cmd, "kubectl", []string{})
+	if err != nil {
+		t.Fatalf("unexpected error creating apply options: %v", err)
+	}
+	o.Mapper = &resettableMapper{RESTMapper: newMapper(false), reset: func() meta.RESTMapper { return newMapper(true) }}
+	if err := o.Validate(); err != nil {
+		t.Fatalf("unexpected error from validate: %v", err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error applying: %v", err)
+	}
+
+	expected := []string{"/customresourcedefinitions", "/namespaces/test/widgets"}
+	if diff := cmp.Diff(expected, created); diff != "" {
+		t.Errorf("unexpected creations (-want +got):\n%s", diff)
+	}
+	if e, a := "customresourcedefinition.apiextensions.k8s.io/widgets.example.com\nwidget.example.com/gear\n", buf.String(); e != a {
+		t.Errorf("expected output %q, got %q", e, a)
+	}
+}
+
+func TestApplyPolicyViolation(t *testing.T) {
+	policyDir := t.TempDir()
+	policy := "name: team-label\nkinds: [ReplicationController]\nrules: [{path: .metadata.labels.team, required: true}]\n"
+	if err := os.WriteFile(filepath.Join(policyDir, "team.yaml"), []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			t.Fatalf("unexpected request: %s %s", req.Method, req.URL.Path)
+			return nil, nil
+		}),
+	}
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+	ioStreams, _, _, _ := genericiooptions.NewTestIOStreams()
+	flags := NewApplyFlags(ioStreams)
+	cmd := &cobra.Command{}
+	flags.AddFlags(cmd)
+	cmd.Flags().Set("filename", filenameRC)
+	cmd.Flags().Set("policy-dir", policyDir)
+	o, err := flags.ToOptions(tf, cmd, "kubectl", []string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = o.Run()
+	expectedErr := "replicationcontrollers/test-rc violates the policy team-label: .metadata.labels.team is required"
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error %q, got %v", expectedErr, err)
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+const (
+	progressNone  = "none"
+	progressTTY   = "tty"
+	progressPlain = "plain"
+
+	// failedOperation counts the objects which could not be applied.
+	failedOperation = "failed"
+	// prunedOperation counts the objects pruned, which are not part of the
+	// objects applied.
+	prunedOperation = "pruned"
+)
+
+// progressOperations is the order the operations are summarized in.
+var progressOperations = []string{"created", "configured", "serverside-applied", "unchanged", prunedOperation, failedOperation}
+
+// progressIntervals are how often the progress is reported in each mode.
+var progressIntervals = map[string]time.Duration{
+	progressTTY:   100 * time.Millisecond,
+	progressPlain: 5 * time.Second,
+}
+
+// applyProgress counts the objects applied by operation, reporting the
+// counts periodically instead of printing the objects.
+type applyProgress struct {
+	mode  string
+	out   io.Writer
+	total int
+
+	applied int
+	counts  map[string]int
+	last    time.Time
+	now     func() time.Time
+}
+
+func newApplyProgress(mode string, total int, out io.Writer) *applyProgress {
+	return &applyProgress{mode: mode, out: out, total: total, counts: map[string]int{}, now: time.Now}
+}
+
+// toPrinter returns a printer counting the objects of the operation.
+func (p *applyProgress) toPrinter(operation string) (printers.ResourcePrinter, error) {
+	return printers.ResourcePrinterFunc(func(runtime.Object, io.Writer) error {
+		p.record(operation)
+		return nil
+	}), nil
+}
+
+func (p *applyProgress) record(operation string) {
+	p.counts[operation]++
+	if operation != prunedOperation {
+		p.applied++
+	}
+	p.report(false)
+}
+
+// fail counts an object which could not be applied.
+func (p *applyProgress) fail() {
+	p.record(failedOperation)
+}
+
+// report prints the progress, at most once per interval unless final.
+func (p *applyProgress) report(final bool) {
+	now := p.now()
+	if !final && now.Sub(p.last) < progressIntervals[p.mode] {
+		return
+	}
+	p.last = now
+
+	line := fmt.Sprintf("Applied %d/%d objects", p.applied, p.total)
+	if summary := p.summary(); len(summary) > 0 {
+		line += ": " + summary
+	}
+	if p.mode == progressTTY {
+		// the line is redrawn in place
+		fmt.Fprintf(p.out, "\r%s\x1b[K", line)
+		return
+	}
+	fmt.Fprintln(p.out, line)
+}
+
+// done reports the final progress, and prints the number of objects of
+// each operation to out.
+func (p *applyProgress) done(out io.Writer) {
+	p.report(true)
+	if p.mode == progressTTY {
+		fmt.Fprintln(p.out)
+	}
+
+	w := printers.GetNewTabWriter(out)
+	defer w.Flush()
+	fmt.Fprintln(w, "RESULT\tOBJECTS")
+	for _, operation := range p.operations() {
+		fmt.Fprintf(w, "%s\t%d\n", operation, p.counts[operation])
+	}
+}
+
+func (p *applyProgress) summary() string {
+	parts := []string{}
+	for _, operation := range p.operations() {
+		parts = append(parts, fmt.Sprintf("%d %s", p.counts[operation], operation))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// operations returns the operations of the objects counted, in the order
+// of progressOperations and then alphabetically.
+func (p *applyProgress) operations() []string {
+	operations := []string{}
+	for _, operation := range progressOperations {
+		if p.counts[operation] > 0 {
+			operations = append(operations, operation)
+		}
+	}
+	others := []string{}
+	for operation := range p.counts {
+		known := false
+		for _, o := range progressOperations {
+			known = known || o == operation
+		}
+		if !known {
+			others = append(others, operation)
+		}
+	}
+	sort.Strings(others)
+	return append(operations, others...)
+}
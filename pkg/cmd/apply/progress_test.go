@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestApplyProgress(t *testing.T) {
+	type step struct {
+		operation string
+		// elapsed is the time since the previous step
+		elapsed time.Duration
+	}
+	tests := []struct {
+		name      string
+		mode      string
+		total     int
+		steps     []step
+		expectErr string
+		expectOut string
+	}{
+		{
+			name:  "plain",
+			mode:  progressPlain,
+			total: 4,
+			steps: []step{
+				{operation: "created"},
+				{operation: "configured", elapsed: time.Second},
+				{operation: "configured", elapsed: 5 * time.Second},
+				{operation: failedOperation, elapsed: time.Second},
+				{operation: prunedOperation, elapsed: time.Second},
+			},
+			expectErr: "Applied 1/4 objects: 1 created\n" +
+				"Applied 3/4 objects: 1 created, 2 configured\n" +
+				"Applied 4/4 objects: 1 created, 2 configured, 1 pruned, 1 failed\n",
+			expectOut: "RESULT       OBJECTS\n" +
+				"created      1\n" +
+				"configured   2\n" +
+				"pruned       1\n" +
+				"failed       1\n",
+		},
+		{
+			name:  "tty",
+			mode:  progressTTY,
+			total: 2,
+			steps: []step{
+				{operation: "unchanged"},
+				{operation: "serverside-applied", elapsed: time.Second},
+			},
+			expectErr: "\rApplied 1/2 objects: 1 unchanged\x1b[K" +
+				"\rApplied 2/2 objects: 1 serverside-applied, 1 unchanged\x1b[K" +
+				"\rApplied 2/2 objects: 1 serverside-applied, 1 unchanged\x1b[K\n",
+			expectOut: "RESULT               OBJECTS\n" +
+				"serverside-applied   1\n" +
+				"unchanged            1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errOut, out := &bytes.Buffer{}, &bytes.Buffer{}
+			p := newApplyProgress(tt.mode, tt.total, errOut)
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			p.now = func() time.Time { return now }
+			for _, s := range tt.steps {
+				now = now.Add(s.elapsed)
+				if s.operation == failedOperation {
+					p.fail()
+					continue
+				}
+				printer, _ := p.toPrinter(s.operation)
+				if err := printer.PrintObj(nil, out); err != nil {
+					t.Fatal(err)
+				}
+			}
+			p.done(out)
+
+			if errOut.String() != tt.expectErr {
+				t.Errorf("unexpected progress:\n%q\nexpected:\n%q", errOut.String(), tt.expectErr)
+			}
+			if out.String() != tt.expectOut {
+				t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), tt.expectOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+const (
+	// ApplyWaveAnnotation is the annotation giving the wave of an object
+	// applied with --order-by=wave, an integer defaulting to 0.
+	ApplyWaveAnnotation = "kubectl.kubernetes.io/apply-wave"
+
+	orderByFile = "file"
+	orderByWave = "wave"
+)
+
+// waveInterval is the interval the readiness of the objects of a wave is
+// checked at.
+var waveInterval = 2 * time.Second
+
+var (
+	namespaceGroupKind = schema.GroupKind{Kind: "Namespace"}
+	crdGroupKind       = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+)
+
+// groupInWaves groups the objects in the waves they are applied in, in
+// order. The objects of a wave are those with the same apply-wave
+// annotation, the namespaces and custom resource definitions of a wave
+// forming a wave of their own before the other objects, since these depend
+// on them. The order of the files is kept within a wave.
+func groupInWaves(infos []*resource.Info) ([][]*resource.Info, error) {
+	type waveKey struct {
+		wave        int
+		definitions bool
+	}
+	waves := map[waveKey][]*resource.Info{}
+	for _, info := range infos {
+		accessor, err := meta.Accessor(info.Object)
+		if err != nil {
+			return nil, err
+		}
+		key := waveKey{}
+		if value, ok := accessor.GetAnnotations()[ApplyWaveAnnotation]; ok {
+			if key.wave, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("invalid %s annotation %q of %s: must be an integer", ApplyWaveAnnotation, value, info.ObjectName())
+			}
+		}
+		gk := info.Object.GetObjectKind().GroupVersionKind().GroupKind()
+		key.definitions = gk == namespaceGroupKind || gk == crdGroupKind
+		waves[key] = append(waves[key], info)
+	}
+
+	keys := make([]waveKey, 0, len(waves))
+	for key := range waves {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].wave != keys[j].wave {
+			return keys[i].wave < keys[j].wave
+		}
+		return keys[i].definitions && !keys[j].definitions
+	})
+	grouped := make([][]*resource.Info, 0, len(keys))
+	for _, key := range keys {
+		grouped = append(grouped, waves[key])
+	}
+	return grouped, nil
+}
+
+// mapObject sets the mapping and the client of an object read without
+// them, and its namespace like the resource builder does.
+func (o *ApplyOptions) mapObject(info *resource.Info) error {
+	gvk := info.Object.GetObjectKind().GroupVersionKind()
+	mapping, err := o.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			return fmt.Errorf("resource mapping not found for name: %q namespace: %q from %q: %w\nensure CRDs are installed first", info.Name, info.Namespace, info.Source, err)
+		}
+		return fmt.Errorf("unable to recognize %q: %v", info.Source, err)
+	}
+	client, err := o.ClientForMapping(mapping)
+	if err != nil {
+		return fmt.Errorf("unable to connect to a server to handle %q: %v", mapping.Resource, err)
+	}
+	info.Mapping = mapping
+	info.Client = client
+	if o.EnforceNamespace {
+		return resource.RequireNamespace(o.Namespace)(info, nil)
+	}
+	return resource.SetNamespace(o.Namespace)(info, nil)
+}
+
+// applyWaves applies the waves of objects in order, waiting for the objects
+// of a wave to be ready before applying the next one.
+func (o *ApplyOptions) applyWaves(waves [][]*resource.Info) []error {
+	defined := sets.New[schema.GroupKind]()
+	for i, wave := range waves {
+		errs := []error{}
+		deferred := []*resource.Info{}
+		for _, info := range unmappedObjects(wave) {
+			if gk := info.Object.GetObjectKind().GroupVersionKind().GroupKind(); !defined.Has(gk) {
+				errs = append(errs, fmt.Errorf("the custom resource definition of %s must be applied in an earlier wave", info.ObjectName()))
+				continue
+			}
+			deferred = append(deferred, info)
+		}
+		errs = append(errs, o.mapDeferred(deferred)...)
+		for _, info := range mappedObjects(wave) {
+			if err := o.applyOneObject(info); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			if i < len(waves)-1 {
+				errs = append(errs, fmt.Errorf("the %d following waves were not applied", len(waves)-1-i))
+			}
+			return errs
+		}
+		defined = defined.Union(definedKinds(wave))
+		if i == len(waves)-1 || o.DryRunStrategy != cmdutil.DryRunNone {
+			continue
+		}
+		if err := o.waitForReadiness(wave); err != nil {
+			return []error{err}
+		}
+	}
+	return nil
+}
+
+// applyInOrder applies the objects in the order of the files. The objects
+// of the kinds defined by the custom resource definitions being applied
+// are applied last, once these are established.
+func (o *ApplyOptions) applyInOrder(infos []*resource.Info) []error {
+	errs := []error{}
+	for _, info := range mappedObjects(infos) {
+		if err := o.applyOneObject(info); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	deferred := unmappedObjects(infos)
+	if len(deferred) == 0 || len(errs) > 0 {
+		return errs
+	}
+
+	if o.DryRunStrategy == cmdutil.DryRunNone {
+		crds := []*resource.Info{}
+		for _, info := range mappedObjects(infos) {
+			if info.Mapping.GroupVersionKind.GroupKind() == crdGroupKind {
+				crds = append(crds, info)
+			}
+		}
+		klog.V(2).Infof("waiting for %d custom resource definitions to be established", len(crds))
+		if err := o.waitForReadiness(crds); err != nil {
+			return []error{err}
+		}
+	}
+	errs = o.mapDeferred(deferred)
+	for _, info := range mappedObjects(deferred) {
+		if err := o.applyOneObject(info); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// mapObjects maps the objects read by the resource builder, keeping the
+// objects of the kinds defined by the custom resource definitions among
+// them unmapped, to be mapped by mapDeferred once these are established.
+func (o *ApplyOptions) mapObjects(infos []*resource.Info, err error) ([]*resource.Info, error) {
+	errs := []error{}
+	if err != nil {
+		errs = append(errs, err)
+	}
+	defined := definedKinds(infos)
+	mapped := []*resource.Info{}
+	for _, info := range infos {
+		if err := o.mapObject(info); err != nil {
+			if !meta.IsNoMatchError(err) || !defined.Has(info.Object.GetObjectKind().GroupVersionKind().GroupKind()) {
+				errs = append(errs, err)
+				continue
+			}
+			klog.V(2).Infof("deferring %s until its custom resource definition is established", info.ObjectName())
+		}
+		mapped = append(mapped, info)
+	}
+	return mapped, utilerrors.NewAggregate(errs)
+}
+
+// mapDeferred maps the objects left unmapped by mapObjects, resetting the
+// mapper to discover the kinds of the established custom resource
+// definitions. The mapping is retried until the wave timeout while the
+// kinds are not discovered yet, except in dry-run, since the definitions
+// are not created then. The objects that can not be mapped stay unmapped.
+func (o *ApplyOptions) mapDeferred(infos []*resource.Info) []error {
+	if len(infos) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), o.WaveTimeout)
+	defer cancel()
+	pending := infos
+	errs := []error{}
+	_ = wait.PollUntilContextCancel(ctx, waveInterval, true, func(ctx context.Context) (bool, error) {
+		meta.MaybeResetRESTMapper(o.Mapper)
+		errs = []error{}
+		remaining := []*resource.Info{}
+		for _, info := range pending {
+			if err := o.mapObject(info); err != nil {
+				errs = append(errs, err)
+				if meta.IsNoMatchError(err) {
+					remaining = append(remaining, info)
+				}
+			}
+		}
+		pending = remaining
+		return len(pending) == 0 || o.DryRunStrategy != cmdutil.DryRunNone, nil
+	})
+	if mapped := mappedObjects(infos); o.ApplySet != nil && len(mapped) > 0 {
+		if err := o.ApplySet.BeforeApply(mapped, o.DryRunStrategy, o.ValidationDirective); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// definedKinds returns the kinds defined by the custom resource definitions
+// among the objects.
+func definedKinds(infos []*resource.Info) sets.Set[schema.GroupKind] {
+	kinds := sets.New[schema.GroupKind]()
+	for _, info := range infos {
+		obj, ok := info.Object.(*unstructured.Unstructured)
+		if !ok || obj.GroupVersionKind().GroupKind() != crdGroupKind {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+		kinds.Insert(schema.GroupKind{Group: group, Kind: kind})
+	}
+	return kinds
+}
+
+func mappedObjects(infos []*resource.Info) []*resource.Info {
+	mapped := []*resource.Info{}
+	for _, info := range infos {
+		if info.Mapping != nil {
+			mapped = append(mapped, info)
+		}
+	}
+	return mapped
+}
+
+func unmappedObjects(infos []*resource.Info) []*resource.Info {
+	unmapped := []*resource.Info{}
+	for _, info := range infos {
+		if info.Mapping == nil {
+			unmapped = append(unmapped, info)
+		}
+	}
+	return unmapped
+}
+
+// waitForReadiness waits for the objects of a wave to be ready, for at most
+// the wave timeout.
+func (o *ApplyOptions) waitForReadiness(wave []*resource.Info) error {
+	ctx, cancel := context.WithTimeout(context.Background(), o.WaveTimeout)
+	defer cancel()
+	for _, info := range wave {
+		message := "not found"
+		err := wait.PollUntilContextCancel(ctx, waveInterval, true, func(ctx context.Context) (bool, error) {
+			obj, err := o.DynamicClient.Resource(info.Mapping.Resource).Namespace(info.Namespace).Get(ctx, info.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			ready, reason, err := isReady(obj, info.Mapping)
+			message = reason
+			return ready, err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("timed out waiting for %s to be ready: %s", info.ObjectName(), message)
+			}
+			return err
+		}
+		klog.V(2).Infof("%s is ready", info.ObjectName())
+	}
+	return nil
+}
+
+// isReady returns whether the object is ready for the objects of the next
+// waves, and the reason when it is not. Custom resource definitions must be
+// established, namespaces active, workloads rolled out, and objects with a
+// Ready condition ready. Other objects are ready once applied.
+func isReady(obj *unstructured.Unstructured, mapping *meta.RESTMapping) (bool, string, error) {
+	switch mapping.GroupVersionKind.GroupKind() {
+	case crdGroupKind:
+		if conditionStatus(obj, "Established") != "True" {
+			return false, "the custom resource definition is not established", nil
+		}
+		return true, "", nil
+	case namespaceGroupKind:
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		if phase != "Active" {
+			return false, fmt.Sprintf("the namespace is %s", phase), nil
+		}
+		return true, "", nil
+	}
+
+	if viewer, err := polymorphichelpers.StatusViewerFn(mapping); err == nil {
+		message, done, err := viewer.Status(obj, 0)
+		return done, message, err
+	}
+	switch conditionStatus(obj, "Ready") {
+	case "", "True":
+		return true, "", nil
+	default:
+		return false, "the Ready condition is not true", nil
+	}
+}
+
+// conditionStatus returns the status of the condition of the object, or an
+// empty string when the object has no such condition.
+func conditionStatus(obj *unstructured.Unstructured, conditionType string) string {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		return status
+	}
+	return ""
+}
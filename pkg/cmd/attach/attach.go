@@ -28,10 +28,10 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/resource"
+	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/kubectl/pkg/cmd/exec"
@@ -59,6 +59,12 @@ var (
 
 		# Get output from the first pod of a replica set named nginx
 		kubectl attach rs/nginx
+
+		# Reattach to the tmux or screen session named dev started with 'kubectl exec mypod -i -t --session dev'
+		kubectl attach mypod -i -t --session dev
+
+		# List the tmux or screen sessions in pod mypod
+		kubectl attach mypod --list-sessions
 		`))
 )
 
@@ -87,6 +93,16 @@ type AttachOptions struct {
 	Attach        RemoteAttach
 	GetPodTimeout time.Duration
 	Config        *restclient.Config
+
+	// Session reattaches to a session started with 'kubectl exec --session'
+	// instead of attaching to the container's main process.
+	Session string
+	// ListSessions lists the sessions running in the container.
+	ListSessions bool
+	// PodClient and Executor are used to reach sessions, which are
+	// executed in the container.
+	PodClient coreclient.PodsGetter
+	Executor  exec.RemoteExecutor
 }
 
 // NewAttachOptions creates the options for attach
@@ -97,6 +113,7 @@ func NewAttachOptions(streams genericiooptions.IOStreams) *AttachOptions {
 		},
 		Attach:     &DefaultRemoteAttach{},
 		AttachFunc: DefaultAttachFunc,
+		Executor:   &exec.DefaultRemoteExecutor{},
 	}
 }
 
@@ -121,6 +138,8 @@ func NewCmdAttach(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.
 	cmd.Flags().BoolVarP(&o.Stdin, "stdin", "i", o.Stdin, "Pass stdin to the container")
 	cmd.Flags().BoolVarP(&o.TTY, "tty", "t", o.TTY, "Stdin is a TTY")
 	cmd.Flags().BoolVarP(&o.Quiet, "quiet", "q", o.Quiet, "Only print output from the remote session")
+	cmd.Flags().StringVar(&o.Session, "session", o.Session, "Reattach to the named session started with 'kubectl exec --session'. Requires tmux or screen in the container.")
+	cmd.Flags().BoolVar(&o.ListSessions, "list-sessions", o.ListSessions, "List the tmux or screen sessions running in the container. Requires tmux or screen in the container.")
 	return cmd
 }
 
@@ -158,23 +177,10 @@ type DefaultRemoteAttach struct{}
 
 // Attach executes attach to a running container
 func (*DefaultRemoteAttach) Attach(url *url.URL, config *restclient.Config, stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue remotecommand.TerminalSizeQueue) error {
-	// Legacy SPDY executor is default. If feature gate enabled, fallback
-	// executor attempts websockets first--then SPDY.
-	exec, err := remotecommand.NewSPDYExecutor(config, "POST", url)
+	exec, err := cmdutil.NewRemoteCommandExecutor(config, url)
 	if err != nil {
 		return err
 	}
-	if cmdutil.RemoteCommandWebsockets.IsEnabled() {
-		// WebSocketExecutor must be "GET" method as described in RFC 6455 Sec. 4.1 (page 17).
-		websocketExec, err := remotecommand.NewWebSocketExecutor(config, "GET", url.String())
-		if err != nil {
-			return err
-		}
-		exec, err = remotecommand.NewFallbackExecutor(websocketExec, exec, httpstream.IsUpgradeFailure)
-		if err != nil {
-			return err
-		}
-	}
 	return exec.StreamWithContext(context.Background(), remotecommand.StreamOptions{
 		Stdin:             stdin,
 		Stdout:            stdout,
@@ -227,6 +233,17 @@ func (o *AttachOptions) Validate() error {
 	if o.GetPodTimeout <= 0 {
 		return fmt.Errorf("--pod-running-timeout must be higher than zero")
 	}
+	if len(o.Session) > 0 {
+		if o.ListSessions {
+			return fmt.Errorf("--session and --list-sessions are mutually exclusive")
+		}
+		if err := exec.ValidateSessionName(o.Session); err != nil {
+			return err
+		}
+		if !o.Stdin || !o.TTY {
+			return fmt.Errorf("--session requires -i/--stdin and -t/--tty")
+		}
+	}
 
 	return nil
 }
@@ -266,6 +283,9 @@ func (o *AttachOptions) Run() error {
 	if err != nil {
 		return fmt.Errorf("cannot attach to the container: %v", err)
 	}
+	if len(o.Session) > 0 || o.ListSessions {
+		return o.runSession(containerToAttach)
+	}
 	if o.TTY && !containerToAttach.TTY {
 		o.TTY = false
 		if !o.Quiet && o.ErrOut != nil {
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package attach
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/cmd/exec"
+)
+
+// runSession reattaches to, or lists, the sessions in container. Sessions
+// are processes of their own, so they are reached through exec rather than
+// attach.
+func (o *AttachOptions) runSession(container *corev1.Container) error {
+	if o.PodClient == nil {
+		clientset, err := kubernetes.NewForConfig(o.Config)
+		if err != nil {
+			return err
+		}
+		o.PodClient = clientset.CoreV1()
+	}
+
+	if o.Executor == nil {
+		o.Executor = &exec.DefaultRemoteExecutor{}
+	}
+
+	options := &exec.ExecOptions{
+		StreamOptions: o.StreamOptions,
+		Command:       exec.ReattachSessionCommand(o.Session),
+		Executor:      o.Executor,
+		PodClient:     o.PodClient,
+		Config:        o.Config,
+	}
+	options.Namespace = o.Pod.Namespace
+	options.PodName = o.Pod.Name
+	options.ContainerName = container.Name
+	if o.ListSessions {
+		options.Command = exec.ListSessionsCommand()
+		options.Stdin = false
+		options.TTY = false
+	}
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	if err := options.Run(); err != nil {
+		if o.ListSessions {
+			return fmt.Errorf("unable to list sessions in container %s: %v", container.Name, err)
+		}
+		return fmt.Errorf("unable to reattach to session %s in container %s: %v", o.Session, container.Name, err)
+	}
+
+	if len(o.Session) > 0 && !o.Quiet {
+		fmt.Fprintf(o.ErrOut, "If session %s was detached, reattach using '%s %s -c %s -i -t --session %s'\n", o.Session, o.CommandName, o.Pod.Name, container.Name, o.Session)
+	}
+	return nil
+}
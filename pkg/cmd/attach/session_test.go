@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package attach
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/kubectl/pkg/cmd/exec"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+type fakeSessionExecutor struct {
+	url   *url.URL
+	stdin bool
+	tty   bool
+	err   error
+}
+
+func (f *fakeSessionExecutor) Execute(url *url.URL, config *restclient.Config, stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue remotecommand.TerminalSizeQueue) error {
+	f.url = url
+	f.stdin = stdin != nil
+	f.tty = tty
+	return f.err
+}
+
+func TestValidateSession(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     AttachOptions
+		expectError string
+	}{
+		{
+			name:    "reattach",
+			options: AttachOptions{StreamOptions: exec.StreamOptions{Stdin: true, TTY: true}, Session: "dev"},
+		},
+		{
+			name:    "list",
+			options: AttachOptions{ListSessions: true},
+		},
+		{
+			name:        "reattach without tty",
+			options:     AttachOptions{Session: "dev"},
+			expectError: "requires -i/--stdin and -t/--tty",
+		},
+		{
+			name:        "reattach and list",
+			options:     AttachOptions{StreamOptions: exec.StreamOptions{Stdin: true, TTY: true}, Session: "dev", ListSessions: true},
+			expectError: "mutually exclusive",
+		},
+		{
+			name:        "invalid name",
+			options:     AttachOptions{StreamOptions: exec.StreamOptions{Stdin: true, TTY: true}, Session: "a.b"},
+			expectError: "invalid session name",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.Resources = []string{"foo"}
+			test.options.GetPodTimeout = time.Minute
+			err := test.options.Validate()
+			if len(test.expectError) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expectError) {
+				t.Errorf("expected error containing %q, got %v", test.expectError, err)
+			}
+		})
+	}
+}
+
+func TestAttachSession(t *testing.T) {
+	tests := []struct {
+		name            string
+		session         string
+		list            bool
+		expectedCommand []string
+		expectedStdin   bool
+		expectedOutput  string
+	}{
+		{
+			name:            "reattach",
+			session:         "dev",
+			expectedCommand: exec.ReattachSessionCommand("dev"),
+			expectedStdin:   true,
+			expectedOutput:  "If session dev was detached, reattach using 'kubectl attach foo -c bar -i -t --session dev'\n",
+		},
+		{
+			name:            "list",
+			list:            true,
+			expectedCommand: exec.ListSessionsCommand(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := attachPod()
+			ex := &fakeSessionExecutor{}
+			streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+			options := &AttachOptions{
+				StreamOptions: exec.StreamOptions{
+					IOStreams:     streams,
+					ContainerName: "bar",
+					Stdin:         len(test.session) > 0,
+					TTY:           len(test.session) > 0,
+				},
+				CommandName:  "kubectl attach",
+				Pod:          pod,
+				Session:      test.session,
+				ListSessions: test.list,
+				PodClient:    fake.NewSimpleClientset(pod).CoreV1(),
+				Executor:     ex,
+				Config:       &restclient.Config{APIPath: "/api", ContentConfig: restclient.ContentConfig{NegotiatedSerializer: scheme.Codecs, GroupVersion: &schema.GroupVersion{Version: "v1"}}},
+			}
+			if err := options.Run(); err != nil {
+				t.Fatal(err)
+			}
+
+			if ex.url == nil || ex.url.Path != "/api/v1/namespaces/test/pods/foo/exec" {
+				t.Fatalf("expected an exec request, got %v", ex.url)
+			}
+			if got := ex.url.Query()["command"]; !reflect.DeepEqual(test.expectedCommand, got) {
+				t.Errorf("expected command %v, got %v", test.expectedCommand, got)
+			}
+			if ex.stdin != test.expectedStdin {
+				t.Errorf("expected stdin %t, got %t", test.expectedStdin, ex.stdin)
+			}
+			// the test streams are not a terminal, which is reported before the session output
+			if !strings.HasSuffix(errOut.String(), test.expectedOutput) || (len(test.expectedOutput) == 0 && strings.Contains(errOut.String(), "reattach")) {
+				t.Errorf("expected %q, got %q", test.expectedOutput, errOut.String())
+			}
+		})
+	}
+}
+
+func TestAttachSessionWithoutMultiplexer(t *testing.T) {
+	pod := attachPod()
+	options := &AttachOptions{
+		StreamOptions: exec.StreamOptions{
+			IOStreams:     genericiooptions.NewTestIOStreamsDiscard(),
+			ContainerName: "bar",
+			Stdin:         true,
+			TTY:           true,
+		},
+		CommandName: "kubectl attach",
+		Pod:         pod,
+		Session:     "dev",
+		PodClient:   fake.NewSimpleClientset(pod).CoreV1(),
+		Executor:    &fakeSessionExecutor{err: utilexec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 127"), Code: 127}},
+		Config:      &restclient.Config{APIPath: "/api", ContentConfig: restclient.ContentConfig{NegotiatedSerializer: scheme.Codecs, GroupVersion: &schema.GroupVersion{Version: "v1"}}},
+	}
+	err := options.Run()
+	if want := "neither tmux nor screen is installed"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("expected error containing %q, got %v", want, err)
+	}
+}
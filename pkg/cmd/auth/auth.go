@@ -36,6 +36,7 @@ func NewCmdAuth(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 	cmds.AddCommand(NewCmdCanI(f, streams))
 	cmds.AddCommand(NewCmdReconcile(f, streams))
 	cmds.AddCommand(NewCmdWhoAmI(f, streams))
+	cmds.AddCommand(NewCmdWhoCan(f, streams))
 
 	return cmds
 }
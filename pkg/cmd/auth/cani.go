@@ -18,6 +18,7 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -38,6 +39,7 @@ import (
 	"k8s.io/cli-runtime/pkg/printers"
 	discovery "k8s.io/client-go/discovery"
 	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/rest"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/describe"
 	rbacutil "k8s.io/kubectl/pkg/util/rbac"
@@ -61,6 +63,15 @@ type CanIOptions struct {
 	Subresource    string
 	ResourceName   string
 	List           bool
+	// Output prints the allowed actions of --list as a resource by verb
+	// matrix, in a table or as JSON.
+	Output string
+	// AsFile names a file of users to impersonate, one per line, to list
+	// the allowed actions of each of them.
+	AsFile   string
+	Subjects []string
+	// AuthClientForSubject returns a client impersonating the subject.
+	AuthClientForSubject func(subject string) (authorizationv1client.AuthorizationV1Interface, error)
 
 	genericiooptions.IOStreams
 	WarningPrinter *printers.WarningPrinter
@@ -101,7 +112,13 @@ var (
 		kubectl auth can-i get /logs/
 
 		# List all allowed actions in namespace "foo"
-		kubectl auth can-i --list --namespace=foo`)
+		kubectl auth can-i --list --namespace=foo
+
+		# Show the allowed verbs of every resource, subresource and non-resource URL in namespace "foo"
+		kubectl auth can-i --list --namespace=foo -o matrix
+
+		# Audit the service accounts listed in accounts.txt, one user name per line, as JSON
+		kubectl auth can-i --list --as-file=accounts.txt -o json`)
 
 	resourceVerbs       = sets.NewString("get", "list", "watch", "create", "update", "patch", "delete", "deletecollection", "use", "bind", "impersonate", "*")
 	nonResourceURLVerbs = sets.NewString("get", "put", "post", "head", "options", "delete", "patch", "*")
@@ -145,6 +162,8 @@ func NewCmdCanI(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 	cmd.Flags().StringVar(&o.Subresource, "subresource", o.Subresource, "SubResource such as pod/log or deployment/scale")
 	cmd.Flags().BoolVar(&o.List, "list", o.List, "If true, prints all allowed actions.")
 	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "If true, prints allowed actions without headers")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format of --list. One of: (matrix, json). matrix prints the allowed verbs of each resource, subresource and non-resource URL; json prints the same as JSON.")
+	cmd.Flags().StringVar(&o.AsFile, "as-file", o.AsFile, "A file of users to impersonate, one per line, to list the allowed actions of each of them. Only valid with --list.")
 	return cmd
 }
 
@@ -159,6 +178,16 @@ func (o *CanIOptions) Complete(f cmdutil.Factory, args []string) error {
 		if len(args) != 0 {
 			return errors.New("list option must be specified with no arguments")
 		}
+		if len(o.AsFile) > 0 {
+			var err error
+			o.Subjects, err = readSubjects(o.AsFile)
+			if err != nil {
+				return err
+			}
+			if err := o.completeImpersonation(f); err != nil {
+				return err
+			}
+		}
 	} else {
 		if o.Quiet {
 			o.Out = io.Discard
@@ -211,8 +240,14 @@ func (o *CanIOptions) Validate() error {
 		if o.Quiet || o.AllNamespaces || o.Subresource != "" {
 			return errors.New("list option can't be specified with neither quiet, all-namespaces nor subresource options")
 		}
+		if o.Output != "" && o.Output != "matrix" && o.Output != "json" {
+			return fmt.Errorf("--output must be one of (matrix, json), not %q", o.Output)
+		}
 		return nil
 	}
+	if o.Output != "" || o.AsFile != "" {
+		return errors.New("--output and --as-file can only be used with --list")
+	}
 
 	if o.WarningPrinter == nil {
 		return fmt.Errorf("WarningPrinter can not be used without initialization")
@@ -247,19 +282,107 @@ func (o *CanIOptions) Validate() error {
 	return nil
 }
 
-// RunAccessList lists all the access current user has
+// RunAccessList lists all the access current user has, or the subjects read
+// from --as-file have
 func (o *CanIOptions) RunAccessList() error {
+	if len(o.Subjects) == 0 {
+		status, err := o.rulesReview(o.AuthClient)
+		if err != nil {
+			return err
+		}
+		if len(o.Output) == 0 {
+			return o.printStatus(*status)
+		}
+		return o.printMatrices([]string{""}, []authorizationv1.SubjectRulesReviewStatus{*status})
+	}
+
+	statuses := []authorizationv1.SubjectRulesReviewStatus{}
+	for _, subject := range o.Subjects {
+		client, err := o.AuthClientForSubject(subject)
+		if err != nil {
+			return err
+		}
+		status, err := o.rulesReview(client)
+		if err != nil {
+			return fmt.Errorf("failed to list the actions allowed to %s: %v", subject, err)
+		}
+		statuses = append(statuses, *status)
+	}
+	if len(o.Output) > 0 {
+		return o.printMatrices(o.Subjects, statuses)
+	}
+	for i, subject := range o.Subjects {
+		if i > 0 {
+			fmt.Fprintln(o.Out)
+		}
+		fmt.Fprintf(o.Out, "Subject: %s\n", subject)
+		if err := o.printStatus(statuses[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *CanIOptions) rulesReview(client authorizationv1client.AuthorizationV1Interface) (*authorizationv1.SubjectRulesReviewStatus, error) {
 	sar := &authorizationv1.SelfSubjectRulesReview{
 		Spec: authorizationv1.SelfSubjectRulesReviewSpec{
 			Namespace: o.Namespace,
 		},
 	}
-	response, err := o.AuthClient.SelfSubjectRulesReviews().Create(context.TODO(), sar, metav1.CreateOptions{})
+	response, err := client.SelfSubjectRulesReviews().Create(context.TODO(), sar, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &response.Status, nil
+}
+
+func (o *CanIOptions) printMatrices(subjects []string, statuses []authorizationv1.SubjectRulesReviewStatus) error {
+	resourceLists, err := serverResources(o.DiscoveryClient)
 	if err != nil {
 		return err
 	}
+	matrices := []accessMatrix{}
+	for i, status := range statuses {
+		if status.Incomplete {
+			o.WarningPrinter.Print(fmt.Sprintf("the list may be incomplete: %v", status.EvaluationError))
+		}
+		matrix := buildAccessMatrix(status, resourceLists)
+		matrix.Subject = subjects[i]
+		matrix.Namespace = o.Namespace
+		matrices = append(matrices, matrix)
+	}
 
-	return o.printStatus(response.Status)
+	if o.Output == "json" {
+		var v interface{} = matrices
+		if len(matrices) == 1 && len(matrices[0].Subject) == 0 {
+			v = matrices[0]
+		}
+		data, err := json.MarshalIndent(v, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.Out, string(data))
+		return err
+	}
+	return printAccessMatrix(o.Out, matrices, o.NoHeaders)
+}
+
+// completeImpersonation sets up the clients impersonating the subjects of
+// --as-file.
+func (o *CanIOptions) completeImpersonation(f cmdutil.Factory) error {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if len(config.Impersonate.UserName) > 0 {
+		return errors.New("--as-file can not be used with --as")
+	}
+	o.AuthClientForSubject = func(subject string) (authorizationv1client.AuthorizationV1Interface, error) {
+		impersonated := rest.CopyConfig(config)
+		impersonated.Impersonate = rest.ImpersonationConfig{UserName: subject}
+		return authorizationv1client.NewForConfig(impersonated)
+	}
+	return nil
 }
 
 // RunAccessCheck checks if user has access to a certain resource or non resource URL
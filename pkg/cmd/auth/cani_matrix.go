@@ -0,0 +1,274 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	accessAllowed = "yes"
+	// accessLimited is shown when the verb is only allowed on the resources
+	// with some names.
+	accessLimited = "limited"
+	accessDenied  = "no"
+)
+
+// matrixVerbs are the columns of the access matrix. The HTTP verbs of
+// non-resource URLs are shown in the columns of the verbs they are
+// authorized as.
+var matrixVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete", "deletecollection"}
+
+var nonResourceVerbColumns = map[string]string{
+	"get":    "get",
+	"head":   "get",
+	"post":   "create",
+	"put":    "update",
+	"patch":  "patch",
+	"delete": "delete",
+}
+
+// accessMatrix holds the verbs a subject is allowed to perform on each
+// resource, subresource and non-resource URL.
+type accessMatrix struct {
+	Subject   string            `json:"subject,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Rows      []accessMatrixRow `json:"rows"`
+}
+
+type accessMatrixRow struct {
+	Resource       string `json:"resource,omitempty"`
+	NonResourceURL string `json:"nonResourceURL,omitempty"`
+	// Verbs maps the verbs to yes, limited or no. Verbs the resource does
+	// not support are omitted.
+	Verbs map[string]string `json:"verbs"`
+}
+
+// readSubjects reads the users to impersonate from a file, one per line.
+// Empty lines and lines starting with # are ignored.
+func readSubjects(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	subjects := []string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		subjects = append(subjects, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(subjects) == 0 {
+		return nil, fmt.Errorf("no subjects found in %s", path)
+	}
+	return subjects, nil
+}
+
+// buildAccessMatrix evaluates the rules of the review against every resource
+// and subresource served, and the non-resource URLs found in the rules. Only
+// the rows with at least one allowed verb are kept.
+func buildAccessMatrix(status authorizationv1.SubjectRulesReviewStatus, resourceLists []*metav1.APIResourceList) accessMatrix {
+	matrix := accessMatrix{Rows: []accessMatrixRow{}}
+
+	seen := sets.New[string]()
+	for _, list := range resourceLists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, apiResource := range list.APIResources {
+			resource, subresource, _ := strings.Cut(apiResource.Name, "/")
+			name := schema.GroupResource{Group: gv.Group, Resource: resource}.String()
+			if len(subresource) > 0 {
+				name += "/" + subresource
+			}
+			// the same resource is served by several versions
+			if seen.Has(name) {
+				continue
+			}
+			seen.Insert(name)
+
+			row := accessMatrixRow{Resource: name, Verbs: map[string]string{}}
+			allowedAny := false
+			for _, verb := range matrixVerbs {
+				if !sets.New[string](apiResource.Verbs...).Has(verb) {
+					continue
+				}
+				access := resourceAccess(status.ResourceRules, verb, gv.Group, resource, subresource)
+				row.Verbs[verb] = access
+				allowedAny = allowedAny || access != accessDenied
+			}
+			if allowedAny {
+				matrix.Rows = append(matrix.Rows, row)
+			}
+		}
+	}
+
+	urls := sets.New[string]()
+	for _, rule := range status.NonResourceRules {
+		urls.Insert(rule.NonResourceURLs...)
+	}
+	for _, url := range sets.List(urls) {
+		row := accessMatrixRow{NonResourceURL: url, Verbs: map[string]string{}}
+		for httpVerb, verb := range nonResourceVerbColumns {
+			if nonResourceAllowed(status.NonResourceRules, httpVerb, url) {
+				row.Verbs[verb] = accessAllowed
+			} else if _, ok := row.Verbs[verb]; !ok {
+				row.Verbs[verb] = accessDenied
+			}
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+
+	// resources first, then non-resource URLs
+	sort.SliceStable(matrix.Rows, func(i, j int) bool {
+		a, b := matrix.Rows[i], matrix.Rows[j]
+		if (len(a.Resource) == 0) != (len(b.Resource) == 0) {
+			return len(a.Resource) > 0
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		return a.NonResourceURL < b.NonResourceURL
+	})
+	return matrix
+}
+
+// resourceAccess matches the rules the same way the RBAC authorizer does.
+func resourceAccess(rules []authorizationv1.ResourceRule, verb, group, resource, subresource string) string {
+	combined := resource
+	if len(subresource) > 0 {
+		combined = resource + "/" + subresource
+	}
+	access := accessDenied
+	for _, rule := range rules {
+		if !matchesAny(rule.Verbs, verb) || !matchesAny(rule.APIGroups, group) {
+			continue
+		}
+		matched := false
+		for _, r := range rule.Resources {
+			if r == "*" || r == combined || (len(subresource) > 0 && r == "*/"+subresource) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		if len(rule.ResourceNames) == 0 {
+			return accessAllowed
+		}
+		access = accessLimited
+	}
+	return access
+}
+
+func nonResourceAllowed(rules []authorizationv1.NonResourceRule, verb, url string) bool {
+	for _, rule := range rules {
+		if !matchesAny(rule.Verbs, verb) {
+			continue
+		}
+		for _, ruleURL := range rule.NonResourceURLs {
+			if ruleURL == "*" || ruleURL == url || (strings.HasSuffix(ruleURL, "*") && strings.HasPrefix(url, strings.TrimSuffix(ruleURL, "*"))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesAny(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// serverResources returns the resources and subresources served, tolerating
+// the groups that failed discovery.
+func serverResources(client discovery.DiscoveryInterface) ([]*metav1.APIResourceList, error) {
+	_, resourceLists, err := client.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+	return resourceLists, nil
+}
+
+func printAccessMatrix(out io.Writer, matrices []accessMatrix, noHeaders bool) error {
+	w := printers.GetNewTabWriter(out)
+	defer w.Flush()
+
+	withSubject := len(matrices) > 1 || (len(matrices) == 1 && len(matrices[0].Subject) > 0)
+	if !noHeaders {
+		columns := []string{}
+		if withSubject {
+			columns = append(columns, "SUBJECT")
+		}
+		columns = append(columns, "RESOURCE")
+		for _, verb := range matrixVerbs {
+			columns = append(columns, strings.ToUpper(verb))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(columns, "\t")); err != nil {
+			return err
+		}
+	}
+	for _, matrix := range matrices {
+		for _, row := range matrix.Rows {
+			cells := []string{}
+			if withSubject {
+				cells = append(cells, matrix.Subject)
+			}
+			name := row.Resource
+			if len(row.NonResourceURL) > 0 {
+				name = row.NonResourceURL
+			}
+			cells = append(cells, name)
+			for _, verb := range matrixVerbs {
+				access, ok := row.Verbs[verb]
+				if !ok {
+					access = "-"
+				}
+				cells = append(cells, access)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
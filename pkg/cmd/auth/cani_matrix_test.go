@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes/fake"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var matrixResources = []*metav1.APIResourceList{
+	{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete", "deletecollection"}},
+			{Name: "pods/log", Verbs: []string{"get"}},
+			{Name: "secrets", Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete", "deletecollection"}},
+		},
+	},
+	{
+		GroupVersion: "apps/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "deployments", Verbs: []string{"get", "list", "watch", "create", "update", "patch", "delete", "deletecollection"}},
+			{Name: "deployments/scale", Verbs: []string{"get", "update", "patch"}},
+		},
+	},
+}
+
+func matrixRulesStatus() authorizationv1.SubjectRulesReviewStatus {
+	return authorizationv1.SubjectRulesReviewStatus{
+		ResourceRules: []authorizationv1.ResourceRule{
+			{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}},
+			{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"token"}},
+			{Verbs: []string{"*"}, APIGroups: []string{"apps"}, Resources: []string{"*/scale"}},
+		},
+		NonResourceRules: []authorizationv1.NonResourceRule{
+			{Verbs: []string{"get"}, NonResourceURLs: []string{"/healthz"}},
+		},
+	}
+}
+
+func TestBuildAccessMatrix(t *testing.T) {
+	matrix := buildAccessMatrix(matrixRulesStatus(), matrixResources)
+	expected := []accessMatrixRow{
+		{Resource: "deployments.apps/scale", Verbs: map[string]string{"get": "yes", "update": "yes", "patch": "yes"}},
+		{Resource: "pods", Verbs: map[string]string{"get": "yes", "list": "yes", "watch": "no", "create": "no", "update": "no", "patch": "no", "delete": "no", "deletecollection": "no"}},
+		{Resource: "pods/log", Verbs: map[string]string{"get": "yes"}},
+		{Resource: "secrets", Verbs: map[string]string{"get": "limited", "list": "no", "watch": "no", "create": "no", "update": "no", "patch": "no", "delete": "no", "deletecollection": "no"}},
+		{NonResourceURL: "/healthz", Verbs: map[string]string{"get": "yes", "create": "no", "update": "no", "patch": "no", "delete": "no"}},
+	}
+	if !reflect.DeepEqual(matrix.Rows, expected) {
+		t.Errorf("expected rows:\n%#v\ngot:\n%#v", expected, matrix.Rows)
+	}
+}
+
+func TestRunAccessListMatrix(t *testing.T) {
+	subjectsFile := filepath.Join(t.TempDir(), "subjects")
+	if err := os.WriteFile(subjectsFile, []byte("# auditors\nsystem:serviceaccount:dev:reader\n\njane\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	subjects, err := readSubjects(subjectsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(subjects, []string{"system:serviceaccount:dev:reader", "jane"}) {
+		t.Fatalf("unexpected subjects %v", subjects)
+	}
+
+	client := fake.NewSimpleClientset()
+	client.Resources = matrixResources
+	clientFor := func(subject string) (authorizationv1client.AuthorizationV1Interface, error) {
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("create", "selfsubjectrulesreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			review := &authorizationv1.SelfSubjectRulesReview{}
+			if subject == "jane" {
+				review.Status = matrixRulesStatus()
+			}
+			return true, review, nil
+		})
+		return client.AuthorizationV1(), nil
+	}
+
+	ioStreams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	o := &CanIOptions{
+		List:                 true,
+		Output:               "matrix",
+		Namespace:            "test",
+		Subjects:             subjects,
+		AuthClientForSubject: clientFor,
+		DiscoveryClient:      client.Discovery(),
+		IOStreams:            ioStreams,
+		WarningPrinter:       printers.NewWarningPrinter(ioStreams.ErrOut, printers.WarningPrinterOptions{}),
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunAccessList(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `SUBJECT   RESOURCE                 GET       LIST   WATCH   CREATE   UPDATE   PATCH   DELETE   DELETECOLLECTION
+jane      deployments.apps/scale   yes       -      -       -        yes      yes     -        -
+jane      pods                     yes       yes    no      no       no       no      no       no
+jane      pods/log                 yes       -      -       -        -        -       -        -
+jane      secrets                  limited   no     no      no       no       no      no       no
+jane      /healthz                 yes       -      -       no       no       no      no       -
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	o.Output = "json"
+	if err := o.RunAccessList(); err != nil {
+		t.Fatal(err)
+	}
+	matrices := []accessMatrix{}
+	if err := json.Unmarshal(buf.Bytes(), &matrices); err != nil {
+		t.Fatal(err)
+	}
+	if len(matrices) != 2 || matrices[0].Subject != "system:serviceaccount:dev:reader" || len(matrices[0].Rows) != 0 || len(matrices[1].Rows) != 5 || matrices[1].Namespace != "test" {
+		t.Errorf("unexpected matrices: %#v", matrices)
+	}
+}
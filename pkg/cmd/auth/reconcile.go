@@ -47,6 +47,14 @@ type ReconcileOptions struct {
 	DryRun                 bool
 	RemoveExtraPermissions bool
 	RemoveExtraSubjects    bool
+	// Prune removes both the permissions and the subjects absent from the
+	// input objects.
+	Prune bool
+	// Report prints the rules and subjects added, removed and unchanged
+	// in each object instead of the objects.
+	Report       bool
+	reportFormat string
+	reports      []reconcileReport
 
 	Visitor         resource.Visitor
 	RBACClient      rbacv1client.RbacV1Interface
@@ -69,11 +77,23 @@ var (
 		Existing bindings are updated to include the subjects in the input objects,
 		and remove extra subjects if --remove-extra-subjects is specified.
 
+		Both are removed if --prune is specified.
+
+		With --report, the rules and subjects added, removed, unchanged and left in place
+		are printed for each object as JSON or YAML, which can be combined with --dry-run
+		to detect drift from the input objects.
+
 		This is preferred to 'apply' for RBAC resources so that semantically-aware merging of rules and subjects is done.`)
 
 	reconcileExample = templates.Examples(`
 		# Reconcile RBAC resources from a file
-		kubectl auth reconcile -f my-rbac-rules.yaml`)
+		kubectl auth reconcile -f my-rbac-rules.yaml
+
+		# Reconcile RBAC resources from a file, removing the rules and subjects absent from it
+		kubectl auth reconcile -f my-rbac-rules.yaml --prune
+
+		# Report the drift of the RBAC resources in the cluster from a file, without changing them
+		kubectl auth reconcile -f my-rbac-rules.yaml --prune --dry-run=client --report -o json`)
 )
 
 // NewReconcileOptions returns a new ReconcileOptions instance
@@ -107,6 +127,8 @@ func NewCmdReconcile(f cmdutil.Factory, streams genericiooptions.IOStreams) *cob
 	cmdutil.AddFilenameOptionFlags(cmd, o.FilenameOptions, "identifying the resource to reconcile.")
 	cmd.Flags().BoolVar(&o.RemoveExtraPermissions, "remove-extra-permissions", o.RemoveExtraPermissions, "If true, removes extra permissions added to roles")
 	cmd.Flags().BoolVar(&o.RemoveExtraSubjects, "remove-extra-subjects", o.RemoveExtraSubjects, "If true, removes extra subjects added to rolebindings")
+	cmd.Flags().BoolVar(&o.Prune, "prune", o.Prune, "If true, removes the permissions and subjects absent from the input objects, as --remove-extra-permissions and --remove-extra-subjects do.")
+	cmd.Flags().BoolVar(&o.Report, "report", o.Report, "If true, print the rules and subjects added, removed and unchanged in each object instead of the objects. Requires -o json or -o yaml.")
 	cmdutil.AddDryRunFlag(cmd)
 
 	return cmd
@@ -160,6 +182,14 @@ func (o *ReconcileOptions) Complete(cmd *cobra.Command, f cmdutil.Factory, args
 		return err
 	}
 
+	if o.Prune {
+		o.RemoveExtraPermissions = true
+		o.RemoveExtraSubjects = true
+	}
+	if o.Report {
+		o.reportFormat = *o.PrintFlags.OutputFormat
+	}
+
 	if o.DryRun {
 		o.PrintFlags.Complete("%s (dry run)")
 	}
@@ -192,12 +222,16 @@ func (o *ReconcileOptions) Validate() error {
 	if o.ErrOut == nil {
 		return errors.New("ReconcileOptions.Err must be set")
 	}
+	if o.Report && o.reportFormat != "json" && o.reportFormat != "yaml" {
+		return errors.New("--report requires -o json or -o yaml")
+	}
 	return nil
 }
 
 // RunReconcile performs the execution
 func (o *ReconcileOptions) RunReconcile() error {
-	return o.Visitor.Visit(func(info *resource.Info, err error) error {
+	o.reports = []reconcileReport{}
+	err := o.Visitor.Visit(func(info *resource.Info, err error) error {
 		if err != nil {
 			return err
 		}
@@ -217,7 +251,7 @@ func (o *ReconcileOptions) RunReconcile() error {
 			if err != nil {
 				return err
 			}
-			o.printResults(result.Role.GetObject(), nil, nil, result.MissingRules, result.ExtraRules, result.Operation, result.Protected)
+			o.printResults(result.Role.GetObject(), nil, nil, nil, t.Rules, result.MissingRules, result.ExtraRules, result.Operation, result.Protected)
 
 		case *rbacv1.ClusterRole:
 			reconcileOptions := reconciliation.ReconcileRoleOptions{
@@ -232,7 +266,7 @@ func (o *ReconcileOptions) RunReconcile() error {
 			if err != nil {
 				return err
 			}
-			o.printResults(result.Role.GetObject(), nil, nil, result.MissingRules, result.ExtraRules, result.Operation, result.Protected)
+			o.printResults(result.Role.GetObject(), nil, nil, nil, t.Rules, result.MissingRules, result.ExtraRules, result.Operation, result.Protected)
 
 		case *rbacv1.RoleBinding:
 			reconcileOptions := reconciliation.ReconcileRoleBindingOptions{
@@ -248,7 +282,7 @@ func (o *ReconcileOptions) RunReconcile() error {
 			if err != nil {
 				return err
 			}
-			o.printResults(result.RoleBinding.GetObject(), result.MissingSubjects, result.ExtraSubjects, nil, nil, result.Operation, result.Protected)
+			o.printResults(result.RoleBinding.GetObject(), t.Subjects, result.MissingSubjects, result.ExtraSubjects, nil, nil, nil, result.Operation, result.Protected)
 
 		case *rbacv1.ClusterRoleBinding:
 			reconcileOptions := reconciliation.ReconcileRoleBindingOptions{
@@ -263,7 +297,7 @@ func (o *ReconcileOptions) RunReconcile() error {
 			if err != nil {
 				return err
 			}
-			o.printResults(result.RoleBinding.GetObject(), result.MissingSubjects, result.ExtraSubjects, nil, nil, result.Operation, result.Protected)
+			o.printResults(result.RoleBinding.GetObject(), t.Subjects, result.MissingSubjects, result.ExtraSubjects, nil, nil, nil, result.Operation, result.Protected)
 
 		case *rbacv1beta1.Role,
 			*rbacv1beta1.RoleBinding,
@@ -282,14 +316,30 @@ func (o *ReconcileOptions) RunReconcile() error {
 
 		return nil
 	})
+	if err != nil || !o.Report {
+		return err
+	}
+	return printReconcileReports(o.Out, o.reports, o.reportFormat)
 }
 
 func (o *ReconcileOptions) printResults(object runtime.Object,
-	missingSubjects, extraSubjects []rbacv1.Subject,
-	missingRules, extraRules []rbacv1.PolicyRule,
+	expectedSubjects, missingSubjects, extraSubjects []rbacv1.Subject,
+	expectedRules, missingRules, extraRules []rbacv1.PolicyRule,
 	operation reconciliation.ReconcileOperation,
 	protected bool) {
 
+	if o.Report {
+		report := newReconcileReport(object, operation, protected)
+		switch object.(type) {
+		case *rbacv1.Role, *rbacv1.ClusterRole:
+			report.Rules = diffRules(expectedRules, missingRules, extraRules, o.RemoveExtraPermissions)
+		default:
+			report.Subjects = diffSubjects(expectedSubjects, missingSubjects, extraSubjects, o.RemoveExtraSubjects)
+		}
+		o.reports = append(o.reports, report)
+		return
+	}
+
 	o.PrintObject(object, o.Out)
 
 	caveat := ""
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/component-helpers/auth/rbac/reconciliation"
+	"k8s.io/component-helpers/auth/rbac/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// reconcileReport details the changes reconciliation made, or would make
+// with --dry-run, to one object.
+type reconcileReport struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Operation is one of create, update, recreate or none.
+	Operation string `json:"operation"`
+	// Protected is set when the object opted out of reconciliation with the
+	// rbac.authorization.kubernetes.io/autoupdate: false annotation.
+	Protected bool            `json:"protected,omitempty"`
+	Rules     *ruleChanges    `json:"rules,omitempty"`
+	Subjects  *subjectChanges `json:"subjects,omitempty"`
+}
+
+// ruleChanges lists the rules of the input object that were added or already
+// present, and the rules only present in the cluster, removed with --prune or
+// kept otherwise.
+type ruleChanges struct {
+	Added     []rbacv1.PolicyRule `json:"added"`
+	Removed   []rbacv1.PolicyRule `json:"removed"`
+	Unchanged []rbacv1.PolicyRule `json:"unchanged"`
+	Kept      []rbacv1.PolicyRule `json:"kept"`
+}
+
+// subjectChanges is the equivalent of ruleChanges for the subjects of bindings.
+type subjectChanges struct {
+	Added     []rbacv1.Subject `json:"added"`
+	Removed   []rbacv1.Subject `json:"removed"`
+	Unchanged []rbacv1.Subject `json:"unchanged"`
+	Kept      []rbacv1.Subject `json:"kept"`
+}
+
+func newReconcileReport(object runtime.Object, operation reconciliation.ReconcileOperation, protected bool) reconcileReport {
+	report := reconcileReport{
+		Operation: string(operation),
+		Protected: protected,
+	}
+	switch object.(type) {
+	case *rbacv1.Role:
+		report.Kind = "Role"
+	case *rbacv1.ClusterRole:
+		report.Kind = "ClusterRole"
+	case *rbacv1.RoleBinding:
+		report.Kind = "RoleBinding"
+	case *rbacv1.ClusterRoleBinding:
+		report.Kind = "ClusterRoleBinding"
+	}
+	if accessor, err := meta.Accessor(object); err == nil {
+		report.Namespace = accessor.GetNamespace()
+		report.Name = accessor.GetName()
+	}
+	return report
+}
+
+// diffRules sorts the expected rules into added and unchanged ones. The
+// missing rules are broken down into one verb and resource per rule, an
+// expected rule is unchanged when none of its parts were missing.
+func diffRules(expected, missing, extra []rbacv1.PolicyRule, prune bool) *ruleChanges {
+	changes := &ruleChanges{
+		Added:     missing,
+		Removed:   []rbacv1.PolicyRule{},
+		Unchanged: []rbacv1.PolicyRule{},
+		Kept:      []rbacv1.PolicyRule{},
+	}
+	if changes.Added == nil {
+		changes.Added = []rbacv1.PolicyRule{}
+	}
+	for _, rule := range expected {
+		added := false
+		for _, part := range validation.BreakdownRule(rule) {
+			for _, m := range missing {
+				if equality.Semantic.DeepEqual(part, m) {
+					added = true
+				}
+			}
+		}
+		if !added {
+			changes.Unchanged = append(changes.Unchanged, rule)
+		}
+	}
+	if prune {
+		changes.Removed = append(changes.Removed, extra...)
+	} else {
+		changes.Kept = append(changes.Kept, extra...)
+	}
+	return changes
+}
+
+func diffSubjects(expected, missing, extra []rbacv1.Subject, prune bool) *subjectChanges {
+	changes := &subjectChanges{
+		Added:     append([]rbacv1.Subject{}, missing...),
+		Removed:   []rbacv1.Subject{},
+		Unchanged: []rbacv1.Subject{},
+		Kept:      []rbacv1.Subject{},
+	}
+	for _, subject := range expected {
+		added := false
+		for _, m := range missing {
+			if equality.Semantic.DeepEqual(subject, m) {
+				added = true
+				break
+			}
+		}
+		if !added {
+			changes.Unchanged = append(changes.Unchanged, subject)
+		}
+	}
+	if prune {
+		changes.Removed = append(changes.Removed, extra...)
+	} else {
+		changes.Kept = append(changes.Kept, extra...)
+	}
+	return changes
+}
+
+func printReconcileReports(out io.Writer, reports []reconcileReport, format string) error {
+	var data []byte
+	var err error
+	switch format {
+	case "json":
+		data, err = json.MarshalIndent(reports, "", "    ")
+		data = append(data, '\n')
+	case "yaml":
+		data, err = yaml.Marshal(reports)
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReconcileReport(t *testing.T) {
+	existingRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "reader"},
+		Rules: []rbacv1.PolicyRule{
+			{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+			{Verbs: []string{"delete"}, APIGroups: []string{""}, Resources: []string{"secrets"}},
+		},
+	}
+	existingBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "readers", Namespace: "test"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "reader"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: "rbac.authorization.k8s.io", Name: "jane"}, {Kind: rbacv1.UserKind, APIGroup: "rbac.authorization.k8s.io", Name: "mallory"}},
+	}
+	client := fake.NewSimpleClientset(existingRole, existingBinding, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}})
+
+	role := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "reader"},
+		Rules: []rbacv1.PolicyRule{
+			{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+			{Verbs: []string{"list"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+		},
+	}
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "readers", Namespace: "test"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "reader"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: "rbac.authorization.k8s.io", Name: "jane"}, {Kind: rbacv1.UserKind, APIGroup: "rbac.authorization.k8s.io", Name: "bob"}},
+	}
+
+	ioStreams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	o := NewReconcileOptions(ioStreams)
+	o.DryRun = true
+	o.Prune = true
+	o.RemoveExtraPermissions = true
+	o.RemoveExtraSubjects = true
+	o.Report = true
+	o.reportFormat = "json"
+	o.RBACClient = client.RbacV1()
+	o.NamespaceClient = client.CoreV1()
+	o.PrintObject = func(runtime.Object, io.Writer) error { return nil }
+	o.Visitor = resource.InfoListVisitor{
+		{Object: role, Name: "reader"},
+		{Object: binding, Namespace: "test", Name: "readers"},
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunReconcile(); err != nil {
+		t.Fatal(err)
+	}
+
+	reports := []reconcileReport{}
+	if err := json.Unmarshal(buf.Bytes(), &reports); err != nil {
+		t.Fatalf("unexpected output %s: %v", buf.String(), err)
+	}
+	expected := []reconcileReport{
+		{
+			Kind:      "ClusterRole",
+			Name:      "reader",
+			Operation: "update",
+			Rules: &ruleChanges{
+				Added:     []rbacv1.PolicyRule{{Verbs: []string{"list"}, APIGroups: []string{""}, Resources: []string{"pods"}}},
+				Removed:   []rbacv1.PolicyRule{{Verbs: []string{"delete"}, APIGroups: []string{""}, Resources: []string{"secrets"}}},
+				Unchanged: []rbacv1.PolicyRule{{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"pods"}}},
+				Kept:      []rbacv1.PolicyRule{},
+			},
+		},
+		{
+			Kind:      "RoleBinding",
+			Namespace: "test",
+			Name:      "readers",
+			Operation: "update",
+			Subjects: &subjectChanges{
+				Added:     []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: "rbac.authorization.k8s.io", Name: "bob"}},
+				Removed:   []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: "rbac.authorization.k8s.io", Name: "mallory"}},
+				Unchanged: []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: "rbac.authorization.k8s.io", Name: "jane"}},
+				Kept:      []rbacv1.Subject{},
+			},
+		},
+	}
+	if !equality.Semantic.DeepEqual(reports, expected) {
+		t.Errorf("expected reports:\n%#v\ngot:\n%s", expected, buf.String())
+	}
+
+	// the cluster is not changed on dry run
+	current, err := client.RbacV1().ClusterRoles().Get(context.TODO(), "reader", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equality.Semantic.DeepEqual(current.Rules, existingRole.Rules) {
+		t.Errorf("unexpected change of the cluster role: %v", current.Rules)
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	"k8s.io/component-helpers/auth/rbac/validation"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// WhoCanOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
+// referencing the cmd.Flags()
+type WhoCanOptions struct {
+	AllNamespaces bool
+	NoHeaders     bool
+	Namespace     string
+	RbacClient    rbacv1client.RbacV1Interface
+
+	Verb           string
+	Resource       schema.GroupResource
+	NonResourceURL string
+	Subresource    string
+	ResourceName   string
+
+	genericiooptions.IOStreams
+}
+
+var (
+	whoCanLong = templates.LongDesc(`
+		List the subjects allowed to perform an action.
+
+		The role bindings of the namespace and the cluster role bindings are resolved to
+		the rules of their roles, including the rules of aggregated cluster roles, to find
+		the users, groups and service accounts they grant the action to.
+
+		VERB is a logical Kubernetes API verb like 'get', 'list', 'watch', 'delete', etc.
+		TYPE is a Kubernetes resource. Shortcuts and groups will be resolved.
+		NONRESOURCEURL is a partial URL that starts with "/".
+		NAME is the name of a particular Kubernetes resource.
+
+		Only RBAC is consulted: subjects allowed by other authorizers, such as the node
+		authorizer or webhooks, are not listed.`)
+
+	whoCanExample = templates.Examples(`
+		# List who can create pods in the current namespace
+		kubectl auth who-can create pods
+
+		# List who can get the secret named "token" in namespace "foo"
+		kubectl auth who-can get secrets/token -n foo
+
+		# List who can delete deployments in any namespace
+		kubectl auth who-can delete deployments.apps --all-namespaces
+
+		# List who can read pod logs
+		kubectl auth who-can get pods --subresource=log
+
+		# List who can access the URL /metrics
+		kubectl auth who-can get /metrics`)
+)
+
+// NewCmdWhoCan returns an initialized Command for 'auth who-can' sub command
+func NewCmdWhoCan(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &WhoCanOptions{
+		IOStreams: streams,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "who-can VERB (TYPE | TYPE/NAME | NONRESOURCEURL)",
+		DisableFlagsInUseLine: true,
+		Short:                 "List the subjects allowed to perform an action",
+		Long:                  whoCanLong,
+		Example:               whoCanExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If true, resolve the role bindings of all namespaces.")
+	cmd.Flags().StringVar(&o.Subresource, "subresource", o.Subresource, "SubResource such as pod/log or deployment/scale")
+	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "If true, prints the subjects without headers")
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *WhoCanOptions) Complete(f cmdutil.Factory, args []string) error {
+	if len(args) != 2 {
+		return errors.New("you must specify two arguments: verb resource or verb resource/resourceName.\nSee 'kubectl auth who-can -h' for help and examples.")
+	}
+	o.Verb = args[0]
+	if strings.HasPrefix(args[1], "/") {
+		o.NonResourceURL = args[1]
+	} else {
+		resourceTokens := strings.SplitN(args[1], "/", 2)
+		restMapper, err := f.ToRESTMapper()
+		if err != nil {
+			return err
+		}
+		o.Resource = schema.ParseGroupResource(strings.ToLower(resourceTokens[0]))
+		if o.Resource.Resource != "*" {
+			if gvr, err := restMapper.ResourceFor(o.Resource.WithVersion("")); err == nil {
+				o.Resource = gvr.GroupResource()
+			}
+		}
+		if len(resourceTokens) > 1 {
+			o.ResourceName = resourceTokens[1]
+		}
+	}
+
+	client, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.RbacClient = client.RbacV1()
+	if !o.AllNamespaces {
+		o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate makes sure provided values for WhoCanOptions are valid
+func (o *WhoCanOptions) Validate() error {
+	if len(o.NonResourceURL) > 0 {
+		if len(o.Subresource) > 0 {
+			return fmt.Errorf("--subresource can not be used with NonResourceURL")
+		}
+		if o.AllNamespaces {
+			return fmt.Errorf("--all-namespaces can not be used with NonResourceURL")
+		}
+	}
+	return nil
+}
+
+type whoCanSubject struct {
+	subject rbacv1.Subject
+	binding string
+	role    string
+}
+
+// Run lists the subjects bound to roles allowing the action
+func (o *WhoCanOptions) Run() error {
+	requested := o.requestedRule()
+
+	clusterRoles, err := o.RbacClient.ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	clusterRoleRules := resolveClusterRoleRules(clusterRoles.Items)
+
+	found := []whoCanSubject{}
+	clusterRoleBindings, err := o.RbacClient.ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, binding := range clusterRoleBindings.Items {
+		if binding.RoleRef.Kind != "ClusterRole" {
+			continue
+		}
+		if covers(clusterRoleRules[binding.RoleRef.Name], requested) {
+			for _, subject := range binding.Subjects {
+				found = append(found, whoCanSubject{subject: subject, binding: "ClusterRoleBinding/" + binding.Name, role: "ClusterRole/" + binding.RoleRef.Name})
+			}
+		}
+	}
+
+	// non-resource URLs can only be granted cluster-wide
+	if len(o.NonResourceURL) == 0 {
+		roleBindings, err := o.RbacClient.RoleBindings(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		roleRules := map[string][]rbacv1.PolicyRule{}
+		for _, binding := range roleBindings.Items {
+			var rules []rbacv1.PolicyRule
+			switch binding.RoleRef.Kind {
+			case "ClusterRole":
+				rules = clusterRoleRules[binding.RoleRef.Name]
+			case "Role":
+				key := binding.Namespace + "/" + binding.RoleRef.Name
+				var ok bool
+				if rules, ok = roleRules[key]; !ok {
+					role, err := o.RbacClient.Roles(binding.Namespace).Get(context.TODO(), binding.RoleRef.Name, metav1.GetOptions{})
+					if err != nil && !apierrors.IsNotFound(err) {
+						return err
+					}
+					if role != nil {
+						rules = role.Rules
+					}
+					roleRules[key] = rules
+				}
+			}
+			if !covers(rules, requested) {
+				continue
+			}
+			for _, subject := range binding.Subjects {
+				if subject.Kind == rbacv1.ServiceAccountKind && len(subject.Namespace) == 0 {
+					subject.Namespace = binding.Namespace
+				}
+				found = append(found, whoCanSubject{subject: subject, binding: "RoleBinding/" + binding.Namespace + "/" + binding.Name, role: binding.RoleRef.Kind + "/" + binding.RoleRef.Name})
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		fmt.Fprintln(o.ErrOut, "No subjects found")
+		return nil
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].subject.Kind != found[j].subject.Kind {
+			return found[i].subject.Kind < found[j].subject.Kind
+		}
+		return subjectName(found[i].subject) < subjectName(found[j].subject)
+	})
+
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+	if !o.NoHeaders {
+		fmt.Fprintln(w, "KIND\tSUBJECT\tBINDING\tROLE")
+	}
+	for _, s := range found {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.subject.Kind, subjectName(s.subject), s.binding, s.role)
+	}
+	return nil
+}
+
+func (o *WhoCanOptions) requestedRule() rbacv1.PolicyRule {
+	if len(o.NonResourceURL) > 0 {
+		return rbacv1.PolicyRule{Verbs: []string{o.Verb}, NonResourceURLs: []string{o.NonResourceURL}}
+	}
+	resource := o.Resource.Resource
+	if len(o.Subresource) > 0 {
+		resource += "/" + o.Subresource
+	}
+	rule := rbacv1.PolicyRule{Verbs: []string{o.Verb}, APIGroups: []string{o.Resource.Group}, Resources: []string{resource}}
+	if len(o.ResourceName) > 0 {
+		rule.ResourceNames = []string{o.ResourceName}
+	}
+	return rule
+}
+
+// resolveClusterRoleRules returns the rules of each cluster role, including
+// the rules of the cluster roles selected by aggregation rules. The rules
+// are normally copied by the aggregation controller, this covers the roles
+// it did not process yet.
+func resolveClusterRoleRules(clusterRoles []rbacv1.ClusterRole) map[string][]rbacv1.PolicyRule {
+	rules := map[string][]rbacv1.PolicyRule{}
+	for _, role := range clusterRoles {
+		rules[role.Name] = append([]rbacv1.PolicyRule{}, role.Rules...)
+		if role.AggregationRule == nil {
+			continue
+		}
+		for _, selector := range role.AggregationRule.ClusterRoleSelectors {
+			s, err := metav1.LabelSelectorAsSelector(&selector)
+			if err != nil {
+				continue
+			}
+			for _, aggregated := range clusterRoles {
+				if aggregated.Name != role.Name && s.Matches(labels.Set(aggregated.Labels)) {
+					rules[role.Name] = append(rules[role.Name], aggregated.Rules...)
+				}
+			}
+		}
+	}
+	return rules
+}
+
+func covers(rules []rbacv1.PolicyRule, requested rbacv1.PolicyRule) bool {
+	if len(rules) == 0 {
+		return false
+	}
+	covered, _ := validation.Covers(rules, []rbacv1.PolicyRule{requested})
+	return covered
+}
+
+func subjectName(subject rbacv1.Subject) string {
+	if subject.Kind == rbacv1.ServiceAccountKind {
+		return subject.Namespace + "/" + subject.Name
+	}
+	return subject.Name
+}
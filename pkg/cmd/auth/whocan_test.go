@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunWhoCan(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		// aggregated into "view" through its labels, without rules copied yet
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "view"},
+			AggregationRule: &rbacv1.AggregationRule{ClusterRoleSelectors: []metav1.LabelSelector{
+				{MatchLabels: map[string]string{"aggregate-to-view": "true"}},
+			}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-reader", Labels: map[string]string{"aggregate-to-view": "true"}},
+			Rules:      []rbacv1.PolicyRule{{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin"},
+			Rules:      []rbacv1.PolicyRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}, {Verbs: []string{"*"}, NonResourceURLs: []string{"*"}}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "admins"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:masters"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "viewers", Namespace: "test"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "jane"}, {Kind: rbacv1.ServiceAccountKind, Name: "reader"}},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "token-reader", Namespace: "test"},
+			Rules:      []rbacv1.PolicyRule{{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"token"}}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "token-readers", Namespace: "test"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "token-reader"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "bob"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "viewers", Namespace: "other"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "alice"}},
+		},
+	)
+
+	tests := []struct {
+		name     string
+		options  WhoCanOptions
+		expected string
+	}{
+		{
+			name:    "aggregated role",
+			options: WhoCanOptions{Namespace: "test", Verb: "get", Resource: schema.GroupResource{Resource: "pods"}, Subresource: "log"},
+			expected: `KIND             SUBJECT          BINDING                     ROLE
+Group            system:masters   ClusterRoleBinding/admins   ClusterRole/cluster-admin
+ServiceAccount   test/reader      RoleBinding/test/viewers    ClusterRole/view
+User             jane             RoleBinding/test/viewers    ClusterRole/view
+`,
+		},
+		{
+			name:    "all namespaces",
+			options: WhoCanOptions{AllNamespaces: true, NoHeaders: true, Verb: "list", Resource: schema.GroupResource{Resource: "pods"}},
+			expected: `Group            system:masters   ClusterRoleBinding/admins   ClusterRole/cluster-admin
+ServiceAccount   test/reader      RoleBinding/test/viewers    ClusterRole/view
+User             alice            RoleBinding/other/viewers   ClusterRole/view
+User             jane             RoleBinding/test/viewers    ClusterRole/view
+`,
+		},
+		{
+			name:    "resource name",
+			options: WhoCanOptions{Namespace: "test", NoHeaders: true, Verb: "get", Resource: schema.GroupResource{Resource: "secrets"}, ResourceName: "token"},
+			expected: `Group   system:masters   ClusterRoleBinding/admins        ClusterRole/cluster-admin
+User    bob              RoleBinding/test/token-readers   Role/token-reader
+`,
+		},
+		{
+			name:    "non-resource URL",
+			options: WhoCanOptions{NoHeaders: true, Verb: "get", NonResourceURL: "/metrics"},
+			expected: `Group   system:masters   ClusterRoleBinding/admins   ClusterRole/cluster-admin
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ioStreams, _, buf, _ := genericiooptions.NewTestIOStreams()
+			o := tt.options
+			o.RbacClient = client.RbacV1()
+			o.IOStreams = ioStreams
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, buf.String())
+			}
+		})
+	}
+}
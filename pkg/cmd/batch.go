@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/color"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"k8s.io/kubectl/pkg/util/timeformat"
+)
+
+var (
+	batchLong = templates.LongDesc(i18n.T(`
+		Run kubectl commands read from a file or from the standard input, one per line.
+
+		Each line holds the arguments of a kubectl command without the leading kubectl,
+		quoted as in a shell. Empty lines and lines starting with # are skipped. The
+		commands run in a single process, sharing the connections to the server, the
+		discovery cache and the credentials of exec plugins, which is much faster than
+		running kubectl for each command.
+
+		The global flags given to batch apply to every command. The commands stop at
+		the first failure, unless --keep-going is set. Plugins can not be run in a batch.
+		When the commands are read from the standard input, they can not read it.`))
+
+	batchExample = templates.Examples(i18n.T(`
+		# Label the pods listed in pods.txt
+		sed 's/.*/label pod & checked=true/' pods.txt | kubectl batch -
+
+		# Run the commands of a file in the namespace test, continuing after failures
+		kubectl batch commands.txt -n test --keep-going`))
+)
+
+// BatchOptions holds the options of the batch command
+type BatchOptions struct {
+	Filename  string
+	KeepGoing bool
+
+	commandRunner
+}
+
+// commandRunner runs kubectl commands in the current process.
+type commandRunner struct {
+	// GlobalArgs are the global flags of the command, passed to each command.
+	GlobalArgs []string
+	// NewCommand returns the kubectl command running the arguments.
+	NewCommand func(streams genericiooptions.IOStreams, args []string) *cobra.Command
+
+	// timeFormat and colorMode are restored before each command, as they are
+	// global and the commands may change them.
+	timeFormat string
+	colorMode  string
+
+	genericiooptions.IOStreams
+}
+
+// commandExit is the panic value stopping a command which exits.
+type commandExit int
+
+func newCmdBatch(newCommand func(streams genericiooptions.IOStreams, args []string) *cobra.Command, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &BatchOptions{
+		commandRunner: commandRunner{
+			NewCommand: newCommand,
+			IOStreams:  streams,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "batch (FILENAME | -) [--keep-going]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Run kubectl commands read from a file or from the standard input"),
+		Long:                  batchLong,
+		Example:               batchExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.KeepGoing, "keep-going", o.KeepGoing, "If true, run the next commands after a command fails.")
+	return cmd
+}
+
+// Complete completes the options of the batch command
+func (o *BatchOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmdutil.UsageErrorf(cmd, "exactly one file name, or - for the standard input, is required")
+	}
+	o.Filename = args[0]
+	o.commandRunner.complete(cmd)
+	return nil
+}
+
+// complete collects the global flags set on the command.
+func (o *commandRunner) complete(cmd *cobra.Command) {
+	o.GlobalArgs = []string{}
+	cmd.InheritedFlags().VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+		if value, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, v := range value.GetSlice() {
+				o.GlobalArgs = append(o.GlobalArgs, "--"+flag.Name+"="+v)
+			}
+			return
+		}
+		o.GlobalArgs = append(o.GlobalArgs, "--"+flag.Name+"="+flag.Value.String())
+	})
+	if flag := cmd.Flags().Lookup("time-format"); flag != nil {
+		o.timeFormat = flag.Value.String()
+	}
+	if flag := cmd.Flags().Lookup("color"); flag != nil {
+		o.colorMode = flag.Value.String()
+	}
+}
+
+// Run runs the commands of the batch
+func (o *BatchOptions) Run() error {
+	in := o.In
+	// the commands can not read the standard input when the batch is read from it
+	var commandIn io.Reader = strings.NewReader("")
+	if o.Filename != "-" {
+		file, err := os.Open(o.Filename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+		commandIn = o.In
+	}
+
+	commands, failed := 0, 0
+	scanner := bufio.NewScanner(in)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		commands++
+		args, err := shlex.Split(line)
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "error: line %d: %v\n", lineNumber, err)
+		} else if o.runCommand(args, commandIn) == 0 {
+			continue
+		}
+		failed++
+		if !o.KeepGoing {
+			return fmt.Errorf("the command of line %d failed", lineNumber)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d commands failed", failed, commands)
+	}
+	return nil
+}
+
+// runCommand runs the kubectl command of the arguments and returns its exit
+// code.
+func (o *commandRunner) runCommand(args []string, in io.Reader) int {
+	if len(o.timeFormat) > 0 {
+		timeformat.Set(o.timeFormat)
+	}
+	if len(o.colorMode) > 0 {
+		color.SetMode(o.colorMode)
+	}
+
+	cmd := o.NewCommand(genericiooptions.IOStreams{In: in, Out: o.Out, ErrOut: o.ErrOut}, append(o.GlobalArgs, args...))
+	return o.execute(cmd)
+}
+
+// execute executes the command and returns its exit code. The commands
+// exiting on errors are stopped with a panic instead.
+func (o *commandRunner) execute(cmd *cobra.Command) (exitCode int) {
+	var previous func(string, int)
+	cmdutil.WrapBehaviorOnFatal(func(f func(string, int)) func(string, int) {
+		previous = f
+		return func(msg string, code int) {
+			if len(msg) > 0 {
+				fmt.Fprintln(o.ErrOut, strings.TrimSuffix(msg, "\n"))
+			}
+			panic(commandExit(code))
+		}
+	})
+	defer cmdutil.BehaviorOnFatal(previous)
+	defer func() {
+		if r := recover(); r != nil {
+			code, ok := r.(commandExit)
+			if !ok {
+				panic(r)
+			}
+			// the exit skipped the post-run hooks writing the profile
+			flushRequestProfiling(o.ErrOut)
+			exitCode = int(code)
+		}
+	}()
+
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprintf(o.ErrOut, "error: %v\n", err)
+		return cmdutil.DefaultErrorExitCode
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func newFakeBatchCommand(streams genericiooptions.IOStreams, args []string) *cobra.Command {
+	root := &cobra.Command{Use: "kubectl"}
+	root.PersistentFlags().StringP("namespace", "n", "default", "")
+	root.AddCommand(&cobra.Command{
+		Use: "echo",
+		Run: func(cmd *cobra.Command, args []string) {
+			namespace, _ := cmd.Flags().GetString("namespace")
+			fmt.Fprintf(streams.Out, "%s: %s\n", namespace, strings.Join(args, "|"))
+		},
+	})
+	root.AddCommand(&cobra.Command{
+		Use: "fail",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(errors.New("boom"))
+		},
+	})
+	root.SetArgs(args)
+	return root
+}
+
+func TestBatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		globalArgs     []string
+		keepGoing      bool
+		expectedOut    string
+		expectedErrOut string
+		expectedError  string
+	}{
+		{
+			name:        "commands",
+			input:       "# labels\necho a 'b c'\n\n  echo -n test \"d\"\n",
+			expectedOut: "default: a|b c\ntest: d\n",
+		},
+		{
+			name:        "global flags",
+			input:       "echo a\necho -n other b\n",
+			globalArgs:  []string{"--namespace=prod"},
+			expectedOut: "prod: a\nother: b\n",
+		},
+		{
+			name:           "failure",
+			input:          "echo a\nfail\necho b\n",
+			expectedOut:    "default: a\n",
+			expectedErrOut: "error: boom\n",
+			expectedError:  "the command of line 2 failed",
+		},
+		{
+			name:           "keep going",
+			input:          "echo a\nfail\nunknown\necho 'b\necho c\n",
+			keepGoing:      true,
+			expectedOut:    "default: a\ndefault: c\n",
+			expectedErrOut: "error: boom\nerror: unknown command \"unknown\" for \"kubectl\"\nerror: line 4: EOF found when expecting closing quote\n",
+			expectedError:  "3 of 5 commands failed",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			streams, in, out, errOut := genericiooptions.NewTestIOStreams()
+			in.WriteString(tc.input)
+			o := &BatchOptions{
+				Filename:  "-",
+				KeepGoing: tc.keepGoing,
+				commandRunner: commandRunner{
+					GlobalArgs: tc.globalArgs,
+					NewCommand: newFakeBatchCommand,
+					IOStreams:  streams,
+				},
+			}
+			err := o.Run()
+			if len(tc.expectedError) > 0 {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expectedOut {
+				t.Errorf("expected output %q, got %q", tc.expectedOut, out.String())
+			}
+			if errOut.String() != tc.expectedErrOut {
+				t.Errorf("expected error output %q, got %q", tc.expectedErrOut, errOut.String())
+			}
+		})
+	}
+}
@@ -52,6 +52,7 @@ func NewCmdCertificate(restClientGetter genericclioptions.RESTClientGetter, ioSt
 		},
 	}
 
+	cmd.AddCommand(NewCmdCertificateCreate(restClientGetter, ioStreams))
 	cmd.AddCommand(NewCmdCertificateApprove(restClientGetter, ioStreams))
 	cmd.AddCommand(NewCmdCertificateDeny(restClientGetter, ioStreams))
 
@@ -0,0 +1,289 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/certificate/csr"
+	"k8s.io/client-go/util/keyutil"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const nodeClientSignerName = "kubernetes.io/kube-apiserver-client-kubelet"
+
+var (
+	createLong = templates.LongDesc(i18n.T(`
+		Create a client certificate through a certificate signing request.
+
+		kubectl certificate create generates a private key and a certificate signing
+		request (CSR) for a user or a node, submits the request to the cluster and waits
+		for the certificate to be issued. The key and the certificate are written to
+		NAME.key and NAME.crt, and with --set-credentials a user entry named NAME is
+		added to the kubeconfig, ready to be used by a context.
+
+		The request has to be approved before the certificate is issued, either by a
+		cluster admin with kubectl certificate approve, or right away with --approve
+		when you are allowed to approve it.`))
+
+	createExample = templates.Examples(i18n.T(`
+		# Request a certificate for the user jane in the group dev and wait for a cluster admin to approve it
+		kubectl certificate create jane --user=jane --group=dev
+
+		# Request, approve and add the credentials of the user jane to the kubeconfig
+		kubectl certificate create jane --user=jane --approve --set-credentials
+
+		# Request a client certificate for the node worker-1, valid for a day
+		kubectl certificate create worker-1 --node=worker-1 --expiration=24h --approve`))
+)
+
+// CertificateCreateOptions declares the arguments accepted by the certificate create command
+type CertificateCreateOptions struct {
+	Name           string
+	User           string
+	Groups         []string
+	Node           string
+	SignerName     string
+	Expiration     time.Duration
+	Approve        bool
+	Timeout        time.Duration
+	CertDir        string
+	SetCredentials bool
+	EmbedCerts     bool
+
+	client       kubernetes.Interface
+	configAccess clientcmd.ConfigAccess
+
+	genericiooptions.IOStreams
+}
+
+// NewCertificateCreateOptions creates CertificateCreateOptions struct for `certificate create` command
+func NewCertificateCreateOptions(ioStreams genericiooptions.IOStreams) *CertificateCreateOptions {
+	return &CertificateCreateOptions{
+		Timeout:   5 * time.Minute,
+		CertDir:   ".",
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdCertificateCreate returns the `certificate create` Cobra command
+func NewCmdCertificateCreate(restClientGetter genericclioptions.RESTClientGetter, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	o := NewCertificateCreateOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "create NAME (--user=USER [--group=GROUP] | --node=NODE)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Create a client certificate through a certificate signing request"),
+		Long:                  createLong,
+		Example:               createExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(restClientGetter, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.User, "user", o.User, "The user name, set as the common name of the certificate.")
+	cmd.Flags().StringArrayVar(&o.Groups, "group", o.Groups, "The groups of the user, set as the organizations of the certificate. Can be repeated.")
+	cmd.Flags().StringVar(&o.Node, "node", o.Node, "The name of the node to request a kubelet client certificate for.")
+	cmd.Flags().StringVar(&o.SignerName, "signer-name", o.SignerName, "The signer of the certificate. Defaults to kubernetes.io/kube-apiserver-client, or kubernetes.io/kube-apiserver-client-kubelet with --node.")
+	cmd.Flags().DurationVar(&o.Expiration, "expiration", o.Expiration, "The requested duration of the certificate. The signer may ignore it. Zero means the default of the signer.")
+	cmd.Flags().BoolVar(&o.Approve, "approve", o.Approve, "If true, approve the request once created.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to wait for the certificate to be issued.")
+	cmd.Flags().StringVar(&o.CertDir, "cert-dir", o.CertDir, "The directory to write the key and the certificate to.")
+	cmd.Flags().BoolVar(&o.SetCredentials, "set-credentials", o.SetCredentials, "If true, add a user entry named NAME with the key and the certificate to the kubeconfig.")
+	cmd.Flags().BoolVar(&o.EmbedCerts, clientcmd.FlagEmbedCerts, o.EmbedCerts, "If true, embed the key and the certificate in the user entry instead of referencing the files.")
+
+	return cmd
+}
+
+// Complete loads data from the command environment
+func (o *CertificateCreateOptions) Complete(restClientGetter genericclioptions.RESTClientGetter, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("exactly one NAME is required, got %d", len(args))
+	}
+	o.Name = args[0]
+
+	if len(o.SignerName) == 0 {
+		o.SignerName = certificatesv1.KubeAPIServerClientSignerName
+		if len(o.Node) > 0 {
+			o.SignerName = nodeClientSignerName
+		}
+	}
+
+	clientConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.client, err = kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	o.configAccess = restClientGetter.ToRawKubeConfigLoader().ConfigAccess()
+	return nil
+}
+
+// Validate checks if the provided `certificate create` arguments are valid
+func (o *CertificateCreateOptions) Validate() error {
+	if (len(o.User) == 0) == (len(o.Node) == 0) {
+		return fmt.Errorf("exactly one of --user or --node is required")
+	}
+	if len(o.Node) > 0 && len(o.Groups) > 0 {
+		return fmt.Errorf("--group can not be used with --node")
+	}
+	if o.Expiration < 0 {
+		return fmt.Errorf("--expiration must not be negative")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be greater than zero")
+	}
+	if o.EmbedCerts && !o.SetCredentials {
+		return fmt.Errorf("--%s requires --set-credentials", clientcmd.FlagEmbedCerts)
+	}
+	return nil
+}
+
+// Run generates the key, submits the request and writes the issued certificate
+func (o *CertificateCreateOptions) Run() error {
+	subject := &pkix.Name{CommonName: o.User, Organization: o.Groups}
+	if len(o.Node) > 0 {
+		subject = &pkix.Name{CommonName: "system:node:" + o.Node, Organization: []string{"system:nodes"}}
+	}
+
+	keyData, err := keyutil.MakeEllipticPrivateKeyPEM()
+	if err != nil {
+		return err
+	}
+	key, err := keyutil.ParsePrivateKeyPEM(keyData)
+	if err != nil {
+		return err
+	}
+	csrData, err := certutil.MakeCSR(key, subject, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	var expiration *time.Duration
+	if o.Expiration > 0 {
+		expiration = &o.Expiration
+	}
+	usages := []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageClientAuth}
+	name, uid, err := csr.RequestCertificate(o.client, csrData, o.Name, o.SignerName, expiration, usages, key)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "certificatesigningrequest.certificates.k8s.io/%s created\n", name)
+
+	if o.Approve {
+		if err := o.approve(name, uid); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "certificatesigningrequest.certificates.k8s.io/%s approved\n", name)
+	} else {
+		fmt.Fprintf(o.ErrOut, "waiting for the request to be approved, run: kubectl certificate approve %s\n", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.Timeout)
+	defer cancel()
+	certData, err := csr.WaitForCertificate(ctx, o.client, name, uid)
+	if err != nil {
+		return fmt.Errorf("the certificate of %q was not issued: %v", name, err)
+	}
+
+	keyPath := filepath.Join(o.CertDir, o.Name+".key")
+	certPath := filepath.Join(o.CertDir, o.Name+".crt")
+	if err := keyutil.WriteKey(keyPath, keyData); err != nil {
+		return err
+	}
+	if err := certutil.WriteCert(certPath, certData); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "wrote %s and %s\n", keyPath, certPath)
+
+	if o.SetCredentials {
+		return o.setCredentials(keyPath, certPath, keyData, certData)
+	}
+	return nil
+}
+
+// approve approves the request, unless it was replaced by another one of
+// the same name in the meantime.
+func (o *CertificateCreateOptions) approve(name string, uid types.UID) error {
+	client := o.client.CertificatesV1().CertificateSigningRequests()
+	req, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if req.UID != uid {
+		return fmt.Errorf("certificate signing request %q changed UIDs", name)
+	}
+	modified, hasCondition, err := addConditionIfNeeded(string(certificatesv1.CertificateDenied), string(certificatesv1.CertificateApproved), "KubectlApprove", "This CSR was approved by kubectl certificate create.")(req)
+	if err != nil || hasCondition {
+		return err
+	}
+	_, err = client.UpdateApproval(context.TODO(), name, modified.(*certificatesv1.CertificateSigningRequest), metav1.UpdateOptions{})
+	return err
+}
+
+func (o *CertificateCreateOptions) setCredentials(keyPath, certPath string, keyData, certData []byte) error {
+	config, err := o.configAccess.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+
+	authInfo, exists := config.AuthInfos[o.Name]
+	if !exists {
+		authInfo = clientcmdapi.NewAuthInfo()
+	}
+	if o.EmbedCerts {
+		authInfo.ClientCertificate, authInfo.ClientKey = "", ""
+		authInfo.ClientCertificateData, authInfo.ClientKeyData = certData, keyData
+	} else {
+		if keyPath, err = filepath.Abs(keyPath); err != nil {
+			return err
+		}
+		if certPath, err = filepath.Abs(certPath); err != nil {
+			return err
+		}
+		authInfo.ClientCertificateData, authInfo.ClientKeyData = nil, nil
+		authInfo.ClientCertificate, authInfo.ClientKey = certPath, keyPath
+	}
+	config.AuthInfos[o.Name] = authInfo
+
+	if err := clientcmd.ModifyConfig(o.configAccess, *config, true); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "User %q set.\n", o.Name)
+	return nil
+}
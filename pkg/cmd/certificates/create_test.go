@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificates
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+func TestCertificateCreate(t *testing.T) {
+	tests := []struct {
+		name               string
+		user               string
+		groups             []string
+		node               string
+		expectedSigner     string
+		expectedCommonName string
+		expectedOrgs       []string
+	}{
+		{
+			name:               "user",
+			user:               "jane",
+			groups:             []string{"dev", "ops"},
+			expectedSigner:     certificatesv1.KubeAPIServerClientSignerName,
+			expectedCommonName: "jane",
+			expectedOrgs:       []string{"dev", "ops"},
+		},
+		{
+			name:               "node",
+			node:               "worker-1",
+			expectedSigner:     nodeClientSignerName,
+			expectedCommonName: "system:node:worker-1",
+			expectedOrgs:       []string{"system:nodes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			kubeconfig := filepath.Join(dir, "config")
+			if err := clientcmd.WriteToFile(*clientcmdapi.NewConfig(), kubeconfig); err != nil {
+				t.Fatal(err)
+			}
+			pathOptions := clientcmd.NewDefaultPathOptions()
+			pathOptions.GlobalFile = kubeconfig
+			pathOptions.EnvVar = ""
+
+			caKey, err := keyutil.MakeEllipticPrivateKeyPEM()
+			if err != nil {
+				t.Fatal(err)
+			}
+			signer, err := keyutil.ParsePrivateKeyPEM(caKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			caCert, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: "ca"}, signer.(crypto.Signer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			issued := pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: caCert.Raw})
+
+			var created *certificatesv1.CertificateSigningRequest
+			client := fake.NewSimpleClientset()
+			client.PrependReactor("create", "certificatesigningrequests", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				created = action.(clienttesting.CreateAction).GetObject().(*certificatesv1.CertificateSigningRequest)
+				created.UID = types.UID("uid-1")
+				return false, nil, nil
+			})
+			// issue the certificate once approved, as the signer would
+			client.PrependReactor("update", "certificatesigningrequests", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "approval" {
+					action.(clienttesting.UpdateAction).GetObject().(*certificatesv1.CertificateSigningRequest).Status.Certificate = issued
+				}
+				return false, nil, nil
+			})
+
+			ioStreams, _, _, _ := genericiooptions.NewTestIOStreams()
+			o := NewCertificateCreateOptions(ioStreams)
+			o.Name = "creds"
+			o.User = tt.user
+			o.Groups = tt.groups
+			o.Node = tt.node
+			o.Approve = true
+			o.SetCredentials = true
+			o.CertDir = dir
+			o.Timeout = 10 * time.Second
+			o.SignerName = tt.expectedSigner
+			o.client = client
+			o.configAccess = pathOptions
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatal(err)
+			}
+
+			if created.Spec.SignerName != tt.expectedSigner {
+				t.Errorf("expected signer %q, got %q", tt.expectedSigner, created.Spec.SignerName)
+			}
+			block, _ := pem.Decode(created.Spec.Request)
+			request, err := x509.ParseCertificateRequest(block.Bytes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if request.Subject.CommonName != tt.expectedCommonName || !reflect.DeepEqual(request.Subject.Organization, tt.expectedOrgs) {
+				t.Errorf("unexpected subject %v", request.Subject)
+			}
+
+			certData, err := os.ReadFile(filepath.Join(dir, "creds.crt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(certData, issued) {
+				t.Errorf("unexpected certificate written:\n%s", certData)
+			}
+			if _, err := keyutil.PrivateKeyFromFile(filepath.Join(dir, "creds.key")); err != nil {
+				t.Errorf("unexpected key written: %v", err)
+			}
+
+			config, err := clientcmd.LoadFromFile(kubeconfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			authInfo, ok := config.AuthInfos["creds"]
+			if !ok {
+				t.Fatalf("expected the user entry creds in %v", config.AuthInfos)
+			}
+			// the paths are written relative to the kubeconfig
+			if authInfo.ClientCertificate != "creds.crt" || authInfo.ClientKey != "creds.key" {
+				t.Errorf("unexpected user entry %v", authInfo)
+			}
+		})
+	}
+}
+
+func TestCertificateCreateValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     CertificateCreateOptions
+		expectedErr string
+	}{
+		{
+			name:        "no subject",
+			options:     CertificateCreateOptions{Timeout: time.Minute},
+			expectedErr: "exactly one of --user or --node is required",
+		},
+		{
+			name:        "user and node",
+			options:     CertificateCreateOptions{User: "jane", Node: "worker-1", Timeout: time.Minute},
+			expectedErr: "exactly one of --user or --node is required",
+		},
+		{
+			name:        "groups of a node",
+			options:     CertificateCreateOptions{Node: "worker-1", Groups: []string{"dev"}, Timeout: time.Minute},
+			expectedErr: "--group can not be used with --node",
+		},
+		{
+			name:        "embed without credentials",
+			options:     CertificateCreateOptions{User: "jane", EmbedCerts: true, Timeout: time.Minute},
+			expectedErr: "--embed-certs requires --set-credentials",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if err == nil || err.Error() != tt.expectedErr {
+				t.Errorf("expected error %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -17,20 +17,31 @@ limitations under the License.
 package clusterinfo
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/dynamic"
 	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -43,30 +54,70 @@ import (
 const (
 	defaultPodLogsTimeout = 20 * time.Second
 	timeout               = 5 * time.Minute
+
+	// indexFile is the file listing the files of the dump.
+	indexFile = "index.json"
+	// redactedValue replaces the values of the secrets.
+	redactedValue = "REDACTED"
 )
 
+// defaultDumpResources are the resources dumped by default. The cluster-scoped
+// resources are dumped once, the namespaced ones for each namespace.
+var defaultDumpResources = []string{"nodes", "events", "replicationcontrollers", "services", "daemonsets", "deployments", "replicasets", "pods"}
+
+// metricsResources are the resources dumped with --include-metrics.
+var metricsResources = []string{"nodes.metrics.k8s.io", "pods.metrics.k8s.io"}
+
 type ClusterInfoDumpOptions struct {
 	PrintFlags *genericclioptions.PrintFlags
 	PrintObj   printers.ResourcePrinterFunc
 
-	OutputDir     string
-	AllNamespaces bool
-	Namespaces    []string
-
-	Timeout          time.Duration
+	OutputDir         string
+	AllNamespaces     bool
+	Namespaces        []string
+	NamespaceSelector string
+	Resources         []string
+	LabelSelector     string
+	RedactSecrets     bool
+	Since             time.Duration
+	IncludeLogs       bool
+	IncludeMetrics    bool
+
+	Timeout time.Duration
+	// Deprecated: the objects are listed with DynamicClient.
 	AppsClient       appsv1client.AppsV1Interface
 	CoreClient       corev1client.CoreV1Interface
+	DynamicClient    dynamic.Interface
+	RESTMapper       meta.RESTMapper
 	Namespace        string
 	RESTClientGetter genericclioptions.RESTClientGetter
 	LogsForObject    polymorphichelpers.LogsForObjectFunc
 
+	archive *dumpArchive
+	index   []dumpIndexEntry
+	now     func() time.Time
+
 	genericiooptions.IOStreams
 }
 
+// dumpIndexEntry describes a file of the dump in the index.
+type dumpIndexEntry struct {
+	Path      string `json:"path"`
+	Resource  string `json:"resource,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Pod       string `json:"pod,omitempty"`
+	Items     *int   `json:"items,omitempty"`
+}
+
 func NewCmdClusterInfoDump(restClientGetter genericclioptions.RESTClientGetter, ioStreams genericiooptions.IOStreams) *cobra.Command {
 	o := &ClusterInfoDumpOptions{
 		PrintFlags: genericclioptions.NewPrintFlags("").WithTypeSetter(scheme.Scheme).WithDefaultOutput("json"),
 
+		Resources:     defaultDumpResources,
+		RedactSecrets: true,
+		IncludeLogs:   true,
+		now:           time.Now,
+
 		IOStreams: ioStreams,
 	}
 
@@ -77,15 +128,23 @@ func NewCmdClusterInfoDump(restClientGetter genericclioptions.RESTClientGetter,
 		Example: dumpExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(restClientGetter, cmd))
+			cmdutil.CheckErr(o.Validate())
 			cmdutil.CheckErr(o.Run())
 		},
 	}
 
 	o.PrintFlags.AddFlags(cmd)
 
-	cmd.Flags().StringVar(&o.OutputDir, "output-directory", o.OutputDir, i18n.T("Where to output the files.  If empty or '-' uses stdout, otherwise creates a directory hierarchy in that directory"))
+	cmd.Flags().StringVar(&o.OutputDir, "output-directory", o.OutputDir, i18n.T("Where to output the files.  If empty or '-' uses stdout, if ending with .tar.gz or .tgz writes a compressed archive, otherwise creates a directory hierarchy in that directory"))
 	cmd.Flags().StringSliceVar(&o.Namespaces, "namespaces", o.Namespaces, "A comma separated list of namespaces to dump.")
 	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If true, dump all namespaces.  If true, --namespaces is ignored.")
+	cmd.Flags().StringVar(&o.NamespaceSelector, "namespace-selector", o.NamespaceSelector, "Selector (label query) to filter the namespaces to dump on. If set, --namespaces is ignored.")
+	cmd.Flags().StringSliceVar(&o.Resources, "resources", o.Resources, "A comma separated list of the resources to dump. The cluster-scoped resources are dumped once, the namespaced ones for each namespace.")
+	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+	cmd.Flags().BoolVar(&o.RedactSecrets, "redact-secrets", o.RedactSecrets, "If true, replace the values of the secrets by REDACTED.")
+	cmd.Flags().DurationVar(&o.Since, "since", o.Since, "Only dump the events and the logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all the events and logs.")
+	cmd.Flags().BoolVar(&o.IncludeLogs, "include-logs", o.IncludeLogs, "If true, dump the logs of the containers of the dumped pods.")
+	cmd.Flags().BoolVar(&o.IncludeMetrics, "include-metrics", o.IncludeMetrics, "If true, dump the metrics of the nodes and of the pods, when the metrics API is available.")
 	cmdutil.AddPodRunningTimeoutFlag(cmd, defaultPodLogsTimeout)
 	return cmd
 }
@@ -94,11 +153,16 @@ var (
 	dumpLong = templates.LongDesc(i18n.T(`
     Dump cluster information out suitable for debugging and diagnosing cluster problems.  By default, dumps everything to
     stdout. You can optionally specify a directory with --output-directory.  If you specify a directory, Kubernetes will
-    build a set of files in that directory.  By default, only dumps things in the current namespace and 'kube-system' namespace, but you can
-    switch to a different namespace with the --namespaces flag, or specify --all-namespaces to dump all namespaces.
+    build a set of files in that directory, listed in an index.json file.  If the directory ends with .tar.gz or .tgz, the
+    files are written to a compressed archive instead.  By default, only dumps things in the current namespace and
+    'kube-system' namespace, but you can switch to a different namespace with the --namespaces flag, select the namespaces
+    by label with --namespace-selector, or specify --all-namespaces to dump all namespaces.
+
+    The nodes, events, replication controllers, services, daemon sets, deployments, replica sets and pods are dumped, or
+    the resources given with --resources.  The values of the secrets are redacted, unless --redact-secrets=false.
 
     The command also dumps the logs of all of the pods in the cluster; these logs are dumped into different directories
-    based on namespace and pod name.`))
+    based on namespace and pod name.  Use --since to only dump the recent events and logs.`))
 
 	dumpExample = templates.Examples(i18n.T(`
     # Dump current cluster state to stdout
@@ -111,7 +175,10 @@ var (
     kubectl cluster-info dump --all-namespaces
 
     # Dump a set of namespaces to /path/to/cluster-state
-    kubectl cluster-info dump --namespaces default,kube-system --output-directory=/path/to/cluster-state`))
+    kubectl cluster-info dump --namespaces default,kube-system --output-directory=/path/to/cluster-state
+
+    # Dump the last hour of the namespaces of team a, with their secrets redacted and metrics, to a support bundle
+    kubectl cluster-info dump --namespace-selector team=a --resources pods,events,secrets,deployments --since 1h --include-metrics --output-directory=bundle.tar.gz`))
 )
 
 func setupOutputWriter(dir string, defaultWriter io.Writer, filename string, fileExtension string) io.Writer {
@@ -150,6 +217,16 @@ func (o *ClusterInfoDumpOptions) Complete(restClientGetter genericclioptions.RES
 		return err
 	}
 
+	o.DynamicClient, err = dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	o.RESTMapper, err = restClientGetter.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+
 	o.Timeout, err = cmdutil.GetPodRunningTimeoutFlag(cmd)
 	if err != nil {
 		return err
@@ -162,16 +239,25 @@ func (o *ClusterInfoDumpOptions) Complete(restClientGetter genericclioptions.RES
 
 	o.RESTClientGetter = restClientGetter
 	o.LogsForObject = polymorphichelpers.LogsForObjectFn
+	if o.now == nil {
+		o.now = time.Now
+	}
 
 	return nil
 }
 
-func (o *ClusterInfoDumpOptions) Run() error {
-	nodes, err := o.CoreClient.Nodes().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return err
+// Validate makes sure that provided values for command-line options are valid
+func (o *ClusterInfoDumpOptions) Validate() error {
+	if len(o.Resources) == 0 && !o.IncludeMetrics {
+		return fmt.Errorf("at least one resource must be dumped")
+	}
+	if o.Since < 0 {
+		return fmt.Errorf("--since must be greater than 0")
 	}
+	return nil
+}
 
+func (o *ClusterInfoDumpOptions) Run() error {
 	fileExtension := ".txt"
 	if o.PrintFlags.OutputFormat != nil {
 		switch *o.PrintFlags.OutputFormat {
@@ -182,129 +268,361 @@ func (o *ClusterInfoDumpOptions) Run() error {
 		}
 	}
 
-	if err := o.PrintObj(nodes, setupOutputWriter(o.OutputDir, o.Out, "nodes", fileExtension)); err != nil {
-		return err
+	if isArchive(o.OutputDir) {
+		archive, err := newDumpArchive(o.OutputDir, o.now())
+		if err != nil {
+			return err
+		}
+		o.archive = archive
 	}
+	o.index = nil
 
-	var namespaces []string
-	if o.AllNamespaces {
-		namespaceList, err := o.CoreClient.Namespaces().List(context.TODO(), metav1.ListOptions{})
+	mappings := []*meta.RESTMapping{}
+	resources := o.Resources
+	if o.IncludeMetrics {
+		resources = append(append([]string{}, resources...), metricsResources...)
+	}
+	for _, resource := range resources {
+		mapping, err := o.mappingFor(resource)
 		if err != nil {
+			if o.IncludeMetrics && strings.HasSuffix(resource, ".metrics.k8s.io") {
+				fmt.Fprintf(o.ErrOut, "Warning: the metrics API is not available: %v\n", err)
+				continue
+			}
 			return err
 		}
-		for ix := range namespaceList.Items {
-			namespaces = append(namespaces, namespaceList.Items[ix].Name)
+		mappings = append(mappings, mapping)
+	}
+
+	for _, mapping := range mappings {
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			continue
 		}
-	} else {
-		if len(o.Namespaces) == 0 {
-			namespaces = []string{
-				metav1.NamespaceSystem,
-				o.Namespace,
-			}
-		} else {
-			namespaces = o.Namespaces
+		if _, err := o.dumpResource(mapping, "", fileExtension); err != nil {
+			return err
 		}
 	}
+
+	namespaces, err := o.namespaces()
+	if err != nil {
+		return err
+	}
 	for _, namespace := range namespaces {
-		// TODO: this is repetitive in the extreme.  Use reflection or
-		// something to make this a for loop.
-		events, err := o.CoreClient.Events(namespace).List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			return err
-		}
-		if err := o.PrintObj(events, setupOutputWriter(o.OutputDir, o.Out, path.Join(namespace, "events"), fileExtension)); err != nil {
-			return err
+		for _, mapping := range mappings {
+			if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+				continue
+			}
+			list, err := o.dumpResource(mapping, namespace, fileExtension)
+			if err != nil {
+				return err
+			}
+			if o.IncludeLogs && mapping.Resource.GroupResource() == corev1.Resource("pods") {
+				if err := o.dumpLogs(list); err != nil {
+					return err
+				}
+			}
 		}
+	}
 
-		rcs, err := o.CoreClient.ReplicationControllers(namespace).List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
+	dest := o.OutputDir
+	if len(dest) == 0 || dest == "-" {
+		return nil
+	}
+	if err := o.dumpIndex(namespaces); err != nil {
+		return err
+	}
+	if o.archive != nil {
+		if err := o.archive.close(); err != nil {
 			return err
 		}
-		if err := o.PrintObj(rcs, setupOutputWriter(o.OutputDir, o.Out, path.Join(namespace, "replication-controllers"), fileExtension)); err != nil {
-			return err
+	}
+	fmt.Fprintf(o.Out, "Cluster info dumped to %s\n", dest)
+	return nil
+}
+
+// mappingFor returns the mapping of a resource, with an optional group.
+func (o *ClusterInfoDumpOptions) mappingFor(resource string) (*meta.RESTMapping, error) {
+	gvr, err := o.RESTMapper.ResourceFor(schema.ParseGroupResource(resource).WithVersion(""))
+	if err != nil {
+		return nil, err
+	}
+	gvk, err := o.RESTMapper.KindFor(gvr)
+	if err != nil {
+		return nil, err
+	}
+	return o.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+// namespaces returns the namespaces to dump.
+func (o *ClusterInfoDumpOptions) namespaces() ([]string, error) {
+	if !o.AllNamespaces && len(o.NamespaceSelector) == 0 {
+		if len(o.Namespaces) == 0 {
+			return []string{
+				metav1.NamespaceSystem,
+				o.Namespace,
+			}, nil
 		}
+		return o.Namespaces, nil
+	}
 
-		svcs, err := o.CoreClient.Services(namespace).List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
-			return err
+	namespaceList, err := o.CoreClient.Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: o.NamespaceSelector})
+	if err != nil {
+		return nil, err
+	}
+	namespaces := []string{}
+	for ix := range namespaceList.Items {
+		namespaces = append(namespaces, namespaceList.Items[ix].Name)
+	}
+	return namespaces, nil
+}
+
+// dumpResource dumps the objects of the resource in the namespace, and
+// returns them.
+func (o *ClusterInfoDumpOptions) dumpResource(mapping *meta.RESTMapping, namespace string, fileExtension string) (*unstructured.UnstructuredList, error) {
+	groupResource := mapping.Resource.GroupResource()
+	options := metav1.ListOptions{}
+	// events have no labels
+	if groupResource != corev1.Resource("events") {
+		options.LabelSelector = o.LabelSelector
+	}
+	list, err := o.DynamicClient.Resource(mapping.Resource).Namespace(namespace).List(context.TODO(), options)
+	if err != nil {
+		return nil, err
+	}
+
+	switch groupResource {
+	case corev1.Resource("events"):
+		if o.Since > 0 {
+			list.Items = recentEvents(list.Items, o.now().Add(-o.Since))
 		}
-		if err := o.PrintObj(svcs, setupOutputWriter(o.OutputDir, o.Out, path.Join(namespace, "services"), fileExtension)); err != nil {
-			return err
+	case corev1.Resource("secrets"):
+		if o.RedactSecrets {
+			for i := range list.Items {
+				redactSecret(&list.Items[i])
+			}
 		}
+	}
+
+	items := len(list.Items)
+	entry := dumpIndexEntry{
+		Path:      path.Join(namespace, dumpFileName(groupResource)),
+		Resource:  groupResource.String(),
+		Namespace: namespace,
+		Items:     &items,
+	}
+	err = o.dumpFile(entry, fileExtension, func(w io.Writer) error {
+		return o.PrintObj(list, w)
+	})
+	return list, err
+}
 
-		sets, err := o.AppsClient.DaemonSets(namespace).List(context.TODO(), metav1.ListOptions{})
+// dumpLogs dumps the logs of the containers of the pods.
+func (o *ClusterInfoDumpOptions) dumpLogs(pods *unstructured.UnstructuredList) error {
+	printContainer := func(writer io.Writer, container corev1.Container, pod *corev1.Pod) {
+		writer.Write([]byte(fmt.Sprintf("==== START logs for container %s of pod %s/%s ====\n", container.Name, pod.Namespace, pod.Name)))
+		defer writer.Write([]byte(fmt.Sprintf("==== END logs for container %s of pod %s/%s ====\n", container.Name, pod.Namespace, pod.Name)))
+
+		logOptions := &corev1.PodLogOptions{Container: container.Name}
+		if o.Since > 0 {
+			sinceSeconds := int64(o.Since.Round(time.Second).Seconds())
+			logOptions.SinceSeconds = &sinceSeconds
+		}
+		requests, err := o.LogsForObject(o.RESTClientGetter, pod, logOptions, timeout, false)
 		if err != nil {
-			return err
+			// Print error and return.
+			writer.Write([]byte(fmt.Sprintf("Create log request error: %s\n", err.Error())))
+			return
 		}
-		if err := o.PrintObj(sets, setupOutputWriter(o.OutputDir, o.Out, path.Join(namespace, "daemonsets"), fileExtension)); err != nil {
-			return err
+
+		for _, request := range requests {
+			data, err := request.DoRaw(context.TODO())
+			if err != nil {
+				// Print error and return.
+				writer.Write([]byte(fmt.Sprintf("Request log error: %s\n", err.Error())))
+				return
+			}
+			writer.Write(data)
 		}
+	}
 
-		deps, err := o.AppsClient.Deployments(namespace).List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
+	for ix := range pods.Items {
+		pod := &corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(pods.Items[ix].Object, pod); err != nil {
 			return err
 		}
-		if err := o.PrintObj(deps, setupOutputWriter(o.OutputDir, o.Out, path.Join(namespace, "deployments"), fileExtension)); err != nil {
-			return err
+		entry := dumpIndexEntry{
+			Path:      path.Join(pod.Namespace, pod.Name, "logs"),
+			Namespace: pod.Namespace,
+			Pod:       pod.Name,
 		}
-
-		rps, err := o.AppsClient.ReplicaSets(namespace).List(context.TODO(), metav1.ListOptions{})
+		err := o.dumpFile(entry, ".txt", func(writer io.Writer) error {
+			for i := range pod.Spec.InitContainers {
+				printContainer(writer, pod.Spec.InitContainers[i], pod)
+			}
+			for i := range pod.Spec.Containers {
+				printContainer(writer, pod.Spec.Containers[i], pod)
+			}
+			return nil
+		})
 		if err != nil {
 			return err
 		}
-		if err := o.PrintObj(rps, setupOutputWriter(o.OutputDir, o.Out, path.Join(namespace, "replicasets"), fileExtension)); err != nil {
-			return err
-		}
+	}
+	return nil
+}
 
-		pods, err := o.CoreClient.Pods(namespace).List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
+// dumpIndex dumps the index of the files of the dump.
+func (o *ClusterInfoDumpOptions) dumpIndex(namespaces []string) error {
+	index := struct {
+		Created    time.Time        `json:"created"`
+		Namespaces []string         `json:"namespaces"`
+		Files      []dumpIndexEntry `json:"files"`
+	}{
+		Created:    o.now().UTC(),
+		Namespaces: namespaces,
+		Files:      o.index,
+	}
+	data, err := json.MarshalIndent(index, "", "    ")
+	if err != nil {
+		return err
+	}
+	return o.dumpFile(dumpIndexEntry{Path: strings.TrimSuffix(indexFile, ".json")}, ".json", func(w io.Writer) error {
+		_, err := fmt.Fprintln(w, string(data))
+		return err
+	})
+}
+
+// dumpFile writes a file of the dump and records it in the index.
+func (o *ClusterInfoDumpOptions) dumpFile(entry dumpIndexEntry, fileExtension string, write func(io.Writer) error) error {
+	filename := entry.Path
+	entry.Path += fileExtension
+	if filename != strings.TrimSuffix(indexFile, ".json") {
+		o.index = append(o.index, entry)
+	}
+
+	if o.archive != nil {
+		buf := &bytes.Buffer{}
+		if err := write(buf); err != nil {
 			return err
 		}
+		return o.archive.add(entry.Path, buf.Bytes())
+	}
 
-		if err := o.PrintObj(pods, setupOutputWriter(o.OutputDir, o.Out, path.Join(namespace, "pods"), fileExtension)); err != nil {
-			return err
+	writer := setupOutputWriter(o.OutputDir, o.Out, filename, fileExtension)
+	err := write(writer)
+	if file, ok := writer.(*os.File); ok && writer != o.Out {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
 		}
+	}
+	return err
+}
 
-		printContainer := func(writer io.Writer, container corev1.Container, pod *corev1.Pod) {
-			writer.Write([]byte(fmt.Sprintf("==== START logs for container %s of pod %s/%s ====\n", container.Name, pod.Namespace, pod.Name)))
-			defer writer.Write([]byte(fmt.Sprintf("==== END logs for container %s of pod %s/%s ====\n", container.Name, pod.Namespace, pod.Name)))
+// dumpFileName returns the name of the file of the objects of a resource,
+// without extension. The resources of the built-in groups are named after
+// the resource only.
+func dumpFileName(groupResource schema.GroupResource) string {
+	if groupResource == corev1.Resource("replicationcontrollers") {
+		return "replication-controllers"
+	}
+	if strings.Contains(groupResource.Group, ".") {
+		return groupResource.String()
+	}
+	return groupResource.Resource
+}
 
-			requests, err := o.LogsForObject(o.RESTClientGetter, pod, &corev1.PodLogOptions{Container: container.Name}, timeout, false)
-			if err != nil {
-				// Print error and return.
-				writer.Write([]byte(fmt.Sprintf("Create log request error: %s\n", err.Error())))
-				return
+// recentEvents returns the events which last occurred after the time.
+func recentEvents(events []unstructured.Unstructured, after time.Time) []unstructured.Unstructured {
+	recent := []unstructured.Unstructured{}
+	for _, event := range events {
+		last := event.GetCreationTimestamp().Time
+		for _, field := range []string{"lastTimestamp", "eventTime"} {
+			value, _, _ := unstructured.NestedString(event.Object, field)
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil && t.After(last) {
+				last = t
 			}
+		}
+		if !last.Before(after) {
+			recent = append(recent, event)
+		}
+	}
+	return recent
+}
 
-			for _, request := range requests {
-				data, err := request.DoRaw(context.TODO())
-				if err != nil {
-					// Print error and return.
-					writer.Write([]byte(fmt.Sprintf("Request log error: %s\n", err.Error())))
-					return
-				}
-				writer.Write(data)
-			}
+// redactSecret replaces the values of the secret, including the ones of the
+// last applied configuration.
+func redactSecret(secret *unstructured.Unstructured) {
+	for _, field := range []string{"data", "stringData"} {
+		values, found, _ := unstructured.NestedMap(secret.Object, field)
+		if !found {
+			continue
+		}
+		for key := range values {
+			values[key] = redactedValue
 		}
+		unstructured.SetNestedMap(secret.Object, values, field)
+	}
+	annotations := secret.GetAnnotations()
+	if _, ok := annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		annotations[corev1.LastAppliedConfigAnnotation] = redactedValue
+		secret.SetAnnotations(annotations)
+	}
+}
 
-		for ix := range pods.Items {
-			pod := &pods.Items[ix]
-			initcontainers := pod.Spec.InitContainers
-			containers := pod.Spec.Containers
-			writer := setupOutputWriter(o.OutputDir, o.Out, path.Join(namespace, pod.Name, "logs"), ".txt")
+// isArchive returns whether the dump is written to a compressed archive.
+func isArchive(dest string) bool {
+	return strings.HasSuffix(dest, ".tar.gz") || strings.HasSuffix(dest, ".tgz")
+}
 
-			for i := range initcontainers {
-				printContainer(writer, initcontainers[i], pod)
-			}
-			for i := range containers {
-				printContainer(writer, containers[i], pod)
-			}
-		}
+// dumpArchive is a compressed tar archive of the files of a dump. The files
+// are in a directory named after the archive.
+type dumpArchive struct {
+	file    *os.File
+	gzip    *gzip.Writer
+	tar     *tar.Writer
+	dir     string
+	modTime time.Time
+}
+
+func newDumpArchive(filename string, modTime time.Time) (*dumpArchive, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
 	}
+	gzipWriter := gzip.NewWriter(file)
+	base := filepath.Base(filename)
+	return &dumpArchive{
+		file:    file,
+		gzip:    gzipWriter,
+		tar:     tar.NewWriter(gzipWriter),
+		dir:     strings.TrimSuffix(strings.TrimSuffix(base, ".tgz"), ".tar.gz"),
+		modTime: modTime,
+	}, nil
+}
 
-	dest := o.OutputDir
-	if len(dest) > 0 && dest != "-" {
-		fmt.Fprintf(o.Out, "Cluster info dumped to %s\n", dest)
+func (a *dumpArchive) add(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    path.Join(a.dir, name),
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: a.modTime,
 	}
-	return nil
+	if err := a.tar.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := a.tar.Write(data)
+	return err
+}
+
+func (a *dumpArchive) close() error {
+	if err := a.tar.Close(); err != nil {
+		return err
+	}
+	if err := a.gzip.Close(); err != nil {
+		return err
+	}
+	return a.file.Close()
 }
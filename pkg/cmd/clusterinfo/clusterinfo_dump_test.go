@@ -17,12 +17,30 @@ limitations under the License.
 package clusterinfo
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
 	"os"
 	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	restclient "k8s.io/client-go/rest"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
 )
 
 func TestSetupOutputWriterNoOp(t *testing.T) {
@@ -82,3 +100,166 @@ func TestSetupOutputWriterFile(t *testing.T) {
 		t.Errorf("expected: %v, saw: %v", output, data)
 	}
 }
+
+type responseWrapperMock struct {
+	data string
+}
+
+func (r *responseWrapperMock) DoRaw(context.Context) ([]byte, error) {
+	return []byte(r.data), nil
+}
+
+func (r *responseWrapperMock) Stream(context.Context) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(r.data)), nil
+}
+
+func TestDumpArchive(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	objects := []runtime.Object{
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test", Labels: map[string]string{"app": "web"}},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx"}}},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test"}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "creds", Namespace: "test", Labels: map[string]string{"app": "web"}},
+			Data:       map[string][]byte{"password": []byte("secret")},
+		},
+		&corev1.Event{
+			ObjectMeta:    metav1.ObjectMeta{Name: "old", Namespace: "test"},
+			LastTimestamp: metav1.NewTime(now.Add(-2 * time.Hour)),
+		},
+		&corev1.Event{
+			ObjectMeta:    metav1.ObjectMeta{Name: "recent", Namespace: "test"},
+			LastTimestamp: metav1.NewTime(now.Add(-10 * time.Minute)),
+		},
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme.Scheme, map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "pods"}:    "PodList",
+		{Version: "v1", Resource: "secrets"}: "SecretList",
+		{Version: "v1", Resource: "events"}:  "EventList",
+	}, objects...)
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	mapper, err := tf.ToRESTMapper()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	streams, _, out, errOut := genericiooptions.NewTestIOStreams()
+	archive := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	printFlags := genericclioptions.NewPrintFlags("").WithTypeSetter(scheme.Scheme).WithDefaultOutput("json")
+	printer, err := printFlags.ToPrinter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logOptions *corev1.PodLogOptions
+	o := &ClusterInfoDumpOptions{
+		PrintFlags:    printFlags,
+		PrintObj:      printer.PrintObj,
+		OutputDir:     archive,
+		Namespaces:    []string{"test"},
+		Resources:     []string{"pods", "secrets", "events"},
+		LabelSelector: "app=web",
+		RedactSecrets: true,
+		Since:         time.Hour,
+		IncludeLogs:   true,
+		DynamicClient: dynamicClient,
+		RESTMapper:    mapper,
+		LogsForObject: func(restClientGetter genericclioptions.RESTClientGetter, object, options runtime.Object, timeout time.Duration, allContainers bool) (map[corev1.ObjectReference]restclient.ResponseWrapper, error) {
+			logOptions = options.(*corev1.PodLogOptions)
+			return map[corev1.ObjectReference]restclient.ResponseWrapper{{}: &responseWrapperMock{data: "started\n"}}, nil
+		},
+		now:       func() time.Time { return now },
+		IOStreams: streams,
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Cluster info dumped to " + archive + "\n"; out.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, out.String())
+	}
+	if errOut.Len() > 0 {
+		t.Errorf("unexpected error output: %s", errOut.String())
+	}
+	if logOptions.SinceSeconds == nil || *logOptions.SinceSeconds != 3600 {
+		t.Errorf("expected the logs of the last hour, got %v", logOptions.SinceSeconds)
+	}
+
+	files := readArchive(t, archive)
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	expectedNames := []string{"bundle/index.json", "bundle/test/events.json", "bundle/test/pods.json", "bundle/test/secrets.json", "bundle/test/web/logs.txt"}
+	if !reflect.DeepEqual(sortedStrings(names), expectedNames) {
+		t.Errorf("expected the files %v, got %v", expectedNames, sortedStrings(names))
+	}
+
+	if strings.Contains(files["bundle/test/pods.json"], `"db"`) {
+		t.Errorf("expected the pods to be selected, got:\n%s", files["bundle/test/pods.json"])
+	}
+	if !strings.Contains(files["bundle/test/secrets.json"], `"password": "REDACTED"`) {
+		t.Errorf("expected the secret to be redacted, got:\n%s", files["bundle/test/secrets.json"])
+	}
+	if strings.Contains(files["bundle/test/events.json"], `"old"`) || !strings.Contains(files["bundle/test/events.json"], `"recent"`) {
+		t.Errorf("expected the recent events only, got:\n%s", files["bundle/test/events.json"])
+	}
+	if expected := "==== START logs for container nginx of pod test/web ====\nstarted\n==== END logs for container nginx of pod test/web ====\n"; files["bundle/test/web/logs.txt"] != expected {
+		t.Errorf("expected the logs:\n%s\ngot:\n%s", expected, files["bundle/test/web/logs.txt"])
+	}
+
+	index := struct {
+		Namespaces []string         `json:"namespaces"`
+		Files      []dumpIndexEntry `json:"files"`
+	}{}
+	if err := json.Unmarshal([]byte(files["bundle/index.json"]), &index); err != nil {
+		t.Fatal(err)
+	}
+	one := 1
+	expectedIndex := []dumpIndexEntry{
+		{Path: "test/pods.json", Resource: "pods", Namespace: "test", Items: &one},
+		{Path: "test/web/logs.txt", Namespace: "test", Pod: "web"},
+		{Path: "test/secrets.json", Resource: "secrets", Namespace: "test", Items: &one},
+		{Path: "test/events.json", Resource: "events", Namespace: "test", Items: &one},
+	}
+	if !reflect.DeepEqual(index.Files, expectedIndex) || !reflect.DeepEqual(index.Namespaces, []string{"test"}) {
+		t.Errorf("unexpected index:\n%s", files["bundle/index.json"])
+	}
+}
+
+func readArchive(t *testing.T, filename string) map[string]string {
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(data)
+	}
+	return files
+}
+
+func sortedStrings(s []string) []string {
+	sorted := append([]string{}, s...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -23,6 +23,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"syscall"
 
@@ -33,6 +34,7 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/alias"
 	"k8s.io/kubectl/pkg/cmd/annotate"
 	"k8s.io/kubectl/pkg/cmd/apiresources"
 	"k8s.io/kubectl/pkg/cmd/apply"
@@ -56,27 +58,40 @@ import (
 	"k8s.io/kubectl/pkg/cmd/explain"
 	"k8s.io/kubectl/pkg/cmd/expose"
 	"k8s.io/kubectl/pkg/cmd/get"
+	"k8s.io/kubectl/pkg/cmd/graph"
+	"k8s.io/kubectl/pkg/cmd/history"
+	"k8s.io/kubectl/pkg/cmd/images"
 	"k8s.io/kubectl/pkg/cmd/label"
 	"k8s.io/kubectl/pkg/cmd/logs"
+	"k8s.io/kubectl/pkg/cmd/namespace"
 	"k8s.io/kubectl/pkg/cmd/options"
 	"k8s.io/kubectl/pkg/cmd/patch"
 	"k8s.io/kubectl/pkg/cmd/plugin"
 	"k8s.io/kubectl/pkg/cmd/portforward"
+	"k8s.io/kubectl/pkg/cmd/preview"
 	"k8s.io/kubectl/pkg/cmd/proxy"
+	"k8s.io/kubectl/pkg/cmd/recommend"
+	"k8s.io/kubectl/pkg/cmd/render"
 	"k8s.io/kubectl/pkg/cmd/replace"
+	"k8s.io/kubectl/pkg/cmd/resize"
+	"k8s.io/kubectl/pkg/cmd/restore"
 	"k8s.io/kubectl/pkg/cmd/rollout"
 	"k8s.io/kubectl/pkg/cmd/run"
 	"k8s.io/kubectl/pkg/cmd/scale"
 	"k8s.io/kubectl/pkg/cmd/set"
+	"k8s.io/kubectl/pkg/cmd/status"
 	"k8s.io/kubectl/pkg/cmd/taint"
 	"k8s.io/kubectl/pkg/cmd/top"
+	"k8s.io/kubectl/pkg/cmd/tree"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/cmd/version"
 	"k8s.io/kubectl/pkg/cmd/wait"
+	"k8s.io/kubectl/pkg/util/color"
 	utilcomp "k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
 	"k8s.io/kubectl/pkg/util/term"
+	"k8s.io/kubectl/pkg/util/timeformat"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/kubectl/pkg/cmd/kustomize"
@@ -88,6 +103,9 @@ type KubectlOptions struct {
 	PluginHandler PluginHandler
 	Arguments     []string
 	ConfigFlags   *genericclioptions.ConfigFlags
+	// SharedDiscoveryClients, when set, are the discovery clients shared
+	// with the other commands run in the process.
+	SharedDiscoveryClients *cmdutil.SharedDiscoveryClients
 
 	genericiooptions.IOStreams
 }
@@ -111,6 +129,14 @@ func NewDefaultKubectlCommand() *cobra.Command {
 func NewDefaultKubectlCommandWithArgs(o KubectlOptions) *cobra.Command {
 	cmd := NewKubectlCommand(o)
 
+	if len(o.Arguments) > 1 {
+		args := expandAliases(cmd, o.Arguments[1:])
+		if !slices.Equal(args, o.Arguments[1:]) {
+			o.Arguments = append([]string{o.Arguments[0]}, args...)
+			cmd.SetArgs(args)
+		}
+	}
+
 	if o.PluginHandler == nil {
 		return cmd
 	}
@@ -330,12 +356,18 @@ func NewKubectlCommand(o KubectlOptions) *cobra.Command {
 				plugin.SetupPluginCompletion(cmd, args)
 			}
 
+			if err := initRequestProfiling(cmd.CommandPath()); err != nil {
+				return err
+			}
 			return initProfiling()
 		},
 		PersistentPostRunE: func(*cobra.Command, []string) error {
 			if err := flushProfiling(); err != nil {
 				return err
 			}
+			if err := flushRequestProfiling(o.IOStreams.ErrOut); err != nil {
+				return err
+			}
 			if warningsAsErrors {
 				count := warningHandler.WarningCount()
 				switch count {
@@ -357,20 +389,40 @@ func NewKubectlCommand(o KubectlOptions) *cobra.Command {
 	flags := cmds.PersistentFlags()
 
 	addProfilingFlags(flags)
+	addRequestProfilingFlags(flags)
+	addImpersonationFlags(flags)
+	addRetryFlags(flags)
+	addRequestPriorityFlags(flags)
 
 	flags.BoolVar(&warningsAsErrors, "warnings-as-errors", warningsAsErrors, "Treat warnings received from the server as errors and exit with a non-zero exit code")
+	timeformat.AddFlags(flags)
+	color.AddFlags(flags)
+	cmdutil.AddStreamProtocolFlags(flags)
 
 	kubeConfigFlags := o.ConfigFlags
 	if kubeConfigFlags == nil {
 		kubeConfigFlags = defaultConfigFlags().WithWarningPrinter(o.IOStreams)
 	}
 	kubeConfigFlags.AddFlags(flags)
-	matchVersionKubeConfigFlags := cmdutil.NewMatchVersionFlags(kubeConfigFlags)
+	discoveryCacheFlags := cmdutil.NewDiscoveryCacheFlags(kubeConfigFlags, o.IOStreams)
+	discoveryCacheFlags.Shared = o.SharedDiscoveryClients
+	discoveryCacheFlags.AddFlags(flags)
+	addDiscoveryCacheHooks(cmds, discoveryCacheFlags)
+	localClusterFlags := cmdutil.NewLocalClusterFlags(discoveryCacheFlags)
+	localClusterFlags.AddFlags(flags)
+	matchVersionKubeConfigFlags := cmdutil.NewMatchVersionFlags(localClusterFlags)
 	matchVersionKubeConfigFlags.AddFlags(flags)
 	// Updates hooks to add kubectl command headers: SIG CLI KEP 859.
 	addCmdHeaderHooks(cmds, kubeConfigFlags)
+	addRequestProfilingHooks(kubeConfigFlags)
+	addImpersonationHooks(cmds, kubeConfigFlags, o.IOStreams)
+	addRetryHooks(cmds, kubeConfigFlags)
+	addRequestPriorityHooks(cmds, kubeConfigFlags)
+	addProtectedContextHooks(cmds, kubeConfigFlags, o.IOStreams)
 
 	f := cmdutil.NewFactory(matchVersionKubeConfigFlags)
+	addOutputTemplateHooks(cmds, f)
+	setColorThemeLoader(f)
 
 	// Proxy command is incompatible with CommandHeaderRoundTripper, so
 	// clear the WrapConfigFn before running proxy command.
@@ -400,13 +452,16 @@ func NewKubectlCommand(o KubectlOptions) *cobra.Command {
 				getCmd,
 				edit.NewCmdEdit(f, o.IOStreams),
 				delete.NewCmdDelete(f, o.IOStreams),
+				restore.NewCmdRestore(f, o.IOStreams),
 			},
 		},
 		{
 			Message: "Deploy Commands:",
 			Commands: []*cobra.Command{
 				rollout.NewCmdRollout(f, o.IOStreams),
+				status.NewCmdStatus(f, o.IOStreams),
 				scale.NewCmdScale(f, o.IOStreams),
+				resize.NewCmdResize(f, o.IOStreams),
 				autoscale.NewCmdAutoscale(f, o.IOStreams),
 			},
 		},
@@ -416,6 +471,8 @@ func NewKubectlCommand(o KubectlOptions) *cobra.Command {
 				certificates.NewCmdCertificate(f, o.IOStreams),
 				clusterinfo.NewCmdClusterInfo(f, o.IOStreams),
 				top.NewCmdTop(f, o.IOStreams),
+				recommend.NewCmdRecommend(f, o.IOStreams),
+				images.NewCmdImages(f, o.IOStreams),
 				drain.NewCmdCordon(f, o.IOStreams),
 				drain.NewCmdUncordon(f, o.IOStreams),
 				drain.NewCmdDrain(f, o.IOStreams),
@@ -426,6 +483,8 @@ func NewKubectlCommand(o KubectlOptions) *cobra.Command {
 			Message: "Troubleshooting and Debugging Commands:",
 			Commands: []*cobra.Command{
 				describe.NewCmdDescribe("kubectl", f, o.IOStreams),
+				tree.NewCmdTree(f, o.IOStreams),
+				graph.NewCmdGraph(f, o.IOStreams),
 				logs.NewCmdLogs(f, o.IOStreams),
 				attach.NewCmdAttach(f, o.IOStreams),
 				cmdexec.NewCmdExec(f, o.IOStreams),
@@ -435,17 +494,20 @@ func NewKubectlCommand(o KubectlOptions) *cobra.Command {
 				auth.NewCmdAuth(f, o.IOStreams),
 				debug.NewCmdDebug(f, o.IOStreams),
 				events.NewCmdEvents(f, o.IOStreams),
+				namespace.NewCmdNamespace(f, o.IOStreams),
 			},
 		},
 		{
 			Message: "Advanced Commands:",
 			Commands: []*cobra.Command{
 				diff.NewCmdDiff(f, o.IOStreams),
+				preview.NewCmdPreview(f, o.IOStreams),
 				apply.NewCmdApply("kubectl", f, o.IOStreams),
 				patch.NewCmdPatch(f, o.IOStreams),
 				replace.NewCmdReplace(f, o.IOStreams),
 				wait.NewCmdWait(f, o.IOStreams),
 				kustomize.NewCmdKustomize(o.IOStreams),
+				render.NewCmdRender(o.IOStreams),
 			},
 		},
 		{
@@ -481,10 +543,32 @@ func NewKubectlCommand(o KubectlOptions) *cobra.Command {
 	cmds.AddCommand(alpha)
 	cmds.AddCommand(cmdconfig.NewCmdConfig(clientcmd.NewDefaultPathOptions(), o.IOStreams))
 	cmds.AddCommand(plugin.NewCmdPlugin(o.IOStreams))
+	cmds.AddCommand(alias.NewCmdAlias(f, o.IOStreams))
 	cmds.AddCommand(version.NewCmdVersion(f, o.IOStreams))
 	cmds.AddCommand(apiresources.NewCmdAPIVersions(f, o.IOStreams))
 	cmds.AddCommand(apiresources.NewCmdAPIResources(f, o.IOStreams))
 	cmds.AddCommand(options.NewCmdOptions(o.IOStreams.Out))
+	cmds.AddCommand(history.NewCmdHistory(o.IOStreams))
+	// the commands run by batch and shell discover the API resources once
+	sharedDiscoveryClients := cmdutil.NewSharedDiscoveryClients()
+	newCommand := func(streams genericiooptions.IOStreams, args []string) *cobra.Command {
+		cmd := NewKubectlCommand(KubectlOptions{
+			Arguments:              append([]string{"kubectl"}, args...),
+			SharedDiscoveryClients: sharedDiscoveryClients,
+			IOStreams:              streams,
+		})
+		cmd.SetArgs(expandAliases(cmd, args))
+		return cmd
+	}
+	cmds.AddCommand(newCmdBatch(newCommand, o.IOStreams))
+	cmds.AddCommand(newCmdShell(newCommand, kubeConfigFlags, o.IOStreams))
+	registerCompletionFuncForSelectorFlags(cmds, f)
+	historyArgs := os.Args[1:]
+	if len(o.Arguments) > 0 {
+		historyArgs = o.Arguments[1:]
+	}
+	addHistoryHooks(cmds, kubeConfigFlags, historyArgs)
+	addRequestProfilingExitHooks(cmds, o.IOStreams.ErrOut)
 
 	// Stop warning about normalization of flags. That makes it possible to
 	// add the klog flags later.
@@ -539,10 +623,56 @@ func addCmdHeaderHooks(cmds *cobra.Command, kubeConfigFlags *genericclioptions.C
 	}
 }
 
+// addDiscoveryCacheHooks waits for the background refresh of the discovery
+// cache, if any, after the command ran.
+func addDiscoveryCacheHooks(cmds *cobra.Command, discoveryCacheFlags *cmdutil.DiscoveryCacheFlags) {
+	existingPostRunE := cmds.PersistentPostRunE
+	cmds.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+		discoveryCacheFlags.WaitForRefresh()
+		return existingPostRunE(cmd, args)
+	}
+}
+
+// addOutputTemplateHooks replaces --output=template:NAME with the output
+// template of that name, stored in the kubeconfig file by
+// "kubectl config set-output-template", before any command runs.
+func addOutputTemplateHooks(cmds *cobra.Command, f cmdutil.Factory) {
+	existingPreRunE := cmds.PersistentPreRunE
+	cmds.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := cmdutil.ResolveOutputTemplate(cmd, f.ToRawKubeConfigLoader()); err != nil {
+			return err
+		}
+		return existingPreRunE(cmd, args)
+	}
+}
+
+// setColorThemeLoader loads the color theme from the kubeconfig file when
+// some output is first colored.
+func setColorThemeLoader(f cmdutil.Factory) {
+	color.SetThemeLoader(func() (color.Theme, error) {
+		config, err := f.ToRawKubeConfigLoader().RawConfig()
+		if err != nil {
+			return nil, err
+		}
+		return cmdutil.LoadColorTheme(&config)
+	})
+}
+
 func runHelp(cmd *cobra.Command, args []string) {
 	cmd.Help()
 }
 
+// registerCompletionFuncForSelectorFlags completes the label selector of
+// the commands with a -l flag, which is defined in the util package.
+func registerCompletionFuncForSelectorFlags(cmd *cobra.Command, f cmdutil.Factory) {
+	if flag := cmd.Flags().Lookup("selector"); flag != nil && flag.Shorthand == "l" {
+		cmdutil.CheckErr(cmd.RegisterFlagCompletionFunc("selector", utilcomp.LabelSelectorCompletionFunc(f)))
+	}
+	for _, c := range cmd.Commands() {
+		registerCompletionFuncForSelectorFlags(c, f)
+	}
+}
+
 func registerCompletionFuncForGlobalFlags(cmd *cobra.Command, f cmdutil.Factory) {
 	cmdutil.CheckErr(cmd.RegisterFlagCompletionFunc(
 		"namespace",
@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/history"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	// maxHistoryRequests is the number of requests recorded per command.
+	maxHistoryRequests = 100
+	redactedArg        = "REDACTED"
+)
+
+// unrecordedCommands are the commands which are not recorded in the history.
+var unrecordedCommands = sets.New(cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd, "completion", "help", "history", "batch", "shell")
+
+// sensitiveFlags are the flags whose values are not recorded in the history.
+var sensitiveFlags = sets.New("token", "password", "docker-password", "from-literal")
+
+// commandRecorder records the command in the history file.
+type commandRecorder struct {
+	filename string
+	now      func() time.Time
+
+	lock     sync.Mutex
+	entry    *history.Entry
+	start    time.Time
+	requests sets.Set[string]
+}
+
+// addHistoryHooks records the commands, run with the arguments commandArgs,
+// their target and their result in the history file when KUBECTL_HISTORY is
+// true.
+func addHistoryHooks(cmds *cobra.Command, kubeConfigFlags *genericclioptions.ConfigFlags, commandArgs []string) {
+	if !history.Enabled() {
+		return
+	}
+	r := &commandRecorder{filename: history.File(), now: time.Now, requests: sets.New[string]()}
+
+	existingPreRunE := cmds.PersistentPreRunE
+	cmds.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if !unrecordedCommands.Has(cmd.Name()) {
+			r.begin(cmd, commandArgs, kubeConfigFlags)
+		}
+		return existingPreRunE(cmd, args)
+	}
+	existingPostRunE := cmds.PersistentPostRunE
+	cmds.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+		err := existingPostRunE(cmd, args)
+		if err != nil {
+			r.end(1, err.Error())
+		} else {
+			r.end(0, "")
+		}
+		return err
+	}
+	cmdutil.WrapBehaviorOnFatal(func(f func(string, int)) func(string, int) {
+		return func(msg string, code int) {
+			r.end(code, msg)
+			f(msg, code)
+		}
+	})
+	wrapRunE(cmds, r)
+
+	wrapConfigFn := kubeConfigFlags.WrapConfigFn
+	kubeConfigFlags.WrapConfigFn = func(c *rest.Config) *rest.Config {
+		if wrapConfigFn != nil {
+			c = wrapConfigFn(c)
+		}
+		c.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &historyRoundTripper{delegate: rt, recorder: r}
+		})
+		return c
+	}
+}
+
+// wrapRunE records the errors returned by the commands, which end them
+// without running the post-run hooks.
+func wrapRunE(cmd *cobra.Command, r *commandRecorder) {
+	if runE := cmd.RunE; runE != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			err := runE(cmd, args)
+			if err != nil {
+				r.end(1, err.Error())
+			}
+			return err
+		}
+	}
+	for _, c := range cmd.Commands() {
+		wrapRunE(c, r)
+	}
+}
+
+func (r *commandRecorder) begin(cmd *cobra.Command, args []string, kubeConfigFlags *genericclioptions.ConfigFlags) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.start = r.now()
+	r.entry = &history.Entry{
+		Time:    r.start,
+		Command: cmd.CommandPath(),
+		Args:    redactArgs(args),
+	}
+
+	loader := kubeConfigFlags.ToRawKubeConfigLoader()
+	if config, err := loader.RawConfig(); err == nil {
+		r.entry.Context = config.CurrentContext
+		if kubeConfigFlags.Context != nil && len(*kubeConfigFlags.Context) > 0 {
+			r.entry.Context = *kubeConfigFlags.Context
+		}
+		clusterName := ""
+		if context, ok := config.Contexts[r.entry.Context]; ok {
+			clusterName = context.Cluster
+		}
+		if kubeConfigFlags.ClusterName != nil && len(*kubeConfigFlags.ClusterName) > 0 {
+			clusterName = *kubeConfigFlags.ClusterName
+		}
+		if cluster, ok := config.Clusters[clusterName]; ok {
+			r.entry.Server = cluster.Server
+		}
+	}
+	if kubeConfigFlags.APIServer != nil && len(*kubeConfigFlags.APIServer) > 0 {
+		r.entry.Server = *kubeConfigFlags.APIServer
+	}
+	if namespace, _, err := loader.Namespace(); err == nil {
+		r.entry.Namespace = namespace
+	}
+}
+
+func (r *commandRecorder) record(req *http.Request) {
+	if isDiscovery(req.URL.Path) {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.entry != nil && r.requests.Len() < maxHistoryRequests {
+		request := req.Method + " " + req.URL.Path
+		if !r.requests.Has(request) {
+			r.requests.Insert(request)
+			r.entry.Requests = append(r.entry.Requests, request)
+		}
+	}
+}
+
+// end records the command with its result, once.
+func (r *commandRecorder) end(exitCode int, msg string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.entry == nil {
+		return
+	}
+	r.entry.Duration = r.now().Sub(r.start).Round(time.Millisecond).String()
+	r.entry.ExitCode = exitCode
+	r.entry.Error = strings.TrimSpace(msg)
+	if err := history.Append(r.filename, r.entry); err != nil {
+		klog.V(2).Infof("failed to record the command in %s: %v", r.filename, err)
+	}
+	r.entry = nil
+}
+
+// redactArgs replaces the values of the sensitive flags.
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 0; i < len(redacted); i++ {
+		if !strings.HasPrefix(redacted[i], "--") {
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(redacted[i], "--"), "=")
+		if !sensitiveFlags.Has(name) {
+			continue
+		}
+		if hasValue {
+			redacted[i] = "--" + name + "=" + redactedArg
+		} else if i+1 < len(redacted) {
+			redacted[i+1] = redactedArg
+			i++
+		}
+	}
+	return redacted
+}
+
+type historyRoundTripper struct {
+	delegate http.RoundTripper
+	recorder *commandRecorder
+}
+
+func (rt *historyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.recorder.record(req)
+	return rt.delegate.RoundTrip(req)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/kubectl/pkg/cmd/history"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func TestRedactArgs(t *testing.T) {
+	args := []string{"create", "secret", "generic", "db", "--from-literal=password=secret", "--token", "abc", "--token=abc", "-o", "yaml", "--password"}
+	expected := []string{"create", "secret", "generic", "db", "--from-literal=REDACTED", "--token", "REDACTED", "--token=REDACTED", "-o", "yaml", "--password"}
+	if got := redactArgs(args); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestCommandRecorder(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	config := `apiVersion: v1
+kind: Config
+current-context: prod
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    namespace: web
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com
+`
+	if err := os.WriteFile(kubeconfig, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	kubeConfigFlags := genericclioptions.NewConfigFlags(false)
+	kubeConfigFlags.KubeConfig = &kubeconfig
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := &commandRecorder{
+		filename: filepath.Join(dir, "history.log"),
+		now: func() time.Time {
+			now = now.Add(time.Second)
+			return now
+		},
+		requests: sets.New[string](),
+	}
+	root := &cobra.Command{Use: "kubectl"}
+	deleteCmd := &cobra.Command{Use: "delete"}
+	root.AddCommand(deleteCmd)
+
+	r.begin(deleteCmd, []string{"delete", "pod", "web"}, kubeConfigFlags)
+	rt := &historyRoundTripper{recorder: r, delegate: fakeRoundTripper(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+	})}
+	for _, path := range []string{"/api", "/api/v1/namespaces/web/pods/web", "/api/v1/namespaces/web/pods/web"} {
+		req, _ := http.NewRequest(http.MethodDelete, "https://prod.example.com"+path, nil)
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.end(1, "error: timed out\n")
+	// the command is only recorded once
+	r.end(0, "")
+
+	entries, err := history.Read(r.filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []history.Entry{{
+		Time:      time.Date(2024, 1, 1, 12, 0, 1, 0, time.UTC),
+		Duration:  "1s",
+		Command:   "kubectl delete",
+		Args:      []string{"delete", "pod", "web"},
+		Context:   "prod",
+		Server:    "https://prod.example.com",
+		Namespace: "web",
+		Requests:  []string{"DELETE /api/v1/namespaces/web/pods/web"},
+		ExitCode:  1,
+		Error:     "error: timed out",
+	}}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %#v, got %#v", expected, entries)
+	}
+}
+
+func TestHistoryHooks(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "history.log")
+	t.Setenv(history.EnvHistory, "true")
+	t.Setenv(history.EnvHistoryFile, filename)
+	defer cmdutil.DefaultBehaviorOnFatal()
+
+	noop := func(*cobra.Command, []string) error { return nil }
+	root := &cobra.Command{Use: "kubectl", PersistentPreRunE: noop, PersistentPostRunE: noop}
+	deleteCmd := &cobra.Command{Use: "delete", Run: func(*cobra.Command, []string) {}}
+	deleteCmd.Flags().Bool("now", false, "")
+	root.AddCommand(deleteCmd)
+
+	// the command line is recorded, rather than the arguments of the command
+	commandArgs := []string{"delete", "pod", "web", "--now"}
+	addHistoryHooks(root, genericclioptions.NewConfigFlags(false), commandArgs)
+	root.SetArgs(commandArgs)
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := history.Read(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Command != "kubectl delete" || !reflect.DeepEqual(entries[0].Args, commandArgs) {
+		t.Errorf("expected %v to be recorded, got %#v", commandArgs, entries)
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	certutil "k8s.io/client-go/util/cert"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	credentialOK          = "OK"
+	credentialExpiring    = "Expiring"
+	credentialExpired     = "Expired"
+	credentialInvalid     = "Invalid"
+	credentialUnreachable = "Unreachable"
+	// credentialUnknown is reported when the expiry can not be known without
+	// using the credential, such as for opaque tokens.
+	credentialUnknown = "Unknown"
+)
+
+// CheckCredentialsOptions holds the command-line options for 'config check-credentials' sub command
+type CheckCredentialsOptions struct {
+	configAccess clientcmd.ConfigAccess
+	userNames    []string
+	outputFormat string
+	noHeaders    bool
+	warnWithin   time.Duration
+
+	now      func() time.Time
+	lookPath func(string) (string, error)
+
+	genericiooptions.IOStreams
+}
+
+// credentialReport describes the credentials of a user entry.
+type credentialReport struct {
+	User    string     `json:"user"`
+	Type    string     `json:"type"`
+	Expires *time.Time `json:"expires,omitempty"`
+	Status  string     `json:"status"`
+	Message string     `json:"message,omitempty"`
+}
+
+var (
+	checkCredentialsLong = templates.LongDesc(i18n.T(`
+		Check the credentials of the users in the kubeconfig file.
+
+		The expiry of client certificates and of JSON web tokens is reported, and
+		credentials expiring within --warn-within are flagged. The commands of exec
+		plugins are looked up but not run. The credentials are not sent to any server.
+
+		The command fails when a credential is expired, invalid or unreachable.`))
+
+	checkCredentialsExample = templates.Examples(`
+		# Check the credentials of all the users
+		kubectl config check-credentials
+
+		# Check the credentials of the user admin, warning about expiry within 30 days
+		kubectl config check-credentials admin --warn-within=720h
+
+		# Check the credentials for automation
+		kubectl config check-credentials -o json`)
+)
+
+// NewCmdConfigCheckCredentials returns a Command instance for 'config check-credentials' sub command
+func NewCmdConfigCheckCredentials(streams genericiooptions.IOStreams, configAccess clientcmd.ConfigAccess) *cobra.Command {
+	o := &CheckCredentialsOptions{
+		configAccess: configAccess,
+		warnWithin:   7 * 24 * time.Hour,
+		now:          time.Now,
+		lookPath:     exec.LookPath,
+		IOStreams:    streams,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "check-credentials [USER_NAME...] [-o json]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Check the expiry of the credentials of the users in the kubeconfig file"),
+		Long:                  checkCredentialsLong,
+		Example:               checkCredentialsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.noHeaders, "no-headers", o.noHeaders, "When using the default output format, don't print headers (default print headers).")
+	cmd.Flags().StringVarP(&o.outputFormat, "output", "o", o.outputFormat, `Output format. One of: (json).`)
+	cmd.Flags().DurationVar(&o.warnWithin, "warn-within", o.warnWithin, "Report the credentials expiring within this duration as expiring.")
+	return cmd
+}
+
+// Complete completes the required command-line options
+func (o *CheckCredentialsOptions) Complete(args []string) error {
+	o.userNames = args
+	return nil
+}
+
+// Validate makes sure that provided values for command-line options are valid
+func (o *CheckCredentialsOptions) Validate() error {
+	if o.outputFormat != "" && o.outputFormat != "json" {
+		return fmt.Errorf("--output %v is not available in kubectl config check-credentials", o.outputFormat)
+	}
+	if o.warnWithin < 0 {
+		return fmt.Errorf("--warn-within must not be negative")
+	}
+	return nil
+}
+
+// Run performs the execution of 'config check-credentials' sub command
+func (o *CheckCredentialsOptions) Run() error {
+	config, err := o.configAccess.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+
+	names := o.userNames
+	if len(names) == 0 {
+		for name := range config.AuthInfos {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	reports := []credentialReport{}
+	for _, name := range names {
+		authInfo, ok := config.AuthInfos[name]
+		if !ok {
+			return fmt.Errorf("no user exists with the name: %q", name)
+		}
+		reports = append(reports, o.checkAuthInfo(name, authInfo)...)
+	}
+
+	if o.outputFormat == "json" {
+		data, err := json.MarshalIndent(reports, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+	} else {
+		w := printers.GetNewTabWriter(o.Out)
+		if !o.noHeaders {
+			fmt.Fprintln(w, "USER\tTYPE\tEXPIRES\tSTATUS\tMESSAGE")
+		}
+		for _, r := range reports {
+			expires := "<unknown>"
+			if r.Expires != nil {
+				expires = r.Expires.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.User, r.Type, expires, r.Status, r.Message)
+		}
+		w.Flush()
+	}
+
+	failed := 0
+	for _, r := range reports {
+		switch r.Status {
+		case credentialExpired, credentialInvalid, credentialUnreachable:
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d credentials are expired, invalid or unreachable", failed, len(reports))
+	}
+	return nil
+}
+
+// checkAuthInfo returns a report for each kind of credential of the user.
+func (o *CheckCredentialsOptions) checkAuthInfo(name string, authInfo *clientcmdapi.AuthInfo) []credentialReport {
+	reports := []credentialReport{}
+	if len(authInfo.ClientCertificate) > 0 || len(authInfo.ClientCertificateData) > 0 {
+		reports = append(reports, o.checkClientCertificate(name, authInfo))
+	}
+
+	if len(authInfo.Token) > 0 || len(authInfo.TokenFile) > 0 {
+		reports = append(reports, o.checkToken(name, authInfo))
+	}
+
+	if authInfo.Exec != nil {
+		r := credentialReport{User: name, Type: "exec", Status: credentialUnknown, Message: "the plugin decides the expiry"}
+		if _, err := o.lookPath(authInfo.Exec.Command); err != nil {
+			r.Status, r.Message = credentialUnreachable, err.Error()
+			if len(authInfo.Exec.InstallHint) > 0 {
+				r.Message += ": " + strings.TrimSpace(authInfo.Exec.InstallHint)
+			}
+		}
+		reports = append(reports, r)
+	}
+
+	if authInfo.AuthProvider != nil {
+		reports = append(reports, credentialReport{User: name, Type: "auth-provider", Status: credentialUnknown, Message: "the " + authInfo.AuthProvider.Name + " provider decides the expiry"})
+	}
+
+	if len(authInfo.Username) > 0 {
+		reports = append(reports, credentialReport{User: name, Type: "basic", Status: credentialUnknown, Message: "passwords do not expire"})
+	}
+
+	if len(reports) == 0 {
+		reports = append(reports, credentialReport{User: name, Type: "none", Status: credentialUnknown, Message: "no credentials"})
+	}
+	return reports
+}
+
+func (o *CheckCredentialsOptions) checkClientCertificate(name string, authInfo *clientcmdapi.AuthInfo) credentialReport {
+	r := credentialReport{User: name, Type: "client-certificate"}
+	data := authInfo.ClientCertificateData
+	if len(data) == 0 {
+		var err error
+		if data, err = os.ReadFile(authInfo.ClientCertificate); err != nil {
+			r.Status, r.Message = credentialInvalid, err.Error()
+			return r
+		}
+	}
+	certs, err := certutil.ParseCertsPEM(data)
+	if err != nil {
+		r.Status, r.Message = credentialInvalid, err.Error()
+		return r
+	}
+	o.setExpiry(&r, certs[0].NotAfter)
+	return r
+}
+
+func (o *CheckCredentialsOptions) checkToken(name string, authInfo *clientcmdapi.AuthInfo) credentialReport {
+	r := credentialReport{User: name, Type: "token"}
+	token := authInfo.Token
+	if len(token) == 0 {
+		data, err := os.ReadFile(authInfo.TokenFile)
+		if err != nil {
+			r.Status, r.Message = credentialInvalid, err.Error()
+			return r
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	expiry, ok := jwtExpiry(token)
+	if !ok {
+		r.Status, r.Message = credentialUnknown, "the token is not a JSON web token with an expiry"
+		return r
+	}
+	o.setExpiry(&r, expiry)
+	return r
+}
+
+func (o *CheckCredentialsOptions) setExpiry(r *credentialReport, expiry time.Time) {
+	r.Expires = &expiry
+	remaining := expiry.Sub(o.now())
+	switch {
+	case remaining <= 0:
+		r.Status = credentialExpired
+	case remaining <= o.warnWithin:
+		r.Status = credentialExpiring
+		r.Message = fmt.Sprintf("expires in %s", remaining.Round(time.Minute))
+	default:
+		r.Status = credentialOK
+	}
+}
+
+// jwtExpiry returns the exp claim of a JSON web token, without verifying
+// the token.
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	claims := struct {
+		Exp *int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(*claims.Exp, 0), true
+}
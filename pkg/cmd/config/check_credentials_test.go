@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestCheckCredentials(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	jwt := func(exp time.Time) string {
+		payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin","exp":` + big.NewInt(exp.Unix()).String() + `}`))
+		return "eyJhbGciOiJSUzI1NiJ9." + payload + ".signature"
+	}
+
+	conf := clientcmdapi.Config{
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"cert":     {ClientCertificateData: makeCertificate(t, now.Add(90*24*time.Hour))},
+			"expiring": {Token: jwt(now.Add(48 * time.Hour))},
+			"expired":  {Token: jwt(now.Add(-time.Hour))},
+			"opaque":   {Token: "abcdef"},
+			"plugin":   {Exec: &clientcmdapi.ExecConfig{Command: "missing-plugin", InstallHint: "install it"}},
+		},
+	}
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := clientcmd.WriteToFile(conf, kubeconfig); err != nil {
+		t.Fatal(err)
+	}
+	pathOptions := clientcmd.NewDefaultPathOptions()
+	pathOptions.GlobalFile = kubeconfig
+	pathOptions.EnvVar = ""
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	o := &CheckCredentialsOptions{
+		configAccess: pathOptions,
+		warnWithin:   7 * 24 * time.Hour,
+		now:          func() time.Time { return now },
+		lookPath: func(command string) (string, error) {
+			return "", errors.New(`exec: "` + command + `": executable file not found in $PATH`)
+		},
+		IOStreams: streams,
+	}
+	err := o.Run()
+	if err == nil || err.Error() != "2 of 5 credentials are expired, invalid or unreachable" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	expected := `USER       TYPE                 EXPIRES                STATUS        MESSAGE
+cert       client-certificate   2024-07-30T00:00:00Z   OK            
+expired    token                2024-04-30T23:00:00Z   Expired       
+expiring   token                2024-05-03T00:00:00Z   Expiring      expires in 48h0m0s
+opaque     token                <unknown>              Unknown       the token is not a JSON web token with an expiry
+plugin     exec                 <unknown>              Unreachable   exec: "missing-plugin": executable file not found in $PATH: install it
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	o.userNames = []string{"cert"}
+	o.outputFormat = "json"
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected = `[
+    {
+        "user": "cert",
+        "type": "client-certificate",
+        "expires": "2024-07-30T00:00:00Z",
+        "status": "OK"
+    }
+]
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func makeCertificate(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "admin"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -63,6 +63,9 @@ func NewCmdConfig(pathOptions *clientcmd.PathOptions, streams genericiooptions.I
 	cmd.AddCommand(NewCmdConfigUnset(streams.Out, pathOptions))
 	cmd.AddCommand(NewCmdConfigCurrentContext(streams.Out, pathOptions))
 	cmd.AddCommand(NewCmdConfigUseContext(streams.Out, pathOptions))
+	cmd.AddCommand(NewCmdConfigUseGroup(streams.Out, pathOptions))
+	cmd.AddCommand(NewCmdConfigSetGroup(streams.Out, pathOptions))
+	cmd.AddCommand(NewCmdConfigSetOutputTemplate(streams.Out, pathOptions))
 	cmd.AddCommand(NewCmdConfigGetContexts(streams, pathOptions))
 	cmd.AddCommand(NewCmdConfigGetClusters(streams.Out, pathOptions))
 	cmd.AddCommand(NewCmdConfigGetUsers(streams, pathOptions))
@@ -70,6 +73,10 @@ func NewCmdConfig(pathOptions *clientcmd.PathOptions, streams genericiooptions.I
 	cmd.AddCommand(NewCmdConfigDeleteContext(streams.Out, streams.ErrOut, pathOptions))
 	cmd.AddCommand(NewCmdConfigDeleteUser(streams, pathOptions))
 	cmd.AddCommand(NewCmdConfigRenameContext(streams.Out, pathOptions))
+	cmd.AddCommand(NewCmdConfigMerge(streams))
+	cmd.AddCommand(NewCmdConfigExtract(streams, pathOptions))
+	cmd.AddCommand(NewCmdConfigCheckCredentials(streams, pathOptions))
+	cmd.AddCommand(NewCmdConfigExecCredentials(streams, pathOptions))
 
 	return cmd
 }
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// contextGroupsExtension is the context extension holding the list of
+	// groups the context belongs to.
+	contextGroupsExtension = "kubectl.kubernetes.io/context-groups"
+	// currentGroupExtension is the preferences extension holding the name
+	// of the group selected by use-group. Preferences are used since they are
+	// written to the kubeconfig file like the current context.
+	currentGroupExtension = "kubectl.kubernetes.io/current-context-group"
+)
+
+// contextGroups returns the groups of the context, sorted.
+func contextGroups(context *clientcmdapi.Context) []string {
+	groups := []string{}
+	if err := decodeExtension(context.Extensions, contextGroupsExtension, &groups); err != nil {
+		return []string{}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// setContextGroups stores the groups of the context, removing the extension
+// when there are none left.
+func setContextGroups(context *clientcmdapi.Context, groups sets.Set[string]) error {
+	if groups.Len() == 0 {
+		delete(context.Extensions, contextGroupsExtension)
+		return nil
+	}
+	if context.Extensions == nil {
+		context.Extensions = map[string]runtime.Object{}
+	}
+	return encodeExtension(context.Extensions, contextGroupsExtension, sets.List(groups))
+}
+
+// contextsInGroup returns the names of the contexts in the group, sorted.
+func contextsInGroup(config *clientcmdapi.Config, group string) []string {
+	names := []string{}
+	for name, context := range config.Contexts {
+		if sets.New(contextGroups(context)...).Has(group) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// currentGroup returns the group selected by use-group, if any.
+func currentGroup(config *clientcmdapi.Config) string {
+	group := ""
+	if err := decodeExtension(config.Preferences.Extensions, currentGroupExtension, &group); err != nil {
+		return ""
+	}
+	return group
+}
+
+func setCurrentGroup(config *clientcmdapi.Config, group string) error {
+	if config.Preferences.Extensions == nil {
+		config.Preferences.Extensions = map[string]runtime.Object{}
+	}
+	return encodeExtension(config.Preferences.Extensions, currentGroupExtension, group)
+}
+
+func decodeExtension(extensions map[string]runtime.Object, name string, into interface{}) error {
+	extension, ok := extensions[name].(*runtime.Unknown)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(extension.Raw, into)
+}
+
+func encodeExtension(extensions map[string]runtime.Object, name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	extensions[name] = &runtime.Unknown{Raw: data, ContentType: runtime.ContentTypeJSON}
+	return nil
+}
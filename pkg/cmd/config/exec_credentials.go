@@ -0,0 +1,325 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	clientauthenticationv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	certutil "k8s.io/client-go/util/cert"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"k8s.io/kubectl/pkg/util/term"
+)
+
+const (
+	// execInfoEnv is the environment variable the exec plugins read their input from.
+	execInfoEnv = "KUBERNETES_EXEC_INFO"
+	// execClusterConfigExtension is the extension of the clusters passed to the exec plugins.
+	execClusterConfigExtension = "client.authentication.k8s.io/exec"
+)
+
+// TestExecCredentialsOptions holds the command-line options for 'config exec-credentials test' sub command
+type TestExecCredentialsOptions struct {
+	configAccess clientcmd.ConfigAccess
+	userName     string
+	timeout      time.Duration
+
+	now func() time.Time
+
+	genericiooptions.IOStreams
+}
+
+var (
+	testExecCredentialsLong = templates.LongDesc(i18n.T(`
+		Run the exec credential plugin of a user of the kubeconfig file, and check
+		the credential it returns.
+
+		The plugin is run as kubectl runs it, with the cluster information when it is
+		requested, and its error output is shown. The command reports how long the
+		plugin took, the kind of credential returned and its expiry, and fails when the
+		plugin can not be run, fails, or returns an invalid or expired credential. The
+		credential itself is not printed.
+
+		The user of the current context is tested by default.`))
+
+	testExecCredentialsExample = templates.Examples(`
+		# Test the exec plugin of the user of the current context
+		kubectl config exec-credentials test
+
+		# Test the exec plugin of the user eks-admin
+		kubectl config exec-credentials test eks-admin`)
+)
+
+// NewCmdConfigExecCredentials returns a Command instance for 'config exec-credentials' sub command
+func NewCmdConfigExecCredentials(streams genericiooptions.IOStreams, configAccess clientcmd.ConfigAccess) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "exec-credentials SUBCOMMAND",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Debug the exec credential plugins of the users in the kubeconfig file"),
+		Run:                   cmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmd.AddCommand(NewCmdConfigExecCredentialsTest(streams, configAccess))
+	return cmd
+}
+
+// NewCmdConfigExecCredentialsTest returns a Command instance for 'config exec-credentials test' sub command
+func NewCmdConfigExecCredentialsTest(streams genericiooptions.IOStreams, configAccess clientcmd.ConfigAccess) *cobra.Command {
+	o := &TestExecCredentialsOptions{
+		configAccess: configAccess,
+		timeout:      time.Minute,
+		now:          time.Now,
+		IOStreams:    streams,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "test [USER_NAME]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Run the exec credential plugin of a user and check the credential returned"),
+		Long:                  testExecCredentialsLong,
+		Example:               testExecCredentialsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().DurationVar(&o.timeout, "timeout", o.timeout, "The time to wait for the plugin to return a credential.")
+	return cmd
+}
+
+// Complete completes the required command-line options
+func (o *TestExecCredentialsOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) > 1 {
+		return cmdutil.UsageErrorf(cmd, "at most one user name is allowed, got %d", len(args))
+	}
+	if len(args) == 1 {
+		o.userName = args[0]
+	}
+	return nil
+}
+
+// Run performs the execution of 'config exec-credentials test' sub command
+func (o *TestExecCredentialsOptions) Run() error {
+	config, err := o.configAccess.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+	if len(o.userName) == 0 {
+		context, ok := config.Contexts[config.CurrentContext]
+		if !ok {
+			return errors.New("no user name given and no current context is set")
+		}
+		o.userName = context.AuthInfo
+	}
+	authInfo, ok := config.AuthInfos[o.userName]
+	if !ok {
+		return fmt.Errorf("no user exists with the name: %q", o.userName)
+	}
+	if authInfo.Exec == nil {
+		return fmt.Errorf("the user %q does not use an exec credential plugin", o.userName)
+	}
+	execConfig := authInfo.Exec
+
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+	fmt.Fprintf(w, "User:\t%s\n", o.userName)
+	fmt.Fprintf(w, "Command:\t%s\n", strings.Join(append([]string{execConfig.Command}, execConfig.Args...), " "))
+	fmt.Fprintf(w, "API version:\t%s\n", execConfig.APIVersion)
+
+	path, err := exec.LookPath(execConfig.Command)
+	if err != nil {
+		if len(execConfig.InstallHint) > 0 {
+			return fmt.Errorf("%v\n%s", err, strings.TrimSpace(execConfig.InstallHint))
+		}
+		return err
+	}
+	input, interactive, err := o.execInput(config, execConfig)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+	plugin := exec.CommandContext(ctx, path, execConfig.Args...)
+	plugin.Env = append(os.Environ(), execInfoEnv+"="+input)
+	for _, env := range execConfig.Env {
+		plugin.Env = append(plugin.Env, env.Name+"="+env.Value)
+	}
+	if interactive {
+		plugin.Stdin = o.In
+	}
+	stdout := &bytes.Buffer{}
+	plugin.Stdout = stdout
+	plugin.Stderr = o.ErrOut
+
+	start := o.now()
+	err = plugin.Run()
+	fmt.Fprintf(w, "Latency:\t%s\n", o.now().Sub(start).Round(time.Millisecond))
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("the plugin did not return within %s", o.timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("the plugin failed: %v", err)
+	}
+
+	credential := &clientauthenticationv1.ExecCredential{}
+	if err := json.Unmarshal(stdout.Bytes(), credential); err != nil {
+		return fmt.Errorf("the plugin did not return an ExecCredential object: %v", err)
+	}
+	if credential.APIVersion != execConfig.APIVersion || credential.Kind != "ExecCredential" {
+		return fmt.Errorf("the plugin returned a %s %s object, expected a %s ExecCredential object", credential.APIVersion, credential.Kind, execConfig.APIVersion)
+	}
+	status := credential.Status
+	if status == nil {
+		return errors.New("the plugin returned no credential in the status of the ExecCredential object")
+	}
+
+	var expiry time.Time
+	if status.ExpirationTimestamp != nil {
+		expiry = status.ExpirationTimestamp.Time
+	}
+	switch {
+	case len(status.Token) > 0:
+		fmt.Fprintf(w, "Credential:\ttoken\n")
+	case len(status.ClientCertificateData) > 0 && len(status.ClientKeyData) > 0:
+		fmt.Fprintf(w, "Credential:\tclient certificate\n")
+		certs, err := certutil.ParseCertsPEM([]byte(status.ClientCertificateData))
+		if err != nil {
+			return fmt.Errorf("the plugin returned an invalid client certificate: %v", err)
+		}
+		if expiry.IsZero() || certs[0].NotAfter.Before(expiry) {
+			expiry = certs[0].NotAfter
+		}
+	case len(status.ClientCertificateData) > 0 || len(status.ClientKeyData) > 0:
+		return errors.New("the plugin returned a client certificate without its key, or a key without its certificate")
+	default:
+		return errors.New("the plugin returned neither a token nor a client certificate")
+	}
+
+	if expiry.IsZero() {
+		fmt.Fprintf(w, "Expires:\t<none>, the credential is used until it is rejected\n")
+		return nil
+	}
+	remaining := expiry.Sub(o.now())
+	fmt.Fprintf(w, "Expires:\t%s (in %s)\n", expiry.UTC().Format(time.RFC3339), remaining.Round(time.Second))
+	if remaining <= 0 {
+		return errors.New("the plugin returned an expired credential")
+	}
+	return nil
+}
+
+// execInput returns the ExecCredential object passed to the plugin, and
+// whether the plugin can interact with the user.
+func (o *TestExecCredentialsOptions) execInput(config *clientcmdapi.Config, execConfig *clientcmdapi.ExecConfig) (string, bool, error) {
+	interactive := false
+	switch execConfig.InteractiveMode {
+	case clientcmdapi.AlwaysExecInteractiveMode:
+		if !term.IsTerminal(o.In) {
+			return "", false, errors.New("the plugin requires a terminal as standard input")
+		}
+		interactive = true
+	case clientcmdapi.IfAvailableExecInteractiveMode, "":
+		interactive = term.IsTerminal(o.In)
+	}
+
+	credential := &clientauthenticationv1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{APIVersion: execConfig.APIVersion, Kind: "ExecCredential"},
+		Spec:     clientauthenticationv1.ExecCredentialSpec{Interactive: interactive},
+	}
+	if execConfig.ProvideClusterInfo {
+		cluster, err := o.execCluster(config)
+		if err != nil {
+			return "", false, err
+		}
+		credential.Spec.Cluster = cluster
+	}
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), interactive, nil
+}
+
+// execCluster returns the cluster of the current context when it uses the
+// user, else of the first context using it.
+func (o *TestExecCredentialsOptions) execCluster(config *clientcmdapi.Config) (*clientauthenticationv1.Cluster, error) {
+	contextName := config.CurrentContext
+	if context, ok := config.Contexts[contextName]; !ok || context.AuthInfo != o.userName {
+		contextName = ""
+		names := []string{}
+		for name, context := range config.Contexts {
+			if context.AuthInfo == o.userName {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("the plugin requires the cluster information, but no context uses the user %q", o.userName)
+		}
+		contextName = names[0]
+	}
+	cluster, ok := config.Clusters[config.Contexts[contextName].Cluster]
+	if !ok {
+		return nil, fmt.Errorf("the cluster of the context %q does not exist", contextName)
+	}
+
+	execCluster := &clientauthenticationv1.Cluster{
+		Server:                   cluster.Server,
+		TLSServerName:            cluster.TLSServerName,
+		InsecureSkipTLSVerify:    cluster.InsecureSkipTLSVerify,
+		CertificateAuthorityData: cluster.CertificateAuthorityData,
+		ProxyURL:                 cluster.ProxyURL,
+		DisableCompression:       cluster.DisableCompression,
+	}
+	if len(execCluster.CertificateAuthorityData) == 0 && len(cluster.CertificateAuthority) > 0 {
+		data, err := os.ReadFile(cluster.CertificateAuthority)
+		if err != nil {
+			return nil, err
+		}
+		execCluster.CertificateAuthorityData = data
+	}
+	if extension, ok := cluster.Extensions[execClusterConfigExtension]; ok {
+		data, err := json.Marshal(extension)
+		if unknown, isUnknown := extension.(*runtime.Unknown); isUnknown {
+			data, err = unknown.Raw, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		execCluster.Config = runtime.RawExtension{Raw: data}
+	}
+	return execCluster, nil
+}
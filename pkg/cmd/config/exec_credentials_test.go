@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestExecCredentialsTest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugins are shell scripts")
+	}
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	plugin := func(name, script string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	certificate, err := json.Marshal(string(makeCertificate(t, now.Add(24*time.Hour))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	execInfo := filepath.Join(dir, "exec-info")
+
+	conf := clientcmdapi.Config{
+		CurrentContext: "prod",
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"prod": {Server: "https://prod.example.com"},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"prod": {Cluster: "prod", AuthInfo: "token"},
+			"dev":  {Cluster: "prod", AuthInfo: "cert"},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"token": {Exec: &clientcmdapi.ExecConfig{
+				APIVersion:         "client.authentication.k8s.io/v1beta1",
+				Command:            plugin("token", `echo "$KUBERNETES_EXEC_INFO" > `+execInfo+`; echo '{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential","status":{"token":"abc","expirationTimestamp":"2024-05-01T01:00:00Z"}}'`),
+				Args:               []string{"--region", "eu"},
+				ProvideClusterInfo: true,
+			}},
+			"cert": {Exec: &clientcmdapi.ExecConfig{
+				APIVersion: "client.authentication.k8s.io/v1",
+				Command:    plugin("cert", `printf '%s\n' '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"clientCertificateData":`+string(certificate)+`,"clientKeyData":"key"}}'`),
+			}},
+			"expired": {Exec: &clientcmdapi.ExecConfig{
+				APIVersion: "client.authentication.k8s.io/v1",
+				Command:    plugin("expired", `echo '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"abc","expirationTimestamp":"2024-04-30T00:00:00Z"}}'`),
+			}},
+			"version": {Exec: &clientcmdapi.ExecConfig{
+				APIVersion: "client.authentication.k8s.io/v1",
+				Command:    plugin("version", `echo '{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential","status":{"token":"abc"}}'`),
+			}},
+			"failing": {Exec: &clientcmdapi.ExecConfig{
+				APIVersion: "client.authentication.k8s.io/v1",
+				Command:    plugin("failing", `echo "not logged in" >&2; exit 3`),
+			}},
+			"missing": {Exec: &clientcmdapi.ExecConfig{
+				APIVersion:  "client.authentication.k8s.io/v1",
+				Command:     filepath.Join(dir, "missing"),
+				InstallHint: "install the plugin",
+			}},
+			"static": {Token: "abc"},
+		},
+	}
+	kubeconfig := filepath.Join(dir, "config")
+	if err := clientcmd.WriteToFile(conf, kubeconfig); err != nil {
+		t.Fatal(err)
+	}
+	pathOptions := clientcmd.NewDefaultPathOptions()
+	pathOptions.GlobalFile = kubeconfig
+	pathOptions.EnvVar = ""
+
+	tests := []struct {
+		userName       string
+		expectedOut    string
+		expectedErrOut string
+		expectedErr    string
+	}{
+		{
+			expectedOut: `User:          token
+Command:       ` + dir + `/token --region eu
+API version:   client.authentication.k8s.io/v1beta1
+Latency:       0s
+Credential:    token
+Expires:       2024-05-01T01:00:00Z (in 1h0m0s)
+`,
+		},
+		{
+			userName: "cert",
+			expectedOut: `User:          cert
+Command:       ` + dir + `/cert
+API version:   client.authentication.k8s.io/v1
+Latency:       0s
+Credential:    client certificate
+Expires:       2024-05-02T00:00:00Z (in 24h0m0s)
+`,
+		},
+		{
+			userName:    "expired",
+			expectedErr: "the plugin returned an expired credential",
+		},
+		{
+			userName:    "version",
+			expectedErr: "the plugin returned a client.authentication.k8s.io/v1beta1 ExecCredential object, expected a client.authentication.k8s.io/v1 ExecCredential object",
+		},
+		{
+			userName:       "failing",
+			expectedErrOut: "not logged in\n",
+			expectedErr:    "the plugin failed: exit status 3",
+		},
+		{
+			userName:    "missing",
+			expectedErr: `exec: "` + dir + `/missing": stat ` + dir + `/missing: no such file or directory` + "\ninstall the plugin",
+		},
+		{
+			userName:    "static",
+			expectedErr: `the user "static" does not use an exec credential plugin`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.userName, func(t *testing.T) {
+			streams, _, out, errOut := genericiooptions.NewTestIOStreams()
+			o := &TestExecCredentialsOptions{
+				configAccess: pathOptions,
+				userName:     tc.userName,
+				timeout:      time.Minute,
+				now:          func() time.Time { return now },
+				IOStreams:    streams,
+			}
+			err := o.Run()
+			if len(tc.expectedErr) > 0 {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tc.expectedOut) > 0 && out.String() != tc.expectedOut {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expectedOut, out.String())
+			}
+			if errOut.String() != tc.expectedErrOut {
+				t.Errorf("expected error output %q, got %q", tc.expectedErrOut, errOut.String())
+			}
+		})
+	}
+
+	data, err := os.ReadFile(execInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedExecInfo := `{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1beta1","spec":{"cluster":{"server":"https://prod.example.com","config":null},"interactive":false}}`
+	if strings.TrimSpace(string(data)) != expectedExecInfo {
+		t.Errorf("expected the plugin input %s, got %s", expectedExecInfo, data)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/completion"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// ExtractOptions holds the command-line options for 'config extract' sub command
+type ExtractOptions struct {
+	ConfigAccess clientcmd.ConfigAccess
+	ContextName  string
+	OutputFile   string
+	Flatten      bool
+
+	genericiooptions.IOStreams
+}
+
+var (
+	extractLong = templates.LongDesc(i18n.T(`
+		Extract a context into a standalone kubeconfig file.
+
+		The context, its cluster and its user are written to the file, with the context
+		as the current context. Unlike config view --minify, the credentials are not
+		redacted, so that the file can be used as is. Relative paths to certificates
+		and keys are made absolute, or the files are embedded with --flatten.`))
+
+	extractExample = templates.Examples(`
+		# Extract the context prod into prod.yaml
+		kubectl config extract prod -o prod.yaml
+
+		# Extract the context prod into a self-contained file to share with a colleague
+		kubectl config extract prod --flatten -o prod.yaml`)
+)
+
+// NewCmdConfigExtract returns a Command instance for 'config extract' sub command
+func NewCmdConfigExtract(streams genericiooptions.IOStreams, configAccess clientcmd.ConfigAccess) *cobra.Command {
+	o := &ExtractOptions{
+		ConfigAccess: configAccess,
+		IOStreams:    streams,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "extract CONTEXT_NAME [-o OUTPUT_FILE]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Extract a context into a standalone kubeconfig file"),
+		Long:                  extractLong,
+		Example:               extractExample,
+		ValidArgsFunction:     completion.ContextCompletionFunc,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.OutputFile, "output", "o", o.OutputFile, "The file to write the kubeconfig to. Defaults to the standard output.")
+	cmd.Flags().BoolVar(&o.Flatten, "flatten", o.Flatten, "Embed the certificates and keys referenced by the context in the kubeconfig")
+	return cmd
+}
+
+// Complete completes the required command-line options
+func (o *ExtractOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmdutil.UsageErrorf(cmd, "exactly one context name is required, got %d", len(args))
+	}
+	o.ContextName = args[0]
+	return nil
+}
+
+// Run performs the execution of 'config extract' sub command
+func (o *ExtractOptions) Run() error {
+	config, err := o.ConfigAccess.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := config.Contexts[o.ContextName]; !ok {
+		return fmt.Errorf("no context exists with the name: %q", o.ContextName)
+	}
+	if err := clientcmd.ResolveLocalPaths(config); err != nil {
+		return err
+	}
+
+	config.CurrentContext = o.ContextName
+	if err := clientcmdapi.MinifyConfig(config); err != nil {
+		return err
+	}
+	// the preferences and extensions of the file are not part of the context
+	config.Preferences = *clientcmdapi.NewPreferences()
+	config.Extensions = map[string]runtime.Object{}
+	return writeConfig(config, o.Flatten, o.OutputFile, o.Out)
+}
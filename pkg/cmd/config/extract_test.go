@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	conf := clientcmdapi.Config{
+		CurrentContext: "dev",
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"prod": {Server: "https://prod.example.com", CertificateAuthority: "certs/ca.crt"},
+			"dev":  {Server: "https://dev.example.com"},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"admin": {Token: "secret"},
+			"dev":   {Token: "dev"},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"prod": {Cluster: "prod", AuthInfo: "admin", Namespace: "default"},
+			"dev":  {Cluster: "dev", AuthInfo: "dev"},
+		},
+	}
+	if err := clientcmd.WriteToFile(conf, kubeconfig); err != nil {
+		t.Fatal(err)
+	}
+	pathOptions := clientcmd.NewDefaultPathOptions()
+	pathOptions.GlobalFile = kubeconfig
+	pathOptions.EnvVar = ""
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdConfigExtract(streams, pathOptions)
+	cmd.SetArgs([]string{"prod"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	extracted, err := clientcmd.Load(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extracted.CurrentContext != "prod" || len(extracted.Contexts) != 1 || len(extracted.Clusters) != 1 || len(extracted.AuthInfos) != 1 {
+		t.Fatalf("expected only the prod context, got %v", extracted)
+	}
+	if user := extracted.AuthInfos["admin"]; user.Token != "secret" {
+		t.Errorf("expected the token not to be redacted, got %q", user.Token)
+	}
+	if cluster := extracted.Clusters["prod"]; cluster.CertificateAuthority != filepath.Join(dir, "certs", "ca.crt") {
+		t.Errorf("expected an absolute certificate authority path, got %q", cluster.CertificateAuthority)
+	}
+
+	o := &ExtractOptions{ConfigAccess: pathOptions, ContextName: "missing", IOStreams: streams}
+	if err := o.Run(); err == nil || err.Error() != `no context exists with the name: "missing"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
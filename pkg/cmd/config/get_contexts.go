@@ -21,6 +21,7 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/liggitt/tabwriter"
 	"github.com/spf13/cobra"
@@ -29,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -45,19 +47,31 @@ type GetContextsOptions struct {
 
 	outputFormat string
 	noHeaders    bool
+	group        string
+	allGroups    bool
+	check        bool
+	// checkTimeout bounds the request made to each context with check.
+	checkTimeout time.Duration
 
 	genericiooptions.IOStreams
 }
 
 var (
-	getContextsLong = templates.LongDesc(i18n.T(`Display one or many contexts from the kubeconfig file.`))
+	getContextsLong = templates.LongDesc(i18n.T(`
+		Display one or many contexts from the kubeconfig file.
+
+		When a group was selected with use-group, only the contexts of that group are
+		displayed, unless another group or --all-groups is given.`))
 
 	getContextsExample = templates.Examples(`
 		# List all the contexts in your kubeconfig file
 		kubectl config get-contexts
 
 		# Describe one context in your kubeconfig file
-		kubectl config get-contexts my-context`)
+		kubectl config get-contexts my-context
+
+		# List the contexts of the group prod and check that their clusters are reachable
+		kubectl config get-contexts --group=prod --check`)
 )
 
 // NewCmdConfigGetContexts creates a command object for the "get-contexts" action, which
@@ -65,6 +79,7 @@ var (
 func NewCmdConfigGetContexts(streams genericiooptions.IOStreams, configAccess clientcmd.ConfigAccess) *cobra.Command {
 	options := &GetContextsOptions{
 		configAccess: configAccess,
+		checkTimeout: 5 * time.Second,
 
 		IOStreams: streams,
 	}
@@ -84,6 +99,9 @@ func NewCmdConfigGetContexts(streams genericiooptions.IOStreams, configAccess cl
 
 	cmd.Flags().BoolVar(&options.noHeaders, "no-headers", options.noHeaders, "When using the default or custom-column output format, don't print headers (default print headers).")
 	cmd.Flags().StringVarP(&options.outputFormat, "output", "o", options.outputFormat, `Output format. One of: (name).`)
+	cmd.Flags().StringVar(&options.group, "group", options.group, "Only display the contexts of this group. Defaults to the group selected with use-group.")
+	cmd.Flags().BoolVar(&options.allGroups, "all-groups", options.allGroups, "If true, display the contexts of all the groups, ignoring the group selected with use-group.")
+	cmd.Flags().BoolVar(&options.check, "check", options.check, "If true, request the version of the server of each context and display whether it is reachable.")
 	return cmd
 }
 
@@ -108,6 +126,12 @@ func (o *GetContextsOptions) Complete(cmd *cobra.Command, args []string) error {
 
 // Validate ensures the of output format
 func (o *GetContextsOptions) Validate() error {
+	if o.check && o.nameOnly {
+		return fmt.Errorf("--check can not be used with -o name")
+	}
+	if o.allGroups && len(o.group) > 0 {
+		return fmt.Errorf("--group can not be used with --all-groups")
+	}
 	return nil
 }
 
@@ -128,7 +152,13 @@ func (o GetContextsOptions) RunGetContexts() error {
 	// Do this before printing the headers so it doesn't look ugly.
 	allErrs := []error{}
 	toPrint := []string{}
-	if len(o.contextNames) == 0 {
+	group := o.group
+	if len(group) == 0 && !o.allGroups {
+		group = currentGroup(config)
+	}
+	if len(o.contextNames) == 0 && len(group) > 0 {
+		toPrint = contextsInGroup(config, group)
+	} else if len(o.contextNames) == 0 {
 		for name := range config.Contexts {
 			toPrint = append(toPrint, name)
 		}
@@ -143,33 +173,65 @@ func (o GetContextsOptions) RunGetContexts() error {
 		}
 	}
 	if o.showHeaders {
-		err = printContextHeaders(out, o.nameOnly)
+		err = printContextHeaders(out, o.nameOnly, o.check)
 		if err != nil {
 			allErrs = append(allErrs, err)
 		}
 	}
 
 	sort.Strings(toPrint)
+	unreachable := 0
 	for _, name := range toPrint {
-		err = printContext(name, config.Contexts[name], out, o.nameOnly, config.CurrentContext == name)
+		status := ""
+		if o.check {
+			status = o.checkContext(config, name)
+			if !strings.HasPrefix(status, "Reachable") {
+				unreachable++
+			}
+		}
+		err = printContext(name, config.Contexts[name], out, o.nameOnly, config.CurrentContext == name, status)
 		if err != nil {
 			allErrs = append(allErrs, err)
 		}
 	}
+	if unreachable > 0 {
+		allErrs = append(allErrs, fmt.Errorf("%d of %d contexts are unreachable", unreachable, len(toPrint)))
+	}
 
 	return utilerrors.NewAggregate(allErrs)
 }
 
-func printContextHeaders(out io.Writer, nameOnly bool) error {
+// checkContext requests the version of the server of the context.
+func (o GetContextsOptions) checkContext(config *clientcmdapi.Config, name string) string {
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(*config, name, &clientcmd.ConfigOverrides{}, o.configAccess).ClientConfig()
+	if err != nil {
+		return "Invalid: " + err.Error()
+	}
+	restConfig.Timeout = o.checkTimeout
+	client, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return "Invalid: " + err.Error()
+	}
+	version, err := client.ServerVersion()
+	if err != nil {
+		return "Unreachable: " + err.Error()
+	}
+	return fmt.Sprintf("Reachable (%s)", version.GitVersion)
+}
+
+func printContextHeaders(out io.Writer, nameOnly, check bool) error {
 	columnNames := []string{"CURRENT", "NAME", "CLUSTER", "AUTHINFO", "NAMESPACE"}
 	if nameOnly {
 		columnNames = columnNames[:1]
 	}
+	if check {
+		columnNames = append(columnNames, "STATUS")
+	}
 	_, err := fmt.Fprintf(out, "%s\n", strings.Join(columnNames, "\t"))
 	return err
 }
 
-func printContext(name string, context *clientcmdapi.Context, w io.Writer, nameOnly, current bool) error {
+func printContext(name string, context *clientcmdapi.Context, w io.Writer, nameOnly, current bool, status string) error {
 	if nameOnly {
 		_, err := fmt.Fprintf(w, "%s\n", name)
 		return err
@@ -178,6 +240,10 @@ func printContext(name string, context *clientcmdapi.Context, w io.Writer, nameO
 	if current {
 		prefix = "*"
 	}
+	if len(status) > 0 {
+		_, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", prefix, name, context.Cluster, context.AuthInfo, context.Namespace, status)
+		return err
+	}
 	_, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", prefix, name, context.Cluster, context.AuthInfo, context.Namespace)
 	return err
 }
@@ -17,11 +17,14 @@ limitations under the License.
 package config
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
 	utiltesting "k8s.io/client-go/util/testing"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
@@ -32,6 +35,9 @@ type getContextsTest struct {
 	names          []string
 	noHeader       bool
 	nameOnly       bool
+	group          string
+	allGroups      bool
+	check          bool
 	expectedOut    string
 }
 
@@ -144,6 +150,85 @@ func TestGetContextsSelectOneOfTwo(t *testing.T) {
 	test.run(t)
 }
 
+func groupedContextsConfig(t *testing.T, server string) clientcmdapi.Config {
+	conf := clientcmdapi.Config{
+		CurrentContext: "prod-eu",
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"eu": {Server: server},
+			"us": {Server: server},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{"admin": {}},
+		Contexts: map[string]*clientcmdapi.Context{
+			"prod-eu": {AuthInfo: "admin", Cluster: "eu"},
+			"prod-us": {AuthInfo: "admin", Cluster: "us"},
+			"dev":     {AuthInfo: "admin", Cluster: "eu", Namespace: "dev"},
+		},
+	}
+	for _, name := range []string{"prod-eu", "prod-us"} {
+		if err := setContextGroups(conf.Contexts[name], sets.New("prod")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return conf
+}
+
+func TestGetContextsGroup(t *testing.T) {
+	test := getContextsTest{
+		startingConfig: groupedContextsConfig(t, "https://example.com"),
+		group:          "prod",
+		expectedOut: `CURRENT   NAME      CLUSTER   AUTHINFO   NAMESPACE
+*         prod-eu   eu        admin      
+          prod-us   us        admin      
+`,
+	}
+	test.run(t)
+}
+
+func TestGetContextsCurrentGroup(t *testing.T) {
+	conf := groupedContextsConfig(t, "https://example.com")
+	if err := setCurrentGroup(&conf, "prod"); err != nil {
+		t.Fatal(err)
+	}
+	test := getContextsTest{
+		startingConfig: conf,
+		nameOnly:       true,
+		expectedOut: `prod-eu
+prod-us
+`,
+	}
+	test.run(t)
+
+	test.allGroups = true
+	test.expectedOut = `dev
+prod-eu
+prod-us
+`
+	test.run(t)
+}
+
+func TestGetContextsCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"gitVersion":"v1.29.0"}`))
+	}))
+	defer server.Close()
+
+	test := getContextsTest{
+		startingConfig: groupedContextsConfig(t, server.URL),
+		group:          "prod",
+		check:          true,
+		expectedOut: `CURRENT   NAME      CLUSTER   AUTHINFO   NAMESPACE   STATUS
+*         prod-eu   eu        admin                  Reachable (v1.29.0)
+          prod-us   us        admin                  Reachable (v1.29.0)
+`,
+	}
+	test.run(t)
+}
+
 func (test getContextsTest) run(t *testing.T) {
 	fakeKubeFile, err := os.CreateTemp(os.TempDir(), "")
 	if err != nil {
@@ -169,6 +254,15 @@ func (test getContextsTest) run(t *testing.T) {
 	if test.noHeader {
 		cmd.Flags().Set("no-headers", "true")
 	}
+	if len(test.group) > 0 {
+		cmd.Flags().Set("group", test.group)
+	}
+	if test.allGroups {
+		cmd.Flags().Set("all-groups", "true")
+	}
+	if test.check {
+		cmd.Flags().Set("check", "true")
+	}
 	cmd.Run(cmd, test.names)
 	if len(test.expectedOut) != 0 {
 		if buf.String() != test.expectedOut {
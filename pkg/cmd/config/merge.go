@@ -0,0 +1,291 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	onConflictError  = "error"
+	onConflictFirst  = "first"
+	onConflictRename = "rename"
+)
+
+// MergeOptions holds the command-line options for 'config merge' sub command
+type MergeOptions struct {
+	Filenames  []string
+	OutputFile string
+	Flatten    bool
+	OnConflict string
+
+	genericiooptions.IOStreams
+}
+
+var (
+	mergeLong = templates.LongDesc(i18n.T(`
+		Merge kubeconfig files into a single kubeconfig file.
+
+		The clusters, users and contexts of the files are merged in the order given.
+		Entries with the same name and the same content are kept once, and clusters and
+		users with the same content but different names are merged into the first one,
+		updating the contexts referencing them. Entries with the same name but different
+		content are conflicts: by default the merge fails, --on-conflict=first keeps the
+		entry of the first file and --on-conflict=rename adds a numeric suffix to the
+		name of the later entries.
+
+		The current context is the one of the first file setting it. Relative paths to
+		certificates and keys are made absolute, or the files are embedded with --flatten.`))
+
+	mergeExample = templates.Examples(`
+		# Merge two kubeconfig files into merged.yaml
+		kubectl config merge ~/.kube/config ~/Downloads/new-cluster.yaml -o merged.yaml
+
+		# Merge two kubeconfig files with the same context names, embedding the certificates
+		kubectl config merge a.yaml b.yaml --on-conflict=rename --flatten -o merged.yaml`)
+)
+
+// NewCmdConfigMerge returns a Command instance for 'config merge' sub command
+func NewCmdConfigMerge(streams genericiooptions.IOStreams) *cobra.Command {
+	o := &MergeOptions{
+		OnConflict: onConflictError,
+		IOStreams:  streams,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "merge FILE FILE... [-o OUTPUT_FILE]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Merge kubeconfig files into a single file"),
+		Long:                  mergeLong,
+		Example:               mergeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.OutputFile, "output", "o", o.OutputFile, "The file to write the merged kubeconfig to. Defaults to the standard output.")
+	cmd.Flags().BoolVar(&o.Flatten, "flatten", o.Flatten, "Embed the certificates and keys referenced by the files in the merged kubeconfig")
+	cmd.Flags().StringVar(&o.OnConflict, "on-conflict", o.OnConflict, "What to do with entries of the same name but different content. One of: error, first, rename.")
+	return cmd
+}
+
+// Complete completes the required command-line options
+func (o *MergeOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return cmdutil.UsageErrorf(cmd, "at least two files are required, got %d", len(args))
+	}
+	o.Filenames = args
+	return nil
+}
+
+// Validate makes sure that provided values for command-line options are valid
+func (o *MergeOptions) Validate() error {
+	if !sets.New(onConflictError, onConflictFirst, onConflictRename).Has(o.OnConflict) {
+		return fmt.Errorf("invalid --on-conflict %q, must be one of: error, first, rename", o.OnConflict)
+	}
+	return nil
+}
+
+// Run performs the execution of 'config merge' sub command
+func (o *MergeOptions) Run() error {
+	configs := []*clientcmdapi.Config{}
+	for _, filename := range o.Filenames {
+		config, err := clientcmd.LoadFromFile(filename)
+		if err != nil {
+			return err
+		}
+		if err := clientcmd.ResolveLocalPaths(config); err != nil {
+			return err
+		}
+		configs = append(configs, config)
+	}
+
+	merged, err := mergeConfigs(configs, o.OnConflict)
+	if err != nil {
+		return err
+	}
+	return writeConfig(merged, o.Flatten, o.OutputFile, o.Out)
+}
+
+// mergeConfigs merges the clusters, users and contexts of the configs.
+func mergeConfigs(configs []*clientcmdapi.Config, onConflict string) (*clientcmdapi.Config, error) {
+	merged := clientcmdapi.NewConfig()
+	conflicts := []error{}
+	// currentFrom is the index of the config the current context is taken from
+	currentFrom := -1
+	for i, config := range configs {
+		if currentFrom < 0 && len(config.CurrentContext) > 0 {
+			merged.CurrentContext = config.CurrentContext
+			currentFrom = i
+		}
+
+		clusterNames := map[string]string{}
+		for _, name := range sortedKeys(config.Clusters) {
+			cluster := config.Clusters[name]
+			newName, err := mergeEntry(merged.Clusters, name, cluster, equalClusters, true, onConflict, keys(config.Clusters))
+			if err != nil {
+				conflicts = append(conflicts, fmt.Errorf("cluster %s of file %d: %v", name, i+1, err))
+				continue
+			}
+			clusterNames[name] = newName
+		}
+
+		userNames := map[string]string{}
+		for _, name := range sortedKeys(config.AuthInfos) {
+			user := config.AuthInfos[name]
+			newName, err := mergeEntry(merged.AuthInfos, name, user, equalAuthInfos, true, onConflict, keys(config.AuthInfos))
+			if err != nil {
+				conflicts = append(conflicts, fmt.Errorf("user %s of file %d: %v", name, i+1, err))
+				continue
+			}
+			userNames[name] = newName
+		}
+
+		for _, name := range sortedKeys(config.Contexts) {
+			context := *config.Contexts[name]
+			if newName, ok := clusterNames[context.Cluster]; ok {
+				context.Cluster = newName
+			}
+			if newName, ok := userNames[context.AuthInfo]; ok {
+				context.AuthInfo = newName
+			}
+			newName, err := mergeEntry(merged.Contexts, name, &context, equalContexts, false, onConflict, keys(config.Contexts))
+			if err != nil {
+				conflicts = append(conflicts, fmt.Errorf("context %s of file %d: %v", name, i+1, err))
+				continue
+			}
+			if currentFrom == i && name == config.CurrentContext {
+				merged.CurrentContext = newName
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, utilerrors.NewAggregate(conflicts)
+	}
+	return merged, nil
+}
+
+// mergeEntry adds the entry to the merged entries and returns the name it
+// was merged as. With byContent, an entry equal to an entry of another name
+// is merged into it. The names of the file the entry is from are taken into
+// account when renaming it.
+func mergeEntry[T any](merged map[string]*T, name string, entry *T, equal func(a, b *T) bool, byContent bool, onConflict string, taken []string) (string, error) {
+	existing, found := merged[name]
+	if found && equal(existing, entry) {
+		return name, nil
+	}
+	if byContent {
+		for _, existingName := range sortedKeys(merged) {
+			if equal(merged[existingName], entry) {
+				return existingName, nil
+			}
+		}
+	}
+	if !found {
+		merged[name] = entry
+		return name, nil
+	}
+
+	switch onConflict {
+	case onConflictFirst:
+		return name, nil
+	case onConflictRename:
+		used := sets.New(taken...).Insert(sortedKeys(merged)...)
+		for i := 2; ; i++ {
+			newName := name + "-" + strconv.Itoa(i)
+			if !used.Has(newName) {
+				merged[newName] = entry
+				return newName, nil
+			}
+		}
+	default:
+		return "", fmt.Errorf("conflicts with an entry of the same name, use --on-conflict to keep the first entry or rename it")
+	}
+}
+
+// equalClusters, equalAuthInfos and equalContexts compare two entries,
+// ignoring the file they were loaded from.
+func equalClusters(a, b *clientcmdapi.Cluster) bool {
+	x, y := *a, *b
+	x.LocationOfOrigin, y.LocationOfOrigin = "", ""
+	return reflect.DeepEqual(x, y)
+}
+
+func equalAuthInfos(a, b *clientcmdapi.AuthInfo) bool {
+	x, y := *a, *b
+	x.LocationOfOrigin, y.LocationOfOrigin = "", ""
+	return reflect.DeepEqual(x, y)
+}
+
+func equalContexts(a, b *clientcmdapi.Context) bool {
+	x, y := *a, *b
+	x.LocationOfOrigin, y.LocationOfOrigin = "", ""
+	return reflect.DeepEqual(x, y)
+}
+
+// writeConfig writes the config to the file, or to out when no file is given.
+func writeConfig(config *clientcmdapi.Config, flatten bool, filename string, out io.Writer) error {
+	if flatten {
+		if err := clientcmdapi.FlattenConfig(config); err != nil {
+			return err
+		}
+	}
+	if len(filename) == 0 {
+		data, err := clientcmd.Write(*config)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+	if err := clientcmd.WriteToFile(*config, filename); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "Kubeconfig written to %q.\n", filename)
+	return err
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	names := keys(m)
+	sort.Strings(names)
+	return names
+}
+
+func keys[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestMergeConfigs(t *testing.T) {
+	first := &clientcmdapi.Config{
+		CurrentContext: "prod",
+		Clusters:       map[string]*clientcmdapi.Cluster{"prod": {Server: "https://prod.example.com", LocationOfOrigin: "a.yaml"}},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{"admin": {Token: "a", LocationOfOrigin: "a.yaml"}},
+		Contexts:       map[string]*clientcmdapi.Context{"prod": {Cluster: "prod", AuthInfo: "admin", LocationOfOrigin: "a.yaml"}},
+	}
+
+	tests := []struct {
+		name          string
+		second        *clientcmdapi.Config
+		onConflict    string
+		expected      *clientcmdapi.Config
+		expectedError string
+	}{
+		{
+			name: "identical entries are kept once",
+			second: &clientcmdapi.Config{
+				CurrentContext: "stage",
+				Clusters: map[string]*clientcmdapi.Cluster{
+					"prod":  {Server: "https://prod.example.com", LocationOfOrigin: "b.yaml"},
+					"stage": {Server: "https://stage.example.com", LocationOfOrigin: "b.yaml"},
+				},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"admin": {Token: "a", LocationOfOrigin: "b.yaml"}},
+				Contexts:  map[string]*clientcmdapi.Context{"stage": {Cluster: "stage", AuthInfo: "admin", LocationOfOrigin: "b.yaml"}},
+			},
+			onConflict: onConflictError,
+			expected: &clientcmdapi.Config{
+				CurrentContext: "prod",
+				Clusters: map[string]*clientcmdapi.Cluster{
+					"prod":  {Server: "https://prod.example.com", LocationOfOrigin: "a.yaml"},
+					"stage": {Server: "https://stage.example.com", LocationOfOrigin: "b.yaml"},
+				},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"admin": {Token: "a", LocationOfOrigin: "a.yaml"}},
+				Contexts: map[string]*clientcmdapi.Context{
+					"prod":  {Cluster: "prod", AuthInfo: "admin", LocationOfOrigin: "a.yaml"},
+					"stage": {Cluster: "stage", AuthInfo: "admin", LocationOfOrigin: "b.yaml"},
+				},
+			},
+		},
+		{
+			name: "same content under another name is deduplicated",
+			second: &clientcmdapi.Config{
+				Clusters:  map[string]*clientcmdapi.Cluster{"production": {Server: "https://prod.example.com"}},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"root": {Token: "a"}},
+				Contexts:  map[string]*clientcmdapi.Context{"prod-system": {Cluster: "production", AuthInfo: "root", Namespace: "kube-system"}},
+			},
+			onConflict: onConflictError,
+			expected: &clientcmdapi.Config{
+				CurrentContext: "prod",
+				Clusters:       map[string]*clientcmdapi.Cluster{"prod": {Server: "https://prod.example.com", LocationOfOrigin: "a.yaml"}},
+				AuthInfos:      map[string]*clientcmdapi.AuthInfo{"admin": {Token: "a", LocationOfOrigin: "a.yaml"}},
+				Contexts: map[string]*clientcmdapi.Context{
+					"prod":        {Cluster: "prod", AuthInfo: "admin", LocationOfOrigin: "a.yaml"},
+					"prod-system": {Cluster: "prod", AuthInfo: "admin", Namespace: "kube-system"},
+				},
+			},
+		},
+		{
+			name: "conflicts fail by default",
+			second: &clientcmdapi.Config{
+				Clusters:  map[string]*clientcmdapi.Cluster{"prod": {Server: "https://other.example.com"}},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"admin": {Token: "a"}},
+				Contexts:  map[string]*clientcmdapi.Context{"prod": {Cluster: "prod", AuthInfo: "admin"}},
+			},
+			onConflict:    onConflictError,
+			expectedError: "cluster prod of file 2: conflicts with an entry of the same name, use --on-conflict to keep the first entry or rename it",
+		},
+		{
+			name: "conflicts keep the first entry",
+			second: &clientcmdapi.Config{
+				Clusters:  map[string]*clientcmdapi.Cluster{"prod": {Server: "https://other.example.com"}},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"admin": {Token: "a"}},
+				Contexts:  map[string]*clientcmdapi.Context{"prod": {Cluster: "prod", AuthInfo: "admin", Namespace: "other"}},
+			},
+			onConflict: onConflictFirst,
+			expected:   first,
+		},
+		{
+			name: "conflicts are renamed",
+			second: &clientcmdapi.Config{
+				CurrentContext: "prod",
+				Clusters:       map[string]*clientcmdapi.Cluster{"prod": {Server: "https://other.example.com"}},
+				AuthInfos:      map[string]*clientcmdapi.AuthInfo{"admin": {Token: "b"}},
+				Contexts:       map[string]*clientcmdapi.Context{"prod": {Cluster: "prod", AuthInfo: "admin"}},
+			},
+			onConflict: onConflictRename,
+			expected: &clientcmdapi.Config{
+				CurrentContext: "prod",
+				Clusters: map[string]*clientcmdapi.Cluster{
+					"prod":   {Server: "https://prod.example.com", LocationOfOrigin: "a.yaml"},
+					"prod-2": {Server: "https://other.example.com"},
+				},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{
+					"admin":   {Token: "a", LocationOfOrigin: "a.yaml"},
+					"admin-2": {Token: "b"},
+				},
+				Contexts: map[string]*clientcmdapi.Context{
+					"prod":   {Cluster: "prod", AuthInfo: "admin", LocationOfOrigin: "a.yaml"},
+					"prod-2": {Cluster: "prod-2", AuthInfo: "admin-2"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := mergeConfigs([]*clientcmdapi.Config{first, tt.second}, tt.onConflict)
+			if len(tt.expectedError) > 0 {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if merged.CurrentContext != tt.expected.CurrentContext ||
+				!reflect.DeepEqual(merged.Clusters, tt.expected.Clusters) ||
+				!reflect.DeepEqual(merged.AuthInfos, tt.expected.AuthInfos) ||
+				!reflect.DeepEqual(merged.Contexts, tt.expected.Contexts) {
+				t.Errorf("expected:\n%v\ngot:\n%v", tt.expected, merged)
+			}
+		})
+	}
+}
+
+func TestMergeFlatten(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ca.crt"), []byte("certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	a := clientcmdapi.Config{
+		Clusters:  map[string]*clientcmdapi.Cluster{"a": {Server: "https://a.example.com", CertificateAuthority: "ca.crt"}},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{"a": {Token: "a"}},
+		Contexts:  map[string]*clientcmdapi.Context{"a": {Cluster: "a", AuthInfo: "a"}},
+	}
+	b := clientcmdapi.Config{
+		CurrentContext: "b",
+		Clusters:       map[string]*clientcmdapi.Cluster{"b": {Server: "https://b.example.com"}},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{"b": {Token: "b"}},
+		Contexts:       map[string]*clientcmdapi.Context{"b": {Cluster: "b", AuthInfo: "b"}},
+	}
+	for name, config := range map[string]clientcmdapi.Config{"a.yaml": a, "b.yaml": b} {
+		if err := clientcmd.WriteToFile(config, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdConfigMerge(streams)
+	output := filepath.Join(dir, "merged.yaml")
+	cmd.SetArgs([]string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml"), "--flatten", "-o", output})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Kubeconfig written to \"" + output + "\".\n"; buf.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, buf.String())
+	}
+
+	merged, err := clientcmd.LoadFromFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.CurrentContext != "b" || len(merged.Contexts) != 2 {
+		t.Errorf("unexpected merged config: %v", merged)
+	}
+	if cluster := merged.Clusters["a"]; string(cluster.CertificateAuthorityData) != "certificate" || len(cluster.CertificateAuthority) > 0 {
+		t.Errorf("expected the certificate authority to be embedded, got %v", cluster)
+	}
+}
@@ -39,17 +39,24 @@ type setContextOptions struct {
 	cluster      cliflag.StringFlag
 	authInfo     cliflag.StringFlag
 	namespace    cliflag.StringFlag
+	protected    *bool
 }
 
 var (
 	setContextLong = templates.LongDesc(i18n.T(`
 		Set a context entry in kubeconfig.
 
-		Specifying a name that already exists will merge new fields on top of existing values for those fields.`))
+		Specifying a name that already exists will merge new fields on top of existing values for those fields.
+
+		Destructive commands against a protected context, such as delete, drain, scale to zero and
+		apply --prune, ask for a confirmation unless --yes is given.`))
 
 	setContextExample = templates.Examples(`
 		# Set the user field on the gce context entry without touching other values
-		kubectl config set-context gce --user=cluster-admin`)
+		kubectl config set-context gce --user=cluster-admin
+
+		# Ask for a confirmation before destructive commands against the prod context
+		kubectl config set-context prod --protected`)
 )
 
 // NewCmdConfigSetContext returns a Command instance for 'config set-context' sub command
@@ -79,6 +86,7 @@ func NewCmdConfigSetContext(out io.Writer, configAccess clientcmd.ConfigAccess)
 	cmd.Flags().Var(&options.cluster, clientcmd.FlagClusterName, clientcmd.FlagClusterName+" for the context entry in kubeconfig")
 	cmd.Flags().Var(&options.authInfo, clientcmd.FlagAuthInfoName, clientcmd.FlagAuthInfoName+" for the context entry in kubeconfig")
 	cmd.Flags().Var(&options.namespace, clientcmd.FlagNamespace, clientcmd.FlagNamespace+" for the context entry in kubeconfig")
+	cmd.Flags().Bool("protected", false, "If true, ask for a confirmation before destructive commands against the context. Without a terminal to ask on, the commands require --yes. Use --protected=false to remove the protection.")
 
 	return cmd
 }
@@ -128,6 +136,9 @@ func (o *setContextOptions) modifyContext(existingContext clientcmdapi.Context)
 	if o.namespace.Provided() {
 		modifiedContext.Namespace = o.namespace.Value()
 	}
+	if o.protected != nil {
+		cmdutil.SetProtectedContext(&modifiedContext, *o.protected)
+	}
 
 	return modifiedContext
 }
@@ -140,6 +151,10 @@ func (o *setContextOptions) complete(cmd *cobra.Command) error {
 	if len(args) == 1 {
 		o.name = args[0]
 	}
+	if cmd.Flags().Changed("protected") {
+		protected := cmdutil.GetFlagBool(cmd, "protected")
+		o.protected = &protected
+	}
 	return nil
 }
 
@@ -25,6 +25,7 @@ import (
 
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
 type setContextTest struct {
@@ -35,6 +36,7 @@ type setContextTest struct {
 	flags          []string            //kubectl set-context flags
 	expected       string              //expectd out
 	expectedConfig clientcmdapi.Config //expect kubectl config
+	protected      bool                //expect the context to be protected
 }
 
 func TestCreateContext(t *testing.T) {
@@ -107,6 +109,31 @@ func TestModifyCurrentContext(t *testing.T) {
 	test.run(t)
 }
 
+func TestProtectContext(t *testing.T) {
+	conf := clientcmdapi.Config{
+		Contexts: map[string]*clientcmdapi.Context{
+			"shaker-context": {AuthInfo: "blue-user", Cluster: "big-cluster", Namespace: "saw-ns"}}}
+	test := setContextTest{
+		testContext: "shaker-context",
+		description: "Testing for protect a context",
+		config:      conf,
+		args:        []string{"shaker-context"},
+		flags:       []string{"--protected"},
+		expected:    `Context "shaker-context" modified.` + "\n",
+		expectedConfig: clientcmdapi.Config{
+			Contexts: map[string]*clientcmdapi.Context{
+				"shaker-context": {AuthInfo: "blue-user", Cluster: "big-cluster", Namespace: "saw-ns"}}},
+		protected: true,
+	}
+	test.run(t)
+
+	cmdutil.SetProtectedContext(conf.Contexts["shaker-context"], true)
+	test.description = "Testing for unprotect a context"
+	test.flags = []string{"--protected=false"}
+	test.protected = false
+	test.run(t)
+}
+
 func (test setContextTest) run(t *testing.T) {
 	fakeKubeFile, err := os.CreateTemp(os.TempDir(), "")
 	if err != nil {
@@ -144,5 +171,8 @@ func (test setContextTest) run(t *testing.T) {
 			expectContext.Namespace != actualContext.Namespace {
 			t.Errorf("Fail in %q:\n expected Context %v\n but found %v in kubeconfig\n", test.description, expectContext, actualContext)
 		}
+		if cmdutil.IsProtectedContext(actualContext) != test.protected {
+			t.Errorf("Fail in %q:\n expected the context to be protected: %v", test.description, test.protected)
+		}
 	}
 }
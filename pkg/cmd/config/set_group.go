@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/clientcmd"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type setGroupOptions struct {
+	configAccess clientcmd.ConfigAccess
+	group        string
+	contextNames []string
+	remove       bool
+}
+
+var (
+	setGroupLong = templates.LongDesc(i18n.T(`
+		Add contexts to a group, or remove them from it with --remove.
+
+		Groups tag contexts, for instance by environment, so that they can be listed
+		together with get-contexts --group and selected with use-group. A context can
+		belong to several groups. The groups are stored in an extension of each context.`))
+
+	setGroupExample = templates.Examples(`
+		# Add the contexts prod-eu and prod-us to the group prod
+		kubectl config set-group prod prod-eu prod-us
+
+		# Remove the context prod-us from the group prod
+		kubectl config set-group prod prod-us --remove`)
+)
+
+// NewCmdConfigSetGroup returns a Command instance for 'config set-group' sub command
+func NewCmdConfigSetGroup(out io.Writer, configAccess clientcmd.ConfigAccess) *cobra.Command {
+	options := &setGroupOptions{configAccess: configAccess}
+
+	cmd := &cobra.Command{
+		Use:                   "set-group GROUP CONTEXT_NAME [CONTEXT_NAME...] [--remove]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Add contexts to a group in a kubeconfig file"),
+		Long:                  setGroupLong,
+		Example:               setGroupExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(options.complete(cmd, args))
+			cmdutil.CheckErr(options.run(out))
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.remove, "remove", options.remove, "If true, remove the contexts from the group instead of adding them.")
+	return cmd
+}
+
+func (o *setGroupOptions) complete(cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return helpErrorf(cmd, "Unexpected args: %v", args)
+	}
+	o.group = args[0]
+	o.contextNames = args[1:]
+	if len(o.group) == 0 {
+		return errors.New("empty group names are not allowed")
+	}
+	return nil
+}
+
+func (o setGroupOptions) run(out io.Writer) error {
+	config, err := o.configAccess.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range o.contextNames {
+		context, ok := config.Contexts[name]
+		if !ok {
+			return fmt.Errorf("no context exists with the name: %q", name)
+		}
+		groups := sets.New(contextGroups(context)...)
+		if o.remove {
+			groups.Delete(o.group)
+		} else {
+			groups.Insert(o.group)
+		}
+		if err := setContextGroups(context, groups); err != nil {
+			return err
+		}
+	}
+
+	if err := clientcmd.ModifyConfig(o.configAccess, *config, true); err != nil {
+		return err
+	}
+	for _, name := range o.contextNames {
+		if o.remove {
+			fmt.Fprintf(out, "Context %q removed from group %q.\n", name, o.group)
+		} else {
+			fmt.Fprintf(out, "Context %q added to group %q.\n", name, o.group)
+		}
+	}
+	return nil
+}
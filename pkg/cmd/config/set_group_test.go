@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	utiltesting "k8s.io/client-go/util/testing"
+)
+
+func TestSetGroup(t *testing.T) {
+	fakeKubeFile, err := os.CreateTemp(os.TempDir(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer utiltesting.CloseAndRemove(t, fakeKubeFile)
+	if err := clientcmd.WriteToFile(groupedContextsConfig(t, "https://example.com"), fakeKubeFile.Name()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pathOptions := clientcmd.NewDefaultPathOptions()
+	pathOptions.GlobalFile = fakeKubeFile.Name()
+	pathOptions.EnvVar = ""
+
+	buf := bytes.NewBuffer([]byte{})
+	cmd := NewCmdConfigSetGroup(buf, pathOptions)
+	cmd.SetArgs([]string{"eu", "dev", "prod-eu"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cmd = NewCmdConfigSetGroup(buf, pathOptions)
+	cmd.SetArgs([]string{"prod", "prod-us", "--remove"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedOut := `Context "dev" added to group "eu".
+Context "prod-eu" added to group "eu".
+Context "prod-us" removed from group "prod".
+`
+	if buf.String() != expectedOut {
+		t.Errorf("expected output:\n%s\ngot:\n%s", expectedOut, buf.String())
+	}
+
+	config, err := clientcmd.LoadFromFile(fakeKubeFile.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string][]string{
+		"dev":     {"eu"},
+		"prod-eu": {"eu", "prod"},
+		"prod-us": {},
+	}
+	for name, groups := range expected {
+		if actual := contextGroups(config.Contexts[name]); !reflect.DeepEqual(actual, groups) {
+			t.Errorf("expected the groups of %s to be %v, got %v", name, groups, actual)
+		}
+	}
+	if _, ok := config.Contexts["prod-us"].Extensions[contextGroupsExtension]; ok {
+		t.Errorf("expected the extension of prod-us to be removed")
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type setOutputTemplateOptions struct {
+	configAccess clientcmd.ConfigAccess
+	name         string
+	format       string
+	remove       bool
+}
+
+var (
+	setOutputTemplateLong = templates.LongDesc(i18n.T(`
+		Set an output template in a kubeconfig file, or remove it with --remove.
+
+		Output templates give a name to an output format and its template, so that
+		they can be used with --output=template:NAME by any command printing
+		resources. The format is one of custom-columns, custom-columns-file,
+		go-template, go-template-file, template, templatefile, jsonpath,
+		jsonpath-file, jsonpath-as-json or jq, followed by an equal sign and the
+		template, as given to --output. The templates are stored in an extension
+		of the preferences, so that the kubeconfig file can be shared.`))
+
+	setOutputTemplateExample = templates.Examples(`
+		# Set an output template listing the pods with their node
+		kubectl config set-output-template compact-pods 'custom-columns=NAME:.metadata.name,NODE:.spec.nodeName'
+
+		# Use the output template
+		kubectl get pods -o template:compact-pods
+
+		# Remove the output template
+		kubectl config set-output-template compact-pods --remove`)
+)
+
+// NewCmdConfigSetOutputTemplate returns a Command instance for 'config set-output-template' sub command
+func NewCmdConfigSetOutputTemplate(out io.Writer, configAccess clientcmd.ConfigAccess) *cobra.Command {
+	options := &setOutputTemplateOptions{configAccess: configAccess}
+
+	cmd := &cobra.Command{
+		Use:                   "set-output-template NAME [FORMAT=TEMPLATE] [--remove]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Set a named output template in a kubeconfig file"),
+		Long:                  setOutputTemplateLong,
+		Example:               setOutputTemplateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(options.complete(cmd, args))
+			cmdutil.CheckErr(options.run(out))
+		},
+	}
+
+	cmd.Flags().BoolVar(&options.remove, "remove", options.remove, "If true, remove the output template instead of setting it.")
+	return cmd
+}
+
+func (o *setOutputTemplateOptions) complete(cmd *cobra.Command, args []string) error {
+	if o.remove && len(args) != 1 || !o.remove && len(args) != 2 {
+		return helpErrorf(cmd, "Unexpected args: %v", args)
+	}
+	o.name = args[0]
+	if len(o.name) == 0 {
+		return fmt.Errorf("empty output template names are not allowed")
+	}
+	if o.remove {
+		return nil
+	}
+
+	o.format = args[1]
+	for _, format := range cmdutil.OutputTemplateFormats {
+		if strings.HasPrefix(o.format, format) && len(o.format) > len(format) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output template %q, must be one of %s followed by the template", o.format, strings.Join(cmdutil.OutputTemplateFormats, ", "))
+}
+
+func (o setOutputTemplateOptions) run(out io.Writer) error {
+	config, err := o.configAccess.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+
+	outputTemplates, err := cmdutil.OutputTemplates(config)
+	if err != nil {
+		return err
+	}
+	if o.remove {
+		if _, ok := outputTemplates[o.name]; !ok {
+			return fmt.Errorf("no output template exists with the name: %q", o.name)
+		}
+		delete(outputTemplates, o.name)
+	} else {
+		outputTemplates[o.name] = o.format
+	}
+
+	if len(outputTemplates) == 0 {
+		delete(config.Preferences.Extensions, cmdutil.OutputTemplatesExtension)
+	} else {
+		if config.Preferences.Extensions == nil {
+			config.Preferences.Extensions = map[string]runtime.Object{}
+		}
+		if err := encodeExtension(config.Preferences.Extensions, cmdutil.OutputTemplatesExtension, outputTemplates); err != nil {
+			return err
+		}
+	}
+	if err := clientcmd.ModifyConfig(o.configAccess, *config, true); err != nil {
+		return err
+	}
+
+	if o.remove {
+		fmt.Fprintf(out, "Output template %q removed.\n", o.name)
+	} else {
+		fmt.Fprintf(out, "Output template %q set.\n", o.name)
+	}
+	return nil
+}
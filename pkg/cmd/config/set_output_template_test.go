@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	utiltesting "k8s.io/client-go/util/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func TestSetOutputTemplate(t *testing.T) {
+	fakeKubeFile, err := os.CreateTemp(os.TempDir(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer utiltesting.CloseAndRemove(t, fakeKubeFile)
+	if err := clientcmd.WriteToFile(*clientcmdapi.NewConfig(), fakeKubeFile.Name()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pathOptions := clientcmd.NewDefaultPathOptions()
+	pathOptions.GlobalFile = fakeKubeFile.Name()
+	pathOptions.EnvVar = ""
+
+	buf := bytes.NewBuffer([]byte{})
+	for _, args := range [][]string{
+		{"compact-pods", "custom-columns=NAME:.metadata.name,NODE:.spec.nodeName"},
+		{"names", "jsonpath={.items[*].metadata.name}"},
+		{"names", "--remove"},
+	} {
+		cmd := NewCmdConfigSetOutputTemplate(buf, pathOptions)
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	expectedOut := `Output template "compact-pods" set.
+Output template "names" set.
+Output template "names" removed.
+`
+	if buf.String() != expectedOut {
+		t.Errorf("expected output:\n%s\ngot:\n%s", expectedOut, buf.String())
+	}
+
+	config, err := clientcmd.LoadFromFile(fakeKubeFile.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	outputTemplates, err := cmdutil.OutputTemplates(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{"compact-pods": "custom-columns=NAME:.metadata.name,NODE:.spec.nodeName"}
+	if !reflect.DeepEqual(outputTemplates, expected) {
+		t.Errorf("expected output templates %v, got %v", expected, outputTemplates)
+	}
+}
+
+func TestSetOutputTemplateInvalid(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{
+			args:     []string{"wide", "wide"},
+			expected: `invalid output template "wide", must be one of custom-columns=, custom-columns-file=, go-template=, go-template-file=, template=, templatefile=, jsonpath=, jsonpath-file=, jsonpath-as-json=, jq= followed by the template`,
+		},
+		{
+			args:     []string{"", "jsonpath={.kind}"},
+			expected: "empty output template names are not allowed",
+		},
+	}
+	for _, tc := range tests {
+		o := &setOutputTemplateOptions{}
+		err := o.complete(NewCmdConfigSetOutputTemplate(bytes.NewBuffer(nil), clientcmd.NewDefaultPathOptions()), tc.args)
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("expected error %q, got %v", tc.expected, err)
+		}
+	}
+}
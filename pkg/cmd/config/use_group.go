@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/clientcmd"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+type useGroupOptions struct {
+	configAccess clientcmd.ConfigAccess
+	group        string
+}
+
+var (
+	useGroupLong = templates.LongDesc(i18n.T(`
+		Select the current group of contexts in a kubeconfig file.
+
+		get-contexts lists the contexts of the current group by default. When the
+		current context is not in the group, the first context of the group, in
+		alphabetical order, becomes the current context.
+
+		Use an empty name to list all the contexts again.`))
+
+	useGroupExample = templates.Examples(`
+		# Work with the contexts of the group prod
+		kubectl config use-group prod
+
+		# Stop working with a group
+		kubectl config use-group ""`)
+)
+
+// NewCmdConfigUseGroup returns a Command instance for 'config use-group' sub command
+func NewCmdConfigUseGroup(out io.Writer, configAccess clientcmd.ConfigAccess) *cobra.Command {
+	options := &useGroupOptions{configAccess: configAccess}
+
+	cmd := &cobra.Command{
+		Use:                   "use-group GROUP",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Set the current group of contexts in a kubeconfig file"),
+		Long:                  useGroupLong,
+		Example:               useGroupExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(options.complete(cmd, args))
+			cmdutil.CheckErr(options.run(out))
+		},
+	}
+
+	return cmd
+}
+
+func (o *useGroupOptions) complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return helpErrorf(cmd, "Unexpected args: %v", args)
+	}
+	o.group = args[0]
+	return nil
+}
+
+func (o useGroupOptions) run(out io.Writer) error {
+	config, err := o.configAccess.GetStartingConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(o.group) == 0 {
+		delete(config.Preferences.Extensions, currentGroupExtension)
+		if err := clientcmd.ModifyConfig(o.configAccess, *config, true); err != nil {
+			return err
+		}
+		fmt.Fprintln(out, "Unset the current group.")
+		return nil
+	}
+
+	contexts := contextsInGroup(config, o.group)
+	if len(contexts) == 0 {
+		return fmt.Errorf("no context exists in the group: %q", o.group)
+	}
+	if err := setCurrentGroup(config, o.group); err != nil {
+		return err
+	}
+	if !sets.New(contexts...).Has(config.CurrentContext) {
+		config.CurrentContext = contexts[0]
+	}
+	if err := clientcmd.ModifyConfig(o.configAccess, *config, true); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Switched to group %q, context %q.\n", o.group, config.CurrentContext)
+	return nil
+}
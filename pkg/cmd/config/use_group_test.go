@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	utiltesting "k8s.io/client-go/util/testing"
+)
+
+func TestUseGroup(t *testing.T) {
+	tests := []struct {
+		name                   string
+		currentContext         string
+		group                  string
+		expectedOut            string
+		expectedCurrentContext string
+		expectedGroup          string
+	}{
+		{
+			name:                   "current context in the group",
+			currentContext:         "prod-us",
+			group:                  "prod",
+			expectedOut:            `Switched to group "prod", context "prod-us".` + "\n",
+			expectedCurrentContext: "prod-us",
+			expectedGroup:          "prod",
+		},
+		{
+			name:                   "current context not in the group",
+			currentContext:         "dev",
+			group:                  "prod",
+			expectedOut:            `Switched to group "prod", context "prod-eu".` + "\n",
+			expectedCurrentContext: "prod-eu",
+			expectedGroup:          "prod",
+		},
+		{
+			name:                   "unset",
+			currentContext:         "dev",
+			group:                  "",
+			expectedOut:            "Unset the current group.\n",
+			expectedCurrentContext: "dev",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeKubeFile, err := os.CreateTemp(os.TempDir(), "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer utiltesting.CloseAndRemove(t, fakeKubeFile)
+			conf := groupedContextsConfig(t, "https://example.com")
+			conf.CurrentContext = tt.currentContext
+			if err := setCurrentGroup(&conf, "other"); err != nil {
+				t.Fatal(err)
+			}
+			if err := clientcmd.WriteToFile(conf, fakeKubeFile.Name()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			pathOptions := clientcmd.NewDefaultPathOptions()
+			pathOptions.GlobalFile = fakeKubeFile.Name()
+			pathOptions.EnvVar = ""
+
+			buf := bytes.NewBuffer([]byte{})
+			cmd := NewCmdConfigUseGroup(buf, pathOptions)
+			cmd.SetArgs([]string{tt.group})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.expectedOut {
+				t.Errorf("expected output %q, got %q", tt.expectedOut, buf.String())
+			}
+
+			config, err := clientcmd.LoadFromFile(fakeKubeFile.Name())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if config.CurrentContext != tt.expectedCurrentContext {
+				t.Errorf("expected current context %q, got %q", tt.expectedCurrentContext, config.CurrentContext)
+			}
+			if group := currentGroup(config); group != tt.expectedGroup {
+				t.Errorf("expected current group %q, got %q", tt.expectedGroup, group)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package cp
 import (
 	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
@@ -62,7 +63,17 @@ var (
 		kubectl cp /tmp/foo <some-namespace>/<some-pod>:/tmp/bar
 
 		# Copy /tmp/foo from a remote pod to /tmp/bar locally
-		kubectl cp <some-namespace>/<some-pod>:/tmp/foo /tmp/bar`))
+		kubectl cp <some-namespace>/<some-pod>:/tmp/foo /tmp/bar
+
+		# Copy /data from one remote pod to /data in another, streaming through kubectl
+		kubectl cp <some-namespace>/<some-pod>:/data <other-namespace>/<other-pod>:/data
+
+		# Copy a large file from a remote pod with gzip compression, showing progress
+		# and verifying the checksum once the transfer is complete
+		kubectl cp <some-pod>:/data/dump.sql /tmp/dump.sql --compress --progress --checksum
+
+		# Resume an interrupted copy of a large file to a remote pod
+		kubectl cp /tmp/image.iso <some-pod>:/tmp/image.iso --resume --retries=5`))
 )
 
 // CopyOptions have the data required to perform the copy operation
@@ -71,12 +82,18 @@ type CopyOptions struct {
 	Namespace  string
 	NoPreserve bool
 	MaxTries   int
+	Progress   bool
+	Compress   bool
+	Checksum   bool
+	Resume     bool
 
 	ClientConfig      *restclient.Config
 	Clientset         kubernetes.Interface
 	ExecParentCmdName string
 
 	args []string
+	// copied records the files extracted by untarAll for checksum verification.
+	copied []copiedFile
 
 	genericiooptions.IOStreams
 }
@@ -96,7 +113,7 @@ func NewCmdCp(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra.Co
 		Use:                   "cp <file-spec-src> <file-spec-dest>",
 		DisableFlagsInUseLine: true,
 		Short:                 i18n.T("Copy files and directories to and from containers"),
-		Long:                  i18n.T("Copy files and directories to and from containers."),
+		Long:                  i18n.T("Copy files and directories to and from containers, or between containers of two pods. Copies between pods are streamed through kubectl without being stored locally; the container flag applies to both pods."),
 		Example:               cpExample,
 		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 			var comps []string
@@ -158,6 +175,10 @@ func NewCmdCp(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra.Co
 	cmdutil.AddContainerVarFlags(cmd, &o.Container, o.Container)
 	cmd.Flags().BoolVarP(&o.NoPreserve, "no-preserve", "", false, "The copied file/directory's ownership and permissions will not be preserved in the container")
 	cmd.Flags().IntVarP(&o.MaxTries, "retries", "", 0, "Set number of retries to complete a copy operation from a container. Specify 0 to disable or any negative value for infinite retrying. The default is 0 (no retry).")
+	cmd.Flags().BoolVar(&o.Progress, "progress", o.Progress, "If true, report the transfer progress on stderr.")
+	cmd.Flags().BoolVar(&o.Compress, "compress", o.Compress, "If true, compress the transferred data with gzip. Requires gzip support in the container's tar.")
+	cmd.Flags().BoolVar(&o.Checksum, "checksum", o.Checksum, "If true, verify the SHA-256 checksum of every copied file after the transfer. Requires 'sha256sum' in the container.")
+	cmd.Flags().BoolVar(&o.Resume, "resume", o.Resume, "If true, continue a previously interrupted copy of a single regular file by transferring only the missing part. Requires 'sh', 'wc' and 'tail' in the container. Combined with --retries, interrupted transfers are resumed automatically.")
 
 	return cmd
 }
@@ -243,17 +264,26 @@ func (o *CopyOptions) Run() error {
 		return err
 	}
 
-	if len(srcSpec.PodName) != 0 && len(destSpec.PodName) != 0 {
-		return fmt.Errorf("one of src or dest must be a local file specification")
-	}
 	if len(srcSpec.File.String()) == 0 || len(destSpec.File.String()) == 0 {
 		return errors.New("filepath can not be empty")
 	}
 
+	if len(srcSpec.PodName) != 0 && len(destSpec.PodName) != 0 {
+		if o.Resume {
+			return fmt.Errorf("--resume is not supported for copies between pods")
+		}
+		return o.copyBetweenPods(srcSpec, destSpec)
+	}
 	if len(srcSpec.PodName) != 0 {
+		if o.Resume {
+			return o.resumeFromPod(srcSpec, destSpec)
+		}
 		return o.copyFromPod(srcSpec, destSpec)
 	}
 	if len(destSpec.PodName) != 0 {
+		if o.Resume {
+			return o.resumeToPod(srcSpec, destSpec)
+		}
 		return o.copyToPod(srcSpec, destSpec, &exec.ExecOptions{})
 	}
 	return fmt.Errorf("one of src or dest must be a remote file specification")
@@ -297,16 +327,39 @@ func (o *CopyOptions) copyToPod(src, dest fileSpec, options *exec.ExecOptions) e
 		destFile = destFile.Join(srcFile.Base())
 	}
 
+	var progress *progressWriter
+	if o.Progress {
+		size, err := estimateTarSize(srcFile)
+		if err != nil {
+			return err
+		}
+		progress = newProgressWriter(o.ErrOut, srcFile.String(), size, 0)
+	}
+
 	go func(src localPath, dest remotePath, writer io.WriteCloser) {
 		defer writer.Close()
-		cmdutil.CheckErr(makeTar(src, dest, writer))
+		var out io.Writer = writer
+		if o.Compress {
+			gz := gzip.NewWriter(writer)
+			defer gz.Close()
+			out = gz
+		}
+		if progress != nil {
+			defer progress.Done()
+			out = io.MultiWriter(out, progress)
+		}
+		cmdutil.CheckErr(makeTar(src, dest, out))
 	}(srcFile, destFile, writer)
 	var cmdArr []string
 
+	extractFlags := "-xmf"
+	if o.Compress {
+		extractFlags = "-xzmf"
+	}
 	if o.NoPreserve {
-		cmdArr = []string{"tar", "--no-same-permissions", "--no-same-owner", "-xmf", "-"}
+		cmdArr = []string{"tar", "--no-same-permissions", "--no-same-owner", extractFlags, "-"}
 	} else {
-		cmdArr = []string{"tar", "-xmf", "-"}
+		cmdArr = []string{"tar", extractFlags, "-"}
 	}
 	destFileDir := destFile.Dir().String()
 	if len(destFileDir) > 0 {
@@ -327,17 +380,48 @@ func (o *CopyOptions) copyToPod(src, dest fileSpec, options *exec.ExecOptions) e
 
 	options.Command = cmdArr
 	options.Executor = &exec.DefaultRemoteExecutor{}
-	return o.execute(options)
+	if err := o.execute(options); err != nil {
+		return err
+	}
+
+	if o.Checksum {
+		files, err := localFilesToCopy(srcFile, destFile)
+		if err != nil {
+			return err
+		}
+		return o.verifyChecksums(dest, files)
+	}
+	return nil
 }
 
 func (o *CopyOptions) copyFromPod(src, dest fileSpec) error {
-	reader := newTarPipe(src, o)
+	var reader io.Reader = newTarPipe(src, o)
 	srcFile := src.File.(remotePath)
 	destFile := dest.File.(localPath)
+	if o.Progress {
+		progress := newProgressWriter(o.ErrOut, srcFile.String(), 0, 0)
+		defer progress.Done()
+		reader = io.TeeReader(reader, progress)
+	}
+	if o.Compress {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		reader = gz
+	}
 	// remove extraneous path shortcuts - these could occur if a path contained extra "../"
 	// and attempted to navigate beyond "/" in a remote filesystem
 	prefix := stripPathShortcuts(srcFile.StripSlashes().Clean().String())
-	return o.untarAll(src.PodNamespace, src.PodName, prefix, srcFile, destFile, reader)
+	if err := o.untarAll(src.PodNamespace, src.PodName, prefix, srcFile, destFile, reader); err != nil {
+		return err
+	}
+
+	if o.Checksum {
+		return o.verifyChecksums(src, o.copied)
+	}
+	return nil
 }
 
 type TarPipe struct {
@@ -374,8 +458,13 @@ func (t *TarPipe) initReadFrom(n uint64) {
 		Command:  []string{"tar", "cf", "-", t.src.File.String()},
 		Executor: &exec.DefaultRemoteExecutor{},
 	}
+	createFlags := "cf"
+	if t.o.Compress {
+		createFlags = "czf"
+		options.Command[1] = createFlags
+	}
 	if t.o.MaxTries != 0 {
-		options.Command = []string{"sh", "-c", fmt.Sprintf("tar cf - %s | tail -c+%d", t.src.File, n)}
+		options.Command = []string{"sh", "-c", fmt.Sprintf("tar %s - %s | tail -c+%d", createFlags, t.src.File, n)}
 	}
 
 	go func() {
@@ -547,6 +636,13 @@ func (o *CopyOptions) untarAll(ns, pod string, prefix string, src remotePath, de
 		if err := outFile.Close(); err != nil {
 			return err
 		}
+
+		// tar strips the leading slash from absolute paths
+		remoteName := header.Name
+		if strings.HasPrefix(src.String(), "/") {
+			remoteName = "/" + remoteName
+		}
+		o.copied = append(o.copied, copiedFile{local: destFileName.String(), remote: remoteName})
 	}
 
 	return nil
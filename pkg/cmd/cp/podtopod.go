@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cp
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/kubectl/pkg/cmd/exec"
+)
+
+// copyBetweenPods streams a tar archive of src out of its container and
+// into the container of dest through the client, renaming the entries on
+// the way so that nothing is staged on the local filesystem.
+func (o *CopyOptions) copyBetweenPods(src, dest fileSpec) error {
+	srcFile := src.File.(remotePath)
+	destFile := dest.File.(remotePath)
+	if err := o.checkDestinationIsDir(dest); err == nil {
+		// If no error, dest.File was found to be a directory.
+		// Copy specified src into it
+		destFile = destFile.Join(srcFile.Base())
+	}
+
+	var source io.Reader = newTarPipe(src, o)
+	if o.Progress {
+		progress := newProgressWriter(o.ErrOut, src.PodName+":"+srcFile.String(), 0, 0)
+		defer progress.Done()
+		source = io.TeeReader(source, progress)
+	}
+	if o.Compress {
+		gz, err := gzip.NewReader(source)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		source = gz
+	}
+
+	// remove extraneous path shortcuts - these could occur if a path contained extra "../"
+	// and attempted to navigate beyond "/" in a remote filesystem
+	prefix := stripPathShortcuts(srcFile.StripSlashes().Clean().String())
+
+	reader, writer := io.Pipe()
+	renamed := make(chan []podFile, 1)
+	go func() {
+		var out io.Writer = writer
+		var gz *gzip.Writer
+		if o.Compress {
+			gz = gzip.NewWriter(writer)
+			out = gz
+		}
+		files, err := renameTarEntries(source, out, prefix, srcFile, destFile)
+		if gz != nil && err == nil {
+			err = gz.Close()
+		}
+		writer.CloseWithError(err)
+		renamed <- files
+	}()
+
+	extractFlags := "-xmf"
+	if o.Compress {
+		extractFlags = "-xzmf"
+	}
+	cmdArr := []string{"tar", extractFlags, "-"}
+	if o.NoPreserve {
+		cmdArr = []string{"tar", "--no-same-permissions", "--no-same-owner", extractFlags, "-"}
+	}
+	if destFileDir := destFile.Dir().String(); len(destFileDir) > 0 {
+		cmdArr = append(cmdArr, "-C", destFileDir)
+	}
+
+	options := &exec.ExecOptions{
+		StreamOptions: exec.StreamOptions{
+			IOStreams: genericiooptions.IOStreams{
+				In:     reader,
+				Out:    o.Out,
+				ErrOut: o.ErrOut,
+			},
+			Stdin: true,
+
+			Namespace: dest.PodNamespace,
+			PodName:   dest.PodName,
+		},
+
+		Command:  cmdArr,
+		Executor: &exec.DefaultRemoteExecutor{},
+	}
+	err := o.execute(options)
+	// unblock the renaming goroutine if the destination stopped reading early
+	reader.Close()
+	files := <-renamed
+	if err != nil {
+		return err
+	}
+
+	if o.Checksum {
+		return o.verifyChecksumsBetweenPods(src, dest, files)
+	}
+	return nil
+}
+
+// podFile is a regular file copied between two containers.
+type podFile struct {
+	src  string
+	dest string
+}
+
+// renameTarEntries copies the tar stream in to out, replacing the prefix of
+// every entry created from src with the base name of dest. Entries are
+// extracted relative to the directory of dest. It returns the regular files
+// written.
+func renameTarEntries(in io.Reader, out io.Writer, prefix string, src, dest remotePath) ([]podFile, error) {
+	var files []podFile
+	tarReader := tar.NewReader(in)
+	tarWriter := tar.NewWriter(out)
+	destBase := dest.Base().String()
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return files, err
+		}
+		// see untarAll: every entry must be below the requested path
+		if !strings.HasPrefix(header.Name, prefix) {
+			return files, fmt.Errorf("tar contents corrupted")
+		}
+		rel := newRemotePath(header.Name[len(prefix):])
+		name := newRemotePath(destBase).Join(rel).String()
+		if strings.HasPrefix(name, "../") || strings.Contains(name, "/../") {
+			return files, fmt.Errorf("tar contents corrupted")
+		}
+		if header.Typeflag == tar.TypeReg {
+			remoteName := header.Name
+			if strings.HasPrefix(src.String(), "/") {
+				remoteName = "/" + remoteName
+			}
+			files = append(files, podFile{
+				src:  remoteName,
+				dest: dest.Dir().Join(newRemotePath(name)).String(),
+			})
+		}
+		header.Name = name
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return files, err
+		}
+		if _, err := io.Copy(tarWriter, tarReader); err != nil {
+			return files, err
+		}
+	}
+	return files, tarWriter.Close()
+}
+
+// verifyChecksumsBetweenPods compares the SHA-256 digests of the copied
+// files in the source and destination containers.
+func (o *CopyOptions) verifyChecksumsBetweenPods(src, dest fileSpec, files []podFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	srcCommand := []string{"sha256sum", "--"}
+	destCommand := []string{"sha256sum", "--"}
+	for _, file := range files {
+		srcCommand = append(srcCommand, file.src)
+		destCommand = append(destCommand, file.dest)
+	}
+	srcOut, err := o.runInPod(src, srcCommand)
+	if err != nil {
+		return fmt.Errorf("unable to compute checksums in pod %s, is sha256sum installed? %v", src.PodName, err)
+	}
+	destOut, err := o.runInPod(dest, destCommand)
+	if err != nil {
+		return fmt.Errorf("unable to compute checksums in pod %s, is sha256sum installed? %v", dest.PodName, err)
+	}
+	srcSums, destSums := parseChecksums(srcOut), parseChecksums(destOut)
+
+	var mismatched []string
+	for _, file := range files {
+		if sum, ok := srcSums[file.src]; !ok || sum != destSums[file.dest] {
+			mismatched = append(mismatched, file.dest)
+		}
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("checksum verification failed for %s", strings.Join(mismatched, ", "))
+	}
+	fmt.Fprintf(o.ErrOut, "Verified checksums of %d file(s)\n", len(files))
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cp
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameTarEntries(t *testing.T) {
+	tests := []struct {
+		name          string
+		entries       []string
+		prefix        string
+		src           string
+		dest          string
+		expectedNames []string
+		expectedFiles []podFile
+		expectedErr   bool
+	}{
+		{
+			name:          "directory into renamed directory",
+			entries:       []string{"data/", "data/a", "data/sub/b"},
+			prefix:        "data",
+			src:           "/data",
+			dest:          "/backup/copy",
+			expectedNames: []string{"copy", "copy/a", "copy/sub/b"},
+			expectedFiles: []podFile{
+				{src: "/data/a", dest: "/backup/copy/a"},
+				{src: "/data/sub/b", dest: "/backup/copy/sub/b"},
+			},
+		},
+		{
+			name:          "relative single file",
+			entries:       []string{"logs/app.log"},
+			prefix:        "logs/app.log",
+			src:           "logs/app.log",
+			dest:          "/tmp/app.log",
+			expectedNames: []string{"app.log"},
+			expectedFiles: []podFile{{src: "logs/app.log", dest: "/tmp/app.log"}},
+		},
+		{
+			name:        "entry outside of the prefix",
+			entries:     []string{"data/a", "etc/passwd"},
+			prefix:      "data",
+			src:         "/data",
+			dest:        "/backup",
+			expectedErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			in := &bytes.Buffer{}
+			tw := tar.NewWriter(in)
+			for _, entry := range test.entries {
+				header := &tar.Header{Name: entry, Mode: 0644, Typeflag: tar.TypeReg}
+				if entry[len(entry)-1] == '/' {
+					header.Typeflag = tar.TypeDir
+				} else {
+					header.Size = int64(len(entry))
+				}
+				require.NoError(t, tw.WriteHeader(header))
+				if header.Typeflag == tar.TypeReg {
+					_, err := tw.Write([]byte(entry))
+					require.NoError(t, err)
+				}
+			}
+			require.NoError(t, tw.Close())
+
+			out := &bytes.Buffer{}
+			files, err := renameTarEntries(in, out, test.prefix, newRemotePath(test.src), newRemotePath(test.dest))
+			if test.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				return
+			}
+			require.NoError(t, err)
+			if !reflect.DeepEqual(files, test.expectedFiles) {
+				t.Errorf("expected files %v, got %v", test.expectedFiles, files)
+			}
+
+			var names []string
+			tr := tar.NewReader(out)
+			for {
+				header, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				names = append(names, header.Name)
+			}
+			if !reflect.DeepEqual(names, test.expectedNames) {
+				t.Errorf("expected entries %v, got %v", test.expectedNames, names)
+			}
+		})
+	}
+}
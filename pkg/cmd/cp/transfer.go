@@ -0,0 +1,519 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/kubectl/pkg/cmd/exec"
+)
+
+// progressInterval is how often the progress line is redrawn.
+const progressInterval = 500 * time.Millisecond
+
+// progressWriter counts the bytes written to it and periodically reports
+// the transfer progress on a single, redrawn line.
+type progressWriter struct {
+	out   io.Writer
+	label string
+	// total is the expected number of bytes, or 0 when unknown.
+	total int64
+
+	lock        sync.Mutex
+	initial     int64
+	transferred int64
+	start       time.Time
+	last        time.Time
+	now         func() time.Time
+}
+
+func newProgressWriter(out io.Writer, label string, total, transferred int64) *progressWriter {
+	p := &progressWriter{out: out, label: label, total: total, initial: transferred, transferred: transferred, now: time.Now}
+	p.start = p.now()
+	return p
+}
+
+func (p *progressWriter) Write(data []byte) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.transferred += int64(len(data))
+	if now := p.now(); now.Sub(p.last) >= progressInterval {
+		p.last = now
+		p.print("\r")
+	}
+	return len(data), nil
+}
+
+// Done prints the final progress line.
+func (p *progressWriter) Done() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.print("\r")
+	fmt.Fprintln(p.out)
+}
+
+func (p *progressWriter) print(prefix string) {
+	elapsed := p.now().Sub(p.start).Seconds()
+	rate := ""
+	if elapsed > 0 {
+		rate = fmt.Sprintf(" %s/s", formatBytes(int64(float64(p.transferred-p.initial)/elapsed)))
+	}
+	if p.total > 0 {
+		// the estimated size of a tar stream may be slightly off
+		percent := p.transferred * 100 / p.total
+		if percent > 100 {
+			percent = 100
+		}
+		fmt.Fprintf(p.out, "%s%s: %s / %s (%d%%)%s", prefix, p.label, formatBytes(p.transferred), formatBytes(p.total), percent, rate)
+		return
+	}
+	fmt.Fprintf(p.out, "%s%s: %s%s", prefix, p.label, formatBytes(p.transferred), rate)
+}
+
+// formatBytes renders a byte count using binary units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// estimateTarSize estimates the size of the tar stream makeTar produces for
+// src: a header block per entry, file contents padded to the block size and
+// the two trailing blocks.
+func estimateTarSize(src localPath) (int64, error) {
+	const block = 512
+	matchedPaths, err := src.Clean().Glob()
+	if err != nil {
+		return 0, err
+	}
+	var size int64 = 2 * block
+	for _, fpath := range matchedPaths {
+		err := walkLocal(fpath, func(path string, info os.FileInfo) error {
+			size += block
+			if info.Mode().IsRegular() {
+				size += (info.Size() + block - 1) / block * block
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return size, nil
+}
+
+// walkLocal calls fn for path and, if it is a directory, every entry below
+// it. Symlinks are not followed, matching recursiveTar.
+func walkLocal(path string, fn func(path string, info os.FileInfo) error) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(path, info); err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := walkLocal(newLocalPath(path).Join(newLocalPath(entry.Name())).String(), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copiedFile is a regular file transferred between the local filesystem
+// and a container, used for checksum verification.
+type copiedFile struct {
+	local  string
+	remote string
+}
+
+// localFilesToCopy lists the regular files makeTar sends for src, and the
+// remote paths they are extracted to.
+func localFilesToCopy(src localPath, dest remotePath) ([]copiedFile, error) {
+	var files []copiedFile
+	srcPath := src.Clean()
+	matchedPaths, err := srcPath.Dir().Join(srcPath.Base()).Glob()
+	if err != nil {
+		return nil, err
+	}
+	for _, fpath := range matchedPaths {
+		err := walkLocal(fpath, func(path string, info os.FileInfo) error {
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			rel := strings.TrimPrefix(path, fpath)
+			files = append(files, copiedFile{
+				local:  path,
+				remote: dest.Clean().Join(newRemotePath(rel)).String(),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// verifyChecksums compares the SHA-256 digests of the copied files on both
+// sides, using sha256sum in the container.
+func (o *CopyOptions) verifyChecksums(pod fileSpec, files []copiedFile) error {
+	if len(files) == 0 {
+		return nil
+	}
+	command := []string{"sha256sum", "--"}
+	for _, file := range files {
+		command = append(command, file.remote)
+	}
+	out, err := o.runInPod(pod, command)
+	if err != nil {
+		return fmt.Errorf("unable to compute checksums in the container, is sha256sum installed? %v", err)
+	}
+	remoteSums := parseChecksums(out)
+
+	var mismatched []string
+	for _, file := range files {
+		localSum, err := sha256File(file.local)
+		if err != nil {
+			return err
+		}
+		if remoteSums[file.remote] != localSum {
+			mismatched = append(mismatched, file.remote)
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		return fmt.Errorf("checksum verification failed for %s", strings.Join(mismatched, ", "))
+	}
+	fmt.Fprintf(o.ErrOut, "Verified checksums of %d file(s)\n", len(files))
+	return nil
+}
+
+// parseChecksums parses sha256sum output into a map of path to digest.
+func parseChecksums(out string) map[string]string {
+	sums := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		// binary mode output prefixes the name with '*'
+		sums[strings.TrimPrefix(strings.TrimSpace(fields[1]), "*")] = fields[0]
+	}
+	return sums
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// runInPod runs command in the container of pod and returns its output.
+func (o *CopyOptions) runInPod(pod fileSpec, command []string) (string, error) {
+	out := &bytes.Buffer{}
+	errOut := &bytes.Buffer{}
+	options := &exec.ExecOptions{
+		StreamOptions: exec.StreamOptions{
+			IOStreams: genericiooptions.IOStreams{
+				Out:    out,
+				ErrOut: errOut,
+			},
+
+			Namespace: pod.PodNamespace,
+			PodName:   pod.PodName,
+		},
+
+		Command:  command,
+		Executor: &exec.DefaultRemoteExecutor{},
+	}
+	if err := o.execute(options); err != nil {
+		if msg := strings.TrimSpace(errOut.String()); len(msg) > 0 {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// remoteFileSize returns the size of a regular file in the container, or
+// -1 if it does not exist.
+func (o *CopyOptions) remoteFileSize(pod fileSpec, path string) (int64, error) {
+	out, err := o.runInPod(pod, []string{"sh", "-c", `if [ -f "$1" ]; then wc -c < "$1"; elif [ -e "$1" ]; then echo "$1 is not a regular file" >&2; exit 1; else echo -1; fi`, "sh", path})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+}
+
+// resumeFromPod appends the part of the remote file src that is missing
+// from the local file dest, retrying with the remainder on failures.
+func (o *CopyOptions) resumeFromPod(src, dest fileSpec) error {
+	srcFile := src.File.(remotePath)
+	destFile := dest.File.(localPath)
+	if info, err := os.Stat(destFile.String()); err == nil && info.IsDir() {
+		destFile = destFile.Join(srcFile.Base())
+	}
+
+	size, err := o.remoteFileSize(src, srcFile.String())
+	if err != nil {
+		return err
+	}
+	if size < 0 {
+		return fmt.Errorf("%s does not exist in the container", srcFile)
+	}
+
+	lastOffset := int64(-1)
+	for tries := 0; ; tries++ {
+		offset := int64(0)
+		if info, err := os.Stat(destFile.String()); err == nil {
+			offset = info.Size()
+		}
+		if offset == lastOffset {
+			return fmt.Errorf("copy of %s made no progress at %s", srcFile, formatBytes(offset))
+		}
+		lastOffset = offset
+		if offset > size {
+			return fmt.Errorf("local file %s is larger than %s in the container, refusing to resume", destFile, srcFile)
+		}
+		if offset == size {
+			break
+		}
+		if offset > 0 {
+			fmt.Fprintf(o.ErrOut, "Resuming copy of %s at %s\n", srcFile, formatBytes(offset))
+		}
+
+		err := o.appendFromPod(src, srcFile, destFile, offset, size)
+		if err == nil {
+			continue
+		}
+		if o.MaxTries >= 0 && tries >= o.MaxTries {
+			return err
+		}
+		fmt.Fprintf(o.ErrOut, "Copy interrupted: %v, retry %d/%d\n", err, tries+1, o.MaxTries)
+	}
+
+	if o.Checksum {
+		return o.verifyChecksums(src, []copiedFile{{local: destFile.String(), remote: srcFile.String()}})
+	}
+	return nil
+}
+
+func (o *CopyOptions) appendFromPod(src fileSpec, srcFile remotePath, destFile localPath, offset, size int64) error {
+	outFile, err := os.OpenFile(destFile.String(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	script := `tail -c +$2 -- "$1"`
+	if o.Compress {
+		script += " | gzip -c"
+	}
+
+	reader, writer := io.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		options := &exec.ExecOptions{
+			StreamOptions: exec.StreamOptions{
+				IOStreams: genericiooptions.IOStreams{
+					Out:    writer,
+					ErrOut: o.ErrOut,
+				},
+
+				Namespace: src.PodNamespace,
+				PodName:   src.PodName,
+			},
+
+			Command:  []string{"sh", "-c", script, "sh", srcFile.String(), strconv.FormatInt(offset+1, 10)},
+			Executor: &exec.DefaultRemoteExecutor{},
+		}
+		err := o.execute(options)
+		writer.CloseWithError(err)
+		errCh <- err
+	}()
+
+	var in io.Reader = reader
+	if o.Compress {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			reader.CloseWithError(err)
+			<-errCh
+			return err
+		}
+		in = gz
+	}
+
+	var out io.Writer = outFile
+	if o.Progress {
+		p := newProgressWriter(o.ErrOut, srcFile.String(), size, offset)
+		defer p.Done()
+		out = io.MultiWriter(outFile, p)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		reader.CloseWithError(err)
+		<-errCh
+		return err
+	}
+	if err := <-errCh; err != nil {
+		return err
+	}
+	return outFile.Close()
+}
+
+// resumeToPod appends the part of the local file src that is missing from
+// the remote file dest, retrying with the remainder on failures.
+func (o *CopyOptions) resumeToPod(src, dest fileSpec) error {
+	srcFile := src.File.(localPath)
+	destFile := dest.File.(remotePath)
+	info, err := os.Stat(srcFile.String())
+	if err != nil {
+		return fmt.Errorf("%s doesn't exist in local filesystem", srcFile)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("--resume is only supported for regular files, %s is not one", srcFile)
+	}
+	if err := o.checkDestinationIsDir(dest); err == nil {
+		destFile = destFile.Join(srcFile.Base())
+	}
+
+	lastOffset := int64(-1)
+	for tries := 0; ; tries++ {
+		offset, err := o.remoteFileSize(dest, destFile.String())
+		if err != nil {
+			return err
+		}
+		if offset < 0 {
+			offset = 0
+		}
+		if offset == lastOffset {
+			return fmt.Errorf("copy of %s made no progress at %s", srcFile, formatBytes(offset))
+		}
+		lastOffset = offset
+		if offset > info.Size() {
+			return fmt.Errorf("%s in the container is larger than local file %s, refusing to resume", destFile, srcFile)
+		}
+		if offset == info.Size() {
+			break
+		}
+		if offset > 0 {
+			fmt.Fprintf(o.ErrOut, "Resuming copy of %s at %s\n", srcFile, formatBytes(offset))
+		}
+
+		err = o.appendToPod(dest, srcFile, destFile, offset, info.Size())
+		if err == nil {
+			continue
+		}
+		if o.MaxTries >= 0 && tries >= o.MaxTries {
+			return err
+		}
+		fmt.Fprintf(o.ErrOut, "Copy interrupted: %v, retry %d/%d\n", err, tries+1, o.MaxTries)
+	}
+
+	if o.Checksum {
+		return o.verifyChecksums(dest, []copiedFile{{local: srcFile.String(), remote: destFile.String()}})
+	}
+	return nil
+}
+
+func (o *CopyOptions) appendToPod(dest fileSpec, srcFile localPath, destFile remotePath, offset, size int64) error {
+	f, err := os.Open(srcFile.String())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	script := `cat >> "$1"`
+	if o.Compress {
+		script = `gzip -dc >> "$1"`
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		var out io.WriteCloser = writer
+		var gz *gzip.Writer
+		if o.Compress {
+			gz = gzip.NewWriter(writer)
+			out = gz
+		}
+		var in io.Reader = f
+		if o.Progress {
+			p := newProgressWriter(o.ErrOut, srcFile.String(), size, offset)
+			defer p.Done()
+			in = io.TeeReader(f, p)
+		}
+		_, err := io.Copy(out, in)
+		if gz != nil && err == nil {
+			err = gz.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	options := &exec.ExecOptions{
+		StreamOptions: exec.StreamOptions{
+			IOStreams: genericiooptions.IOStreams{
+				In:     reader,
+				Out:    o.Out,
+				ErrOut: o.ErrOut,
+			},
+			Stdin: true,
+
+			Namespace: dest.PodNamespace,
+			PodName:   dest.PodName,
+		},
+
+		Command:  []string{"sh", "-c", script, "sh", destFile.String()},
+		Executor: &exec.DefaultRemoteExecutor{},
+	}
+	return o.execute(options)
+}
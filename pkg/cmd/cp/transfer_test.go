@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cp
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                 "0 B",
+		1023:              "1023 B",
+		1024:              "1.0 KiB",
+		1536:              "1.5 KiB",
+		5 * 1024 * 1024:   "5.0 MiB",
+		3 << 30:           "3.0 GiB",
+		int64(1.5 * 1e12): "1.4 TiB",
+		int64(1<<50) * 2:  "2.0 PiB",
+	}
+	for n, expected := range tests {
+		if actual := formatBytes(n); actual != expected {
+			t.Errorf("%d: expected %q, got %q", n, expected, actual)
+		}
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	now := time.Unix(0, 0)
+	p := newProgressWriter(out, "/tmp/foo", 4096, 1024)
+	p.now = func() time.Time { return now }
+	p.start = now
+
+	now = now.Add(time.Second)
+	p.Write(make([]byte, 1024))
+	// writes within the interval do not redraw the line
+	now = now.Add(progressInterval / 2)
+	p.Write(make([]byte, 1024))
+	now = now.Add(progressInterval / 2)
+	p.Done()
+
+	expected := "\r/tmp/foo: 2.0 KiB / 4.0 KiB (50%) 1.0 KiB/s" +
+		"\r/tmp/foo: 3.0 KiB / 4.0 KiB (75%) 1.3 KiB/s\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func createTmpFileInDir(t *testing.T, path, data string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	createTmpFile(t, path, data)
+}
+
+func TestEstimateTarSize(t *testing.T) {
+	dir := t.TempDir()
+	createTmpFileInDir(t, filepath.Join(dir, "src", "a"), strings.Repeat("a", 1000))
+	createTmpFileInDir(t, filepath.Join(dir, "src", "sub", "b"), "b")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "src", "empty"), 0755))
+
+	src := newLocalPath(filepath.Join(dir, "src"))
+	estimated, err := estimateTarSize(src)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, makeTar(src, newRemotePath("/dest"), buf))
+	// the estimate counts directories that makeTar does not write headers
+	// for, and tar pads the stream to its record size
+	if diff := int64(buf.Len()) - estimated; diff < -3*512 || diff > 10240 {
+		t.Errorf("estimate %d is too far from the actual size %d", estimated, buf.Len())
+	}
+}
+
+func TestLocalFilesToCopy(t *testing.T) {
+	dir := t.TempDir()
+	createTmpFileInDir(t, filepath.Join(dir, "src", "a"), "a")
+	createTmpFileInDir(t, filepath.Join(dir, "src", "sub", "b"), "b")
+	require.NoError(t, os.Symlink("a", filepath.Join(dir, "src", "link")))
+
+	files, err := localFilesToCopy(newLocalPath(filepath.Join(dir, "src")), newRemotePath("/dest/src"))
+	require.NoError(t, err)
+	sort.Slice(files, func(i, j int) bool { return files[i].remote < files[j].remote })
+
+	expected := []copiedFile{
+		{local: filepath.Join(dir, "src", "a"), remote: "/dest/src/a"},
+		{local: filepath.Join(dir, "src", "sub", "b"), remote: "/dest/src/sub/b"},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	out := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  /tmp/empty\n" +
+		"ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb *a file\n\n"
+	expected := map[string]string{
+		"/tmp/empty": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"a file":     "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb",
+	}
+	if actual := parseChecksums(out); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	dir := t.TempDir()
+	createTmpFile(t, filepath.Join(dir, "a"), "a")
+	sum, err := sha256File(filepath.Join(dir, "a"))
+	require.NoError(t, err)
+	if sum != expected["a file"] {
+		t.Errorf("expected %s, got %s", expected["a file"], sum)
+	}
+}
+
+func TestUntarCompressedRecordsCopiedFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	// the output of "tar czf - /tmp/src" in the container
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{"tmp/src/a": "a", "tmp/src/sub/b": "b"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	reader, err := gzip.NewReader(buf)
+	require.NoError(t, err)
+	dest := filepath.Join(dir, "dest")
+	opts := NewCopyOptions(genericiooptions.NewTestIOStreamsDiscard())
+	require.NoError(t, opts.untarAll("", "", "tmp/src", newRemotePath("/tmp/src"), newLocalPath(dest), reader))
+
+	cmpFileData(t, filepath.Join(dest, "a"), "a")
+	cmpFileData(t, filepath.Join(dest, "sub", "b"), "b")
+	sort.Slice(opts.copied, func(i, j int) bool { return opts.copied[i].remote < opts.copied[j].remote })
+	expected := []copiedFile{
+		{local: filepath.Join(dest, "a"), remote: "/tmp/src/a"},
+		{local: filepath.Join(dest, "sub", "b"), remote: "/tmp/src/sub/b"},
+	}
+	if !reflect.DeepEqual(opts.copied, expected) {
+		t.Errorf("expected %v, got %v", expected, opts.copied)
+	}
+}
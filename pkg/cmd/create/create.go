@@ -44,6 +44,7 @@ import (
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util"
 	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/manifestsource"
 	"k8s.io/kubectl/pkg/util/templates"
 )
 
@@ -82,6 +83,9 @@ var (
 		# Create a pod based on the JSON passed into stdin
 		cat pod.json | kubectl create -f -
 
+		# Create the resources of an OCI artifact
+		kubectl create -f oci://registry.example.com/manifests/app:v1.2
+
 		# Edit the data in registry.yaml in JSON then create the resource using the edited data
 		kubectl create -f registry.yaml --edit -o json`))
 )
@@ -152,6 +156,8 @@ func NewCmdCreate(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobr
 	cmd.AddCommand(NewCmdCreateRoleBinding(f, ioStreams))
 	cmd.AddCommand(NewCmdCreatePodDisruptionBudget(f, ioStreams))
 	cmd.AddCommand(NewCmdCreatePriorityClass(f, ioStreams))
+	cmd.AddCommand(NewCmdCreatePersistentVolumeClaim(f, ioStreams))
+	cmd.AddCommand(NewCmdCreateVolumeSnapshot(f, ioStreams))
 	cmd.AddCommand(NewCmdCreateJob(f, ioStreams))
 	cmd.AddCommand(NewCmdCreateCronJob(f, ioStreams))
 	cmd.AddCommand(NewCmdCreateIngress(f, ioStreams))
@@ -235,6 +241,12 @@ func (o *CreateOptions) RunCreate(f cmdutil.Factory, cmd *cobra.Command) error {
 		return rawhttp.RawPost(restClient, o.IOStreams, o.Raw, o.FilenameOptions.Filenames[0])
 	}
 
+	cleanup, err := manifestsource.Fetch(&o.FilenameOptions)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
 	if o.EditBeforeCreate {
 		return RunEditOnCreate(f, o.PrintFlags, o.RecordFlags, o.IOStreams, cmd, &o.FilenameOptions, o.fieldManager)
 	}
@@ -21,12 +21,14 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"unicode/utf8"
 
 	"github.com/spf13/cobra"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation"
@@ -52,7 +54,11 @@ var (
 
 		When creating a config map based on a directory, each file whose basename is a valid key in the directory will be
 		packaged into the config map.  Any directory entries except regular files are ignored (e.g. subdirectories,
-		symlinks, devices, pipes, etc).`))
+		symlinks, devices, pipes, etc). Directories given with --from-dir are packaged the same way, keeping only the
+		files whose basename matches an --include pattern, if any, and no --exclude pattern.
+
+		Files that are not valid UTF-8 are packaged as binary data. The config map is rejected before it is sent to the
+		server if its keys and values are larger than --max-size.`))
 
 	configMapExample = templates.Examples(i18n.T(`
 		  # Create a new config map named my-config based on folder bar
@@ -68,7 +74,10 @@ var (
 		  kubectl create configmap my-config --from-file=path/to/bar
 
 		  # Create a new config map named my-config from an env file
-		  kubectl create configmap my-config --from-env-file=path/to/foo.env --from-env-file=path/to/bar.env`))
+		  kubectl create configmap my-config --from-env-file=path/to/foo.env --from-env-file=path/to/bar.env
+
+		  # Create a new config map named my-config from the .conf files of a folder, except the backups
+		  kubectl create configmap my-config --from-dir=path/to/bar --include='*.conf' --exclude='*.bak'`))
 )
 
 // ConfigMapOptions holds properties for create configmap sub-command
@@ -87,6 +96,14 @@ type ConfigMapOptions struct {
 	LiteralSources []string
 	// EnvFileSources to derive the configMap from (optional)
 	EnvFileSources []string
+	// DirSources are directories to derive the configMap from (optional)
+	DirSources []string
+	// IncludePatterns select the files of DirSources by basename (optional)
+	IncludePatterns []string
+	// ExcludePatterns exclude files of DirSources by basename (optional)
+	ExcludePatterns []string
+	// MaxSize is the maximum size of the keys and values of the configMap, 0 for no limit
+	MaxSize string
 	// AppendHash; if true, derive a hash from the ConfigMap and append it to the name
 	AppendHash bool
 
@@ -106,6 +123,7 @@ type ConfigMapOptions struct {
 func NewConfigMapOptions(ioStreams genericiooptions.IOStreams) *ConfigMapOptions {
 	return &ConfigMapOptions{
 		PrintFlags: genericclioptions.NewPrintFlags("created").WithTypeSetter(scheme.Scheme),
+		MaxSize:    "1Mi",
 		IOStreams:  ioStreams,
 	}
 }
@@ -115,7 +133,7 @@ func NewCmdCreateConfigMap(f cmdutil.Factory, ioStreams genericiooptions.IOStrea
 	o := NewConfigMapOptions(ioStreams)
 
 	cmd := &cobra.Command{
-		Use:                   "configmap NAME [--from-file=[key=]source] [--from-literal=key1=value1] [--dry-run=server|client|none]",
+		Use:                   "configmap NAME [--from-file=[key=]source] [--from-literal=key1=value1] [--from-dir=dir] [--dry-run=server|client|none]",
 		DisableFlagsInUseLine: true,
 		Aliases:               []string{"cm"},
 		Short:                 i18n.T("Create a config map from a local file, directory or literal value"),
@@ -136,6 +154,10 @@ func NewCmdCreateConfigMap(f cmdutil.Factory, ioStreams genericiooptions.IOStrea
 	cmd.Flags().StringSliceVar(&o.FileSources, "from-file", o.FileSources, "Key file can be specified using its file path, in which case file basename will be used as configmap key, or optionally with a key and file path, in which case the given key will be used.  Specifying a directory will iterate each named file in the directory whose basename is a valid configmap key.")
 	cmd.Flags().StringArrayVar(&o.LiteralSources, "from-literal", o.LiteralSources, "Specify a key and literal value to insert in configmap (i.e. mykey=somevalue)")
 	cmd.Flags().StringSliceVar(&o.EnvFileSources, "from-env-file", o.EnvFileSources, "Specify the path to a file to read lines of key=val pairs to create a configmap.")
+	cmd.Flags().StringSliceVar(&o.DirSources, "from-dir", o.DirSources, "Specify a directory whose files, filtered by --include and --exclude, are inserted in configmap with their basename as key.")
+	cmd.Flags().StringSliceVar(&o.IncludePatterns, "include", o.IncludePatterns, "Only insert the files of --from-dir whose basename matches one of these glob patterns (e.g. '*.conf').")
+	cmd.Flags().StringSliceVar(&o.ExcludePatterns, "exclude", o.ExcludePatterns, "Skip the files of --from-dir whose basename matches one of these glob patterns (e.g. '*.bak').")
+	cmd.Flags().StringVar(&o.MaxSize, "max-size", o.MaxSize, "Fail if the keys and values of the configmap are larger than this size. 0 disables the check.")
 	cmd.Flags().BoolVar(&o.AppendHash, "append-hash", o.AppendHash, "Append a hash of the configmap to its name.")
 
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-create")
@@ -198,6 +220,22 @@ func (o *ConfigMapOptions) Validate() error {
 	if len(o.EnvFileSources) > 0 && (len(o.FileSources) > 0 || len(o.LiteralSources) > 0) {
 		return fmt.Errorf("from-env-file cannot be combined with from-file or from-literal")
 	}
+	if len(o.EnvFileSources) > 0 && len(o.DirSources) > 0 {
+		return fmt.Errorf("from-env-file cannot be combined with from-dir")
+	}
+	if len(o.DirSources) == 0 && (len(o.IncludePatterns) > 0 || len(o.ExcludePatterns) > 0) {
+		return fmt.Errorf("include and exclude require from-dir")
+	}
+	for _, pattern := range append(append([]string{}, o.IncludePatterns...), o.ExcludePatterns...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	if len(o.MaxSize) > 0 {
+		if size, err := resource.ParseQuantity(o.MaxSize); err != nil || size.Sign() < 0 {
+			return fmt.Errorf("invalid max-size %q, expected a size such as 1Mi", o.MaxSize)
+		}
+	}
 	return nil
 }
 
@@ -265,6 +303,14 @@ func (o *ConfigMapOptions) createConfigMap() (*corev1.ConfigMap, error) {
 			return nil, err
 		}
 	}
+	if len(o.DirSources) > 0 {
+		if err := handleConfigMapFromDirSources(configMap, o.DirSources, o.IncludePatterns, o.ExcludePatterns); err != nil {
+			return nil, err
+		}
+	}
+	if err := o.checkSize(configMap); err != nil {
+		return nil, err
+	}
 	if o.AppendHash {
 		hash, err := hash.ConfigMapHash(configMap)
 		if err != nil {
@@ -339,6 +385,69 @@ func handleConfigMapFromFileSources(configMap *corev1.ConfigMap, fileSources []s
 	return nil
 }
 
+// handleConfigMapFromDirSources adds the regular files of the directories
+// whose basename matches an include pattern, if any, and no exclude pattern
+// into the provided configMap.
+func handleConfigMapFromDirSources(configMap *corev1.ConfigMap, dirSources, includePatterns, excludePatterns []string) error {
+	for _, dirSource := range dirSources {
+		fileList, err := os.ReadDir(dirSource)
+		if err != nil {
+			return fmt.Errorf("error listing files in %s: %v", dirSource, err)
+		}
+		for _, item := range fileList {
+			if !item.Type().IsRegular() || !matchesFilePatterns(item.Name(), includePatterns, excludePatterns) {
+				continue
+			}
+			if err := addKeyFromFileToConfigMap(configMap, item.Name(), path.Join(dirSource, item.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchesFilePatterns returns true if the name matches one of the include
+// patterns, or there are none, and none of the exclude patterns.
+func matchesFilePatterns(name string, includePatterns, excludePatterns []string) bool {
+	for _, pattern := range excludePatterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return false
+		}
+	}
+	for _, pattern := range includePatterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return len(includePatterns) == 0
+}
+
+// checkSize returns an error if the keys and values of the config map are
+// larger than MaxSize, as the server would reject it.
+func (o *ConfigMapOptions) checkSize(configMap *corev1.ConfigMap) error {
+	if len(o.MaxSize) == 0 {
+		return nil
+	}
+	maxSize, err := resource.ParseQuantity(o.MaxSize)
+	if err != nil {
+		return err
+	}
+	if maxSize.IsZero() {
+		return nil
+	}
+	size := 0
+	for key, value := range configMap.Data {
+		size += len(key) + len(value)
+	}
+	for key, value := range configMap.BinaryData {
+		size += len(key) + len(value)
+	}
+	if int64(size) > maxSize.Value() {
+		return fmt.Errorf("config map %q holds %d bytes of data, more than the maximum of %s", configMap.Name, size, o.MaxSize)
+	}
+	return nil
+}
+
 // handleConfigMapFromEnvFileSources adds the specified env file source information
 // into the provided configMap
 func handleConfigMapFromEnvFileSources(configMap *corev1.ConfigMap, envFileSources []string) error {
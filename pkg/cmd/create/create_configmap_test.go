@@ -18,6 +18,7 @@ package create
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -503,3 +504,72 @@ func setupBinaryFile(data []byte) func(*testing.T, *ConfigMapOptions) func() {
 		}
 	}
 }
+
+func TestCreateConfigMapFromDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"app.conf":     []byte("port=80"),
+		"app.conf.bak": []byte("port=8080"),
+		"db.conf":      []byte("host=db"),
+		"logo.bin":     {0xff, 0xfe, 0x00},
+		"README":       []byte("docs"),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "nested.conf"), 0755))
+
+	tests := map[string]struct {
+		include   []string
+		exclude   []string
+		maxSize   string
+		expected  map[string]string
+		binary    map[string][]byte
+		expectErr string
+	}{
+		"all files": {
+			expected: map[string]string{"app.conf": "port=80", "app.conf.bak": "port=8080", "db.conf": "host=db", "README": "docs"},
+			binary:   map[string][]byte{"logo.bin": {0xff, 0xfe, 0x00}},
+		},
+		"include and exclude": {
+			include:  []string{"*.conf*", "*.bin"},
+			exclude:  []string{"*.bak"},
+			expected: map[string]string{"app.conf": "port=80", "db.conf": "host=db"},
+			binary:   map[string][]byte{"logo.bin": {0xff, 0xfe, 0x00}},
+		},
+		"too large": {
+			include:   []string{"*.conf"},
+			maxSize:   "20",
+			expectErr: `config map "foo" holds 29 bytes of data, more than the maximum of 20`,
+		},
+		"invalid pattern": {
+			include:   []string{"[conf"},
+			expectErr: `invalid pattern "[conf": syntax error in pattern`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := ConfigMapOptions{
+				Name:            "foo",
+				DirSources:      []string{dir},
+				IncludePatterns: test.include,
+				ExcludePatterns: test.exclude,
+				MaxSize:         test.maxSize,
+			}
+			err := o.Validate()
+			var configMap *corev1.ConfigMap
+			if err == nil {
+				configMap, err = o.createConfigMap()
+			}
+			if len(test.expectErr) > 0 {
+				require.EqualError(t, err, test.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, configMap.Data)
+			require.Equal(t, test.binary, configMap.BinaryData)
+		})
+	}
+
+	o := ConfigMapOptions{Name: "foo", ExcludePatterns: []string{"*.bak"}}
+	require.EqualError(t, o.Validate(), "include and exclude require from-dir")
+}
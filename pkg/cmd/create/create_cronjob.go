@@ -19,6 +19,8 @@ package create
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -26,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -39,14 +42,27 @@ import (
 
 var (
 	cronjobLong = templates.LongDesc(i18n.T(`
-		Create a cron job with the specified name.`))
+		Create a cron job with the specified name.
+
+		The history limits and the starting deadline are left to the server defaults
+		unless they are given.`))
 
 	cronjobExample = templates.Examples(`
 		# Create a cron job
 		kubectl create cronjob my-job --image=busybox --schedule="*/1 * * * *"
 
 		# Create a cron job with a command
-		kubectl create cronjob my-job --image=busybox --schedule="*/1 * * * *" -- date`)
+		kubectl create cronjob my-job --image=busybox --schedule="*/1 * * * *" -- date
+
+		# Create a cron job that runs at 2am in Berlin, never runs concurrently and keeps one failed job
+		kubectl create cronjob my-job --image=busybox --schedule="0 2 * * *" --timezone=Europe/Berlin \
+		  --concurrency-policy=Forbid --failed-history-limit=1 --starting-deadline-seconds=300`)
+
+	validConcurrencyPolicies = []string{
+		string(batchv1.AllowConcurrent),
+		string(batchv1.ForbidConcurrent),
+		string(batchv1.ReplaceConcurrent),
+	}
 )
 
 // CreateCronJobOptions is returned by NewCreateCronJobOptions
@@ -61,6 +77,12 @@ type CreateCronJobOptions struct {
 	Command  []string
 	Restart  string
 
+	TimeZone                string
+	ConcurrencyPolicy       string
+	SuccessfulHistoryLimit  *int32
+	FailedHistoryLimit      *int32
+	StartingDeadlineSeconds *int64
+
 	Namespace           string
 	EnforceNamespace    bool
 	Client              batchv1client.BatchV1Interface
@@ -93,6 +115,7 @@ func NewCmdCreateCronJob(f cmdutil.Factory, ioStreams genericiooptions.IOStreams
 		Example:               cronjobExample,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
 			cmdutil.CheckErr(o.Run())
 		},
 	}
@@ -107,6 +130,11 @@ func NewCmdCreateCronJob(f cmdutil.Factory, ioStreams genericiooptions.IOStreams
 	cmd.Flags().StringVar(&o.Schedule, "schedule", o.Schedule, "A schedule in the Cron format the job should be run with.")
 	cmd.MarkFlagRequired("schedule")
 	cmd.Flags().StringVar(&o.Restart, "restart", o.Restart, "job's restart policy. supported values: OnFailure, Never")
+	cmd.Flags().StringVar(&o.TimeZone, "timezone", o.TimeZone, "The time zone name of the schedule, such as 'Etc/UTC'. Defaults to the time zone of the kube-controller-manager.")
+	cmd.Flags().StringVar(&o.ConcurrencyPolicy, "concurrency-policy", o.ConcurrencyPolicy, fmt.Sprintf("How to treat concurrent executions of a job. One of: %s.", strings.Join(validConcurrencyPolicies, ", ")))
+	cmd.Flags().Int32("successful-history-limit", 3, "The number of successful finished jobs to retain.")
+	cmd.Flags().Int32("failed-history-limit", 1, "The number of failed finished jobs to retain.")
+	cmd.Flags().Int64("starting-deadline-seconds", 0, "Deadline in seconds for starting a job that missed its scheduled time.")
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-create")
 
 	return cmd
@@ -125,6 +153,18 @@ func (o *CreateCronJobOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, a
 	if len(o.Restart) == 0 {
 		o.Restart = "OnFailure"
 	}
+	if cmd.Flags().Changed("successful-history-limit") {
+		limit := cmdutil.GetFlagInt32(cmd, "successful-history-limit")
+		o.SuccessfulHistoryLimit = &limit
+	}
+	if cmd.Flags().Changed("failed-history-limit") {
+		limit := cmdutil.GetFlagInt32(cmd, "failed-history-limit")
+		o.FailedHistoryLimit = &limit
+	}
+	if cmd.Flags().Changed("starting-deadline-seconds") {
+		deadline := cmdutil.GetFlagInt64(cmd, "starting-deadline-seconds")
+		o.StartingDeadlineSeconds = &deadline
+	}
 
 	clientConfig, err := f.ToRESTConfig()
 	if err != nil {
@@ -164,6 +204,28 @@ func (o *CreateCronJobOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, a
 	return nil
 }
 
+// Validate makes sure provided values and valid CronJob options
+func (o *CreateCronJobOptions) Validate() error {
+	if len(o.TimeZone) > 0 {
+		if _, err := time.LoadLocation(o.TimeZone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %v", o.TimeZone, err)
+		}
+	}
+	if len(o.ConcurrencyPolicy) > 0 && !sets.New(validConcurrencyPolicies...).Has(o.ConcurrencyPolicy) {
+		return fmt.Errorf("invalid concurrency-policy %q: must be one of %s", o.ConcurrencyPolicy, strings.Join(validConcurrencyPolicies, ", "))
+	}
+	if o.SuccessfulHistoryLimit != nil && *o.SuccessfulHistoryLimit < 0 {
+		return fmt.Errorf("--successful-history-limit must not be negative")
+	}
+	if o.FailedHistoryLimit != nil && *o.FailedHistoryLimit < 0 {
+		return fmt.Errorf("--failed-history-limit must not be negative")
+	}
+	if o.StartingDeadlineSeconds != nil && *o.StartingDeadlineSeconds < 0 {
+		return fmt.Errorf("--starting-deadline-seconds must not be negative")
+	}
+	return nil
+}
+
 // Run performs the execution of 'create cronjob' sub command
 func (o *CreateCronJobOptions) Run() error {
 	cronJob := o.createCronJob()
@@ -197,7 +259,11 @@ func (o *CreateCronJobOptions) createCronJob() *batchv1.CronJob {
 			Name: o.Name,
 		},
 		Spec: batchv1.CronJobSpec{
-			Schedule: o.Schedule,
+			Schedule:                   o.Schedule,
+			ConcurrencyPolicy:          batchv1.ConcurrencyPolicy(o.ConcurrencyPolicy),
+			SuccessfulJobsHistoryLimit: o.SuccessfulHistoryLimit,
+			FailedJobsHistoryLimit:     o.FailedHistoryLimit,
+			StartingDeadlineSeconds:    o.StartingDeadlineSeconds,
 			JobTemplate: batchv1.JobTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: o.Name,
@@ -219,6 +285,9 @@ func (o *CreateCronJobOptions) createCronJob() *batchv1.CronJob {
 			},
 		},
 	}
+	if len(o.TimeZone) > 0 {
+		cronjob.Spec.TimeZone = &o.TimeZone
+	}
 	if o.EnforceNamespace {
 		cronjob.Namespace = o.Namespace
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package create
 
 import (
+	"strings"
 	"testing"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -118,3 +119,83 @@ func TestCreateCronJob(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateCronJobSchedulingOptions(t *testing.T) {
+	timeZone := "Europe/Berlin"
+	successful := int32(5)
+	failed := int32(0)
+	deadline := int64(300)
+	o := &CreateCronJobOptions{
+		Name:                    "test-job",
+		Image:                   "busybox",
+		Schedule:                "0 2 * * *",
+		Restart:                 "OnFailure",
+		TimeZone:                timeZone,
+		ConcurrencyPolicy:       "Forbid",
+		SuccessfulHistoryLimit:  &successful,
+		FailedHistoryLimit:      &failed,
+		StartingDeadlineSeconds: &deadline,
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := batchv1.CronJobSpec{
+		Schedule:                   "0 2 * * *",
+		TimeZone:                   &timeZone,
+		ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+		SuccessfulJobsHistoryLimit: &successful,
+		FailedJobsHistoryLimit:     &failed,
+		StartingDeadlineSeconds:    &deadline,
+	}
+	spec := o.createCronJob().Spec
+	spec.JobTemplate = batchv1.JobTemplateSpec{}
+	if !apiequality.Semantic.DeepEqual(spec, expected) {
+		t.Errorf("expected:\n%#v\ngot:\n%#v", expected, spec)
+	}
+}
+
+func TestCreateCronJobValidation(t *testing.T) {
+	negative := int32(-1)
+	negativeDeadline := int64(-1)
+	tests := map[string]struct {
+		options  CreateCronJobOptions
+		expected string
+	}{
+		"no options": {},
+		"valid options": {
+			options: CreateCronJobOptions{TimeZone: "Etc/UTC", ConcurrencyPolicy: "Replace"},
+		},
+		"unknown timezone": {
+			options:  CreateCronJobOptions{TimeZone: "Mars/Olympus"},
+			expected: `invalid timezone "Mars/Olympus"`,
+		},
+		"unknown concurrency policy": {
+			options:  CreateCronJobOptions{ConcurrencyPolicy: "Queue"},
+			expected: `invalid concurrency-policy "Queue": must be one of Allow, Forbid, Replace`,
+		},
+		"negative successful history limit": {
+			options:  CreateCronJobOptions{SuccessfulHistoryLimit: &negative},
+			expected: "--successful-history-limit must not be negative",
+		},
+		"negative failed history limit": {
+			options:  CreateCronJobOptions{FailedHistoryLimit: &negative},
+			expected: "--failed-history-limit must not be negative",
+		},
+		"negative starting deadline": {
+			options:  CreateCronJobOptions{StartingDeadlineSeconds: &negativeDeadline},
+			expected: "--starting-deadline-seconds must not be negative",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.options.Validate()
+			switch {
+			case len(tc.expected) == 0 && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case len(tc.expected) > 0 && (err == nil || !strings.HasPrefix(err.Error(), tc.expected)):
+				t.Errorf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
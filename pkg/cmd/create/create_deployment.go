@@ -25,13 +25,17 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	appsv1client "k8s.io/client-go/kubernetes/typed/apps/v1"
+	envutil "k8s.io/kubectl/pkg/cmd/set/env"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/generate"
+	generateversioned "k8s.io/kubectl/pkg/generate/versioned"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util"
 	"k8s.io/kubectl/pkg/util/i18n"
@@ -40,7 +44,11 @@ import (
 
 var (
 	deploymentLong = templates.LongDesc(i18n.T(`
-	Create a deployment with the specified name.`))
+	Create a deployment with the specified name.
+
+	The environment variables, resources and volume mounts given are set on every container.
+	Volumes are given as TYPE:NAME:PATH[:ro], where TYPE is pvc, configmap, secret or emptydir.
+	The pods are selected with the app label, which defaults to the name of the deployment.`))
 
 	deploymentExample = templates.Examples(i18n.T(`
 	# Create a deployment named my-dep that runs the busybox image
@@ -56,7 +64,11 @@ var (
 	kubectl create deployment my-dep --image=busybox --port=5701
 
 	# Create a deployment named my-dep that runs multiple containers
-	kubectl create deployment my-dep --image=busybox:latest --image=ubuntu:latest --image=nginx`))
+	kubectl create deployment my-dep --image=busybox:latest --image=ubuntu:latest --image=nginx
+
+	# Create a deployment with environment variables, resources, a volume and labels
+	kubectl create deployment my-dep --image=nginx --env=MODE=prod --requests=cpu=100m,memory=128Mi --limits=memory=256Mi \
+	  --volume=pvc:my-data:/data --service-account=my-sa --node-selector=disktype=ssd --labels=tier=web`))
 )
 
 // CreateDeploymentOptions is returned by NewCmdCreateDeployment
@@ -70,6 +82,13 @@ type CreateDeploymentOptions struct {
 	Port             int32
 	Replicas         int32
 	Command          []string
+	Env              []string
+	Limits           string
+	Requests         string
+	Volumes          []string
+	ServiceAccount   string
+	NodeSelector     string
+	Labels           string
 	Namespace        string
 	EnforceNamespace bool
 	FieldManager     string
@@ -119,6 +138,13 @@ func NewCmdCreateDeployment(f cmdutil.Factory, ioStreams genericiooptions.IOStre
 	cmd.MarkFlagRequired("image")
 	cmd.Flags().Int32Var(&o.Port, "port", o.Port, "The containerPort that this deployment exposes.")
 	cmd.Flags().Int32VarP(&o.Replicas, "replicas", "r", o.Replicas, "Number of replicas to create. Default is 1.")
+	cmd.Flags().StringArrayVar(&o.Env, "env", o.Env, "Environment variables to set in the containers (e.g. KEY=VALUE).")
+	cmd.Flags().StringVar(&o.Limits, "limits", o.Limits, "The resource limits of the containers, such as 'cpu=200m,memory=512Mi'.")
+	cmd.Flags().StringVar(&o.Requests, "requests", o.Requests, "The resource requests of the containers, such as 'cpu=100m,memory=256Mi'.")
+	cmd.Flags().StringArrayVar(&o.Volumes, "volume", o.Volumes, "A volume to mount in the containers, as TYPE:NAME:PATH[:ro] where TYPE is pvc, configmap, secret or emptydir.")
+	cmd.Flags().StringVar(&o.ServiceAccount, "service-account", o.ServiceAccount, "The service account to run the pods with.")
+	cmd.Flags().StringVar(&o.NodeSelector, "node-selector", o.NodeSelector, "Comma separated labels of the nodes the pods may run on, such as 'disktype=ssd'.")
+	cmd.Flags().StringVarP(&o.Labels, "labels", "l", o.Labels, "Comma separated labels to apply to the deployment and its pods, in addition to app=NAME.")
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-create")
 
 	return cmd
@@ -183,7 +209,10 @@ func (o *CreateDeploymentOptions) Validate() error {
 
 // Run performs the execution of 'create deployment' sub command
 func (o *CreateDeploymentOptions) Run() error {
-	deploy := o.createDeployment()
+	deploy, err := o.createDeployment()
+	if err != nil {
+		return err
+	}
 
 	if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, deploy, scheme.DefaultJSONEncoder()); err != nil {
 		return err
@@ -198,7 +227,6 @@ func (o *CreateDeploymentOptions) Run() error {
 		if o.DryRunStrategy == cmdutil.DryRunServer {
 			createOptions.DryRun = []string{metav1.DryRunAll}
 		}
-		var err error
 		deploy, err = o.Client.Deployments(o.Namespace).Create(context.TODO(), deploy, createOptions)
 		if err != nil {
 			return fmt.Errorf("failed to create deployment: %v", err)
@@ -208,9 +236,22 @@ func (o *CreateDeploymentOptions) Run() error {
 	return o.PrintObj(deploy)
 }
 
-func (o *CreateDeploymentOptions) createDeployment() *appsv1.Deployment {
+func (o *CreateDeploymentOptions) createDeployment() (*appsv1.Deployment, error) {
 	labels := map[string]string{"app": o.Name}
-	selector := metav1.LabelSelector{MatchLabels: labels}
+	if len(o.Labels) > 0 {
+		extra, err := generate.ParseLabels(o.Labels)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range extra {
+			labels[key] = value
+		}
+	}
+	selector := metav1.LabelSelector{MatchLabels: map[string]string{"app": labels["app"]}}
+	podSpec, err := o.buildPodSpec()
+	if err != nil {
+		return nil, err
+	}
 	namespace := ""
 	if o.EnforceNamespace {
 		namespace = o.Namespace
@@ -230,7 +271,7 @@ func (o *CreateDeploymentOptions) createDeployment() *appsv1.Deployment {
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: labels,
 				},
-				Spec: o.buildPodSpec(),
+				Spec: podSpec,
 			},
 		},
 	}
@@ -238,13 +279,59 @@ func (o *CreateDeploymentOptions) createDeployment() *appsv1.Deployment {
 	if o.Port >= 0 && len(deploy.Spec.Template.Spec.Containers) > 0 {
 		deploy.Spec.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{{ContainerPort: o.Port}}
 	}
-	return deploy
+	return deploy, nil
 }
 
 // buildPodSpec parses the image strings and assemble them into the Containers
-// of a PodSpec. This is all you need to create the PodSpec for a deployment.
-func (o *CreateDeploymentOptions) buildPodSpec() corev1.PodSpec {
-	podSpec := corev1.PodSpec{Containers: []corev1.Container{}}
+// of a PodSpec, along with the environment, resources, volumes, service
+// account and node selector given.
+func (o *CreateDeploymentOptions) buildPodSpec() (corev1.PodSpec, error) {
+	podSpec := corev1.PodSpec{
+		Containers:         []corev1.Container{},
+		ServiceAccountName: o.ServiceAccount,
+	}
+	if len(o.NodeSelector) > 0 {
+		nodeSelector, err := generate.ParseLabels(o.NodeSelector)
+		if err != nil {
+			return podSpec, fmt.Errorf("invalid node selector: %v", err)
+		}
+		podSpec.NodeSelector = nodeSelector
+	}
+	env, remove, _, err := envutil.ParseEnv(o.Env, nil)
+	if err != nil {
+		return podSpec, err
+	}
+	if len(remove) > 0 {
+		return podSpec, fmt.Errorf("environment variables cannot be removed from a new deployment: %s", strings.Join(remove, ", "))
+	}
+	if len(env) == 0 {
+		env = nil
+	}
+	resources, err := generateversioned.HandleResourceRequirementsV1(map[string]string{"limits": o.Limits, "requests": o.Requests})
+	if err != nil {
+		return podSpec, err
+	}
+	var mounts []corev1.VolumeMount
+	for _, spec := range o.Volumes {
+		volume, mount, err := parseVolume(spec)
+		if err != nil {
+			return podSpec, err
+		}
+		found := false
+		for _, existing := range podSpec.Volumes {
+			if existing.Name == volume.Name {
+				if !apiequality.Semantic.DeepEqual(existing, volume) {
+					return podSpec, fmt.Errorf("volume %q is given with different sources", volume.Name)
+				}
+				found = true
+			}
+		}
+		if !found {
+			podSpec.Volumes = append(podSpec.Volumes, volume)
+		}
+		mounts = append(mounts, mount)
+	}
+
 	for _, imageString := range o.Images {
 		// Retain just the image name
 		imageSplit := strings.Split(imageString, "/")
@@ -258,12 +345,44 @@ func (o *CreateDeploymentOptions) buildPodSpec() corev1.PodSpec {
 		}
 		name = sanitizeAndUniquify(name)
 		podSpec.Containers = append(podSpec.Containers, corev1.Container{
-			Name:    name,
-			Image:   imageString,
-			Command: o.Command,
+			Name:         name,
+			Image:        imageString,
+			Command:      o.Command,
+			Env:          env,
+			Resources:    *resources.DeepCopy(),
+			VolumeMounts: append([]corev1.VolumeMount(nil), mounts...),
 		})
 	}
-	return podSpec
+	return podSpec, nil
+}
+
+// parseVolume parses a TYPE:NAME:PATH[:ro] volume into a volume of the pod
+// and its mount.
+func parseVolume(spec string) (corev1.Volume, corev1.VolumeMount, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 3 || len(parts) > 4 || len(parts[1]) == 0 || (len(parts) == 4 && parts[3] != "ro") {
+		return corev1.Volume{}, corev1.VolumeMount{}, fmt.Errorf("invalid volume %q, expected TYPE:NAME:PATH[:ro]", spec)
+	}
+	volumeType, name, mountPath := parts[0], parts[1], parts[2]
+	if !strings.HasPrefix(mountPath, "/") {
+		return corev1.Volume{}, corev1.VolumeMount{}, fmt.Errorf("invalid volume %q, the mount path must be absolute", spec)
+	}
+
+	volume := corev1.Volume{Name: strings.ReplaceAll(name, ".", "-")}
+	switch volumeType {
+	case "pvc":
+		volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: name}
+	case "configmap":
+		volume.ConfigMap = &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}}
+	case "secret":
+		volume.Secret = &corev1.SecretVolumeSource{SecretName: name}
+	case "emptydir":
+		volume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+	default:
+		return corev1.Volume{}, corev1.VolumeMount{}, fmt.Errorf("invalid volume %q, the type must be one of pvc, configmap, secret or emptydir", spec)
+	}
+	mount := corev1.VolumeMount{Name: volume.Name, MountPath: mountPath, ReadOnly: len(parts) == 4}
+	return volume, mount, nil
 }
 
 // sanitizeAndUniquify replaces characters like "." or "_" into "-" to follow DNS1123 rules.
@@ -25,6 +25,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	restclient "k8s.io/client-go/rest"
@@ -161,3 +162,60 @@ func TestCreateDeploymentNoImage(t *testing.T) {
 	err = options.Run()
 	assert.Error(t, err, "at least one image must be specified")
 }
+
+func TestCreateDeploymentPodTemplate(t *testing.T) {
+	o := &CreateDeploymentOptions{
+		Name:           "web",
+		Images:         []string{"nginx", "busybox"},
+		Replicas:       1,
+		Port:           -1,
+		Env:            []string{"MODE=prod"},
+		Limits:         "memory=256Mi",
+		Requests:       "cpu=100m,memory=128Mi",
+		Volumes:        []string{"pvc:web-data:/data", "configmap:web.conf:/etc/web:ro", "pvc:web-data:/backup"},
+		ServiceAccount: "web-sa",
+		NodeSelector:   "disktype=ssd",
+		Labels:         "tier=frontend",
+	}
+	deploy, err := o.createDeployment()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedLabels := map[string]string{"app": "web", "tier": "frontend"}
+	assert.Equal(t, expectedLabels, deploy.Labels)
+	assert.Equal(t, expectedLabels, deploy.Spec.Template.Labels)
+	assert.Equal(t, map[string]string{"app": "web"}, deploy.Spec.Selector.MatchLabels)
+
+	podSpec := deploy.Spec.Template.Spec
+	assert.Equal(t, "web-sa", podSpec.ServiceAccountName)
+	assert.Equal(t, map[string]string{"disktype": "ssd"}, podSpec.NodeSelector)
+	assert.Equal(t, []corev1.Volume{
+		{Name: "web-data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "web-data"}}},
+		{Name: "web-conf", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "web.conf"}}}},
+	}, podSpec.Volumes)
+	assert.Len(t, podSpec.Containers, 2)
+	for _, container := range podSpec.Containers {
+		assert.Equal(t, []corev1.EnvVar{{Name: "MODE", Value: "prod"}}, container.Env)
+		assert.Equal(t, "256Mi", container.Resources.Limits.Memory().String())
+		assert.Equal(t, "100m", container.Resources.Requests.Cpu().String())
+		assert.Equal(t, []corev1.VolumeMount{
+			{Name: "web-data", MountPath: "/data"},
+			{Name: "web-conf", MountPath: "/etc/web", ReadOnly: true},
+			{Name: "web-data", MountPath: "/backup"},
+		}, container.VolumeMounts)
+	}
+
+	for _, invalid := range []*CreateDeploymentOptions{
+		{Name: "web", Images: []string{"nginx"}, Volumes: []string{"hostpath:x:/data"}},
+		{Name: "web", Images: []string{"nginx"}, Volumes: []string{"pvc:data:relative"}},
+		{Name: "web", Images: []string{"nginx"}, Volumes: []string{"pvc:data:/a", "secret:data:/b"}},
+		{Name: "web", Images: []string{"nginx"}, Env: []string{"MODE-"}},
+		{Name: "web", Images: []string{"nginx"}, Limits: "cpu"},
+		{Name: "web", Images: []string{"nginx"}, NodeSelector: "ssd"},
+	} {
+		if _, err := invalid.createDeployment(); err == nil {
+			t.Errorf("expected an error for %#v", invalid)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -28,6 +29,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	networkingv1client "k8s.io/client-go/kubernetes/typed/networking/v1"
@@ -59,7 +61,11 @@ var (
 	ruleRegex = regexHostPathSvc + regexTLS
 
 	ingressLong = templates.LongDesc(i18n.T(`
-	Create an ingress with the specified name.`))
+	Create an ingress with the specified name.
+
+	A preset adds the annotations conventionally used by a common ingress controller,
+	and sets the ingress class when none is given. Annotations given with --annotation
+	take precedence over the ones added by the preset.`))
 
 	ingressExample = templates.Examples(i18n.T(`
 		# Create a single ingress called 'simple' that directs requests to foo.com/bar to svc
@@ -98,23 +104,77 @@ var (
 		   --default-backend=defaultsvc:http \
 		   --rule="foo.com/*=svc:8080,tls=secret1"
 
+		# Create an ingress for the NGINX ingress controller where paths without '*' are of pathType Prefix
+		kubectl create ingress ingnginx --preset=nginx --path-type=Prefix \
+		   --rule="foo.com/api=svc:8080,tls=secret1"
+
+		# Create an ingress whose default backend is port 80 of service defaultsvc
+		kubectl create ingress ingdefaultsvc --preset=alb --default-backend-service=defaultsvc
+
 		`))
 )
 
+// ingressPreset holds the conventional settings of an ingress controller.
+type ingressPreset struct {
+	class       string
+	annotations map[string]string
+	// tlsAnnotations are only added when at least one rule enables TLS.
+	tlsAnnotations map[string]string
+}
+
+var ingressPresets = map[string]ingressPreset{
+	"nginx": {
+		class: "nginx",
+		tlsAnnotations: map[string]string{
+			"nginx.ingress.kubernetes.io/ssl-redirect": "true",
+		},
+	},
+	"alb": {
+		class: "alb",
+		annotations: map[string]string{
+			"alb.ingress.kubernetes.io/scheme":      "internet-facing",
+			"alb.ingress.kubernetes.io/target-type": "ip",
+		},
+		tlsAnnotations: map[string]string{
+			"alb.ingress.kubernetes.io/listen-ports": `[{"HTTP": 80}, {"HTTPS": 443}]`,
+			"alb.ingress.kubernetes.io/ssl-redirect": "443",
+		},
+	},
+	"gce": {
+		// The GCE controller selects ingresses by annotation rather than by class.
+		annotations: map[string]string{
+			"kubernetes.io/ingress.class": "gce",
+		},
+		tlsAnnotations: map[string]string{
+			"kubernetes.io/ingress.allow-http": "false",
+		},
+	},
+}
+
+var validPathTypes = []string{
+	string(networkingv1.PathTypeExact),
+	string(networkingv1.PathTypePrefix),
+	string(networkingv1.PathTypeImplementationSpecific),
+}
+
 // CreateIngressOptions is returned by NewCmdCreateIngress
 type CreateIngressOptions struct {
 	PrintFlags *genericclioptions.PrintFlags
 
 	PrintObj func(obj runtime.Object) error
 
-	Name             string
-	IngressClass     string
-	Rules            []string
-	Annotations      []string
-	DefaultBackend   string
-	Namespace        string
-	EnforceNamespace bool
-	CreateAnnotation bool
+	Name           string
+	IngressClass   string
+	Rules          []string
+	Annotations    []string
+	DefaultBackend string
+	// DefaultBackendService is the default backend given as svcname[:port]
+	DefaultBackendService string
+	PathType              string
+	Preset                string
+	Namespace             string
+	EnforceNamespace      bool
+	CreateAnnotation      bool
 
 	Client              networkingv1client.NetworkingV1Interface
 	DryRunStrategy      cmdutil.DryRunStrategy
@@ -160,7 +220,10 @@ func NewCmdCreateIngress(f cmdutil.Factory, ioStreams genericiooptions.IOStreams
 	cmd.Flags().StringVar(&o.IngressClass, "class", o.IngressClass, "Ingress Class to be used")
 	cmd.Flags().StringArrayVar(&o.Rules, "rule", o.Rules, "Rule in format host/path=service:port[,tls=secretname]. Paths containing the leading character '*' are considered pathType=Prefix. tls argument is optional.")
 	cmd.Flags().StringVar(&o.DefaultBackend, "default-backend", o.DefaultBackend, "Default service for backend, in format of svcname:port")
+	cmd.Flags().StringVar(&o.DefaultBackendService, "default-backend-service", o.DefaultBackendService, "Default service for backend, in format of svcname[:port]. The port defaults to 80.")
 	cmd.Flags().StringArrayVar(&o.Annotations, "annotation", o.Annotations, "Annotation to insert in the ingress object, in the format annotation=value")
+	cmd.Flags().StringVar(&o.PathType, "path-type", o.PathType, fmt.Sprintf("The pathType of the paths not ending with '*'. One of: %s. Defaults to Exact.", strings.Join(validPathTypes, ", ")))
+	cmd.Flags().StringVar(&o.Preset, "preset", o.Preset, fmt.Sprintf("Add the conventional annotations of an ingress controller. One of: %s.", strings.Join(presetNames(), ", ")))
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-create")
 
 	return cmd
@@ -210,7 +273,14 @@ func (o *CreateIngressOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, a
 
 // Validate validates the Ingress object to be created
 func (o *CreateIngressOptions) Validate() error {
-	if len(o.DefaultBackend) == 0 && len(o.Rules) == 0 {
+	if len(o.DefaultBackend) > 0 && len(o.DefaultBackendService) > 0 {
+		return fmt.Errorf("--default-backend and --default-backend-service cannot be used together")
+	}
+	if len(o.DefaultBackendService) > 0 && len(strings.Split(o.DefaultBackendService, ":")) > 2 {
+		return fmt.Errorf("default-backend-service should be in format servicename[:serviceport]")
+	}
+
+	if len(o.defaultBackend()) == 0 && len(o.Rules) == 0 {
 		return fmt.Errorf("not enough information provided: every ingress has to either specify a default-backend (which catches all traffic) or a list of rules (which catch specific paths)")
 	}
 
@@ -235,6 +305,14 @@ func (o *CreateIngressOptions) Validate() error {
 		return fmt.Errorf("default-backend should be in format servicename:serviceport")
 	}
 
+	if len(o.PathType) > 0 && !sets.New(validPathTypes...).Has(o.PathType) {
+		return fmt.Errorf("invalid path-type %q: must be one of %s", o.PathType, strings.Join(validPathTypes, ", "))
+	}
+
+	if _, ok := ingressPresets[o.Preset]; len(o.Preset) > 0 && !ok {
+		return fmt.Errorf("unknown preset %q: must be one of %s", o.Preset, strings.Join(presetNames(), ", "))
+	}
+
 	return nil
 }
 
@@ -289,6 +367,17 @@ func (o *CreateIngressOptions) buildAnnotations() map[string]string {
 
 	var annotations = make(map[string]string)
 
+	if preset, ok := ingressPresets[o.Preset]; ok {
+		for key, value := range preset.annotations {
+			annotations[key] = value
+		}
+		if o.hasTLS() {
+			for key, value := range preset.tlsAnnotations {
+				annotations[key] = value
+			}
+		}
+	}
+
 	for _, annotation := range o.Annotations {
 		an := strings.SplitN(annotation, "=", 2)
 		annotations[an[0]] = an[1]
@@ -302,10 +391,12 @@ func (o *CreateIngressOptions) buildIngressSpec() networkingv1.IngressSpec {
 
 	if len(o.IngressClass) > 0 {
 		ingressSpec.IngressClassName = &o.IngressClass
+	} else if preset, ok := ingressPresets[o.Preset]; ok && len(preset.class) > 0 {
+		ingressSpec.IngressClassName = &preset.class
 	}
 
-	if len(o.DefaultBackend) > 0 {
-		defaultbackend := buildIngressBackendSvc(o.DefaultBackend)
+	if defaultBackend := o.defaultBackend(); len(defaultBackend) > 0 {
+		defaultbackend := buildIngressBackendSvc(defaultBackend)
 		ingressSpec.DefaultBackend = &defaultbackend
 	}
 	ingressSpec.TLS = o.buildTLSRules()
@@ -314,6 +405,37 @@ func (o *CreateIngressOptions) buildIngressSpec() networkingv1.IngressSpec {
 	return ingressSpec
 }
 
+// defaultBackend returns the default backend in format svcname:port, taken
+// from either --default-backend or --default-backend-service.
+func (o *CreateIngressOptions) defaultBackend() string {
+	if len(o.DefaultBackendService) == 0 {
+		return o.DefaultBackend
+	}
+	if !strings.Contains(o.DefaultBackendService, ":") {
+		return o.DefaultBackendService + ":80"
+	}
+	return o.DefaultBackendService
+}
+
+// hasTLS returns true if any of the rules enables TLS.
+func (o *CreateIngressOptions) hasTLS() bool {
+	for _, rule := range o.Rules {
+		if len(strings.Split(rule, ",")) == 2 {
+			return true
+		}
+	}
+	return false
+}
+
+func presetNames() []string {
+	names := make([]string, 0, len(ingressPresets))
+	for name := range ingressPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (o *CreateIngressOptions) buildTLSRules() []networkingv1.IngressTLS {
 	hostAlreadyPresent := make(map[string]struct{})
 
@@ -365,7 +487,7 @@ func (o *CreateIngressOptions) buildIngressRules() []networkingv1.IngressRule {
 		removeTLS := strings.Split(rule, ",")[0]
 		hostSplit := strings.SplitN(removeTLS, "/", 2)
 		host := hostSplit[0]
-		ingressPath := buildHTTPIngressPath(hostSplit[1])
+		ingressPath := buildHTTPIngressPath(hostSplit[1], o.PathType)
 		ingressRule := networkingv1.IngressRule{}
 
 		if host != "" {
@@ -390,13 +512,16 @@ func (o *CreateIngressOptions) buildIngressRules() []networkingv1.IngressRule {
 	return ingressRules
 }
 
-func buildHTTPIngressPath(pathsvc string) networkingv1.HTTPIngressPath {
+func buildHTTPIngressPath(pathsvc, defaultPathType string) networkingv1.HTTPIngressPath {
 	pathsvcsplit := strings.Split(pathsvc, "=")
 	path := "/" + pathsvcsplit[0]
 	service := pathsvcsplit[1]
 
 	var pathType networkingv1.PathType
 	pathType = "Exact"
+	if len(defaultPathType) > 0 {
+		pathType = networkingv1.PathType(defaultPathType)
+	}
 
 	// If * in the End, turn pathType=Prefix but remove the * from the end
 	if path[len(path)-1:] == "*" {
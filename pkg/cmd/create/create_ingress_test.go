@@ -30,6 +30,9 @@ func TestCreateIngressValidation(t *testing.T) {
 		ingressclass   string
 		rules          []string
 		annotations    []string
+		backendservice string
+		pathtype       string
+		preset         string
 		expected       string
 	}{
 		"no default backend and rule": {
@@ -65,6 +68,29 @@ func TestCreateIngressValidation(t *testing.T) {
 			},
 			expected: "",
 		},
+		"default backend and default backend service": {
+			defaultbackend: "xpto:4444",
+			backendservice: "xpto",
+			expected:       "--default-backend and --default-backend-service cannot be used together",
+		},
+		"default backend service without port is ok": {
+			backendservice: "xpto",
+			expected:       "",
+		},
+		"invalid default backend service": {
+			backendservice: "xpto:80:81",
+			expected:       "default-backend-service should be in format servicename[:serviceport]",
+		},
+		"invalid path type": {
+			defaultbackend: "xpto:4444",
+			pathtype:       "Regex",
+			expected:       `invalid path-type "Regex": must be one of Exact, Prefix, ImplementationSpecific`,
+		},
+		"unknown preset": {
+			defaultbackend: "xpto:4444",
+			preset:         "traefik",
+			expected:       `unknown preset "traefik": must be one of alb, gce, nginx`,
+		},
 		"multiple conformant rules": {
 			rules: []string{
 				"foo.com/path*=svc:8080",
@@ -139,6 +165,10 @@ func TestCreateIngressValidation(t *testing.T) {
 				Rules:          tc.rules,
 				IngressClass:   tc.ingressclass,
 				Annotations:    tc.annotations,
+
+				DefaultBackendService: tc.backendservice,
+				PathType:              tc.pathtype,
+				Preset:                tc.preset,
 			}
 
 			err := o.Validate()
@@ -519,3 +549,102 @@ func TestCreateIngress(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateIngressPresetAndPathType(t *testing.T) {
+	pathTypeExact := networkingv1.PathTypeExact
+	pathTypePrefix := networkingv1.PathTypePrefix
+	pathTypeImplementationSpecific := networkingv1.PathTypeImplementationSpecific
+	nginxClass := "nginx"
+	internalClass := "internal"
+
+	tests := map[string]struct {
+		options             CreateIngressOptions
+		expectedClass       *string
+		expectedAnnotations map[string]string
+		expectedPathTypes   []*networkingv1.PathType
+		expectedBackend     *networkingv1.IngressBackend
+	}{
+		"nginx preset with TLS": {
+			options: CreateIngressOptions{
+				Preset: "nginx",
+				Rules:  []string{"foo.com/api=svc:8080,tls=secret1"},
+			},
+			expectedClass:       &nginxClass,
+			expectedAnnotations: map[string]string{"nginx.ingress.kubernetes.io/ssl-redirect": "true"},
+			expectedPathTypes:   []*networkingv1.PathType{&pathTypeExact},
+		},
+		"alb preset without TLS keeps explicit class and annotations": {
+			options: CreateIngressOptions{
+				Preset:       "alb",
+				IngressClass: "internal",
+				Annotations:  []string{"alb.ingress.kubernetes.io/scheme=internal"},
+				Rules:        []string{"foo.com/api=svc:8080"},
+			},
+			expectedClass: &internalClass,
+			expectedAnnotations: map[string]string{
+				"alb.ingress.kubernetes.io/scheme":      "internal",
+				"alb.ingress.kubernetes.io/target-type": "ip",
+			},
+			expectedPathTypes: []*networkingv1.PathType{&pathTypeExact},
+		},
+		"gce preset sets the class annotation": {
+			options: CreateIngressOptions{
+				Preset: "gce",
+				Rules:  []string{"foo.com/=svc:8080,tls"},
+			},
+			expectedAnnotations: map[string]string{
+				"kubernetes.io/ingress.class":      "gce",
+				"kubernetes.io/ingress.allow-http": "false",
+			},
+			expectedPathTypes: []*networkingv1.PathType{&pathTypeExact},
+		},
+		"path type applies to paths without wildcard": {
+			options: CreateIngressOptions{
+				PathType: "ImplementationSpecific",
+				Rules:    []string{"foo.com/api=svc:8080", "foo.com/static*=svc:8080"},
+			},
+			expectedAnnotations: map[string]string{},
+			expectedPathTypes:   []*networkingv1.PathType{&pathTypeImplementationSpecific, &pathTypePrefix},
+		},
+		"default backend service defaults to port 80": {
+			options: CreateIngressOptions{
+				DefaultBackendService: "defaultsvc",
+			},
+			expectedAnnotations: map[string]string{},
+			expectedBackend: &networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: "defaultsvc",
+					Port: networkingv1.ServiceBackendPort{Number: 80},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			tc.options.Name = "test-ingress"
+			if err := tc.options.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ingress := tc.options.createIngress()
+			if !apiequality.Semantic.DeepEqual(ingress.Spec.IngressClassName, tc.expectedClass) {
+				t.Errorf("expected class %v, got %v", tc.expectedClass, ingress.Spec.IngressClassName)
+			}
+			if !apiequality.Semantic.DeepEqual(ingress.Annotations, tc.expectedAnnotations) {
+				t.Errorf("expected annotations %v, got %v", tc.expectedAnnotations, ingress.Annotations)
+			}
+			var pathTypes []*networkingv1.PathType
+			for _, rule := range ingress.Spec.Rules {
+				for _, path := range rule.HTTP.Paths {
+					pathTypes = append(pathTypes, path.PathType)
+				}
+			}
+			if !apiequality.Semantic.DeepEqual(pathTypes, tc.expectedPathTypes) {
+				t.Errorf("expected path types %v, got %v", tc.expectedPathTypes, pathTypes)
+			}
+			if !apiequality.Semantic.DeepEqual(ingress.Spec.DefaultBackend, tc.expectedBackend) {
+				t.Errorf("expected default backend %v, got %v", tc.expectedBackend, ingress.Spec.DefaultBackend)
+			}
+		})
+	}
+}
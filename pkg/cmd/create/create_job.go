@@ -30,16 +30,22 @@ import (
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	batchv1client "k8s.io/client-go/kubernetes/typed/batch/v1"
+	envutil "k8s.io/kubectl/pkg/cmd/set/env"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
+	"k8s.io/utils/pointer"
 )
 
 var (
 	jobLong = templates.LongDesc(i18n.T(`
-		Create a job with the specified name.`))
+		Create a job with the specified name.
+
+		Jobs created from a cron job run its job template, whose container may be changed with
+		--image, --env and --args. Templates with several containers need --container to select
+		the one to change.`))
 
 	jobExample = templates.Examples(i18n.T(`
 		# Create a job
@@ -49,7 +55,10 @@ var (
 		kubectl create job my-job --image=busybox -- date
 
 		# Create a job from a cron job named "a-cronjob"
-		kubectl create job test-job --from=cronjob/a-cronjob`))
+		kubectl create job test-job --from=cronjob/a-cronjob
+
+		# Create a suspended job from a cron job, with another image, environment and arguments
+		kubectl create job test-job --from=cronjob/a-cronjob --image=busybox:1.36 --env=DRY_RUN=false --env=DEBUG- --args=--full --suspend`))
 )
 
 // CreateJobOptions is the command line options for 'create job'
@@ -62,6 +71,14 @@ type CreateJobOptions struct {
 	Image   string
 	From    string
 	Command []string
+	// Env sets (KEY=VALUE) or removes (KEY-) environment variables of the container
+	Env []string
+	// Args replace the arguments of the container
+	Args []string
+	// Container is the container of the cron job template to override
+	Container string
+	// Suspend creates the job suspended
+	Suspend bool
 
 	Namespace           string
 	EnforceNamespace    bool
@@ -87,7 +104,7 @@ func NewCreateJobOptions(ioStreams genericiooptions.IOStreams) *CreateJobOptions
 func NewCmdCreateJob(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra.Command {
 	o := NewCreateJobOptions(ioStreams)
 	cmd := &cobra.Command{
-		Use:                   "job NAME --image=image [--from=cronjob/name] -- [COMMAND] [args...]",
+		Use:                   "job NAME --image=image [--from=cronjob/name] [--env=KEY=VALUE] [--args=arg] [--suspend] -- [COMMAND] [args...]",
 		DisableFlagsInUseLine: true,
 		Short:                 i18n.T("Create a job with the specified name"),
 		Long:                  jobLong,
@@ -106,6 +123,10 @@ func NewCmdCreateJob(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *c
 	cmdutil.AddDryRunFlag(cmd)
 	cmd.Flags().StringVar(&o.Image, "image", o.Image, "Image name to run.")
 	cmd.Flags().StringVar(&o.From, "from", o.From, "The name of the resource to create a Job from (only cronjob is supported).")
+	cmd.Flags().StringArrayVar(&o.Env, "env", o.Env, "Environment variable to set in the container (e.g. KEY=VALUE), or to remove with KEY-.")
+	cmd.Flags().StringArrayVar(&o.Args, "args", o.Args, "Argument replacing the arguments of the container. Repeat the flag for each argument.")
+	cmd.Flags().StringVarP(&o.Container, "container", "c", o.Container, "The container of the cron job template to override with --image, --env and --args.")
+	cmd.Flags().BoolVar(&o.Suspend, "suspend", o.Suspend, "Create the job suspended, so that it only runs once it is resumed.")
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-create")
 	return cmd
 }
@@ -161,19 +182,25 @@ func (o *CreateJobOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args
 
 // Validate makes sure provided values and valid Job options
 func (o *CreateJobOptions) Validate() error {
-	if (len(o.Image) == 0 && len(o.From) == 0) || (len(o.Image) != 0 && len(o.From) != 0) {
+	if len(o.Image) == 0 && len(o.From) == 0 {
 		return fmt.Errorf("either --image or --from must be specified")
 	}
 	if o.Command != nil && len(o.Command) != 0 && len(o.From) != 0 {
 		return fmt.Errorf("cannot specify --from and command")
 	}
+	if len(o.Container) > 0 && len(o.From) == 0 {
+		return fmt.Errorf("--container can only be used with --from")
+	}
+	if _, _, _, err := envutil.ParseEnv(o.Env, nil); err != nil {
+		return err
+	}
 	return nil
 }
 
 // Run performs the execution of 'create job' sub command
 func (o *CreateJobOptions) Run() error {
 	var job *batchv1.Job
-	if len(o.Image) > 0 {
+	if len(o.From) == 0 {
 		job = o.createJob()
 	} else {
 		infos, err := o.Builder.
@@ -198,6 +225,9 @@ func (o *CreateJobOptions) Run() error {
 			return fmt.Errorf("unknown object type %T", obj)
 		}
 	}
+	if err := o.applyOverrides(job); err != nil {
+		return err
+	}
 
 	if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, job, scheme.DefaultJSONEncoder()); err != nil {
 		return err
@@ -266,10 +296,71 @@ func (o *CreateJobOptions) createJobFromCronJob(cronJob *batchv1.CronJob) *batch
 			Labels:          cronJob.Spec.JobTemplate.Labels,
 			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(cronJob, batchv1.SchemeGroupVersion.WithKind("CronJob"))},
 		},
-		Spec: cronJob.Spec.JobTemplate.Spec,
+		Spec: *cronJob.Spec.JobTemplate.Spec.DeepCopy(),
 	}
 	if o.EnforceNamespace {
 		job.Namespace = o.Namespace
 	}
 	return job
 }
+
+// applyOverrides suspends the job and changes the image, environment and
+// arguments of its container as requested.
+func (o *CreateJobOptions) applyOverrides(job *batchv1.Job) error {
+	if o.Suspend {
+		job.Spec.Suspend = pointer.Bool(true)
+	}
+	if len(o.Image) == 0 && len(o.Env) == 0 && o.Args == nil {
+		return nil
+	}
+
+	containers := job.Spec.Template.Spec.Containers
+	var container *corev1.Container
+	switch {
+	case len(o.Container) > 0:
+		for i := range containers {
+			if containers[i].Name == o.Container {
+				container = &containers[i]
+			}
+		}
+		if container == nil {
+			return fmt.Errorf("container %q not found in the job template", o.Container)
+		}
+	case len(containers) == 1:
+		container = &containers[0]
+	default:
+		return fmt.Errorf("the job template has %d containers, select the one to override with --container", len(containers))
+	}
+
+	if len(o.Image) > 0 {
+		container.Image = o.Image
+	}
+	if o.Args != nil {
+		container.Args = o.Args
+	}
+	env, remove, _, err := envutil.ParseEnv(o.Env, nil)
+	if err != nil {
+		return err
+	}
+	for _, name := range remove {
+		for i := 0; i < len(container.Env); i++ {
+			if container.Env[i].Name == name {
+				container.Env = append(container.Env[:i], container.Env[i+1:]...)
+				i--
+			}
+		}
+	}
+	for _, envVar := range env {
+		found := false
+		for i := range container.Env {
+			if container.Env[i].Name == envVar.Name {
+				container.Env[i] = envVar
+				found = true
+			}
+		}
+		if !found {
+			container.Env = append(container.Env, envVar)
+		}
+	}
+	return nil
+}
@@ -28,18 +28,23 @@ import (
 
 func TestCreateJobValidation(t *testing.T) {
 	tests := map[string]struct {
-		image    string
-		command  []string
-		from     string
-		expected string
+		image     string
+		command   []string
+		from      string
+		container string
+		expected  string
 	}{
 		"empty flags": {
 			expected: "--image or --from must be specified",
 		},
-		"both image and from specified": {
-			image:    "my-image",
-			from:     "cronjob/xyz",
-			expected: "--image or --from must be specified",
+		"image overriding from": {
+			image: "my-image",
+			from:  "cronjob/xyz",
+		},
+		"container without from": {
+			image:     "my-image",
+			container: "app",
+			expected:  "--container can only be used with --from",
 		},
 		"from and command specified": {
 			from:     "cronjob/xyz",
@@ -51,15 +56,19 @@ func TestCreateJobValidation(t *testing.T) {
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			o := &CreateJobOptions{
-				Image:   tc.image,
-				From:    tc.from,
-				Command: tc.command,
+				Image:     tc.image,
+				From:      tc.from,
+				Command:   tc.command,
+				Container: tc.container,
 			}
 
 			err := o.Validate()
 			if err != nil && !strings.Contains(err.Error(), tc.expected) {
 				t.Errorf("unexpected error: %v", err)
 			}
+			if err == nil && len(tc.expected) > 0 {
+				t.Errorf("expected error %q", tc.expected)
+			}
 		})
 	}
 }
@@ -192,3 +201,64 @@ func TestCreateJobFromCronJob(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateJobFromCronJobOverrides(t *testing.T) {
+	cronJob := &batchv1.CronJob{
+		Spec: batchv1.CronJobSpec{
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  "app",
+									Image: "app:v1",
+									Args:  []string{"--quick"},
+									Env:   []corev1.EnvVar{{Name: "DRY_RUN", Value: "true"}, {Name: "DEBUG", Value: "1"}},
+								},
+								{Name: "sidecar", Image: "sidecar:v1"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	o := &CreateJobOptions{
+		Name:      "test-job",
+		Image:     "app:v2",
+		Env:       []string{"DRY_RUN=false", "DEBUG-", "LEVEL=full"},
+		Args:      []string{"--full"},
+		Container: "app",
+		Suspend:   true,
+	}
+	job := o.createJobFromCronJob(cronJob)
+	if err := o.applyOverrides(job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := corev1.Container{
+		Name:  "app",
+		Image: "app:v2",
+		Args:  []string{"--full"},
+		Env:   []corev1.EnvVar{{Name: "DRY_RUN", Value: "false"}, {Name: "LEVEL", Value: "full"}},
+	}
+	if !apiequality.Semantic.DeepEqual(job.Spec.Template.Spec.Containers[0], expected) {
+		t.Errorf("expected:\n%#v\ngot:\n%#v", expected, job.Spec.Template.Spec.Containers[0])
+	}
+	if job.Spec.Suspend == nil || !*job.Spec.Suspend {
+		t.Errorf("expected the job to be suspended")
+	}
+	if cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Image != "app:v1" {
+		t.Errorf("expected the cron job to be left unchanged")
+	}
+
+	o.Container = ""
+	if err := o.applyOverrides(o.createJobFromCronJob(cronJob)); err == nil || !strings.Contains(err.Error(), "--container") {
+		t.Errorf("expected an error asking for a container, got %v", err)
+	}
+	o.Container = "missing"
+	if err := o.applyOverrides(o.createJobFromCronJob(cronJob)); err == nil {
+		t.Errorf("expected an error for a missing container")
+	}
+}
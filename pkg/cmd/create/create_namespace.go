@@ -19,13 +19,17 @@ package create
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
+	rbacclient "k8s.io/client-go/kubernetes/typed/rbac/v1"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util"
 
@@ -38,26 +42,50 @@ import (
 
 var (
 	namespaceLong = templates.LongDesc(i18n.T(`
-		Create a namespace with the specified name.`))
+		Create a namespace with the specified name.
+
+		With --from, the namespace is created as a clone of an existing namespace: the
+		resources of the kinds selected with --include are copied from the existing
+		namespace into the new one. Secrets are only copied when they are included
+		explicitly. The namespace of the copies, and of the service
+		account subjects of the role bindings, is rewritten. The objects generated by
+		the cluster are not copied: the kube-root-ca.crt config map, the service account
+		token secrets and the objects owned by other objects.`))
 
 	namespaceExample = templates.Examples(i18n.T(`
 	  # Create a new namespace named my-namespace
-	  kubectl create namespace my-namespace`))
+	  kubectl create namespace my-namespace
+
+	  # Create a namespace named staging with the config maps, secrets and roles of the namespace prod
+	  kubectl create namespace staging --from=prod --include=configmaps,secrets,rbac`))
 )
 
+// namespaceCloneKinds are the kinds of resources which can be cloned from a
+// namespace with --include.
+var namespaceCloneKinds = []string{"configmaps", "secrets", "quotas", "limits", "rbac"}
+
+// defaultNamespaceCloneKinds are the kinds of resources cloned when --include
+// is not set. Secrets are left out so they are only copied on request.
+var defaultNamespaceCloneKinds = []string{"configmaps", "quotas", "limits", "rbac"}
+
 // NamespaceOptions is the options for 'create namespace' sub command
 type NamespaceOptions struct {
 	// PrintFlags holds options necessary for obtaining a printer
 	PrintFlags *genericclioptions.PrintFlags
 	// Name of resource being created
 	Name string
+	// From is the namespace to clone the resources of
+	From string
+	// Include are the kinds of resources to clone, see namespaceCloneKinds
+	Include []string
 
 	DryRunStrategy      cmdutil.DryRunStrategy
 	ValidationDirective string
 	CreateAnnotation    bool
 	FieldManager        string
 
-	Client *coreclient.CoreV1Client
+	Client     coreclient.CoreV1Interface
+	RbacClient rbacclient.RbacV1Interface
 
 	PrintObj func(obj runtime.Object) error
 
@@ -68,6 +96,7 @@ type NamespaceOptions struct {
 func NewNamespaceOptions(ioStreams genericiooptions.IOStreams) *NamespaceOptions {
 	return &NamespaceOptions{
 		PrintFlags: genericclioptions.NewPrintFlags("created").WithTypeSetter(scheme.Scheme),
+		Include:    defaultNamespaceCloneKinds,
 		IOStreams:  ioStreams,
 	}
 }
@@ -78,7 +107,7 @@ func NewCmdCreateNamespace(f cmdutil.Factory, ioStreams genericiooptions.IOStrea
 	o := NewNamespaceOptions(ioStreams)
 
 	cmd := &cobra.Command{
-		Use:                   "namespace NAME [--dry-run=server|client|none]",
+		Use:                   "namespace NAME [--from=NAMESPACE [--include=KIND,...]] [--dry-run=server|client|none]",
 		DisableFlagsInUseLine: true,
 		Aliases:               []string{"ns"},
 		Short:                 i18n.T("Create a namespace with the specified name"),
@@ -93,6 +122,8 @@ func NewCmdCreateNamespace(f cmdutil.Factory, ioStreams genericiooptions.IOStrea
 
 	o.PrintFlags.AddFlags(cmd)
 
+	cmd.Flags().StringVar(&o.From, "from", o.From, i18n.T("The namespace to clone the resources of into the new namespace."))
+	cmd.Flags().StringSliceVar(&o.Include, "include", o.Include, i18n.T("The kinds of resources to clone with --from. Any of: configmaps, secrets, quotas, limits, rbac. Secrets are only cloned when included explicitly."))
 	cmdutil.AddApplyAnnotationFlags(cmd)
 	cmdutil.AddValidateFlags(cmd)
 	cmdutil.AddDryRunFlag(cmd)
@@ -116,6 +147,10 @@ func (o *NamespaceOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args
 	if err != nil {
 		return err
 	}
+	o.RbacClient, err = rbacclient.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
 
 	o.Name = name
 	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
@@ -144,21 +179,198 @@ func (o *NamespaceOptions) Run() error {
 	}
 
 	if o.DryRunStrategy != cmdutil.DryRunClient {
-		createOptions := metav1.CreateOptions{}
-		if o.FieldManager != "" {
-			createOptions.FieldManager = o.FieldManager
-		}
-		createOptions.FieldValidation = o.ValidationDirective
-		if o.DryRunStrategy == cmdutil.DryRunServer {
-			createOptions.DryRun = []string{metav1.DryRunAll}
-		}
 		var err error
-		namespace, err = o.Client.Namespaces().Create(context.TODO(), namespace, createOptions)
+		namespace, err = o.Client.Namespaces().Create(context.TODO(), namespace, o.createOptions())
 		if err != nil {
 			return err
 		}
 	}
-	return o.PrintObj(namespace)
+	if err := o.PrintObj(namespace); err != nil {
+		return err
+	}
+	if len(o.From) == 0 {
+		return nil
+	}
+
+	objects, err := o.cloneObjects()
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		// the objects can not be created in a namespace created with
+		// --dry-run=server, so they are printed as they would be created
+		if o.DryRunStrategy == cmdutil.DryRunNone {
+			if obj, err = o.createObject(obj); err != nil {
+				return err
+			}
+		}
+		if err := o.PrintObj(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *NamespaceOptions) createOptions() metav1.CreateOptions {
+	createOptions := metav1.CreateOptions{}
+	if o.FieldManager != "" {
+		createOptions.FieldManager = o.FieldManager
+	}
+	createOptions.FieldValidation = o.ValidationDirective
+	if o.DryRunStrategy == cmdutil.DryRunServer {
+		createOptions.DryRun = []string{metav1.DryRunAll}
+	}
+	return createOptions
+}
+
+// cloneObjects returns the copies of the resources of the kinds to include
+// of the namespace to clone, for the new namespace.
+func (o *NamespaceOptions) cloneObjects() ([]runtime.Object, error) {
+	ctx := context.TODO()
+	if _, err := o.Client.Namespaces().Get(ctx, o.From, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+
+	include := sets.New(o.Include...)
+	objects := []runtime.Object{}
+	if include.Has("configmaps") {
+		list, err := o.Client.ConfigMaps(o.From).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			configMap := &list.Items[i]
+			// the root certificate authority is published in each namespace
+			if configMap.Name == "kube-root-ca.crt" || isGenerated(&configMap.ObjectMeta) {
+				continue
+			}
+			configMap.ObjectMeta = o.cloneObjectMeta(configMap.ObjectMeta)
+			objects = append(objects, configMap)
+		}
+	}
+	if include.Has("secrets") {
+		list, err := o.Client.Secrets(o.From).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			secret := &list.Items[i]
+			if secret.Type == corev1.SecretTypeServiceAccountToken || isGenerated(&secret.ObjectMeta) {
+				continue
+			}
+			secret.ObjectMeta = o.cloneObjectMeta(secret.ObjectMeta)
+			objects = append(objects, secret)
+		}
+	}
+	if include.Has("quotas") {
+		list, err := o.Client.ResourceQuotas(o.From).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			quota := &list.Items[i]
+			if isGenerated(&quota.ObjectMeta) {
+				continue
+			}
+			quota.ObjectMeta = o.cloneObjectMeta(quota.ObjectMeta)
+			quota.Status = corev1.ResourceQuotaStatus{}
+			objects = append(objects, quota)
+		}
+	}
+	if include.Has("limits") {
+		list, err := o.Client.LimitRanges(o.From).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			limitRange := &list.Items[i]
+			if isGenerated(&limitRange.ObjectMeta) {
+				continue
+			}
+			limitRange.ObjectMeta = o.cloneObjectMeta(limitRange.ObjectMeta)
+			objects = append(objects, limitRange)
+		}
+	}
+	if include.Has("rbac") {
+		roles, err := o.RbacClient.Roles(o.From).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range roles.Items {
+			role := &roles.Items[i]
+			if isGenerated(&role.ObjectMeta) {
+				continue
+			}
+			role.ObjectMeta = o.cloneObjectMeta(role.ObjectMeta)
+			objects = append(objects, role)
+		}
+		bindings, err := o.RbacClient.RoleBindings(o.From).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for i := range bindings.Items {
+			binding := &bindings.Items[i]
+			if isGenerated(&binding.ObjectMeta) {
+				continue
+			}
+			binding.ObjectMeta = o.cloneObjectMeta(binding.ObjectMeta)
+			for j := range binding.Subjects {
+				if binding.Subjects[j].Kind == rbacv1.ServiceAccountKind && binding.Subjects[j].Namespace == o.From {
+					binding.Subjects[j].Namespace = o.Name
+				}
+			}
+			objects = append(objects, binding)
+		}
+	}
+	return objects, nil
+}
+
+// isGenerated returns whether the object was generated from another object,
+// which is not cloned.
+func isGenerated(meta *metav1.ObjectMeta) bool {
+	return len(meta.OwnerReferences) > 0
+}
+
+// cloneObjectMeta returns the metadata of the copy of an object in the new
+// namespace.
+func (o *NamespaceOptions) cloneObjectMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	annotations := map[string]string{}
+	for key, value := range meta.Annotations {
+		// the last applied configuration is the one of the original object
+		if key != corev1.LastAppliedConfigAnnotation {
+			annotations[key] = value
+		}
+	}
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	return metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   o.Name,
+		Labels:      meta.Labels,
+		Annotations: annotations,
+	}
+}
+
+// createObject creates a copy returned by cloneObjects.
+func (o *NamespaceOptions) createObject(obj runtime.Object) (runtime.Object, error) {
+	ctx := context.TODO()
+	switch obj := obj.(type) {
+	case *corev1.ConfigMap:
+		return o.Client.ConfigMaps(o.Name).Create(ctx, obj, o.createOptions())
+	case *corev1.Secret:
+		return o.Client.Secrets(o.Name).Create(ctx, obj, o.createOptions())
+	case *corev1.ResourceQuota:
+		return o.Client.ResourceQuotas(o.Name).Create(ctx, obj, o.createOptions())
+	case *corev1.LimitRange:
+		return o.Client.LimitRanges(o.Name).Create(ctx, obj, o.createOptions())
+	case *rbacv1.Role:
+		return o.RbacClient.Roles(o.Name).Create(ctx, obj, o.createOptions())
+	case *rbacv1.RoleBinding:
+		return o.RbacClient.RoleBindings(o.Name).Create(ctx, obj, o.createOptions())
+	default:
+		return nil, fmt.Errorf("cannot clone %T", obj)
+	}
 }
 
 // createNamespace outputs a namespace object using the configured fields
@@ -175,5 +387,16 @@ func (o *NamespaceOptions) Validate() error {
 	if len(o.Name) == 0 {
 		return fmt.Errorf("name must be specified")
 	}
+	if len(o.From) == 0 {
+		return nil
+	}
+	if o.From == o.Name {
+		return fmt.Errorf("cannot clone the namespace %s into itself", o.From)
+	}
+	for _, kind := range o.Include {
+		if !sets.New(namespaceCloneKinds...).Has(kind) {
+			return fmt.Errorf("invalid --include %q, must be any of: %s", kind, strings.Join(namespaceCloneKinds, ", "))
+		}
+	}
 	return nil
 }
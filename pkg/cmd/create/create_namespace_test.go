@@ -17,11 +17,21 @@ limitations under the License.
 package create
 
 import (
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"context"
+	"strings"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
 func TestCreateNamespace(t *testing.T) {
@@ -53,3 +63,133 @@ func TestCreateNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateNamespaceFrom(t *testing.T) {
+	owner := []metav1.OwnerReference{{APIVersion: "v1", Kind: "Service", Name: "web", UID: "uid"}}
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "prod", UID: "1", ResourceVersion: "10",
+			Labels:      map[string]string{"app": "web"},
+			Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: `{"metadata":{"namespace":"prod"}}`, "team": "web"}},
+			Data: map[string]string{"mode": "fast"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt", Namespace: "prod"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "generated", Namespace: "prod", OwnerReferences: owner}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "password", Namespace: "prod"}, Data: map[string][]byte{"password": []byte("secret")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "default-token", Namespace: "prod"}, Type: corev1.SecretTypeServiceAccountToken},
+		&corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "prod"},
+			Status: corev1.ResourceQuotaStatus{Used: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("3")}}},
+		&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "reader", Namespace: "prod"}},
+		&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "reader", Namespace: "prod"},
+			RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "reader"},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: "web", Namespace: "prod"},
+				{Kind: rbacv1.ServiceAccountKind, Name: "monitoring", Namespace: "monitoring"},
+			}},
+	)
+
+	printed := []string{}
+	o := &NamespaceOptions{
+		Name:           "staging",
+		From:           "prod",
+		Include:        []string{"configmaps", "secrets", "quotas", "rbac"},
+		DryRunStrategy: cmdutil.DryRunNone,
+		Client:         client.CoreV1(),
+		RbacClient:     client.RbacV1(),
+		PrintObj: func(obj runtime.Object) error {
+			accessor, err := meta.Accessor(obj)
+			if err != nil {
+				return err
+			}
+			printed = append(printed, accessor.GetNamespace()+"/"+accessor.GetName())
+			return nil
+		},
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPrinted := []string{"/staging", "staging/settings", "staging/password", "staging/quota", "staging/reader", "staging/reader"}
+	if !apiequality.Semantic.DeepEqual(printed, expectedPrinted) {
+		t.Errorf("expected printed objects %v, got %v", expectedPrinted, printed)
+	}
+
+	configMaps, err := client.CoreV1().ConfigMaps("staging").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedConfigMap := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "staging", Labels: map[string]string{"app": "web"}, Annotations: map[string]string{"team": "web"}},
+		Data:       map[string]string{"mode": "fast"},
+	}
+	if len(configMaps.Items) != 1 || !apiequality.Semantic.DeepEqual(configMaps.Items[0], expectedConfigMap) {
+		t.Errorf("expected config maps %v, got %v", expectedConfigMap, configMaps.Items)
+	}
+
+	quota, err := client.CoreV1().ResourceQuotas("staging").Get(context.TODO(), "quota", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quota.Status.Used) > 0 {
+		t.Errorf("expected the status of the quota to be cleared, got %v", quota.Status)
+	}
+
+	binding, err := client.RbacV1().RoleBindings("staging").Get(context.TODO(), "reader", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedSubjects := []rbacv1.Subject{
+		{Kind: rbacv1.ServiceAccountKind, Name: "web", Namespace: "staging"},
+		{Kind: rbacv1.ServiceAccountKind, Name: "monitoring", Namespace: "monitoring"},
+	}
+	if !apiequality.Semantic.DeepEqual(binding.Subjects, expectedSubjects) {
+		t.Errorf("expected subjects %v, got %v", expectedSubjects, binding.Subjects)
+	}
+}
+
+func TestCreateNamespaceDefaultInclude(t *testing.T) {
+	tf := cmdtesting.NewTestFactory()
+	defer tf.Cleanup()
+
+	cmd := NewCmdCreateNamespace(tf, genericiooptions.NewTestIOStreamsDiscard())
+	include, err := cmd.Flags().GetStringSlice("include")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !apiequality.Semantic.DeepEqual(include, defaultNamespaceCloneKinds) {
+		t.Errorf("expected --include to default to %v, got %v", defaultNamespaceCloneKinds, include)
+	}
+}
+
+func TestCreateNamespaceFromValidate(t *testing.T) {
+	tests := map[string]struct {
+		options     *NamespaceOptions
+		expectedErr string
+	}{
+		"clone": {
+			options: &NamespaceOptions{Name: "staging", From: "prod", Include: namespaceCloneKinds},
+		},
+		"clone into itself": {
+			options:     &NamespaceOptions{Name: "prod", From: "prod", Include: namespaceCloneKinds},
+			expectedErr: "cannot clone the namespace prod into itself",
+		},
+		"invalid kind": {
+			options:     &NamespaceOptions{Name: "staging", From: "prod", Include: []string{"configmaps", "pods"}},
+			expectedErr: `invalid --include "pods"`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.options.Validate()
+			if len(tc.expectedErr) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/completion"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	pvcLong = templates.LongDesc(i18n.T(`
+		Create a persistent volume claim with the specified name and storage.
+
+		For clusters without dynamic provisioning, --with-pv also creates a persistent
+		volume bound to the claim, with the same capacity, access modes and storage
+		class. The volume is named after the namespace and the claim, and is retained
+		when the claim is deleted. Its source is one of:
+
+		* hostpath:PATH, a directory of the node the pod runs on
+		* local:NODE:PATH, a directory of the node NODE
+		* nfs:SERVER:PATH, an export of an NFS server`))
+
+	pvcExample = templates.Examples(i18n.T(`
+		# Create a persistent volume claim named data requesting 10Gi
+		kubectl create persistentvolumeclaim data --storage=10Gi
+
+		# Create a persistent volume claim named data with a volume in /data/foo of the nodes
+		kubectl create pvc data --storage=10Gi --with-pv=hostpath:/data/foo
+
+		# Create a persistent volume claim named shared, writable by many pods, with an NFS volume
+		kubectl create pvc shared --storage=100Gi --access-mode=ReadWriteMany --with-pv=nfs:nfs.example.com:/exports/shared`))
+
+	pvcAccessModes = sets.New(
+		string(corev1.ReadWriteOnce),
+		string(corev1.ReadOnlyMany),
+		string(corev1.ReadWriteMany),
+		string(corev1.ReadWriteOncePod),
+	)
+)
+
+// PersistentVolumeClaimOptions holds the options for 'create persistentvolumeclaim' sub command
+type PersistentVolumeClaimOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	PrintObj   func(obj runtime.Object) error
+
+	Name         string
+	Storage      string
+	AccessModes  []string
+	StorageClass string
+	VolumeMode   string
+	// WithPV is the source of the persistent volume to create for the
+	// claim, none is created when empty.
+	WithPV string
+
+	FieldManager     string
+	CreateAnnotation bool
+	Namespace        string
+	EnforceNamespace bool
+
+	Client              coreclient.CoreV1Interface
+	DryRunStrategy      cmdutil.DryRunStrategy
+	ValidationDirective string
+
+	genericiooptions.IOStreams
+}
+
+// NewPersistentVolumeClaimOptions returns an initialized PersistentVolumeClaimOptions instance
+func NewPersistentVolumeClaimOptions(ioStreams genericiooptions.IOStreams) *PersistentVolumeClaimOptions {
+	return &PersistentVolumeClaimOptions{
+		AccessModes: []string{string(corev1.ReadWriteOnce)},
+		PrintFlags:  genericclioptions.NewPrintFlags("created").WithTypeSetter(scheme.Scheme),
+		IOStreams:   ioStreams,
+	}
+}
+
+// NewCmdCreatePersistentVolumeClaim is a macro command to create a new persistent volume claim.
+func NewCmdCreatePersistentVolumeClaim(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	o := NewPersistentVolumeClaimOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "persistentvolumeclaim NAME --storage=SIZE [--access-mode=MODE] [--storage-class=CLASS] [--with-pv=TYPE:PATH] [--dry-run=server|client|none]",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"pvc"},
+		Short:                 i18n.T("Create a persistent volume claim with the specified name"),
+		Long:                  pvcLong,
+		Example:               pvcExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+
+	cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddValidateFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().StringVar(&o.Storage, "storage", o.Storage, i18n.T("The storage requested by the claim, such as 10Gi."))
+	cmd.Flags().StringSliceVar(&o.AccessModes, "access-mode", o.AccessModes, i18n.T("The access modes of the claim. One of: ReadWriteOnce, ReadOnlyMany, ReadWriteMany, ReadWriteOncePod. Can be repeated."))
+	cmd.Flags().StringVar(&o.StorageClass, "storage-class", o.StorageClass, i18n.T("The storage class of the claim. The default storage class is used when not set, unless --with-pv is set."))
+	cmd.Flags().StringVar(&o.VolumeMode, "volume-mode", o.VolumeMode, i18n.T("The volume mode of the claim. One of: Filesystem, Block."))
+	cmd.Flags().StringVar(&o.WithPV, "with-pv", o.WithPV, i18n.T("Also create a persistent volume bound to the claim. One of: hostpath:PATH, local:NODE:PATH, nfs:SERVER:PATH."))
+	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-create")
+	cmdutil.CheckErr(cmd.RegisterFlagCompletionFunc(
+		"storage-class",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completion.CompGetResource(f, "storageclass", toComplete), cobra.ShellCompDirectiveNoFileComp
+		}))
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *PersistentVolumeClaimOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Name, err = NameFromCommandArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	restConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.Client, err = coreclient.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	o.CreateAnnotation = cmdutil.GetFlagBool(cmd, cmdutil.ApplyAnnotationsFlag)
+
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = func(obj runtime.Object) error {
+		return printer.PrintObj(obj, o.Out)
+	}
+
+	o.ValidationDirective, err = cmdutil.GetValidationDirective(cmd)
+	return err
+}
+
+// Validate makes sure provided values for PersistentVolumeClaimOptions are valid
+func (o *PersistentVolumeClaimOptions) Validate() error {
+	if len(o.Storage) == 0 {
+		return fmt.Errorf("--storage must be specified")
+	}
+	if _, err := resourceapi.ParseQuantity(o.Storage); err != nil {
+		return fmt.Errorf("invalid --storage %q: %v", o.Storage, err)
+	}
+	if len(o.AccessModes) == 0 {
+		return fmt.Errorf("at least one --access-mode must be specified")
+	}
+	for _, mode := range o.AccessModes {
+		if !pvcAccessModes.Has(mode) {
+			return fmt.Errorf("invalid --access-mode %q, must be one of: %s", mode, strings.Join(sets.List(pvcAccessModes), ", "))
+		}
+	}
+	if len(o.VolumeMode) > 0 && o.VolumeMode != string(corev1.PersistentVolumeFilesystem) && o.VolumeMode != string(corev1.PersistentVolumeBlock) {
+		return fmt.Errorf("invalid --volume-mode %q, must be one of: Filesystem, Block", o.VolumeMode)
+	}
+	if len(o.WithPV) > 0 {
+		if _, _, err := parsePVSource(o.WithPV); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run creates the persistent volume, then the claim bound to it
+func (o *PersistentVolumeClaimOptions) Run() error {
+	claim, volume, err := o.createPersistentVolumeClaim()
+	if err != nil {
+		return err
+	}
+
+	if volume != nil {
+		if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, volume, scheme.DefaultJSONEncoder()); err != nil {
+			return err
+		}
+		if o.DryRunStrategy != cmdutil.DryRunClient {
+			volume, err = o.Client.PersistentVolumes().Create(context.TODO(), volume, o.createOptions())
+			if err != nil {
+				return fmt.Errorf("failed to create persistentvolume: %v", err)
+			}
+		}
+		if err := o.PrintObj(volume); err != nil {
+			return err
+		}
+	}
+
+	if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, claim, scheme.DefaultJSONEncoder()); err != nil {
+		return err
+	}
+	if o.DryRunStrategy != cmdutil.DryRunClient {
+		claim, err = o.Client.PersistentVolumeClaims(o.Namespace).Create(context.TODO(), claim, o.createOptions())
+		if err != nil {
+			return fmt.Errorf("failed to create persistentvolumeclaim: %v", err)
+		}
+	}
+	return o.PrintObj(claim)
+}
+
+func (o *PersistentVolumeClaimOptions) createOptions() metav1.CreateOptions {
+	createOptions := metav1.CreateOptions{}
+	if o.FieldManager != "" {
+		createOptions.FieldManager = o.FieldManager
+	}
+	createOptions.FieldValidation = o.ValidationDirective
+	if o.DryRunStrategy == cmdutil.DryRunServer {
+		createOptions.DryRun = []string{metav1.DryRunAll}
+	}
+	return createOptions
+}
+
+// createPersistentVolumeClaim returns the claim, and the volume bound to it
+// with --with-pv.
+func (o *PersistentVolumeClaimOptions) createPersistentVolumeClaim() (*corev1.PersistentVolumeClaim, *corev1.PersistentVolume, error) {
+	storage, err := resourceapi.ParseQuantity(o.Storage)
+	if err != nil {
+		return nil, nil, err
+	}
+	accessModes := []corev1.PersistentVolumeAccessMode{}
+	for _, mode := range o.AccessModes {
+		accessModes = append(accessModes, corev1.PersistentVolumeAccessMode(mode))
+	}
+	var volumeMode *corev1.PersistentVolumeMode
+	if len(o.VolumeMode) > 0 {
+		mode := corev1.PersistentVolumeMode(o.VolumeMode)
+		volumeMode = &mode
+	}
+
+	namespace := ""
+	if o.EnforceNamespace {
+		namespace = o.Namespace
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "PersistentVolumeClaim"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      o.Name,
+			Namespace: namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: accessModes,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: storage},
+			},
+			VolumeMode: volumeMode,
+		},
+	}
+	if len(o.StorageClass) > 0 {
+		claim.Spec.StorageClassName = &o.StorageClass
+	}
+	if len(o.WithPV) == 0 {
+		return claim, nil, nil
+	}
+
+	source, nodeAffinity, err := parsePVSource(o.WithPV)
+	if err != nil {
+		return nil, nil, err
+	}
+	volume := &corev1.PersistentVolume{
+		TypeMeta: metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "PersistentVolume"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: o.Namespace + "-" + o.Name,
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity:                      corev1.ResourceList{corev1.ResourceStorage: storage},
+			AccessModes:                   accessModes,
+			PersistentVolumeSource:        source,
+			PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+			StorageClassName:              o.StorageClass,
+			VolumeMode:                    volumeMode,
+			NodeAffinity:                  nodeAffinity,
+			ClaimRef: &corev1.ObjectReference{
+				APIVersion: "v1",
+				Kind:       "PersistentVolumeClaim",
+				Namespace:  o.Namespace,
+				Name:       o.Name,
+			},
+		},
+	}
+	// an explicitly empty storage class keeps the default storage class from
+	// being set on the claim, which would not match the volume
+	claim.Spec.StorageClassName = &volume.Spec.StorageClassName
+	claim.Spec.VolumeName = volume.Name
+	return claim, volume, nil
+}
+
+// parsePVSource parses the --with-pv source of a persistent volume, and
+// returns the node affinity of local volumes.
+func parsePVSource(spec string) (corev1.PersistentVolumeSource, *corev1.VolumeNodeAffinity, error) {
+	kind, rest, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "hostpath":
+		if strings.HasPrefix(rest, "/") {
+			hostPathType := corev1.HostPathDirectoryOrCreate
+			return corev1.PersistentVolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: rest, Type: &hostPathType}}, nil, nil
+		}
+	case "local":
+		node, path, found := strings.Cut(rest, ":")
+		if found && len(node) > 0 && strings.HasPrefix(path, "/") {
+			nodeAffinity := &corev1.VolumeNodeAffinity{
+				Required: &corev1.NodeSelector{
+					NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key:      corev1.LabelHostname,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   []string{node},
+						}},
+					}},
+				},
+			}
+			return corev1.PersistentVolumeSource{Local: &corev1.LocalVolumeSource{Path: path}}, nodeAffinity, nil
+		}
+	case "nfs":
+		server, path, found := strings.Cut(rest, ":")
+		if found && len(server) > 0 && strings.HasPrefix(path, "/") {
+			return corev1.PersistentVolumeSource{NFS: &corev1.NFSVolumeSource{Server: server, Path: path}}, nil, nil
+		}
+	}
+	return corev1.PersistentVolumeSource{}, nil, fmt.Errorf("invalid --with-pv %q, must be one of: hostpath:PATH, local:NODE:PATH, nfs:SERVER:PATH, with an absolute PATH", spec)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	resourceapi "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	restclient "k8s.io/client-go/rest"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestCreatePersistentVolumeClaim(t *testing.T) {
+	storage := resourceapi.MustParse("10Gi")
+	empty := ""
+	directoryOrCreate := corev1.HostPathDirectoryOrCreate
+	claimMeta := metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"}
+	volumeMeta := metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolume"}
+
+	tests := map[string]struct {
+		options        *PersistentVolumeClaimOptions
+		expectedClaim  *corev1.PersistentVolumeClaim
+		expectedVolume *corev1.PersistentVolume
+	}{
+		"claim": {
+			options: &PersistentVolumeClaimOptions{Name: "data", Storage: "10Gi", AccessModes: []string{"ReadWriteOnce"}, Namespace: "test"},
+			expectedClaim: &corev1.PersistentVolumeClaim{
+				TypeMeta:   claimMeta,
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources:   corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: storage}},
+				},
+			},
+		},
+		"claim with a host path volume": {
+			options: &PersistentVolumeClaimOptions{Name: "data", Storage: "10Gi", AccessModes: []string{"ReadWriteOnce"}, WithPV: "hostpath:/data/foo", Namespace: "test", EnforceNamespace: true},
+			expectedClaim: &corev1.PersistentVolumeClaim{
+				TypeMeta:   claimMeta,
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "test"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: storage}},
+					StorageClassName: &empty,
+					VolumeName:       "test-data",
+				},
+			},
+			expectedVolume: &corev1.PersistentVolume{
+				TypeMeta:   volumeMeta,
+				ObjectMeta: metav1.ObjectMeta{Name: "test-data"},
+				Spec: corev1.PersistentVolumeSpec{
+					Capacity:                      corev1.ResourceList{corev1.ResourceStorage: storage},
+					AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					PersistentVolumeSource:        corev1.PersistentVolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/data/foo", Type: &directoryOrCreate}},
+					PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+					ClaimRef:                      &corev1.ObjectReference{APIVersion: "v1", Kind: "PersistentVolumeClaim", Namespace: "test", Name: "data"},
+				},
+			},
+		},
+		"claim with a local volume": {
+			options: &PersistentVolumeClaimOptions{Name: "data", Storage: "10Gi", AccessModes: []string{"ReadWriteOncePod"}, StorageClass: "local", WithPV: "local:node-1:/mnt/ssd", Namespace: "test"},
+			expectedClaim: &corev1.PersistentVolumeClaim{
+				TypeMeta:   claimMeta,
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod},
+					Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: storage}},
+					StorageClassName: stringPtr("local"),
+					VolumeName:       "test-data",
+				},
+			},
+			expectedVolume: &corev1.PersistentVolume{
+				TypeMeta:   volumeMeta,
+				ObjectMeta: metav1.ObjectMeta{Name: "test-data"},
+				Spec: corev1.PersistentVolumeSpec{
+					Capacity:                      corev1.ResourceList{corev1.ResourceStorage: storage},
+					AccessModes:                   []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod},
+					PersistentVolumeSource:        corev1.PersistentVolumeSource{Local: &corev1.LocalVolumeSource{Path: "/mnt/ssd"}},
+					PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+					StorageClassName:              "local",
+					ClaimRef:                      &corev1.ObjectReference{APIVersion: "v1", Kind: "PersistentVolumeClaim", Namespace: "test", Name: "data"},
+					NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}}},
+					}}}},
+				},
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			claim, volume, err := tc.options.createPersistentVolumeClaim()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !apiequality.Semantic.DeepEqual(claim, tc.expectedClaim) {
+				t.Errorf("expected claim:\n%#v\ngot:\n%#v", tc.expectedClaim, claim)
+			}
+			if !apiequality.Semantic.DeepEqual(volume, tc.expectedVolume) {
+				t.Errorf("expected volume:\n%#v\ngot:\n%#v", tc.expectedVolume, volume)
+			}
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestCreatePersistentVolumeClaimValidation(t *testing.T) {
+	tests := map[string]struct {
+		options     *PersistentVolumeClaimOptions
+		expectedErr string
+	}{
+		"missing storage": {
+			options:     &PersistentVolumeClaimOptions{AccessModes: []string{"ReadWriteOnce"}},
+			expectedErr: "--storage must be specified",
+		},
+		"invalid access mode": {
+			options:     &PersistentVolumeClaimOptions{Storage: "1Gi", AccessModes: []string{"ReadWriteSometimes"}},
+			expectedErr: `invalid --access-mode "ReadWriteSometimes"`,
+		},
+		"relative host path": {
+			options:     &PersistentVolumeClaimOptions{Storage: "1Gi", AccessModes: []string{"ReadWriteOnce"}, WithPV: "hostpath:data"},
+			expectedErr: `invalid --with-pv "hostpath:data"`,
+		},
+		"local volume without node": {
+			options:     &PersistentVolumeClaimOptions{Storage: "1Gi", AccessModes: []string{"ReadWriteOnce"}, WithPV: "local:/mnt/ssd"},
+			expectedErr: `invalid --with-pv "local:/mnt/ssd"`,
+		},
+		"nfs volume": {
+			options: &PersistentVolumeClaimOptions{Storage: "1Gi", AccessModes: []string{"ReadWriteMany"}, WithPV: "nfs:nfs.example.com:/exports/shared"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.options.Validate()
+			if len(tc.expectedErr) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCreatePersistentVolumeClaimDryRun(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	tf.ClientConfigVal = &restclient.Config{}
+
+	ioStreams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdCreatePersistentVolumeClaim(tf, ioStreams)
+	cmd.Flags().Set("storage", "10Gi")
+	cmd.Flags().Set("with-pv", "hostpath:/data/foo")
+	cmd.Flags().Set("dry-run", "client")
+	cmd.Flags().Set("output", "name")
+	cmd.Run(cmd, []string{"data"})
+
+	expected := "persistentvolume/test-data\npersistentvolumeclaim/data\n"
+	if buf.String() != expected {
+		t.Errorf("expected output:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -78,7 +78,12 @@ var (
 
 		When creating a secret based on a directory, each file whose basename is a valid key in the directory will be
 		packaged into the secret. Any directory entries except regular files are ignored (e.g. subdirectories,
-		symlinks, devices, pipes, etc).`))
+		symlinks, devices, pipes, etc).
+
+		When creating a secret based on a SOPS encrypted file, the file is decrypted locally with the sops binary,
+		using the age identity file if one is given. Each top-level field of an encrypted YAML or JSON file, and each
+		line of an encrypted env file, becomes a key. Other encrypted files are packaged like files, with the .enc
+		extension removed from the default key name.`))
 
 	secretForGenericExample = templates.Examples(i18n.T(`
 	  # Create a new secret named my-secret with keys for each file in folder bar
@@ -94,7 +99,10 @@ var (
 	  kubectl create secret generic my-secret --from-file=ssh-privatekey=path/to/id_rsa --from-literal=passphrase=topsecret
 
 	  # Create a new secret named my-secret from env files
-	  kubectl create secret generic my-secret --from-env-file=path/to/foo.env --from-env-file=path/to/bar.env`))
+	  kubectl create secret generic my-secret --from-env-file=path/to/foo.env --from-env-file=path/to/bar.env
+
+	  # Create a new secret named my-secret from a SOPS file encrypted with age
+	  kubectl create secret generic my-secret --from-encrypted-file=secrets.enc.yaml --age-identity=path/to/keys.txt`))
 )
 
 // CreateSecretOptions holds the options for 'create secret' sub command
@@ -113,6 +121,10 @@ type CreateSecretOptions struct {
 	LiteralSources []string
 	// EnvFileSources to derive the secret from (optional)
 	EnvFileSources []string
+	// EncryptedFileSources are SOPS encrypted files to derive the secret from (optional)
+	EncryptedFileSources []string
+	// AgeIdentityFile holds the age keys used to decrypt EncryptedFileSources (optional)
+	AgeIdentityFile string
 	// AppendHash; if true, derive a hash from the Secret data and type and append it to the name
 	AppendHash bool
 
@@ -141,7 +153,7 @@ func NewCmdCreateSecretGeneric(f cmdutil.Factory, ioStreams genericiooptions.IOS
 	o := NewSecretOptions(ioStreams)
 
 	cmd := &cobra.Command{
-		Use:                   "generic NAME [--type=string] [--from-file=[key=]source] [--from-literal=key1=value1] [--dry-run=server|client|none]",
+		Use:                   "generic NAME [--type=string] [--from-file=[key=]source] [--from-literal=key1=value1] [--from-encrypted-file=[key=]source] [--dry-run=server|client|none]",
 		DisableFlagsInUseLine: true,
 		Short:                 i18n.T("Create a secret from a local file, directory, or literal value"),
 		Long:                  secretForGenericLong,
@@ -161,6 +173,8 @@ func NewCmdCreateSecretGeneric(f cmdutil.Factory, ioStreams genericiooptions.IOS
 	cmd.Flags().StringSliceVar(&o.FileSources, "from-file", o.FileSources, "Key files can be specified using their file path, in which case a default name will be given to them, or optionally with a name and file path, in which case the given name will be used.  Specifying a directory will iterate each named file in the directory that is a valid secret key.")
 	cmd.Flags().StringArrayVar(&o.LiteralSources, "from-literal", o.LiteralSources, "Specify a key and literal value to insert in secret (i.e. mykey=somevalue)")
 	cmd.Flags().StringSliceVar(&o.EnvFileSources, "from-env-file", o.EnvFileSources, "Specify the path to a file to read lines of key=val pairs to create a secret.")
+	cmd.Flags().StringSliceVar(&o.EncryptedFileSources, "from-encrypted-file", o.EncryptedFileSources, "Specify the path to a SOPS encrypted file, optionally with a key name, to decrypt locally and insert in secret. YAML, JSON and env files insert one key per entry.")
+	cmd.Flags().StringVar(&o.AgeIdentityFile, "age-identity", o.AgeIdentityFile, "Path to the age identity file used to decrypt --from-encrypted-file. Defaults to the sops key file.")
 	cmd.Flags().StringVar(&o.Type, "type", o.Type, i18n.T("The type of secret to create"))
 	cmd.Flags().BoolVar(&o.AppendHash, "append-hash", o.AppendHash, "Append a hash of the secret to its name.")
 
@@ -225,6 +239,9 @@ func (o *CreateSecretOptions) Validate() error {
 	if len(o.EnvFileSources) > 0 && (len(o.FileSources) > 0 || len(o.LiteralSources) > 0) {
 		return fmt.Errorf("from-env-file cannot be combined with from-file or from-literal")
 	}
+	if len(o.AgeIdentityFile) > 0 && len(o.EncryptedFileSources) == 0 {
+		return fmt.Errorf("age-identity requires from-encrypted-file")
+	}
 	return nil
 }
 
@@ -280,6 +297,11 @@ func (o *CreateSecretOptions) createSecret() (*corev1.Secret, error) {
 			return nil, err
 		}
 	}
+	if len(o.EncryptedFileSources) > 0 {
+		if err := handleSecretFromEncryptedFileSources(secret, o.EncryptedFileSources, o.AgeIdentityFile); err != nil {
+			return nil, err
+		}
+	}
 	if o.AppendHash {
 		hash, err := hash.SecretHash(secret)
 		if err != nil {
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util"
+	"sigs.k8s.io/yaml"
+)
+
+// decryptFile returns the decrypted content of a SOPS encrypted file,
+// using the age identity file if one is given. It is a variable so that
+// tests don't need the sops binary.
+var decryptFile = decryptWithSOPS
+
+// decryptWithSOPS decrypts the file with the sops binary. Decryption happens
+// locally, the keys never leave the machine.
+func decryptWithSOPS(filePath, identityFile string) ([]byte, error) {
+	cmd := exec.Command("sops", "--decrypt", filePath)
+	cmd.Env = os.Environ()
+	if len(identityFile) > 0 {
+		cmd.Env = append(cmd.Env, "SOPS_AGE_KEY_FILE="+identityFile)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, fmt.Errorf("decrypting %s requires sops to be installed: %v", filePath, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting %s: %v %s", filePath, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// handleSecretFromEncryptedFileSources adds the content of SOPS encrypted
+// files into the provided secret. YAML and JSON files hold one key per
+// top-level field and env files one key per line. Other files, and files
+// given with an explicit key name, are added as a single key whose default
+// name is the basename of the file without the .enc extension.
+func handleSecretFromEncryptedFileSources(secret *corev1.Secret, encryptedFileSources []string, identityFile string) error {
+	for _, fileSource := range encryptedFileSources {
+		keyName, filePath, err := util.ParseFileSource(fileSource)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(filePath); err != nil {
+			return fmt.Errorf("error reading %s: %v", filePath, err)
+		} else if info.IsDir() {
+			return fmt.Errorf("encrypted secret file %s cannot be a directory", filePath)
+		}
+		data, err := decryptFile(filePath, identityFile)
+		if err != nil {
+			return err
+		}
+
+		if strings.Contains(fileSource, "=") {
+			if err := addKeyFromLiteralToSecret(secret, keyName, data); err != nil {
+				return err
+			}
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(filePath)) {
+		case ".yaml", ".yml", ".json":
+			err = addKeysFromDecryptedDocument(secret, filePath, data)
+		case ".env":
+			err = cmdutil.AddFromEnvReader(bytes.NewReader(data), filePath, func(key, value string) error {
+				return addKeyFromLiteralToSecret(secret, key, []byte(value))
+			})
+		default:
+			err = addKeyFromLiteralToSecret(secret, decryptedKeyName(keyName), data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decryptedKeyName removes the .enc extension of an encrypted file name, as
+// in tls.key.enc or tls.enc.key.
+func decryptedKeyName(name string) string {
+	if trimmed := strings.TrimSuffix(name, ".enc"); trimmed != name {
+		return trimmed
+	}
+	return strings.Replace(name, ".enc.", ".", 1)
+}
+
+// addKeysFromDecryptedDocument adds the top-level fields of a decrypted YAML
+// or JSON document as keys of the secret. Values must be scalars.
+func addKeysFromDecryptedDocument(secret *corev1.Secret, filePath string, data []byte) error {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return fmt.Errorf("error parsing decrypted %s: %v", filePath, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	fields := map[string]interface{}{}
+	if err := decoder.Decode(&fields); err != nil {
+		return fmt.Errorf("decrypted %s must hold a map of keys to values: %v", filePath, err)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var value string
+		switch v := fields[key].(type) {
+		case string:
+			value = v
+		case json.Number, bool:
+			value = fmt.Sprint(v)
+		case nil:
+		default:
+			return fmt.Errorf("key %s of decrypted %s is not a string, number or boolean", key, filePath)
+		}
+		if err := addKeyFromLiteralToSecret(secret, key, []byte(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -17,7 +17,11 @@ limitations under the License.
 package create
 
 import (
+	"bytes"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -578,3 +582,95 @@ func setupSecretBinaryFile(data []byte) func(*testing.T, *CreateSecretOptions) f
 		}
 	}
 }
+
+func TestCreateSecretFromEncryptedFile(t *testing.T) {
+	defer func(decrypt func(string, string) ([]byte, error)) { decryptFile = decrypt }(decryptFile)
+	decryptFile = func(filePath, identityFile string) ([]byte, error) {
+		if identityFile != "keys.txt" {
+			return nil, fmt.Errorf("unexpected identity file %q", identityFile)
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.TrimPrefix(data, []byte("ENC:")), nil
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"secrets.enc.yaml": "ENC:password: hunter2\nport: 5432\ndebug: false\n",
+		"secrets.enc.json": `ENC:{"token": "abc"}`,
+		"app.enc.env":      "ENC:USER=admin\n# comment\n",
+		"tls.key.enc":      "ENC:private",
+		"nested.enc.yaml":  "ENC:db:\n  password: hunter2\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		sources   []string
+		identity  string
+		expected  map[string][]byte
+		expectErr string
+	}{
+		"documents, env files and binary files": {
+			sources:  []string{"secrets.enc.yaml", "secrets.enc.json", "app.enc.env", "tls.key.enc"},
+			identity: "keys.txt",
+			expected: map[string][]byte{
+				"password": []byte("hunter2"),
+				"port":     []byte("5432"),
+				"debug":    []byte("false"),
+				"token":    []byte("abc"),
+				"USER":     []byte("admin"),
+				"tls.key":  []byte("private"),
+			},
+		},
+		"explicit key name": {
+			sources:  []string{"config=secrets.enc.json"},
+			identity: "keys.txt",
+			expected: map[string][]byte{"config": []byte(`{"token": "abc"}`)},
+		},
+		"nested values": {
+			sources:   []string{"nested.enc.yaml"},
+			identity:  "keys.txt",
+			expectErr: "is not a string, number or boolean",
+		},
+		"decryption failure": {
+			sources:   []string{"tls.key.enc"},
+			expectErr: "unexpected identity file",
+		},
+		"duplicate keys": {
+			sources:   []string{"tls.key.enc", "tls.key=secrets.enc.json"},
+			identity:  "keys.txt",
+			expectErr: "cannot add key tls.key, another key by that name already exists",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := CreateSecretOptions{Name: "foo", AgeIdentityFile: test.identity}
+			for _, source := range test.sources {
+				key, file, found := strings.Cut(source, "=")
+				if found {
+					source = key + "=" + filepath.Join(dir, file)
+				} else {
+					source = filepath.Join(dir, source)
+				}
+				o.EncryptedFileSources = append(o.EncryptedFileSources, source)
+			}
+			require.NoError(t, o.Validate())
+			secret, err := o.createSecret()
+			if len(test.expectErr) > 0 {
+				require.ErrorContains(t, err, test.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, secret.Data)
+		})
+	}
+
+	o := CreateSecretOptions{Name: "foo", AgeIdentityFile: "keys.txt"}
+	require.EqualError(t, o.Validate(), "age-identity requires from-encrypted-file")
+}
@@ -17,10 +17,18 @@ limitations under the License.
 package create
 
 import (
+	"bufio"
 	"context"
+	"crypto"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
@@ -35,6 +43,7 @@ import (
 	"k8s.io/kubectl/pkg/util/hash"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
+	"software.sslmate.com/src/go-pkcs12"
 )
 
 var (
@@ -42,11 +51,18 @@ var (
 		Create a TLS secret from the given public/private key pair.
 
 		The public/private key pair must exist beforehand. The public key certificate must be .PEM encoded and match
-		the given private key.`))
+		the given private key. The key pair may also be read from a PKCS#12 bundle, whose password is read from stdin.
+
+		With --include-ca-chain, the certificates following the public key certificate must form its chain, each one
+		signing the previous one. Without it, a warning is printed when they do not. A warning is also printed for
+		certificates that are expired, not yet valid, or expire within 30 days.`))
 
 	secretForTLSExample = templates.Examples(i18n.T(`
 	  # Create a new TLS secret named tls-secret with the given key pair
-	  kubectl create secret tls tls-secret --cert=path/to/tls.cert --key=path/to/tls.key`))
+	  kubectl create secret tls tls-secret --cert=path/to/tls.cert --key=path/to/tls.key
+
+	  # Create a new TLS secret named tls-secret from a PKCS#12 bundle, with its CA chain in ca.crt
+	  kubectl create secret tls tls-secret --from-p12=path/to/tls.p12 --password-stdin --include-ca-chain < password.txt`))
 )
 
 // CreateSecretTLSOptions holds the options for 'create secret tls' sub command
@@ -61,6 +77,12 @@ type CreateSecretTLSOptions struct {
 	Key string
 	// Cert is the path to the user's public key certificate.
 	Cert string
+	// P12 is the path to a PKCS#12 bundle holding the private key and certificates.
+	P12 string
+	// PasswordStdin; if true, the password of the PKCS#12 bundle is read from stdin
+	PasswordStdin bool
+	// IncludeCAChain; if true, the certificates following the leaf certificate are added as ca.crt
+	IncludeCAChain bool
 	// AppendHash; if true, derive a hash from the Secret and append it to the name
 	AppendHash bool
 
@@ -89,7 +111,7 @@ func NewCmdCreateSecretTLS(f cmdutil.Factory, ioStreams genericiooptions.IOStrea
 	o := NewSecretTLSOptions(ioStreams)
 
 	cmd := &cobra.Command{
-		Use:                   "tls NAME --cert=path/to/cert/file --key=path/to/key/file [--dry-run=server|client|none]",
+		Use:                   "tls NAME (--cert=path/to/cert/file --key=path/to/key/file | --from-p12=path/to/bundle) [--dry-run=server|client|none]",
 		DisableFlagsInUseLine: true,
 		Short:                 i18n.T("Create a TLS secret"),
 		Long:                  secretForTLSLong,
@@ -109,6 +131,9 @@ func NewCmdCreateSecretTLS(f cmdutil.Factory, ioStreams genericiooptions.IOStrea
 
 	cmd.Flags().StringVar(&o.Cert, "cert", o.Cert, i18n.T("Path to PEM encoded public key certificate."))
 	cmd.Flags().StringVar(&o.Key, "key", o.Key, i18n.T("Path to private key associated with given certificate."))
+	cmd.Flags().StringVar(&o.P12, "from-p12", o.P12, i18n.T("Path to a PKCS#12 bundle holding the private key and certificate chain, instead of --cert and --key."))
+	cmd.Flags().BoolVar(&o.PasswordStdin, "password-stdin", o.PasswordStdin, i18n.T("Read the password of the PKCS#12 bundle from stdin."))
+	cmd.Flags().BoolVar(&o.IncludeCAChain, "include-ca-chain", o.IncludeCAChain, i18n.T("Add the certificates following the leaf certificate to the secret as ca.crt. They must form the chain of the leaf certificate."))
 	cmd.Flags().BoolVar(&o.AppendHash, "append-hash", o.AppendHash, "Append a hash of the secret to its name.")
 
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-create")
@@ -169,6 +194,15 @@ func (o *CreateSecretTLSOptions) Validate() error {
 	// TODO: This is not strictly necessary. We can generate a self signed cert
 	// if no key/cert is given. The only requirement is that we either get both
 	// or none. See test/e2e/ingress_utils for self signed cert generation.
+	if len(o.P12) > 0 {
+		if len(o.Key) > 0 || len(o.Cert) > 0 {
+			return fmt.Errorf("from-p12 cannot be combined with key or cert")
+		}
+		return nil
+	}
+	if o.PasswordStdin {
+		return fmt.Errorf("password-stdin requires from-p12")
+	}
 	if len(o.Key) == 0 || len(o.Cert) == 0 {
 		return fmt.Errorf("key and cert must be specified")
 	}
@@ -182,6 +216,19 @@ func (o *CreateSecretTLSOptions) Run() error {
 	if err != nil {
 		return err
 	}
+	certs, err := parseCertificates(secretTLS.Data[corev1.TLSCertKey])
+	if err != nil {
+		return err
+	}
+	for _, warning := range certificateWarnings(certs, time.Now()) {
+		fmt.Fprintf(o.ErrOut, "Warning: %s\n", warning)
+	}
+	// bundles in another order, or with extra certificates, are accepted
+	if !o.IncludeCAChain {
+		if err := validateChain(certs); err != nil {
+			fmt.Fprintf(o.ErrOut, "Warning: %v\n", err)
+		}
+	}
 	err = util.CreateOrUpdateAnnotation(o.CreateAnnotation, secretTLS, scheme.DefaultJSONEncoder())
 	if err != nil {
 		return err
@@ -210,24 +257,39 @@ func (o *CreateSecretTLSOptions) createSecretTLS() (*corev1.Secret, error) {
 	if o.EnforceNamespace {
 		namespace = o.Namespace
 	}
-	tlsCert, err := readFile(o.Cert)
-	if err != nil {
-		return nil, err
+	var tlsCert, tlsKey []byte
+	var err error
+	if len(o.P12) > 0 {
+		tlsCert, tlsKey, err = o.readP12()
+	} else {
+		if tlsCert, err = readFile(o.Cert); err == nil {
+			tlsKey, err = readFile(o.Key)
+		}
 	}
-	tlsKey, err := readFile(o.Key)
 	if err != nil {
 		return nil, err
 	}
 	if _, err := tls.X509KeyPair(tlsCert, tlsKey); err != nil {
 		return nil, err
 	}
-	// TODO: Add more validation.
-	// 1. If the certificate contains intermediates, it is a valid chain.
-	// 2. Format etc.
 
 	secretTLS := newSecretObj(o.Name, namespace, corev1.SecretTypeTLS)
 	secretTLS.Data[corev1.TLSCertKey] = []byte(tlsCert)
 	secretTLS.Data[corev1.TLSPrivateKeyKey] = []byte(tlsKey)
+	if o.IncludeCAChain {
+		certs, err := parseCertificates(tlsCert)
+		if err != nil {
+			return nil, err
+		}
+		if len(certs) < 2 {
+			return nil, fmt.Errorf("include-ca-chain requires the certificate to be followed by its CA chain")
+		}
+		// ca.crt must hold the chain of the certificate only
+		if err := validateChain(certs); err != nil {
+			return nil, err
+		}
+		secretTLS.Data[corev1.ServiceAccountRootCAKey] = encodeCertificates(certs[1:])
+	}
 	if o.AppendHash {
 		hash, err := hash.SecretHash(secretTLS)
 		if err != nil {
@@ -247,3 +309,128 @@ func readFile(file string) ([]byte, error) {
 	}
 	return b, nil
 }
+
+// readP12 returns the PEM encoded certificate chain and private key of the
+// PKCS#12 bundle, the leaf certificate first.
+func (o *CreateSecretTLSOptions) readP12() ([]byte, []byte, error) {
+	data, err := readFile(o.P12)
+	if err != nil {
+		return nil, nil, err
+	}
+	password := ""
+	if o.PasswordStdin {
+		line, err := bufio.NewReader(o.In).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, nil, fmt.Errorf("error reading the password from stdin: %v", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	key, certs, err := decodePKCS12(data, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading %s: %v", o.P12, err)
+	}
+
+	leaf := -1
+	if signer, ok := key.(crypto.Signer); ok {
+		for i, cert := range certs {
+			if public, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool }); ok && public.Equal(cert.PublicKey) {
+				leaf = i
+				break
+			}
+		}
+	}
+	if leaf < 0 {
+		return nil, nil, fmt.Errorf("the private key of %s matches none of its certificates", o.P12)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	chain := orderChain(certs[leaf], append(certs[:leaf:leaf], certs[leaf+1:]...))
+	return encodeCertificates(chain), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), nil
+}
+
+// decodePKCS12 returns the private key and the certificates of a PKCS#12
+// bundle, in the order of the bundle.
+func decodePKCS12(data []byte, password string) (interface{}, []*x509.Certificate, error) {
+	key, cert, caCerts, err := pkcs12.DecodeChain(data, password)
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) || errors.Is(err, pkcs12.ErrDecryption) {
+		return nil, nil, fmt.Errorf("unable to decode the PKCS#12 bundle, the password is incorrect or the bundle is corrupted")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, append([]*x509.Certificate{cert}, caCerts...), nil
+}
+
+// orderChain returns the leaf followed by its issuers, then by the
+// certificates that are not part of its chain.
+func orderChain(leaf *x509.Certificate, others []*x509.Certificate) []*x509.Certificate {
+	chain := []*x509.Certificate{leaf}
+	for current := leaf; ; {
+		next := -1
+		for i, cert := range others {
+			if string(cert.RawSubject) == string(current.RawIssuer) && string(current.RawSubject) != string(current.RawIssuer) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return append(chain, others...)
+		}
+		current = others[next]
+		chain = append(chain, current)
+		others = append(others[:next:next], others[next+1:]...)
+	}
+}
+
+// validateChain checks that each certificate is signed by the next one.
+func validateChain(certs []*x509.Certificate) error {
+	for i := 0; i+1 < len(certs); i++ {
+		if err := certs[i].CheckSignatureFrom(certs[i+1]); err != nil {
+			return fmt.Errorf("certificate %q is not signed by the next certificate %q of the chain: %v", certs[i].Subject, certs[i+1].Subject, err)
+		}
+	}
+	return nil
+}
+
+// certificateWarnings returns warnings for the certificates that are not
+// valid at the given time or expire within 30 days.
+func certificateWarnings(certs []*x509.Certificate, now time.Time) []string {
+	var warnings []string
+	for _, cert := range certs {
+		switch {
+		case now.After(cert.NotAfter):
+			warnings = append(warnings, fmt.Sprintf("certificate %q expired on %s", cert.Subject, cert.NotAfter.UTC().Format(time.RFC3339)))
+		case now.Before(cert.NotBefore):
+			warnings = append(warnings, fmt.Sprintf("certificate %q is not valid before %s", cert.Subject, cert.NotBefore.UTC().Format(time.RFC3339)))
+		case cert.NotAfter.Sub(now) < 30*24*time.Hour:
+			warnings = append(warnings, fmt.Sprintf("certificate %q expires on %s", cert.Subject, cert.NotAfter.UTC().Format(time.RFC3339)))
+		}
+	}
+	return warnings
+}
+
+// parseCertificates parses the certificates of PEM encoded data.
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+func encodeCertificates(certs []*x509.Certificate) []byte {
+	var out []byte
+	for _, cert := range certs {
+		out = append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	return out
+}
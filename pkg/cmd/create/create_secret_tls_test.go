@@ -17,13 +17,22 @@ limitations under the License.
 package create
 
 import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
 	"os"
 	"path"
+	"strings"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
 var rsaCertPEM = `-----BEGIN CERTIFICATE-----
@@ -203,3 +212,188 @@ func writeKeyPair(tmpDirPath, key, cert string, t *testing.T) (keyPath, certPath
 	write(certPath, cert, t)
 	return
 }
+
+// p12AES and p12DES hold the key of example.com, its certificate and the
+// certificate of the test-ca issuer, protected with the password kubectl.
+// They were exported with openssl pkcs12 -export, using its defaults for
+// p12AES and -keypbe PBE-SHA1-3DES -certpbe PBE-SHA1-3DES -macalg sha1 for
+// p12DES.
+var p12AES = `MIIFXAIBAzCCBRIGCSqGSIb3DQEHAaCCBQMEggT/MIIE+zCCA7IGCSqGSIb3DQEH
+BqCCA6MwggOfAgEAMIIDmAYJKoZIhvcNAQcBMFcGCSqGSIb3DQEFDTBKMCkGCSqG
+SIb3DQEFDDAcBAh1zzcWtFYzkAICCAAwDAYIKoZIhvcNAgkFADAdBglghkgBZQME
+ASoEENG5hr28VqKZEEuFXEHGqHWAggMwHXJrGBB3lhE3hNWAImwMV0EN6uPMrg+0
+bpEITDgtWJGtmeeX+aUJUV93VFOGyBtg8anldl86X+VwLI8PZv+rdK6L5sRSk4YG
+hRJg28OvqxRsSiLyWzNLCBKNsOD/dVBQCyS5kXBR/4e+h6EuCvtaz9NvX0wK4Mk3
+IBC8Q7JEyDkv1od3nOvTv6mHpkBLZQE2yszPzv/d8SqqKiLBAVty0ZXueAPBLurF
+pBLn6mPkfdV4EGi4ZRbdpER05ZUlpmB5Kx8lbLdVDsrjsLwx4yh5Ml0Ura5cUQmT
+VD/epQ0vn0cgS/WHrpoTU5fsQGEsxGB6rLnAaYWQNRZdTepUKqnOnqBESzEN50jr
+VCXr4NavuqiYv+KFf5UuVSyFKuejFXj3aLAgc1BNCTe+Xakjt9eooA9QBDqYR7pR
+9+1Db1T2bO5iEVhhdm39ndS4/YDTV8k9sApf0zBatRo0tORidBe8dfLjVZPIHTd5
+/t9inFMuQcjhuvaA73jd7cwTDf/MeGAnV8d/GFrECppr3YZ3LC4cqKb91qNMuwuU
+ovE4EtwI22enhYj9NAV3CmMEB2uEi4RiN6gzRvNAHuXGRSsXPVoq/irhboa93UOo
+giewY56jzhBmV8QUqi15lkd0s6izMcynk6qaSex3FHk4B2VDixooHc/0kxUfYUVX
+NqIe2A36p/7L9KBgMBz5AoQg75DW1X58uNPXB6JBP8XV6cm5s2NC5KTw1fwIBdxY
+jMrZA0aAe/PnMLqLVQFfZXJrTLJwK/qDf+f2Sh6lrhUnDNlLPNw+b0a1gsryZ4zc
+tXVUUQhAoH2OYvW8pMXhUs5/qnGqNXpQWgxmKQrT25oTtufbrdtmrwq8i58KlYEt
+cpPy8xD4dvXzGIdO4l6LtdBy8EQAJrCwnEcWLFJqfDLl4yoNKzv3+MS0hodCejja
+rgMKHKKKDjr/qwvJTe5jjoJy4b8e+oy7H+r79rbaY3Bkts9zUoPu2Xcwik5wl//Q
+5/nYPheF4hPaxa7goYOkO4+fIOa8esaetlllH2xwN20d4TR1Iu8SyNSnMEOH24VH
+Q60G81MkltvNc9TAzoOZXLDqEgMJCUWNMIIBQQYJKoZIhvcNAQcBoIIBMgSCAS4w
+ggEqMIIBJgYLKoZIhvcNAQwKAQKgge8wgewwVwYJKoZIhvcNAQUNMEowKQYJKoZI
+hvcNAQUMMBwECCxlctVX/y8pAgIIADAMBggqhkiG9w0CCQUAMB0GCWCGSAFlAwQB
+KgQQjUCg63XDcWpEkndMqzWRCASBkPBQzwzQPu1MfQIS+wwAEgqR3h+5CdaPTH15
+164s+auGbWoFosoYK8bbjAyIvbejAh2/lePCYooMesO4gMGpVAHZBoJtk8LVJjyQ
+PXvOf3aDI83J7O6buKZbXQRlKc2YR1ujcT79fNZsv8AcHLJDOlNmvshsPvhDEpdU
+j5L7R3tB/jJrrBox5magcjestdaLzzElMCMGCSqGSIb3DQEJFTEWBBRP6Bd8dImu
+qXdmyjFxmxCmbAzFeDBBMDEwDQYJYIZIAWUDBAIBBQAEILLQEbZXqIcGRBK2kygs
+FO4EIxkPO4peFn71qezi/QIyBAjj72Xsr6j46gICCAA=`
+
+var p12DES = `MIIE0gIBAzCCBJgGCSqGSIb3DQEHAaCCBIkEggSFMIIEgTCCA3cGCSqGSIb3DQEH
+BqCCA2gwggNkAgEAMIIDXQYJKoZIhvcNAQcBMBwGCiqGSIb3DQEMAQMwDgQIMFcz
+lJ8A8IQCAggAgIIDMMlBvwbzsij9DzK01k8eeNkNG9QTD5z8fd41Plam88zCBgrS
+GwutAUTROT6MvhgjN1YO5C5xEj9TtqwPZerFfmkh0gPw76ppggEC3VzfQOJHxLqK
+H+hP+2ZqY6iRPiVMQZ1KOFKHN1FclLjXJtbU5hvBv3JRL+dEz1PI1R/MXTpILhtw
+uybv6MipL1nzXlfpWAczipkd4TU7FLaUp2XiBPWmkRXeqi38udJZznH2B64DcdYg
+ePxadrLLVVqrucqklZMf0h8wn1BwZCHRUC7AIGHRXJFcV3rvXSd58VtadT0c+nxK
+79jNxtgAfynXE36d8nUc3n9ScFGMIw6NixAnyCCp8INk1G2/I+0zlAJfHQd9xdbz
+ZIjkzas27EmfZGiodD7m+7YPYkZprBsgB6IQyR6zmNA5fjERm54eb5hm+0mSgkOn
+/5w6vqRSk9XgLQVVjaIQ83Qu7YBHU+LrjY3vsDUMhv/l55Fob1wej+UuILGWt1XX
+PlNW9GDIaRxmKQitBU5ROvQERpyW4aVOpGYQJHnGeRUIGfQU1+L9VYyqGkqnuukL
+mYhmWIq5Qxnz5Zlq2EU9aKQeVKoTFcdX83A0HuCkkIz66lYwhmzcFANCBnTRAWtz
+J/bzzm8NBVBw4EyDHVZokEkLQv+alVrkToj9Qnngd7z6ddP6/ojcqdw2qlf70HVo
+edrSRH3EqvEU0r+Bku1hNaRRdq5i0ZDt7guFwdZRtsJ9MaNEsHNmss/oIN1nI9+B
+j9lJu2DUnU4ma+q3tM32sI+6c2Dics8zIgpz2tQWk3FstuyaaiWCg3Zwbgx0TGQT
+fF9yAt7fcQ0fTgT5nVDyJ3dbcL0YOIQqNvX5jA6nUi2KAvPteHLIfo0Yvp9aRFr8
+m4xyhls5E99HQ59smpld7uc5D5zWcbWqGyjMlOsLaoeQH5wbYd9cwP91NXq1XJTA
+939T6PGaeZFl4c+uIynrXv6HMFbJW0ZP8NHNZX7Yf+3YXHyFQi9bcI7nSrVNKIip
+00m9ST7j7nCkcyW64kP1oWunDAVlA4HpOZCe2AmX5vpmPMY4XMEk5kWmTO/fW5vw
+JduOLf01UYaliwoajzCCAQIGCSqGSIb3DQEHAaCB9ASB8TCB7jCB6wYLKoZIhvcN
+AQwKAQKggbQwgbEwHAYKKoZIhvcNAQwBAzAOBAjrUq2xQtkkKAICCAAEgZCPCUfy
+v9bAqaRYCfD04ZKJz7Vxm6b9qYI+vD+6IDGQqG0cWOxv2tqXtpwgQB74sLXbrf7Y
+k7OF5KI+YFoEhSH8w5+u9Tkv5qm1TFU0pBbvzq15sCAbCzebAtjWSVpluJpJqh7A
+1P78MBbXaf/s5HKAeu2aulJp5bpV7oArLeY4UosDRc0nZHYLpEszUV9twfkxJTAj
+BgkqhkiG9w0BCRUxFgQUT+gXfHSJrql3ZsoxcZsQpmwMxXgwMTAhMAkGBSsOAwIa
+BQAEFEIxd1AbDJjxqy8zCXsbZqVIrRmVBAicfDkI8mmJlAICCAA=`
+
+func writeP12(t *testing.T, encoded string) string {
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(encoded, "\n", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p12Path := path.Join(t.TempDir(), "tls.p12")
+	write(p12Path, string(data), t)
+	return p12Path
+}
+
+func TestCreateSecretTLSFromP12(t *testing.T) {
+	for name, encoded := range map[string]string{"aes": p12AES, "3des": p12DES} {
+		t.Run(name, func(t *testing.T) {
+			streams, in, _, _ := genericiooptions.NewTestIOStreams()
+			in.WriteString("kubectl\n")
+			o := CreateSecretTLSOptions{
+				Name:           "foo",
+				P12:            writeP12(t, encoded),
+				PasswordStdin:  true,
+				IncludeCAChain: true,
+				IOStreams:      streams,
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			secret, err := o.createSecretTLS()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			certs, err := parseCertificates(secret.Data[corev1.TLSCertKey])
+			if err != nil || len(certs) != 2 || certs[0].Subject.CommonName != "example.com" || certs[1].Subject.CommonName != "test-ca" {
+				t.Errorf("expected the leaf certificate followed by its issuer, got %v: %v", certs, err)
+			}
+			ca, err := parseCertificates(secret.Data[corev1.ServiceAccountRootCAKey])
+			if err != nil || len(ca) != 1 || ca[0].Subject.CommonName != "test-ca" {
+				t.Errorf("expected the CA certificate in ca.crt, got %v: %v", ca, err)
+			}
+
+			in.WriteString("wrong\n")
+			if _, err := o.createSecretTLS(); err == nil || !strings.Contains(err.Error(), "password is incorrect") {
+				t.Errorf("expected an incorrect password error, got %v", err)
+			}
+		})
+	}
+
+	o := CreateSecretTLSOptions{Name: "foo", P12: "tls.p12", Cert: "tls.crt"}
+	if err := o.Validate(); err == nil {
+		t.Errorf("expected from-p12 and cert to be exclusive")
+	}
+}
+
+func TestValidateChain(t *testing.T) {
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(p12AES, "\n", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, certs, err := decodePKCS12(data, "kubectl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateChain(certs); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := validateChain([]*x509.Certificate{certs[1], certs[0]}); err == nil {
+		t.Errorf("expected a chain in the wrong order to be rejected")
+	}
+}
+
+func TestCreateSecretTLSUnorderedBundle(t *testing.T) {
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(p12AES, "\n", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, certs, err := decodePKCS12(data, "kubectl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the leaf certificate, its issuer and an extra certificate
+	keyPath, certPath := writeKeyPair(t.TempDir(),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+		string(encodeCertificates([]*x509.Certificate{certs[0], certs[1], certs[0]})), t)
+
+	streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+	o := CreateSecretTLSOptions{Name: "foo", Key: keyPath, Cert: certPath, DryRunStrategy: cmdutil.DryRunClient, IOStreams: streams}
+	o.PrintObj = func(obj runtime.Object) error { return nil }
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "is not signed by the next certificate") {
+		t.Errorf("expected a chain warning, got %q", errOut.String())
+	}
+
+	o.IncludeCAChain = true
+	if _, err := o.createSecretTLS(); err == nil || !strings.Contains(err.Error(), "is not signed by the next certificate") {
+		t.Errorf("expected the chain to be rejected with include-ca-chain, got %v", err)
+	}
+}
+
+func TestCertificateWarnings(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	cert := func(name string, notBefore, notAfter time.Time) *x509.Certificate {
+		return &x509.Certificate{Subject: pkix.Name{CommonName: name}, NotBefore: notBefore, NotAfter: notAfter}
+	}
+	warnings := certificateWarnings([]*x509.Certificate{
+		cert("valid", now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0)),
+		cert("expired", now.AddDate(-1, 0, 0), now.AddDate(0, 0, -1)),
+		cert("expiring", now.AddDate(-1, 0, 0), now.AddDate(0, 0, 10)),
+		cert("future", now.AddDate(0, 0, 1), now.AddDate(1, 0, 0)),
+	}, now)
+	expected := []string{
+		`certificate "CN=expired" expired on 2024-05-31T00:00:00Z`,
+		`certificate "CN=expiring" expires on 2024-06-11T00:00:00Z`,
+		`certificate "CN=future" is not valid before 2024-06-02T00:00:00Z`,
+	}
+	if strings.Join(warnings, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected warnings:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(warnings, "\n"))
+	}
+}
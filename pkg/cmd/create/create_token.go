@@ -19,7 +19,9 @@ package create
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -27,13 +29,18 @@ import (
 	"github.com/spf13/pflag"
 
 	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/completion"
@@ -68,15 +75,37 @@ type TokenOptions struct {
 	// Duration is the requested token lifetime. Optional.
 	Duration time.Duration
 
+	// KubeconfigOut is the file to write a kubeconfig using the token to. Optional.
+	KubeconfigOut string
+
+	// ShowPermissions prints the permissions of the service account when the
+	// token is printed to a terminal or written to a kubeconfig.
+	ShowPermissions bool
+
 	// CoreClient is the API client used to request the token. Required.
 	CoreClient corev1client.CoreV1Interface
 
+	// RESTConfig is the configuration of the client, used to write the
+	// kubeconfig and to impersonate the service account.
+	RESTConfig *rest.Config
+	// RawConfig is the kubeconfig the cluster is taken from.
+	RawConfig clientcmdapi.Config
+
 	// IOStreams are the output streams for the operation. Required.
 	genericiooptions.IOStreams
 }
 
 var (
-	tokenLong = templates.LongDesc(`Request a service account token.`)
+	tokenLong = templates.LongDesc(`
+		Request a service account token.
+
+		With --kubeconfig-out, a kubeconfig file using the token to connect to the
+		current cluster, in the namespace of the service account, is written instead of
+		printing the token.
+
+		When the token is printed to a terminal or written to a kubeconfig, what the
+		service account is allowed to do in its namespace is printed to the standard
+		error, as reported by the server to a user impersonating the service account.`)
 
 	tokenExample = templates.Examples(`
 		# Request a token to authenticate to the kube-apiserver as the service account "myapp" in the current namespace
@@ -88,8 +117,11 @@ var (
 		# Request a token with a custom expiration
 		kubectl create token myapp --duration 10m
 
-		# Request a token with a custom audience
-		kubectl create token myapp --audience https://example.com
+		# Request a token valid for several custom audiences
+		kubectl create token myapp --audience https://example.com --audience https://example.org
+
+		# Write a kubeconfig file authenticating as the service account "myapp"
+		kubectl create token myapp --duration 24h --kubeconfig-out myapp.kubeconfig
 
 		# Request a token bound to an instance of a Secret object
 		kubectl create token myapp --bound-object-kind Secret --bound-object-name mysecret
@@ -112,8 +144,9 @@ func boundObjectKindToAPIVersions() map[string]string {
 
 func NewTokenOpts(ioStreams genericiooptions.IOStreams) *TokenOptions {
 	return &TokenOptions{
-		PrintFlags: genericclioptions.NewPrintFlags("created").WithTypeSetter(scheme.Scheme),
-		IOStreams:  ioStreams,
+		PrintFlags:      genericclioptions.NewPrintFlags("created").WithTypeSetter(scheme.Scheme),
+		ShowPermissions: true,
+		IOStreams:       ioStreams,
 	}
 }
 
@@ -159,6 +192,8 @@ func NewCmdCreateToken(f cmdutil.Factory, ioStreams genericiooptions.IOStreams)
 	cmd.Flags().StringVar(&o.BoundObjectUID, "bound-object-uid", o.BoundObjectUID, "UID of an object to bind the token to. "+
 		"Requires --bound-object-kind and --bound-object-name. "+
 		"If unset, the UID of the existing object is used.")
+	cmd.Flags().StringVar(&o.KubeconfigOut, "kubeconfig-out", o.KubeconfigOut, "If set, write a kubeconfig file using the token to connect to the current cluster to this file, instead of printing the token.")
+	cmd.Flags().BoolVar(&o.ShowPermissions, "show-permissions", o.ShowPermissions, "If true, print the permissions of the service account in its namespace when the token is printed to a terminal or written to a kubeconfig.")
 
 	o.Flags = cmd.Flags()
 
@@ -185,6 +220,17 @@ func (o *TokenOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []st
 	}
 	o.CoreClient = client.CoreV1()
 
+	o.RESTConfig, err = f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if len(o.KubeconfigOut) > 0 {
+		o.RawConfig, err = f.ToRawKubeConfigLoader().RawConfig()
+		if err != nil {
+			return err
+		}
+	}
+
 	printer, err := o.PrintFlags.ToPrinter()
 	if err != nil {
 		return err
@@ -219,6 +265,9 @@ func (o *TokenOptions) Validate() error {
 			return fmt.Errorf("--audience must not be an empty string")
 		}
 	}
+	if len(o.KubeconfigOut) > 0 && o.PrintFlags.OutputFlagSpecified() {
+		return fmt.Errorf("--kubeconfig-out and --output are mutually exclusive")
+	}
 
 	if len(o.BoundObjectKind) == 0 {
 		if len(o.BoundObjectName) > 0 {
@@ -266,11 +315,21 @@ func (o *TokenOptions) Run() error {
 		return fmt.Errorf("failed to create token: no token in server response")
 	}
 
+	if len(o.KubeconfigOut) > 0 {
+		if err := o.writeKubeconfig(response.Status.Token); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "Kubeconfig written to %q.\n", o.KubeconfigOut)
+		o.printPermissions()
+		return nil
+	}
+
 	if o.PrintFlags.OutputFlagSpecified() {
 		return o.PrintObj(response)
 	}
 
 	if term.IsTerminal(o.Out) {
+		defer o.printPermissions()
 		// include a newline when printing interactively
 		fmt.Fprintf(o.Out, "%s\n", response.Status.Token)
 	} else {
@@ -280,3 +339,104 @@ func (o *TokenOptions) Run() error {
 
 	return nil
 }
+
+// writeKubeconfig writes a kubeconfig using the token to connect to the
+// cluster of the client.
+func (o *TokenOptions) writeKubeconfig(token string) error {
+	cluster := clientcmdapi.NewCluster()
+	cluster.Server = o.RESTConfig.Host
+	cluster.TLSServerName = o.RESTConfig.ServerName
+	cluster.InsecureSkipTLSVerify = o.RESTConfig.Insecure
+	cluster.CertificateAuthorityData = o.RESTConfig.CAData
+	if len(cluster.CertificateAuthorityData) == 0 && len(o.RESTConfig.CAFile) > 0 {
+		data, err := os.ReadFile(o.RESTConfig.CAFile)
+		if err != nil {
+			return err
+		}
+		cluster.CertificateAuthorityData = data
+	}
+
+	// the cluster keeps the name it has in the current kubeconfig
+	clusterName := ""
+	for name, c := range o.RawConfig.Clusters {
+		if c.Server == cluster.Server && (len(clusterName) == 0 || name < clusterName) {
+			clusterName = name
+		}
+	}
+	if len(clusterName) == 0 {
+		clusterName = cluster.Server
+		if u, err := url.Parse(cluster.Server); err == nil && len(u.Host) > 0 {
+			clusterName = u.Host
+		}
+	}
+
+	userName := serviceAccountUserName(o.Namespace, o.Name)
+	contextName := userName + "@" + clusterName
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = cluster
+	config.AuthInfos[userName] = &clientcmdapi.AuthInfo{Token: token}
+	config.Contexts[contextName] = &clientcmdapi.Context{Cluster: clusterName, AuthInfo: userName, Namespace: o.Namespace}
+	config.CurrentContext = contextName
+	return clientcmd.WriteToFile(*config, o.KubeconfigOut)
+}
+
+// printPermissions prints what the service account can do in its namespace,
+// as reported to a user impersonating it.
+func (o *TokenOptions) printPermissions() {
+	if !o.ShowPermissions || o.RESTConfig == nil {
+		return
+	}
+	config := rest.CopyConfig(o.RESTConfig)
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: serviceAccountUserName(o.Namespace, o.Name),
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:" + o.Namespace, "system:authenticated"},
+	}
+	client, err := authorizationv1client.NewForConfig(config)
+	if err != nil {
+		fmt.Fprintf(o.ErrOut, "The permissions of the service account could not be listed: %v\n", err)
+		return
+	}
+	review, err := client.SelfSubjectRulesReviews().Create(context.TODO(), &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: o.Namespace},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		fmt.Fprintf(o.ErrOut, "The permissions of the service account could not be listed: %v\n", err)
+		return
+	}
+
+	lines := []string{}
+	for _, rule := range review.Status.ResourceRules {
+		resources := []string{}
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				if len(group) > 0 {
+					resource += "." + group
+				}
+				resources = append(resources, resource)
+			}
+		}
+		line := strings.Join(rule.Verbs, ", ") + " " + strings.Join(resources, ", ")
+		if len(rule.ResourceNames) > 0 {
+			line += " named " + strings.Join(rule.ResourceNames, ", ")
+		}
+		lines = append(lines, line)
+	}
+	for _, rule := range review.Status.NonResourceRules {
+		lines = append(lines, strings.Join(rule.Verbs, ", ")+" "+strings.Join(rule.NonResourceURLs, ", "))
+	}
+	sort.Strings(lines)
+
+	fmt.Fprintf(o.ErrOut, "The service account %s/%s can, in the namespace %s:\n", o.Namespace, o.Name, o.Namespace)
+	for _, line := range lines {
+		fmt.Fprintf(o.ErrOut, "  %s\n", line)
+	}
+	if review.Status.Incomplete {
+		fmt.Fprintf(o.ErrOut, "The list may be incomplete: %s\n", review.Status.EvaluationError)
+	}
+}
+
+// serviceAccountUserName returns the name of the user authenticated with
+// the tokens of the service account.
+func serviceAccountUserName(namespace, name string) string {
+	return "system:serviceaccount:" + namespace + ":" + name
+}
@@ -19,9 +19,11 @@ package create
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -31,11 +33,14 @@ import (
 	kjson "sigs.k8s.io/json"
 
 	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/rest/fake"
+	"k8s.io/client-go/tools/clientcmd"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
@@ -371,3 +376,74 @@ status:
 		})
 	}
 }
+
+func TestCreateTokenKubeconfig(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	impersonated := ""
+	fakeClient := &fake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/api/v1/namespaces/test/serviceaccounts/mysa/token" && m == "POST":
+				response := &authenticationv1.TokenRequest{Status: authenticationv1.TokenRequestStatus{Token: "abc"}}
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, response)}, nil
+			case p == "/apis/authorization.k8s.io/v1/selfsubjectrulesreviews" && m == "POST":
+				impersonated = req.Header.Get("Impersonate-User")
+				review := &authorizationv1.SelfSubjectRulesReview{Status: authorizationv1.SubjectRulesReviewStatus{
+					ResourceRules: []authorizationv1.ResourceRule{
+						{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+						{Verbs: []string{"update"}, APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{"web"}},
+					},
+					NonResourceRules: []authorizationv1.NonResourceRule{
+						{Verbs: []string{"get"}, NonResourceURLs: []string{"/version"}},
+					},
+				}}
+				return &http.Response{StatusCode: http.StatusCreated, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, review)}, nil
+			default:
+				t.Fatalf("unexpected request: %s %s", m, p)
+				return nil, nil
+			}
+		}),
+	}
+	tf.Client = fakeClient
+	tf.ClientConfigVal = &rest.Config{Host: "https://example.com:6443", Transport: fakeClient.Client.Transport}
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	ioStreams, _, stdout, stderr := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdCreateToken(tf, ioStreams)
+	cmd.Flags().Set("kubeconfig-out", kubeconfig)
+	cmd.Run(cmd, []string{"mysa"})
+
+	if expected := fmt.Sprintf("Kubeconfig written to %q.\n", kubeconfig); stdout.String() != expected {
+		t.Errorf("unexpected stdout:\n%s", cmp.Diff(expected, stdout.String()))
+	}
+	expectedStderr := `The service account test/mysa can, in the namespace test:
+  get /version
+  get, list pods
+  update deployments.apps named web
+`
+	if stderr.String() != expectedStderr {
+		t.Errorf("unexpected stderr:\n%s", cmp.Diff(expectedStderr, stderr.String()))
+	}
+	if impersonated != "system:serviceaccount:test:mysa" {
+		t.Errorf("expected the service account to be impersonated, got %q", impersonated)
+	}
+
+	config, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	context := config.Contexts[config.CurrentContext]
+	if config.CurrentContext != "system:serviceaccount:test:mysa@example.com:6443" || context.Namespace != "test" {
+		t.Errorf("unexpected current context %q: %#v", config.CurrentContext, context)
+	}
+	if server := config.Clusters[context.Cluster].Server; server != "https://example.com:6443" {
+		t.Errorf("unexpected server %q", server)
+	}
+	if token := config.AuthInfos[context.AuthInfo].Token; token != "abc" {
+		t.Errorf("unexpected token %q", token)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	volumeSnapshotLong = templates.LongDesc(i18n.T(`
+		Create a CSI volume snapshot of a persistent volume claim.
+
+		The volume snapshot CRDs and the snapshot controller must be installed in the
+		cluster. The default volume snapshot class of the driver of the claim is used
+		unless --class is given.`))
+
+	volumeSnapshotExample = templates.Examples(i18n.T(`
+		# Create a volume snapshot named data-snap of the persistent volume claim data
+		kubectl create volumesnapshot data-snap --pvc=data
+
+		# Create a volume snapshot with the volume snapshot class csi-snapclass
+		kubectl create volumesnapshot data-snap --pvc=data --class=csi-snapclass`))
+
+	volumeSnapshotGVR = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+)
+
+// VolumeSnapshotOptions holds the options for 'create volumesnapshot' sub command
+type VolumeSnapshotOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	PrintObj   func(obj runtime.Object) error
+
+	Name  string
+	PVC   string
+	Class string
+
+	FieldManager     string
+	CreateAnnotation bool
+	Namespace        string
+	EnforceNamespace bool
+
+	Client              dynamic.Interface
+	DryRunStrategy      cmdutil.DryRunStrategy
+	ValidationDirective string
+
+	genericiooptions.IOStreams
+}
+
+// NewVolumeSnapshotOptions returns an initialized VolumeSnapshotOptions instance
+func NewVolumeSnapshotOptions(ioStreams genericiooptions.IOStreams) *VolumeSnapshotOptions {
+	return &VolumeSnapshotOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("created"),
+		IOStreams:  ioStreams,
+	}
+}
+
+// NewCmdCreateVolumeSnapshot is a macro command to create a new volume snapshot.
+func NewCmdCreateVolumeSnapshot(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	o := NewVolumeSnapshotOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "volumesnapshot NAME --pvc=CLAIM [--class=CLASS] [--dry-run=server|client|none]",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"vs"},
+		Short:                 i18n.T("Create a volume snapshot of a persistent volume claim"),
+		Long:                  volumeSnapshotLong,
+		Example:               volumeSnapshotExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+
+	cmdutil.AddApplyAnnotationFlags(cmd)
+	cmdutil.AddValidateFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().StringVar(&o.PVC, "pvc", o.PVC, i18n.T("The persistent volume claim to snapshot."))
+	cmd.Flags().StringVar(&o.Class, "class", o.Class, i18n.T("The volume snapshot class of the snapshot."))
+	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-create")
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *VolumeSnapshotOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Name, err = NameFromCommandArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	o.Client, err = f.DynamicClient()
+	if err != nil {
+		return err
+	}
+
+	o.CreateAnnotation = cmdutil.GetFlagBool(cmd, cmdutil.ApplyAnnotationsFlag)
+
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = func(obj runtime.Object) error {
+		return printer.PrintObj(obj, o.Out)
+	}
+
+	o.ValidationDirective, err = cmdutil.GetValidationDirective(cmd)
+	return err
+}
+
+// Validate makes sure provided values for VolumeSnapshotOptions are valid
+func (o *VolumeSnapshotOptions) Validate() error {
+	if len(o.PVC) == 0 {
+		return fmt.Errorf("--pvc must be specified")
+	}
+	return nil
+}
+
+// Run creates the volume snapshot, once the claim is found
+func (o *VolumeSnapshotOptions) Run() error {
+	snapshot := o.createVolumeSnapshot()
+
+	if err := util.CreateOrUpdateAnnotation(o.CreateAnnotation, snapshot, unstructured.UnstructuredJSONScheme); err != nil {
+		return err
+	}
+
+	if o.DryRunStrategy != cmdutil.DryRunClient {
+		claims := o.Client.Resource(corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims"))
+		if _, err := claims.Namespace(o.Namespace).Get(context.TODO(), o.PVC, metav1.GetOptions{}); err != nil {
+			return err
+		}
+
+		createOptions := metav1.CreateOptions{}
+		if o.FieldManager != "" {
+			createOptions.FieldManager = o.FieldManager
+		}
+		createOptions.FieldValidation = o.ValidationDirective
+		if o.DryRunStrategy == cmdutil.DryRunServer {
+			createOptions.DryRun = []string{metav1.DryRunAll}
+		}
+		var err error
+		snapshot, err = o.Client.Resource(volumeSnapshotGVR).Namespace(o.Namespace).Create(context.TODO(), snapshot, createOptions)
+		if err != nil {
+			return fmt.Errorf("failed to create volumesnapshot: %v", err)
+		}
+	}
+	return o.PrintObj(snapshot)
+}
+
+func (o *VolumeSnapshotOptions) createVolumeSnapshot() *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": o.PVC,
+		},
+	}
+	if len(o.Class) > 0 {
+		spec["volumeSnapshotClassName"] = o.Class
+	}
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVR.GroupVersion().WithKind("VolumeSnapshot"))
+	snapshot.SetName(o.Name)
+	if o.EnforceNamespace {
+		snapshot.SetNamespace(o.Namespace)
+	}
+	return snapshot
+}
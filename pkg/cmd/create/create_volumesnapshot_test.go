@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package create
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestCreateVolumeSnapshot(t *testing.T) {
+	tests := map[string]struct {
+		pvc         string
+		class       string
+		expectedErr string
+	}{
+		"snapshot": {
+			pvc: "data",
+		},
+		"snapshot with a class": {
+			pvc:   "data",
+			class: "csi-snapclass",
+		},
+		"missing claim": {
+			pvc:         "other",
+			expectedErr: `persistentvolumeclaims "other" not found`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			claim := &corev1.PersistentVolumeClaim{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "test"},
+			}
+			client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme.Scheme, map[schema.GroupVersionResource]string{
+				volumeSnapshotGVR: "VolumeSnapshotList",
+			}, claim)
+
+			ioStreams, _, buf, _ := genericiooptions.NewTestIOStreams()
+			printer, err := genericclioptions.NewPrintFlags("created").ToPrinter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			o := &VolumeSnapshotOptions{
+				Name:             "data-snap",
+				PVC:              tc.pvc,
+				Class:            tc.class,
+				Namespace:        "test",
+				EnforceNamespace: true,
+				Client:           client,
+				DryRunStrategy:   cmdutil.DryRunNone,
+				PrintObj:         func(obj runtime.Object) error { return printer.PrintObj(obj, ioStreams.Out) },
+				IOStreams:        ioStreams,
+			}
+			err = o.Run()
+			if len(tc.expectedErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expected := "volumesnapshot.snapshot.storage.k8s.io/data-snap created\n"; buf.String() != expected {
+				t.Errorf("expected output %q, got %q", expected, buf.String())
+			}
+
+			snapshot, err := client.Resource(volumeSnapshotGVR).Namespace("test").Get(context.TODO(), "data-snap", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedSpec := map[string]interface{}{"source": map[string]interface{}{"persistentVolumeClaimName": "data"}}
+			if len(tc.class) > 0 {
+				expectedSpec["volumeSnapshotClassName"] = tc.class
+			}
+			if spec := snapshot.Object["spec"]; !reflect.DeepEqual(spec, expectedSpec) {
+				t.Errorf("expected spec %v, got %v", expectedSpec, spec)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/exec"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/utils/pointer"
+)
+
+// captureIdleCommand keeps the capture container running until the capture
+// is executed in it. Unbounded captures are limited to a day.
+func captureIdleCommand(d time.Duration) []string {
+	seconds := 24 * 60 * 60
+	if d > 0 {
+		// leave some time for the image to be pulled and the container to start
+		seconds = int(d.Seconds()) + 5*60
+	}
+	return []string{"sleep", strconv.Itoa(seconds)}
+}
+
+// captureCommand returns the command that runs tcpdump on every interface
+// and writes the pcap to stdout. When d is set, tcpdump is interrupted after
+// d so that it flushes its output and exits cleanly. The filter is passed as
+// a positional parameter rather than interpolated into the script.
+func captureCommand(filter string, d time.Duration) []string {
+	if d <= 0 {
+		return []string{"tcpdump", "-i", "any", "-U", "-w", "-", filter}
+	}
+	script := `tcpdump -i any -U -w - "$1" & pid=$!; sleep ` + strconv.Itoa(int(d.Seconds())) + `; kill -INT $pid; wait $pid`
+	return []string{"sh", "-c", script, "tcpdump", filter}
+}
+
+// generateCaptureNodePod returns a node debugging pod able to capture the
+// node's network traffic, regardless of the selected profile.
+func (o *DebugOptions) generateCaptureNodePod(node *corev1.Node) (*corev1.Pod, error) {
+	// the pcap may be written to stdout, keep it free of informational messages
+	opts := *o
+	opts.Out = o.ErrOut
+	opts.Args = captureIdleCommand(o.CaptureDuration)
+	opts.ArgsOnly = false
+	p, err := opts.generateNodeDebugPod(node)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Spec.HostNetwork = true
+	c := &p.Spec.Containers[0]
+	if c.SecurityContext == nil {
+		c.SecurityContext = &corev1.SecurityContext{}
+	}
+	c.SecurityContext.Privileged = pointer.Bool(true)
+	c.SecurityContext.AllowPrivilegeEscalation = nil
+	c.SecurityContext.RunAsNonRoot = nil
+	return p, nil
+}
+
+// captureNode runs a packet capture in the network namespace of node and
+// writes the pcap to CaptureOutput. The capture pod is removed afterwards.
+func (o *DebugOptions) captureNode(ctx context.Context, node *corev1.Node) error {
+	debugPod, err := o.generateCaptureNodePod(node)
+	if err != nil {
+		return err
+	}
+	pods := o.podClient.Pods(o.Namespace)
+	pod, err := pods.Create(ctx, debugPod, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	containerName := pod.Spec.Containers[0].Name
+
+	cleanup := func() {
+		klog.V(2).Infof("deleting capture pod %s/%s", pod.Namespace, pod.Name)
+		if err := pods.Delete(context.Background(), pod.Name, metav1.DeleteOptions{GracePeriodSeconds: pointer.Int64(0)}); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: unable to delete capture pod %s: %v\n", pod.Name, err)
+		}
+	}
+
+	// waitForContainer handles interrupts on its own, the handler makes sure
+	// the pod is removed however the capture ends.
+	handler := interrupt.New(nil, cleanup)
+	defer handler.Close()
+
+	running, err := o.waitForContainer(ctx, pod.Namespace, pod.Name, containerName)
+	if err != nil {
+		return err
+	}
+	if status := getContainerStatusByName(running, containerName); status == nil || status.State.Running == nil {
+		return fmt.Errorf("capture container %s in pod %s is not running", containerName, pod.Name)
+	}
+
+	return handler.Run(func() error {
+		var out io.Writer = o.Out
+		if o.CaptureOutput != "-" {
+			file, err := os.Create(o.CaptureOutput)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+		counter := &countingWriter{w: out}
+
+		if !o.Quiet {
+			limit := "until interrupted"
+			if o.CaptureDuration > 0 {
+				limit = "for " + o.CaptureDuration.String()
+			}
+			fmt.Fprintf(o.ErrOut, "Capturing packets matching %q on node %s %s.\n", o.Capture, node.Name, limit)
+		}
+		errOut := o.ErrOut
+		if o.Quiet {
+			errOut = io.Discard
+		}
+		options := &exec.ExecOptions{
+			StreamOptions: exec.StreamOptions{
+				IOStreams: genericiooptions.IOStreams{
+					Out:    counter,
+					ErrOut: errOut,
+				},
+				Namespace:     pod.Namespace,
+				PodName:       pod.Name,
+				ContainerName: containerName,
+				Quiet:         true,
+			},
+			Command:   captureCommand(o.Capture, o.CaptureDuration),
+			Executor:  o.captureExecutor,
+			Config:    o.clientConfig,
+			PodClient: o.podClient,
+		}
+		if options.Executor == nil {
+			options.Executor = &exec.DefaultRemoteExecutor{}
+		}
+		if err := options.Validate(); err != nil {
+			return err
+		}
+		if err := options.Run(); err != nil {
+			return fmt.Errorf("packet capture failed, does image %s provide tcpdump? %v", o.Image, err)
+		}
+
+		if !o.Quiet && o.CaptureOutput != "-" {
+			fmt.Fprintf(o.ErrOut, "Wrote %d bytes to %s.\n", counter.n, o.CaptureOutput)
+		}
+		return nil
+	})
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
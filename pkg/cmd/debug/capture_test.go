@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes/fake"
+	restclient "k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestCaptureCommand(t *testing.T) {
+	if diff := cmp.Diff([]string{"tcpdump", "-i", "any", "-U", "-w", "-", "port 443"}, captureCommand("port 443", 0)); diff != "" {
+		t.Errorf("unexpected unbounded command (-want +got):\n%s", diff)
+	}
+
+	got := captureCommand("host 10.0.0.1 and port 53", time.Minute)
+	want := []string{"sh", "-c", `tcpdump -i any -U -w - "$1" & pid=$!; sleep 60; kill -INT $pid; wait $pid`, "tcpdump", "host 10.0.0.1 and port 53"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected bounded command (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateCaptureNodePod(t *testing.T) {
+	defer func(old func(int) string) { nameSuffixFunc = old }(nameSuffixFunc)
+	nameSuffixFunc = func(int) string { return "capt" }
+
+	for _, profile := range []string{ProfileLegacy, ProfileRestricted} {
+		t.Run(profile, func(t *testing.T) {
+			applier, err := NewProfileApplier(profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			streams, _, out, errOut := genericiooptions.NewTestIOStreams()
+			opts := &DebugOptions{
+				Image:           "nicolaka/netshoot",
+				Capture:         "port 443",
+				CaptureDuration: time.Minute,
+				CaptureOutput:   "-",
+				Applier:         applier,
+				IOStreams:       streams,
+			}
+			pod, err := opts.generateCaptureNodePod(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if out.Len() != 0 {
+				t.Errorf("expected no output on stdout, got %q", out.String())
+			}
+			if !strings.Contains(errOut.String(), "node-debugger-node-1-capt") {
+				t.Errorf("expected the pod name to be reported on stderr, got %q", errOut.String())
+			}
+			if !pod.Spec.HostNetwork {
+				t.Error("expected the capture pod to use the host network")
+			}
+			c := pod.Spec.Containers[0]
+			if c.SecurityContext == nil || c.SecurityContext.Privileged == nil || !*c.SecurityContext.Privileged {
+				t.Errorf("expected a privileged container, got %#v", c.SecurityContext)
+			}
+			if diff := cmp.Diff([]string{"sleep", "360"}, c.Command); diff != "" {
+				t.Errorf("unexpected container command (-want +got):\n%s", diff)
+			}
+			if c.Stdin || c.TTY {
+				t.Error("expected the capture container to run without stdin or tty")
+			}
+		})
+	}
+}
+
+func TestCaptureValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    DebugOptions
+		wantErr string
+	}{
+		{
+			name: "valid",
+			opts: DebugOptions{Capture: "port 443", CaptureOutput: "out.pcap", CaptureDuration: time.Minute},
+		},
+		{
+			name:    "missing output",
+			opts:    DebugOptions{Capture: "port 443"},
+			wantErr: "--output is required",
+		},
+		{
+			name:    "interactive",
+			opts:    DebugOptions{Capture: "port 443", CaptureOutput: "-", Interactive: true, Attach: true},
+			wantErr: "incompatible with -i/--stdin",
+		},
+		{
+			name:    "arguments",
+			opts:    DebugOptions{Capture: "port 443", CaptureOutput: "-", Args: []string{"sh"}},
+			wantErr: "does not accept command arguments",
+		},
+		{
+			name:    "negative duration",
+			opts:    DebugOptions{Capture: "port 443", CaptureOutput: "-", CaptureDuration: -time.Second},
+			wantErr: "must not be negative",
+		},
+		{
+			name:    "output without capture",
+			opts:    DebugOptions{CaptureOutput: "out.pcap"},
+			wantErr: "may only be used with --capture",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			streams := genericiooptions.NewTestIOStreamsDiscard()
+			tc.opts.IOStreams = streams
+			tc.opts.Image = "nicolaka/netshoot"
+			tc.opts.TargetNames = []string{"node/node-1"}
+			tc.opts.WarningPrinter = printers.NewWarningPrinter(streams.ErrOut, printers.WarningPrinterOptions{})
+			err := tc.opts.Validate()
+			if len(tc.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+type fakeCaptureExecutor struct {
+	command []string
+	data    string
+}
+
+func (f *fakeCaptureExecutor) Execute(url *url.URL, config *restclient.Config, stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue remotecommand.TerminalSizeQueue) error {
+	f.command = url.Query()["command"]
+	_, err := io.WriteString(stdout, f.data)
+	return err
+}
+
+func TestCaptureNode(t *testing.T) {
+	defer func(old func(int) string) { nameSuffixFunc = old }(nameSuffixFunc)
+	nameSuffixFunc = func(int) string { return "capt" }
+
+	client := fake.NewSimpleClientset()
+	// the fake client doesn't run pods, report the container as running
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		pod.Namespace = "test"
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  pod.Spec.Containers[0].Name,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}}
+		return false, nil, nil
+	})
+
+	output := filepath.Join(t.TempDir(), "capture.pcap")
+	executor := &fakeCaptureExecutor{data: "pcap-data"}
+	streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+	opts := &DebugOptions{
+		Image:           "nicolaka/netshoot",
+		Namespace:       "test",
+		Capture:         "port 443",
+		CaptureDuration: 10 * time.Second,
+		CaptureOutput:   output,
+		Applier:         &legacyProfile{},
+		IOStreams:       streams,
+		WarningPrinter:  printers.NewWarningPrinter(streams.ErrOut, printers.WarningPrinterOptions{}),
+		podClient:       client.CoreV1(),
+		captureExecutor: executor,
+		clientConfig: &restclient.Config{
+			ContentConfig: restclient.ContentConfig{
+				GroupVersion:         &corev1.SchemeGroupVersion,
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+			},
+		},
+	}
+
+	if err := opts.captureNode(context.Background(), &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, errOut.String())
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "pcap-data" {
+		t.Errorf("unexpected capture %q", string(data))
+	}
+	if diff := cmp.Diff(captureCommand("port 443", 10*time.Second), executor.command); diff != "" {
+		t.Errorf("unexpected capture command (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(errOut.String(), "Wrote 9 bytes to "+output) {
+		t.Errorf("expected a summary, got %q", errOut.String())
+	}
+
+	pods, err := client.CoreV1().Pods("test").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 0 {
+		t.Errorf("expected the capture pod to be deleted, found %d pods", len(pods.Items))
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/kubectl/pkg/util/podutils"
+	"sigs.k8s.io/yaml"
+)
+
+// CustomProfiles is the content of a debug profiles file.
+type CustomProfiles struct {
+	Profiles []CustomProfile `json:"profiles"`
+}
+
+// CustomProfile is a team defined debugging profile. It is applied on top
+// of one of the built-in profiles.
+type CustomProfile struct {
+	// Name is the value passed to --custom-profile.
+	Name string `json:"name"`
+	// BaseProfile is the built-in profile applied before this one. It is
+	// used when --profile is not given explicitly.
+	BaseProfile string `json:"baseProfile,omitempty"`
+	// Image is used for the debug container when --image is not given.
+	Image string `json:"image,omitempty"`
+	// ImagePullPolicy is used when --image-pull-policy is not given.
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// SecurityContext replaces the security context of the debug container.
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+	// Env is added to the environment of the debug container, unless a
+	// variable of the same name is given with --env.
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// VolumeMounts are added to the debug container.
+	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// Volumes are added to the pod. They cannot be added to an existing pod,
+	// so they are only supported with --copy-to and when debugging nodes.
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+}
+
+// DefaultCustomProfilesFile returns the default location of the debug
+// profiles file, next to the default kubeconfig.
+func DefaultCustomProfilesFile() string {
+	return filepath.Join(homedir.HomeDir(), ".kube", "debug-profiles.yaml")
+}
+
+// LoadCustomProfile reads the profiles file at path and returns the profile
+// with the given name.
+func LoadCustomProfile(path, name string) (*CustomProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read debug profiles: %v", err)
+	}
+	profiles := &CustomProfiles{}
+	if err := yaml.UnmarshalStrict(data, profiles); err != nil {
+		return nil, fmt.Errorf("unable to parse debug profiles in %s: %v", path, err)
+	}
+	for i := range profiles.Profiles {
+		if profiles.Profiles[i].Name == name {
+			return &profiles.Profiles[i], nil
+		}
+	}
+	return nil, fmt.Errorf("debug profile %q not found in %s", name, path)
+}
+
+// customProfileApplier applies a custom profile after its base profile.
+type customProfileApplier struct {
+	base    ProfileApplier
+	profile *CustomProfile
+}
+
+// NewCustomProfileApplier returns a ProfileApplier that applies profile on top of base.
+func NewCustomProfileApplier(base ProfileApplier, profile *CustomProfile) ProfileApplier {
+	return &customProfileApplier{base: base, profile: profile}
+}
+
+func (p *customProfileApplier) Apply(pod *corev1.Pod, containerName string, target runtime.Object) error {
+	if err := p.base.Apply(pod, containerName, target); err != nil {
+		return err
+	}
+
+	style, err := getDebugStyle(pod, target)
+	if err != nil {
+		return fmt.Errorf("custom profile %s: %s", p.profile.Name, err)
+	}
+	if len(p.profile.Volumes) > 0 {
+		if style == ephemeral {
+			return fmt.Errorf("custom profile %s: volumes can't be added to a running pod, use --copy-to", p.profile.Name)
+		}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, p.profile.Volumes...)
+	}
+
+	podutils.VisitContainers(&pod.Spec, podutils.AllContainers, func(c *corev1.Container, _ podutils.ContainerType) bool {
+		if c.Name != containerName {
+			return true
+		}
+		if p.profile.SecurityContext != nil {
+			c.SecurityContext = p.profile.SecurityContext.DeepCopy()
+		}
+		for _, env := range p.profile.Env {
+			c.Env = addEnvVar(c.Env, env)
+		}
+		c.VolumeMounts = append(c.VolumeMounts, p.profile.VolumeMounts...)
+		return false
+	})
+	return nil
+}
+
+// addEnvVar appends env to vars unless a variable with its name is already set.
+func addEnvVar(vars []corev1.EnvVar, env corev1.EnvVar) []corev1.EnvVar {
+	for i := range vars {
+		if vars[i].Name == env.Name {
+			return vars
+		}
+	}
+	return append(vars, env)
+}
@@ -0,0 +1,263 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debug
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/utils/pointer"
+)
+
+const testCustomProfiles = `profiles:
+- name: netshoot
+  baseProfile: netadmin
+  image: nicolaka/netshoot
+  imagePullPolicy: Always
+  env:
+  - name: HISTFILE
+    value: /dev/null
+  - name: DEBUG
+    value: "false"
+- name: dumps
+  image: busybox
+  securityContext:
+    runAsUser: 1000
+  volumeMounts:
+  - name: dumps
+    mountPath: /dumps
+  volumes:
+  - name: dumps
+    emptyDir: {}
+`
+
+func writeTestCustomProfiles(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "debug-profiles.yaml")
+	if err := os.WriteFile(path, []byte(testCustomProfiles), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCustomProfile(t *testing.T) {
+	path := writeTestCustomProfiles(t)
+
+	profile, err := LoadCustomProfile(path, "netshoot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.BaseProfile != ProfileNetadmin || profile.Image != "nicolaka/netshoot" || len(profile.Env) != 2 {
+		t.Errorf("unexpected profile: %#v", profile)
+	}
+
+	if _, err := LoadCustomProfile(path, "missing"); err == nil || !strings.Contains(err.Error(), `debug profile "missing" not found`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("profiles:\n- name: x\n  imag: typo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCustomProfile(invalid, "x"); err == nil {
+		t.Errorf("expected unknown fields to be rejected")
+	}
+}
+
+func TestCustomProfileApplier(t *testing.T) {
+	path := writeTestCustomProfiles(t)
+	netshoot, err := LoadCustomProfile(path, "netshoot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dumps, err := LoadCustomProfile(path, "dumps")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	podWithEphemeral := func() *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+			Spec: corev1.PodSpec{EphemeralContainers: []corev1.EphemeralContainer{
+				{
+					EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+						Name: "dbg",
+						Env:  []corev1.EnvVar{{Name: "DEBUG", Value: "true"}},
+					},
+				},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		profile     *CustomProfile
+		pod         *corev1.Pod
+		target      func(pod *corev1.Pod) runtime.Object
+		expectPod   *corev1.Pod
+		expectedErr bool
+	}{
+		{
+			name:    "ephemeral with base profile and env",
+			profile: netshoot,
+			pod:     podWithEphemeral(),
+			target:  func(pod *corev1.Pod) runtime.Object { return pod },
+			expectPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+				Spec: corev1.PodSpec{EphemeralContainers: []corev1.EphemeralContainer{
+					{
+						EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+							Name: "dbg",
+							Env: []corev1.EnvVar{
+								{Name: "DEBUG", Value: "true"},
+								{Name: "HISTFILE", Value: "/dev/null"},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								Capabilities: &corev1.Capabilities{
+									Add: []corev1.Capability{"NET_ADMIN", "NET_RAW"},
+								},
+							},
+						},
+					},
+				}},
+			},
+		},
+		{
+			name:        "volumes are not supported for ephemeral containers",
+			profile:     dumps,
+			pod:         podWithEphemeral(),
+			target:      func(pod *corev1.Pod) runtime.Object { return pod },
+			expectedErr: true,
+		},
+		{
+			name:    "node with security context and volumes",
+			profile: dumps,
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "dbg"}}},
+			},
+			target: func(*corev1.Pod) runtime.Object { return testNode },
+			expectPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod"},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "dbg",
+							SecurityContext: &corev1.SecurityContext{RunAsUser: pointer.Int64(1000)},
+							VolumeMounts:    []corev1.VolumeMount{{Name: "dumps", MountPath: "/dumps"}},
+						},
+					},
+					Volumes: []corev1.Volume{{Name: "dumps", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base, err := NewProfileApplier(ProfileBaseline)
+			if test.profile.BaseProfile != "" {
+				base, err = NewProfileApplier(test.profile.BaseProfile)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			applier := NewCustomProfileApplier(base, test.profile)
+			err = applier.Apply(test.pod, "dbg", test.target(test.pod))
+			if test.expectedErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.expectPod, test.pod); diff != "" {
+				t.Error("unexpected diff in generated object: (-want +got):\n", diff)
+			}
+		})
+	}
+}
+
+func TestCompleteCustomProfile(t *testing.T) {
+	path := writeTestCustomProfiles(t)
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectImage   string
+		expectProfile string
+		expectPolicy  corev1.PullPolicy
+	}{
+		{
+			name:          "defaults from the profile",
+			args:          []string{"--custom-profile=netshoot", "mypod"},
+			expectImage:   "nicolaka/netshoot",
+			expectProfile: ProfileNetadmin,
+			expectPolicy:  corev1.PullAlways,
+		},
+		{
+			name:          "flags take precedence",
+			args:          []string{"--custom-profile=netshoot", "--image=busybox", "--profile=general", "--image-pull-policy=Never", "mypod"},
+			expectImage:   "busybox",
+			expectProfile: ProfileGeneral,
+			expectPolicy:  corev1.PullNever,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := NewDebugOptions(genericiooptions.NewTestIOStreamsDiscard())
+			var gotError error
+			cmd := &cobra.Command{
+				Run: func(cmd *cobra.Command, args []string) {
+					opts.CustomProfileFile = path
+					if gotError = opts.Complete(tf, cmd, args); gotError != nil {
+						return
+					}
+					gotError = opts.Validate()
+				},
+			}
+			cmd.SetArgs(test.args)
+			opts.AddFlags(cmd)
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			if gotError != nil {
+				t.Fatal(gotError)
+			}
+			if opts.Image != test.expectImage || opts.Profile != test.expectProfile || opts.PullPolicy != test.expectPolicy {
+				t.Errorf("expected image=%s profile=%s policy=%s, got image=%s profile=%s policy=%s",
+					test.expectImage, test.expectProfile, test.expectPolicy, opts.Image, opts.Profile, opts.PullPolicy)
+			}
+			if _, ok := opts.Applier.(*customProfileApplier); !ok {
+				t.Errorf("expected a custom profile applier, got %T", opts.Applier)
+			}
+		})
+	}
+}
@@ -43,6 +43,7 @@ import (
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/kubernetes"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	watchtools "k8s.io/client-go/tools/watch"
 	"k8s.io/kubectl/pkg/cmd/attach"
@@ -73,6 +74,19 @@ var (
 		            debugging utilities without restarting the pod.
 		* Node: Create a new pod that runs in the node's host namespaces and can access
 		        the node's filesystem.
+
+		Teams can standardize their debugging containers with custom profiles defined in
+		~/.kube/debug-profiles.yaml and selected with --custom-profile. A custom profile
+		may set the image, image pull policy, security context, environment, volume mounts
+		and volumes of the debug container and is applied on top of a built-in profile:
+
+		    profiles:
+		    - name: netshoot
+		      baseProfile: netadmin
+		      image: nicolaka/netshoot
+		      env:
+		      - name: HISTFILE
+		        value: /dev/null
 `))
 
 	debugExample = templates.Examples(i18n.T(`
@@ -101,6 +115,13 @@ var (
 		# Create an interactive debugging session on a node and immediately attach to it.
 		# The container will run in the host namespaces and the host's filesystem will be mounted at /host
 		kubectl debug node/mynode -it --image=busybox
+
+		# Capture the HTTPS traffic of a node for one minute and write it to capture.pcap
+		kubectl debug node/mynode --image=nicolaka/netshoot --capture='port 443' --duration=60s --output=capture.pcap
+
+		# Create an interactive debugging session in pod mypod using the "netshoot" profile
+		# defined in ~/.kube/debug-profiles.yaml
+		kubectl debug mypod -it --custom-profile=netshoot
 `))
 )
 
@@ -130,11 +151,20 @@ type DebugOptions struct {
 	Profile         string
 	Applier         ProfileApplier
 
+	CustomProfile     string
+	CustomProfileFile string
+
+	Capture         string
+	CaptureDuration time.Duration
+	CaptureOutput   string
+
 	explicitNamespace     bool
 	attachChanged         bool
 	shareProcessedChanged bool
 
-	podClient corev1client.CoreV1Interface
+	podClient       corev1client.CoreV1Interface
+	clientConfig    *restclient.Config
+	captureExecutor exec.RemoteExecutor
 
 	Builder *resource.Builder
 	genericiooptions.IOStreams
@@ -193,6 +223,11 @@ func (o *DebugOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&o.TargetContainer, "target", "", i18n.T("When using an ephemeral container, target processes in this container name."))
 	cmd.Flags().BoolVarP(&o.TTY, "tty", "t", o.TTY, i18n.T("Allocate a TTY for the debugging container."))
 	cmd.Flags().StringVar(&o.Profile, "profile", ProfileLegacy, i18n.T(`Debugging profile. Options are "legacy", "general", "baseline", "netadmin", or "restricted".`))
+	cmd.Flags().StringVar(&o.CustomProfile, "custom-profile", o.CustomProfile, i18n.T("Name of a custom debugging profile to apply on top of --profile, or on top of the profile's own baseProfile if --profile is not given."))
+	cmd.Flags().StringVar(&o.Capture, "capture", o.Capture, i18n.T("When debugging a node, capture the node's network traffic matching this tcpdump filter expression instead of starting a session. The image must provide tcpdump."))
+	cmd.Flags().DurationVar(&o.CaptureDuration, "duration", o.CaptureDuration, i18n.T("When used with '--capture', stop capturing after this duration. Zero means capture until interrupted."))
+	cmd.Flags().StringVar(&o.CaptureOutput, "output", o.CaptureOutput, i18n.T("When used with '--capture', the file the pcap is written to, or '-' for stdout."))
+	cmd.Flags().StringVar(&o.CustomProfileFile, "custom-profile-file", o.CustomProfileFile, i18n.T("Path to the file defining custom debugging profiles. Defaults to ~/.kube/debug-profiles.yaml."))
 }
 
 // Complete finishes run-time initialization of debug.DebugOptions.
@@ -248,6 +283,12 @@ func (o *DebugOptions) Complete(restClientGetter genericclioptions.RESTClientGet
 		o.WarningPrinter = printers.NewWarningPrinter(o.ErrOut, printers.WarningPrinterOptions{Color: term.AllowsColorOutput(o.ErrOut)})
 	}
 
+	if len(o.CustomProfile) > 0 {
+		if err := o.completeCustomProfile(cmd); err != nil {
+			return err
+		}
+	}
+
 	if o.Applier == nil {
 		applier, err := NewProfileApplier(o.Profile)
 		if err != nil {
@@ -267,12 +308,45 @@ func (o *DebugOptions) Complete(restClientGetter genericclioptions.RESTClientGet
 	}
 
 	o.podClient = client.CoreV1()
+	o.clientConfig = clientConfig
 
 	o.Builder = resource.NewBuilder(restClientGetter)
 
 	return nil
 }
 
+// completeCustomProfile loads the custom profile and applies its defaults
+// to the options the user did not set explicitly.
+func (o *DebugOptions) completeCustomProfile(cmd *cobra.Command) error {
+	file := o.CustomProfileFile
+	if len(file) == 0 {
+		file = DefaultCustomProfilesFile()
+	}
+	profile, err := LoadCustomProfile(file, o.CustomProfile)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Flags().Changed("profile") && len(profile.BaseProfile) > 0 {
+		o.Profile = profile.BaseProfile
+	}
+	if len(o.Image) == 0 {
+		o.Image = profile.Image
+	}
+	if len(o.PullPolicy) == 0 {
+		o.PullPolicy = profile.ImagePullPolicy
+	}
+
+	if o.Applier == nil {
+		base, err := NewProfileApplier(o.Profile)
+		if err != nil {
+			return err
+		}
+		o.Applier = NewCustomProfileApplier(base, profile)
+	}
+	return nil
+}
+
 // Validate checks that the provided debug options are specified.
 func (o *DebugOptions) Validate() error {
 	// Attach
@@ -343,6 +417,24 @@ func (o *DebugOptions) Validate() error {
 		return fmt.Errorf("-i/--stdin is required for containers with -t/--tty=true")
 	}
 
+	// Capture
+	if len(o.Capture) > 0 {
+		switch {
+		case len(o.CopyTo) > 0:
+			return fmt.Errorf("--capture is incompatible with --copy-to.")
+		case o.Interactive || o.Attach:
+			return fmt.Errorf("--capture is incompatible with -i/--stdin and --attach.")
+		case len(o.Args) > 0:
+			return fmt.Errorf("--capture does not accept command arguments.")
+		case len(o.CaptureOutput) == 0:
+			return fmt.Errorf("--output is required with --capture.")
+		case o.CaptureDuration < 0:
+			return fmt.Errorf("--duration must not be negative.")
+		}
+	} else if o.CaptureDuration != 0 || len(o.CaptureOutput) > 0 {
+		return fmt.Errorf("--duration and --output may only be used with --capture.")
+	}
+
 	// WarningPrinter
 	if o.WarningPrinter == nil {
 		return fmt.Errorf("WarningPrinter can not be used without initialization")
@@ -402,6 +494,9 @@ func (o *DebugOptions) Run(restClientGetter genericclioptions.RESTClientGetter,
 // visitNode handles debugging for node targets by creating a privileged pod running in the host namespaces.
 // Returns an already created pod and container name for subsequent attach, if applicable.
 func (o *DebugOptions) visitNode(ctx context.Context, node *corev1.Node) (*corev1.Pod, string, error) {
+	if len(o.Capture) > 0 {
+		return nil, "", o.captureNode(ctx, node)
+	}
 	pods := o.podClient.Pods(o.Namespace)
 	debugPod, err := o.generateNodeDebugPod(node)
 	if err != nil {
@@ -421,6 +516,9 @@ func (o *DebugOptions) visitNode(ctx context.Context, node *corev1.Node) (*corev
 //
 // visitPod returns a pod and debug container name for subsequent attach, if applicable.
 func (o *DebugOptions) visitPod(ctx context.Context, pod *corev1.Pod) (*corev1.Pod, string, error) {
+	if len(o.Capture) > 0 {
+		return nil, "", fmt.Errorf("--capture is only supported when debugging nodes")
+	}
 	if len(o.CopyTo) > 0 {
 		return o.debugByCopy(ctx, pod)
 	}
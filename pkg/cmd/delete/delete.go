@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
@@ -39,6 +40,8 @@ import (
 	"k8s.io/kubectl/pkg/rawhttp"
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/manifestsource"
+	"k8s.io/kubectl/pkg/util/policy"
 	"k8s.io/kubectl/pkg/util/templates"
 	"k8s.io/kubectl/pkg/util/term"
 )
@@ -75,7 +78,24 @@ var (
 
 		After a CustomResourceDefinition is deleted, invalidation of discovery cache may take up
 		to 6 hours. If you don't want to wait, you might want to run "kubectl api-resources" to refresh
-		the discovery cache.`))
+		the discovery cache.
+
+		With --trash, a snapshot of each resource is saved to ~/.kube/trash/CLUSTER/NAMESPACE/ before it
+		is deleted. Deleted resources can then be brought back with "kubectl restore".
+
+		With --ordered, dependents are deleted before their owners, and namespaced objects and custom
+		resources before the namespaces and CustomResourceDefinitions they belong to. Custom resources are
+		deleted first and are waited for, so that the controllers handling their finalizers are still
+		running, then the other objects, the CustomResourceDefinitions and the namespaces, each once the
+		previous ones are gone. With --wait-dependents, deletion is done with --cascade=foreground and the
+		command only returns once the garbage collector removed all the dependents.
+
+		With --escalate-after, the resources still there after the given duration, often because of a
+		finalizer whose controller is gone, are reported with the finalizers blocking their deletion.
+		With --remove-finalizers, the command then asks whether to remove these finalizers.
+
+		With --policy-dir, the resources are checked against the policies of the directory before
+		any of them is deleted. See "kubectl apply --help" for the format of the policies.`))
 
 	deleteExample = templates.Examples(i18n.T(`
 		# Delete a pod using the type and name specified in pod.json
@@ -103,7 +123,19 @@ var (
 		kubectl delete pod foo --force
 
 		# Delete all pods
-		kubectl delete pods --all`))
+		kubectl delete pods --all
+
+		# Delete a deployment, keeping a snapshot to restore it from
+		kubectl delete deployment nginx --trash
+
+		# Delete the resources of a directory in dependency order and wait for their dependents to be gone
+		kubectl delete -f dir/ --ordered --wait-dependents
+
+		# Pick the pod to delete from the pods with label name=myLabel
+		kubectl delete pods -l name=myLabel --pick
+
+		# Delete a namespace, offering to remove the finalizers blocking it after a minute
+		kubectl delete namespace dev --escalate-after=60s --remove-finalizers`))
 )
 
 type DeleteOptions struct {
@@ -122,6 +154,27 @@ type DeleteOptions struct {
 	WarnClusterScope    bool
 	Raw                 string
 	Interactive         bool
+	Trash               bool
+	// TrashDir is where snapshots are saved with Trash, it defaults to
+	// the trash directory of the current cluster.
+	TrashDir string
+	// Ordered deletes the resources in dependency order.
+	Ordered bool
+	// WaitDependents waits for the garbage collector to remove the dependents.
+	WaitDependents bool
+	// Pick asks the user to pick the object to delete when only its type is
+	// given, with PickFn.
+	Pick   bool
+	PickFn cmdutil.PickFunc
+	// EscalateAfter is how long to wait for the resources to be gone before
+	// reporting the finalizers blocking them, zero disables it.
+	EscalateAfter time.Duration
+	// RemoveFinalizers asks whether to remove the finalizers blocking the
+	// resources after EscalateAfter.
+	RemoveFinalizers bool
+	// Policies are the client-side policies the resources are checked
+	// against before being deleted.
+	Policies []*policy.Policy
 
 	GracePeriod int
 	Timeout     time.Duration
@@ -135,6 +188,8 @@ type DeleteOptions struct {
 	Result             *resource.Result
 	PreviewResult      *resource.Result
 	previewResourceMap map[cmdwait.ResourceLocation]struct{}
+	// cleanupSources removes the remote sources fetched for the filenames.
+	cleanupSources func()
 
 	genericiooptions.IOStreams
 	WarningPrinter *printers.WarningPrinter
@@ -162,6 +217,7 @@ func NewCmdDelete(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.
 
 	deleteFlags.AddFlags(cmd)
 	cmdutil.AddDryRunFlag(cmd)
+	cmdutil.AddConfirmationFlag(cmd)
 
 	return cmd
 }
@@ -194,6 +250,14 @@ func (o *DeleteOptions) Complete(f cmdutil.Factory, args []string, cmd *cobra.Co
 	if o.ForceDeletion && o.GracePeriod < 0 {
 		o.GracePeriod = 0
 	}
+	if o.WaitDependents {
+		// foreground deletion keeps the owners until all dependents are gone,
+		// so waiting for the owners waits for the garbage collector.
+		if f := cmd.Flags().Lookup("cascade"); f == nil || !f.Changed {
+			o.CascadingStrategy = metav1.DeletePropagationForeground
+		}
+		o.WaitForDeletion = true
+	}
 
 	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
 	if err != nil {
@@ -209,6 +273,39 @@ func (o *DeleteOptions) Complete(f cmdutil.Factory, args []string, cmd *cobra.Co
 		return nil
 	}
 
+	if o.Pick && !o.DeleteAll && len(args) == 1 && !strings.ContainsAny(args[0], "/,") && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
+		if o.PickFn == nil {
+			o.PickFn, err = cmdutil.NewTerminalPickFunc(o.IOStreams)
+			if err != nil {
+				return err
+			}
+		}
+		info, err := cmdutil.PickObject(f, o.PickFn, args[0], cmdNamespace, o.DeleteAllNamespaces, o.LabelSelector)
+		if err != nil {
+			return err
+		}
+		args = []string{args[0], info.Name}
+		if info.Namespaced() {
+			cmdNamespace = info.Namespace
+		}
+		o.DeleteAllNamespaces = false
+		o.LabelSelector = ""
+	}
+
+	if o.Trash && len(o.TrashDir) == 0 {
+		cluster, err := TrashClusterName(f)
+		if err != nil {
+			return err
+		}
+		o.TrashDir = DefaultTrashDir(cluster)
+	}
+
+	// the results are visited in RunDelete, which removes the fetched sources
+	o.cleanupSources, err = manifestsource.Fetch(&o.FilenameOptions)
+	if err != nil {
+		return err
+	}
+
 	r := f.NewBuilder().
 		Unstructured().
 		ContinueOnError().
@@ -278,6 +375,15 @@ func (o *DeleteOptions) Validate() error {
 	if o.WarningPrinter == nil {
 		return fmt.Errorf("WarningPrinter can not be used without initialization")
 	}
+	if o.WaitDependents && o.CascadingStrategy != metav1.DeletePropagationForeground {
+		return fmt.Errorf("--wait-dependents can not be used with --cascade=%s", strings.ToLower(string(o.CascadingStrategy)))
+	}
+	if o.EscalateAfter < 0 {
+		return fmt.Errorf("--escalate-after must not be negative")
+	}
+	if o.RemoveFinalizers && o.EscalateAfter == 0 {
+		return fmt.Errorf("--remove-finalizers requires --escalate-after")
+	}
 
 	switch {
 	case o.GracePeriod == 0 && o.ForceDeletion:
@@ -293,6 +399,18 @@ func (o *DeleteOptions) Validate() error {
 	if o.Interactive {
 		return fmt.Errorf("--interactive can not be used with --raw")
 	}
+	if o.Trash {
+		return fmt.Errorf("--trash can not be used with --raw")
+	}
+	if o.Ordered {
+		return fmt.Errorf("--ordered can not be used with --raw")
+	}
+	if o.EscalateAfter > 0 {
+		return fmt.Errorf("--escalate-after can not be used with --raw")
+	}
+	if len(o.Policies) > 0 {
+		return fmt.Errorf("--policy-dir can not be used with --raw")
+	}
 	if len(o.FilenameOptions.Filenames) > 1 {
 		return fmt.Errorf("--raw can only use a single local file or stdin")
 	} else if len(o.FilenameOptions.Filenames) == 1 {
@@ -358,6 +476,9 @@ func (o *DeleteOptions) RunDelete(f cmdutil.Factory) error {
 		}
 	}
 
+	if o.cleanupSources != nil {
+		defer o.cleanupSources()
+	}
 	return o.DeleteResult(o.Result)
 }
 
@@ -369,7 +490,23 @@ func (o *DeleteOptions) DeleteResult(r *resource.Result) error {
 	warnClusterScope := o.WarnClusterScope
 	deletedInfos := []*resource.Info{}
 	uidMap := cmdwait.UIDMap{}
-	err := r.Visit(func(info *resource.Info, err error) error {
+	var visitor resource.Visitor = r
+	var tiers [][]*resource.Info
+	if o.Ordered || len(o.Policies) > 0 {
+		infos, err := r.Infos()
+		if err != nil {
+			return err
+		}
+		// nothing is deleted when a resource violates a policy
+		if err := policy.Check(o.Policies, policy.OperationDelete, infos, o.ErrOut); err != nil {
+			return err
+		}
+		if o.Ordered {
+			tiers = orderForDeletion(infos)
+		}
+		visitor = resource.InfoListVisitor(infos)
+	}
+	deleteInfo := func(info *resource.Info, err error) error {
 		if err != nil {
 			return err
 		}
@@ -405,6 +542,11 @@ func (o *DeleteOptions) DeleteResult(r *resource.Result) error {
 			}
 			return nil
 		}
+		if o.Trash && o.DryRunStrategy == cmdutil.DryRunNone {
+			if err := o.saveToTrash(info); err != nil {
+				return err
+			}
+		}
 		response, err := o.deleteResource(info, options)
 		if err != nil {
 			return err
@@ -427,14 +569,34 @@ func (o *DeleteOptions) DeleteResult(r *resource.Result) error {
 		uidMap[resourceLocation] = responseMetadata.GetUID()
 
 		return nil
-	})
-	if err != nil {
-		return err
+	}
+	if tiers == nil {
+		if err := visitor.Visit(deleteInfo); err != nil {
+			return err
+		}
+	}
+	for i, tier := range tiers {
+		start := len(deletedInfos)
+		if err := resource.InfoListVisitor(tier).Visit(deleteInfo); err != nil {
+			return err
+		}
+		// the next tiers hold the definitions and namespaces of this one, so
+		// they are only deleted once this one is gone
+		if i < len(tiers)-1 && len(deletedInfos) > start && o.DynamicClient != nil && o.DryRunStrategy == cmdutil.DryRunNone {
+			if err := o.waitForDeletion(deletedInfos[start:], uidMap); err != nil {
+				return err
+			}
+		}
 	}
 	if found == 0 {
 		fmt.Fprintf(o.Out, "No resources found\n")
 		return nil
 	}
+	if o.EscalateAfter > 0 && o.DynamicClient != nil && o.DryRunStrategy == cmdutil.DryRunNone {
+		if err := o.escalate(deletedInfos, uidMap); err != nil {
+			return err
+		}
+	}
 	if !o.WaitForDeletion {
 		return nil
 	}
@@ -448,7 +610,11 @@ func (o *DeleteOptions) DeleteResult(r *resource.Result) error {
 	if o.DryRunStrategy != cmdutil.DryRunNone {
 		return nil
 	}
+	return o.waitForDeletion(deletedInfos, uidMap)
+}
 
+// waitForDeletion waits until the deleted resources are gone.
+func (o *DeleteOptions) waitForDeletion(deletedInfos []*resource.Info, uidMap cmdwait.UIDMap) error {
 	effectiveTimeout := o.Timeout
 	if effectiveTimeout == 0 {
 		// if we requested to wait forever, set it to a week.
@@ -464,7 +630,7 @@ func (o *DeleteOptions) DeleteResult(r *resource.Result) error {
 		ConditionFn: cmdwait.IsDeleted,
 		IOStreams:   o.IOStreams,
 	}
-	err = waitOptions.RunWait()
+	err := waitOptions.RunWait()
 	if errors.IsForbidden(err) || errors.IsMethodNotSupported(err) {
 		// if we're forbidden from waiting, we shouldn't fail.
 		// if the resource doesn't support a verb we need, we shouldn't fail.
@@ -474,6 +640,24 @@ func (o *DeleteOptions) DeleteResult(r *resource.Result) error {
 	return err
 }
 
+// saveToTrash saves the current state of the resource to the trash directory.
+func (o *DeleteOptions) saveToTrash(info *resource.Info) error {
+	obj, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name)
+	if err != nil {
+		return cmdutil.AddSourceToErr("saving to trash", info.Source, err)
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unable to save %s %q to trash: unexpected object %T", info.Mapping.Resource.GroupResource(), info.Name, obj)
+	}
+	path, err := SaveToTrash(o.TrashDir, info.Mapping.Resource.GroupResource(), u, time.Now())
+	if err != nil {
+		return fmt.Errorf("unable to save %s %q to trash: %v", info.Mapping.Resource.GroupResource(), info.Name, err)
+	}
+	klog.V(2).Infof("saved %s %q to %s", info.Mapping.Resource.GroupResource(), info.Name, path)
+	return nil
+}
+
 func (o *DeleteOptions) deleteResource(info *resource.Info, deleteOptions *metav1.DeleteOptions) (runtime.Object, error) {
 	deleteResponse, err := resource.
 		NewHelper(info.Client, info.Mapping).
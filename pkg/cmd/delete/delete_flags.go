@@ -28,6 +28,7 @@ import (
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/client-go/dynamic"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/policy"
 )
 
 // DeleteFlags composes common printer flag structs
@@ -49,6 +50,13 @@ type DeleteFlags struct {
 	Output            *string
 	Raw               *string
 	Interactive       *bool
+	Trash             *bool
+	Ordered           *bool
+	WaitDependents    *bool
+	Pick              *bool
+	EscalateAfter     *time.Duration
+	RemoveFinalizers  *bool
+	PolicyDir         *string
 }
 
 func (f *DeleteFlags) ToOptions(dynamicClient dynamic.Interface, streams genericiooptions.IOStreams) (*DeleteOptions, error) {
@@ -110,6 +118,31 @@ func (f *DeleteFlags) ToOptions(dynamicClient dynamic.Interface, streams generic
 	if f.Interactive != nil {
 		options.Interactive = *f.Interactive
 	}
+	if f.Trash != nil {
+		options.Trash = *f.Trash
+	}
+	if f.Ordered != nil {
+		options.Ordered = *f.Ordered
+	}
+	if f.WaitDependents != nil {
+		options.WaitDependents = *f.WaitDependents
+	}
+	if f.Pick != nil {
+		options.Pick = *f.Pick
+	}
+	if f.EscalateAfter != nil {
+		options.EscalateAfter = *f.EscalateAfter
+	}
+	if f.RemoveFinalizers != nil {
+		options.RemoveFinalizers = *f.RemoveFinalizers
+	}
+	if f.PolicyDir != nil {
+		policies, err := policy.Load(*f.PolicyDir)
+		if err != nil {
+			return nil, err
+		}
+		options.Policies = policies
+	}
 
 	return options, nil
 }
@@ -163,6 +196,27 @@ func (f *DeleteFlags) AddFlags(cmd *cobra.Command) {
 	if f.Interactive != nil {
 		cmd.Flags().BoolVarP(f.Interactive, "interactive", "i", *f.Interactive, "If true, delete resource only when user confirms.")
 	}
+	if f.Trash != nil {
+		cmd.Flags().BoolVar(f.Trash, "trash", *f.Trash, "If true, save a snapshot of each resource to ~/.kube/trash before deleting it, so that it can be brought back with 'kubectl restore'.")
+	}
+	if f.Ordered != nil {
+		cmd.Flags().BoolVar(f.Ordered, "ordered", *f.Ordered, "If true, delete dependents before their owners, and namespaced objects and custom resources before their namespaces and definitions.")
+	}
+	if f.WaitDependents != nil {
+		cmd.Flags().BoolVar(f.WaitDependents, "wait-dependents", *f.WaitDependents, "If true, delete with --cascade=foreground and wait until the garbage collector removed all the dependents. Can not be used with another --cascade strategy.")
+	}
+	if f.Pick != nil {
+		cmdutil.AddPickFlagVar(cmd, f.Pick)
+	}
+	if f.EscalateAfter != nil {
+		cmd.Flags().DurationVar(f.EscalateAfter, "escalate-after", *f.EscalateAfter, "If greater than zero, report the finalizers blocking the resources still there after this duration.")
+	}
+	if f.RemoveFinalizers != nil {
+		cmd.Flags().BoolVar(f.RemoveFinalizers, "remove-finalizers", *f.RemoveFinalizers, "If true with --escalate-after, ask whether to remove the finalizers blocking the resources still there.")
+	}
+	if f.PolicyDir != nil {
+		cmd.Flags().StringVar(f.PolicyDir, "policy-dir", *f.PolicyDir, "A directory of YAML or JSON policies the resources are checked against before being deleted. The violations of deny policies fail the command, those of warn policies are printed as warnings.")
+	}
 }
 
 // NewDeleteCommandFlags provides default flags and values for use with the "delete" command
@@ -183,6 +237,13 @@ func NewDeleteCommandFlags(usage string) *DeleteFlags {
 	wait := true
 	raw := ""
 	interactive := false
+	trash := false
+	ordered := false
+	waitDependents := false
+	pick := false
+	escalateAfter := time.Duration(0)
+	removeFinalizers := false
+	policyDir := ""
 
 	filenames := []string{}
 	recursive := false
@@ -207,6 +268,14 @@ func NewDeleteCommandFlags(usage string) *DeleteFlags {
 		Output:         &output,
 		Raw:            &raw,
 		Interactive:    &interactive,
+		Trash:          &trash,
+		Ordered:        &ordered,
+		WaitDependents: &waitDependents,
+		Pick:           &pick,
+
+		EscalateAfter:    &escalateAfter,
+		RemoveFinalizers: &removeFinalizers,
+		PolicyDir:        &policyDir,
 	}
 }
 
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdwait "k8s.io/kubectl/pkg/cmd/wait"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+// escalateInterval is how often the deleted objects are checked for with
+// EscalateAfter.
+var escalateInterval = time.Second
+
+// escalate waits EscalateAfter for the deleted objects to be gone, then
+// reports the finalizers blocking the objects left and, with
+// RemoveFinalizers, offers to remove them.
+func (o *DeleteOptions) escalate(infos []*resource.Info, uidMap cmdwait.UIDMap) error {
+	remaining := []stuckObject{}
+	err := wait.PollUntilContextTimeout(context.Background(), escalateInterval, o.EscalateAfter, true, func(ctx context.Context) (bool, error) {
+		var err error
+		remaining, err = o.remainingObjects(ctx, infos, uidMap)
+		return len(remaining) == 0, err
+	})
+	if err != nil && !wait.Interrupted(err) {
+		return err
+	}
+
+	for _, stuck := range remaining {
+		info, obj := stuck.info, stuck.obj
+		name := fmt.Sprintf("%s/%s", strings.ToLower(obj.GetKind()), obj.GetName())
+		finalizers := obj.GetFinalizers()
+		if len(finalizers) == 0 {
+			fmt.Fprintf(o.ErrOut, "%s is still being deleted after %s, no finalizer is blocking it\n", name, o.EscalateAfter)
+			continue
+		}
+		fmt.Fprintf(o.ErrOut, "%s is still being deleted after %s, blocked by the finalizers: %s\n", name, o.EscalateAfter, strings.Join(finalizers, ", "))
+		if !o.RemoveFinalizers || !o.confirmFinalizerRemoval(name) {
+			continue
+		}
+		patch := []byte(`{"metadata":{"finalizers":null}}`)
+		if _, err := o.DynamicClient.Resource(info.Mapping.Resource).Namespace(obj.GetNamespace()).Patch(context.TODO(), obj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("unable to remove the finalizers of %s: %v", name, err)
+		}
+		fmt.Fprintf(o.Out, "%s finalizers removed\n", name)
+	}
+	return nil
+}
+
+// stuckObject is an object still there after EscalateAfter.
+type stuckObject struct {
+	info *resource.Info
+	obj  *unstructured.Unstructured
+}
+
+// remainingObjects returns the objects not deleted yet.
+func (o *DeleteOptions) remainingObjects(ctx context.Context, infos []*resource.Info, uidMap cmdwait.UIDMap) ([]stuckObject, error) {
+	remaining := []stuckObject{}
+	for _, info := range infos {
+		obj, err := o.DynamicClient.Resource(info.Mapping.Resource).Namespace(info.Namespace).Get(ctx, info.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		location := cmdwait.ResourceLocation{GroupResource: info.Mapping.Resource.GroupResource(), Namespace: info.Namespace, Name: info.Name}
+		if uid, ok := uidMap[location]; ok && uid != obj.GetUID() {
+			// the object was recreated
+			continue
+		}
+		remaining = append(remaining, stuckObject{info: info, obj: obj})
+	}
+	return remaining, nil
+}
+
+func (o *DeleteOptions) confirmFinalizerRemoval(name string) bool {
+	fmt.Fprintf(o.Out, i18n.T("Removing the finalizers lets %s be deleted before the controllers owning them clean up. Remove them?")+" (y/n): ", name)
+	var input string
+	if _, err := fmt.Fscan(o.In, &input); err != nil {
+		return false
+	}
+	return strings.EqualFold(input, "y")
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	dynamicfakeclient "k8s.io/client-go/dynamic/fake"
+	cmdwait "k8s.io/kubectl/pkg/cmd/wait"
+)
+
+func TestEscalate(t *testing.T) {
+	defer func(interval time.Duration) { escalateInterval = interval }(escalateInterval)
+	escalateInterval = 10 * time.Millisecond
+
+	namespaces := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	newNamespace := func(name string, finalizers ...string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("Namespace")
+		obj.SetName(name)
+		obj.SetUID(types.UID(name))
+		obj.SetFinalizers(finalizers)
+		return obj
+	}
+	newInfo := func(name string) *resource.Info {
+		return &resource.Info{
+			Name: name,
+			Mapping: &meta.RESTMapping{
+				Resource:         namespaces,
+				GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Namespace"},
+				Scope:            meta.RESTScopeRoot,
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		removeFinalizers bool
+		input            string
+		expectedErrOut   string
+		expectedOut      string
+		uidMap           cmdwait.UIDMap
+		expectedLeft     []string
+	}{
+		{
+			name:           "report",
+			expectedErrOut: "namespace/stuck is still being deleted after 50ms, blocked by the finalizers: example.com/cleanup, kubernetes\nnamespace/slow is still being deleted after 50ms, no finalizer is blocking it\n",
+			expectedLeft:   []string{"example.com/cleanup", "kubernetes"},
+		},
+		{
+			name:             "remove finalizers",
+			removeFinalizers: true,
+			input:            "y\n",
+			expectedErrOut:   "namespace/stuck is still being deleted after 50ms, blocked by the finalizers: example.com/cleanup, kubernetes\nnamespace/slow is still being deleted after 50ms, no finalizer is blocking it\n",
+			expectedOut:      "namespace/stuck finalizers removed\n",
+		},
+		{
+			name:             "keep finalizers",
+			removeFinalizers: true,
+			input:            "n\n",
+			expectedErrOut:   "namespace/stuck is still being deleted after 50ms, blocked by the finalizers: example.com/cleanup, kubernetes\nnamespace/slow is still being deleted after 50ms, no finalizer is blocking it\n",
+			expectedLeft:     []string{"example.com/cleanup", "kubernetes"},
+		},
+		{
+			name:           "recreated",
+			uidMap:         cmdwait.UIDMap{{GroupResource: namespaces.GroupResource(), Name: "stuck"}: "deleted"},
+			expectedErrOut: "namespace/slow is still being deleted after 50ms, no finalizer is blocking it\n",
+			expectedLeft:   []string{"example.com/cleanup", "kubernetes"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := dynamicfakeclient.NewSimpleDynamicClient(runtime.NewScheme(),
+				newNamespace("stuck", "example.com/cleanup", "kubernetes"), newNamespace("slow"))
+			streams, in, out, errOut := genericiooptions.NewTestIOStreams()
+			in.WriteString(test.input)
+			o := &DeleteOptions{
+				DynamicClient:    client,
+				EscalateAfter:    50 * time.Millisecond,
+				RemoveFinalizers: test.removeFinalizers,
+				IOStreams:        streams,
+			}
+
+			infos := []*resource.Info{newInfo("stuck"), newInfo("slow"), newInfo("gone")}
+			if err := o.escalate(infos, test.uidMap); err != nil {
+				t.Fatal(err)
+			}
+			if e, a := test.expectedErrOut, errOut.String(); e != a {
+				t.Errorf("expected error output %q, got %q", e, a)
+			}
+			if !strings.HasSuffix(out.String(), test.expectedOut) {
+				t.Errorf("expected output ending with %q, got %q", test.expectedOut, out.String())
+			}
+			obj, err := client.Resource(namespaces).Get(context.TODO(), "stuck", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e, a := strings.Join(test.expectedLeft, ","), strings.Join(obj.GetFinalizers(), ","); e != a {
+				t.Errorf("expected finalizers %q, got %q", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// installOrder lists kinds in the order they are usually created in, so that
+// the objects they depend on exist first. Deletion goes the opposite way.
+// Kinds not listed, such as custom resources, are deleted before all others.
+var installOrder = []string{
+	"Namespace",
+	"NetworkPolicy",
+	"ResourceQuota",
+	"LimitRange",
+	"PodSecurityPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"CustomResourceDefinition",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+	"MutatingWebhookConfiguration",
+	"ValidatingWebhookConfiguration",
+}
+
+// Tiers of deletion, each deleted once the previous ones are gone: custom
+// resources first, while the controllers handling their finalizers still
+// run, then the other objects, the definitions and the namespaces.
+const (
+	tierCustomResources = iota
+	tierObjects
+	tierDefinitions
+	tierNamespaces
+	tierCount
+)
+
+type objectKey struct {
+	groupKind schema.GroupKind
+	namespace string
+	name      string
+}
+
+// orderForDeletion sorts the objects into tiers to delete one after the
+// other, so that dependents are deleted before their owners, and namespaced
+// objects and custom resources before the namespaces and definitions they
+// live in. Dependents are deleted in the tier of their owner when it comes
+// first.
+func orderForDeletion(infos []*resource.Info) [][]*resource.Info {
+	rank := map[string]int{}
+	for i, kind := range installOrder {
+		rank[kind] = len(installOrder) - i
+	}
+	sorted := make([]*resource.Info, len(infos))
+	copy(sorted, infos)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank[sorted[i].Mapping.GroupVersionKind.Kind] < rank[sorted[j].Mapping.GroupVersionKind.Kind]
+	})
+
+	// owner references found in the manifests move dependents ahead of their owners
+	dependents := map[objectKey][]*resource.Info{}
+	for _, info := range sorted {
+		accessor, err := meta.Accessor(info.Object)
+		if err != nil {
+			continue
+		}
+		for _, ref := range accessor.GetOwnerReferences() {
+			gv, err := schema.ParseGroupVersion(ref.APIVersion)
+			if err != nil {
+				continue
+			}
+			owner := objectKey{groupKind: gv.WithKind(ref.Kind).GroupKind(), namespace: info.Namespace, name: ref.Name}
+			dependents[owner] = append(dependents[owner], info)
+		}
+	}
+
+	tiers := make([][]*resource.Info, tierCount)
+	visited := map[*resource.Info]bool{}
+	var visit func(info *resource.Info, maxTier int)
+	visit = func(info *resource.Info, maxTier int) {
+		if visited[info] {
+			return
+		}
+		visited[info] = true
+		tier := tierForInfo(info, rank)
+		if tier > maxTier {
+			tier = maxTier
+		}
+		for _, dependent := range dependents[keyForInfo(info)] {
+			visit(dependent, tier)
+		}
+		tiers[tier] = append(tiers[tier], info)
+	}
+	for _, info := range sorted {
+		visit(info, tierNamespaces)
+	}
+
+	ordered := [][]*resource.Info{}
+	for _, tier := range tiers {
+		if len(tier) > 0 {
+			ordered = append(ordered, tier)
+		}
+	}
+	return ordered
+}
+
+func tierForInfo(info *resource.Info, rank map[string]int) int {
+	switch kind := info.Mapping.GroupVersionKind.Kind; {
+	case kind == "Namespace":
+		return tierNamespaces
+	case kind == "CustomResourceDefinition":
+		return tierDefinitions
+	case rank[kind] == 0:
+		return tierCustomResources
+	default:
+		return tierObjects
+	}
+}
+
+func keyForInfo(info *resource.Info) objectKey {
+	return objectKey{
+		groupKind: info.Mapping.GroupVersionKind.GroupKind(),
+		namespace: info.Namespace,
+		name:      info.Name,
+	}
+}
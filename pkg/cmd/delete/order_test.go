@@ -0,0 +1,189 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	dynamicfakeclient "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/rest/fake"
+	k8stesting "k8s.io/client-go/testing"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func testInfo(apiVersion, kind, namespace, name string, owners ...metav1.OwnerReference) *resource.Info {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetOwnerReferences(owners)
+	gv, _ := schema.ParseGroupVersion(apiVersion)
+	return &resource.Info{
+		Namespace: namespace,
+		Name:      name,
+		Object:    obj,
+		Mapping:   &meta.RESTMapping{GroupVersionKind: gv.WithKind(kind)},
+	}
+}
+
+func TestOrderForDeletion(t *testing.T) {
+	infos := []*resource.Info{
+		testInfo("v1", "Namespace", "", "demo"),
+		testInfo("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "widgets.example.com"),
+		testInfo("v1", "ConfigMap", "demo", "settings"),
+		testInfo("apps/v1", "Deployment", "demo", "web"),
+		// the config map is owned by the widget in the manifests
+		testInfo("v1", "ConfigMap", "demo", "generated", metav1.OwnerReference{APIVersion: "example.com/v1", Kind: "Widget", Name: "widget"}),
+		testInfo("example.com/v1", "Widget", "demo", "widget"),
+		testInfo("v1", "Service", "demo", "web"),
+	}
+
+	tiers := orderForDeletion(infos)
+	names := [][]string{}
+	for _, tier := range tiers {
+		tierNames := []string{}
+		for _, info := range tier {
+			tierNames = append(tierNames, info.Mapping.GroupVersionKind.Kind+"/"+info.Name)
+		}
+		names = append(names, tierNames)
+	}
+	expected := [][]string{
+		{"ConfigMap/generated", "Widget/widget"},
+		{"Deployment/web", "Service/web", "ConfigMap/settings"},
+		{"CustomResourceDefinition/widgets.example.com"},
+		{"Namespace/demo"},
+	}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestWaitDependentsOptions(t *testing.T) {
+	tf := cmdtesting.NewTestFactory()
+	defer tf.Cleanup()
+
+	deleteFlags := NewDeleteCommandFlags("")
+	*deleteFlags.WaitDependents = true
+	*deleteFlags.Wait = false
+	o, err := deleteFlags.ToOptions(nil, genericiooptions.NewTestIOStreamsDiscard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.Raw = "/api/v1/namespaces/test"
+	if err := o.Complete(tf, nil, fakecmd()); err != nil {
+		t.Fatal(err)
+	}
+	if o.CascadingStrategy != metav1.DeletePropagationForeground || !o.WaitForDeletion {
+		t.Errorf("expected foreground deletion with wait, got %s and %v", o.CascadingStrategy, o.WaitForDeletion)
+	}
+
+	o.CascadingStrategy = metav1.DeletePropagationOrphan
+	if err := o.Validate(); err == nil || err.Error() != "--wait-dependents can not be used with --cascade=orphan" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// an explicit --cascade=background is not turned into foreground
+	cmd := fakecmd()
+	cmd.Flags().String("cascade", "background", "")
+	if err := cmd.Flags().Set("cascade", "background"); err != nil {
+		t.Fatal(err)
+	}
+	o.CascadingStrategy = metav1.DeletePropagationBackground
+	if err := o.Complete(tf, nil, cmd); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Validate(); err == nil || err.Error() != "--wait-dependents can not be used with --cascade=background" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDeleteOrderedWaitsBetweenTiers(t *testing.T) {
+	var calls []string
+	client := &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			calls = append(calls, req.Method+" "+req.URL.Path)
+			body := io.NopCloser(strings.NewReader(`{"kind":"Status","apiVersion":"v1","status":"Success"}`))
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: body}, nil
+		}),
+	}
+	dynamicClient := dynamicfakeclient.NewSimpleDynamicClient(runtime.NewScheme())
+	dynamicClient.PrependReactor("get", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		calls = append(calls, "wait "+action.GetResource().Resource)
+		return false, nil, nil
+	})
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}, meta.RESTScopeNamespace)
+	manifests := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+  namespace: demo
+`
+	result := resource.NewFakeBuilder(
+		func(schema.GroupVersion) (resource.RESTClient, error) { return client, nil },
+		func() (meta.RESTMapper, error) { return mapper, nil },
+		nil,
+	).Unstructured().Stream(strings.NewReader(manifests), "test").Flatten().Do()
+
+	o := &DeleteOptions{
+		Ordered:           true,
+		GracePeriod:       -1,
+		CascadingStrategy: metav1.DeletePropagationBackground,
+		DynamicClient:     dynamicClient,
+		Quiet:             true,
+		IOStreams:         genericiooptions.NewTestIOStreamsDiscard(),
+	}
+	if err := o.DeleteResult(result); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"DELETE /namespaces/demo/widgets/widget",
+		"wait widgets",
+		"DELETE /customresourcedefinitions/widgets.example.com",
+		"wait customresourcedefinitions",
+		"DELETE /namespaces/demo",
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+}
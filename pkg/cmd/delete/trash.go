@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/homedir"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/yaml"
+)
+
+// clusterScopedTrashDir holds the snapshots of cluster-scoped objects, it
+// cannot clash with a namespace name as those may not contain underscores.
+const clusterScopedTrashDir = "_cluster"
+
+// trashTimeFormat names the snapshot files, it sorts in chronological order.
+const trashTimeFormat = "20060102T150405.000000000Z"
+
+// TrashEntry is the snapshot of an object taken before it was deleted.
+type TrashEntry struct {
+	Path          string
+	Namespace     string
+	GroupResource schema.GroupResource
+	Name          string
+	DeletedAt     time.Time
+}
+
+// DefaultTrashDir returns the directory holding the snapshots of the objects
+// deleted from the given cluster.
+func DefaultTrashDir(cluster string) string {
+	return filepath.Join(homedir.HomeDir(), ".kube", "trash", sanitizeTrashPath(cluster))
+}
+
+// TrashClusterName returns the name identifying the current cluster in the
+// trash: the name of the cluster of the current context, or the host of the
+// server when there is none.
+func TrashClusterName(f cmdutil.Factory) (string, error) {
+	loader := f.ToRawKubeConfigLoader()
+	rawConfig, err := loader.RawConfig()
+	if err == nil {
+		if context, ok := rawConfig.Contexts[rawConfig.CurrentContext]; ok && len(context.Cluster) > 0 {
+			return context.Cluster, nil
+		}
+	}
+	restConfig, err := loader.ClientConfig()
+	if err != nil {
+		return "", err
+	}
+	if u, err := url.Parse(restConfig.Host); err == nil && len(u.Host) > 0 {
+		return u.Host, nil
+	}
+	return restConfig.Host, nil
+}
+
+// SaveToTrash writes the object to the trash directory, without its managed
+// fields, and returns the path of the snapshot.
+func SaveToTrash(dir string, gr schema.GroupResource, obj *unstructured.Unstructured, now time.Time) (string, error) {
+	obj = obj.DeepCopy()
+	obj.SetManagedFields(nil)
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+
+	objDir := trashObjectDir(dir, obj.GetNamespace(), gr, obj.GetName())
+	if err := os.MkdirAll(objDir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(objDir, now.UTC().Format(trashTimeFormat)+".yaml")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ListTrash returns the snapshots in the trash directory, optionally limited
+// to one namespace, oldest first.
+func ListTrash(dir, namespace string) ([]TrashEntry, error) {
+	pattern := filepath.Join(dir, "*", "*", "*", "*.yaml")
+	if len(namespace) > 0 {
+		pattern = filepath.Join(dir, namespace, "*", "*", "*.yaml")
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []TrashEntry{}
+	for _, path := range paths {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.Split(rel, string(filepath.Separator))
+		deletedAt, err := time.Parse(trashTimeFormat, strings.TrimSuffix(parts[3], ".yaml"))
+		if err != nil {
+			// not a snapshot written by kubectl
+			continue
+		}
+		entry := TrashEntry{
+			Path:          path,
+			GroupResource: schema.ParseGroupResource(parts[1]),
+			Name:          parts[2],
+			DeletedAt:     deletedAt,
+		}
+		if parts[0] != clusterScopedTrashDir {
+			entry.Namespace = parts[0]
+		}
+		entries = append(entries, entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.Before(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// LatestTrashEntry returns the most recent snapshot of the given object.
+func LatestTrashEntry(dir, namespace string, gr schema.GroupResource, name string) (*TrashEntry, error) {
+	paths, err := filepath.Glob(filepath.Join(trashObjectDir(dir, namespace, gr, name), "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no snapshot of %s %q found in the trash", gr.String(), name)
+	}
+	sort.Strings(paths)
+	path := paths[len(paths)-1]
+	deletedAt, err := time.Parse(trashTimeFormat, strings.TrimSuffix(filepath.Base(path), ".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %v", path, err)
+	}
+	return &TrashEntry{
+		Path:          path,
+		Namespace:     namespace,
+		GroupResource: gr,
+		Name:          name,
+		DeletedAt:     deletedAt,
+	}, nil
+}
+
+// ReadTrashEntry reads the object of a snapshot, stripped of the fields set
+// by the server so that it can be created again. The owner references are
+// stripped too: they point to the uids of owners which may be gone, and the
+// garbage collector would delete the restored object again.
+func ReadTrashEntry(entry *TrashEntry) (*unstructured.Unstructured, error) {
+	data, err := os.ReadFile(entry.Path)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &obj.Object); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %v", entry.Path, err)
+	}
+	for _, field := range []string{"resourceVersion", "uid", "selfLink", "creationTimestamp", "generation", "deletionTimestamp", "deletionGracePeriodSeconds", "managedFields", "ownerReferences"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "status")
+	return obj, nil
+}
+
+// RemoveTrashEntry deletes a snapshot, along with the directories left empty.
+func RemoveTrashEntry(dir string, entry *TrashEntry) error {
+	if err := os.Remove(entry.Path); err != nil {
+		return err
+	}
+	for path := filepath.Dir(entry.Path); path != dir && strings.HasPrefix(path, dir); path = filepath.Dir(path) {
+		// fails on the first directory that is not empty
+		if os.Remove(path) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+func trashObjectDir(dir, namespace string, gr schema.GroupResource, name string) string {
+	if len(namespace) == 0 {
+		namespace = clusterScopedTrashDir
+	}
+	return filepath.Join(dir, namespace, gr.String(), name)
+}
+
+func sanitizeTrashPath(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':':
+			return '_'
+		}
+		return r
+	}, name)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package delete
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestTrashRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	obj.SetNamespace("test")
+	obj.SetName("nginx")
+	obj.SetResourceVersion("42")
+	obj.SetUID("1234")
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl"}})
+	obj.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "v1", Kind: "Foo", Name: "owner", UID: "5678"}})
+	obj.Object["status"] = map[string]interface{}{"replicas": int64(1)}
+
+	first := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := SaveToTrash(dir, deployments, obj, first); err != nil {
+		t.Fatal(err)
+	}
+	path, err := SaveToTrash(dir, deployments, obj, first.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else if len(data) == 0 || filepath.Dir(path) != filepath.Join(dir, "test", "deployments.apps", "nginx") {
+		t.Errorf("unexpected snapshot %s:\n%s", path, data)
+	}
+
+	entries, err := ListTrash(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || !entries[0].DeletedAt.Equal(first) || entries[1].GroupResource != deployments || entries[1].Namespace != "test" || entries[1].Name != "nginx" {
+		t.Fatalf("unexpected entries: %#v", entries)
+	}
+	if entries, err := ListTrash(dir, "other"); err != nil || len(entries) != 0 {
+		t.Errorf("expected no entries in namespace other, got %#v, %v", entries, err)
+	}
+
+	latest, err := LatestTrashEntry(dir, "test", deployments, "nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Path != path {
+		t.Errorf("expected latest snapshot %s, got %s", path, latest.Path)
+	}
+	restored, err := ReadTrashEntry(latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.GetResourceVersion() != "" || restored.GetUID() != "" || restored.GetManagedFields() != nil || restored.GetOwnerReferences() != nil || restored.Object["status"] != nil {
+		t.Errorf("expected server fields to be stripped, got %#v", restored.Object)
+	}
+	if restored.GetName() != "nginx" || restored.GetKind() != "Deployment" {
+		t.Errorf("unexpected object %#v", restored.Object)
+	}
+
+	for i := range entries {
+		if err := RemoveTrashEntry(dir, &entries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test")); !os.IsNotExist(err) {
+		t.Errorf("expected empty directories to be removed, got %v", err)
+	}
+	if _, err := LatestTrashEntry(dir, "test", deployments, "nginx"); err == nil {
+		t.Errorf("expected an error once the trash is empty")
+	}
+}
+
+func TestDeleteWithTrash(t *testing.T) {
+	cmdtesting.InitTestErrorHandler(t)
+	_, svc, _ := cmdtesting.TestData()
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	deleted := false
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/namespaces/test/services/baz" && m == "GET":
+				if deleted {
+					t.Errorf("the service was read after it was deleted")
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &svc.Items[0])}, nil
+			case p == "/namespaces/test/services/baz" && m == "DELETE":
+				deleted = true
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &svc.Items[0])}, nil
+			default:
+				t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+				return nil, nil
+			}
+		}),
+	}
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	deleteFlags := NewDeleteCommandFlags("")
+	*deleteFlags.Trash = true
+	*deleteFlags.Wait = false
+	o, err := deleteFlags.ToOptions(nil, streams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.TrashDir = t.TempDir()
+	cmd := fakecmd()
+	if err := o.Complete(tf, []string{"services/baz"}, cmd); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunDelete(tf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "service \"baz\" deleted\n" {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+
+	entry, err := LatestTrashEntry(o.TrashDir, "test", schema.GroupResource{Resource: "services"}, "baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := ReadTrashEntry(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetName() != "baz" || obj.GetKind() != "Service" {
+		t.Errorf("unexpected snapshot %#v", obj.Object)
+	}
+}
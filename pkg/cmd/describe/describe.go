@@ -30,6 +30,7 @@ import (
 	"k8s.io/cli-runtime/pkg/resource"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/describe"
+	"k8s.io/kubectl/pkg/util/color"
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -46,7 +47,10 @@ var (
 		    $ kubectl describe TYPE NAME_PREFIX
 
 		will first check for an exact match on TYPE and NAME_PREFIX. If no such resource
-		exists, it will output details for every resource that has a name prefixed with NAME_PREFIX.`))
+		exists, it will output details for every resource that has a name prefixed with NAME_PREFIX.
+
+		With --all-namespaces, the objects given by name are searched in all the namespaces,
+		and when a name is found in several of them the namespace is asked for on a terminal.`))
 
 	describeExample = templates.Examples(i18n.T(`
 		# Describe a node
@@ -64,9 +68,15 @@ var (
 		# Describe pods by label name=myLabel
 		kubectl describe pods -l name=myLabel
 
+		# Describe the pod nginx without knowing its namespace
+		kubectl describe pods/nginx -A
+
 		# Describe all pods managed by the 'frontend' replication controller
 		# (rc-created pods get the name of the rc as a prefix in the pod name)
-		kubectl describe pods frontend`))
+		kubectl describe pods frontend
+
+		# Describe only the conditions and the 10 most recent events of the last hour of a node
+		kubectl describe nodes kubernetes-node-emt8.c.myproject.internal --include=Conditions,Events --events-since=1h --events-limit=10`))
 )
 
 // DescribeFlags directly reflect the information that CLI is gathering via flags. They will be converted to Options,
@@ -77,6 +87,8 @@ type DescribeFlags struct {
 	AllNamespaces     bool
 	FilenameOptions   *resource.FilenameOptions
 	DescriberSettings *describe.DescriberSettings
+	Include           []string
+	RequestEncoding   string
 	genericiooptions.IOStreams
 }
 
@@ -89,7 +101,8 @@ func NewDescribeFlags(f cmdutil.Factory, streams genericiooptions.IOStreams) *De
 			ShowEvents: true,
 			ChunkSize:  cmdutil.DefaultChunkSize,
 		},
-		IOStreams: streams,
+		RequestEncoding: cmdutil.RequestEncodingProtobuf,
+		IOStreams:       streams,
 	}
 }
 
@@ -99,7 +112,11 @@ func (flags *DescribeFlags) AddFlags(cmd *cobra.Command) {
 	cmdutil.AddLabelSelectorFlagVar(cmd, &flags.Selector)
 	cmd.Flags().BoolVarP(&flags.AllNamespaces, "all-namespaces", "A", flags.AllNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
 	cmd.Flags().BoolVar(&flags.DescriberSettings.ShowEvents, "show-events", flags.DescriberSettings.ShowEvents, "If true, display events related to the described object.")
+	cmd.Flags().DurationVar(&flags.DescriberSettings.EventsSince, "events-since", flags.DescriberSettings.EventsSince, "Only display the events last seen within this duration, like 30m or 2h.")
+	cmd.Flags().IntVar(&flags.DescriberSettings.EventsLimit, "events-limit", flags.DescriberSettings.EventsLimit, "Only display this number of the most recent events. 0 displays all the events.")
+	cmd.Flags().StringSliceVar(&flags.Include, "include", flags.Include, "Only display these sections of the description, like Conditions,Events. The name and namespace of the objects are always displayed.")
 	cmdutil.AddChunkSizeFlag(cmd, &flags.DescriberSettings.ChunkSize)
+	cmdutil.AddRequestEncodingFlag(cmd, &flags.RequestEncoding)
 }
 
 // ToOptions converts from CLI inputs to runtime input
@@ -111,8 +128,12 @@ func (flags *DescribeFlags) ToOptions(parent string, args []string) (*DescribeOp
 		return nil, err
 	}
 
+	var pickFn cmdutil.PickFunc
 	if flags.AllNamespaces {
 		enforceNamespace = false
+		// the namespace of an object found in several namespaces is picked on
+		// a terminal, and is an error otherwise
+		pickFn, _ = cmdutil.NewTerminalPickFunc(flags.IOStreams)
 	}
 
 	if len(args) == 0 && cmdutil.IsFilenameSliceEmpty(flags.FilenameOptions.Filenames, flags.FilenameOptions.Kustomize) {
@@ -121,8 +142,13 @@ func (flags *DescribeFlags) ToOptions(parent string, args []string) (*DescribeOp
 
 	builderArgs := args
 
+	if len(flags.Include) > 0 && !describe.IncludesSection(flags.Include, "Events") {
+		// the events are not fetched when they are not displayed
+		flags.DescriberSettings.ShowEvents = false
+	}
+
 	describer := func(mapping *meta.RESTMapping) (describe.ResourceDescriber, error) {
-		return describe.DescriberFn(flags.Factory, mapping)
+		return describe.DescriberFn(cmdutil.WithRequestEncoding(flags.Factory, flags.RequestEncoding), mapping)
 	}
 
 	o := &DescribeOptions{
@@ -133,8 +159,11 @@ func (flags *DescribeFlags) ToOptions(parent string, args []string) (*DescribeOp
 		BuilderArgs:       builderArgs,
 		EnforceNamespace:  enforceNamespace,
 		AllNamespaces:     flags.AllNamespaces,
+		PickFn:            pickFn,
 		FilenameOptions:   flags.FilenameOptions,
 		DescriberSettings: flags.DescriberSettings,
+		Include:           flags.Include,
+		RequestEncoding:   flags.RequestEncoding,
 		IOStreams:         flags.IOStreams,
 	}
 
@@ -165,10 +194,30 @@ func NewCmdDescribe(parent string, f cmdutil.Factory, streams genericiooptions.I
 }
 
 func (o *DescribeOptions) Validate() error {
-	return nil
+	if o.DescriberSettings.EventsSince < 0 {
+		return fmt.Errorf("--events-since must not be negative")
+	}
+	if o.DescriberSettings.EventsLimit < 0 {
+		return fmt.Errorf("--events-limit must not be negative")
+	}
+	return cmdutil.ValidateRequestEncoding(o.RequestEncoding)
 }
 
 func (o *DescribeOptions) Run() error {
+	if hasNames, _ := resource.HasNames(o.BuilderArgs); o.AllNamespaces && hasNames && cmdutil.IsFilenameSliceEmpty(o.FilenameOptions.Filenames, o.FilenameOptions.Kustomize) {
+		namespace, err := cmdutil.InferNamespace(o.NewBuilder, o.PickFn, o.BuilderArgs)
+		if apierrors.IsNotFound(err) && len(o.BuilderArgs) == 2 {
+			return o.DescribeMatchingResources(err, o.BuilderArgs[0], o.BuilderArgs[1])
+		}
+		if err != nil {
+			return err
+		}
+		if len(namespace) > 0 {
+			o.Namespace = namespace
+		}
+		o.AllNamespaces = false
+	}
+
 	r := o.NewBuilder().
 		Unstructured().
 		ContinueOnError().
@@ -215,6 +264,7 @@ func (o *DescribeOptions) Run() error {
 			errs.Insert(err.Error())
 			continue
 		}
+		s = o.colorize(describe.FilterSections(s, o.Include))
 		if first {
 			first = false
 			fmt.Fprint(o.Out, s)
@@ -238,7 +288,7 @@ func (o *DescribeOptions) Run() error {
 func (o *DescribeOptions) DescribeMatchingResources(originalError error, resource, prefix string) error {
 	r := o.NewBuilder().
 		Unstructured().
-		NamespaceParam(o.Namespace).DefaultNamespace().
+		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
 		ResourceTypeOrNameArgs(true, resource).
 		SingleResourceType().
 		RequestChunksOf(o.DescriberSettings.ChunkSize).
@@ -265,7 +315,7 @@ func (o *DescribeOptions) DescribeMatchingResources(originalError error, resourc
 			if err != nil {
 				return err
 			}
-			fmt.Fprintf(o.Out, "%s\n", s)
+			fmt.Fprintf(o.Out, "%s\n", o.colorize(describe.FilterSections(s, o.Include)))
 		}
 	}
 	if !isFound {
@@ -286,9 +336,24 @@ type DescribeOptions struct {
 
 	EnforceNamespace bool
 	AllNamespaces    bool
+	// PickFn picks the namespace of an object given by name with AllNamespaces
+	// when it is found in several namespaces
+	PickFn cmdutil.PickFunc
 
 	DescriberSettings *describe.DescriberSettings
 	FilenameOptions   *resource.FilenameOptions
+	// Include holds the sections of the descriptions to print, all of them when empty
+	Include []string
+	// RequestEncoding is the encoding the describers request built-in resources in
+	RequestEncoding string
 
 	genericiooptions.IOStreams
 }
+
+// colorize colors the statuses of the conditions when the output is colored.
+func (o *DescribeOptions) colorize(s string) string {
+	if !color.Enabled(o.Out) {
+		return s
+	}
+	return color.Conditions(s)
+}
@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -243,6 +244,78 @@ func TestDescribeObjectChunkSize(t *testing.T) {
 	}
 }
 
+func TestDescribeObjectEventsWindowAndSections(t *testing.T) {
+	d := &testDescriber{Output: `Name:         bar
+Namespace:    test
+Labels:       app=bar
+              tier=web
+Conditions:
+  Type    Status
+  Ready   True
+Volumes:
+  data:
+    Type:  EmptyDir
+Events:  <none>
+`}
+	oldFn := describe.DescriberFn
+	defer func() {
+		describe.DescriberFn = oldFn
+	}()
+	describe.DescriberFn = d.describerFor
+
+	pods, _, _ := cmdtesting.TestData()
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Resp:                 &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &pods.Items[0])},
+	}
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdDescribe("kubectl", tf, streams)
+	cmd.Flags().Set("events-since", "1h")
+	cmd.Flags().Set("events-limit", "10")
+	cmd.Flags().Set("include", "conditions,Events")
+	cmd.Run(cmd, []string{"pods/foo"})
+	if d.Settings.EventsSince != time.Hour || d.Settings.EventsLimit != 10 || !d.Settings.ShowEvents {
+		t.Errorf("unexpected settings: %#v", d.Settings)
+	}
+	expected := `Name:         bar
+Namespace:    test
+Conditions:
+  Type    Status
+  Ready   True
+Events:  <none>
+`
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+
+	// the events are not fetched when they are not displayed
+	buf.Reset()
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Resp:                 &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &pods.Items[0])},
+	}
+	cmd = NewCmdDescribe("kubectl", tf, streams)
+	cmd.Flags().Set("include", "Volumes")
+	cmd.Run(cmd, []string{"pods/foo"})
+	if d.Settings.ShowEvents {
+		t.Errorf("ShowEvents = false expected, got ShowEvents = %v", d.Settings.ShowEvents)
+	}
+	expected = `Name:         bar
+Namespace:    test
+Volumes:
+  data:
+    Type:  EmptyDir
+`
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+}
+
 func TestDescribeHelpMessage(t *testing.T) {
 	tf := cmdtesting.NewTestFactory()
 	defer tf.Cleanup()
@@ -325,6 +398,51 @@ func TestDescribeNoResourcesFound(t *testing.T) {
 	}
 }
 
+func TestDescribeAllNamespacesByName(t *testing.T) {
+	d := &testDescriber{Output: "test output"}
+	oldFn := describe.DescriberFn
+	defer func() {
+		describe.DescriberFn = oldFn
+	}()
+	describe.DescriberFn = d.describerFor
+
+	pods, _, _ := cmdtesting.TestData()
+	pods.Items[1].Namespace = "other"
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/pods" && m == "GET" && req.URL.Query().Get("fieldSelector") == "metadata.name=bar":
+				list := pods.DeepCopy()
+				list.Items = list.Items[1:]
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, list)}, nil
+			case p == "/namespaces/other/pods/bar" && m == "GET":
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &pods.Items[1])}, nil
+			default:
+				t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+				return nil, nil
+			}
+		}),
+	}
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+
+	cmd := NewCmdDescribe("kubectl", tf, streams)
+	cmd.Flags().Set("all-namespaces", "true")
+	cmd.Run(cmd, []string{"pods", "bar"})
+
+	if d.Name != "bar" || d.Namespace != "other" {
+		t.Errorf("unexpected describer: %#v", d)
+	}
+	if buf.String() != d.Output {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
+
 type testDescriber struct {
 	Name, Namespace string
 	Settings        describe.DescriberSettings
@@ -41,6 +41,7 @@ import (
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/color"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/openapi"
 	"k8s.io/kubectl/pkg/util/prune"
@@ -216,6 +217,12 @@ func (d *DiffProgram) getCommand(args ...string) (string, exec.Cmd) {
 // Run runs the detected diff program. `from` and `to` are the directory to diff.
 func (d *DiffProgram) Run(from, to string) error {
 	diff, cmd := d.getCommand(from, to)
+	if len(os.Getenv("KUBECTL_EXTERNAL_DIFF")) == 0 && color.Enabled(d.Out) {
+		// external diff programs are left to color their output
+		out := color.NewDiffWriter(d.Out)
+		defer out.Flush()
+		cmd.SetStdout(out)
+	}
 	if err := cmd.Run(); err != nil {
 		// Let's not wrap diff errors, or we won't be able to
 		// differentiate them later.
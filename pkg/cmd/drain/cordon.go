@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/drain"
+)
+
+// cordonAnnotations returns the annotations to set along with the
+// schedulability of a node. Cordoning records the reason and expiry when
+// they are given, uncordoning removes both.
+func (o *DrainCmdOptions) cordonAnnotations(desired bool, now time.Time) map[string]string {
+	if !desired {
+		return map[string]string{
+			drain.CordonReasonAnnotation:  "",
+			drain.CordonExpiresAnnotation: "",
+		}
+	}
+	annotations := map[string]string{}
+	if len(o.CordonReason) > 0 {
+		annotations[drain.CordonReasonAnnotation] = o.CordonReason
+	}
+	if o.CordonTTL > 0 {
+		annotations[drain.CordonExpiresAnnotation] = now.Add(o.CordonTTL).UTC().Format(time.RFC3339)
+	}
+	return annotations
+}
+
+// cordonExpired returns true if the cordon expiry annotation of the node
+// lies before now.
+func cordonExpired(obj runtime.Object, now time.Time) bool {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	value, ok := accessor.GetAnnotations()[drain.CordonExpiresAnnotation]
+	if !ok {
+		return false
+	}
+	expires, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		klog.Warningf("ignoring invalid %s annotation %q of node %s: %v", drain.CordonExpiresAnnotation, value, accessor.GetName(), err)
+		return false
+	}
+	return !expires.After(now)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kubectl/pkg/drain"
+)
+
+func TestCordonExpired(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{name: "no expiry"},
+		{name: "expired", annotations: map[string]string{drain.CordonExpiresAnnotation: "2024-05-01T11:00:00Z"}, expected: true},
+		{name: "not expired", annotations: map[string]string{drain.CordonExpiresAnnotation: "2024-05-01T13:00:00Z"}},
+		{name: "invalid", annotations: map[string]string{drain.CordonExpiresAnnotation: "tomorrow"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Annotations: test.annotations}}
+			if got := cordonExpired(node, now); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCordonReasonAndTTL(t *testing.T) {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	o, client := newInteractiveTestOptions(t, "", node)
+	o.report = nil
+	o.CordonReason = "kernel upgrade"
+	o.CordonTTL = time.Hour
+
+	getNode := func() *corev1.Node {
+		t.Helper()
+		node, err := client.CoreV1().Nodes().Get(context.Background(), "node", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return node
+	}
+
+	o.nodeInfos = []*resource.Info{parallelTestNode("node")}
+	before := time.Now()
+	if err := o.RunCordonOrUncordon(true); err != nil {
+		t.Fatal(err)
+	}
+	cordoned := getNode()
+	if !cordoned.Spec.Unschedulable {
+		t.Errorf("expected the node to be cordoned")
+	}
+	if reason := cordoned.Annotations[drain.CordonReasonAnnotation]; reason != "kernel upgrade" {
+		t.Errorf("unexpected reason %q", reason)
+	}
+	expires, err := time.Parse(time.RFC3339, cordoned.Annotations[drain.CordonExpiresAnnotation])
+	if err != nil {
+		t.Fatalf("invalid expiry: %v", err)
+	}
+	if expires.Before(before.Add(time.Hour).Truncate(time.Second)) || expires.After(time.Now().Add(time.Hour)) {
+		t.Errorf("unexpected expiry %v", expires)
+	}
+
+	// a new reason is recorded even though the node is already cordoned
+	o.CordonReason = "disk replacement"
+	o.CordonTTL = 0
+	o.nodeInfos = []*resource.Info{{Name: "node", Object: cordoned, Mapping: parallelTestNode("node").Mapping}}
+	if err := o.RunCordonOrUncordon(true); err != nil {
+		t.Fatal(err)
+	}
+	cordoned = getNode()
+	if reason := cordoned.Annotations[drain.CordonReasonAnnotation]; reason != "disk replacement" {
+		t.Errorf("unexpected reason %q", reason)
+	}
+	if _, ok := cordoned.Annotations[drain.CordonExpiresAnnotation]; !ok {
+		t.Errorf("expected the expiry to be kept")
+	}
+
+	o.nodeInfos = []*resource.Info{{Name: "node", Object: cordoned, Mapping: parallelTestNode("node").Mapping}}
+	if err := o.RunCordonOrUncordon(false); err != nil {
+		t.Fatal(err)
+	}
+	uncordoned := getNode()
+	if uncordoned.Spec.Unschedulable || len(uncordoned.Annotations) != 0 {
+		t.Errorf("expected an uncordoned node without annotations, got %#v", uncordoned)
+	}
+}
@@ -19,6 +19,9 @@ package drain
 import (
 	"errors"
 	"fmt"
+	"io"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -26,7 +29,6 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
@@ -47,9 +49,34 @@ type DrainCmdOptions struct {
 
 	Namespace string
 
+	// Interactive asks what to do with each pod blocking the drain.
+	Interactive bool
+	// Output, if set, prints a report of the drain in this format instead
+	// of progress messages.
+	Output string
+	// MaxUnavailableNodes, if set, drains up to this many nodes at once,
+	// cordoning each node only when its drain starts. It bounds the drains in
+	// progress, not the cordoned nodes: drained nodes stay cordoned.
+	MaxUnavailableNodes int
+	// Pace limits how many node drains are started per interval, as COUNT/DURATION.
+	Pace string
+	// CordonReason, if set, is recorded on cordoned nodes.
+	CordonReason string
+	// CordonTTL, if set, records when the cordon of the nodes expires.
+	CordonTTL time.Duration
+	// UncordonExpired only uncordons nodes whose cordon has expired.
+	UncordonExpired bool
+
+	pace         *drainPace
+	printerMutex sync.Mutex
+
 	drainer   *drain.Helper
 	nodeInfos []*resource.Info
 
+	report    *drainReport
+	reportOut io.Writer
+	promptOut io.Writer
+
 	genericiooptions.IOStreams
 	WarningPrinter *printers.WarningPrinter
 }
@@ -60,7 +87,10 @@ var (
 
 	cordonExample = templates.Examples(i18n.T(`
 		# Mark node "foo" as unschedulable
-		kubectl cordon foo`))
+		kubectl cordon foo
+
+		# Mark node "foo" as unschedulable for two hours, recording why
+		kubectl cordon foo --reason="kernel upgrade" --ttl=2h`))
 )
 
 func NewCmdCordon(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra.Command {
@@ -78,6 +108,8 @@ func NewCmdCordon(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobr
 			cmdutil.CheckErr(o.RunCordonOrUncordon(true))
 		},
 	}
+	cmd.Flags().StringVar(&o.CordonReason, "reason", o.CordonReason, "Record why the node is cordoned in the "+drain.CordonReasonAnnotation+" annotation.")
+	cmd.Flags().DurationVar(&o.CordonTTL, "ttl", o.CordonTTL, "Record in the "+drain.CordonExpiresAnnotation+" annotation that the cordon expires after this long. Expired cordons are lifted by 'kubectl uncordon --expired'.")
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.drainer.Selector)
 	cmdutil.AddDryRunFlag(cmd)
 	return cmd
@@ -89,7 +121,10 @@ var (
 
 	uncordonExample = templates.Examples(i18n.T(`
 		# Mark node "foo" as schedulable
-		kubectl uncordon foo`))
+		kubectl uncordon foo
+
+		# Mark all nodes whose cordon has expired as schedulable
+		kubectl uncordon --expired`))
 )
 
 func NewCmdUncordon(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra.Command {
@@ -107,6 +142,7 @@ func NewCmdUncordon(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *co
 			cmdutil.CheckErr(o.RunCordonOrUncordon(false))
 		},
 	}
+	cmd.Flags().BoolVar(&o.UncordonExpired, "expired", o.UncordonExpired, "Only uncordon nodes whose cordon, set with 'kubectl cordon --ttl', has expired. Without a node name or selector all nodes are checked.")
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.drainer.Selector)
 	cmdutil.AddDryRunFlag(cmd)
 	return cmd
@@ -143,7 +179,16 @@ var (
 		kubectl drain foo --force
 
 		# As above, but abort if there are pods not managed by a replication controller, replica set, job, daemon set, or stateful set, and use a grace period of 15 minutes
-		kubectl drain foo --grace-period=900`))
+		kubectl drain foo --grace-period=900
+
+		# Drain all worker nodes, two at a time and starting at most one drain every 5 minutes (drained nodes stay cordoned)
+		kubectl drain -l node-role=worker --ignore-daemonsets --max-unavailable-nodes=2 --pace=1/5m
+
+		# Drain node "foo", choosing what to do with each pod that blocks the drain
+		kubectl drain foo --interactive
+
+		# Drain node "foo" and print a JSON report of the pods evicted, skipped or blocked
+		kubectl drain foo --ignore-daemonsets -o json`))
 )
 
 func NewDrainCmdOptions(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *DrainCmdOptions {
@@ -167,17 +212,20 @@ func (o *DrainCmdOptions) onPodDeletionOrEvictionFinished(pod *corev1.Pod, using
 	var verbStr string
 	if usingEviction {
 		if err != nil {
-			verbStr = "eviction failed"
+			verbStr = podActionEvictionFailed
 		} else {
-			verbStr = "evicted"
+			verbStr = podActionEvicted
 		}
 	} else {
 		if err != nil {
-			verbStr = "deletion failed"
+			verbStr = podActionDeletionFailed
 		} else {
-			verbStr = "deleted"
+			verbStr = podActionDeleted
 		}
 	}
+	if o.report != nil {
+		o.report.addPod(pod, verbStr, "", err)
+	}
 	printObj, err := o.ToPrinter(verbStr)
 	if err != nil {
 		fmt.Fprintf(o.ErrOut, "error building printer: %v\n", err)
@@ -231,10 +279,15 @@ func NewCmdDrain(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra
 	cmd.Flags().DurationVar(&o.drainer.Timeout, "timeout", o.drainer.Timeout, "The length of time to wait before giving up, zero means infinite")
 	cmd.Flags().StringVarP(&o.drainer.PodSelector, "pod-selector", "", o.drainer.PodSelector, "Label selector to filter pods on the node")
 	cmd.Flags().BoolVar(&o.drainer.DisableEviction, "disable-eviction", o.drainer.DisableEviction, "Force drain to use delete, even if eviction is supported. This will bypass checking PodDisruptionBudgets, use with caution.")
+	cmd.Flags().BoolVar(&o.Interactive, "interactive", o.Interactive, "List the pods blocking the drain, such as pods with local storage, DaemonSet-managed pods and pods whose PodDisruptionBudget allows no disruptions, and ask what to do with each of them.")
+	cmd.Flags().IntVar(&o.MaxUnavailableNodes, "max-unavailable-nodes", o.MaxUnavailableNodes, "Drain up to this many nodes at once, cordoning each node only when its drain starts. This limits the drains in progress, not the unschedulable nodes: drained nodes stay cordoned, so the number of cordoned nodes keeps growing as drains complete. Nodes whose pods share a PodDisruptionBudget are not drained at the same time. Zero cordons all nodes first and drains them one at a time.")
+	cmd.Flags().StringVar(&o.Pace, "pace", o.Pace, "Start at most COUNT node drains every DURATION, for example 1/5m.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format, one of: json, yaml. Prints a report of the pods evicted, deleted, skipped or blocked on each node instead of progress messages.")
 	cmd.Flags().IntVar(&o.drainer.SkipWaitForDeleteTimeoutSeconds, "skip-wait-for-delete-timeout", o.drainer.SkipWaitForDeleteTimeoutSeconds, "If pod DeletionTimestamp older than N seconds, skip waiting for the pod.  Seconds must be greater than 0 to skip.")
 
 	cmdutil.AddChunkSizeFlag(cmd, &o.drainer.ChunkSize)
 	cmdutil.AddDryRunFlag(cmd)
+	cmdutil.AddConfirmationFlag(cmd)
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.drainer.Selector)
 	return cmd
 }
@@ -244,7 +297,8 @@ func NewCmdDrain(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra
 func (o *DrainCmdOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
 	var err error
 
-	if len(args) == 0 && !cmd.Flags().Changed("selector") {
+	selectAll := o.UncordonExpired && len(args) == 0 && len(o.drainer.Selector) == 0
+	if len(args) == 0 && !cmd.Flags().Changed("selector") && !selectAll {
 		return cmdutil.UsageErrorf(cmd, fmt.Sprintf("USAGE: %s [flags]", cmd.Use))
 	}
 	if len(args) > 0 && len(o.drainer.Selector) > 0 {
@@ -266,6 +320,35 @@ func (o *DrainCmdOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args [
 		}
 	}
 
+	if len(o.Pace) > 0 {
+		if o.pace, err = parsePace(o.Pace); err != nil {
+			return cmdutil.UsageErrorf(cmd, err.Error())
+		}
+	}
+	if o.MaxUnavailableNodes < 0 {
+		return cmdutil.UsageErrorf(cmd, "--max-unavailable-nodes must not be negative")
+	}
+	if o.CordonTTL < 0 {
+		return cmdutil.UsageErrorf(cmd, "--ttl must not be negative")
+	}
+	if o.Interactive && (o.MaxUnavailableNodes > 0 || o.pace != nil) {
+		return cmdutil.UsageErrorf(cmd, "--interactive cannot be combined with --max-unavailable-nodes or --pace")
+	}
+
+	o.promptOut = o.Out
+	switch o.Output {
+	case "":
+	case "json", "yaml":
+		// progress messages are replaced by the report
+		o.report = &drainReport{DryRun: o.drainer.DryRunStrategy != cmdutil.DryRunNone}
+		o.reportOut = o.Out
+		o.promptOut = o.ErrOut
+		o.Out = io.Discard
+		o.drainer.Out = io.Discard
+	default:
+		return cmdutil.UsageErrorf(cmd, "unsupported output format %q, allowed formats are: json, yaml", o.Output)
+	}
+
 	o.nodeInfos = []*resource.Info{}
 
 	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
@@ -274,6 +357,9 @@ func (o *DrainCmdOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args [
 	}
 
 	o.ToPrinter = func(operation string) (printers.ResourcePrinterFunc, error) {
+		// nodes drained in parallel share the print flags
+		o.printerMutex.Lock()
+		defer o.printerMutex.Unlock()
 		o.PrintFlags.NamePrintFlags.Operation = operation
 		cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.drainer.DryRunStrategy)
 
@@ -302,6 +388,9 @@ func (o *DrainCmdOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args [
 		builder = builder.LabelSelectorParam(o.drainer.Selector).
 			ResourceTypes("nodes")
 	}
+	if selectAll {
+		builder = builder.ResourceTypes("nodes").SelectAllParam(true)
+	}
 
 	r := builder.Do()
 
@@ -317,6 +406,10 @@ func (o *DrainCmdOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args [
 			return fmt.Errorf("error: expected resource of type node, got %q", info.Mapping.Resource)
 		}
 
+		if o.UncordonExpired && !cordonExpired(info.Object, time.Now()) {
+			klog.V(2).Infof("skipping node %s, its cordon has not expired", info.Name)
+			return nil
+		}
 		o.nodeInfos = append(o.nodeInfos, info)
 		return nil
 	})
@@ -324,36 +417,50 @@ func (o *DrainCmdOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args [
 
 // RunDrain runs the 'drain' command
 func (o *DrainCmdOptions) RunDrain() error {
+	if o.MaxUnavailableNodes > 0 || o.pace != nil {
+		remainingNodes, fatal := o.runParallelDrain()
+		return o.finishDrain(remainingNodes, fatal)
+	}
+
 	if err := o.RunCordonOrUncordon(true); err != nil {
 		return err
 	}
 
-	drainedNodes := sets.NewString()
 	var fatal []error
-
 	remainingNodes := []string{}
 	for _, info := range o.nodeInfos {
-		if err := o.deleteOrEvictPodsSimple(info); err == nil {
-			drainedNodes.Insert(info.Name)
-
-			printObj, err := o.ToPrinter("drained")
-			if err != nil {
-				return err
-			}
-
-			printObj(info.Object, o.Out)
-		} else {
-			fmt.Fprintf(o.ErrOut, "error: unable to drain node %q due to error:%s, continuing command...\n", info.Name, err)
+		if err := o.drainNode(info); err != nil {
+			fatal = append(fatal, err)
+			remainingNodes = append(remainingNodes, info.Name)
+		}
+	}
+	return o.finishDrain(remainingNodes, fatal)
+}
 
-			if !drainedNodes.Has(info.Name) {
-				fatal = append(fatal, err)
-				remainingNodes = append(remainingNodes, info.Name)
-			}
+// drainNode evicts or deletes the pods of a cordoned node and prints the outcome.
+func (o *DrainCmdOptions) drainNode(info *resource.Info) error {
+	drainFn := o.deleteOrEvictPodsSimple
+	if o.Interactive {
+		drainFn = o.deleteOrEvictPodsInteractive
+	}
+	err := drainFn(info)
+	if o.report != nil {
+		o.report.setResult(info.Name, err)
+	}
+	if err != nil {
+		fmt.Fprintf(o.ErrOut, "error: unable to drain node %q due to error:%s, continuing command...\n", info.Name, err)
+		return err
+	}
 
-			continue
-		}
+	printObj, err := o.ToPrinter("drained")
+	if err != nil {
+		return err
 	}
+	return printObj(info.Object, o.Out)
+}
 
+// finishDrain reports the nodes that could not be drained.
+func (o *DrainCmdOptions) finishDrain(remainingNodes []string, fatal []error) error {
 	if len(remainingNodes) > 0 {
 		fmt.Fprintf(o.ErrOut, "There are pending nodes to be drained:\n")
 		for _, nodeName := range remainingNodes {
@@ -361,11 +468,19 @@ func (o *DrainCmdOptions) RunDrain() error {
 		}
 	}
 
+	if o.report != nil {
+		if err := o.report.print(o); err != nil {
+			fatal = append(fatal, err)
+		}
+	}
 	return utilerrors.NewAggregate(fatal)
 }
 
 func (o *DrainCmdOptions) deleteOrEvictPodsSimple(nodeInfo *resource.Info) error {
 	list, errs := o.drainer.GetPodsForDeletion(nodeInfo.Name)
+	if list != nil && o.report != nil {
+		o.report.addFiltered(list)
+	}
 	if errs != nil {
 		return utilerrors.NewAggregate(errs)
 	}
@@ -373,9 +488,7 @@ func (o *DrainCmdOptions) deleteOrEvictPodsSimple(nodeInfo *resource.Info) error
 		o.WarningPrinter.Print(warnings)
 	}
 	if o.drainer.DryRunStrategy == cmdutil.DryRunClient {
-		for _, pod := range list.Pods() {
-			fmt.Fprintf(o.Out, "evicting pod %s/%s (dry run)\n", pod.Namespace, pod.Name)
-		}
+		o.printDryRun(list.Pods(), podActionEvicted)
 		return nil
 	}
 
@@ -401,60 +514,77 @@ func (o *DrainCmdOptions) deleteOrEvictPodsSimple(nodeInfo *resource.Info) error
 // RunCordonOrUncordon runs either Cordon or Uncordon.  The desired value for
 // "Unschedulable" is passed as the first arg.
 func (o *DrainCmdOptions) RunCordonOrUncordon(desired bool) error {
+	return o.cordonOrUncordonNodes(o.nodeInfos, desired)
+}
+
+func (o *DrainCmdOptions) cordonOrUncordonNodes(nodeInfos []*resource.Info, desired bool) error {
+	for _, nodeInfo := range nodeInfos {
+		// failures are printed, the remaining nodes are still updated
+		o.cordonOrUncordonNode(nodeInfo, desired)
+	}
+	return nil
+}
+
+// cordonOrUncordonNode sets the unschedulable field of a node to desired and
+// prints the outcome. It returns the error the node could not be updated with.
+func (o *DrainCmdOptions) cordonOrUncordonNode(nodeInfo *resource.Info, desired bool) error {
 	cordonOrUncordon := "cordon"
 	if !desired {
 		cordonOrUncordon = "un" + cordonOrUncordon
 	}
 
-	for _, nodeInfo := range o.nodeInfos {
+	printError := func(err error) {
+		fmt.Fprintf(o.ErrOut, "error: unable to %s node %q: %v\n", cordonOrUncordon, nodeInfo.Name, err)
+	}
 
-		printError := func(err error) {
-			fmt.Fprintf(o.ErrOut, "error: unable to %s node %q: %v\n", cordonOrUncordon, nodeInfo.Name, err)
+	gvk := nodeInfo.ResourceMapping().GroupVersionKind
+	if gvk.Kind == "Node" {
+		c, err := drain.NewCordonHelperFromRuntimeObject(nodeInfo.Object, scheme.Scheme, gvk)
+		if err != nil {
+			printError(err)
+			return err
 		}
+		c.WithAnnotations(o.cordonAnnotations(desired, time.Now()))
 
-		gvk := nodeInfo.ResourceMapping().GroupVersionKind
-		if gvk.Kind == "Node" {
-			c, err := drain.NewCordonHelperFromRuntimeObject(nodeInfo.Object, scheme.Scheme, gvk)
+		if updateRequired := c.UpdateIfRequired(desired); !updateRequired {
+			if o.report != nil {
+				o.report.setCordoned(nodeInfo.Name, desired)
+			}
+			printObj, err := o.ToPrinter(already(desired))
 			if err != nil {
-				printError(err)
-				continue
+				fmt.Fprintf(o.ErrOut, "error: %v\n", err)
+				return nil
 			}
-
-			if updateRequired := c.UpdateIfRequired(desired); !updateRequired {
-				printObj, err := o.ToPrinter(already(desired))
-				if err != nil {
-					fmt.Fprintf(o.ErrOut, "error: %v\n", err)
-					continue
-				}
-				printObj(nodeInfo.Object, o.Out)
-			} else {
-				if o.drainer.DryRunStrategy != cmdutil.DryRunClient {
-					err, patchErr := c.PatchOrReplace(o.drainer.Client, o.drainer.DryRunStrategy == cmdutil.DryRunServer)
-					if patchErr != nil {
-						printError(patchErr)
-					}
-					if err != nil {
-						printError(err)
-						continue
-					}
+			printObj(nodeInfo.Object, o.Out)
+		} else {
+			if o.drainer.DryRunStrategy != cmdutil.DryRunClient {
+				err, patchErr := c.PatchOrReplace(o.drainer.Client, o.drainer.DryRunStrategy == cmdutil.DryRunServer)
+				if patchErr != nil {
+					printError(patchErr)
 				}
-				printObj, err := o.ToPrinter(changed(desired))
 				if err != nil {
-					fmt.Fprintf(o.ErrOut, "%v\n", err)
-					continue
+					printError(err)
+					return err
 				}
-				printObj(nodeInfo.Object, o.Out)
 			}
-		} else {
-			printObj, err := o.ToPrinter("skipped")
+			if o.report != nil {
+				o.report.setCordoned(nodeInfo.Name, desired)
+			}
+			printObj, err := o.ToPrinter(changed(desired))
 			if err != nil {
 				fmt.Fprintf(o.ErrOut, "%v\n", err)
-				continue
+				return nil
 			}
 			printObj(nodeInfo.Object, o.Out)
 		}
+	} else {
+		printObj, err := o.ToPrinter("skipped")
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "%v\n", err)
+			return nil
+		}
+		printObj(nodeInfo.Object, o.Out)
 	}
-
 	return nil
 }
 
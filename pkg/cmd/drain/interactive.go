@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/drain"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+// blockingPod is a pod that prevents the node from being drained as is.
+type blockingPod struct {
+	pod    corev1.Pod
+	reason string
+	// evictable is true for pods protected by a disruption budget, which
+	// drain would otherwise keep trying to evict.
+	evictable bool
+}
+
+// podAction is the action chosen for a blocking pod.
+type podAction string
+
+const (
+	actionEvict  podAction = "e"
+	actionDelete podAction = "d"
+	actionSkip   podAction = "s"
+	actionAbort  podAction = "a"
+)
+
+// deleteOrEvictPodsInteractive lists the pods blocking the drain of the
+// node and asks the user what to do with each before evicting the rest.
+func (o *DrainCmdOptions) deleteOrEvictPodsInteractive(nodeInfo *resource.Info) error {
+	list, errs := o.drainer.GetPodsForDeletion(nodeInfo.Name)
+	if list == nil {
+		return utilerrors.NewAggregate(errs)
+	}
+	if warnings := list.Warnings(); warnings != "" {
+		o.WarningPrinter.Print(warnings)
+	}
+
+	blocking, err := o.blockingPods(list)
+	if err != nil {
+		return err
+	}
+	if o.report != nil {
+		// blocking pods are recorded with the action the user chooses
+		for _, item := range list.Items() {
+			if !item.Status.Delete && item.Status.Reason != drain.PodDeleteStatusTypeError {
+				pod := item.Pod
+				o.report.addPod(&pod, podActionSkipped, item.Status.Message, nil)
+			}
+		}
+	}
+
+	var evict, remove []corev1.Pod
+	blocked := map[string]bool{}
+	if len(blocking) > 0 {
+		fmt.Fprintf(o.promptOut, i18n.T("The following pods on node %q block the drain:\n"), nodeInfo.Name)
+		w := printers.GetNewTabWriter(o.promptOut)
+		fmt.Fprintln(w, "NAMESPACE\tNAME\tREASON")
+		for _, b := range blocking {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", b.pod.Namespace, b.pod.Name, b.reason)
+		}
+		w.Flush()
+	}
+	for _, b := range blocking {
+		blocked[b.pod.Namespace+"/"+b.pod.Name] = true
+		action, err := o.promptPodAction(b)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case actionEvict:
+			evict = append(evict, b.pod)
+		case actionDelete:
+			remove = append(remove, b.pod)
+		case actionSkip:
+			if o.report != nil {
+				o.report.addPod(&b.pod, podActionSkipped, "skipped by user: "+b.reason, nil)
+			}
+		case actionAbort:
+			return fmt.Errorf("drain of node %q aborted", nodeInfo.Name)
+		}
+	}
+	for _, pod := range list.Pods() {
+		if !blocked[pod.Namespace+"/"+pod.Name] {
+			evict = append(evict, pod)
+		}
+	}
+
+	if o.drainer.DryRunStrategy == cmdutil.DryRunClient {
+		o.printDryRun(evict, podActionEvicted)
+		o.printDryRun(remove, podActionDeleted)
+		return nil
+	}
+
+	var errors []error
+	if err := o.drainer.DeleteOrEvictPods(evict); err != nil {
+		errors = append(errors, err)
+	}
+	if len(remove) > 0 {
+		deleter := *o.drainer
+		deleter.DisableEviction = true
+		if err := deleter.DeleteOrEvictPods(remove); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return utilerrors.NewAggregate(errors)
+}
+
+// blockingPods returns the pods the filters refuse to delete and the pods
+// whose disruption budget currently allows no evictions.
+func (o *DrainCmdOptions) blockingPods(list *drain.PodDeleteList) ([]blockingPod, error) {
+	var blocking []blockingPod
+	for _, item := range list.Items() {
+		if item.Status.Reason == drain.PodDeleteStatusTypeError {
+			blocking = append(blocking, blockingPod{pod: item.Pod, reason: item.Status.Message})
+		}
+	}
+	if o.drainer.DisableEviction {
+		return blocking, nil
+	}
+
+	pods := list.Pods()
+	budgets, err := o.drainer.GetBlockingDisruptionBudgets(pods)
+	if err != nil {
+		// eviction still honors the budgets, they just can't be shown upfront
+		fmt.Fprintf(o.ErrOut, "warning: unable to check PodDisruptionBudgets: %v\n", err)
+		return blocking, nil
+	}
+	for _, pod := range pods {
+		if pdb, ok := budgets[pod.Namespace+"/"+pod.Name]; ok {
+			blocking = append(blocking, blockingPod{
+				pod:       pod,
+				reason:    fmt.Sprintf("PodDisruptionBudget %s allows no disruptions", pdb),
+				evictable: true,
+			})
+		}
+	}
+	return blocking, nil
+}
+
+// promptPodAction asks the user what to do with a blocking pod until a
+// valid answer is given.
+func (o *DrainCmdOptions) promptPodAction(b blockingPod) (podAction, error) {
+	choices := []podAction{actionEvict, actionSkip, actionAbort}
+	prompt := i18n.T("[e]vict, [s]kip or [a]bort")
+	if b.evictable {
+		choices = []podAction{actionEvict, actionDelete, actionSkip, actionAbort}
+		prompt = i18n.T("[e]vict when the budget allows, [d]elete bypassing the budget, [s]kip or [a]bort")
+	}
+	for {
+		fmt.Fprintf(o.promptOut, "pod %s/%s: %s? ", b.pod.Namespace, b.pod.Name, prompt)
+		var input string
+		if _, err := fmt.Fscan(o.In, &input); err != nil {
+			return "", fmt.Errorf("no action given for pod %s/%s: %v", b.pod.Namespace, b.pod.Name, err)
+		}
+		answer := podAction(strings.ToLower(strings.TrimSpace(input)))
+		for _, choice := range choices {
+			if answer == choice || string(answer) == actionNames[choice] {
+				return choice, nil
+			}
+		}
+	}
+}
+
+var actionNames = map[podAction]string{
+	actionEvict:  "evict",
+	actionDelete: "delete",
+	actionSkip:   "skip",
+	actionAbort:  "abort",
+}
+
+// printDryRun prints and records the pods a client side dry run would
+// evict or delete.
+func (o *DrainCmdOptions) printDryRun(pods []corev1.Pod, action string) {
+	verb := "evicting"
+	if action == podActionDeleted {
+		verb = "deleting"
+	}
+	for i := range pods {
+		fmt.Fprintf(o.Out, "%s pod %s/%s (dry run)\n", verb, pods[i].Namespace, pods[i].Name)
+		if o.report != nil {
+			o.report.addPod(&pods[i], action, "", nil)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	utilpointer "k8s.io/utils/pointer"
+)
+
+func interactiveTestPod(name string, controlled bool, labels map[string]string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+		Spec:       corev1.PodSpec{NodeName: "node"},
+	}
+	if controlled {
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1",
+			Kind:       "ReplicaSet",
+			Name:       "rs",
+			Controller: utilpointer.Bool(true),
+		}}
+	}
+	return pod
+}
+
+func newInteractiveTestOptions(t *testing.T, input string, objects ...runtime.Object) (*DrainCmdOptions, *fake.Clientset) {
+	t.Helper()
+	streams, in, out, _ := genericiooptions.NewTestIOStreams()
+	in.WriteString(input)
+	client := fake.NewSimpleClientset(objects...)
+	// without the eviction subresource pods are deleted
+	client.Resources = []*metav1.APIResourceList{{GroupVersion: "v1"}}
+	// the fake client ignores field selectors, filter pods by node
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		restrictions := action.(k8stesting.ListAction).GetListRestrictions()
+		obj, err := client.Tracker().List(corev1.SchemeGroupVersion.WithResource("pods"), corev1.SchemeGroupVersion.WithKind("Pod"), action.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		list := obj.(*corev1.PodList)
+		filtered := &corev1.PodList{}
+		for _, pod := range list.Items {
+			if restrictions.Fields.Matches(fields.Set{"spec.nodeName": pod.Spec.NodeName}) {
+				filtered.Items = append(filtered.Items, pod)
+			}
+		}
+		return true, filtered, nil
+	})
+
+	o := NewDrainCmdOptions(nil, streams)
+	o.Interactive = true
+	o.Output = "json"
+	o.drainer.Client = client
+	o.drainer.Out = io.Discard
+	o.report = &drainReport{}
+	o.reportOut = out
+	o.promptOut = streams.ErrOut
+	o.Out = io.Discard
+	o.WarningPrinter = printers.NewWarningPrinter(streams.ErrOut, printers.WarningPrinterOptions{})
+	o.ToPrinter = func(string) (printers.ResourcePrinterFunc, error) {
+		return func(runtime.Object, io.Writer) error { return nil }, nil
+	}
+	return o, client
+}
+
+func TestDrainInteractive(t *testing.T) {
+	budget := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+	}
+	o, client := newInteractiveTestOptions(t, "skip\nd\n",
+		budget,
+		interactiveTestPod("api", true, nil),
+		interactiveTestPod("lonely", false, nil),
+		interactiveTestPod("web", true, map[string]string{"app": "web"}),
+	)
+
+	nodeInfo := &resource.Info{Name: "node"}
+	err := o.deleteOrEvictPodsInteractive(nodeInfo)
+	o.report.setResult("node", err)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pods, err := client.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 1 || pods.Items[0].Name != "lonely" {
+		t.Errorf("expected only the skipped pod to remain, got %v", pods.Items)
+	}
+
+	if err := o.report.print(o); err != nil {
+		t.Fatal(err)
+	}
+	report := &drainReport{}
+	if err := json.Unmarshal(o.reportOut.(*bytes.Buffer).Bytes(), report); err != nil {
+		t.Fatalf("invalid report: %v", err)
+	}
+	if len(report.Nodes) != 1 || !report.Nodes[0].Drained {
+		t.Fatalf("expected a drained node, got %#v", report.Nodes)
+	}
+	got := report.Nodes[0].Pods
+	sort.Slice(got, func(i, j int) bool { return got[i].Name < got[j].Name })
+	expected := []podReport{
+		{Namespace: "default", Name: "api", Action: podActionDeleted},
+		{Namespace: "default", Name: "lonely", Action: podActionSkipped, Reason: "skipped by user: " + unmanagedFatalMessage},
+		{Namespace: "default", Name: "web", Action: podActionDeleted},
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected report (-want +got):\n%s", diff)
+	}
+}
+
+// unmanagedFatalMessage is the filter message for pods without a controller.
+const unmanagedFatalMessage = "cannot delete Pods that declare no controller (use --force to override)"
+
+func TestDrainInteractiveAbort(t *testing.T) {
+	o, client := newInteractiveTestOptions(t, "maybe\nabort\n",
+		interactiveTestPod("api", true, nil),
+		interactiveTestPod("lonely", false, nil),
+	)
+
+	err := o.deleteOrEvictPodsInteractive(&resource.Info{Name: "node"})
+	if err == nil || !strings.Contains(err.Error(), "aborted") {
+		t.Fatalf("expected the drain to be aborted, got %v", err)
+	}
+	pods, err := client.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 2 {
+		t.Errorf("expected no pods to be removed, got %d pods", len(pods.Items))
+	}
+}
+
+func TestDrainInteractiveNoInput(t *testing.T) {
+	o, _ := newInteractiveTestOptions(t, "", interactiveTestPod("lonely", false, nil))
+
+	err := o.deleteOrEvictPodsInteractive(&resource.Info{Name: "node"})
+	if err == nil || !strings.Contains(err.Error(), "no action given for pod default/lonely") {
+		t.Fatalf("expected a missing answer error, got %v", err)
+	}
+}
+
+func TestDrainReportFiltered(t *testing.T) {
+	o, _ := newInteractiveTestOptions(t, "", interactiveTestPod("lonely", false, nil))
+	o.Interactive = false
+
+	err := o.deleteOrEvictPodsSimple(&resource.Info{Name: "node"})
+	if err == nil {
+		t.Fatal("expected the drain to fail")
+	}
+	o.report.setResult("node", err)
+	node := o.report.Nodes[0]
+	if node.Drained || len(node.Error) == 0 {
+		t.Errorf("expected a failed node, got %#v", node)
+	}
+	expected := []podReport{{Namespace: "default", Name: "lonely", Action: podActionBlocked, Reason: unmanagedFatalMessage}}
+	if diff := cmp.Diff(expected, node.Pods); diff != "" {
+		t.Errorf("unexpected report (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+)
+
+// drainPace limits how many node drains are started per interval.
+type drainPace struct {
+	count    int
+	interval time.Duration
+}
+
+func parsePace(value string) (*drainPace, error) {
+	count, interval, ok := strings.Cut(value, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid --pace %q, expected COUNT/DURATION such as 1/5m", value)
+	}
+	pace := &drainPace{}
+	var err error
+	if pace.count, err = strconv.Atoi(count); err != nil || pace.count <= 0 {
+		return nil, fmt.Errorf("invalid --pace %q, the count must be a positive integer", value)
+	}
+	if pace.interval, err = time.ParseDuration(interval); err != nil || pace.interval <= 0 {
+		return nil, fmt.Errorf("invalid --pace %q, the duration must be positive", value)
+	}
+	return pace, nil
+}
+
+// wait returns how long to wait before another drain may be started, given
+// the start times of the previous drains.
+func (p *drainPace) wait(starts []time.Time, now time.Time) time.Duration {
+	if p == nil || len(starts) < p.count {
+		return 0
+	}
+	if d := starts[len(starts)-p.count].Add(p.interval).Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// drainResult is the outcome of draining a node in parallel.
+type drainResult struct {
+	info *resource.Info
+	err  error
+}
+
+// runParallelDrain drains up to MaxUnavailableNodes nodes at once, starting
+// them no faster than the pace allows. A node is cordoned when its drain
+// starts. Nodes whose pods share a PodDisruptionBudget with a node being
+// drained wait for that drain to finish, so that budgets are not exhausted
+// by concurrent drains. It returns the nodes that could not be drained.
+func (o *DrainCmdOptions) runParallelDrain() ([]string, []error) {
+	maxUnavailable := o.MaxUnavailableNodes
+	if maxUnavailable == 0 {
+		maxUnavailable = 1
+	}
+	o.Out = &lockedWriter{w: o.Out}
+	o.ErrOut = &lockedWriter{w: o.ErrOut}
+	o.drainer.Out = &lockedWriter{w: o.drainer.Out}
+	o.drainer.ErrOut = o.ErrOut
+
+	pending := append([]*resource.Info{}, o.nodeInfos...)
+	running := map[string]sets.Set[string]{}
+	done := make(chan drainResult)
+	var starts []time.Time
+	// the budgets of the pending nodes are listed once per scheduling round,
+	// until a drain finishes and pods have moved
+	var budgets map[string]sets.Set[string]
+	var fatal []error
+	remainingNodes := []string{}
+
+	for len(pending) > 0 || len(running) > 0 {
+		var paced <-chan time.Time
+		if len(pending) > 0 && len(running) < maxUnavailable {
+			if d := o.pace.wait(starts, time.Now()); d > 0 {
+				klog.V(2).Infof("waiting %v before starting the next drain", d)
+				paced = time.After(d)
+			} else {
+				if budgets == nil {
+					budgets = o.nodeBudgets(pending)
+				}
+				if i := nextNode(pending, budgets, running); i >= 0 {
+					info := pending[i]
+					pending = append(pending[:i], pending[i+1:]...)
+					running[info.Name] = budgets[info.Name]
+					starts = append(starts, time.Now())
+					go func() {
+						// a node that cannot be cordoned is not drained, as
+						// its pods could be scheduled on it again
+						if err := o.cordonOrUncordonNode(info, true); err != nil {
+							done <- drainResult{info: info, err: fmt.Errorf("unable to cordon node %q: %v", info.Name, err)}
+							return
+						}
+						done <- drainResult{info: info, err: o.drainNode(info)}
+					}()
+					continue
+				}
+			}
+		}
+
+		select {
+		case result := <-done:
+			delete(running, result.info.Name)
+			budgets = nil
+			if result.err != nil {
+				fatal = append(fatal, result.err)
+				remainingNodes = append(remainingNodes, result.info.Name)
+			}
+		case <-paced:
+		}
+	}
+	return remainingNodes, fatal
+}
+
+// nodeBudgets returns the PodDisruptionBudgets selecting the pods of each of
+// the nodes, listing the budgets of a namespace once for all the nodes.
+func (o *DrainCmdOptions) nodeBudgets(nodes []*resource.Info) map[string]sets.Set[string] {
+	budgets := map[string]sets.Set[string]{}
+	var pods []corev1.Pod
+	for _, info := range nodes {
+		budgets[info.Name] = sets.New[string]()
+		if list, _ := o.drainer.GetPodsForDeletion(info.Name); list != nil {
+			pods = append(pods, list.Pods()...)
+		}
+	}
+	podBudgets, err := o.drainer.GetDisruptionBudgets(pods)
+	if err != nil {
+		klog.V(2).Infof("unable to get the PodDisruptionBudgets of the nodes: %v", err)
+		return budgets
+	}
+	for _, pod := range pods {
+		if nodeBudgets, ok := budgets[pod.Spec.NodeName]; ok {
+			nodeBudgets.Insert(sets.List(podBudgets[pod.Namespace+"/"+pod.Name])...)
+		}
+	}
+	return budgets
+}
+
+// nextNode returns the index of the first pending node whose pods share no
+// PodDisruptionBudget with the running drains, or -1 if every pending node
+// has to wait.
+func nextNode(pending []*resource.Info, budgets map[string]sets.Set[string], running map[string]sets.Set[string]) int {
+	inUse := sets.New[string]()
+	for _, nodeBudgets := range running {
+		inUse = inUse.Union(nodeBudgets)
+	}
+	for i, info := range pending {
+		if !budgets[info.Name].HasAny(sets.List(inUse)...) {
+			return i
+		}
+		klog.V(2).Infof("delaying the drain of node %s, it shares PodDisruptionBudgets %v with a node being drained", info.Name, sets.List(budgets[info.Name].Intersection(inUse)))
+	}
+	return -1
+}
+
+// lockedWriter serializes the output of nodes drained in parallel.
+type lockedWriter struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.w.Write(p)
+}
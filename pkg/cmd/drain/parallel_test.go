@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/resource"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestParsePace(t *testing.T) {
+	pace, err := parsePace("2/5m")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pace.count != 2 || pace.interval != 5*time.Minute {
+		t.Errorf("unexpected pace %#v", pace)
+	}
+	for _, value := range []string{"2", "0/5m", "x/5m", "1/0s", "1/soon"} {
+		if _, err := parsePace(value); err == nil {
+			t.Errorf("expected %q to be invalid", value)
+		}
+	}
+}
+
+func TestPaceWait(t *testing.T) {
+	now := time.Now()
+	pace := &drainPace{count: 2, interval: time.Minute}
+	tests := []struct {
+		name     string
+		starts   []time.Time
+		expected time.Duration
+	}{
+		{name: "no drains", expected: 0},
+		{name: "below the limit", starts: []time.Time{now}, expected: 0},
+		{name: "at the limit", starts: []time.Time{now.Add(-20 * time.Second), now}, expected: 40 * time.Second},
+		{name: "interval elapsed", starts: []time.Time{now.Add(-2 * time.Minute), now.Add(-time.Minute), now.Add(-10 * time.Second)}, expected: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := pace.wait(test.starts, now); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+	var unlimited *drainPace
+	if got := unlimited.wait([]time.Time{now, now, now}, now); got != 0 {
+		t.Errorf("expected no wait without a pace, got %v", got)
+	}
+}
+
+func parallelTestNode(name string) *resource.Info {
+	return &resource.Info{
+		Name:    name,
+		Object:  &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}},
+		Mapping: &meta.RESTMapping{GroupVersionKind: corev1.SchemeGroupVersion.WithKind("Node")},
+	}
+}
+
+func parallelTestPod(name, node string, labels map[string]string) *corev1.Pod {
+	pod := interactiveTestPod(name, true, labels)
+	pod.Spec.NodeName = node
+	return pod
+}
+
+func TestNextNode(t *testing.T) {
+	budget := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+		Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 1},
+	}
+	o, _ := newInteractiveTestOptions(t, "", budget,
+		parallelTestPod("web-b", "node-b", map[string]string{"app": "web"}),
+		parallelTestPod("api-c", "node-c", nil),
+	)
+
+	pending := []*resource.Info{parallelTestNode("node-b"), parallelTestNode("node-c")}
+	budgets := o.nodeBudgets(pending)
+	if !budgets["node-b"].Equal(sets.New("default/web")) || budgets["node-c"].Len() != 0 {
+		t.Fatalf("unexpected budgets %v", budgets)
+	}
+
+	running := map[string]sets.Set[string]{"node-a": sets.New("default/web")}
+	if i := nextNode(pending, budgets, running); i != 1 {
+		t.Errorf("expected node-c to be drained next, got %d", i)
+	}
+	if i := nextNode(pending[:1], budgets, running); i != -1 {
+		t.Errorf("expected node-b to wait for node-a, got %d", i)
+	}
+	if i := nextNode(pending, budgets, map[string]sets.Set[string]{}); i != 0 {
+		t.Errorf("expected node-b to be drained first, got %d", i)
+	}
+}
+
+func TestRunParallelDrain(t *testing.T) {
+	o, client := newInteractiveTestOptions(t, "",
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-c"}},
+		parallelTestPod("pod-a", "node-a", nil),
+		parallelTestPod("pod-b", "node-b", nil),
+		parallelTestPod("pod-c", "node-c", nil),
+	)
+	o.Interactive = false
+	o.MaxUnavailableNodes = 2
+	o.nodeInfos = []*resource.Info{parallelTestNode("node-a"), parallelTestNode("node-b"), parallelTestNode("node-c")}
+
+	remaining, fatal := o.runParallelDrain()
+	if len(remaining) != 0 || len(fatal) != 0 {
+		t.Fatalf("unexpected failures %v: %v", remaining, fatal)
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range nodes.Items {
+		if !node.Spec.Unschedulable {
+			t.Errorf("expected node %s to be cordoned", node.Name)
+		}
+	}
+	pods, err := client.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 0 {
+		t.Errorf("expected all pods to be removed, got %d", len(pods.Items))
+	}
+	if len(o.report.Nodes) != 3 {
+		t.Errorf("expected a report for all nodes, got %d", len(o.report.Nodes))
+	}
+	for _, node := range o.report.Nodes {
+		if !node.Drained || !node.Cordoned {
+			t.Errorf("expected node %s to be cordoned and drained, got %#v", node.Name, node)
+		}
+	}
+}
+
+func TestRunParallelDrainCordonFailure(t *testing.T) {
+	o, client := newInteractiveTestOptions(t, "",
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-b"}},
+		parallelTestPod("pod-a", "node-a", nil),
+		parallelTestPod("pod-b", "node-b", nil),
+	)
+	o.Interactive = false
+	o.MaxUnavailableNodes = 2
+	o.nodeInfos = []*resource.Info{parallelTestNode("node-a"), parallelTestNode("node-b")}
+	client.PrependReactor("patch", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.PatchAction).GetName() == "node-b" {
+			return true, nil, errors.New("forbidden")
+		}
+		return false, nil, nil
+	})
+	client.PrependReactor("update", "nodes", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.UpdateAction).GetObject().(*corev1.Node).Name == "node-b" {
+			return true, nil, errors.New("forbidden")
+		}
+		return false, nil, nil
+	})
+	pdbLists := 0
+	client.PrependReactor("list", "poddisruptionbudgets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pdbLists++
+		return false, nil, nil
+	})
+
+	remaining, fatal := o.runParallelDrain()
+	if len(remaining) != 1 || remaining[0] != "node-b" || len(fatal) != 1 || !strings.Contains(fatal[0].Error(), `unable to cordon node "node-b"`) {
+		t.Fatalf("expected node-b to fail to be cordoned, got %v: %v", remaining, fatal)
+	}
+	// both nodes are scheduled in the same round
+	if pdbLists != 1 {
+		t.Errorf("expected the PodDisruptionBudgets to be listed once, got %d", pdbLists)
+	}
+	if _, err := client.CoreV1().Pods("default").Get(context.Background(), "pod-b", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the pods of node-b not to be removed: %v", err)
+	}
+	if _, err := client.CoreV1().Pods("default").Get(context.Background(), "pod-a", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected the pods of node-a to be removed")
+	}
+}
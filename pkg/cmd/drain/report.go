@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/kubectl/pkg/drain"
+	"sigs.k8s.io/yaml"
+)
+
+// Actions recorded for pods in a drain report.
+const (
+	podActionEvicted        = "evicted"
+	podActionDeleted        = "deleted"
+	podActionEvictionFailed = "eviction failed"
+	podActionDeletionFailed = "deletion failed"
+	podActionSkipped        = "skipped"
+	podActionBlocked        = "blocked"
+)
+
+// drainReport is printed by drain with --output once all nodes are processed.
+type drainReport struct {
+	DryRun bool          `json:"dryRun,omitempty"`
+	Nodes  []*nodeReport `json:"nodes"`
+
+	lock sync.Mutex
+}
+
+// nodeReport is the outcome of draining a single node.
+type nodeReport struct {
+	Name     string      `json:"name"`
+	Cordoned bool        `json:"cordoned"`
+	Drained  bool        `json:"drained"`
+	Error    string      `json:"error,omitempty"`
+	Pods     []podReport `json:"pods,omitempty"`
+}
+
+// podReport is the action taken for a pod of a drained node.
+type podReport struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// node returns the report of the named node, adding it if needed. The lock
+// must be held.
+func (r *drainReport) node(name string) *nodeReport {
+	for _, node := range r.Nodes {
+		if node.Name == name {
+			return node
+		}
+	}
+	node := &nodeReport{Name: name}
+	r.Nodes = append(r.Nodes, node)
+	return node
+}
+
+// setCordoned records whether the node is cordoned.
+func (r *drainReport) setCordoned(name string, cordoned bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.node(name).Cordoned = cordoned
+}
+
+// setResult records the outcome of draining the node.
+func (r *drainReport) setResult(name string, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	node := r.node(name)
+	node.Drained = err == nil
+	if err != nil {
+		node.Error = err.Error()
+	}
+}
+
+// addPod records the action taken for pod. Evictions and deletions finish
+// concurrently, so this may be called from several goroutines.
+func (r *drainReport) addPod(pod *corev1.Pod, action, reason string, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	report := podReport{Namespace: pod.Namespace, Name: pod.Name, Action: action, Reason: reason}
+	if err != nil {
+		report.Error = err.Error()
+	}
+	node := r.node(pod.Spec.NodeName)
+	node.Pods = append(node.Pods, report)
+}
+
+// addFiltered records the pods drain does not evict, along with the reason
+// given by the filters.
+func (r *drainReport) addFiltered(list *drain.PodDeleteList) {
+	for _, item := range list.Items() {
+		if item.Status.Delete {
+			continue
+		}
+		action := podActionSkipped
+		if item.Status.Reason == drain.PodDeleteStatusTypeError {
+			action = podActionBlocked
+		}
+		pod := item.Pod
+		r.addPod(&pod, action, item.Status.Message, nil)
+	}
+}
+
+// print writes the report in the given format.
+func (r *drainReport) print(o *DrainCmdOptions) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var data []byte
+	var err error
+	switch o.Output {
+	case "json":
+		data, err = json.MarshalIndent(r, "", "    ")
+		data = append(data, '\n')
+	case "yaml":
+		data, err = yaml.Marshal(r)
+	default:
+		return fmt.Errorf("unsupported output format %q", o.Output)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = o.reportOut.Write(data)
+	return err
+}
@@ -48,6 +48,11 @@ var (
 
 		The default format is YAML. To edit in JSON, specify "-o json".
 
+		Before the changes are sent, the edited objects are checked against the OpenAPI schemas
+		of the server. Fields with the wrong type, missing required fields, unsupported enum values
+		and unknown fields reopen the editor with the errors listed in the header. With
+		--validate=warn the errors are printed as warnings instead, and --validate=ignore skips the check.
+
 		The flag --windows-line-endings can be used to force Windows line endings,
 		otherwise the default for your operating system will be used.
 
@@ -67,6 +72,9 @@ var (
 		# Edit the job 'myjob' in JSON using the v1 API format
 		kubectl edit job.v1.batch/myjob -o json
 
+		# Edit the deployment 'mydeployment', applying only the changed fields with server-side apply
+		kubectl edit deployment/mydeployment --ssa --field-manager=me
+
 		# Edit the deployment 'mydeployment' in YAML and save the modified config in its annotation
 		kubectl edit deployment/mydeployment -o yaml --save-config
 
@@ -102,6 +110,8 @@ func NewCmdEdit(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra.
 	cmd.Flags().BoolVar(&o.WindowsLineEndings, "windows-line-endings", o.WindowsLineEndings,
 		"Defaults to the line ending native to your platform.")
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-edit")
+	cmd.Flags().BoolVar(&o.ServerSideApply, "ssa", o.ServerSideApply, "If true, submit the edit as a server-side apply patch holding only the changed fields, owned by the --field-manager, instead of a patch of the whole object. Removing fields is not supported in this mode.")
+	cmd.Flags().BoolVar(&o.ForceConflicts, "force-conflicts", o.ForceConflicts, "If true, the changed fields are taken over from the managers they conflict with. Only valid with --ssa.")
 	cmdutil.AddApplyAnnotationVarFlags(cmd, &o.ApplyAnnotation)
 	cmdutil.AddSubresourceFlags(cmd, &o.Subresource, "If specified, edit will operate on the subresource of the requested object.", editor.SupportedSubresources...)
 	return cmd
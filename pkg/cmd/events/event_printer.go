@@ -19,14 +19,17 @@ package events
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/kubectl/pkg/util/color"
+	"k8s.io/kubectl/pkg/util/timeformat"
 )
 
 // EventPrinter stores required fields to be used for
@@ -34,10 +37,20 @@ import (
 type EventPrinter struct {
 	NoHeaders     bool
 	AllNamespaces bool
+	// GroupBy summarizes lists of events by reason or by object instead of
+	// printing each event.
+	GroupBy string
+	// Color highlights the type of the events by severity.
+	Color bool
 
 	headersPrinted bool
 }
 
+const (
+	groupByReason = "reason"
+	groupByObject = "object"
+)
+
 // PrintObj prints different type of event objects.
 func (ep *EventPrinter) PrintObj(obj runtime.Object, out io.Writer) error {
 	if !ep.NoHeaders && !ep.headersPrinted {
@@ -47,6 +60,10 @@ func (ep *EventPrinter) PrintObj(obj runtime.Object, out io.Writer) error {
 
 	switch t := obj.(type) {
 	case *corev1.EventList:
+		if len(ep.GroupBy) > 0 {
+			ep.printGroups(out, t.Items)
+			return nil
+		}
 		for _, e := range t.Items {
 			ep.printOneEvent(out, e)
 		}
@@ -60,6 +77,17 @@ func (ep *EventPrinter) PrintObj(obj runtime.Object, out io.Writer) error {
 }
 
 func (ep *EventPrinter) printHeadings(w io.Writer) {
+	switch ep.GroupBy {
+	case groupByReason:
+		fmt.Fprintf(w, "REASON\tCOUNT\tTYPES\tLAST SEEN\tOBJECTS\n")
+		return
+	case groupByObject:
+		if ep.AllNamespaces {
+			fmt.Fprintf(w, "NAMESPACE\t")
+		}
+		fmt.Fprintf(w, "OBJECT\tCOUNT\tTYPES\tLAST SEEN\tREASONS\n")
+		return
+	}
 	if ep.AllNamespaces {
 		fmt.Fprintf(w, "NAMESPACE\t")
 	}
@@ -73,7 +101,7 @@ func (ep *EventPrinter) printOneEvent(w io.Writer, e corev1.Event) {
 	}
 	fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%v\n",
 		interval,
-		printers.EscapeTerminal(e.Type),
+		ep.colorType(printers.EscapeTerminal(e.Type)),
 		printers.EscapeTerminal(e.Reason),
 		printers.EscapeTerminal(e.InvolvedObject.Kind),
 		printers.EscapeTerminal(e.InvolvedObject.Name),
@@ -81,6 +109,88 @@ func (ep *EventPrinter) printOneEvent(w io.Writer, e corev1.Event) {
 	)
 }
 
+// colorType colors the type of an event by severity.
+func (ep *EventPrinter) colorType(eventType string) string {
+	if !ep.Color {
+		return eventType
+	}
+	if eventType == corev1.EventTypeWarning {
+		return color.Colorize(color.Warning, eventType)
+	}
+	// the other types are as long, so that the columns stay aligned
+	return color.Colorize(color.Plain, eventType)
+}
+
+// eventGroup summarizes the events of a reason or an object.
+type eventGroup struct {
+	namespace string
+	key       string
+	count     int32
+	types     sets.Set[string]
+	members   sets.Set[string]
+	lastSeen  time.Time
+}
+
+// printGroups prints a line per reason or object, in the order of the last
+// time they were seen.
+func (ep *EventPrinter) printGroups(w io.Writer, events []corev1.Event) {
+	groups := map[string]*eventGroup{}
+	for _, e := range events {
+		object := e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name
+		key, member, namespace := e.Reason, object, ""
+		if ep.GroupBy == groupByObject {
+			key, member, namespace = object, e.Reason, e.Namespace
+		}
+		g, ok := groups[namespace+"/"+key]
+		if !ok {
+			g = &eventGroup{namespace: namespace, key: key, types: sets.New[string](), members: sets.New[string]()}
+			groups[namespace+"/"+key] = g
+		}
+		g.count += eventCount(e)
+		g.types.Insert(e.Type)
+		g.members.Insert(member)
+		if t := eventTime(e); t.After(g.lastSeen) {
+			g.lastSeen = t
+		}
+	}
+
+	sorted := make([]*eventGroup, 0, len(groups))
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].lastSeen.Equal(sorted[j].lastSeen) {
+			return sorted[i].lastSeen.Before(sorted[j].lastSeen)
+		}
+		return sorted[i].namespace+"/"+sorted[i].key < sorted[j].namespace+"/"+sorted[j].key
+	})
+
+	for _, g := range sorted {
+		if ep.GroupBy == groupByObject && ep.AllNamespaces {
+			fmt.Fprintf(w, "%v\t", g.namespace)
+		}
+		lastSeen := timeformat.Timestamp(g.lastSeen)
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
+			printers.EscapeTerminal(g.key),
+			g.count,
+			printers.EscapeTerminal(strings.Join(sets.List(g.types), ",")),
+			lastSeen,
+			printers.EscapeTerminal(strings.Join(sets.List(g.members), ",")),
+		)
+	}
+}
+
+// eventCount returns the number of occurrences of an event.
+func eventCount(e corev1.Event) int32 {
+	if e.Series != nil {
+		return e.Series.Count
+	}
+	if e.Count > 1 {
+		return e.Count
+	}
+	return 1
+}
+
 func getInterval(e corev1.Event) string {
 	var interval string
 	firstTimestampSince := translateMicroTimestampSince(e.EventTime)
@@ -98,24 +208,16 @@ func getInterval(e corev1.Event) string {
 	return interval
 }
 
-// translateMicroTimestampSince returns the elapsed time since timestamp in
-// human-readable approximation.
+// translateMicroTimestampSince returns the timestamp in the --time-format,
+// by default the elapsed time since timestamp in human-readable approximation.
 func translateMicroTimestampSince(timestamp metav1.MicroTime) string {
-	if timestamp.IsZero() {
-		return "<unknown>"
-	}
-
-	return duration.HumanDuration(time.Since(timestamp.Time))
+	return timeformat.Timestamp(timestamp.Time)
 }
 
-// translateTimestampSince returns the elapsed time since timestamp in
-// human-readable approximation.
+// translateTimestampSince returns the timestamp in the --time-format, by
+// default the elapsed time since timestamp in human-readable approximation.
 func translateTimestampSince(timestamp metav1.Time) string {
-	if timestamp.IsZero() {
-		return "<unknown>"
-	}
-
-	return duration.HumanDuration(time.Since(timestamp.Time))
+	return timeformat.Timestamp(timestamp.Time)
 }
 
 func NewEventPrinter(noHeader, allNamespaces bool) *EventPrinter {
@@ -244,6 +244,32 @@ foo	12m (x3 over 20m)	Normal	ScalingReplicaSet	Deployment/bar	Scaled up replica
 60s (x3 over 20m)	test^[	test^[	Deployment/bar^[	^[
 `,
 		},
+		{
+			printer: EventPrinter{
+				NoHeaders: true,
+				Color:     true,
+			},
+			obj: &corev1.EventList{
+				Items: []corev1.Event{
+					{
+						InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+						Type:           corev1.EventTypeWarning,
+						Reason:         "BackOff",
+						Message:        "Back-off restarting failed container",
+						EventTime:      metav1.NewMicroTime(time.Now().Add(-5 * time.Minute)),
+					},
+					{
+						InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+						Type:           corev1.EventTypeNormal,
+						Reason:         "Pulled",
+						Message:        "Container image already present on machine",
+						EventTime:      metav1.NewMicroTime(time.Now().Add(-5 * time.Minute)),
+					},
+				},
+			},
+			expected: "5m\t\x1b[33mWarning\x1b[0m\tBackOff\tPod/web\tBack-off restarting failed container\n" +
+				"5m\t\x1b[39mNormal\x1b[0m\tPulled\tPod/web\tContainer image already present on machine\n",
+		},
 	}
 
 	for _, test := range tests {
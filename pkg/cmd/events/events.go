@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -42,6 +43,7 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	watchtools "k8s.io/client-go/tools/watch"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/color"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/interrupt"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -53,7 +55,11 @@ var (
 
 		Prints a table of the most important information about events.
 		You can request events for a namespace, for all namespace, or
-		filtered to only those pertaining to a specified resource.`))
+		filtered to only those pertaining to a specified resource.
+
+		The filters on the object, the kind and a single type are applied by the
+		server. The filters on the message and the age of the events are applied
+		by kubectl. Warnings are highlighted when the output is a terminal.`))
 
 	eventsExample = templates.Examples(i18n.T(`
 	# List recent events in the default namespace
@@ -69,7 +75,13 @@ var (
 	kubectl events -oyaml
 
 	# List recent only events of type 'Warning' or 'Normal'
-	kubectl events --types=Warning,Normal`))
+	kubectl events --types=Warning,Normal
+
+	# List the warnings of the last 30 minutes about pods whose message mentions an image
+	kubectl events --for-kind=Pod --types=Warning --since=30m --grep=image
+
+	# Count the events of the namespace by reason
+	kubectl events --group-by=reason`))
 )
 
 // EventsFlags directly reflect the information that CLI is gathering via flags.  They will be converted to Options, which
@@ -83,7 +95,11 @@ type EventsFlags struct {
 	Watch         bool
 	NoHeaders     bool
 	ForObject     string
+	ForKind       string
 	FilterTypes   []string
+	Grep          string
+	GroupBy       string
+	Since         time.Duration
 	ChunkSize     int64
 	genericiooptions.IOStreams
 }
@@ -105,9 +121,13 @@ type EventsOptions struct {
 	AllNamespaces bool
 	Watch         bool
 	FilterTypes   []string
+	GroupBy       string
+	Since         time.Duration
 
 	forGVK  schema.GroupVersionKind
 	forName string
+	forKind string
+	grep    *regexp.Regexp
 
 	client *kubernetes.Clientset
 
@@ -121,7 +141,7 @@ func NewCmdEvents(restClientGetter genericclioptions.RESTClientGetter, streams g
 	flags := NewEventsFlags(restClientGetter, streams)
 
 	cmd := &cobra.Command{
-		Use:                   fmt.Sprintf("events [(-o|--output=)%s] [--for TYPE/NAME] [--watch] [--types=Normal,Warning]", strings.Join(flags.PrintFlags.AllowedFormats(), "|")),
+		Use:                   fmt.Sprintf("events [(-o|--output=)%s] [--for TYPE/NAME | --for-kind TYPE] [--watch] [--types=Normal,Warning] [--grep=REGEX] [--since=DURATION] [--group-by=reason|object]", strings.Join(flags.PrintFlags.AllowedFormats(), "|")),
 		DisableFlagsInUseLine: true,
 		Short:                 i18n.T("List events"),
 		Long:                  eventsLong,
@@ -143,7 +163,11 @@ func (flags *EventsFlags) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&flags.Watch, "watch", "w", flags.Watch, "After listing the requested events, watch for more events.")
 	cmd.Flags().BoolVarP(&flags.AllNamespaces, "all-namespaces", "A", flags.AllNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
 	cmd.Flags().StringVar(&flags.ForObject, "for", flags.ForObject, "Filter events to only those pertaining to the specified resource.")
+	cmd.Flags().StringVar(&flags.ForKind, "for-kind", flags.ForKind, "Filter events to only those pertaining to resources of the specified type.")
 	cmd.Flags().StringSliceVar(&flags.FilterTypes, "types", flags.FilterTypes, "Output only events of given types.")
+	cmd.Flags().StringVar(&flags.Grep, "grep", flags.Grep, "Output only events whose message matches the given regular expression.")
+	cmd.Flags().StringVar(&flags.GroupBy, "group-by", flags.GroupBy, "Summarize the events by reason or by object instead of listing them. One of: reason, object.")
+	cmd.Flags().DurationVar(&flags.Since, "since", flags.Since, "Output only events last seen within the given duration, like 30m or 2h.")
 	cmd.Flags().BoolVar(&flags.NoHeaders, "no-headers", flags.NoHeaders, "When using the default output format, don't print headers.")
 	cmdutil.AddChunkSizeFlag(cmd, &flags.ChunkSize)
 }
@@ -154,6 +178,8 @@ func (flags *EventsFlags) ToOptions() (*EventsOptions, error) {
 		AllNamespaces: flags.AllNamespaces,
 		Watch:         flags.Watch,
 		FilterTypes:   flags.FilterTypes,
+		GroupBy:       flags.GroupBy,
+		Since:         flags.Since,
 		IOStreams:     flags.IOStreams,
 	}
 	var err error
@@ -177,6 +203,37 @@ func (flags *EventsFlags) ToOptions() (*EventsOptions, error) {
 		}
 	}
 
+	if flags.ForKind != "" {
+		if flags.ForObject != "" {
+			return nil, fmt.Errorf("--for and --for-kind can not be used together")
+		}
+		mapper, err := flags.RESTClientGetter.ToRESTMapper()
+		if err != nil {
+			return nil, err
+		}
+		fullySpecifiedGVR, groupResource := schema.ParseResourceArg(strings.ToLower(flags.ForKind))
+		gvr := schema.GroupVersionResource{}
+		if fullySpecifiedGVR != nil {
+			gvr, _ = mapper.ResourceFor(*fullySpecifiedGVR)
+		}
+		if gvr.Empty() {
+			if gvr, err = mapper.ResourceFor(groupResource.WithVersion("")); err != nil {
+				return nil, err
+			}
+		}
+		gvk, err := mapper.KindFor(gvr)
+		if err != nil {
+			return nil, err
+		}
+		o.forKind = gvk.Kind
+	}
+
+	if flags.Grep != "" {
+		if o.grep, err = regexp.Compile(flags.Grep); err != nil {
+			return nil, fmt.Errorf("invalid --grep %q: %v", flags.Grep, err)
+		}
+	}
+
 	clientConfig, err := flags.RESTClientGetter.ToRESTConfig()
 	if err != nil {
 		return nil, err
@@ -193,12 +250,18 @@ func (flags *EventsFlags) ToOptions() (*EventsOptions, error) {
 
 	var printer printers.ResourcePrinter
 	if flags.PrintFlags.OutputFormat != nil && len(*flags.PrintFlags.OutputFormat) > 0 {
+		if flags.GroupBy != "" {
+			return nil, fmt.Errorf("--group-by can not be used with --output")
+		}
 		printer, err = flags.PrintFlags.ToPrinter()
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		printer = NewEventPrinter(flags.NoHeaders, flags.AllNamespaces)
+		eventPrinter := NewEventPrinter(flags.NoHeaders, flags.AllNamespaces)
+		eventPrinter.GroupBy = flags.GroupBy
+		eventPrinter.Color = color.Enabled(flags.Out)
+		printer = eventPrinter
 	}
 
 	o.PrintObj = func(object runtime.Object, writer io.Writer) error {
@@ -214,6 +277,17 @@ func (o *EventsOptions) Validate() error {
 			return fmt.Errorf("valid --types are Normal or Warning")
 		}
 	}
+	switch o.GroupBy {
+	case "", groupByReason, groupByObject:
+	default:
+		return fmt.Errorf("valid --group-by are reason or object")
+	}
+	if o.GroupBy != "" && o.Watch {
+		return fmt.Errorf("--group-by can not be used with --watch")
+	}
+	if o.Since < 0 {
+		return fmt.Errorf("--since must not be negative")
+	}
 
 	return nil
 }
@@ -226,11 +300,27 @@ func (o *EventsOptions) Run() error {
 		namespace = ""
 	}
 	listOptions := metav1.ListOptions{Limit: cmdutil.DefaultChunkSize}
+	// The filters supported by the field selectors of events are applied by
+	// the server, the others are applied as the events are received.
+	selectors := []fields.Selector{}
 	if o.forName != "" {
-		listOptions.FieldSelector = fields.AndSelectors(
+		selectors = append(selectors,
 			fields.OneTermEqualSelector("involvedObject.kind", o.forGVK.Kind),
 			fields.OneTermEqualSelector("involvedObject.apiVersion", o.forGVK.GroupVersion().String()),
-			fields.OneTermEqualSelector("involvedObject.name", o.forName)).String()
+			fields.OneTermEqualSelector("involvedObject.name", o.forName))
+	}
+	if o.forKind != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("involvedObject.kind", o.forKind))
+	}
+	if len(o.FilterTypes) == 1 {
+		eventType := corev1.EventTypeNormal
+		if strings.EqualFold(o.FilterTypes[0], corev1.EventTypeWarning) {
+			eventType = corev1.EventTypeWarning
+		}
+		selectors = append(selectors, fields.OneTermEqualSelector("type", eventType))
+	}
+	if len(selectors) > 0 {
+		listOptions.FieldSelector = fields.AndSelectors(selectors...).String()
 	}
 	if o.Watch {
 		return o.runWatch(ctx, namespace, listOptions)
@@ -259,7 +349,7 @@ func (o *EventsOptions) Run() error {
 
 	var filteredEvents []corev1.Event
 	for _, e := range el.Items {
-		if !o.filteredEventType(e.Type) {
+		if !o.filteredEvent(e) {
 			continue
 		}
 		if e.GetObjectKind().GroupVersionKind().Empty() {
@@ -307,7 +397,7 @@ func (o *EventsOptions) runWatch(ctx context.Context, namespace string, listOpti
 				return false, nil
 			}
 
-			if ev, ok := e.Object.(*corev1.Event); !ok || !o.filteredEventType(ev.Type) {
+			if ev, ok := e.Object.(*corev1.Event); !ok || !o.filteredEvent(*ev) {
 				return false, nil
 			}
 
@@ -328,6 +418,22 @@ func (o *EventsOptions) runWatch(ctx context.Context, namespace string, listOpti
 	return nil
 }
 
+// filteredEvent checks the type, the message and the time of the event
+// against the filters given by the user.
+func (o *EventsOptions) filteredEvent(e corev1.Event) bool {
+	if !o.filteredEventType(e.Type) {
+		return false
+	}
+	if o.grep != nil && !o.grep.MatchString(e.Message) {
+		return false
+	}
+	// events whose time is unknown are kept
+	if t := eventTime(e); o.Since > 0 && !t.IsZero() && time.Since(t) > o.Since {
+		return false
+	}
+	return true
+}
+
 // filteredEventType checks given event can be printed
 // by comparing it in filtered event flag.
 // If --event flag is not set by user, this function allows
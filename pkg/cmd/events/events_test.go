@@ -19,6 +19,7 @@ package events
 import (
 	"io"
 	"net/http"
+	"regexp"
 	"testing"
 	"time"
 
@@ -217,3 +218,149 @@ foo         18m (x3 over 28m)   Warning   ScalingReplicaSet   Deployment/bar   S
 		t.Errorf("expected\n%v\ngot\n%v", e, a)
 	}
 }
+
+func TestEventFieldSelector(t *testing.T) {
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	clientset, err := kubernetes.NewForConfig(cmdtesting.DefaultClientConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fieldSelector string
+	clientset.CoreV1().RESTClient().(*restclient.RESTClient).Client = fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+		fieldSelector = req.URL.Query().Get("fieldSelector")
+		return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, getFakeEvents())}, nil
+	})
+
+	printer := NewEventPrinter(false, true)
+	options := &EventsOptions{
+		AllNamespaces: true,
+		client:        clientset,
+		FilterTypes:   []string{"warning"},
+		forKind:       "Deployment",
+		PrintObj: func(object runtime.Object, writer io.Writer) error {
+			return printer.PrintObj(object, writer)
+		},
+		IOStreams: streams,
+	}
+
+	if err := options.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "involvedObject.kind=Deployment,type=Warning", fieldSelector; e != a {
+		t.Errorf("expected field selector %q, got %q", e, a)
+	}
+}
+
+func TestEventGrepAndSince(t *testing.T) {
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	clientset, err := kubernetes.NewForConfig(cmdtesting.DefaultClientConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := getFakeEvents()
+	events.Items[1].Message = "Scaled down replica set bar-001 to 0"
+	clientset.CoreV1().RESTClient().(*restclient.RESTClient).Client = fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, events)}, nil
+	})
+
+	printer := NewEventPrinter(false, true)
+	options := &EventsOptions{
+		AllNamespaces: true,
+		client:        clientset,
+		Since:         19 * time.Minute,
+		grep:          regexp.MustCompile("Scaled (up|down)"),
+		PrintObj: func(object runtime.Object, writer io.Writer) error {
+			return printer.PrintObj(object, writer)
+		},
+		IOStreams: streams,
+	}
+
+	if err := options.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `NAMESPACE   LAST SEEN           TYPE      REASON              OBJECT           MESSAGE
+foo         18m (x3 over 28m)   Warning   ScalingReplicaSet   Deployment/bar   Scaled down replica set bar-001 to 0
+otherfoo    15m (x3 over 25m)   Normal    ScalingReplicaSet   Deployment/bar   Scaled up replica set bar-002 to 1
+`
+	if e, a := expected, buf.String(); e != a {
+		t.Errorf("expected\n%v\ngot\n%v", e, a)
+	}
+
+	buf.Reset()
+	printer = NewEventPrinter(false, true)
+	options.grep = regexp.MustCompile("down")
+	if err := options.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected = `NAMESPACE   LAST SEEN           TYPE      REASON              OBJECT           MESSAGE
+foo         18m (x3 over 28m)   Warning   ScalingReplicaSet   Deployment/bar   Scaled down replica set bar-001 to 0
+`
+	if e, a := expected, buf.String(); e != a {
+		t.Errorf("expected\n%v\ngot\n%v", e, a)
+	}
+}
+
+func TestEventGroupBy(t *testing.T) {
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	clientset, err := kubernetes.NewForConfig(cmdtesting.DefaultClientConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := getFakeEvents()
+	events.Items[1].Reason = "FailedCreate"
+	clientset.CoreV1().RESTClient().(*restclient.RESTClient).Client = fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, events)}, nil
+	})
+
+	testCases := []struct {
+		groupBy  string
+		expected string
+	}{
+		{
+			groupBy: "reason",
+			expected: `REASON              COUNT   TYPES     LAST SEEN   OBJECTS
+FailedCreate        3       Warning   18m         Deployment/bar
+ScalingReplicaSet   6       Normal    15m         Deployment/bar
+`,
+		},
+		{
+			groupBy: "object",
+			expected: `NAMESPACE   OBJECT           COUNT   TYPES            LAST SEEN   REASONS
+foo         Deployment/bar   6       Normal,Warning   18m         FailedCreate,ScalingReplicaSet
+otherfoo    Deployment/bar   3       Normal           15m         ScalingReplicaSet
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.groupBy, func(t *testing.T) {
+			streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+			printer := NewEventPrinter(false, true)
+			printer.GroupBy = tc.groupBy
+			options := &EventsOptions{
+				AllNamespaces: true,
+				GroupBy:       tc.groupBy,
+				client:        clientset,
+				PrintObj: func(object runtime.Object, writer io.Writer) error {
+					return printer.PrintObj(object, writer)
+				},
+				IOStreams: streams,
+			}
+			if err := options.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := options.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if e, a := tc.expected, buf.String(); e != a {
+				t.Errorf("expected\n%v\ngot\n%v", e, a)
+			}
+		})
+	}
+}
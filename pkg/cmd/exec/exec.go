@@ -27,7 +27,6 @@ import (
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/httpstream"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -71,6 +70,13 @@ var (
 
 		# Get output from running 'date' command from the first pod of the service myservice, using the first container by default
 		kubectl exec svc/myservice -- date
+
+		# Start bash in a tmux or screen session named dev that keeps running if the connection drops,
+		# reattach later with 'kubectl attach mypod -i -t --session dev'; tmux or screen must be installed in the container
+		kubectl exec mypod -i -t --session dev -- bash
+
+		# Pick the pod to start bash in from the pods of the current namespace
+		kubectl exec --pick -i -t -- bash
 		`))
 )
 
@@ -109,6 +115,8 @@ func NewCmdExec(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 	cmd.Flags().BoolVarP(&options.Stdin, "stdin", "i", options.Stdin, "Pass stdin to the container")
 	cmd.Flags().BoolVarP(&options.TTY, "tty", "t", options.TTY, "Stdin is a TTY")
 	cmd.Flags().BoolVarP(&options.Quiet, "quiet", "q", options.Quiet, "Only print output from the remote session")
+	cmd.Flags().StringVar(&options.Session, "session", options.Session, "Run the command in a named tmux or screen session that survives disconnects and can be reattached with 'kubectl attach --session'. The session runs inside the container, which must have tmux or screen installed.")
+	cmdutil.AddPickFlagVar(cmd, &options.Pick)
 	return cmd
 }
 
@@ -121,23 +129,10 @@ type RemoteExecutor interface {
 type DefaultRemoteExecutor struct{}
 
 func (*DefaultRemoteExecutor) Execute(url *url.URL, config *restclient.Config, stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue remotecommand.TerminalSizeQueue) error {
-	// Legacy SPDY executor is default. If feature gate enabled, fallback
-	// executor attempts websockets first--then SPDY.
-	exec, err := remotecommand.NewSPDYExecutor(config, "POST", url)
+	exec, err := cmdutil.NewRemoteCommandExecutor(config, url)
 	if err != nil {
 		return err
 	}
-	if cmdutil.RemoteCommandWebsockets.IsEnabled() {
-		// WebSocketExecutor must be "GET" method as described in RFC 6455 Sec. 4.1 (page 17).
-		websocketExec, err := remotecommand.NewWebSocketExecutor(config, "GET", url.String())
-		if err != nil {
-			return err
-		}
-		exec, err = remotecommand.NewFallbackExecutor(websocketExec, exec, httpstream.IsUpgradeFailure)
-		if err != nil {
-			return err
-		}
-	}
 	return exec.StreamWithContext(context.Background(), remotecommand.StreamOptions{
 		Stdin:             stdin,
 		Stdout:            stdout,
@@ -173,6 +168,12 @@ type ExecOptions struct {
 	ResourceName     string
 	Command          []string
 	EnforceNamespace bool
+	// Session, if set, runs Command in a named session that can be
+	// reattached with 'kubectl attach --session'.
+	Session string
+	// Pick asks the user to pick the pod when none is given, with PickFn.
+	Pick   bool
+	PickFn cmdutil.PickFunc
 
 	Builder          func() *resource.Builder
 	ExecutablePodFn  polymorphichelpers.AttachablePodForObjectFunc
@@ -211,6 +212,20 @@ func (p *ExecOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, argsIn []s
 		return err
 	}
 
+	if p.Pick && len(p.ResourceName) == 0 && len(p.FilenameOptions.Filenames) == 0 {
+		if p.PickFn == nil {
+			p.PickFn, err = cmdutil.NewTerminalPickFunc(p.IOStreams)
+			if err != nil {
+				return err
+			}
+		}
+		info, err := cmdutil.PickObject(f, p.PickFn, "pods", p.Namespace, false, "")
+		if err != nil {
+			return err
+		}
+		p.ResourceName = info.Name
+	}
+
 	p.ExecutablePodFn = polymorphichelpers.AttachablePodForObjectFn
 
 	p.GetPodTimeout, err = cmdutil.GetPodRunningTimeoutFlag(cmd)
@@ -240,7 +255,14 @@ func (p *ExecOptions) Validate() error {
 	if len(p.PodName) == 0 && len(p.ResourceName) == 0 && len(p.FilenameOptions.Filenames) == 0 {
 		return fmt.Errorf("pod, type/name or --filename must be specified")
 	}
-	if len(p.Command) == 0 {
+	if len(p.Session) > 0 {
+		if err := ValidateSessionName(p.Session); err != nil {
+			return err
+		}
+		if !p.Stdin || !p.TTY {
+			return fmt.Errorf("--session requires -i/--stdin and -t/--tty")
+		}
+	} else if len(p.Command) == 0 {
 		return fmt.Errorf("you must specify at least one command for the container")
 	}
 	if p.Out == nil || p.ErrOut == nil {
@@ -351,6 +373,16 @@ func (p *ExecOptions) Run() error {
 		containerName = container.Name
 	}
 
+	command := p.Command
+	if len(p.Session) > 0 {
+		command = SessionCommand(p.Session, p.Command)
+	}
+	if isSessionCommand(command) {
+		if err := p.checkSessions(pod, containerName); err != nil {
+			return err
+		}
+	}
+
 	// ensure we can recover the terminal while attached
 	t := p.SetupTTY()
 
@@ -378,7 +410,7 @@ func (p *ExecOptions) Run() error {
 			SubResource("exec")
 		req.VersionedParams(&corev1.PodExecOptions{
 			Container: containerName,
-			Command:   p.Command,
+			Command:   command,
 			Stdin:     p.Stdin,
 			Stdout:    p.Out != nil,
 			Stderr:    p.ErrOut != nil,
@@ -392,5 +424,8 @@ func (p *ExecOptions) Run() error {
 		return err
 	}
 
+	if len(p.Session) > 0 && !p.Quiet {
+		fmt.Fprintf(p.ErrOut, "If session %s was detached, reattach using 'kubectl attach %s -c %s -i -t --session %s'\n", p.Session, pod.Name, containerName, p.Session)
+	}
 	return nil
 }
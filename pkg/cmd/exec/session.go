@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+	restclient "k8s.io/client-go/rest"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+// Sessions are kept by a terminal multiplexer running inside the container,
+// so that they survive the client disconnecting and keep their scrollback.
+// No sidecar is involved: tmux or screen must be installed in the image.
+// tmux is preferred, screen is used when tmux is not installed, and using
+// sessions fails with an error naming the container when neither is.
+
+var sessionNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidateSessionName checks that name can be used as a tmux and screen session name.
+func ValidateSessionName(name string) error {
+	if !sessionNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid session name %q: only letters, digits, '-' and '_' are allowed", name)
+	}
+	return nil
+}
+
+// the session name is passed to the scripts as $0 and the command as $@,
+// nothing given by the user is interpolated into the script itself.
+const (
+	sessionScript = `if command -v tmux >/dev/null 2>&1; then exec tmux new-session -A -s "$0" "$@"; ` +
+		`elif command -v screen >/dev/null 2>&1; then exec screen -xRR -S "$0" "$@"; ` +
+		`else echo "sessions require tmux or screen to be installed in the container" >&2; exit 127; fi`
+
+	reattachScript = `if command -v tmux >/dev/null 2>&1; then exec tmux attach-session -t "=$0"; ` +
+		`elif command -v screen >/dev/null 2>&1; then exec screen -x "$0"; ` +
+		`else echo "sessions require tmux or screen to be installed in the container" >&2; exit 127; fi`
+
+	checkSessionsScript = `command -v tmux >/dev/null 2>&1 || command -v screen >/dev/null 2>&1 || exit 127`
+
+	listSessionsScript = `if command -v tmux >/dev/null 2>&1; then tmux list-sessions; ` +
+		`elif command -v screen >/dev/null 2>&1; then screen -ls; ` +
+		`else echo "sessions require tmux or screen to be installed in the container" >&2; exit 127; fi`
+)
+
+// SessionCommand returns the command that runs command in the named
+// session, creating the session if it does not exist and attaching to it
+// otherwise. An empty command starts the multiplexer's default shell.
+func SessionCommand(name string, command []string) []string {
+	return append([]string{"sh", "-c", sessionScript, name}, command...)
+}
+
+// ReattachSessionCommand returns the command that attaches to an existing session.
+func ReattachSessionCommand(name string) []string {
+	return []string{"sh", "-c", reattachScript, name}
+}
+
+// ListSessionsCommand returns the command that lists the sessions in a container.
+func ListSessionsCommand() []string {
+	return []string{"sh", "-c", listSessionsScript}
+}
+
+// isSessionCommand reports whether command runs one of the session scripts.
+func isSessionCommand(command []string) bool {
+	if len(command) < 3 || command[0] != "sh" || command[1] != "-c" {
+		return false
+	}
+	switch command[2] {
+	case sessionScript, reattachScript, listSessionsScript:
+		return true
+	}
+	return false
+}
+
+// checkSessions fails when neither tmux nor screen is installed in the
+// container. It runs before the session is started without a terminal, so
+// that the failure is reported as an error rather than written to a raw
+// terminal and lost when the session ends.
+func (p *ExecOptions) checkSessions(pod *corev1.Pod, containerName string) error {
+	restClient, err := restclient.RESTClientFor(p.Config)
+	if err != nil {
+		return err
+	}
+
+	req := restClient.Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec")
+	req.VersionedParams(&corev1.PodExecOptions{
+		Container: containerName,
+		Command:   []string{"sh", "-c", checkSessionsScript},
+		Stdout:    true,
+		Stderr:    true,
+	}, scheme.ParameterCodec)
+
+	if err := p.Executor.Execute(req.URL(), p.Config, nil, io.Discard, io.Discard, false, nil); err != nil {
+		var exitErr utilexec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitStatus() == 127 {
+			return fmt.Errorf("sessions cannot be used in container %s of pod %s: neither tmux nor screen is installed", containerName, pod.Name)
+		}
+		return fmt.Errorf("unable to check for tmux or screen in container %s of pod %s: %v", containerName, pod.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/rest/fake"
+	utilexec "k8s.io/client-go/util/exec"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestValidateSessionName(t *testing.T) {
+	for _, name := range []string{"dev", "my-session_2"} {
+		if err := ValidateSessionName(name); err != nil {
+			t.Errorf("expected %q to be valid: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "a:b", "a.b", "a b", "$(id)"} {
+		if err := ValidateSessionName(name); err == nil {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+}
+
+func TestSessionCommand(t *testing.T) {
+	got := SessionCommand("dev", []string{"bash", "-il"})
+	want := []string{"sh", "-c", sessionScript, "dev", "bash", "-il"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	got = ReattachSessionCommand("dev")
+	want = []string{"sh", "-c", reattachScript, "dev"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestValidateSession(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     ExecOptions
+		expectError bool
+	}{
+		{
+			name:    "session without command",
+			options: ExecOptions{StreamOptions: StreamOptions{PodName: "foo", Stdin: true, TTY: true}, Session: "dev"},
+		},
+		{
+			name:        "session without tty",
+			options:     ExecOptions{StreamOptions: StreamOptions{PodName: "foo", Stdin: true}, Session: "dev", Command: []string{"bash"}},
+			expectError: true,
+		},
+		{
+			name:        "invalid session name",
+			options:     ExecOptions{StreamOptions: StreamOptions{PodName: "foo", Stdin: true, TTY: true}, Session: "a:b"},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.IOStreams = genericiooptions.NewTestIOStreamsDiscard()
+			err := test.options.Validate()
+			if test.expectError != (err != nil) {
+				t.Errorf("expected error %t, got %v", test.expectError, err)
+			}
+		})
+	}
+}
+
+func TestExecSession(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: "", Version: "v1"},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			body := cmdtesting.ObjBody(codec, execPod())
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: body}, nil
+		}),
+	}
+	tf.ClientConfigVal = &restclient.Config{APIPath: "/api", ContentConfig: restclient.ContentConfig{NegotiatedSerializer: scheme.Codecs, GroupVersion: &schema.GroupVersion{Version: "v1"}}}
+
+	ex := &fakeRemoteExecutor{}
+	streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+	params := &ExecOptions{
+		StreamOptions: StreamOptions{
+			PodName:       "foo",
+			ContainerName: "bar",
+			IOStreams:     streams,
+		},
+		Executor: ex,
+		Session:  "dev",
+	}
+	cmd := NewCmdExec(tf, streams)
+	if err := params.Complete(tf, cmd, []string{"foo", "bash"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := params.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := ex.url.Query()["command"], SessionCommand("dev", []string{"bash"}); !reflect.DeepEqual(want, got) {
+		t.Errorf("expected command %v, got %v", want, got)
+	}
+	if want := "If session dev was detached, reattach using 'kubectl attach foo -c bar -i -t --session dev'\n"; errOut.String() != want {
+		t.Errorf("expected %q, got %q", want, errOut.String())
+	}
+}
+
+func TestExecSessionWithoutMultiplexer(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Group: "", Version: "v1"},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			body := cmdtesting.ObjBody(codec, execPod())
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: body}, nil
+		}),
+	}
+	tf.ClientConfigVal = &restclient.Config{APIPath: "/api", ContentConfig: restclient.ContentConfig{NegotiatedSerializer: scheme.Codecs, GroupVersion: &schema.GroupVersion{Version: "v1"}}}
+
+	ex := &fakeRemoteExecutor{execErr: utilexec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 127"), Code: 127}}
+	streams := genericiooptions.NewTestIOStreamsDiscard()
+	params := &ExecOptions{
+		StreamOptions: StreamOptions{
+			PodName:       "foo",
+			ContainerName: "bar",
+			IOStreams:     streams,
+		},
+		Executor: ex,
+		Session:  "dev",
+	}
+	cmd := NewCmdExec(tf, streams)
+	if err := params.Complete(tf, cmd, []string{"foo", "bash"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	err := params.Run()
+	if want := "sessions cannot be used in container bar of pod foo: neither tmux nor screen is installed"; err == nil || err.Error() != want {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+	if got := ex.url.Query()["command"]; !reflect.DeepEqual([]string{"sh", "-c", checkSessionsScript}, got) {
+		t.Errorf("expected only the check to run, got %v", got)
+	}
+
+	ex.execErr = fmt.Errorf("connection refused")
+	if err := params.Run(); err == nil || !strings.Contains(err.Error(), "unable to check for tmux or screen") {
+		t.Errorf("expected the check to fail, got %v", err)
+	}
+}
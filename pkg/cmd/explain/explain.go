@@ -18,6 +18,7 @@ package explain
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/spf13/cobra"
 
@@ -58,7 +59,17 @@ var (
 		kubectl explain pods.spec.containers
 		
 		# Get the documentation of resources in different format
-		kubectl explain deployment --output=plaintext-openapiv2`))
+		kubectl explain deployment --output=plaintext-openapiv2
+
+		# Find the fields named image, or starting with image, anywhere in a resource
+		kubectl explain pods --search='image.*'
+
+		# Get a skeleton YAML of a field of a resource
+		kubectl explain deployments.spec.strategy --example
+
+		# Export the schemas of the cluster, then get the documentation without a cluster
+		kubectl explain --export-schemas=./schemas
+		kubectl explain pods --schema-dir=./schemas`))
 
 	plaintextTemplateName          = "plaintext"
 	plaintextOpenAPIV2TemplateName = "plaintext-openapiv2"
@@ -71,6 +82,18 @@ type ExplainOptions struct {
 	APIVersion string
 	Recursive  bool
 
+	// Search is the name or regular expression of the fields to search for
+	Search string
+	// Example prints a skeleton YAML instead of the documentation
+	Example bool
+
+	searchPattern *regexp.Regexp
+
+	// SchemaDir is the directory of a schema bundle to use instead of the cluster
+	SchemaDir string
+	// ExportSchemas is the directory to export the schemas of the cluster to
+	ExportSchemas string
+
 	args []string
 
 	Mapper        meta.RESTMapper
@@ -96,7 +119,7 @@ func NewCmdExplain(parent string, f cmdutil.Factory, streams genericiooptions.IO
 	o := NewExplainOptions(parent, streams)
 
 	cmd := &cobra.Command{
-		Use:                   "explain TYPE [--recursive=FALSE|TRUE] [--api-version=api-version-group] [--output=plaintext|plaintext-openapiv2]",
+		Use:                   "explain TYPE [--recursive=FALSE|TRUE] [--api-version=api-version-group] [--output=plaintext|plaintext-openapiv2] [--search=FIELD_NAME] [--example] [--schema-dir=DIR]",
 		DisableFlagsInUseLine: true,
 		Short:                 i18n.T("Get documentation for a resource"),
 		Long:                  explainLong + "\n\n" + cmdutil.SuggestAPIResources(parent),
@@ -109,6 +132,10 @@ func NewCmdExplain(parent string, f cmdutil.Factory, streams genericiooptions.IO
 	}
 	cmd.Flags().BoolVar(&o.Recursive, "recursive", o.Recursive, "When true, print the name of all the fields recursively. Otherwise, print the available fields with their description.")
 	cmd.Flags().StringVar(&o.APIVersion, "api-version", o.APIVersion, "Use given api-version (group/version) of the resource.")
+	cmd.Flags().StringVar(&o.Search, "search", o.Search, "Print the path of all the fields nested within the resource or field whose name matches this case-insensitive regular expression.")
+	cmd.Flags().BoolVar(&o.Example, "example", o.Example, "When true, print a skeleton YAML of the resource or field, using the default and enum values of the fields.")
+	cmd.Flags().StringVar(&o.SchemaDir, "schema-dir", o.SchemaDir, "Read the OpenAPI V3 schemas from this directory, as written by --export-schemas, instead of the cluster. Resources must be given by their kind, singular or plural name.")
+	cmd.Flags().StringVar(&o.ExportSchemas, "export-schemas", o.ExportSchemas, "Write the OpenAPI V3 schemas of the cluster to this directory, to use them with --schema-dir.")
 
 	// Only enable --output as a valid flag if the feature is enabled
 	cmd.Flags().StringVar(&o.OutputFormat, "output", plaintextTemplateName, "Format in which to render the schema. Valid values are: (plaintext, plaintext-openapiv2).")
@@ -117,7 +144,17 @@ func NewCmdExplain(parent string, f cmdutil.Factory, streams genericiooptions.IO
 }
 
 func (o *ExplainOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.args = args
 	var err error
+	if len(o.SchemaDir) > 0 {
+		// Resources are mapped from the paths of the schemas, there is no cluster to discover them.
+		o.OpenAPIV3Client = &bundleClient{dir: o.SchemaDir}
+		if o.Mapper, err = bundleRESTMapper(o.OpenAPIV3Client); err != nil {
+			return fmt.Errorf("failed to read the schemas of %s: %w", o.SchemaDir, err)
+		}
+		return nil
+	}
+
 	o.Mapper, err = f.ToRESTMapper()
 	if err != nil {
 		return err
@@ -131,23 +168,61 @@ func (o *ExplainOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []
 
 	// Lazy-load the OpenAPI V2 Resources, so they're not loaded when using OpenAPI V3.
 	o.openAPIGetter = f
-	o.args = args
 	return nil
 }
 
 func (o *ExplainOptions) Validate() error {
+	if len(o.ExportSchemas) > 0 {
+		if len(o.args) > 0 {
+			return fmt.Errorf("--export-schemas does not take a resource")
+		}
+		if len(o.SchemaDir) > 0 {
+			return fmt.Errorf("--export-schemas can not be used with --schema-dir")
+		}
+		return nil
+	}
 	if len(o.args) == 0 {
 		return fmt.Errorf("You must specify the type of resource to explain. %s\n", cmdutil.SuggestAPIResources(o.CmdParent))
 	}
 	if len(o.args) > 1 {
 		return fmt.Errorf("We accept only this format: explain RESOURCE\n")
 	}
+	if len(o.Search) > 0 || o.Example {
+		if len(o.Search) > 0 && o.Example {
+			return fmt.Errorf("--search and --example can not be used together")
+		}
+		if o.Recursive {
+			return fmt.Errorf("--recursive can not be used with --search or --example")
+		}
+		if o.OutputFormat != plaintextTemplateName {
+			return fmt.Errorf("--output %s can not be used with --search or --example", o.OutputFormat)
+		}
+	}
+	if len(o.SchemaDir) > 0 && o.OutputFormat == plaintextOpenAPIV2TemplateName {
+		return fmt.Errorf("--output %s can not be used with --schema-dir", o.OutputFormat)
+	}
+	if len(o.Search) > 0 {
+		var err error
+		// the pattern has to match the whole field name
+		if o.searchPattern, err = regexp.Compile("(?i)^(?:" + o.Search + ")$"); err != nil {
+			return fmt.Errorf("invalid --search %q: %v", o.Search, err)
+		}
+	}
 
 	return nil
 }
 
 // Run executes the appropriate steps to print a model's documentation
 func (o *ExplainOptions) Run() error {
+	if len(o.ExportSchemas) > 0 {
+		count, err := exportSchemas(o.OpenAPIV3Client, o.ExportSchemas)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "%d OpenAPI V3 schemas written to %q.\n", count, o.ExportSchemas)
+		return nil
+	}
+
 	var fullySpecifiedGVR schema.GroupVersionResource
 	var fieldsPath []string
 	var err error
@@ -166,6 +241,18 @@ func (o *ExplainOptions) Run() error {
 		}
 	}
 
+	if len(o.Search) > 0 || o.Example {
+		// Searching and examples need the OpenAPI V3 schema, there is no fallback.
+		gvr, err := o.withAPIVersion(fullySpecifiedGVR)
+		if err != nil {
+			return err
+		}
+		if o.Example {
+			return openapiv3explain.PrintExample(fieldsPath, o.Out, o.OpenAPIV3Client, gvr)
+		}
+		return openapiv3explain.SearchFields(fieldsPath, o.Out, o.OpenAPIV3Client, gvr, o.searchPattern)
+	}
+
 	// Fallback to openapiv2 implementation using special template name
 	switch o.OutputFormat {
 	case plaintextOpenAPIV2TemplateName:
@@ -179,26 +266,35 @@ func (o *ExplainOptions) Run() error {
 
 		fallthrough
 	default:
-		if len(o.APIVersion) > 0 {
-			apiVersion, err := schema.ParseGroupVersion(o.APIVersion)
-			if err != nil {
-				return err
-			}
-			fullySpecifiedGVR.Group = apiVersion.Group
-			fullySpecifiedGVR.Version = apiVersion.Version
+		gvr, err := o.withAPIVersion(fullySpecifiedGVR)
+		if err != nil {
+			return err
 		}
 
 		return openapiv3explain.PrintModelDescription(
 			fieldsPath,
 			o.Out,
 			o.OpenAPIV3Client,
-			fullySpecifiedGVR,
+			gvr,
 			o.Recursive,
 			o.OutputFormat,
 		)
 	}
 }
 
+// withAPIVersion returns the resource in the group version given with
+// --api-version, if any.
+func (o *ExplainOptions) withAPIVersion(gvr schema.GroupVersionResource) (schema.GroupVersionResource, error) {
+	if len(o.APIVersion) == 0 {
+		return gvr, nil
+	}
+	apiVersion, err := schema.ParseGroupVersion(o.APIVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	return apiVersion.WithResource(gvr.Resource), nil
+}
+
 func (o *ExplainOptions) renderOpenAPIV2(
 	fullySpecifiedGVR schema.GroupVersionResource,
 	fieldsPath []string,
@@ -18,8 +18,10 @@ package explain_test
 
 import (
 	"errors"
+	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -182,6 +184,30 @@ func TestExplainOpenAPIV3(t *testing.T) {
 			ExpectPattern:     []string{`\s*KIND:[\t ]*Pod\s*`},
 			OpenAPIV3SchemaFn: fallbackV3SchemaFn,
 		},
+		{
+			Name:          "Search",
+			Args:          []string{"pods"},
+			Flags:         map[string]string{"search": "image"},
+			ExpectPattern: []string{`pods\.spec\.containers\.image\s+<string>`},
+		},
+		{
+			Name:          "Example",
+			Args:          []string{"pods.spec.containers"},
+			Flags:         map[string]string{"example": "true"},
+			ExpectPattern: []string{`containers:\n- args: \[\]\n`},
+		},
+		{
+			Name:               "SearchAndExample",
+			Args:               []string{"pods"},
+			Flags:              map[string]string{"search": "image", "example": "true"},
+			ExpectErrorPattern: `--search and --example can not be used together`,
+		},
+		{
+			Name:               "InvalidSearch",
+			Args:               []string{"pods"},
+			Flags:              map[string]string{"search": "image("},
+			ExpectErrorPattern: `invalid --search "image\("`,
+		},
 	}
 	cases = append(cases, explainV2Cases...)
 
@@ -316,3 +342,79 @@ func TestExplainOpenAPIV3DoesNotLoadOpenAPIV2Specs(t *testing.T) {
 	}
 	cmd.Run(cmd, []string{"pods"})
 }
+
+func TestExplainSchemaBundle(t *testing.T) {
+	fakeServer, err := clienttestutil.NewFakeOpenAPIV3Server(filepath.Join(testDataPath, "openapi", "v3"))
+	if err != nil {
+		t.Fatalf("error starting fake openapi server: %v", err.Error())
+	}
+	defer fakeServer.HttpServer.Close()
+	tf := cmdtesting.NewTestFactory()
+	defer tf.Cleanup()
+	tf.OpenAPIV3ClientFunc = func() (openapiclient.Client, error) {
+		fakeDiscoveryClient := discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: fakeServer.HttpServer.URL})
+		return fakeDiscoveryClient.OpenAPIV3(), nil
+	}
+
+	dir := t.TempDir()
+	ioStreams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	o := explain.NewExplainOptions("kubectl", ioStreams)
+	o.ExportSchemas = dir
+	cmd := explain.NewCmdExplain("kubectl", tf, ioStreams)
+	if err := o.Complete(tf, cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^\d+ OpenAPI V3 schemas written to ".*"\.\n$`).MatchString(buf.String()) {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+	for _, name := range []string{"api__v1_openapi.json", "apis__apps__v1_openapi.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to be exported: %v", name, err)
+		}
+	}
+
+	// The cluster is not used with a schema bundle.
+	tf.OpenAPIV3ClientFunc = func() (openapiclient.Client, error) {
+		panic("should never be called")
+	}
+	tf.OpenAPISchemaFunc = panicOpenAPISchemaFn
+	testCases := []struct {
+		resource string
+		expected string
+	}{
+		{resource: "pods", expected: `\s*KIND:[\t ]*Pod\s*`},
+		{resource: "deployment.spec.replicas", expected: `\s*GROUP:[\t ]*apps\s*KIND:[\t ]*Deployment\s*VERSION:[\t ]*v1\s*FIELD: replicas <integer>`},
+		{resource: "Service", expected: `\s*KIND:[\t ]*Service\s*`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.resource, func(t *testing.T) {
+			buf.Reset()
+			o := explain.NewExplainOptions("kubectl", ioStreams)
+			o.SchemaDir = dir
+			if err := o.Complete(tf, cmd, []string{tc.resource}); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if !regexp.MustCompile(tc.expected).MatchString(buf.String()) {
+				t.Errorf("expected output to match regex:\n\t%s\ninstead got:\n\t%s", tc.expected, buf.String())
+			}
+		})
+	}
+
+	o = explain.NewExplainOptions("kubectl", ioStreams)
+	o.SchemaDir = t.TempDir()
+	if err := o.Complete(tf, cmd, []string{"pods"}); err == nil || !strings.Contains(err.Error(), "no OpenAPI V3 schema found") {
+		t.Errorf("expected an error for an empty schema directory, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	openapiclient "k8s.io/client-go/openapi"
+)
+
+// The schemas of a bundle are stored one file per path, with the slashes of
+// the path replaced by double underscores, as in api/openapi-spec/v3 of the
+// Kubernetes repository: apis/apps/v1 is stored in apis__apps__v1_openapi.json.
+const schemaFileSuffix = "_openapi.json"
+
+func schemaFileName(path string) string {
+	return strings.ReplaceAll(path, "/", "__") + schemaFileSuffix
+}
+
+// exportSchemas writes the OpenAPI V3 schemas served by the client to the
+// directory, and returns the number of schemas written.
+func exportSchemas(client openapiclient.Client, dir string) (int, error) {
+	paths, err := client.Paths()
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch list of groupVersions: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	for path, gv := range paths {
+		data, err := gv.Schema(runtime.ContentTypeJSON)
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch openapi schema for %s: %w", path, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, schemaFileName(path)), data, 0644); err != nil {
+			return 0, err
+		}
+	}
+	return len(paths), nil
+}
+
+// bundleClient serves the OpenAPI V3 schemas of a bundle written by
+// exportSchemas.
+type bundleClient struct {
+	dir string
+}
+
+var _ openapiclient.Client = &bundleClient{}
+
+func (c *bundleClient) Paths() (map[string]openapiclient.GroupVersion, error) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	paths := map[string]openapiclient.GroupVersion{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), schemaFileSuffix) {
+			continue
+		}
+		path := strings.ReplaceAll(strings.TrimSuffix(e.Name(), schemaFileSuffix), "__", "/")
+		paths[path] = &bundleGroupVersion{filename: filepath.Join(c.dir, e.Name())}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no OpenAPI V3 schema found in %s", c.dir)
+	}
+	return paths, nil
+}
+
+type bundleGroupVersion struct {
+	filename string
+}
+
+var _ openapiclient.GroupVersion = &bundleGroupVersion{}
+
+func (gv *bundleGroupVersion) Schema(contentType string) ([]byte, error) {
+	if contentType != runtime.ContentTypeJSON {
+		return nil, fmt.Errorf("unsupported content type %q for schema bundles", contentType)
+	}
+	return os.ReadFile(gv.filename)
+}
+
+// bundleRESTMapper returns a RESTMapper of the resources whose paths are
+// described by the schemas of the client, so that explain can find resources
+// without a cluster. Short names and categories are not part of the schemas.
+func bundleRESTMapper(client openapiclient.Client) (meta.RESTMapper, error) {
+	paths, err := client.Paths()
+	if err != nil {
+		return nil, err
+	}
+
+	type mapping struct {
+		gvk        schema.GroupVersionKind
+		namespaced bool
+	}
+	mappings := map[schema.GroupVersionResource]mapping{}
+	groupVersions := []schema.GroupVersion{}
+	for path, gv := range paths {
+		groupVersion, ok := pathGroupVersion(path)
+		if !ok {
+			continue
+		}
+		groupVersions = append(groupVersions, groupVersion)
+
+		data, err := gv.Schema(runtime.ContentTypeJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch openapi schema for %s: %w", path, err)
+		}
+		document := struct {
+			Paths map[string]map[string]json.RawMessage `json:"paths"`
+		}{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse openapi schema for %s: %w", path, err)
+		}
+
+		for resourcePath, operations := range document.Paths {
+			resource, namespaced, ok := pathResource(strings.TrimPrefix(resourcePath, "/"+path+"/"))
+			if !ok {
+				continue
+			}
+			gvk, ok := operationsGroupVersionKind(operations)
+			if !ok {
+				continue
+			}
+			gvr := groupVersion.WithResource(resource)
+			m := mappings[gvr]
+			mappings[gvr] = mapping{gvk: gvk, namespaced: m.namespaced || namespaced}
+		}
+	}
+
+	// the core group is preferred, then the groups in alphabetical order
+	sort.Slice(groupVersions, func(i, j int) bool {
+		if (groupVersions[i].Group == "") != (groupVersions[j].Group == "") {
+			return groupVersions[i].Group == ""
+		}
+		return groupVersions[i].String() < groupVersions[j].String()
+	})
+	mapper := meta.NewDefaultRESTMapper(groupVersions)
+	for gvr, m := range mappings {
+		scope := meta.RESTScopeRoot
+		if m.namespaced {
+			scope = meta.RESTScopeNamespace
+		}
+		mapper.AddSpecific(m.gvk, gvr, gvr.GroupVersion().WithResource(strings.ToLower(m.gvk.Kind)), scope)
+	}
+	return mapper, nil
+}
+
+// pathGroupVersion returns the group version served at the path, such as
+// api/v1 or apis/apps/v1.
+func pathGroupVersion(path string) (schema.GroupVersion, bool) {
+	parts := strings.Split(path, "/")
+	switch {
+	case len(parts) == 2 && parts[0] == "api":
+		return schema.GroupVersion{Version: parts[1]}, true
+	case len(parts) == 3 && parts[0] == "apis":
+		return schema.GroupVersion{Group: parts[1], Version: parts[2]}, true
+	default:
+		return schema.GroupVersion{}, false
+	}
+}
+
+// pathResource returns the resource of a path relative to its group version,
+// such as namespaces/{namespace}/pods/{name}. Subresources and watch paths are
+// skipped.
+func pathResource(path string) (string, bool, bool) {
+	namespaced := false
+	if rest := strings.TrimPrefix(path, "namespaces/{namespace}/"); rest != path {
+		path, namespaced = rest, true
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) > 2 || (len(parts) == 2 && parts[1] != "{name}") || strings.HasPrefix(parts[0], "{") || parts[0] == "watch" {
+		return "", false, false
+	}
+	return parts[0], namespaced, true
+}
+
+// operationsGroupVersionKind returns the kind of the resource an operation
+// of a path acts on.
+func operationsGroupVersionKind(operations map[string]json.RawMessage) (schema.GroupVersionKind, bool) {
+	for _, method := range []string{"get", "post", "put", "patch", "delete"} {
+		raw, ok := operations[method]
+		if !ok {
+			continue
+		}
+		operation := struct {
+			GVK *schema.GroupVersionKind `json:"x-kubernetes-group-version-kind"`
+		}{}
+		if err := json.Unmarshal(raw, &operation); err != nil || operation.GVK == nil {
+			continue
+		}
+		return *operation.GVK, true
+	}
+	return schema.GroupVersionKind{}, false
+}
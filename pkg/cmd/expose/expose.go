@@ -25,6 +25,7 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -59,6 +60,10 @@ var (
 		--port and the exposed resource has multiple ports, all will be re-used by the new service. Also if no
 		labels are specified, the new service will re-use the labels from the resource it exposes.
 
+		Several ports can be given by repeating --port as [NAME=]PORT[:TARGET_PORT]. With --all-ports every
+		container port is mapped under its own name instead. With --with-servicemonitor a Prometheus
+		ServiceMonitor scraping the named ports of the service is printed after it; this requires -o yaml or -o json.
+
 		Possible resources include (case insensitive):
 
 		`) + exposeResources)
@@ -83,7 +88,13 @@ var (
 		kubectl expose rs nginx --port=80 --target-port=8000
 
 		# Create a service for an nginx deployment, which serves on port 80 and connects to the containers on port 8000
-		kubectl expose deployment nginx --port=80 --target-port=8000`))
+		kubectl expose deployment nginx --port=80 --target-port=8000
+
+		# Create a service for an app deployment with a named http port and a named metrics port
+		kubectl expose deployment app --port=http=80:8080 --port=metrics=9090
+
+		# Print a service mapping every container port of the app deployment, along with a ServiceMonitor for it
+		kubectl expose deployment app --all-ports --with-servicemonitor --dry-run=client -o yaml`))
 )
 
 // ExposeServiceOptions holds the options for kubectl expose command
@@ -101,7 +112,16 @@ type ExposeServiceOptions struct {
 	// Port will be used if a user specifies --port OR the exposed object as one port
 	Port string
 	// Ports will be used iff a user doesn't specify --port AND the exposed object has multiple ports
-	Ports          string
+	Ports string
+	// PortMappings will be used if a user specifies --port several times, or with a name or target port
+	PortMappings []string
+	// AllPorts maps every container port of the exposed object under its own name
+	AllPorts bool
+	// ServicePorts are the ports derived with AllPorts, they take precedence over all the others
+	ServicePorts []corev1.ServicePort
+	// WithServiceMonitor prints a Prometheus ServiceMonitor for the service created
+	WithServiceMonitor bool
+
 	Labels         string
 	ExternalIP     string
 	LoadBalancerIP string
@@ -143,19 +163,21 @@ type ExposeServiceFlags struct {
 	fieldManager string
 	Protocol     string
 
-	// Port will be used if a user specifies --port OR the exposed object as one port
-	Port            string
-	Type            string
-	LoadBalancerIP  string
-	Selector        string
-	Labels          string
-	TargetPort      string
-	ExternalIP      string
-	Name            string
-	SessionAffinity string
-	ClusterIP       string
-	Recorder        genericclioptions.Recorder
-	FilenameOptions resource.FilenameOptions
+	// Port holds every --port given, either as a port or as [NAME=]PORT[:TARGET_PORT]
+	Port               []string
+	AllPorts           bool
+	WithServiceMonitor bool
+	Type               string
+	LoadBalancerIP     string
+	Selector           string
+	Labels             string
+	TargetPort         string
+	ExternalIP         string
+	Name               string
+	SessionAffinity    string
+	ClusterIP          string
+	Recorder           genericclioptions.Recorder
+	FilenameOptions    resource.FilenameOptions
 	genericiooptions.IOStreams
 }
 
@@ -203,7 +225,9 @@ func (flags *ExposeServiceFlags) AddFlags(cmd *cobra.Command) {
 	flags.RecordFlags.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&flags.Protocol, "protocol", flags.Protocol, i18n.T("The network protocol for the service to be created. Default is 'TCP'."))
-	cmd.Flags().StringVar(&flags.Port, "port", flags.Port, i18n.T("The port that the service should serve on. Copied from the resource being exposed, if unspecified"))
+	cmd.Flags().StringArrayVar(&flags.Port, "port", flags.Port, i18n.T("The port that the service should serve on, or [NAME=]PORT[:TARGET_PORT] mappings when given several times. Copied from the resource being exposed, if unspecified"))
+	cmd.Flags().BoolVar(&flags.AllPorts, "all-ports", flags.AllPorts, i18n.T("If true, map every container port of the resource being exposed, using the name of the container port."))
+	cmd.Flags().BoolVar(&flags.WithServiceMonitor, "with-servicemonitor", flags.WithServiceMonitor, i18n.T("If true, also print a Prometheus ServiceMonitor scraping the named ports of the service. Requires -o yaml or -o json."))
 	cmd.Flags().StringVar(&flags.Type, "type", flags.Type, i18n.T("Type for this service: ClusterIP, NodePort, LoadBalancer, or ExternalName. Default is 'ClusterIP'."))
 	cmd.Flags().StringVar(&flags.LoadBalancerIP, "load-balancer-ip", flags.LoadBalancerIP, i18n.T("IP to assign to the LoadBalancer. If empty, an ephemeral IP will be created and used (cloud-provider specific)."))
 	cmd.Flags().StringVar(&flags.Selector, "selector", flags.Selector, i18n.T("A label selector to use for this service. Only equality-based selector requirements are supported. If empty (the default) infer the selector from the replication controller or replica set.)"))
@@ -242,6 +266,15 @@ func (flags *ExposeServiceFlags) ToOptions(cmd *cobra.Command, args []string) (*
 		return nil, err
 	}
 
+	if flags.AllPorts && len(flags.Port) > 0 {
+		return nil, fmt.Errorf("--all-ports and --port cannot be used together")
+	}
+	if flags.WithServiceMonitor {
+		if format := *flags.PrintFlags.OutputFormat; format != "yaml" && format != "json" {
+			return nil, fmt.Errorf("--with-servicemonitor requires -o yaml or -o json")
+		}
+	}
+
 	e := &ExposeServiceOptions{
 		DryRunStrategy:  dryRunStratergy,
 		PrintObj:        printer.PrintObj,
@@ -252,7 +285,7 @@ func (flags *ExposeServiceFlags) ToOptions(cmd *cobra.Command, args []string) (*
 		RecordFlags:     flags.RecordFlags,
 		FilenameOptions: flags.FilenameOptions,
 		Protocol:        flags.Protocol,
-		Port:            flags.Port,
+		AllPorts:        flags.AllPorts,
 		Type:            flags.Type,
 		LoadBalancerIP:  flags.LoadBalancerIP,
 		Selector:        flags.Selector,
@@ -263,6 +296,14 @@ func (flags *ExposeServiceFlags) ToOptions(cmd *cobra.Command, args []string) (*
 		SessionAffinity: flags.SessionAffinity,
 		ClusterIP:       flags.ClusterIP,
 		OverrideOptions: flags.OverrideOptions,
+
+		WithServiceMonitor: flags.WithServiceMonitor,
+	}
+	// A single plain port keeps the behaviour of the original --port flag
+	if len(flags.Port) == 1 && !strings.ContainsAny(flags.Port[0], "=:") {
+		e.Port = flags.Port[0]
+	} else {
+		e.PortMappings = flags.Port
 	}
 	return e, nil
 }
@@ -335,9 +376,19 @@ func (o *ExposeServiceOptions) RunExpose(cmd *cobra.Command, args []string) erro
 
 		isHeadlessService := o.ClusterIP == "None"
 
+		if o.AllPorts {
+			o.ServicePorts, err = allPortsForObject(info.Object)
+			if err != nil {
+				return err
+			}
+			if len(o.ServicePorts) == 0 && !isHeadlessService {
+				return fmt.Errorf("couldn't find any port to map with --all-ports")
+			}
+		}
+
 		// For objects that need a port, derive it from the exposed object in case a user
 		// didn't explicitly specify one via --port
-		if len(o.Port) == 0 {
+		if len(o.Port) == 0 && len(o.PortMappings) == 0 && !o.AllPorts {
 			ports, err := o.PortsForObject(info.Object)
 			if err != nil {
 				return fmt.Errorf("couldn't find port via --port flag or introspection: %v", err)
@@ -391,7 +442,10 @@ func (o *ExposeServiceOptions) RunExpose(cmd *cobra.Command, args []string) erro
 			if meta, err := meta.Accessor(overrideService); err == nil && o.EnforceNamespace {
 				meta.SetNamespace(o.Namespace)
 			}
-			return o.PrintObj(overrideService, o.Out)
+			if err := o.PrintObj(overrideService, o.Out); err != nil {
+				return err
+			}
+			return o.printServiceMonitor(overrideService)
 		}
 		if err := util.CreateOrUpdateAnnotation(cmdutil.GetFlagBool(cmd, cmdutil.ApplyAnnotationsFlag), overrideService, scheme.DefaultJSONEncoder()); err != nil {
 			return err
@@ -422,11 +476,123 @@ func (o *ExposeServiceOptions) RunExpose(cmd *cobra.Command, args []string) erro
 		if err != nil {
 			return err
 		}
-		return o.PrintObj(actualObject, o.Out)
+		if err := o.PrintObj(actualObject, o.Out); err != nil {
+			return err
+		}
+		return o.printServiceMonitor(actualObject)
 	})
 	return err
 }
 
+// printServiceMonitor prints the ServiceMonitor paired with the service, when
+// one was asked for.
+func (o *ExposeServiceOptions) printServiceMonitor(service runtime.Object) error {
+	if !o.WithServiceMonitor {
+		return nil
+	}
+	serviceMonitor, err := serviceMonitorForService(service)
+	if err != nil {
+		return err
+	}
+	if o.EnforceNamespace {
+		serviceMonitor.SetNamespace(o.Namespace)
+	}
+	// separate yaml objects
+	if *o.PrintFlags.OutputFormat == "yaml" {
+		fmt.Fprintln(o.Out, "---")
+	}
+	return o.PrintObj(serviceMonitor, o.Out)
+}
+
+// serviceMonitorForService builds a Prometheus ServiceMonitor selecting the
+// service by its labels and scraping each of its named ports.
+func serviceMonitorForService(obj runtime.Object) (*unstructured.Unstructured, error) {
+	service := &corev1.Service{}
+	if err := scheme.Scheme.Convert(obj, service, nil); err != nil {
+		return nil, err
+	}
+	if len(service.Labels) == 0 {
+		return nil, fmt.Errorf("--with-servicemonitor requires the service to have labels to select it with, use --labels")
+	}
+	endpoints := []interface{}{}
+	for _, port := range service.Spec.Ports {
+		if len(port.Name) > 0 {
+			endpoints = append(endpoints, map[string]interface{}{"port": port.Name})
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("--with-servicemonitor requires a named service port, use --port=NAME=PORT or --all-ports")
+	}
+	matchLabels := map[string]interface{}{}
+	for key, value := range service.Labels {
+		matchLabels[key] = value
+	}
+	serviceMonitor := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"selector":  map[string]interface{}{"matchLabels": matchLabels},
+			"endpoints": endpoints,
+		},
+	}}
+	serviceMonitor.SetAPIVersion("monitoring.coreos.com/v1")
+	serviceMonitor.SetKind("ServiceMonitor")
+	serviceMonitor.SetName(service.Name)
+	serviceMonitor.SetNamespace(service.Namespace)
+	serviceMonitor.SetLabels(service.Labels)
+	return serviceMonitor, nil
+}
+
+// allPortsForObject returns a service port for each container port of the
+// object, named after the container port and targeting it.
+func allPortsForObject(object runtime.Object) ([]corev1.ServicePort, error) {
+	var podSpec corev1.PodSpec
+	switch t := object.(type) {
+	case *corev1.Pod:
+		podSpec = t.Spec
+	case *corev1.ReplicationController:
+		if t.Spec.Template != nil {
+			podSpec = t.Spec.Template.Spec
+		}
+	case *appsv1.Deployment:
+		podSpec = t.Spec.Template.Spec
+	case *appsv1.ReplicaSet:
+		podSpec = t.Spec.Template.Spec
+	case *corev1.Service:
+		return t.Spec.Ports, nil
+	default:
+		return nil, fmt.Errorf("cannot map all ports of %T", object)
+	}
+
+	ports := []corev1.ServicePort{}
+	exists := map[string]bool{}
+	for _, container := range podSpec.Containers {
+		for _, containerPort := range container.Ports {
+			protocol := containerPort.Protocol
+			if len(protocol) == 0 {
+				protocol = corev1.ProtocolTCP
+			}
+			// remove duplicate ports
+			key := fmt.Sprintf("%d/%s", containerPort.ContainerPort, protocol)
+			if exists[key] {
+				continue
+			}
+			exists[key] = true
+			servicePort := corev1.ServicePort{
+				Name:       containerPort.Name,
+				Port:       containerPort.ContainerPort,
+				Protocol:   protocol,
+				TargetPort: intstr.FromInt32(containerPort.ContainerPort),
+			}
+			if len(containerPort.Name) > 0 {
+				servicePort.TargetPort = intstr.FromString(containerPort.Name)
+			} else {
+				servicePort.Name = fmt.Sprintf("%s-%d", strings.ToLower(string(protocol)), containerPort.ContainerPort)
+			}
+			ports = append(ports, servicePort)
+		}
+	}
+	return ports, nil
+}
+
 func (o *ExposeServiceOptions) createService() (*corev1.Service, error) {
 	if len(o.Selector) == 0 {
 		return nil, fmt.Errorf("selector must be specified")
@@ -470,7 +636,15 @@ func (o *ExposeServiceOptions) createService() (*corev1.Service, error) {
 	}
 
 	ports := []corev1.ServicePort{}
-	if len(portString) != 0 {
+	switch {
+	case len(o.ServicePorts) > 0:
+		ports = append(ports, o.ServicePorts...)
+	case len(o.PortMappings) > 0:
+		ports, err = parsePortMappings(o.PortMappings, o.Protocol)
+		if err != nil {
+			return nil, err
+		}
+	case len(portString) != 0:
 		portStringSlice := strings.Split(portString, ",")
 		servicePortName := o.PortName
 		for i, stillPortString := range portStringSlice {
@@ -531,16 +705,17 @@ func (o *ExposeServiceOptions) createService() (*corev1.Service, error) {
 		},
 	}
 	targetPortString := o.TargetPort
-	if len(targetPortString) > 0 {
-		targetPort := intstr.Parse(targetPortString)
-		// Use the same target-port for every port
-		for i := range service.Spec.Ports {
-			service.Spec.Ports[i].TargetPort = targetPort
-		}
-	} else {
-		// If --target-port or --container-port haven't been specified, this
-		// should be the same as Port
-		for i := range service.Spec.Ports {
+	for i := range service.Spec.Ports {
+		// Keep the target ports given with each port mapping
+		if service.Spec.Ports[i].TargetPort != (intstr.IntOrString{}) {
+			continue
+		}
+		if len(targetPortString) > 0 {
+			// Use the same target-port for every port
+			service.Spec.Ports[i].TargetPort = intstr.Parse(targetPortString)
+		} else {
+			// If --target-port or --container-port haven't been specified, this
+			// should be the same as Port
 			port := service.Spec.Ports[i].Port
 			service.Spec.Ports[i].TargetPort = intstr.FromInt32(port)
 		}
@@ -574,6 +749,48 @@ func (o *ExposeServiceOptions) createService() (*corev1.Service, error) {
 	return &service, nil
 }
 
+// parsePortMappings turns [NAME=]PORT[:TARGET_PORT] mappings into service
+// ports. Unnamed ports are named after their position when there are several.
+func parsePortMappings(mappings []string, protocol string) ([]corev1.ServicePort, error) {
+	if len(protocol) == 0 {
+		protocol = string(corev1.ProtocolTCP)
+	}
+	ports := []corev1.ServicePort{}
+	names := map[string]bool{}
+	for i, mapping := range mappings {
+		servicePort := corev1.ServicePort{Protocol: corev1.Protocol(protocol)}
+		portString := mapping
+		if name, rest, found := strings.Cut(mapping, "="); found {
+			if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+				return nil, fmt.Errorf("invalid port name %q: %s", name, strings.Join(errs, "; "))
+			}
+			servicePort.Name = name
+			portString = rest
+		} else if len(mappings) > 1 {
+			servicePort.Name = fmt.Sprintf("port-%d", i+1)
+		}
+		if names[servicePort.Name] {
+			return nil, fmt.Errorf("port name %q is used more than once", servicePort.Name)
+		}
+		names[servicePort.Name] = true
+
+		portString, targetPortString, hasTarget := strings.Cut(portString, ":")
+		port, err := strconv.Atoi(portString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port mapping %q, should be in format [NAME=]PORT[:TARGET_PORT]", mapping)
+		}
+		servicePort.Port = int32(port)
+		if hasTarget {
+			if len(targetPortString) == 0 {
+				return nil, fmt.Errorf("invalid port mapping %q, should be in format [NAME=]PORT[:TARGET_PORT]", mapping)
+			}
+			servicePort.TargetPort = intstr.Parse(targetPortString)
+		}
+		ports = append(ports, servicePort)
+	}
+	return ports, nil
+}
+
 // parseLabels turns a string representation of a label set into a map[string]string
 func parseLabels(labelSpec string) (map[string]string, error) {
 	if len(labelSpec) == 0 {
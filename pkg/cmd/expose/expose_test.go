@@ -865,6 +865,7 @@ func TestGenerateService(t *testing.T) {
 		selector        string
 		name            string
 		port            string
+		portMappings    []string
 		protocol        string
 		protocols       string
 		targetPort      string
@@ -1729,6 +1730,54 @@ func TestGenerateService(t *testing.T) {
 			clusterIP:  "None",
 			expectErr:  `selector must be specified`,
 		},
+		"port mappings": {
+			selector:     "foo=bar",
+			name:         "test",
+			portMappings: []string{"http=80:http-alt", "metrics=9090", "443:8443"},
+			targetPort:   "1234",
+			expected: &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: corev1.ServiceSpec{
+					Selector: map[string]string{
+						"foo": "bar",
+					},
+					Ports: []corev1.ServicePort{
+						{
+							Name:       "http",
+							Port:       80,
+							Protocol:   "TCP",
+							TargetPort: intstr.FromString("http-alt"),
+						},
+						{
+							Name:       "metrics",
+							Port:       9090,
+							Protocol:   "TCP",
+							TargetPort: intstr.FromInt32(1234),
+						},
+						{
+							Name:       "port-3",
+							Port:       443,
+							Protocol:   "TCP",
+							TargetPort: intstr.FromInt32(8443),
+						},
+					},
+				},
+			},
+		},
+		"invalid port mapping": {
+			selector:     "foo=bar",
+			name:         "test",
+			portMappings: []string{"http=eighty"},
+			expectErr:    `invalid port mapping "http=eighty", should be in format [NAME=]PORT[:TARGET_PORT]`,
+		},
+		"duplicate port mapping names": {
+			selector:     "foo=bar",
+			name:         "test",
+			portMappings: []string{"http=80", "http=8080"},
+			expectErr:    `port name "http" is used more than once`,
+		},
 		"check name": {
 			selector:   "foo=bar,baz=blah",
 			protocol:   "SCTP",
@@ -1745,6 +1794,7 @@ func TestGenerateService(t *testing.T) {
 				Protocol:        test.protocol,
 				Protocols:       test.protocols,
 				Port:            test.port,
+				PortMappings:    test.portMappings,
 				ClusterIP:       test.clusterIP,
 				TargetPort:      test.targetPort,
 				Labels:          test.labels,
@@ -1768,3 +1818,70 @@ func TestGenerateService(t *testing.T) {
 
 	}
 }
+
+func TestAllPortsForObject(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "app",
+					Ports: []corev1.ContainerPort{
+						{Name: "http", ContainerPort: 8080},
+						{ContainerPort: 53, Protocol: corev1.ProtocolUDP},
+					},
+				},
+				{
+					Name: "exporter",
+					Ports: []corev1.ContainerPort{
+						{Name: "metrics", ContainerPort: 9090},
+						{Name: "http", ContainerPort: 8080},
+					},
+				},
+			},
+		},
+	}
+	expected := []corev1.ServicePort{
+		{Name: "http", Port: 8080, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromString("http")},
+		{Name: "udp-53", Port: 53, Protocol: corev1.ProtocolUDP, TargetPort: intstr.FromInt32(53)},
+		{Name: "metrics", Port: 9090, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromString("metrics")},
+	}
+	ports, err := allPortsForObject(pod)
+	require.NoError(t, err)
+	if !apiequality.Semantic.DeepEqual(ports, expected) {
+		t.Errorf("\nexpected:\n%#v\ngot:\n%#v", expected, ports)
+	}
+}
+
+func TestServiceMonitorForService(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test", Labels: map[string]string{"app": "web"}},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "http", Port: 80}, {Port: 81}, {Name: "metrics", Port: 9090}},
+		},
+	}
+	serviceMonitor, err := serviceMonitorForService(service)
+	require.NoError(t, err)
+	expected := map[string]interface{}{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "ServiceMonitor",
+		"metadata": map[string]interface{}{
+			"name":      "app",
+			"namespace": "test",
+			"labels":    map[string]interface{}{"app": "web"},
+		},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+			"endpoints": []interface{}{
+				map[string]interface{}{"port": "http"},
+				map[string]interface{}{"port": "metrics"},
+			},
+		},
+	}
+	if !apiequality.Semantic.DeepEqual(serviceMonitor.Object, expected) {
+		t.Errorf("\nexpected:\n%#v\ngot:\n%#v", expected, serviceMonitor.Object)
+	}
+
+	service.Spec.Ports = []corev1.ServicePort{{Port: 80}}
+	_, err = serviceMonitorForService(service)
+	require.EqualError(t, err, "--with-servicemonitor requires a named service port, use --port=NAME=PORT or --all-ports")
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/liggitt/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/kubectl/pkg/util/color"
+	"k8s.io/kubectl/pkg/util/timeformat"
+)
+
+const conditionsFormat = "conditions"
+
+// ConditionsPrinter prints the status conditions of objects of any type, one
+// condition per line. The message of the conditions not in their healthy
+// state is printed as a warning.
+type ConditionsPrinter struct {
+	NoHeaders bool
+
+	headersPrinted bool
+	withNamespace  bool
+}
+
+// PrintObj prints the conditions of the object, or of the items of a list.
+func (p *ConditionsPrinter) PrintObj(obj runtime.Object, out io.Writer) error {
+	if event, ok := obj.(*metav1.WatchEvent); ok {
+		obj = event.Object.Object
+	}
+	if _, found := out.(*tabwriter.Writer); !found {
+		w := printers.GetNewTabWriter(out)
+		out = w
+		defer w.Flush()
+	}
+
+	objs := []runtime.Object{obj}
+	if meta.IsListType(obj) {
+		var err error
+		if objs, err = meta.ExtractList(obj); err != nil {
+			return err
+		}
+	}
+	items := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+			if err != nil {
+				return err
+			}
+			u = &unstructured.Unstructured{Object: content}
+		}
+		items = append(items, u)
+	}
+
+	if !p.headersPrinted {
+		// the namespaces are printed when the first objects printed are in
+		// several namespaces
+		for _, u := range items {
+			p.withNamespace = p.withNamespace || u.GetNamespace() != items[0].GetNamespace()
+		}
+		if !p.NoHeaders {
+			if p.withNamespace {
+				fmt.Fprint(out, "NAMESPACE\t")
+			}
+			fmt.Fprintln(out, "NAME\tTYPE\tSTATUS\tREASON\tAGE\tMESSAGE")
+		}
+		p.headersPrinted = true
+	}
+
+	for _, u := range items {
+		p.printConditions(u, out)
+	}
+	return nil
+}
+
+func (p *ConditionsPrinter) printConditions(u *unstructured.Unstructured, out io.Writer) {
+	gvk := u.GroupVersionKind()
+	name := strings.ToLower(gvk.Kind)
+	if len(gvk.Group) > 0 {
+		name += "." + gvk.Group
+	}
+	name = printers.EscapeTerminal(name + "/" + u.GetName())
+	namespace := printers.EscapeTerminal(u.GetNamespace())
+
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	if len(conditions) == 0 {
+		if p.withNamespace {
+			fmt.Fprintf(out, "%s\t", namespace)
+		}
+		fmt.Fprintf(out, "%s\t<none>\t\t\t\t\n", name)
+		return
+	}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		field := func(name string) string {
+			value, _, _ := unstructured.NestedString(condition, name)
+			return printers.EscapeTerminal(value)
+		}
+		var transition time.Time
+		if t, err := time.Parse(time.RFC3339, field("lastTransitionTime")); err == nil {
+			transition = t
+		}
+		message := ""
+		if color.ConditionRole(field("type"), field("status")) != color.Good {
+			message, _, _ = strings.Cut(strings.TrimSpace(field("message")), "\n")
+		}
+
+		if p.withNamespace {
+			fmt.Fprintf(out, "%s\t", namespace)
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\t%s\n", name, field("type"), field("status"), field("reason"), timeformat.Timestamp(transition), message)
+		// the name and namespace are only printed on the first line
+		name, namespace = "", ""
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// ConditionsPrintFlags provides default flags necessary for printing the
+// status conditions of objects.
+type ConditionsPrintFlags struct {
+	NoHeaders bool
+}
+
+func (f *ConditionsPrintFlags) AllowedFormats() []string {
+	return []string{conditionsFormat}
+}
+
+// ToPrinter receives an outputFormat and returns a printer capable of
+// printing the conditions of objects.
+// Returns false if the specified outputFormat does not match the conditions format.
+func (f *ConditionsPrintFlags) ToPrinter(outputFormat string) (printers.ResourcePrinter, error) {
+	if outputFormat != conditionsFormat {
+		return nil, genericclioptions.NoCompatiblePrinterError{OutputFormat: &outputFormat, AllowedFormats: f.AllowedFormats()}
+	}
+	return &ConditionsPrinter{NoHeaders: f.NoHeaders}, nil
+}
+
+// AddFlags receives a *cobra.Command reference and binds
+// flags related to conditions printing
+func (f *ConditionsPrintFlags) AddFlags(c *cobra.Command) {}
+
+// NewConditionsPrintFlags returns flags associated with conditions printing,
+// with default values set.
+func NewConditionsPrintFlags() *ConditionsPrintFlags {
+	return &ConditionsPrintFlags{}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubectl/pkg/util/timeformat"
+)
+
+func TestConditionsPrinter(t *testing.T) {
+	defer timeformat.Set(timeformat.Relative)
+	if err := timeformat.Set(timeformat.ISO); err != nil {
+		t.Fatal(err)
+	}
+
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "gear", "namespace": "prod"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "False", "reason": "Reconciling", "message": "waiting for the database\nretrying", "lastTransitionTime": "2024-03-01T12:00:00Z"},
+				map[string]interface{}{"type": "Synced", "status": "True", "reason": "Synced", "message": "all good", "lastTransitionTime": "2024-03-01T11:00:00Z"},
+				map[string]interface{}{"type": "Degraded", "status": "False"},
+			},
+		},
+	}}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings", "namespace": "dev"},
+	}}
+	list := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"},
+		Items:  []unstructured.Unstructured{*widget, *configMap},
+	}
+
+	tests := []struct {
+		name      string
+		noHeaders bool
+		objects   []runtime.Object
+		expected  string
+	}{
+		{
+			name:    "list",
+			objects: []runtime.Object{list},
+			expected: `NAMESPACE   NAME                      TYPE       STATUS   REASON        AGE                    MESSAGE
+prod        widget.example.com/gear   Ready      False    Reconciling   2024-03-01T12:00:00Z   waiting for the database
+                                      Synced     True     Synced        2024-03-01T11:00:00Z
+                                      Degraded   False                  <unknown>
+dev         configmap/settings        <none>
+`,
+		},
+		{
+			name:      "single object without headers",
+			noHeaders: true,
+			objects:   []runtime.Object{configMap},
+			expected: `configmap/settings   <none>
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			printer := &ConditionsPrinter{NoHeaders: test.noHeaders}
+			buf := &bytes.Buffer{}
+			for _, obj := range test.objects {
+				if err := printer.PrintObj(obj, buf); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// the tabwriter pads the empty trailing columns
+			got := regexp.MustCompile(` +\n`).ReplaceAllString(buf.String(), "\n")
+			if got != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, got)
+			}
+		})
+	}
+}
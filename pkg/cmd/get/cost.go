@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/util/homedir"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// hoursPerMonth is the number of hours the monthly costs are estimated for.
+const hoursPerMonth = 730
+
+// PriceSheet is the content of the price sheet file used to estimate the
+// cost of workloads with --show-cost.
+type PriceSheet struct {
+	// Currency is printed after the costs.
+	Currency string `json:"currency,omitempty"`
+	// Resources are the prices of the requested resources. The resources
+	// without a price are free.
+	Resources []ResourcePrice `json:"resources,omitempty"`
+	// Exec is a command printing the price sheet, such as a plugin reading
+	// the prices of a cloud provider, used instead of the prices of the file.
+	Exec *PriceSheetExec `json:"exec,omitempty"`
+}
+
+// ResourcePrice is the price of a resource.
+type ResourcePrice struct {
+	Name v1.ResourceName `json:"name"`
+	// Unit is the quantity of the resource the price is for, 1 by default,
+	// such as 1Gi for memory.
+	Unit *apiresource.Quantity `json:"unit,omitempty"`
+	// HourlyPrice is the price of a unit of the resource for an hour.
+	HourlyPrice float64 `json:"hourlyPrice"`
+}
+
+// PriceSheetExec is a command printing a price sheet in YAML or JSON.
+type PriceSheetExec struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// DefaultPriceSheetFile returns the default location of the price sheet,
+// next to the default kubeconfig.
+func DefaultPriceSheetFile() string {
+	return filepath.Join(homedir.HomeDir(), ".kube", "pricing.yaml")
+}
+
+// LoadPriceSheet reads the price sheet at path, running its command when it
+// has one.
+func LoadPriceSheet(path string) (*PriceSheet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the price sheet: %v", err)
+	}
+	sheet, err := parsePriceSheet(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the price sheet in %s: %v", path, err)
+	}
+	if sheet.Exec == nil {
+		return sheet, nil
+	}
+
+	command := sheet.Exec.Command
+	cmd := exec.Command(command, sheet.Exec.Args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	if data, err = cmd.Output(); err != nil {
+		return nil, fmt.Errorf("unable to get the price sheet from %s: %v: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	if sheet, err = parsePriceSheet(data); err != nil {
+		return nil, fmt.Errorf("unable to parse the price sheet printed by %s: %v", command, err)
+	}
+	if sheet.Exec != nil {
+		return nil, fmt.Errorf("the price sheet printed by %s must not run a command", command)
+	}
+	return sheet, nil
+}
+
+func parsePriceSheet(data []byte) (*PriceSheet, error) {
+	sheet := &PriceSheet{}
+	if err := yaml.UnmarshalStrict(data, sheet); err != nil {
+		return nil, err
+	}
+	for _, price := range sheet.Resources {
+		if len(price.Name) == 0 {
+			return nil, fmt.Errorf("a resource has no name")
+		}
+		if price.Unit != nil && price.Unit.Sign() <= 0 {
+			return nil, fmt.Errorf("the unit of %s must be positive", price.Name)
+		}
+		if price.HourlyPrice < 0 {
+			return nil, fmt.Errorf("the price of %s must not be negative", price.Name)
+		}
+	}
+	if sheet.Exec != nil && len(sheet.Exec.Command) == 0 {
+		return nil, fmt.Errorf("exec has no command")
+	}
+	return sheet, nil
+}
+
+// costPrinter adds the estimated monthly cost of the objects to the tables
+// printed by the server, from the resources requested by their pods.
+type costPrinter struct {
+	Delegate printers.ResourcePrinter
+	Prices   *PriceSheet
+}
+
+func (p *costPrinter) PrintObj(obj runtime.Object, writer io.Writer) error {
+	table, ok := obj.(*metav1.Table)
+	if event, isEvent := obj.(*metav1.WatchEvent); isEvent {
+		table, ok = event.Object.Object.(*metav1.Table)
+	}
+	if ok {
+		p.addCostColumn(table)
+	}
+	return p.Delegate.PrintObj(obj, writer)
+}
+
+func (p *costPrinter) addCostColumn(table *metav1.Table) {
+	table.ColumnDefinitions = append(table.ColumnDefinitions, metav1.TableColumnDefinition{
+		Name:        "Cost/Month",
+		Type:        "string",
+		Description: "The estimated monthly cost of the resources requested by the pods of the object.",
+	})
+	for i := range table.Rows {
+		row := &table.Rows[i]
+		cell := "<none>"
+		if cost, ok := p.estimateCost(row.Object.Object); ok {
+			cell = strings.TrimSpace(fmt.Sprintf("%.2f %s", cost, p.Prices.Currency))
+		}
+		row.Cells = append(row.Cells, cell)
+	}
+}
+
+// estimateCost returns the monthly cost of the resources requested by the
+// pods of obj, for the objects which are pods or have a pod template.
+func (p *costPrinter) estimateCost(obj runtime.Object) (float64, bool) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return 0, false
+	}
+
+	specPath := []string{"spec", "template", "spec"}
+	replicas := int64(1)
+	switch u.GetKind() {
+	case "Pod":
+		specPath = []string{"spec"}
+		phase, _, _ := unstructured.NestedString(u.Object, "status", "phase")
+		if phase == string(v1.PodSucceeded) || phase == string(v1.PodFailed) {
+			replicas = 0
+		}
+	case "DaemonSet":
+		replicas, _, _ = unstructured.NestedInt64(u.Object, "status", "desiredNumberScheduled")
+	case "Job":
+		replicas, _, _ = unstructured.NestedInt64(u.Object, "status", "active")
+	default:
+		if value, found, err := unstructured.NestedInt64(u.Object, "spec", "replicas"); found && err == nil {
+			replicas = value
+		}
+	}
+	spec, found, err := unstructured.NestedMap(u.Object, specPath...)
+	if !found || err != nil {
+		return 0, false
+	}
+	pod := &v1.Pod{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, &pod.Spec); err != nil {
+		return 0, false
+	}
+
+	requests, _ := resourcehelper.PodRequestsAndLimits(pod)
+	hourly := 0.0
+	for _, price := range p.Prices.Resources {
+		quantity, ok := requests[price.Name]
+		if !ok {
+			continue
+		}
+		unit := 1.0
+		if price.Unit != nil {
+			unit = price.Unit.AsApproximateFloat64()
+		}
+		hourly += quantity.AsApproximateFloat64() / unit * price.HourlyPrice
+	}
+	return hourly * hoursPerMonth * float64(replicas), true
+}
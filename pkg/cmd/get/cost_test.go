@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+const testPriceSheet = `currency: USD
+resources:
+- name: cpu
+  hourlyPrice: 0.04
+- name: memory
+  unit: 1Gi
+  hourlyPrice: 0.005
+`
+
+func TestLoadPriceSheet(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pricing.yaml")
+	if err := os.WriteFile(path, []byte(testPriceSheet), 0600); err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := LoadPriceSheet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Currency != "USD" || len(sheet.Resources) != 2 || sheet.Resources[1].Unit.String() != "1Gi" {
+		t.Errorf("unexpected price sheet %#v", sheet)
+	}
+
+	invalid := map[string]string{
+		"negative price": "resources:\n- name: cpu\n  hourlyPrice: -1\n",
+		"zero unit":      "resources:\n- name: memory\n  unit: \"0\"\n  hourlyPrice: 1\n",
+		"unknown field":  "resources:\n- name: cpu\n  price: 1\n",
+	}
+	for name, content := range invalid {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPriceSheet(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	content := "exec:\n  command: sh\n  args: [\"-c\", \"cat " + filepath.Join(dir, "cloud.yaml") + "\"]\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cloud.yaml"), []byte("currency: EUR\nresources:\n- name: cpu\n  hourlyPrice: 0.03\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if sheet, err = LoadPriceSheet(path); err != nil {
+		t.Fatal(err)
+	}
+	if sheet.Currency != "EUR" || len(sheet.Resources) != 1 || sheet.Exec != nil {
+		t.Errorf("unexpected price sheet from the command %#v", sheet)
+	}
+}
+
+func TestCostPrinter(t *testing.T) {
+	sheet, err := parsePriceSheet([]byte(testPriceSheet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	podSpec := map[string]interface{}{
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app", "resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "500m", "memory": "2Gi"}}},
+			map[string]interface{}{"name": "sidecar", "resources": map[string]interface{}{"requests": map[string]interface{}{"cpu": "500m"}}},
+		},
+	}
+	object := func(kind string, spec, status map[string]interface{}) kruntime.RawExtension {
+		obj := map[string]interface{}{"apiVersion": "v1", "kind": kind, "metadata": map[string]interface{}{"name": "foo"}}
+		if spec != nil {
+			obj["spec"] = spec
+		}
+		if status != nil {
+			obj["status"] = status
+		}
+		return kruntime.RawExtension{Object: &unstructured.Unstructured{Object: obj}}
+	}
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name", Type: "string"}},
+		Rows: []metav1.TableRow{
+			// a pod requesting 1 CPU and 2Gi costs 0.05 an hour
+			{Cells: []interface{}{"deployment"}, Object: object("Deployment", map[string]interface{}{"replicas": int64(3), "template": map[string]interface{}{"spec": podSpec}}, nil)},
+			{Cells: []interface{}{"daemonset"}, Object: object("DaemonSet", map[string]interface{}{"template": map[string]interface{}{"spec": podSpec}}, map[string]interface{}{"desiredNumberScheduled": int64(2)})},
+			{Cells: []interface{}{"pod"}, Object: object("Pod", podSpec, map[string]interface{}{"phase": "Running"})},
+			{Cells: []interface{}{"completed-pod"}, Object: object("Pod", podSpec, map[string]interface{}{"phase": "Succeeded"})},
+			{Cells: []interface{}{"configmap"}, Object: object("ConfigMap", nil, nil)},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	printer := &costPrinter{Delegate: printers.NewTablePrinter(printers.PrintOptions{}), Prices: sheet}
+	if err := printer.PrintObj(table, out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `NAME            COST/MONTH
+deployment      109.50 USD
+daemonset       73.00 USD
+pod             36.50 USD
+completed-pod   0.00 USD
+configmap       <none>
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestShowCostValidation(t *testing.T) {
+	for _, format := range []string{"", "yaml"} {
+		o := NewGetOptions("kubectl", genericiooptions.NewTestIOStreamsDiscard())
+		o.ShowCost = true
+		*o.PrintFlags.OutputFormat = format
+		if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "--show-cost") {
+			t.Errorf("-o %q: expected --show-cost to be rejected, got %v", format, err)
+		}
+	}
+	o := NewGetOptions("kubectl", genericiooptions.NewTestIOStreamsDiscard())
+	o.ShowCost = true
+	*o.PrintFlags.OutputFormat = "wide"
+	if err := o.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
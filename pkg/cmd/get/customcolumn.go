@@ -24,6 +24,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/liggitt/tabwriter"
 
@@ -33,6 +34,15 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/kubectl/pkg/util/timeformat"
+)
+
+const (
+	// ColumnTypeDuration prints a time as the time elapsed since, and a number
+	// of seconds or a duration in human-readable form.
+	ColumnTypeDuration = "duration"
+	// ColumnTypeTimestamp prints a time in the --time-format.
+	ColumnTypeTimestamp = "timestamp"
 )
 
 var jsonRegexp = regexp.MustCompile(`^\{\.?([^{}]+)\}$|^\.?([^{}]+)$`)
@@ -71,6 +81,9 @@ func RelaxedJSONPathExpression(pathExpression string) (string, error) {
 //
 //	NAME               API_VERSION
 //	foo                bar
+//
+// The field spec can be followed by the type of the column, such as in
+// AGE:metadata.creationTimestamp:duration.
 func NewCustomColumnsPrinterFromSpec(spec string, decoder runtime.Decoder, noHeaders bool) (*CustomColumnsPrinter, error) {
 	if len(spec) == 0 {
 		return nil, fmt.Errorf("custom-columns format specified but no custom columns given")
@@ -82,11 +95,12 @@ func NewCustomColumnsPrinterFromSpec(spec string, decoder runtime.Decoder, noHea
 		if len(colSpec) != 2 {
 			return nil, fmt.Errorf("unexpected custom-columns spec: %s, expected <header>:<json-path-expr>", parts[ix])
 		}
-		spec, err := RelaxedJSONPathExpression(colSpec[1])
+		fieldSpec, columnType := splitColumnType(colSpec[1])
+		spec, err := RelaxedJSONPathExpression(fieldSpec)
 		if err != nil {
 			return nil, err
 		}
-		columns[ix] = Column{Header: colSpec[0], FieldSpec: spec}
+		columns[ix] = Column{Header: colSpec[0], FieldSpec: spec, Type: columnType}
 	}
 	return &CustomColumnsPrinter{Columns: columns, Decoder: decoder, NoHeaders: noHeaders}, nil
 }
@@ -124,13 +138,15 @@ func NewCustomColumnsPrinterFromTemplate(templateReader io.Reader, decoder runti
 
 	columns := make([]Column, len(headers))
 	for ix := range headers {
-		spec, err := RelaxedJSONPathExpression(specs[ix])
+		fieldSpec, columnType := splitColumnType(specs[ix])
+		spec, err := RelaxedJSONPathExpression(fieldSpec)
 		if err != nil {
 			return nil, err
 		}
 		columns[ix] = Column{
 			Header:    headers[ix],
 			FieldSpec: spec,
+			Type:      columnType,
 		}
 	}
 	return &CustomColumnsPrinter{Columns: columns, Decoder: decoder, NoHeaders: false}, nil
@@ -143,6 +159,60 @@ type Column struct {
 	// The pointer to the field in the object to print in JSONPath form
 	// e.g. {.ObjectMeta.Name}, see pkg/util/jsonpath for more details.
 	FieldSpec string
+	// The type of the values, ColumnTypeDuration or ColumnTypeTimestamp, or
+	// empty to print the values as is.
+	Type string
+}
+
+// splitColumnType splits the type of the column from the end of the field
+// spec, as in {.status.startTime}:duration.
+func splitColumnType(spec string) (string, string) {
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		switch columnType := spec[i+1:]; columnType {
+		case ColumnTypeDuration, ColumnTypeTimestamp:
+			return spec[:i], columnType
+		}
+	}
+	return spec, ""
+}
+
+// formatValue formats a value of a column of the given type. Values that are
+// not times or durations are printed as is.
+func formatValue(columnType string, value interface{}) string {
+	if len(columnType) == 0 {
+		return fmt.Sprint(value)
+	}
+	switch v := value.(type) {
+	case metav1.Time:
+		value = v.Time
+	case *metav1.Time:
+		if v != nil {
+			value = v.Time
+		}
+	case int32:
+		value = int64(v)
+	case *int64:
+		if v != nil {
+			value = *v
+		}
+	}
+
+	switch columnType {
+	case ColumnTypeDuration:
+		if formatted, err := timeformat.Duration(value); err == nil {
+			return formatted
+		}
+	case ColumnTypeTimestamp:
+		if t, ok := value.(time.Time); ok {
+			return timeformat.Timestamp(t)
+		}
+		if s, ok := value.(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				return timeformat.Timestamp(t)
+			}
+		}
+	}
+	return fmt.Sprint(value)
 }
 
 // CustomColumnPrinter is a printer that knows how to print arbitrary columns
@@ -252,7 +322,7 @@ func (s *CustomColumnsPrinter) printOneObject(obj runtime.Object, parsers []*jso
 		}
 		for arrIx := range values {
 			for valIx := range values[arrIx] {
-				valueStrings = append(valueStrings, printers.EscapeTerminal(fmt.Sprint(values[arrIx][valIx].Interface())))
+				valueStrings = append(valueStrings, printers.EscapeTerminal(formatValue(s.Columns[ix].Type, values[arrIx][valIx].Interface())))
 			}
 		}
 		columns[ix] = strings.Join(valueStrings, ",")
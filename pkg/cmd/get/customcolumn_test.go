@@ -24,9 +24,11 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/timeformat"
 )
 
 // UniversalDecoder call must specify parameter versions; otherwise it will decode to internal versions.
@@ -110,6 +112,26 @@ func TestNewColumnPrinterFromSpec(t *testing.T) {
 				},
 			},
 		},
+		{
+			spec: "AGE:metadata.creationTimestamp:duration,CREATED:{.metadata.creationTimestamp}:timestamp,SELECTOR:{.metadata.labels.a:b}",
+			name: "types",
+			expectedColumns: []Column{
+				{
+					Header:    "AGE",
+					FieldSpec: "{.metadata.creationTimestamp}",
+					Type:      ColumnTypeDuration,
+				},
+				{
+					Header:    "CREATED",
+					FieldSpec: "{.metadata.creationTimestamp}",
+					Type:      ColumnTypeTimestamp,
+				},
+				{
+					Header:    "SELECTOR",
+					FieldSpec: "{.metadata.labels.a:b}",
+				},
+			},
+		},
 		{
 			spec:      "API_VERSION:apiVersion",
 			name:      "no-headers",
@@ -348,6 +370,43 @@ foo    baz           <none>
 	}
 }
 
+func TestColumnPrintTypes(t *testing.T) {
+	defer timeformat.Set(timeformat.Relative)
+	if err := timeformat.Set(timeformat.ISO); err != nil {
+		t.Fatal(err)
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":              "pi",
+			"creationTimestamp": "2024-03-01T12:00:00Z",
+		},
+		"spec": map[string]interface{}{
+			"activeDeadlineSeconds": int64(3600),
+		},
+	}}
+	printer := &CustomColumnsPrinter{
+		Columns: []Column{
+			{Header: "NAME", FieldSpec: "{.metadata.name}", Type: ColumnTypeTimestamp},
+			{Header: "CREATED", FieldSpec: "{.metadata.creationTimestamp}", Type: ColumnTypeTimestamp},
+			{Header: "DEADLINE", FieldSpec: "{.spec.activeDeadlineSeconds}", Type: ColumnTypeDuration},
+		},
+		Decoder: decoder,
+	}
+	buffer := &bytes.Buffer{}
+	if err := printer.PrintObj(obj, buffer); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `NAME   CREATED                DEADLINE
+pi     2024-03-01T12:00:00Z   60m
+`
+	if buffer.String() != expected {
+		t.Errorf("\nexpected:\n'%s'\nsaw\n'%s'\n", expected, buffer.String())
+	}
+}
+
 // this mimics how resource/get.go calls the customcolumn printer
 func TestIndividualPrintObjOnExistingTabWriter(t *testing.T) {
 	columns := []Column{
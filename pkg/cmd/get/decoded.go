@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const decodedFormat = "decoded"
+
+// sensitiveKeyWords are the words of the keys whose values are masked.
+var sensitiveKeyWords = []string{"password", "passwd", "token", "secret", "key", "credential"}
+
+// DecodedPrinter prints the data of secrets decoded. The values which are
+// not text are replaced by their size, and the values looking like
+// passwords, tokens or keys are masked unless Reveal is set.
+type DecodedPrinter struct {
+	ShowKeysOnly bool
+	Reveal       bool
+
+	printed int
+}
+
+// PrintObj prints the decoded data of the secret, or of the secrets of a list.
+func (p *DecodedPrinter) PrintObj(obj runtime.Object, out io.Writer) error {
+	if event, ok := obj.(*metav1.WatchEvent); ok {
+		obj = event.Object.Object
+	}
+
+	objs := []runtime.Object{obj}
+	if meta.IsListType(obj) {
+		var err error
+		if objs, err = meta.ExtractList(obj); err != nil {
+			return err
+		}
+	}
+	for _, obj := range objs {
+		secret, err := toSecret(obj)
+		if err != nil {
+			return err
+		}
+		if p.printed > 0 {
+			fmt.Fprintln(out)
+		}
+		p.printSecret(secret, out)
+		p.printed++
+	}
+	return nil
+}
+
+func toSecret(obj runtime.Object) (*corev1.Secret, error) {
+	if secret, ok := obj.(*corev1.Secret); ok {
+		return secret, nil
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok || u.GroupVersionKind() != corev1.SchemeGroupVersion.WithKind("Secret") {
+		return nil, fmt.Errorf("the decoded output format only supports secrets, got %s", obj.GetObjectKind().GroupVersionKind().Kind)
+	}
+	secret := &corev1.Secret{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+func (p *DecodedPrinter) printSecret(secret *corev1.Secret, out io.Writer) {
+	fmt.Fprintf(out, "Name:       %s\n", secret.Name)
+	if len(secret.Namespace) > 0 {
+		fmt.Fprintf(out, "Namespace:  %s\n", secret.Namespace)
+	}
+	fmt.Fprintf(out, "Type:       %s\n", secret.Type)
+	fmt.Fprintln(out, "Data:")
+	if len(secret.Data) == 0 {
+		fmt.Fprintln(out, "  <none>")
+		return
+	}
+
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := secret.Data[key]
+		switch {
+		case p.ShowKeysOnly:
+			fmt.Fprintf(out, "  %s: <%d bytes>\n", key, len(value))
+		case !isText(value):
+			fmt.Fprintf(out, "  %s: <binary, %d bytes>\n", key, len(value))
+		case !p.Reveal && isSensitive(key, string(value)):
+			fmt.Fprintf(out, "  %s: <masked, %d bytes>\n", key, len(value))
+		case strings.Contains(strings.TrimSuffix(string(value), "\n"), "\n"):
+			fmt.Fprintf(out, "  %s: |\n", key)
+			for _, line := range strings.Split(strings.TrimSuffix(string(value), "\n"), "\n") {
+				fmt.Fprintf(out, "    %s\n", line)
+			}
+		default:
+			fmt.Fprintf(out, "  %s: %s\n", key, value)
+		}
+	}
+}
+
+// isText returns whether the value is printable text, which can be written
+// to a terminal as is.
+func isText(value []byte) bool {
+	if !utf8.Valid(value) {
+		return false
+	}
+	for _, r := range string(value) {
+		if !unicode.IsPrint(r) && r != '\n' && r != '\t' && r != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// isSensitive returns whether the value looks like a password, a token or a
+// key, from the name of its key or from the entropy of its words: random
+// words use most of the characters they could.
+func isSensitive(key, value string) bool {
+	key = strings.ToLower(key)
+	for _, word := range sensitiveKeyWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	for _, word := range strings.Fields(value) {
+		if len(word) >= 8 && entropy(word) >= math.Min(3.5, 0.95*math.Log2(float64(len(word)))) {
+			return true
+		}
+	}
+	return false
+}
+
+// entropy returns the Shannon entropy of the value, in bits per byte.
+func entropy(value string) float64 {
+	counts := map[byte]int{}
+	for i := 0; i < len(value); i++ {
+		counts[value[i]]++
+	}
+	result := 0.0
+	for _, count := range counts {
+		frequency := float64(count) / float64(len(value))
+		result -= frequency * math.Log2(frequency)
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// DecodedPrintFlags provides default flags necessary for printing the
+// decoded data of secrets.
+type DecodedPrintFlags struct {
+	ShowKeysOnly bool
+	Reveal       bool
+}
+
+func (f *DecodedPrintFlags) AllowedFormats() []string {
+	return []string{decodedFormat}
+}
+
+// ToPrinter receives an outputFormat and returns a printer capable of
+// printing the decoded data of secrets.
+// Returns false if the specified outputFormat does not match the decoded format.
+func (f *DecodedPrintFlags) ToPrinter(outputFormat string) (printers.ResourcePrinter, error) {
+	if outputFormat != decodedFormat {
+		return nil, genericclioptions.NoCompatiblePrinterError{OutputFormat: &outputFormat, AllowedFormats: f.AllowedFormats()}
+	}
+	return &DecodedPrinter{ShowKeysOnly: f.ShowKeysOnly, Reveal: f.Reveal}, nil
+}
+
+// AddFlags receives a *cobra.Command reference and binds
+// flags related to decoded printing
+func (f *DecodedPrintFlags) AddFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&f.ShowKeysOnly, "show-keys-only", f.ShowKeysOnly, "Print the keys of secrets and the size of their values, without the values. Implies -o decoded.")
+	c.Flags().BoolVar(&f.Reveal, "reveal", f.Reveal, "When using the decoded output format, also print the values looking like passwords, tokens or keys instead of masking them.")
+}
+
+// NewDecodedPrintFlags returns flags associated with decoded printing, with
+// default values set.
+func NewDecodedPrintFlags() *DecodedPrintFlags {
+	return &DecodedPrintFlags{}
+}
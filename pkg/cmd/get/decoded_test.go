@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestDecodedPrinter(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "prod"},
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"username":    []byte("admin"),
+			"db-password": []byte("hunter2"),
+			"url":         []byte("mongodb://x7Kq9pLmZ2vR@db:27017"),
+			"config":      []byte("host: db\nport: 5432\n"),
+			"blob":        {0xff, 0x00, 0x1b},
+		},
+	}
+	encoded := map[string]interface{}{}
+	for key, value := range map[string]string{"user": "viewer", "escape": "\x1b[31mred"} {
+		encoded[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	unstructuredSecret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": "viewer", "namespace": "prod"},
+		"type":       "Opaque",
+		"data":       encoded,
+	}}
+	list := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"},
+		Items: []unstructured.Unstructured{*unstructuredSecret, {Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "empty"},
+			"type":       "Opaque",
+		}}},
+	}
+
+	tests := []struct {
+		name         string
+		showKeysOnly bool
+		reveal       bool
+		objects      []runtime.Object
+		expected     string
+		expectedErr  string
+	}{
+		{
+			name:    "masked",
+			objects: []runtime.Object{secret},
+			expected: `Name:       db
+Namespace:  prod
+Type:       Opaque
+Data:
+  blob: <binary, 3 bytes>
+  config: |
+    host: db
+    port: 5432
+  db-password: <masked, 7 bytes>
+  url: <masked, 31 bytes>
+  username: admin
+`,
+		},
+		{
+			name:    "revealed",
+			reveal:  true,
+			objects: []runtime.Object{secret},
+			expected: `Name:       db
+Namespace:  prod
+Type:       Opaque
+Data:
+  blob: <binary, 3 bytes>
+  config: |
+    host: db
+    port: 5432
+  db-password: hunter2
+  url: mongodb://x7Kq9pLmZ2vR@db:27017
+  username: admin
+`,
+		},
+		{
+			name:         "keys only",
+			showKeysOnly: true,
+			objects:      []runtime.Object{secret},
+			expected: `Name:       db
+Namespace:  prod
+Type:       Opaque
+Data:
+  blob: <3 bytes>
+  config: <20 bytes>
+  db-password: <7 bytes>
+  url: <31 bytes>
+  username: <5 bytes>
+`,
+		},
+		{
+			name:    "list",
+			objects: []runtime.Object{list},
+			expected: `Name:       viewer
+Namespace:  prod
+Type:       Opaque
+Data:
+  escape: <binary, 8 bytes>
+  user: viewer
+
+Name:       empty
+Type:       Opaque
+Data:
+  <none>
+`,
+		},
+		{
+			name: "not a secret",
+			objects: []runtime.Object{&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "settings"},
+			}}},
+			expectedErr: "the decoded output format only supports secrets, got ConfigMap",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &DecodedPrinter{ShowKeysOnly: tc.showKeysOnly, Reveal: tc.reveal}
+			out := &bytes.Buffer{}
+			for _, obj := range tc.objects {
+				if err := p.PrintObj(obj, out); err != nil {
+					if err.Error() != tc.expectedErr {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					return
+				}
+			}
+			if len(tc.expectedErr) > 0 {
+				t.Fatalf("expected error %q", tc.expectedErr)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
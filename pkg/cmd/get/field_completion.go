@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// fieldPathCompletions returns the JSONPath expressions of the fields of the resource type
+// specified by the first argument which begin with toComplete, such as ".spec.replicas".
+// The fields are read from the OpenAPI schema of the resource. Objects are completed with a
+// trailing '.' and arrays with their first item, so that their fields can be completed next.
+func fieldPathCompletions(f cmdutil.Factory, args []string, toComplete string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil
+	}
+	resourceType := strings.Split(args[0], "/")[0]
+	gvk, err := mapper.KindFor(schema.ParseGroupResource(resourceType).WithVersion(""))
+	if err != nil {
+		return nil
+	}
+	resources, err := f.OpenAPISchema()
+	if err != nil {
+		return nil
+	}
+	s := resources.LookupResource(gvk)
+	if s == nil {
+		return nil
+	}
+
+	// the braces of the template are optional
+	braces := strings.HasPrefix(toComplete, "{")
+	path := strings.TrimPrefix(toComplete, "{")
+	if len(path) == 0 {
+		path = "."
+	}
+	if !strings.HasPrefix(path, ".") {
+		return nil
+	}
+	segments := strings.Split(path[1:], ".")
+	for _, segment := range segments[:len(segments)-1] {
+		if s = fieldSchema(s, segment); s == nil {
+			return nil
+		}
+	}
+	kind, ok := resolveSchema(s).(*proto.Kind)
+	if !ok {
+		return nil
+	}
+
+	partial := segments[len(segments)-1]
+	base := path[:len(path)-len(partial)]
+	var comps []string
+	for _, name := range kind.Keys() {
+		if !strings.HasPrefix(name, partial) {
+			continue
+		}
+		comp := base + name
+		switch field := resolveSchema(kind.Fields[name]).(type) {
+		case *proto.Kind, *proto.Map:
+			comp += "."
+		case *proto.Array:
+			comp += "[0]"
+			if _, ok := resolveSchema(field.SubType).(*proto.Kind); ok {
+				comp += "."
+			}
+		default:
+			if braces {
+				comp += "}"
+			}
+		}
+		if braces {
+			comp = "{" + comp
+		}
+		comps = append(comps, comp)
+	}
+	return comps
+}
+
+// fieldSchema returns the schema of the field of s named by the segment of
+// a JSONPath expression, such as "containers[0]", or nil if there is none.
+func fieldSchema(s proto.Schema, segment string) proto.Schema {
+	name, index, _ := strings.Cut(segment, "[")
+	switch s := resolveSchema(s).(type) {
+	case *proto.Kind:
+		field, ok := s.Fields[name]
+		if !ok {
+			return nil
+		}
+		if len(index) > 0 {
+			array, ok := resolveSchema(field).(*proto.Array)
+			if !ok {
+				return nil
+			}
+			return array.SubType
+		}
+		return field
+	case *proto.Map:
+		// the segment is a key of the map
+		return s.SubType
+	}
+	return nil
+}
+
+func resolveSchema(s proto.Schema) proto.Schema {
+	for {
+		ref, ok := s.(proto.Reference)
+		if !ok {
+			return s
+		}
+		s = ref.SubSchema()
+	}
+}
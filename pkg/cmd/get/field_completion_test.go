@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	openapitesting "k8s.io/kubectl/pkg/util/openapi/testing"
+)
+
+func TestFieldPathCompletion(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "top level fields",
+			args:     []string{"pods", "--sort-by", ""},
+			expected: []string{".apiVersion", ".kind", ".metadata.", ".spec.", ".status."},
+		},
+		{
+			name:     "nested fields",
+			args:     []string{"pods", "--sort-by", ".metadata.creation"},
+			expected: []string{".metadata.creationTimestamp"},
+		},
+		{
+			name:     "array fields",
+			args:     []string{"pod/foo", "--sort-by", ".spec.containers[0].ima"},
+			expected: []string{".spec.containers[0].image", ".spec.containers[0].imagePullPolicy"},
+		},
+		{
+			name:     "arrays",
+			args:     []string{"pods", "--sort-by", ".spec.initC"},
+			expected: []string{".spec.initContainers[0]."},
+		},
+		{
+			name:     "braces",
+			args:     []string{"pods", "--sort-by", "{.spec.nodeN"},
+			expected: []string{"{.spec.nodeName}"},
+		},
+		{
+			name:     "unknown field",
+			args:     []string{"pods", "--sort-by", ".spec.unknown.f"},
+			expected: []string{},
+		},
+		{
+			name:     "no resource type",
+			args:     []string{"--sort-by", ".m"},
+			expected: []string{},
+		},
+		{
+			name:     "custom columns",
+			args:     []string{"pods", "-o", "custom-columns=NAME:.metadata.name,NODE:.spec.nodeN"},
+			expected: []string{"custom-columns=NAME:.metadata.name,NODE:.spec.nodeName"},
+		},
+		{
+			name:     "custom column header",
+			args:     []string{"pods", "-o", "custom-columns=NAME:.metadata.name,NO"},
+			expected: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			tf.OpenAPISchemaFunc = openapitesting.CreateOpenAPISchemaFunc(filepath.Join("..", "..", "..", "testdata", "openapi", "swagger.json"))
+
+			streams, _, out, _ := genericiooptions.NewTestIOStreams()
+			cmd := NewCmdGet("kubectl", tf, streams)
+			cmd.SetOut(out)
+			cmd.SetArgs(append([]string{"__complete"}, test.args...))
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			comps := []string{}
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				// the last line is the directive
+				if !strings.HasPrefix(line, ":") {
+					comps = append(comps, line)
+				}
+			}
+			if !reflect.DeepEqual(test.expected, comps) {
+				t.Errorf("expected completions %v, got %v", test.expected, comps)
+			}
+		})
+	}
+}
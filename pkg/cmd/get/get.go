@@ -24,6 +24,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/liggitt/tabwriter"
 	"github.com/spf13/cobra"
 
 	corev1 "k8s.io/api/core/v1"
@@ -32,6 +33,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -41,10 +43,10 @@ import (
 	"k8s.io/cli-runtime/pkg/resource"
 	kubernetesscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
-	watchtools "k8s.io/client-go/tools/watch"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/rawhttp"
 	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/color"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/interrupt"
 	"k8s.io/kubectl/pkg/util/slice"
@@ -66,6 +68,9 @@ type GetOptions struct {
 	Watch     bool
 	WatchOnly bool
 	ChunkSize int64
+	// WatchReconnect resumes the watch when it is closed, instead of
+	// exiting.
+	WatchReconnect bool
 
 	OutputWatchEvents bool
 
@@ -81,6 +86,23 @@ type GetOptions struct {
 
 	NoHeaders      bool
 	IgnoreNotFound bool
+	// RequestEncoding is the encoding built-in resources are requested in,
+	// when they are not printed by the server. Protobuf is opt-in, as the
+	// objects are decoded into the types known to this client, dropping the
+	// fields it does not know.
+	RequestEncoding string
+	// ShowProgress shows the number of resources listed so far while the
+	// next chunk of a list is fetched.
+	ShowProgress bool
+	// Pick asks the user to pick the object to get when only its type is
+	// given, with PickFn.
+	Pick   bool
+	PickFn cmdutil.PickFunc
+	// ShowCost adds the estimated monthly cost of the objects, from the
+	// resources requested by their pods and the prices of PricingFile.
+	ShowCost    bool
+	PricingFile string
+	priceSheet  *PriceSheet
 
 	genericiooptions.IOStreams
 }
@@ -92,10 +114,21 @@ var (
 		Prints a table of the most important information about the specified resources.
 		You can filter the list using a label selector and the --selector flag. If the
 		desired resource type is namespaced you will only see results in your current
-		namespace unless you pass --all-namespaces.
+		namespace unless you pass --all-namespaces. Objects given by name with
+		--all-namespaces are searched in all the namespaces, and when a name is found in
+		several of them the namespace is asked for on a terminal.
 
 		By specifying the output as 'template' and providing a Go template as the value
-		of the --template flag, you can filter the attributes of the fetched resources.`))
+		of the --template flag, you can filter the attributes of the fetched resources.
+
+		Large lists of a single resource type are fetched in chunks of --chunk-size
+		resources, and each chunk is printed as soon as it arrives. Sorting with
+		--sort-by requires the whole list to be fetched before printing.
+
+		The AGE column is printed in the format of the global --time-format flag. A
+		custom column followed by :duration prints a time as the time elapsed since, or
+		a number of seconds as a duration, and a custom column followed by :timestamp
+		prints a time in the --time-format.`))
 
 	getExample = templates.Examples(i18n.T(`
 		# List all pods in ps output format
@@ -125,6 +158,24 @@ var (
 		# List resource information in custom columns
 		kubectl get pod test-pod -o custom-columns=CONTAINER:.spec.containers[0].name,IMAGE:.spec.containers[0].image
 
+		# List the pods with their creation time in ISO 8601 format
+		kubectl get pods --time-format=iso
+
+		# List the jobs with the time elapsed since they started
+		kubectl get jobs -o custom-columns=NAME:.metadata.name,RUNNING:.status.startTime:duration
+
+		# List the status conditions of the deployments, with the messages of the unhealthy ones
+		kubectl get deployments -o conditions
+
+		# Display the decoded data of a secret, masking the passwords, tokens and keys
+		kubectl get secret db-credentials -o decoded
+
+		# List the deployments with the estimated monthly cost of their pods, from the prices of ~/.kube/pricing.yaml
+		kubectl get deployments -o wide --show-cost
+
+		# List the keys of a secret, without their values
+		kubectl get secret db-credentials --show-keys-only
+
 		# List all replication controllers and services together in ps output format
 		kubectl get rc,services
 
@@ -132,7 +183,16 @@ var (
 		kubectl get rc/web service/frontend pods/web-pod-13je7
 
 		# List the 'status' subresource for a single pod
-		kubectl get pod web-pod-13je7 --subresource status`))
+		kubectl get pod web-pod-13je7 --subresource status
+
+		# Pick the deployment to display in YAML from the deployments of all namespaces
+		kubectl get deployments -A --pick -o yaml
+
+		# Display the pod web-pod-13je7 without knowing its namespace
+		kubectl get pod web-pod-13je7 -A
+
+		# Watch the pods, resuming the watch when the connection to the server is lost
+		kubectl get pods --watch --watch-reconnect`))
 )
 
 const (
@@ -147,9 +207,10 @@ func NewGetOptions(parent string, streams genericiooptions.IOStreams) *GetOption
 		PrintFlags: NewGetPrintFlags(),
 		CmdParent:  parent,
 
-		IOStreams:   streams,
-		ChunkSize:   cmdutil.DefaultChunkSize,
-		ServerPrint: true,
+		IOStreams:       streams,
+		ChunkSize:       cmdutil.DefaultChunkSize,
+		ServerPrint:     true,
+		RequestEncoding: cmdutil.RequestEncodingJSON,
 	}
 }
 
@@ -173,20 +234,36 @@ func NewCmdGet(parent string, f cmdutil.Factory, streams genericiooptions.IOStre
 		SuggestFor: []string{"list", "ps"},
 	}
 
+	o.PrintFlags.fieldPathCompletion = func(args []string, toComplete string) []string {
+		return fieldPathCompletions(f, args, toComplete)
+	}
 	o.PrintFlags.AddFlags(cmd)
 
 	cmd.Flags().StringVar(&o.Raw, "raw", o.Raw, "Raw URI to request from the server.  Uses the transport specified by the kubeconfig file.")
 	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "After listing/getting the requested object, watch for changes.")
 	cmd.Flags().BoolVar(&o.WatchOnly, "watch-only", o.WatchOnly, "Watch for changes to the requested object(s), without listing/getting first.")
+	cmd.Flags().BoolVar(&o.WatchReconnect, "watch-reconnect", o.WatchReconnect, "If true, when --watch or --watch-only is used, resume the watch when the connection to the server is lost or the server closes it, from the last change received. The objects are listed again when that change is too old. If false, exit when the watch is closed.")
 	cmd.Flags().BoolVar(&o.OutputWatchEvents, "output-watch-events", o.OutputWatchEvents, "Output watch event objects when --watch or --watch-only is used. Existing objects are output as initial ADDED events.")
 	cmd.Flags().BoolVar(&o.IgnoreNotFound, "ignore-not-found", o.IgnoreNotFound, "If the requested object does not exist the command will return exit code 0.")
 	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The server only supports a limited number of field queries per type.")
 	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
 	addServerPrintColumnFlags(cmd, o)
+	cmd.Flags().BoolVar(&o.ShowCost, "show-cost", o.ShowCost, "When printing with -o wide, add the estimated monthly cost of the objects, from the resources requested by their pods and a price sheet.")
+	cmd.Flags().StringVar(&o.PricingFile, "pricing-file", o.PricingFile, "Path to the price sheet used by --show-cost. Defaults to ~/.kube/pricing.yaml.")
 	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "identifying the resource to get from a server.")
 	cmdutil.AddChunkSizeFlag(cmd, &o.ChunkSize)
+	cmdutil.AddRequestEncodingFlag(cmd, &o.RequestEncoding)
+	if flag := cmd.Flags().Lookup("request-encoding"); flag != nil {
+		flag.Usage += " With protobuf, the fields unknown to this version of kubectl are dropped from the -o json and -o yaml output."
+	}
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+	cmdutil.AddPickFlagVar(cmd, &o.Pick)
 	cmdutil.AddSubresourceFlags(cmd, &o.Subresource, "If specified, gets the subresource of the requested object.", supportedSubresources...)
+	cmdutil.CheckErr(cmd.RegisterFlagCompletionFunc(
+		"sort-by",
+		func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return fieldPathCompletions(f, args, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+		}))
 	return cmd
 }
 
@@ -213,6 +290,33 @@ func (o *GetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []stri
 	}
 
 	o.NoHeaders = cmdutil.GetFlagBool(cmd, "no-headers")
+	o.ShowProgress = printers.IsTerminal(o.ErrOut)
+
+	if o.Pick && o.PickFn == nil && needsPick(args, o.Filenames, o.Kustomize) {
+		o.PickFn, err = cmdutil.NewTerminalPickFunc(o.IOStreams)
+		if err != nil {
+			return err
+		}
+	}
+	if o.AllNamespaces && o.PickFn == nil && o.namesObjects(args) {
+		// the namespace of an object found in several namespaces is picked on
+		// a terminal, and is an error otherwise
+		o.PickFn, _ = cmdutil.NewTerminalPickFunc(o.IOStreams)
+	}
+
+	if o.ShowCost {
+		file := o.PricingFile
+		if len(file) == 0 {
+			file = DefaultPriceSheetFile()
+		}
+		if o.priceSheet, err = LoadPriceSheet(file); err != nil {
+			return err
+		}
+	}
+
+	if o.PrintFlags.DecodedFlags.ShowKeysOnly && len(*o.PrintFlags.OutputFormat) == 0 {
+		*o.PrintFlags.OutputFormat = decodedFormat
+	}
 
 	// TODO (soltysh): currently we don't support custom columns
 	// with server side print. So in these cases force the old behavior.
@@ -261,6 +365,9 @@ func (o *GetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []stri
 			printer = &skipPrinter{delegate: printer, output: outputObjects}
 		}
 		if o.ServerPrint {
+			if o.priceSheet != nil {
+				printer = &costPrinter{Delegate: printer, Prices: o.priceSheet}
+			}
 			printer = &TablePrinter{Delegate: printer}
 		}
 		return printer.PrintObj, nil
@@ -287,6 +394,19 @@ func (o *GetOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []stri
 	return nil
 }
 
+// needsPick returns whether the arguments are a single resource type, without
+// names, so that the object can be picked.
+func needsPick(args []string, filenames []string, kustomize string) bool {
+	return len(args) == 1 && !strings.ContainsAny(args[0], "/,") && cmdutil.IsFilenameSliceEmpty(filenames, kustomize)
+}
+
+// namesObjects returns whether the arguments name the objects to get, rather
+// than only their type.
+func (o *GetOptions) namesObjects(args []string) bool {
+	hasNames, err := resource.HasNames(args)
+	return err == nil && hasNames && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize)
+}
+
 // Validate checks the set of flags provided by the user.
 func (o *GetOptions) Validate() error {
 	if len(o.Raw) > 0 {
@@ -300,12 +420,24 @@ func (o *GetOptions) Validate() error {
 			return fmt.Errorf("--raw must be a valid URL path: %v", err)
 		}
 	}
+	if err := cmdutil.ValidateRequestEncoding(o.RequestEncoding); err != nil {
+		return err
+	}
 	if o.PrintFlags.HumanReadableFlags.ShowLabels != nil && *o.PrintFlags.HumanReadableFlags.ShowLabels && o.PrintFlags.OutputFormat != nil {
 		outputOption := *o.PrintFlags.OutputFormat
 		if outputOption != "" && outputOption != "wide" {
 			return fmt.Errorf("--show-labels option cannot be used with %s printer", outputOption)
 		}
 	}
+	if (o.PrintFlags.DecodedFlags.ShowKeysOnly || o.PrintFlags.DecodedFlags.Reveal) && *o.PrintFlags.OutputFormat != decodedFormat {
+		return fmt.Errorf("--show-keys-only and --reveal can only be used with the decoded output format")
+	}
+	if o.PrintFlags.DecodedFlags.ShowKeysOnly && o.PrintFlags.DecodedFlags.Reveal {
+		return fmt.Errorf("--show-keys-only and --reveal are mutually exclusive")
+	}
+	if o.ShowCost && (*o.PrintFlags.OutputFormat != "wide" || !o.ServerPrint) {
+		return fmt.Errorf("--show-cost can only be used with -o wide and --server-print")
+	}
 	if o.OutputWatchEvents && !(o.Watch || o.WatchOnly) {
 		return fmt.Errorf("--output-watch-events option can only be used with --watch or --watch-only")
 	}
@@ -428,8 +560,9 @@ func (o *GetOptions) transformRequests(req *rest.Request) {
 		"application/json",
 	}, ","))
 
-	// if sorting, ensure we receive the full object in order to introspect its fields via jsonpath
-	if len(o.SortBy) > 0 {
+	// if sorting, ensure we receive the full object in order to introspect its fields via jsonpath,
+	// and to estimate the cost of the pods of the objects
+	if len(o.SortBy) > 0 || o.ShowCost {
 		req.Param("includeObject", "Object")
 	}
 }
@@ -444,6 +577,34 @@ func (o *GetOptions) Run(f cmdutil.Factory, args []string) error {
 		}
 		return rawhttp.RawGet(restClient, o.IOStreams, o.Raw)
 	}
+	if o.Pick && needsPick(args, o.Filenames, o.Kustomize) {
+		info, err := cmdutil.PickObject(f, o.PickFn, args[0], o.Namespace, o.AllNamespaces, o.LabelSelector)
+		if err != nil {
+			return err
+		}
+		args = []string{args[0], info.Name}
+		if info.Namespaced() {
+			o.Namespace = info.Namespace
+		}
+		o.AllNamespaces = false
+		o.LabelSelector = ""
+	}
+	if o.AllNamespaces && o.namesObjects(args) {
+		namespace, err := cmdutil.InferNamespace(f.NewBuilder, o.PickFn, args)
+		if err != nil {
+			return err
+		}
+		if len(namespace) > 0 {
+			o.Namespace = namespace
+		}
+		o.AllNamespaces = false
+	}
+	if o.IsHumanReadablePrinter && color.Enabled(o.Out) {
+		// the statuses are colored once the columns are aligned
+		out := color.NewStatusWriter(o.Out)
+		defer out.Flush()
+		o.Out = out
+	}
 	if o.Watch || o.WatchOnly {
 		return o.watch(f, args)
 	}
@@ -455,20 +616,18 @@ func (o *GetOptions) Run(f cmdutil.Factory, args []string) error {
 		chunkSize = 0
 	}
 
-	r := f.NewBuilder().
-		Unstructured().
-		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
-		FilenameParam(o.ExplicitNamespace, &o.FilenameOptions).
-		LabelSelectorParam(o.LabelSelector).
-		FieldSelectorParam(o.FieldSelector).
-		Subresource(o.Subresource).
-		RequestChunksOf(chunkSize).
-		ResourceTypeOrNameArgs(true, args...).
-		ContinueOnError().
-		Latest().
-		Flatten().
+	r := o.newBuilder(f.NewBuilder().Unstructured(), args, chunkSize).
 		TransformRequests(o.transformRequests).
 		Do()
+	if !o.IsHumanReadablePrinter && o.RequestEncoding == cmdutil.RequestEncodingProtobuf {
+		if mapping, err := r.ResourceMapping(); err == nil && scheme.Scheme.Recognizes(mapping.GroupVersionKind) {
+			// built-in resources are requested as protobuf, and converted
+			// to unstructured objects before printing
+			r = o.newBuilder(f.NewBuilder().WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...), args, chunkSize).
+				TransformRequests(cmdutil.AcceptProtobuf).
+				Do()
+		}
+	}
 
 	if o.IgnoreNotFound {
 		r.IgnoreErrors(apierrors.IsNotFound)
@@ -480,6 +639,10 @@ func (o *GetOptions) Run(f cmdutil.Factory, args []string) error {
 	if !o.IsHumanReadablePrinter {
 		return o.printGeneric(r)
 	}
+	if _, err := r.ResourceMapping(); err == nil && len(o.SortBy) == 0 {
+		// a list of a single resource type is printed as its chunks arrive
+		return o.printChunks(r)
+	}
 
 	allErrs := []error{}
 	errs := sets.NewString()
@@ -570,6 +733,90 @@ func (o *GetOptions) Run(f cmdutil.Factory, args []string) error {
 	return utilerrors.NewAggregate(allErrs)
 }
 
+// printChunks prints the resources as they are visited, without keeping them
+// in memory once printed. The tab writer remembers the column widths of the
+// previous chunks, so that the columns stay aligned unless a later chunk has
+// wider cells.
+func (o *GetOptions) printChunks(r *resource.Result) error {
+	var printer printers.ResourcePrinterFunc
+	trackingWriter := &trackingWriterWrapper{Delegate: o.Out}
+	w := printers.GetNewTabWriter(trackingWriter)
+	allResourcesNamespaced := !o.AllNamespaces
+	listed := 0
+	showingProgress := false
+
+	err := r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		allResourcesNamespaced = allResourcesNamespaced && info.Namespaced()
+		if printer == nil {
+			printWithNamespace := o.AllNamespaces
+			if info.Mapping != nil && info.Mapping.Scope.Name() == meta.RESTScopeNameRoot {
+				printWithNamespace = false
+			}
+			printer, err = o.ToPrinter(info.Mapping, nil, printWithNamespace, false)
+			if err != nil {
+				return err
+			}
+		}
+
+		if showingProgress {
+			fmt.Fprint(o.ErrOut, "\r\x1b[K")
+			showingProgress = false
+		}
+		printer.PrintObj(info.Object, w)
+		w.Flush()
+
+		rows, more := tableChunk(info.Object)
+		listed += rows
+		if o.ShowProgress && more {
+			fmt.Fprintf(o.ErrOut, "\r%d %s listed, fetching more...", listed, info.Mapping.Resource.Resource)
+			showingProgress = true
+		}
+		return nil
+	})
+	if showingProgress {
+		fmt.Fprint(o.ErrOut, "\r\x1b[K")
+	}
+	if trackingWriter.Written == 0 && !o.IgnoreNotFound && err == nil {
+		// if we wrote no output, and had no errors, and are not ignoring NotFound, be sure we output something
+		if allResourcesNamespaced {
+			fmt.Fprintf(o.ErrOut, "No resources found in %s namespace.\n", o.Namespace)
+		} else {
+			fmt.Fprintln(o.ErrOut, "No resources found")
+		}
+	}
+	return err
+}
+
+// tableChunk returns the number of rows of a chunk of a list printed by the
+// server, and whether more chunks follow it.
+func tableChunk(obj runtime.Object) (int, bool) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok || u.GetKind() != "Table" {
+		return 1, false
+	}
+	rows, _, _ := unstructured.NestedSlice(u.Object, "rows")
+	return len(rows), len(u.GetContinue()) > 0
+}
+
+// newBuilder adds the requested resources to a builder, which decodes them
+// with its scheme.
+func (o *GetOptions) newBuilder(b *resource.Builder, args []string, chunkSize int64) *resource.Builder {
+	return b.
+		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
+		FilenameParam(o.ExplicitNamespace, &o.FilenameOptions).
+		LabelSelectorParam(o.LabelSelector).
+		FieldSelectorParam(o.FieldSelector).
+		Subresource(o.Subresource).
+		RequestChunksOf(chunkSize).
+		ResourceTypeOrNameArgs(true, args...).
+		ContinueOnError().
+		Latest().
+		Flatten()
+}
+
 type trackingWriterWrapper struct {
 	Delegate io.Writer
 	Written  int
@@ -602,18 +849,7 @@ func (s *separatorWriterWrapper) SetReady(state bool) {
 // watch starts a client-side watch of one or more resources.
 // TODO: remove the need for arguments here.
 func (o *GetOptions) watch(f cmdutil.Factory, args []string) error {
-	r := f.NewBuilder().
-		Unstructured().
-		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
-		FilenameParam(o.ExplicitNamespace, &o.FilenameOptions).
-		LabelSelectorParam(o.LabelSelector).
-		FieldSelectorParam(o.FieldSelector).
-		RequestChunksOf(o.ChunkSize).
-		ResourceTypeOrNameArgs(true, args...).
-		SingleResourceType().
-		Latest().
-		TransformRequests(o.transformRequests).
-		Do()
+	r := o.watchResult(f, args)
 	if err := r.Err(); err != nil {
 		return err
 	}
@@ -624,6 +860,9 @@ func (o *GetOptions) watch(f cmdutil.Factory, args []string) error {
 	if multipleGVKsRequested(infos) {
 		return i18n.Errorf("watch is only supported on individual resources and resource collections - more than 1 resource was found")
 	}
+	if len(infos) != 1 {
+		return fmt.Errorf("watch is only supported on individual resources and resource collections - %d resources were found", len(infos))
+	}
 
 	info := infos[0]
 	mapping := info.ResourceMapping()
@@ -644,7 +883,7 @@ func (o *GetOptions) watch(f cmdutil.Factory, args []string) error {
 	rv := "0"
 	isList := meta.IsListType(obj)
 	if isList {
-		// the resourceVersion of list objects is ~now but won't return
+		// the resourceVersion of the list is ~now but won't return
 		// an initial watch event
 		rv, err = meta.NewAccessor().ResourceVersion(obj)
 		if err != nil {
@@ -655,21 +894,9 @@ func (o *GetOptions) watch(f cmdutil.Factory, args []string) error {
 	writer := printers.GetNewTabWriter(o.Out)
 
 	// print the current object
-	var objsToPrint []runtime.Object
-	if isList {
-		objsToPrint, _ = meta.ExtractList(obj)
-	} else {
-		objsToPrint = append(objsToPrint, obj)
-	}
-	for _, objToPrint := range objsToPrint {
-		if o.OutputWatchEvents {
-			objToPrint = &metav1.WatchEvent{Type: string(watch.Added), Object: runtime.RawExtension{Object: objToPrint}}
-		}
-		if err := printer.PrintObj(objToPrint, writer); err != nil {
-			return fmt.Errorf("unable to output the provided object: %v", err)
-		}
+	if err := o.printWatchObjects(obj, printer, writer); err != nil {
+		return err
 	}
-	writer.Flush()
 	if isList {
 		// we can start outputting objects now, watches started from lists don't emit synthetic added events
 		*outputObjects = true
@@ -679,30 +906,89 @@ func (o *GetOptions) watch(f cmdutil.Factory, args []string) error {
 	}
 
 	// print watched changes
-	w, err := r.Watch(rv)
-	if err != nil {
-		return err
-	}
-
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	intr := interrupt.New(nil, cancel)
-	intr.Run(func() error {
-		_, err := watchtools.UntilWithoutRetry(ctx, w, func(e watch.Event) (bool, error) {
+	watcher := &resumingWatcher{
+		watch: func(resourceVersion string) (watch.Interface, error) {
+			options := &metav1.ListOptions{ResourceVersion: resourceVersion, AllowWatchBookmarks: true, LabelSelector: o.LabelSelector, FieldSelector: o.FieldSelector}
+			if !isList {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", info.Name).String()
+			}
+			return resource.NewHelper(info.Client, mapping).Watch(info.Namespace, mapping.GroupVersionKind.GroupVersion().String(), options)
+		},
+		relist: func() (string, error) {
+			obj, err := o.watchResult(f, args).Object()
+			if apierrors.IsNotFound(err) && !isList {
+				// the object was deleted, the watch reports when it is created again
+				return "0", nil
+			}
+			if err != nil {
+				return "", err
+			}
+			*outputObjects = true
+			if err := o.printWatchObjects(obj, printer, writer); err != nil {
+				return "", err
+			}
+			return meta.NewAccessor().ResourceVersion(obj)
+		},
+		handle: func(e watch.Event) error {
 			objToPrint := e.Object
 			if o.OutputWatchEvents {
 				objToPrint = &metav1.WatchEvent{Type: string(e.Type), Object: runtime.RawExtension{Object: objToPrint}}
 			}
 			if err := printer.PrintObj(objToPrint, writer); err != nil {
-				return false, err
+				return err
 			}
 			writer.Flush()
 			// after processing at least one event, start outputting objects
 			*outputObjects = true
-			return false, nil
-		})
-		return err
+			return nil
+		},
+		resourceVersion: rv,
+		resume:          o.WatchReconnect,
+		backoff:         minWatchBackoff,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	intr := interrupt.New(nil, cancel)
+	return intr.Run(func() error {
+		return watcher.run(ctx)
 	})
+}
+
+// watchResult returns the objects to watch.
+func (o *GetOptions) watchResult(f cmdutil.Factory, args []string) *resource.Result {
+	return f.NewBuilder().
+		Unstructured().
+		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
+		FilenameParam(o.ExplicitNamespace, &o.FilenameOptions).
+		LabelSelectorParam(o.LabelSelector).
+		FieldSelectorParam(o.FieldSelector).
+		RequestChunksOf(o.ChunkSize).
+		ResourceTypeOrNameArgs(true, args...).
+		SingleResourceType().
+		Latest().
+		TransformRequests(o.transformRequests).
+		Do()
+}
+
+// printWatchObjects prints the object, or the items of the list, before
+// watching them.
+func (o *GetOptions) printWatchObjects(obj runtime.Object, printer printers.ResourcePrinter, writer *tabwriter.Writer) error {
+	var objsToPrint []runtime.Object
+	if meta.IsListType(obj) {
+		objsToPrint, _ = meta.ExtractList(obj)
+	} else {
+		objsToPrint = append(objsToPrint, obj)
+	}
+	for _, objToPrint := range objsToPrint {
+		if o.OutputWatchEvents {
+			objToPrint = &metav1.WatchEvent{Type: string(watch.Added), Object: runtime.RawExtension{Object: objToPrint}}
+		}
+		if err := printer.PrintObj(objToPrint, writer); err != nil {
+			return fmt.Errorf("unable to output the provided object: %v", err)
+		}
+	}
+	writer.Flush()
 	return nil
 }
 
@@ -724,6 +1010,11 @@ func (o *GetOptions) printGeneric(r *resource.Result) error {
 	if len(infos) == 0 && o.IgnoreNotFound {
 		return utilerrors.Reduce(utilerrors.Flatten(utilerrors.NewAggregate(errs)))
 	}
+	for _, info := range infos {
+		if err := toUnstructured(info); err != nil {
+			return err
+		}
+	}
 
 	printer, err := o.ToPrinter(nil, nil, false, false)
 	if err != nil {
@@ -799,6 +1090,25 @@ func (o *GetOptions) printGeneric(r *resource.Result) error {
 	return utilerrors.Reduce(utilerrors.Flatten(utilerrors.NewAggregate(errs)))
 }
 
+// toUnstructured converts the typed object of the info, decoded from protobuf,
+// to an unstructured object, so that it is printed as if requested as JSON.
+func toUnstructured(info *resource.Info) error {
+	if _, ok := info.Object.(runtime.Unstructured); ok {
+		return nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(info.Object)
+	if err != nil {
+		return err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	if u.GetObjectKind().GroupVersionKind().Empty() {
+		// the items of protobuf lists have no kind
+		u.SetGroupVersionKind(info.Mapping.GroupVersionKind)
+	}
+	info.Object = u
+	return nil
+}
+
 func addServerPrintColumnFlags(cmd *cobra.Command, opt *GetOptions) {
 	cmd.Flags().BoolVar(&opt.ServerPrint, useServerPrintColumns, opt.ServerPrint, "If true, have the server return the appropriate table output. Supports extension APIs and CRDs.")
 }
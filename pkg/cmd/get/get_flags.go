@@ -34,11 +34,18 @@ type PrintFlags struct {
 	JSONYamlPrintFlags *genericclioptions.JSONYamlPrintFlags
 	NamePrintFlags     *genericclioptions.NamePrintFlags
 	CustomColumnsFlags *CustomColumnsPrintFlags
+	JQFlags            *JQPrintFlags
+	StreamFlags        *StreamPrintFlags
+	ConditionsFlags    *ConditionsPrintFlags
+	DecodedFlags       *DecodedPrintFlags
 	HumanReadableFlags *HumanPrintFlags
 	TemplateFlags      *genericclioptions.KubeTemplatePrintFlags
 
 	NoHeaders    *bool
 	OutputFormat *string
+
+	// fieldPathCompletion completes the field paths of custom columns, when set.
+	fieldPathCompletion func(args []string, toComplete string) []string
 }
 
 // SetKind sets the Kind option of humanreadable flags
@@ -70,6 +77,10 @@ func (f *PrintFlags) AllowedFormats() []string {
 	formats = append(formats, f.NamePrintFlags.AllowedFormats()...)
 	formats = append(formats, f.TemplateFlags.AllowedFormats()...)
 	formats = append(formats, f.CustomColumnsFlags.AllowedFormats()...)
+	formats = append(formats, f.JQFlags.AllowedFormats()...)
+	formats = append(formats, f.StreamFlags.AllowedFormats()...)
+	formats = append(formats, f.ConditionsFlags.AllowedFormats()...)
+	formats = append(formats, f.DecodedFlags.AllowedFormats()...)
 	formats = append(formats, f.HumanReadableFlags.AllowedFormats()...)
 	return formats
 }
@@ -88,6 +99,7 @@ func (f *PrintFlags) ToPrinter() (printers.ResourcePrinter, error) {
 	}
 	f.HumanReadableFlags.NoHeaders = noHeaders
 	f.CustomColumnsFlags.NoHeaders = noHeaders
+	f.ConditionsFlags.NoHeaders = noHeaders
 
 	// for "get.go" we want to support a --template argument given, even when no --output format is provided
 	if f.TemplateFlags.TemplateArgument != nil && len(*f.TemplateFlags.TemplateArgument) > 0 && len(outputFormat) == 0 {
@@ -100,6 +112,7 @@ func (f *PrintFlags) ToPrinter() (printers.ResourcePrinter, error) {
 
 	if f.TemplateFlags.TemplateArgument != nil {
 		f.CustomColumnsFlags.TemplateArgument = *f.TemplateFlags.TemplateArgument
+		f.JQFlags.TemplateArgument = *f.TemplateFlags.TemplateArgument
 	}
 
 	if p, err := f.JSONYamlPrintFlags.ToPrinter(outputFormat); !genericclioptions.IsNoCompatiblePrinterError(err) {
@@ -114,6 +127,23 @@ func (f *PrintFlags) ToPrinter() (printers.ResourcePrinter, error) {
 		return p, err
 	}
 
+	if p, err := f.JQFlags.ToPrinter(outputFormat); !genericclioptions.IsNoCompatiblePrinterError(err) {
+		return p, err
+	}
+
+	f.StreamFlags.ShowManagedFields = f.JSONYamlPrintFlags.ShowManagedFields
+	if p, err := f.StreamFlags.ToPrinter(outputFormat); !genericclioptions.IsNoCompatiblePrinterError(err) {
+		return p, err
+	}
+
+	if p, err := f.ConditionsFlags.ToPrinter(outputFormat); !genericclioptions.IsNoCompatiblePrinterError(err) {
+		return p, err
+	}
+
+	if p, err := f.DecodedFlags.ToPrinter(outputFormat); !genericclioptions.IsNoCompatiblePrinterError(err) {
+		return p, err
+	}
+
 	if p, err := f.NamePrintFlags.ToPrinter(outputFormat); !genericclioptions.IsNoCompatiblePrinterError(err) {
 		return p, err
 	}
@@ -129,12 +159,28 @@ func (f *PrintFlags) AddFlags(cmd *cobra.Command) {
 	f.TemplateFlags.AddFlags(cmd)
 	f.HumanReadableFlags.AddFlags(cmd)
 	f.CustomColumnsFlags.AddFlags(cmd)
+	f.JQFlags.AddFlags(cmd)
+	f.StreamFlags.AddFlags(cmd)
+	f.ConditionsFlags.AddFlags(cmd)
+	f.DecodedFlags.AddFlags(cmd)
 
 	if f.OutputFormat != nil {
-		cmd.Flags().StringVarP(f.OutputFormat, "output", "o", *f.OutputFormat, fmt.Sprintf(`Output format. One of: (%s). See custom columns [https://kubernetes.io/docs/reference/kubectl/#custom-columns], golang template [http://golang.org/pkg/text/template/#pkg-overview] and jsonpath template [https://kubernetes.io/docs/reference/kubectl/jsonpath/].`, strings.Join(f.AllowedFormats(), ", ")))
+		cmd.Flags().StringVarP(f.OutputFormat, "output", "o", *f.OutputFormat, fmt.Sprintf(`Output format. One of: (%s). See custom columns [https://kubernetes.io/docs/reference/kubectl/#custom-columns], golang template [http://golang.org/pkg/text/template/#pkg-overview], jsonpath template [https://kubernetes.io/docs/reference/kubectl/jsonpath/] and jq expression [https://jqlang.github.io/jq/manual/].`, strings.Join(f.AllowedFormats(), ", ")))
 		util.CheckErr(cmd.RegisterFlagCompletionFunc(
 			"output",
 			func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				if columns, ok := strings.CutPrefix(toComplete, "custom-columns="); ok && f.fieldPathCompletion != nil {
+					// complete the field of the last column
+					i := strings.LastIndex(columns, ":")
+					if i < 0 || strings.Contains(columns[i:], ",") {
+						return nil, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+					}
+					var comps []string
+					for _, comp := range f.fieldPathCompletion(args, columns[i+1:]) {
+						comps = append(comps, toComplete[:len(toComplete)-len(columns)+i+1]+comp)
+					}
+					return comps, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+				}
 				var comps []string
 				for _, format := range f.AllowedFormats() {
 					if strings.HasPrefix(format, toComplete) {
@@ -146,7 +192,7 @@ func (f *PrintFlags) AddFlags(cmd *cobra.Command) {
 		))
 	}
 	if f.NoHeaders != nil {
-		cmd.Flags().BoolVar(f.NoHeaders, "no-headers", *f.NoHeaders, "When using the default or custom-column output format, don't print headers (default print headers).")
+		cmd.Flags().BoolVar(f.NoHeaders, "no-headers", *f.NoHeaders, "When using the default, custom-column or conditions output format, don't print headers (default print headers).")
 	}
 }
 
@@ -166,5 +212,9 @@ func NewGetPrintFlags() *PrintFlags {
 
 		HumanReadableFlags: NewHumanPrintFlags(),
 		CustomColumnsFlags: NewCustomColumnsPrintFlags(),
+		JQFlags:            NewJQPrintFlags(),
+		StreamFlags:        NewStreamPrintFlags(),
+		ConditionsFlags:    NewConditionsPrintFlags(),
+		DecodedFlags:       NewDecodedPrintFlags(),
 	}
 }
@@ -25,8 +25,10 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	batchv1 "k8s.io/api/batch/v1"
@@ -44,6 +46,7 @@ import (
 	"k8s.io/client-go/rest/fake"
 	restclientwatch "k8s.io/client-go/rest/watch"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 )
 
@@ -758,6 +761,72 @@ func TestGetObjectIgnoreNotFound(t *testing.T) {
 	}
 }
 
+func TestGetObjectsRequestEncoding(t *testing.T) {
+	pods, _, _ := cmdtesting.TestData()
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	info, _ := runtime.SerializerInfoForMediaType(scheme.Codecs.SupportedMediaTypes(), runtime.ContentTypeProtobuf)
+	protobufCodec := scheme.Codecs.CodecForVersions(info.Serializer, info.Serializer, corev1.SchemeGroupVersion, nil)
+
+	tests := []struct {
+		encoding   string
+		serializer runtime.NegotiatedSerializer
+		accept     string
+	}{
+		{
+			// the fields unknown to the client would be dropped with protobuf
+			encoding:   "default",
+			serializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+			accept:     "application/json, */*",
+		},
+		{
+			encoding:   "protobuf",
+			serializer: scheme.Codecs.WithoutConversion(),
+			accept:     "application/vnd.kubernetes.protobuf,application/json",
+		},
+		{
+			encoding:   "json",
+			serializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+			accept:     "application/json, */*",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.encoding, func(t *testing.T) {
+			cmdtesting.InitTestErrorHandler(t)
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			tf.UnstructuredClient = &fake.RESTClient{
+				NegotiatedSerializer: tc.serializer,
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					if e, a := tc.accept, req.Header.Get("Accept"); e != a {
+						t.Errorf("expected Accept %q, got %q", e, a)
+					}
+					if strings.HasPrefix(tc.accept, runtime.ContentTypeProtobuf) {
+						header := http.Header{}
+						header.Set("Content-Type", runtime.ContentTypeProtobuf)
+						return &http.Response{StatusCode: http.StatusOK, Header: header, Body: cmdtesting.ObjBody(protobufCodec, pods)}, nil
+					}
+					return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, pods)}, nil
+				}),
+			}
+
+			streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+			cmd := NewCmdGet("kubectl", tf, streams)
+			cmd.SetOut(buf)
+			cmd.SetErr(buf)
+			if tc.encoding != "default" {
+				cmd.Flags().Set("request-encoding", tc.encoding)
+			}
+			cmd.Flags().Set("output", "jsonpath={range .items[*]}{.apiVersion}/{.kind}/{.metadata.name} {end}")
+			cmd.Run(cmd, []string{"pods"})
+
+			if e, a := "v1/Pod/foo v1/Pod/bar ", buf.String(); e != a {
+				t.Errorf("expected %q, got %q", e, a)
+			}
+		})
+	}
+}
+
 func TestEmptyResult(t *testing.T) {
 	cmdtesting.InitTestErrorHandler(t)
 
@@ -1230,6 +1299,58 @@ bar    0/0              0          <unknown>
 	}
 }
 
+func TestGetChunkedTableObjects(t *testing.T) {
+	pods, _, _ := cmdtesting.TestData()
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+	// each pod is returned in a chunk of its own
+	requests := []string{}
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/namespaces/test/pods" {
+				t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+			}
+			query := req.URL.Query()
+			requests = append(requests, query.Get("limit")+"/"+query.Get("continue"))
+			if query.Get("continue") == "" {
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: podTableChunkBody(codec, "next", pods.Items[0])}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: podTableChunkBody(codec, "", pods.Items[1])}, nil
+		}),
+	}
+
+	streams, _, buf, errbuf := genericiooptions.NewTestIOStreams()
+	o := NewGetOptions("kubectl", streams)
+	cmd := &cobra.Command{}
+	o.PrintFlags.AddFlags(cmd)
+	o.ChunkSize = 1
+	if err := o.Complete(tf, cmd, []string{"pods"}); err != nil {
+		t.Fatal(err)
+	}
+	o.ShowProgress = true
+	if err := o.Run(tf, []string{"pods"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `NAME   READY   STATUS   RESTARTS   AGE
+foo    0/0              0          <unknown>
+bar    0/0              0          <unknown>
+`
+	if e, a := expected, buf.String(); e != a {
+		t.Errorf("expected\n%v\ngot\n%v", e, a)
+	}
+	if e, a := "\r1 pods listed, fetching more...\r\x1b[K", errbuf.String(); e != a {
+		t.Errorf("expected progress %q, got %q", e, a)
+	}
+	if e, a := []string{"1/", "1/next"}, requests; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected requests %v, got %v", e, a)
+	}
+}
+
 func TestGetListComponentStatus(t *testing.T) {
 	statuses := testComponentStatusData()
 
@@ -2767,6 +2888,133 @@ foo    0/0              0          <unknown>
 	}
 }
 
+// watchReconnectClient serves the pods of watchTestData, and the watches in
+// order, the last one failing with a forbidden error to end the command. It
+// records the resourceVersion each watch is started from.
+func watchReconnectClient(t *testing.T, watches []func() *http.Response, resourceVersions *[]string) *fake.RESTClient {
+	pods, _ := watchTestData()
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	podList := &corev1.PodList{
+		Items: pods,
+		ListMeta: metav1.ListMeta{
+			ResourceVersion: "10",
+		},
+	}
+	forbidden := &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusForbidden, Reason: metav1.StatusReasonForbidden, Message: "denied"}
+	return &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/namespaces/test/pods" {
+				t.Fatalf("request url: %#v,and request: %#v", req.URL, req)
+			}
+			if req.URL.Query().Get("watch") != "true" {
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, podList)}, nil
+			}
+			if req.URL.Query().Get("allowWatchBookmarks") != "true" {
+				t.Errorf("expected the watch to allow bookmarks, got %v", req.URL)
+			}
+			*resourceVersions = append(*resourceVersions, req.URL.Query().Get("resourceVersion"))
+			if len(*resourceVersions) > len(watches) {
+				return &http.Response{StatusCode: http.StatusForbidden, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, forbidden)}, nil
+			}
+			return watches[len(*resourceVersions)-1](), nil
+		}),
+	}
+}
+
+func TestWatchReconnect(t *testing.T) {
+	defer func(backoff time.Duration) { minWatchBackoff = backoff }(minWatchBackoff)
+	minWatchBackoff = time.Millisecond
+	_, events := watchTestData()
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+	internal := &metav1.Status{Status: metav1.StatusFailure, Code: http.StatusInternalServerError, Reason: metav1.StatusReasonInternalError, Message: "Something happened"}
+	resourceVersions := []string{}
+	tf.UnstructuredClient = watchReconnectClient(t, []func() *http.Response{
+		// closed after the events, as on a server timeout
+		func() *http.Response {
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: watchBody(codec, events[2:])}
+		},
+		// a transient error is retried from the same resourceVersion
+		func() *http.Response {
+			return &http.Response{StatusCode: http.StatusInternalServerError, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, internal)}
+		},
+	}, &resourceVersions)
+
+	var fatal string
+	cmdutil.BehaviorOnFatal(func(msg string, code int) { fatal = msg })
+	defer cmdutil.DefaultBehaviorOnFatal()
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdGet("kubectl", tf, streams)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.Flags().Set("watch", "true")
+	cmd.Flags().Set("watch-reconnect", "true")
+	cmd.Run(cmd, []string{"pods"})
+
+	expected := `NAME   AGE
+bar    <unknown>
+foo    <unknown>
+foo    <unknown>
+foo    <unknown>
+`
+	if e, a := expected, buf.String(); e != a {
+		t.Errorf("expected\n%v\ngot\n%v", e, a)
+	}
+	if expected := []string{"10", "12", "12"}; !reflect.DeepEqual(expected, resourceVersions) {
+		t.Errorf("expected watches from %v, got %v", expected, resourceVersions)
+	}
+	if !strings.Contains(fatal, "Forbidden") {
+		t.Errorf("expected the forbidden watch to end the command, got %q", fatal)
+	}
+}
+
+func TestWatchBookmarkResume(t *testing.T) {
+	defer func(backoff time.Duration) { minWatchBackoff = backoff }(minWatchBackoff)
+	minWatchBackoff = time.Millisecond
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+	bookmark := []watch.Event{{Type: watch.Bookmark, Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "20"}}}}
+	resourceVersions := []string{}
+	tf.UnstructuredClient = watchReconnectClient(t, []func() *http.Response{
+		func() *http.Response {
+			return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: watchBody(codec, bookmark)}
+		},
+	}, &resourceVersions)
+
+	cmdutil.BehaviorOnFatal(func(msg string, code int) {})
+	defer cmdutil.DefaultBehaviorOnFatal()
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdGet("kubectl", tf, streams)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+
+	cmd.Flags().Set("watch", "true")
+	cmd.Flags().Set("watch-reconnect", "true")
+	cmd.Run(cmd, []string{"pods"})
+
+	// bookmarks are not printed
+	expected := `NAME   AGE
+bar    <unknown>
+foo    <unknown>
+`
+	if e, a := expected, buf.String(); e != a {
+		t.Errorf("expected\n%v\ngot\n%v", e, a)
+	}
+	if expected := []string{"10", "20"}; !reflect.DeepEqual(expected, resourceVersions) {
+		t.Errorf("expected watches from %v, got %v", expected, resourceVersions)
+	}
+}
+
 func watchBody(codec runtime.Codec, events []watch.Event) io.ReadCloser {
 	buf := bytes.NewBuffer([]byte{})
 	enc := restclientwatch.NewEncoder(streaming.NewEncoder(buf, codec), codec)
@@ -2814,6 +3062,28 @@ func podTableObjBody(codec runtime.Codec, pods ...corev1.Pod) io.ReadCloser {
 	return cmdtesting.BytesBody(data)
 }
 
+// build a chunk of a meta table response from a pod list
+func podTableChunkBody(codec runtime.Codec, continueToken string, pods ...corev1.Pod) io.ReadCloser {
+	table := &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ListMeta:          metav1.ListMeta{Continue: continueToken},
+		ColumnDefinitions: podColumns,
+	}
+	for i := range pods {
+		b := bytes.NewBuffer(nil)
+		codec.Encode(&pods[i], b)
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Object: runtime.RawExtension{Raw: b.Bytes()},
+			Cells:  []interface{}{pods[i].Name, "0/0", "", int64(0), "<unknown>", "<none>", "<none>", "<none>", "<none>"},
+		})
+	}
+	data, err := json.Marshal(table)
+	if err != nil {
+		panic(err)
+	}
+	return cmdtesting.BytesBody(data)
+}
+
 // build a meta table response from a pod list
 func podV1TableObjBody(codec runtime.Codec, pods ...corev1.Pod) io.ReadCloser {
 	table := &metav1.Table{
@@ -2960,3 +3230,160 @@ func replicationControllersScaleSubresourceTableObjBody(codec runtime.Codec, rep
 	}
 	return cmdtesting.ObjBody(codec, table)
 }
+
+func TestGetPick(t *testing.T) {
+	pods, _, _ := cmdtesting.TestData()
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/namespaces/test/pods" && m == "GET":
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, pods)}, nil
+			case p == "/namespaces/test/pods/bar" && m == "GET":
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &pods.Items[1])}, nil
+			default:
+				t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+				return nil, nil
+			}
+		}),
+	}
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdGet("kubectl", tf, streams)
+	o := NewGetOptions("kubectl", streams)
+	o.Pick = true
+	var picked []string
+	o.PickFn = func(items []string) (int, error) {
+		picked = items
+		return 1, nil
+	}
+	if err := o.Complete(tf, cmd, []string{"pods"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(tf, []string{"pods"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if e, a := []string{"test/foo", "test/bar"}, picked; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected to pick from %v, got %v", e, a)
+	}
+	expected := `NAME   AGE
+bar    <unknown>
+`
+	if e, a := expected, buf.String(); e != a {
+		t.Errorf("expected\n%v\ngot\n%v", e, a)
+	}
+}
+
+func TestGetAllNamespacesByName(t *testing.T) {
+	pods, _, _ := cmdtesting.TestData()
+	pods.Items[0].Namespace = "a"
+	pods.Items[1].Namespace = "b"
+	web := []corev1.Pod{*pods.Items[0].DeepCopy(), *pods.Items[1].DeepCopy()}
+	web[0].Name, web[1].Name = "web", "web"
+
+	tests := []struct {
+		name        string
+		args        []string
+		pickFn      cmdutil.PickFunc
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:     "unique name",
+			args:     []string{"pods", "bar"},
+			expected: "bar",
+		},
+		{
+			name:     "resource/name form",
+			args:     []string{"pods/foo"},
+			expected: "foo",
+		},
+		{
+			name:        "ambiguous name",
+			args:        []string{"pods", "web"},
+			expectedErr: `pods "web" exists in several namespaces: a, b; specify one with --namespace`,
+		},
+		{
+			name: "picked namespace",
+			args: []string{"pods", "web"},
+			pickFn: func(items []string) (int, error) {
+				if e, a := []string{"a/web", "b/web"}, items; !reflect.DeepEqual(e, a) {
+					t.Errorf("expected to pick from %v, got %v", e, a)
+				}
+				return 1, nil
+			},
+			expected: "web",
+		},
+		{
+			name:        "different namespaces",
+			args:        []string{"pods", "foo", "bar"},
+			expectedErr: "the objects are in different namespaces: a, b; name them in separate commands",
+		},
+		{
+			name:        "not found",
+			args:        []string{"pods", "missing"},
+			expectedErr: `pods "missing" not found`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+			tf.UnstructuredClient = &fake.RESTClient{
+				NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					switch p, m := req.URL.Path, req.Method; {
+					case p == "/pods" && m == "GET":
+						list := &corev1.PodList{ListMeta: pods.ListMeta}
+						for _, pod := range append(pods.DeepCopy().Items, web...) {
+							if req.URL.Query().Get("fieldSelector") == "metadata.name="+pod.Name {
+								list.Items = append(list.Items, pod)
+							}
+						}
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, list)}, nil
+					case p == "/namespaces/a/pods/foo" && m == "GET":
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &pods.Items[0])}, nil
+					case p == "/namespaces/b/pods/bar" && m == "GET":
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &pods.Items[1])}, nil
+					case p == "/namespaces/b/pods/web" && m == "GET":
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &web[1])}, nil
+					default:
+						t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+						return nil, nil
+					}
+				}),
+			}
+
+			streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+			cmd := NewCmdGet("kubectl", tf, streams)
+			o := NewGetOptions("kubectl", streams)
+			o.AllNamespaces = true
+			o.PickFn = test.pickFn
+			if err := o.Complete(tf, cmd, test.args); err != nil {
+				t.Fatal(err)
+			}
+			err := o.Run(tf, test.args)
+			if len(test.expectedErr) > 0 {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := "NAME   AGE\n" + fmt.Sprintf("%-6s <unknown>\n", test.expected)
+			if e, a := expected, buf.String(); e != a {
+				t.Errorf("expected\n%v\ngot\n%v", e, a)
+			}
+		})
+	}
+}
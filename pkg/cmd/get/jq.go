@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/itchyny/gojq"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// JQPrinter prints the results of a jq expression evaluated against the
+// objects. Strings are printed as is, like jq --raw-output, and other values
+// as JSON.
+type JQPrinter struct {
+	code *gojq.Code
+}
+
+// NewJQPrinter parses and compiles a jq expression into a printer.
+func NewJQPrinter(expression string) (*JQPrinter, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing jq expression %q: %v", expression, err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling jq expression %q: %v", expression, err)
+	}
+	return &JQPrinter{code: code}, nil
+}
+
+// PrintObj evaluates the expression against the object and prints the results,
+// one per line.
+func (p *JQPrinter) PrintObj(obj runtime.Object, w io.Writer) error {
+	// the evaluator only supports the types of decoded JSON, so the object
+	// is encoded and decoded, whether typed or unstructured
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	var input interface{}
+	if err := json.Unmarshal(data, &input); err != nil {
+		return err
+	}
+
+	iter := p.code.Run(input)
+	for {
+		value, ok := iter.Next()
+		if !ok {
+			return nil
+		}
+		if err, ok := value.(error); ok {
+			return fmt.Errorf("error evaluating jq expression: %v", err)
+		}
+		if s, ok := value.(string); ok {
+			fmt.Fprintln(w, s)
+			continue
+		}
+		output, err := json.MarshalIndent(value, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(output))
+	}
+}
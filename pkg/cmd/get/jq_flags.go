@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+const jqFormat = "jq"
+
+// JQPrintFlags provides default flags necessary for printing the results
+// of a jq expression.
+type JQPrintFlags struct {
+	TemplateArgument string
+}
+
+func (f *JQPrintFlags) AllowedFormats() []string {
+	return []string{jqFormat}
+}
+
+// ToPrinter receives an outputFormat and returns a printer evaluating the
+// jq expression following "jq=", or given with --template.
+// Returns false if the specified outputFormat does not match the jq format.
+func (f *JQPrintFlags) ToPrinter(outputFormat string) (printers.ResourcePrinter, error) {
+	expression := f.TemplateArgument
+	if strings.HasPrefix(outputFormat, jqFormat+"=") {
+		expression = outputFormat[len(jqFormat)+1:]
+		outputFormat = jqFormat
+	}
+	if outputFormat != jqFormat {
+		return nil, genericclioptions.NoCompatiblePrinterError{OutputFormat: &outputFormat, AllowedFormats: f.AllowedFormats()}
+	}
+
+	if len(expression) == 0 {
+		return nil, fmt.Errorf("jq format specified but no jq expression given")
+	}
+	return NewJQPrinter(expression)
+}
+
+// AddFlags receives a *cobra.Command reference and binds
+// flags related to jq printing
+func (f *JQPrintFlags) AddFlags(c *cobra.Command) {}
+
+// NewJQPrintFlags returns flags associated with jq printing, with default
+// values set. TemplateArgument should be set by callers.
+func NewJQPrintFlags() *JQPrintFlags {
+	return &JQPrintFlags{}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"bytes"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestJQPrinter(t *testing.T) {
+	pods := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items": []interface{}{
+			map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "web"},
+				"status":   map[string]interface{}{"phase": "Running"},
+				"spec":     map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "web"}}},
+			},
+			map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "batch"},
+				"status":   map[string]interface{}{"phase": "Pending"},
+				"spec":     map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b"}}},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name          string
+		expression    string
+		obj           runtime.Object
+		expected      string
+		expectedError string
+	}{
+		{
+			name:       "select strings",
+			expression: `.items[] | select(.status.phase=="Pending") | .metadata.name`,
+			obj:        pods,
+			expected:   "batch\n",
+		},
+		{
+			name:       "objects",
+			expression: `.items[] | {name: .metadata.name, containers: (.spec.containers | length)}`,
+			obj:        pods,
+			expected: `{
+    "containers": 1,
+    "name": "web"
+}
+{
+    "containers": 2,
+    "name": "batch"
+}
+`,
+		},
+		{
+			name:       "typed object",
+			expression: `.metadata.name, .spec.restartPolicy`,
+			obj:        &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo"}, Spec: corev1.PodSpec{RestartPolicy: corev1.RestartPolicyAlways}},
+			expected:   "foo\nAlways\n",
+		},
+		{
+			name:          "evaluation error",
+			expression:    `.items.name`,
+			obj:           pods,
+			expectedError: `error evaluating jq expression: expected an object but got: array ([{"metadata":{"name":"web ...])`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewJQPrinter(tc.expression)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			buf := &bytes.Buffer{}
+			err = p.PrintObj(tc.obj, buf)
+			if len(tc.expectedError) > 0 {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestJQPrintFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		outputFormat  string
+		template      string
+		expectedError string
+		expectNoMatch bool
+	}{
+		{name: "inline expression", outputFormat: "jq=.metadata.name"},
+		{name: "template argument", outputFormat: "jq", template: ".metadata.name"},
+		{name: "no expression", outputFormat: "jq", expectedError: "jq format specified but no jq expression given"},
+		{name: "invalid expression", outputFormat: "jq=.[", expectedError: `error parsing jq expression ".[": unexpected EOF`},
+		{name: "other format", outputFormat: "json", expectNoMatch: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &JQPrintFlags{TemplateArgument: tc.template}
+			_, err := f.ToPrinter(tc.outputFormat)
+			switch {
+			case tc.expectNoMatch:
+				if !genericclioptions.IsNoCompatiblePrinterError(err) {
+					t.Errorf("expected no compatible printer error, got %v", err)
+				}
+			case len(tc.expectedError) > 0:
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
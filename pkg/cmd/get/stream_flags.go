@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+)
+
+// StreamPrintFlags provides default flags necessary for printing objects
+// as a stream of YAML documents or of JSON lines.
+type StreamPrintFlags struct {
+	FlattenLists      bool
+	ShowManagedFields bool
+}
+
+func (f *StreamPrintFlags) AllowedFormats() []string {
+	return []string{jsonLinesFormat, yamlStreamFormat}
+}
+
+// ToPrinter receives an outputFormat and returns a printer capable of
+// handling yamlstream and jsonl printing.
+// Returns false if the specified outputFormat does not match a supported format.
+func (f *StreamPrintFlags) ToPrinter(outputFormat string) (printers.ResourcePrinter, error) {
+	if outputFormat != yamlStreamFormat && outputFormat != jsonLinesFormat {
+		return nil, genericclioptions.NoCompatiblePrinterError{OutputFormat: &outputFormat, AllowedFormats: f.AllowedFormats()}
+	}
+
+	var printer printers.ResourcePrinter = &StreamPrinter{Format: outputFormat, FlattenLists: f.FlattenLists}
+	if !f.ShowManagedFields {
+		printer = &printers.OmitManagedFieldsPrinter{Delegate: printer}
+	}
+	return printer, nil
+}
+
+// AddFlags receives a *cobra.Command reference and binds
+// flags related to stream printing
+func (f *StreamPrintFlags) AddFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&f.FlattenLists, "flatten-lists", f.FlattenLists, "When using the yamlstream or jsonl output format, also print the items of the List objects found in lists, like in files, on their own.")
+}
+
+// NewStreamPrintFlags returns flags associated with stream printing, with
+// default values set.
+func NewStreamPrintFlags() *StreamPrintFlags {
+	return &StreamPrintFlags{}
+}
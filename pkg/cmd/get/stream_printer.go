@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	yamlStreamFormat = "yamlstream"
+	jsonLinesFormat  = "jsonl"
+)
+
+// StreamPrinter prints each object of a list on its own, as a YAML document
+// or a line of JSON, without the list wrapping them, so that the output can
+// be split by stream processors.
+type StreamPrinter struct {
+	// Format is yamlstream or jsonl
+	Format string
+	// FlattenLists expands the lists found in the items of a list too
+	FlattenLists bool
+
+	printCount int
+}
+
+// PrintObj prints the items of the object when it is a list, or the object.
+func (p *StreamPrinter) PrintObj(obj runtime.Object, w io.Writer) error {
+	if !meta.IsListType(obj) {
+		return p.printItem(obj, w)
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		// the lists found in the items of a list are not decoded as lists
+		list, err := u.ToList()
+		if err != nil {
+			return err
+		}
+		obj = list
+	}
+	items, err := meta.ExtractList(obj)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if p.FlattenLists && meta.IsListType(item) {
+			if err := p.PrintObj(item, w); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := p.printItem(item, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *StreamPrinter) printItem(obj runtime.Object, w io.Writer) error {
+	if p.Format == jsonLinesFormat {
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+
+	p.printCount++
+	if p.printCount > 1 {
+		if _, err := w.Write([]byte("---\n")); err != nil {
+			return err
+		}
+	}
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
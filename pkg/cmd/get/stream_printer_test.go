@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestStreamPrinter(t *testing.T) {
+	pod := func(name string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name":          name,
+				"managedFields": []interface{}{map[string]interface{}{"manager": "kubectl"}},
+			},
+		}
+	}
+	list := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{"apiVersion": "v1", "kind": "List"},
+		Items: []unstructured.Unstructured{
+			{Object: pod("foo")},
+			{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "List",
+				"items":      []interface{}{pod("bar"), pod("baz")},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		flags    StreamPrintFlags
+		format   string
+		expected string
+	}{
+		{
+			name:   "json lines",
+			format: "jsonl",
+			expected: `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"foo"}}
+{"apiVersion":"v1","items":[{"apiVersion":"v1","kind":"Pod","metadata":{"managedFields":[{"manager":"kubectl"}],"name":"bar"}},{"apiVersion":"v1","kind":"Pod","metadata":{"managedFields":[{"manager":"kubectl"}],"name":"baz"}}],"kind":"List"}
+`,
+		},
+		{
+			name:   "json lines flattening lists",
+			flags:  StreamPrintFlags{FlattenLists: true},
+			format: "jsonl",
+			expected: `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"foo"}}
+{"apiVersion":"v1","kind":"Pod","metadata":{"managedFields":[{"manager":"kubectl"}],"name":"bar"}}
+{"apiVersion":"v1","kind":"Pod","metadata":{"managedFields":[{"manager":"kubectl"}],"name":"baz"}}
+`,
+		},
+		{
+			name:   "yaml stream flattening lists with managed fields",
+			flags:  StreamPrintFlags{FlattenLists: true, ShowManagedFields: true},
+			format: "yamlstream",
+			expected: `apiVersion: v1
+kind: Pod
+metadata:
+  managedFields:
+  - manager: kubectl
+  name: foo
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  managedFields:
+  - manager: kubectl
+  name: bar
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  managedFields:
+  - manager: kubectl
+  name: baz
+`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := tc.flags.ToPrinter(tc.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			buf := &bytes.Buffer{}
+			if err := p.PrintObj(list.DeepCopy(), buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, buf.String())
+			}
+		})
+	}
+}
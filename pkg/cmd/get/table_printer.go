@@ -19,7 +19,9 @@ package get
 import (
 	"fmt"
 	"io"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	metav1beta1 "k8s.io/apimachinery/pkg/apis/meta/v1beta1"
@@ -27,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/util/timeformat"
 )
 
 // TablePrinter decodes table objects into typed objects before delegating to another printer.
@@ -85,6 +88,7 @@ func decodeIntoTable(obj runtime.Object) (runtime.Object, error) {
 		}
 		row.Object.Object = converted
 	}
+	formatAges(table)
 
 	if isEvent {
 		event.Object.Object = table
@@ -92,3 +96,27 @@ func decodeIntoTable(obj runtime.Object) (runtime.Object, error) {
 	}
 	return table, nil
 }
+
+// formatAges prints the age columns in the --time-format, from the creation
+// timestamp of the objects of the rows. The server prints relative ages.
+func formatAges(table *metav1.Table) {
+	if timeformat.Current() == timeformat.Relative {
+		return
+	}
+	for i, column := range table.ColumnDefinitions {
+		if !strings.EqualFold(column.Name, "Age") {
+			continue
+		}
+		for j := range table.Rows {
+			row := &table.Rows[j]
+			if i >= len(row.Cells) || row.Object.Object == nil {
+				continue
+			}
+			accessor, err := meta.Accessor(row.Object.Object)
+			if err != nil {
+				continue
+			}
+			row.Cells[i] = timeformat.Timestamp(accessor.GetCreationTimestamp().Time)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubectl/pkg/util/timeformat"
+)
+
+func TestFormatAges(t *testing.T) {
+	defer timeformat.Set(timeformat.Relative)
+
+	newTable := func() *metav1.Table {
+		pod := &unstructured.Unstructured{}
+		pod.SetName("web")
+		pod.SetCreationTimestamp(metav1.Unix(1709294400, 0))
+		return &metav1.Table{
+			ColumnDefinitions: []metav1.TableColumnDefinition{{Name: "Name"}, {Name: "Age", Type: "date"}},
+			Rows: []metav1.TableRow{
+				{Cells: []interface{}{"web", "5d"}, Object: runtime.RawExtension{Object: pod}},
+				{Cells: []interface{}{"db", "3d"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		format   string
+		expected []interface{}
+	}{
+		{format: timeformat.Relative, expected: []interface{}{"5d", "3d"}},
+		{format: timeformat.ISO, expected: []interface{}{"2024-03-01T12:00:00Z", "3d"}},
+		{format: timeformat.Unix, expected: []interface{}{"1709294400", "3d"}},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			if err := timeformat.Set(test.format); err != nil {
+				t.Fatal(err)
+			}
+			table := newTable()
+			formatAges(table)
+			for i, row := range table.Rows {
+				if row.Cells[1] != test.expected[i] {
+					t.Errorf("row %d: expected age %v, got %v", i, test.expected[i], row.Cells[1])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+)
+
+// minWatchBackoff and maxWatchBackoff bound how long to wait before resuming
+// a watch, doubled while the watches fail or are closed without any event.
+var minWatchBackoff = time.Second
+
+const maxWatchBackoff = 30 * time.Second
+
+// resumingWatcher watches objects, resuming the watch from the
+// resourceVersion of the last event or bookmark received when it is closed,
+// such as on network errors or server timeouts, so that no event is missed.
+// When that resourceVersion is too old, the objects are listed again.
+type resumingWatcher struct {
+	// watch starts a watch from the resourceVersion.
+	watch func(resourceVersion string) (watch.Interface, error)
+	// relist prints the current objects, and returns the resourceVersion to
+	// resume the watch from.
+	relist func() (string, error)
+	// handle is called with each event, except for bookmarks.
+	handle func(watch.Event) error
+
+	resourceVersion string
+	// resume is false to stop when the watch is closed, handling the error
+	// events as any other event.
+	resume  bool
+	backoff time.Duration
+}
+
+// run watches the objects until the context is done, or until the watch is
+// closed when not resuming it.
+func (w *resumingWatcher) run(ctx context.Context) error {
+	backoff := w.backoff
+	relist := false
+	for {
+		var received bool
+		var err error
+		if relist {
+			w.resourceVersion, err = w.relist()
+			relist = err != nil
+		}
+		if err == nil {
+			received, err = w.watchOnce(ctx)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		switch {
+		case w.resume && (apierrors.IsResourceExpired(err) || apierrors.IsGone(err)):
+			klog.V(2).Infof("The resourceVersion %s is too old, listing the objects again", w.resourceVersion)
+			relist = true
+		case err != nil:
+			if !w.resume || !isTransientWatchError(err) {
+				return err
+			}
+			klog.V(2).Infof("Resuming the watch from resourceVersion %s in %s after error: %v", w.resourceVersion, backoff, err)
+		case !w.resume:
+			return nil
+		default:
+			klog.V(2).Infof("The watch was closed, resuming it from resourceVersion %s", w.resourceVersion)
+		}
+
+		if received {
+			backoff = w.backoff
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		if !received {
+			backoff *= 2
+			if backoff > maxWatchBackoff {
+				backoff = maxWatchBackoff
+			}
+		}
+	}
+}
+
+// watchOnce handles the events of a watch until it is closed, and returns
+// true if any event was received.
+func (w *resumingWatcher) watchOnce(ctx context.Context) (bool, error) {
+	wi, err := w.watch(w.resourceVersion)
+	if err != nil {
+		return false, err
+	}
+	defer wi.Stop()
+
+	received := false
+	for {
+		select {
+		case <-ctx.Done():
+			return received, ctx.Err()
+		case e, ok := <-wi.ResultChan():
+			if !ok {
+				return received, nil
+			}
+			switch e.Type {
+			case watch.Bookmark:
+				w.setResourceVersion(e)
+				continue
+			case watch.Error:
+				if err := apierrors.FromObject(e.Object); w.resume && (apierrors.IsResourceExpired(err) || apierrors.IsGone(err)) {
+					return received, err
+				}
+			default:
+				w.setResourceVersion(e)
+				received = true
+			}
+			if err := w.handle(e); err != nil {
+				return received, err
+			}
+		}
+	}
+}
+
+func (w *resumingWatcher) setResourceVersion(e watch.Event) {
+	accessor, err := meta.Accessor(e.Object)
+	if err != nil {
+		return
+	}
+	if rv := accessor.GetResourceVersion(); len(rv) > 0 {
+		w.resourceVersion = rv
+	}
+}
+
+// isTransientWatchError returns true if a watch failing with the error may
+// succeed later.
+func isTransientWatchError(err error) bool {
+	return utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err) || utilnet.IsTimeout(err) ||
+		apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsInternalError(err)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package get
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func watchedPod(name, resourceVersion string) *unstructured.Unstructured {
+	pod := &unstructured.Unstructured{}
+	pod.SetAPIVersion("v1")
+	pod.SetKind("Pod")
+	pod.SetName(name)
+	pod.SetResourceVersion(resourceVersion)
+	return pod
+}
+
+func TestResumingWatcher(t *testing.T) {
+	expired := &metav1.Status{Status: metav1.StatusFailure, Code: 410, Reason: metav1.StatusReasonExpired, Message: "too old resource version"}
+	internal := &metav1.Status{Status: metav1.StatusFailure, Code: 500, Reason: metav1.StatusReasonInternalError, Message: "Something happened"}
+
+	tests := []struct {
+		name   string
+		resume bool
+		// watches are the events of each watch, a nil entry fails the watch
+		// with a forbidden error. The test ends at the next watch.
+		watches                  [][]watch.Event
+		expectedResourceVersions []string
+		expectedHandled          []string
+		expectedRelists          int
+		expectedError            string
+	}{
+		{
+			name:   "resumed from the last event and bookmark",
+			resume: true,
+			watches: [][]watch.Event{
+				{{Type: watch.Added, Object: watchedPod("a", "5")}},
+				{{Type: watch.Bookmark, Object: watchedPod("", "7")}},
+				{{Type: watch.Modified, Object: watchedPod("a", "8")}},
+			},
+			expectedResourceVersions: []string{"1", "5", "7", "8"},
+			expectedHandled:          []string{"ADDED a", "MODIFIED a"},
+		},
+		{
+			name:   "relisted when too old",
+			resume: true,
+			watches: [][]watch.Event{
+				{{Type: watch.Error, Object: expired}},
+				{{Type: watch.Deleted, Object: watchedPod("a", "21")}},
+			},
+			expectedResourceVersions: []string{"1", "20", "21"},
+			expectedHandled:          []string{"DELETED a"},
+			expectedRelists:          1,
+		},
+		{
+			name:   "error events printed",
+			resume: true,
+			watches: [][]watch.Event{
+				{{Type: watch.Added, Object: watchedPod("a", "5")}, {Type: watch.Error, Object: internal}},
+			},
+			expectedResourceVersions: []string{"1", "5"},
+			expectedHandled:          []string{"ADDED a", "ERROR "},
+		},
+		{
+			name:                     "failed watch",
+			resume:                   true,
+			watches:                  [][]watch.Event{nil},
+			expectedResourceVersions: []string{"1"},
+			expectedHandled:          []string{},
+			expectedError:            `pods "a" is forbidden: denied`,
+		},
+		{
+			name:   "not resumed",
+			resume: false,
+			watches: [][]watch.Event{
+				{{Type: watch.Added, Object: watchedPod("a", "5")}, {Type: watch.Error, Object: expired}},
+			},
+			expectedResourceVersions: []string{"1"},
+			expectedHandled:          []string{"ADDED a", "ERROR "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			resourceVersions := []string{}
+			handled := []string{}
+			relists := 0
+			w := &resumingWatcher{
+				watch: func(resourceVersion string) (watch.Interface, error) {
+					resourceVersions = append(resourceVersions, resourceVersion)
+					if len(resourceVersions) > len(tt.watches) {
+						// the test is done
+						cancel()
+						return watch.NewEmptyWatch(), nil
+					}
+					events := tt.watches[len(resourceVersions)-1]
+					if events == nil {
+						return nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "a", errors.New("denied"))
+					}
+					fake := watch.NewFakeWithChanSize(len(events), false)
+					for _, e := range events {
+						fake.Action(e.Type, e.Object)
+					}
+					fake.Stop()
+					return fake, nil
+				},
+				relist: func() (string, error) {
+					relists++
+					return "20", nil
+				},
+				handle: func(e watch.Event) error {
+					name := ""
+					if pod, ok := e.Object.(*unstructured.Unstructured); ok {
+						name = pod.GetName()
+					}
+					handled = append(handled, string(e.Type)+" "+name)
+					return nil
+				},
+				resourceVersion: "1",
+				resume:          tt.resume,
+				backoff:         time.Millisecond,
+			}
+
+			err := w.run(ctx)
+			if len(tt.expectedError) > 0 {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tt.expectedResourceVersions, resourceVersions) {
+				t.Errorf("expected watches from %v, got %v", tt.expectedResourceVersions, resourceVersions)
+			}
+			if !reflect.DeepEqual(tt.expectedHandled, handled) {
+				t.Errorf("expected events %v, got %v", tt.expectedHandled, handled)
+			}
+			if relists != tt.expectedRelists {
+				t.Errorf("expected %d relists, got %d", tt.expectedRelists, relists)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/relations"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	formatDot     = "dot"
+	formatMermaid = "mermaid"
+)
+
+var (
+	graphLong = templates.LongDesc(i18n.T(`
+		Print the relationships between the objects of a namespace as a graph.
+
+		The graph shows which objects own other objects, the config maps, secrets and
+		persistent volume claims mounted by pods and workloads, the pods selected by
+		services, the services routed to by ingresses, the roles and subjects of role
+		bindings, and the volumes bound to claims. Objects without any relationship are
+		left out.
+
+		The graph is printed in the DOT language of Graphviz, or as a Mermaid flowchart
+		with -o mermaid.`))
+
+	graphExample = templates.Examples(i18n.T(`
+		# Render the graph of the namespace shop as an SVG image with Graphviz
+		kubectl graph -n shop | dot -Tsvg > shop.svg
+
+		# Print the graph of all the namespaces as a Mermaid flowchart
+		kubectl graph -A -o mermaid`))
+)
+
+// GraphOptions holds the command-line options for 'graph' command
+type GraphOptions struct {
+	Output        string
+	AllNamespaces bool
+	Namespace     string
+
+	DiscoveryClient discovery.CachedDiscoveryInterface
+	DynamicClient   dynamic.Interface
+
+	genericiooptions.IOStreams
+}
+
+// NewGraphOptions returns an initialized GraphOptions instance
+func NewGraphOptions(streams genericiooptions.IOStreams) *GraphOptions {
+	return &GraphOptions{
+		Output:    formatDot,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdGraph returns a Command instance for the 'graph' command
+func NewCmdGraph(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewGraphOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "graph [-A] [-o dot|mermaid]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Print the relationships between the objects of a namespace as a graph"),
+		Long:                  graphLong,
+		Example:               graphExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: (dot, mermaid).")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, print the relationships of the objects of all the namespaces and of the cluster-scoped objects.")
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *GraphOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = ""
+	}
+	o.DiscoveryClient, err = f.ToDiscoveryClient()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = f.DynamicClient()
+	return err
+}
+
+// Validate makes sure all the provided values for command-line options are valid
+func (o *GraphOptions) Validate() error {
+	if o.Output != formatDot && o.Output != formatMermaid {
+		return fmt.Errorf("--output %v is not available in kubectl graph, must be one of: dot, mermaid", o.Output)
+	}
+	return nil
+}
+
+// Run prints the graph of the relationships between the objects
+func (o *GraphOptions) Run() error {
+	objects, err := relations.ListObjects(o.DiscoveryClient, o.DynamicClient, o.Namespace)
+	if err != nil {
+		return err
+	}
+	return writeGraph(o.Out, o.Output, relations.Resolve(objects), o.AllNamespaces)
+}
+
+// writeGraph writes the relationships in the format, the namespaces of the
+// objects being part of their labels when withNamespace is true.
+func writeGraph(out io.Writer, format string, rels []relations.Relation, withNamespace bool) error {
+	ids := map[relations.Object]string{}
+	nodes := []relations.Object{}
+	for _, r := range rels {
+		for _, obj := range []relations.Object{r.From, r.To} {
+			if _, found := ids[obj]; !found {
+				ids[obj] = ""
+				nodes = append(nodes, obj)
+			}
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].String() < nodes[j].String() })
+	for i, node := range nodes {
+		// mermaid identifiers can not contain slashes and dots
+		ids[node] = fmt.Sprintf("n%d", i)
+	}
+	label := func(obj relations.Object) string {
+		l := obj.Kind + "/" + obj.Name
+		if withNamespace && len(obj.Namespace) > 0 {
+			l = obj.Namespace + "/" + l
+		}
+		return l
+	}
+
+	var err error
+	printf := func(format string, a ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(out, format, a...)
+		}
+	}
+	switch format {
+	case formatMermaid:
+		printf("flowchart LR\n")
+		for _, node := range nodes {
+			printf("  %s[\"%s\"]\n", ids[node], label(node))
+		}
+		for _, r := range rels {
+			printf("  %s -->|%s| %s\n", ids[r.From], r.Type, ids[r.To])
+		}
+	default:
+		printf("digraph {\n")
+		for _, node := range nodes {
+			printf("  %s [label=%q];\n", ids[node], label(node))
+		}
+		for _, r := range rels {
+			printf("  %s -> %s [label=%q];\n", ids[r.From], ids[r.To], r.Type)
+		}
+		printf("}\n")
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubectl/pkg/util/relations"
+)
+
+func TestWriteGraph(t *testing.T) {
+	deployment := relations.Object{GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"}, Namespace: "shop", Name: "web"}
+	replicaSet := relations.Object{GroupKind: schema.GroupKind{Group: "apps", Kind: "ReplicaSet"}, Namespace: "shop", Name: "web-1"}
+	configMap := relations.Object{GroupKind: schema.GroupKind{Kind: "ConfigMap"}, Namespace: "shop", Name: "web-config"}
+	rels := []relations.Relation{
+		{From: deployment, To: replicaSet, Type: relations.Owns},
+		{From: replicaSet, To: configMap, Type: relations.Mounts},
+	}
+
+	tests := []struct {
+		name          string
+		format        string
+		withNamespace bool
+		expected      string
+	}{
+		{
+			name:   "dot",
+			format: formatDot,
+			expected: `digraph {
+  n0 [label="ConfigMap/web-config"];
+  n1 [label="Deployment/web"];
+  n2 [label="ReplicaSet/web-1"];
+  n1 -> n2 [label="owns"];
+  n2 -> n0 [label="mounts"];
+}
+`,
+		},
+		{
+			name:          "mermaid with namespaces",
+			format:        formatMermaid,
+			withNamespace: true,
+			expected: `flowchart LR
+  n0["shop/ConfigMap/web-config"]
+  n1["shop/Deployment/web"]
+  n2["shop/ReplicaSet/web-1"]
+  n1 -->|owns| n2
+  n2 -->|mounts| n0
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			if err := writeGraph(buf, test.format, rels, test.withNamespace); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/util/homedir"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// EnvHistory enables the recording of the commands when set to true.
+	EnvHistory = "KUBECTL_HISTORY"
+	// EnvHistoryFile is the file the commands are recorded to, instead of
+	// ~/.kube/history.log.
+	EnvHistoryFile = "KUBECTL_HISTORY_FILE"
+)
+
+// maxFileSize is the size of the history file above which it is rotated,
+// keeping a single previous file.
+var maxFileSize int64 = 10 * 1024 * 1024
+
+// Entry is a command recorded in the history file.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Duration  string    `json:"duration"`
+	Command   string    `json:"command"`
+	Args      []string  `json:"args"`
+	Context   string    `json:"context,omitempty"`
+	Server    string    `json:"server,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	// Requests are the requests made to the server, other than the discovery
+	// ones, as the method and the path of the request.
+	Requests []string `json:"requests,omitempty"`
+	ExitCode int      `json:"exitCode"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Enabled returns whether the commands are recorded.
+func Enabled() bool {
+	value := os.Getenv(EnvHistory)
+	return value == "true" || value == "1"
+}
+
+// File returns the history file.
+func File() string {
+	if file := os.Getenv(EnvHistoryFile); len(file) > 0 {
+		return file
+	}
+	return filepath.Join(homedir.HomeDir(), ".kube", "history.log")
+}
+
+// Append appends the entry to the history file, as a line of JSON. The file
+// is only readable by the user, as the commands may reveal sensitive
+// information.
+func Append(filename string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	if info, err := os.Stat(filename); err == nil && info.Size() > maxFileSize {
+		if err := os.Rename(filename, filename+".1"); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read returns the entries of the history file and of its previous file,
+// from the oldest to the newest. Lines which are not entries are skipped.
+func Read(filename string) ([]Entry, error) {
+	entries := []Entry{}
+	for _, name := range []string{filename + ".1", filename} {
+		f, err := os.Open(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			entry := Entry{}
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// Options holds the command-line options for the history command
+type Options struct {
+	Filename string
+	Since    time.Duration
+	Context  string
+	Command  string
+	Failed   bool
+	Tail     int
+	Output   string
+
+	now func() time.Time
+
+	genericiooptions.IOStreams
+}
+
+var (
+	historyLong = templates.LongDesc(i18n.T(`
+		Display the commands recorded in the local history file.
+
+		Commands are only recorded when the KUBECTL_HISTORY environment variable is set
+		to true. Each command is recorded with its arguments, the context, server and
+		namespace it targeted, the requests it made to the server and its result, to
+		~/.kube/history.log or the file set by the KUBECTL_HISTORY_FILE environment
+		variable. The values of the flags holding secrets are not recorded. Nothing is
+		sent anywhere.`))
+
+	historyExample = templates.Examples(i18n.T(`
+		# Record the commands from now on
+		export KUBECTL_HISTORY=true
+
+		# Display the commands of the last 2 hours
+		kubectl history --since=2h
+
+		# Display the failed delete commands run against the context prod
+		kubectl history --for-context=prod --command=delete --failed
+
+		# Display the last 10 commands with the requests they made
+		kubectl history --tail=10 -o json`))
+)
+
+// NewCmdHistory returns a cobra command displaying the history of the commands
+func NewCmdHistory(streams genericiooptions.IOStreams) *cobra.Command {
+	o := &Options{
+		Tail:      -1,
+		now:       time.Now,
+		IOStreams: streams,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "history [--since=DURATION] [--for-context=CONTEXT] [--command=COMMAND] [--failed] [--tail=N] [-o json]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Display the commands recorded in the local history"),
+		Long:                  historyLong,
+		Example:               historyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().DurationVar(&o.Since, "since", o.Since, "Only display the commands run within a relative duration like 5s, 2m, or 3h.")
+	cmd.Flags().StringVar(&o.Context, "for-context", o.Context, "Only display the commands run against this context.")
+	cmd.Flags().StringVar(&o.Command, "command", o.Command, "Only display the commands starting with this command, such as delete or rollout restart.")
+	cmd.Flags().BoolVar(&o.Failed, "failed", o.Failed, "If true, only display the commands which failed.")
+	cmd.Flags().IntVar(&o.Tail, "tail", o.Tail, "The number of the most recent commands to display. Defaults to -1, displaying all the commands.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: (json).")
+	return cmd
+}
+
+// Complete completes the required command-line options
+func (o *Options) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+	if len(o.Filename) == 0 {
+		o.Filename = File()
+	}
+	return nil
+}
+
+// Validate makes sure that provided values for command-line options are valid
+func (o *Options) Validate() error {
+	if o.Output != "" && o.Output != "json" {
+		return fmt.Errorf("--output %v is not available in kubectl history", o.Output)
+	}
+	if o.Since < 0 {
+		return fmt.Errorf("--since must be greater than 0")
+	}
+	return nil
+}
+
+// Run displays the matching entries of the history file
+func (o *Options) Run() error {
+	entries, err := Read(o.Filename)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 && !Enabled() {
+		fmt.Fprintf(o.ErrOut, "No commands recorded, set the %s environment variable to true to record them.\n", EnvHistory)
+		return nil
+	}
+
+	matching := []Entry{}
+	for _, entry := range entries {
+		if o.matches(entry) {
+			matching = append(matching, entry)
+		}
+	}
+	if o.Tail >= 0 && len(matching) > o.Tail {
+		matching = matching[len(matching)-o.Tail:]
+	}
+
+	if o.Output == "json" {
+		data, err := json.MarshalIndent(matching, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+		return nil
+	}
+
+	w := printers.GetNewTabWriter(o.Out)
+	fmt.Fprintln(w, "TIME\tCONTEXT\tNAMESPACE\tDURATION\tRESULT\tCOMMAND")
+	for _, entry := range matching {
+		result := "OK"
+		if entry.ExitCode != 0 {
+			result = fmt.Sprintf("Exit %d", entry.ExitCode)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", entry.Time.Format(time.RFC3339), valueOrNone(entry.Context), valueOrNone(entry.Namespace), entry.Duration, result, strings.Join(append([]string{"kubectl"}, entry.Args...), " "))
+	}
+	return w.Flush()
+}
+
+func (o *Options) matches(entry Entry) bool {
+	if o.Since > 0 && entry.Time.Before(o.now().Add(-o.Since)) {
+		return false
+	}
+	if len(o.Context) > 0 && entry.Context != o.Context {
+		return false
+	}
+	if o.Failed && entry.ExitCode == 0 {
+		return false
+	}
+	if len(o.Command) > 0 {
+		command := strings.TrimPrefix(entry.Command, "kubectl ")
+		if command != o.Command && !strings.HasPrefix(command, o.Command+" ") {
+			return false
+		}
+	}
+	return true
+}
+
+func valueOrNone(value string) string {
+	if len(value) == 0 {
+		return "<none>"
+	}
+	return value
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package history
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestHistory(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	filename := filepath.Join(t.TempDir(), "history.log")
+	entries := []*Entry{
+		{Time: now.Add(-3 * time.Hour), Duration: "1s", Command: "kubectl get", Args: []string{"get", "pods"}, Context: "dev", Namespace: "default"},
+		{Time: now.Add(-time.Hour), Duration: "2s", Command: "kubectl delete", Args: []string{"delete", "pod", "web"}, Context: "prod", Namespace: "web", ExitCode: 1, Error: "error: pods \"web\" not found"},
+		{Time: now.Add(-time.Minute), Duration: "3s", Command: "kubectl rollout restart", Args: []string{"rollout", "restart", "deploy/web"}, Context: "prod", Namespace: "web"},
+	}
+	for _, entry := range entries {
+		if err := Append(filename, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the history file to be only readable by the user, got %v", info.Mode().Perm())
+	}
+
+	tests := []struct {
+		name     string
+		options  Options
+		expected string
+	}{
+		{
+			name: "all",
+			expected: `TIME                   CONTEXT   NAMESPACE   DURATION   RESULT   COMMAND
+2024-01-01T09:00:00Z   dev       default     1s         OK       kubectl get pods
+2024-01-01T11:00:00Z   prod      web         2s         Exit 1   kubectl delete pod web
+2024-01-01T11:59:00Z   prod      web         3s         OK       kubectl rollout restart deploy/web
+`,
+		},
+		{
+			name:    "since",
+			options: Options{Since: 2 * time.Hour},
+			expected: `TIME                   CONTEXT   NAMESPACE   DURATION   RESULT   COMMAND
+2024-01-01T11:00:00Z   prod      web         2s         Exit 1   kubectl delete pod web
+2024-01-01T11:59:00Z   prod      web         3s         OK       kubectl rollout restart deploy/web
+`,
+		},
+		{
+			name:    "context and command",
+			options: Options{Context: "prod", Command: "rollout"},
+			expected: `TIME                   CONTEXT   NAMESPACE   DURATION   RESULT   COMMAND
+2024-01-01T11:59:00Z   prod      web         3s         OK       kubectl rollout restart deploy/web
+`,
+		},
+		{
+			name:    "failed",
+			options: Options{Failed: true},
+			expected: `TIME                   CONTEXT   NAMESPACE   DURATION   RESULT   COMMAND
+2024-01-01T11:00:00Z   prod      web         2s         Exit 1   kubectl delete pod web
+`,
+		},
+		{
+			name:    "tail",
+			options: Options{Tail: 1, Output: "json"},
+			expected: `[
+    {
+        "time": "2024-01-01T11:59:00Z",
+        "duration": "3s",
+        "command": "kubectl rollout restart",
+        "args": [
+            "rollout",
+            "restart",
+            "deploy/web"
+        ],
+        "context": "prod",
+        "namespace": "web",
+        "exitCode": 0
+    }
+]
+`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			streams, _, out, _ := genericiooptions.NewTestIOStreams()
+			o := tc.options
+			o.Filename = filename
+			o.IOStreams = streams
+			o.now = func() time.Time { return now }
+			if o.Tail == 0 {
+				o.Tail = -1
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestHistoryRotation(t *testing.T) {
+	defer func(size int64) { maxFileSize = size }(maxFileSize)
+	maxFileSize = 100
+	filename := filepath.Join(t.TempDir(), "history.log")
+	if err := os.WriteFile(filename, append([]byte(`{"command":"kubectl get"}`+"\n"), bytes.Repeat([]byte("\n"), 100)...), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(filename, &Entry{Command: "kubectl delete"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := Read(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Command != "kubectl get" || entries[1].Command != "kubectl delete" {
+		t.Errorf("expected the entries of the rotated file and of the new file, got %v", entries)
+	}
+}
@@ -0,0 +1,393 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/distribution/reference"
+	"github.com/spf13/cobra"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/registry"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	signatureVerified = "verified"
+	signatureUnsigned = "unsigned"
+	signatureInvalid  = "invalid"
+)
+
+var (
+	imagesLong = templates.LongDesc(i18n.T(`
+		List the images used by the containers of the pods, with their digests.
+
+		The digest of an image is the one reported by the container runtime, and the
+		images referenced by a tag rather than by a digest are reported as mutable, as
+		the tag may be moved to another image.
+
+		With --verify-signatures, the cosign signatures of the images are read from
+		their registry and verified, with a public key or with certificates issued to
+		an identity by an OIDC issuer and chaining to trusted roots, such as the Fulcio
+		roots. The signatures made with certificates must be in the Rekor transparency
+		log: the Rekor bundle attached to the signature is verified with the public key
+		of the log, and the certificate must have been valid when the signature was
+		logged. The transparency log is not contacted. The defaults of the verification
+		flags can be set in the kubectl.kubernetes.io/image-verification extension of
+		the preferences of the kubeconfig file.
+
+		With --sbom, whether an SBOM is attached to the images, as an OCI referrer, with
+		cosign attach sbom or as a cosign attestation, is reported.
+
+		The registries are authenticated to with the credentials of the docker config
+		file.`))
+
+	imagesExample = templates.Examples(i18n.T(`
+		# List the images used in the current namespace
+		kubectl images
+
+		# List the images used in all the namespaces
+		kubectl images -A
+
+		# Verify the signatures of the images of the pods labeled app=web with a public key, and check they have an SBOM
+		kubectl images -l app=web --verify-signatures --key=cosign.pub --sbom
+
+		# Verify the images are signed by the release workflow of a GitHub repository
+		kubectl images --verify-signatures --certificate-roots=fulcio.pem --rekor-key=rekor.pub \
+		  --certificate-oidc-issuer=https://token.actions.githubusercontent.com \
+		  --certificate-identity=https://github.com/example/app/.github/workflows/release.yaml@refs/heads/main`))
+)
+
+// ImagesOptions holds the command-line options for the 'images' command
+type ImagesOptions struct {
+	Namespace     string
+	AllNamespaces bool
+	LabelSelector string
+	NoHeaders     bool
+	Output        string
+
+	VerifySignatures  bool
+	SBOM              bool
+	ImageVerification cmdutil.ImageVerification
+
+	PodClient corev1client.PodsGetter
+	Registry  *registry.Client
+	verifier  *signatureVerifier
+
+	genericiooptions.IOStreams
+}
+
+// imageReport describes an image used by pods.
+type imageReport struct {
+	Image  string `json:"image"`
+	Digest string `json:"digest,omitempty"`
+	// Pods is the number of pods using the image.
+	Pods      int      `json:"pods"`
+	Signature string   `json:"signature,omitempty"`
+	SBOM      string   `json:"sbom,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// NewImagesOptions returns an ImagesOptions with the default values of the flags
+func NewImagesOptions(streams genericiooptions.IOStreams) *ImagesOptions {
+	return &ImagesOptions{IOStreams: streams}
+}
+
+// NewCmdImages returns a Command instance for the 'images' command
+func NewCmdImages(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewImagesOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "images [-n NAMESPACE | -A] [-l label] [--verify-signatures] [--sbom]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("List the images used by pods, with their digests and signatures"),
+		Long:                  imagesLong,
+		Example:               imagesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list the images used in all the namespaces.")
+	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+	cmd.Flags().BoolVar(&o.NoHeaders, "no-headers", o.NoHeaders, "When using the default output format, don't print headers (default print headers).")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, `Output format. One of: (json).`)
+	cmd.Flags().BoolVar(&o.VerifySignatures, "verify-signatures", o.VerifySignatures, "If true, verify the cosign signatures of the images.")
+	cmd.Flags().BoolVar(&o.SBOM, "sbom", o.SBOM, "If true, report whether an SBOM is attached to the images.")
+	cmd.Flags().StringVar(&o.ImageVerification.Key, "key", o.ImageVerification.Key, "Path to the PEM encoded public key the signatures are verified with.")
+	cmd.Flags().StringVar(&o.ImageVerification.CertificateIdentity, "certificate-identity", o.ImageVerification.CertificateIdentity, "The email or URI the signing certificates must be issued to, when verifying without --key.")
+	cmd.Flags().StringVar(&o.ImageVerification.CertificateOIDCIssuer, "certificate-oidc-issuer", o.ImageVerification.CertificateOIDCIssuer, "The OIDC issuer of the identity of the signing certificates, when verifying without --key.")
+	cmd.Flags().StringVar(&o.ImageVerification.CertificateRoots, "certificate-roots", o.ImageVerification.CertificateRoots, "Path to the PEM encoded certificates the signing certificates must chain to, when verifying without --key.")
+	cmd.Flags().StringVar(&o.ImageVerification.RekorKey, "rekor-key", o.ImageVerification.RekorKey, "Path to the PEM encoded public key of the Rekor transparency log the signatures must be in, when verifying without --key.")
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *ImagesOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.PodClient = clientset.CoreV1()
+
+	if !o.VerifySignatures && !o.SBOM {
+		return nil
+	}
+	if o.Registry, err = registry.NewClient(); err != nil {
+		return err
+	}
+	if !o.VerifySignatures {
+		return nil
+	}
+
+	config, err := f.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return err
+	}
+	defaults, err := cmdutil.LoadImageVerification(&config)
+	if err != nil {
+		return err
+	}
+	// the key or the certificate settings given as flags replace all the
+	// settings of the kubeconfig file
+	changed := false
+	for _, name := range []string{"key", "certificate-identity", "certificate-oidc-issuer", "certificate-roots", "rekor-key"} {
+		changed = changed || cmd.Flags().Changed(name)
+	}
+	if !changed {
+		o.ImageVerification = defaults
+	}
+	return nil
+}
+
+// Validate makes sure that the options are valid
+func (o *ImagesOptions) Validate() error {
+	if o.Output != "" && o.Output != "json" {
+		return fmt.Errorf("--output %v is not available in kubectl images", o.Output)
+	}
+	if !o.VerifySignatures {
+		return nil
+	}
+
+	settings := o.ImageVerification
+	certificate := len(settings.CertificateIdentity) > 0 || len(settings.CertificateOIDCIssuer) > 0 || len(settings.CertificateRoots) > 0 || len(settings.RekorKey) > 0
+	switch {
+	case len(settings.Key) > 0 && certificate:
+		return fmt.Errorf("--key cannot be combined with --certificate-identity, --certificate-oidc-issuer, --certificate-roots or --rekor-key")
+	case len(settings.Key) == 0 && !certificate:
+		return fmt.Errorf("--verify-signatures requires --key, or --certificate-identity, --certificate-oidc-issuer, --certificate-roots and --rekor-key")
+	case len(settings.Key) == 0 && (len(settings.CertificateIdentity) == 0 || len(settings.CertificateOIDCIssuer) == 0 || len(settings.CertificateRoots) == 0 || len(settings.RekorKey) == 0):
+		return fmt.Errorf("--certificate-identity, --certificate-oidc-issuer, --certificate-roots and --rekor-key are required together")
+	}
+	var err error
+	o.verifier, err = newSignatureVerifier(settings)
+	return err
+}
+
+// Run performs the execution of the 'images' command
+func (o *ImagesOptions) Run() error {
+	pods, err := o.PodClient.Pods(o.Namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: o.LabelSelector})
+	if err != nil {
+		return err
+	}
+	reports := collectImages(pods.Items)
+	if len(reports) == 0 {
+		if o.AllNamespaces {
+			fmt.Fprintln(o.ErrOut, "No resources found")
+		} else {
+			fmt.Fprintf(o.ErrOut, "No resources found in %s namespace.\n", o.Namespace)
+		}
+		return nil
+	}
+	for _, report := range reports {
+		o.inspect(report)
+	}
+
+	if o.Output == "json" {
+		data, err := json.MarshalIndent(reports, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+		return nil
+	}
+
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+	if !o.NoHeaders {
+		headers := []string{"IMAGE", "DIGEST", "PODS"}
+		if o.VerifySignatures {
+			headers = append(headers, "SIGNATURE")
+		}
+		if o.SBOM {
+			headers = append(headers, "SBOM")
+		}
+		fmt.Fprintln(w, strings.Join(append(headers, "WARNINGS"), "\t"))
+	}
+	for _, report := range reports {
+		cells := []string{report.Image, valueOrUnknown(report.Digest), strconv.Itoa(report.Pods)}
+		if o.VerifySignatures {
+			cells = append(cells, valueOrUnknown(report.Signature))
+		}
+		if o.SBOM {
+			cells = append(cells, valueOrUnknown(report.SBOM))
+		}
+		warnings := "<none>"
+		if len(report.Warnings) > 0 {
+			warnings = strings.Join(report.Warnings, ", ")
+		}
+		fmt.Fprintln(w, strings.Join(append(cells, warnings), "\t"))
+	}
+	return nil
+}
+
+// inspect verifies the signature of the image of the report and looks for
+// its SBOM, adding warnings for what is missing.
+func (o *ImagesOptions) inspect(report *imageReport) {
+	if !strings.Contains(report.Image, "@") {
+		report.Warnings = append(report.Warnings, "mutable tag")
+	}
+	if len(report.Digest) == 0 {
+		report.Warnings = append(report.Warnings, "unknown digest")
+		return
+	}
+	if !o.VerifySignatures && !o.SBOM {
+		return
+	}
+	named, err := reference.ParseNormalizedNamed(report.Image)
+	if err != nil {
+		report.Warnings = append(report.Warnings, err.Error())
+		return
+	}
+	repository := reference.TrimNamed(named)
+
+	if o.VerifySignatures {
+		switch err := o.verifier.verify(o.Registry, repository, report.Digest); {
+		case err == nil:
+			report.Signature = signatureVerified
+		case errors.Is(err, errUnsigned):
+			report.Signature = signatureUnsigned
+			report.Warnings = append(report.Warnings, "unsigned")
+		default:
+			report.Signature = signatureInvalid
+			report.Warnings = append(report.Warnings, "signature not verified: "+err.Error())
+		}
+	}
+	if o.SBOM {
+		sbom, err := findSBOM(o.Registry, repository, report.Digest)
+		switch {
+		case err != nil:
+			report.Warnings = append(report.Warnings, "unable to find the SBOM: "+err.Error())
+		case len(sbom) == 0:
+			report.SBOM = "none"
+			report.Warnings = append(report.Warnings, "no SBOM")
+		default:
+			report.SBOM = sbom
+		}
+	}
+}
+
+// collectImages returns a report for each image and digest used by the
+// containers of the pods, sorted by image.
+func collectImages(pods []v1.Pod) []*imageReport {
+	reports := map[string]*imageReport{}
+	podsUsing := map[string]sets.Set[string]{}
+	for _, pod := range pods {
+		statuses := map[string]string{}
+		for _, status := range append(append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...), pod.Status.EphemeralContainerStatuses...) {
+			statuses[status.Name] = status.ImageID
+		}
+		images := map[string]string{}
+		for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+			images[c.Name] = c.Image
+		}
+		for _, c := range pod.Spec.EphemeralContainers {
+			images[c.Name] = c.Image
+		}
+
+		for name, image := range images {
+			digest := imageDigest(image, statuses[name])
+			key := image + " " + digest
+			if _, ok := reports[key]; !ok {
+				reports[key] = &imageReport{Image: image, Digest: digest}
+				podsUsing[key] = sets.New[string]()
+			}
+			podsUsing[key].Insert(pod.Namespace + "/" + pod.Name)
+		}
+	}
+
+	sorted := []*imageReport{}
+	for key, report := range reports {
+		report.Pods = podsUsing[key].Len()
+		sorted = append(sorted, report)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Image != sorted[j].Image {
+			return sorted[i].Image < sorted[j].Image
+		}
+		return sorted[i].Digest < sorted[j].Digest
+	})
+	return sorted
+}
+
+// imageDigest returns the digest of the image of a container, from the image
+// ID reported by the container runtime, such as
+// docker.io/library/nginx@sha256:..., or from the image when it names one.
+func imageDigest(image, imageID string) string {
+	if _, digest, ok := strings.Cut(imageID, "@"); ok && strings.HasPrefix(digest, "sha256:") {
+		return digest
+	}
+	if _, digest, ok := strings.Cut(image, "@"); ok {
+		return digest
+	}
+	return ""
+}
+
+func valueOrUnknown(value string) string {
+	if len(value) == 0 {
+		return "<unknown>"
+	}
+	return value
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func testPod(name string, images map[string]string, imageIDs map[string]string) *v1.Pod {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+	for container, image := range images {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: container, Image: image})
+		if imageID, ok := imageIDs[container]; ok {
+			pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, v1.ContainerStatus{Name: container, ImageID: imageID})
+		}
+	}
+	return pod
+}
+
+func TestImages(t *testing.T) {
+	pinned := "registry.example.com/app@" + testImageDigest
+	client := fake.NewSimpleClientset(
+		testPod("web-1", map[string]string{"web": "nginx:1.25", "app": pinned}, map[string]string{
+			"web": "docker.io/library/nginx@sha256:1111",
+			"app": "registry.example.com/app@" + testImageDigest,
+		}),
+		testPod("web-2", map[string]string{"web": "nginx:1.25"}, map[string]string{"web": "docker-pullable://nginx@sha256:2222"}),
+		testPod("web-3", map[string]string{"web": "nginx:1.25"}, map[string]string{"web": "docker.io/library/nginx@sha256:1111"}),
+		testPod("pending", map[string]string{"app": "registry.example.com/app:latest"}, nil),
+	)
+
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+	o := NewImagesOptions(streams)
+	o.Namespace = "test"
+	o.PodClient = client.CoreV1()
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"IMAGE DIGEST PODS WARNINGS",
+		"nginx:1.25 sha256:1111 2 mutable tag",
+		"nginx:1.25 sha256:2222 1 mutable tag",
+		"registry.example.com/app:latest <unknown> 1 mutable tag, unknown digest",
+		pinned + " " + testImageDigest + " 1 <none>",
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	for i := range lines {
+		lines[i] = strings.Join(strings.Fields(lines[i]), " ")
+	}
+	if diff := cmp.Diff(expected, lines); diff != "" {
+		t.Errorf("unexpected output (-want +got):\n%s", diff)
+	}
+}
+
+func TestImagesValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		output       string
+		verification cmdutil.ImageVerification
+		expected     string
+	}{
+		{name: "invalid output", output: "yaml", expected: "--output yaml is not available"},
+		{name: "no key or certificate", expected: "--verify-signatures requires --key"},
+		{name: "key and certificate", verification: cmdutil.ImageVerification{Key: "cosign.pub", CertificateIdentity: testIdentity}, expected: "--key cannot be combined"},
+		{name: "incomplete certificate", verification: cmdutil.ImageVerification{CertificateIdentity: testIdentity}, expected: "required together"},
+		{name: "missing key", verification: cmdutil.ImageVerification{Key: "does-not-exist.pub"}, expected: "does-not-exist.pub"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := NewImagesOptions(genericiooptions.NewTestIOStreamsDiscard())
+			o.Output = test.output
+			o.VerifySignatures = true
+			o.ImageVerification = test.verification
+			if err := o.Validate(); err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"k8s.io/apimachinery/pkg/util/sets"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/registry"
+)
+
+const (
+	cosignSignatureAnnotation   = "dev.cosignproject.cosign/signature"
+	cosignCertificateAnnotation = "dev.sigstore.cosign/certificate"
+	cosignChainAnnotation       = "dev.sigstore.cosign/chain"
+	cosignBundleAnnotation      = "dev.sigstore.cosign/bundle"
+	ociManifestMediaType        = "application/vnd.oci.image.manifest.v1+json"
+)
+
+var (
+	// oidIssuer and oidIssuerV2 are the extensions of the Fulcio
+	// certificates holding the OIDC issuer of the identity.
+	oidIssuer   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+
+	// errUnsigned is returned when an image has no signature.
+	errUnsigned = errors.New("unsigned")
+)
+
+// ociManifest is the part of an OCI manifest or index used to find the
+// signatures and SBOMs of images.
+type ociManifest struct {
+	Layers    []ociDescriptor `json:"layers"`
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+type ociDescriptor struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType"`
+	Digest       string            `json:"digest"`
+	Annotations  map[string]string `json:"annotations"`
+}
+
+// simpleSigningPayload is the part of the payload signed by cosign
+// identifying the image.
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// rekorBundle is the proof, attached by cosign to the signatures made with
+// certificates, that the signature was entered in the Rekor transparency log.
+type rekorBundle struct {
+	SignedEntryTimestamp []byte             `json:"SignedEntryTimestamp"`
+	Payload              rekorBundlePayload `json:"Payload"`
+}
+
+// rekorBundlePayload is the log entry signed by Rekor. Its fields are in the
+// order of the canonical JSON encoding the signature is made over.
+type rekorBundlePayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// hashedRekord is the part of the body of a hashedrekord log entry binding
+// it to a signature.
+type hashedRekord struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+		Signature struct {
+			Content   string `json:"content"`
+			PublicKey struct {
+				Content string `json:"content"`
+			} `json:"publicKey"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+// signatureVerifier verifies the cosign signatures of images, with a public
+// key or with certificates issued to an identity.
+type signatureVerifier struct {
+	key      crypto.PublicKey
+	identity string
+	issuer   string
+	roots    *x509.CertPool
+	rekorKey crypto.PublicKey
+}
+
+// newSignatureVerifier returns a verifier for the settings, which must have
+// a key or a certificate identity, issuer, roots and Rekor key.
+func newSignatureVerifier(settings cmdutil.ImageVerification) (*signatureVerifier, error) {
+	if len(settings.Key) > 0 {
+		key, err := readPublicKey(settings.Key)
+		if err != nil {
+			return nil, err
+		}
+		return &signatureVerifier{key: key}, nil
+	}
+
+	data, err := os.ReadFile(settings.CertificateRoots)
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM encoded certificates in %s", settings.CertificateRoots)
+	}
+	rekorKey, err := readPublicKey(settings.RekorKey)
+	if err != nil {
+		return nil, err
+	}
+	return &signatureVerifier{
+		identity: settings.CertificateIdentity,
+		issuer:   settings.CertificateOIDCIssuer,
+		roots:    roots,
+		rekorKey: rekorKey,
+	}, nil
+}
+
+// readPublicKey reads a PEM encoded public key.
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded public key in %s", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key in %s: %v", path, err)
+	}
+	return key, nil
+}
+
+// verify returns nil when one of the cosign signatures of the image with the
+// given digest verifies, errUnsigned when it has none, and the reason the
+// signatures don't verify otherwise.
+func (v *signatureVerifier) verify(client *registry.Client, repository reference.Named, digest string) error {
+	manifest, err := client.Manifest(repository, cosignTag(digest, "sig"), ociManifestMediaType)
+	if errors.Is(err, registry.ErrNotFound) {
+		return errUnsigned
+	}
+	if err != nil {
+		return err
+	}
+	signatures := &ociManifest{}
+	if err := json.Unmarshal(manifest.Data, signatures); err != nil {
+		return fmt.Errorf("invalid signature manifest: %v", err)
+	}
+
+	err = errUnsigned
+	for _, layer := range signatures.Layers {
+		if _, ok := layer.Annotations[cosignSignatureAnnotation]; !ok {
+			continue
+		}
+		payload, blobErr := client.Blob(repository, layer.Digest)
+		if blobErr != nil {
+			return blobErr
+		}
+		if err = v.verifyPayload(payload, layer.Annotations, digest); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// verifyPayload verifies the signature in the annotations of a simple signing
+// payload, and that the payload is for the image with the given digest.
+func (v *signatureVerifier) verifyPayload(payload []byte, annotations map[string]string, digest string) error {
+	signature, err := base64.StdEncoding.DecodeString(annotations[cosignSignatureAnnotation])
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+
+	key := v.key
+	if key == nil {
+		certificate, err := v.verifyCertificate(payload, annotations)
+		if err != nil {
+			return err
+		}
+		key = certificate.PublicKey
+	}
+	if err := verifySignature(key, payload, signature); err != nil {
+		return err
+	}
+
+	image := &simpleSigningPayload{}
+	if err := json.Unmarshal(payload, image); err != nil {
+		return fmt.Errorf("invalid signature payload: %v", err)
+	}
+	if signed := image.Critical.Image.DockerManifestDigest; signed != digest {
+		return fmt.Errorf("the signature is for the digest %s", signed)
+	}
+	return nil
+}
+
+// verifyCertificate verifies that the signing certificate in the annotations
+// chains to the roots, at the time the signature was entered in the Rekor
+// transparency log, and was issued to the identity by the issuer.
+func (v *signatureVerifier) verifyCertificate(payload []byte, annotations map[string]string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(annotations[cosignCertificateAnnotation]))
+	if block == nil {
+		return nil, fmt.Errorf("the signature has no certificate")
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing certificate: %v", err)
+	}
+	// the certificates are short lived, they are checked at the time the
+	// signature was logged, which must be while the certificate was valid
+	integrated, err := v.verifyBundle(payload, annotations, certificate)
+	if err != nil {
+		return nil, err
+	}
+	if integrated.Before(certificate.NotBefore) || integrated.After(certificate.NotAfter) {
+		return nil, fmt.Errorf("the signature was logged at %s, outside of the validity of the signing certificate", integrated.UTC().Format(time.RFC3339))
+	}
+	intermediates := x509.NewCertPool()
+	intermediates.AppendCertsFromPEM([]byte(annotations[cosignChainAnnotation]))
+	if _, err := certificate.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   integrated,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return nil, fmt.Errorf("untrusted signing certificate: %v", err)
+	}
+
+	identities := sets.New(certificate.EmailAddresses...)
+	for _, uri := range certificate.URIs {
+		identities.Insert(uri.String())
+	}
+	if !identities.Has(v.identity) {
+		return nil, fmt.Errorf("the signing certificate is issued to %s", strings.Join(sets.List(identities), ", "))
+	}
+	if issuer := certificateIssuer(certificate); issuer != v.issuer {
+		return nil, fmt.Errorf("the signing certificate is issued by %q", issuer)
+	}
+	return certificate, nil
+}
+
+// verifyBundle verifies the Rekor bundle in the annotations, which must be
+// signed by the Rekor key and be for the signature of the payload made with
+// the certificate, and returns the time the signature was logged.
+func (v *signatureVerifier) verifyBundle(payload []byte, annotations map[string]string, certificate *x509.Certificate) (time.Time, error) {
+	data, ok := annotations[cosignBundleAnnotation]
+	if !ok {
+		return time.Time{}, fmt.Errorf("the signature is not in the transparency log")
+	}
+	bundle := &rekorBundle{}
+	if err := json.Unmarshal([]byte(data), bundle); err != nil {
+		return time.Time{}, fmt.Errorf("invalid transparency log bundle: %v", err)
+	}
+	signed, err := json.Marshal(bundle.Payload)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := verifySignature(v.rekorKey, signed, bundle.SignedEntryTimestamp); err != nil {
+		return time.Time{}, fmt.Errorf("untrusted transparency log bundle: %v", err)
+	}
+
+	body, err := base64.StdEncoding.DecodeString(bundle.Payload.Body)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid transparency log entry: %v", err)
+	}
+	entry := &hashedRekord{}
+	if err := json.Unmarshal(body, entry); err != nil {
+		return time.Time{}, fmt.Errorf("invalid transparency log entry: %v", err)
+	}
+	entryCertificate, err := base64.StdEncoding.DecodeString(entry.Spec.Signature.PublicKey.Content)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid transparency log entry: %v", err)
+	}
+	block, _ := pem.Decode(entryCertificate)
+	digest := sha256.Sum256(payload)
+	switch {
+	case entry.Kind != "hashedrekord":
+		return time.Time{}, fmt.Errorf("unsupported transparency log entry %q", entry.Kind)
+	case entry.Spec.Signature.Content != annotations[cosignSignatureAnnotation],
+		entry.Spec.Data.Hash.Algorithm != "sha256" || entry.Spec.Data.Hash.Value != fmt.Sprintf("%x", digest),
+		block == nil || !bytes.Equal(block.Bytes, certificate.Raw):
+		return time.Time{}, fmt.Errorf("the transparency log entry is for another signature")
+	}
+	return time.Unix(bundle.Payload.IntegratedTime, 0), nil
+}
+
+// certificateIssuer returns the OIDC issuer of a Fulcio certificate.
+func certificateIssuer(certificate *x509.Certificate) string {
+	for _, extension := range certificate.Extensions {
+		switch {
+		case extension.Id.Equal(oidIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(extension.Value, &issuer); err == nil {
+				return issuer
+			}
+		case extension.Id.Equal(oidIssuer):
+			return string(extension.Value)
+		}
+	}
+	return ""
+}
+
+// verifySignature verifies the signature of the payload made by the private
+// key of key, the way cosign signs.
+func verifySignature(key crypto.PublicKey, payload, signature []byte) error {
+	digest := sha256.Sum256(payload)
+	valid := false
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest[:], signature)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, payload, signature)
+	default:
+		return fmt.Errorf("unsupported public key %T", key)
+	}
+	if !valid {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// findSBOM returns how an SBOM is attached to the image with the given
+// digest, or an empty string when it has none: as an OCI referrer, with
+// cosign attach sbom or as a cosign attestation.
+func findSBOM(client *registry.Client, repository reference.Named, digest string) (string, error) {
+	referrers, err := client.Referrers(repository, digest)
+	switch {
+	case err == nil:
+		index := &ociManifest{}
+		if err := json.Unmarshal(referrers.Data, index); err != nil {
+			return "", fmt.Errorf("invalid referrers: %v", err)
+		}
+		for _, referrer := range index.Manifests {
+			if isSBOMType(referrer.ArtifactType) {
+				return "referrer", nil
+			}
+		}
+	case !errors.Is(err, registry.ErrNotFound):
+		return "", err
+	}
+
+	_, err = client.Manifest(repository, cosignTag(digest, "sbom"), registry.ManifestMediaTypes...)
+	switch {
+	case err == nil:
+		return "attached", nil
+	case !errors.Is(err, registry.ErrNotFound):
+		return "", err
+	}
+
+	manifest, err := client.Manifest(repository, cosignTag(digest, "att"), ociManifestMediaType)
+	switch {
+	case err == nil:
+		attestations := &ociManifest{}
+		if err := json.Unmarshal(manifest.Data, attestations); err != nil {
+			return "", fmt.Errorf("invalid attestation manifest: %v", err)
+		}
+		for _, layer := range attestations.Layers {
+			if isSBOMType(layer.Annotations["predicateType"]) {
+				return "attestation", nil
+			}
+		}
+	case !errors.Is(err, registry.ErrNotFound):
+		return "", err
+	}
+	return "", nil
+}
+
+func isSBOMType(value string) bool {
+	value = strings.ToLower(value)
+	return strings.Contains(value, "spdx") || strings.Contains(value, "cyclonedx")
+}
+
+// cosignTag returns the tag cosign stores the artifacts of the given kind,
+// such as sig, for the image with the given digest.
+func cosignTag(digest, kind string) string {
+	return strings.Replace(digest, ":", "-", 1) + "." + kind
+}
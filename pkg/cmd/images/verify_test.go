@@ -0,0 +1,403 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/registry"
+)
+
+const (
+	testImageDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	testIssuer      = "https://issuer.example.com"
+	testIdentity    = "release@example.com"
+)
+
+// testRegistry serves manifests by tag and blobs by digest, from the
+// repository team/app.
+type testRegistry struct {
+	manifests map[string]string
+	blobs     map[string][]byte
+}
+
+func (r *testRegistry) start(t *testing.T) (*registry.Client, reference.Named) {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		kind, name, _ := strings.Cut(strings.TrimPrefix(req.URL.Path, "/v2/team/app/"), "/")
+		switch kind {
+		case "manifests", "referrers":
+			if manifest, ok := r.manifests[kind+"/"+name]; ok {
+				fmt.Fprint(w, manifest)
+				return
+			}
+		case "blobs":
+			if blob, ok := r.blobs[name]; ok {
+				w.Write(blob)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	client, err := registry.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.HTTPClient = server.Client()
+	repository, err := reference.ParseNormalizedNamed(strings.TrimPrefix(server.URL, "https://") + "/team/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, repository
+}
+
+// testRekor signs the bundles of the signatures, as if the signatures were
+// entered in the transparency log at integrated.
+type testRekor struct {
+	key        *ecdsa.PrivateKey
+	integrated time.Time
+}
+
+// bundle returns the Rekor bundle of the signature of the payload made with
+// the key of the certificate.
+func (r *testRekor) bundle(t *testing.T, payload, signature, certificate []byte) string {
+	t.Helper()
+	hash := sha256.Sum256(payload)
+	body, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]interface{}{
+			"data": map[string]interface{}{"hash": map[string]string{"algorithm": "sha256", "value": fmt.Sprintf("%x", hash)}},
+			"signature": map[string]interface{}{
+				"content":   base64.StdEncoding.EncodeToString(signature),
+				"publicKey": map[string]string{"content": base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}))},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := fmt.Sprintf(`{"body":%q,"integratedTime":%d,"logID":"c0d23d6ad406973f","logIndex":42}`, base64.StdEncoding.EncodeToString(body), r.integrated.Unix())
+	entryHash := sha256.Sum256([]byte(entry))
+	set, err := ecdsa.SignASN1(rand.Reader, r.key, entryHash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf(`{"SignedEntryTimestamp":%q,"Payload":%s}`, base64.StdEncoding.EncodeToString(set), entry)
+}
+
+// addSignature adds a cosign signature of the image digest made with key,
+// with the certificate of the key and its Rekor bundle when there is one.
+func (r *testRegistry) addSignature(t *testing.T, key *ecdsa.PrivateKey, certificate []byte, rekor *testRekor, digest string) {
+	t.Helper()
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"team/app"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, digest))
+	hash := sha256.Sum256(payload)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	payloadDigest := fmt.Sprintf("sha256:%x", hash)
+	annotations := map[string]string{cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(signature)}
+	if certificate != nil {
+		annotations[cosignCertificateAnnotation] = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}))
+	}
+	if rekor != nil {
+		annotations[cosignBundleAnnotation] = rekor.bundle(t, payload, signature, certificate)
+	}
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"layers": []interface{}{map[string]interface{}{
+			"mediaType":   "application/vnd.dev.cosign.simplesigning.v1+json",
+			"digest":      payloadDigest,
+			"annotations": annotations,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.manifests["manifests/"+cosignTag(testImageDigest, "sig")] = string(manifest)
+	r.blobs[payloadDigest] = payload
+}
+
+func writePEM(t *testing.T, blockType string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerifyWithKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := newSignatureVerifier(cmdutil.ImageVerification{Key: writePEM(t, "PUBLIC KEY", publicKey)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		key      *ecdsa.PrivateKey
+		digest   string
+		expected string
+	}{
+		{name: "signed", key: key, digest: testImageDigest},
+		{name: "unsigned", expected: "unsigned"},
+		{name: "signed with another key", key: otherKey, digest: testImageDigest, expected: "invalid signature"},
+		{name: "signature of another image", key: key, digest: "sha256:ffff", expected: "the signature is for the digest sha256:ffff"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &testRegistry{manifests: map[string]string{}, blobs: map[string][]byte{}}
+			if test.key != nil {
+				r.addSignature(t, test.key, nil, nil, test.digest)
+			}
+			client, repository := r.start(t)
+			err := verifier.verify(client, repository, testImageDigest)
+			if len(test.expected) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error %q, got %v", test.expected, err)
+			}
+			if test.expected == "unsigned" && !errors.Is(err, errUnsigned) {
+				t.Errorf("expected errUnsigned, got %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyWithCertificate(t *testing.T) {
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test root"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	root, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootCertificate, err := x509.ParseCertificate(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the signing certificates are expired, as Fulcio certificates are short lived
+	issue := func(email, issuer string) (*ecdsa.PrivateKey, []byte) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issuerValue, err := asn1.Marshal(issuer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template := &x509.Certificate{
+			SerialNumber:    big.NewInt(2),
+			NotBefore:       now.Add(-30 * time.Minute),
+			NotAfter:        now.Add(-20 * time.Minute),
+			EmailAddresses:  []string{email},
+			KeyUsage:        x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+			ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuerValue}},
+		}
+		certificate, err := x509.CreateCertificate(rand.Reader, template, rootCertificate, key.Public(), rootKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key, certificate
+	}
+
+	rekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rekorPublicKey, err := x509.MarshalPKIXPublicKey(&rekorKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRekorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := newSignatureVerifier(cmdutil.ImageVerification{
+		CertificateIdentity:   testIdentity,
+		CertificateOIDCIssuer: testIssuer,
+		CertificateRoots:      writePEM(t, "CERTIFICATE", root),
+		RekorKey:              writePEM(t, "PUBLIC KEY", rekorPublicKey),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logged := &testRekor{key: rekorKey, integrated: now.Add(-25 * time.Minute)}
+
+	tests := []struct {
+		name     string
+		email    string
+		issuer   string
+		rekor    *testRekor
+		expected string
+	}{
+		{name: "trusted identity", email: testIdentity, issuer: testIssuer, rekor: logged},
+		{name: "other identity", email: "someone@example.com", issuer: testIssuer, rekor: logged, expected: "issued to someone@example.com"},
+		{name: "other issuer", email: testIdentity, issuer: "https://other.example.com", rekor: logged, expected: `issued by "https://other.example.com"`},
+		{name: "not logged", email: testIdentity, issuer: testIssuer, expected: "not in the transparency log"},
+		{name: "bundle of another log", email: testIdentity, issuer: testIssuer, rekor: &testRekor{key: otherRekorKey, integrated: logged.integrated}, expected: "untrusted transparency log bundle"},
+		{name: "logged after the certificate expired", email: testIdentity, issuer: testIssuer, rekor: &testRekor{key: rekorKey, integrated: now}, expected: "outside of the validity of the signing certificate"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, certificate := issue(test.email, test.issuer)
+			r := &testRegistry{manifests: map[string]string{}, blobs: map[string][]byte{}}
+			r.addSignature(t, key, certificate, test.rekor, testImageDigest)
+			client, repository := r.start(t)
+			err := verifier.verify(client, repository, testImageDigest)
+			if len(test.expected) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error %q, got %v", test.expected, err)
+			}
+		})
+	}
+
+	// a certificate of an untrusted root
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selfSigned, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, otherKey.Public(), otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &testRegistry{manifests: map[string]string{}, blobs: map[string][]byte{}}
+	r.addSignature(t, otherKey, selfSigned, logged, testImageDigest)
+	client, repository := r.start(t)
+	if err := verifier.verify(client, repository, testImageDigest); err == nil || !strings.Contains(err.Error(), "untrusted signing certificate") {
+		t.Errorf("expected an untrusted certificate, got %v", err)
+	}
+}
+
+func TestFindSBOM(t *testing.T) {
+	tests := []struct {
+		name      string
+		manifests map[string]string
+		expected  string
+	}{
+		{
+			name:      "referrer",
+			manifests: map[string]string{"referrers/" + testImageDigest: `{"manifests":[{"artifactType":"application/spdx+json","digest":"sha256:aaaa"}]}`},
+			expected:  "referrer",
+		},
+		{
+			name: "attached",
+			manifests: map[string]string{
+				"referrers/" + testImageDigest:                    `{"manifests":[{"artifactType":"application/vnd.dev.cosign.artifact.sig.v1+json"}]}`,
+				"manifests/" + cosignTag(testImageDigest, "sbom"): `{"schemaVersion":2}`,
+			},
+			expected: "attached",
+		},
+		{
+			name:      "attestation",
+			manifests: map[string]string{"manifests/" + cosignTag(testImageDigest, "att"): `{"layers":[{"annotations":{"predicateType":"https://slsa.dev/provenance/v0.2"}},{"annotations":{"predicateType":"https://cyclonedx.org/bom"}}]}`},
+			expected:  "attestation",
+		},
+		{
+			name:      "provenance only",
+			manifests: map[string]string{"manifests/" + cosignTag(testImageDigest, "att"): `{"layers":[{"annotations":{"predicateType":"https://slsa.dev/provenance/v0.2"}}]}`},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &testRegistry{manifests: test.manifests, blobs: map[string][]byte{}}
+			client, repository := r.start(t)
+			sbom, err := findSBOM(client, repository, testImageDigest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sbom != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, sbom)
+			}
+		})
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte("payload")
+	hash := sha256.Sum256(payload)
+	signature, err := key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifySignature(&key.PublicKey, payload, signature); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := verifySignature(&key.PublicKey, []byte("tampered"), signature); err == nil {
+		t.Errorf("expected a tampered payload to be rejected")
+	}
+}
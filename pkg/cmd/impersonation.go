@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/rest"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+// impersonationReasonExtra is the extra of the impersonated user holding the
+// reason given with --reason, which the audit events of the requests record.
+const impersonationReasonExtra = "reason"
+
+var (
+	asProfile           string
+	impersonationReason string
+)
+
+func addImpersonationFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&asProfile, "as-profile", "", "Impersonate the user, groups and extras of the impersonation profile of this name, stored in the "+cmdutil.ImpersonationProfilesExtension+" preferences extension of the kubeconfig file.")
+	flags.StringVar(&impersonationReason, "reason", "", "The reason for impersonating a user, recorded in the audit events of the requests as the reason extra of the impersonated user.")
+}
+
+// addImpersonationHooks impersonates the profile selected with --as-profile,
+// after asking for a confirmation if the profile requires it, and adds the
+// reason given with --reason to the impersonated requests.
+func addImpersonationHooks(cmds *cobra.Command, kubeConfigFlags *genericclioptions.ConfigFlags, streams genericiooptions.IOStreams) {
+	var profile *cmdutil.ImpersonationProfile
+	existingPreRunE := cmds.PersistentPreRunE
+	cmds.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		var err error
+		if profile, err = resolveImpersonationProfile(cmd, kubeConfigFlags, streams); err != nil {
+			return err
+		}
+		return existingPreRunE(cmd, args)
+	}
+
+	wrapConfigFn := kubeConfigFlags.WrapConfigFn
+	kubeConfigFlags.WrapConfigFn = func(c *rest.Config) *rest.Config {
+		if wrapConfigFn != nil {
+			c = wrapConfigFn(c)
+		}
+		if profile != nil {
+			c.Impersonate = rest.ImpersonationConfig{UserName: profile.User, UID: profile.UID, Groups: profile.Groups, Extra: map[string][]string{}}
+			for key, values := range profile.Extra {
+				c.Impersonate.Extra[key] = values
+			}
+		}
+		if len(impersonationReason) > 0 && len(c.Impersonate.UserName) > 0 {
+			if c.Impersonate.Extra == nil {
+				c.Impersonate.Extra = map[string][]string{}
+			}
+			c.Impersonate.Extra[impersonationReasonExtra] = []string{impersonationReason}
+		}
+		return c
+	}
+}
+
+// resolveImpersonationProfile returns the profile selected with --as-profile,
+// or nil.
+func resolveImpersonationProfile(cmd *cobra.Command, kubeConfigFlags *genericclioptions.ConfigFlags, streams genericiooptions.IOStreams) (*cmdutil.ImpersonationProfile, error) {
+	impersonating := (kubeConfigFlags.Impersonate != nil && len(*kubeConfigFlags.Impersonate) > 0) ||
+		(kubeConfigFlags.ImpersonateUID != nil && len(*kubeConfigFlags.ImpersonateUID) > 0) ||
+		(kubeConfigFlags.ImpersonateGroup != nil && len(*kubeConfigFlags.ImpersonateGroup) > 0)
+	if len(asProfile) == 0 {
+		if len(impersonationReason) > 0 && !impersonating {
+			return nil, fmt.Errorf("--reason can only be used when impersonating, with --as-profile or --as")
+		}
+		return nil, nil
+	}
+	if impersonating {
+		return nil, fmt.Errorf("--as-profile can not be used with --as, --as-uid or --as-group")
+	}
+
+	config, err := kubeConfigFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := cmdutil.LoadImpersonationProfiles(&config)
+	if err != nil {
+		return nil, err
+	}
+	profile, ok := profiles[asProfile]
+	if !ok {
+		return nil, fmt.Errorf("no impersonation profile exists with the name: %q", asProfile)
+	}
+	if profile.RequireReason && len(impersonationReason) == 0 {
+		return nil, fmt.Errorf("the impersonation profile %q requires a reason, given with --reason", asProfile)
+	}
+	if profile.Confirm {
+		who := profile.User
+		if len(profile.Groups) > 0 {
+			who += " (" + strings.Join(profile.Groups, ", ") + ")"
+		}
+		fmt.Fprintf(streams.ErrOut, i18n.T("Run %q as %s?")+" (y/n): ", cmd.CommandPath(), who)
+		var input string
+		if _, err := fmt.Fscan(streams.In, &input); err != nil || !strings.EqualFold(input, "y") {
+			return nil, fmt.Errorf("impersonation of the profile %q not confirmed", asProfile)
+		}
+	}
+	return &profile, nil
+}
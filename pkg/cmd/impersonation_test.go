@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/rest"
+)
+
+const impersonationKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: c
+  cluster:
+    server: https://example.com
+users:
+- name: u
+  user:
+    token: secret
+contexts:
+- name: c
+  context:
+    cluster: c
+    user: u
+current-context: c
+preferences:
+  extensions:
+  - name: kubectl.kubernetes.io/impersonation-profiles
+    extension:
+      readonly:
+        user: viewer
+        groups: [viewers]
+      breakglass:
+        user: admin
+        groups: [system:masters]
+        confirm: true
+        requireReason: true
+`
+
+func TestImpersonationHooks(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(impersonationKubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		profile       string
+		reason        string
+		as            string
+		input         string
+		expected      rest.ImpersonationConfig
+		expectedError string
+	}{
+		{name: "none"},
+		{name: "profile", profile: "readonly", expected: rest.ImpersonationConfig{UserName: "viewer", Groups: []string{"viewers"}, Extra: map[string][]string{}}},
+		{name: "reason with --as", as: "jane", reason: "incident 42", expected: rest.ImpersonationConfig{UserName: "jane", Extra: map[string][]string{"reason": {"incident 42"}}}},
+		{
+			name:     "confirmed",
+			profile:  "breakglass",
+			reason:   "incident 42",
+			input:    "y\n",
+			expected: rest.ImpersonationConfig{UserName: "admin", Groups: []string{"system:masters"}, Extra: map[string][]string{"reason": {"incident 42"}}},
+		},
+		{name: "not confirmed", profile: "breakglass", reason: "incident 42", input: "n\n", expectedError: `impersonation of the profile "breakglass" not confirmed`},
+		{name: "no reason", profile: "breakglass", expectedError: `the impersonation profile "breakglass" requires a reason, given with --reason`},
+		{name: "unknown profile", profile: "root", expectedError: `no impersonation profile exists with the name: "root"`},
+		{name: "profile with --as", profile: "readonly", as: "jane", expectedError: "--as-profile can not be used with --as, --as-uid or --as-group"},
+		{name: "reason without impersonation", reason: "incident 42", expectedError: "--reason can only be used when impersonating, with --as-profile or --as"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			asProfile, impersonationReason = tc.profile, tc.reason
+			defer func() { asProfile, impersonationReason = "", "" }()
+
+			kubeConfigFlags := genericclioptions.NewConfigFlags(true)
+			kubeConfigFlags.KubeConfig = &kubeconfig
+			kubeConfigFlags.Impersonate = &tc.as
+			streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+			streams.In = strings.NewReader(tc.input)
+
+			cmd := &cobra.Command{Use: "kubectl", PersistentPreRunE: func(*cobra.Command, []string) error { return nil }}
+			addImpersonationHooks(cmd, kubeConfigFlags, streams)
+			err := cmd.PersistentPreRunE(cmd, nil)
+			if len(tc.expectedError) > 0 {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tc.input) > 0 && !strings.Contains(errOut.String(), `Run "kubectl" as admin (system:masters)? (y/n): `) {
+				t.Errorf("expected a confirmation prompt, got %q", errOut.String())
+			}
+
+			config, err := kubeConfigFlags.ToRESTConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tc.expected, config.Impersonate) {
+				t.Errorf("expected %#v, got %#v", tc.expected, config.Impersonate)
+			}
+		})
+	}
+}
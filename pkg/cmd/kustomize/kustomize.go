@@ -21,6 +21,7 @@ import (
 
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/kubectl/pkg/util/i18n"
+	kustomizeutil "k8s.io/kubectl/pkg/util/kustomize"
 	"k8s.io/kubectl/pkg/util/templates"
 	"sigs.k8s.io/kustomize/kustomize/v5/commands/build"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
@@ -28,9 +29,10 @@ import (
 
 // NewCmdKustomize returns an adapted kustomize build command.
 func NewCmdKustomize(streams genericiooptions.IOStreams) *cobra.Command {
+	o := kustomizeutil.NewOptions()
 	h := build.MakeHelp("kubectl", "kustomize")
-	return build.NewCmdBuild(
-		filesys.MakeFsOnDisk(),
+	cmd := build.NewCmdBuild(
+		o.FileSystem(filesys.MakeFsOnDisk()),
 		&build.Help{
 			Use:     h.Use,
 			Short:   i18n.T(h.Short),
@@ -38,4 +40,21 @@ func NewCmdKustomize(streams genericiooptions.IOStreams) *cobra.Command {
 			Example: templates.Examples(i18n.T(h.Example)),
 		},
 		streams.Out)
+
+	// the remote bases are read from the cache, including the kustomization
+	// root itself
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		o.Complete(cmd)
+		for i := range args {
+			root, err := o.Root(args[i])
+			if err != nil {
+				return err
+			}
+			args[i] = root
+		}
+		return o.Error(run(cmd, args))
+	}
+	o.AddFlags(cmd)
+	return cmd
 }
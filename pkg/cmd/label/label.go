@@ -73,6 +73,9 @@ type LabelOptions struct {
 	fieldSelector   string
 	outputFormat    string
 	fieldManager    string
+	fromFiles       []string
+	printPatch      bool
+	atomic          bool
 
 	// results of arg parsing
 	resources    []string
@@ -117,7 +120,16 @@ var (
 
 		# Update pod 'foo' by removing a label named 'bar' if it exists
 		# Does not require the --overwrite flag
-		kubectl label pods foo bar-`))
+		kubectl label pods foo bar-
+
+		# Update pod 'foo' with the label 'version' read from the file VERSION
+		kubectl label pods foo --from-file=version=VERSION
+
+		# Print the patch that would update pod 'foo' instead of applying it
+		kubectl label pods foo status=unhealthy -o patch
+
+		# Update all the pods with the label 'app=web', or none of them if any update fails
+		kubectl label pods -l app=web tier=frontend --atomic`))
 )
 
 func NewLabelOptions(ioStreams genericiooptions.IOStreams) *LabelOptions {
@@ -150,8 +162,12 @@ func NewCmdLabel(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra
 
 	o.RecordFlags.AddFlags(cmd)
 	o.PrintFlags.AddFlags(cmd)
+	if flag := cmd.Flags().Lookup("output"); flag != nil {
+		flag.Usage += " Use 'patch' to print the JSON merge patch for each resource instead of applying it."
+	}
 
 	cmd.Flags().BoolVar(&o.overwrite, "overwrite", o.overwrite, "If true, allow labels to be overwritten, otherwise reject label updates that overwrite existing labels.")
+	cmd.Flags().StringArrayVar(&o.fromFiles, "from-file", o.fromFiles, "Set the label KEY to the content of the file at PATH, given as KEY=PATH. A trailing newline is removed.")
 	cmd.Flags().BoolVar(&o.list, "list", o.list, "If true, display the labels for a given resource.")
 	cmd.Flags().BoolVar(&o.local, "local", o.local, "If true, label will NOT contact api-server but run locally.")
 	cmd.Flags().StringVar(&o.fieldSelector, "field-selector", o.fieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The server only supports a limited number of field queries per type.")
@@ -163,6 +179,7 @@ func NewCmdLabel(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra
 	cmdutil.AddDryRunFlag(cmd)
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.fieldManager, "kubectl-label")
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.selector)
+	cmdutil.AddAtomicFlagVar(cmd, &o.atomic)
 
 	return cmd
 }
@@ -178,6 +195,11 @@ func (o *LabelOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []st
 	}
 
 	o.outputFormat = cmdutil.GetFlagString(cmd, "output")
+	if o.outputFormat == "patch" {
+		o.printPatch = true
+		o.outputFormat = ""
+		*o.PrintFlags.OutputFormat = ""
+	}
 	o.dryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
 	if err != nil {
 		return err
@@ -199,8 +221,30 @@ func (o *LabelOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []st
 	if err != nil {
 		return err
 	}
+	fileLabels, err := cmdutil.ParseFilePairs(o.fromFiles, "label")
+	if err != nil {
+		return err
+	}
+	for key, value := range fileLabels {
+		if _, found := o.newLabels[key]; found {
+			return fmt.Errorf("label %q is given both as an argument and with --from-file", key)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) != 0 {
+			return fmt.Errorf("invalid label key %q given with --from-file: %s", key, strings.Join(errs, ";"))
+		}
+		for _, removeLabel := range o.removeLabels {
+			if removeLabel == key {
+				return fmt.Errorf("can not both modify and remove a label in the same command")
+			}
+		}
+		value = strings.TrimRight(value, "\r\n")
+		if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+			return fmt.Errorf("invalid label value for %q read from file: %s", key, strings.Join(errs, ";"))
+		}
+		o.newLabels[key] = value
+	}
 
-	if o.list && len(o.outputFormat) > 0 {
+	if o.list && (len(o.outputFormat) > 0 || o.printPatch) {
 		return fmt.Errorf("--list and --output may not be specified together")
 	}
 
@@ -240,6 +284,9 @@ func (o *LabelOptions) Validate() error {
 	if len(o.newLabels) < 1 && len(o.removeLabels) < 1 && !o.list {
 		return fmt.Errorf("at least one label update is required")
 	}
+	if o.atomic && (o.local || o.list || o.printPatch || o.dryRunStrategy != cmdutil.DryRunNone) {
+		return fmt.Errorf("--atomic cannot be used with --local, --list, --dry-run or -o patch")
+	}
 	return nil
 }
 
@@ -272,8 +319,13 @@ func (o *LabelOptions) RunLabel() error {
 		return fmt.Errorf("--resource-version may only be used with a single resource")
 	}
 
+	var transaction *cmdutil.Transaction
+	if o.atomic {
+		transaction = &cmdutil.Transaction{}
+	}
+
 	// TODO: support bulk generic output a la Get
-	return r.Visit(func(info *resource.Info, err error) error {
+	change := func(info *resource.Info, err error) error {
 		if err != nil {
 			return err
 		}
@@ -295,6 +347,21 @@ func (o *LabelOptions) RunLabel() error {
 		if err != nil {
 			return err
 		}
+		if o.printPatch {
+			if err := labelFunc(obj, o.overwrite, o.resourceVersion, o.newLabels, o.removeLabels); err != nil {
+				return err
+			}
+			newObj, err := json.Marshal(obj)
+			if err != nil {
+				return err
+			}
+			patchBytes, err := jsonpatch.CreateMergePatch(oldData, newObj)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(o.Out, "%s\n", patchBytes)
+			return err
+		}
 		if o.dryRunStrategy == cmdutil.DryRunClient || o.local || o.list {
 			err = labelFunc(obj, o.overwrite, o.resourceVersion, o.newLabels, o.removeLabels)
 			if err != nil {
@@ -355,6 +422,11 @@ func (o *LabelOptions) RunLabel() error {
 			if err != nil {
 				return err
 			}
+			if transaction != nil {
+				if err := transaction.Record(helper, info, oldData, newObj); err != nil {
+					return err
+				}
+			}
 		}
 
 		if o.list {
@@ -384,7 +456,14 @@ func (o *LabelOptions) RunLabel() error {
 			return err
 		}
 		return printer.PrintObj(info.Object, o.Out)
-	})
+	}
+
+	if transaction != nil {
+		return transaction.Run(r, func(info *resource.Info) error {
+			return labelFunc(info.Object.DeepCopyObject(), o.overwrite, o.resourceVersion, o.newLabels, o.removeLabels)
+		}, change, o.ErrOut)
+	}
+	return r.Visit(change)
 }
 
 func updateDataChangeMsg(oldObj []byte, newObj []byte, overwrite bool) string {
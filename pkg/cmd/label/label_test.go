@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -458,6 +460,79 @@ func TestLabelLocal(t *testing.T) {
 	}
 }
 
+func TestLabelFromFilePatch(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			t.Fatalf("unexpected request: %s %#v\n%#v", req.Method, req.URL, req)
+			return nil, nil
+		}),
+	}
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+	dir := t.TempDir()
+	versionFile := filepath.Join(dir, "VERSION")
+	if err := os.WriteFile(versionFile, []byte("v1.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ioStreams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdLabel(tf, ioStreams)
+	if err := cmd.Flags().Set("output", "patch"); err != nil {
+		t.Fatal(err)
+	}
+	opts := NewLabelOptions(ioStreams)
+	opts.Filenames = []string{"../../../testdata/controller.yaml"}
+	opts.local = true
+	opts.fromFiles = []string{"version=" + versionFile}
+	err := opts.Complete(tf, cmd, []string{"a=b"})
+	if err == nil {
+		err = opts.Validate()
+	}
+	if err == nil {
+		err = opts.RunLabel()
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"metadata":{"labels":{"a":"b","version":"v1.2"}}}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected patch %q, got %q", expected, buf.String())
+	}
+
+	opts = NewLabelOptions(ioStreams)
+	opts.fromFiles = []string{"a=" + versionFile}
+	err = opts.Complete(tf, cmd, []string{"pods", "foo", "a=b"})
+	if err == nil || !strings.Contains(err.Error(), "both as an argument and with --from-file") {
+		t.Errorf("expected a duplicate label error, got %v", err)
+	}
+
+	invalidFile := filepath.Join(dir, "invalid")
+	if err := os.WriteFile(invalidFile, []byte("not a label value\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		fromFile string
+		args     []string
+		expected string
+	}{
+		{fromFile: "bad key=" + versionFile, expected: "invalid label key \"bad key\""},
+		{fromFile: "example.com/=" + versionFile, expected: "invalid label key \"example.com/\""},
+		{fromFile: "version=" + invalidFile, expected: "invalid label value for \"version\""},
+		{fromFile: "version=" + versionFile, args: []string{"version-"}, expected: "can not both modify and remove a label"},
+	} {
+		opts = NewLabelOptions(ioStreams)
+		opts.fromFiles = []string{tc.fromFile}
+		err = opts.Complete(tf, cmd, append([]string{"pods", "foo"}, tc.args...))
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", tc.fromFile, tc.expected, err)
+		}
+	}
+}
+
 func TestLabelMultipleObjects(t *testing.T) {
 	pods, _, _ := cmdtesting.TestData()
 	tf := cmdtesting.NewTestFactory().WithNamespace("test")
@@ -514,6 +589,103 @@ func TestLabelMultipleObjects(t *testing.T) {
 	}
 }
 
+func TestLabelAtomic(t *testing.T) {
+	tests := []struct {
+		name            string
+		barLabels       map[string]string
+		failBar         bool
+		expectedPatches []string
+		expectedErr     string
+		expectedErrOut  string
+	}{
+		{
+			name:            "all labeled",
+			expectedPatches: []string{`foo {"metadata":{"labels":{"a":"b"}}}`, `bar {"metadata":{"labels":{"a":"b"}}}`},
+			expectedErrOut:  "Transaction committed: 2 of 2 objects changed.\n",
+		},
+		{
+			name:            "rolled back",
+			failBar:         true,
+			expectedPatches: []string{`foo {"metadata":{"labels":{"a":"b"}}}`, `bar {"metadata":{"labels":{"a":"b"}}}`, `foo {"metadata":{"labels":null}}`},
+			expectedErr:     "pods/bar:",
+			expectedErrOut:  "Transaction rolled back: 1 of 1 changed objects restored.\n",
+		},
+		{
+			name:            "check failed",
+			barLabels:       map[string]string{"a": "c"},
+			expectedPatches: []string{},
+			expectedErr:     "no object was changed, pods/bar: 'a' already has a value (c), and --overwrite is false",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pods, _, _ := cmdtesting.TestData()
+			pods.Items[1].Labels = tc.barLabels
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+			patches := []string{}
+			tf.UnstructuredClient = &fake.RESTClient{
+				NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					switch req.Method {
+					case "GET":
+						if req.URL.Path == "/namespaces/test/pods" {
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, pods)}, nil
+						}
+					case "PATCH":
+						data, err := io.ReadAll(req.Body)
+						if err != nil {
+							t.Fatal(err)
+						}
+						switch req.URL.Path {
+						case "/namespaces/test/pods/foo":
+							patches = append(patches, "foo "+string(data))
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &pods.Items[0])}, nil
+						case "/namespaces/test/pods/bar":
+							patches = append(patches, "bar "+string(data))
+							if tc.failBar {
+								return &http.Response{StatusCode: http.StatusInternalServerError, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.StringBody("")}, nil
+							}
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &pods.Items[1])}, nil
+						}
+					}
+					t.Fatalf("unexpected request: %s %#v\n%#v", req.Method, req.URL, req)
+					return nil, nil
+				}),
+			}
+			tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+			ioStreams, _, _, errBuf := genericiooptions.NewTestIOStreams()
+			opts := NewLabelOptions(ioStreams)
+			opts.all = true
+			opts.atomic = true
+			cmd := NewCmdLabel(tf, ioStreams)
+			err := opts.Complete(tf, cmd, []string{"pods", "a=b"})
+			if err == nil {
+				err = opts.Validate()
+			}
+			if err == nil {
+				err = opts.RunLabel()
+			}
+			if len(tc.expectedErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expectedPatches, patches) {
+				t.Errorf("expected patches %v, got %v", tc.expectedPatches, patches)
+			}
+			if errBuf.String() != tc.expectedErrOut {
+				t.Errorf("expected %q, got %q", tc.expectedErrOut, errBuf.String())
+			}
+		})
+	}
+}
+
 func TestLabelResourceVersion(t *testing.T) {
 	tf := cmdtesting.NewTestFactory().WithNamespace("test")
 	defer tf.Cleanup()
@@ -38,6 +38,7 @@ import (
 	"k8s.io/kubectl/pkg/polymorphichelpers"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/color"
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -84,7 +85,10 @@ var (
 		kubectl logs job/hello
 
 		# Return snapshot logs from container nginx-1 of a deployment named nginx
-		kubectl logs deployment/nginx -c nginx-1`))
+		kubectl logs deployment/nginx -c nginx-1
+
+		# Pick the pod to begin streaming the logs of from the pods defined by label app=nginx
+		kubectl logs -f -l app=nginx --pick`))
 
 	selectorTail    int64 = 10
 	logsUsageErrStr       = fmt.Sprintf("expected '%s'.\nPOD or TYPE/NAME is a required argument for the logs command", logsUsageStr)
@@ -120,6 +124,10 @@ type LogsOptions struct {
 	Selector               string
 	MaxFollowConcurrency   int
 	Prefix                 bool
+	// Pick asks the user to pick the pod when none is given, among the pods
+	// matching the selector if any, with PickFn.
+	Pick   bool
+	PickFn cmdutil.PickFunc
 
 	Object           runtime.Object
 	GetPodTimeout    time.Duration
@@ -162,6 +170,7 @@ func NewCmdLogs(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 		},
 	}
 	o.AddFlags(cmd)
+	cmdutil.CheckErr(cmd.RegisterFlagCompletionFunc("container", completion.ContainerCompletionFunc(f)))
 	return cmd
 }
 
@@ -182,6 +191,7 @@ func (o *LogsOptions) AddFlags(cmd *cobra.Command) {
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.Selector)
 	cmd.Flags().IntVar(&o.MaxFollowConcurrency, "max-log-requests", o.MaxFollowConcurrency, "Specify maximum number of concurrent logs to follow when using by a selector. Defaults to 5.")
 	cmd.Flags().BoolVar(&o.Prefix, "prefix", o.Prefix, "Prefix each log line with the log source (pod name and container name)")
+	cmdutil.AddPickFlagVar(cmd, &o.Pick)
 }
 
 func (o *LogsOptions) ToLogOptions() (*corev1.PodLogOptions, error) {
@@ -228,7 +238,7 @@ func (o *LogsOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []str
 
 	switch len(args) {
 	case 0:
-		if len(o.Selector) == 0 {
+		if len(o.Selector) == 0 && !o.Pick {
 			return cmdutil.UsageErrorf(cmd, "%s", logsUsageErrStr)
 		}
 	case 1:
@@ -248,6 +258,22 @@ func (o *LogsOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []str
 		return err
 	}
 
+	if o.Pick && len(args) == 0 {
+		if o.PickFn == nil {
+			o.PickFn, err = cmdutil.NewTerminalPickFunc(o.IOStreams)
+			if err != nil {
+				return err
+			}
+		}
+		info, err := cmdutil.PickObject(f, o.PickFn, "pods", o.Namespace, false, o.Selector)
+		if err != nil {
+			return err
+		}
+		o.ResourceArg = info.Name
+		o.Resources = []string{info.Name}
+		o.Selector = ""
+	}
+
 	o.ConsumeRequestFn = DefaultConsumeRequest
 
 	o.GetPodTimeout, err = cmdutil.GetPodRunningTimeoutFlag(cmd)
@@ -329,6 +355,12 @@ func (o LogsOptions) RunLogs() error {
 		return err
 	}
 
+	if color.Enabled(o.Out) {
+		out := color.NewLogWriter(o.Out)
+		defer out.Flush()
+		o.Out = out
+	}
+
 	if o.Follow && len(requests) > 1 {
 		if len(requests) > o.MaxFollowConcurrency {
 			return fmt.Errorf(
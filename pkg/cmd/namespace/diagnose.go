@@ -0,0 +1,356 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/completion"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	diagnoseLong = templates.LongDesc(i18n.T(`
+		Diagnose why a namespace is stuck terminating.
+
+		The conditions reported by the namespace controller are printed, with the API
+		groups which can not be discovered, such as aggregated APIs whose server is
+		unavailable: the namespace can not be deleted until they are available again or
+		their API service is deleted. The resources remaining in the namespace are
+		counted by group and kind, and the finalizers blocking their deletion are listed.
+
+		With --clear-finalizers, the finalizers of the remaining resources are removed
+		after a confirmation. When no resource remains, the finalizers of the namespace
+		itself are removed instead. Removing finalizers lets the objects be deleted
+		before the controllers owning them clean up, which can leave resources behind
+		outside of the cluster.`))
+
+	diagnoseExample = templates.Examples(i18n.T(`
+		# Diagnose why the namespace old-team is stuck terminating
+		kubectl namespace diagnose old-team
+
+		# Remove the finalizers blocking the deletion of the namespace old-team
+		kubectl namespace diagnose old-team --clear-finalizers`))
+)
+
+// DiagnoseOptions holds the command-line options for 'namespace diagnose' sub command
+type DiagnoseOptions struct {
+	Name            string
+	ClearFinalizers bool
+
+	Client          coreclient.CoreV1Interface
+	DiscoveryClient discovery.DiscoveryInterface
+	DynamicClient   dynamic.Interface
+
+	genericiooptions.IOStreams
+
+	now func() time.Time
+}
+
+// remainingResource counts the objects of a resource left in the namespace.
+type remainingResource struct {
+	gvr     schema.GroupVersionResource
+	kind    string
+	objects []unstructured.Unstructured
+	// err is the error listing the objects
+	err error
+}
+
+// NewDiagnoseOptions returns an initialized DiagnoseOptions instance
+func NewDiagnoseOptions(streams genericiooptions.IOStreams) *DiagnoseOptions {
+	return &DiagnoseOptions{
+		IOStreams: streams,
+		now:       time.Now,
+	}
+}
+
+// NewCmdNamespaceDiagnose returns a Command instance for 'namespace diagnose' sub command
+func NewCmdNamespaceDiagnose(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewDiagnoseOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "diagnose NAME [--clear-finalizers]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Diagnose why a namespace is stuck terminating"),
+		Long:                  diagnoseLong,
+		Example:               diagnoseExample,
+		ValidArgsFunction:     completion.ResourceNameCompletionFunc(f, "namespace"),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.ClearFinalizers, "clear-finalizers", o.ClearFinalizers, "If true, remove the finalizers blocking the deletion of the namespace after a confirmation.")
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *DiagnoseOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmdutil.UsageErrorf(cmd, "exactly one namespace name is required, got %d", len(args))
+	}
+	o.Name = args[0]
+
+	clientset, err := f.KubernetesClientSet()
+	if err != nil {
+		return err
+	}
+	o.Client = clientset.CoreV1()
+	o.DiscoveryClient, err = f.ToDiscoveryClient()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = f.DynamicClient()
+	return err
+}
+
+// Run performs the execution of 'namespace diagnose' sub command
+func (o *DiagnoseOptions) Run() error {
+	ctx := context.TODO()
+	namespace, err := o.Client.Namespaces().Get(ctx, o.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	terminating := namespace.DeletionTimestamp != nil
+
+	lists, err := o.DiscoveryClient.ServerPreferredNamespacedResources()
+	failedGroups := map[schema.GroupVersion]error{}
+	if err != nil {
+		var discoveryErr *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &discoveryErr) {
+			return err
+		}
+		failedGroups = discoveryErr.Groups
+	}
+	remaining := o.remainingResources(ctx, lists)
+
+	w := printers.GetNewTabWriter(o.Out)
+	o.printNamespace(w, namespace)
+	printFailedGroups(w, failedGroups)
+	printRemainingResources(w, remaining)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !o.ClearFinalizers {
+		return nil
+	}
+	if !terminating {
+		return fmt.Errorf("namespace %q is not terminating, its finalizers are not removed", o.Name)
+	}
+	return o.clearFinalizers(ctx, namespace, remaining)
+}
+
+// remainingResources lists the objects of the namespaced resources which
+// can be listed and deleted.
+func (o *DiagnoseOptions) remainingResources(ctx context.Context, lists []*metav1.APIResourceList) []remainingResource {
+	remaining := []remainingResource{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") || !sets.New(r.Verbs...).HasAll("list", "delete") {
+				continue
+			}
+			gvr := gv.WithResource(r.Name)
+			items, err := o.DynamicClient.Resource(gvr).Namespace(o.Name).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				remaining = append(remaining, remainingResource{gvr: gvr, kind: r.Kind, err: err})
+				continue
+			}
+			if len(items.Items) > 0 {
+				remaining = append(remaining, remainingResource{gvr: gvr, kind: r.Kind, objects: items.Items})
+			}
+		}
+	}
+	sort.Slice(remaining, func(i, j int) bool {
+		if remaining[i].gvr.Group != remaining[j].gvr.Group {
+			return remaining[i].gvr.Group < remaining[j].gvr.Group
+		}
+		return remaining[i].kind < remaining[j].kind
+	})
+	return remaining
+}
+
+func (o *DiagnoseOptions) printNamespace(w io.Writer, namespace *corev1.Namespace) {
+	fmt.Fprintf(w, "Namespace:\t%s\n", namespace.Name)
+	if namespace.DeletionTimestamp != nil {
+		since := o.now().Sub(namespace.DeletionTimestamp.Time).Round(time.Second)
+		fmt.Fprintf(w, "Status:\tTerminating for %s\n", since)
+	} else {
+		fmt.Fprintf(w, "Status:\t%s\n", namespace.Status.Phase)
+	}
+	finalizers := []string{}
+	for _, finalizer := range namespace.Spec.Finalizers {
+		finalizers = append(finalizers, string(finalizer))
+	}
+	finalizers = append(finalizers, namespace.Finalizers...)
+	if len(finalizers) == 0 {
+		finalizers = append(finalizers, "<none>")
+	}
+	fmt.Fprintf(w, "Finalizers:\t%s\n", strings.Join(finalizers, ", "))
+
+	for _, condition := range namespace.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\t%s\n", condition.Type, condition.Message)
+	}
+}
+
+func printFailedGroups(w io.Writer, failedGroups map[schema.GroupVersion]error) {
+	if len(failedGroups) == 0 {
+		return
+	}
+	groupVersions := []schema.GroupVersion{}
+	for gv := range failedGroups {
+		groupVersions = append(groupVersions, gv)
+	}
+	sort.Slice(groupVersions, func(i, j int) bool {
+		return groupVersions[i].String() < groupVersions[j].String()
+	})
+	fmt.Fprintf(w, "\nUnavailable APIs, blocking the deletion until available again or their API service is deleted:\n")
+	for _, gv := range groupVersions {
+		fmt.Fprintf(w, "  %s\t%v\n", gv, failedGroups[gv])
+	}
+}
+
+func printRemainingResources(w io.Writer, remaining []remainingResource) {
+	if len(remaining) == 0 {
+		fmt.Fprintf(w, "\nNo resources remaining.\n")
+		return
+	}
+	fmt.Fprintf(w, "\nRemaining resources:\n")
+	fmt.Fprintf(w, "  GROUP\tKIND\tCOUNT\n")
+	blocked := []string{}
+	for _, r := range remaining {
+		group := r.gvr.Group
+		if len(group) == 0 {
+			group = "core"
+		}
+		if r.err != nil {
+			fmt.Fprintf(w, "  %s\t%s\t<unknown>: %v\n", group, r.kind, r.err)
+			continue
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%d\n", group, r.kind, len(r.objects))
+		for _, obj := range r.objects {
+			if finalizers := obj.GetFinalizers(); len(finalizers) > 0 {
+				blocked = append(blocked, fmt.Sprintf("  %s\t%s\t%s\n", resourceKind(r), obj.GetName(), strings.Join(finalizers, ", ")))
+			}
+		}
+	}
+	if len(blocked) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nBlocking finalizers:\n")
+	fmt.Fprintf(w, "  KIND\tNAME\tFINALIZERS\n")
+	for _, line := range blocked {
+		fmt.Fprint(w, line)
+	}
+}
+
+// clearFinalizers removes the finalizers of the remaining objects or, when
+// no object remains, of the namespace, after a confirmation.
+func (o *DiagnoseOptions) clearFinalizers(ctx context.Context, namespace *corev1.Namespace, remaining []remainingResource) error {
+	if len(remaining) == 0 {
+		if len(namespace.Spec.Finalizers) == 0 {
+			fmt.Fprintf(o.Out, "No finalizers to remove.\n")
+			return nil
+		}
+		if !o.confirm(fmt.Sprintf("the namespace %s", namespace.Name)) {
+			return nil
+		}
+		namespace.Spec.Finalizers = nil
+		if _, err := o.Client.Namespaces().Finalize(ctx, namespace, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("unable to remove the finalizers of namespace %s: %v", namespace.Name, err)
+		}
+		fmt.Fprintf(o.Out, "namespace/%s finalizers removed\n", namespace.Name)
+		return nil
+	}
+
+	blocked := 0
+	for _, r := range remaining {
+		for _, obj := range r.objects {
+			if len(obj.GetFinalizers()) > 0 {
+				blocked++
+			}
+		}
+	}
+	if blocked == 0 {
+		fmt.Fprintf(o.Out, "No finalizers to remove.\n")
+		return nil
+	}
+	if !o.confirm(fmt.Sprintf("the %d objects blocked by finalizers", blocked)) {
+		return nil
+	}
+	patch := []byte(`{"metadata":{"finalizers":null}}`)
+	for _, r := range remaining {
+		for _, obj := range r.objects {
+			if len(obj.GetFinalizers()) == 0 {
+				continue
+			}
+			name := fmt.Sprintf("%s/%s", resourceKind(r), obj.GetName())
+			if _, err := o.DynamicClient.Resource(r.gvr).Namespace(o.Name).Patch(ctx, obj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return fmt.Errorf("unable to remove the finalizers of %s: %v", name, err)
+			}
+			fmt.Fprintf(o.Out, "%s finalizers removed\n", name)
+		}
+	}
+	return nil
+}
+
+func (o *DiagnoseOptions) confirm(what string) bool {
+	fmt.Fprintf(o.Out, i18n.T("Removing the finalizers lets %s be deleted before the controllers owning them clean up. Remove them?")+" (y/n): ", what)
+	var input string
+	if _, err := fmt.Fscan(o.In, &input); err != nil {
+		return false
+	}
+	return strings.EqualFold(input, "y")
+}
+
+// resourceKind returns the kind of the resource, qualified by its group.
+func resourceKind(r remainingResource) string {
+	kind := strings.ToLower(r.kind)
+	if len(r.gvr.Group) > 0 {
+		kind += "." + r.gvr.Group
+	}
+	return kind
+}
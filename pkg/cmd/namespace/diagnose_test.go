@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	cgtesting "k8s.io/client-go/testing"
+)
+
+type fakeDiscovery struct {
+	*fakediscovery.FakeDiscovery
+	lists []*metav1.APIResourceList
+	err   error
+}
+
+func (d *fakeDiscovery) ServerPreferredNamespacedResources() ([]*metav1.APIResourceList, error) {
+	return d.lists, d.err
+}
+
+func newWidget(name string, finalizers ...string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("example.com/v1")
+	obj.SetKind("Widget")
+	obj.SetNamespace("old-team")
+	obj.SetName(name)
+	obj.SetFinalizers(finalizers)
+	return obj
+}
+
+func TestNamespaceDiagnose(t *testing.T) {
+	deleted := metav1.NewTime(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name            string
+		objects         []runtime.Object
+		clearFinalizers bool
+		input           string
+		expectedOutput  string
+		expectedCleared []string
+		expectFinalized bool
+	}{
+		{
+			name:    "blocked by finalizers",
+			objects: []runtime.Object{newWidget("gear", "example.com/cleanup"), newWidget("bolt")},
+			expectedOutput: `Namespace: old-team
+Status: Terminating for 2h0m0s
+Finalizers: kubernetes
+
+NamespaceDeletionDiscoveryFailure: Discovery failed for some groups, 1 failing: metrics.k8s.io/v1beta1
+
+Unavailable APIs, blocking the deletion until available again or their API service is deleted:
+metrics.k8s.io/v1beta1 the server is currently unable to handle the request
+
+Remaining resources:
+GROUP KIND COUNT
+example.com Widget 2
+
+Blocking finalizers:
+KIND NAME FINALIZERS
+widget.example.com gear example.com/cleanup
+`,
+		},
+		{
+			name:            "clear the finalizers of the objects",
+			objects:         []runtime.Object{newWidget("gear", "example.com/cleanup"), newWidget("bolt")},
+			clearFinalizers: true,
+			input:           "y\n",
+			expectedCleared: []string{"gear"},
+		},
+		{
+			name:            "clearing the finalizers not confirmed",
+			objects:         []runtime.Object{newWidget("gear", "example.com/cleanup")},
+			clearFinalizers: true,
+			input:           "n\n",
+		},
+		{
+			name:            "clear the finalizers of the namespace",
+			clearFinalizers: true,
+			input:           "y\n",
+			expectFinalized: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			namespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "old-team", DeletionTimestamp: &deleted},
+				Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes}},
+				Status: corev1.NamespaceStatus{
+					Phase: corev1.NamespaceTerminating,
+					Conditions: []corev1.NamespaceCondition{
+						{Type: corev1.NamespaceDeletionDiscoveryFailure, Status: corev1.ConditionTrue, Message: "Discovery failed for some groups, 1 failing: metrics.k8s.io/v1beta1"},
+						{Type: corev1.NamespaceDeletionContentFailure, Status: corev1.ConditionFalse, Message: "All content successfully deleted"},
+					},
+				},
+			}
+			client := fake.NewSimpleClientset(namespace)
+			finalized := false
+			client.PrependReactor("create", "namespaces", func(action cgtesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "finalize" {
+					return false, nil, nil
+				}
+				finalized = len(action.(cgtesting.CreateAction).GetObject().(*corev1.Namespace).Spec.Finalizers) == 0
+				return true, namespace, nil
+			})
+
+			widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+			dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{widgets: "WidgetList"}, tc.objects...)
+			cleared := []string{}
+			dynamicClient.PrependReactor("patch", "widgets", func(action cgtesting.Action) (bool, runtime.Object, error) {
+				cleared = append(cleared, action.(cgtesting.PatchAction).GetName())
+				return true, nil, nil
+			})
+			discoveryClient := &fakeDiscovery{
+				FakeDiscovery: &fakediscovery.FakeDiscovery{},
+				lists: []*metav1.APIResourceList{{
+					GroupVersion: "example.com/v1",
+					APIResources: []metav1.APIResource{
+						{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: []string{"list", "delete", "patch"}},
+						{Name: "widgets/status", Kind: "Widget", Namespaced: true, Verbs: []string{"get"}},
+					},
+				}},
+				err: &discovery.ErrGroupDiscoveryFailed{Groups: map[schema.GroupVersion]error{
+					{Group: "metrics.k8s.io", Version: "v1beta1"}: errors.New("the server is currently unable to handle the request"),
+				}},
+			}
+			if len(tc.objects) == 0 {
+				discoveryClient.lists[0].APIResources = nil
+			}
+
+			streams, in, out, _ := genericiooptions.NewTestIOStreams()
+			in.WriteString(tc.input)
+			o := NewDiagnoseOptions(streams)
+			o.Name = "old-team"
+			o.ClearFinalizers = tc.clearFinalizers
+			o.Client = client.CoreV1()
+			o.DynamicClient = dynamicClient
+			o.DiscoveryClient = discoveryClient
+			o.now = func() time.Time { return deleted.Add(2 * time.Hour) }
+
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tc.expectedOutput) > 0 {
+				// compare the output without the padding of the columns
+				got := regexp.MustCompile(`[ \t]+`).ReplaceAllString(out.String(), " ")
+				got = strings.ReplaceAll(got, "\n ", "\n")
+				if diff := cmp.Diff(tc.expectedOutput, got); diff != "" {
+					t.Errorf("unexpected output (-want +got):\n%s", diff)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedCleared, cleared, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("unexpected cleared objects (-want +got):\n%s", diff)
+			}
+			if finalized != tc.expectFinalized {
+				t.Errorf("expected the namespace finalized %v, got %v", tc.expectFinalized, finalized)
+			}
+		})
+	}
+}
+
+func TestNamespaceDiagnoseNotTerminating(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "team"},
+		Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+	})
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+	o := NewDiagnoseOptions(streams)
+	o.Name = "team"
+	o.ClearFinalizers = true
+	o.Client = client.CoreV1()
+	o.DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	o.DiscoveryClient = &fakeDiscovery{FakeDiscovery: &fakediscovery.FakeDiscovery{}}
+
+	err := o.Run()
+	if err == nil || !strings.Contains(err.Error(), `namespace "team" is not terminating`) {
+		t.Errorf("expected a not terminating error, got %v", err)
+	}
+	if !regexp.MustCompile(`Status:\s+Active`).MatchString(out.String()) {
+		t.Errorf("expected the status in the output, got:\n%s", out.String())
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package patch
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
@@ -61,12 +62,16 @@ type PatchOptions struct {
 	ToPrinter   func(string) (printers.ResourcePrinter, error)
 	Recorder    genericclioptions.Recorder
 
-	Local       bool
-	PatchType   string
-	Patch       string
-	PatchFile   string
-	Subresource string
+	Local           bool
+	PatchType       string
+	Patch           string
+	PatchFiles      []string
+	Subresource     string
+	Selector        string
+	ContinueOnError bool
+	Atomic          bool
 
+	transaction                  *cmdutil.Transaction
 	namespace                    string
 	enforceNamespace             bool
 	dryRunStrategy               cmdutil.DryRunStrategy
@@ -104,7 +109,14 @@ var (
 		kubectl patch pod valid-pod --type='json' -p='[{"op": "replace", "path": "/spec/containers/0/image", "value":"new image"}]'
 
 		# Update a deployment's replicas through the 'scale' subresource using a merge patch
-		kubectl patch deployment nginx-deployment --subresource='scale' --type='merge' -p '{"spec":{"replicas":2}}'`))
+		kubectl patch deployment nginx-deployment --subresource='scale' --type='merge' -p '{"spec":{"replicas":2}}'
+
+		# Apply the patches in resources.yaml and then limits.yaml to every deployment labeled app=web,
+		# reporting the deployments that could not be patched at the end
+		kubectl patch deployments -l app=web --patch-file resources.yaml --patch-file limits.yaml --continue-on-error
+
+		# Patch every deployment labeled app=web, or none of them if a patch fails
+		kubectl patch deployments -l app=web --type='merge' -p '{"spec":{"revisionHistoryLimit":5}}' --atomic`))
 )
 
 var supportedSubresources = []string{"status", "scale"}
@@ -122,7 +134,7 @@ func NewCmdPatch(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra
 	o := NewPatchOptions(ioStreams)
 
 	cmd := &cobra.Command{
-		Use:                   "patch (-f FILENAME | TYPE NAME) [-p PATCH|--patch-file FILE]",
+		Use:                   "patch (-f FILENAME | TYPE NAME | TYPE -l SELECTOR) [-p PATCH|--patch-file FILE]...",
 		DisableFlagsInUseLine: true,
 		Short:                 i18n.T("Update fields of a resource"),
 		Long:                  patchLong,
@@ -139,13 +151,16 @@ func NewCmdPatch(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra
 	o.PrintFlags.AddFlags(cmd)
 
 	cmd.Flags().StringVarP(&o.Patch, "patch", "p", "", "The patch to be applied to the resource JSON file.")
-	cmd.Flags().StringVar(&o.PatchFile, "patch-file", "", "A file containing a patch to be applied to the resource.")
+	cmd.Flags().StringArrayVar(&o.PatchFiles, "patch-file", o.PatchFiles, "A file containing a patch to be applied to the resource. Can be repeated to apply several patches in order, they are combined into a single request.")
 	cmd.Flags().StringVar(&o.PatchType, "type", "strategic", fmt.Sprintf("The type of patch being provided; one of %v", sets.StringKeySet(patchTypes).List()))
 	cmdutil.AddDryRunFlag(cmd)
 	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "identifying the resource to update")
 	cmd.Flags().BoolVar(&o.Local, "local", o.Local, "If true, patch will operate on the content of the file, not the server-side resource.")
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.fieldManager, "kubectl-patch")
 	cmdutil.AddSubresourceFlags(cmd, &o.Subresource, "If specified, patch will operate on the subresource of the requested object.", supportedSubresources...)
+	cmdutil.AddLabelSelectorFlagVar(cmd, &o.Selector)
+	cmd.Flags().BoolVar(&o.ContinueOnError, "continue-on-error", o.ContinueOnError, "If true, keep patching the remaining objects when one of them fails, and report the failures at the end.")
+	cmdutil.AddAtomicFlagVar(cmd, &o.Atomic)
 
 	return cmd
 }
@@ -183,15 +198,18 @@ func (o *PatchOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []st
 }
 
 func (o *PatchOptions) Validate() error {
-	if len(o.Patch) > 0 && len(o.PatchFile) > 0 {
+	if len(o.Patch) > 0 && len(o.PatchFiles) > 0 {
 		return fmt.Errorf("cannot specify --patch and --patch-file together")
 	}
-	if len(o.Patch) == 0 && len(o.PatchFile) == 0 {
+	if len(o.Patch) == 0 && len(o.PatchFiles) == 0 {
 		return fmt.Errorf("must specify --patch or --patch-file containing the contents of the patch")
 	}
 	if o.Local && len(o.args) != 0 {
 		return fmt.Errorf("cannot specify --local and server resources")
 	}
+	if o.Local && len(o.Selector) > 0 {
+		return fmt.Errorf("cannot specify --local and --selector")
+	}
 	if o.Local && o.dryRunStrategy == cmdutil.DryRunServer {
 		return fmt.Errorf("cannot specify --local and --dry-run=server - did you mean --dry-run=client?")
 	}
@@ -203,6 +221,12 @@ func (o *PatchOptions) Validate() error {
 	if len(o.Subresource) > 0 && !slice.ContainsString(supportedSubresources, o.Subresource, nil) {
 		return fmt.Errorf("invalid subresource value: %q. Must be one of %v", o.Subresource, supportedSubresources)
 	}
+	if o.Atomic && o.ContinueOnError {
+		return fmt.Errorf("cannot specify --atomic and --continue-on-error")
+	}
+	if o.Atomic && (o.Local || o.dryRunStrategy != cmdutil.DryRunNone || len(o.Subresource) > 0) {
+		return fmt.Errorf("--atomic cannot be used with --local, --dry-run or --subresource")
+	}
 	return nil
 }
 
@@ -212,114 +236,225 @@ func (o *PatchOptions) RunPatch() error {
 		patchType = patchTypes[strings.ToLower(o.PatchType)]
 	}
 
-	var patchBytes []byte
-	if len(o.PatchFile) > 0 {
-		var err error
-		patchBytes, err = os.ReadFile(o.PatchFile)
-		if err != nil {
-			return fmt.Errorf("unable to read patch file: %v", err)
-		}
-	} else {
-		patchBytes = []byte(o.Patch)
-	}
-
-	patchBytes, err := yaml.ToJSON(patchBytes)
+	patches, err := o.readPatches()
 	if err != nil {
-		return fmt.Errorf("unable to parse %q: %v", o.Patch, err)
+		return err
 	}
 
-	r := o.builder.
+	b := o.builder.
 		Unstructured().
 		ContinueOnError().
 		LocalParam(o.Local).
 		NamespaceParam(o.namespace).DefaultNamespace().
 		FilenameParam(o.enforceNamespace, &o.FilenameOptions).
+		LabelSelectorParam(o.Selector).
 		Subresource(o.Subresource).
 		ResourceTypeOrNameArgs(false, o.args...).
-		Flatten().
-		Do()
+		Flatten()
+	if o.Atomic {
+		// the objects are rolled back to their state on the server
+		b = b.Latest()
+		o.transaction = &cmdutil.Transaction{}
+	}
+	r := b.Do()
 	err = r.Err()
 	if err != nil {
 		return err
 	}
 
 	count := 0
-	err = r.Visit(func(info *resource.Info, err error) error {
+	failed := 0
+	change := func(info *resource.Info, err error) error {
 		if err != nil {
 			return err
 		}
 		count++
-		name, namespace := info.Name, info.Namespace
 
-		if !o.Local && o.dryRunStrategy != cmdutil.DryRunClient {
-			mapping := info.ResourceMapping()
-			client, err := o.unstructuredClientForMapping(mapping)
-			if err != nil {
+		patchedObj, didPatch, err := o.patchObject(info, patchType, patches)
+		if err != nil {
+			if !o.ContinueOnError {
 				return err
 			}
+			failed++
+			fmt.Fprintf(o.ErrOut, "error: %s/%s: %v\n", strings.ToLower(info.Mapping.GroupVersionKind.GroupKind().String()), info.Name, err)
+			return nil
+		}
 
-			helper := resource.
-				NewHelper(client, mapping).
-				DryRun(o.dryRunStrategy == cmdutil.DryRunServer).
-				WithFieldManager(o.fieldManager).
-				WithSubresource(o.Subresource)
-			patchedObj, err := helper.Patch(namespace, name, patchType, patchBytes, nil)
-			if err != nil {
-				if apierrors.IsUnsupportedMediaType(err) {
-					return errors.Wrap(err, fmt.Sprintf("%s is not supported by %s", patchType, mapping.GroupVersionKind))
-				}
-				return err
-			}
+		printer, err := o.ToPrinter(patchOperation(didPatch))
+		if err != nil {
+			return err
+		}
+		return printer.PrintObj(patchedObj, o.Out)
+	}
+	if o.transaction != nil {
+		err = o.transaction.Run(r, func(info *resource.Info) error {
+			return o.checkPatches(info, patchType, patches)
+		}, change, o.ErrOut)
+	} else {
+		err = r.Visit(change)
+	}
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("no objects passed to patch")
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to patch %d of %d objects", failed, count)
+	}
+	return nil
+}
 
-			didPatch := !reflect.DeepEqual(info.Object, patchedObj)
-
-			// if the recorder makes a change, compute and create another patch
-			if mergePatch, err := o.Recorder.MakeRecordMergePatch(patchedObj); err != nil {
-				klog.V(4).Infof("error recording current command: %v", err)
-			} else if len(mergePatch) > 0 {
-				if recordedObj, err := helper.Patch(namespace, name, types.MergePatchType, mergePatch, nil); err != nil {
-					klog.V(4).Infof("error recording reason: %v", err)
-				} else {
-					patchedObj = recordedObj
-				}
-			}
+// readPatches returns the patch given with --patch, or the patches of the
+// --patch-file files in order, as JSON.
+func (o *PatchOptions) readPatches() ([][]byte, error) {
+	if len(o.PatchFiles) == 0 {
+		patch, err := yaml.ToJSON([]byte(o.Patch))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %q: %v", o.Patch, err)
+		}
+		return [][]byte{patch}, nil
+	}
 
-			printer, err := o.ToPrinter(patchOperation(didPatch))
-			if err != nil {
-				return err
-			}
-			return printer.PrintObj(patchedObj, o.Out)
+	patches := [][]byte{}
+	for _, file := range o.PatchFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read patch file: %v", err)
 		}
+		patch, err := yaml.ToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %q: %v", file, err)
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// patchObject applies the patches in order to the object, on the server or
+// locally, and returns the patched object and whether it changed.
+func (o *PatchOptions) patchObject(info *resource.Info, patchType types.PatchType, patches [][]byte) (runtime.Object, bool, error) {
+	name, namespace := info.Name, info.Namespace
 
-		originalObjJS, err := runtime.Encode(unstructured.UnstructuredJSONScheme, info.Object)
+	if !o.Local && o.dryRunStrategy != cmdutil.DryRunClient {
+		mapping := info.ResourceMapping()
+		client, err := o.unstructuredClientForMapping(mapping)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 
-		originalPatchedObjJS, err := getPatchedJSON(patchType, originalObjJS, patchBytes, info.Object.GetObjectKind().GroupVersionKind(), scheme.Scheme)
+		helper := resource.
+			NewHelper(client, mapping).
+			DryRun(o.dryRunStrategy == cmdutil.DryRunServer).
+			WithFieldManager(o.fieldManager).
+			WithSubresource(o.Subresource)
+		patchType, patchBytes, err := composePatches(helper, info, patchType, patches)
 		if err != nil {
-			return err
+			return nil, false, err
+		}
+		patchedObj, err := helper.Patch(namespace, name, patchType, patchBytes, nil)
+		if err != nil {
+			if apierrors.IsUnsupportedMediaType(err) {
+				return nil, false, errors.Wrap(err, fmt.Sprintf("%s is not supported by %s", patchType, mapping.GroupVersionKind))
+			}
+			return nil, false, err
+		}
+
+		didPatch := !reflect.DeepEqual(info.Object, patchedObj)
+
+		// if the recorder makes a change, compute and create another patch
+		if mergePatch, err := o.Recorder.MakeRecordMergePatch(patchedObj); err != nil {
+			klog.V(4).Infof("error recording current command: %v", err)
+		} else if len(mergePatch) > 0 {
+			if recordedObj, err := helper.Patch(namespace, name, types.MergePatchType, mergePatch, nil); err != nil {
+				klog.V(4).Infof("error recording reason: %v", err)
+			} else {
+				patchedObj = recordedObj
+			}
+		}
+		if o.transaction != nil {
+			original, err := json.Marshal(info.Object)
+			if err != nil {
+				return nil, false, err
+			}
+			changed, err := json.Marshal(patchedObj)
+			if err != nil {
+				return nil, false, err
+			}
+			if err := o.transaction.Record(helper, info, original, changed); err != nil {
+				return nil, false, err
+			}
 		}
+		return patchedObj, didPatch, nil
+	}
 
-		targetObj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, originalPatchedObjJS)
+	patchedObjJS, err := runtime.Encode(unstructured.UnstructuredJSONScheme, info.Object)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, patchBytes := range patches {
+		patchedObjJS, err = getPatchedJSON(patchType, patchedObjJS, patchBytes, info.Object.GetObjectKind().GroupVersionKind(), scheme.Scheme)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
+	}
 
-		didPatch := !reflect.DeepEqual(info.Object, targetObj)
-		printer, err := o.ToPrinter(patchOperation(didPatch))
+	targetObj, err := runtime.Decode(unstructured.UnstructuredJSONScheme, patchedObjJS)
+	if err != nil {
+		return nil, false, err
+	}
+	return targetObj, !reflect.DeepEqual(info.Object, targetObj), nil
+}
+
+// composePatches returns the patch to send for the patches. Several patches
+// are applied in order to the object fetched from the server, and sent as a
+// single merge patch from the fetched object to the result, so that they are
+// all applied or none is, and a server dry run shows their combined result.
+// The patch holds the resource version of the fetched object, so it fails if
+// the object changed in the meantime.
+func composePatches(helper *resource.Helper, info *resource.Info, patchType types.PatchType, patches [][]byte) (types.PatchType, []byte, error) {
+	if len(patches) == 1 {
+		return patchType, patches[0], nil
+	}
+	current, err := helper.Get(info.Namespace, info.Name)
+	if err != nil {
+		return "", nil, err
+	}
+	currentJS, err := runtime.Encode(unstructured.UnstructuredJSONScheme, current)
+	if err != nil {
+		return "", nil, err
+	}
+	patchedJS := currentJS
+	for _, patchBytes := range patches {
+		patchedJS, err = getPatchedJSON(patchType, patchedJS, patchBytes, info.Mapping.GroupVersionKind, scheme.Scheme)
 		if err != nil {
-			return err
+			return "", nil, err
 		}
-		return printer.PrintObj(targetObj, o.Out)
-	})
+	}
+	patch, err := jsonpatch.CreateMergePatch(currentJS, patchedJS)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
-	if count == 0 {
-		return fmt.Errorf("no objects passed to patch")
+	accessor, err := meta.Accessor(current)
+	if err != nil {
+		return "", nil, err
 	}
-	return nil
+	if len(accessor.GetResourceVersion()) > 0 {
+		patchMap := map[string]interface{}{}
+		if err := json.Unmarshal(patch, &patchMap); err != nil {
+			return "", nil, err
+		}
+		metadata, _ := patchMap["metadata"].(map[string]interface{})
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+			patchMap["metadata"] = metadata
+		}
+		metadata["resourceVersion"] = accessor.GetResourceVersion()
+		if patch, err = json.Marshal(patchMap); err != nil {
+			return "", nil, err
+		}
+	}
+	return types.MergePatchType, patch, nil
 }
 
 func getPatchedJSON(patchType types.PatchType, originalJS, patchJS []byte, gvk schema.GroupVersionKind, creater runtime.ObjectCreater) ([]byte, error) {
@@ -362,3 +497,22 @@ func patchOperation(didPatch bool) string {
 	}
 	return "patched (no change)"
 }
+
+// checkPatches applies the patches to the object with a server dry run.
+func (o *PatchOptions) checkPatches(info *resource.Info, patchType types.PatchType, patches [][]byte) error {
+	mapping := info.ResourceMapping()
+	client, err := o.unstructuredClientForMapping(mapping)
+	if err != nil {
+		return err
+	}
+	helper := resource.
+		NewHelper(client, mapping).
+		DryRun(true).
+		WithFieldManager(o.fieldManager)
+	patchType, patchBytes, err := composePatches(helper, info, patchType, patches)
+	if err != nil {
+		return err
+	}
+	_, err = helper.Patch(info.Namespace, info.Name, patchType, patchBytes, nil)
+	return err
+}
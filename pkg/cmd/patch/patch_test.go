@@ -17,12 +17,17 @@ limitations under the License.
 package patch
 
 import (
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	jsonpath "github.com/exponent-io/jsonpath"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/rest/fake"
@@ -240,3 +245,251 @@ func TestPatchSubresource(t *testing.T) {
 		t.Errorf("unexpected pod status to be set to %s got: %s", expectedStatus, actualStatus)
 	}
 }
+
+func TestPatchMultipleObjects(t *testing.T) {
+	_, svc, _ := cmdtesting.TestData()
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+	list := svc.DeepCopy()
+	list.Items = append(list.Items, *svc.Items[0].DeepCopy())
+	list.Items[0].Name = "frontend"
+	list.Items[1].Name = "backend"
+
+	patches := map[string][]string{}
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/namespaces/test/services" && m == "GET":
+				if req.URL.Query().Get("labelSelector") != "app=web" {
+					t.Errorf("unexpected label selector: %s", req.URL.RawQuery)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, list)}, nil
+			case p == "/namespaces/test/services/frontend" && m == "GET":
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &list.Items[0])}, nil
+			case p == "/namespaces/test/services/backend" && m == "GET":
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &list.Items[1])}, nil
+			case p == "/namespaces/test/services/frontend" && m == "PATCH":
+				body, _ := io.ReadAll(req.Body)
+				patches["frontend"] = append(patches["frontend"], string(body))
+				obj := list.Items[0].DeepCopy()
+				obj.Spec.Type = corev1.ServiceTypeNodePort
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+			case p == "/namespaces/test/services/backend" && m == "PATCH":
+				return &http.Response{StatusCode: http.StatusForbidden, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.StringBody(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"forbidden","reason":"Forbidden","code":403}`)}, nil
+			default:
+				t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+				return nil, nil
+			}
+		}),
+	}
+
+	dir := t.TempDir()
+	typeFile := filepath.Join(dir, "type.yaml")
+	if err := os.WriteFile(typeFile, []byte("spec:\n  type: NodePort\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	labelFile := filepath.Join(dir, "label.yaml")
+	if err := os.WriteFile(labelFile, []byte(`{"metadata":{"labels":{"patched":"true"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, _, buf, errBuf := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdPatch(tf, stream)
+	o := NewPatchOptions(stream)
+	o.PatchFiles = []string{typeFile, labelFile}
+	o.Selector = "app=web"
+	o.ContinueOnError = true
+	if err := o.Complete(tf, cmd, []string{"services"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	err := o.RunPatch()
+	if err == nil || err.Error() != "failed to patch 1 of 2 objects" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if buf.String() != "service/frontend patched\n" {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+	if !strings.Contains(errBuf.String(), "error: service/backend: forbidden") {
+		t.Errorf("expected the failure of backend to be reported, got: %s", errBuf.String())
+	}
+	expected := []string{`{"metadata":{"labels":{"patched":"true"},"resourceVersion":"12"},"spec":{"type":"NodePort"}}`}
+	if !reflect.DeepEqual(patches["frontend"], expected) {
+		t.Errorf("expected patches %v, got %v", expected, patches["frontend"])
+	}
+}
+
+func TestPatchFilesServerDryRun(t *testing.T) {
+	_, svc, _ := cmdtesting.TestData()
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+	patches := []string{}
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/namespaces/test/services/baz" && m == "GET":
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &svc.Items[0])}, nil
+			case p == "/namespaces/test/services/baz" && m == "PATCH":
+				if req.URL.Query().Get("dryRun") != "All" {
+					t.Errorf("expected a server dry run, got %s", req.URL.RawQuery)
+				}
+				if req.Header.Get("Content-Type") != string(types.MergePatchType) {
+					t.Errorf("expected a merge patch, got %s", req.Header.Get("Content-Type"))
+				}
+				body, _ := io.ReadAll(req.Body)
+				patches = append(patches, string(body))
+				// the live object is not changed by the dry run
+				obj := svc.Items[0].DeepCopy()
+				obj.Spec.Type = corev1.ServiceTypeNodePort
+				obj.Labels = map[string]string{"patched": "true"}
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+			default:
+				t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+				return nil, nil
+			}
+		}),
+	}
+
+	dir := t.TempDir()
+	typeFile := filepath.Join(dir, "type.yaml")
+	if err := os.WriteFile(typeFile, []byte("spec:\n  type: NodePort\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	labelFile := filepath.Join(dir, "label.yaml")
+	if err := os.WriteFile(labelFile, []byte(`{"metadata":{"labels":{"patched":"true"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdPatch(tf, stream)
+	cmd.Flags().Set("dry-run", "server")
+	o := NewPatchOptions(stream)
+	o.PatchFiles = []string{typeFile, labelFile}
+	if err := o.Complete(tf, cmd, []string{"services/baz"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunPatch(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{`{"metadata":{"labels":{"patched":"true"},"resourceVersion":"12"},"spec":{"type":"NodePort"}}`}
+	if !reflect.DeepEqual(patches, expected) {
+		t.Errorf("expected the patches to be sent in a single request %v, got %v", expected, patches)
+	}
+	if !strings.HasPrefix(buf.String(), "service/baz patched") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
+
+func TestPatchAtomic(t *testing.T) {
+	tests := []struct {
+		name            string
+		failBackend     string
+		expectedPatches []string
+		expectedErr     string
+	}{
+		{
+			name:            "check failed",
+			failBackend:     "All",
+			expectedPatches: []string{"dry-run frontend", "dry-run backend"},
+			expectedErr:     "no object was changed, services/backend: forbidden",
+		},
+		{
+			name:            "rolled back",
+			failBackend:     "",
+			expectedPatches: []string{"dry-run frontend", "dry-run backend", `frontend {"spec":{"type":"NodePort"}}`, "backend", `frontend {"spec":{"type":"ClusterIP"}}`},
+			expectedErr:     "services/backend: forbidden",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, svc, _ := cmdtesting.TestData()
+
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+			list := svc.DeepCopy()
+			list.Items = append(list.Items, *svc.Items[0].DeepCopy())
+			list.Items[0].Name = "frontend"
+			list.Items[1].Name = "backend"
+
+			patches := []string{}
+			tf.UnstructuredClient = &fake.RESTClient{
+				NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					dryRun := req.URL.Query().Get("dryRun")
+					switch p, m := req.URL.Path, req.Method; {
+					case p == "/namespaces/test/services" && m == "GET":
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, list)}, nil
+					case p == "/namespaces/test/services/frontend" && m == "GET":
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &list.Items[0])}, nil
+					case p == "/namespaces/test/services/backend" && m == "GET":
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &list.Items[1])}, nil
+					case p == "/namespaces/test/services/frontend" && m == "PATCH":
+						obj := list.Items[0].DeepCopy()
+						if len(dryRun) > 0 {
+							patches = append(patches, "dry-run frontend")
+						} else {
+							body, _ := io.ReadAll(req.Body)
+							patches = append(patches, "frontend "+string(body))
+							obj.Spec.Type = corev1.ServiceTypeNodePort
+						}
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, obj)}, nil
+					case p == "/namespaces/test/services/backend" && m == "PATCH":
+						if len(dryRun) > 0 {
+							patches = append(patches, "dry-run backend")
+						} else {
+							patches = append(patches, "backend")
+						}
+						if dryRun == tc.failBackend {
+							return &http.Response{StatusCode: http.StatusForbidden, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.StringBody(`{"kind":"Status","apiVersion":"v1","status":"Failure","message":"forbidden","reason":"Forbidden","code":403}`)}, nil
+						}
+						return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &list.Items[1])}, nil
+					default:
+						t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+						return nil, nil
+					}
+				}),
+			}
+
+			stream, _, _, _ := genericiooptions.NewTestIOStreams()
+			cmd := NewCmdPatch(tf, stream)
+			o := NewPatchOptions(stream)
+			o.Patch = `{"spec":{"type":"NodePort"}}`
+			o.PatchType = "merge"
+			o.Selector = "app=web"
+			o.Atomic = true
+			if err := o.Complete(tf, cmd, []string{"services"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			err := o.RunPatch()
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+			}
+			if !reflect.DeepEqual(tc.expectedPatches, patches) {
+				t.Errorf("expected patches %v, got %v", tc.expectedPatches, patches)
+			}
+		})
+	}
+}
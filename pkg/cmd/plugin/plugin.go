@@ -40,8 +40,13 @@ var (
 		Plugins provide extended functionality that is not part of the major command-line distribution.
 		Please refer to the documentation and examples for more information about how write your own plugins.
 
-		The easiest way to discover and install plugins is via the kubernetes sub-project krew.
-		To install krew, visit [krew.sigs.k8s.io](https://krew.sigs.k8s.io/docs/user-guide/setup/install/)`))
+		A plugin kubectl-NAME can describe its command in a kubectl_manifest-NAME.yaml file next to its
+		executable: the short description is shown in the command listings of kubectl, and the plugin can
+		declare that it completes its arguments with "__complete" or list its flags for completion.
+
+		Plugins can be discovered and installed from the index of the kubernetes sub-project krew
+		with the search, install and upgrade subcommands, without installing krew itself.
+		To learn more about krew, visit [krew.sigs.k8s.io](https://krew.sigs.k8s.io/docs/user-guide/setup/install/)`))
 
 	pluginExample = templates.Examples(i18n.T(`
 		# List all available plugins
@@ -71,6 +76,9 @@ func NewCmdPlugin(streams genericiooptions.IOStreams) *cobra.Command {
 	}
 
 	cmd.AddCommand(NewCmdPluginList(streams))
+	cmd.AddCommand(NewCmdPluginSearch(streams))
+	cmd.AddCommand(NewCmdPluginInstall(streams))
+	cmd.AddCommand(NewCmdPluginUpgrade(streams))
 	return cmd
 }
 
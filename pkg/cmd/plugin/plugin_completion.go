@@ -23,6 +23,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 
@@ -30,8 +31,77 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
 )
 
+// pluginCommandManifest describes the command of a plugin. It is read from
+// the kubectl_manifest-<plugin>.yaml file next to the plugin executable, for
+// instance kubectl_manifest-get_all.yaml for the plugin kubectl-get_all.
+type pluginCommandManifest struct {
+	// Short is the description of the plugin shown in the command listings
+	// and with the completion choices.
+	Short string `json:"short,omitempty"`
+	// Complete indicates that the plugin completes its own arguments when
+	// called as "PLUGIN __complete ARGS...", as programs built with Cobra do.
+	Complete bool `json:"complete,omitempty"`
+	// Flags are the flags of the plugin, completed when the plugin does not
+	// complete its own arguments.
+	Flags []pluginCommandFlag `json:"flags,omitempty"`
+}
+
+type pluginCommandFlag struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Usage     string `json:"usage,omitempty"`
+}
+
+// pluginCommandManifestPath returns the path of the manifest of the plugin
+// executable.
+func pluginCommandManifestPath(pluginPath string) string {
+	dir, name := filepath.Split(pluginPath)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return filepath.Join(dir, strings.Replace(name, "-", "_manifest-", 1)+".yaml")
+}
+
+// readPluginCommandManifest returns the manifest of the plugin executable,
+// or nil when the plugin has no valid manifest.
+func readPluginCommandManifest(pluginPath string) *pluginCommandManifest {
+	data, err := os.ReadFile(pluginCommandManifestPath(pluginPath))
+	if err != nil {
+		return nil
+	}
+	manifest := &pluginCommandManifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		cobra.CompDebugln(fmt.Sprintf("Ignoring invalid plugin manifest %s: %v", pluginCommandManifestPath(pluginPath), err), true)
+		return nil
+	}
+	return manifest
+}
+
+// flagCompletions returns the flags of the manifest starting with
+// toComplete, with their usage as description.
+func (m *pluginCommandManifest) flagCompletions(toComplete string) []string {
+	comps := []string{}
+	for _, flag := range m.Flags {
+		names := []string{"--" + flag.Name}
+		if len(flag.Shorthand) > 0 {
+			names = append(names, "-"+flag.Shorthand)
+		}
+		for _, name := range names {
+			if strings.HasPrefix(name, toComplete) {
+				comp := name
+				if len(flag.Usage) > 0 {
+					comp += "\t" + flag.Usage
+				}
+				comps = append(comps, comp)
+			}
+		}
+	}
+	return comps
+}
+
 func GetPluginCommandGroup(kubectl *cobra.Command) templates.CommandGroup {
 	// Find root level
 	return templates.CommandGroup{
@@ -103,8 +173,8 @@ func registerPluginCommands(kubectl *cobra.Command, list bool) (cmds []*cobra.Co
 	o.Complete(kubectl)
 	plugins, _ := o.ListPlugins()
 
-	for _, plugin := range plugins {
-		plugin = filepath.Base(plugin)
+	for _, pluginPath := range plugins {
+		plugin := filepath.Base(pluginPath)
 		args := []string{}
 
 		// Plugins are named "kubectl-<name>" or with more - such as
@@ -130,13 +200,21 @@ func registerPluginCommands(kubectl *cobra.Command, list bool) (cmds []*cobra.Co
 			parentCmd = kubectl
 		}
 
-		for _, remainingArg := range remainingArgs {
+		for i, remainingArg := range remainingArgs {
+			short := fmt.Sprintf(i18n.T("The command %s is a plugin installed by the user"), remainingArg)
+			// the manifest describes the command of the plugin executable, the
+			// last one of its name
+			if len(args) == len(rawPluginArgs) && i == len(remainingArgs)-1 {
+				if manifest := readPluginCommandManifest(pluginPath); manifest != nil && len(manifest.Short) > 0 {
+					short = manifest.Short
+				}
+			}
 			cmd := &cobra.Command{
 				Use: remainingArg,
 				// Add a description that will be shown with completion choices.
 				// Make each one different by including the plugin name to avoid
 				// all plugins being grouped in a single line during completion for zsh.
-				Short:              fmt.Sprintf(i18n.T("The command %s is a plugin installed by the user"), remainingArg),
+				Short:              short,
 				DisableFlagParsing: true,
 				// Allow plugins to provide their own completion choices
 				ValidArgsFunction: pluginCompletion,
@@ -185,19 +263,39 @@ func registerPluginCommands(kubectl *cobra.Command, list bool) (cmds []*cobra.Co
 // The completion executable should be named kubectl_complete-<plugin>.  For example, for a plugin
 // named kubectl-get_all, the completion file should be named kubectl_complete-get_all.  The completion
 // executable must have executable permissions set on it and must be on $PATH.
+//
+// Without completion executable, the manifest of the plugin is used: a plugin declaring that it completes
+// its own arguments is called as "kubectl-get_all __complete arg1 arg2 a", following the protocol of Cobra,
+// and the flags listed in the manifest are completed otherwise.
 func pluginCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	// Recreate the plugin name from the commandPath
 	pluginName := strings.ReplaceAll(strings.ReplaceAll(cmd.CommandPath(), "-", "_"), " ", "-")
 
+	args = append(args, toComplete)
 	path, found := lookupCompletionExec(pluginName)
-	if !found {
-		cobra.CompDebugln(fmt.Sprintf("Plugin %s does not provide a matching completion executable", pluginName), true)
-		return nil, cobra.ShellCompDirectiveDefault
+	if found {
+		cobra.CompDebugln(fmt.Sprintf("About to call: %s %s", path, strings.Join(args, " ")), true)
+		return getPluginCompletions(path, args, os.Environ())
 	}
+	cobra.CompDebugln(fmt.Sprintf("Plugin %s does not provide a matching completion executable", pluginName), true)
 
-	args = append(args, toComplete)
-	cobra.CompDebugln(fmt.Sprintf("About to call: %s %s", path, strings.Join(args, " ")), true)
-	return getPluginCompletions(path, args, os.Environ())
+	pluginPath, err := exec.LookPath(pluginName)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	manifest := readPluginCommandManifest(pluginPath)
+	switch {
+	case manifest == nil:
+		return nil, cobra.ShellCompDirectiveDefault
+	case manifest.Complete:
+		args = append([]string{cobra.ShellCompRequestCmd}, args...)
+		cobra.CompDebugln(fmt.Sprintf("About to call: %s %s", pluginPath, strings.Join(args, " ")), true)
+		return getPluginCompletions(pluginPath, args, os.Environ())
+	case strings.HasPrefix(toComplete, "-"):
+		return manifest.flagCompletions(toComplete), cobra.ShellCompDirectiveNoFileComp
+	default:
+		return nil, cobra.ShellCompDirectiveDefault
+	}
 }
 
 // lookupCompletionExec will look for the existence of an executable
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func writeTestPluginFile(t *testing.T, dir, name, content string, mode os.FileMode) {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPluginCommandManifest(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	writeTestPluginFile(t, dir, "kubectl-described", "#!/bin/sh\n", 0755)
+	writeTestPluginFile(t, dir, "kubectl_manifest-described.yaml", "short: Describe everything\n", 0644)
+	writeTestPluginFile(t, dir, "kubectl-plain", "#!/bin/sh\n", 0755)
+	writeTestPluginFile(t, dir, "kubectl-invalid", "#!/bin/sh\n", 0755)
+	writeTestPluginFile(t, dir, "kubectl_manifest-invalid.yaml", "short: [\n", 0644)
+
+	group := GetPluginCommandGroup(&cobra.Command{Use: "kubectl"})
+	shorts := map[string]string{}
+	for _, cmd := range group.Commands {
+		shorts[cmd.Name()] = cmd.Short
+	}
+	expected := map[string]string{
+		"described": "Describe everything",
+		"plain":     "The command plain is a plugin installed by the user",
+		"invalid":   "The command invalid is a plugin installed by the user",
+	}
+	if !reflect.DeepEqual(expected, shorts) {
+		t.Errorf("expected %v, got %v", expected, shorts)
+	}
+}
+
+func TestPluginCompletionFromManifest(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	writeTestPluginFile(t, dir, "kubectl-selfcomplete", "#!/bin/sh\necho \"$@\"\necho :4\n", 0755)
+	writeTestPluginFile(t, dir, "kubectl_manifest-selfcomplete.yaml", "complete: true\n", 0644)
+	writeTestPluginFile(t, dir, "kubectl-flags", "#!/bin/sh\nexit 1\n", 0755)
+	writeTestPluginFile(t, dir, "kubectl_manifest-flags.yaml", `flags:
+- name: all
+  shorthand: A
+  usage: Include everything
+- name: output
+`, 0644)
+
+	tests := []struct {
+		name              string
+		plugin            string
+		args              []string
+		toComplete        string
+		expectedComps     []string
+		expectedDirective cobra.ShellCompDirective
+	}{
+		{
+			name:              "plugin completing itself",
+			plugin:            "selfcomplete",
+			args:              []string{"arg1"},
+			toComplete:        "a",
+			expectedComps:     []string{"__complete arg1 a"},
+			expectedDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:              "flags of the manifest",
+			plugin:            "flags",
+			toComplete:        "-",
+			expectedComps:     []string{"--all\tInclude everything", "-A\tInclude everything", "--output"},
+			expectedDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:              "flags of the manifest with a prefix",
+			plugin:            "flags",
+			toComplete:        "--o",
+			expectedComps:     []string{"--output"},
+			expectedDirective: cobra.ShellCompDirectiveNoFileComp,
+		},
+		{
+			name:              "arguments without completion",
+			plugin:            "flags",
+			toComplete:        "a",
+			expectedDirective: cobra.ShellCompDirectiveDefault,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := &cobra.Command{Use: "kubectl"}
+			cmd := &cobra.Command{Use: tc.plugin}
+			root.AddCommand(cmd)
+
+			comps, directive := pluginCompletion(cmd, tc.args, tc.toComplete)
+			if !reflect.DeepEqual(tc.expectedComps, comps) {
+				t.Errorf("expected completions %q, got %q", tc.expectedComps, comps)
+			}
+			if directive != tc.expectedDirective {
+				t.Errorf("expected directive %d, got %d", tc.expectedDirective, directive)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	pluginInstallLong = templates.LongDesc(i18n.T(`
+		Install plugins from a krew-compatible plugin index.
+
+		The archive of the plugin for the current platform is downloaded, its sha256
+		checksum verified, and it is extracted in the store directory of --root. The
+		executable of the plugin is linked in the bin directory of --root, which must be
+		on the PATH for kubectl to find the plugin.
+
+		The index is a git repository, cloned in --root and updated before use, or a
+		local directory.`))
+
+	pluginInstallExample = templates.Examples(i18n.T(`
+		# Install the plugin view-secret from the krew index
+		kubectl plugin install view-secret
+
+		# Install the plugin view-secret from a local copy of an index
+		kubectl plugin install view-secret --index=./my-index`))
+
+	pluginUpgradeLong = templates.LongDesc(i18n.T(`
+		Upgrade installed plugins to the version of the plugin index.
+
+		Without names, all the installed plugins are upgraded.`))
+
+	pluginUpgradeExample = templates.Examples(i18n.T(`
+		# Upgrade all the installed plugins
+		kubectl plugin upgrade
+
+		# Upgrade the plugin view-secret
+		kubectl plugin upgrade view-secret`))
+
+	pluginSearchLong = templates.LongDesc(i18n.T(`
+		Search the plugin index for plugins.
+
+		The keyword is looked up in the names and the descriptions of the plugins. Without
+		keyword, all the plugins of the index are listed.`))
+
+	pluginSearchExample = templates.Examples(i18n.T(`
+		# List all the plugins of the index
+		kubectl plugin search
+
+		# Search for plugins about secrets
+		kubectl plugin search secret`))
+)
+
+// PluginInstallOptions holds the options for 'plugin install' sub command
+type PluginInstallOptions struct {
+	*PluginManagerOptions
+
+	Names []string
+}
+
+// NewCmdPluginInstall returns a Command instance for 'plugin install' sub command
+func NewCmdPluginInstall(streams genericiooptions.IOStreams) *cobra.Command {
+	o := &PluginInstallOptions{PluginManagerOptions: NewPluginManagerOptions(streams)}
+
+	cmd := &cobra.Command{
+		Use:                   "install NAME...",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Install plugins from a plugin index"),
+		Long:                  pluginInstallLong,
+		Example:               pluginInstallExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				cmdutil.CheckErr(cmdutil.UsageErrorf(cmd, "at least one plugin name is required"))
+			}
+			o.Names = args
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
+
+// Run installs the plugins
+func (o *PluginInstallOptions) Run() error {
+	indexDir, err := o.indexDir()
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, name := range o.Names {
+		receipt, err := o.receipt(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if receipt != nil {
+			errs = append(errs, fmt.Errorf("plugin %q is already installed, use 'kubectl plugin upgrade' to upgrade it", name))
+			continue
+		}
+		manifest, err := o.indexManifest(indexDir, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := o.install(manifest); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintf(o.Out, "Installed plugin: %s %s\n", name, manifest.Spec.Version)
+	}
+
+	if binDir := filepath.Join(o.Root, "bin"); !onPath(binDir) {
+		fmt.Fprintf(o.ErrOut, "warning: %s is not on your PATH, add it to use the installed plugins\n", binDir)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// PluginUpgradeOptions holds the options for 'plugin upgrade' sub command
+type PluginUpgradeOptions struct {
+	*PluginManagerOptions
+
+	Names []string
+}
+
+// NewCmdPluginUpgrade returns a Command instance for 'plugin upgrade' sub command
+func NewCmdPluginUpgrade(streams genericiooptions.IOStreams) *cobra.Command {
+	o := &PluginUpgradeOptions{PluginManagerOptions: NewPluginManagerOptions(streams)}
+
+	cmd := &cobra.Command{
+		Use:                   "upgrade [NAME...]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Upgrade installed plugins"),
+		Long:                  pluginUpgradeLong,
+		Example:               pluginUpgradeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.Names = args
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
+
+// Run upgrades the plugins
+func (o *PluginUpgradeOptions) Run() error {
+	names := o.Names
+	if len(names) == 0 {
+		var err error
+		if names, err = o.installedPlugins(); err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(o.ErrOut, "No plugins are installed.")
+			return nil
+		}
+	}
+
+	indexDir, err := o.indexDir()
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, name := range names {
+		receipt, err := o.receipt(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if receipt == nil {
+			errs = append(errs, fmt.Errorf("plugin %q is not installed, use 'kubectl plugin install' to install it", name))
+			continue
+		}
+		manifest, err := o.indexManifest(indexDir, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !newerVersion(receipt.Spec.Version, manifest.Spec.Version) {
+			fmt.Fprintf(o.Out, "Plugin %s is up to date: %s\n", name, receipt.Spec.Version)
+			continue
+		}
+		if err := o.install(manifest); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Fprintf(o.Out, "Upgraded plugin: %s %s -> %s\n", name, receipt.Spec.Version, manifest.Spec.Version)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// PluginSearchOptions holds the options for 'plugin search' sub command
+type PluginSearchOptions struct {
+	*PluginManagerOptions
+
+	Keyword string
+}
+
+// NewCmdPluginSearch returns a Command instance for 'plugin search' sub command
+func NewCmdPluginSearch(streams genericiooptions.IOStreams) *cobra.Command {
+	o := &PluginSearchOptions{PluginManagerOptions: NewPluginManagerOptions(streams)}
+
+	cmd := &cobra.Command{
+		Use:                   "search [KEYWORD]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Search the plugin index for plugins"),
+		Long:                  pluginSearchLong,
+		Example:               pluginSearchExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 1 {
+				cmdutil.CheckErr(cmdutil.UsageErrorf(cmd, "at most one keyword is allowed, got %d", len(args)))
+			}
+			if len(args) == 1 {
+				o.Keyword = args[0]
+			}
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.AddFlags(cmd)
+	return cmd
+}
+
+// Run lists the plugins of the index matching the keyword
+func (o *PluginSearchOptions) Run() error {
+	indexDir, err := o.indexDir()
+	if err != nil {
+		return err
+	}
+	manifests, err := o.indexManifests(indexDir)
+	if err != nil {
+		return err
+	}
+
+	keyword := strings.ToLower(o.Keyword)
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+	fmt.Fprintln(w, "NAME\tVERSION\tINSTALLED\tDESCRIPTION")
+	for _, manifest := range manifests {
+		name, description := manifest.Metadata.Name, manifest.Spec.ShortDescription
+		if !strings.Contains(strings.ToLower(name), keyword) && !strings.Contains(strings.ToLower(description), keyword) {
+			continue
+		}
+		installed := "no"
+		receipt, err := o.receipt(name)
+		if err != nil {
+			return err
+		}
+		if receipt != nil {
+			installed = receipt.Spec.Version
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, manifest.Spec.Version, installed, description)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func testPluginArchive(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeTestPluginManifest(t *testing.T, indexDir, name, version, uri, checksum string) {
+	manifest := fmt.Sprintf(`apiVersion: krew.googlecontainertools.github.com/v1alpha2
+kind: Plugin
+metadata:
+  name: %s
+spec:
+  version: %s
+  shortDescription: Decode the data of secrets
+  platforms:
+  - selector:
+      matchExpressions:
+      - {key: os, operator: In, values: [darwin]}
+    uri: %s/darwin.tar.gz
+    sha256: %s
+    bin: view-secret
+  - selector:
+      matchLabels:
+        os: linux
+        arch: amd64
+    uri: %s
+    sha256: %s
+    bin: view-secret
+    files:
+    - from: "*/view-secret"
+      to: "."
+    - from: "*/LICENSE"
+      to: "."
+`, name, version, uri, checksum, uri, checksum)
+	if err := os.MkdirAll(filepath.Join(indexDir, "plugins"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(indexDir, "plugins", name+".yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPluginInstallUpgradeSearch(t *testing.T) {
+	archives := map[string][]byte{
+		"/v1.0.0.tar.gz": testPluginArchive(t, map[string]string{"view-secret-v1/view-secret": "#!/bin/sh\necho v1\n", "view-secret-v1/LICENSE": "license"}),
+		"/v1.1.0.tar.gz": testPluginArchive(t, map[string]string{"view-secret-v1.1/view-secret": "#!/bin/sh\necho v1.1\n", "view-secret-v1.1/LICENSE": "license"}),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		archive, ok := archives[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer server.Close()
+	checksum := func(path string) string {
+		sum := sha256.Sum256(archives[path])
+		return hex.EncodeToString(sum[:])
+	}
+
+	indexDir := t.TempDir()
+	root := t.TempDir()
+	writeTestPluginManifest(t, indexDir, "view-secret", "v1.0.0", server.URL+"/v1.0.0.tar.gz", checksum("/v1.0.0.tar.gz"))
+	writeTestPluginManifest(t, indexDir, "corrupted", "v0.1.0", server.URL+"/v1.0.0.tar.gz", checksum("/v1.1.0.tar.gz"))
+
+	newOptions := func() (*PluginManagerOptions, *bytes.Buffer, *bytes.Buffer) {
+		streams, _, out, errOut := genericiooptions.NewTestIOStreams()
+		o := NewPluginManagerOptions(streams)
+		o.Index = indexDir
+		o.Root = root
+		o.OS, o.Arch = "linux", "amd64"
+		o.Client = server.Client()
+		o.RunGit = func(args ...string) error {
+			t.Fatalf("unexpected git %v", args)
+			return nil
+		}
+		return o, out, errOut
+	}
+	link := filepath.Join(root, "bin", "kubectl-view_secret")
+
+	o, out, errOut := newOptions()
+	if err := (&PluginInstallOptions{PluginManagerOptions: o, Names: []string{"view-secret"}}).Run(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Installed plugin: view-secret v1.0.0\n" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	if !strings.Contains(errOut.String(), "is not on your PATH") {
+		t.Errorf("expected a PATH warning, got %q", errOut.String())
+	}
+	if content, err := os.ReadFile(link); err != nil || string(content) != "#!/bin/sh\necho v1\n" {
+		t.Errorf("unexpected plugin executable: %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "store", "view-secret", "v1.0.0", "LICENSE")); err != nil {
+		t.Errorf("expected the license to be installed: %v", err)
+	}
+	if manifest := readPluginCommandManifest(link); manifest == nil || manifest.Short != "Decode the data of secrets" {
+		t.Errorf("unexpected plugin command manifest: %#v", manifest)
+	}
+
+	o, _, _ = newOptions()
+	err := (&PluginInstallOptions{PluginManagerOptions: o, Names: []string{"view-secret", "corrupted", "missing"}}).Run()
+	for _, expected := range []string{
+		`plugin "view-secret" is already installed`,
+		"checksum mismatch for " + server.URL + "/v1.0.0.tar.gz",
+		`plugin "missing" does not exist in the plugin index`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	}
+
+	o, out, _ = newOptions()
+	if err := (&PluginUpgradeOptions{PluginManagerOptions: o}).Run(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Plugin view-secret is up to date: v1.0.0\n" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	writeTestPluginManifest(t, indexDir, "view-secret", "v1.1.0", server.URL+"/v1.1.0.tar.gz", checksum("/v1.1.0.tar.gz"))
+	o, out, _ = newOptions()
+	if err := (&PluginUpgradeOptions{PluginManagerOptions: o, Names: []string{"view-secret"}}).Run(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Upgraded plugin: view-secret v1.0.0 -> v1.1.0\n" {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+	if content, err := os.ReadFile(link); err != nil || string(content) != "#!/bin/sh\necho v1.1\n" {
+		t.Errorf("unexpected plugin executable: %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "store", "view-secret", "v1.0.0")); !os.IsNotExist(err) {
+		t.Errorf("expected the previous version to be removed, got %v", err)
+	}
+
+	o, out, _ = newOptions()
+	if err := (&PluginSearchOptions{PluginManagerOptions: o, Keyword: "SECRET"}).Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `NAME          VERSION   INSTALLED   DESCRIPTION
+corrupted     v0.1.0    no          Decode the data of secrets
+view-secret   v1.1.0    v1.1.0      Decode the data of secrets
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	o, _, _ = newOptions()
+	o.OS = "windows"
+	if err := (&PluginInstallOptions{PluginManagerOptions: o, Names: []string{"corrupted"}}).Run(); err == nil || !strings.Contains(err.Error(), `plugin "corrupted" is not available for windows/amd64`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPluginInstallPathTraversal(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	victim := filepath.Join(base, "victim")
+	if err := os.MkdirAll(victim, 0755); err != nil {
+		t.Fatal(err)
+	}
+	indexDir := t.TempDir()
+	writeTestPluginManifest(t, indexDir, "evil", "../../../victim", "https://example.com/evil.tar.gz", "0000")
+
+	streams, _, _, _ := genericiooptions.NewTestIOStreams()
+	o := NewPluginManagerOptions(streams)
+	o.Index = indexDir
+	o.Root = root
+	o.OS, o.Arch = "linux", "amd64"
+	o.Client = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected download of %s", req.URL)
+		return nil, nil
+	})}
+
+	err := (&PluginInstallOptions{PluginManagerOptions: o, Names: []string{"evil", "../victim"}}).Run()
+	for _, expected := range []string{
+		`invalid plugin version "../../../victim"`,
+		`invalid plugin name "../victim"`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error %q, got %v", expected, err)
+		}
+	}
+
+	manifest := &pluginManifest{}
+	manifest.Metadata.Name = "evil"
+	manifest.Spec.Version = "../../victim"
+	if err := o.install(manifest); err == nil || !strings.Contains(err.Error(), "invalid plugin version") {
+		t.Errorf("expected an invalid version error, got %v", err)
+	}
+	manifest.Metadata.Name = ".."
+	manifest.Spec.Version = "v1.0.0"
+	if err := o.install(manifest); err == nil || !strings.Contains(err.Error(), "invalid plugin name") {
+		t.Errorf("expected an invalid name error, got %v", err)
+	}
+
+	if _, err := os.Stat(victim); err != nil {
+		t.Errorf("expected the directory outside of the root to be kept: %v", err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestArchivePath(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := archivePath(dir, "../escape"); err == nil {
+		t.Errorf("expected paths outside of the directory to be rejected")
+	}
+	if path, err := archivePath(dir, "a/b"); err != nil || path != filepath.Join(dir, "a", "b") {
+		t.Errorf("unexpected path %q: %v", path, err)
+	}
+}
@@ -0,0 +1,590 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultPluginIndex is the index of the krew plugin manager.
+const defaultPluginIndex = "https://github.com/kubernetes-sigs/krew-index.git"
+
+// pluginNameRegexp matches the valid plugin names, as krew does. The names
+// and the versions are part of the paths the plugins are installed in.
+var pluginNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// pluginManifest is a krew plugin manifest, as found in plugins/NAME.yaml of
+// the index.
+type pluginManifest struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Version          string           `json:"version"`
+		ShortDescription string           `json:"shortDescription,omitempty"`
+		Homepage         string           `json:"homepage,omitempty"`
+		Platforms        []pluginPlatform `json:"platforms"`
+	} `json:"spec"`
+}
+
+// pluginPlatform is the archive of a plugin for the platforms matching the
+// selector.
+type pluginPlatform struct {
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	URI      string                `json:"uri"`
+	Sha256   string                `json:"sha256"`
+	Bin      string                `json:"bin"`
+	Files    []struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"files,omitempty"`
+}
+
+// PluginManagerOptions holds the options shared by the commands installing
+// plugins from an index.
+type PluginManagerOptions struct {
+	// Index is the git repository, or the local directory, of the index.
+	Index string
+	// Root is the directory the plugins are installed in: the archives are
+	// extracted in store, the plugin executables linked in bin and the
+	// installed manifests kept in receipts.
+	Root string
+
+	OS     string
+	Arch   string
+	Client *http.Client
+	RunGit func(args ...string) error
+
+	genericiooptions.IOStreams
+}
+
+// NewPluginManagerOptions returns the options of the plugin manager for the
+// current platform.
+func NewPluginManagerOptions(streams genericiooptions.IOStreams) *PluginManagerOptions {
+	return &PluginManagerOptions{
+		Index:  defaultPluginIndex,
+		Root:   filepath.Join(homedir.HomeDir(), ".kube", "plugins"),
+		OS:     runtime.GOOS,
+		Arch:   runtime.GOARCH,
+		Client: http.DefaultClient,
+		RunGit: func(args ...string) error {
+			cmd := exec.Command("git", args...)
+			cmd.Stderr = streams.ErrOut
+			return cmd.Run()
+		},
+		IOStreams: streams,
+	}
+}
+
+// AddFlags adds the flags of the plugin manager to the command.
+func (o *PluginManagerOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Index, "index", o.Index, "The git repository of the krew-compatible plugin index, or a local directory containing it.")
+	cmd.Flags().StringVar(&o.Root, "root", o.Root, "The directory the plugins are installed in. Its bin directory must be on the PATH.")
+}
+
+// indexDir returns the directory of the index, cloning or updating the git
+// repository of the index in the root directory when needed.
+func (o *PluginManagerOptions) indexDir() (string, error) {
+	if info, err := os.Stat(o.Index); err == nil && info.IsDir() {
+		return o.Index, nil
+	}
+	dir := filepath.Join(o.Root, "index")
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		if err := o.RunGit("-C", dir, "pull", "--ff-only", "-q"); err != nil {
+			return "", fmt.Errorf("failed to update the plugin index: %v", err)
+		}
+		return dir, nil
+	}
+	if err := os.MkdirAll(o.Root, 0755); err != nil {
+		return "", err
+	}
+	if err := o.RunGit("clone", "--depth", "1", "-q", o.Index, dir); err != nil {
+		return "", fmt.Errorf("failed to clone the plugin index %s: %v", o.Index, err)
+	}
+	return dir, nil
+}
+
+// indexManifest returns the manifest of the plugin in the index.
+func (o *PluginManagerOptions) indexManifest(indexDir, name string) (*pluginManifest, error) {
+	if err := validatePluginName(name); err != nil {
+		return nil, err
+	}
+	manifest, err := readPluginManifest(filepath.Join(indexDir, "plugins", name+".yaml"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("plugin %q does not exist in the plugin index", name)
+	}
+	return manifest, err
+}
+
+// indexManifests returns the manifests of all the plugins of the index,
+// sorted by name.
+func (o *PluginManagerOptions) indexManifests(indexDir string) ([]*pluginManifest, error) {
+	paths, err := filepath.Glob(filepath.Join(indexDir, "plugins", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	manifests := []*pluginManifest{}
+	for _, path := range paths {
+		manifest, err := readPluginManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+// receipt returns the manifest the plugin was installed from, or nil when
+// the plugin is not installed.
+func (o *PluginManagerOptions) receipt(name string) (*pluginManifest, error) {
+	if err := validatePluginName(name); err != nil {
+		return nil, err
+	}
+	manifest, err := readPluginManifest(o.receiptPath(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return manifest, err
+}
+
+// installedPlugins returns the names of the installed plugins.
+func (o *PluginManagerOptions) installedPlugins() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(o.Root, "receipts", "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, path := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(path), ".yaml"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (o *PluginManagerOptions) receiptPath(name string) string {
+	return filepath.Join(o.Root, "receipts", name+".yaml")
+}
+
+func readPluginManifest(path string) (*pluginManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &pluginManifest{}
+	if err := yaml.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid plugin manifest %s: %v", path, err)
+	}
+	if len(manifest.Metadata.Name) == 0 || len(manifest.Spec.Version) == 0 {
+		return nil, fmt.Errorf("invalid plugin manifest %s: the name and the version are required", path)
+	}
+	if err := validatePluginManifest(manifest); err != nil {
+		return nil, fmt.Errorf("invalid plugin manifest %s: %v", path, err)
+	}
+	return manifest, nil
+}
+
+// validatePluginManifest checks the name and the version of the manifest
+// before they are used in paths.
+func validatePluginManifest(manifest *pluginManifest) error {
+	if err := validatePluginName(manifest.Metadata.Name); err != nil {
+		return err
+	}
+	return validatePluginVersion(manifest.Spec.Version)
+}
+
+func validatePluginName(name string) error {
+	if !pluginNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid plugin name %q, it must consist of lower case alphanumeric characters and dashes", name)
+	}
+	return nil
+}
+
+// validatePluginVersion checks that the version is a semantic version
+// prefixed with v, such as v1.2.3.
+func validatePluginVersion(v string) error {
+	parsed, err := version.ParseSemantic(v)
+	if err != nil || v != "v"+parsed.String() {
+		return fmt.Errorf("invalid plugin version %q, it must be a semantic version such as v1.2.3", v)
+	}
+	return nil
+}
+
+// platform returns the platform of the manifest matching the os and the
+// architecture of the options.
+func (o *PluginManagerOptions) platform(manifest *pluginManifest) (*pluginPlatform, error) {
+	set := labels.Set{"os": o.OS, "arch": o.Arch}
+	for i := range manifest.Spec.Platforms {
+		platform := &manifest.Spec.Platforms[i]
+		selector, err := metav1.LabelSelectorAsSelector(platform.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid platform selector of plugin %q: %v", manifest.Metadata.Name, err)
+		}
+		if selector.Matches(set) {
+			return platform, nil
+		}
+	}
+	return nil, fmt.Errorf("plugin %q is not available for %s/%s", manifest.Metadata.Name, o.OS, o.Arch)
+}
+
+// install downloads, verifies and extracts the archive of the plugin, links
+// its executable in the bin directory and records the manifest as the
+// receipt of the installation. The files of a previously installed version
+// are removed.
+func (o *PluginManagerOptions) install(manifest *pluginManifest) error {
+	if err := validatePluginManifest(manifest); err != nil {
+		return err
+	}
+	name := manifest.Metadata.Name
+	platform, err := o.platform(manifest)
+	if err != nil {
+		return err
+	}
+	if len(platform.Bin) == 0 {
+		return fmt.Errorf("invalid plugin manifest of %q: the platform has no bin", name)
+	}
+
+	previous, err := o.receipt(name)
+	if err != nil {
+		return err
+	}
+
+	archive, err := o.download(platform.URI, platform.Sha256)
+	if err != nil {
+		return fmt.Errorf("failed to download plugin %q: %v", name, err)
+	}
+	defer os.Remove(archive)
+
+	extracted, err := os.MkdirTemp("", "kubectl-plugin-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(extracted)
+	if err := extractArchive(archive, platform.URI, extracted); err != nil {
+		return fmt.Errorf("failed to extract plugin %q: %v", name, err)
+	}
+
+	storeDir := filepath.Join(o.Root, "store", name, manifest.Spec.Version)
+	if err := os.RemoveAll(storeDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(storeDir), 0755); err != nil {
+		return err
+	}
+	if len(platform.Files) == 0 {
+		if err := copyDir(extracted, storeDir); err != nil {
+			return err
+		}
+	}
+	for _, file := range platform.Files {
+		matches, err := filepath.Glob(filepath.Join(extracted, filepath.FromSlash(file.From)))
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("invalid plugin manifest of %q: no file matches %s in the archive", name, file.From)
+		}
+		for _, match := range matches {
+			to := filepath.Join(storeDir, filepath.FromSlash(file.To))
+			if len(matches) > 1 || strings.HasSuffix(file.To, "/") || file.To == "." || len(file.To) == 0 {
+				to = filepath.Join(to, filepath.Base(match))
+			}
+			if err := copyDir(match, to); err != nil {
+				return err
+			}
+		}
+	}
+
+	bin := filepath.Join(storeDir, filepath.FromSlash(platform.Bin))
+	if _, err := os.Stat(bin); err != nil {
+		return fmt.Errorf("invalid plugin manifest of %q: the bin %s is not in the archive", name, platform.Bin)
+	}
+	if err := os.Chmod(bin, 0755); err != nil {
+		return err
+	}
+	link, err := o.link(name, bin)
+	if err != nil {
+		return err
+	}
+	// the description of the plugin is shown in the command listings of kubectl
+	commandManifest, err := yaml.Marshal(&pluginCommandManifest{Short: manifest.Spec.ShortDescription})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(pluginCommandManifestPath(link), commandManifest, 0644); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(o.receiptPath(name)), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(o.receiptPath(name), data, 0644); err != nil {
+		return err
+	}
+
+	if previous != nil && previous.Spec.Version != manifest.Spec.Version {
+		return os.RemoveAll(filepath.Join(o.Root, "store", name, previous.Spec.Version))
+	}
+	return nil
+}
+
+// link makes the executable of the plugin available as kubectl-NAME in the
+// bin directory, dashes in the name being replaced by underscores as kubectl
+// expects. The path of the link is returned.
+func (o *PluginManagerOptions) link(name, bin string) (string, error) {
+	binDir := filepath.Join(o.Root, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return "", err
+	}
+	link := filepath.Join(binDir, "kubectl-"+strings.ReplaceAll(name, "-", "_"))
+	if o.OS == "windows" {
+		link += ".exe"
+	}
+	if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err := os.Symlink(bin, link); err != nil {
+		// symbolic links may not be allowed, for instance on windows
+		return link, copyFile(bin, link, 0755)
+	}
+	return link, nil
+}
+
+// download downloads the archive to a temporary file, verifying its sha256
+// checksum.
+func (o *PluginManagerOptions) download(uri, checksum string) (string, error) {
+	resp, err := o.Client.Get(uri)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", uri, resp.Status)
+	}
+
+	f, err := os.CreateTemp("", "kubectl-plugin-*")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), resp.Body); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, checksum) {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", uri, checksum, sum)
+	}
+	return f.Name(), nil
+}
+
+// extractArchive extracts the zip or gzipped tar archive to dir. The format
+// is detected from the content of the archive.
+func extractArchive(archive, uri, dir string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return fmt.Errorf("unknown archive format of %s", uri)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	switch {
+	case magic[0] == 'P' && magic[1] == 'K':
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return extractZip(f, info.Size(), dir)
+	case magic[0] == 0x1f && magic[1] == 0x8b:
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		return extractTar(gz, dir)
+	default:
+		return fmt.Errorf("unknown archive format of %s, only .zip and .tar.gz are supported", uri)
+	}
+}
+
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path, err := archivePath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(path, tr, os.FileMode(header.Mode)&0755); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func extractZip(r io.ReaderAt, size int64, dir string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, file := range zr.File {
+		path, err := archivePath(dir, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeFile(path, rc, file.Mode()&0755)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archivePath returns the path of an entry of an archive extracted to dir,
+// rejecting the entries outside of dir.
+func archivePath(dir, name string) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if path != dir && !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid path %q in the archive", name)
+	}
+	return path, nil
+}
+
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func copyFile(from, to string, mode os.FileMode) error {
+	f, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return writeFile(to, f, mode)
+}
+
+// copyDir copies the file or the directory from to the path to.
+func copyDir(from, to string) error {
+	return filepath.Walk(from, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(path, target, info.Mode()&0755)
+	})
+}
+
+// newerVersion reports whether the version of the index is newer than the
+// installed version. Versions that are not semantic are compared as is.
+func newerVersion(installed, available string) bool {
+	i, err := version.ParseSemantic(installed)
+	if err != nil {
+		return installed != available
+	}
+	a, err := version.ParseSemantic(available)
+	if err != nil {
+		return installed != available
+	}
+	return i.LessThan(a)
+}
+
+// onPath reports whether the directory is on the PATH.
+func onPath(dir string) bool {
+	for _, path := range filepath.SplitList(os.Getenv("PATH")) {
+		if filepath.Clean(path) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/util/duration"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/util/homedir"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// forwardState is the record written to the state directory for each
+// background port forward.
+type forwardState struct {
+	PID       int       `json:"pid"`
+	Namespace string    `json:"namespace"`
+	Resource  string    `json:"resource"`
+	Pod       string    `json:"pod"`
+	Ports     []string  `json:"ports"`
+	Addresses []string  `json:"addresses"`
+	Retry     bool      `json:"retry"`
+	LogFile   string    `json:"logFile"`
+	StartTime time.Time `json:"startTime"`
+}
+
+var (
+	portforwardListLong = templates.LongDesc(i18n.T(`
+		List port forwards running in the background.
+
+		Entries whose process is no longer running are removed from the state directory.`))
+
+	portforwardListExample = templates.Examples(i18n.T(`
+		# List background port forwards
+		kubectl port-forward list`))
+
+	portforwardStopLong = templates.LongDesc(i18n.T(`
+		Stop port forwards running in the background.
+
+		Forwards can be selected by process ID or by the TYPE/NAME they were started for.`))
+
+	portforwardStopExample = templates.Examples(i18n.T(`
+		# Stop the background port forward with process ID 4242
+		kubectl port-forward stop 4242
+
+		# Stop all background port forwards to deployment/mydeployment
+		kubectl port-forward stop deployment/mydeployment
+
+		# Stop all background port forwards
+		kubectl port-forward stop --all`))
+)
+
+func defaultStateDir() string {
+	return filepath.Join(homedir.HomeDir(), ".kube", "port-forward")
+}
+
+// RunDaemon starts the port forward in a detached child process that
+// re-executes the current command line, and records its state.
+func (o PortForwardOptions) RunDaemon(out io.Writer) error {
+	if err := os.MkdirAll(o.StateDir, 0750); err != nil {
+		return err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	logFile, err := os.CreateTemp(o.StateDir, "port-forward-*.log")
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	// the state file name is only known once the child is started, so it is
+	// reserved up front and handed to the child which removes it on exit.
+	stateFile, err := os.CreateTemp(o.StateDir, "port-forward-*.json")
+	if err != nil {
+		return err
+	}
+	stateFile.Close()
+
+	child := exec.Command(executable, os.Args[1:]...)
+	child.Env = append(os.Environ(), daemonEnvVar+"="+stateFile.Name())
+	child.Stdin = nil
+	child.Stdout = logFile
+	child.Stderr = logFile
+	if err := child.Start(); err != nil {
+		os.Remove(stateFile.Name())
+		return err
+	}
+
+	state := forwardState{
+		PID:       child.Process.Pid,
+		Namespace: o.Namespace,
+		Resource:  o.Resource,
+		Pod:       o.PodName,
+		Ports:     o.Ports,
+		Addresses: o.Address,
+		Retry:     o.Retry,
+		LogFile:   logFile.Name(),
+		StartTime: time.Now(),
+	}
+	if err := writeState(stateFile.Name(), state); err != nil {
+		return err
+	}
+	// the child is intentionally not waited for
+	if err := child.Process.Release(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Forwarding %s in the background (pid %d), logs are written to %s\n", strings.Join(o.Ports, ","), state.PID, state.LogFile)
+	return nil
+}
+
+func writeState(path string, state forwardState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0640)
+}
+
+// loadStates reads every state file in dir. Entries whose process is no
+// longer running are removed, along with their log files.
+func loadStates(dir string) (map[string]forwardState, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "port-forward-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	states := map[string]forwardState{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		// a state file is reserved empty before its process is started
+		if len(data) == 0 {
+			continue
+		}
+		state := forwardState{}
+		if err := json.Unmarshal(data, &state); err != nil || state.PID == 0 || !processAlive(state.PID) {
+			removeState(file, state)
+			continue
+		}
+		states[file] = state
+	}
+	return states, nil
+}
+
+func removeState(file string, state forwardState) {
+	os.Remove(file)
+	if len(state.LogFile) > 0 {
+		os.Remove(state.LogFile)
+	}
+}
+
+// processAlive reports whether a process with the given pid is running.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// FindProcess always succeeds on unix, probe with signal 0 instead;
+	// on windows it fails when the process does not exist.
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// sortedStates returns the state files ordered by start time.
+func sortedStates(states map[string]forwardState) []string {
+	files := make([]string, 0, len(states))
+	for file := range states {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return states[files[i]].StartTime.Before(states[files[j]].StartTime)
+	})
+	return files
+}
+
+// NewCmdPortForwardList returns a command that lists background port forwards.
+func NewCmdPortForwardList(streams genericiooptions.IOStreams, stateDir *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "list",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("List port forwards running in the background"),
+		Long:                  portforwardListLong,
+		Example:               portforwardListExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(listForwards(streams.Out, *stateDir))
+		},
+	}
+	return cmd
+}
+
+func listForwards(out io.Writer, stateDir string) error {
+	states, err := loadStates(stateDir)
+	if err != nil {
+		return err
+	}
+	if len(states) == 0 {
+		fmt.Fprintln(out, "No background port forwards found.")
+		return nil
+	}
+
+	w := printers.GetNewTabWriter(out)
+	defer w.Flush()
+	fmt.Fprintln(w, "PID\tNAMESPACE\tRESOURCE\tPOD\tADDRESSES\tPORTS\tRETRY\tAGE")
+	for _, file := range sortedStates(states) {
+		state := states[file]
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%t\t%s\n",
+			state.PID,
+			state.Namespace,
+			state.Resource,
+			state.Pod,
+			strings.Join(state.Addresses, ","),
+			strings.Join(state.Ports, ","),
+			state.Retry,
+			duration.HumanDuration(time.Since(state.StartTime)))
+	}
+	return nil
+}
+
+// NewCmdPortForwardStop returns a command that stops background port forwards.
+func NewCmdPortForwardStop(streams genericiooptions.IOStreams, stateDir *string) *cobra.Command {
+	all := false
+	cmd := &cobra.Command{
+		Use:                   "stop (PID | TYPE/NAME)... | --all",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Stop port forwards running in the background"),
+		Long:                  portforwardStopLong,
+		Example:               portforwardStopExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 && !all {
+				cmdutil.CheckErr(cmdutil.UsageErrorf(cmd, "a PID or TYPE/NAME is required unless --all is specified"))
+			}
+			if len(args) > 0 && all {
+				cmdutil.CheckErr(cmdutil.UsageErrorf(cmd, "PID or TYPE/NAME arguments cannot be combined with --all"))
+			}
+			cmdutil.CheckErr(stopForwards(streams.Out, *stateDir, args, all))
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", all, "Stop all background port forwards.")
+	return cmd
+}
+
+func stopForwards(out io.Writer, stateDir string, targets []string, all bool) error {
+	states, err := loadStates(stateDir)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, target := range targets {
+		if !hasMatch(states, target) {
+			errs = append(errs, fmt.Errorf("no background port forward found for %q", target))
+		}
+	}
+	for _, file := range sortedStates(states) {
+		state := states[file]
+		if !all && !matchesAny(state, targets) {
+			continue
+		}
+		if err := stopProcess(state.PID); err != nil {
+			errs = append(errs, fmt.Errorf("unable to stop port forward (pid %d): %v", state.PID, err))
+			continue
+		}
+		removeState(file, state)
+		fmt.Fprintf(out, "Stopped port forward to %s (pid %d)\n", state.Resource, state.PID)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func hasMatch(states map[string]forwardState, target string) bool {
+	for _, state := range states {
+		if matchesAny(state, []string{target}) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(state forwardState, targets []string) bool {
+	for _, target := range targets {
+		if pid, err := strconv.Atoi(target); err == nil && pid == state.PID {
+			return true
+		}
+		if target == state.Resource || target == "pod/"+state.Pod || target == state.Pod {
+			return true
+		}
+	}
+	return false
+}
+
+func stopProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	// windows does not support delivering SIGTERM
+	if runtime.GOOS == "windows" {
+		return process.Kill()
+	}
+	return process.Signal(syscall.SIGTERM)
+}
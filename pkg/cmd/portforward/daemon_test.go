@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadStatesRemovesStaleEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	alive := filepath.Join(dir, "port-forward-alive.json")
+	if err := writeState(alive, forwardState{PID: os.Getpid(), Resource: "pod/foo", StartTime: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	staleLog := filepath.Join(dir, "port-forward-stale.log")
+	if err := os.WriteFile(staleLog, []byte("log"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dir, "port-forward-stale.json")
+	// pids are bounded well below this value on every supported platform
+	if err := writeState(stale, forwardState{PID: 1 << 30, Resource: "pod/bar", LogFile: staleLog}); err != nil {
+		t.Fatal(err)
+	}
+	reserved := filepath.Join(dir, "port-forward-reserved.json")
+	if err := os.WriteFile(reserved, nil, 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	states, err := loadStates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(states) != 1 || states[alive].Resource != "pod/foo" {
+		t.Errorf("unexpected states: %#v", states)
+	}
+	for _, file := range []string{stale, staleLog} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", file, err)
+		}
+	}
+	if _, err := os.Stat(reserved); err != nil {
+		t.Errorf("expected reserved state file to be kept, got %v", err)
+	}
+}
+
+func TestListForwards(t *testing.T) {
+	dir := t.TempDir()
+	out := &bytes.Buffer{}
+	if err := listForwards(out, dir); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "No background port forwards found.") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+
+	state := forwardState{
+		PID:       os.Getpid(),
+		Namespace: "test",
+		Resource:  "deployment/web",
+		Pod:       "web-1",
+		Ports:     []string{"8080:80"},
+		Addresses: []string{"localhost"},
+		Retry:     true,
+		StartTime: time.Now(),
+	}
+	if err := writeState(filepath.Join(dir, "port-forward-1.json"), state); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := listForwards(out, dir); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected header and one row, got %q", out.String())
+	}
+	for _, expected := range []string{"deployment/web", "web-1", "8080:80", "localhost", "true"} {
+		if !strings.Contains(lines[1], expected) {
+			t.Errorf("expected %q in %q", expected, lines[1])
+		}
+	}
+}
+
+func TestMatchesAny(t *testing.T) {
+	state := forwardState{PID: 42, Resource: "service/web", Pod: "web-1"}
+	tests := []struct {
+		targets  []string
+		expected bool
+	}{
+		{targets: []string{"42"}, expected: true},
+		{targets: []string{"service/web"}, expected: true},
+		{targets: []string{"web-1"}, expected: true},
+		{targets: []string{"pod/web-1"}, expected: true},
+		{targets: []string{"43", "service/other"}, expected: false},
+		{targets: nil, expected: false},
+	}
+	for _, test := range tests {
+		if actual := matchesAny(state, test.targets); actual != test.expected {
+			t.Errorf("%v: expected %t, got %t", test.targets, test.expected, actual)
+		}
+	}
+}
+
+func TestStopForwardsUnknownTarget(t *testing.T) {
+	err := stopForwards(&bytes.Buffer{}, t.TempDir(), []string{"pod/missing"}, false)
+	if err == nil || !strings.Contains(err.Error(), `no background port forward found for "pod/missing"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
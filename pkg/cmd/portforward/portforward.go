@@ -18,13 +18,16 @@ package portforward
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -32,6 +35,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/client-go/kubernetes/scheme"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -58,6 +62,22 @@ type PortForwardOptions struct {
 	PortForwarder portForwarder
 	StopChannel   chan struct{}
 	ReadyChannel  chan struct{}
+
+	// Resource is the TYPE/NAME argument the forward was started for.
+	Resource string
+	// Daemon runs the forward in a detached background process.
+	Daemon bool
+	// Retry reconnects with exponential backoff when the connection to the pod is lost.
+	Retry bool
+	// StateDir is where background forwards record their state files.
+	StateDir string
+	// RetryBackoff controls the delay between reconnect attempts.
+	RetryBackoff wait.Backoff
+
+	// resolvePod re-resolves the target pod on reconnect, since the pod
+	// selected for a workload may have been replaced.
+	resolvePod func() (string, error)
+	errOut     io.Writer
 }
 
 var (
@@ -90,19 +110,42 @@ var (
 		kubectl port-forward --address localhost,10.19.21.23 pod/mypod 8888:5000
 
 		# Listen on a random port locally, forwarding to 5000 in the pod
-		kubectl port-forward pod/mypod :5000`))
+		kubectl port-forward pod/mypod :5000
+
+		# Forward port 8888 in the background, reconnecting when the connection to the pod is lost
+		kubectl port-forward --daemon --retry deployment/mydeployment 8888:5000
+
+		# List and stop background port forwards
+		kubectl port-forward list
+		kubectl port-forward stop deployment/mydeployment`))
 )
 
 const (
 	// Amount of time to wait until at least one pod is running
 	defaultPodPortForwardWaitTimeout = 60 * time.Second
+
+	// daemonEnvVar is set in the environment of a background port-forward
+	// process so that it runs the forward in the foreground instead of forking again.
+	daemonEnvVar = "KUBECTL_PORT_FORWARD_DAEMON"
 )
 
+// defaultRetryBackoff is the reconnect backoff used with --retry: 1s doubling up to 1m.
+var defaultRetryBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    7,
+	Cap:      time.Minute,
+}
+
 func NewCmdPortForward(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
 	opts := &PortForwardOptions{
 		PortForwarder: &defaultPortForwarder{
 			IOStreams: streams,
 		},
+		StateDir:     defaultStateDir(),
+		RetryBackoff: defaultRetryBackoff,
+		errOut:       streams.ErrOut,
 	}
 	cmd := &cobra.Command{
 		Use:                   "port-forward TYPE/NAME [options] [LOCAL_PORT:]REMOTE_PORT [...[LOCAL_PORT_N:]REMOTE_PORT_N]",
@@ -114,12 +157,22 @@ func NewCmdPortForward(f cmdutil.Factory, streams genericiooptions.IOStreams) *c
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(opts.Complete(f, cmd, args))
 			cmdutil.CheckErr(opts.Validate())
+			if opts.Daemon {
+				cmdutil.CheckErr(opts.RunDaemon(streams.Out))
+				return
+			}
 			cmdutil.CheckErr(opts.RunPortForward())
 		},
 	}
 	cmdutil.AddPodRunningTimeoutFlag(cmd, defaultPodPortForwardWaitTimeout)
 	cmd.Flags().StringSliceVar(&opts.Address, "address", []string{"localhost"}, "Addresses to listen on (comma separated). Only accepts IP addresses or localhost as a value. When localhost is supplied, kubectl will try to bind on both 127.0.0.1 and ::1 and will fail if neither of these addresses are available to bind.")
+	cmd.Flags().BoolVar(&opts.Daemon, "daemon", opts.Daemon, "If true, run the port forward in a background process and record it in the state directory.")
+	cmd.Flags().BoolVar(&opts.Retry, "retry", opts.Retry, "If true, reconnect with exponential backoff when the connection to the pod is lost, re-selecting the pod if needed.")
+	cmd.PersistentFlags().StringVar(&opts.StateDir, "state-dir", opts.StateDir, "Directory where background port forwards record their state.")
 	// TODO support UID
+
+	cmd.AddCommand(NewCmdPortForwardList(streams, &opts.StateDir))
+	cmd.AddCommand(NewCmdPortForwardStop(streams, &opts.StateDir))
 	return cmd
 }
 
@@ -312,6 +365,7 @@ func (o *PortForwardOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, arg
 	}
 
 	resourceName := args[0]
+	o.Resource = resourceName
 	builder.ResourceNames("pods", resourceName)
 
 	obj, err := builder.Do().Object()
@@ -325,6 +379,21 @@ func (o *PortForwardOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, arg
 	}
 
 	o.PodName = forwardablePod.Name
+	o.resolvePod = func() (string, error) {
+		obj, err := f.NewBuilder().
+			WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+			NamespaceParam(o.Namespace).DefaultNamespace().
+			ResourceNames("pods", resourceName).
+			Do().Object()
+		if err != nil {
+			return "", err
+		}
+		pod, err := polymorphichelpers.AttachablePodForObjectFn(f, obj, getPodTimeout)
+		if err != nil {
+			return "", err
+		}
+		return pod.Name, nil
+	}
 
 	// handle service port mapping to target port if needed
 	switch t := obj.(type) {
@@ -366,6 +435,12 @@ func (o *PortForwardOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, arg
 
 	o.StopChannel = make(chan struct{}, 1)
 	o.ReadyChannel = make(chan struct{})
+
+	// a background process re-executes the original command line; it must
+	// forward in the foreground rather than fork again.
+	if len(os.Getenv(daemonEnvVar)) > 0 {
+		o.Daemon = false
+	}
 	return nil
 }
 
@@ -382,22 +457,19 @@ func (o PortForwardOptions) Validate() error {
 	if o.PortForwarder == nil || o.PodClient == nil || o.RESTClient == nil || o.Config == nil {
 		return fmt.Errorf("client, client config, restClient, and portforwarder must be provided")
 	}
+
+	// client-go has no port forwarding dialer over WebSockets yet, so
+	// port-forward stays on SPDY whatever the --stream-protocol
+	if cmdutil.StreamProtocol() == cmdutil.StreamProtocolWebSocket {
+		return fmt.Errorf("port-forward only streams over SPDY, --stream-protocol=%s is not supported yet", cmdutil.StreamProtocolWebSocket)
+	}
 	return nil
 }
 
 // RunPortForward implements all the necessary functionality for port-forward cmd.
 func (o PortForwardOptions) RunPortForward() error {
-	pod, err := o.PodClient.Pods(o.Namespace).Get(context.TODO(), o.PodName, metav1.GetOptions{})
-	if err != nil {
-		return err
-	}
-
-	if pod.Status.Phase != corev1.PodRunning {
-		return fmt.Errorf("unable to forward port because pod is not running. Current status=%v", pod.Status.Phase)
-	}
-
 	signals := make(chan os.Signal, 1)
-	signal.Notify(signals, os.Interrupt)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
 	defer signal.Stop(signals)
 
 	go func() {
@@ -407,6 +479,27 @@ func (o PortForwardOptions) RunPortForward() error {
 		}
 	}()
 
+	if stateFile := os.Getenv(daemonEnvVar); len(stateFile) > 0 {
+		defer os.Remove(stateFile)
+	}
+
+	if !o.Retry {
+		return o.forwardPorts()
+	}
+	return o.forwardPortsWithRetry()
+}
+
+// forwardPorts runs a single port forwarding session against the selected pod.
+func (o PortForwardOptions) forwardPorts() error {
+	pod, err := o.PodClient.Pods(o.Namespace).Get(context.TODO(), o.PodName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if pod.Status.Phase != corev1.PodRunning {
+		return fmt.Errorf("unable to forward port because pod is not running. Current status=%v", pod.Status.Phase)
+	}
+
 	req := o.RESTClient.Post().
 		Resource("pods").
 		Namespace(o.Namespace).
@@ -415,3 +508,67 @@ func (o PortForwardOptions) RunPortForward() error {
 
 	return o.PortForwarder.ForwardPorts("POST", req.URL(), o)
 }
+
+// forwardPortsWithRetry runs port forwarding sessions until the stop channel
+// is closed, waiting with exponential backoff between failed attempts. The
+// backoff is reset once a session becomes ready.
+func (o PortForwardOptions) forwardPortsWithRetry() error {
+	errOut := o.errOut
+	if errOut == nil {
+		errOut = io.Discard
+	}
+	backoff := o.RetryBackoff
+	for {
+		if o.ReadyChannel == nil {
+			o.ReadyChannel = make(chan struct{})
+		}
+		ready := o.ReadyChannel
+		err := o.forwardPorts()
+		if isClosed(o.StopChannel) {
+			return nil
+		}
+		if err == nil {
+			err = portforward.ErrLostConnectionToPod
+		}
+		if isClosed(ready) {
+			backoff = o.RetryBackoff
+		}
+
+		delay := backoff.Step()
+		if errors.Is(err, portforward.ErrLostConnectionToPod) {
+			fmt.Fprintf(errOut, "Lost connection to pod %s, reconnecting in %s\n", o.PodName, delay.Round(time.Millisecond))
+		} else {
+			fmt.Fprintf(errOut, "Port forward to pod %s failed: %v, retrying in %s\n", o.PodName, err, delay.Round(time.Millisecond))
+		}
+
+		select {
+		case <-o.StopChannel:
+			return nil
+		case <-time.After(delay):
+		}
+
+		if o.resolvePod != nil {
+			podName, err := o.resolvePod()
+			if err != nil {
+				fmt.Fprintf(errOut, "Unable to select a pod for %s: %v\n", o.Resource, err)
+			} else {
+				o.PodName = podName
+			}
+		}
+		// the port forwarder closes the ready channel once it is listening
+		o.ReadyChannel = make(chan struct{})
+	}
+}
+
+// isClosed reports whether ch has been closed without blocking.
+func isClosed(ch chan struct{}) bool {
+	if ch == nil {
+		return false
+	}
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
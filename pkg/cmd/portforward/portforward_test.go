@@ -17,11 +17,14 @@ limitations under the License.
 package portforward
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -29,8 +32,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/client-go/rest/fake"
+	"k8s.io/client-go/tools/portforward"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 	"k8s.io/kubectl/pkg/scheme"
 )
@@ -142,6 +147,76 @@ func TestPortForward(t *testing.T) {
 	testPortForward(t, nil, []string{"foo", ":5000", ":1000"})
 }
 
+type flakyPortForwarder struct {
+	calls    int
+	failures int
+	stop     chan struct{}
+}
+
+func (f *flakyPortForwarder) ForwardPorts(method string, url *url.URL, opts PortForwardOptions) error {
+	f.calls++
+	if f.calls <= f.failures {
+		close(opts.ReadyChannel)
+		return portforward.ErrLostConnectionToPod
+	}
+	close(f.stop)
+	return nil
+}
+
+func TestPortForwardRetry(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	ns := scheme.Codecs.WithoutConversion()
+	podGets := 0
+	tf.Client = &fake.RESTClient{
+		VersionedAPIPath:     "/api/v1",
+		GroupVersion:         schema.GroupVersion{Group: "", Version: "v1"},
+		NegotiatedSerializer: ns,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/api/v1/namespaces/test/pods/foo" && m == "GET":
+				podGets++
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, execPod())}, nil
+			default:
+				t.Errorf("unexpected request: %#v\n%#v", req.URL, req)
+				return nil, nil
+			}
+		}),
+	}
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+	errOut := &bytes.Buffer{}
+	opts := &PortForwardOptions{
+		Retry:        true,
+		RetryBackoff: wait.Backoff{Duration: time.Millisecond, Factor: 2, Steps: 3},
+		errOut:       errOut,
+	}
+	cmd := NewCmdPortForward(tf, genericiooptions.NewTestIOStreamsDiscard())
+	if err := opts.Complete(tf, cmd, []string{"foo", ":5000"}); err != nil {
+		t.Fatal(err)
+	}
+	ff := &flakyPortForwarder{failures: 2, stop: opts.StopChannel}
+	opts.PortForwarder = ff
+	if err := opts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := opts.RunPortForward(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ff.calls != 3 {
+		t.Errorf("expected 3 port-forward attempts, got %d", ff.calls)
+	}
+	// one GET while completing, then one for resolving and one for forwarding per attempt
+	if podGets != 1+3+2 {
+		t.Errorf("expected 6 pod requests, got %d", podGets)
+	}
+	if count := strings.Count(errOut.String(), "Lost connection to pod foo, reconnecting"); count != 2 {
+		t.Errorf("expected 2 reconnect messages, got %d: %q", count, errOut.String())
+	}
+}
+
 func TestTranslateServicePortToTargetPort(t *testing.T) {
 	cases := []struct {
 		name       string
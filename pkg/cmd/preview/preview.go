@@ -0,0 +1,310 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/color"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const previewFieldManager = "kubectl-preview"
+
+var (
+	previewLong = templates.LongDesc(i18n.T(`
+		Preview how the server admits resources, without persisting them.
+
+		Each resource is created, or applied on the server side when it already exists,
+		as a dry-run, and the fields changed by the server are reported: the defaults it
+		set, and the changes of the mutating admission webhooks and policies. The fields
+		always set by the server, such as the UID or the status, are not reported. For
+		the resources which already exist, the fields kept from the live resource are not
+		reported either.`))
+
+	previewExample = templates.Examples(i18n.T(`
+		# Preview the changes the server makes to the resources of manifest.yaml
+		kubectl preview -f manifest.yaml`))
+)
+
+// PreviewOptions holds the command-line options for 'preview' command
+type PreviewOptions struct {
+	FilenameOptions resource.FilenameOptions
+
+	Namespace        string
+	EnforceNamespace bool
+	Builder          func() *resource.Builder
+
+	genericiooptions.IOStreams
+}
+
+// NewPreviewOptions returns an initialized PreviewOptions instance
+func NewPreviewOptions(streams genericiooptions.IOStreams) *PreviewOptions {
+	return &PreviewOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdPreview returns a Command instance for the 'preview' command
+func NewCmdPreview(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewPreviewOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "preview -f FILENAME",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Preview the changes the server makes to resources when admitting them"),
+		Long:                  previewLong,
+		Example:               previewExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "containing the resources to preview.")
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *PreviewOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return cmdutil.UsageErrorf(cmd, "unexpected arguments: %v", args)
+	}
+	var err error
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.Builder = f.NewBuilder
+	return nil
+}
+
+// Validate makes sure all the provided values for command-line options are valid
+func (o *PreviewOptions) Validate() error {
+	if cmdutil.IsFilenameSliceEmpty(o.FilenameOptions.Filenames, o.FilenameOptions.Kustomize) {
+		return fmt.Errorf("must specify --filename or --kustomize")
+	}
+	return nil
+}
+
+// Run previews the admission of the resources
+func (o *PreviewOptions) Run() error {
+	infos, err := o.Builder().
+		Unstructured().
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
+		Flatten().
+		Do().Infos()
+	if err != nil {
+		return err
+	}
+
+	colored := color.Enabled(o.Out)
+	for i, info := range infos {
+		if i > 0 {
+			fmt.Fprintln(o.Out)
+		}
+		sent, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected object %T", info.Object)
+		}
+		helper := resource.NewHelper(info.Client, info.Mapping).
+			DryRun(true).
+			WithFieldManager(previewFieldManager)
+
+		var live, returned runtime.Object
+		live, err = helper.Get(info.Namespace, info.Name)
+		switch {
+		case apierrors.IsNotFound(err):
+			live = nil
+			returned, err = helper.Create(info.Namespace, true, sent)
+		case err == nil:
+			var data []byte
+			if data, err = runtime.Encode(unstructured.UnstructuredJSONScheme, sent); err != nil {
+				return err
+			}
+			force := true
+			returned, err = helper.Patch(info.Namespace, info.Name, types.ApplyPatchType, data, &metav1.PatchOptions{Force: &force})
+		}
+		if err != nil {
+			return cmdutil.AddSourceToErr("preview", info.Source, err)
+		}
+
+		changes, err := fieldChanges(sent, returned, live)
+		if err != nil {
+			return err
+		}
+		operation := "created"
+		if live != nil {
+			operation = "applied"
+		}
+		fmt.Fprintf(o.Out, "%s (%s, dry run)\n", info.ObjectName(), operation)
+		printChanges(o.Out, changes, colored)
+	}
+	return nil
+}
+
+// change is a field changed by the server.
+type change struct {
+	path     string
+	sent     string
+	returned string
+}
+
+// serverFields are the fields always set by the server, which are not
+// reported.
+var serverFields = []string{
+	"metadata.uid", "metadata.resourceVersion", "metadata.creationTimestamp", "metadata.generation",
+	"metadata.managedFields", "metadata.selfLink", "metadata.namespace", "status",
+}
+
+// fieldChanges returns the fields of the returned object which are not the
+// ones sent, sorted by path, ignoring the fields always set by the server and
+// the ones kept from the live object, if any.
+func fieldChanges(sent, returned, live runtime.Object) ([]change, error) {
+	sentFields, err := leafFields(sent)
+	if err != nil {
+		return nil, err
+	}
+	returnedFields, err := leafFields(returned)
+	if err != nil {
+		return nil, err
+	}
+	liveFields := map[string]string{}
+	if live != nil {
+		if liveFields, err = leafFields(live); err != nil {
+			return nil, err
+		}
+	}
+
+	paths := map[string]bool{}
+	for path := range sentFields {
+		paths[path] = true
+	}
+	for path := range returnedFields {
+		paths[path] = true
+	}
+	changes := []change{}
+	for path := range paths {
+		sentValue, wasSent := sentFields[path]
+		returnedValue, isReturned := returnedFields[path]
+		switch {
+		case isServerField(path), wasSent == isReturned && sentValue == returnedValue:
+			continue
+		case !wasSent && live != nil && liveFields[path] == returnedValue:
+			// kept from the live object
+			continue
+		}
+		changes = append(changes, change{path: path, sent: sentValue, returned: returnedValue})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].path < changes[j].path })
+	return changes, nil
+}
+
+func isServerField(path string) bool {
+	for _, field := range serverFields {
+		if path == field || strings.HasPrefix(path, field+".") || strings.HasPrefix(path, field+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// leafFields returns the JSON encoded values of the leaf fields of the
+// object by path, such as spec.containers[0].image.
+func leafFields(obj runtime.Object) (map[string]string, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]string{}
+	var walk func(path string, value interface{}) error
+	walk = func(path string, value interface{}) error {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if len(v) == 0 && len(path) > 0 {
+				fields[path] = "{}"
+			}
+			for key, child := range v {
+				childPath := key
+				if len(path) > 0 {
+					childPath = path + "." + key
+				}
+				if err := walk(childPath, child); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			if len(v) == 0 {
+				fields[path] = "[]"
+			}
+			for i, child := range v {
+				if err := walk(path+"["+strconv.Itoa(i)+"]", child); err != nil {
+					return err
+				}
+			}
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			fields[path] = string(data)
+		}
+		return nil
+	}
+	return fields, walk("", content)
+}
+
+func printChanges(out io.Writer, changes []change, colored bool) {
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "  no field changed by the server")
+		return
+	}
+	paint := func(role, line string) string {
+		if colored {
+			return color.Colorize(role, line)
+		}
+		return line
+	}
+	for _, c := range changes {
+		switch {
+		case len(c.sent) == 0:
+			fmt.Fprintln(out, paint(color.Added, fmt.Sprintf("  + %s: %s", c.path, c.returned)))
+		case len(c.returned) == 0:
+			fmt.Fprintln(out, paint(color.Removed, fmt.Sprintf("  - %s: %s", c.path, c.sent)))
+		default:
+			fmt.Fprintln(out, paint(color.Warning, fmt.Sprintf("  ~ %s: %s -> %s", c.path, c.sent, c.returned)))
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preview
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestPreview(t *testing.T) {
+	manifest := filepath.Join(t.TempDir(), "manifest.yaml")
+	err := os.WriteFile(manifest, []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: created
+  labels:
+    app: web
+data:
+  mode: debug
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: applied
+data:
+  mode: debug
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the server sets a label and the level, and changes the mode of the
+	// created config map, and keeps the live owner label of the applied one
+	created := `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "created", "namespace": "test", "uid": "1", "resourceVersion": "2",
+		"labels": {"app": "web", "injected": "true"}}, "data": {"mode": "info", "level": "1"}}`
+	live := `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "applied", "namespace": "test", "labels": {"owner": "ops"}}, "data": {"mode": "info"}}`
+	applied := `{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "applied", "namespace": "test", "labels": {"owner": "ops"}}, "data": {"mode": "debug"}}`
+
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			body := func(code int, data string) (*http.Response, error) {
+				return &http.Response{StatusCode: code, Header: cmdtesting.DefaultHeader(), Body: io.NopCloser(bytes.NewReader([]byte(data)))}, nil
+			}
+			if req.Method != "GET" && req.URL.Query().Get("dryRun") != "All" {
+				t.Fatalf("unexpected request without dry run: %s %s", req.Method, req.URL)
+			}
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/namespaces/test/configmaps/created" && m == "GET":
+				return body(http.StatusNotFound, `{"kind": "Status", "apiVersion": "v1", "status": "Failure", "reason": "NotFound", "code": 404}`)
+			case p == "/namespaces/test/configmaps" && m == "POST":
+				return body(http.StatusCreated, created)
+			case p == "/namespaces/test/configmaps/applied" && m == "GET":
+				return body(http.StatusOK, live)
+			case p == "/namespaces/test/configmaps/applied" && m == "PATCH":
+				return body(http.StatusOK, applied)
+			}
+			t.Fatalf("unexpected request: %s %s", req.Method, req.URL)
+			return nil, nil
+		}),
+	}
+
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+	o := NewPreviewOptions(streams)
+	o.FilenameOptions.Filenames = []string{manifest}
+	if err := o.Complete(tf, NewCmdPreview(tf, streams), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `configmaps/created (created, dry run)
+  + data.level: "1"
+  ~ data.mode: "debug" -> "info"
+  + metadata.labels.injected: "true"
+
+configmaps/applied (applied, dry run)
+  no field changed by the server
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/term"
+)
+
+// isTerminalIn returns true if the input is a terminal the confirmation can
+// be asked on.
+var isTerminalIn = func(in io.Reader) bool {
+	return term.TTY{In: in}.IsTerminalIn()
+}
+
+// addProtectedContextHooks asks for a confirmation before running a
+// destructive command against a protected context.
+func addProtectedContextHooks(cmds *cobra.Command, kubeConfigFlags *genericclioptions.ConfigFlags, streams genericiooptions.IOStreams) {
+	existingPreRunE := cmds.PersistentPreRunE
+	cmds.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := confirmProtectedContext(cmd, kubeConfigFlags, streams); err != nil {
+			return err
+		}
+		return existingPreRunE(cmd, args)
+	}
+}
+
+func confirmProtectedContext(cmd *cobra.Command, kubeConfigFlags *genericclioptions.ConfigFlags, streams genericiooptions.IOStreams) error {
+	operation, ok := destructiveOperation(cmd)
+	if !ok || cmdutil.GetFlagBool(cmd, "yes") {
+		return nil
+	}
+
+	config, err := kubeConfigFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		// the command reports the error of the kubeconfig
+		return nil
+	}
+	contextName := config.CurrentContext
+	if kubeConfigFlags.Context != nil && len(*kubeConfigFlags.Context) > 0 {
+		contextName = *kubeConfigFlags.Context
+	}
+	context, ok := config.Contexts[contextName]
+	if !ok || !cmdutil.IsProtectedContext(context) {
+		return nil
+	}
+
+	// the answer would be read from the manifests, or from a script
+	if readsStdin(cmd) {
+		return fmt.Errorf("the context %q is protected and the standard input is read by the command, use --yes", contextName)
+	}
+	if !isTerminalIn(streams.In) {
+		return fmt.Errorf("the context %q is protected and the standard input is not a terminal, use --yes", contextName)
+	}
+	fmt.Fprintf(streams.ErrOut, i18n.T("The context %q is protected. Do you want to %s?")+" (y/n): ", contextName, operation)
+	var input string
+	if _, err := fmt.Fscan(streams.In, &input); err != nil || !strings.EqualFold(input, "y") {
+		return fmt.Errorf("the context %q is protected, confirm the command or use --yes", contextName)
+	}
+	return nil
+}
+
+// readsStdin returns true if the command reads the manifests from the
+// standard input, with -f -.
+func readsStdin(cmd *cobra.Command) bool {
+	if cmd.Flags().Lookup("filename") == nil {
+		return false
+	}
+	filenames, err := cmd.Flags().GetStringSlice("filename")
+	if err != nil {
+		return false
+	}
+	for _, filename := range filenames {
+		if filename == "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// destructiveOperation returns the destructive operation the command
+// performs, if any. Commands doing a dry run are not destructive.
+func destructiveOperation(cmd *cobra.Command) (string, bool) {
+	if cmd.Flags().Lookup("yes") == nil {
+		return "", false
+	}
+	if cmd.Flags().Lookup("dry-run") != nil {
+		if dryRun, err := cmdutil.GetDryRunStrategy(cmd); err != nil || dryRun != cmdutil.DryRunNone {
+			return "", false
+		}
+	}
+	switch cmd.Name() {
+	case "delete":
+		return "delete resources", true
+	case "drain":
+		return "drain nodes", true
+	case "scale":
+		if replicas := cmd.Flags().Lookup("replicas"); replicas != nil {
+			if count, err := strconv.Atoi(replicas.Value.String()); err == nil && count == 0 {
+				return "scale resources to zero", true
+			}
+		}
+	case "apply":
+		if cmdutil.GetFlagBool(cmd, "prune") {
+			return "prune resources", true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const protectedContextKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: c
+  cluster:
+    server: https://example.com
+users:
+- name: u
+  user:
+    token: secret
+contexts:
+- name: dev
+  context:
+    cluster: c
+    user: u
+- name: prod
+  context:
+    cluster: c
+    user: u
+    extensions:
+    - name: kubectl.kubernetes.io/protected
+      extension: true
+current-context: prod
+`
+
+func TestProtectedContextHooks(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(protectedContextKubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		command        string
+		args           []string
+		context        string
+		input          string
+		notTerminal    bool
+		expectedPrompt string
+		expectedError  string
+	}{
+		{name: "confirmed", command: "delete", input: "y\n", expectedPrompt: `The context "prod" is protected. Do you want to delete resources? (y/n): `},
+		{name: "not confirmed", command: "delete", input: "n\n", expectedError: `the context "prod" is protected, confirm the command or use --yes`},
+		{name: "no input", command: "drain", expectedError: `the context "prod" is protected, confirm the command or use --yes`},
+		{name: "yes", command: "delete", args: []string{"--yes"}},
+		{name: "dry run", command: "delete", args: []string{"--dry-run=server"}},
+		{name: "not protected", command: "delete", context: "dev"},
+		{name: "scale to zero", command: "scale", args: []string{"--replicas=0"}, input: "y\n", expectedPrompt: `The context "prod" is protected. Do you want to scale resources to zero? (y/n): `},
+		{name: "scale to zero with leading zeros", command: "scale", args: []string{"--replicas=00"}, input: "y\n", expectedPrompt: `The context "prod" is protected. Do you want to scale resources to zero? (y/n): `},
+		{name: "scale", command: "scale", args: []string{"--replicas=3"}},
+		{name: "not a terminal", command: "delete", input: "y\n", notTerminal: true, expectedError: `the context "prod" is protected and the standard input is not a terminal, use --yes`},
+		{name: "manifests from stdin", command: "delete", args: []string{"-f", "-"}, input: "y\n", expectedError: `the context "prod" is protected and the standard input is read by the command, use --yes`},
+		{name: "manifests from stdin with yes", command: "apply", args: []string{"--prune", "-f", "-", "--yes"}},
+		{name: "apply", command: "apply"},
+		{name: "apply with prune", command: "apply", args: []string{"--prune"}, expectedError: `the context "prod" is protected, confirm the command or use --yes`},
+		{name: "not destructive", command: "get"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeConfigFlags := genericclioptions.NewConfigFlags(true)
+			kubeConfigFlags.KubeConfig = &kubeconfig
+			kubeConfigFlags.Context = &tc.context
+			streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+			streams.In = strings.NewReader(tc.input)
+			defer func(f func(io.Reader) bool) { isTerminalIn = f }(isTerminalIn)
+			isTerminalIn = func(io.Reader) bool { return !tc.notTerminal }
+
+			root := &cobra.Command{Use: "kubectl", PersistentPreRunE: func(*cobra.Command, []string) error { return nil }}
+			cmd := &cobra.Command{Use: tc.command}
+			if tc.command != "get" {
+				cmdutil.AddDryRunFlag(cmd)
+				cmdutil.AddConfirmationFlag(cmd)
+			}
+			cmd.Flags().Int("replicas", -1, "")
+			cmd.Flags().Bool("prune", false, "")
+			cmd.Flags().StringSliceP("filename", "f", nil, "")
+			root.AddCommand(cmd)
+			if err := cmd.ParseFlags(tc.args); err != nil {
+				t.Fatal(err)
+			}
+
+			addProtectedContextHooks(root, kubeConfigFlags, streams)
+			err := root.PersistentPreRunE(cmd, nil)
+			if len(tc.expectedError) > 0 {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if errOut.String() != tc.expectedPrompt {
+				t.Errorf("expected prompt %q, got %q", tc.expectedPrompt, errOut.String())
+			}
+		})
+	}
+}
@@ -52,6 +52,7 @@ type ProxyOptions struct {
 	disableFilter bool
 	unixSocket    string
 	keepalive     time.Duration
+	recordFile    string
 
 	appendServerPath bool
 
@@ -96,7 +97,13 @@ var (
 
 		# Run a proxy to the Kubernetes API server, changing the API prefix to k8s-api
 		# This makes e.g. the pods API available at localhost:8001/k8s-api/v1/pods/
-		kubectl proxy --api-prefix=/k8s-api`))
+		kubectl proxy --api-prefix=/k8s-api
+
+		# Run a proxy to the Kubernetes API server, recording all requests and responses to traffic.jsonl
+		kubectl proxy --record=traffic.jsonl
+
+		# Serve the responses recorded in traffic.jsonl without contacting an API server
+		kubectl proxy replay traffic.jsonl`))
 )
 
 // NewProxyOptions creates the options for proxy
@@ -144,6 +151,9 @@ func NewCmdProxy(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra
 	cmd.Flags().StringVarP(&o.unixSocket, "unix-socket", "u", o.unixSocket, "Unix socket on which to run the proxy.")
 	cmd.Flags().DurationVar(&o.keepalive, "keepalive", o.keepalive, "keepalive specifies the keep-alive period for an active network connection. Set to 0 to disable keepalive.")
 	cmd.Flags().BoolVar(&o.appendServerPath, "append-server-path", o.appendServerPath, "If true, enables automatic path appending of the kube context server path to each request.")
+	cmd.Flags().StringVar(&o.recordFile, "record", o.recordFile, "If set, record all proxied requests and responses to this file, one JSON document per line. Credentials, Secret data and service account tokens are redacted. The recording can be served with 'kubectl proxy replay'.")
+
+	cmd.AddCommand(NewCmdProxyReplay(ioStreams))
 	return cmd
 }
 
@@ -214,6 +224,15 @@ func (o ProxyOptions) RunProxy() error {
 		return err
 	}
 
+	if o.recordFile != "" {
+		recording, err := os.OpenFile(o.recordFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return err
+		}
+		defer recording.Close()
+		server.RecordTo(recording)
+	}
+
 	// Separate listening from serving so we can report the bound port
 	// when it is chosen by os (eg: port == 0)
 	var l net.Listener
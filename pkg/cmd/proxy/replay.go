@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/proxy"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// ReplayOptions have the data required to serve a recording made by kubectl proxy --record
+type ReplayOptions struct {
+	port       int
+	address    string
+	unixSocket string
+
+	records []proxy.Record
+
+	genericiooptions.IOStreams
+}
+
+var (
+	replayLong = templates.LongDesc(i18n.T(`
+		Serve the API responses recorded with 'kubectl proxy --record' without contacting
+		an API server.
+
+		Requests are matched to recorded responses by method and URL. When the same request was
+		recorded several times, the responses are served in the recorded order and the last one
+		is repeated afterwards. Requests without a recorded response are answered with 404 Not Found.`))
+
+	replayExample = templates.Examples(i18n.T(`
+		# Serve a recording on the default proxy port
+		kubectl proxy replay traffic.jsonl
+
+		# Serve a recording and point kubectl at it
+		kubectl proxy replay traffic.jsonl --port=8011 &
+		kubectl --server=http://localhost:8011 get pods`))
+)
+
+// NewReplayOptions creates the options for proxy replay
+func NewReplayOptions(ioStreams genericiooptions.IOStreams) *ReplayOptions {
+	return &ReplayOptions{
+		IOStreams: ioStreams,
+		port:      defaultPort,
+		address:   defaultAddress,
+	}
+}
+
+// NewCmdProxyReplay returns the proxy replay Cobra command
+func NewCmdProxyReplay(ioStreams genericiooptions.IOStreams) *cobra.Command {
+	o := NewReplayOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "replay FILE [--port=PORT]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Serve API responses recorded by kubectl proxy"),
+		Long:                  replayLong,
+		Example:               replayExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.RunReplay())
+		},
+	}
+
+	cmd.Flags().IntVarP(&o.port, "port", "p", o.port, "The port on which to serve the recording. Set to 0 to pick a random port.")
+	cmd.Flags().StringVar(&o.address, "address", o.address, "The IP address on which to serve on.")
+	cmd.Flags().StringVarP(&o.unixSocket, "unix-socket", "u", o.unixSocket, "Unix socket on which to serve the recording.")
+	return cmd
+}
+
+// Complete loads the recording named in args.
+func (o *ReplayOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmdutil.UsageErrorf(cmd, "exactly one recording FILE is required")
+	}
+	recording, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer recording.Close()
+	o.records, err = proxy.ReadRecords(recording)
+	if err != nil {
+		return fmt.Errorf("unable to read recording %s: %v", args[0], err)
+	}
+	return nil
+}
+
+// Validate checks to the ReplayOptions to see if there is sufficient information to run the command.
+func (o ReplayOptions) Validate() error {
+	if o.port != defaultPort && o.unixSocket != "" {
+		return errors.New("cannot set --unix-socket and --port at the same time")
+	}
+	if len(o.records) == 0 {
+		return errors.New("the recording does not contain any requests")
+	}
+	return nil
+}
+
+// RunReplay serves the recording until the process is stopped
+func (o ReplayOptions) RunReplay() error {
+	server := proxy.NewReplayServer(o.records)
+
+	var l net.Listener
+	var err error
+	if o.unixSocket == "" {
+		l, err = server.Listen(o.address, o.port)
+	} else {
+		l, err = server.ListenUnix(o.unixSocket)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.IOStreams.Out, "Replaying %d recorded requests on %s\n", len(o.records), l.Addr().String())
+	return server.ServeOnListener(l)
+}
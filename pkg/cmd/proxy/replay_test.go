@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestReplayOptions(t *testing.T) {
+	dir := t.TempDir()
+	writeRecording := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	valid := writeRecording("valid.jsonl", `{"time":"2024-01-01T00:00:00Z","method":"GET","url":"/api/v1/pods","statusCode":200,"responseBody":"{}"}`+"\n")
+	empty := writeRecording("empty.jsonl", "")
+	invalid := writeRecording("invalid.jsonl", "not a recording\n")
+
+	tests := []struct {
+		name              string
+		args              []string
+		unixSocket        string
+		port              int
+		expectedRecords   int
+		expectedCompleteE string
+		expectedValidateE string
+	}{
+		{
+			name:            "valid recording",
+			args:            []string{valid},
+			expectedRecords: 1,
+		},
+		{
+			name:              "no recording",
+			expectedCompleteE: "exactly one recording FILE is required",
+		},
+		{
+			name:              "missing recording",
+			args:              []string{filepath.Join(dir, "missing.jsonl")},
+			expectedCompleteE: "no such file or directory",
+		},
+		{
+			name:              "invalid recording",
+			args:              []string{invalid},
+			expectedCompleteE: "unable to read recording",
+		},
+		{
+			name:              "empty recording",
+			args:              []string{empty},
+			expectedValidateE: "the recording does not contain any requests",
+		},
+		{
+			name:              "port and unix socket",
+			args:              []string{valid},
+			port:              8011,
+			unixSocket:        "/tmp/replay.sock",
+			expectedRecords:   1,
+			expectedValidateE: "cannot set --unix-socket and --port at the same time",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ioStreams, _, _, _ := genericiooptions.NewTestIOStreams()
+			cmd := NewCmdProxyReplay(ioStreams)
+			o := NewReplayOptions(ioStreams)
+			if test.port != 0 {
+				o.port = test.port
+			}
+			o.unixSocket = test.unixSocket
+
+			err := o.Complete(cmd, test.args)
+			if test.expectedCompleteE != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedCompleteE) {
+					t.Fatalf("expected error %q, got %v", test.expectedCompleteE, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(o.records) != test.expectedRecords {
+				t.Errorf("expected %d records, got %d", test.expectedRecords, len(o.records))
+			}
+
+			err = o.Validate()
+			if test.expectedValidateE != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedValidateE) {
+					t.Fatalf("expected error %q, got %v", test.expectedValidateE, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,408 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	v1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kubectl/pkg/cmd/set"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	metricsServerSourceName = "metrics-server"
+
+	// minCPURequest and minMemoryRequest are the smallest requests
+	// recommended, for the containers which are mostly idle.
+	minCPURequest    = 10 // millicores
+	minMemoryRequest = 16 << 20
+)
+
+var (
+	resourcesLong = templates.LongDesc(i18n.T(`
+		Recommend the CPU and memory requests and limits of the containers of a workload
+		from their usage.
+
+		The usage of the containers of all the pods of the workload is read from
+		metrics-server, which is sampled every --sample-interval for the duration of
+		--window, 5m by default, or from the cAdvisor metrics recorded by the Prometheus
+		server of --prometheus-url over --window, 7d by default.
+
+		The CPU request recommended is the 95th percentile of the CPU usage, and the
+		memory request and limit are the peak of the memory working set, both increased
+		by --margin. No CPU limit is recommended, so that the containers can use the idle
+		CPU of their node.
+
+		The recommendation can be printed as a patch of the workload, or written with
+		-o yaml to a file to set with 'kubectl set resources --from-recommendation'.`))
+
+	resourcesExample = templates.Examples(i18n.T(`
+		# Recommend the resources of the containers of the deployment foo from a week of usage
+		kubectl recommend resources deployment/foo --prometheus-url=http://prometheus.monitoring:9090 --window=7d
+
+		# Recommend the resources of the containers of the deployment foo by sampling metrics-server for 10 minutes
+		kubectl recommend resources deployment/foo --window=10m
+
+		# Apply the recommendation
+		kubectl recommend resources deployment/foo -o yaml > foo-resources.yaml
+		kubectl set resources deployment/foo --from-recommendation=foo-resources.yaml
+
+		# Print the recommendation as a patch of the deployment
+		kubectl recommend resources deployment/foo -o patch`))
+)
+
+// ResourcesOptions holds the command-line options for 'recommend resources' sub command
+type ResourcesOptions struct {
+	PrometheusURL  string
+	Window         string
+	SampleInterval time.Duration
+	Margin         int
+	Output         string
+
+	info   *resource.Info
+	window time.Duration
+	source usageSource
+
+	genericiooptions.IOStreams
+}
+
+// NewResourcesOptions returns a ResourcesOptions with the default values of the flags
+func NewResourcesOptions(streams genericiooptions.IOStreams) *ResourcesOptions {
+	return &ResourcesOptions{
+		SampleInterval: 30 * time.Second,
+		Margin:         15,
+		IOStreams:      streams,
+	}
+}
+
+// NewCmdRecommendResources returns a Command instance for 'recommend resources' sub command
+func NewCmdRecommendResources(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewResourcesOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "resources (TYPE NAME | TYPE/NAME) [--prometheus-url=URL] [--window=DURATION] [-o yaml|json|patch]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Recommend the requests and limits of the containers of a workload from their usage"),
+		Long:                  resourcesLong,
+		Example:               resourcesExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.PrometheusURL, "prometheus-url", o.PrometheusURL, "The URL of a Prometheus server recording the cAdvisor metrics of the kubelets to read the usage from. The usage is sampled from metrics-server when empty.")
+	cmd.Flags().StringVar(&o.Window, "window", o.Window, "The duration to read the usage over, such as 30m or 7d. Defaults to 7d with --prometheus-url and to 5m with metrics-server.")
+	cmd.Flags().DurationVar(&o.SampleInterval, "sample-interval", o.SampleInterval, "The interval between the samples of the usage read from metrics-server.")
+	cmd.Flags().IntVar(&o.Margin, "margin", o.Margin, "The percentage the usage is increased by in the recommended requests and limits.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: (json, yaml, patch).")
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *ResourcesOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmdutil.UsageErrorf(cmd, "a workload is required, such as deployment/foo")
+	}
+
+	var err error
+	if len(o.Window) == 0 {
+		o.Window = "5m"
+		if len(o.PrometheusURL) > 0 {
+			o.Window = "7d"
+		}
+	}
+	if o.window, err = parseWindow(o.Window); err != nil {
+		return err
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	infos, err := f.NewBuilder().
+		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		NamespaceParam(namespace).DefaultNamespace().
+		ResourceTypeOrNameArgs(false, args...).
+		SingleResourceType().
+		Latest().
+		Do().Infos()
+	if err != nil {
+		return err
+	}
+	if len(infos) != 1 {
+		return fmt.Errorf("exactly one workload is required, got %d", len(infos))
+	}
+	o.info = infos[0]
+
+	if len(o.PrometheusURL) > 0 {
+		o.source = &prometheusSource{url: o.PrometheusURL, client: &http.Client{Timeout: time.Minute}, window: o.window}
+		return nil
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	config.ContentType = "application/vnd.kubernetes.protobuf"
+	metricsClient, err := metricsclientset.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.source = &metricsServerSource{client: metricsClient, window: o.window, interval: o.SampleInterval, sleep: time.Sleep}
+	return nil
+}
+
+// Validate makes sure that the options are valid
+func (o *ResourcesOptions) Validate() error {
+	if !sets.New("", "json", "yaml", "patch").Has(o.Output) {
+		return fmt.Errorf("--output %v is not available in kubectl recommend resources", o.Output)
+	}
+	if o.Margin < 0 {
+		return fmt.Errorf("--margin must not be negative")
+	}
+	if o.SampleInterval <= 0 {
+		return fmt.Errorf("--sample-interval must be positive")
+	}
+	if len(o.PrometheusURL) > 0 {
+		u, err := url.Parse(o.PrometheusURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return fmt.Errorf("invalid --prometheus-url %q, expected an http or https URL", o.PrometheusURL)
+		}
+	}
+	return nil
+}
+
+// Run performs the execution of 'recommend resources' sub command
+func (o *ResourcesOptions) Run() error {
+	name := o.info.ObjectName()
+	containers, err := podContainers(o.info.Object)
+	if err != nil {
+		return err
+	}
+	namespace, selector, err := polymorphichelpers.SelectorsForObject(o.info.Object)
+	if err != nil {
+		return fmt.Errorf("cannot find the pods of %s: %v", name, err)
+	}
+	pods := workloadPods{
+		namespace:   namespace,
+		selector:    selector,
+		namePattern: podNamePattern(o.info.Mapping.GroupVersionKind.Kind, o.info.Name),
+	}
+
+	recommendation := &cmdutil.ResourceRecommendation{
+		Target:    name,
+		Namespace: namespace,
+		Source:    metricsServerSourceName,
+		Window:    o.Window,
+	}
+	if len(o.PrometheusURL) > 0 {
+		u, _ := url.Parse(o.PrometheusURL)
+		recommendation.Source = u.Redacted()
+	} else if o.window > o.SampleInterval {
+		fmt.Fprintf(o.ErrOut, "Sampling the usage of the pods of %s every %s for %s...\n", name, o.SampleInterval, o.window)
+	}
+	usage, err := o.source.usage(context.TODO(), pods)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		if _, ok := usage[c.Name]; !ok {
+			fmt.Fprintf(o.ErrOut, "Warning: no usage found for the container %s of %s\n", c.Name, name)
+		}
+	}
+	recommendation.Containers = recommendResources(containers, usage, float64(o.Margin)/100)
+	if len(recommendation.Containers) == 0 {
+		return fmt.Errorf("no usage found for the containers of %s", name)
+	}
+
+	switch o.Output {
+	case "json":
+		data, err := json.MarshalIndent(recommendation, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+	case "yaml":
+		data, err := yaml.Marshal(recommendation)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(o.Out, string(data))
+	case "patch":
+		patch, err := recommendationPatch(o.info, recommendation)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(patch))
+	default:
+		printRecommendation(o.Out, containers, recommendation)
+	}
+	return nil
+}
+
+// podContainers returns the containers of the pod template of obj, including
+// the sidecar containers.
+func podContainers(obj runtime.Object) ([]v1.Container, error) {
+	containers := []v1.Container{}
+	_, err := polymorphichelpers.UpdatePodSpecForObjectFn(obj, func(spec *v1.PodSpec) error {
+		containers = append(containers, spec.Containers...)
+		for _, c := range spec.InitContainers {
+			if c.RestartPolicy != nil && *c.RestartPolicy == v1.ContainerRestartPolicyAlways {
+				containers = append(containers, c)
+			}
+		}
+		return nil
+	})
+	return containers, err
+}
+
+// recommendResources recommends the requests and limits of the containers
+// from their usage, increased by margin.
+func recommendResources(containers []v1.Container, usage map[string]containerUsage, margin float64) []cmdutil.ContainerRecommendation {
+	recommendations := []cmdutil.ContainerRecommendation{}
+	for _, c := range containers {
+		u, ok := usage[c.Name]
+		if !ok {
+			continue
+		}
+		cpu := int64(math.Ceil(u.cpu * 1000 * (1 + margin)))
+		if cpu < minCPURequest {
+			cpu = minCPURequest
+		}
+		memory := int64(math.Ceil(u.memory*(1+margin)/(1<<20))) << 20
+		if memory < minMemoryRequest {
+			memory = minMemoryRequest
+		}
+		memoryQuantity := *apiresource.NewQuantity(memory, apiresource.BinarySI)
+		recommendations = append(recommendations, cmdutil.ContainerRecommendation{
+			Name: c.Name,
+			Usage: v1.ResourceList{
+				v1.ResourceCPU:    *apiresource.NewMilliQuantity(int64(math.Ceil(u.cpu*1000)), apiresource.DecimalSI),
+				v1.ResourceMemory: *apiresource.NewQuantity(int64(math.Ceil(u.memory/1024))*1024, apiresource.BinarySI),
+			},
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceCPU:    *apiresource.NewMilliQuantity(cpu, apiresource.DecimalSI),
+					v1.ResourceMemory: memoryQuantity,
+				},
+				Limits: v1.ResourceList{
+					v1.ResourceMemory: memoryQuantity.DeepCopy(),
+				},
+			},
+		})
+	}
+	return recommendations
+}
+
+// recommendationPatch returns the strategic merge patch setting the
+// recommended resources on the workload of info.
+func recommendationPatch(info *resource.Info, recommendation *cmdutil.ResourceRecommendation) ([]byte, error) {
+	patch := &set.Patch{Info: info}
+	set.CalculatePatch(patch, scheme.DefaultJSONEncoder(), func(obj runtime.Object) ([]byte, error) {
+		_, err := polymorphichelpers.UpdatePodSpecForObjectFn(obj, func(spec *v1.PodSpec) error {
+			for i := range spec.Containers {
+				if recommended, ok := recommendation.Container(spec.Containers[i].Name); ok {
+					recommended.ApplyTo(&spec.Containers[i].Resources)
+				}
+			}
+			for i := range spec.InitContainers {
+				if recommended, ok := recommendation.Container(spec.InitContainers[i].Name); ok {
+					recommended.ApplyTo(&spec.InitContainers[i].Resources)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return runtime.Encode(scheme.DefaultJSONEncoder(), obj)
+	})
+	return patch.Patch, patch.Err
+}
+
+// printRecommendation prints the current and the recommended resources of
+// the containers as a table.
+func printRecommendation(out io.Writer, containers []v1.Container, recommendation *cmdutil.ResourceRecommendation) {
+	current := map[string]v1.ResourceRequirements{}
+	for _, c := range containers {
+		current[c.Name] = c.Resources
+	}
+	w := printers.GetNewTabWriter(out)
+	defer w.Flush()
+	fmt.Fprintln(w, "CONTAINER\tCPU (P95)\tMEMORY (MAX)\tREQUESTS\tLIMITS\tRECOMMENDED REQUESTS\tRECOMMENDED LIMITS")
+	for _, c := range recommendation.Containers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.Name,
+			c.Usage.Cpu(), c.Usage.Memory(),
+			formatResourceList(current[c.Name].Requests), formatResourceList(current[c.Name].Limits),
+			formatResourceList(c.Resources.Requests), formatResourceList(c.Resources.Limits))
+	}
+}
+
+func formatResourceList(list v1.ResourceList) string {
+	if len(list) == 0 {
+		return "<none>"
+	}
+	values := []string{}
+	for name, quantity := range list {
+		values = append(values, string(name)+"="+quantity.String())
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
+// parseWindow parses a duration, also accepting a number of days such as 7d.
+func parseWindow(value string) (time.Duration, error) {
+	var window time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int64
+		n, err = strconv.ParseInt(days, 10, 64)
+		window = time.Duration(n) * 24 * time.Hour
+	} else {
+		window, err = time.ParseDuration(value)
+	}
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("invalid --window %q, expected a positive duration such as 30m or 7d", value)
+	}
+	return window, nil
+}
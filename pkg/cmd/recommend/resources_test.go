@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func TestRecommendResources(t *testing.T) {
+	containers := []v1.Container{{Name: "app"}, {Name: "idle"}, {Name: "unknown"}}
+	usage := map[string]containerUsage{
+		"app":  {cpu: 0.2, memory: 200 << 20},
+		"idle": {cpu: 0.001, memory: 1 << 20},
+	}
+	recommendations := recommendResources(containers, usage, 0.15)
+	if len(recommendations) != 2 {
+		t.Fatalf("expected recommendations for the containers with usage only, got %v", recommendations)
+	}
+
+	expected := []struct {
+		name                      string
+		cpuUsage, memoryUsage     string
+		cpuRequest, memoryRequest string
+		memoryLimit               string
+	}{
+		{name: "app", cpuUsage: "200m", memoryUsage: "200Mi", cpuRequest: "230m", memoryRequest: "230Mi", memoryLimit: "230Mi"},
+		{name: "idle", cpuUsage: "1m", memoryUsage: "1Mi", cpuRequest: "10m", memoryRequest: "16Mi", memoryLimit: "16Mi"},
+	}
+	for i, e := range expected {
+		r := recommendations[i]
+		actual := []string{r.Name, r.Usage.Cpu().String(), r.Usage.Memory().String(), r.Resources.Requests.Cpu().String(), r.Resources.Requests.Memory().String(), r.Resources.Limits.Memory().String()}
+		wanted := []string{e.name, e.cpuUsage, e.memoryUsage, e.cpuRequest, e.memoryRequest, e.memoryLimit}
+		for j := range wanted {
+			if actual[j] != wanted[j] {
+				t.Errorf("expected %v, got %v", wanted, actual)
+				break
+			}
+		}
+		if _, ok := r.Resources.Limits[v1.ResourceCPU]; ok {
+			t.Errorf("expected no CPU limit for %s", r.Name)
+		}
+	}
+}
+
+func TestRecommendationPatch(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test"},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name: "app",
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{v1.ResourceCPU: apiresource.MustParse("1")},
+								Limits:   v1.ResourceList{v1.ResourceCPU: apiresource.MustParse("100m")},
+							},
+						},
+						{Name: "other"},
+					},
+				},
+			},
+		},
+	}
+	info := &resource.Info{
+		Name:      "foo",
+		Namespace: "test",
+		Object:    deployment,
+		Mapping:   &meta.RESTMapping{GroupVersionKind: appsv1.SchemeGroupVersion.WithKind("Deployment")},
+	}
+	recommendation := &cmdutil.ResourceRecommendation{
+		Containers: []cmdutil.ContainerRecommendation{{
+			Name: "app",
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: apiresource.MustParse("250m"), v1.ResourceMemory: apiresource.MustParse("64Mi")},
+				Limits:   v1.ResourceList{v1.ResourceMemory: apiresource.MustParse("64Mi")},
+			},
+		}},
+	}
+	patch, err := recommendationPatch(info, recommendation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the CPU limit lower than the recommended request is raised to it
+	expected := `{"spec":{"template":{"spec":{"$setElementOrder/containers":[{"name":"app"},{"name":"other"}],"containers":[{"name":"app","resources":{"limits":{"cpu":"250m","memory":"64Mi"},"requests":{"cpu":"250m","memory":"64Mi"}}}]}}}}`
+	if string(patch) != expected {
+		t.Errorf("expected patch:\n%s\ngot:\n%s", expected, patch)
+	}
+}
+
+func TestPrintRecommendation(t *testing.T) {
+	containers := []v1.Container{{
+		Name:      "app",
+		Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: apiresource.MustParse("1")}},
+	}}
+	recommendation := &cmdutil.ResourceRecommendation{
+		Containers: recommendResources(containers, map[string]containerUsage{"app": {cpu: 0.1, memory: 100 << 20}}, 0),
+	}
+	out := &bytes.Buffer{}
+	printRecommendation(out, containers, recommendation)
+	expected := `CONTAINER   CPU (P95)   MEMORY (MAX)   REQUESTS   LIMITS   RECOMMENDED REQUESTS    RECOMMENDED LIMITS
+app         100m        100Mi          cpu=1      <none>   cpu=100m,memory=100Mi   memory=100Mi
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := map[string]time.Duration{
+		"7d":    7 * 24 * time.Hour,
+		"90m":   90 * time.Minute,
+		"1h30m": 90 * time.Minute,
+	}
+	for value, expected := range tests {
+		window, err := parseWindow(value)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", value, err)
+		} else if window != expected {
+			t.Errorf("%s: expected %s, got %s", value, expected, window)
+		}
+	}
+	for _, value := range []string{"", "d", "-1d", "0s", "week"} {
+		if _, err := parseWindow(value); err == nil {
+			t.Errorf("expected %q to be invalid", value)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// containerUsage is the usage of a container over all the pods of a workload.
+type containerUsage struct {
+	// cpu is the 95th percentile of the CPU usage, in cores.
+	cpu float64
+	// memory is the peak of the memory working set, in bytes.
+	memory float64
+}
+
+// workloadPods identifies the pods of a workload.
+type workloadPods struct {
+	namespace string
+	selector  labels.Selector
+	// namePattern matches the names of the pods of the workload, including
+	// the pods which no longer exist.
+	namePattern string
+}
+
+// usageSource reads the usage of the containers of the pods of a workload,
+// by container name.
+type usageSource interface {
+	usage(ctx context.Context, pods workloadPods) (map[string]containerUsage, error)
+}
+
+// metricsServerSource samples the usage reported by the metrics API every
+// interval for the duration of window.
+type metricsServerSource struct {
+	client   metricsclientset.Interface
+	window   time.Duration
+	interval time.Duration
+	sleep    func(time.Duration)
+}
+
+func (s *metricsServerSource) usage(ctx context.Context, pods workloadPods) (map[string]containerUsage, error) {
+	cpu := map[string][]float64{}
+	memory := map[string]float64{}
+	samples := int(s.window / s.interval)
+	if samples < 1 {
+		samples = 1
+	}
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			s.sleep(s.interval)
+		}
+		metrics, err := s.client.MetricsV1beta1().PodMetricses(pods.namespace).List(ctx, metav1.ListOptions{LabelSelector: pods.selector.String()})
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range metrics.Items {
+			for _, c := range pod.Containers {
+				cpu[c.Name] = append(cpu[c.Name], c.Usage.Cpu().AsApproximateFloat64())
+				memory[c.Name] = math.Max(memory[c.Name], c.Usage.Memory().AsApproximateFloat64())
+			}
+		}
+	}
+
+	usage := map[string]containerUsage{}
+	for name, values := range cpu {
+		usage[name] = containerUsage{cpu: percentile(values, 0.95), memory: memory[name]}
+	}
+	return usage, nil
+}
+
+// prometheusSource queries the usage recorded by a Prometheus server
+// scraping the cAdvisor metrics of the kubelets over window.
+type prometheusSource struct {
+	url    string
+	client *http.Client
+	window time.Duration
+}
+
+func (s *prometheusSource) usage(ctx context.Context, pods workloadPods) (map[string]containerUsage, error) {
+	matchers := fmt.Sprintf(`namespace=%s,pod=~%s,container!="",container!="POD"`, strconv.Quote(pods.namespace), strconv.Quote(pods.namePattern))
+	window := fmt.Sprintf("%ds", int64(s.window.Seconds()))
+	cpu, err := s.query(ctx, fmt.Sprintf(`max by (container) (quantile_over_time(0.95, rate(container_cpu_usage_seconds_total{%s}[5m])[%s:1m]))`, matchers, window))
+	if err != nil {
+		return nil, err
+	}
+	memory, err := s.query(ctx, fmt.Sprintf(`max by (container) (max_over_time(container_memory_working_set_bytes{%s}[%s]))`, matchers, window))
+	if err != nil {
+		return nil, err
+	}
+
+	usage := map[string]containerUsage{}
+	for name, value := range cpu {
+		usage[name] = containerUsage{cpu: value, memory: memory[name]}
+	}
+	for name, value := range memory {
+		if _, ok := cpu[name]; !ok {
+			usage[name] = containerUsage{memory: value}
+		}
+	}
+	return usage, nil
+}
+
+// query runs an instant query returning a value by container.
+func (s *prometheusSource) query(ctx context.Context, query string) (map[string]float64, error) {
+	endpoint := strings.TrimSuffix(s.url, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	response := struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			Result []struct {
+				Metric map[string]string `json:"metric"`
+				Value  []interface{}     `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("unexpected response from Prometheus (%s): %v", resp.Status, err)
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("Prometheus query failed (%s): %s", resp.Status, response.Error)
+	}
+
+	values := map[string]float64{}
+	for _, sample := range response.Data.Result {
+		if len(sample.Value) != 2 {
+			return nil, fmt.Errorf("unexpected sample from Prometheus: %v", sample.Value)
+		}
+		text, _ := sample.Value[1].(string)
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected sample from Prometheus: %v", sample.Value)
+		}
+		if !math.IsNaN(value) {
+			values[sample.Metric["container"]] = value
+		}
+	}
+	return values, nil
+}
+
+// podNamePattern returns a regular expression matching the names of the pods
+// created for the workload of the given kind and name, from the suffixes the
+// controllers add to the name of the workload.
+func podNamePattern(kind, name string) string {
+	name = regexp.QuoteMeta(name)
+	switch kind {
+	case "Deployment":
+		return name + "-[a-z0-9]+-[a-z0-9]{5}"
+	case "StatefulSet":
+		return name + "-[0-9]+"
+	default:
+		return name + "-[a-z0-9]{5}"
+	}
+}
+
+// percentile returns the p-th percentile of values, using the nearest rank.
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+	metricsv1beta1api "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+func TestMetricsServerSource(t *testing.T) {
+	// the CPU usage of the container app in the successive samples of the
+	// two pods of the workload
+	cpu := [][]string{{"100m", "200m"}, {"300m", "150m"}, {"1", "50m"}}
+	memory := [][]string{{"100Mi", "120Mi"}, {"200Mi", "110Mi"}, {"90Mi", "130Mi"}}
+	sample := 0
+	client := &metricsfake.Clientset{}
+	client.AddReactor("list", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		if selector := action.(core.ListAction).GetListRestrictions().Labels.String(); selector != "app=foo" {
+			t.Errorf("unexpected selector %q", selector)
+		}
+		list := &metricsv1beta1api.PodMetricsList{}
+		for i := range cpu[sample] {
+			list.Items = append(list.Items, metricsv1beta1api.PodMetrics{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("foo-%d", i), Namespace: "test", Labels: map[string]string{"app": "foo"}},
+				Containers: []metricsv1beta1api.ContainerMetrics{{
+					Name: "app",
+					Usage: v1.ResourceList{
+						v1.ResourceCPU:    apiresource.MustParse(cpu[sample][i]),
+						v1.ResourceMemory: apiresource.MustParse(memory[sample][i]),
+					},
+				}},
+			})
+		}
+		sample++
+		return true, list, nil
+	})
+
+	slept := []time.Duration{}
+	source := &metricsServerSource{
+		client:   client,
+		window:   90 * time.Second,
+		interval: 30 * time.Second,
+		sleep:    func(d time.Duration) { slept = append(slept, d) },
+	}
+	usage, err := source.usage(context.TODO(), workloadPods{namespace: "test", selector: labels.SelectorFromSet(labels.Set{"app": "foo"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample != 3 || len(slept) != 2 {
+		t.Errorf("expected 3 samples 30s apart, got %d samples and sleeps %v", sample, slept)
+	}
+	expected := map[string]containerUsage{"app": {cpu: 1, memory: 200 << 20}}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("expected %v, got %v", expected, usage)
+	}
+}
+
+func TestPrometheusSource(t *testing.T) {
+	queries := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/prometheus/api/v1/query" {
+			t.Errorf("unexpected path %s", req.URL.Path)
+		}
+		query := req.URL.Query().Get("query")
+		queries = append(queries, query)
+		switch {
+		case strings.Contains(query, "container_cpu_usage_seconds_total"):
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"container":"app"},"value":[1700000000,"0.25"]},{"metric":{"container":"sidecar"},"value":[1700000000,"NaN"]}]}}`)
+		case strings.Contains(query, "container_memory_working_set_bytes"):
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"container":"app"},"value":[1700000000,"268435456"]},{"metric":{"container":"sidecar"},"value":[1700000000,"1048576"]}]}}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"unknown query"}`)
+		}
+	}))
+	defer server.Close()
+
+	source := &prometheusSource{url: server.URL + "/prometheus/", client: server.Client(), window: 7 * 24 * time.Hour}
+	usage, err := source.usage(context.TODO(), workloadPods{namespace: "test", namePattern: podNamePattern("Deployment", "foo")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]containerUsage{
+		"app":     {cpu: 0.25, memory: 256 << 20},
+		"sidecar": {memory: 1 << 20},
+	}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("expected %v, got %v", expected, usage)
+	}
+	expectedQueries := []string{
+		`max by (container) (quantile_over_time(0.95, rate(container_cpu_usage_seconds_total{namespace="test",pod=~"foo-[a-z0-9]+-[a-z0-9]{5}",container!="",container!="POD"}[5m])[604800s:1m]))`,
+		`max by (container) (max_over_time(container_memory_working_set_bytes{namespace="test",pod=~"foo-[a-z0-9]+-[a-z0-9]{5}",container!="",container!="POD"}[604800s]))`,
+	}
+	if !reflect.DeepEqual(queries, expectedQueries) {
+		t.Errorf("expected queries %q, got %q", expectedQueries, queries)
+	}
+
+	if _, err := source.query(context.TODO(), "up"); err == nil || !strings.Contains(err.Error(), "unknown query") {
+		t.Errorf("expected the error of Prometheus, got %v", err)
+	}
+}
+
+func TestPodNamePattern(t *testing.T) {
+	tests := []struct {
+		kind    string
+		name    string
+		pattern string
+	}{
+		{kind: "Deployment", name: "foo", pattern: "foo-[a-z0-9]+-[a-z0-9]{5}"},
+		{kind: "StatefulSet", name: "db.primary", pattern: `db\.primary-[0-9]+`},
+		{kind: "DaemonSet", name: "agent", pattern: "agent-[a-z0-9]{5}"},
+	}
+	for _, test := range tests {
+		if pattern := podNamePattern(test.kind, test.name); pattern != test.pattern {
+			t.Errorf("%s %s: expected %q, got %q", test.kind, test.name, test.pattern, pattern)
+		}
+	}
+}
@@ -0,0 +1,434 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	renderLong = templates.LongDesc(i18n.T(`
+		Render manifest templates with values, for simple parameterized YAML.
+
+		The values are read from the --values files in order, and overridden by --set.
+		Keys with dots set nested values. The templates can use the values in two ways:
+
+		* ${key} is replaced with the value of key, or with default for ${key:-default}.
+		  $${ is a literal ${.
+
+		* Go templates, such as {{ .image.tag }} or {{ if .debug }}...{{ end }}, with
+		  the functions default, quote, required, toYaml, toJson and indent.
+
+		Missing values are errors when they are printed, default and required can be
+		used for them. The ${key} values are not parsed as templates. The rendered manifests must be valid YAML or JSON,
+		and are written to the standard output, ready to be piped into kubectl apply.`))
+
+	renderExample = templates.Examples(i18n.T(`
+		# Render deployment.yaml with the values of values.yaml
+		kubectl render -f deployment.yaml --values=values.yaml
+
+		# Render the templates of a directory, overriding the image tag, and apply them
+		kubectl render -f templates/ --values=values.yaml --set image.tag=v1.2 | kubectl apply -f -`))
+)
+
+// envsubstPattern matches ${key} and ${key:-default}, and the escaped $${.
+var envsubstPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z0-9_.\-]+)(:-([^}]*))?\}`)
+
+// RenderOptions holds the options for 'render' command
+type RenderOptions struct {
+	Filenames  []string
+	Recursive  bool
+	ValueFiles []string
+	SetValues  []string
+
+	values map[string]interface{}
+
+	genericiooptions.IOStreams
+}
+
+// NewRenderOptions returns an initialized RenderOptions instance
+func NewRenderOptions(ioStreams genericiooptions.IOStreams) *RenderOptions {
+	return &RenderOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdRender returns a cobra command rendering manifest templates
+func NewCmdRender(ioStreams genericiooptions.IOStreams) *cobra.Command {
+	o := NewRenderOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "render -f FILENAME [--values=FILE] [--set KEY=VALUE]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Render manifest templates with values"),
+		Long:                  renderLong,
+		Example:               renderExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmdutil.AddJsonFilenameFlag(cmd.Flags(), &o.Filenames, "Filename or directory of the templates to render, - for the standard input.")
+	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "R", o.Recursive, "Process the directory used in -f, --filename recursively.")
+	cmd.Flags().StringArrayVar(&o.ValueFiles, "values", o.ValueFiles, "YAML or JSON file of values. Can be repeated, later files override earlier ones.")
+	cmd.Flags().StringArrayVar(&o.SetValues, "set", o.SetValues, "Value to set, as KEY=VALUE. Can be repeated, and overrides the values files.")
+
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *RenderOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmdutil.UsageErrorf(cmd, "Unexpected args: %v", args)
+	}
+
+	o.values = map[string]interface{}{}
+	for _, filename := range o.ValueFiles {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		values, err := parseValues(data)
+		if err != nil {
+			return fmt.Errorf("invalid values file %s: %v", filename, err)
+		}
+		mergeValues(o.values, values)
+	}
+	for _, value := range o.SetValues {
+		key, v, found := strings.Cut(value, "=")
+		if !found || len(key) == 0 {
+			return cmdutil.UsageErrorf(cmd, "invalid --set %q, must be KEY=VALUE", value)
+		}
+		if err := setValue(o.values, key, parseValue(v)); err != nil {
+			return fmt.Errorf("invalid --set %q: %v", value, err)
+		}
+	}
+	return nil
+}
+
+// Validate makes sure provided values for RenderOptions are valid
+func (o *RenderOptions) Validate() error {
+	if len(o.Filenames) == 0 {
+		return fmt.Errorf("must specify one of -f or --filename")
+	}
+	return nil
+}
+
+// Run renders the templates
+func (o *RenderOptions) Run() error {
+	files, err := o.templateFiles()
+	if err != nil {
+		return err
+	}
+	for i, filename := range files {
+		rendered, err := o.renderFile(filename)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(o.Out, "---")
+		}
+		if _, err := o.Out.Write(rendered); err != nil {
+			return err
+		}
+		if len(rendered) > 0 && rendered[len(rendered)-1] != '\n' {
+			fmt.Fprintln(o.Out)
+		}
+	}
+	return nil
+}
+
+// templateFiles returns the files of the filenames, with the YAML and JSON
+// files of the directories in alphabetical order.
+func (o *RenderOptions) templateFiles() ([]string, error) {
+	files := []string{}
+	for _, filename := range o.Filenames {
+		if filename == "-" {
+			files = append(files, filename)
+			continue
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, filename)
+			continue
+		}
+		dirFiles := []string{}
+		err = filepath.WalkDir(filename, func(path string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != filename && !o.Recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			for _, ext := range resource.FileExtensions {
+				if filepath.Ext(path) == ext {
+					dirFiles = append(dirFiles, path)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(dirFiles)
+		files = append(files, dirFiles...)
+	}
+	return files, nil
+}
+
+func (o *RenderOptions) renderFile(filename string) ([]byte, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(o.In)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := render(filename, string(data), o.values)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateYAML(rendered); err != nil {
+		return nil, fmt.Errorf("%s does not render to valid YAML or JSON: %v", filename, err)
+	}
+	return rendered, nil
+}
+
+// noValue is printed by the templates for the missing values.
+const noValue = "<no value>"
+
+// render executes the template as a Go template, with its ${key} references
+// replaced by actions printing their values, so that the values are never
+// parsed as template code. Missing values evaluate to nil for the functions,
+// such as default and required, and are errors when they are printed.
+func render(name, text string, values map[string]interface{}) ([]byte, error) {
+	var substErr error
+	substitutions := []string{}
+	text = envsubstPattern.ReplaceAllStringFunc(text, func(match string) string {
+		substitution := "${"
+		if match != "$${" {
+			groups := envsubstPattern.FindStringSubmatch(match)
+			value, found := lookupValue(values, groups[1])
+			switch {
+			case found:
+				substitution = formatValue(value)
+			case len(groups[2]) > 0:
+				substitution = groups[3]
+			default:
+				if substErr == nil {
+					substErr = fmt.Errorf("%s: no value for ${%s}", name, groups[1])
+				}
+				return match
+			}
+		}
+		substitutions = append(substitutions, substitution)
+		return fmt.Sprintf("{{ envsubst %d }}", len(substitutions)-1)
+	})
+	if substErr != nil {
+		return nil, substErr
+	}
+
+	tmpl, err := template.New(name).Funcs(funcMap).Funcs(template.FuncMap{
+		"envsubst": func(i int) string {
+			return substitutions[i]
+		},
+	}).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	out := &bytes.Buffer{}
+	if err := tmpl.Execute(out, values); err != nil {
+		return nil, err
+	}
+	if bytes.Contains(out.Bytes(), []byte(noValue)) {
+		return nil, fmt.Errorf("%s: a missing value is printed, set it or use default or required", name)
+	}
+	return out.Bytes(), nil
+}
+
+var funcMap = template.FuncMap{
+	"default": func(def, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return def
+		}
+		return value
+	},
+	"quote": func(value interface{}) string {
+		return strconv.Quote(formatValue(value))
+	},
+	"required": func(message string, value interface{}) (interface{}, error) {
+		if value == nil || value == "" {
+			return nil, fmt.Errorf("%s", message)
+		}
+		return value, nil
+	},
+	"toYaml": func(value interface{}) (string, error) {
+		data, err := yaml.Marshal(value)
+		return strings.TrimSuffix(string(data), "\n"), err
+	},
+	"toJson": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+	"indent": func(spaces int, text string) string {
+		padding := strings.Repeat(" ", spaces)
+		return padding + strings.ReplaceAll(text, "\n", "\n"+padding)
+	},
+}
+
+// validateYAML makes sure that each document of data parses.
+func validateYAML(data []byte) error {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var obj interface{}
+		if err := yaml.Unmarshal(document, &obj); err != nil {
+			return err
+		}
+	}
+}
+
+// lookupValue returns the value of a dotted key.
+func lookupValue(values map[string]interface{}, key string) (interface{}, bool) {
+	var current interface{} = values
+	for _, part := range strings.Split(key, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setValue sets the value of a dotted key, creating the intermediate maps.
+func setValue(values map[string]interface{}, key string, value interface{}) error {
+	parts := strings.Split(key, ".")
+	current := values
+	for i, part := range parts[:len(parts)-1] {
+		next, ok := current[part]
+		if !ok {
+			next = map[string]interface{}{}
+			current[part] = next
+		}
+		m, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not a map", strings.Join(parts[:i+1], "."))
+		}
+		current = m
+	}
+	current[parts[len(parts)-1]] = value
+	return nil
+}
+
+// parseValues parses YAML or JSON values, keeping the numbers as they are
+// written rather than as floats.
+func parseValues(data []byte) (map[string]interface{}, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	if string(data) == "null" {
+		return values, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// mergeValues merges src into dst, merging the maps they both have.
+func mergeValues(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// parseValue returns the boolean or integer a --set value is, or the string.
+func parseValue(s string) interface{} {
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	return s
+}
+
+// formatValue formats a value for ${key}, the nested values as JSON.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestRender(t *testing.T) {
+	values := `
+name: web
+replicas: 1000000
+image:
+  repository: nginx
+  tag: "1.25"
+labels:
+  tier: frontend
+`
+	tests := map[string]struct {
+		template    string
+		set         []string
+		expected    string
+		expectedErr string
+	}{
+		"envsubst": {
+			template: "name: ${name}\nreplicas: ${replicas}\nimage: ${image.repository}:${image.tag}\n",
+			expected: "name: web\nreplicas: 1000000\nimage: nginx:1.25\n",
+		},
+		"envsubst default and escape": {
+			template: "namespace: ${namespace:-default}\ncommand: echo $${HOME}\n",
+			expected: "namespace: default\ncommand: echo ${HOME}\n",
+		},
+		"go template": {
+			template: "name: {{ .name }}\n{{ if .debug }}debug: true\n{{ end }}labels:\n{{ toYaml .labels | indent 2 }}\ntag: {{ quote .image.tag }}\n",
+			set:      []string{"debug=true"},
+			expected: "name: web\ndebug: true\nlabels:\n  tier: frontend\ntag: \"1.25\"\n",
+		},
+		"set overrides values": {
+			template: "image: ${image.repository}:${image.tag}\n",
+			set:      []string{"image.tag=1.26"},
+			expected: "image: nginx:1.26\n",
+		},
+		"missing envsubst value": {
+			template:    "namespace: ${namespace}\n",
+			expectedErr: "no value for ${namespace}",
+		},
+		"missing template value": {
+			template:    "namespace: {{ .namespace }}\n",
+			expectedErr: "a missing value is printed",
+		},
+		"template default": {
+			template: "namespace: {{ .namespace | default \"prod\" }}\nname: {{ .name | default \"other\" }}\n",
+			expected: "namespace: prod\nname: web\n",
+		},
+		"template required": {
+			template:    "namespace: {{ required \"namespace is required\" .namespace }}\n",
+			expectedErr: "namespace is required",
+		},
+		"envsubst value is not a template": {
+			template: "command: echo ${command}\nname: {{ .name }}\n",
+			set:      []string{"command={{ .image.tag }}"},
+			expected: "command: echo {{ .image.tag }}\nname: web\n",
+		},
+		"invalid YAML": {
+			template:    "name: [${name}\n",
+			expectedErr: "does not render to valid YAML or JSON",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			valuesFile := filepath.Join(dir, "values.yaml")
+			if err := os.WriteFile(valuesFile, []byte(values), 0600); err != nil {
+				t.Fatal(err)
+			}
+			templateFile := filepath.Join(dir, "template.yaml")
+			if err := os.WriteFile(templateFile, []byte(tc.template), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			streams, _, out, _ := genericiooptions.NewTestIOStreams()
+			o := NewRenderOptions(streams)
+			o.Filenames = []string{templateFile}
+			o.ValueFiles = []string{valuesFile}
+			o.SetValues = tc.set
+			err := o.Complete(&cobra.Command{}, nil)
+			if err == nil {
+				err = o.Run()
+			}
+			if len(tc.expectedErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestRenderDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"b-service.yaml":        "kind: Service\nname: ${name}",
+		"a-deployment.yaml":     "kind: Deployment\nname: ${name}\n",
+		"README.md":             "not a template",
+		"nested/configmap.yaml": "kind: ConfigMap\nname: ${name}\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := map[string]struct {
+		recursive bool
+		expected  string
+	}{
+		"directory": {
+			expected: "kind: Deployment\nname: web\n---\nkind: Service\nname: web\n",
+		},
+		"recursive": {
+			recursive: true,
+			expected:  "kind: Deployment\nname: web\n---\nkind: Service\nname: web\n---\nkind: ConfigMap\nname: web\n",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			streams, _, out, _ := genericiooptions.NewTestIOStreams()
+			o := NewRenderOptions(streams)
+			o.Filenames = []string{dir}
+			o.Recursive = tc.recursive
+			o.SetValues = []string{"name=web"}
+			if err := o.Complete(&cobra.Command{}, nil); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
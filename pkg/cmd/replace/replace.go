@@ -29,10 +29,12 @@ import (
 	"k8s.io/klog/v2"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/kubectl/pkg/cmd/delete"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -52,7 +54,13 @@ var (
 		JSON and YAML formats are accepted. If replacing an existing resource, the
 		complete resource spec must be provided. This can be obtained by
 
-		    $ kubectl get TYPE NAME -o yaml`))
+		    $ kubectl get TYPE NAME -o yaml
+
+		With --force, the resources are deleted and then re-created. When a resource
+		can not be re-created, for instance because it fails validation or admission,
+		the deleted resource is restored. Fields assigned by the server that can not be
+		changed, such as the cluster IP of a service, can be carried forward to the
+		re-created resources with --preserve-fields.`))
 
 	replaceExample = templates.Examples(i18n.T(`
 		# Replace a pod using the data in pod.json
@@ -65,7 +73,10 @@ var (
 		kubectl get pod mypod -o yaml | sed 's/\(image: myimage\):.*$/\1:v4/' | kubectl replace -f -
 
 		# Force replace, delete and then re-create the resource
-		kubectl replace --force -f ./pod.json`))
+		kubectl replace --force -f ./pod.json
+
+		# Force replace a service, keeping its cluster IP
+		kubectl replace --force --preserve-fields=spec.clusterIP,spec.clusterIPs -f ./service.yaml`))
 )
 
 var supportedSubresources = []string{"status", "scale"}
@@ -96,6 +107,10 @@ type ReplaceOptions struct {
 
 	Subresource string
 
+	// PreserveFields are the fields carried forward from the deleted objects
+	// to the re-created ones with --force.
+	PreserveFields []string
+
 	genericiooptions.IOStreams
 
 	fieldManager string
@@ -137,6 +152,7 @@ func NewCmdReplace(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra
 	cmd.Flags().StringVar(&o.Raw, "raw", o.Raw, "Raw URI to PUT to the server.  Uses the transport specified by the kubeconfig file.")
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.fieldManager, "kubectl-replace")
 	cmdutil.AddSubresourceFlags(cmd, &o.Subresource, "If specified, replace will operate on the subresource of the requested object.", supportedSubresources...)
+	cmd.Flags().StringSliceVar(&o.PreserveFields, "preserve-fields", o.PreserveFields, "Comma-separated fields, such as spec.clusterIP, to carry forward from the deleted objects to the re-created objects that do not set them. Requires --force.")
 
 	return cmd
 }
@@ -227,6 +243,15 @@ func (o *ReplaceOptions) Validate() error {
 		return fmt.Errorf("--dry-run can not be used when --force is set")
 	}
 
+	if len(o.PreserveFields) > 0 && !o.DeleteOptions.ForceDeletion {
+		return fmt.Errorf("--preserve-fields must have --force specified")
+	}
+	for _, field := range o.PreserveFields {
+		if len(field) == 0 || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") || strings.Contains(field, "..") {
+			return fmt.Errorf("invalid --preserve-fields field %q, must be a dot-separated path such as spec.clusterIP", field)
+		}
+	}
+
 	if cmdutil.IsFilenameSliceEmpty(o.DeleteOptions.FilenameOptions.Filenames, o.DeleteOptions.FilenameOptions.Kustomize) {
 		return fmt.Errorf("must specify --filename to replace")
 	}
@@ -353,6 +378,27 @@ func (o *ReplaceOptions) forceReplace() error {
 		return err
 	}
 
+	// the live objects are kept to restore them when they can not be re-created
+	originals := map[string]*unstructured.Unstructured{}
+	err := r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := info.Get(); err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if obj, ok := info.Object.(*unstructured.Unstructured); ok {
+			originals[objectKey(info)] = obj.DeepCopy()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
 	if err := o.DeleteOptions.DeleteResult(r); err != nil {
 		return err
 	}
@@ -361,7 +407,7 @@ func (o *ReplaceOptions) forceReplace() error {
 	if timeout == 0 {
 		timeout = 5 * time.Minute
 	}
-	err := r.Visit(func(info *resource.Info, err error) error {
+	err = r.Visit(func(info *resource.Info, err error) error {
 		if err != nil {
 			return err
 		}
@@ -408,12 +454,20 @@ func (o *ReplaceOptions) forceReplace() error {
 			klog.V(4).Infof("error recording current command: %v", err)
 		}
 
-		obj, err := resource.NewHelper(info.Client, info.Mapping).
-			WithFieldManager(o.fieldManager).
-			WithFieldValidation(o.validationDirective).
-			Create(info.Namespace, true, info.Object)
+		original := originals[objectKey(info)]
+		if obj, ok := info.Object.(*unstructured.Unstructured); ok && original != nil {
+			if err := preserveFields(obj, original, o.PreserveFields); err != nil {
+				return err
+			}
+		}
+
+		helper := resource.NewHelper(info.Client, info.Mapping).WithFieldManager(o.fieldManager)
+		obj, err := helper.WithFieldValidation(o.validationDirective).Create(info.Namespace, true, info.Object)
 		if err != nil {
-			return err
+			if original == nil {
+				return err
+			}
+			return o.restore(helper, info, original, err)
 		}
 
 		count++
@@ -428,3 +482,57 @@ func (o *ReplaceOptions) forceReplace() error {
 	}
 	return nil
 }
+
+// restore re-creates the original object of info after its replacement
+// failed to be created with createErr. If the original object can not be
+// re-created either, it is saved to a file.
+func (o *ReplaceOptions) restore(helper *resource.Helper, info *resource.Info, original *unstructured.Unstructured, createErr error) error {
+	obj := original.DeepCopy()
+	for _, field := range []string{"resourceVersion", "uid", "creationTimestamp", "deletionTimestamp", "deletionGracePeriodSeconds", "generation", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	if _, err := helper.Create(info.Namespace, true, obj); err != nil {
+		filename, backupErr := writeBackup(original)
+		if backupErr != nil {
+			return fmt.Errorf("%v; the original object could not be restored: %v, nor saved: %v", createErr, err, backupErr)
+		}
+		return fmt.Errorf("%v; the original object could not be restored: %v, it was saved to %s", createErr, err, filename)
+	}
+	fmt.Fprintf(o.ErrOut, "%s could not be re-created, the original object was restored\n", info.ObjectName())
+	return createErr
+}
+
+// writeBackup saves the object to a temporary file and returns its name.
+func writeBackup(obj *unstructured.Unstructured) (string, error) {
+	file, err := os.CreateTemp("", "kubectl_replace_backup_*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	if err := (&printers.YAMLPrinter{}).PrintObj(obj, file); err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// preserveFields copies the fields that obj does not set from original.
+func preserveFields(obj, original *unstructured.Unstructured, fields []string) error {
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+		if _, found, _ := unstructured.NestedFieldNoCopy(obj.Object, path...); found {
+			continue
+		}
+		value, found, err := unstructured.NestedFieldCopy(original.Object, path...)
+		if err != nil || !found {
+			continue
+		}
+		if err := unstructured.SetNestedField(obj.Object, value, path...); err != nil {
+			return fmt.Errorf("preserving %s: %v", field, err)
+		}
+	}
+	return nil
+}
+
+func objectKey(info *resource.Info) string {
+	return info.Mapping.Resource.GroupResource().String() + "/" + info.Namespace + "/" + info.Name
+}
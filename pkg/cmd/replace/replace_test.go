@@ -17,15 +17,21 @@ limitations under the License.
 package replace
 
 import (
+	"encoding/json"
 	"net/http"
 	"strings"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/rest/fake"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 )
 
@@ -248,3 +254,84 @@ func TestForceReplaceObjectNotFound(t *testing.T) {
 		t.Errorf("unexpected output: %s", buf.String())
 	}
 }
+
+func TestForceReplaceRestore(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+
+	live := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "test", ResourceVersion: "10", UID: "1234"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.10", Ports: []corev1.ServicePort{{Port: 80}}},
+	}
+	deleted := false
+	created := []map[string]interface{}{}
+	tf.UnstructuredClient = &fake.RESTClient{
+		NegotiatedSerializer: resource.UnstructuredPlusDefaultContentConfig().NegotiatedSerializer,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/api/v1/namespaces/test" && m == http.MethodGet:
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &corev1.Namespace{})}, nil
+			case p == "/namespaces/test/services/frontend" && m == http.MethodGet:
+				if deleted {
+					return &http.Response{StatusCode: http.StatusNotFound, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.StringBody("")}, nil
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, live)}, nil
+			case p == "/namespaces/test/services/frontend" && m == http.MethodDelete:
+				deleted = true
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, live)}, nil
+			case p == "/namespaces/test/services" && m == http.MethodPost:
+				obj := map[string]interface{}{}
+				if err := json.NewDecoder(req.Body).Decode(&obj); err != nil {
+					t.Fatal(err)
+				}
+				created = append(created, obj)
+				if len(created) == 1 {
+					status := apierrors.NewInvalid(schema.GroupKind{Kind: "Service"}, "frontend", nil).Status()
+					return &http.Response{StatusCode: http.StatusUnprocessableEntity, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, &status)}, nil
+				}
+				deleted = false
+				return &http.Response{StatusCode: http.StatusCreated, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, live)}, nil
+			default:
+				t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+				return nil, nil
+			}
+		}),
+	}
+	streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+
+	fatalErr := ""
+	cmdutil.BehaviorOnFatal(func(str string, code int) {
+		fatalErr = str
+		panic(nil)
+	})
+	defer cmdutil.DefaultBehaviorOnFatal()
+
+	cmd := NewCmdReplace(tf, streams)
+	cmd.Flags().Set("filename", "../../../testdata/frontend-service.yaml")
+	cmd.Flags().Set("force", "true")
+	cmd.Flags().Set("preserve-fields", "spec.clusterIP,spec.clusterIPs")
+	func() {
+		defer func() { recover() }()
+		cmd.Run(cmd, []string{})
+	}()
+
+	if !strings.Contains(fatalErr, `Service "frontend" is invalid`) {
+		t.Errorf("expected the creation error, got %q", fatalErr)
+	}
+	if errOut.String() != "services/frontend could not be re-created, the original object was restored\n" {
+		t.Errorf("unexpected error output: %q", errOut.String())
+	}
+	if len(created) != 2 {
+		t.Fatalf("expected the object to be created and then restored, got %d creations", len(created))
+	}
+	if clusterIP, _, _ := unstructured.NestedString(created[0], "spec", "clusterIP"); clusterIP != "10.0.0.10" {
+		t.Errorf("expected the cluster IP to be preserved, got %q", clusterIP)
+	}
+	if _, found, _ := unstructured.NestedString(created[1], "metadata", "resourceVersion"); found {
+		t.Errorf("expected the restored object to have no resource version")
+	}
+	if clusterIP, _, _ := unstructured.NestedString(created[1], "spec", "clusterIP"); clusterIP != "10.0.0.10" {
+		t.Errorf("expected the original object to be restored, got cluster IP %q", clusterIP)
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	requestPriorityNormal = "normal"
+	requestPriorityLow    = "low"
+
+	// lowPriorityQPS and lowPriorityBurst are the client-side rate limits
+	// of the requests of low priority, below the defaults of client-go.
+	lowPriorityQPS   = 2
+	lowPriorityBurst = 4
+)
+
+var (
+	requestPriority string
+	requestQPS      float32
+	requestBurst    int
+)
+
+func addRequestPriorityFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&requestPriority, "request-priority", requestPriorityNormal, "The priority of the requests made to the server. One of (normal|low). low lowers the default --qps and --burst, for bulk background scripts not to compete with interactive clients, and adds priority/low to the user agent of the requests.")
+	flags.Float32Var(&requestQPS, "qps", 0, "The maximum number of requests per second made to the server. 0 uses the default of --request-priority, a negative value disables the client-side rate limiting.")
+	flags.IntVar(&requestBurst, "burst", 0, "The maximum number of requests made to the server in a burst above --qps. 0 uses the default of --request-priority.")
+}
+
+// addRequestPriorityHooks validates the priority flags, and sets the rate
+// limits and the user agent of the REST configs of the flags accordingly.
+func addRequestPriorityHooks(cmds *cobra.Command, kubeConfigFlags *genericclioptions.ConfigFlags) {
+	existingPreRunE := cmds.PersistentPreRunE
+	cmds.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := validateRequestPriority(); err != nil {
+			return err
+		}
+		return existingPreRunE(cmd, args)
+	}
+
+	wrapConfigFn := kubeConfigFlags.WrapConfigFn
+	kubeConfigFlags.WrapConfigFn = func(c *rest.Config) *rest.Config {
+		if wrapConfigFn != nil {
+			c = wrapConfigFn(c)
+		}
+		applyRequestPriority(c)
+		return c
+	}
+}
+
+func validateRequestPriority() error {
+	switch requestPriority {
+	case requestPriorityNormal, requestPriorityLow:
+	default:
+		return fmt.Errorf("unknown --request-priority '%s', must be one of (normal|low)", requestPriority)
+	}
+	if requestBurst < 0 {
+		return fmt.Errorf("--burst must not be negative")
+	}
+	return nil
+}
+
+func applyRequestPriority(c *rest.Config) {
+	if requestPriority == requestPriorityLow {
+		c.QPS, c.Burst = lowPriorityQPS, lowPriorityBurst
+		userAgent := c.UserAgent
+		if len(userAgent) == 0 {
+			userAgent = rest.DefaultKubernetesUserAgent()
+		}
+		c.UserAgent = userAgent + " priority/" + requestPriorityLow
+	}
+	if requestQPS != 0 {
+		c.QPS = requestQPS
+	}
+	if requestBurst > 0 {
+		c.Burst = requestBurst
+	}
+}
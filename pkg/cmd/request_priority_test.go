@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestApplyRequestPriority(t *testing.T) {
+	tests := []struct {
+		name            string
+		priority        string
+		qps             float32
+		burst           int
+		expectedQPS     float32
+		expectedBurst   int
+		expectUserAgent bool
+		expectedError   string
+	}{
+		{name: "default", priority: requestPriorityNormal},
+		{name: "low", priority: requestPriorityLow, expectedQPS: lowPriorityQPS, expectedBurst: lowPriorityBurst, expectUserAgent: true},
+		{name: "low with overrides", priority: requestPriorityLow, qps: 10, burst: 20, expectedQPS: 10, expectedBurst: 20, expectUserAgent: true},
+		{name: "rate limiting disabled", priority: requestPriorityNormal, qps: -1, expectedQPS: -1},
+		{name: "unknown priority", priority: "high", expectedError: "unknown --request-priority 'high', must be one of (normal|low)"},
+		{name: "negative burst", priority: requestPriorityNormal, burst: -1, expectedError: "--burst must not be negative"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			requestPriority, requestQPS, requestBurst = tc.priority, tc.qps, tc.burst
+			defer func() { requestPriority, requestQPS, requestBurst = requestPriorityNormal, 0, 0 }()
+
+			err := validateRequestPriority()
+			if len(tc.expectedError) > 0 {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			c := &rest.Config{}
+			applyRequestPriority(c)
+			if c.QPS != tc.expectedQPS || c.Burst != tc.expectedBurst {
+				t.Errorf("expected qps %v and burst %d, got %v and %d", tc.expectedQPS, tc.expectedBurst, c.QPS, c.Burst)
+			}
+			if hasPriority := strings.HasSuffix(c.UserAgent, " priority/low"); hasPriority != tc.expectUserAgent {
+				t.Errorf("unexpected user agent %q", c.UserAgent)
+			}
+		})
+	}
+}
@@ -0,0 +1,488 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/metrics"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+var (
+	profileRequests       string
+	profileRequestsOutput string
+
+	// requestProfiler records the requests when --profile-requests is set.
+	requestProfiler *requestProfile
+
+	// the client metrics can only be registered once, the waits of the rate
+	// limiter are forwarded to the profile of the running command.
+	registerRequestProfilingMetrics sync.Once
+)
+
+// rateLimiterObserver records the waits for the client-side rate limiter in
+// the current profile.
+type rateLimiterObserver struct{}
+
+func (rateLimiterObserver) Observe(ctx context.Context, verb string, u url.URL, latency time.Duration) {
+	if p := requestProfiler; p != nil {
+		p.Observe(ctx, verb, u, latency)
+	}
+}
+
+func addRequestProfilingFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&profileRequests, "profile-requests", "none", "Record the requests made to the server and report them when the command ends. One of (none|summary|otlp). summary prints the requests, the time spent in discovery, client-side throttling and retries to the standard error, otlp writes the requests as OpenTelemetry spans to --profile-requests-output.")
+	flags.StringVar(&profileRequestsOutput, "profile-requests-output", "requests.json", "Name of the file to write the requests to, in the OTLP JSON format, with --profile-requests=otlp")
+}
+
+// addRequestProfilingHooks records the requests made with the REST configs
+// of the flags when --profile-requests is set.
+func addRequestProfilingHooks(kubeConfigFlags *genericclioptions.ConfigFlags) {
+	wrapConfigFn := kubeConfigFlags.WrapConfigFn
+	kubeConfigFlags.WrapConfigFn = func(c *rest.Config) *rest.Config {
+		if wrapConfigFn != nil {
+			c = wrapConfigFn(c)
+		}
+		if requestProfiler != nil {
+			c.Wrap(requestProfiler.wrapTransport)
+		}
+		return c
+	}
+}
+
+// addRequestProfilingExitHooks writes the profile when the commands return an
+// error or exit on a fatal error, which skips the post-run hooks.
+func addRequestProfilingExitHooks(cmds *cobra.Command, errOut io.Writer) {
+	cmdutil.WrapBehaviorOnFatal(func(f func(string, int)) func(string, int) {
+		return func(msg string, code int) {
+			flushRequestProfiling(errOut)
+			f(msg, code)
+		}
+	})
+	wrapRunEWithRequestProfiling(cmds, errOut)
+}
+
+func wrapRunEWithRequestProfiling(cmd *cobra.Command, errOut io.Writer) {
+	if runE := cmd.RunE; runE != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			err := runE(cmd, args)
+			if err != nil {
+				flushRequestProfiling(errOut)
+			}
+			return err
+		}
+	}
+	for _, c := range cmd.Commands() {
+		wrapRunEWithRequestProfiling(c, errOut)
+	}
+}
+
+func initRequestProfiling(command string) error {
+	switch profileRequests {
+	case "none":
+		return nil
+	case "summary", "otlp":
+	default:
+		return fmt.Errorf("unknown --profile-requests '%s', must be one of (none|summary|otlp)", profileRequests)
+	}
+	requestProfiler = newRequestProfile(command, time.Now)
+	registerRequestProfilingMetrics.Do(func() {
+		metrics.Register(metrics.RegisterOpts{RateLimiterLatency: rateLimiterObserver{}})
+	})
+	return nil
+}
+
+// flushRequestProfiling writes the profile of the command, once.
+func flushRequestProfiling(errOut io.Writer) error {
+	p := requestProfiler
+	if p == nil {
+		return nil
+	}
+	requestProfiler = nil
+	switch profileRequests {
+	case "summary":
+		return p.writeSummary(errOut)
+	case "otlp":
+		f, err := os.Create(profileRequestsOutput)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return p.writeOTLP(f)
+	}
+	return nil
+}
+
+// requestRecord is a request made to the server. A request resent after a
+// response with a Retry-After header is recorded again, with the number of
+// the attempt.
+type requestRecord struct {
+	method  string
+	url     *url.URL
+	attempt int
+	status  int
+	err     error
+	start   time.Time
+	// end is when the body of the response was read, it is zero while it is
+	// being read.
+	end   time.Time
+	bytes int64
+}
+
+// throttleRecord is a wait of a request for the client-side rate limiter.
+// The rate limiter reports the path of the request with its names replaced
+// by placeholders.
+type throttleRecord struct {
+	method  string
+	path    string
+	start   time.Time
+	latency time.Duration
+}
+
+// requestProfile records the requests made by a command.
+type requestProfile struct {
+	command string
+	now     func() time.Time
+	start   time.Time
+
+	lock      sync.Mutex
+	requests  []*requestRecord
+	throttles []throttleRecord
+	// retryAfter counts the attempts of the requests answered with a
+	// Retry-After header, which the REST client resends.
+	retryAfter map[string]int
+}
+
+func newRequestProfile(command string, now func() time.Time) *requestProfile {
+	return &requestProfile{
+		command:    command,
+		now:        now,
+		start:      now(),
+		retryAfter: map[string]int{},
+	}
+}
+
+// Observe records the time a request waited for the client-side rate limiter.
+func (p *requestProfile) Observe(_ context.Context, verb string, u url.URL, latency time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.throttles = append(p.throttles, throttleRecord{method: verb, path: u.Path, start: p.now().Add(-latency), latency: latency})
+}
+
+func (p *requestProfile) wrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &profilingRoundTripper{profile: p, delegate: rt}
+}
+
+type profilingRoundTripper struct {
+	profile  *requestProfile
+	delegate http.RoundTripper
+}
+
+func (rt *profilingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := rt.profile
+	key := req.Method + " " + req.URL.String()
+	p.lock.Lock()
+	record := &requestRecord{method: req.Method, url: req.URL, attempt: p.retryAfter[key], start: p.now()}
+	p.requests = append(p.requests, record)
+	p.lock.Unlock()
+
+	resp, err := rt.delegate.RoundTrip(req)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err != nil {
+		record.err = err
+		record.end = p.now()
+		return resp, err
+	}
+	record.status = resp.StatusCode
+	if len(resp.Header.Get("Retry-After")) > 0 {
+		p.retryAfter[key]++
+	} else {
+		delete(p.retryAfter, key)
+	}
+	resp.Body = &profilingBody{profile: p, record: record, delegate: resp.Body}
+	return resp, nil
+}
+
+// profilingBody records the size of a response, and the time it was read at.
+type profilingBody struct {
+	profile  *requestProfile
+	record   *requestRecord
+	delegate io.ReadCloser
+}
+
+func (b *profilingBody) Read(data []byte) (int, error) {
+	n, err := b.delegate.Read(data)
+	b.profile.lock.Lock()
+	defer b.profile.lock.Unlock()
+	b.record.bytes += int64(n)
+	if err != nil && b.record.end.IsZero() {
+		b.record.end = b.profile.now()
+	}
+	return n, err
+}
+
+func (b *profilingBody) Close() error {
+	b.profile.lock.Lock()
+	if b.record.end.IsZero() {
+		b.record.end = b.profile.now()
+	}
+	b.profile.lock.Unlock()
+	return b.delegate.Close()
+}
+
+// latency returns the latency of the request, up to now for the requests
+// whose response is still being read, such as watches.
+func (r *requestRecord) latency(now time.Time) time.Duration {
+	if r.end.IsZero() {
+		return now.Sub(r.start)
+	}
+	return r.end.Sub(r.start)
+}
+
+// isDiscovery returns whether the path is a path of the discovery of the
+// resources or of their OpenAPI schema.
+func isDiscovery(path string) bool {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch segments[0] {
+	case "api":
+		return len(segments) <= 2
+	case "apis":
+		return len(segments) <= 3
+	case "openapi":
+		return true
+	}
+	return false
+}
+
+func (p *requestProfile) writeSummary(out io.Writer) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.now()
+
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintln(w, "METHOD\tPATH\tSTATUS\tLATENCY\tBYTES\tATTEMPT")
+	var total, discovery time.Duration
+	discoveryCount, retries := 0, 0
+	for _, r := range p.requests {
+		status := strconv.Itoa(r.status)
+		if r.err != nil {
+			status = "error"
+		}
+		latency := r.latency(now)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\n", r.method, r.url.Path, status, latency.Round(time.Millisecond), r.bytes, r.attempt+1)
+		total += latency
+		if isDiscovery(r.url.Path) {
+			discoveryCount++
+			discovery += latency
+		}
+		if r.attempt > 0 {
+			retries++
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var throttled time.Duration
+	throttledCount := 0
+	for _, t := range p.throttles {
+		// waits of less than a millisecond are the rate limiter itself
+		if t.latency >= time.Millisecond {
+			throttledCount++
+			throttled += t.latency
+		}
+	}
+	fmt.Fprintf(out, "\n%d requests took %s in total, the command ran for %s.\n", len(p.requests), total.Round(time.Millisecond), now.Sub(p.start).Round(time.Millisecond))
+	fmt.Fprintf(out, "Discovery: %d requests took %s.\n", discoveryCount, discovery.Round(time.Millisecond))
+	fmt.Fprintf(out, "Client-side throttling: %d requests waited %s.\n", throttledCount, throttled.Round(time.Millisecond))
+	fmt.Fprintf(out, "Retries: %d requests were resent after a Retry-After response.\n", retries)
+	return nil
+}
+
+// writeOTLP writes the requests and the throttling waits as spans of a trace
+// of the command, in the OTLP JSON format of OpenTelemetry.
+func (p *requestProfile) writeOTLP(out io.Writer) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.now()
+
+	traceID := randomHex(16)
+	rootID := randomHex(8)
+	spans := []otlpSpan{{
+		TraceID:           traceID,
+		SpanID:            rootID,
+		Name:              p.command,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: unixNano(p.start),
+		EndTimeUnixNano:   unixNano(now),
+	}}
+	for _, r := range p.requests {
+		attributes := []otlpAttribute{
+			stringAttribute("http.request.method", r.method),
+			stringAttribute("url.full", r.url.String()),
+			intAttribute("http.response.body.size", r.bytes),
+		}
+		if r.attempt > 0 {
+			attributes = append(attributes, intAttribute("http.request.resend_count", int64(r.attempt)))
+		}
+		span := otlpSpan{
+			TraceID:           traceID,
+			SpanID:            randomHex(8),
+			ParentSpanID:      rootID,
+			Name:              r.method + " " + r.url.Path,
+			Kind:              otlpSpanKindClient,
+			StartTimeUnixNano: unixNano(r.start),
+			EndTimeUnixNano:   unixNano(r.start.Add(r.latency(now))),
+		}
+		if r.err != nil {
+			attributes = append(attributes, stringAttribute("error.type", r.err.Error()))
+			span.Status = &otlpStatus{Code: otlpStatusCodeError, Message: r.err.Error()}
+		} else {
+			attributes = append(attributes, intAttribute("http.response.status_code", int64(r.status)))
+			if r.status >= 400 {
+				span.Status = &otlpStatus{Code: otlpStatusCodeError}
+			}
+		}
+		span.Attributes = attributes
+		spans = append(spans, span)
+	}
+	for _, t := range p.throttles {
+		if t.latency < time.Millisecond {
+			continue
+		}
+		spans = append(spans, otlpSpan{
+			TraceID:           traceID,
+			SpanID:            randomHex(8),
+			ParentSpanID:      rootID,
+			Name:              "client-side throttling " + t.method + " " + t.path,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: unixNano(t.start),
+			EndTimeUnixNano:   unixNano(t.start.Add(t.latency)),
+		})
+	}
+
+	data := otlpTraces{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: []otlpAttribute{stringAttribute("service.name", "kubectl")}},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "k8s.io/kubectl"},
+			Spans: spans,
+		}},
+	}}}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// The types of the OTLP JSON format, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+const (
+	otlpSpanKindInternal = 1
+	otlpSpanKindClient   = 3
+
+	otlpStatusCodeError = 2
+)
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	// IntValue is a string, as 64-bit integers are in JSON.
+	IntValue *string `json:"intValue,omitempty"`
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+func intAttribute(key string, value int64) otlpAttribute {
+	s := strconv.FormatInt(value, 10)
+	return otlpAttribute{Key: key, Value: otlpValue{IntValue: &s}}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func randomHex(n int) string {
+	id := make([]byte, n)
+	// the IDs only need to be unique within the trace
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/metrics"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+type fakeRoundTripper func(*http.Request) (*http.Response, error)
+
+func (f fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRequestProfile(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time {
+		now = now.Add(10 * time.Millisecond)
+		return now
+	}
+	p := newRequestProfile("kubectl get", clock)
+
+	attempts := 0
+	rt := p.wrapTransport(fakeRoundTripper(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/api":
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"versions":["v1"]}`))}, nil
+		case "/api/v1/namespaces/test/pods":
+			attempts++
+			if attempts == 1 {
+				return &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"1"}}, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"items":[]}`))}, nil
+		}
+		return nil, errors.New("connection refused")
+	}))
+	for _, path := range []string{"/api", "/api/v1/namespaces/test/pods", "/api/v1/namespaces/test/pods", "/apis/apps/v1/namespaces/test/deployments/web"} {
+		req, _ := http.NewRequest("GET", "https://example.com"+path, nil)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			continue
+		}
+		if _, err := io.ReadAll(resp.Body); err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	p.Observe(context.TODO(), "GET", url.URL{Path: "/api/v1/namespaces/{namespace}/pods"}, 2*time.Second)
+	p.Observe(context.TODO(), "GET", url.URL{Path: "/api"}, time.Microsecond)
+
+	out := &bytes.Buffer{}
+	if err := p.writeSummary(out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `METHOD   PATH                                            STATUS   LATENCY   BYTES   ATTEMPT
+GET      /api                                            200      10ms      19      1
+GET      /api/v1/namespaces/test/pods                    429      10ms      0       1
+GET      /api/v1/namespaces/test/pods                    200      10ms      12      2
+GET      /apis/apps/v1/namespaces/test/deployments/web   error    10ms      0       1
+
+4 requests took 40ms in total, the command ran for 110ms.
+Discovery: 1 requests took 10ms.
+Client-side throttling: 1 requests waited 2s.
+Retries: 1 requests were resent after a Retry-After response.
+`
+	if out.String() != expected {
+		t.Errorf("expected summary\n%s\ngot\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	if err := p.writeOTLP(out); err != nil {
+		t.Fatal(err)
+	}
+	traces := otlpTraces{}
+	if err := json.Unmarshal(out.Bytes(), &traces); err != nil {
+		t.Fatal(err)
+	}
+	spans := traces.ResourceSpans[0].ScopeSpans[0].Spans
+	names := []string{}
+	for _, span := range spans {
+		names = append(names, span.Name)
+		if span.TraceID != spans[0].TraceID {
+			t.Errorf("expected the spans to be in the same trace, got %s and %s", span.TraceID, spans[0].TraceID)
+		}
+	}
+	expectedNames := []string{
+		"kubectl get",
+		"GET /api",
+		"GET /api/v1/namespaces/test/pods",
+		"GET /api/v1/namespaces/test/pods",
+		"GET /apis/apps/v1/namespaces/test/deployments/web",
+		"client-side throttling GET /api/v1/namespaces/{namespace}/pods",
+	}
+	if strings.Join(names, "\n") != strings.Join(expectedNames, "\n") {
+		t.Errorf("expected spans %v, got %v", expectedNames, names)
+	}
+	if spans[4].Status == nil || spans[4].Status.Code != otlpStatusCodeError {
+		t.Errorf("expected the failed request to have an error status, got %v", spans[4].Status)
+	}
+}
+
+func TestIsDiscovery(t *testing.T) {
+	for path, expected := range map[string]bool{
+		"/api":                         true,
+		"/api/v1":                      true,
+		"/apis":                        true,
+		"/apis/apps":                   true,
+		"/apis/apps/v1":                true,
+		"/openapi/v3/apis/apps/v1":     true,
+		"/api/v1/pods":                 false,
+		"/apis/apps/v1/deployments":    false,
+		"/api/v1/namespaces/test/pods": false,
+	} {
+		if isDiscovery(path) != expected {
+			t.Errorf("expected isDiscovery(%q) to be %v", path, expected)
+		}
+	}
+}
+
+func TestRequestProfilingExitHooks(t *testing.T) {
+	defer func(mode string) { profileRequests = mode }(profileRequests)
+	profileRequests = "summary"
+	defer func() { requestProfiler = nil }()
+	defer cmdutil.DefaultBehaviorOnFatal()
+	cmdutil.BehaviorOnFatal(func(string, int) {})
+
+	out := &bytes.Buffer{}
+	cmds := &cobra.Command{Use: "kubectl"}
+	cmds.AddCommand(&cobra.Command{
+		Use:  "fail",
+		RunE: func(*cobra.Command, []string) error { return errors.New("failed") },
+	})
+	addRequestProfilingExitHooks(cmds, out)
+
+	if err := initRequestProfiling("kubectl fail"); err != nil {
+		t.Fatal(err)
+	}
+	cmds.SetArgs([]string{"fail"})
+	cmds.SetOut(io.Discard)
+	cmds.SetErr(io.Discard)
+	if err := cmds.Execute(); err == nil {
+		t.Fatal("expected the command to fail")
+	}
+	if !strings.Contains(out.String(), "requests took") {
+		t.Errorf("expected the profile to be written when the command fails, got %q", out.String())
+	}
+	// the profile is written once
+	out.Reset()
+	if err := flushRequestProfiling(out); err != nil || out.Len() > 0 {
+		t.Errorf("expected the profile to be written once, got %q: %v", out.String(), err)
+	}
+
+	if err := initRequestProfiling("kubectl fail"); err != nil {
+		t.Fatal(err)
+	}
+	// the waits of the rate limiter are recorded in the profile of the
+	// running command, the metrics being registered only once
+	metrics.RateLimiterLatency.Observe(context.TODO(), "GET", url.URL{Path: "/api"}, 2*time.Second)
+	if len(requestProfiler.throttles) != 1 {
+		t.Errorf("expected the wait to be recorded in the new profile, got %v", requestProfiler.throttles)
+	}
+	cmdutil.CheckErr(errors.New("fatal"))
+	if !strings.Contains(out.String(), "Client-side throttling: 1 requests waited 2s.") {
+		t.Errorf("expected the profile to be written on a fatal error, got %q", out.String())
+	}
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resize
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	resourceapi "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	resizeLong = templates.LongDesc(i18n.T(`
+		Resize a persistent volume claim.
+
+		The storage requested by the claim is increased to --to, once the storage class
+		of the claim is checked to allow volume expansion. Volumes can not be shrunk.
+
+		With --wait, the command waits for the capacity of the claim to reach the new
+		size, reporting the progress of the resize. The file system of some volumes is
+		only resized once a pod mounts the volume.`))
+
+	resizeExample = templates.Examples(i18n.T(`
+		# Resize the persistent volume claim data to 20Gi
+		kubectl resize pvc data --to=20Gi
+
+		# Resize the persistent volume claim data to 20Gi and wait for the resize to complete
+		kubectl resize pvc/data --to=20Gi --wait --timeout=10m`))
+)
+
+// ResizeOptions holds the options for 'resize' command
+type ResizeOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	PrintObj   func(obj runtime.Object) error
+
+	To      string
+	Wait    bool
+	Timeout time.Duration
+
+	Name           string
+	Namespace      string
+	Client         kubernetes.Interface
+	DryRunStrategy cmdutil.DryRunStrategy
+
+	// pollInterval is the interval between the checks of the claim with Wait.
+	pollInterval time.Duration
+
+	genericiooptions.IOStreams
+}
+
+// NewResizeOptions returns an initialized ResizeOptions instance
+func NewResizeOptions(ioStreams genericiooptions.IOStreams) *ResizeOptions {
+	return &ResizeOptions{
+		PrintFlags:   genericclioptions.NewPrintFlags("resized").WithTypeSetter(scheme.Scheme),
+		Timeout:      5 * time.Minute,
+		pollInterval: 2 * time.Second,
+		IOStreams:    ioStreams,
+	}
+}
+
+// NewCmdResize returns a cobra command for resizing persistent volume claims
+func NewCmdResize(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	o := NewResizeOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "resize (pvc NAME | pvc/NAME) --to=SIZE [--wait]",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Resize a persistent volume claim"),
+		Long:                  resizeLong,
+		Example:               resizeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().StringVar(&o.To, "to", o.To, "The new size of the claim, such as 20Gi.")
+	cmd.Flags().BoolVar(&o.Wait, "wait", o.Wait, "If true, wait for the capacity of the claim to reach the new size.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to wait for the resize with --wait.")
+
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *ResizeOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Name, err = claimName(args)
+	if err != nil {
+		return cmdutil.UsageErrorf(cmd, "%v", err)
+	}
+
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	o.DryRunStrategy, err = cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	printer, err := o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	o.PrintObj = func(obj runtime.Object) error {
+		return printer.PrintObj(obj, o.Out)
+	}
+
+	o.Client, err = f.KubernetesClientSet()
+	return err
+}
+
+// claimName returns the name of the claim of the arguments, in the
+// TYPE NAME or TYPE/NAME forms.
+func claimName(args []string) (string, error) {
+	if len(args) == 1 && strings.Contains(args[0], "/") {
+		args = strings.SplitN(args[0], "/", 2)
+	}
+	if len(args) != 2 || len(args[1]) == 0 {
+		return "", fmt.Errorf("a persistent volume claim must be specified as pvc NAME or pvc/NAME")
+	}
+	switch strings.ToLower(args[0]) {
+	case "pvc", "persistentvolumeclaim", "persistentvolumeclaims":
+		return args[1], nil
+	}
+	return "", fmt.Errorf("only persistent volume claims can be resized, got %s", args[0])
+}
+
+// Validate makes sure provided values for ResizeOptions are valid
+func (o *ResizeOptions) Validate() error {
+	if len(o.To) == 0 {
+		return fmt.Errorf("--to must be specified")
+	}
+	if _, err := resourceapi.ParseQuantity(o.To); err != nil {
+		return fmt.Errorf("invalid --to %q: %v", o.To, err)
+	}
+	if o.Wait && o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	return nil
+}
+
+// Run resizes the claim
+func (o *ResizeOptions) Run() error {
+	ctx := context.TODO()
+	size := resourceapi.MustParse(o.To)
+
+	claim, err := o.Client.CoreV1().PersistentVolumeClaims(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	requested := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	switch requested.Cmp(size) {
+	case 0:
+		return fmt.Errorf("persistentvolumeclaim %s already requests %s", o.Name, requested.String())
+	case 1:
+		return fmt.Errorf("persistentvolumeclaim %s requests %s, volumes can not be shrunk", o.Name, requested.String())
+	}
+
+	if claim.Spec.StorageClassName == nil || len(*claim.Spec.StorageClassName) == 0 {
+		return fmt.Errorf("persistentvolumeclaim %s has no storage class, volume expansion needs a storage class allowing it", o.Name)
+	}
+	class, err := o.Client.StorageV1().StorageClasses().Get(ctx, *claim.Spec.StorageClassName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if class.AllowVolumeExpansion == nil || !*class.AllowVolumeExpansion {
+		return fmt.Errorf("storageclass %s of persistentvolumeclaim %s does not allow volume expansion", class.Name, o.Name)
+	}
+
+	if o.DryRunStrategy == cmdutil.DryRunClient {
+		claim.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		return o.PrintObj(claim)
+	}
+	patch := fmt.Sprintf(`{"spec":{"resources":{"requests":{"storage":%q}}}}`, size.String())
+	patchOptions := metav1.PatchOptions{}
+	if o.DryRunStrategy == cmdutil.DryRunServer {
+		patchOptions.DryRun = []string{metav1.DryRunAll}
+	}
+	claim, err = o.Client.CoreV1().PersistentVolumeClaims(o.Namespace).Patch(ctx, o.Name, types.MergePatchType, []byte(patch), patchOptions)
+	if err != nil {
+		return err
+	}
+	if err := o.PrintObj(claim); err != nil {
+		return err
+	}
+
+	if !o.Wait || o.DryRunStrategy != cmdutil.DryRunNone {
+		return nil
+	}
+	return o.waitForResize(ctx, size)
+}
+
+// waitForResize waits for the capacity of the claim to reach size, printing
+// the resize conditions of the claim as they change.
+func (o *ResizeOptions) waitForResize(ctx context.Context, size resourceapi.Quantity) error {
+	lastProgress := ""
+	err := wait.PollUntilContextTimeout(ctx, o.pollInterval, o.Timeout, true, func(ctx context.Context) (bool, error) {
+		claim, err := o.Client.CoreV1().PersistentVolumeClaims(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		capacity := claim.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(size) >= 0 {
+			return true, nil
+		}
+		if progress := resizeProgress(claim); progress != lastProgress {
+			fmt.Fprintf(o.ErrOut, "persistentvolumeclaim/%s: %s\n", o.Name, progress)
+			lastProgress = progress
+		}
+		return false, nil
+	})
+	if err != nil {
+		if wait.Interrupted(err) {
+			return fmt.Errorf("timed out waiting for persistentvolumeclaim %s to be resized to %s", o.Name, size.String())
+		}
+		return err
+	}
+	fmt.Fprintf(o.ErrOut, "persistentvolumeclaim/%s: resized to %s\n", o.Name, size.String())
+	return nil
+}
+
+// resizeProgress describes the resize conditions of the claim.
+func resizeProgress(claim *corev1.PersistentVolumeClaim) string {
+	for _, condition := range claim.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case corev1.PersistentVolumeClaimResizing:
+			return "the volume is being resized"
+		case corev1.PersistentVolumeClaimFileSystemResizePending:
+			return "waiting for a pod to mount the volume to resize its file system"
+		}
+	}
+	return "waiting for the resize to start"
+}
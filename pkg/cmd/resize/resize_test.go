@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resize
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	resourceapi "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func TestClaimName(t *testing.T) {
+	tests := map[string]struct {
+		args        []string
+		expected    string
+		expectedErr string
+	}{
+		"type and name":  {args: []string{"pvc", "data"}, expected: "data"},
+		"type/name":      {args: []string{"persistentvolumeclaim/data"}, expected: "data"},
+		"other resource": {args: []string{"deployment", "web"}, expectedErr: "only persistent volume claims can be resized, got deployment"},
+		"name only":      {args: []string{"data"}, expectedErr: "a persistent volume claim must be specified"},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := claimName(tc.args)
+			if len(tc.expectedErr) > 0 {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil || got != tc.expected {
+				t.Errorf("expected %q, got %q, %v", tc.expected, got, err)
+			}
+		})
+	}
+}
+
+func newClaim(storageClass, size string, conditions ...corev1.PersistentVolumeClaimConditionType) *corev1.PersistentVolumeClaim {
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "test"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resourceapi.MustParse("10Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resourceapi.MustParse(size)},
+		},
+	}
+	if len(storageClass) > 0 {
+		claim.Spec.StorageClassName = &storageClass
+	}
+	for _, condition := range conditions {
+		claim.Status.Conditions = append(claim.Status.Conditions, corev1.PersistentVolumeClaimCondition{Type: condition, Status: corev1.ConditionTrue})
+	}
+	return claim
+}
+
+func TestResize(t *testing.T) {
+	allow, deny := true, false
+	classes := []runtime.Object{
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: &allow},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fixed"}, AllowVolumeExpansion: &deny},
+	}
+
+	tests := map[string]struct {
+		claim          *corev1.PersistentVolumeClaim
+		to             string
+		wait           bool
+		progress       []*corev1.PersistentVolumeClaim
+		expectedOut    string
+		expectedErrOut string
+		expectedErr    string
+	}{
+		"resize": {
+			claim:       newClaim("expandable", "10Gi"),
+			to:          "20Gi",
+			expectedOut: "persistentvolumeclaim/data resized\n",
+		},
+		"resize and wait": {
+			claim: newClaim("expandable", "10Gi"),
+			to:    "20Gi",
+			wait:  true,
+			progress: []*corev1.PersistentVolumeClaim{
+				newClaim("expandable", "10Gi", corev1.PersistentVolumeClaimResizing),
+				newClaim("expandable", "10Gi", corev1.PersistentVolumeClaimResizing),
+				newClaim("expandable", "10Gi", corev1.PersistentVolumeClaimFileSystemResizePending),
+				newClaim("expandable", "20Gi"),
+			},
+			expectedOut: "persistentvolumeclaim/data resized\n",
+			expectedErrOut: "persistentvolumeclaim/data: the volume is being resized\n" +
+				"persistentvolumeclaim/data: waiting for a pod to mount the volume to resize its file system\n" +
+				"persistentvolumeclaim/data: resized to 20Gi\n",
+		},
+		"storage class without expansion": {
+			claim:       newClaim("fixed", "10Gi"),
+			to:          "20Gi",
+			expectedErr: "storageclass fixed of persistentvolumeclaim data does not allow volume expansion",
+		},
+		"no storage class": {
+			claim:       newClaim("", "10Gi"),
+			to:          "20Gi",
+			expectedErr: "persistentvolumeclaim data has no storage class",
+		},
+		"shrink": {
+			claim:       newClaim("expandable", "10Gi"),
+			to:          "5Gi",
+			expectedErr: "persistentvolumeclaim data requests 10Gi, volumes can not be shrunk",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(append(classes, tc.claim)...)
+			patched := false
+			client.PrependReactor("patch", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				patched = true
+				return false, nil, nil
+			})
+			gets := 0
+			client.PrependReactor("get", "persistentvolumeclaims", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				gets++
+				// the first get is the one of the resize
+				if gets == 1 || len(tc.progress) == 0 {
+					return false, nil, nil
+				}
+				return true, tc.progress[min(gets-2, len(tc.progress)-1)], nil
+			})
+
+			streams, _, out, errOut := genericiooptions.NewTestIOStreams()
+			o := NewResizeOptions(streams)
+			printer, err := o.PrintFlags.ToPrinter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.PrintObj = func(obj runtime.Object) error { return printer.PrintObj(obj, o.Out) }
+			o.Name, o.Namespace, o.Client = "data", "test", client
+			o.DryRunStrategy = cmdutil.DryRunNone
+			o.To, o.Wait = tc.to, tc.wait
+			o.pollInterval = time.Millisecond
+
+			err = o.Validate()
+			if err == nil {
+				err = o.Run()
+			}
+			if len(tc.expectedErr) > 0 {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				if patched {
+					t.Errorf("expected the claim not to be patched")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expectedOut {
+				t.Errorf("expected output %q, got %q", tc.expectedOut, out.String())
+			}
+			if errOut.String() != tc.expectedErrOut {
+				t.Errorf("expected error output %q, got %q", tc.expectedErrOut, errOut.String())
+			}
+
+			claim, err := client.Tracker().Get(corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims"), "test", "data")
+			if err != nil {
+				t.Fatal(err)
+			}
+			requested := claim.(*corev1.PersistentVolumeClaim).Spec.Resources.Requests[corev1.ResourceStorage]
+			if requested.String() != tc.to {
+				t.Errorf("expected the claim to request %s, got %s", tc.to, requested.String())
+			}
+		})
+	}
+}
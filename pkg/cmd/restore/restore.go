@@ -0,0 +1,256 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/dynamic"
+	cmddelete "k8s.io/kubectl/pkg/cmd/delete"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	restoreLong = templates.LongDesc(i18n.T(`
+		Restore resources deleted with "kubectl delete --trash".
+
+		The most recent snapshot of the resource taken in the current cluster is created again,
+		without the fields set by the server and without its owner references, as the owners may
+		be gone, and removed from the trash unless --keep is given.`))
+
+	restoreExample = templates.Examples(i18n.T(`
+		# List the resources that can be restored in the current namespace
+		kubectl restore --list
+
+		# List the resources that can be restored in all namespaces
+		kubectl restore --list -A
+
+		# Restore the deployment nginx
+		kubectl restore deployment/nginx
+
+		# Restore the config maps a and b, keeping their snapshots in the trash
+		kubectl restore configmap a b --keep`))
+)
+
+// RestoreOptions holds the options for 'restore' sub command
+type RestoreOptions struct {
+	List          bool
+	AllNamespaces bool
+	Keep          bool
+
+	Namespace string
+	TrashDir  string
+	Resources []string
+
+	Mapper        meta.RESTMapper
+	DynamicClient dynamic.Interface
+
+	genericiooptions.IOStreams
+}
+
+// NewRestoreOptions returns an initialized RestoreOptions instance
+func NewRestoreOptions(ioStreams genericiooptions.IOStreams) *RestoreOptions {
+	return &RestoreOptions{
+		IOStreams: ioStreams,
+	}
+}
+
+// NewCmdRestore returns a cobra command for restoring deleted resources
+func NewCmdRestore(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	o := NewRestoreOptions(ioStreams)
+
+	cmd := &cobra.Command{
+		Use:                   "restore (TYPE NAME... | TYPE/NAME... | --list)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Restore resources deleted with --trash"),
+		Long:                  restoreLong,
+		Example:               restoreExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.List, "list", o.List, "If true, list the resources in the trash instead of restoring them.")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, list the resources in the trash across all namespaces.")
+	cmd.Flags().BoolVar(&o.Keep, "keep", o.Keep, "If true, keep the snapshots in the trash after restoring them.")
+
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *RestoreOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	if len(o.TrashDir) == 0 {
+		cluster, err := cmddelete.TrashClusterName(f)
+		if err != nil {
+			return err
+		}
+		o.TrashDir = cmddelete.DefaultTrashDir(cluster)
+	}
+
+	o.Resources, err = splitResourceArgs(args)
+	if err != nil {
+		return cmdutil.UsageErrorf(cmd, "%v", err)
+	}
+	if o.List {
+		return nil
+	}
+
+	o.Mapper, err = f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = f.DynamicClient()
+	return err
+}
+
+// Validate makes sure provided values for RestoreOptions are valid
+func (o *RestoreOptions) Validate() error {
+	if o.List {
+		if len(o.Resources) > 0 {
+			return fmt.Errorf("resources cannot be given with --list")
+		}
+		return nil
+	}
+	if o.AllNamespaces {
+		return fmt.Errorf("--all-namespaces can only be used with --list")
+	}
+	if len(o.Resources) == 0 {
+		return fmt.Errorf("at least one resource must be given to restore, or --list")
+	}
+	return nil
+}
+
+// Run restores the resources, or lists the trash
+func (o *RestoreOptions) Run() error {
+	if o.List {
+		return o.listTrash()
+	}
+
+	for _, resource := range o.Resources {
+		typeName, name, _ := strings.Cut(resource, "/")
+		gvr, err := o.Mapper.ResourceFor(schema.ParseGroupResource(typeName).WithVersion(""))
+		if err != nil {
+			return err
+		}
+		gvk, err := o.Mapper.KindFor(gvr)
+		if err != nil {
+			return err
+		}
+		mapping, err := o.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return err
+		}
+
+		namespace := ""
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			namespace = o.Namespace
+		}
+		entry, err := cmddelete.LatestTrashEntry(o.TrashDir, namespace, gvr.GroupResource(), name)
+		if err != nil {
+			return err
+		}
+		obj, err := cmddelete.ReadTrashEntry(entry)
+		if err != nil {
+			return err
+		}
+
+		_, err = o.DynamicClient.Resource(mapping.Resource).Namespace(namespace).Create(context.TODO(), obj, metav1.CreateOptions{FieldManager: "kubectl-restore"})
+		if err != nil {
+			return fmt.Errorf("failed to restore %s %q: %v", gvr.GroupResource(), name, err)
+		}
+		fmt.Fprintf(o.Out, "%s/%s restored\n", strings.ToLower(gvk.GroupKind().String()), name)
+
+		if !o.Keep {
+			if err := cmddelete.RemoveTrashEntry(o.TrashDir, entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (o *RestoreOptions) listTrash() error {
+	namespace := o.Namespace
+	if o.AllNamespaces {
+		namespace = ""
+	}
+	entries, err := cmddelete.ListTrash(o.TrashDir, namespace)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(o.ErrOut, "No resources found in the trash")
+		return nil
+	}
+
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+	fmt.Fprintln(w, "NAMESPACE\tRESOURCE\tNAME\tDELETED")
+	now := time.Now()
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s ago\n", entry.Namespace, entry.GroupResource, entry.Name, duration.HumanDuration(now.Sub(entry.DeletedAt)))
+	}
+	return nil
+}
+
+// splitResourceArgs turns TYPE NAME... and TYPE/NAME... arguments into a
+// list of TYPE/NAME.
+func splitResourceArgs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	if !strings.Contains(args[0], "/") {
+		if len(args) < 2 {
+			return nil, fmt.Errorf("a resource name must be given with type %s", args[0])
+		}
+		resources := []string{}
+		for _, name := range args[1:] {
+			if strings.Contains(name, "/") {
+				return nil, fmt.Errorf("there is no need to specify a resource type as a separate argument when passing arguments in resource/name form")
+			}
+			resources = append(resources, args[0]+"/"+name)
+		}
+		return resources, nil
+	}
+	for _, arg := range args {
+		if typeName, name, _ := strings.Cut(arg, "/"); len(typeName) == 0 || len(name) == 0 || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("arguments in resource/name form must have a single resource and name, got %q", arg)
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	cmddelete "k8s.io/kubectl/pkg/cmd/delete"
+)
+
+func TestSplitResourceArgs(t *testing.T) {
+	tests := map[string]struct {
+		args        []string
+		expected    []string
+		expectedErr string
+	}{
+		"no arguments": {},
+		"type and names": {
+			args:     []string{"configmap", "a", "b"},
+			expected: []string{"configmap/a", "configmap/b"},
+		},
+		"type/name": {
+			args:     []string{"deployment.apps/nginx", "svc/nginx"},
+			expected: []string{"deployment.apps/nginx", "svc/nginx"},
+		},
+		"type without name": {
+			args:        []string{"configmap"},
+			expectedErr: "a resource name must be given with type configmap",
+		},
+		"mixed forms": {
+			args:        []string{"configmap", "svc/nginx"},
+			expectedErr: "there is no need to specify a resource type",
+		},
+		"empty name": {
+			args:        []string{"configmap/"},
+			expectedErr: "arguments in resource/name form must have a single resource and name",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resources, err := splitResourceArgs(tc.args)
+			if len(tc.expectedErr) > 0 {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(resources, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, resources)
+			}
+		})
+	}
+}
+
+func TestListTrash(t *testing.T) {
+	dir := t.TempDir()
+	for _, namespace := range []string{"test", "other"} {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetNamespace(namespace)
+		obj.SetName("settings")
+		if _, err := cmddelete.SaveToTrash(dir, schema.GroupResource{Resource: "configmaps"}, obj, time.Now().Add(-time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+	o := NewRestoreOptions(streams)
+	o.List = true
+	o.Namespace = "test"
+	o.TrashDir = dir
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "NAMESPACE   RESOURCE     NAME       DELETED\ntest        configmaps   settings   60m ago\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	o.AllNamespaces = true
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 3 {
+		t.Errorf("expected the snapshots of both namespaces, got:\n%s", out.String())
+	}
+}
+
+func TestRestoreValidate(t *testing.T) {
+	tests := map[string]struct {
+		options     RestoreOptions
+		expectedErr string
+	}{
+		"nothing to restore": {
+			expectedErr: "at least one resource must be given to restore, or --list",
+		},
+		"list with resources": {
+			options:     RestoreOptions{List: true, Resources: []string{"configmap/a"}},
+			expectedErr: "resources cannot be given with --list",
+		},
+		"all namespaces without list": {
+			options:     RestoreOptions{AllNamespaces: true, Resources: []string{"configmap/a"}},
+			expectedErr: "--all-namespaces can only be used with --list",
+		},
+		"restore": {
+			options: RestoreOptions{Resources: []string{"configmap/a"}},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.options.Validate()
+			if len(tc.expectedErr) == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if len(tc.expectedErr) > 0 && (err == nil || err.Error() != tc.expectedErr) {
+				t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
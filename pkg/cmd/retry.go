@@ -0,0 +1,223 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	flagRetries      = "retries"
+	flagRetryBackoff = "retry-backoff"
+	flagRetryOn      = "retry-on"
+
+	// retryOnConnReset retries the requests whose connection was reset,
+	// refused or closed before a response was received.
+	retryOnConnReset = "conn-reset"
+
+	// maxRetryBackoff caps the backoff doubled after each retry.
+	maxRetryBackoff = 30 * time.Second
+)
+
+var (
+	retries      int
+	retryBackoff time.Duration
+	retryOn      []string
+
+	// requestRetrier retries the requests when --retries is set.
+	requestRetrier *retryPolicy
+)
+
+func addRetryFlags(flags *pflag.FlagSet) {
+	flags.IntVar(&retries, flagRetries, 0, "The number of times a request failing with one of the --retry-on errors is retried. The default can be set in the "+cmdutil.RetryPolicyExtension+" preferences extension of the kubeconfig file.")
+	flags.DurationVar(&retryBackoff, flagRetryBackoff, time.Second, "How long to wait before the first retry of a request, doubled after each retry up to 30s. A longer Retry-After of the response is honored.")
+	flags.StringSliceVar(&retryOn, flagRetryOn, []string{"429", "500", "503", retryOnConnReset}, "The HTTP status codes to retry the requests on, and conn-reset to retry the requests whose connection was reset, refused or closed. Requests which may have been processed, such as creations and patches, are only retried on 429.")
+}
+
+// addRetryHooks resolves the retry policy from the flags and the defaults of
+// the kubeconfig file, and retries the requests made with the REST configs
+// of the flags accordingly.
+func addRetryHooks(cmds *cobra.Command, kubeConfigFlags *genericclioptions.ConfigFlags) {
+	existingPreRunE := cmds.PersistentPreRunE
+	cmds.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		var err error
+		if requestRetrier, err = resolveRetryPolicy(cmd, kubeConfigFlags); err != nil {
+			return err
+		}
+		return existingPreRunE(cmd, args)
+	}
+
+	wrapConfigFn := kubeConfigFlags.WrapConfigFn
+	kubeConfigFlags.WrapConfigFn = func(c *rest.Config) *rest.Config {
+		if wrapConfigFn != nil {
+			c = wrapConfigFn(c)
+		}
+		if requestRetrier != nil {
+			c.Wrap(requestRetrier.wrapTransport)
+		}
+		return c
+	}
+}
+
+// resolveRetryPolicy returns the retry policy given with the flags, falling
+// back to the kubeconfig file for the flags not set, or nil when the
+// requests are not retried.
+func resolveRetryPolicy(cmd *cobra.Command, kubeConfigFlags *genericclioptions.ConfigFlags) (*retryPolicy, error) {
+	count, backoff, on := retries, retryBackoff, retryOn
+	if config, err := kubeConfigFlags.ToRawKubeConfigLoader().RawConfig(); err == nil {
+		defaults, err := cmdutil.LoadRetryPolicy(&config)
+		if err != nil {
+			return nil, err
+		}
+		if defaults.Retries != nil && !cmd.Flags().Changed(flagRetries) {
+			count = *defaults.Retries
+		}
+		if defaults.Backoff != nil && !cmd.Flags().Changed(flagRetryBackoff) {
+			backoff = defaults.Backoff.Duration
+		}
+		if len(defaults.RetryOn) > 0 && !cmd.Flags().Changed(flagRetryOn) {
+			on = []string{}
+			for _, value := range defaults.RetryOn {
+				on = append(on, value.String())
+			}
+		}
+	}
+	// else the command reports the error of the kubeconfig
+
+	if count < 0 {
+		return nil, fmt.Errorf("--%s must not be negative", flagRetries)
+	}
+	if backoff < 0 {
+		return nil, fmt.Errorf("--%s must not be negative", flagRetryBackoff)
+	}
+	policy := &retryPolicy{retries: count, backoff: backoff, statusCodes: sets.New[int]()}
+	for _, value := range on {
+		value = strings.TrimSpace(value)
+		if value == retryOnConnReset {
+			policy.connReset = true
+			continue
+		}
+		code, err := strconv.Atoi(value)
+		if err != nil || code < 400 || code > 599 {
+			return nil, fmt.Errorf("invalid --%s %q, must be an HTTP error status code or %s", flagRetryOn, value, retryOnConnReset)
+		}
+		policy.statusCodes.Insert(code)
+	}
+	if policy.retries == 0 {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+type retryPolicy struct {
+	retries     int
+	backoff     time.Duration
+	statusCodes sets.Set[int]
+	connReset   bool
+}
+
+func (p *retryPolicy) wrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &retryRoundTripper{policy: p, delegate: rt}
+}
+
+// shouldRetry returns true if the request is retried after the response or
+// the error. Creations and patches may have been processed by the server
+// before it failed, so they are only retried when they were rejected.
+func (p *retryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	idempotent := req.Method != http.MethodPost && req.Method != http.MethodPatch
+	if err != nil {
+		return idempotent && p.connReset && (utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err))
+	}
+	if !p.statusCodes.Has(resp.StatusCode) {
+		return false
+	}
+	return idempotent || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// delay returns how long to wait before the retry of the attempt, honoring
+// the Retry-After header of the response.
+func (p *retryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	delay := p.backoff
+	for i := 0; i < attempt && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && time.Duration(seconds)*time.Second > delay {
+			delay = time.Duration(seconds) * time.Second
+		}
+	}
+	return delay
+}
+
+type retryRoundTripper struct {
+	policy   *retryPolicy
+	delegate http.RoundTripper
+}
+
+func (rt *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// a request whose body can not be read again is not retried
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	for attempt := 0; ; attempt++ {
+		resp, err := rt.delegate.RoundTrip(req)
+		if !replayable || attempt >= rt.policy.retries || !rt.policy.shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		delay := rt.policy.delay(attempt, resp)
+		if err != nil {
+			klog.V(2).Infof("Retrying %s %s in %s after error: %v", req.Method, req.URL, delay, err)
+		} else {
+			klog.V(2).Infof("Retrying %s %s in %s after status %d", req.Method, req.URL, delay, resp.StatusCode)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
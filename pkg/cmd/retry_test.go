@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+const retryKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: c
+  cluster:
+    server: https://example.com
+contexts:
+- name: c
+  context:
+    cluster: c
+current-context: c
+preferences:
+  extensions:
+  - name: kubectl.kubernetes.io/retry-policy
+    extension:
+      retries: 3
+      backoff: 2s
+      retryOn: [429, conn-reset]
+`
+
+func TestResolveRetryPolicy(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(retryKubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		kubeconfig    string
+		flags         map[string]string
+		expected      *retryPolicy
+		expectedError string
+	}{
+		{name: "disabled", kubeconfig: "/dev/null"},
+		{
+			name:       "flags",
+			kubeconfig: "/dev/null",
+			flags:      map[string]string{"retries": "2"},
+			expected:   &retryPolicy{retries: 2, backoff: time.Second, statusCodes: sets.New(429, 500, 503), connReset: true},
+		},
+		{
+			name:       "kubeconfig",
+			kubeconfig: kubeconfig,
+			expected:   &retryPolicy{retries: 3, backoff: 2 * time.Second, statusCodes: sets.New(429), connReset: true},
+		},
+		{
+			name:       "flags override the kubeconfig",
+			kubeconfig: kubeconfig,
+			flags:      map[string]string{"retry-backoff": "500ms", "retry-on": "502,503"},
+			expected:   &retryPolicy{retries: 3, backoff: 500 * time.Millisecond, statusCodes: sets.New(502, 503)},
+		},
+		{name: "disabled by the flag", kubeconfig: kubeconfig, flags: map[string]string{"retries": "0"}},
+		{name: "negative retries", kubeconfig: "/dev/null", flags: map[string]string{"retries": "-1"}, expectedError: "--retries must not be negative"},
+		{
+			name:          "invalid retry-on",
+			kubeconfig:    "/dev/null",
+			flags:         map[string]string{"retries": "1", "retry-on": "200"},
+			expectedError: `invalid --retry-on "200", must be an HTTP error status code or conn-reset`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			kubeConfigFlags := genericclioptions.NewConfigFlags(true)
+			kubeConfigFlags.KubeConfig = &tc.kubeconfig
+			cmd := &cobra.Command{Use: "kubectl"}
+			addRetryFlags(cmd.Flags())
+			for name, value := range tc.flags {
+				if err := cmd.Flags().Set(name, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			policy, err := resolveRetryPolicy(cmd, kubeConfigFlags)
+			if len(tc.expectedError) > 0 {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expected, policy) {
+				t.Errorf("expected policy %#v, got %#v", tc.expected, policy)
+			}
+		})
+	}
+}
+
+func TestRetryRoundTripper(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		statuses       []int
+		expectedStatus int
+		expectedCalls  int
+	}{
+		{name: "retried", method: http.MethodGet, statuses: []int{503, 500, 200}, expectedStatus: 200, expectedCalls: 3},
+		{name: "retries exhausted", method: http.MethodGet, statuses: []int{503, 503, 503, 503}, expectedStatus: 503, expectedCalls: 3},
+		{name: "not retried status", method: http.MethodGet, statuses: []int{404, 200}, expectedStatus: 404, expectedCalls: 1},
+		{name: "creation not retried", method: http.MethodPost, statuses: []int{500, 201}, expectedStatus: 500, expectedCalls: 1},
+		{name: "rejected creation retried", method: http.MethodPost, statuses: []int{429, 201}, expectedStatus: 201, expectedCalls: 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPost && string(body) != "object" {
+					t.Errorf("unexpected body %q", body)
+				}
+				w.WriteHeader(tc.statuses[calls])
+				calls++
+			}))
+			defer server.Close()
+
+			policy := &retryPolicy{retries: 2, backoff: time.Millisecond, statusCodes: sets.New(429, 500, 503)}
+			client := &http.Client{Transport: policy.wrapTransport(http.DefaultTransport)}
+			req, err := http.NewRequest(tc.method, server.URL, bytes.NewReader([]byte("object")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, resp.StatusCode)
+			}
+			if calls != tc.expectedCalls {
+				t.Errorf("expected %d requests, got %d", tc.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := &retryPolicy{backoff: 10 * time.Second}
+	for attempt, expected := range []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second} {
+		if delay := policy.delay(attempt, nil); delay != expected {
+			t.Errorf("expected a delay of %s for attempt %d, got %s", expected, attempt, delay)
+		}
+	}
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"45"}}}
+	if delay := policy.delay(0, resp); delay != 45*time.Second {
+		t.Errorf("expected the Retry-After delay, got %s", delay)
+	}
+}
@@ -17,22 +17,34 @@ limitations under the License.
 package rollout
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
 	"k8s.io/kubectl/pkg/cmd/set"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/polymorphichelpers"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/interrupt"
 	"k8s.io/kubectl/pkg/util/templates"
 )
 
@@ -50,17 +62,32 @@ type RestartOptions struct {
 	EnforceNamespace bool
 	LabelSelector    string
 
+	// Stagger is the pause between the restarts of two resources.
+	Stagger time.Duration
+	// HealthGate waits for the rollout of each resource to complete before
+	// restarting the next one.
+	HealthGate     bool
+	Timeout        time.Duration
+	StatusViewerFn func(*meta.RESTMapping) (polymorphichelpers.StatusViewer, error)
+	DynamicClient  dynamic.Interface
+
 	resource.FilenameOptions
 	genericiooptions.IOStreams
 
 	fieldManager string
+	sleep        func(time.Duration)
 }
 
 var (
 	restartLong = templates.LongDesc(i18n.T(`
 		Restart a resource.
 
-	        Resource rollout will be restarted.`))
+	        Resource rollout will be restarted.
+
+		By default all the resources are restarted at once. With --stagger, the resources
+		are restarted one after the other, pausing between them, and with --health-gate
+		the rollout of each resource must complete before the next one is restarted. The
+		restarts stop at the first resource failing to become healthy.`))
 
 	restartExample = templates.Examples(`
 		# Restart all deployments in test-namespace namespace
@@ -73,14 +100,20 @@ var (
 		kubectl rollout restart daemonset/abc
 
 		# Restart deployments with the app=nginx label
-		kubectl rollout restart deployment --selector=app=nginx`)
+		kubectl rollout restart deployment --selector=app=nginx
+
+		# Restart the deployments with the app=nginx label one at a time, waiting for each rollout to complete
+		# and 30 seconds more before restarting the next deployment
+		kubectl rollout restart deployment --selector=app=nginx --health-gate --stagger=30s`)
 )
 
 // NewRolloutRestartOptions returns an initialized RestartOptions instance
 func NewRolloutRestartOptions(streams genericiooptions.IOStreams) *RestartOptions {
 	return &RestartOptions{
 		PrintFlags: genericclioptions.NewPrintFlags("restarted").WithTypeSetter(scheme.Scheme),
+		Timeout:    5 * time.Minute,
 		IOStreams:  streams,
+		sleep:      time.Sleep,
 	}
 }
 
@@ -108,6 +141,9 @@ func NewCmdRolloutRestart(f cmdutil.Factory, streams genericiooptions.IOStreams)
 	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.fieldManager, "kubectl-rollout")
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+	cmd.Flags().DurationVar(&o.Stagger, "stagger", o.Stagger, "The time to wait between the restarts of two resources. By default all the resources are restarted at once.")
+	cmd.Flags().BoolVar(&o.HealthGate, "health-gate", o.HealthGate, "If true, wait for the rollout of each resource to complete before restarting the next one.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to wait for the rollout of each resource with --health-gate, zero means never. Any other values should contain a corresponding time unit (e.g. 1s, 2m, 3h).")
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
 }
@@ -131,6 +167,14 @@ func (o *RestartOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []
 
 	o.Builder = f.NewBuilder
 
+	if o.HealthGate {
+		o.StatusViewerFn = polymorphichelpers.StatusViewerFn
+		o.DynamicClient, err = f.DynamicClient()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -138,6 +182,12 @@ func (o *RestartOptions) Validate() error {
 	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
 		return fmt.Errorf("required resource not specified")
 	}
+	if o.Stagger < 0 {
+		return fmt.Errorf("--stagger must not be negative")
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
 	return nil
 }
 
@@ -175,6 +225,7 @@ func (o RestartOptions) RunRestart() error {
 		return nil
 	}
 
+	restarted := 0
 	for _, patch := range patches {
 		info := patch.Info
 
@@ -192,6 +243,11 @@ func (o RestartOptions) RunRestart() error {
 			continue
 		}
 
+		if restarted > 0 && o.Stagger > 0 {
+			fmt.Fprintf(o.ErrOut, "Waiting %s before restarting %s/%s\n", o.Stagger, info.Mapping.Resource.Resource, info.Name)
+			o.sleep(o.Stagger)
+		}
+
 		obj, err := resource.NewHelper(info.Client, info.Mapping).
 			WithFieldManager(o.fieldManager).
 			Patch(info.Namespace, info.Name, types.StrategicMergePatchType, patch.Patch, nil)
@@ -209,7 +265,58 @@ func (o RestartOptions) RunRestart() error {
 		if err = printer.PrintObj(info.Object, o.Out); err != nil {
 			allErrs = append(allErrs, err)
 		}
+		restarted++
+
+		if o.HealthGate {
+			if err := o.waitForRollout(info); err != nil {
+				allErrs = append(allErrs, fmt.Errorf("%s/%s did not become healthy, the remaining resources were not restarted: %v", info.Mapping.Resource.Resource, info.Name, err))
+				break
+			}
+		}
 	}
 
 	return utilerrors.NewAggregate(allErrs)
 }
+
+// waitForRollout watches the resource until its rollout is done, writing the
+// progress to ErrOut.
+func (o RestartOptions) waitForRollout(info *resource.Info) error {
+	statusViewer, err := o.StatusViewerFn(info.ResourceMapping())
+	if err != nil {
+		return err
+	}
+
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", info.Name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return o.DynamicClient.Resource(info.Mapping.Resource).Namespace(info.Namespace).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return o.DynamicClient.Resource(info.Mapping.Resource).Namespace(info.Namespace).Watch(context.TODO(), options)
+		},
+	}
+
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), o.Timeout)
+	defer cancel()
+	intr := interrupt.New(nil, cancel)
+	return intr.Run(func() error {
+		_, err := watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, nil, func(e watch.Event) (bool, error) {
+			switch e.Type {
+			case watch.Added, watch.Modified:
+				status, done, err := statusViewer.Status(e.Object.(runtime.Unstructured), 0)
+				if err != nil {
+					return false, err
+				}
+				fmt.Fprintf(o.ErrOut, "%s", status)
+				return done, nil
+			case watch.Deleted:
+				return true, fmt.Errorf("object has been deleted")
+			default:
+				return true, fmt.Errorf("internal error: unexpected event %#v", e)
+			}
+		})
+		return err
+	})
+}
@@ -22,14 +22,19 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/client-go/rest/fake"
+	cgtesting "k8s.io/client-go/testing"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 	"k8s.io/kubectl/pkg/scheme"
 )
@@ -216,6 +221,120 @@ func TestRolloutRestartSelectorMany(t *testing.T) {
 	}
 }
 
+func TestRolloutRestartStaggeredHealthGate(t *testing.T) {
+	tests := []struct {
+		name               string
+		progressDeadline   bool
+		expectedPatched    []string
+		expectedSleeps     []time.Duration
+		expectedOutput     string
+		expectedErrMessage string
+	}{
+		{
+			name:            "all healthy",
+			expectedPatched: []string{"nginx-deployment-1", "nginx-deployment-2"},
+			expectedSleeps:  []time.Duration{30 * time.Second},
+			expectedOutput:  "deployment.apps/nginx-deployment-1 restarted\ndeployment.apps/nginx-deployment-2 restarted\n",
+		},
+		{
+			name:               "first not healthy",
+			progressDeadline:   true,
+			expectedPatched:    []string{"nginx-deployment-1"},
+			expectedOutput:     "deployment.apps/nginx-deployment-1 restarted\n",
+			expectedErrMessage: "deployments/nginx-deployment-1 did not become healthy, the remaining resources were not restarted",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			firstDeployment := appsv1.Deployment{}
+			firstDeployment.Name = "nginx-deployment-1"
+			secondDeployment := appsv1.Deployment{}
+			secondDeployment.Name = "nginx-deployment-2"
+
+			ns := scheme.Codecs.WithoutConversion()
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			info, _ := runtime.SerializerInfoForMediaType(ns.SupportedMediaTypes(), runtime.ContentTypeJSON)
+			encoder := ns.EncoderForVersion(info.Serializer, rolloutRestartGroupVersionEncoder)
+			patched := []string{}
+			tf.Client = &RolloutRestartRESTClient{
+				RESTClient: &fake.RESTClient{
+					GroupVersion:         rolloutRestartGroupVersionEncoder,
+					NegotiatedSerializer: ns,
+					Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+						switch p, m := req.URL.Path, req.Method; {
+						case p == "/namespaces/test/deployments" && m == "GET":
+							responseDeployments := &appsv1.DeploymentList{}
+							responseDeployments.Items = []appsv1.Deployment{firstDeployment, secondDeployment}
+							body := io.NopCloser(bytes.NewReader([]byte(runtime.EncodeOrDie(encoder, responseDeployments))))
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: body}, nil
+						case strings.HasPrefix(p, "/namespaces/test/deployments/") && m == "PATCH":
+							responseDeployment := firstDeployment
+							if strings.HasSuffix(p, "nginx-deployment-2") {
+								responseDeployment = secondDeployment
+							}
+							patched = append(patched, responseDeployment.Name)
+							body := io.NopCloser(bytes.NewReader([]byte(runtime.EncodeOrDie(encoder, &responseDeployment))))
+							return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: body}, nil
+						default:
+							t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+							return nil, nil
+						}
+					}),
+				},
+			}
+
+			tf.FakeDynamicClient.WatchReactionChain = nil
+			tf.FakeDynamicClient.AddWatchReactor("*", func(action cgtesting.Action) (handled bool, ret watch.Interface, err error) {
+				fw := watch.NewFake()
+				dep := &appsv1.Deployment{}
+				dep.Name = patched[len(patched)-1]
+				dep.Status = appsv1.DeploymentStatus{
+					Replicas:          1,
+					UpdatedReplicas:   1,
+					ReadyReplicas:     1,
+					AvailableReplicas: 1,
+				}
+				if tc.progressDeadline {
+					dep.Status.Conditions = []appsv1.DeploymentCondition{{
+						Type:   appsv1.DeploymentProgressing,
+						Status: corev1.ConditionFalse,
+						Reason: "ProgressDeadlineExceeded",
+					}}
+				}
+				c, err := runtime.DefaultUnstructuredConverter.ToUnstructured(dep)
+				if err != nil {
+					t.Errorf("unexpected err %s", err)
+				}
+				go fw.Add(&unstructured.Unstructured{Object: c})
+				return true, fw, nil
+			})
+
+			streams, _, buf, errBuf := genericiooptions.NewTestIOStreams()
+			o := NewRolloutRestartOptions(streams)
+			var sleeps []time.Duration
+			o.sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+			cmd := NewCmdRolloutRestart(tf, streams)
+			o.HealthGate = true
+			o.Stagger = 30 * time.Second
+
+			assert.NoError(t, o.Complete(tf, cmd, []string{"deployment"}))
+			assert.NoError(t, o.Validate())
+			err := o.RunRestart()
+			if len(tc.expectedErrMessage) > 0 {
+				assert.ErrorContains(t, err, tc.expectedErrMessage)
+			} else {
+				assert.NoError(t, err)
+				assert.Contains(t, errBuf.String(), "Waiting 30s before restarting deployments/nginx-deployment-2")
+			}
+			assert.Equal(t, tc.expectedPatched, patched)
+			assert.Equal(t, tc.expectedSleeps, sleeps)
+			assert.Equal(t, tc.expectedOutput, buf.String())
+		})
+	}
+}
+
 type RolloutRestartRESTClient struct {
 	*fake.RESTClient
 }
@@ -19,10 +19,16 @@ package rollout
 import (
 	"context"
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -33,12 +39,15 @@ import (
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/klog/v2"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/polymorphichelpers"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/completion"
+	deploymentutil "k8s.io/kubectl/pkg/util/deployment"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/interrupt"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -53,11 +62,20 @@ var (
 		you can use --watch=false. Note that if a new rollout starts in-between, then
 		'rollout status' will continue watching the latest revision. If you want to
 		pin to a specific revision and abort if it is rolled over by another revision,
-		use --revision=N where N is the revision you need to watch for.`))
+		use --revision=N where N is the revision you need to watch for.
+
+		With --auto-undo, a deployment is rolled back to its previous revision when its
+		rollout fails: when its progress deadline is exceeded, or when more than
+		--error-budget of the pods of its new replica set are crash looping. The checks
+		and the decision to roll back are printed as the rollout is watched, and the
+		command fails when the deployment was rolled back.`))
 
 	statusExample = templates.Examples(`
 		# Watch the rollout status of a deployment
-		kubectl rollout status deployment/nginx`)
+		kubectl rollout status deployment/nginx
+
+		# Watch the rollout of a deployment, rolling it back when more than 20% of its new pods crash loop
+		kubectl rollout status deployment/nginx --auto-undo --error-budget=20%`)
 )
 
 // RolloutStatusOptions holds the command-line options for 'rollout status' sub command
@@ -73,12 +91,26 @@ type RolloutStatusOptions struct {
 	Revision int64
 	Timeout  time.Duration
 
+	// AutoUndo rolls a deployment back when its rollout fails, that is when
+	// its progress deadline is exceeded or when more than ErrorBudget of the
+	// pods of its new replica set are crash looping.
+	AutoUndo    bool
+	ErrorBudget string
+
 	StatusViewerFn func(*meta.RESTMapping) (polymorphichelpers.StatusViewer, error)
+	RollbackerFn   func(*meta.RESTMapping) (polymorphichelpers.Rollbacker, error)
 	Builder        func() *resource.Builder
 	DynamicClient  dynamic.Interface
+	Client         kubernetes.Interface
 
 	FilenameOptions *resource.FilenameOptions
 	genericiooptions.IOStreams
+
+	// errorBudget is the parsed ErrorBudget, in percent
+	errorBudget int
+	// checkInterval is the interval between the checks of the pods of the
+	// new replica set, which are made on the changes of the deployment too
+	checkInterval time.Duration
 }
 
 // NewRolloutStatusOptions returns an initialized RolloutStatusOptions instance
@@ -89,6 +121,8 @@ func NewRolloutStatusOptions(streams genericiooptions.IOStreams) *RolloutStatusO
 		IOStreams:       streams,
 		Watch:           true,
 		Timeout:         0,
+		ErrorBudget:     "20%",
+		checkInterval:   10 * time.Second,
 	}
 }
 
@@ -117,6 +151,8 @@ func NewCmdRolloutStatus(f cmdutil.Factory, streams genericiooptions.IOStreams)
 	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "Watch the status of the rollout until it's done.")
 	cmd.Flags().Int64Var(&o.Revision, "revision", o.Revision, "Pin to a specific revision for showing its status. Defaults to 0 (last revision).")
 	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to wait before ending watch, zero means never. Any other values should contain a corresponding time unit (e.g. 1s, 2m, 3h).")
+	cmd.Flags().BoolVar(&o.AutoUndo, "auto-undo", o.AutoUndo, "If true, roll the deployment back to its previous revision when its progress deadline is exceeded or when more than --error-budget of its new pods are crash looping.")
+	cmd.Flags().StringVar(&o.ErrorBudget, "error-budget", o.ErrorBudget, "The percentage of the pods of the new replica set allowed to crash loop before rolling back with --auto-undo.")
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
 
 	return cmd
@@ -140,6 +176,16 @@ func (o *RolloutStatusOptions) Complete(f cmdutil.Factory, args []string) error
 		return err
 	}
 
+	if o.AutoUndo {
+		o.Client, err = f.KubernetesClientSet()
+		if err != nil {
+			return err
+		}
+		o.RollbackerFn = func(mapping *meta.RESTMapping) (polymorphichelpers.Rollbacker, error) {
+			return polymorphichelpers.RollbackerFn(f, mapping)
+		}
+	}
+
 	return nil
 }
 
@@ -153,6 +199,16 @@ func (o *RolloutStatusOptions) Validate() error {
 		return fmt.Errorf("revision must be a positive integer: %v", o.Revision)
 	}
 
+	budget, err := strconv.Atoi(strings.TrimSuffix(o.ErrorBudget, "%"))
+	if err != nil || !strings.HasSuffix(o.ErrorBudget, "%") || budget < 0 || budget > 100 {
+		return fmt.Errorf("invalid --error-budget %q, must be a percentage between 0%% and 100%%", o.ErrorBudget)
+	}
+	o.errorBudget = budget
+
+	if o.AutoUndo && !o.Watch {
+		return fmt.Errorf("--auto-undo requires --watch")
+	}
+
 	return nil
 }
 
@@ -183,6 +239,15 @@ func (o *RolloutStatusOptions) Run() error {
 			return err
 		}
 
+		var guard *rolloutGuard
+		if o.AutoUndo {
+			if mapping.GroupVersionKind.Kind != "Deployment" {
+				return fmt.Errorf("--auto-undo is only supported for deployments, not %s", mapping.Resource.Resource)
+			}
+			guard = &rolloutGuard{client: o.Client, budget: o.errorBudget, out: o.Out, lastCrashing: -1}
+			fmt.Fprintf(o.Out, "Auto-undo: deployment %q is rolled back if its progress deadline is exceeded or more than %d%% of its new pods crash loop\n", info.Name, o.errorBudget)
+		}
+
 		fieldSelector := fields.OneTermEqualSelector("metadata.name", info.Name).String()
 		lw := &cache.ListWatch{
 			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
@@ -197,11 +262,27 @@ func (o *RolloutStatusOptions) Run() error {
 
 		// if the rollout isn't done yet, keep watching deployment status
 		ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), o.Timeout)
+		defer cancel()
 		intr := interrupt.New(nil, cancel)
 		return intr.Run(func() error {
+			if guard != nil {
+				// the pods of the new replica set can crash loop without
+				// the deployment changing, so they are checked periodically
+				go guard.poll(ctx, cancel, info.Namespace, info.Name, o.checkInterval)
+			}
 			_, err = watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, nil, func(e watch.Event) (bool, error) {
 				switch t := e.Type; t {
 				case watch.Added, watch.Modified:
+					if guard != nil {
+						deployment := &appsv1.Deployment{}
+						if err := runtime.DefaultUnstructuredConverter.FromUnstructured(e.Object.(runtime.Unstructured).UnstructuredContent(), deployment); err != nil {
+							return false, err
+						}
+						failed, err := guard.check(ctx, deployment)
+						if err != nil || failed {
+							return true, err
+						}
+					}
 					status, done, err := statusViewer.Status(e.Object.(runtime.Unstructured), o.Revision)
 					if err != nil {
 						return false, err
@@ -209,6 +290,9 @@ func (o *RolloutStatusOptions) Run() error {
 					fmt.Fprintf(o.Out, "%s", status)
 					// Quit waiting if the rollout is done
 					if done {
+						if guard != nil {
+							fmt.Fprintf(o.Out, "Auto-undo: deployment %q rolled out within the error budget, no rollback needed\n", info.Name)
+						}
 						return true, nil
 					}
 
@@ -227,6 +311,9 @@ func (o *RolloutStatusOptions) Run() error {
 					return true, fmt.Errorf("internal error: unexpected event %#v", e)
 				}
 			})
+			if reason := guard.failure(); len(reason) > 0 {
+				return o.rollback(info, reason)
+			}
 			return err
 		})
 	})
@@ -241,3 +328,145 @@ func (o *RolloutStatusOptions) Run() error {
 
 	return nil
 }
+
+// rollback rolls the deployment back to its previous revision after its
+// rollout failed for the given reason.
+func (o *RolloutStatusOptions) rollback(info *resource.Info, reason string) error {
+	rollbacker, err := o.RollbackerFn(info.ResourceMapping())
+	if err != nil {
+		return err
+	}
+	result, err := rollbacker.Rollback(info.Object, nil, 0, cmdutil.DryRunNone)
+	if err != nil {
+		return fmt.Errorf("rollout of deployment %q failed (%s) and could not be rolled back: %v", info.Name, reason, err)
+	}
+	fmt.Fprintf(o.Out, "Auto-undo: deployment %q %s\n", info.Name, result)
+	return fmt.Errorf("rollout of deployment %q failed and was rolled back: %s", info.Name, reason)
+}
+
+// rolloutGuard decides whether the rollout of a deployment failed, printing
+// the checks leading to the decision.
+type rolloutGuard struct {
+	client kubernetes.Interface
+	// budget is the percentage of the pods of the new replica set allowed
+	// to crash loop
+	budget int
+	out    io.Writer
+
+	lock sync.Mutex
+	// lastCrashing is the number of crash looping pods last printed
+	lastCrashing int
+	reason       string
+}
+
+// poll checks the deployment every interval until the context is done,
+// cancelling it when the rollout failed.
+func (g *rolloutGuard) poll(ctx context.Context, cancel context.CancelFunc, namespace, name string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		deployment, err := g.client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			klog.V(4).Infof("Failed to get deployment %s/%s: %v", namespace, name, err)
+			continue
+		}
+		failed, err := g.check(ctx, deployment)
+		if err != nil {
+			klog.V(4).Infof("Failed to check deployment %s/%s: %v", namespace, name, err)
+			continue
+		}
+		if failed {
+			cancel()
+			return
+		}
+	}
+}
+
+// check returns whether the rollout of the deployment failed.
+func (g *rolloutGuard) check(ctx context.Context, deployment *appsv1.Deployment) (bool, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if len(g.reason) > 0 {
+		return true, nil
+	}
+
+	if deployment.Generation <= deployment.Status.ObservedGeneration {
+		cond := deploymentutil.GetDeploymentCondition(deployment.Status, appsv1.DeploymentProgressing)
+		if cond != nil && cond.Reason == deploymentutil.TimedOutReason {
+			g.fail(deployment, "its progress deadline was exceeded")
+			return true, nil
+		}
+	}
+
+	_, _, newRS, err := deploymentutil.GetAllReplicaSets(deployment, g.client.AppsV1())
+	if err != nil || newRS == nil {
+		return false, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(newRS.Spec.Selector)
+	if err != nil {
+		return false, err
+	}
+	pods, err := g.client.CoreV1().Pods(newRS.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return false, err
+	}
+
+	total, crashing := 0, 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil || !metav1.IsControlledBy(pod, newRS) {
+			continue
+		}
+		total++
+		if isCrashLooping(pod) {
+			crashing++
+		}
+	}
+	if total == 0 {
+		return false, nil
+	}
+	if crashing != g.lastCrashing {
+		fmt.Fprintf(g.out, "Auto-undo: %d of %d pods of the new replica set %q are crash looping, the error budget is %d%%\n", crashing, total, newRS.Name, g.budget)
+		g.lastCrashing = crashing
+	}
+	if crashing*100 > g.budget*total {
+		g.fail(deployment, fmt.Sprintf("%d of %d pods of the new replica set %q are crash looping, above the error budget of %d%%", crashing, total, newRS.Name, g.budget))
+		return true, nil
+	}
+	return false, nil
+}
+
+// fail records the reason of the failure of the rollout. It is called with
+// the lock held.
+func (g *rolloutGuard) fail(deployment *appsv1.Deployment, reason string) {
+	g.reason = reason
+	fmt.Fprintf(g.out, "Auto-undo: rolling back deployment %q: %s\n", deployment.Name, reason)
+}
+
+// failure returns the reason of the failure of the rollout, if any.
+func (g *rolloutGuard) failure() string {
+	if g == nil {
+		return ""
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.reason
+}
+
+// isCrashLooping returns whether a container of the pod is waiting to be
+// restarted after crashing.
+func isCrashLooping(pod *corev1.Pod) bool {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -20,18 +20,26 @@ import (
 	"bytes"
 	"io"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest/fake"
 	cgtesting "k8s.io/client-go/testing"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
 	"k8s.io/kubectl/pkg/scheme"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/utils/ptr"
 )
 
 var rolloutStatusGroupVersionEncoder = schema.GroupVersion{Group: "apps", Version: "v1"}
@@ -284,3 +292,184 @@ func TestRolloutStatusEmptyList(t *testing.T) {
 		t.Errorf("expected output: %s, but got: %s", expectedMsg, err.String())
 	}
 }
+
+func TestRolloutStatusAutoUndo(t *testing.T) {
+	tests := []struct {
+		name             string
+		progressDeadline bool
+		crashing         int
+		errorBudget      string
+		expectRollback   bool
+		expectedOutput   []string
+		expectedErr      string
+	}{
+		{
+			name:           "rolled out",
+			errorBudget:    "20%",
+			expectedOutput: []string{"Auto-undo: 0 of 2 pods of the new replica set \"nginx-new\" are crash looping, the error budget is 20%", "successfully rolled out", "no rollback needed"},
+		},
+		{
+			name:             "progress deadline exceeded",
+			progressDeadline: true,
+			errorBudget:      "20%",
+			expectRollback:   true,
+			expectedOutput:   []string{"Auto-undo: rolling back deployment \"nginx\": its progress deadline was exceeded", "Auto-undo: deployment \"nginx\" rolled back"},
+			expectedErr:      "rollout of deployment \"nginx\" failed and was rolled back: its progress deadline was exceeded",
+		},
+		{
+			name:           "crash looping above the error budget",
+			crashing:       1,
+			errorBudget:    "20%",
+			expectRollback: true,
+			expectedOutput: []string{"Auto-undo: 1 of 2 pods of the new replica set \"nginx-new\" are crash looping, the error budget is 20%", "Auto-undo: deployment \"nginx\" rolled back"},
+			expectedErr:    "1 of 2 pods of the new replica set \"nginx-new\" are crash looping, above the error budget of 20%",
+		},
+		{
+			name:           "crash looping within the error budget",
+			crashing:       1,
+			errorBudget:    "50%",
+			expectedOutput: []string{"Auto-undo: 1 of 2 pods of the new replica set \"nginx-new\" are crash looping, the error budget is 50%", "no rollback needed"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			labels := map[string]string{"app": "nginx"}
+			dep := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "test", UID: "deployment-uid"},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: ptr.To[int32](2),
+					Selector: &metav1.LabelSelector{MatchLabels: labels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: labels},
+						Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx:broken"}}},
+					},
+				},
+				Status: appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2, AvailableReplicas: 2},
+			}
+			if tc.progressDeadline {
+				dep.Status.AvailableReplicas = 0
+				dep.Status.Conditions = []appsv1.DeploymentCondition{{
+					Type:   appsv1.DeploymentProgressing,
+					Status: corev1.ConditionFalse,
+					Reason: "ProgressDeadlineExceeded",
+				}}
+			}
+
+			rsLabels := map[string]string{"app": "nginx", appsv1.DefaultDeploymentUniqueLabelKey: "new"}
+			rs := &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "nginx-new", Namespace: "test", UID: "rs-uid", Labels: rsLabels,
+					OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(dep, appsv1.SchemeGroupVersion.WithKind("Deployment"))}},
+				Spec: appsv1.ReplicaSetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: rsLabels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: rsLabels},
+						Spec:       dep.Spec.Template.Spec,
+					},
+				},
+			}
+			objects := []runtime.Object{dep, rs}
+			for i, name := range []string{"nginx-new-a", "nginx-new-b"} {
+				pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Labels: rsLabels,
+					OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(rs, appsv1.SchemeGroupVersion.WithKind("ReplicaSet"))}}}
+				state := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+				if i < tc.crashing {
+					state = corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+				}
+				pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "nginx", State: state}}
+				objects = append(objects, pod)
+			}
+
+			ns := scheme.Codecs.WithoutConversion()
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+			tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+			info, _ := runtime.SerializerInfoForMediaType(ns.SupportedMediaTypes(), runtime.ContentTypeJSON)
+			encoder := ns.EncoderForVersion(info.Serializer, rolloutStatusGroupVersionEncoder)
+			tf.Client = &fake.RESTClient{
+				GroupVersion:         rolloutStatusGroupVersionEncoder,
+				NegotiatedSerializer: ns,
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					body := io.NopCloser(bytes.NewReader([]byte(runtime.EncodeOrDie(encoder, dep))))
+					return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: body}, nil
+				}),
+			}
+
+			tf.FakeDynamicClient.WatchReactionChain = nil
+			tf.FakeDynamicClient.AddWatchReactor("*", func(action cgtesting.Action) (handled bool, ret watch.Interface, err error) {
+				fw := watch.NewFake()
+				c, err := runtime.DefaultUnstructuredConverter.ToUnstructured(dep.DeepCopyObject())
+				if err != nil {
+					t.Errorf("unexpected err %s", err)
+				}
+				go fw.Add(&unstructured.Unstructured{Object: c})
+				return true, fw, nil
+			})
+
+			streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+			o := NewRolloutStatusOptions(streams)
+			o.AutoUndo = true
+			o.ErrorBudget = tc.errorBudget
+			o.checkInterval = time.Hour
+			if err := o.Complete(tf, []string{"deployment/nginx"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			o.Client = kubefake.NewSimpleClientset(objects...)
+			rollbacker := &fakeRollbacker{}
+			o.RollbackerFn = func(*meta.RESTMapping) (polymorphichelpers.Rollbacker, error) {
+				return rollbacker, nil
+			}
+
+			err := o.Run()
+			if len(tc.expectedErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if rolledBack := rollbacker.rollbacks > 0; rolledBack != tc.expectRollback {
+				t.Errorf("expected rollback %v, got %v", tc.expectRollback, rolledBack)
+			}
+			for _, expected := range tc.expectedOutput {
+				if !strings.Contains(buf.String(), expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRolloutStatusAutoUndoValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		watch       bool
+		errorBudget string
+		expectedErr string
+	}{
+		{name: "valid", watch: true, errorBudget: "0%"},
+		{name: "no watch", watch: false, errorBudget: "20%", expectedErr: "--auto-undo requires --watch"},
+		{name: "no percent sign", watch: true, errorBudget: "20", expectedErr: "invalid --error-budget \"20\""},
+		{name: "above 100%", watch: true, errorBudget: "120%", expectedErr: "invalid --error-budget \"120%\""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := NewRolloutStatusOptions(genericiooptions.NewTestIOStreamsDiscard())
+			o.BuilderArgs = []string{"deployment/nginx"}
+			o.AutoUndo = true
+			o.Watch = tc.watch
+			o.ErrorBudget = tc.errorBudget
+			err := o.Validate()
+			if len(tc.expectedErr) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
@@ -21,16 +21,19 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kubectl/pkg/cmd/diff"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/polymorphichelpers"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"k8s.io/kubectl/pkg/util/templates"
+	"k8s.io/utils/exec"
 )
 
 // UndoOptions is the start of the data required to perform the operation.  As new fields are added, add them here instead of
@@ -48,13 +51,26 @@ type UndoOptions struct {
 	EnforceNamespace bool
 	RESTClientGetter genericclioptions.RESTClientGetter
 
+	// Diff prints the changes of the rollback instead of the objects
+	Diff        bool
+	DiffProgram *diff.DiffProgram
+
 	resource.FilenameOptions
 	genericiooptions.IOStreams
 }
 
 var (
 	undoLong = templates.LongDesc(i18n.T(`
-		Roll back to a previous rollout.`))
+		Roll back to a previous rollout.
+
+		Deployments are rolled back to the template of one of their replica sets, and
+		daemon sets and stateful sets to the template of one of their controller
+		revisions. The change cause recorded with the revision is restored as well.
+
+		With --dry-run and -o diff, the changes of the rollback are printed as a diff
+		of the live object and the rolled back object, computed by the server with
+		--dry-run=server. The diff program can be changed with KUBECTL_EXTERNAL_DIFF,
+		as for the diff command.`))
 
 	undoExample = templates.Examples(`
 		# Roll back to the previous deployment
@@ -64,7 +80,10 @@ var (
 		kubectl rollout undo daemonset/abc --to-revision=3
 
 		# Roll back to the previous deployment with dry-run
-		kubectl rollout undo --dry-run=server deployment/abc`)
+		kubectl rollout undo --dry-run=server deployment/abc
+
+		# Show the changes rolling back to statefulset revision 2 would make
+		kubectl rollout undo statefulset/abc --to-revision=2 --dry-run=server -o diff`)
 )
 
 // NewRolloutUndoOptions returns an initialized UndoOptions instance
@@ -102,6 +121,9 @@ func NewCmdRolloutUndo(f cmdutil.Factory, streams genericiooptions.IOStreams) *c
 	cmdutil.AddDryRunFlag(cmd)
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
 	o.PrintFlags.AddFlags(cmd)
+	if flag := cmd.Flags().Lookup("output"); flag != nil {
+		flag.Usage += " Use 'diff' with --dry-run to print the changes of the rollback instead."
+	}
 	return cmd
 }
 
@@ -113,6 +135,11 @@ func (o *UndoOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []str
 	if err != nil {
 		return err
 	}
+	if o.PrintFlags.OutputFormat != nil && *o.PrintFlags.OutputFormat == "diff" {
+		o.Diff = true
+		*o.PrintFlags.OutputFormat = ""
+	}
+	o.DiffProgram = &diff.DiffProgram{Exec: exec.New(), IOStreams: o.IOStreams}
 
 	if o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
 		return err
@@ -134,6 +161,9 @@ func (o *UndoOptions) Validate() error {
 	if len(o.Resources) == 0 && cmdutil.IsFilenameSliceEmpty(o.Filenames, o.Kustomize) {
 		return fmt.Errorf("required resource not specified")
 	}
+	if o.Diff && o.DryRunStrategy == cmdutil.DryRunNone {
+		return fmt.Errorf("-o diff requires --dry-run")
+	}
 	return nil
 }
 
@@ -152,6 +182,9 @@ func (o *UndoOptions) RunUndo() error {
 	if err := r.Err(); err != nil {
 		return err
 	}
+	if o.Diff {
+		return o.runDiff(r)
+	}
 
 	err := r.Visit(func(info *resource.Info, err error) error {
 		if err != nil {
@@ -177,3 +210,64 @@ func (o *UndoOptions) RunUndo() error {
 
 	return err
 }
+
+// runDiff prints the diff of the live objects and the rolled back objects.
+func (o *UndoOptions) runDiff(r *resource.Result) error {
+	differ, err := diff.NewDiffer("LIVE", "MERGED")
+	if err != nil {
+		return err
+	}
+	defer differ.TearDown()
+
+	err = r.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		rollbacker, err := polymorphichelpers.RollbackerFn(o.RESTClientGetter, info.ResourceMapping())
+		if err != nil {
+			return err
+		}
+		previewer, ok := rollbacker.(polymorphichelpers.RollbackPreviewer)
+		if !ok {
+			return fmt.Errorf("the rollback of %s can not be printed as a diff", info.ObjectName())
+		}
+		live, rolledBack, err := previewer.PreviewRollback(info.Object, o.ToRevision, o.DryRunStrategy)
+		if err != nil {
+			return err
+		}
+		live.GetObjectKind().SetGroupVersionKind(info.Mapping.GroupVersionKind)
+		rolledBack.GetObjectKind().SetGroupVersionKind(info.Mapping.GroupVersionKind)
+		return differ.Diff(&rollbackObject{info: info, live: live, rolledBack: rolledBack}, diff.Printer{}, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	// diff exits with 1 when the objects differ
+	if err := differ.Run(o.DiffProgram); err != nil {
+		if exitErr, ok := err.(exec.ExitError); !ok || exitErr.ExitStatus() > 1 {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollbackObject is the diff.Object of the rollback of an object.
+type rollbackObject struct {
+	info       *resource.Info
+	live       runtime.Object
+	rolledBack runtime.Object
+}
+
+func (obj *rollbackObject) Live() runtime.Object {
+	return obj.live
+}
+
+func (obj *rollbackObject) Merged() (runtime.Object, error) {
+	return obj.rolledBack, nil
+}
+
+func (obj *rollbackObject) Name() string {
+	gvk := obj.info.Mapping.GroupVersionKind
+	return fmt.Sprintf("%s.%s.%s.%s.%s", gvk.Group, gvk.Version, gvk.Kind, obj.info.Namespace, obj.info.Name)
+}
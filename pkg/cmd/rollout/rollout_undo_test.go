@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rollout
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/rest/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+type fakeRollbacker struct {
+	live, rolledBack runtime.Object
+	dryRunStrategy   cmdutil.DryRunStrategy
+	rollbacks        int
+}
+
+func (r *fakeRollbacker) Rollback(obj runtime.Object, updatedAnnotations map[string]string, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
+	r.rollbacks++
+	return "rolled back", nil
+}
+
+func (r *fakeRollbacker) PreviewRollback(obj runtime.Object, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (runtime.Object, runtime.Object, error) {
+	r.dryRunStrategy = dryRunStrategy
+	return r.live, r.rolledBack, nil
+}
+
+func TestRolloutUndoDiff(t *testing.T) {
+	deployment := func(image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "test"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: image}}},
+			}},
+		}
+	}
+	rollbacker := &fakeRollbacker{live: deployment("nginx:v2"), rolledBack: deployment("nginx:v1")}
+	defer func(fn polymorphichelpers.RollbackerFunc) { polymorphichelpers.RollbackerFn = fn }(polymorphichelpers.RollbackerFn)
+	polymorphichelpers.RollbackerFn = func(genericclioptions.RESTClientGetter, *meta.RESTMapping) (polymorphichelpers.Rollbacker, error) {
+		return rollbacker, nil
+	}
+
+	gv := schema.GroupVersion{Group: "apps", Version: "v1"}
+	ns := scheme.Codecs.WithoutConversion()
+	info, _ := runtime.SerializerInfoForMediaType(ns.SupportedMediaTypes(), runtime.ContentTypeJSON)
+	encoder := ns.EncoderForVersion(info.Serializer, gv)
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         gv,
+		NegotiatedSerializer: ns,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			switch p, m := req.URL.Path, req.Method; {
+			case p == "/namespaces/test/deployments/nginx" && m == http.MethodGet:
+				body := io.NopCloser(bytes.NewReader([]byte(runtime.EncodeOrDie(encoder, deployment("nginx:v2")))))
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: body}, nil
+			default:
+				t.Fatalf("unexpected request: %#v\n%#v", req.URL, req)
+				return nil, nil
+			}
+		}),
+	}
+
+	streams, _, buf, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdRolloutUndo(tf, streams)
+	cmd.Flags().Set("dry-run", "server")
+	cmd.Flags().Set("output", "diff")
+	cmd.Run(cmd, []string{"deployment/nginx"})
+
+	if rollbacker.dryRunStrategy != cmdutil.DryRunServer {
+		t.Errorf("expected a server dry run, got %v", rollbacker.dryRunStrategy)
+	}
+	for _, line := range []string{"-      - image: nginx:v2", "+      - image: nginx:v1"} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("expected the diff to contain %q, got\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestRolloutUndoDiffRequiresDryRun(t *testing.T) {
+	o := NewRolloutUndoOptions(genericiooptions.NewTestIOStreamsDiscard())
+	o.Resources = []string{"deployment/nginx"}
+	o.Diff = true
+	if err := o.Validate(); err == nil || err.Error() != "-o diff requires --dry-run" {
+		t.Errorf("expected an error, got %v", err)
+	}
+}
@@ -86,7 +86,11 @@ var (
 		kubectl run nginx --image=nginx -- <arg1> <arg2> ... <argN>
 
 		# Start the nginx pod using a different command and custom arguments
-		kubectl run nginx --image=nginx --command -- <cmd> <arg1> ... <argN>`))
+		kubectl run nginx --image=nginx --command -- <cmd> <arg1> ... <argN>
+
+		# Start a nginx pod that waits for a database in an init container and runs a sidecar sharing its process namespace
+		kubectl run nginx --image=nginx --init-image="image=busybox,command=sh -c 'until nc -z db 5432; do sleep 1; done'" \
+		  --sidecar-image=name=busybox,command="sleep infinity" --share-process-namespace`))
 )
 
 const (
@@ -205,6 +209,9 @@ func addRunFlags(cmd *cobra.Command, opt *RunOptions) {
 	cmd.Flags().BoolVar(&opt.Expose, "expose", opt.Expose, "If true, create a ClusterIP service associated with the pod.  Requires `--port`.")
 	cmd.Flags().BoolVarP(&opt.Quiet, "quiet", "q", opt.Quiet, "If true, suppress prompt messages.")
 	cmd.Flags().BoolVar(&opt.Privileged, "privileged", opt.Privileged, i18n.T("If true, run the container in privileged mode."))
+	cmd.Flags().StringArray("init-image", []string{}, i18n.T("An init container to run before the container, given as an image or as name=NAME,image=IMAGE,command=COMMAND. The command has to come last."))
+	cmd.Flags().StringArray("sidecar-image", []string{}, i18n.T("A sidecar container to run alongside the container, in the same format as --init-image. Sidecars are started as init containers with restartPolicy Always."))
+	cmd.Flags().Bool("share-process-namespace", false, i18n.T("If true, share a single process namespace between all the containers of the pod."))
 	cmdutil.AddFieldManagerFlagVar(cmd, &opt.fieldManager, "kubectl-run")
 	opt.AddOverrideFlags(cmd)
 }
@@ -321,6 +328,8 @@ func (o *RunOptions) Run(f cmdutil.Factory, cmd *cobra.Command, args []string) e
 
 	params["annotations"] = cmdutil.GetFlagStringArray(cmd, "annotations")
 	params["env"] = cmdutil.GetFlagStringArray(cmd, "env")
+	params["init-image"] = cmdutil.GetFlagStringArray(cmd, "init-image")
+	params["sidecar-image"] = cmdutil.GetFlagStringArray(cmd, "sidecar-image")
 
 	var createdObjects = []*RunObject{}
 	runObject, err := o.createGeneratedObject(f, cmd, generator, names, params, o.NewOverrider(&corev1.Pod{}))
@@ -17,6 +17,7 @@ limitations under the License.
 package scale
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -24,12 +25,20 @@ import (
 	"k8s.io/klog/v2"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/kubernetes"
+	scaleclient "k8s.io/client-go/scale"
+	watchtools "k8s.io/client-go/tools/watch"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scale"
 	"k8s.io/kubectl/pkg/util/completion"
@@ -45,7 +54,12 @@ var (
 
 		If --current-replicas or --resource-version is specified, it is validated before the
 		scale is attempted, and it is guaranteed that the precondition holds true when the
-		scale is sent to the server.`))
+		scale is sent to the server.
+
+		With --wait, the command waits for the scaled resources to have the new number of
+		replicas ready, for up to --timeout. The ready replicas are read from the
+		status.readyReplicas field of the resources, or from the replicas of the status of
+		their scale subresource when they have no such field.`))
 
 	scaleExample = templates.Examples(i18n.T(`
 		# Scale a replica set named 'foo' to 3
@@ -61,7 +75,10 @@ var (
 		kubectl scale --replicas=5 rc/example1 rc/example2 rc/example3
 
 		# Scale stateful set named 'web' to 3
-		kubectl scale --replicas=3 statefulset/web`))
+		kubectl scale --replicas=3 statefulset/web
+
+		# Scale the deployment named mysql to 3 and wait up to 5 minutes for the 3 replicas to be ready
+		kubectl scale --replicas=3 deployment/mysql --wait --timeout=5m`))
 )
 
 type ScaleOptions struct {
@@ -76,6 +93,8 @@ type ScaleOptions struct {
 	ResourceVersion string
 	CurrentReplicas int
 	Timeout         time.Duration
+	// Wait waits for the new number of replicas to be ready.
+	Wait bool
 
 	Recorder                     genericclioptions.Recorder
 	builder                      *resource.Builder
@@ -85,9 +104,11 @@ type ScaleOptions struct {
 	shortOutput                  bool
 	clientSet                    kubernetes.Interface
 	scaler                       scale.Scaler
+	scalesGetter                 scaleclient.ScalesGetter
 	unstructuredClientForMapping func(mapping *meta.RESTMapping) (resource.RESTClient, error)
 	parent                       string
 	dryRunStrategy               cmdutil.DryRunStrategy
+	waitInterval                 time.Duration
 
 	genericiooptions.IOStreams
 }
@@ -99,6 +120,7 @@ func NewScaleOptions(ioStreams genericiooptions.IOStreams) *ScaleOptions {
 		CurrentReplicas: -1,
 		Recorder:        genericclioptions.NoopRecorder{},
 		IOStreams:       ioStreams,
+		waitInterval:    time.Second,
 	}
 }
 
@@ -130,9 +152,11 @@ func NewCmdScale(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cobra
 	cmd.Flags().IntVar(&o.CurrentReplicas, "current-replicas", o.CurrentReplicas, "Precondition for current size. Requires that the current size of the resource match this value in order to scale. -1 (default) for no condition.")
 	cmd.Flags().IntVar(&o.Replicas, "replicas", o.Replicas, "The new desired number of replicas. Required.")
 	cmd.MarkFlagRequired("replicas")
-	cmd.Flags().DurationVar(&o.Timeout, "timeout", 0, "The length of time to wait before giving up on a scale operation, zero means don't wait. Any other values should contain a corresponding time unit (e.g. 1s, 2m, 3h).")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", 0, "The length of time to wait before giving up on a scale operation, zero means don't wait, or wait forever with --wait. Any other values should contain a corresponding time unit (e.g. 1s, 2m, 3h).")
+	cmd.Flags().BoolVar(&o.Wait, "wait", o.Wait, "If true, wait for the new number of replicas of the resources to be ready.")
 	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "identifying the resource to set a new size")
 	cmdutil.AddDryRunFlag(cmd)
+	cmdutil.AddConfirmationFlag(cmd)
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.Selector)
 	return cmd
 }
@@ -167,10 +191,11 @@ func (o *ScaleOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []st
 	if err != nil {
 		return err
 	}
-	o.scaler, err = scaler(f)
+	o.scalesGetter, err = cmdutil.ScaleClientFn(f)
 	if err != nil {
 		return err
 	}
+	o.scaler = scale.NewScaler(o.scalesGetter)
 	o.unstructuredClientForMapping = f.UnstructuredClientForMapping
 	o.parent = cmd.Parent().Name()
 
@@ -186,6 +211,10 @@ func (o *ScaleOptions) Validate() error {
 		return fmt.Errorf("The --current-replicas must specify an integer of -1 or greater")
 	}
 
+	if o.Timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+
 	return nil
 }
 
@@ -224,7 +253,7 @@ func (o *ScaleOptions) RunScale() error {
 	retry := scale.NewRetryParams(1*time.Second, 5*time.Minute)
 
 	var waitForReplicas *scale.RetryParams
-	if o.Timeout != 0 && o.dryRunStrategy == cmdutil.DryRunNone {
+	if o.Timeout != 0 && !o.Wait && o.dryRunStrategy == cmdutil.DryRunNone {
 		waitForReplicas = scale.NewRetryParams(1*time.Second, o.Timeout)
 	}
 
@@ -232,6 +261,7 @@ func (o *ScaleOptions) RunScale() error {
 		return fmt.Errorf("no objects passed to scale")
 	}
 
+	scaled := []*resource.Info{}
 	for _, info := range infos {
 		mapping := info.ResourceMapping()
 		if o.dryRunStrategy == cmdutil.DryRunClient {
@@ -263,16 +293,103 @@ func (o *ScaleOptions) RunScale() error {
 		if err != nil {
 			return err
 		}
+		scaled = append(scaled, info)
+	}
+
+	if o.Wait && o.dryRunStrategy == cmdutil.DryRunNone {
+		ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), o.Timeout)
+		defer cancel()
+		for _, info := range scaled {
+			if err := o.waitForReadyReplicas(ctx, info); err != nil {
+				return err
+			}
+		}
 	}
 
 	return infoErr
 }
 
-func scaler(f cmdutil.Factory) (scale.Scaler, error) {
-	scalesGetter, err := cmdutil.ScaleClientFn(f)
+// readyReplicasKinds are the kinds known to report their ready replicas in
+// status.readyReplicas, omitting the field when there are none.
+var readyReplicasKinds = sets.New(
+	schema.GroupKind{Group: "apps", Kind: "Deployment"},
+	schema.GroupKind{Group: "apps", Kind: "ReplicaSet"},
+	schema.GroupKind{Group: "apps", Kind: "StatefulSet"},
+	schema.GroupKind{Group: "", Kind: "ReplicationController"},
+)
+
+// waitForReadyReplicas waits until the resource has the new number of
+// replicas ready, writing the progress to ErrOut. Failures to get the
+// resource are retried until the wait times out.
+func (o *ScaleOptions) waitForReadyReplicas(ctx context.Context, info *resource.Info) error {
+	mapping := info.ResourceMapping()
+	client, err := o.unstructuredClientForMapping(mapping)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	helper := resource.NewHelper(client, mapping)
+	knownKind := readyReplicasKinds.Has(mapping.GroupVersionKind.GroupKind())
 
-	return scale.NewScaler(scalesGetter), nil
+	lastReady := int64(-1)
+	var lastErr error
+	err = wait.PollUntilContextCancel(ctx, o.waitInterval, true, func(ctx context.Context) (bool, error) {
+		currentScale, err := o.scalesGetter.Scales(info.Namespace).Get(ctx, mapping.Resource.GroupResource(), info.Name, metav1.GetOptions{})
+		if err != nil {
+			klog.V(2).Infof("Unable to get the scale of %s, retrying: %v", info.ObjectName(), err)
+			lastErr = err
+			return false, nil
+		}
+		// the replicas were changed by something else, such as an autoscaler
+		if currentScale.Spec.Replicas != int32(o.Replicas) {
+			fmt.Fprintf(o.ErrOut, "The replicas of %s were changed to %d, not waiting for them to be ready\n", info.ObjectName(), currentScale.Spec.Replicas)
+			return true, nil
+		}
+		obj, err := helper.Get(info.Namespace, info.Name)
+		if err != nil {
+			klog.V(2).Infof("Unable to get %s, retrying: %v", info.ObjectName(), err)
+			lastErr = err
+			return false, nil
+		}
+		lastErr = nil
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return false, err
+		}
+
+		ready, observed := readyReplicas(content, knownKind, int64(currentScale.Status.Replicas))
+		if !observed {
+			return false, nil
+		}
+		if ready != lastReady {
+			fmt.Fprintf(o.ErrOut, "Waiting for %s: %d of %d replicas ready\n", info.ObjectName(), ready, o.Replicas)
+			lastReady = ready
+		}
+		return ready == int64(o.Replicas) && currentScale.Status.Replicas == int32(o.Replicas), nil
+	})
+	if wait.Interrupted(err) {
+		if lastErr != nil {
+			return fmt.Errorf("timed out waiting for %s to have %d ready replicas: %v", info.ObjectName(), o.Replicas, lastErr)
+		}
+		return fmt.Errorf("timed out waiting for %s to have %d ready replicas", info.ObjectName(), o.Replicas)
+	}
+	return err
+}
+
+// readyReplicas returns the number of ready replicas of the object, from
+// status.readyReplicas or, for the kinds not known to report it when the
+// field is missing, from the replicas of the status of its scale
+// subresource. It also returns whether the status reflects the latest
+// generation of the object.
+func readyReplicas(obj map[string]interface{}, knownKind bool, statusReplicas int64) (int64, bool) {
+	generation, _, _ := unstructured.NestedInt64(obj, "metadata", "generation")
+	observedGeneration, found, _ := unstructured.NestedInt64(obj, "status", "observedGeneration")
+	if found && observedGeneration < generation {
+		return 0, false
+	}
+
+	ready, found, err := unstructured.NestedInt64(obj, "status", "readyReplicas")
+	if err != nil || (!found && !knownKind) {
+		return statusReplicas, true
+	}
+	return ready, true
 }
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scale
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest/fake"
+	fakescale "k8s.io/client-go/scale/fake"
+	testcore "k8s.io/client-go/testing"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+func TestReadyReplicas(t *testing.T) {
+	tests := []struct {
+		name             string
+		obj              map[string]interface{}
+		knownKind        bool
+		statusReplicas   int64
+		expectedReady    int64
+		expectedObserved bool
+	}{
+		{
+			name:             "ready replicas",
+			obj:              map[string]interface{}{"status": map[string]interface{}{"readyReplicas": int64(2)}},
+			knownKind:        true,
+			statusReplicas:   3,
+			expectedReady:    2,
+			expectedObserved: true,
+		},
+		{
+			name:             "no ready replicas of a known kind",
+			obj:              map[string]interface{}{"status": map[string]interface{}{}},
+			knownKind:        true,
+			statusReplicas:   3,
+			expectedReady:    0,
+			expectedObserved: true,
+		},
+		{
+			name:             "no ready replicas of another kind",
+			obj:              map[string]interface{}{"status": map[string]interface{}{}},
+			statusReplicas:   3,
+			expectedReady:    3,
+			expectedObserved: true,
+		},
+		{
+			name: "generation not observed",
+			obj: map[string]interface{}{
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"status":   map[string]interface{}{"observedGeneration": int64(1), "readyReplicas": int64(3)},
+			},
+			knownKind: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ready, observed := readyReplicas(tc.obj, tc.knownKind, tc.statusReplicas)
+			if ready != tc.expectedReady || observed != tc.expectedObserved {
+				t.Errorf("expected %d ready replicas and observed %v, got %d and %v", tc.expectedReady, tc.expectedObserved, ready, observed)
+			}
+		})
+	}
+}
+
+func TestWaitForReadyReplicas(t *testing.T) {
+	tests := []struct {
+		name          string
+		readyReplicas []int32
+		specReplicas  int32
+		// scaleErrors and getErrors are the numbers of gets of the scale and
+		// of the object failing first
+		scaleErrors    int
+		getErrors      int
+		timeout        time.Duration
+		expectedErrOut string
+		expectedErr    string
+	}{
+		{
+			name:           "ready",
+			readyReplicas:  []int32{1, 1, 3},
+			specReplicas:   3,
+			expectedErrOut: "Waiting for deployments/nginx: 1 of 3 replicas ready\nWaiting for deployments/nginx: 3 of 3 replicas ready\n",
+		},
+		{
+			name:           "transient errors",
+			readyReplicas:  []int32{3},
+			specReplicas:   3,
+			scaleErrors:    2,
+			getErrors:      1,
+			expectedErrOut: "Waiting for deployments/nginx: 3 of 3 replicas ready\n",
+		},
+		{
+			name:         "timed out on errors",
+			specReplicas: 3,
+			getErrors:    1000,
+			timeout:      50 * time.Millisecond,
+			expectedErr:  "timed out waiting for deployments/nginx to have 3 ready replicas: an error on the server",
+		},
+		{
+			name:           "replicas changed",
+			readyReplicas:  []int32{1},
+			specReplicas:   5,
+			expectedErrOut: "The replicas of deployments/nginx were changed to 5, not waiting for them to be ready\n",
+		},
+		{
+			name:          "timed out",
+			readyReplicas: []int32{1},
+			specReplicas:  3,
+			timeout:       50 * time.Millisecond,
+			expectedErr:   "timed out waiting for deployments/nginx to have 3 ready replicas",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scaleClient := &fakescale.FakeScaleClient{}
+			scaleGets := 0
+			scaleClient.AddReactor("get", "deployments", func(action testcore.Action) (bool, runtime.Object, error) {
+				scaleGets++
+				if scaleGets <= tc.scaleErrors {
+					return true, nil, apierrors.NewServiceUnavailable("unavailable")
+				}
+				return true, &autoscalingv1.Scale{
+					ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "test"},
+					Spec:       autoscalingv1.ScaleSpec{Replicas: tc.specReplicas},
+					Status:     autoscalingv1.ScaleStatus{Replicas: 3},
+				}, nil
+			})
+
+			gets := 0
+			codec := scheme.Codecs.LegacyCodec(appsv1.SchemeGroupVersion)
+			client := &fake.RESTClient{
+				NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+				Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+					if tc.getErrors > 0 {
+						tc.getErrors--
+						return &http.Response{StatusCode: http.StatusInternalServerError, Header: cmdtesting.DefaultHeader(), Body: io.NopCloser(strings.NewReader(""))}, nil
+					}
+					ready := tc.readyReplicas[min(gets, len(tc.readyReplicas)-1)]
+					gets++
+					deployment := &appsv1.Deployment{
+						ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "test"},
+						Status:     appsv1.DeploymentStatus{Replicas: 3, ReadyReplicas: ready},
+					}
+					return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, deployment)}, nil
+				}),
+			}
+
+			streams, _, _, errOut := genericiooptions.NewTestIOStreams()
+			o := NewScaleOptions(streams)
+			o.Replicas = 3
+			o.waitInterval = time.Millisecond
+			o.scalesGetter = scaleClient
+			o.unstructuredClientForMapping = func(*meta.RESTMapping) (resource.RESTClient, error) {
+				return client, nil
+			}
+			info := &resource.Info{
+				Name:      "nginx",
+				Namespace: "test",
+				Mapping: &meta.RESTMapping{
+					Resource:         appsv1.SchemeGroupVersion.WithResource("deployments"),
+					GroupVersionKind: appsv1.SchemeGroupVersion.WithKind("Deployment"),
+					Scope:            meta.RESTScopeNamespace,
+				},
+			}
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+			err := o.waitForReadyReplicas(ctx, info)
+			if len(tc.expectedErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if errOut.String() != tc.expectedErrOut {
+				t.Errorf("expected output %q, got %q", tc.expectedErrOut, errOut.String())
+			}
+		})
+	}
+}
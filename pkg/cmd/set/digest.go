@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import (
+	"github.com/distribution/reference"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/util/registry"
+)
+
+// digestResolver resolves image tags to the digest the registry currently
+// serves for them.
+type digestResolver struct {
+	registry *registry.Client
+	// resolved caches the digests of the images resolved so far.
+	resolved map[string]string
+}
+
+// newDigestResolver returns a resolver authenticating with the docker config
+// file in $DOCKER_CONFIG or ~/.docker.
+func newDigestResolver() (*digestResolver, error) {
+	client, err := registry.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	return &digestResolver{
+		registry: client,
+		resolved: map[string]string{},
+	}, nil
+}
+
+// Resolve returns the image pinned to the digest of its tag, keeping the tag
+// for readability. Images that already name a digest are returned as is.
+func (r *digestResolver) Resolve(image string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := named.(reference.Canonical); ok {
+		return image, nil
+	}
+	if digest, ok := r.resolved[image]; ok {
+		return image + "@" + digest, nil
+	}
+	tag := reference.TagNameOnly(named).(reference.NamedTagged).Tag()
+	manifest, err := r.registry.Manifest(named, tag, registry.ManifestMediaTypes...)
+	if err != nil {
+		return "", err
+	}
+	r.resolved[image] = manifest.Digest
+	klog.V(2).Infof("resolved image %s to %s", image, manifest.Digest)
+	return image + "@" + manifest.Digest, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func newTestRegistry(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/token":
+			username, password, ok := req.BasicAuth()
+			if !ok || username != "user" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if scope := req.URL.Query().Get("scope"); scope != "repository:team/app:pull" {
+				t.Errorf("unexpected scope %q", scope)
+			}
+			fmt.Fprint(w, `{"token":"registry-token"}`)
+		case req.URL.Path == "/v2/team/app/manifests/v1":
+			if req.Header.Get("Authorization") != "Bearer registry-token" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !strings.Contains(req.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+				t.Errorf("expected image indexes to be accepted, got %q", req.Header.Get("Accept"))
+			}
+			w.Header().Set("Docker-Content-Digest", testDigest)
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDigestResolver(t *testing.T) {
+	server := newTestRegistry(t)
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	dir := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	config := fmt.Sprintf(`{"auths":{"https://%s/v1/":{"auth":%q}}}`, host, auth)
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_CONFIG", dir)
+
+	resolver, err := newDigestResolver()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver.registry.HTTPClient = server.Client()
+
+	image := host + "/team/app:v1"
+	resolved, err := resolver.Resolve(image)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := image + "@" + testDigest; resolved != expected {
+		t.Errorf("expected %s, got %s", expected, resolved)
+	}
+
+	pinned := host + "/team/app@" + testDigest
+	if resolved, err := resolver.Resolve(pinned); err != nil || resolved != pinned {
+		t.Errorf("expected pinned images to be kept, got %s: %v", resolved, err)
+	}
+
+	if _, err := resolver.Resolve(host + "/team/app:missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+	if resolver, err = newDigestResolver(); err != nil {
+		t.Fatal(err)
+	}
+	resolver.registry.HTTPClient = server.Client()
+	if _, err := resolver.Resolve(image); err == nil {
+		t.Errorf("expected resolving without credentials to fail")
+	}
+}
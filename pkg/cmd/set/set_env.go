@@ -97,7 +97,13 @@ var (
 	  kubectl set env -f deploy.json ENV-
 
 	  # Set some of the local shell environment into a deployment config on the server
-	  env | grep RAILS_ | kubectl set env -e - deployment/registry`)
+	  env | grep RAILS_ | kubectl set env -e - deployment/registry
+
+	  # Import environment from a .env file with a prefix
+	  kubectl set env --from-env-file=path/.env --prefix=APP_ deployment/myapp
+
+	  # Import specific keys from a secret, removing the variables with the prefix that are no longer in the secret
+	  kubectl set env --from=secret/mysecret --keys=a,b,c --prefix=APP_ --sync deployment/myapp`)
 )
 
 // EnvOptions holds values for 'set env' command-lone options
@@ -116,6 +122,8 @@ type EnvOptions struct {
 	From              string
 	Prefix            string
 	Keys              []string
+	EnvFiles          []string
+	Sync              bool
 	fieldManager      string
 
 	PrintObj printers.ResourcePrinterFunc
@@ -168,6 +176,8 @@ func NewCmdEnv(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Com
 	cmd.Flags().StringVarP(&o.From, "from", "", "", "The name of a resource from which to inject environment variables")
 	cmd.Flags().StringVarP(&o.Prefix, "prefix", "", "", "Prefix to append to variable names")
 	cmd.Flags().StringArrayVarP(&o.EnvParams, "env", "e", o.EnvParams, "Specify a key-value pair for an environment variable to set into each container.")
+	cmd.Flags().StringArrayVar(&o.EnvFiles, "from-env-file", o.EnvFiles, "Specify the path to a file to read lines of key=val pairs to set as environment variables (i.e. a Docker .env file).")
+	cmd.Flags().BoolVar(&o.Sync, "sync", o.Sync, "If true, remove the environment variables of the containers that are not given or imported by this command. With --prefix, only variables with the prefix are removed.")
 	cmd.Flags().StringSliceVarP(&o.Keys, "keys", "", o.Keys, "Comma-separated list of keys to import from specified resource")
 	cmd.Flags().BoolVar(&o.List, "list", o.List, "If true, display the environment and any changes in the standard format. this flag will removed when we have kubectl view env.")
 	cmd.Flags().BoolVar(&o.Resolve, "resolve", o.Resolve, "If true, show secret or configmap references when listing variables")
@@ -270,6 +280,12 @@ func (o *EnvOptions) Validate() error {
 	if len(o.Keys) > 0 && len(o.From) == 0 {
 		return fmt.Errorf("when specifying --keys, a configmap or secret must be provided with --from")
 	}
+	if o.Sync && len(o.EnvParams) == 0 && len(o.envArgs) == 0 && len(o.EnvFiles) == 0 && len(o.From) == 0 {
+		return fmt.Errorf("--sync requires environment variables to be given or imported with --from or --from-env-file")
+	}
+	if o.Sync && o.List {
+		return fmt.Errorf("--sync and --list may not be specified together")
+	}
 	if o.WarningPrinter == nil {
 		return fmt.Errorf("WarningPrinter can not be used without initialization")
 	}
@@ -283,6 +299,19 @@ func (o *EnvOptions) RunEnv() error {
 		return err
 	}
 
+	for _, envFile := range o.EnvFiles {
+		err := cmdutil.AddFromEnvFile(envFile, func(key, value string) error {
+			// variables given as arguments take precedence
+			if _, found := findEnv(env, key); !found {
+				env = append(env, v1.EnvVar{Name: key, Value: value})
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
 	if len(o.From) != 0 {
 		b := o.builder().
 			WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
@@ -436,7 +465,11 @@ func (o *EnvOptions) RunEnv() error {
 					}
 				}
 
-				c.Env = updateEnv(c.Env, env, remove)
+				if o.Sync {
+					c.Env = updateEnv(c.Env, env, append(remove, o.unsyncedEnv(c.Env, env)...))
+				} else {
+					c.Env = updateEnv(c.Env, env, remove)
+				}
 				if o.List {
 					resolveErrors := map[string][]string{}
 					store := envutil.NewResourceStore()
@@ -541,3 +574,18 @@ func (o *EnvOptions) RunEnv() error {
 	}
 	return utilerrors.NewAggregate(allErrs)
 }
+
+// unsyncedEnv returns the names of the existing variables that --sync
+// removes, which are those not in env and, with --prefix, having the prefix.
+func (o *EnvOptions) unsyncedEnv(existing []v1.EnvVar, env []v1.EnvVar) []string {
+	var names []string
+	for _, e := range existing {
+		if !strings.HasPrefix(e.Name, o.Prefix) {
+			continue
+		}
+		if _, found := findEnv(env, e.Name); !found {
+			names = append(names, e.Name)
+		}
+	}
+	return names
+}
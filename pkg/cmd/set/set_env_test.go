@@ -17,9 +17,12 @@ limitations under the License.
 package set
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -35,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/rest/fake"
@@ -116,6 +120,91 @@ func TestSetEnvLocalNamespace(t *testing.T) {
 	}
 }
 
+func TestSetEnvFromEnvFileSync(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Version: ""},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			t.Fatalf("unexpected request: %s %#v\n%#v", req.Method, req.URL, req)
+			return nil, nil
+		}),
+	}
+	tf.ClientConfigVal = &restclient.Config{ContentConfig: restclient.ContentConfig{GroupVersion: &schema.GroupVersion{Version: ""}}}
+
+	dir := t.TempDir()
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+      - name: app
+        image: app
+        env:
+        - name: APP_OLD
+          value: "1"
+        - name: APP_KEEP
+          value: old
+        - name: OTHER
+          value: kept
+`
+	if err := os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte(deployment), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("# settings\nKEEP=new\nNEW=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	streams, _, buf, bufErr := genericiooptions.NewTestIOStreams()
+	opts := NewEnvOptions(streams)
+	opts.PrintFlags = genericclioptions.NewPrintFlags("").WithDefaultOutput("json").WithTypeSetter(scheme.Scheme)
+	opts.FilenameOptions = resource.FilenameOptions{
+		Filenames: []string{filepath.Join(dir, "deployment.yaml")},
+	}
+	opts.Local = true
+	opts.EnvFiles = []string{filepath.Join(dir, ".env")}
+	opts.Prefix = "APP_"
+	opts.Sync = true
+
+	err := opts.Complete(tf, NewCmdEnv(tf, streams), []string{})
+	assert.NoError(t, err)
+	err = opts.Validate()
+	assert.NoError(t, err)
+	err = opts.RunEnv()
+	assert.NoError(t, err)
+	if bufErr.Len() > 0 {
+		t.Errorf("unexpected error: %s", bufErr.String())
+	}
+
+	updated := &appsv1.Deployment{}
+	if err := json.Unmarshal(buf.Bytes(), updated); err != nil {
+		t.Fatalf("unexpected output %s: %v", buf.String(), err)
+	}
+	expected := []corev1.EnvVar{
+		{Name: "APP_KEEP", Value: "new"},
+		{Name: "OTHER", Value: "kept"},
+		{Name: "APP_NEW", Value: "2"},
+	}
+	assert.Equal(t, expected, updated.Spec.Template.Spec.Containers[0].Env)
+
+	opts = NewEnvOptions(streams)
+	opts.Sync = true
+	opts.resources = []string{"deployment/app"}
+	opts.WarningPrinter = printers.NewWarningPrinter(bufErr, printers.WarningPrinterOptions{})
+	assert.Error(t, opts.Validate(), "expected --sync without a source to be rejected")
+}
+
 func TestSetMultiResourcesEnvLocal(t *testing.T) {
 	tf := cmdtesting.NewTestFactory().WithNamespace("test")
 	defer tf.Cleanup()
@@ -53,6 +53,7 @@ type SetImageOptions struct {
 	Output         string
 	Local          bool
 	ResolveImage   ImageResolverFunc
+	ResolveDigests bool
 	fieldManager   string
 
 	PrintObj printers.ResourcePrinterFunc
@@ -94,7 +95,10 @@ var (
 		kubectl set image daemonset abc *=nginx:1.9.1
 
 		# Print result (in yaml format) of updating nginx container image from local file, without hitting the server
-		kubectl set image -f path/to/file.yaml nginx=nginx:1.9.1 --local -o yaml`)
+		kubectl set image -f path/to/file.yaml nginx=nginx:1.9.1 --local -o yaml
+
+		# Set a deployment's nginx container image to the digest 'nginx:1.9.1' currently points to
+		kubectl set image deployment/nginx nginx=nginx:1.9.1 --resolve-digests`)
 )
 
 // NewImageOptions returns an initialized SetImageOptions instance
@@ -133,6 +137,7 @@ func NewCmdImage(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
 	cmd.Flags().BoolVar(&o.All, "all", o.All, "Select all resources, in the namespace of the specified resource types")
 	cmd.Flags().BoolVar(&o.Local, "local", o.Local, "If true, set image will NOT contact api-server but run locally.")
+	cmd.Flags().BoolVar(&o.ResolveDigests, "resolve-digests", o.ResolveDigests, "If true, pin each image to the digest its tag currently points to, as image:tag@sha256:..., by asking the registry. Credentials are read from the docker config file.")
 	cmdutil.AddDryRunFlag(cmd)
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.fieldManager, "kubectl-set")
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.Selector)
@@ -158,6 +163,20 @@ func (o *SetImageOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args [
 
 	o.Output = cmdutil.GetFlagString(cmd, "output")
 	o.ResolveImage = ImageResolver
+	if o.ResolveDigests {
+		resolver, err := newDigestResolver()
+		if err != nil {
+			return err
+		}
+		resolveImage := o.ResolveImage
+		o.ResolveImage = func(in string) (string, error) {
+			image, err := resolveImage(in)
+			if err != nil {
+				return "", err
+			}
+			return resolver.Resolve(image)
+		}
+	}
 
 	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
 	printer, err := o.PrintFlags.ToPrinter()
@@ -18,11 +18,18 @@ package set
 
 import (
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 
 	v1 "k8s.io/api/core/v1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -58,7 +65,17 @@ var (
 		kubectl set resources deployment nginx --limits=cpu=0,memory=0 --requests=cpu=0,memory=0
 
 		# Print the result (in yaml format) of updating nginx container limits from a local, without hitting the server
-		kubectl set resources -f path/to/file.yaml --limits=cpu=200m,memory=512Mi --local -o yaml`)
+		kubectl set resources -f path/to/file.yaml --limits=cpu=200m,memory=512Mi --local -o yaml
+
+		# Set the memory limits of all the sidecar containers in nginx
+		kubectl set resources deployment nginx --containers-regex='-sidecar$' --limits=memory=128Mi
+
+		# Grow the current requests and limits of all containers in the namespace's deployments by half
+		kubectl set resources deployments --all --scale-by=1.5x
+
+		# Set the requests and limits recommended from the usage of the containers in nginx
+		kubectl recommend resources deployment/nginx -o yaml > nginx-resources.yaml
+		kubectl set resources deployment nginx --from-recommendation=nginx-resources.yaml`)
 )
 
 // SetResourcesOptions is the start of the data required to perform the operation. As new fields are added, add them here instead of
@@ -72,6 +89,7 @@ type SetResourcesOptions struct {
 	Infos             []*resource.Info
 	Selector          string
 	ContainerSelector string
+	ContainerRegex    string
 	Output            string
 	All               bool
 	Local             bool
@@ -84,8 +102,14 @@ type SetResourcesOptions struct {
 
 	Limits               string
 	Requests             string
+	ScaleBy              string
+	FromRecommendation   string
 	ResourceRequirements v1.ResourceRequirements
 
+	containerRegex *regexp.Regexp
+	scaleFactor    float64
+	recommendation *cmdutil.ResourceRecommendation
+
 	UpdatePodSpecForObject polymorphichelpers.UpdatePodSpecForObjectFunc
 	Resources              []string
 
@@ -134,9 +158,12 @@ func NewCmdResources(f cmdutil.Factory, streams genericiooptions.IOStreams) *cob
 	cmd.Flags().BoolVar(&o.All, "all", o.All, "Select all resources, in the namespace of the specified resource types")
 	cmdutil.AddLabelSelectorFlagVar(cmd, &o.Selector)
 	cmd.Flags().StringVarP(&o.ContainerSelector, "containers", "c", o.ContainerSelector, "The names of containers in the selected pod templates to change, all containers are selected by default - may use wildcards")
+	cmd.Flags().StringVar(&o.ContainerRegex, "containers-regex", o.ContainerRegex, "A regular expression matching the names of containers in the selected pod templates to change. Cannot be combined with --containers.")
 	cmd.Flags().BoolVar(&o.Local, "local", o.Local, "If true, set resources will NOT contact api-server but run locally.")
 	cmdutil.AddDryRunFlag(cmd)
 	cmd.Flags().StringVar(&o.Limits, "limits", o.Limits, "The resource requirement requests for this container.  For example, 'cpu=100m,memory=256Mi'.  Note that server side components may assign requests depending on the server configuration, such as limit ranges.")
+	cmd.Flags().StringVar(&o.ScaleBy, "scale-by", o.ScaleBy, "Multiply the current requests and limits of the selected containers by this factor, for example '1.5x' or '0.5x', rounding CPU up to whole millicores and memory and ephemeral storage up to whole bytes. Other resources, such as GPUs and hugepages, are left unchanged. Cannot be combined with --limits or --requests.")
+	cmd.Flags().StringVar(&o.FromRecommendation, "from-recommendation", o.FromRecommendation, "A file written by 'kubectl recommend resources -o yaml', or - for the standard input, to set the recommended requests and limits of the containers from. The containers which are not in the recommendation are left unchanged. Cannot be combined with --limits, --requests or --scale-by.")
 	cmd.Flags().StringVar(&o.Requests, "requests", o.Requests, "The resource requirement requests for this container.  For example, 'cpu=100m,memory=256Mi'.  Note that server side components may assign requests depending on the server configuration, such as limit ranges.")
 	cmdutil.AddFieldManagerFlagVar(cmd, &o.fieldManager, "kubectl-set")
 	return cmd
@@ -166,6 +193,21 @@ func (o *SetResourcesOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, ar
 	}
 	o.PrintObj = printer.PrintObj
 
+	if len(o.FromRecommendation) > 0 {
+		var data []byte
+		if o.FromRecommendation == "-" {
+			data, err = io.ReadAll(o.In)
+		} else {
+			data, err = os.ReadFile(o.FromRecommendation)
+		}
+		if err != nil {
+			return err
+		}
+		if o.recommendation, err = cmdutil.ParseResourceRecommendation(data); err != nil {
+			return err
+		}
+	}
+
 	cmdNamespace, enforceNamespace, err := f.ToRawKubeConfigLoader().Namespace()
 	if err != nil && !(o.Local && clientcmd.IsEmptyConfig(err)) {
 		return err
@@ -207,8 +249,31 @@ func (o *SetResourcesOptions) Validate() error {
 	if o.All && len(o.Selector) > 0 {
 		return fmt.Errorf("cannot set --all and --selector at the same time")
 	}
+	if len(o.ContainerRegex) > 0 {
+		if o.ContainerSelector != "*" {
+			return fmt.Errorf("cannot set --containers and --containers-regex at the same time")
+		}
+		if o.containerRegex, err = regexp.Compile(o.ContainerRegex); err != nil {
+			return fmt.Errorf("invalid --containers-regex: %v", err)
+		}
+	}
+	if len(o.FromRecommendation) > 0 {
+		if len(o.Limits) != 0 || len(o.Requests) != 0 || len(o.ScaleBy) != 0 {
+			return fmt.Errorf("cannot set --from-recommendation together with --limits, --requests or --scale-by")
+		}
+		return nil
+	}
+	if len(o.ScaleBy) > 0 {
+		if len(o.Limits) != 0 || len(o.Requests) != 0 {
+			return fmt.Errorf("cannot set --scale-by together with --limits or --requests")
+		}
+		if o.scaleFactor, err = parseScaleFactor(o.ScaleBy); err != nil {
+			return err
+		}
+		return nil
+	}
 	if len(o.Limits) == 0 && len(o.Requests) == 0 {
-		return fmt.Errorf("you must specify an update to requests or limits (in the form of --requests/--limits)")
+		return fmt.Errorf("you must specify an update to requests or limits (in the form of --requests/--limits/--scale-by/--from-recommendation)")
 	}
 
 	o.ResourceRequirements, err = generateversioned.HandleResourceRequirementsV1(map[string]string{"limits": o.Limits, "requests": o.Requests})
@@ -225,11 +290,25 @@ func (o *SetResourcesOptions) Run() error {
 	patches := CalculatePatches(o.Infos, scheme.DefaultJSONEncoder(), func(obj runtime.Object) ([]byte, error) {
 		transformed := false
 		_, err := o.UpdatePodSpecForObject(obj, func(spec *v1.PodSpec) error {
-			initContainers, _ := selectContainers(spec.InitContainers, o.ContainerSelector)
-			containers, _ := selectContainers(spec.Containers, o.ContainerSelector)
-			containers = append(containers, initContainers...)
+			containers := append(o.selectContainers(spec.Containers), o.selectContainers(spec.InitContainers)...)
 			if len(containers) != 0 {
 				for i := range containers {
+					if o.recommendation != nil {
+						if recommended, ok := o.recommendation.Container(containers[i].Name); ok {
+							recommended.ApplyTo(&containers[i].Resources)
+							transformed = true
+						}
+						continue
+					}
+					if o.scaleFactor != 0 {
+						resources := &containers[i].Resources
+						if len(resources.Limits) != 0 || len(resources.Requests) != 0 {
+							resources.Limits = scaleResourceList(resources.Limits, o.scaleFactor)
+							resources.Requests = scaleResourceList(resources.Requests, o.scaleFactor)
+							transformed = true
+						}
+						continue
+					}
 					if len(o.Limits) != 0 && len(containers[i].Resources.Limits) == 0 {
 						containers[i].Resources.Limits = make(v1.ResourceList)
 					}
@@ -245,6 +324,8 @@ func (o *SetResourcesOptions) Run() error {
 					}
 					transformed = true
 				}
+			} else if o.containerRegex != nil {
+				allErrs = append(allErrs, fmt.Errorf("error: unable to find container matching %s", o.ContainerRegex))
 			} else {
 				allErrs = append(allErrs, fmt.Errorf("error: unable to find container named %s", o.ContainerSelector))
 			}
@@ -300,3 +381,51 @@ func (o *SetResourcesOptions) Run() error {
 	}
 	return utilerrors.NewAggregate(allErrs)
 }
+
+// selectContainers returns the containers selected by --containers or
+// --containers-regex.
+func (o *SetResourcesOptions) selectContainers(containers []v1.Container) []*v1.Container {
+	if o.containerRegex != nil {
+		selected := []*v1.Container{}
+		for i := range containers {
+			if o.containerRegex.MatchString(containers[i].Name) {
+				selected = append(selected, &containers[i])
+			}
+		}
+		return selected
+	}
+	selected, _ := selectContainers(containers, o.ContainerSelector)
+	return selected
+}
+
+// parseScaleFactor parses a positive factor such as "1.5x" or "2".
+func parseScaleFactor(value string) (float64, error) {
+	factor, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(value), "x"), 64)
+	if err != nil || math.IsNaN(factor) || factor <= 0 || math.IsInf(factor, 0) {
+		return 0, fmt.Errorf("invalid --scale-by %q, expected a positive factor such as 1.5x", value)
+	}
+	return factor, nil
+}
+
+// scaleResourceList multiplies the CPU, memory and ephemeral storage of list
+// by factor, rounding up CPU to whole millicores and the others to whole
+// bytes. The other resources, such as extended resources and hugepages, are
+// counts or multiples of a size and are left unchanged.
+func scaleResourceList(list v1.ResourceList, factor float64) v1.ResourceList {
+	if list == nil {
+		return nil
+	}
+	scaled := v1.ResourceList{}
+	for name, quantity := range list {
+		value := quantity.AsApproximateFloat64() * factor
+		switch name {
+		case v1.ResourceCPU:
+			scaled[name] = *apiresource.NewMilliQuantity(int64(math.Ceil(value*1000-1e-6)), quantity.Format)
+		case v1.ResourceMemory, v1.ResourceEphemeralStorage:
+			scaled[name] = *apiresource.NewQuantity(int64(math.Ceil(value-1e-6)), quantity.Format)
+		default:
+			scaled[name] = quantity.DeepCopy()
+		}
+	}
+	return scaled
+}
@@ -30,6 +30,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -524,6 +525,7 @@ func TestSetResourcesRemoteWithSpecificContainers(t *testing.T) {
 	inputs := []struct {
 		name     string
 		selector string
+		regex    string
 		args     []string
 
 		expectedContainers int
@@ -546,6 +548,20 @@ func TestSetResourcesRemoteWithSpecificContainers(t *testing.T) {
 			selector:           "redis",
 			expectedContainers: 1,
 		},
+		{
+			name:               "use a regular expression to select some containers",
+			args:               []string{"deployments", "redis"},
+			selector:           "*",
+			regex:              "^in",
+			expectedContainers: 1,
+		},
+		{
+			name:               "use a regular expression to select all containers",
+			args:               []string{"deployments", "redis"},
+			selector:           "*",
+			regex:              "^(init|redis)$",
+			expectedContainers: 2,
+		},
 	}
 
 	for _, input := range inputs {
@@ -615,6 +631,7 @@ func TestSetResourcesRemoteWithSpecificContainers(t *testing.T) {
 
 				Limits:            "cpu=200m,memory=512Mi",
 				ContainerSelector: input.selector,
+				ContainerRegex:    input.regex,
 				IOStreams:         streams,
 			}
 			err := opts.Complete(tf, cmd, input.args)
@@ -628,3 +645,127 @@ func TestSetResourcesRemoteWithSpecificContainers(t *testing.T) {
 		})
 	}
 }
+
+func TestSetResourcesFromRecommendation(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("test")
+	defer tf.Cleanup()
+
+	tf.Client = &fake.RESTClient{
+		GroupVersion:         schema.GroupVersion{Version: ""},
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			t.Fatalf("unexpected request: %s %#v\n%#v", req.Method, req.URL, req)
+			return nil, nil
+		}),
+	}
+	tf.ClientConfigVal = &restclient.Config{ContentConfig: restclient.ContentConfig{GroupVersion: &schema.GroupVersion{Version: ""}}}
+
+	streams, in, buf, _ := genericiooptions.NewTestIOStreams()
+	in.WriteString(`target: replicationcontrollers/cassandra
+source: metrics-server
+containers:
+- name: cassandra
+  resources:
+    requests:
+      cpu: "1"
+      memory: 1Gi
+    limits:
+      memory: 1Gi
+- name: removed
+  resources:
+    requests:
+      cpu: 10m
+`)
+	cmd := NewCmdResources(tf, streams)
+	cmd.Flags().Set("output", "yaml")
+	cmd.Flags().Set("local", "true")
+
+	opts := SetResourcesOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("").WithDefaultOutput("yaml").WithTypeSetter(scheme.Scheme),
+		FilenameOptions: resource.FilenameOptions{
+			Filenames: []string{"../../../testdata/controller.yaml"}},
+		Local:              true,
+		FromRecommendation: "-",
+		ContainerSelector:  "*",
+		IOStreams:          streams,
+	}
+	err := opts.Complete(tf, cmd, []string{})
+	if err == nil {
+		err = opts.Validate()
+	}
+	if err == nil {
+		err = opts.Run()
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the CPU limit lower than the recommended request is raised to it
+	expected := `        resources:
+          limits:
+            cpu: "1"
+            memory: 1Gi
+          requests:
+            cpu: "1"
+            memory: 1Gi
+`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected the recommended resources to be set, got:\n%s", buf.String())
+	}
+
+	opts = SetResourcesOptions{FromRecommendation: "recommendation.yaml", ScaleBy: "2x", ContainerSelector: "*"}
+	if err := opts.Validate(); err == nil || !strings.Contains(err.Error(), "--from-recommendation") {
+		t.Errorf("expected --from-recommendation and --scale-by to conflict, got %v", err)
+	}
+}
+
+func TestScaleResourceList(t *testing.T) {
+	list := corev1.ResourceList{
+		corev1.ResourceCPU:    apiresource.MustParse("100m"),
+		corev1.ResourceMemory: apiresource.MustParse("512Mi"),
+		"nvidia.com/gpu":      apiresource.MustParse("2"),
+		"hugepages-2Mi":       apiresource.MustParse("4Mi"),
+	}
+	odd := corev1.ResourceList{
+		corev1.ResourceCPU:              apiresource.MustParse("1m"),
+		corev1.ResourceMemory:           apiresource.MustParse("1001"),
+		corev1.ResourceEphemeralStorage: apiresource.MustParse("3"),
+	}
+	factor, err := parseScaleFactor("1.5x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scaled := scaleResourceList(list, factor)
+	expected := map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    "150m",
+		corev1.ResourceMemory: "768Mi",
+		// extended resources and hugepages are not scaled
+		"nvidia.com/gpu": "2",
+		"hugepages-2Mi":  "4Mi",
+	}
+	for name, value := range expected {
+		if quantity := scaled[name]; quantity.String() != value {
+			t.Errorf("expected %s to be %s, got %s", name, value, quantity.String())
+		}
+	}
+	// small quantities are rounded up to whole millicores and bytes
+	scaled = scaleResourceList(odd, factor)
+	expected = map[corev1.ResourceName]string{
+		corev1.ResourceCPU:              "2m",
+		corev1.ResourceMemory:           "1502",
+		corev1.ResourceEphemeralStorage: "5",
+	}
+	for name, value := range expected {
+		if quantity := scaled[name]; quantity.String() != value {
+			t.Errorf("expected %s to be %s, got %s", name, value, quantity.String())
+		}
+	}
+	if scaleResourceList(nil, factor) != nil {
+		t.Errorf("expected no resources to stay unset")
+	}
+
+	for _, value := range []string{"0x", "-1x", "x", "twice", "+Infx", "NaN"} {
+		if _, err := parseScaleFactor(value); err == nil {
+			t.Errorf("expected %q to be invalid", value)
+		}
+	}
+}
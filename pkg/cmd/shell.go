@@ -0,0 +1,357 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/tools/clientcmd"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	shellLong = templates.LongDesc(i18n.T(`
+		Run kubectl commands interactively.
+
+		Each line holds the arguments of a kubectl command without the leading kubectl,
+		quoted as in a shell. The commands run in a single process, sharing the
+		connections to the server, the discovery cache and the credentials of exec
+		plugins, which is much faster than running kubectl for each command.
+
+		The prompt shows the current context and namespace. They are changed for the
+		next commands with:
+
+		* context NAME: use the context NAME, and its namespace
+		* ns NAMESPACE: use the namespace NAMESPACE
+
+		Commands, resources and flags are completed with the tab key, and the previous
+		commands are recalled with the up and down keys. The commands are recorded in
+		the command history, see kubectl history. Exit with exit, Ctrl-C or Ctrl-D.
+
+		The global flags given to shell apply to every command. Interrupting a command
+		with Ctrl-C stops the shell.`))
+
+	shellExample = templates.Examples(i18n.T(`
+		# Start a shell
+		kubectl shell
+
+		# Start a shell in the context prod and the namespace kube-system
+		kubectl shell --context prod -n kube-system`))
+)
+
+// ShellOptions holds the options of the shell command
+type ShellOptions struct {
+	// Context and Namespace are the context and the namespace of the
+	// commands, the ones of the kubeconfig when empty.
+	Context   string
+	Namespace string
+
+	configAccess clientcmd.ConfigAccess
+
+	commandRunner
+}
+
+func newCmdShell(newCommand func(streams genericiooptions.IOStreams, args []string) *cobra.Command, restClientGetter genericclioptions.RESTClientGetter, streams genericiooptions.IOStreams) *cobra.Command {
+	o := &ShellOptions{
+		commandRunner: commandRunner{
+			NewCommand: newCommand,
+			IOStreams:  streams,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "shell",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Run kubectl commands interactively"),
+		Long:                  shellLong,
+		Example:               shellExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			o.configAccess = restClientGetter.ToRawKubeConfigLoader().ConfigAccess()
+			cmdutil.CheckErr(o.Complete(cmd))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	return cmd
+}
+
+// Complete completes the options of the shell command
+func (o *ShellOptions) Complete(cmd *cobra.Command) error {
+	o.commandRunner.complete(cmd)
+
+	// the context and the namespace are given to the commands separately, as
+	// they can be changed
+	globalArgs := []string{}
+	for _, arg := range o.GlobalArgs {
+		switch {
+		case strings.HasPrefix(arg, "--context="):
+			o.Context = strings.TrimPrefix(arg, "--context=")
+		case strings.HasPrefix(arg, "--namespace="):
+			o.Namespace = strings.TrimPrefix(arg, "--namespace=")
+		default:
+			globalArgs = append(globalArgs, arg)
+		}
+	}
+	o.GlobalArgs = globalArgs
+	return nil
+}
+
+// Run reads and runs the commands until the end of the input
+func (o *ShellOptions) Run() error {
+	file, ok := o.In.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		// the commands can not read the standard input the shell reads from
+		scanner := bufio.NewScanner(o.In)
+		for scanner.Scan() {
+			if !o.runLine(scanner.Text(), strings.NewReader("")) {
+				return nil
+			}
+		}
+		return scanner.Err()
+	}
+
+	fd := int(file.Fd())
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{o.In, o.Out}, "")
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		newLine, newPos, candidates := o.completeLine(line, pos)
+		if len(candidates) > 1 {
+			fmt.Fprintln(terminal, strings.Join(candidates, "  "))
+		}
+		return newLine, newPos, true
+	}
+	for {
+		terminal.SetPrompt(o.prompt())
+		if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+			terminal.SetSize(width, height)
+		}
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		line, err := terminal.ReadLine()
+		if restoreErr := term.Restore(fd, state); restoreErr != nil {
+			return restoreErr
+		}
+		if err == io.EOF {
+			fmt.Fprintln(o.Out)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !o.runLine(line, o.In) {
+			return nil
+		}
+	}
+}
+
+// runLine runs the command of the line, and returns false when the shell
+// should exit.
+func (o *ShellOptions) runLine(line string, in io.Reader) bool {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return true
+	}
+	args, err := shlex.Split(line)
+	if err != nil {
+		fmt.Fprintf(o.ErrOut, "error: %v\n", err)
+		return true
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return false
+	case "context":
+		if len(args) != 2 {
+			fmt.Fprintln(o.ErrOut, "error: context takes exactly one context name")
+			return true
+		}
+		config, err := o.configAccess.GetStartingConfig()
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "error: %v\n", err)
+			return true
+		}
+		if _, ok := config.Contexts[args[1]]; !ok {
+			fmt.Fprintf(o.ErrOut, "error: no context exists with the name: %q\n", args[1])
+			return true
+		}
+		o.Context, o.Namespace = args[1], ""
+	case "ns":
+		if len(args) != 2 {
+			fmt.Fprintln(o.ErrOut, "error: ns takes exactly one namespace")
+			return true
+		}
+		o.Namespace = args[1]
+	default:
+		o.runCommand(append(o.contextArgs(), args...), in)
+	}
+	return true
+}
+
+// contextArgs returns the global flags of the commands, with the context and
+// the namespace.
+func (o *ShellOptions) contextArgs() []string {
+	args := append([]string{}, o.GlobalArgs...)
+	if len(o.Context) > 0 {
+		args = append(args, "--context="+o.Context)
+	}
+	if len(o.Namespace) > 0 {
+		args = append(args, "--namespace="+o.Namespace)
+	}
+	return args
+}
+
+// prompt returns the prompt showing the current context and namespace.
+func (o *ShellOptions) prompt() string {
+	context, namespace := o.Context, o.Namespace
+	if config, err := o.configAccess.GetStartingConfig(); err == nil {
+		if len(context) == 0 {
+			context = config.CurrentContext
+		}
+		if c, ok := config.Contexts[context]; ok && len(namespace) == 0 {
+			namespace = c.Namespace
+		}
+	}
+	if len(namespace) == 0 {
+		namespace = "default"
+	}
+	return fmt.Sprintf("kubectl (%s:%s)> ", context, namespace)
+}
+
+// shellBuiltinCompletions maps the commands of the shell to the kubectl
+// commands completing their arguments.
+var shellBuiltinCompletions = map[string][]string{
+	"context": {"config", "use-context"},
+	"ns":      {"get", "namespace"},
+}
+
+// completeLine completes the word before the position, and returns the new
+// line and position with the candidates.
+func (o *ShellOptions) completeLine(line string, pos int) (string, int, []string) {
+	prefix := line[:pos]
+	words, err := shlex.Split(prefix)
+	if err != nil {
+		return line, pos, nil
+	}
+	toComplete := ""
+	if len(words) > 0 && !strings.HasSuffix(prefix, " ") {
+		toComplete = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	if len(words) > 0 {
+		if command, ok := shellBuiltinCompletions[words[0]]; ok {
+			words = append(append([]string{}, command...), words[1:]...)
+		}
+	}
+
+	candidates, noSpace := o.completions(words, toComplete)
+	if len(candidates) == 0 {
+		return line, pos, nil
+	}
+	completed := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, completed) {
+			completed = completed[:len(completed)-1]
+		}
+	}
+	if len(candidates) == 1 && !noSpace {
+		completed += " "
+	}
+	start := pos - len(toComplete)
+	if prefix[start:] != toComplete {
+		// the word was quoted, it is left as is
+		return line, pos, candidates
+	}
+	return line[:start] + completed + line[pos:], start + len(completed), candidates
+}
+
+// completions returns the completions of kubectl for the word, and whether
+// no space should be added after it.
+func (o *ShellOptions) completions(words []string, toComplete string) ([]string, bool) {
+	args := append([]string{cobra.ShellCompNoDescRequestCmd}, o.contextArgs()...)
+	args = append(args, words...)
+	args = append(args, toComplete)
+	out := &bytes.Buffer{}
+	streams := genericiooptions.IOStreams{In: strings.NewReader(""), Out: out, ErrOut: io.Discard}
+	cmd := o.NewCommand(streams, args)
+	cmd.SetOut(out)
+	cmd.SetErr(io.Discard)
+	runner := o.commandRunner
+	runner.ErrOut = io.Discard
+	if runner.execute(cmd) != 0 {
+		return nil, false
+	}
+
+	candidates := []string{}
+	directive := cobra.ShellCompDirectiveDefault
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.HasPrefix(line, ":") {
+			fmt.Sscanf(line, ":%d", &directive)
+			break
+		}
+		if len(line) > 0 && strings.HasPrefix(line, toComplete) {
+			candidates = append(candidates, line)
+		}
+	}
+	if directive&cobra.ShellCompDirectiveError != 0 {
+		return nil, false
+	}
+	sort.Strings(candidates)
+	noSpace := directive&cobra.ShellCompDirectiveNoSpace != 0
+	if len(candidates) == 0 && directive == cobra.ShellCompDirectiveDefault {
+		candidates = completeFilenames(toComplete)
+		return candidates, len(candidates) == 1 && strings.HasSuffix(candidates[0], string(filepath.Separator))
+	}
+	return candidates, noSpace
+}
+
+// completeFilenames returns the files starting with the word, with a
+// trailing separator for directories.
+func completeFilenames(toComplete string) []string {
+	matches, _ := filepath.Glob(toComplete + "*")
+	candidates := []string{}
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && info.IsDir() {
+			match += string(filepath.Separator)
+		}
+		candidates = append(candidates, match)
+	}
+	sort.Strings(candidates)
+	return candidates
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func newFakeShellCommand(streams genericiooptions.IOStreams, args []string) *cobra.Command {
+	root := &cobra.Command{Use: "kubectl"}
+	root.PersistentFlags().String("context", "", "")
+	root.PersistentFlags().StringP("namespace", "n", "", "")
+	root.AddCommand(&cobra.Command{
+		Use:       "get",
+		ValidArgs: []string{"pods", "poddisruptionbudgets", "services"},
+		Run: func(cmd *cobra.Command, args []string) {
+			context, _ := cmd.Flags().GetString("context")
+			namespace, _ := cmd.Flags().GetString("namespace")
+			fmt.Fprintf(streams.Out, "%s/%s: %s\n", context, namespace, strings.Join(args, "|"))
+		},
+	})
+	root.AddCommand(&cobra.Command{
+		Use: "fail",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(errors.New("boom"))
+		},
+	})
+	root.SetArgs(args)
+	return root
+}
+
+func newFakeShellOptions(t *testing.T, streams genericiooptions.IOStreams) *ShellOptions {
+	config := clientcmdapi.NewConfig()
+	config.CurrentContext = "dev"
+	config.Contexts["dev"] = &clientcmdapi.Context{Namespace: "apps"}
+	config.Contexts["prod"] = &clientcmdapi.Context{}
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := clientcmd.WriteToFile(*config, kubeconfig); err != nil {
+		t.Fatal(err)
+	}
+	configAccess := clientcmd.NewDefaultPathOptions()
+	configAccess.GlobalFile = kubeconfig
+	configAccess.EnvVar = ""
+
+	return &ShellOptions{
+		configAccess: configAccess,
+		commandRunner: commandRunner{
+			NewCommand: newFakeShellCommand,
+			IOStreams:  streams,
+		},
+	}
+}
+
+func TestShellRun(t *testing.T) {
+	streams, in, out, errOut := genericiooptions.NewTestIOStreams()
+	in.WriteString("get pods\nns test\n# comment\nget 'a b'\ncontext prod\nget\ncontext missing\nfail\nexit\nget pods\n")
+	o := newFakeShellOptions(t, streams)
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedOut := "/: pods\n/test: a b\nprod/: \n"
+	if out.String() != expectedOut {
+		t.Errorf("expected output %q, got %q", expectedOut, out.String())
+	}
+	expectedErrOut := "error: no context exists with the name: \"missing\"\nerror: boom\n"
+	if errOut.String() != expectedErrOut {
+		t.Errorf("expected error output %q, got %q", expectedErrOut, errOut.String())
+	}
+}
+
+func TestShellPrompt(t *testing.T) {
+	o := newFakeShellOptions(t, genericiooptions.NewTestIOStreamsDiscard())
+	if prompt := o.prompt(); prompt != "kubectl (dev:apps)> " {
+		t.Errorf("unexpected prompt %q", prompt)
+	}
+	o.Context = "prod"
+	if prompt := o.prompt(); prompt != "kubectl (prod:default)> " {
+		t.Errorf("unexpected prompt %q", prompt)
+	}
+	o.Namespace = "test"
+	if prompt := o.prompt(); prompt != "kubectl (prod:test)> " {
+		t.Errorf("unexpected prompt %q", prompt)
+	}
+}
+
+func TestShellCompleteLine(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pod.yaml"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		line               string
+		pos                int
+		expectedLine       string
+		expectedPos        int
+		expectedCandidates []string
+	}{
+		{
+			name:               "command",
+			line:               "ge",
+			pos:                2,
+			expectedLine:       "get ",
+			expectedPos:        4,
+			expectedCandidates: []string{"get"},
+		},
+		{
+			name:               "common prefix",
+			line:               "get p",
+			pos:                5,
+			expectedLine:       "get pod",
+			expectedPos:        7,
+			expectedCandidates: []string{"poddisruptionbudgets", "pods"},
+		},
+		{
+			name:               "before the cursor",
+			line:               "get se -o yaml",
+			pos:                6,
+			expectedLine:       "get services  -o yaml",
+			expectedPos:        13,
+			expectedCandidates: []string{"services"},
+		},
+		{
+			name:         "no candidates",
+			line:         "get x",
+			pos:          5,
+			expectedLine: "get x",
+			expectedPos:  5,
+		},
+		{
+			name:               "files",
+			line:               "fail " + dir + "/p",
+			pos:                len("fail " + dir + "/p"),
+			expectedLine:       "fail " + dir + "/pod.yaml ",
+			expectedPos:        len("fail " + dir + "/pod.yaml "),
+			expectedCandidates: []string{dir + "/pod.yaml"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := newFakeShellOptions(t, genericiooptions.NewTestIOStreamsDiscard())
+			line, pos, candidates := o.completeLine(tc.line, tc.pos)
+			if line != tc.expectedLine || pos != tc.expectedPos {
+				t.Errorf("expected %q at %d, got %q at %d", tc.expectedLine, tc.expectedPos, line, pos)
+			}
+			if !reflect.DeepEqual(candidates, tc.expectedCandidates) {
+				t.Errorf("expected candidates %v, got %v", tc.expectedCandidates, candidates)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+// Status is the health status of an object.
+type Status string
+
+const (
+	// Current objects are fully reconciled.
+	Current Status = "Current"
+	// InProgress objects are still being reconciled.
+	InProgress Status = "InProgress"
+	// Failed objects could not be reconciled.
+	Failed Status = "Failed"
+	// Terminating objects are being deleted.
+	Terminating Status = "Terminating"
+	// NotFound objects do not exist.
+	NotFound Status = "NotFound"
+)
+
+// ComputeStatus returns the status of the object and a message explaining
+// it. The rollout of the workloads is checked with the status viewers, and
+// the conditions are the conditions reporting the health of custom
+// resources by group kind.
+func ComputeStatus(obj *unstructured.Unstructured, statusViewerFn func(*meta.RESTMapping) (polymorphichelpers.StatusViewer, error), conditions map[schema.GroupKind]string) (Status, string) {
+	if obj.GetDeletionTimestamp() != nil {
+		if finalizers := obj.GetFinalizers(); len(finalizers) > 0 {
+			return Terminating, "waiting for the finalizers: " + strings.Join(finalizers, ", ")
+		}
+		return Terminating, "being deleted"
+	}
+	observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	if found && observed < obj.GetGeneration() {
+		return InProgress, "the latest generation has not been observed"
+	}
+
+	gvk := obj.GroupVersionKind()
+	if condition, ok := conditions[gvk.GroupKind()]; ok {
+		if conditionStatus(obj, condition) == "True" {
+			return Current, ""
+		}
+		return InProgress, conditionMessage(obj, condition, fmt.Sprintf("the %s condition is not true", condition))
+	}
+
+	switch gvk.GroupKind() {
+	case schema.GroupKind{Kind: "Pod"}:
+		return podStatus(obj)
+	case schema.GroupKind{Group: "batch", Kind: "Job"}:
+		switch {
+		case conditionStatus(obj, "Failed") == "True":
+			return Failed, conditionMessage(obj, "Failed", "the job failed")
+		case conditionStatus(obj, "Complete") == "True":
+			return Current, ""
+		}
+		return InProgress, "the job is not complete"
+	case schema.GroupKind{Kind: "PersistentVolumeClaim"}:
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		if phase != "Bound" {
+			return InProgress, "the claim is not bound"
+		}
+		return Current, ""
+	case schema.GroupKind{Kind: "Service"}:
+		serviceType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+		ingress, _, _ := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+		if serviceType == "LoadBalancer" && len(ingress) == 0 {
+			return InProgress, "the load balancer is not provisioned"
+		}
+		return Current, ""
+	case schema.GroupKind{Kind: "Namespace"}:
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		if phase != "Active" {
+			return InProgress, fmt.Sprintf("the namespace is %s", phase)
+		}
+		return Current, ""
+	case schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:
+		switch {
+		case conditionStatus(obj, "NamesAccepted") == "False":
+			return Failed, conditionMessage(obj, "NamesAccepted", "the names are not accepted")
+		case conditionStatus(obj, "Established") != "True":
+			return InProgress, "the custom resource definition is not established"
+		}
+		return Current, ""
+	case schema.GroupKind{Group: "apps", Kind: "Deployment"}:
+		if conditionReason(obj, "Progressing") == "ProgressDeadlineExceeded" {
+			return Failed, conditionMessage(obj, "Progressing", "the progress deadline is exceeded")
+		}
+	}
+
+	if viewer, err := statusViewerFn(&meta.RESTMapping{GroupVersionKind: gvk}); err == nil {
+		// the rollout of the workloads not updated on rollout, such as
+		// daemon sets with the OnDelete strategy, is done with an error
+		message, done, err := viewer.Status(obj, 0)
+		switch {
+		case done:
+			return Current, ""
+		case err != nil:
+			return InProgress, err.Error()
+		}
+		return InProgress, strings.TrimSpace(message)
+	}
+
+	switch {
+	case conditionStatus(obj, "Stalled") == "True":
+		return Failed, conditionMessage(obj, "Stalled", "the reconciliation is stalled")
+	case conditionStatus(obj, "Reconciling") == "True":
+		return InProgress, conditionMessage(obj, "Reconciling", "the object is being reconciled")
+	}
+	switch conditionStatus(obj, "Ready") {
+	case "", "True":
+		return Current, ""
+	}
+	return InProgress, conditionMessage(obj, "Ready", "the Ready condition is not true")
+}
+
+var failedContainerReasons = map[string]bool{
+	"CrashLoopBackOff": true, "ImagePullBackOff": true, "ErrImagePull": true, "InvalidImageName": true,
+	"CreateContainerConfigError": true, "CreateContainerError": true, "RunContainerError": true,
+}
+
+func podStatus(obj *unstructured.Unstructured) (Status, string) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Succeeded":
+		return Current, ""
+	case "Failed":
+		message, _, _ := unstructured.NestedString(obj.Object, "status", "message")
+		if len(message) == 0 {
+			message = "the pod failed"
+		}
+		return Failed, message
+	}
+	for _, field := range []string{"initContainerStatuses", "containerStatuses"} {
+		statuses, _, _ := unstructured.NestedSlice(obj.Object, "status", field)
+		for _, s := range statuses {
+			status, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			reason, _, _ := unstructured.NestedString(status, "state", "waiting", "reason")
+			if failedContainerReasons[reason] {
+				name, _, _ := unstructured.NestedString(status, "name")
+				return Failed, fmt.Sprintf("container %s: %s", name, reason)
+			}
+		}
+	}
+	if conditionStatus(obj, "Ready") != "True" {
+		return InProgress, conditionMessage(obj, "Ready", "the pod is not ready")
+	}
+	return Current, ""
+}
+
+// findCondition returns the condition of the object, or nil when the
+// object has no such condition.
+func findCondition(obj *unstructured.Unstructured, conditionType string) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == conditionType {
+			return condition
+		}
+	}
+	return nil
+}
+
+// conditionStatus returns the status of the condition of the object, or an
+// empty string when the object has no such condition.
+func conditionStatus(obj *unstructured.Unstructured, conditionType string) string {
+	status, _ := findCondition(obj, conditionType)["status"].(string)
+	return status
+}
+
+func conditionReason(obj *unstructured.Unstructured, conditionType string) string {
+	reason, _ := findCondition(obj, conditionType)["reason"].(string)
+	return reason
+}
+
+// conditionMessage returns the first line of the message of the condition,
+// or the default message when the condition has none.
+func conditionMessage(obj *unstructured.Unstructured, conditionType, defaultMessage string) string {
+	message, _ := findCondition(obj, conditionType)["message"].(string)
+	message, _, _ = strings.Cut(strings.TrimSpace(message), "\n")
+	if len(message) == 0 {
+		return defaultMessage
+	}
+	return message
+}
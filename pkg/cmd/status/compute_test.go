@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+func TestComputeStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		obj             map[string]interface{}
+		expectedStatus  Status
+		expectedMessage string
+	}{
+		{
+			name:           "config map",
+			obj:            map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"},
+			expectedStatus: Current,
+		},
+		{
+			name: "terminating",
+			obj: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{
+				"deletionTimestamp": "2024-03-01T12:00:00Z", "finalizers": []interface{}{"example.com/cleanup"},
+			}},
+			expectedStatus:  Terminating,
+			expectedMessage: "waiting for the finalizers: example.com/cleanup",
+		},
+		{
+			name: "generation not observed",
+			obj: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Widget",
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"status":   map[string]interface{}{"observedGeneration": int64(1)},
+			},
+			expectedStatus:  InProgress,
+			expectedMessage: "the latest generation has not been observed",
+		},
+		{
+			name: "stalled",
+			obj: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Widget", "status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Stalled", "status": "True", "message": "invalid spec\ndetails"}},
+			}},
+			expectedStatus:  Failed,
+			expectedMessage: "invalid spec",
+		},
+		{
+			name: "not ready",
+			obj: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Widget", "status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False"}},
+			}},
+			expectedStatus:  InProgress,
+			expectedMessage: "the Ready condition is not true",
+		},
+		{
+			name: "mapped condition",
+			obj: map[string]interface{}{"apiVersion": "example.com/v1", "kind": "Gadget", "status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True"},
+					map[string]interface{}{"type": "Available", "status": "False", "message": "no capacity"},
+				},
+			}},
+			expectedStatus:  InProgress,
+			expectedMessage: "no capacity",
+		},
+		{
+			name: "crash looping pod",
+			obj: map[string]interface{}{"apiVersion": "v1", "kind": "Pod", "status": map[string]interface{}{
+				"phase": "Running",
+				"containerStatuses": []interface{}{map[string]interface{}{
+					"name": "app", "state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}},
+				}},
+			}},
+			expectedStatus:  Failed,
+			expectedMessage: "container app: CrashLoopBackOff",
+		},
+		{
+			name: "ready pod",
+			obj: map[string]interface{}{"apiVersion": "v1", "kind": "Pod", "status": map[string]interface{}{
+				"phase":      "Running",
+				"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+			}},
+			expectedStatus: Current,
+		},
+		{
+			name: "failed job",
+			obj: map[string]interface{}{"apiVersion": "batch/v1", "kind": "Job", "status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Failed", "status": "True", "message": "backoff limit exceeded"}},
+			}},
+			expectedStatus:  Failed,
+			expectedMessage: "backoff limit exceeded",
+		},
+		{
+			name:            "pending load balancer",
+			obj:             map[string]interface{}{"apiVersion": "v1", "kind": "Service", "spec": map[string]interface{}{"type": "LoadBalancer"}},
+			expectedStatus:  InProgress,
+			expectedMessage: "the load balancer is not provisioned",
+		},
+		{
+			name: "deployment past its deadline",
+			obj: map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"}},
+			}},
+			expectedStatus:  Failed,
+			expectedMessage: "the progress deadline is exceeded",
+		},
+		{
+			name: "deployment rolling out",
+			obj: map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment",
+				"metadata": map[string]interface{}{"name": "nginx", "generation": int64(1)},
+				"spec":     map[string]interface{}{"replicas": int64(2)},
+				"status":   map[string]interface{}{"observedGeneration": int64(1), "replicas": int64(2), "updatedReplicas": int64(1)},
+			},
+			expectedStatus:  InProgress,
+			expectedMessage: `Waiting for deployment "nginx" rollout to finish: 1 out of 2 new replicas have been updated...`,
+		},
+		{
+			name: "rolled out deployment",
+			obj: map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment",
+				"metadata": map[string]interface{}{"name": "nginx", "generation": int64(1)},
+				"spec":     map[string]interface{}{"replicas": int64(2)},
+				"status":   map[string]interface{}{"observedGeneration": int64(1), "replicas": int64(2), "updatedReplicas": int64(2), "availableReplicas": int64(2)},
+			},
+			expectedStatus: Current,
+		},
+	}
+	conditions := map[schema.GroupKind]string{{Group: "example.com", Kind: "Gadget"}: "Available"}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, message := ComputeStatus(&unstructured.Unstructured{Object: test.obj}, polymorphichelpers.StatusViewerFn, conditions)
+			if status != test.expectedStatus || message != test.expectedMessage {
+				t.Errorf("expected %s %q, got %s %q", test.expectedStatus, test.expectedMessage, status, message)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+	"k8s.io/kubectl/pkg/util/color"
+	"k8s.io/kubectl/pkg/util/completion"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	statusLong = templates.LongDesc(i18n.T(`
+		Show the health status of resources.
+
+		Each object is reported as Current when it is fully reconciled, InProgress when it
+		is still being reconciled, Failed when its reconciliation failed, Terminating when
+		it is being deleted, or NotFound. Pods, workloads, jobs, services, persistent volume
+		claims, custom resource definitions and namespaces have their own rules. Other
+		objects are InProgress while their latest generation has not been observed or their
+		Reconciling condition is true, Failed while their Stalled condition is true, and
+		otherwise follow their Ready condition, if any.
+
+		Custom resources reporting their health with another condition can be mapped to it
+		with --condition. The command exits with a non-zero code unless all the objects are
+		Current, which makes it suitable to gate continuous integration pipelines.`))
+
+	statusExample = templates.Examples(i18n.T(`
+		# Show the status of the resources in the directory manifests
+		kubectl status -f manifests/
+
+		# Wait for the resources labeled app=nginx to be current, for at most 5 minutes
+		kubectl status -l app=nginx --watch --timeout=5m
+
+		# Show the status of the widgets, which report their health with the Available condition
+		kubectl status widgets --condition=Widget.example.com=Available`))
+)
+
+// StatusOptions holds the command-line options for 'status' command
+type StatusOptions struct {
+	FilenameOptions resource.FilenameOptions
+	LabelSelector   string
+	AllNamespaces   bool
+	Watch           bool
+	Timeout         time.Duration
+	Conditions      []string
+
+	Namespace        string
+	EnforceNamespace bool
+	BuilderArgs      []string
+
+	// conditions are the conditions reporting the health of custom
+	// resources, by group kind
+	conditions map[schema.GroupKind]string
+
+	Builder        func() *resource.Builder
+	DynamicClient  dynamic.Interface
+	StatusViewerFn func(*meta.RESTMapping) (polymorphichelpers.StatusViewer, error)
+
+	genericiooptions.IOStreams
+}
+
+// NewStatusOptions returns an initialized StatusOptions instance
+func NewStatusOptions(streams genericiooptions.IOStreams) *StatusOptions {
+	return &StatusOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdStatus returns a Command instance for the 'status' command
+func NewCmdStatus(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewStatusOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "status ([-f FILENAME] | TYPE [NAME_PREFIX | -l label])",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Show the health status of resources"),
+		Long:                  statusLong,
+		Example:               statusExample,
+		ValidArgsFunction:     completion.ResourceTypeAndNameCompletionFunc(f),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, "identifying the resources to show the status of.")
+	cmdutil.AddLabelSelectorFlagVar(cmd, &o.LabelSelector)
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If present, show the status of the requested objects across all namespaces.")
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "If true, watch the status of the objects until each of them is Current or Failed.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to watch the objects, zero means never.")
+	cmd.Flags().StringArrayVar(&o.Conditions, "condition", o.Conditions, "The condition reporting the health of a kind of custom resource, as KIND.GROUP=CONDITION. The objects are Current when the condition is true. May be repeated.")
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *StatusOptions) Complete(f cmdutil.Factory, args []string) error {
+	var err error
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.BuilderArgs = args
+	o.Builder = f.NewBuilder
+	o.StatusViewerFn = polymorphichelpers.StatusViewerFn
+
+	o.conditions = map[schema.GroupKind]string{}
+	for _, c := range o.Conditions {
+		kind, condition, found := strings.Cut(c, "=")
+		if !found || len(kind) == 0 || len(condition) == 0 {
+			return fmt.Errorf("invalid --condition %q, must be KIND.GROUP=CONDITION", c)
+		}
+		o.conditions[schema.ParseGroupKind(kind)] = condition
+	}
+
+	o.DynamicClient, err = f.DynamicClient()
+	return err
+}
+
+// Validate makes sure all the provided values for command-line options are valid
+func (o *StatusOptions) Validate() error {
+	if len(o.BuilderArgs) == 0 && cmdutil.IsFilenameSliceEmpty(o.FilenameOptions.Filenames, o.FilenameOptions.Kustomize) {
+		return fmt.Errorf("you must specify the resources to show the status of, with -f or TYPE")
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+	if o.Timeout > 0 && !o.Watch {
+		return fmt.Errorf("--timeout can only be used with --watch")
+	}
+	return nil
+}
+
+// Run shows the status of the objects, and returns an error unless they are
+// all current.
+func (o *StatusOptions) Run() error {
+	r := o.Builder().
+		Unstructured().
+		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
+		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
+		LabelSelectorParam(o.LabelSelector).
+		ResourceTypeOrNameArgs(true, o.BuilderArgs...).
+		ContinueOnError().
+		Flatten().
+		Do()
+	infos, err := r.Infos()
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		fmt.Fprintln(o.ErrOut, "No resources found")
+		return nil
+	}
+
+	out := o.Out
+	if color.Enabled(o.Out) {
+		// the statuses are colored once the columns are aligned
+		w := color.NewStatusWriter(o.Out)
+		defer w.Flush()
+		out = w
+	}
+	w := printers.GetNewTabWriter(out)
+	defer w.Flush()
+	if o.AllNamespaces {
+		fmt.Fprint(w, "NAMESPACE\t")
+	}
+	fmt.Fprintln(w, "NAME\tSTATUS\tMESSAGE")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if o.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+	}
+	defer cancel()
+
+	results := make([]result, len(infos))
+	err = wait.PollUntilContextCancel(ctx, statusInterval, true, func(ctx context.Context) (bool, error) {
+		for i, info := range infos {
+			current, err := o.status(ctx, info)
+			if err != nil {
+				return false, err
+			}
+			if current == results[i] {
+				continue
+			}
+			results[i] = current
+			if o.AllNamespaces {
+				fmt.Fprintf(w, "%s\t", printers.EscapeTerminal(info.Namespace))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", printers.EscapeTerminal(info.ObjectName()), current.status, printers.EscapeTerminal(current.message))
+		}
+		// the changes are printed as they are observed
+		w.Flush()
+		return !o.Watch || settled(results), nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+
+	notCurrent := 0
+	for _, r := range results {
+		if r.status != Current {
+			notCurrent++
+		}
+	}
+	if notCurrent > 0 {
+		return fmt.Errorf("%d of %d objects are not %s", notCurrent, len(results), Current)
+	}
+	return nil
+}
+
+var statusInterval = 2 * time.Second
+
+type result struct {
+	status  Status
+	message string
+}
+
+// status returns the status of the object as currently found on the server.
+func (o *StatusOptions) status(ctx context.Context, info *resource.Info) (result, error) {
+	obj, err := o.DynamicClient.Resource(info.Mapping.Resource).Namespace(info.Namespace).Get(ctx, info.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return result{status: NotFound}, nil
+	}
+	if err != nil {
+		return result{}, err
+	}
+	status, message := ComputeStatus(obj, o.StatusViewerFn, o.conditions)
+	return result{status: status, message: message}, nil
+}
+
+// settled returns whether no object is expected to change status anymore.
+func settled(results []result) bool {
+	for _, r := range results {
+		if r.status != Current && r.status != Failed {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	dynamicfakeclient "k8s.io/client-go/dynamic/fake"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestStatus(t *testing.T) {
+	defer func(interval time.Duration) { statusInterval = interval }(statusInterval)
+	statusInterval = 10 * time.Millisecond
+
+	manifest := filepath.Join(t.TempDir(), "manifest.yaml")
+	err := os.WriteFile(manifest, []byte(`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+  namespace: test
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: test
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   map[string]interface{}{"name": "migrate", "namespace": "test"},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Complete", "status": "True"}},
+		},
+	}}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings", "namespace": "test"},
+	}}
+
+	tests := []struct {
+		name        string
+		objects     []runtime.Object
+		watch       bool
+		timeout     time.Duration
+		expectedOut string
+		expectedErr string
+	}{
+		{
+			name:        "current",
+			objects:     []runtime.Object{job, configMap},
+			expectedOut: "NAME                  STATUS    MESSAGE\njobs/migrate          Current   \nconfigmaps/settings   Current   \n",
+		},
+		{
+			name:        "not found",
+			objects:     []runtime.Object{job},
+			expectedOut: "NAME                  STATUS     MESSAGE\njobs/migrate          Current    \nconfigmaps/settings   NotFound   \n",
+			expectedErr: "1 of 2 objects are not Current",
+		},
+		{
+			name:        "watch timeout",
+			objects:     []runtime.Object{job},
+			watch:       true,
+			timeout:     50 * time.Millisecond,
+			expectedOut: "NAME                  STATUS     MESSAGE\njobs/migrate          Current    \nconfigmaps/settings   NotFound   \n",
+			expectedErr: "1 of 2 objects are not Current",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test")
+			defer tf.Cleanup()
+
+			streams, _, out, _ := genericiooptions.NewTestIOStreams()
+			o := NewStatusOptions(streams)
+			o.FilenameOptions.Filenames = []string{manifest}
+			o.Watch = test.watch
+			o.Timeout = test.timeout
+			if err := o.Complete(tf, nil); err != nil {
+				t.Fatal(err)
+			}
+			o.DynamicClient = dynamicfakeclient.NewSimpleDynamicClient(runtime.NewScheme(), test.objects...)
+
+			err := o.Run()
+			switch {
+			case len(test.expectedErr) > 0 && (err == nil || err.Error() != test.expectedErr):
+				t.Errorf("expected error %q, got %v", test.expectedErr, err)
+			case len(test.expectedErr) == 0 && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+			if out.String() != test.expectedOut {
+				t.Errorf("expected output %q, got %q", test.expectedOut, out.String())
+			}
+		})
+	}
+}
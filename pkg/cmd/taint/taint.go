@@ -19,6 +19,7 @@ package taint
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -57,7 +58,9 @@ type TaintOptions struct {
 	builder        *resource.Builder
 	selector       string
 	overwrite      bool
+	overwriteAll   bool
 	all            bool
+	diff           bool
 	fieldManager   string
 
 	ClientForMapping func(*meta.RESTMapping) (resource.RESTClient, error)
@@ -93,7 +96,16 @@ var (
 		kubectl taint node -l myLabel=X  dedicated=foo:PreferNoSchedule
 
 		# Add to node 'foo' a taint with key 'bar' and no value
-		kubectl taint nodes foo bar:NoSchedule`))
+		kubectl taint nodes foo bar:NoSchedule
+
+		# Preview how the taints of the nodes having label myLabel=X would change
+		kubectl taint node -l myLabel=X dedicated=foo:NoSchedule --dry-run=client -o diff
+
+		# Replace all the taints of node 'foo' with a single taint
+		kubectl taint nodes foo dedicated=special-user:NoSchedule --overwrite-all
+
+		# Remove all the taints of node 'foo'
+		kubectl taint nodes foo --overwrite-all`))
 )
 
 func NewCmdTaint(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
@@ -119,10 +131,14 @@ func NewCmdTaint(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.C
 	}
 
 	options.PrintFlags.AddFlags(cmd)
+	if flag := cmd.Flags().Lookup("output"); flag != nil {
+		flag.Usage += " Use 'diff' to print the taints removed from (-) and added to (+) each node instead."
+	}
 	cmdutil.AddDryRunFlag(cmd)
 	cmdutil.AddValidateFlags(cmd)
 	cmdutil.AddLabelSelectorFlagVar(cmd, &options.selector)
 	cmd.Flags().BoolVar(&options.overwrite, "overwrite", options.overwrite, "If true, allow taints to be overwritten, otherwise reject taint updates that overwrite existing taints.")
+	cmd.Flags().BoolVar(&options.overwriteAll, "overwrite-all", options.overwriteAll, "If true, replace all the taints of the nodes with the given taints. Without taints, all the taints are removed.")
 	cmd.Flags().BoolVar(&options.all, "all", options.all, "Select all nodes in the cluster")
 	cmdutil.AddFieldManagerFlagVar(cmd, &options.fieldManager, "kubectl-taint")
 	return cmd
@@ -145,6 +161,10 @@ func (o *TaintOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []st
 		return err
 	}
 	cmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	if o.PrintFlags.OutputFormat != nil && *o.PrintFlags.OutputFormat == "diff" {
+		o.diff = true
+		*o.PrintFlags.OutputFormat = ""
+	}
 
 	o.ValidationDirective, err = cmdutil.GetValidationDirective(cmd)
 	if err != nil {
@@ -178,7 +198,7 @@ func (o *TaintOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []st
 	if len(o.resources) < 1 {
 		return fmt.Errorf("one or more resources must be specified as <resource> <name>")
 	}
-	if len(taintArgs) < 1 {
+	if len(taintArgs) < 1 && !o.overwriteAll {
 		return fmt.Errorf("at least one taint update is required")
 	}
 
@@ -253,6 +273,9 @@ func (o TaintOptions) Validate() error {
 			}
 		}
 	}
+	if o.overwriteAll && len(o.taintsToRemove) > 0 {
+		return fmt.Errorf("can not remove taints with --overwrite-all, all taints not given are removed")
+	}
 	if len(conflictTaints) > 0 {
 		return fmt.Errorf("can not both modify and remove the following taint(s) in the same command: %s", strings.Join(conflictTaints, ", "))
 	}
@@ -277,6 +300,10 @@ func (o TaintOptions) RunTaint() error {
 		if err != nil {
 			return err
 		}
+		var oldTaints []v1.Taint
+		if node, ok := obj.(*v1.Node); ok {
+			oldTaints = node.Spec.Taints
+		}
 		operation, err := o.updateTaints(obj)
 		if err != nil {
 			return err
@@ -295,6 +322,14 @@ func (o TaintOptions) RunTaint() error {
 		if err != nil {
 			return err
 		}
+		newTaints := obj.(*v1.Node).Spec.Taints
+		printObj := func(obj runtime.Object) error {
+			if o.diff {
+				printTaintDiff(o.Out, name, oldTaints, newTaints)
+				return nil
+			}
+			return printer.PrintObj(obj, o.Out)
+		}
 		if o.DryRunStrategy == cmdutil.DryRunClient {
 			if createdPatch {
 				typedObj, err := scheme.Scheme.ConvertToVersion(info.Object, info.Mapping.GroupVersionKind.GroupVersion())
@@ -321,9 +356,9 @@ func (o TaintOptions) RunTaint() error {
 				if err != nil {
 					return err
 				}
-				return printer.PrintObj(targetObj, o.Out)
+				return printObj(targetObj)
 			}
-			return printer.PrintObj(obj, o.Out)
+			return printObj(obj)
 		}
 
 		mapping := info.ResourceMapping()
@@ -347,16 +382,57 @@ func (o TaintOptions) RunTaint() error {
 			return err
 		}
 
-		return printer.PrintObj(outputObj, o.Out)
+		return printObj(outputObj)
 	})
 }
 
+// printTaintDiff prints the taints of the node, prefixing the removed taints
+// with '-' and the added taints with '+'.
+func printTaintDiff(out io.Writer, name string, oldTaints, newTaints []v1.Taint) {
+	contains := func(taints []v1.Taint, taint v1.Taint) bool {
+		for _, t := range taints {
+			if t.MatchTaint(&taint) && t.Value == taint.Value {
+				return true
+			}
+		}
+		return false
+	}
+	var lines []string
+	changed := false
+	for _, taint := range oldTaints {
+		if contains(newTaints, taint) {
+			lines = append(lines, "  "+taint.ToString())
+		} else {
+			lines = append(lines, "- "+taint.ToString())
+			changed = true
+		}
+	}
+	for _, taint := range newTaints {
+		if !contains(oldTaints, taint) {
+			lines = append(lines, "+ "+taint.ToString())
+			changed = true
+		}
+	}
+	if changed {
+		fmt.Fprintf(out, "node/%s\n", name)
+	} else {
+		fmt.Fprintf(out, "node/%s (unchanged)\n", name)
+	}
+	for _, line := range lines {
+		fmt.Fprintf(out, "    %s\n", line)
+	}
+}
+
 // updateTaints applies a taint option(o) to a node in cluster after computing the net effect of operation(i.e. does it result in an overwrite?), it reports back the end result in a way that user can easily interpret.
 func (o TaintOptions) updateTaints(obj runtime.Object) (string, error) {
 	node, ok := obj.(*v1.Node)
 	if !ok {
 		return "", fmt.Errorf("unexpected type %T, expected Node", obj)
 	}
+	if o.overwriteAll {
+		node.Spec.Taints = replaceTaints(node.Spec.Taints, o.taintsToAdd)
+		return MODIFIED, nil
+	}
 	if !o.overwrite {
 		if exists := checkIfTaintsAlreadyExists(node.Spec.Taints, o.taintsToAdd); len(exists) != 0 {
 			return "", fmt.Errorf("node %s already has %v taint(s) with same effect(s) and --overwrite is false", node.Name, exists)
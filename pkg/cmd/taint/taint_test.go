@@ -197,6 +197,37 @@ func TestTaint(t *testing.T) {
 			expectTaint: true,
 		},
 
+		{
+			description: "replace all the taints of the node",
+			oldTaints: []corev1.Taint{{
+				Key:    "foo",
+				Value:  "bar",
+				Effect: "NoSchedule",
+			}, {
+				Key:    "dedicated",
+				Effect: "NoExecute",
+			}},
+			newTaints: []corev1.Taint{{
+				Key:    "dedicated",
+				Value:  "special-user",
+				Effect: "NoSchedule",
+			}},
+			args:        []string{"node", "node-name", "dedicated=special-user:NoSchedule", "--overwrite-all"},
+			expectFatal: false,
+			expectTaint: true,
+		},
+		{
+			description: "remove all the taints of the node",
+			oldTaints: []corev1.Taint{{
+				Key:    "foo",
+				Value:  "bar",
+				Effect: "NoSchedule",
+			}},
+			args:        []string{"node", "node-name", "--overwrite-all"},
+			expectFatal: false,
+			expectTaint: true,
+		},
+
 		// error cases
 		{
 			description: "invalid taint key",
@@ -222,6 +253,12 @@ func TestTaint(t *testing.T) {
 			expectFatal: true,
 			expectTaint: false,
 		},
+		{
+			description: "removing taints can't be combined with 'overwrite-all'",
+			args:        []string{"node", "node-name", "foo=bar:NoSchedule", "baz-", "--overwrite-all"},
+			expectFatal: true,
+			expectTaint: false,
+		},
 		{
 			description: "can't update existing taint on the node, since 'overwrite' flag is not set",
 			oldTaints: []corev1.Taint{{
@@ -347,6 +384,52 @@ func TestTaint(t *testing.T) {
 	}
 }
 
+func TestTaintDiff(t *testing.T) {
+	oldTaints := []corev1.Taint{
+		{Key: "foo", Value: "bar", Effect: "NoSchedule"},
+		{Key: "dedicated", Effect: "NoExecute"},
+	}
+	node, _ := generateNodeAndTaintedNode(oldTaints, nil)
+	other := node.DeepCopy()
+	other.Name = "other"
+	other.Spec.Taints = []corev1.Taint{{Key: "foo", Value: "baz", Effect: "NoSchedule"}}
+
+	tf := cmdtesting.NewTestFactory()
+	defer tf.Cleanup()
+	codec := scheme.Codecs.LegacyCodec(scheme.Scheme.PrioritizedVersionsAllGroups()...)
+	tf.Client = &fake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		GroupVersion:         corev1.SchemeGroupVersion,
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			m := &MyReq{req}
+			if m.isFor("GET", "/nodes") {
+				list := &corev1.NodeList{Items: []corev1.Node{*node, *other}}
+				return &http.Response{StatusCode: http.StatusOK, Header: cmdtesting.DefaultHeader(), Body: cmdtesting.ObjBody(codec, list)}, nil
+			}
+			t.Fatalf("unexpected request: %v %#v", req.Method, req.URL)
+			return nil, nil
+		}),
+	}
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+	cmd := NewCmdTaint(tf, streams)
+	cmd.SetArgs([]string{"node", "--all", "foo=baz:NoSchedule", "--overwrite", "--dry-run=client", "-o", "diff"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `node/node-name
+    - foo=bar:NoSchedule
+      dedicated:NoExecute
+    + foo=baz:NoSchedule
+node/other (unchanged)
+      foo=baz:NoSchedule
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
 func TestValidateFlags(t *testing.T) {
 	tests := []struct {
 		taintOpts   TaintOptions
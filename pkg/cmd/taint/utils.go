@@ -141,6 +141,22 @@ func reorganizeTaints(node *corev1.Node, overwrite bool, taintsToAdd []corev1.Ta
 	return UNTAINTED, newTaints, utilerrors.NewAggregate(allErrs)
 }
 
+// replaceTaints returns the given taints as the full set of taints, keeping
+// the existing taints that are unchanged so their TimeAdded is preserved.
+func replaceTaints(oldTaints []corev1.Taint, taints []corev1.Taint) []corev1.Taint {
+	newTaints := []corev1.Taint{}
+	for _, taint := range taints {
+		for _, oldTaint := range oldTaints {
+			if oldTaint.MatchTaint(&taint) && oldTaint.Value == taint.Value {
+				taint = oldTaint
+				break
+			}
+		}
+		newTaints = append(newTaints, taint)
+	}
+	return newTaints
+}
+
 // deleteTaints deletes the given taints from the node's taintlist.
 func deleteTaints(taintsToRemove []corev1.Taint, newTaints *[]corev1.Taint) ([]error, bool) {
 	allErrs := []error{}
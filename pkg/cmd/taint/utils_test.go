@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestDeleteTaint(t *testing.T) {
@@ -531,3 +532,25 @@ func TestParseTaints(t *testing.T) {
 		}
 	}
 }
+
+func TestReplaceTaints(t *testing.T) {
+	added := metav1.Now()
+	oldTaints := []corev1.Taint{
+		{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoExecute, TimeAdded: &added},
+		{Key: "baz", Effect: corev1.TaintEffectNoSchedule},
+	}
+	taints := []corev1.Taint{
+		{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoExecute},
+		{Key: "qux", Effect: corev1.TaintEffectPreferNoSchedule},
+	}
+	expected := []corev1.Taint{
+		{Key: "foo", Value: "bar", Effect: corev1.TaintEffectNoExecute, TimeAdded: &added},
+		{Key: "qux", Effect: corev1.TaintEffectPreferNoSchedule},
+	}
+	if got := replaceTaints(oldTaints, taints); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := replaceTaints(oldTaints, nil); len(got) != 0 {
+		t.Errorf("expected no taints, got %v", got)
+	}
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tree
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	cmdstatus "k8s.io/kubectl/pkg/cmd/status"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+	"k8s.io/kubectl/pkg/util/color"
+	"k8s.io/kubectl/pkg/util/completion"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"k8s.io/kubectl/pkg/util/relations"
+	"k8s.io/kubectl/pkg/util/templates"
+	"k8s.io/kubectl/pkg/util/timeformat"
+)
+
+var (
+	treeLong = templates.LongDesc(i18n.T(`
+		Show the objects owned by a resource as a tree.
+
+		The objects whose owner references point to the resource are shown below it,
+		recursively, with their health status. Some objects related to their parent
+		without owner references are shown too: the endpoints of a service, and the
+		persistent volume bound to a persistent volume claim.
+
+		All the resources of the namespace of the resource are listed to find the
+		objects it owns, or of all the namespaces for cluster-scoped resources.`))
+
+	treeExample = templates.Examples(i18n.T(`
+		# Show the replica sets and pods of the deployment nginx
+		kubectl tree deployment nginx
+
+		# Show the objects owned by the custom resource widget/gear
+		kubectl tree widget/gear`))
+)
+
+// TreeOptions holds the command-line options for 'tree' command
+type TreeOptions struct {
+	Namespace        string
+	EnforceNamespace bool
+	BuilderArgs      []string
+
+	Builder         func() *resource.Builder
+	DiscoveryClient discovery.CachedDiscoveryInterface
+	DynamicClient   dynamic.Interface
+	StatusViewerFn  func(*meta.RESTMapping) (polymorphichelpers.StatusViewer, error)
+
+	genericiooptions.IOStreams
+}
+
+// NewTreeOptions returns an initialized TreeOptions instance
+func NewTreeOptions(streams genericiooptions.IOStreams) *TreeOptions {
+	return &TreeOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdTree returns a Command instance for the 'tree' command
+func NewCmdTree(f cmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewTreeOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:                   "tree (TYPE NAME | TYPE/NAME)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Show the objects owned by a resource as a tree"),
+		Long:                  treeLong,
+		Example:               treeExample,
+		ValidArgsFunction:     completion.ResourceTypeAndNameCompletionFunc(f),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(f, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	return cmd
+}
+
+// Complete completes all the required options
+func (o *TreeOptions) Complete(f cmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return cmdutil.UsageErrorf(cmd, "a single resource is required, as TYPE NAME or TYPE/NAME")
+	}
+	o.BuilderArgs = args
+
+	var err error
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.Builder = f.NewBuilder
+	o.StatusViewerFn = polymorphichelpers.StatusViewerFn
+
+	o.DiscoveryClient, err = f.ToDiscoveryClient()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = f.DynamicClient()
+	return err
+}
+
+// Run prints the tree of the objects owned by the resource
+func (o *TreeOptions) Run() error {
+	infos, err := o.Builder().
+		Unstructured().
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		ResourceTypeOrNameArgs(true, o.BuilderArgs...).
+		SingleResourceType().
+		Latest().
+		Flatten().
+		Do().Infos()
+	if err != nil {
+		return err
+	}
+	if len(infos) != 1 {
+		return fmt.Errorf("a single resource is required, got %d", len(infos))
+	}
+	root, ok := infos[0].Object.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected object %T", infos[0].Object)
+	}
+
+	// the persistent volumes are listed to find the volumes bound to the
+	// claims
+	objects, err := relations.ListObjects(o.DiscoveryClient, o.DynamicClient, root.GetNamespace(), persistentVolumes)
+	if err != nil {
+		return err
+	}
+
+	out := o.Out
+	if color.Enabled(o.Out) {
+		// the statuses are colored once the columns are aligned
+		w := color.NewStatusWriter(o.Out)
+		defer w.Flush()
+		out = w
+	}
+	w := printers.GetNewTabWriter(out)
+	defer w.Flush()
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tSTATUS\tAGE\tMESSAGE")
+	o.printTree(w, buildTree(root, objects), "", "")
+	return nil
+}
+
+var persistentVolumes = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumes"}
+
+// node is an object of the tree, with the objects it owns or is related to.
+type node struct {
+	obj      *unstructured.Unstructured
+	children []*node
+}
+
+// buildTree returns the tree of the objects owned by the root, or related
+// to it.
+func buildTree(root *unstructured.Unstructured, objects []*unstructured.Unstructured) *node {
+	owned := map[types.UID][]*unstructured.Unstructured{}
+	byName := map[string]*unstructured.Unstructured{}
+	for _, obj := range objects {
+		for _, ref := range obj.GetOwnerReferences() {
+			owned[ref.UID] = append(owned[ref.UID], obj)
+		}
+		byName[objectKey(obj.GroupVersionKind().GroupKind(), obj.GetNamespace(), obj.GetName())] = obj
+	}
+
+	visited := sets.New[types.UID]()
+	var build func(obj *unstructured.Unstructured) *node
+	build = func(obj *unstructured.Unstructured) *node {
+		n := &node{obj: obj}
+		visited.Insert(obj.GetUID())
+		children := append([]*unstructured.Unstructured{}, owned[obj.GetUID()]...)
+		children = append(children, relatedObjects(obj, byName)...)
+		sort.SliceStable(children, func(i, j int) bool {
+			a, b := children[i], children[j]
+			if a.GetKind() != b.GetKind() {
+				return a.GetKind() < b.GetKind()
+			}
+			return a.GetName() < b.GetName()
+		})
+		for _, child := range children {
+			// the owner references can form cycles
+			if visited.Has(child.GetUID()) {
+				continue
+			}
+			n.children = append(n.children, build(child))
+		}
+		return n
+	}
+	return build(root)
+}
+
+// relatedObjects returns the objects related to the object without owner
+// references: the endpoints of a service, and the volume bound to a claim.
+func relatedObjects(obj *unstructured.Unstructured, byName map[string]*unstructured.Unstructured) []*unstructured.Unstructured {
+	var key string
+	switch obj.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Kind: "Service"}:
+		key = objectKey(schema.GroupKind{Kind: "Endpoints"}, obj.GetNamespace(), obj.GetName())
+	case schema.GroupKind{Kind: "PersistentVolumeClaim"}:
+		volumeName, _, _ := unstructured.NestedString(obj.Object, "spec", "volumeName")
+		if len(volumeName) == 0 {
+			return nil
+		}
+		key = objectKey(schema.GroupKind{Kind: "PersistentVolume"}, "", volumeName)
+	default:
+		return nil
+	}
+	if related, found := byName[key]; found {
+		return []*unstructured.Unstructured{related}
+	}
+	return nil
+}
+
+func objectKey(groupKind schema.GroupKind, namespace, name string) string {
+	return groupKind.String() + "/" + namespace + "/" + name
+}
+
+// printTree prints the node and its children, the prefix being printed
+// before the node and the children prefix before its children.
+func (o *TreeOptions) printTree(w io.Writer, n *node, prefix, childrenPrefix string) {
+	status, message := cmdstatus.ComputeStatus(n.obj, o.StatusViewerFn, nil)
+	fmt.Fprintf(w, "%s\t%s%s/%s\t%s\t%s\t%s\n",
+		printers.EscapeTerminal(n.obj.GetNamespace()),
+		prefix, printers.EscapeTerminal(n.obj.GetKind()), printers.EscapeTerminal(n.obj.GetName()),
+		status, timeformat.Timestamp(n.obj.GetCreationTimestamp().Time), printers.EscapeTerminal(message))
+	for i, child := range n.children {
+		if i == len(n.children)-1 {
+			o.printTree(w, child, childrenPrefix+"└─", childrenPrefix+"  ")
+		} else {
+			o.printTree(w, child, childrenPrefix+"├─", childrenPrefix+"│ ")
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tree
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+func TestTree(t *testing.T) {
+	newObject := func(apiVersion, kind, namespace, name string, owners ...string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		obj.SetUID(types.UID(kind + "/" + name))
+		refs := []interface{}{}
+		for _, owner := range owners {
+			refs = append(refs, map[string]interface{}{"uid": owner})
+		}
+		if len(refs) > 0 {
+			obj.Object["metadata"].(map[string]interface{})["ownerReferences"] = refs
+		}
+		return obj
+	}
+
+	root := newObject("example.com/v1", "Widget", "test", "gear")
+	service := newObject("v1", "Service", "test", "gear", "Widget/gear")
+	claim := newObject("v1", "PersistentVolumeClaim", "test", "data", "Widget/gear")
+	claim.Object["spec"] = map[string]interface{}{"volumeName": "pv-1"}
+	failedPod := newObject("v1", "Pod", "test", "gear-b", "Widget/gear")
+	failedPod.Object["status"] = map[string]interface{}{"phase": "Failed", "message": "evicted"}
+	readyPod := newObject("v1", "Pod", "test", "gear-a", "Widget/gear")
+	readyPod.Object["status"] = map[string]interface{}{
+		"phase":      "Running",
+		"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+	}
+	objects := []*unstructured.Unstructured{
+		root,
+		service,
+		newObject("v1", "Endpoints", "test", "gear"),
+		newObject("discovery.k8s.io/v1", "EndpointSlice", "test", "gear-x1", "Service/gear"),
+		claim,
+		newObject("v1", "PersistentVolume", "", "pv-1"),
+		failedPod,
+		readyPod,
+		// the owner references of the cycle are not followed again
+		newObject("v1", "ConfigMap", "test", "a", "Widget/gear", "ConfigMap/b"),
+		newObject("v1", "ConfigMap", "test", "b", "ConfigMap/a"),
+		newObject("v1", "ConfigMap", "test", "unrelated"),
+		newObject("v1", "ConfigMap", "other", "other", "Widget/other"),
+	}
+
+	buf := &bytes.Buffer{}
+	w := printers.GetNewTabWriter(buf)
+	o := &TreeOptions{StatusViewerFn: polymorphichelpers.StatusViewerFn}
+	o.printTree(w, buildTree(root, objects), "", "")
+	w.Flush()
+
+	expected := `test   Widget/gear                    Current      <unknown>
+test   ├─ConfigMap/a                  Current      <unknown>
+test   │ └─ConfigMap/b                Current      <unknown>
+test   ├─PersistentVolumeClaim/data   InProgress   <unknown>   the claim is not bound
+       │ └─PersistentVolume/pv-1      Current      <unknown>
+test   ├─Pod/gear-a                   Current      <unknown>
+test   ├─Pod/gear-b                   Failed       <unknown>   evicted
+test   └─Service/gear                 Current      <unknown>
+test     ├─EndpointSlice/gear-x1      Current      <unknown>
+test     └─Endpoints/gear             Current      <unknown>
+`
+	// the tabwriter pads the empty trailing columns
+	got := regexp.MustCompile(` +\n`).ReplaceAllString(buf.String(), "\n")
+	if got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
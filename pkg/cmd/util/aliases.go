@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// AliasesExtension is the preferences extension of the kubeconfig file
+// holding the aliases.
+const AliasesExtension = "kubectl.kubernetes.io/aliases"
+
+// Aliases are the user-defined aliases of commands and resources.
+type Aliases struct {
+	// Commands maps the name of an alias to the command line it stands for,
+	// such as "get pods -o wide".
+	Commands map[string]string `json:"commands,omitempty"`
+	// Resources maps the name of an alias to the resource it stands for,
+	// such as "deployments.apps".
+	Resources map[string]string `json:"resources,omitempty"`
+}
+
+// LoadAliases returns the aliases of the kubeconfig file.
+func LoadAliases(config *clientcmdapi.Config) (*Aliases, error) {
+	aliases := &Aliases{Commands: map[string]string{}, Resources: map[string]string{}}
+	extension, ok := config.Preferences.Extensions[AliasesExtension].(*runtime.Unknown)
+	if !ok {
+		return aliases, nil
+	}
+	if err := json.Unmarshal(extension.Raw, aliases); err != nil {
+		return nil, fmt.Errorf("invalid %s extension: %v", AliasesExtension, err)
+	}
+	if aliases.Commands == nil {
+		aliases.Commands = map[string]string{}
+	}
+	if aliases.Resources == nil {
+		aliases.Resources = map[string]string{}
+	}
+	return aliases, nil
+}
+
+// StoreAliases stores the aliases in the kubeconfig file, removing the
+// extension when there are none.
+func StoreAliases(config *clientcmdapi.Config, aliases *Aliases) error {
+	if len(aliases.Commands) == 0 && len(aliases.Resources) == 0 {
+		delete(config.Preferences.Extensions, AliasesExtension)
+		return nil
+	}
+	data, err := json.Marshal(aliases)
+	if err != nil {
+		return err
+	}
+	if config.Preferences.Extensions == nil {
+		config.Preferences.Extensions = map[string]runtime.Object{}
+	}
+	config.Preferences.Extensions[AliasesExtension] = &runtime.Unknown{Raw: data, ContentType: runtime.ContentTypeJSON}
+	return nil
+}
+
+// Expand expands the aliases in the arguments of the root command. A command
+// alias is expanded when it is the first argument that is not a flag, unless
+// a command of that name exists. Resource aliases are then expanded in the
+// arguments of the commands taking resources: in the first argument, which
+// is a list of resource types, and in the arguments of the TYPE/NAME form.
+func (a *Aliases) Expand(root *cobra.Command, args []string) ([]string, error) {
+	expanded := append([]string{}, args...)
+	if i := firstArg(root, expanded); i >= 0 && !hasSubcommand(root, expanded[i]) {
+		if line, ok := a.Commands[expanded[i]]; ok {
+			words, err := shlex.Split(line)
+			if err != nil {
+				return nil, fmt.Errorf("invalid alias %q: %v", expanded[i], err)
+			}
+			expanded = append(append(append([]string{}, expanded[:i]...), words...), expanded[i+1:]...)
+		}
+	}
+	if len(a.Resources) == 0 {
+		return expanded, nil
+	}
+
+	cmd, _, err := root.Find(expanded)
+	if err != nil || cmd == root || !(strings.Contains(cmd.Use, "TYPE") || strings.Contains(cmd.Use, "RESOURCE")) {
+		return expanded, nil
+	}
+	// the names of the command and of its parents are the first arguments
+	depth := 0
+	for c := cmd; c != root; c = c.Parent() {
+		depth++
+	}
+	positional := 0
+	for i := 0; i < len(expanded); i++ {
+		arg := expanded[i]
+		if arg == "--" {
+			break
+		}
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			if takesValue(cmd, arg) {
+				i++
+			}
+			continue
+		}
+		positional++
+		switch {
+		case positional <= depth:
+		case strings.Contains(arg, "/"):
+			resource, name, _ := strings.Cut(arg, "/")
+			if alias, ok := a.Resources[resource]; ok {
+				expanded[i] = alias + "/" + name
+			}
+		case positional == depth+1:
+			resources := strings.Split(arg, ",")
+			for j, resource := range resources {
+				if alias, ok := a.Resources[resource]; ok {
+					resources[j] = alias
+				}
+			}
+			expanded[i] = strings.Join(resources, ",")
+		}
+	}
+	return expanded, nil
+}
+
+// firstArg returns the index of the first argument of the root command that
+// is not a flag, or -1.
+func firstArg(root *cobra.Command, args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			if takesValue(root, arg) {
+				i++
+			}
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// takesValue returns whether the flag of the argument, which is not in the
+// --flag=value form, takes the next argument as its value.
+func takesValue(cmd *cobra.Command, arg string) bool {
+	if strings.Contains(arg, "=") {
+		return false
+	}
+	var flag *pflag.Flag
+	if strings.HasPrefix(arg, "--") {
+		name := strings.TrimPrefix(arg, "--")
+		if flag = cmd.Flags().Lookup(name); flag == nil {
+			flag = cmd.InheritedFlags().Lookup(name)
+		}
+	} else {
+		// longer arguments hold the value of the shorthand, or several
+		// boolean shorthands
+		if len(arg) > 2 {
+			return false
+		}
+		shorthand := arg[1:]
+		if flag = cmd.Flags().ShorthandLookup(shorthand); flag == nil {
+			flag = cmd.InheritedFlags().ShorthandLookup(shorthand)
+		}
+	}
+	return flag != nil && len(flag.NoOptDefVal) == 0
+}
+
+func hasSubcommand(cmd *cobra.Command, name string) bool {
+	for _, c := range cmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
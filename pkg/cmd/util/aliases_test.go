@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestAliasesExpand(t *testing.T) {
+	root := &cobra.Command{Use: "kubectl"}
+	root.PersistentFlags().StringP("namespace", "n", "", "")
+	root.PersistentFlags().Bool("v2", false, "")
+	get := &cobra.Command{Use: "get (TYPE[.VERSION][.GROUP] [NAME | -l label] | TYPE[.VERSION][.GROUP]/NAME ...)", Run: func(*cobra.Command, []string) {}}
+	get.Flags().StringP("output", "o", "", "")
+	get.Flags().BoolP("watch", "w", false, "")
+	rollout := &cobra.Command{Use: "rollout SUBCOMMAND"}
+	rollout.AddCommand(&cobra.Command{Use: "restart RESOURCE", Run: func(*cobra.Command, []string) {}})
+	logs := &cobra.Command{Use: "logs [-f] [-p] (POD | TYPE/NAME) [-c CONTAINER]", Run: func(*cobra.Command, []string) {}}
+	version := &cobra.Command{Use: "version", Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(get, rollout, logs, version)
+
+	aliases := &Aliases{
+		Commands: map[string]string{
+			"gp":      "get pods -o wide",
+			"gj":      `get pods -o 'jsonpath={.items[*].metadata.name}'`,
+			"version": "get nodes",
+		},
+		Resources: map[string]string{
+			"dep": "deployments.apps",
+			"svc": "services",
+		},
+	}
+	tests := []struct {
+		args     string
+		expected string
+	}{
+		{args: "gp", expected: "get pods -o wide"},
+		{args: "-n kube-system gp --watch", expected: "-n kube-system get pods -o wide --watch"},
+		{args: "gj", expected: "get pods -o jsonpath={.items[*].metadata.name}"},
+		{args: "version", expected: "version"},
+		{args: "get dep", expected: "get deployments.apps"},
+		{args: "get dep,svc web", expected: "get deployments.apps,services web"},
+		{args: "get dep/web svc/web", expected: "get deployments.apps/web services/web"},
+		{args: "get pods dep", expected: "get pods dep"},
+		{args: "get -o dep svc", expected: "get -o dep services"},
+		{args: "-n dep get dep", expected: "-n dep get deployments.apps"},
+		{args: "--v2 get dep", expected: "--v2 get deployments.apps"},
+		{args: "rollout restart dep/web", expected: "rollout restart deployments.apps/web"},
+		{args: "logs dep/web", expected: "logs deployments.apps/web"},
+		{args: "get -- dep", expected: "get -- dep"},
+		{args: "unknown dep", expected: "unknown dep"},
+	}
+	for _, test := range tests {
+		t.Run(test.args, func(t *testing.T) {
+			expanded, err := aliases.Expand(root, strings.Fields(test.args))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e, a := strings.Fields(test.expected), expanded; !reflect.DeepEqual(e, a) {
+				t.Errorf("expected %q, got %q", e, a)
+			}
+		})
+	}
+}
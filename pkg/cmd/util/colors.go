@@ -0,0 +1,46 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/kubectl/pkg/util/color"
+)
+
+// ColorsExtension is the preferences extension of the kubeconfig file
+// holding the color theme, which maps roles such as good or bad to colors.
+const ColorsExtension = "kubectl.kubernetes.io/colors"
+
+// LoadColorTheme returns the color theme of the kubeconfig file.
+func LoadColorTheme(config *clientcmdapi.Config) (color.Theme, error) {
+	theme := color.Theme{}
+	extension, ok := config.Preferences.Extensions[ColorsExtension].(*runtime.Unknown)
+	if !ok {
+		return theme, nil
+	}
+	if err := json.Unmarshal(extension.Raw, &theme); err != nil {
+		return nil, fmt.Errorf("invalid %s extension: %v", ColorsExtension, err)
+	}
+	if err := color.ValidateTheme(theme); err != nil {
+		return nil, fmt.Errorf("invalid %s extension: %v", ColorsExtension, err)
+	}
+	return theme, nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/kubectl/pkg/util/color"
+)
+
+func TestLoadColorTheme(t *testing.T) {
+	tests := []struct {
+		name          string
+		extension     string
+		expected      color.Theme
+		expectedError string
+	}{
+		{name: "none", expected: color.Theme{}},
+		{name: "theme", extension: `{"good": "bright-green", "bad": "magenta"}`, expected: color.Theme{"good": "bright-green", "bad": "magenta"}},
+		{name: "unknown color", extension: `{"good": "pink"}`, expectedError: `invalid kubectl.kubernetes.io/colors extension: unknown color "pink" for good`},
+		{name: "invalid", extension: `["green"]`, expectedError: "invalid kubectl.kubernetes.io/colors extension"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := clientcmdapi.NewConfig()
+			if len(tc.extension) > 0 {
+				config.Preferences.Extensions[ColorsExtension] = &runtime.Unknown{Raw: []byte(tc.extension), ContentType: runtime.ContentTypeJSON}
+			}
+			theme, err := LoadColorTheme(config)
+			if len(tc.expectedError) > 0 {
+				if err == nil || !strings.HasPrefix(err.Error(), tc.expectedError) {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tc.expected, theme) {
+				t.Errorf("expected %v, got %v", tc.expected, theme)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/discovery"
+	diskcached "k8s.io/client-go/discovery/cached/disk"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/klog/v2"
+)
+
+const (
+	flagDiscoveryCacheTTL = "discovery-cache-ttl"
+
+	// DefaultDiscoveryCacheTTL is how long the cached discovery is used for
+	// before it is refreshed.
+	DefaultDiscoveryCacheTTL = 6 * time.Hour
+	// maxDiscoveryCacheStaleness is how long an expired discovery cache keeps
+	// being used while it is refreshed in the background. An older cache is
+	// refreshed before it is used.
+	maxDiscoveryCacheStaleness = 7 * 24 * time.Hour
+	// discoveryRefreshWait is how long the end of a command waits for the
+	// background refresh of the discovery cache to complete.
+	discoveryRefreshWait = 10 * time.Second
+)
+
+// DiscoveryCacheFlags configures the discovery cache of the clients of the
+// delegate. The cached discovery is used for --discovery-cache-ttl. When it
+// expired, it keeps being used while it is refreshed in the background, so
+// that commands do not wait for the discovery of all the API resources.
+// A resource missing from the cache, such as the resource of a newly
+// installed custom resource definition, still makes the REST mapper refresh
+// the cache before it fails.
+type DiscoveryCacheFlags struct {
+	Delegate *genericclioptions.ConfigFlags
+
+	TTL            time.Duration
+	DiscoveryBurst int
+	DiscoveryQPS   float32
+
+	// Shared, when set, holds the discovery clients of the other commands
+	// run in the process, which are used instead of creating new ones.
+	Shared *SharedDiscoveryClients
+
+	warningPrinter *printers.WarningPrinter
+
+	lock            sync.Mutex
+	shared          *sharedDiscoveryClient
+	discoveryClient discovery.CachedDiscoveryInterface
+	restMapper      meta.RESTMapper
+	// refreshed is closed when the background refresh completed, it is nil
+	// when there is none.
+	refreshed chan struct{}
+}
+
+var _ genericclioptions.RESTClientGetter = &DiscoveryCacheFlags{}
+
+// SharedDiscoveryClients shares the discovery clients and the REST mappers
+// of the commands run in the same process, such as the commands of kubectl
+// batch, by discovery cache directory. The commands then only discover the
+// API resources of a server once.
+type SharedDiscoveryClients struct {
+	lock    sync.Mutex
+	clients map[string]*sharedDiscoveryClient
+}
+
+type sharedDiscoveryClient struct {
+	discoveryClient discovery.CachedDiscoveryInterface
+	restMapper      meta.RESTMapper
+}
+
+// NewSharedDiscoveryClients returns an empty SharedDiscoveryClients.
+func NewSharedDiscoveryClients() *SharedDiscoveryClients {
+	return &SharedDiscoveryClients{clients: map[string]*sharedDiscoveryClient{}}
+}
+
+func (s *SharedDiscoveryClients) get(discoveryCacheDir string) *sharedDiscoveryClient {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.clients[discoveryCacheDir]
+}
+
+func (s *SharedDiscoveryClients) add(discoveryCacheDir string, discoveryClient discovery.CachedDiscoveryInterface) *sharedDiscoveryClient {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	client := &sharedDiscoveryClient{discoveryClient: discoveryClient}
+	s.clients[discoveryCacheDir] = client
+	return client
+}
+
+// NewDiscoveryCacheFlags returns DiscoveryCacheFlags with default values set.
+func NewDiscoveryCacheFlags(delegate *genericclioptions.ConfigFlags, streams genericiooptions.IOStreams) *DiscoveryCacheFlags {
+	return &DiscoveryCacheFlags{
+		Delegate:       delegate,
+		TTL:            DefaultDiscoveryCacheTTL,
+		DiscoveryBurst: 300,
+		DiscoveryQPS:   50.0,
+		warningPrinter: printers.NewWarningPrinter(streams.ErrOut, printers.WarningPrinterOptions{Color: printers.AllowsColorOutput(streams.ErrOut)}),
+	}
+}
+
+func (f *DiscoveryCacheFlags) AddFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&f.TTL, flagDiscoveryCacheTTL, f.TTL, "How long the cached discovery of the API resources is used for. An expired cache keeps being used while it is refreshed in the background, for up to a week. 0 disables the cache.")
+}
+
+func (f *DiscoveryCacheFlags) ToRESTConfig() (*rest.Config, error) {
+	return f.Delegate.ToRESTConfig()
+}
+
+func (f *DiscoveryCacheFlags) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return f.Delegate.ToRawKubeConfigLoader()
+}
+
+// ToDiscoveryClient returns a discovery client cached on disk, refreshing the
+// cache in the background when it expired.
+func (f *DiscoveryCacheFlags) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.discoveryClient != nil {
+		return f.discoveryClient, nil
+	}
+
+	config, err := f.discoveryConfig()
+	if err != nil {
+		return nil, err
+	}
+	cacheDir := f.cacheDir()
+	httpCacheDir := filepath.Join(cacheDir, "http")
+	discoveryCacheDir := computeDiscoveryCacheDir(filepath.Join(cacheDir, "discovery"), config.Host)
+	if f.Shared != nil {
+		if f.shared = f.Shared.get(discoveryCacheDir); f.shared != nil {
+			f.discoveryClient = f.shared.discoveryClient
+			return f.discoveryClient, nil
+		}
+	}
+
+	ttl := f.TTL
+	if age, ok := discoveryCacheAge(discoveryCacheDir); ok && f.TTL > 0 && age > f.TTL && age < f.TTL+maxDiscoveryCacheStaleness {
+		refreshConfig, err := f.discoveryConfig()
+		if err != nil {
+			return nil, err
+		}
+		// a client ignoring the cache rewrites it
+		refreshClient, err := diskcached.NewCachedDiscoveryClientForConfig(refreshConfig, discoveryCacheDir, httpCacheDir, 0)
+		if err != nil {
+			return nil, err
+		}
+		f.refreshed = make(chan struct{})
+		go func() {
+			defer close(f.refreshed)
+			if _, _, err := refreshClient.ServerGroupsAndResources(); err != nil {
+				klog.V(2).Infof("failed to refresh the discovery cache: %v", err)
+			}
+		}()
+		ttl = f.TTL + maxDiscoveryCacheStaleness
+	}
+	f.discoveryClient, err = diskcached.NewCachedDiscoveryClientForConfig(config, discoveryCacheDir, httpCacheDir, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if f.Shared != nil {
+		f.shared = f.Shared.add(discoveryCacheDir, f.discoveryClient)
+	}
+	return f.discoveryClient, nil
+}
+
+// ToRESTMapper returns a mapper using the discovery client, which refreshes
+// the discovery when a resource is not found.
+func (f *DiscoveryCacheFlags) ToRESTMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := f.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.restMapper != nil {
+		return f.restMapper, nil
+	}
+	if f.shared != nil {
+		f.Shared.lock.Lock()
+		defer f.Shared.lock.Unlock()
+		if f.shared.restMapper != nil {
+			f.restMapper = f.shared.restMapper
+			return f.restMapper, nil
+		}
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	f.restMapper = restmapper.NewShortcutExpander(mapper, discoveryClient, func(warning string) {
+		f.warningPrinter.Print(warning)
+	})
+	if f.shared != nil {
+		f.shared.restMapper = f.restMapper
+	}
+	return f.restMapper, nil
+}
+
+// WaitForRefresh waits a little for the background refresh of the discovery
+// cache to complete, if there is one, so that the next commands use it.
+func (f *DiscoveryCacheFlags) WaitForRefresh() {
+	f.lock.Lock()
+	refreshed := f.refreshed
+	f.lock.Unlock()
+	if refreshed == nil {
+		return
+	}
+	select {
+	case <-refreshed:
+	case <-time.After(discoveryRefreshWait):
+		klog.V(2).Infof("the discovery cache was not refreshed within %s", discoveryRefreshWait)
+	}
+}
+
+func (f *DiscoveryCacheFlags) discoveryConfig() (*rest.Config, error) {
+	config, err := f.Delegate.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.Burst = f.DiscoveryBurst
+	config.QPS = f.DiscoveryQPS
+	return config, nil
+}
+
+// cacheDir returns the --cache-dir, or the KUBECACHEDIR environment variable,
+// or the default cache directory.
+func (f *DiscoveryCacheFlags) cacheDir() string {
+	if f.Delegate.CacheDir != nil && len(*f.Delegate.CacheDir) > 0 {
+		return *f.Delegate.CacheDir
+	}
+	if dir := os.Getenv("KUBECACHEDIR"); len(dir) > 0 {
+		return dir
+	}
+	return filepath.Join(homedir.HomeDir(), ".kube", "cache")
+}
+
+// discoveryCacheAge returns the age of the cached API groups, which are
+// written whenever the cache is refreshed.
+func discoveryCacheAge(discoveryCacheDir string) (time.Duration, bool) {
+	info, err := os.Stat(filepath.Join(discoveryCacheDir, "servergroups.json"))
+	if err != nil {
+		return 0, false
+	}
+	return time.Since(info.ModTime()), true
+}
+
+// overlyCautiousIllegalFileCharacters matches the characters of hosts which
+// might not be supported in file names.
+var overlyCautiousIllegalFileCharacters = regexp.MustCompile(`[^(\w/.)]`)
+
+// computeDiscoveryCacheDir returns the discovery cache directory of the host,
+// the same as the one of the cli-runtime config flags.
+func computeDiscoveryCacheDir(parentDir, host string) string {
+	schemelessHost := strings.Replace(strings.Replace(host, "https://", "", 1), "http://", "", 1)
+	safeHost := overlyCautiousIllegalFileCharacters.ReplaceAllString(schemelessHost, "_")
+	return filepath.Join(parentDir, safeHost)
+}
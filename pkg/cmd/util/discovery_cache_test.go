@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+)
+
+func TestDiscoveryCacheBackgroundRefresh(t *testing.T) {
+	var lock sync.Mutex
+	groups := []string{"apps"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case req.URL.Path == "/api":
+			fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+		case req.URL.Path == "/apis":
+			items := []string{}
+			for _, group := range groups {
+				items = append(items, fmt.Sprintf(`{"name":%q,"versions":[{"groupVersion":"%s/v1","version":"v1"}],"preferredVersion":{"groupVersion":"%s/v1","version":"v1"}}`, group, group, group))
+			}
+			fmt.Fprintf(w, `{"kind":"APIGroupList","groups":[%s]}`, strings.Join(items, ","))
+		default:
+			fmt.Fprintf(w, `{"kind":"APIResourceList","groupVersion":%q,"resources":[]}`, strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, "/apis/"), "/api/"))
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	if err := os.WriteFile(kubeconfig, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	newFlags := func() *DiscoveryCacheFlags {
+		configFlags := genericclioptions.NewConfigFlags(false)
+		configFlags.KubeConfig = &kubeconfig
+		configFlags.APIServer = &server.URL
+		cacheDir := filepath.Join(dir, "cache")
+		configFlags.CacheDir = &cacheDir
+		return NewDiscoveryCacheFlags(configFlags, genericiooptions.NewTestIOStreamsDiscard())
+	}
+	serverGroups := func(f *DiscoveryCacheFlags) []string {
+		client, err := f.ToDiscoveryClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+		list, err := client.ServerGroups()
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, group := range list.Groups {
+			names = append(names, group.Name)
+		}
+		return names
+	}
+
+	// the cache is written by the first command
+	f := newFlags()
+	if e, a := "/apps", strings.Join(serverGroups(f), "/"); e != a {
+		t.Fatalf("expected groups %q, got %q", e, a)
+	}
+	f.WaitForRefresh()
+	cacheFile := filepath.Join(computeDiscoveryCacheDir(filepath.Join(dir, "cache", "discovery"), server.URL), "servergroups.json")
+	if _, err := os.Stat(cacheFile); err != nil {
+		t.Fatal(err)
+	}
+
+	lock.Lock()
+	groups = []string{"apps", "batch"}
+	lock.Unlock()
+
+	// the cache is used before it expires
+	f = newFlags()
+	if e, a := "/apps", strings.Join(serverGroups(f), "/"); e != a {
+		t.Errorf("expected the cached groups %q, got %q", e, a)
+	}
+	f.WaitForRefresh()
+
+	// the expired cache is used while it is refreshed in the background
+	expired := time.Now().Add(-DefaultDiscoveryCacheTTL - time.Hour)
+	if err := os.Chtimes(cacheFile, expired, expired); err != nil {
+		t.Fatal(err)
+	}
+	f = newFlags()
+	// the refresh waits for the server until the cache was read
+	lock.Lock()
+	names := serverGroups(f)
+	lock.Unlock()
+	if e, a := "/apps", strings.Join(names, "/"); e != a {
+		t.Errorf("expected the expired cached groups %q, got %q", e, a)
+	}
+	f.WaitForRefresh()
+	if e, a := "/apps/batch", strings.Join(serverGroups(newFlags()), "/"); e != a {
+		t.Errorf("expected the refreshed groups %q, got %q", e, a)
+	}
+
+	// a cache which expired too long ago is refreshed before it is used
+	lock.Lock()
+	groups = []string{"apps", "batch", "policy"}
+	lock.Unlock()
+	expired = time.Now().Add(-DefaultDiscoveryCacheTTL - maxDiscoveryCacheStaleness - time.Hour)
+	if err := os.Chtimes(cacheFile, expired, expired); err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "/apps/batch/policy", strings.Join(serverGroups(newFlags()), "/"); e != a {
+		t.Errorf("expected the refreshed groups %q, got %q", e, a)
+	}
+}
+
+func TestSharedDiscoveryClients(t *testing.T) {
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "config")
+	if err := os.WriteFile(kubeconfig, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	shared := NewSharedDiscoveryClients()
+	newFlags := func(server string) *DiscoveryCacheFlags {
+		configFlags := genericclioptions.NewConfigFlags(false)
+		configFlags.KubeConfig = &kubeconfig
+		configFlags.APIServer = &server
+		cacheDir := filepath.Join(dir, "cache")
+		configFlags.CacheDir = &cacheDir
+		f := NewDiscoveryCacheFlags(configFlags, genericiooptions.NewTestIOStreamsDiscard())
+		f.Shared = shared
+		return f
+	}
+	discover := func(f *DiscoveryCacheFlags) *DiscoveryCacheFlags {
+		if _, err := f.ToDiscoveryClient(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.ToRESTMapper(); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	first := discover(newFlags("https://one.example.com"))
+	same := discover(newFlags("https://one.example.com"))
+	if first.discoveryClient != same.discoveryClient || same.shared != first.shared || first.shared.restMapper == nil {
+		t.Errorf("expected the commands of the same server to share their discovery client and REST mapper")
+	}
+	other := discover(newFlags("https://two.example.com"))
+	if first.discoveryClient == other.discoveryClient || first.shared == other.shared {
+		t.Errorf("expected the commands of another server to have their own discovery client and REST mapper")
+	}
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package editor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kube-openapi/pkg/util/proto"
+)
+
+// createApplyPatch returns a server-side apply patch holding only the fields
+// that were changed between the original and the edited objects, so that the
+// edit does not take over the fields owned by other managers. Removed fields
+// cannot be expressed in such a patch, their paths are returned instead.
+// The lists are merged item by item with the keys of the schema of the object,
+// lists whose keys are unknown, or all of them if the schema is nil, are
+// applied whole.
+func createApplyPatch(originalJS, editedJS []byte, schema proto.Schema) ([]byte, []string, error) {
+	original := map[string]interface{}{}
+	if err := json.Unmarshal(originalJS, &original); err != nil {
+		return nil, nil, err
+	}
+	edited := map[string]interface{}{}
+	if err := json.Unmarshal(editedJS, &edited); err != nil {
+		return nil, nil, err
+	}
+
+	for _, key := range []string{"apiVersion", "kind"} {
+		if !reflect.DeepEqual(original[key], edited[key]) {
+			return nil, nil, fmt.Errorf("%s", "At least one of apiVersion, kind and name was changed")
+		}
+	}
+	originalMeta, _ := original["metadata"].(map[string]interface{})
+	editedMeta, _ := edited["metadata"].(map[string]interface{})
+	if originalMeta == nil || editedMeta == nil || !reflect.DeepEqual(originalMeta["name"], editedMeta["name"]) {
+		return nil, nil, fmt.Errorf("%s", "At least one of apiVersion, kind and name was changed")
+	}
+	// managed fields and the resource version are not part of the edit
+	for _, meta := range []map[string]interface{}{originalMeta, editedMeta} {
+		delete(meta, "managedFields")
+		delete(meta, "resourceVersion")
+	}
+
+	removed := []string{}
+	patch, _ := diffMaps(nil, schema, original, edited, &removed)
+	if patch == nil {
+		patch = map[string]interface{}{}
+	}
+	patch["apiVersion"] = edited["apiVersion"]
+	patch["kind"] = edited["kind"]
+	patchMeta, _ := patch["metadata"].(map[string]interface{})
+	if patchMeta == nil {
+		patchMeta = map[string]interface{}{}
+		patch["metadata"] = patchMeta
+	}
+	patchMeta["name"] = editedMeta["name"]
+	if namespace, ok := editedMeta["namespace"]; ok {
+		patchMeta["namespace"] = namespace
+	}
+
+	sort.Strings(removed)
+	data, err := json.Marshal(patch)
+	return data, removed, err
+}
+
+// diffMaps returns the fields of edited that differ from original, or nil
+// when there are none.
+func diffMaps(fldPath *field.Path, schema proto.Schema, original, edited map[string]interface{}, removed *[]string) (map[string]interface{}, bool) {
+	changed := map[string]interface{}{}
+	for key, value := range edited {
+		if diff, ok := diffValues(fldPath.Child(key), fieldSchema(schema, key), original[key], value, removed); ok {
+			changed[key] = diff
+		}
+	}
+	for key := range original {
+		if _, ok := edited[key]; !ok {
+			*removed = append(*removed, fldPath.Child(key).String())
+		}
+	}
+	if len(changed) == 0 {
+		return nil, false
+	}
+	return changed, true
+}
+
+func diffValues(fldPath *field.Path, schema proto.Schema, original, edited interface{}, removed *[]string) (interface{}, bool) {
+	if reflect.DeepEqual(original, edited) {
+		return nil, false
+	}
+	switch editedValue := edited.(type) {
+	case map[string]interface{}:
+		if originalValue, ok := original.(map[string]interface{}); ok {
+			return diffMaps(fldPath, schema, originalValue, editedValue, removed)
+		}
+	case []interface{}:
+		if originalValue, ok := original.([]interface{}); ok {
+			if diff, ok := diffKeyedLists(fldPath, schema, originalValue, editedValue, removed); ok {
+				return diff, len(diff) > 0
+			}
+		}
+	}
+	// scalars, atomic lists and values whose type changed are applied whole
+	return edited, true
+}
+
+// diffKeyedLists returns the changed items of the lists whose schema has
+// keys, each with its key fields and changed fields. Other lists, and the lists
+// whose items do not all have a unique key, are not diffed item by item, false
+// is returned for them.
+func diffKeyedLists(fldPath *field.Path, schema proto.Schema, original, edited []interface{}, removed *[]string) ([]interface{}, bool) {
+	array, ok := resolveSchema(schema).(*proto.Array)
+	if !ok {
+		return nil, false
+	}
+	keys := listMapKeys(array)
+	if len(keys) == 0 {
+		return nil, false
+	}
+	originalItems, ok := itemsByKey(original, keys)
+	if !ok {
+		return nil, false
+	}
+	editedItems, ok := itemsByKey(edited, keys)
+	if !ok {
+		return nil, false
+	}
+
+	changed := []interface{}{}
+	for _, item := range edited {
+		editedItem := item.(map[string]interface{})
+		key, name := itemKey(editedItem, keys)
+		originalItem, found := originalItems[key]
+		if !found {
+			changed = append(changed, editedItem)
+			continue
+		}
+		if diff, ok := diffMaps(fldPath.Key(name), array.SubType, originalItem, editedItem, removed); ok {
+			for _, k := range keys {
+				if value, ok := editedItem[k]; ok {
+					diff[k] = value
+				}
+			}
+			changed = append(changed, diff)
+		}
+	}
+	for _, item := range original {
+		key, name := itemKey(item.(map[string]interface{}), keys)
+		if _, found := editedItems[key]; !found {
+			*removed = append(*removed, fldPath.Key(name).String())
+		}
+	}
+	return changed, true
+}
+
+// listMapKeys returns the fields identifying the items of the list, from its
+// list map keys or its patch merge key, or nil if its items are not merged by
+// key.
+func listMapKeys(array *proto.Array) []string {
+	extensions := array.GetExtensions()
+	if listType, ok := extensions["x-kubernetes-list-type"].(string); ok && listType != "map" {
+		return nil
+	}
+	if mapKeys, ok := extensions["x-kubernetes-list-map-keys"].([]interface{}); ok && len(mapKeys) > 0 {
+		keys := []string{}
+		for _, key := range mapKeys {
+			k, ok := key.(string)
+			if !ok {
+				return nil
+			}
+			keys = append(keys, k)
+		}
+		return keys
+	}
+	if key, ok := extensions["x-kubernetes-patch-merge-key"].(string); ok && len(key) > 0 {
+		return []string{key}
+	}
+	return nil
+}
+
+func itemsByKey(items []interface{}, keys []string) (map[string]map[string]interface{}, bool) {
+	if len(items) == 0 {
+		return nil, false
+	}
+	byKey := map[string]map[string]interface{}{}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		key, _ := itemKey(m, keys)
+		if len(key) == 0 {
+			return nil, false
+		}
+		if _, duplicate := byKey[key]; duplicate {
+			return nil, false
+		}
+		byKey[key] = m
+	}
+	return byKey, true
+}
+
+// itemKey returns the key identifying the item, and its name in field paths,
+// joining the values of its key fields. The key is empty if the item has none
+// of the key fields, or if they are not scalars.
+func itemKey(item map[string]interface{}, keys []string) (string, string) {
+	values := []interface{}{}
+	names := []string{}
+	found := false
+	for _, k := range keys {
+		value, ok := item[k]
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return "", ""
+		}
+		if ok {
+			found = true
+			names = append(names, fmt.Sprint(value))
+		}
+		values = append(values, value)
+	}
+	if !found {
+		return "", ""
+	}
+	key, err := json.Marshal(values)
+	if err != nil {
+		return "", ""
+	}
+	return string(key), strings.Join(names, ",")
+}
+
+// fieldSchema returns the schema of the field of the object or map schema, or
+// nil if it is unknown.
+func fieldSchema(schema proto.Schema, name string) proto.Schema {
+	switch s := resolveSchema(schema).(type) {
+	case *proto.Kind:
+		return s.Fields[name]
+	case *proto.Map:
+		return s.SubType
+	}
+	return nil
+}
+
+func resolveSchema(s proto.Schema) proto.Schema {
+	for {
+		ref, ok := s.(proto.Reference)
+		if !ok {
+			return s
+		}
+		s = ref.SubSchema()
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package editor
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	openapitesting "k8s.io/kubectl/pkg/util/openapi/testing"
+)
+
+var fakeResources = openapitesting.NewFakeResources(filepath.Join("..", "..", "..", "..", "testdata", "openapi", "swagger.json"))
+
+func TestCreateApplyPatch(t *testing.T) {
+	original := `{
+  "apiVersion": "apps/v1",
+  "kind": "Deployment",
+  "metadata": {"name": "web", "namespace": "test", "resourceVersion": "1", "labels": {"app": "web"}, "managedFields": [{"manager": "other"}]},
+  "spec": {
+    "replicas": 1,
+    "template": {"spec": {"containers": [
+      {"name": "web", "image": "nginx:1", "ports": [{"containerPort": 80}]},
+      {"name": "proxy", "image": "envoy:1"}
+    ]}}
+  }
+}`
+
+	tests := []struct {
+		name            string
+		edited          string
+		expectedPatch   string
+		expectedRemoved []string
+		expectedErr     string
+	}{
+		{
+			name: "changed fields only",
+			edited: `{
+  "apiVersion": "apps/v1",
+  "kind": "Deployment",
+  "metadata": {"name": "web", "namespace": "test", "resourceVersion": "1", "labels": {"app": "web", "tier": "frontend"}, "managedFields": [{"manager": "other"}]},
+  "spec": {
+    "replicas": 3,
+    "template": {"spec": {"containers": [
+      {"name": "web", "image": "nginx:2", "ports": [{"containerPort": 80}]},
+      {"name": "proxy", "image": "envoy:1"},
+      {"name": "logger", "image": "fluentd"}
+    ]}}
+  }
+}`,
+			expectedPatch:   `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"labels":{"tier":"frontend"},"name":"web","namespace":"test"},"spec":{"replicas":3,"template":{"spec":{"containers":[{"image":"nginx:2","name":"web"},{"image":"fluentd","name":"logger"}]}}}}`,
+			expectedRemoved: []string{},
+		},
+		{
+			name: "removed fields are reported",
+			edited: `{
+  "apiVersion": "apps/v1",
+  "kind": "Deployment",
+  "metadata": {"name": "web", "namespace": "test", "resourceVersion": "1"},
+  "spec": {
+    "replicas": 1,
+    "template": {"spec": {"containers": [
+      {"name": "web", "image": "nginx:1", "ports": [{"containerPort": 8080}]}
+    ]}}
+  }
+}`,
+			expectedPatch:   `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","namespace":"test"},"spec":{"template":{"spec":{"containers":[{"name":"web","ports":[{"containerPort":8080}]}]}}}}`,
+			expectedRemoved: []string{"metadata.labels", "spec.template.spec.containers[proxy]", "spec.template.spec.containers[web].ports[80]"},
+		},
+		{
+			name:        "renamed object",
+			edited:      `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "other"}}`,
+			expectedErr: "At least one of apiVersion, kind and name was changed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := fakeResources.LookupResource(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+			patch, removed, err := createApplyPatch([]byte(original), []byte(tt.edited), deployment)
+			if len(tt.expectedErr) > 0 {
+				if err == nil || err.Error() != tt.expectedErr {
+					t.Fatalf("expected error %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(patch) != tt.expectedPatch {
+				t.Errorf("expected patch:\n%s\ngot:\n%s", tt.expectedPatch, patch)
+			}
+			if !reflect.DeepEqual(removed, tt.expectedRemoved) {
+				t.Errorf("expected removed fields %v, got %v", tt.expectedRemoved, removed)
+			}
+		})
+	}
+}
+
+func TestCreateApplyPatchListKeys(t *testing.T) {
+	deployment := fakeResources.LookupResource(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+	service := fakeResources.LookupResource(schema.GroupVersionKind{Version: "v1", Kind: "Service"})
+
+	tests := []struct {
+		name          string
+		schema        proto.Schema
+		original      string
+		edited        string
+		expectedPatch string
+	}{
+		{
+			name:          "list map keys",
+			schema:        service,
+			original:      `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {"ports": [{"name": "http", "port": 80, "protocol": "TCP", "targetPort": 80}, {"name": "dns", "port": 53, "protocol": "UDP", "targetPort": 53}]}}`,
+			edited:        `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}, "spec": {"ports": [{"name": "http", "port": 80, "protocol": "TCP", "targetPort": 8080}, {"name": "dns", "port": 53, "protocol": "UDP", "targetPort": 53}]}}`,
+			expectedPatch: `{"apiVersion":"v1","kind":"Service","metadata":{"name":"web"},"spec":{"ports":[{"port":80,"protocol":"TCP","targetPort":8080}]}}`,
+		},
+		{
+			name:          "patch merge key",
+			schema:        deployment,
+			original:      `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"}, "spec": {"template": {"spec": {"containers": [{"name": "web", "volumeMounts": [{"name": "data", "mountPath": "/data"}, {"name": "data", "mountPath": "/cache", "subPath": "cache"}]}]}}}}`,
+			edited:        `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"}, "spec": {"template": {"spec": {"containers": [{"name": "web", "volumeMounts": [{"name": "data", "mountPath": "/data", "readOnly": true}, {"name": "data", "mountPath": "/cache", "subPath": "cache"}]}]}}}}`,
+			expectedPatch: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},"spec":{"template":{"spec":{"containers":[{"name":"web","volumeMounts":[{"mountPath":"/data","readOnly":true}]}]}}}}`,
+		},
+		{
+			name:          "atomic list",
+			schema:        deployment,
+			original:      `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"}, "spec": {"template": {"spec": {"containers": [{"name": "web", "args": ["--port", "80"]}]}}}}`,
+			edited:        `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"}, "spec": {"template": {"spec": {"containers": [{"name": "web", "args": ["--port", "8080"]}]}}}}`,
+			expectedPatch: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},"spec":{"template":{"spec":{"containers":[{"args":["--port","8080"],"name":"web"}]}}}}`,
+		},
+		{
+			name:          "unknown keys",
+			original:      `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"}, "spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx:1"}, {"name": "proxy", "image": "envoy:1"}]}}}}`,
+			edited:        `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "web"}, "spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx:2"}, {"name": "proxy", "image": "envoy:1"}]}}}}`,
+			expectedPatch: `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web"},"spec":{"template":{"spec":{"containers":[{"image":"nginx:2","name":"web"},{"image":"envoy:1","name":"proxy"}]}}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, _, err := createApplyPatch([]byte(tt.original), []byte(tt.edited), tt.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(patch) != tt.expectedPatch {
+				t.Errorf("expected patch:\n%s\ngot:\n%s", tt.expectedPatch, patch)
+			}
+		})
+	}
+}
@@ -39,7 +39,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/mergepatch"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -48,11 +50,13 @@ import (
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kube-openapi/pkg/util/proto"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/cmd/util/editor/crlf"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util"
 	"k8s.io/kubectl/pkg/util/slice"
+	"k8s.io/kubectl/pkg/validation"
 )
 
 var SupportedSubresources = []string{"status"}
@@ -67,9 +71,16 @@ type EditOptions struct {
 
 	OutputPatch        bool
 	WindowsLineEndings bool
+	// ServerSideApply submits the edit as a server-side apply patch with
+	// only the fields that were changed.
+	ServerSideApply bool
+	ForceConflicts  bool
 
 	cmdutil.ValidateOptions
 	ValidationDirective string
+	// openAPIV3Validator checks the edited objects against the schemas of
+	// the server before they are submitted.
+	openAPIV3Validator validation.Schema
 
 	OriginalResult *resource.Result
 
@@ -178,6 +189,12 @@ func (o *EditOptions) Complete(f cmdutil.Factory, args []string, cmd *cobra.Comm
 	if o.OutputPatch && o.EditMode != NormalEditMode {
 		return fmt.Errorf("the edit mode doesn't support output the patch")
 	}
+	if o.ServerSideApply && o.EditMode != NormalEditMode {
+		return fmt.Errorf("the edit mode doesn't support server-side apply")
+	}
+	if o.ForceConflicts && !o.ServerSideApply {
+		return fmt.Errorf("--force-conflicts only works with --ssa")
+	}
 
 	cmdNamespace, enforceNamespace, err := f.ToRawKubeConfigLoader().Namespace()
 	if err != nil {
@@ -221,6 +238,14 @@ func (o *EditOptions) Complete(f cmdutil.Factory, args []string, cmd *cobra.Comm
 	if err != nil {
 		return err
 	}
+	if o.ValidationDirective != metav1.FieldValidationIgnore {
+		openAPIV3Client, err := f.OpenAPIV3Client()
+		if err != nil {
+			klog.V(2).Infof("skipping OpenAPI V3 validation of the edited objects: %v", err)
+		} else {
+			o.openAPIV3Validator = validation.NewOpenAPIV3Validation(openAPIV3Client)
+		}
+	}
 
 	o.CmdNamespace = cmdNamespace
 	o.f = f
@@ -352,6 +377,20 @@ func (o *EditOptions) Run() error {
 				file: file,
 			}
 
+			// check the fields against the schemas of the server, so that
+			// mistakes are reported in the file before submitting it
+			if reason := o.validateOpenAPIV3(edited); reason != nil {
+				if o.ValidationDirective == metav1.FieldValidationStrict {
+					containsError = true
+					results.header.reasons = append(results.header.reasons, *reason)
+					fmt.Fprintln(o.ErrOut, "error: the edited file failed validation against the OpenAPI schema")
+					continue
+				}
+				for _, other := range reason.other {
+					fmt.Fprintf(o.ErrOut, "Warning: %s\n", other)
+				}
+			}
+
 			// parse the edited file
 			updatedInfos, err := o.updatedResultGetter(edited).Infos()
 			if err != nil {
@@ -671,12 +710,29 @@ func (o *EditOptions) visitToPatch(originalInfos []*resource.Info, patchVisitor
 			mergepatch.RequireKeyUnchanged("managedFields"),
 		}
 
+		var patchType types.PatchType
+		var patch []byte
+		var patchOptions *metav1.PatchOptions
+		if o.ServerSideApply {
+			patchType = types.ApplyPatchType
+			var removed []string
+			patch, removed, err = createApplyPatch(originalJS, editedJS, o.applyPatchSchema(info.Mapping.GroupVersionKind))
+			if err != nil {
+				klog.V(4).Infof("Unable to calculate the apply patch: %v", err)
+				return err
+			}
+			for _, path := range removed {
+				fmt.Fprintf(o.ErrOut, "Warning: %s was removed from %s %q, removing fields is not supported with --ssa\n", path, info.Mapping.Resource.Resource, info.Name)
+			}
+			patchOptions = &metav1.PatchOptions{Force: &o.ForceConflicts}
+		}
+
 		// Create the versioned struct from the type defined in the mapping
 		// (which is the API version we'll be submitting the patch to)
 		versionedObject, err := scheme.Scheme.New(info.Mapping.GroupVersionKind)
-		var patchType types.PatchType
-		var patch []byte
 		switch {
+		case o.ServerSideApply:
+			// the apply patch was computed above
 		case runtime.IsNotRegisteredError(err):
 			// fall back to generic JSON merge patch
 			patchType = types.MergePatchType
@@ -719,7 +775,7 @@ func (o *EditOptions) visitToPatch(originalInfos []*resource.Info, patchVisitor
 			WithFieldManager(o.FieldManager).
 			WithFieldValidation(o.ValidationDirective).
 			WithSubresource(o.Subresource).
-			Patch(info.Namespace, info.Name, patchType, patch, nil)
+			Patch(info.Namespace, info.Name, patchType, patch, patchOptions)
 		if err != nil {
 			fmt.Fprintln(o.ErrOut, results.addError(err, info))
 			return nil
@@ -786,6 +842,43 @@ const (
 	ApplyEditMode EditMode = "edit_last_applied_mode"
 )
 
+// applyPatchSchema returns the OpenAPI schema of the kind, used to merge the
+// lists of the apply patch item by item, or nil if it is unavailable.
+func (o *EditOptions) applyPatchSchema(gvk schema.GroupVersionKind) proto.Schema {
+	if o.f == nil {
+		return nil
+	}
+	resources, err := o.f.OpenAPISchema()
+	if err != nil {
+		klog.V(2).Infof("Unable to get the OpenAPI schema, the lists will be applied whole: %v", err)
+		return nil
+	}
+	return resources.LookupResource(gvk)
+}
+
+// validateOpenAPIV3 returns the fields of the edited objects that do not match
+// the OpenAPI V3 schemas of the server, or nil when they all do. Other errors,
+// such as syntax errors, are left to the parsing of the edited file.
+func (o *EditOptions) validateOpenAPIV3(edited []byte) *editReason {
+	if o.openAPIV3Validator == nil {
+		return nil
+	}
+	agg, ok := o.openAPIV3Validator.ValidateBytes(cmdutil.StripComments(edited)).(utilerrors.Aggregate)
+	if !ok {
+		return nil
+	}
+	reason := &editReason{head: "The edited file failed validation against the OpenAPI schema"}
+	for _, err := range agg.Errors() {
+		if fieldErr, ok := err.(*field.Error); ok {
+			reason.other = append(reason.other, fieldErr.Error())
+		}
+	}
+	if len(reason.other) == 0 {
+		return nil
+	}
+	return reason
+}
+
 // editReason preserves a message about the reason this file must be edited again
 type editReason struct {
 	head  string
@@ -18,6 +18,7 @@ package editor
 
 import (
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
@@ -30,6 +31,8 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/openapi/openapitest"
+	"k8s.io/kubectl/pkg/validation"
 )
 
 func TestHashOnLineBreak(t *testing.T) {
@@ -472,3 +475,46 @@ func TestEditOptions_visitToPatch(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateOpenAPIV3(t *testing.T) {
+	o := &EditOptions{openAPIV3Validator: validation.NewOpenAPIV3Validation(openapitest.NewEmbeddedFileClient())}
+
+	valid := []byte(`# Please edit the object below.
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  key: value
+`)
+	if reason := o.validateOpenAPIV3(valid); reason != nil {
+		t.Errorf("unexpected reason: %#v", reason)
+	}
+
+	invalid := []byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  lables:
+    app: web
+data:
+  key: 1
+`)
+	reason := o.validateOpenAPIV3(invalid)
+	if reason == nil {
+		t.Fatalf("expected the edited file to fail validation")
+	}
+	sort.Strings(reason.other)
+	expected := []string{
+		`data[key]: Invalid value: 1: must be a string`,
+		`metadata.lables: Invalid value: "lables": unknown field`,
+	}
+	if !reflect.DeepEqual(reason.other, expected) {
+		t.Errorf("expected %v, got %v", expected, reason.other)
+	}
+
+	// syntax errors are reported when parsing the edited file
+	if reason := o.validateOpenAPIV3([]byte("kind: [")); reason != nil {
+		t.Errorf("unexpected reason for a syntax error: %#v", reason)
+	}
+}
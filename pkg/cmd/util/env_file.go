@@ -20,6 +20,7 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"unicode"
@@ -77,14 +78,20 @@ func AddFromEnvFile(filePath string, addTo func(key, value string) error) error
 		return err
 	}
 	defer f.Close()
+	return AddFromEnvReader(f, filePath, addTo)
+}
 
-	scanner := bufio.NewScanner(f)
+// AddFromEnvReader processes env file content read from r, such as a
+// decrypted env file, like AddFromEnvFile. The source names the content in
+// error messages.
+func AddFromEnvReader(r io.Reader, source string, addTo func(key, value string) error) error {
+	scanner := bufio.NewScanner(r)
 	currentLine := 0
 	for scanner.Scan() {
 		// Process the current line, retrieving a key/value pair if
 		// possible.
 		scannedBytes := scanner.Bytes()
-		key, value, err := processEnvFileLine(scannedBytes, filePath, currentLine)
+		key, value, err := processEnvFileLine(scannedBytes, source, currentLine)
 		if err != nil {
 			return err
 		}
@@ -83,6 +83,12 @@ func BehaviorOnFatal(f func(string, int)) {
 	fatalErrHandler = f
 }
 
+// WrapBehaviorOnFatal wraps the current behavior when a fatal error occurs,
+// for instance to record the error before exiting.
+func WrapBehaviorOnFatal(wrap func(f func(string, int)) func(string, int)) {
+	fatalErrHandler = wrap(fatalErrHandler)
+}
+
 // DefaultBehaviorOnFatal allows you to undo any previous override.  Useful in
 // tests.
 func DefaultBehaviorOnFatal() {
@@ -523,6 +529,14 @@ func AddLabelSelectorFlagVar(cmd *cobra.Command, p *string) {
 	cmd.Flags().StringVarP(p, "selector", "l", *p, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2). Matching objects must satisfy all of the specified label constraints.")
 }
 
+func AddAtomicFlagVar(cmd *cobra.Command, p *bool) {
+	cmd.Flags().BoolVar(p, "atomic", *p, "If true, check all the objects before changing any of them, and roll back the objects already changed when changing one fails.")
+}
+
+func AddPickFlagVar(cmd *cobra.Command, p *bool) {
+	cmd.Flags().BoolVar(p, "pick", *p, "If true and no resource name is given, pick the resource from the matching resources, filtered by fuzzy search. Requires a terminal.")
+}
+
 func AddPruningFlags(cmd *cobra.Command, prune *bool, pruneAllowlist *[]string, all *bool, applySetRef *string) {
 	// Flags associated with the original allowlist-based alpha
 	cmd.Flags().StringArrayVar(pruneAllowlist, "prune-allowlist", *pruneAllowlist, "Overwrite the default allowlist with <group/version/kind> for --prune")
@@ -795,6 +809,26 @@ func ParsePairs(pairArgs []string, pairType string, supportRemove bool) (newPair
 	return
 }
 
+// ParseFilePairs reads the values of "KEY=PATH" pair args from the named files
+func ParseFilePairs(pairArgs []string, pairType string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for _, pairArg := range pairArgs {
+		key, path, ok := strings.Cut(pairArg, "=")
+		if !ok || len(key) == 0 || len(path) == 0 {
+			return nil, fmt.Errorf("invalid %s file format: %s, expected KEY=PATH", pairType, pairArg)
+		}
+		if _, found := pairs[key]; found {
+			return nil, fmt.Errorf("%s %q is read from more than one file", pairType, key)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s %q: %v", pairType, key, err)
+		}
+		pairs[key] = string(data)
+	}
+	return pairs, nil
+}
+
 // IsSiblingCommandExists receives a pointer to a cobra command and a target string.
 // Returns true if the target string is found in the list of sibling commands.
 func IsSiblingCommandExists(cmd *cobra.Command, targetCmdName string) bool {
@@ -588,3 +588,31 @@ func TestGetValidationDirective(t *testing.T) {
 
 	}
 }
+
+func TestParseFilePairs(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/cert.pem"
+	if err := os.WriteFile(path, []byte("-----BEGIN CERTIFICATE-----\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pairs, err := ParseFilePairs([]string{"cert=" + path}, "annotation")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(map[string]string{"cert": "-----BEGIN CERTIFICATE-----\n"}, pairs); diff != "" {
+		t.Errorf("unexpected pairs (-want +got):\n%s", diff)
+	}
+
+	for _, args := range [][]string{
+		{"cert"},
+		{"=" + path},
+		{"cert="},
+		{"cert=" + dir + "/missing"},
+		{"cert=" + path, "cert=" + path},
+	} {
+		if _, err := ParseFilePairs(args, "annotation"); err == nil {
+			t.Errorf("expected %v to be invalid", args)
+		}
+	}
+}
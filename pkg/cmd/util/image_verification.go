@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ImageVerificationExtension is the preferences extension of the kubeconfig
+// file holding the defaults of the signature verification flags of
+// kubectl images.
+const ImageVerificationExtension = "kubectl.kubernetes.io/image-verification"
+
+// ImageVerification is how the cosign signatures of images are verified:
+// with a public key, or with certificates issued to an identity by an OIDC
+// issuer and chaining to trusted roots.
+type ImageVerification struct {
+	// Key is the path to the PEM encoded public key of the signatures.
+	Key                   string `json:"key,omitempty"`
+	CertificateIdentity   string `json:"certificateIdentity,omitempty"`
+	CertificateOIDCIssuer string `json:"certificateOidcIssuer,omitempty"`
+	// CertificateRoots is the path to the PEM encoded certificates the
+	// signing certificates must chain to, such as the Fulcio roots.
+	CertificateRoots string `json:"certificateRoots,omitempty"`
+	// RekorKey is the path to the PEM encoded public key of the Rekor
+	// transparency log the signatures made with certificates must be in.
+	RekorKey string `json:"rekorKey,omitempty"`
+}
+
+// LoadImageVerification returns the image verification settings of the
+// kubeconfig file.
+func LoadImageVerification(config *clientcmdapi.Config) (ImageVerification, error) {
+	verification := ImageVerification{}
+	extension, ok := config.Preferences.Extensions[ImageVerificationExtension].(*runtime.Unknown)
+	if !ok {
+		return verification, nil
+	}
+	if err := json.Unmarshal(extension.Raw, &verification); err != nil {
+		return verification, fmt.Errorf("invalid %s extension: %v", ImageVerificationExtension, err)
+	}
+	return verification, nil
+}
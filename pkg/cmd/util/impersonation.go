@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ImpersonationProfilesExtension is the preferences extension of the
+// kubeconfig file holding the impersonation profiles selected with
+// --as-profile, by name.
+const ImpersonationProfilesExtension = "kubectl.kubernetes.io/impersonation-profiles"
+
+// ImpersonationProfile is a named set of impersonation settings.
+type ImpersonationProfile struct {
+	User   string              `json:"user"`
+	UID    string              `json:"uid,omitempty"`
+	Groups []string            `json:"groups,omitempty"`
+	Extra  map[string][]string `json:"extra,omitempty"`
+	// Confirm asks for a confirmation before running a command with the
+	// profile.
+	Confirm bool `json:"confirm,omitempty"`
+	// RequireReason requires a reason to be given with --reason.
+	RequireReason bool `json:"requireReason,omitempty"`
+}
+
+// LoadImpersonationProfiles returns the impersonation profiles of the
+// kubeconfig file.
+func LoadImpersonationProfiles(config *clientcmdapi.Config) (map[string]ImpersonationProfile, error) {
+	profiles := map[string]ImpersonationProfile{}
+	extension, ok := config.Preferences.Extensions[ImpersonationProfilesExtension].(*runtime.Unknown)
+	if !ok {
+		return profiles, nil
+	}
+	if err := json.Unmarshal(extension.Raw, &profiles); err != nil {
+		return nil, fmt.Errorf("invalid %s extension: %v", ImpersonationProfilesExtension, err)
+	}
+	for name, profile := range profiles {
+		if len(profile.User) == 0 {
+			return nil, fmt.Errorf("invalid %s extension: the profile %q has no user", ImpersonationProfilesExtension, name)
+		}
+	}
+	return profiles, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// InferNamespace searches all the namespaces for the objects named by args,
+// as TYPE NAME... or TYPE/NAME..., and returns the namespace they are in.
+// When an object of the name exists in several namespaces, its namespace is
+// picked with pick, or an error listing them is returned when pick is nil.
+// The objects must all be in the same namespace. An empty namespace is
+// returned when the objects are not namespaced.
+func InferNamespace(newBuilder func() *resource.Builder, pick PickFunc, args []string) (string, error) {
+	type namedObject struct {
+		resourceType string
+		name         string
+	}
+	objects := []namedObject{}
+	if len(args) > 0 && strings.Contains(args[0], "/") {
+		for _, arg := range args {
+			resourceType, name, ok := strings.Cut(arg, "/")
+			if !ok {
+				return "", fmt.Errorf("arguments in resource/name form must have a single resource and name")
+			}
+			objects = append(objects, namedObject{resourceType, name})
+		}
+	} else if len(args) > 1 {
+		for _, name := range args[1:] {
+			objects = append(objects, namedObject{args[0], name})
+		}
+	}
+
+	namespace := ""
+	for _, object := range objects {
+		infos, err := newBuilder().
+			Unstructured().
+			AllNamespaces(true).
+			FieldSelectorParam("metadata.name="+object.name).
+			ResourceTypeOrNameArgs(true, object.resourceType).
+			Flatten().
+			Do().
+			Infos()
+		if err != nil {
+			return "", err
+		}
+		namespaces := sets.New[string]()
+		found := false
+		for _, info := range infos {
+			// servers not supporting the field selector return all the objects
+			if info.Name != object.name {
+				continue
+			}
+			found = true
+			if info.Namespaced() {
+				namespaces.Insert(info.Namespace)
+			}
+		}
+		if !found {
+			return "", apierrors.NewNotFound(schema.GroupResource{Resource: object.resourceType}, object.name)
+		}
+
+		candidates := sets.List(namespaces)
+		switch {
+		case len(candidates) == 0:
+			continue
+		case len(candidates) > 1 && pick == nil:
+			return "", fmt.Errorf("%s %q exists in several namespaces: %s; specify one with --namespace", object.resourceType, object.name, strings.Join(candidates, ", "))
+		case len(candidates) > 1:
+			items := make([]string, 0, len(candidates))
+			for _, candidate := range candidates {
+				items = append(items, candidate+"/"+object.name)
+			}
+			i, err := pick(items)
+			if err != nil {
+				return "", err
+			}
+			candidates = candidates[i : i+1]
+		}
+		if len(namespace) > 0 && namespace != candidates[0] {
+			inNamespaces := []string{namespace, candidates[0]}
+			sort.Strings(inNamespaces)
+			return "", fmt.Errorf("the objects are in different namespaces: %s; name them in separate commands", strings.Join(inNamespaces, ", "))
+		}
+		namespace = candidates[0]
+	}
+	return namespace, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/kubectl/pkg/localcluster"
+)
+
+const (
+	flagLocalCluster      = "local-cluster"
+	flagLocalClusterWrite = "local-cluster-write"
+)
+
+// localClusterHost is the host of the local cluster, which is never
+// connected to.
+const localClusterHost = "http://local-cluster"
+
+// LocalClusterFlags makes the clients of the delegate operate on a directory
+// of manifests, or on a cluster dump, instead of the API server, when
+// --local-cluster is set.
+type LocalClusterFlags struct {
+	Delegate genericclioptions.RESTClientGetter
+
+	Dir string
+	// Write makes the objects created, changed or deleted be written to the
+	// manifests of the directory.
+	Write bool
+
+	lock            sync.Mutex
+	cluster         *localcluster.Cluster
+	discoveryClient discovery.CachedDiscoveryInterface
+	restMapper      meta.RESTMapper
+}
+
+var _ genericclioptions.RESTClientGetter = &LocalClusterFlags{}
+
+// NewLocalClusterFlags returns LocalClusterFlags with default values set.
+func NewLocalClusterFlags(delegate genericclioptions.RESTClientGetter) *LocalClusterFlags {
+	return &LocalClusterFlags{
+		Delegate: delegate,
+	}
+}
+
+func (f *LocalClusterFlags) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&f.Dir, flagLocalCluster, f.Dir, "Operate on the manifests of this directory, or on a cluster dump, instead of the API server. The manifests are read-only, unless --local-cluster-write is set: only dry runs of changes are allowed.")
+	flags.BoolVar(&f.Write, flagLocalClusterWrite, f.Write, "If true, the objects created, changed or deleted with --local-cluster are written to the manifests of the directory, which are rewritten, or removed when they have no objects left.")
+}
+
+// ToRESTConfig returns a configuration for the local cluster when
+// --local-cluster is set.
+func (f *LocalClusterFlags) ToRESTConfig() (*rest.Config, error) {
+	if len(f.Dir) == 0 {
+		return f.Delegate.ToRESTConfig()
+	}
+	cluster, err := f.localCluster()
+	if err != nil {
+		return nil, err
+	}
+	return &rest.Config{
+		Host:      localClusterHost,
+		Transport: cluster,
+		QPS:       -1,
+	}, nil
+}
+
+func (f *LocalClusterFlags) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return f.Delegate.ToRawKubeConfigLoader()
+}
+
+// ToDiscoveryClient returns a discovery client of the local cluster, cached
+// in memory, when --local-cluster is set.
+func (f *LocalClusterFlags) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	if len(f.Dir) == 0 {
+		return f.Delegate.ToDiscoveryClient()
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.discoveryClient == nil {
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		f.discoveryClient = memory.NewMemCacheClient(discoveryClient)
+	}
+	return f.discoveryClient, nil
+}
+
+// ToRESTMapper returns a mapper of the resources of the local cluster when
+// --local-cluster is set.
+func (f *LocalClusterFlags) ToRESTMapper() (meta.RESTMapper, error) {
+	if len(f.Dir) == 0 {
+		return f.Delegate.ToRESTMapper()
+	}
+	discoveryClient, err := f.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.restMapper == nil {
+		mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+		f.restMapper = restmapper.NewShortcutExpander(mapper, discoveryClient, nil)
+	}
+	return f.restMapper, nil
+}
+
+func (f *LocalClusterFlags) localCluster() (*localcluster.Cluster, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.cluster == nil {
+		cluster, err := localcluster.New(f.Dir)
+		if err != nil {
+			return nil, err
+		}
+		cluster.Write = f.Write
+		f.cluster = cluster
+	}
+	return f.cluster, nil
+}
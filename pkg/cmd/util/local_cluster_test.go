@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestLocalClusterFlagsRESTMapper(t *testing.T) {
+	dir := t.TempDir()
+	crd := `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  scope: Namespaced
+  names:
+    plural: widgets
+    kind: Widget
+    shortNames: [wd]
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`
+	if err := os.WriteFile(filepath.Join(dir, "crd.yaml"), []byte(crd), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewLocalClusterFlags(genericclioptions.NewConfigFlags(false))
+	f.Dir = dir
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Host != localClusterHost {
+		t.Errorf("expected the host %s, got %s", localClusterHost, config.Host)
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for resource, expected := range map[string]schema.GroupVersionResource{
+		"deploy": {Group: "apps", Version: "v1", Resource: "deployments"},
+		"ev":     {Version: "v1", Resource: "events"},
+		"wd":     {Group: "example.com", Version: "v1", Resource: "widgets"},
+	} {
+		gvr, err := mapper.ResourceFor(schema.GroupVersionResource{Resource: resource})
+		if err != nil {
+			t.Errorf("%s: %v", resource, err)
+			continue
+		}
+		if gvr != expected {
+			t.Errorf("%s: expected %v, got %v", resource, expected, gvr)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// OutputTemplatesExtension is the preferences extension of the kubeconfig
+	// file holding the output templates, by name.
+	OutputTemplatesExtension = "kubectl.kubernetes.io/output-templates"
+	// OutputTemplatePrefix prefixes the name of an output template in the
+	// value of --output.
+	OutputTemplatePrefix = "template:"
+)
+
+// OutputTemplateFormats are the output formats that can be stored as output
+// templates, the template following the equal sign.
+var OutputTemplateFormats = []string{
+	"custom-columns=", "custom-columns-file=",
+	"go-template=", "go-template-file=", "template=", "templatefile=",
+	"jsonpath=", "jsonpath-file=", "jsonpath-as-json=", "jq=",
+}
+
+// OutputTemplates returns the output templates of the kubeconfig file.
+func OutputTemplates(config *clientcmdapi.Config) (map[string]string, error) {
+	templates := map[string]string{}
+	extension, ok := config.Preferences.Extensions[OutputTemplatesExtension].(*runtime.Unknown)
+	if !ok {
+		return templates, nil
+	}
+	if err := json.Unmarshal(extension.Raw, &templates); err != nil {
+		return nil, fmt.Errorf("invalid %s extension: %v", OutputTemplatesExtension, err)
+	}
+	return templates, nil
+}
+
+// ResolveOutputTemplate replaces --output=template:NAME with the output
+// template of that name stored in the kubeconfig file.
+func ResolveOutputTemplate(cmd *cobra.Command, clientConfig clientcmd.ClientConfig) error {
+	flag := cmd.Flags().Lookup("output")
+	if flag == nil || !strings.HasPrefix(flag.Value.String(), OutputTemplatePrefix) {
+		return nil
+	}
+	name := strings.TrimPrefix(flag.Value.String(), OutputTemplatePrefix)
+	config, err := clientConfig.RawConfig()
+	if err != nil {
+		return err
+	}
+	templates, err := OutputTemplates(&config)
+	if err != nil {
+		return err
+	}
+	template, ok := templates[name]
+	if !ok {
+		return fmt.Errorf("no output template exists with the name: %q", name)
+	}
+	return flag.Value.Set(template)
+}
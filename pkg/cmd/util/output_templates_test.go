@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestResolveOutputTemplate(t *testing.T) {
+	config := clientcmdapi.NewConfig()
+	config.Preferences.Extensions[OutputTemplatesExtension] = &runtime.Unknown{
+		Raw:         []byte(`{"names": "jsonpath={.items[*].metadata.name}"}`),
+		ContentType: runtime.ContentTypeJSON,
+	}
+	clientConfig := clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{})
+
+	tests := []struct {
+		output        string
+		expected      string
+		expectedError string
+	}{
+		{output: "wide", expected: "wide"},
+		{output: "template", expected: "template"},
+		{output: "template:names", expected: "jsonpath={.items[*].metadata.name}"},
+		{output: "template:missing", expected: "template:missing", expectedError: `no output template exists with the name: "missing"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.output, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			cmd.Flags().StringP("output", "o", "", "")
+			cmd.Flags().Set("output", tc.output)
+			err := ResolveOutputTemplate(cmd, clientConfig)
+			if len(tc.expectedError) > 0 {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Errorf("expected error %q, got %v", tc.expectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if actual := cmd.Flags().Lookup("output").Value.String(); actual != tc.expected {
+				t.Errorf("expected --output %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kubectl/pkg/util/term"
+)
+
+// PickFunc asks the user to pick one of the items, and returns its index.
+type PickFunc func(items []string) (int, error)
+
+// NewTerminalPickFunc returns a PickFunc asking the user on the terminal of
+// the streams, or an error when the input is not a terminal.
+func NewTerminalPickFunc(streams genericiooptions.IOStreams) (PickFunc, error) {
+	if !(term.TTY{In: streams.In}).IsTerminalIn() {
+		return nil, errors.New("--pick requires a terminal")
+	}
+	return func(items []string) (int, error) {
+		return Pick(streams.In, streams.ErrOut, items)
+	}, nil
+}
+
+// Pick lists the items and reads the choice of the user from in, which is
+// either the number of an item, or a text the items are filtered by. The
+// filter matches the items containing its characters in the same order, and
+// the item is picked when it is the only one matching.
+func Pick(in io.Reader, out io.Writer, items []string) (int, error) {
+	if len(items) == 0 {
+		return -1, errors.New("no resources found")
+	}
+	scanner := bufio.NewScanner(in)
+	matches := allIndexes(items)
+	for {
+		for i, match := range matches {
+			fmt.Fprintf(out, "%3d) %s\n", i+1, items[match])
+		}
+		fmt.Fprint(out, "Pick a resource by number, or type to filter: ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return -1, errors.New("no resource was picked")
+		}
+		input := strings.TrimSpace(scanner.Text())
+		if len(input) == 0 {
+			return -1, errors.New("no resource was picked")
+		}
+		if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(matches) {
+			return matches[n-1], nil
+		}
+
+		filtered := []int{}
+		for i, item := range items {
+			if fuzzyMatch(item, input) {
+				filtered = append(filtered, i)
+			}
+		}
+		switch len(filtered) {
+		case 0:
+			fmt.Fprintf(out, "No resources match %q.\n", input)
+			matches = allIndexes(items)
+		case 1:
+			return filtered[0], nil
+		default:
+			matches = filtered
+		}
+	}
+}
+
+// PickObject lists the objects of the resource type matching the selector,
+// in the namespace or in all of them, and returns the one picked with pick.
+func PickObject(f Factory, pick PickFunc, resourceType, namespace string, allNamespaces bool, selector string) (*resource.Info, error) {
+	infos, err := f.NewBuilder().
+		Unstructured().
+		NamespaceParam(namespace).DefaultNamespace().AllNamespaces(allNamespaces).
+		LabelSelectorParam(selector).
+		ResourceTypeOrNameArgs(true, resourceType).
+		Flatten().
+		Do().
+		Infos()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if info.Namespaced() {
+			items = append(items, info.Namespace+"/"+info.Name)
+		} else {
+			items = append(items, info.Name)
+		}
+	}
+	i, err := pick(items)
+	if err != nil {
+		return nil, err
+	}
+	return infos[i], nil
+}
+
+// fuzzyMatch returns whether s contains the characters of pattern in the same
+// order, ignoring case.
+func fuzzyMatch(s, pattern string) bool {
+	s, pattern = strings.ToLower(s), strings.ToLower(pattern)
+	for _, c := range pattern {
+		i := strings.IndexRune(s, c)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(string(c)):]
+	}
+	return true
+}
+
+func allIndexes(items []string) []int {
+	indexes := make([]int, len(items))
+	for i := range items {
+		indexes[i] = i
+	}
+	return indexes
+}
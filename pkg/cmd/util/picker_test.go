@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPick(t *testing.T) {
+	items := []string{"default/nginx-1", "default/nginx-2", "kube-system/coredns", "default/redis"}
+	tests := []struct {
+		name        string
+		input       string
+		expected    int
+		expectedErr string
+	}{
+		{
+			name:     "number",
+			input:    "2\n",
+			expected: 1,
+		},
+		{
+			name:     "filter to a single item",
+			input:    "dns\n",
+			expected: 2,
+		},
+		{
+			name:     "fuzzy filter",
+			input:    "dfrd\n",
+			expected: 3,
+		},
+		{
+			name:     "filter then number",
+			input:    "ngx\n2\n",
+			expected: 1,
+		},
+		{
+			name:     "number of the filtered items",
+			input:    "nginx\n1\n",
+			expected: 0,
+		},
+		{
+			name:     "no match",
+			input:    "postgres\nredis\n",
+			expected: 3,
+		},
+		{
+			name:        "out of range number",
+			input:       "5\n",
+			expectedErr: "no resource was picked",
+		},
+		{
+			name:        "empty input",
+			input:       "\n",
+			expectedErr: "no resource was picked",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			i, err := Pick(strings.NewReader(test.input), out, items)
+			if len(test.expectedErr) > 0 {
+				if err == nil || err.Error() != test.expectedErr {
+					t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i != test.expected {
+				t.Errorf("expected %s, got %s\n%s", items[test.expected], items[i], out.String())
+			}
+		})
+	}
+
+	if _, err := Pick(strings.NewReader("1\n"), &bytes.Buffer{}, nil); err == nil || err.Error() != "no resources found" {
+		t.Errorf("expected no resources found, got %v", err)
+	}
+}
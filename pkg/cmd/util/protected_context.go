@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ProtectedContextExtension is the context extension marking a context as
+// protected. Destructive commands against a protected context, such as
+// delete, drain, scale to zero and apply --prune, ask for a confirmation
+// unless --yes is given.
+const ProtectedContextExtension = "kubectl.kubernetes.io/protected"
+
+// IsProtectedContext returns true if the context is marked as protected.
+func IsProtectedContext(context *clientcmdapi.Context) bool {
+	extension, ok := context.Extensions[ProtectedContextExtension].(*runtime.Unknown)
+	if !ok {
+		return false
+	}
+	protected := false
+	if err := json.Unmarshal(extension.Raw, &protected); err != nil {
+		return false
+	}
+	return protected
+}
+
+// SetProtectedContext marks the context as protected, or removes the mark.
+func SetProtectedContext(context *clientcmdapi.Context, protected bool) {
+	if !protected {
+		delete(context.Extensions, ProtectedContextExtension)
+		return
+	}
+	if context.Extensions == nil {
+		context.Extensions = map[string]runtime.Object{}
+	}
+	context.Extensions[ProtectedContextExtension] = &runtime.Unknown{Raw: []byte("true"), ContentType: runtime.ContentTypeJSON}
+}
+
+// AddConfirmationFlag adds the --yes flag to a destructive command.
+func AddConfirmationFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("yes", false, "If true, do not ask for a confirmation when the current context is protected.")
+}
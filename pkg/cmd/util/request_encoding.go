@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	RequestEncodingJSON     = "json"
+	RequestEncodingProtobuf = "protobuf"
+)
+
+// protobufContentTypes prefers protobuf, and lets the server fall back to
+// JSON for the types it can not encode as protobuf, like custom resources.
+var protobufContentTypes = strings.Join([]string{runtime.ContentTypeProtobuf, runtime.ContentTypeJSON}, ",")
+
+func AddRequestEncodingFlag(cmd *cobra.Command, value *string) {
+	cmd.Flags().StringVar(value, "request-encoding", *value,
+		"The encoding to request built-in resources in. One of: protobuf, json. Custom resources and server-side printed tables are always requested as JSON.")
+}
+
+// ValidateRequestEncoding makes sure that the value of --request-encoding is valid.
+func ValidateRequestEncoding(encoding string) error {
+	switch encoding {
+	case RequestEncodingJSON, RequestEncodingProtobuf:
+		return nil
+	default:
+		return fmt.Errorf("invalid --request-encoding %q, must be one of: %s, %s", encoding, RequestEncodingProtobuf, RequestEncodingJSON)
+	}
+}
+
+// AcceptProtobuf requests the response of the request as protobuf.
+func AcceptProtobuf(req *rest.Request) {
+	req.SetHeader("Accept", protobufContentTypes)
+}
+
+// WithRequestEncoding returns a RESTClientGetter whose clients request and send
+// protobuf when the encoding is protobuf. Dynamic clients always use JSON.
+func WithRequestEncoding(getter genericclioptions.RESTClientGetter, encoding string) genericclioptions.RESTClientGetter {
+	if encoding != RequestEncodingProtobuf {
+		return getter
+	}
+	return &protobufClientGetter{RESTClientGetter: getter}
+}
+
+type protobufClientGetter struct {
+	genericclioptions.RESTClientGetter
+}
+
+func (g *protobufClientGetter) ToRESTConfig() (*rest.Config, error) {
+	config, err := g.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	config = rest.CopyConfig(config)
+	config.AcceptContentTypes = protobufContentTypes
+	config.ContentType = runtime.ContentTypeProtobuf
+	return config, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ResourceRecommendation holds the requests and limits recommended for the
+// containers of a workload by kubectl recommend resources, in the format read
+// by kubectl set resources --from-recommendation.
+type ResourceRecommendation struct {
+	// Target is the workload the usage was measured for, such as
+	// deployment.apps/foo.
+	Target    string `json:"target"`
+	Namespace string `json:"namespace,omitempty"`
+	// Source is where the usage was read from, metrics-server or the URL of
+	// a Prometheus server.
+	Source     string                    `json:"source"`
+	Window     string                    `json:"window,omitempty"`
+	Containers []ContainerRecommendation `json:"containers"`
+}
+
+// ContainerRecommendation holds the usage measured for a container and the
+// resources recommended for it.
+type ContainerRecommendation struct {
+	Name      string                  `json:"name"`
+	Usage     v1.ResourceList         `json:"usage,omitempty"`
+	Resources v1.ResourceRequirements `json:"resources"`
+}
+
+// ParseResourceRecommendation parses a recommendation written as JSON or YAML.
+func ParseResourceRecommendation(data []byte) (*ResourceRecommendation, error) {
+	recommendation := &ResourceRecommendation{}
+	if err := yaml.UnmarshalStrict(data, recommendation); err != nil {
+		return nil, fmt.Errorf("invalid resource recommendation: %v", err)
+	}
+	if len(recommendation.Containers) == 0 {
+		return nil, fmt.Errorf("invalid resource recommendation: no containers")
+	}
+	return recommendation, nil
+}
+
+// Container returns the recommendation for the container named name.
+func (r *ResourceRecommendation) Container(name string) (ContainerRecommendation, bool) {
+	for _, c := range r.Containers {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ContainerRecommendation{}, false
+}
+
+// ApplyTo sets the recommended requests and limits on resources, keeping the
+// other resources as they are. A limit which is not recommended is raised to
+// the recommended request when it is lower, so that the result stays valid.
+func (c ContainerRecommendation) ApplyTo(resources *v1.ResourceRequirements) {
+	for name, quantity := range c.Resources.Requests {
+		if resources.Requests == nil {
+			resources.Requests = v1.ResourceList{}
+		}
+		resources.Requests[name] = quantity
+		if limit, ok := resources.Limits[name]; ok && limit.Cmp(quantity) < 0 {
+			if _, recommended := c.Resources.Limits[name]; !recommended {
+				resources.Limits[name] = quantity
+			}
+		}
+	}
+	for name, quantity := range c.Resources.Limits {
+		if resources.Limits == nil {
+			resources.Limits = v1.ResourceList{}
+		}
+		resources.Limits[name] = quantity
+	}
+}
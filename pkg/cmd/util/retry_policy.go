@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// RetryPolicyExtension is the preferences extension of the kubeconfig file
+// holding the defaults of --retries, --retry-backoff and --retry-on.
+const RetryPolicyExtension = "kubectl.kubernetes.io/retry-policy"
+
+// RetryPolicy is how the requests failing with a transient error are
+// retried. The fields which are not set keep the defaults of the flags.
+type RetryPolicy struct {
+	Retries *int             `json:"retries,omitempty"`
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+	// RetryOn are the HTTP status codes and the errors, such as conn-reset,
+	// the requests are retried on.
+	RetryOn []intstr.IntOrString `json:"retryOn,omitempty"`
+}
+
+// LoadRetryPolicy returns the retry policy of the kubeconfig file.
+func LoadRetryPolicy(config *clientcmdapi.Config) (RetryPolicy, error) {
+	policy := RetryPolicy{}
+	extension, ok := config.Preferences.Extensions[RetryPolicyExtension].(*runtime.Unknown)
+	if !ok {
+		return policy, nil
+	}
+	if err := json.Unmarshal(extension.Raw, &policy); err != nil {
+		return policy, fmt.Errorf("invalid %s extension: %v", RetryPolicyExtension, err)
+	}
+	return policy, nil
+}
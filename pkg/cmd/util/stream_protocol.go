@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// StreamProtocolAuto streams over WebSockets, falling back to SPDY when
+	// the server, or a proxy in between, does not upgrade the connection to
+	// WebSockets.
+	StreamProtocolAuto = "auto"
+	// StreamProtocolWebSocket only streams over WebSockets.
+	StreamProtocolWebSocket = "websocket"
+	// StreamProtocolSPDY only streams over SPDY.
+	StreamProtocolSPDY = "spdy"
+)
+
+var streamProtocol = StreamProtocolAuto
+
+// SetStreamProtocol sets the protocol of the streams to containers.
+func SetStreamProtocol(protocol string) error {
+	switch protocol {
+	case StreamProtocolAuto, StreamProtocolWebSocket, StreamProtocolSPDY:
+		streamProtocol = protocol
+		return nil
+	default:
+		return fmt.Errorf("invalid stream protocol %q, must be one of: %s, %s, %s", protocol, StreamProtocolAuto, StreamProtocolWebSocket, StreamProtocolSPDY)
+	}
+}
+
+// StreamProtocol returns the protocol of the streams to containers. auto is
+// SPDY when the RemoteCommandWebsockets feature gate is disabled.
+func StreamProtocol() string {
+	if streamProtocol == StreamProtocolAuto && RemoteCommandWebsockets.IsDisabled() {
+		return StreamProtocolSPDY
+	}
+	return streamProtocol
+}
+
+// AddStreamProtocolFlags adds the --stream-protocol flag to the flags. The
+// flag does not apply to port-forward, which stays on SPDY until client-go
+// provides a port forwarding dialer tunneling over WebSockets.
+func AddStreamProtocolFlags(flags *pflag.FlagSet) {
+	flags.Var(streamProtocolValue{}, "stream-protocol", "The protocol of the streams to containers of exec, attach, cp, run and debug. One of: auto, websocket, spdy. auto uses WebSockets, falling back to SPDY when the server or a proxy does not support them. port-forward always uses SPDY and rejects websocket, as the client library does not support WebSocket port forwarding yet.")
+}
+
+type streamProtocolValue struct{}
+
+func (streamProtocolValue) String() string     { return streamProtocol }
+func (streamProtocolValue) Set(s string) error { return SetStreamProtocol(s) }
+func (streamProtocolValue) Type() string       { return "string" }
+
+// NewRemoteCommandExecutor returns an executor streaming to the exec or
+// attach url of a container with the --stream-protocol.
+func NewRemoteCommandExecutor(config *rest.Config, url *url.URL) (remotecommand.Executor, error) {
+	protocol := StreamProtocol()
+	if protocol == StreamProtocolSPDY {
+		return remotecommand.NewSPDYExecutor(config, "POST", url)
+	}
+	// WebSocketExecutor must be "GET" method as described in RFC 6455 Sec. 4.1 (page 17).
+	websocketExec, err := remotecommand.NewWebSocketExecutor(config, "GET", url.String())
+	if err != nil || protocol == StreamProtocolWebSocket {
+		return websocketExec, err
+	}
+	spdyExec, err := remotecommand.NewSPDYExecutor(config, "POST", url)
+	if err != nil {
+		return nil, err
+	}
+	return remotecommand.NewFallbackExecutor(websocketExec, spdyExec, httpstream.IsUpgradeFailure)
+}
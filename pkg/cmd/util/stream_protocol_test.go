@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestNewRemoteCommandExecutor(t *testing.T) {
+	tests := []struct {
+		protocol     string
+		disableGate  bool
+		expectedType string
+	}{
+		{protocol: StreamProtocolAuto, expectedType: "*remotecommand.fallbackExecutor"},
+		{protocol: StreamProtocolAuto, disableGate: true, expectedType: "*remotecommand.spdyStreamExecutor"},
+		{protocol: StreamProtocolWebSocket, expectedType: "*remotecommand.wsStreamExecutor"},
+		{protocol: StreamProtocolWebSocket, disableGate: true, expectedType: "*remotecommand.wsStreamExecutor"},
+		{protocol: StreamProtocolSPDY, expectedType: "*remotecommand.spdyStreamExecutor"},
+	}
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%s, gate disabled %t", tc.protocol, tc.disableGate), func(t *testing.T) {
+			if err := SetStreamProtocol(tc.protocol); err != nil {
+				t.Fatal(err)
+			}
+			defer SetStreamProtocol(StreamProtocolAuto)
+			if tc.disableGate {
+				t.Setenv(string(RemoteCommandWebsockets), "false")
+			}
+
+			u, _ := url.Parse("https://example.com/api/v1/namespaces/default/pods/foo/exec")
+			exec, err := NewRemoteCommandExecutor(&rest.Config{Host: "https://example.com"}, u)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := fmt.Sprintf("%T", exec); actual != tc.expectedType {
+				t.Errorf("expected a %s, got a %s", tc.expectedType, actual)
+			}
+		})
+	}
+}
+
+func TestSetStreamProtocol(t *testing.T) {
+	err := SetStreamProtocol("http2")
+	expected := `invalid stream protocol "http2", must be one of: auto, websocket, spdy`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+	if streamProtocol != StreamProtocolAuto {
+		t.Errorf("expected the protocol to be unchanged, got %s", streamProtocol)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	jsonpatch "github.com/evanphx/json-patch"
+
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// Transaction applies a change to many objects as a whole: all the objects
+// are checked before any of them is changed, and the objects already changed
+// are rolled back when changing one of them fails.
+type Transaction struct {
+	changes []transactionChange
+}
+
+type transactionChange struct {
+	name     string
+	helper   *resource.Helper
+	info     *resource.Info
+	rollback []byte
+}
+
+// Record records that the object of info was changed by helper, from the
+// original to the changed JSON encoded object.
+func (t *Transaction) Record(helper *resource.Helper, info *resource.Info, original, changed []byte) error {
+	original, err := withoutServerFields(original)
+	if err != nil {
+		return err
+	}
+	changed, err = withoutServerFields(changed)
+	if err != nil {
+		return err
+	}
+	rollback, err := jsonpatch.CreateMergePatch(changed, original)
+	if err != nil {
+		return err
+	}
+	if string(rollback) == "{}" {
+		return nil
+	}
+	t.changes = append(t.changes, transactionChange{name: infoName(info), helper: helper, info: info, rollback: rollback})
+	return nil
+}
+
+// Run checks all the infos of r with check, then changes them with change.
+// When a change fails, the changes recorded so far are rolled back, the most
+// recent first. A summary of the transaction is written to out.
+func (t *Transaction) Run(r *resource.Result, check func(*resource.Info) error, change resource.VisitorFunc, out io.Writer) error {
+	infos, err := r.Infos()
+	if err != nil {
+		return fmt.Errorf("no object was changed: %v", err)
+	}
+	for _, info := range infos {
+		if err := check(info); err != nil {
+			return fmt.Errorf("no object was changed, %s: %v", infoName(info), err)
+		}
+	}
+
+	for _, info := range infos {
+		if err := change(info, nil); err != nil {
+			changed := len(t.changes)
+			rollbackErrs := t.rollback()
+			fmt.Fprintf(out, "Transaction rolled back: %d of %d changed objects restored.\n", changed-len(rollbackErrs), changed)
+			return utilerrors.NewAggregate(append([]error{fmt.Errorf("%s: %v", infoName(info), err)}, rollbackErrs...))
+		}
+	}
+	fmt.Fprintf(out, "Transaction committed: %d of %d objects changed.\n", len(t.changes), len(infos))
+	return nil
+}
+
+func (t *Transaction) rollback() []error {
+	errs := []error{}
+	for i := len(t.changes) - 1; i >= 0; i-- {
+		c := t.changes[i]
+		if _, err := c.helper.Patch(c.info.Namespace, c.info.Name, types.MergePatchType, c.rollback, nil); err != nil {
+			errs = append(errs, fmt.Errorf("failed to roll back %s: %v", c.name, err))
+		}
+	}
+	t.changes = nil
+	return errs
+}
+
+// withoutServerFields removes the fields set by the server on every change
+// from a JSON encoded object, so that they are not part of the rollback.
+func withoutServerFields(data []byte) ([]byte, error) {
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	delete(obj, "status")
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		for _, field := range []string{"resourceVersion", "generation", "managedFields"} {
+			delete(metadata, field)
+		}
+	}
+	return json.Marshal(obj)
+}
+
+func infoName(info *resource.Info) string {
+	if info.Mapping == nil {
+		return info.Name
+	}
+	return info.Mapping.Resource.Resource + "/" + info.Name
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/spf13/cobra"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apiresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
@@ -61,6 +62,10 @@ var (
 		Alternatively, the command can wait for the given set of resources to be deleted
 		by providing the "delete" keyword as the value to the --for flag.
 
+		Persistent volume claims can be waited on with --for=bound, until they are bound
+		to a volume, and --for=resized, until the capacity of the volume reaches the
+		requested storage and no resize is in progress.
+
 		A successful message will be printed to stdout indicating when the specified
         condition has been met. You can use -o option to change to output destination.`))
 
@@ -80,6 +85,12 @@ var (
 		# Wait for the service "loadbalancer" to have ingress.
 		kubectl wait --for=jsonpath='{.status.loadBalancer.ingress}' service/loadbalancer
 
+		# Wait for the persistent volume claim "data" to be bound to a volume
+		kubectl wait --for=bound pvc/data
+
+		# Wait for the expansion of the persistent volume claim "data" to complete
+		kubectl wait --for=resized pvc/data --timeout=10m
+
 		# Wait for the pod "busybox1" to be deleted, with a timeout of 60s, after having issued the "delete" command
 		kubectl delete pod/busybox1
 		kubectl wait --for=delete pod/busybox1 --timeout=60s`))
@@ -151,7 +162,7 @@ func (flags *WaitFlags) AddFlags(cmd *cobra.Command) {
 	flags.ResourceBuilderFlags.AddFlags(cmd.Flags())
 
 	cmd.Flags().DurationVar(&flags.Timeout, "timeout", flags.Timeout, "The length of time to wait before giving up.  Zero means check once and don't wait, negative means wait for a week.")
-	cmd.Flags().StringVar(&flags.ForCondition, "for", flags.ForCondition, "The condition to wait on: [delete|condition=condition-name[=condition-value]|jsonpath='{JSONPath expression}'=[JSONPath value]]. The default condition-value is true.  Condition values are compared after Unicode simple case folding, which is a more general form of case-insensitivity.")
+	cmd.Flags().StringVar(&flags.ForCondition, "for", flags.ForCondition, "The condition to wait on: [delete|bound|resized|condition=condition-name[=condition-value]|jsonpath='{JSONPath expression}'=[JSONPath value]]. The default condition-value is true.  Condition values are compared after Unicode simple case folding, which is a more general form of case-insensitivity.")
 }
 
 // ToOptions converts from CLI inputs to runtime inputs
@@ -197,6 +208,12 @@ func conditionFuncFor(condition string, errOut io.Writer) (ConditionFunc, error)
 	if strings.ToLower(condition) == "delete" {
 		return IsDeleted, nil
 	}
+	if lowered := strings.ToLower(condition); lowered == pvcBound || lowered == pvcResized {
+		return PersistentVolumeClaimWait{
+			condition: lowered,
+			errOut:    errOut,
+		}.IsPersistentVolumeClaimConditionMet, nil
+	}
 	if strings.HasPrefix(condition, "condition=") {
 		conditionName := condition[len("condition="):]
 		conditionValue := "true"
@@ -705,3 +722,87 @@ func compareResults(r reflect.Value, expectedVal string) (bool, error) {
 	s := fmt.Sprintf("%v", r.Interface())
 	return strings.TrimSpace(s) == strings.TrimSpace(expectedVal), nil
 }
+
+const (
+	pvcBound   = "bound"
+	pvcResized = "resized"
+)
+
+// PersistentVolumeClaimWait checks the binding or the resize of a persistent
+// volume claim.
+type PersistentVolumeClaimWait struct {
+	condition string
+	// errOut is written to if an error occurs
+	errOut io.Writer
+}
+
+// IsPersistentVolumeClaimConditionMet fulfills the requirements of the interface ConditionFunc which provides condition check
+func (w PersistentVolumeClaimWait) IsPersistentVolumeClaimConditionMet(ctx context.Context, info *resource.Info, o *WaitOptions) (runtime.Object, bool, error) {
+	if info.Mapping != nil && info.Mapping.Resource.GroupResource() != (schema.GroupResource{Resource: "persistentvolumeclaims"}) {
+		return info.Object, false, fmt.Errorf("--for=%s can only be used with persistentvolumeclaims, got %s", w.condition, info.Mapping.Resource.Resource)
+	}
+	return getObjAndCheckCondition(ctx, info, o, w.isConditionMet, w.checkCondition)
+}
+
+func (w PersistentVolumeClaimWait) isConditionMet(event watch.Event) (bool, error) {
+	if event.Type == watch.Error {
+		// keep waiting in the event we see an error - we expect the watch to be closed by
+		// the server
+		err := apierrors.FromObject(event.Object)
+		fmt.Fprintf(w.errOut, "error: An error occurred while waiting for the condition to be satisfied: %v", err)
+		return false, nil
+	}
+	if event.Type == watch.Deleted {
+		// this will chain back out, result in another get and an return false back up the chain
+		return false, nil
+	}
+	obj := event.Object.(*unstructured.Unstructured)
+	return w.checkCondition(obj)
+}
+
+// checkCondition reports whether the claim is bound or, for resized, whether
+// the capacity of the bound volume covers the requested storage and no
+// Resizing or FileSystemResizePending condition is left.
+func (w PersistentVolumeClaimWait) checkCondition(obj *unstructured.Unstructured) (bool, error) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	if phase != "Bound" {
+		return false, nil
+	}
+	if w.condition == pvcBound {
+		return true, nil
+	}
+
+	requested, found, _ := unstructured.NestedString(obj.Object, "spec", "resources", "requests", "storage")
+	if !found {
+		return true, nil
+	}
+	request, err := apiresource.ParseQuantity(requested)
+	if err != nil {
+		return false, err
+	}
+	capacity, found, _ := unstructured.NestedString(obj.Object, "status", "capacity", "storage")
+	if !found {
+		return false, nil
+	}
+	current, err := apiresource.ParseQuantity(capacity)
+	if err != nil {
+		return false, err
+	}
+	if current.Cmp(request) < 0 {
+		return false, nil
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, conditionUncast := range conditions {
+		condition, ok := conditionUncast.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		if (conditionType == "Resizing" || conditionType == "FileSystemResizePending") && strings.EqualFold(status, "true") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
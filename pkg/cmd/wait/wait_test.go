@@ -1622,6 +1622,16 @@ func TestConditionFuncFor(t *testing.T) {
 			condition:   "condition=hello=world",
 			expectedErr: None,
 		},
+		{
+			name:        "persistent volume claim bound",
+			condition:   "bound",
+			expectedErr: None,
+		},
+		{
+			name:        "persistent volume claim resized",
+			condition:   "Resized",
+			expectedErr: None,
+		},
 		{
 			name:        "unrecognized condition",
 			condition:   "cond=invalid",
@@ -1644,3 +1654,102 @@ func TestConditionFuncFor(t *testing.T) {
 		})
 	}
 }
+
+func TestWaitForPersistentVolumeClaim(t *testing.T) {
+	scheme := runtime.NewScheme()
+	pvcGVR := schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+	newClaim := func(phase, requested, capacity string, conditions ...string) *unstructured.Unstructured {
+		claim := newUnstructured("v1", "PersistentVolumeClaim", "ns-foo", "data")
+		unstructured.SetNestedField(claim.Object, requested, "spec", "resources", "requests", "storage")
+		unstructured.SetNestedField(claim.Object, phase, "status", "phase")
+		if len(capacity) > 0 {
+			unstructured.SetNestedField(claim.Object, capacity, "status", "capacity", "storage")
+		}
+		for _, condition := range conditions {
+			claim = addCondition(claim, condition, "True")
+		}
+		return claim
+	}
+
+	tests := []struct {
+		name      string
+		condition string
+		resource  schema.GroupVersionResource
+		claim     *unstructured.Unstructured
+
+		expectedErr string
+	}{
+		{
+			name:      "bound",
+			condition: pvcBound,
+			claim:     newClaim("Bound", "1Gi", "1Gi"),
+		},
+		{
+			name:        "pending",
+			condition:   pvcBound,
+			claim:       newClaim("Pending", "1Gi", ""),
+			expectedErr: "condition not met",
+		},
+		{
+			name:      "resized",
+			condition: pvcResized,
+			claim:     newClaim("Bound", "2Gi", "2048Mi"),
+		},
+		{
+			name:        "capacity below the request",
+			condition:   pvcResized,
+			claim:       newClaim("Bound", "2Gi", "1Gi", "Resizing"),
+			expectedErr: "condition not met",
+		},
+		{
+			name:        "file system resize pending",
+			condition:   pvcResized,
+			claim:       newClaim("Bound", "2Gi", "2Gi", "FileSystemResizePending"),
+			expectedErr: "condition not met",
+		},
+		{
+			name:        "not a persistent volume claim",
+			condition:   pvcBound,
+			resource:    schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+			claim:       newClaim("Bound", "1Gi", "1Gi"),
+			expectedErr: "--for=bound can only be used with persistentvolumeclaims, got pods",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gvr := pvcGVR
+			if !test.resource.Empty() {
+				gvr = test.resource
+			}
+			fakeClient := dynamicfakeclient.NewSimpleDynamicClient(scheme)
+			fakeClient.PrependReactor("get", gvr.Resource, func(action clienttesting.Action) (handled bool, ret runtime.Object, err error) {
+				return true, test.claim, nil
+			})
+			o := &WaitOptions{
+				ResourceFinder: genericclioptions.NewSimpleFakeResourceFinder(&resource.Info{
+					Mapping:   &meta.RESTMapping{Resource: gvr},
+					Name:      "data",
+					Namespace: "ns-foo",
+				}),
+				DynamicClient: fakeClient,
+
+				Printer:     printers.NewDiscardingPrinter(),
+				ConditionFn: PersistentVolumeClaimWait{condition: test.condition, errOut: io.Discard}.IsPersistentVolumeClaimConditionMet,
+				IOStreams:   genericiooptions.NewTestIOStreamsDiscard(),
+			}
+			err := o.RunWait()
+			switch {
+			case err == nil && len(test.expectedErr) == 0:
+			case err != nil && len(test.expectedErr) == 0:
+				t.Fatal(err)
+			case err == nil && len(test.expectedErr) != 0:
+				t.Fatalf("missing: %q", test.expectedErr)
+			case err != nil && len(test.expectedErr) != 0:
+				if !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected %q, got %q", test.expectedErr, err.Error())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/kubectl/pkg/util/timeformat"
+)
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// CustomResourceDescriber generates information about a custom resource from
+// the schema and the printer columns of its custom resource definition: the
+// columns are printed first, the conditions of the status as a table, and
+// the items of lists with keys are labeled with their keys. Custom resources
+// whose definition can not be read are described generically.
+type CustomResourceDescriber struct {
+	mapping *meta.RESTMapping
+	dynamic dynamic.Interface
+	events  corev1client.EventsGetter
+}
+
+func (d *CustomResourceDescriber) Describe(namespace, name string, describerSettings DescriberSettings) (string, error) {
+	obj, err := d.dynamic.Resource(d.mapping.Resource).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	var events *corev1.EventList
+	if describerSettings.ShowEvents {
+		events, _ = searchEvents(d.events, obj, describerSettings)
+	}
+
+	// the definition is not readable by everyone, nor are all the resources
+	// not built in custom resources
+	crdName := d.mapping.Resource.Resource + "." + d.mapping.Resource.Group
+	crd, err := d.dynamic.Resource(crdResource).Get(context.TODO(), crdName, metav1.GetOptions{})
+	if err != nil {
+		return describeUnstructured(obj, events)
+	}
+	return describeCustomResource(obj, crdVersion(crd, d.mapping.Resource.Version), events)
+}
+
+// crdVersion returns the version of the custom resource definition, or nil.
+func crdVersion(crd *unstructured.Unstructured, version string) map[string]interface{} {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		if v, ok := v.(map[string]interface{}); ok && v["name"] == version {
+			return v
+		}
+	}
+	return nil
+}
+
+func describeCustomResource(obj *unstructured.Unstructured, version map[string]interface{}, events *corev1.EventList) (string, error) {
+	schema, _, _ := unstructured.NestedMap(version, "schema", "openAPIV3Schema")
+	columns, _, _ := unstructured.NestedSlice(version, "additionalPrinterColumns")
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+
+	return tabbedString(func(out io.Writer) error {
+		w := NewPrefixWriter(out)
+		w.Write(LEVEL_0, "Name:\t%s\n", obj.GetName())
+		w.Write(LEVEL_0, "Namespace:\t%s\n", obj.GetNamespace())
+		printLabelsMultiline(w, "Labels", obj.GetLabels())
+		printAnnotationsMultiline(w, "Annotations", obj.GetAnnotations())
+		w.Write(LEVEL_0, "API Version:\t%s\n", obj.GetAPIVersion())
+		w.Write(LEVEL_0, "Kind:\t%s\n", obj.GetKind())
+		for _, column := range columns {
+			column, ok := column.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(column, "name")
+			path, _, _ := unstructured.NestedString(column, "jsonPath")
+			columnType, _, _ := unstructured.NestedString(column, "type")
+			if len(name) == 0 || path == ".metadata.creationTimestamp" {
+				// the age is the creation timestamp of the metadata
+				continue
+			}
+			w.Write(LEVEL_0, "%s:\t%s\n", name, printerColumnValue(obj, path, columnType))
+		}
+		printUnstructuredContent(w, LEVEL_0, map[string]interface{}{"metadata": obj.Object["metadata"]}, "", ".metadata.managedFields", ".metadata.name",
+			".metadata.namespace", ".metadata.labels", ".metadata.annotations")
+
+		fields := []string{}
+		for field := range obj.Object {
+			if field != "apiVersion" && field != "kind" && field != "metadata" {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			if field == "status" && len(conditions) > 0 {
+				status, _, _ := unstructured.NestedMap(obj.Object, "status")
+				delete(status, "conditions")
+				if len(status) == 0 {
+					continue
+				}
+				printSchemaValue(w, LEVEL_0, smartLabelFor(field), status, schemaProperty(schema, field))
+				continue
+			}
+			printSchemaValue(w, LEVEL_0, smartLabelFor(field), obj.Object[field], schemaProperty(schema, field))
+		}
+
+		if len(conditions) > 0 {
+			w.Write(LEVEL_0, "Conditions:\n")
+			w.Write(LEVEL_1, "Type\tStatus\tLastTransitionTime\tReason\tMessage\n")
+			w.Write(LEVEL_1, "----\t------\t------------------\t------\t-------\n")
+			for _, c := range conditions {
+				c, ok := c.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				field := func(name string) string {
+					if value, ok := c[name]; ok && value != nil {
+						return fmt.Sprint(value)
+					}
+					return ""
+				}
+				lastTransitionTime := field("lastTransitionTime")
+				if t, err := time.Parse(time.RFC3339, lastTransitionTime); err == nil {
+					lastTransitionTime = t.Format(time.RFC1123Z)
+				}
+				w.Write(LEVEL_1, "%s\t%s\t%s\t%s\t%s\n", field("type"), field("status"), lastTransitionTime, field("reason"), field("message"))
+			}
+		}
+
+		if events != nil {
+			DescribeEvents(events, w)
+		}
+		return nil
+	})
+}
+
+// printerColumnValue returns the value of a printer column of the object.
+func printerColumnValue(obj *unstructured.Unstructured, path, columnType string) string {
+	parser := jsonpath.New("column").AllowMissingKeys(true)
+	if err := parser.Parse(fmt.Sprintf("{%s}", path)); err != nil {
+		return "<invalid>"
+	}
+	results, err := parser.FindResults(obj.Object)
+	if err != nil || len(results) == 0 || len(results[0]) == 0 {
+		return "<none>"
+	}
+	values := []string{}
+	for _, result := range results[0] {
+		value := fmt.Sprint(result.Interface())
+		if t, err := time.Parse(time.RFC3339, value); err == nil && columnType == "date" {
+			value = timeformat.Timestamp(t)
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, ",")
+}
+
+// schemaProperty returns the schema of the property of an object, or nil.
+func schemaProperty(schema map[string]interface{}, name string) map[string]interface{} {
+	if property, ok, _ := unstructured.NestedMap(schema, "properties", name); ok {
+		return property
+	}
+	if additional, ok, _ := unstructured.NestedMap(schema, "additionalProperties"); ok {
+		return additional
+	}
+	return nil
+}
+
+// printSchemaValue prints the value of a field with its schema. The keys of
+// the maps of the schema are printed as is, and the items of the lists of
+// objects are labeled with the values of their list map keys, or with their
+// index.
+func printSchemaValue(w PrefixWriter, level int, label string, value interface{}, schema map[string]interface{}) {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		w.Write(level, "%s:\n", label)
+		_, isMap := schema["additionalProperties"]
+		keys := []string{}
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childLabel := smartLabelFor(key)
+			if isMap {
+				childLabel = key
+			}
+			printSchemaValue(w, level+1, childLabel, typedValue[key], schemaProperty(schema, key))
+		}
+	case []interface{}:
+		w.Write(level, "%s:\n", label)
+		items, _, _ := unstructured.NestedMap(schema, "items")
+		keys, _, _ := unstructured.NestedStringSlice(schema, "x-kubernetes-list-map-keys")
+		for i, item := range typedValue {
+			if object, ok := item.(map[string]interface{}); ok {
+				printSchemaValue(w, level+1, listItemLabel(object, keys, i), object, items)
+			} else {
+				w.Write(level+1, "%v\n", item)
+			}
+		}
+	default:
+		w.Write(level, "%s:\t%v\n", label, typedValue)
+	}
+}
+
+// listItemLabel labels an item of a list with the values of its keys, such
+// as name=foo, or with its index.
+func listItemLabel(item map[string]interface{}, keys []string, index int) string {
+	values := []string{}
+	for _, key := range keys {
+		if value, ok := item[key]; ok {
+			values = append(values, fmt.Sprintf("%s=%v", key, value))
+		}
+	}
+	if len(values) == 0 {
+		return fmt.Sprintf("[%d]", index)
+	}
+	return "[" + strings.Join(values, ",") + "]"
+}
+
+func customResourceDescriberFor(mapping *meta.RESTMapping, clientConfig *rest.Config) (ResourceDescriber, bool) {
+	dynamicClient, err := dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, false
+	}
+	clientSet, err := clientset.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, false
+	}
+	return &CustomResourceDescriber{mapping, dynamicClient, clientSet.CoreV1()}, true
+}
@@ -56,6 +56,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -81,9 +82,11 @@ import (
 	"k8s.io/kubectl/pkg/util/fieldpath"
 	"k8s.io/kubectl/pkg/util/qos"
 	"k8s.io/kubectl/pkg/util/rbac"
+	"k8s.io/kubectl/pkg/util/relations"
 	resourcehelper "k8s.io/kubectl/pkg/util/resource"
 	"k8s.io/kubectl/pkg/util/slice"
 	storageutil "k8s.io/kubectl/pkg/util/storage"
+	"k8s.io/kubectl/pkg/util/timeformat"
 )
 
 // Each level has 2 spaces for PrefixWriter
@@ -113,6 +116,15 @@ func Describer(restClientGetter genericclioptions.RESTClientGetter, mapping *met
 	if describer, ok := DescriberFor(mapping.GroupVersionKind.GroupKind(), clientConfig); ok {
 		return describer, nil
 	}
+	if mapping.GroupVersionKind.GroupKind() == volumeSnapshotGroupKind {
+		if describer, ok := volumeSnapshotDescriberFor(mapping, clientConfig); ok {
+			return describer, nil
+		}
+	}
+	// describe the custom resources with the schema of their definition
+	if describer, ok := customResourceDescriberFor(mapping, clientConfig); ok {
+		return describer, nil
+	}
 	// if this is a kind we don't have a describer for yet, go generic if possible
 	if genericDescriber, ok := GenericDescriberFor(mapping, clientConfig); ok {
 		return genericDescriber, nil
@@ -286,9 +298,13 @@ func (g *genericDescriber) Describe(namespace, name string, describerSettings De
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(g.events, obj, describerSettings.ChunkSize)
+		events, _ = searchEvents(g.events, obj, describerSettings)
 	}
 
+	return describeUnstructured(obj, events)
+}
+
+func describeUnstructured(obj *unstructured.Unstructured, events *corev1.EventList) (string, error) {
 	return tabbedString(func(out io.Writer) error {
 		w := NewPrefixWriter(out)
 		w.Write(LEVEL_0, "Name:\t%s\n", obj.GetName())
@@ -774,7 +790,7 @@ func (d *PodDescriber) Describe(namespace, name string, describerSettings Descri
 			if _, isMirrorPod := pod.Annotations[corev1.MirrorPodAnnotationKey]; isMirrorPod {
 				ref.UID = types.UID(pod.Annotations[corev1.MirrorPodAnnotationKey])
 			}
-			events, _ = searchEvents(d.CoreV1(), ref, describerSettings.ChunkSize)
+			events, _ = searchEvents(d.CoreV1(), ref, describerSettings)
 		}
 	}
 
@@ -1493,7 +1509,7 @@ func (d *PersistentVolumeDescriber) Describe(namespace, name string, describerSe
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.CoreV1(), pv, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.CoreV1(), pv, describerSettings)
 	}
 
 	return describePersistentVolume(pv, events)
@@ -1648,7 +1664,7 @@ func (d *PersistentVolumeClaimDescriber) Describe(namespace, name string, descri
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.CoreV1(), pvc, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.CoreV1(), pvc, describerSettings)
 	}
 
 	return describePersistentVolumeClaim(pvc, events, pods)
@@ -1662,9 +1678,11 @@ func getPodsForPVC(c corev1client.PodInterface, pvc *corev1.PersistentVolumeClai
 
 	var pods []corev1.Pod
 
+	claim := relations.Object{GroupKind: schema.GroupKind{Kind: "PersistentVolumeClaim"}, Namespace: pvc.Namespace, Name: pvc.Name}
 	for _, pod := range nsPods.Items {
-		for _, volume := range pod.Spec.Volumes {
-			if volume.VolumeSource.PersistentVolumeClaim != nil && volume.VolumeSource.PersistentVolumeClaim.ClaimName == pvc.Name {
+		self := relations.Object{GroupKind: schema.GroupKind{Kind: "Pod"}, Namespace: pod.Namespace, Name: pod.Name}
+		for _, relation := range relations.Mounted(self, &pod.Spec) {
+			if relation.To == claim {
 				pods = append(pods, pod)
 			}
 		}
@@ -2159,7 +2177,7 @@ func (d *ReplicationControllerDescriber) Describe(namespace, name string, descri
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.CoreV1(), controller, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.CoreV1(), controller, describerSettings)
 	}
 
 	return describeReplicationController(controller, events, running, waiting, succeeded, failed)
@@ -2237,7 +2255,7 @@ func (d *ReplicaSetDescriber) Describe(namespace, name string, describerSettings
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.CoreV1(), rs, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.CoreV1(), rs, describerSettings)
 	}
 
 	return describeReplicaSet(rs, events, running, waiting, succeeded, failed, getPodErr)
@@ -2289,7 +2307,7 @@ func (d *JobDescriber) Describe(namespace, name string, describerSettings Descri
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.CoreV1(), job, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.CoreV1(), job, describerSettings)
 	}
 
 	return describeJob(job, events)
@@ -2379,7 +2397,7 @@ func (d *CronJobDescriber) Describe(namespace, name string, describerSettings De
 	}
 
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.client.CoreV1(), cronJob, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.client.CoreV1(), cronJob, describerSettings)
 	}
 	return describeCronJob(cronJob, events)
 }
@@ -2490,7 +2508,7 @@ func (d *DaemonSetDescriber) Describe(namespace, name string, describerSettings
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.CoreV1(), daemon, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.CoreV1(), daemon, describerSettings)
 	}
 
 	return describeDaemonSet(daemon, events, running, waiting, succeeded, failed)
@@ -2574,14 +2592,14 @@ func (i *IngressDescriber) Describe(namespace, name string, describerSettings De
 	netV1, err := i.client.NetworkingV1().Ingresses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if err == nil {
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(i.client.CoreV1(), netV1, describerSettings.ChunkSize)
+			events, _ = searchEvents(i.client.CoreV1(), netV1, describerSettings)
 		}
 		return i.describeIngressV1(netV1, events)
 	}
 	netV1beta1, err := i.client.NetworkingV1beta1().Ingresses(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if err == nil {
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(i.client.CoreV1(), netV1beta1, describerSettings.ChunkSize)
+			events, _ = searchEvents(i.client.CoreV1(), netV1beta1, describerSettings)
 		}
 		return i.describeIngressV1beta1(netV1beta1, events)
 	}
@@ -2785,14 +2803,14 @@ func (i *IngressClassDescriber) Describe(namespace, name string, describerSettin
 	netV1, err := i.client.NetworkingV1().IngressClasses().Get(context.TODO(), name, metav1.GetOptions{})
 	if err == nil {
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(i.client.CoreV1(), netV1, describerSettings.ChunkSize)
+			events, _ = searchEvents(i.client.CoreV1(), netV1, describerSettings)
 		}
 		return i.describeIngressClassV1(netV1, events)
 	}
 	netV1beta1, err := i.client.NetworkingV1beta1().IngressClasses().Get(context.TODO(), name, metav1.GetOptions{})
 	if err == nil {
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(i.client.CoreV1(), netV1beta1, describerSettings.ChunkSize)
+			events, _ = searchEvents(i.client.CoreV1(), netV1beta1, describerSettings)
 		}
 		return i.describeIngressClassV1beta1(netV1beta1, events)
 	}
@@ -2856,7 +2874,7 @@ func (c *ServiceCIDRDescriber) Describe(namespace, name string, describerSetting
 	svcV1alpha1, err := c.client.NetworkingV1alpha1().ServiceCIDRs().Get(context.TODO(), name, metav1.GetOptions{})
 	if err == nil {
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(c.client.CoreV1(), svcV1alpha1, describerSettings.ChunkSize)
+			events, _ = searchEvents(c.client.CoreV1(), svcV1alpha1, describerSettings)
 		}
 		return c.describeServiceCIDRV1alpha1(svcV1alpha1, events)
 	}
@@ -2905,7 +2923,7 @@ func (c *IPAddressDescriber) Describe(namespace, name string, describerSettings
 	ipV1alpha1, err := c.client.NetworkingV1alpha1().IPAddresses().Get(context.TODO(), name, metav1.GetOptions{})
 	if err == nil {
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(c.client.CoreV1(), ipV1alpha1, describerSettings.ChunkSize)
+			events, _ = searchEvents(c.client.CoreV1(), ipV1alpha1, describerSettings)
 		}
 		return c.describeIPAddressV1alpha1(ipV1alpha1, events)
 	}
@@ -2950,7 +2968,7 @@ func (d *ServiceDescriber) Describe(namespace, name string, describerSettings De
 	endpoints, _ := d.CoreV1().Endpoints(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.CoreV1(), service, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.CoreV1(), service, describerSettings)
 	}
 	return describeService(service, endpoints, events)
 }
@@ -3069,7 +3087,7 @@ func (d *EndpointsDescriber) Describe(namespace, name string, describerSettings
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.CoreV1(), ep, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.CoreV1(), ep, describerSettings)
 	}
 
 	return describeEndpoints(ep, events)
@@ -3141,7 +3159,7 @@ func (d *EndpointSliceDescriber) Describe(namespace, name string, describerSetti
 	epsV1, err := d.DiscoveryV1().EndpointSlices(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if err == nil {
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(d.CoreV1(), epsV1, describerSettings.ChunkSize)
+			events, _ = searchEvents(d.CoreV1(), epsV1, describerSettings)
 		}
 		return describeEndpointSliceV1(epsV1, events)
 	}
@@ -3152,7 +3170,7 @@ func (d *EndpointSliceDescriber) Describe(namespace, name string, describerSetti
 	}
 
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.CoreV1(), epsV1beta1, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.CoreV1(), epsV1beta1, describerSettings)
 	}
 
 	return describeEndpointSliceV1beta1(epsV1beta1, events)
@@ -3373,7 +3391,7 @@ func (d *ServiceAccountDescriber) Describe(namespace, name string, describerSett
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(d.CoreV1(), serviceAccount, describerSettings.ChunkSize)
+		events, _ = searchEvents(d.CoreV1(), serviceAccount, describerSettings)
 	}
 
 	return describeServiceAccount(serviceAccount, tokens, missingSecrets, events)
@@ -3638,17 +3656,22 @@ func (d *NodeDescriber) Describe(namespace, name string, describerSettings Descr
 			// controller use node.uid
 			// kubelet use node.name
 			// TODO: Uniform use of UID
-			events, _ = searchEvents(d.CoreV1(), ref, describerSettings.ChunkSize)
+			events, _ = searchEvents(d.CoreV1(), ref, describerSettings)
 
 			ref.UID = types.UID(ref.Name)
-			eventsInvName, _ := searchEvents(d.CoreV1(), ref, describerSettings.ChunkSize)
+			eventsInvName, _ := searchEvents(d.CoreV1(), ref, describerSettings)
 
 			// Merge the results of two queries
 			events.Items = append(events.Items, eventsInvName.Items...)
 		}
 	}
 
-	return describeNode(node, nodeNonTerminatedPodsList, events, canViewPods, &LeaseDescriber{d})
+	var capacity *nodeCapacity
+	if canViewPods {
+		capacity = getNodeCapacity(d.Discovery().RESTClient(), node, nodeNonTerminatedPodsList)
+	}
+
+	return describeNode(node, nodeNonTerminatedPodsList, events, canViewPods, &LeaseDescriber{d}, capacity)
 }
 
 type LeaseDescriber struct {
@@ -3656,7 +3679,7 @@ type LeaseDescriber struct {
 }
 
 func describeNode(node *corev1.Node, nodeNonTerminatedPodsList *corev1.PodList, events *corev1.EventList,
-	canViewPods bool, ld *LeaseDescriber) (string, error) {
+	canViewPods bool, ld *LeaseDescriber, capacity *nodeCapacity) (string, error) {
 	return tabbedString(func(out io.Writer) error {
 		w := NewPrefixWriter(out)
 		w.Write(LEVEL_0, "Name:\t%s\n", node.Name)
@@ -3744,6 +3767,9 @@ func describeNode(node *corev1.Node, nodeNonTerminatedPodsList *corev1.PodList,
 		}
 		if canViewPods && nodeNonTerminatedPodsList != nil {
 			describeNodeResource(nodeNonTerminatedPodsList, node, w)
+			if capacity != nil {
+				describeNodeCapacity(node, nodeNonTerminatedPodsList, capacity, w)
+			}
 		} else {
 			w.Write(LEVEL_0, "Pods:\tnot authorized\n")
 		}
@@ -3796,7 +3822,7 @@ func (p *StatefulSetDescriber) Describe(namespace, name string, describerSetting
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(p.client.CoreV1(), ps, describerSettings.ChunkSize)
+		events, _ = searchEvents(p.client.CoreV1(), ps, describerSettings)
 	}
 
 	return describeStatefulSet(ps, selector, events, running, waiting, succeeded, failed)
@@ -3862,7 +3888,7 @@ func (p *CertificateSigningRequestDescriber) Describe(namespace, name string, de
 		expirationSeconds = csr.Spec.ExpirationSeconds
 		username = csr.Spec.Username
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(p.client.CoreV1(), csr, describerSettings.ChunkSize)
+			events, _ = searchEvents(p.client.CoreV1(), csr, describerSettings)
 		}
 	} else if csr, err := p.client.CertificatesV1beta1().CertificateSigningRequests().Get(context.TODO(), name, metav1.GetOptions{}); err == nil {
 		crBytes = csr.Spec.Request
@@ -3878,7 +3904,7 @@ func (p *CertificateSigningRequestDescriber) Describe(namespace, name string, de
 		expirationSeconds = csr.Spec.ExpirationSeconds
 		username = csr.Spec.Username
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(p.client.CoreV1(), csr, describerSettings.ChunkSize)
+			events, _ = searchEvents(p.client.CoreV1(), csr, describerSettings)
 		}
 	} else {
 		return "", err
@@ -3966,7 +3992,7 @@ func (d *HorizontalPodAutoscalerDescriber) Describe(namespace, name string, desc
 	hpaV2, err := d.client.AutoscalingV2().HorizontalPodAutoscalers(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if err == nil {
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(d.client.CoreV1(), hpaV2, describerSettings.ChunkSize)
+			events, _ = searchEvents(d.client.CoreV1(), hpaV2, describerSettings)
 		}
 		return describeHorizontalPodAutoscalerV2(hpaV2, events, d)
 	}
@@ -3974,7 +4000,7 @@ func (d *HorizontalPodAutoscalerDescriber) Describe(namespace, name string, desc
 	hpaV1, err := d.client.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if err == nil {
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(d.client.CoreV1(), hpaV1, describerSettings.ChunkSize)
+			events, _ = searchEvents(d.client.CoreV1(), hpaV1, describerSettings)
 		}
 		return describeHorizontalPodAutoscalerV1(hpaV1, events, d)
 	}
@@ -4323,7 +4349,7 @@ func (dd *DeploymentDescriber) Describe(namespace, name string, describerSetting
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(dd.client.CoreV1(), d, describerSettings.ChunkSize)
+		events, _ = searchEvents(dd.client.CoreV1(), d, describerSettings)
 	}
 
 	var oldRSs, newRSs []*appsv1.ReplicaSet
@@ -4465,7 +4491,7 @@ func (d *ConfigMapDescriber) Describe(namespace, name string, describerSettings
 		w.Write(LEVEL_0, "\n")
 
 		if describerSettings.ShowEvents {
-			events, err := searchEvents(d.CoreV1(), configMap, describerSettings.ChunkSize)
+			events, err := searchEvents(d.CoreV1(), configMap, describerSettings)
 			if err != nil {
 				return err
 			}
@@ -4646,7 +4672,7 @@ func (s *StorageClassDescriber) Describe(namespace, name string, describerSettin
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(s.CoreV1(), sc, describerSettings.ChunkSize)
+		events, _ = searchEvents(s.CoreV1(), sc, describerSettings)
 	}
 
 	return describeStorageClass(sc, events)
@@ -4698,7 +4724,7 @@ func (c *CSINodeDescriber) Describe(namespace, name string, describerSettings De
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(c.CoreV1(), csi, describerSettings.ChunkSize)
+		events, _ = searchEvents(c.CoreV1(), csi, describerSettings)
 	}
 
 	return describeCSINode(csi, events)
@@ -4780,7 +4806,7 @@ func (p *PodDisruptionBudgetDescriber) Describe(namespace, name string, describe
 	if err == nil {
 		var events *corev1.EventList
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(p.CoreV1(), pdbv1, describerSettings.ChunkSize)
+			events, _ = searchEvents(p.CoreV1(), pdbv1, describerSettings)
 		}
 		return describePodDisruptionBudgetV1(pdbv1, events)
 	}
@@ -4792,7 +4818,7 @@ func (p *PodDisruptionBudgetDescriber) Describe(namespace, name string, describe
 	if err == nil {
 		var events *corev1.EventList
 		if describerSettings.ShowEvents {
-			events, _ = searchEvents(p.CoreV1(), pdbv1beta1, describerSettings.ChunkSize)
+			events, _ = searchEvents(p.CoreV1(), pdbv1beta1, describerSettings)
 		}
 		return describePodDisruptionBudgetV1beta1(pdbv1beta1, events)
 	}
@@ -4873,7 +4899,7 @@ func (s *PriorityClassDescriber) Describe(namespace, name string, describerSetti
 
 	var events *corev1.EventList
 	if describerSettings.ShowEvents {
-		events, _ = searchEvents(s.CoreV1(), pc, describerSettings.ChunkSize)
+		events, _ = searchEvents(s.CoreV1(), pc, describerSettings)
 	}
 
 	return describePriorityClass(pc, events)
@@ -5344,21 +5370,13 @@ func shorten(s string, maxLength int) string {
 // translateMicroTimestampSince returns the elapsed time since timestamp in
 // human-readable approximation.
 func translateMicroTimestampSince(timestamp metav1.MicroTime) string {
-	if timestamp.IsZero() {
-		return "<unknown>"
-	}
-
-	return duration.HumanDuration(time.Since(timestamp.Time))
+	return timeformat.Since(timestamp.Time)
 }
 
 // translateTimestampSince returns the elapsed time since timestamp in
 // human-readable approximation.
 func translateTimestampSince(timestamp metav1.Time) string {
-	if timestamp.IsZero() {
-		return "<unknown>"
-	}
-
-	return duration.HumanDuration(time.Since(timestamp.Time))
+	return timeformat.Since(timestamp.Time)
 }
 
 // Pass ports=nil for all ports.
@@ -5524,8 +5542,9 @@ func ingressLoadBalancerStatusStringerV1beta1(s networkingv1beta1.IngressLoadBal
 }
 
 // searchEvents finds events about the specified object.
-// It is very similar to CoreV1.Events.Search, but supports the Limit parameter.
-func searchEvents(client corev1client.EventsGetter, objOrRef runtime.Object, limit int64) (*corev1.EventList, error) {
+// It is very similar to CoreV1.Events.Search, but supports the Limit parameter,
+// and keeps only the events within the window of the describer settings.
+func searchEvents(client corev1client.EventsGetter, objOrRef runtime.Object, describerSettings DescriberSettings) (*corev1.EventList, error) {
 	ref, err := reference.GetReference(scheme.Scheme, objOrRef)
 	if err != nil {
 		return nil, err
@@ -5543,7 +5562,7 @@ func searchEvents(client corev1client.EventsGetter, objOrRef runtime.Object, lim
 
 	e := client.Events(ref.Namespace)
 	fieldSelector := e.GetFieldSelector(&ref.Name, &ref.Namespace, refKind, refUID)
-	initialOpts := metav1.ListOptions{FieldSelector: fieldSelector.String(), Limit: limit}
+	initialOpts := metav1.ListOptions{FieldSelector: fieldSelector.String(), Limit: describerSettings.ChunkSize}
 	eventList := &corev1.EventList{}
 	err = runtimeresource.FollowContinue(&initialOpts,
 		func(options metav1.ListOptions) (runtime.Object, error) {
@@ -5554,5 +5573,38 @@ func searchEvents(client corev1client.EventsGetter, objOrRef runtime.Object, lim
 			eventList.Items = append(eventList.Items, newEvents.Items...)
 			return newEvents, nil
 		})
+	filterEvents(eventList, describerSettings, time.Now())
 	return eventList, err
 }
+
+// filterEvents keeps the events last seen within the EventsSince of the
+// settings, then the EventsLimit most recent of them.
+func filterEvents(eventList *corev1.EventList, describerSettings DescriberSettings, now time.Time) {
+	if describerSettings.EventsSince > 0 {
+		items := []corev1.Event{}
+		for _, e := range eventList.Items {
+			// events whose time is unknown are kept
+			if t := eventLastSeen(e); t.IsZero() || now.Sub(t) <= describerSettings.EventsSince {
+				items = append(items, e)
+			}
+		}
+		eventList.Items = items
+	}
+	if describerSettings.EventsLimit > 0 && len(eventList.Items) > describerSettings.EventsLimit {
+		sort.SliceStable(eventList.Items, func(i, j int) bool {
+			return eventLastSeen(eventList.Items[i]).Before(eventLastSeen(eventList.Items[j]))
+		})
+		eventList.Items = eventList.Items[len(eventList.Items)-describerSettings.EventsLimit:]
+	}
+}
+
+// eventLastSeen returns the last time the event was observed.
+func eventLastSeen(e corev1.Event) time.Time {
+	if e.Series != nil {
+		return e.Series.LastObservedTime.Time
+	}
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	return e.EventTime.Time
+}
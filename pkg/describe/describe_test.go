@@ -19,6 +19,8 @@ package describe
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"net/http"
 	"reflect"
 	"strings"
 	"testing"
@@ -48,6 +50,9 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	fakerest "k8s.io/client-go/rest/fake"
 	utilpointer "k8s.io/utils/pointer"
 )
 
@@ -6300,3 +6305,346 @@ func TestDescribeSeccompProfile(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterEvents(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	newEvent := func(name string, lastSeen time.Time) corev1.Event {
+		return corev1.Event{ObjectMeta: metav1.ObjectMeta{Name: name}, LastTimestamp: metav1.NewTime(lastSeen)}
+	}
+	events := []corev1.Event{
+		newEvent("old", now.Add(-3*time.Hour)),
+		newEvent("recent", now.Add(-10*time.Minute)),
+		newEvent("unknown", time.Time{}),
+		newEvent("latest", now.Add(-time.Minute)),
+	}
+
+	testCases := []struct {
+		name     string
+		settings DescriberSettings
+		expected []string
+	}{
+		{
+			name:     "no window",
+			expected: []string{"old", "recent", "unknown", "latest"},
+		},
+		{
+			name:     "since",
+			settings: DescriberSettings{EventsSince: time.Hour},
+			expected: []string{"recent", "unknown", "latest"},
+		},
+		{
+			name:     "limit",
+			settings: DescriberSettings{EventsLimit: 2},
+			expected: []string{"recent", "latest"},
+		},
+		{
+			name:     "since and limit",
+			settings: DescriberSettings{EventsSince: time.Hour, EventsLimit: 1},
+			expected: []string{"latest"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			eventList := &corev1.EventList{Items: append([]corev1.Event{}, events...)}
+			filterEvents(eventList, tc.settings, now)
+			names := []string{}
+			for _, e := range eventList.Items {
+				names = append(names, e.Name)
+			}
+			if !reflect.DeepEqual(tc.expected, names) {
+				t.Errorf("expected events %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
+
+func TestFilterSections(t *testing.T) {
+	output := `Name:         web
+Namespace:    default
+Labels:       app=web
+              tier=frontend
+Pod Template:
+  Containers:
+   nginx:
+    Image:  nginx
+
+Conditions:
+  Type    Status
+  Ready   True
+Events:  <none>
+`
+	expected := `Name:         web
+Namespace:    default
+Pod Template:
+  Containers:
+   nginx:
+    Image:  nginx
+
+Events:  <none>
+`
+	if got := FilterSections(output, []string{"pod template", "Events"}); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+	if got := FilterSections(output, nil); got != output {
+		t.Errorf("expected the output to be unchanged, got\n%s", got)
+	}
+}
+
+func TestDescribeNodeCapacity(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar"},
+		Status: corev1.NodeStatus{
+			Capacity: getResourceList("4", "8Gi"),
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+			},
+		},
+	}
+	pods := &corev1.PodList{Items: []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "foo"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "web", Resources: corev1.ResourceRequirements{Requests: getResourceList("500m", "1Gi")}},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "foo"},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "batch", Resources: corev1.ResourceRequirements{Requests: getResourceList("1", "512Mi")}},
+			}},
+		},
+	}}
+
+	responses := map[string]string{
+		"/apis/metrics.k8s.io/v1beta1/nodes/bar": `{"usage": {"cpu": "2", "memory": "6Gi"}}`,
+		"/apis/metrics.k8s.io/v1beta1/namespaces/foo/pods": `{"items": [
+			{"metadata": {"name": "web", "namespace": "foo"}, "containers": [{"name": "web", "usage": {"cpu": "250m", "memory": "2Gi"}}]},
+			{"metadata": {"name": "batch", "namespace": "foo"}, "containers": [{"name": "batch", "usage": {"cpu": "1500m", "memory": "256Mi"}}]}
+		]}`,
+		"/api/v1/nodes/bar/proxy/configz": `{"kubeletconfig": {"evictionHard": {"memory.available": "500Mi"}}}`,
+	}
+	client := &fakerest.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: fakerest.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			body, ok := responses[req.URL.Path]
+			if !ok {
+				return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}),
+	}
+
+	tests := []struct {
+		name     string
+		client   rest.Interface
+		expected []string
+	}{
+		{
+			name:   "with metrics and configuration",
+			client: client,
+			expected: []string{
+				`Pod Usage:
+  Namespace  Name   CPU Requests  CPU Usage     Memory Requests  Memory Usage
+  ---------  ----   ------------  ---------     ---------------  ------------
+  foo        web    500m          250m (50%)    1Gi              2Gi (200%)
+  foo        batch  1             1500m (150%)  512Mi            256Mi (50%)`,
+				`Eviction Thresholds:
+  Signal            Threshold  Available  Condition
+  ------            ---------  ---------  ---------
+  memory.available  500Mi      2Gi        MemoryPressure=False`,
+				`Top Consumers:
+  Resource  Namespace  Name   Usage  Requests
+  --------  ---------  ----   -----  --------
+  cpu       foo        batch  1500m  1
+  cpu       foo        web    250m   500m
+  memory    foo        web    2Gi    1Gi
+  memory    foo        batch  256Mi  512Mi`,
+			},
+		},
+		{
+			name:   "without metrics",
+			client: nil,
+			expected: []string{
+				`Eviction Thresholds:
+  (kubelet defaults, the kubelet configuration is not readable)
+  Signal             Threshold  Available  Condition
+  ------             ---------  ---------  ---------
+  imagefs.available  15%        <unknown>  DiskPressure=Unknown
+  memory.available   100Mi      <unknown>  MemoryPressure=False`,
+				`Top Consumers:
+  Resource  Namespace  Name   Usage      Requests
+  --------  ---------  ----   -----      --------
+  cpu       foo        batch  <unknown>  1
+  cpu       foo        web    <unknown>  500m
+  memory    foo        web    <unknown>  1Gi
+  memory    foo        batch  <unknown>  512Mi`,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := tabbedString(func(out io.Writer) error {
+				w := NewPrefixWriter(out)
+				describeNodeCapacity(node, pods, getNodeCapacity(tc.client, node, pods), w)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(out, expected) {
+					t.Errorf("expected to find %q in output: %s", expected, out)
+				}
+			}
+			if tc.client == nil && strings.Contains(out, "Pod Usage:") {
+				t.Errorf("unexpected pod usage without metrics: %s", out)
+			}
+		})
+	}
+}
+
+func TestDescribeVolumeSnapshot(t *testing.T) {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "snapshot.storage.k8s.io/v1",
+		"kind":       "VolumeSnapshot",
+		"metadata":   map[string]interface{}{"name": "data-snap", "namespace": "foo"},
+		"spec": map[string]interface{}{
+			"source":                  map[string]interface{}{"persistentVolumeClaimName": "data"},
+			"volumeSnapshotClassName": "csi-snapclass",
+		},
+		"status": map[string]interface{}{
+			"readyToUse":  false,
+			"restoreSize": "10Gi",
+			"error": map[string]interface{}{
+				"time":    "2024-01-02T03:04:05Z",
+				"message": "Failed to check and update snapshot content: driver not found",
+			},
+		},
+	}}
+	out, err := describeVolumeSnapshot(snapshot, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedOut := `Name:         data-snap
+Namespace:    foo
+Labels:       <none>
+Annotations:  <none>
+Source:
+  PersistentVolumeClaim:  data
+Class:                    csi-snapclass
+Content:                  <none>
+Ready To Use:             false
+Restore Size:             10Gi
+Creation Time:            <none>
+Error:
+  Time:     2024-01-02T03:04:05Z
+  Message:  Failed to check and update snapshot content: driver not found
+`
+	if out != expectedOut {
+		t.Errorf("expected:\n%s\ngot:\n%s", expectedOut, out)
+	}
+}
+
+func TestDescribeCustomResource(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Database",
+		"metadata":   map[string]interface{}{"name": "orders", "namespace": "foo", "generation": int64(2)},
+		"spec": map[string]interface{}{
+			"engineVersion": "15",
+			"replicas":      int64(3),
+			"parameters":    map[string]interface{}{"max_connections": "200"},
+			"users": []interface{}{
+				map[string]interface{}{"name": "app", "roleRef": "readwrite"},
+				map[string]interface{}{"name": "report", "roleRef": "readonly"},
+			},
+		},
+		"status": map[string]interface{}{
+			"phase": "Ready",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": "True", "reason": "Provisioned", "message": "3/3 replicas", "lastTransitionTime": "2024-01-02T03:04:05Z"},
+			},
+		},
+	}}
+	version := map[string]interface{}{
+		"name": "v1",
+		"additionalPrinterColumns": []interface{}{
+			map[string]interface{}{"name": "Phase", "type": "string", "jsonPath": ".status.phase"},
+			map[string]interface{}{"name": "Replicas", "type": "integer", "jsonPath": ".spec.replicas"},
+			map[string]interface{}{"name": "Age", "type": "date", "jsonPath": ".metadata.creationTimestamp"},
+		},
+		"schema": map[string]interface{}{
+			"openAPIV3Schema": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"engineVersion": map[string]interface{}{"type": "string"},
+							"replicas":      map[string]interface{}{"type": "integer"},
+							"parameters":    map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+							"users": map[string]interface{}{
+								"type":                       "array",
+								"x-kubernetes-list-type":     "map",
+								"x-kubernetes-list-map-keys": []interface{}{"name"},
+								"items": map[string]interface{}{
+									"type": "object",
+									"properties": map[string]interface{}{
+										"name":    map[string]interface{}{"type": "string"},
+										"roleRef": map[string]interface{}{"type": "string"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	out, err := describeCustomResource(obj, version, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedOut := `Name:         orders
+Namespace:    foo
+Labels:       <none>
+Annotations:  <none>
+API Version:  example.com/v1
+Kind:         Database
+Phase:        Ready
+Replicas:     3
+Metadata:
+  Generation:  2
+Spec:
+  Engine Version:  15
+  Parameters:
+    max_connections:  200
+  Replicas:           3
+  Users:
+    [name=app]:
+      Name:      app
+      Role Ref:  readwrite
+    [name=report]:
+      Name:      report
+      Role Ref:  readonly
+Status:
+  Phase:  Ready
+Conditions:
+  Type       Status  LastTransitionTime               Reason       Message
+  ----       ------  ------------------               ------       -------
+  Available  True    Tue, 02 Jan 2024 03:04:05 +0000  Provisioned  3/3 replicas
+`
+	if out != expectedOut {
+		t.Errorf("expected:\n%s\ngot:\n%s", expectedOut, out)
+	}
+
+	// without the version of the definition, the items of lists are labeled by index
+	out, err = describeCustomResource(obj, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "Users:\n    [0]:\n      Name:      app\n") {
+		t.Errorf("expected the users labeled by index, got:\n%s", out)
+	}
+}
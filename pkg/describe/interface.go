@@ -18,6 +18,8 @@ package describe
 
 import (
 	"fmt"
+	"time"
+
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
@@ -49,6 +51,10 @@ type ResourceDescriber interface {
 type DescriberSettings struct {
 	ShowEvents bool
 	ChunkSize  int64
+	// EventsSince hides the events last seen before this duration, if set.
+	EventsSince time.Duration
+	// EventsLimit shows only this number of the most recent events, if set.
+	EventsLimit int
 }
 
 // ObjectDescriber is an interface for displaying arbitrary objects with extra
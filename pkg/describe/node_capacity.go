@@ -0,0 +1,246 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// topConsumersCount is the number of pods listed per resource in the top
+// consumers of a node.
+const topConsumersCount = 3
+
+// defaultEvictionHard holds the default hard eviction thresholds of the
+// kubelet on Linux, used when its configuration is not readable.
+var defaultEvictionHard = map[string]string{
+	"memory.available":  "100Mi",
+	"nodefs.available":  "10%",
+	"nodefs.inodesFree": "5%",
+	"imagefs.available": "15%",
+}
+
+// nodeCapacity holds the usage of a node and of its pods, read from the
+// metrics API, and the hard eviction thresholds of its kubelet.
+type nodeCapacity struct {
+	// nodeUsage and podUsage are nil when the metrics API is not available
+	nodeUsage corev1.ResourceList
+	podUsage  map[types.NamespacedName]corev1.ResourceList
+
+	evictionHard map[string]string
+	// evictionFromConfig is false when the thresholds are the defaults
+	evictionFromConfig bool
+}
+
+// getNodeCapacity reads the metrics of the node and its pods, and the
+// configuration of its kubelet through the API server proxy. Everything
+// that is not available is left out of the description.
+func getNodeCapacity(client rest.Interface, node *corev1.Node, pods *corev1.PodList) *nodeCapacity {
+	capacity := &nodeCapacity{evictionHard: defaultEvictionHard}
+	if client == nil {
+		return capacity
+	}
+	// the responses are decoded as JSON, whatever the encoding the client prefers
+	ctx := context.TODO()
+
+	nodeMetrics := &metricsv1beta1.NodeMetrics{}
+	if data, err := client.Get().AbsPath("/apis/metrics.k8s.io/v1beta1/nodes", node.Name).SetHeader("Accept", runtime.ContentTypeJSON).DoRaw(ctx); err == nil && json.Unmarshal(data, nodeMetrics) == nil {
+		capacity.nodeUsage = nodeMetrics.Usage
+	}
+
+	if pods != nil && capacity.nodeUsage != nil {
+		namespaces := sets.New[string]()
+		for _, pod := range pods.Items {
+			namespaces.Insert(pod.Namespace)
+		}
+		capacity.podUsage = map[types.NamespacedName]corev1.ResourceList{}
+		for _, namespace := range sets.List(namespaces) {
+			podMetrics := &metricsv1beta1.PodMetricsList{}
+			data, err := client.Get().AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", namespace, "pods").SetHeader("Accept", runtime.ContentTypeJSON).DoRaw(ctx)
+			if err != nil || json.Unmarshal(data, podMetrics) != nil {
+				continue
+			}
+			for _, m := range podMetrics.Items {
+				usage := corev1.ResourceList{}
+				for _, c := range m.Containers {
+					addResourceList(usage, c.Usage)
+				}
+				capacity.podUsage[types.NamespacedName{Namespace: m.Namespace, Name: m.Name}] = usage
+			}
+		}
+	}
+
+	configz := struct {
+		KubeletConfig struct {
+			EvictionHard map[string]string `json:"evictionHard"`
+		} `json:"kubeletconfig"`
+	}{}
+	if data, err := client.Get().AbsPath("/api/v1/nodes", node.Name, "proxy/configz").SetHeader("Accept", runtime.ContentTypeJSON).DoRaw(ctx); err == nil && json.Unmarshal(data, &configz) == nil && configz.KubeletConfig.EvictionHard != nil {
+		capacity.evictionHard = configz.KubeletConfig.EvictionHard
+		capacity.evictionFromConfig = true
+	}
+	return capacity
+}
+
+func addResourceList(list, add corev1.ResourceList) {
+	for name, quantity := range add {
+		value := list[name]
+		value.Add(quantity)
+		list[name] = value
+	}
+}
+
+// describeNodeCapacity writes the usage of the pods against their requests,
+// the eviction thresholds against the available resources of the node, and
+// the pods consuming the most of each resource.
+func describeNodeCapacity(node *corev1.Node, pods *corev1.PodList, capacity *nodeCapacity, w PrefixWriter) {
+	requests := map[types.NamespacedName]corev1.ResourceList{}
+	for _, pod := range pods.Items {
+		req, _ := resourcehelper.PodRequestsAndLimits(&pod)
+		requests[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = req
+	}
+
+	if capacity.podUsage != nil {
+		w.Write(LEVEL_0, "Pod Usage:\n")
+		w.Write(LEVEL_1, "Namespace\tName\tCPU Requests\tCPU Usage\tMemory Requests\tMemory Usage\n")
+		w.Write(LEVEL_1, "---------\t----\t------------\t---------\t---------------\t------------\n")
+		for _, pod := range pods.Items {
+			key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+			req := requests[key]
+			usage, found := capacity.podUsage[key]
+			w.Write(LEVEL_1, "%s\t%s\t%s\t%s\t%s\t%s\n", pod.Namespace, pod.Name,
+				formatQuantity(corev1.ResourceCPU, req[corev1.ResourceCPU]),
+				formatUsage(corev1.ResourceCPU, usage, req, found),
+				formatQuantity(corev1.ResourceMemory, req[corev1.ResourceMemory]),
+				formatUsage(corev1.ResourceMemory, usage, req, found))
+		}
+	}
+
+	w.Write(LEVEL_0, "Eviction Thresholds:\n")
+	if !capacity.evictionFromConfig {
+		w.Write(LEVEL_1, "(kubelet defaults, the kubelet configuration is not readable)\n")
+	}
+	w.Write(LEVEL_1, "Signal\tThreshold\tAvailable\tCondition\n")
+	w.Write(LEVEL_1, "------\t---------\t---------\t---------\n")
+	signals := make([]string, 0, len(capacity.evictionHard))
+	for signal := range capacity.evictionHard {
+		signals = append(signals, signal)
+	}
+	sort.Strings(signals)
+	for _, signal := range signals {
+		available := "<unknown>"
+		if signal == "memory.available" && capacity.nodeUsage != nil {
+			// the kubelet compares the working set of the node to its capacity
+			memory := node.Status.Capacity.Memory().DeepCopy()
+			memory.Sub(capacity.nodeUsage[corev1.ResourceMemory])
+			available = memory.String()
+		}
+		w.Write(LEVEL_1, "%s\t%s\t%s\t%s\n", signal, capacity.evictionHard[signal], available, signalCondition(node, signal))
+	}
+
+	w.Write(LEVEL_0, "Top Consumers:\n")
+	w.Write(LEVEL_1, "Resource\tNamespace\tName\tUsage\tRequests\n")
+	w.Write(LEVEL_1, "--------\t---------\t----\t-----\t--------\n")
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		// the pods are ranked by usage when it is known, by requests otherwise
+		amount := func(key types.NamespacedName) int64 {
+			list := requests[key]
+			if capacity.podUsage != nil {
+				list = capacity.podUsage[key]
+			}
+			quantity := list[name]
+			return quantity.MilliValue()
+		}
+		keys := make([]types.NamespacedName, 0, len(requests))
+		for key := range requests {
+			if amount(key) > 0 {
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if amount(keys[i]) != amount(keys[j]) {
+				return amount(keys[i]) > amount(keys[j])
+			}
+			return keys[i].String() < keys[j].String()
+		})
+		if len(keys) > topConsumersCount {
+			keys = keys[:topConsumersCount]
+		}
+		for _, key := range keys {
+			usage := "<unknown>"
+			if list, ok := capacity.podUsage[key]; ok {
+				usage = formatQuantity(name, list[name])
+			}
+			w.Write(LEVEL_1, "%s\t%s\t%s\t%s\t%s\n", name, key.Namespace, key.Name, usage, formatQuantity(name, requests[key][name]))
+		}
+	}
+}
+
+// formatQuantity formats CPU in millicores and other resources as is.
+func formatQuantity(name corev1.ResourceName, quantity resource.Quantity) string {
+	if name == corev1.ResourceCPU {
+		return resource.NewMilliQuantity(quantity.MilliValue(), resource.DecimalSI).String()
+	}
+	return quantity.String()
+}
+
+// formatUsage formats the usage of a resource with its percentage of the
+// requests, when there are requests.
+func formatUsage(name corev1.ResourceName, usage, requests corev1.ResourceList, found bool) string {
+	if !found {
+		return "<unknown>"
+	}
+	used, requested := usage[name], requests[name]
+	if requested.MilliValue() == 0 {
+		return formatQuantity(name, used)
+	}
+	return fmt.Sprintf("%s (%d%%)", formatQuantity(name, used), used.MilliValue()*100/requested.MilliValue())
+}
+
+// signalCondition returns the node condition reporting the pressure on the
+// resource of an eviction signal, with its status.
+func signalCondition(node *corev1.Node, signal string) string {
+	var conditionType corev1.NodeConditionType
+	switch {
+	case strings.HasPrefix(signal, "memory."):
+		conditionType = corev1.NodeMemoryPressure
+	case strings.HasPrefix(signal, "nodefs."), strings.HasPrefix(signal, "imagefs."), strings.HasPrefix(signal, "containerfs."):
+		conditionType = corev1.NodeDiskPressure
+	case strings.HasPrefix(signal, "pid."):
+		conditionType = corev1.NodePIDPressure
+	default:
+		return "<none>"
+	}
+	for _, c := range node.Status.Conditions {
+		if c.Type == conditionType {
+			return string(c.Type) + "=" + string(c.Status)
+		}
+	}
+	return string(conditionType) + "=Unknown"
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"strings"
+)
+
+// FilterSections keeps the sections of the output of a describer whose name
+// is in sections, ignoring case, along with the Name and Namespace of the
+// object. A section starts with an unindented "Section:" line and goes on
+// with the indented lines following it.
+func FilterSections(output string, sections []string) string {
+	if len(sections) == 0 {
+		return output
+	}
+
+	var b strings.Builder
+	keep := false
+	for _, line := range strings.SplitAfter(output, "\n") {
+		if len(line) > 0 && line[0] != ' ' && line[0] != '\t' && line[0] != '\n' {
+			name, _, _ := strings.Cut(line, ":")
+			keep = hasSection(sections, strings.TrimSpace(name))
+		}
+		if keep {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+func hasSection(sections []string, name string) bool {
+	if name == "Name" || name == "Namespace" {
+		return true
+	}
+	for _, section := range sections {
+		if strings.EqualFold(strings.TrimSpace(section), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IncludesSection returns whether the section is kept by FilterSections.
+func IncludesSection(sections []string, name string) bool {
+	return len(sections) == 0 || hasSection(sections, name)
+}
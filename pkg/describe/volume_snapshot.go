@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package describe
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	clientset "k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// volumeSnapshotGroupKind is the kind of the CSI volume snapshots, which are
+// custom resources read with the dynamic client.
+var volumeSnapshotGroupKind = schema.GroupKind{Group: "snapshot.storage.k8s.io", Kind: "VolumeSnapshot"}
+
+// VolumeSnapshotDescriber generates information about a CSI volume snapshot:
+// its source, its readiness, its restore size and the error taking it.
+type VolumeSnapshotDescriber struct {
+	resource schema.GroupVersionResource
+	dynamic  dynamic.Interface
+	events   corev1client.EventsGetter
+}
+
+func (d *VolumeSnapshotDescriber) Describe(namespace, name string, describerSettings DescriberSettings) (string, error) {
+	snapshot, err := d.dynamic.Resource(d.resource).Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	var events *corev1.EventList
+	if describerSettings.ShowEvents {
+		events, _ = searchEvents(d.events, snapshot, describerSettings)
+	}
+
+	return describeVolumeSnapshot(snapshot, events)
+}
+
+func describeVolumeSnapshot(snapshot *unstructured.Unstructured, events *corev1.EventList) (string, error) {
+	field := func(fields ...string) string {
+		value, found, err := unstructured.NestedFieldNoCopy(snapshot.Object, fields...)
+		if !found || err != nil || value == nil {
+			return "<none>"
+		}
+		if s, ok := value.(string); ok {
+			if len(s) == 0 {
+				return "<none>"
+			}
+			return s
+		}
+		return fmt.Sprint(value)
+	}
+
+	return tabbedString(func(out io.Writer) error {
+		w := NewPrefixWriter(out)
+		w.Write(LEVEL_0, "Name:\t%s\n", snapshot.GetName())
+		w.Write(LEVEL_0, "Namespace:\t%s\n", snapshot.GetNamespace())
+		printLabelsMultiline(w, "Labels", snapshot.GetLabels())
+		printAnnotationsMultiline(w, "Annotations", snapshot.GetAnnotations())
+		w.Write(LEVEL_0, "Source:\n")
+		if _, found, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "volumeSnapshotContentName"); found {
+			w.Write(LEVEL_1, "VolumeSnapshotContent:\t%s\n", field("spec", "source", "volumeSnapshotContentName"))
+		} else {
+			w.Write(LEVEL_1, "PersistentVolumeClaim:\t%s\n", field("spec", "source", "persistentVolumeClaimName"))
+		}
+		w.Write(LEVEL_0, "Class:\t%s\n", field("spec", "volumeSnapshotClassName"))
+		w.Write(LEVEL_0, "Content:\t%s\n", field("status", "boundVolumeSnapshotContentName"))
+		w.Write(LEVEL_0, "Ready To Use:\t%s\n", field("status", "readyToUse"))
+		w.Write(LEVEL_0, "Restore Size:\t%s\n", field("status", "restoreSize"))
+		w.Write(LEVEL_0, "Creation Time:\t%s\n", field("status", "creationTime"))
+		if _, found, _ := unstructured.NestedMap(snapshot.Object, "status", "error"); found {
+			w.Write(LEVEL_0, "Error:\n")
+			w.Write(LEVEL_1, "Time:\t%s\n", field("status", "error", "time"))
+			w.Write(LEVEL_1, "Message:\t%s\n", field("status", "error", "message"))
+		}
+		if events != nil {
+			DescribeEvents(events, w)
+		}
+		return nil
+	})
+}
+
+func volumeSnapshotDescriberFor(mapping *meta.RESTMapping, clientConfig *rest.Config) (ResourceDescriber, bool) {
+	dynamicClient, err := dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, false
+	}
+	clientSet, err := clientset.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, false
+	}
+	return &VolumeSnapshotDescriber{mapping.Resource, dynamicClient, clientSet.CoreV1()}, true
+}
@@ -31,10 +31,19 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	// CordonReasonAnnotation records why a node was cordoned.
+	CordonReasonAnnotation = "kubectl.kubernetes.io/cordon-reason"
+	// CordonExpiresAnnotation records, in RFC 3339 format, when the cordon
+	// of a node expires and the node may be uncordoned again.
+	CordonExpiresAnnotation = "kubectl.kubernetes.io/cordon-expires"
+)
+
 // CordonHelper wraps functionality to cordon/uncordon nodes
 type CordonHelper struct {
-	node    *corev1.Node
-	desired bool
+	node        *corev1.Node
+	desired     bool
+	annotations map[string]string
 }
 
 // NewCordonHelper returns a new CordonHelper
@@ -60,12 +69,42 @@ func NewCordonHelperFromRuntimeObject(nodeObject runtime.Object, scheme *runtime
 	return NewCordonHelper(node), nil
 }
 
+// WithAnnotations makes the helper set the given annotations on the node
+// along with its schedulability. Annotations with an empty value are removed.
+func (c *CordonHelper) WithAnnotations(annotations map[string]string) *CordonHelper {
+	c.annotations = annotations
+	return c
+}
+
 // UpdateIfRequired returns true if c.node.Spec.Unschedulable isn't already set,
-// or false when no change is needed
+// or any of the annotations differ, or false when no change is needed
 func (c *CordonHelper) UpdateIfRequired(desired bool) bool {
 	c.desired = desired
 
-	return c.node.Spec.Unschedulable != c.desired
+	return c.node.Spec.Unschedulable != c.desired || c.annotationsRequireUpdate()
+}
+
+func (c *CordonHelper) annotationsRequireUpdate() bool {
+	for key, value := range c.annotations {
+		current, ok := c.node.Annotations[key]
+		if value != current || (len(value) == 0 && ok) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CordonHelper) applyAnnotations() {
+	for key, value := range c.annotations {
+		if len(value) == 0 {
+			delete(c.node.Annotations, key)
+			continue
+		}
+		if c.node.Annotations == nil {
+			c.node.Annotations = map[string]string{}
+		}
+		c.node.Annotations[key] = value
+	}
 }
 
 // PatchOrReplace uses given clientset to update the node status, either by patching or
@@ -87,6 +126,7 @@ func (c *CordonHelper) PatchOrReplaceWithContext(clientCtx context.Context, clie
 	}
 
 	c.node.Spec.Unschedulable = c.desired
+	c.applyAnnotations()
 
 	newData, err := json.Marshal(c.node)
 	if err != nil {
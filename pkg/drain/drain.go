@@ -33,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/kubernetes"
@@ -213,6 +214,69 @@ func (d *Helper) GetPodsForDeletion(nodeName string) (*PodDeleteList, []error) {
 	return list, nil
 }
 
+// GetBlockingDisruptionBudgets returns the PodDisruptionBudgets that select
+// any of the pods and currently allow no disruptions, keyed by the
+// "namespace/name" of each blocked pod. Evicting these pods will not
+// succeed until the budget allows it again.
+func (d *Helper) GetBlockingDisruptionBudgets(pods []corev1.Pod) (map[string]string, error) {
+	blocked := map[string]string{}
+	err := d.visitDisruptionBudgets(pods, func(pod *corev1.Pod, pdb *policyv1.PodDisruptionBudget) bool {
+		if pdb.Status.DisruptionsAllowed > 0 {
+			return true
+		}
+		blocked[pod.Namespace+"/"+pod.Name] = pdb.Name
+		return false
+	})
+	return blocked, err
+}
+
+// GetDisruptionBudgets returns the "namespace/name" of every
+// PodDisruptionBudget selecting each of the pods, keyed by the
+// "namespace/name" of the pod. The budgets of a namespace are listed once.
+func (d *Helper) GetDisruptionBudgets(pods []corev1.Pod) (map[string]sets.Set[string], error) {
+	budgets := map[string]sets.Set[string]{}
+	err := d.visitDisruptionBudgets(pods, func(pod *corev1.Pod, pdb *policyv1.PodDisruptionBudget) bool {
+		key := pod.Namespace + "/" + pod.Name
+		if budgets[key] == nil {
+			budgets[key] = sets.New[string]()
+		}
+		budgets[key].Insert(pdb.Namespace + "/" + pdb.Name)
+		return true
+	})
+	return budgets, err
+}
+
+// visitDisruptionBudgets calls fn for every pod and PodDisruptionBudget
+// selecting it, until fn returns false for that pod.
+func (d *Helper) visitDisruptionBudgets(pods []corev1.Pod, fn func(*corev1.Pod, *policyv1.PodDisruptionBudget) bool) error {
+	budgets := map[string][]policyv1.PodDisruptionBudget{}
+	for i := range pods {
+		pod := &pods[i]
+		pdbs, ok := budgets[pod.Namespace]
+		if !ok {
+			list, err := d.Client.PolicyV1().PodDisruptionBudgets(pod.Namespace).List(d.getContext(), metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			pdbs = list.Items
+			budgets[pod.Namespace] = pdbs
+		}
+		for j := range pdbs {
+			selector, err := metav1.LabelSelectorAsSelector(pdbs[j].Spec.Selector)
+			if err != nil {
+				return err
+			}
+			if !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			if !fn(pod, &pdbs[j]) {
+				break
+			}
+		}
+	}
+	return nil
+}
+
 func filterPods(podList *corev1.PodList, filters []PodFilter) *PodDeleteList {
 	pods := []PodDelete{}
 	for _, pod := range podList.Items {
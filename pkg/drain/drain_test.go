@@ -36,6 +36,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/fake"
 	ktest "k8s.io/client-go/testing"
@@ -316,6 +317,49 @@ func TestCheckEvictionSupport(t *testing.T) {
 	}
 }
 
+func TestGetBlockingDisruptionBudgets(t *testing.T) {
+	pdb := func(name string, allowed int32, selector *metav1.LabelSelector) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: selector},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+		}
+	}
+	pod := func(name string, labels map[string]string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}}
+	}
+
+	k := fake.NewSimpleClientset(
+		pdb("web", 0, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}),
+		pdb("db", 1, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}),
+		pdb("none", 0, nil),
+	)
+	h := &Helper{Client: k}
+	blocked, err := h.GetBlockingDisruptionBudgets([]corev1.Pod{
+		pod("web-1", map[string]string{"app": "web"}),
+		pod("db-1", map[string]string{"app": "db"}),
+		pod("other", nil),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{"default/web-1": "web"}
+	if !reflect.DeepEqual(expected, blocked) {
+		t.Errorf("expected %v, got %v", expected, blocked)
+	}
+
+	budgets, err := h.GetDisruptionBudgets([]corev1.Pod{
+		pod("web-1", map[string]string{"app": "web"}),
+		pod("db-1", map[string]string{"app": "db"}),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := map[string]sets.Set[string]{"default/web-1": sets.New("default/web"), "default/db-1": sets.New("default/db")}; !reflect.DeepEqual(expected, budgets) {
+		t.Errorf("expected %v, got %v", expected, budgets)
+	}
+}
+
 func TestDeleteOrEvict(t *testing.T) {
 	tests := []struct {
 		description       string
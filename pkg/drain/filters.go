@@ -59,6 +59,11 @@ func (l *PodDeleteList) Pods() []corev1.Pod {
 	return pods
 }
 
+// Items returns every pod on the node along with the result of filtering it.
+func (l *PodDeleteList) Items() []PodDelete {
+	return l.items
+}
+
 // Warnings returns all warning messages concatenated into a string.
 func (l *PodDeleteList) Warnings() string {
 	ps := make(map[string][]string)
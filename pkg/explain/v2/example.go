@@ -0,0 +1,231 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/openapi"
+	"sigs.k8s.io/yaml"
+)
+
+// exampleDepth is the number of levels of nested objects rendered by
+// PrintExample. Deeper objects are rendered empty.
+const exampleDepth = 3
+
+const objectMetaRef = "#/components/schemas/io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"
+
+// PrintExample prints a skeleton YAML of the resource, or of the field at
+// fieldsPath. Fields are set to their default value, to the first of their
+// enum values or to the zero value of their type. The status of the resource
+// is left out, and so are the fields of the metadata but the name.
+func PrintExample(
+	fieldsPath []string,
+	w io.Writer,
+	client openapi.Client,
+	gvr schema.GroupVersionResource,
+) error {
+	document, err := fetchDocument(client, gvr)
+	if err != nil {
+		return err
+	}
+	gvk, resource, err := resourceSchema(document, gvr)
+	if err != nil {
+		return err
+	}
+	field, err := fieldSchema(document, resource, fieldsPath)
+	if err != nil {
+		return err
+	}
+
+	e := &exampleWriter{document: document}
+	if len(fieldsPath) == 0 {
+		e.root = map[string]string{"apiVersion": gvk.GroupVersion().String(), "kind": gvk.Kind}
+		e.writeObject(resource, "", "", 0, sets.New[string]())
+	} else {
+		e.writeField(fieldsPath[len(fieldsPath)-1], field, "", "", 0, false, sets.New[string]())
+	}
+	_, err = w.Write(e.buf.Bytes())
+	return err
+}
+
+type exampleWriter struct {
+	document map[string]any
+	// root holds the values of the fields of the resource set from its GVK
+	root map[string]string
+	buf  bytes.Buffer
+}
+
+// writeObject writes the fields of the object schema, the first one with the
+// firstIndent and the following ones with the indent, so that an object can
+// be written as an array item.
+func (e *exampleWriter) writeObject(s map[string]any, firstIndent, indent string, depth int, history sets.Set[string]) {
+	properties, _ := s["properties"].(map[string]any)
+	required := sets.New[string]()
+	if names, ok := s["required"].([]any); ok {
+		for _, name := range names {
+			if name, ok := name.(string); ok {
+				required.Insert(name)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		if depth == 0 && e.root != nil && name == "status" {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		property, ok := properties[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		prefix := indent
+		if i == 0 {
+			prefix = firstIndent
+		}
+		if depth == 0 && e.root != nil && len(e.root[name]) > 0 {
+			fmt.Fprintf(&e.buf, "%s%s: %s\n", prefix, name, e.root[name])
+			continue
+		}
+		e.writeField(name, property, prefix, indent, depth+1, required.Has(name), history)
+	}
+}
+
+// writeField writes the field with its value, or with its nested fields when
+// it is an object or an array of objects.
+func (e *exampleWriter) writeField(name string, field map[string]any, prefix, indent string, depth int, required bool, history sets.Set[string]) {
+	resolved, ref := resolveSchema(e.document, field)
+	if ref == objectMetaRef {
+		fmt.Fprintf(&e.buf, "%s%s:\n%s  name: \"\"\n", prefix, name, indent)
+		return
+	}
+
+	if items, ok := resolved["items"].(map[string]any); ok {
+		item, itemRef := resolveSchema(e.document, items)
+		if _, isObject := item["properties"].(map[string]any); isObject && depth < exampleDepth && !history.Has(itemRef) {
+			fmt.Fprintf(&e.buf, "%s%s:%s\n", prefix, name, comment(required, nil))
+			e.writeObject(item, indent+"- ", indent+"  ", depth, withRef(history, itemRef))
+			return
+		}
+		fmt.Fprintf(&e.buf, "%s%s: []%s\n", prefix, name, comment(required, nil))
+		return
+	}
+
+	if _, isObject := resolved["properties"].(map[string]any); isObject {
+		if depth < exampleDepth && !history.Has(ref) {
+			fmt.Fprintf(&e.buf, "%s%s:%s\n", prefix, name, comment(required, nil))
+			e.writeObject(resolved, indent+"  ", indent+"  ", depth, withRef(history, ref))
+			return
+		}
+		fmt.Fprintf(&e.buf, "%s%s: {}%s\n", prefix, name, comment(required, nil))
+		return
+	}
+
+	fmt.Fprintf(&e.buf, "%s%s: ", prefix, name)
+	e.writeValue(field, resolved, required)
+}
+
+// writeValue writes the example value of a scalar field, followed by a
+// comment listing its enum values. The default and enum values of the field
+// take precedence over the ones of the schema it refers to.
+func (e *exampleWriter) writeValue(field, resolved map[string]any, required bool) {
+	var value any
+	enum, _ := field["enum"].([]any)
+	if len(enum) == 0 {
+		enum, _ = resolved["enum"].([]any)
+	}
+	if v, ok := defaultValue(field, resolved); ok {
+		value = v
+	} else if len(enum) > 0 {
+		value = enum[0]
+	} else {
+		switch t, _ := resolved["type"].(string); t {
+		case "integer", "number":
+			value = 0
+		case "boolean":
+			value = false
+		case "object":
+			value = map[string]any{}
+		case "array":
+			value = []any{}
+		default:
+			value = ""
+		}
+	}
+
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		data = []byte(`""`)
+	}
+	fmt.Fprintf(&e.buf, "%s%s\n", strings.TrimSuffix(string(data), "\n"), comment(required, enum))
+}
+
+// withRef returns a copy of the references followed so far, with ref added
+// unless the schema was inline.
+func withRef(history sets.Set[string], ref string) sets.Set[string] {
+	if len(ref) == 0 {
+		return history
+	}
+	return history.Clone().Insert(ref)
+}
+
+// defaultValue returns the default value of the field. The empty object
+// defaults generated for fields referring to a non-object schema, such as
+// IntOrString, are ignored.
+func defaultValue(field, resolved map[string]any) (any, bool) {
+	for _, s := range []map[string]any{field, resolved} {
+		v, ok := s["default"]
+		if !ok {
+			continue
+		}
+		if _, isObject := v.(map[string]any); isObject && resolved["type"] != "object" {
+			continue
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// comment returns the YAML comment noting that a field is required and
+// listing its enum values, or an empty string.
+func comment(required bool, enum []any) string {
+	notes := []string{}
+	if required {
+		notes = append(notes, "required")
+	}
+	if len(enum) > 0 {
+		values := make([]string, 0, len(enum))
+		for _, v := range enum {
+			values = append(values, fmt.Sprint(v))
+		}
+		notes = append(notes, "one of: "+strings.Join(values, ", "))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " # " + strings.Join(notes, "; ")
+}
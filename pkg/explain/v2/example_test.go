@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/openapi/openapitest"
+)
+
+func TestPrintExample(t *testing.T) {
+	client := openapitest.NewEmbeddedFileClient()
+
+	testCases := []struct {
+		name       string
+		fieldsPath []string
+		expected   string
+		err        string
+	}{
+		{
+			name: "resource",
+			expected: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ""
+spec:
+  minReadySeconds: 0
+  paused: false
+  progressDeadlineSeconds: 0
+  replicas: 0
+  revisionHistoryLimit: 0
+  selector: # required
+    matchExpressions: []
+    matchLabels: {}
+  strategy:
+    rollingUpdate: {}
+    type: ""
+  template: # required
+    metadata:
+      name: ""
+    spec: {}
+`,
+		},
+		{
+			name:       "array of objects",
+			fieldsPath: []string{"spec", "template", "spec", "containers", "ports"},
+			expected: `ports:
+- containerPort: 0 # required
+  hostIP: ""
+  hostPort: 0
+  name: ""
+  protocol: TCP
+`,
+		},
+		{
+			name:       "object with int or string fields",
+			fieldsPath: []string{"spec", "template", "spec", "containers", "livenessProbe", "tcpSocket"},
+			expected: `tcpSocket:
+  host: ""
+  port: "" # required
+`,
+		},
+		{
+			name:       "scalar",
+			fieldsPath: []string{"spec", "replicas"},
+			expected:   "replicas: 0\n",
+		},
+		{
+			name:       "unknown field",
+			fieldsPath: []string{"spec", "doesNotExist"},
+			err:        `field "doesNotExist" does not exist`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := PrintExample(tc.fieldsPath, &buf, client, deploymentsGVR)
+			if len(tc.err) > 0 {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestPrintExampleDefaultsAndEnums(t *testing.T) {
+	client := openapitest.NewFakeClient()
+	client.PathsMap["apis/example.com/v1"] = &openapitest.FakeGroupVersion{GVSpec: []byte(`{
+		"paths": {
+			"/apis/example.com/v1/namespaces/{namespace}/widgets/{name}": {
+				"get": {"x-kubernetes-group-version-kind": {"group": "example.com", "kind": "Widget", "version": "v1"}}
+			}
+		},
+		"components": {"schemas": {"com.example.v1.Widget": {
+			"type": "object",
+			"x-kubernetes-group-version-kind": [{"group": "example.com", "kind": "Widget", "version": "v1"}],
+			"properties": {
+				"apiVersion": {"type": "string"},
+				"kind": {"type": "string"},
+				"spec": {
+					"type": "object",
+					"required": ["size"],
+					"properties": {
+						"color": {"type": "string", "default": "blue"},
+						"size": {"type": "string", "enum": ["small", "large"]}
+					}
+				},
+				"status": {"type": "object", "properties": {"ready": {"type": "boolean"}}}
+			}
+		}}}
+	}`)}
+
+	var buf bytes.Buffer
+	err := PrintExample(nil, &buf, client, schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"})
+	require.NoError(t, err)
+	require.Equal(t, `apiVersion: example.com/v1
+kind: Widget
+spec:
+  color: blue
+  size: small # required; one of: small, large
+`, buf.String())
+}
@@ -57,10 +57,28 @@ func printModelDescriptionWithGenerator(
 	recursive bool,
 	outputFormat string,
 ) error {
+	parsedV3Schema, err := fetchDocument(client, gvr)
+	if err != nil {
+		return err
+	}
+
+	err = generator.Render(outputFormat, parsedV3Schema, gvr, fieldsPath, recursive, w)
+
+	explainErr := explainError("")
+	if errors.As(err, &explainErr) {
+		return explainErr
+	}
+
+	return err
+}
+
+// fetchDocument returns the parsed openapi v3 document of the group version
+// of the resource.
+func fetchDocument(client openapi.Client, gvr schema.GroupVersionResource) (map[string]interface{}, error) {
 	paths, err := client.Paths()
 
 	if err != nil {
-		return fmt.Errorf("failed to fetch list of groupVersions: %w", err)
+		return nil, fmt.Errorf("failed to fetch list of groupVersions: %w", err)
 	}
 
 	var resourcePath string
@@ -73,25 +91,17 @@ func printModelDescriptionWithGenerator(
 	gv, exists := paths[resourcePath]
 
 	if !exists {
-		return fmt.Errorf("couldn't find resource for \"%v\"", gvr)
+		return nil, fmt.Errorf("couldn't find resource for \"%v\"", gvr)
 	}
 
 	openAPISchemaBytes, err := gv.Schema(runtime.ContentTypeJSON)
 	if err != nil {
-		return fmt.Errorf("failed to fetch openapi schema for %s: %w", resourcePath, err)
+		return nil, fmt.Errorf("failed to fetch openapi schema for %s: %w", resourcePath, err)
 	}
 
 	var parsedV3Schema map[string]interface{}
 	if err := json.Unmarshal(openAPISchemaBytes, &parsedV3Schema); err != nil {
-		return fmt.Errorf("failed to parse openapi schema for %s: %w", resourcePath, err)
+		return nil, fmt.Errorf("failed to parse openapi schema for %s: %w", resourcePath, err)
 	}
-
-	err = generator.Render(outputFormat, parsedV3Schema, gvr, fieldsPath, recursive, w)
-
-	explainErr := explainError("")
-	if errors.As(err, &explainErr) {
-		return explainErr
-	}
-
-	return err
+	return parsedV3Schema, nil
 }
@@ -197,44 +197,48 @@ func WithBuiltinTemplateFuncs(tmpl *template.Template) *template.Template {
 		"mul": func(value, operand int) int {
 			return value * operand
 		},
-		"resolveRef": func(refAny any, document map[string]any) map[string]any {
-			refString, ok := refAny.(string)
-			if !ok {
-				// if passed nil, or wrong type just treat the same
-				// way as unresolved reference (makes for easier templates)
-				return nil
-			}
-
-			// Resolve field path encoded by the ref
-			ref, err := jsonreference.New(refString)
-			if err != nil {
-				// Unrecognized ref format.
-				return nil
-			}
-
-			if !ref.HasFragmentOnly {
-				// Downloading is not supported. Treat as not found
-				return nil
-			}
-
-			fragment := ref.GetURL().Fragment
-			components := strings.Split(fragment, "/")
-			cur := document
-
-			for _, k := range components {
-				if len(k) == 0 {
-					// first component is usually empty (#/components/) , etc
-					continue
-				}
-
-				next, ok := cur[k].(map[string]any)
-				if !ok {
-					return nil
-				}
-
-				cur = next
-			}
-			return cur
-		},
+		"resolveRef": resolveRef,
 	})
 }
+
+// resolveRef returns the element of the document pointed to by a local
+// reference such as #/components/schemas/io.k8s.api.core.v1.Pod, or nil.
+func resolveRef(refAny any, document map[string]any) map[string]any {
+	refString, ok := refAny.(string)
+	if !ok {
+		// if passed nil, or wrong type just treat the same
+		// way as unresolved reference (makes for easier templates)
+		return nil
+	}
+
+	// Resolve field path encoded by the ref
+	ref, err := jsonreference.New(refString)
+	if err != nil {
+		// Unrecognized ref format.
+		return nil
+	}
+
+	if !ref.HasFragmentOnly {
+		// Downloading is not supported. Treat as not found
+		return nil
+	}
+
+	fragment := ref.GetURL().Fragment
+	components := strings.Split(fragment, "/")
+	cur := document
+
+	for _, k := range components {
+		if len(k) == 0 {
+			// first component is usually empty (#/components/) , etc
+			continue
+		}
+
+		next, ok := cur[k].(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		cur = next
+	}
+	return cur
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// resourceSchema returns the GVK of the resource and its schema, found like
+// the plaintext template does: the GVK is taken from the operations on the
+// paths of the resource, and the schema is the one declaring this GVK.
+func resourceSchema(document map[string]any, gvr schema.GroupVersionResource) (schema.GroupVersionKind, map[string]any, error) {
+	prefix := "/apis/" + gvr.Group
+	if len(gvr.Group) == 0 {
+		prefix = "/api"
+	}
+	searchPaths := []string{
+		strings.Join([]string{prefix, gvr.Version, gvr.Resource}, "/"),
+		strings.Join([]string{prefix, gvr.Version, gvr.Resource, "{name}"}, "/"),
+		strings.Join([]string{prefix, gvr.Version, "namespaces", "{namespace}", gvr.Resource}, "/"),
+		strings.Join([]string{prefix, gvr.Version, "namespaces", "{namespace}", gvr.Resource, "{name}"}, "/"),
+	}
+
+	paths, _ := document["paths"].(map[string]any)
+	var gvk map[string]any
+	for _, searchPath := range searchPaths {
+		pathElement, _ := paths[searchPath].(map[string]any)
+		for _, method := range []string{"get", "post", "put", "patch", "delete"} {
+			operation, _ := pathElement[method].(map[string]any)
+			if gvk, _ = operation["x-kubernetes-group-version-kind"].(map[string]any); gvk != nil {
+				break
+			}
+		}
+		if gvk != nil {
+			break
+		}
+	}
+	if gvk == nil {
+		return schema.GroupVersionKind{}, nil, fmt.Errorf("GVR (%v) not found in OpenAPI schema", gvr)
+	}
+
+	kind := schema.GroupVersionKind{}
+	kind.Group, _ = gvk["group"].(string)
+	kind.Version, _ = gvk["version"].(string)
+	kind.Kind, _ = gvk["kind"].(string)
+
+	schemas, _ := document["components"].(map[string]any)["schemas"].(map[string]any)
+	for _, s := range schemas {
+		s, _ := s.(map[string]any)
+		gvks, _ := s["x-kubernetes-group-version-kind"].([]any)
+		for _, candidate := range gvks {
+			if reflect.DeepEqual(candidate, gvk) {
+				return kind, s, nil
+			}
+		}
+	}
+	return schema.GroupVersionKind{}, nil, fmt.Errorf("GVK %v not found in OpenAPI schema", kind)
+}
+
+// resolveSchema follows the reference of the schema, and the single element
+// of its allOf when it has no properties of its own. It returns the resolved
+// schema and the reference it was found through, if any.
+func resolveSchema(document map[string]any, s map[string]any) (map[string]any, string) {
+	ref := ""
+	for {
+		if refString, ok := s["$ref"].(string); ok {
+			resolved := resolveRef(refString, document)
+			if resolved == nil {
+				return s, ref
+			}
+			s, ref = resolved, refString
+			continue
+		}
+		allOf, _ := s["allOf"].([]any)
+		if _, hasProperties := s["properties"]; len(allOf) == 1 && !hasProperties {
+			if next, ok := allOf[0].(map[string]any); ok {
+				s = next
+				continue
+			}
+		}
+		return s, ref
+	}
+}
+
+// fieldSchema follows the fields path from the schema, through the items of
+// arrays and the values of maps, and returns the schema of the last field.
+func fieldSchema(document map[string]any, s map[string]any, fieldsPath []string) (map[string]any, error) {
+	for _, field := range fieldsPath {
+		resolved, _ := resolveSchema(document, s)
+		for {
+			if items, ok := resolved["items"].(map[string]any); ok {
+				resolved, _ = resolveSchema(document, items)
+			} else if values, ok := resolved["additionalProperties"].(map[string]any); ok {
+				resolved, _ = resolveSchema(document, values)
+			} else {
+				break
+			}
+		}
+		properties, _ := resolved["properties"].(map[string]any)
+		next, ok := properties[field].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field %q does not exist", field)
+		}
+		s = next
+	}
+	return s, nil
+}
+
+// typeName returns the name of the type of the schema, as shown by the
+// plaintext template.
+func typeName(document map[string]any, s map[string]any) string {
+	if items, ok := s["items"].(map[string]any); ok {
+		return "[]" + typeName(document, items)
+	}
+	if values, ok := s["additionalProperties"].(map[string]any); ok {
+		return "map[string]" + typeName(document, values)
+	}
+	allOf, _ := s["allOf"].([]any)
+	if _, hasProperties := s["properties"]; len(allOf) == 1 && !hasProperties {
+		if next, ok := allOf[0].(map[string]any); ok {
+			return typeName(document, next)
+		}
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		refSchema := resolveRef(ref, document)
+		if refType, _ := refSchema["type"].(string); len(refType) > 0 && refType != "object" {
+			return refType
+		}
+		name := ref[strings.LastIndex(ref, "/")+1:]
+		return name[strings.LastIndex(name, ".")+1:]
+	}
+	if t, _ := s["type"].(string); len(t) > 0 && t != "object" {
+		return t
+	}
+	return "Object"
+}
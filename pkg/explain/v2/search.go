@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/openapi"
+)
+
+// SearchFields prints the path and the type of all the fields nested within
+// the resource, or within the field at fieldsPath, whose name matches the
+// pattern.
+func SearchFields(
+	fieldsPath []string,
+	w io.Writer,
+	client openapi.Client,
+	gvr schema.GroupVersionResource,
+	pattern *regexp.Regexp,
+) error {
+	document, err := fetchDocument(client, gvr)
+	if err != nil {
+		return err
+	}
+	_, resource, err := resourceSchema(document, gvr)
+	if err != nil {
+		return err
+	}
+	root, err := fieldSchema(document, resource, fieldsPath)
+	if err != nil {
+		return err
+	}
+
+	s := &fieldSearch{document: document, pattern: pattern}
+	s.walk(root, append([]string{gvr.Resource}, fieldsPath...), sets.New[string]())
+	if len(s.matches) == 0 {
+		return fmt.Errorf("no field of %s matches %q", strings.Join(append([]string{gvr.Resource}, fieldsPath...), "."), pattern)
+	}
+
+	tw := printers.GetNewTabWriter(w)
+	for _, match := range s.matches {
+		fmt.Fprintf(tw, "%s\t<%s>\n", match[0], match[1])
+	}
+	return tw.Flush()
+}
+
+type fieldSearch struct {
+	document map[string]any
+	pattern  *regexp.Regexp
+	// matches holds the path and the type name of the matching fields
+	matches [][2]string
+}
+
+// walk visits the fields of the schema depth first. The references already
+// followed on the way to the schema are skipped, to stop at cycles.
+func (s *fieldSearch) walk(field map[string]any, path []string, history sets.Set[string]) {
+	resolved, ref := resolveSchema(s.document, field)
+	if len(ref) > 0 {
+		if history.Has(ref) {
+			return
+		}
+		history = history.Clone().Insert(ref)
+	}
+
+	properties, _ := resolved["properties"].(map[string]any)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := properties[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		fieldPath := append(append([]string{}, path...), name)
+		if s.pattern.MatchString(name) {
+			s.matches = append(s.matches, [2]string{strings.Join(fieldPath, "."), typeName(s.document, property)})
+		}
+		s.walk(property, fieldPath, history)
+	}
+
+	if items, ok := resolved["items"].(map[string]any); ok {
+		s.walk(items, path, history)
+	}
+	if values, ok := resolved["additionalProperties"].(map[string]any); ok {
+		s.walk(values, path, history)
+	}
+	allOf, _ := resolved["allOf"].([]any)
+	for _, alternative := range allOf {
+		if alternative, ok := alternative.(map[string]any); ok {
+			s.walk(alternative, path, history)
+		}
+	}
+}
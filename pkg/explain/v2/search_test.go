@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/openapi/openapitest"
+)
+
+var deploymentsGVR = schema.GroupVersionResource{
+	Group:    "apps",
+	Version:  "v1",
+	Resource: "deployments",
+}
+
+func TestSearchFields(t *testing.T) {
+	client := openapitest.NewEmbeddedFileClient()
+
+	testCases := []struct {
+		name       string
+		fieldsPath []string
+		pattern    string
+		expected   string
+		err        string
+	}{
+		{
+			name:       "field name",
+			fieldsPath: []string{"spec", "template", "spec"},
+			pattern:    "(?i)^(?:image)$",
+			expected: `deployments.spec.template.spec.containers.image            <string>
+deployments.spec.template.spec.ephemeralContainers.image   <string>
+deployments.spec.template.spec.initContainers.image        <string>
+deployments.spec.template.spec.volumes.rbd.image           <string>
+`,
+		},
+		{
+			name:    "regular expression",
+			pattern: "^replicas|minReadySeconds$",
+			expected: `deployments.spec.minReadySeconds   <integer>
+deployments.spec.replicas          <integer>
+deployments.status.replicas        <integer>
+`,
+		},
+		{
+			name:    "object types",
+			pattern: "^(strategy|rollingUpdate)$",
+			expected: `deployments.spec.strategy                 <DeploymentStrategy>
+deployments.spec.strategy.rollingUpdate   <RollingUpdateDeployment>
+`,
+		},
+		{
+			name:    "no match",
+			pattern: "^doesNotExist$",
+			err:     `no field of deployments matches "^doesNotExist$"`,
+		},
+		{
+			name:       "unknown field",
+			fieldsPath: []string{"spec", "doesNotExist"},
+			pattern:    "^name$",
+			err:        `field "doesNotExist" does not exist`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := SearchFields(tc.fieldsPath, &buf, client, deploymentsGVR, regexp.MustCompile(tc.pattern))
+			if len(tc.err) > 0 {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
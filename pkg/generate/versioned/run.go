@@ -128,6 +128,79 @@ func getEnvs(genericParams map[string]interface{}) ([]v1.EnvVar, error) {
 	return envs, nil
 }
 
+// getExtraContainers returns the containers described by the []string param
+// with the given key. Each description is either an image, or comma separated
+// name, image and command keys such as "name=proxy,image=envoy,command=envoy -c cfg".
+// The command key has to come last, as it extends to the end of the description.
+// Containers without a name are named after the pod, the kind and their position.
+func getExtraContainers(genericParams map[string]interface{}, key, podName, kind string) ([]v1.Container, error) {
+	specs, found := genericParams[key]
+	if !found {
+		return nil, nil
+	}
+	specArray, isArray := specs.([]string)
+	if !isArray {
+		return nil, fmt.Errorf("expected []string, found: %v", specs)
+	}
+	delete(genericParams, key)
+
+	var containers []v1.Container
+	for i, spec := range specArray {
+		container, err := parseExtraContainer(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", key, spec, err)
+		}
+		if len(container.Name) == 0 {
+			container.Name = fmt.Sprintf("%s-%s-%d", podName, kind, i)
+		}
+		if errs := validation.IsDNS1123Label(container.Name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %s %q: container name %q is invalid: %s", key, spec, container.Name, strings.Join(errs, "; "))
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+func parseExtraContainer(spec string) (v1.Container, error) {
+	container := v1.Container{}
+	if !strings.Contains(spec, "=") {
+		container.Image = spec
+		return container, nil
+	}
+	rest := spec
+	for len(rest) > 0 {
+		if strings.HasPrefix(rest, "command=") {
+			container.Command = strings.Fields(strings.TrimPrefix(rest, "command="))
+			break
+		}
+		field := rest
+		rest = ""
+		if i := strings.Index(field, ","); i >= 0 {
+			field, rest = field[:i], field[i+1:]
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return container, fmt.Errorf("%q should be in format key=value", field)
+		}
+		switch key {
+		case "name":
+			container.Name = value
+		case "image":
+			container.Image = value
+		default:
+			return container, fmt.Errorf("unknown key %q, must be one of name, image, command", key)
+		}
+	}
+	if len(container.Image) == 0 {
+		// "name=busybox" is a common shorthand for the image to run
+		container.Image = container.Name
+	}
+	if len(container.Image) == 0 {
+		return container, fmt.Errorf("an image is required")
+	}
+	return container, nil
+}
+
 // populateResourceListV1 takes strings of form <resourceName1>=<value1>,<resourceName1>=<value2>
 // and returns ResourceList.
 func populateResourceListV1(spec string) (v1.ResourceList, error) {
@@ -253,6 +326,9 @@ func (BasicPod) ParamNames() []generate.GeneratorParam {
 		{Name: "limits", Required: false},
 		{Name: "serviceaccount", Required: false},
 		{Name: "privileged", Required: false},
+		{Name: "init-image", Required: false},
+		{Name: "sidecar-image", Required: false},
+		{Name: "share-process-namespace", Required: false},
 	}
 }
 
@@ -272,6 +348,22 @@ func (BasicPod) Generate(genericParams map[string]interface{}) (runtime.Object,
 		return nil, err
 	}
 
+	podName, _ := genericParams["name"].(string)
+	initContainers, err := getExtraContainers(genericParams, "init-image", podName, "init")
+	if err != nil {
+		return nil, err
+	}
+	sidecars, err := getExtraContainers(genericParams, "sidecar-image", podName, "sidecar")
+	if err != nil {
+		return nil, err
+	}
+	// Sidecars are init containers that keep running alongside the main container.
+	for i := range sidecars {
+		restartPolicy := v1.ContainerRestartPolicyAlways
+		sidecars[i].RestartPolicy = &restartPolicy
+	}
+	initContainers = append(initContainers, sidecars...)
+
 	params, err := getParams(genericParams)
 	if err != nil {
 		return nil, err
@@ -322,6 +414,11 @@ func (BasicPod) Generate(genericParams map[string]interface{}) (runtime.Object,
 		}
 	}
 
+	shareProcessNamespace, err := generate.GetBool(params, "share-process-namespace", false)
+	if err != nil {
+		return nil, err
+	}
+
 	pod := v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        name,
@@ -330,6 +427,7 @@ func (BasicPod) Generate(genericParams map[string]interface{}) (runtime.Object,
 		},
 		Spec: v1.PodSpec{
 			ServiceAccountName: params["serviceaccount"],
+			InitContainers:     initContainers,
 			Containers: []v1.Container{
 				{
 					Name:            name,
@@ -345,6 +443,16 @@ func (BasicPod) Generate(genericParams map[string]interface{}) (runtime.Object,
 			RestartPolicy: restartPolicy,
 		},
 	}
+	if shareProcessNamespace {
+		pod.Spec.ShareProcessNamespace = &shareProcessNamespace
+	}
+	containerNames := map[string]bool{name: true}
+	for _, container := range initContainers {
+		if containerNames[container.Name] {
+			return nil, fmt.Errorf("container name %q is used more than once", container.Name)
+		}
+		containerNames[container.Name] = true
+	}
 	imagePullPolicy := v1.PullPolicy(params["image-pull-policy"])
 	if err = updatePodContainers(params, args, envs, imagePullPolicy, &pod.Spec); err != nil {
 		return nil, err
@@ -25,6 +25,8 @@ import (
 )
 
 func TestGeneratePod(t *testing.T) {
+	containerRestartPolicyAlways := v1.ContainerRestartPolicyAlways
+	shareProcessNamespace := true
 	tests := []struct {
 		name      string
 		params    map[string]interface{}
@@ -307,6 +309,68 @@ func TestGeneratePod(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "test12: check init containers, sidecars and shared process namespace",
+			params: map[string]interface{}{
+				"name":                    "foo",
+				"image":                   "someimage",
+				"init-image":              []string{"busybox", "name=wait,image=busybox:1.36,command=sh -c until nc -z db 5432; do sleep 1; done"},
+				"sidecar-image":           []string{"name=busybox,command=sleep infinity"},
+				"share-process-namespace": "true",
+			},
+			expected: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "foo",
+					Labels: map[string]string{"run": "foo"},
+				},
+				Spec: v1.PodSpec{
+					InitContainers: []v1.Container{
+						{
+							Name:  "foo-init-0",
+							Image: "busybox",
+						},
+						{
+							Name:    "wait",
+							Image:   "busybox:1.36",
+							Command: []string{"sh", "-c", "until", "nc", "-z", "db", "5432;", "do", "sleep", "1;", "done"},
+						},
+						{
+							Name:          "busybox",
+							Image:         "busybox",
+							Command:       []string{"sleep", "infinity"},
+							RestartPolicy: &containerRestartPolicyAlways,
+						},
+					},
+					Containers: []v1.Container{
+						{
+							Name:  "foo",
+							Image: "someimage",
+						},
+					},
+					DNSPolicy:             v1.DNSClusterFirst,
+					RestartPolicy:         v1.RestartPolicyAlways,
+					ShareProcessNamespace: &shareProcessNamespace,
+				},
+			},
+		},
+		{
+			name: "test13: check duplicate container names",
+			params: map[string]interface{}{
+				"name":          "foo",
+				"image":         "someimage",
+				"sidecar-image": []string{"name=foo,image=busybox"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "test14: check invalid sidecar",
+			params: map[string]interface{}{
+				"name":          "foo",
+				"image":         "someimage",
+				"sidecar-image": []string{"image=busybox,tag=latest"},
+			},
+			expectErr: true,
+		},
 	}
 	generator := BasicPod{}
 	for _, tt := range tests {
@@ -0,0 +1,686 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package localcluster serves the objects of a directory of manifests, or of
+// a cluster dump, with a minimal implementation of the Kubernetes API, so
+// that kubectl can operate on them without a cluster.
+package localcluster
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	jsonpatch "github.com/evanphx/json-patch"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/pkg/version"
+)
+
+const defaultNamespace = metav1.NamespaceDefault
+
+var crdGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+
+// errReadOnly is the reason changes are refused when Write is not set.
+var errReadOnly = errors.New("the manifests of the local cluster are read-only, only dry runs are allowed")
+
+// Cluster serves the objects of a directory with the Kubernetes API. It
+// supports discovery, getting and listing objects with label and field
+// selectors, and creating, updating, patching and deleting objects. The
+// manifest files are only changed when Write is set, otherwise only dry runs
+// of changes are allowed. New objects are written to files named after their
+// namespace, kind and name. Watches, subresources and server-side apply are
+// not supported.
+//
+// Namespaced objects without a namespace are in the default namespace. The
+// namespaces of the objects are served even when the directory does not
+// define them.
+type Cluster struct {
+	// Write allows the changes to the objects to be written to the manifest
+	// files.
+	Write bool
+
+	dir string
+
+	lock            sync.Mutex
+	files           map[string]*manifestFile
+	objects         map[objectKey]*object
+	resourceVersion int64
+	builtin         []resourceInfo
+}
+
+var _ http.RoundTripper = &Cluster{}
+
+// New loads the objects of the directory.
+func New(dir string) (*Cluster, error) {
+	files, err := loadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cluster{
+		dir:     dir,
+		files:   map[string]*manifestFile{},
+		objects: map[objectKey]*object{},
+		builtin: builtinResources(),
+	}
+	for _, file := range files {
+		c.files[file.path] = file
+		for _, doc := range file.documents {
+			for _, obj := range doc.objects {
+				key := keyOf(obj.content)
+				if existing, ok := c.objects[key]; ok {
+					return nil, fmt.Errorf("%s is defined in both %s and %s", key, existing.file.path, file.path)
+				}
+				c.add(key, obj)
+			}
+		}
+	}
+
+	// namespaced objects without a namespace are in the default namespace
+	namespaced := c.namespacedKinds()
+	for key, obj := range c.objects {
+		if len(key.Namespace) == 0 && namespaced.Has(key.GroupKind) {
+			delete(c.objects, key)
+			key.Namespace = defaultNamespace
+			if existing, ok := c.objects[key]; ok {
+				return nil, fmt.Errorf("%s is defined in both %s and %s", key, existing.file.path, obj.file.path)
+			}
+			c.objects[key] = obj
+		}
+	}
+	return c, nil
+}
+
+// RoundTrip serves the request, without any network access.
+func (c *Cluster) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	c.ServeHTTP(recorder, req)
+	resp := recorder.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// ServeHTTP serves the Kubernetes API.
+func (c *Cluster) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case req.URL.Path == "/version":
+		writeJSON(w, http.StatusOK, version.Get())
+	case req.URL.Path == "/openapi/v2":
+		// an empty document, for the schemas to be unknown and the objects
+		// not to be validated
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.WriteHeader(http.StatusOK)
+	case req.URL.Path == "/api":
+		writeJSON(w, http.StatusOK, &metav1.APIVersions{
+			TypeMeta: metav1.TypeMeta{Kind: "APIVersions"},
+			Versions: []string{"v1"},
+		})
+	case req.URL.Path == "/apis":
+		writeJSON(w, http.StatusOK, c.apiGroupList())
+	case parts[0] == "api" && len(parts) == 2:
+		writeJSON(w, http.StatusOK, c.apiResourceList(schema.GroupVersion{Version: parts[1]}))
+	case parts[0] == "apis" && len(parts) == 3:
+		writeJSON(w, http.StatusOK, c.apiResourceList(schema.GroupVersion{Group: parts[1], Version: parts[2]}))
+	case parts[0] == "api" && len(parts) > 2:
+		c.serveResource(w, req, schema.GroupVersion{Version: parts[1]}, parts[2:])
+	case parts[0] == "apis" && len(parts) > 3:
+		c.serveResource(w, req, schema.GroupVersion{Group: parts[1], Version: parts[2]}, parts[3:])
+	default:
+		writeError(w, apierrors.NewGenericServerResponse(http.StatusNotFound, req.Method, schema.GroupResource{}, "", fmt.Sprintf("the local cluster does not serve %s", req.URL.Path), 0, false))
+	}
+}
+
+// resources returns the resources served by the cluster: the built-in ones,
+// the custom resources of the custom resource definitions, and a guess for
+// the kinds of the other objects.
+func (c *Cluster) resources() []resourceInfo {
+	crds := []*unstructured.Unstructured{}
+	for key, obj := range c.objects {
+		if key.GroupKind == crdGroupKind {
+			crds = append(crds, obj.content)
+		}
+	}
+	resources := append(append([]resourceInfo{}, c.builtin...), customResources(crds)...)
+
+	known := sets.New[schema.GroupKind]()
+	for _, r := range resources {
+		known.Insert(schema.GroupKind{Group: r.Group, Kind: r.Kind})
+	}
+	guessed := map[schema.GroupKind]*resourceInfo{}
+	for key, obj := range c.objects {
+		if known.Has(key.GroupKind) {
+			continue
+		}
+		r, ok := guessed[key.GroupKind]
+		if !ok {
+			r = &resourceInfo{Kind: key.Kind}
+			r.GroupVersionResource, _ = meta.UnsafeGuessKindToResource(obj.content.GroupVersionKind())
+			guessed[key.GroupKind] = r
+		}
+		// the kinds of the objects with a namespace are namespaced
+		r.Namespaced = r.Namespaced || len(obj.content.GetNamespace()) > 0
+	}
+	for _, r := range guessed {
+		resources = append(resources, *r)
+	}
+	return resources
+}
+
+func (c *Cluster) namespacedKinds() sets.Set[schema.GroupKind] {
+	namespaced := sets.New[schema.GroupKind]()
+	for _, r := range c.resources() {
+		if r.Namespaced {
+			namespaced.Insert(schema.GroupKind{Group: r.Group, Kind: r.Kind})
+		}
+	}
+	return namespaced
+}
+
+func (c *Cluster) apiGroupList() *metav1.APIGroupList {
+	versions := map[string]sets.Set[string]{}
+	for _, r := range c.resources() {
+		if len(r.Group) == 0 {
+			continue
+		}
+		if versions[r.Group] == nil {
+			versions[r.Group] = sets.New[string]()
+		}
+		versions[r.Group].Insert(r.Version)
+	}
+
+	groups := sets.List(sets.KeySet(versions))
+	// the groups of the built-in resources without a domain come first, for
+	// the resources in several groups to map to them
+	sort.SliceStable(groups, func(i, j int) bool {
+		return !strings.Contains(groups[i], ".") && strings.Contains(groups[j], ".")
+	})
+	list := &metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"}}
+	for _, group := range groups {
+		apiGroup := metav1.APIGroup{Name: group}
+		groupVersions := sets.List(versions[group])
+		preferred := preferredVersion(groupVersions)
+		for _, v := range append([]string{preferred}, groupVersions...) {
+			gv := metav1.GroupVersionForDiscovery{GroupVersion: group + "/" + v, Version: v}
+			if v == preferred && len(apiGroup.Versions) > 0 {
+				continue
+			}
+			apiGroup.Versions = append(apiGroup.Versions, gv)
+		}
+		apiGroup.PreferredVersion = apiGroup.Versions[0]
+		list.Groups = append(list.Groups, apiGroup)
+	}
+	return list
+}
+
+func (c *Cluster) apiResourceList(gv schema.GroupVersion) *metav1.APIResourceList {
+	list := &metav1.APIResourceList{
+		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList", APIVersion: "v1"},
+		GroupVersion: gv.String(),
+		APIResources: []metav1.APIResource{},
+	}
+	for _, r := range c.resources() {
+		if r.GroupVersion() != gv {
+			continue
+		}
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:         r.Resource,
+			SingularName: strings.ToLower(r.Kind),
+			Namespaced:   r.Namespaced,
+			Kind:         r.Kind,
+			Verbs:        metav1.Verbs{"create", "delete", "get", "list", "patch", "update"},
+			ShortNames:   r.ShortNames,
+			Categories:   r.Categories,
+		})
+	}
+	sort.Slice(list.APIResources, func(i, j int) bool {
+		return list.APIResources[i].Name < list.APIResources[j].Name
+	})
+	return list
+}
+
+// request is a request for objects of a resource.
+type request struct {
+	resourceInfo
+	namespace string
+	name      string
+	dryRun    bool
+}
+
+func (c *Cluster) serveResource(w http.ResponseWriter, req *http.Request, gv schema.GroupVersion, parts []string) {
+	r := request{}
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		r.namespace, parts = parts[1], parts[2:]
+	}
+	found := false
+	for _, info := range c.resources() {
+		if info.Group == gv.Group && info.Resource == parts[0] {
+			r.resourceInfo, found = info, true
+			break
+		}
+	}
+	gr := schema.GroupResource{Group: gv.Group, Resource: parts[0]}
+	if !found || (len(r.namespace) > 0 && !r.Namespaced) {
+		writeError(w, apierrors.NewNotFound(gr, ""))
+		return
+	}
+	// the objects are served in the requested version, without conversion
+	r.Version = gv.Version
+	if len(parts) > 1 {
+		r.name = parts[1]
+	}
+	if len(parts) > 2 {
+		writeError(w, apierrors.NewNotFound(gr, strings.Join(parts[1:], "/")))
+		return
+	}
+	r.dryRun = len(req.URL.Query()["dryRun"]) > 0
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		if !r.dryRun && !c.Write {
+			writeError(w, apierrors.NewForbidden(gr, r.name, errReadOnly))
+			return
+		}
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			writeError(w, apierrors.NewBadRequest(err.Error()))
+			return
+		}
+	}
+
+	var obj interface{}
+	var err error
+	status := http.StatusOK
+	switch {
+	case req.URL.Query().Get("watch") == "true" || req.URL.Query().Get("watch") == "1":
+		err = apierrors.NewMethodNotSupported(gr, "watch")
+	case req.Method == http.MethodGet && len(r.name) == 0:
+		obj, err = c.list(r, req.URL.Query().Get("labelSelector"), req.URL.Query().Get("fieldSelector"))
+	case req.Method == http.MethodGet:
+		obj, err = c.get(r)
+	case req.Method == http.MethodPost && len(r.name) == 0:
+		obj, err = c.create(r, body)
+		status = http.StatusCreated
+	case req.Method == http.MethodPut && len(r.name) > 0:
+		obj, err = c.update(r, body)
+	case req.Method == http.MethodPatch && len(r.name) > 0:
+		obj, err = c.patch(r, types.PatchType(req.Header.Get("Content-Type")), body)
+	case req.Method == http.MethodDelete && len(r.name) > 0:
+		obj, err = c.delete(r)
+	default:
+		err = apierrors.NewMethodNotSupported(gr, strings.ToLower(req.Method))
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, status, obj)
+}
+
+func (c *Cluster) key(r request, name string) objectKey {
+	key := objectKey{GroupKind: schema.GroupKind{Group: r.Group, Kind: r.Kind}, Name: name}
+	if r.Namespaced {
+		key.Namespace = r.namespace
+	}
+	return key
+}
+
+func (c *Cluster) list(r request, labelSelector, fieldSelector string) (*unstructured.UnstructuredList, error) {
+	labelSel, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	fieldSel, err := fields.ParseSelector(fieldSelector)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetAPIVersion(r.GroupVersion().String())
+	list.SetKind(r.Kind + "List")
+	list.SetResourceVersion(strconv.FormatInt(c.resourceVersion, 10))
+	for _, u := range c.objectsOf(r) {
+		if (len(r.namespace) > 0 && u.GetNamespace() != r.namespace) || !labelSel.Matches(labels.Set(u.GetLabels())) || !matchesFields(u, fieldSel) {
+			continue
+		}
+		list.Items = append(list.Items, *u)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		a, b := list.Items[i], list.Items[j]
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	})
+	return list, nil
+}
+
+func (c *Cluster) get(r request) (*unstructured.Unstructured, error) {
+	key := c.key(r, r.name)
+	if obj, ok := c.objects[key]; ok {
+		return c.serve(r, key, obj), nil
+	}
+	if key.GroupKind == (schema.GroupKind{Kind: "Namespace"}) {
+		for _, u := range c.objectsOf(r) {
+			if u.GetName() == r.name {
+				return u, nil
+			}
+		}
+	}
+	return nil, apierrors.NewNotFound(r.GroupResource(), r.name)
+}
+
+// objectsOf returns the objects of the resource, in the version of the
+// request. The namespaces of the objects are served as namespaces.
+func (c *Cluster) objectsOf(r request) []*unstructured.Unstructured {
+	groupKind := schema.GroupKind{Group: r.Group, Kind: r.Kind}
+	objects := []*unstructured.Unstructured{}
+	namespaces := sets.New(defaultNamespace)
+	for key, obj := range c.objects {
+		namespaces.Insert(key.Namespace)
+		if key.GroupKind == groupKind {
+			objects = append(objects, c.serve(r, key, obj))
+		}
+	}
+	if groupKind != (schema.GroupKind{Kind: "Namespace"}) {
+		return objects
+	}
+	for _, u := range objects {
+		namespaces.Delete(u.GetName())
+	}
+	namespaces.Delete("")
+	for _, name := range sets.List(namespaces) {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion("v1")
+		u.SetKind("Namespace")
+		u.SetName(name)
+		u.SetUID(types.UID("namespace-" + name))
+		u.SetResourceVersion("0")
+		_ = unstructured.SetNestedField(u.Object, "Active", "status", "phase")
+		objects = append(objects, u)
+	}
+	return objects
+}
+
+// serve returns the object as it is served: in the version of the request,
+// in its namespace, with a uid and a resource version.
+func (c *Cluster) serve(r request, key objectKey, obj *object) *unstructured.Unstructured {
+	u := obj.content.DeepCopy()
+	u.SetAPIVersion(r.GroupVersion().String())
+	u.SetNamespace(key.Namespace)
+	if len(u.GetUID()) == 0 {
+		u.SetUID(types.UID(obj.uid))
+	}
+	u.SetResourceVersion(strconv.FormatInt(obj.resourceVersion, 10))
+	return u
+}
+
+func (c *Cluster) create(r request, body []byte) (*unstructured.Unstructured, error) {
+	u, err := c.decode(r, body)
+	if err != nil {
+		return nil, err
+	}
+	if len(u.GetName()) == 0 && len(u.GetGenerateName()) > 0 {
+		u.SetName(u.GetGenerateName() + string(uuid.NewUUID())[:5])
+	}
+	if len(u.GetName()) == 0 {
+		return nil, apierrors.NewBadRequest("the name of the object is required")
+	}
+	key := c.key(r, u.GetName())
+	if _, ok := c.objects[key]; ok {
+		return nil, apierrors.NewAlreadyExists(r.GroupResource(), u.GetName())
+	}
+
+	u.SetResourceVersion("")
+	u.SetUID("")
+	obj := &object{content: u}
+	if r.dryRun {
+		obj.uid = string(uuid.NewUUID())
+		obj.resourceVersion = c.resourceVersion + 1
+		return c.serve(r, key, obj), nil
+	}
+
+	name := strings.ToLower(r.Kind) + "-" + u.GetName() + ".yaml"
+	path := filepath.Join(c.dir, key.Namespace, name)
+	file, ok := c.files[path]
+	if !ok {
+		file = &manifestFile{path: path}
+		c.files[path] = file
+	}
+	obj.file = file
+	file.documents = append(file.documents, &document{objects: []*object{obj}})
+	c.add(key, obj)
+	if err := file.save(); err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	return c.serve(r, key, obj), nil
+}
+
+func (c *Cluster) update(r request, body []byte) (*unstructured.Unstructured, error) {
+	key := c.key(r, r.name)
+	obj, ok := c.objects[key]
+	if !ok {
+		return nil, apierrors.NewNotFound(r.GroupResource(), r.name)
+	}
+	u, err := c.decode(r, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.replace(r, key, obj, u)
+}
+
+func (c *Cluster) patch(r request, patchType types.PatchType, patch []byte) (*unstructured.Unstructured, error) {
+	key := c.key(r, r.name)
+	obj, ok := c.objects[key]
+	if !ok {
+		return nil, apierrors.NewNotFound(r.GroupResource(), r.name)
+	}
+	original, err := json.Marshal(c.serve(r, key, obj).Object)
+	if err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+
+	var patched []byte
+	switch patchType {
+	case types.JSONPatchType:
+		var p jsonpatch.Patch
+		if p, err = jsonpatch.DecodePatch(patch); err == nil {
+			patched, err = p.Apply(original)
+		}
+	case types.MergePatchType:
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case types.StrategicMergePatchType:
+		typed, newErr := scheme.Scheme.New(r.GroupVersion().WithKind(r.Kind))
+		if newErr != nil {
+			return nil, unsupportedPatchType(r, patchType)
+		}
+		patched, err = strategicpatch.StrategicMergePatch(original, patch, typed)
+	default:
+		return nil, unsupportedPatchType(r, patchType)
+	}
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("the patch could not be applied: %v", err))
+	}
+	u, err := c.decode(r, patched)
+	if err != nil {
+		return nil, err
+	}
+	return c.replace(r, key, obj, u)
+}
+
+func unsupportedPatchType(r request, patchType types.PatchType) error {
+	return apierrors.NewGenericServerResponse(http.StatusUnsupportedMediaType, "patch", r.GroupResource(), r.name, fmt.Sprintf("the local cluster does not support %s patches of %s", patchType, r.GroupResource()), 0, false)
+}
+
+// replace replaces the content of the object, unless the request is a dry
+// run.
+func (c *Cluster) replace(r request, key objectKey, obj *object, u *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	if u.GetName() != key.Name {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("the name of the object must be %s", key.Name))
+	}
+	if rv := u.GetResourceVersion(); len(rv) > 0 && rv != strconv.FormatInt(obj.resourceVersion, 10) {
+		return nil, apierrors.NewConflict(r.GroupResource(), key.Name, fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+	}
+
+	// the fields set by the cluster are not written to the manifests
+	u.SetResourceVersion("")
+	if len(obj.content.GetUID()) == 0 {
+		u.SetUID("")
+	}
+	if len(obj.content.GetNamespace()) == 0 {
+		u.SetNamespace("")
+	}
+	updated := &object{content: u, file: obj.file, uid: obj.uid, resourceVersion: c.resourceVersion + 1}
+	if r.dryRun {
+		return c.serve(r, key, updated), nil
+	}
+
+	obj.content = u
+	c.resourceVersion++
+	obj.resourceVersion = c.resourceVersion
+	if err := obj.file.save(); err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	return c.serve(r, key, obj), nil
+}
+
+func (c *Cluster) delete(r request) (*unstructured.Unstructured, error) {
+	key := c.key(r, r.name)
+	obj, ok := c.objects[key]
+	if !ok {
+		return nil, apierrors.NewNotFound(r.GroupResource(), r.name)
+	}
+	served := c.serve(r, key, obj)
+	if r.dryRun {
+		return served, nil
+	}
+
+	delete(c.objects, key)
+	obj.file.remove(obj)
+	if err := obj.file.save(); err != nil {
+		return nil, apierrors.NewInternalError(err)
+	}
+	return served, nil
+}
+
+// decode decodes an object of the resource of the request.
+func (c *Cluster) decode(r request, body []byte) (*unstructured.Unstructured, error) {
+	u := &unstructured.Unstructured{}
+	if err := json.Unmarshal(body, &u.Object); err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("the object could not be decoded: %v", err))
+	}
+	if len(u.GetAPIVersion()) == 0 {
+		u.SetAPIVersion(r.GroupVersion().String())
+	}
+	if len(u.GetKind()) == 0 {
+		u.SetKind(r.Kind)
+	}
+	if gvk := u.GroupVersionKind(); gvk.Group != r.Group || gvk.Kind != r.Kind {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("the object of kind %s can not be handled as a %s", gvk.GroupKind(), schema.GroupKind{Group: r.Group, Kind: r.Kind}))
+	}
+	if !r.Namespaced {
+		return u, nil
+	}
+	if len(u.GetNamespace()) == 0 {
+		u.SetNamespace(r.namespace)
+	}
+	if u.GetNamespace() != r.namespace {
+		return nil, apierrors.NewBadRequest("the namespace of the object does not match the namespace of the request")
+	}
+	return u, nil
+}
+
+// add adds a loaded or created object, with its own resource version.
+func (c *Cluster) add(key objectKey, obj *object) {
+	c.resourceVersion++
+	obj.resourceVersion = c.resourceVersion
+	obj.uid = string(uuid.NewUUID())
+	c.objects[key] = obj
+}
+
+func keyOf(u *unstructured.Unstructured) objectKey {
+	return objectKey{GroupKind: u.GroupVersionKind().GroupKind(), Namespace: u.GetNamespace(), Name: u.GetName()}
+}
+
+// matchesFields returns whether the fields of the object match the selector.
+// Any field of the object can be selected.
+func matchesFields(u *unstructured.Unstructured, selector fields.Selector) bool {
+	for _, requirement := range selector.Requirements() {
+		value := ""
+		field, found, _ := unstructured.NestedFieldNoCopy(u.Object, strings.Split(requirement.Field, ".")...)
+		if found && field != nil {
+			value = fmt.Sprint(field)
+		}
+		switch requirement.Operator {
+		case selection.Equals, selection.DoubleEquals:
+			if value != requirement.Value {
+				return false
+			}
+		case selection.NotEquals:
+			if value == requirement.Value {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, obj interface{}) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := apierrors.NewInternalError(err).Status()
+	if apiStatus, ok := err.(apierrors.APIStatus); ok {
+		status = apiStatus.Status()
+	}
+	status.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	status.Status = metav1.StatusFailure
+	writeJSON(w, int(status.Code), &status)
+}
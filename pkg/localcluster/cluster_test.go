@@ -0,0 +1,372 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localcluster
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+const testManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: 1
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+  labels:
+    app: web
+spec:
+  type: ClusterIP
+`
+
+const testDump = `{
+    "apiVersion": "v1",
+    "kind": "ConfigMapList",
+    "items": [
+        {"metadata": {"name": "a", "namespace": "prod"}, "data": {"mode": "fast"}},
+        {"metadata": {"name": "b", "namespace": "prod"}, "data": {"mode": "slow"}}
+    ]
+}
+`
+
+const testCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  scope: Namespaced
+  names:
+    plural: widgets
+    singular: widget
+    kind: Widget
+    shortNames: [wd]
+  versions:
+  - name: v1beta1
+    served: true
+    storage: false
+  - name: v1
+    served: true
+    storage: true
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+  namespace: prod
+`
+
+var (
+	deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	configMapsGVR  = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	namespacesGVR  = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	widgetsGVR     = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+)
+
+func newTestCluster(t *testing.T) (string, *rest.Config) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"app/web.yaml":              testManifests,
+		"dump/prod/configmaps.json": testDump,
+		"crds/widgets.yml":          testCRD,
+		"README.md":                 "not a manifest",
+		".git/config.yaml":          "not: [valid",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cluster, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, &rest.Config{Host: "http://local-cluster", Transport: cluster}
+}
+
+func names(list *unstructured.UnstructuredList) []string {
+	result := []string{}
+	for _, item := range list.Items {
+		result = append(result, item.GetNamespace()+"/"+item.GetName())
+	}
+	return result
+}
+
+func TestNewDuplicateObjects(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.yaml", "b.yaml"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(testManifests), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := New(dir)
+	if err == nil || !strings.Contains(err.Error(), "Deployment.apps web is defined in both") {
+		t.Errorf("expected an error about the duplicate objects, got %v", err)
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	_, config := newTestCluster(t)
+	client := discovery.NewDiscoveryClientForConfigOrDie(config)
+
+	_, resourceLists, err := client.ServerGroupsAndResources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources := map[string]metav1.APIResource{}
+	for _, list := range resourceLists {
+		for _, r := range list.APIResources {
+			resources[list.GroupVersion+"/"+r.Name] = r
+		}
+	}
+	for name, expected := range map[string]metav1.APIResource{
+		"v1/pods":                          {Name: "pods", Namespaced: true, Kind: "Pod", ShortNames: []string{"po"}, Categories: []string{"all"}},
+		"v1/nodes":                         {Name: "nodes", Namespaced: false, Kind: "Node", ShortNames: []string{"no"}},
+		"v1/endpoints":                     {Name: "endpoints", Namespaced: true, Kind: "Endpoints", ShortNames: []string{"ep"}},
+		"apps/v1/deployments":              {Name: "deployments", Namespaced: true, Kind: "Deployment", ShortNames: []string{"deploy"}, Categories: []string{"all"}},
+		"storage.k8s.io/v1/storageclasses": {Name: "storageclasses", Namespaced: false, Kind: "StorageClass", ShortNames: []string{"sc"}},
+		"example.com/v1/widgets":           {Name: "widgets", Namespaced: true, Kind: "Widget", ShortNames: []string{"wd"}},
+	} {
+		r, ok := resources[name]
+		if !ok {
+			t.Errorf("%s is not served", name)
+			continue
+		}
+		r.SingularName, r.Verbs = "", nil
+		if !reflect.DeepEqual(r, expected) {
+			t.Errorf("%s: expected %#v, got %#v", name, expected, r)
+		}
+	}
+	if _, ok := resources["extensions/v1beta1/deployments"]; ok {
+		t.Errorf("the deployments of the extensions group are served")
+	}
+}
+
+func TestList(t *testing.T) {
+	_, config := newTestCluster(t)
+	client := dynamic.NewForConfigOrDie(config)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		gvr      schema.GroupVersionResource
+		ns       string
+		opts     metav1.ListOptions
+		expected []string
+	}{
+		{name: "default namespace", gvr: deploymentsGVR, ns: "default", expected: []string{"default/web"}},
+		{name: "other namespace", gvr: deploymentsGVR, ns: "prod", expected: []string{}},
+		{name: "list items", gvr: configMapsGVR, ns: "prod", expected: []string{"prod/a", "prod/b"}},
+		{name: "field selector", gvr: configMapsGVR, opts: metav1.ListOptions{FieldSelector: "data.mode=slow"}, expected: []string{"prod/b"}},
+		{name: "name field selector", gvr: configMapsGVR, opts: metav1.ListOptions{FieldSelector: "metadata.name!=b"}, expected: []string{"prod/a"}},
+		{name: "label selector", gvr: schema.GroupVersionResource{Version: "v1", Resource: "services"}, opts: metav1.ListOptions{LabelSelector: "app in (web)"}, expected: []string{"prod/web"}},
+		{name: "custom resources", gvr: widgetsGVR, expected: []string{"prod/w"}},
+		{name: "namespaces", gvr: namespacesGVR, expected: []string{"/default", "/prod"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			list, err := client.Resource(tc.gvr).Namespace(tc.ns).List(ctx, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(list); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+
+	if _, err := client.Resource(configMapsGVR).Namespace("prod").Watch(ctx, metav1.ListOptions{}); err == nil {
+		t.Errorf("expected watches not to be supported")
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	dir, config := newTestCluster(t)
+	client := dynamic.NewForConfigOrDie(config)
+	ctx := context.Background()
+	original, err := os.ReadFile(filepath.Join(dir, "app/web.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patch := []byte(`{"spec":{"replicas":3}}`)
+	if _, err := client.Resource(deploymentsGVR).Namespace("default").Patch(ctx, "web", types.StrategicMergePatchType, patch, metav1.PatchOptions{}); !apierrors.IsForbidden(err) {
+		t.Errorf("expected the patch to be forbidden, got %v", err)
+	}
+	if err := client.Resource(deploymentsGVR).Namespace("default").Delete(ctx, "web", metav1.DeleteOptions{}); !apierrors.IsForbidden(err) {
+		t.Errorf("expected the deletion to be forbidden, got %v", err)
+	}
+	dryRun := metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}}
+	if _, err := client.Resource(deploymentsGVR).Namespace("default").Patch(ctx, "web", types.StrategicMergePatchType, patch, dryRun); err != nil {
+		t.Errorf("expected dry runs to be allowed, got %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "app/web.yaml")); err != nil || string(data) != string(original) {
+		t.Errorf("expected the manifest to be unchanged, got %s: %v", string(data), err)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir, config := newTestCluster(t)
+	config.Transport.(*Cluster).Write = true
+	client := dynamic.NewForConfigOrDie(config)
+	ctx := context.Background()
+
+	// a dry run changes nothing
+	dryRun := metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}}
+	patched, err := client.Resource(deploymentsGVR).Namespace("default").Patch(ctx, "web", types.StrategicMergePatchType, []byte(`{"spec":{"replicas":3}}`), dryRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas, _, _ := unstructured.NestedInt64(patched.Object, "spec", "replicas"); replicas != 3 {
+		t.Errorf("expected the dry run to return 3 replicas, got %d", replicas)
+	}
+	web, err := client.Resource(deploymentsGVR).Namespace("default").Get(ctx, "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas, _, _ := unstructured.NestedInt64(web.Object, "spec", "replicas"); replicas != 1 {
+		t.Errorf("expected the dry run not to change the replicas, got %d", replicas)
+	}
+
+	// a patch updates the file of the object, without the fields set by the
+	// cluster
+	if _, err := client.Resource(deploymentsGVR).Namespace("default").Patch(ctx, "web", types.StrategicMergePatchType, []byte(`{"spec":{"replicas":3}}`), metav1.PatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "app/web.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: web
+  name: web
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    app: web
+  name: web
+  namespace: prod
+spec:
+  type: ClusterIP
+`
+	if string(data) != expected {
+		t.Errorf("expected the file:\n%s\ngot:\n%s", expected, data)
+	}
+
+	// an update with an old resource version conflicts
+	if _, err := client.Resource(deploymentsGVR).Namespace("default").Update(ctx, web, metav1.UpdateOptions{}); err == nil || !strings.Contains(err.Error(), "the object has been modified") {
+		t.Errorf("expected a conflict, got %v", err)
+	}
+
+	// the items of a list keep being written as a list
+	if _, err := client.Resource(configMapsGVR).Namespace("prod").Patch(ctx, "a", types.MergePatchType, []byte(`{"data":{"mode":null}}`), metav1.PatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Resource(configMapsGVR).Namespace("prod").Delete(ctx, "b", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "dump/prod/configmaps.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{
+    "apiVersion": "v1",
+    "items": [
+        {
+            "apiVersion": "v1",
+            "data": {},
+            "kind": "ConfigMap",
+            "metadata": {
+                "name": "a",
+                "namespace": "prod"
+            }
+        }
+    ],
+    "kind": "ConfigMapList"
+}
+`
+	if string(data) != expected {
+		t.Errorf("expected the file:\n%s\ngot:\n%s", expected, data)
+	}
+
+	// a new object is written to a new file, which is removed with the object
+	widget := &unstructured.Unstructured{}
+	widget.SetAPIVersion("example.com/v1")
+	widget.SetKind("Widget")
+	widget.SetName("x")
+	if _, err := client.Resource(widgetsGVR).Namespace("prod").Create(ctx, widget, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Resource(widgetsGVR).Namespace("prod").Create(ctx, widget, metav1.CreateOptions{}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected the widget to already exist, got %v", err)
+	}
+	path := filepath.Join(dir, "prod/widget-x.yaml")
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), "name: x") {
+		t.Errorf("expected the widget to be written to %s, got %q, %v", path, data, err)
+	}
+	if err := client.Resource(widgetsGVR).Namespace("prod").Delete(ctx, "x", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed, got %v", path, err)
+	}
+
+	// a new object is reloaded from its file
+	reloaded, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := dynamic.NewForConfigOrDie(&rest.Config{Host: "http://local-cluster", Transport: reloaded}).Resource(configMapsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(list); !reflect.DeepEqual(got, []string{"prod/a"}) {
+		t.Errorf("expected the config map prod/a, got %v", got)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localcluster
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// resourceInfo describes a resource served by the local cluster.
+type resourceInfo struct {
+	schema.GroupVersionResource
+	Kind       string
+	Namespaced bool
+	ShortNames []string
+	Categories []string
+}
+
+// clusterScopedKinds are the built-in kinds which are not namespaced.
+var clusterScopedKinds = sets.New(
+	"APIService",
+	"CertificateSigningRequest",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"ClusterTrustBundle",
+	"ComponentStatus",
+	"CSIDriver",
+	"CSINode",
+	"CustomResourceDefinition",
+	"FlowSchema",
+	"IngressClass",
+	"IPAddress",
+	"MutatingWebhookConfiguration",
+	"Namespace",
+	"Node",
+	"PersistentVolume",
+	"PodSecurityPolicy",
+	"PriorityClass",
+	"PriorityLevelConfiguration",
+	"ResourceClass",
+	"RuntimeClass",
+	"ServiceCIDR",
+	"StorageClass",
+	"StorageVersion",
+	"ValidatingAdmissionPolicy",
+	"ValidatingAdmissionPolicyBinding",
+	"ValidatingWebhookConfiguration",
+	"VolumeAttachment",
+	"VolumeAttributesClass",
+)
+
+// shortNames are the short names of the built-in resources, by group
+// resource.
+var shortNames = map[string][]string{
+	"certificatesigningrequests.certificates.k8s.io": {"csr"},
+	"componentstatuses": {"cs"},
+	"configmaps":        {"cm"},
+	"cronjobs.batch":    {"cj"},
+	"customresourcedefinitions.apiextensions.k8s.io": {"crd", "crds"},
+	"daemonsets.apps":                      {"ds"},
+	"deployments.apps":                     {"deploy"},
+	"endpoints":                            {"ep"},
+	"events":                               {"ev"},
+	"horizontalpodautoscalers.autoscaling": {"hpa"},
+	"ingresses.networking.k8s.io":          {"ing"},
+	"limitranges":                          {"limits"},
+	"namespaces":                           {"ns"},
+	"networkpolicies.networking.k8s.io":    {"netpol"},
+	"nodes":                                {"no"},
+	"persistentvolumeclaims":               {"pvc"},
+	"persistentvolumes":                    {"pv"},
+	"poddisruptionbudgets.policy":          {"pdb"},
+	"pods":                                 {"po"},
+	"priorityclasses.scheduling.k8s.io":    {"pc"},
+	"replicasets.apps":                     {"rs"},
+	"replicationcontrollers":               {"rc"},
+	"resourcequotas":                       {"quota"},
+	"serviceaccounts":                      {"sa"},
+	"services":                             {"svc"},
+	"statefulsets.apps":                    {"sts"},
+	"storageclasses.storage.k8s.io":        {"sc"},
+}
+
+// allCategory are the group resources of the "all" category.
+var allCategory = sets.New(
+	"cronjobs.batch",
+	"daemonsets.apps",
+	"deployments.apps",
+	"horizontalpodautoscalers.autoscaling",
+	"jobs.batch",
+	"pods",
+	"replicasets.apps",
+	"replicationcontrollers",
+	"services",
+	"statefulsets.apps",
+)
+
+// unservedGroups are the built-in groups no longer served by API servers.
+var unservedGroups = sets.New("extensions")
+
+// builtinResources returns the built-in resources, in the preferred version
+// of their group.
+func builtinResources() []resourceInfo {
+	versions := map[string][]string{}
+	for gv := range scheme.Scheme.AllKnownTypes() {
+		gv := gv.GroupVersion()
+		if unservedGroups.Has(gv.Group) || sets.New(versions[gv.Group]...).Has(gv.Version) {
+			continue
+		}
+		versions[gv.Group] = append(versions[gv.Group], gv.Version)
+	}
+
+	metaObject := reflect.TypeOf((*metav1.Object)(nil)).Elem()
+	resources := []resourceInfo{}
+	for group, groupVersions := range versions {
+		gv := schema.GroupVersion{Group: group, Version: preferredVersion(groupVersions)}
+		types := scheme.Scheme.KnownTypes(gv)
+		for kind, t := range types {
+			if strings.HasSuffix(kind, "List") || !reflect.PtrTo(t).Implements(metaObject) {
+				continue
+			}
+			// only kinds which can be listed are resources, the others are
+			// subresources or options
+			if _, ok := types[kind+"List"]; !ok {
+				continue
+			}
+			resources = append(resources, newResourceInfo(gv.WithKind(kind), !clusterScopedKinds.Has(kind)))
+		}
+	}
+	resources = append(resources, newResourceInfo(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}, false))
+	return resources
+}
+
+func newResourceInfo(gvk schema.GroupVersionKind, namespaced bool) resourceInfo {
+	resource, _ := meta.UnsafeGuessKindToResource(gvk)
+	info := resourceInfo{
+		GroupVersionResource: resource,
+		Kind:                 gvk.Kind,
+		Namespaced:           namespaced,
+	}
+	groupResource := info.GroupResource().String()
+	info.ShortNames = shortNames[groupResource]
+	if allCategory.Has(groupResource) {
+		info.Categories = []string{"all"}
+	}
+	return info
+}
+
+// customResources returns the resources defined by the custom resource
+// definitions, in their storage version.
+func customResources(crds []*unstructured.Unstructured) []resourceInfo {
+	resources := []resourceInfo{}
+	for _, crd := range crds {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		plural, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "plural")
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		scope, _, _ := unstructured.NestedString(crd.Object, "spec", "scope")
+		shortNames, _, _ := unstructured.NestedStringSlice(crd.Object, "spec", "names", "shortNames")
+		categories, _, _ := unstructured.NestedStringSlice(crd.Object, "spec", "names", "categories")
+		versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+		if len(group) == 0 || len(plural) == 0 || len(kind) == 0 {
+			continue
+		}
+
+		served := []string{}
+		storage := ""
+		for _, v := range versions {
+			v, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(v, "name")
+			if isStorage, _, _ := unstructured.NestedBool(v, "storage"); isStorage {
+				storage = name
+			}
+			served = append(served, name)
+		}
+		if len(storage) == 0 {
+			if len(served) == 0 {
+				continue
+			}
+			storage = preferredVersion(served)
+		}
+		resources = append(resources, resourceInfo{
+			GroupVersionResource: schema.GroupVersionResource{Group: group, Version: storage, Resource: plural},
+			Kind:                 kind,
+			Namespaced:           scope != "Cluster",
+			ShortNames:           shortNames,
+			Categories:           categories,
+		})
+	}
+	return resources
+}
+
+// preferredVersion returns the most stable of the versions.
+func preferredVersion(versions []string) string {
+	sorted := append([]string{}, versions...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return version.CompareKubeAwareVersionStrings(sorted[i], sorted[j]) > 0
+	})
+	return sorted[0]
+}
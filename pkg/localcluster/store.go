@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localcluster
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// objectKey identifies an object of the local cluster.
+type objectKey struct {
+	schema.GroupKind
+	Namespace string
+	Name      string
+}
+
+func (k objectKey) String() string {
+	if len(k.Namespace) == 0 {
+		return fmt.Sprintf("%s %s", k.GroupKind, k.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", k.GroupKind, k.Namespace, k.Name)
+}
+
+// object is an object of the local cluster.
+type object struct {
+	// content is the object as it is in its manifest file.
+	content *unstructured.Unstructured
+	file    *manifestFile
+	// uid and resourceVersion are the ones served when the manifest has none.
+	uid             string
+	resourceVersion int64
+}
+
+// manifestFile is a file of the directory of the local cluster.
+type manifestFile struct {
+	path      string
+	documents []*document
+}
+
+// document is a document of a manifest file, either an object or a list of
+// objects.
+type document struct {
+	// list is the list without its items, nil when the document is an object.
+	list    map[string]interface{}
+	objects []*object
+}
+
+// isManifest returns whether the file is a manifest file, by its extension.
+func isManifest(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// loadDir loads the objects of the manifest files of the directory and its
+// subdirectories. Hidden files and directories are skipped.
+func loadDir(dir string) ([]*manifestFile, error) {
+	files := []*manifestFile{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !isManifest(path) {
+			return nil
+		}
+		file, err := loadFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		return nil
+	})
+	return files, err
+}
+
+// loadFile loads the objects of a manifest file, which can hold several
+// YAML documents and lists of objects, such as the ones of a cluster dump.
+func loadFile(path string) (*manifestFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &manifestFile{path: path}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		content := map[string]interface{}{}
+		if err := decoder.Decode(&content); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("error parsing %s: %v", path, err)
+		}
+		if len(content) == 0 {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: content}
+		if len(u.GetAPIVersion()) == 0 || len(u.GetKind()) == 0 {
+			return nil, fmt.Errorf("error parsing %s: an object has no apiVersion or kind", path)
+		}
+
+		doc := &document{}
+		if u.IsList() {
+			items, _, _ := unstructured.NestedSlice(content, "items")
+			delete(content, "items")
+			doc.list = content
+			// the items of typed lists have no apiVersion and kind
+			itemKind := strings.TrimSuffix(u.GetKind(), "List")
+			for _, item := range items {
+				itemContent, ok := item.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("error parsing %s: an item of a list is not an object", path)
+				}
+				itemObject := &unstructured.Unstructured{Object: itemContent}
+				if len(itemObject.GetKind()) == 0 {
+					itemObject.SetAPIVersion(u.GetAPIVersion())
+					itemObject.SetKind(itemKind)
+				}
+				doc.objects = append(doc.objects, &object{content: itemObject, file: file})
+			}
+		} else {
+			doc.objects = []*object{{content: u, file: file}}
+		}
+		file.documents = append(file.documents, doc)
+	}
+	return file, nil
+}
+
+// remove removes the object from its file.
+func (f *manifestFile) remove(obj *object) {
+	for _, doc := range f.documents {
+		for i, o := range doc.objects {
+			if o == obj {
+				doc.objects = append(doc.objects[:i], doc.objects[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// save writes the objects of the file, removing the file when it has none.
+func (f *manifestFile) save() error {
+	documents := []interface{}{}
+	for _, doc := range f.documents {
+		if doc.list != nil {
+			items := []interface{}{}
+			for _, obj := range doc.objects {
+				items = append(items, obj.content.Object)
+			}
+			list := map[string]interface{}{}
+			for k, v := range doc.list {
+				list[k] = v
+			}
+			list["items"] = items
+			documents = append(documents, list)
+			continue
+		}
+		for _, obj := range doc.objects {
+			documents = append(documents, obj.content.Object)
+		}
+	}
+	if len(documents) == 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	buf := &bytes.Buffer{}
+	for i, doc := range documents {
+		if strings.ToLower(filepath.Ext(f.path)) == ".json" {
+			data, err := json.MarshalIndent(doc, "", "    ")
+			if err != nil {
+				return err
+			}
+			buf.Write(data)
+			buf.WriteString("\n")
+			continue
+		}
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, buf.Bytes(), 0644)
+}
@@ -49,6 +49,15 @@ type Rollbacker interface {
 	Rollback(obj runtime.Object, updatedAnnotations map[string]string, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (string, error)
 }
 
+// RollbackPreviewer is implemented by the Rollbackers that can return the
+// object a rollback results in, for the rollback to be reviewed first.
+type RollbackPreviewer interface {
+	// PreviewRollback returns the live object and the object rolled back to
+	// toRevision, computed by the server with a dry run when dryRunStrategy is
+	// DryRunServer, and locally otherwise.
+	PreviewRollback(obj runtime.Object, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (live runtime.Object, rolledBack runtime.Object, err error)
+}
+
 type RollbackVisitor struct {
 	clientset kubernetes.Interface
 	result    Rollbacker
@@ -135,21 +144,8 @@ func (r *DeploymentRollbacker) Rollback(obj runtime.Object, updatedAnnotations m
 	// remove hash label before patching back into the deployment
 	delete(rsForRevision.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
 
-	// compute deployment annotations
-	annotations := map[string]string{}
-	for k := range annotationsToSkip {
-		if v, ok := deployment.Annotations[k]; ok {
-			annotations[k] = v
-		}
-	}
-	for k, v := range rsForRevision.Annotations {
-		if !annotationsToSkip[k] {
-			annotations[k] = v
-		}
-	}
-
 	// make patch to restore
-	patchType, patch, err := getDeploymentPatch(&rsForRevision.Spec.Template, annotations)
+	patchType, patch, err := getDeploymentPatch(&rsForRevision.Spec.Template, rollbackAnnotations(deployment, rsForRevision))
 	if err != nil {
 		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
 	}
@@ -165,6 +161,59 @@ func (r *DeploymentRollbacker) Rollback(obj runtime.Object, updatedAnnotations m
 	return rollbackSuccess, nil
 }
 
+func (r *DeploymentRollbacker) PreviewRollback(obj runtime.Object, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (runtime.Object, runtime.Object, error) {
+	if toRevision < 0 {
+		return nil, nil, revisionNotFoundErr(toRevision)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
+	}
+	deployment, err := r.c.AppsV1().Deployments(accessor.GetNamespace()).Get(context.TODO(), accessor.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve Deployment %s: %v", accessor.GetName(), err)
+	}
+	rsForRevision, err := deploymentRevision(deployment, r.c, toRevision)
+	if err != nil {
+		return nil, nil, err
+	}
+	delete(rsForRevision.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	annotations := rollbackAnnotations(deployment, rsForRevision)
+
+	if dryRunStrategy != cmdutil.DryRunServer {
+		rolledBack := deployment.DeepCopy()
+		rolledBack.Spec.Template = rsForRevision.Spec.Template
+		rolledBack.Annotations = annotations
+		return deployment, rolledBack, nil
+	}
+	patchType, patch, err := getDeploymentPatch(&rsForRevision.Spec.Template, annotations)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	}
+	rolledBack, err := r.c.AppsV1().Deployments(deployment.Namespace).Patch(context.TODO(), deployment.Name, patchType, patch, metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	}
+	return deployment, rolledBack, nil
+}
+
+// rollbackAnnotations returns the annotations of the deployment rolled back
+// to the revision of the replica set.
+func rollbackAnnotations(deployment *appsv1.Deployment, rs *appsv1.ReplicaSet) map[string]string {
+	annotations := map[string]string{}
+	for k := range annotationsToSkip {
+		if v, ok := deployment.Annotations[k]; ok {
+			annotations[k] = v
+		}
+	}
+	for k, v := range rs.Annotations {
+		if !annotationsToSkip[k] {
+			annotations[k] = v
+		}
+	}
+	return annotations
+}
+
 // equalIgnoreHash returns true if two given podTemplateSpec are equal, ignoring the diff in value of Labels[pod-template-hash]
 // We ignore pod-template-hash because:
 //  1. The hash result would be different upon podTemplateSpec API changes
@@ -272,13 +321,9 @@ func (r *DaemonSetRollbacker) Rollback(obj runtime.Object, updatedAnnotations ma
 	if err != nil {
 		return "", err
 	}
-	if toRevision == 0 && len(history) <= 1 {
-		return "", fmt.Errorf("no last revision to roll back to")
-	}
-
-	toHistory := findHistory(toRevision, history)
-	if toHistory == nil {
-		return "", revisionNotFoundErr(toRevision)
+	toHistory, err := rollbackHistory(toRevision, history)
+	if err != nil {
+		return "", err
 	}
 
 	if dryRunStrategy == cmdutil.DryRunClient {
@@ -302,14 +347,51 @@ func (r *DaemonSetRollbacker) Rollback(obj runtime.Object, updatedAnnotations ma
 	if dryRunStrategy == cmdutil.DryRunServer {
 		patchOptions.DryRun = []string{metav1.DryRunAll}
 	}
+	patch, err := controllerRevisionPatch(toHistory)
+	if err != nil {
+		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	}
 	// Restore revision
-	if _, err = r.c.AppsV1().DaemonSets(accessor.GetNamespace()).Patch(context.TODO(), accessor.GetName(), types.StrategicMergePatchType, toHistory.Data.Raw, patchOptions); err != nil {
+	if _, err = r.c.AppsV1().DaemonSets(accessor.GetNamespace()).Patch(context.TODO(), accessor.GetName(), types.StrategicMergePatchType, patch, patchOptions); err != nil {
 		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
 	}
 
 	return rollbackSuccess, nil
 }
 
+func (r *DaemonSetRollbacker) PreviewRollback(obj runtime.Object, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (runtime.Object, runtime.Object, error) {
+	if toRevision < 0 {
+		return nil, nil, revisionNotFoundErr(toRevision)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
+	}
+	ds, history, err := daemonSetHistory(r.c.AppsV1(), accessor.GetNamespace(), accessor.GetName())
+	if err != nil {
+		return nil, nil, err
+	}
+	toHistory, err := rollbackHistory(toRevision, history)
+	if err != nil {
+		return nil, nil, err
+	}
+	patch, err := controllerRevisionPatch(toHistory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rolledBack runtime.Object
+	if dryRunStrategy == cmdutil.DryRunServer {
+		rolledBack, err = r.c.AppsV1().DaemonSets(ds.Namespace).Patch(context.TODO(), ds.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}})
+	} else {
+		rolledBack, err = applyControllerRevisionPatch(ds, patch, &appsv1.DaemonSet{})
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	}
+	return ds, rolledBack, nil
+}
+
 // daemonMatch check if the given DaemonSet's template matches the template stored in the given history.
 func daemonSetMatch(ds *appsv1.DaemonSet, history *appsv1.ControllerRevision) (bool, error) {
 	patch, err := getDaemonSetPatch(ds)
@@ -363,13 +445,9 @@ func (r *StatefulSetRollbacker) Rollback(obj runtime.Object, updatedAnnotations
 	if err != nil {
 		return "", err
 	}
-	if toRevision == 0 && len(history) <= 1 {
-		return "", fmt.Errorf("no last revision to roll back to")
-	}
-
-	toHistory := findHistory(toRevision, history)
-	if toHistory == nil {
-		return "", revisionNotFoundErr(toRevision)
+	toHistory, err := rollbackHistory(toRevision, history)
+	if err != nil {
+		return "", err
 	}
 
 	if dryRunStrategy == cmdutil.DryRunClient {
@@ -393,14 +471,99 @@ func (r *StatefulSetRollbacker) Rollback(obj runtime.Object, updatedAnnotations
 	if dryRunStrategy == cmdutil.DryRunServer {
 		patchOptions.DryRun = []string{metav1.DryRunAll}
 	}
+	patch, err := controllerRevisionPatch(toHistory)
+	if err != nil {
+		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	}
 	// Restore revision
-	if _, err = r.c.AppsV1().StatefulSets(sts.Namespace).Patch(context.TODO(), sts.Name, types.StrategicMergePatchType, toHistory.Data.Raw, patchOptions); err != nil {
+	if _, err = r.c.AppsV1().StatefulSets(sts.Namespace).Patch(context.TODO(), sts.Name, types.StrategicMergePatchType, patch, patchOptions); err != nil {
 		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
 	}
 
 	return rollbackSuccess, nil
 }
 
+func (r *StatefulSetRollbacker) PreviewRollback(obj runtime.Object, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (runtime.Object, runtime.Object, error) {
+	if toRevision < 0 {
+		return nil, nil, revisionNotFoundErr(toRevision)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create accessor for kind %v: %s", obj.GetObjectKind(), err.Error())
+	}
+	sts, history, err := statefulSetHistory(r.c.AppsV1(), accessor.GetNamespace(), accessor.GetName())
+	if err != nil {
+		return nil, nil, err
+	}
+	toHistory, err := rollbackHistory(toRevision, history)
+	if err != nil {
+		return nil, nil, err
+	}
+	patch, err := controllerRevisionPatch(toHistory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rolledBack runtime.Object
+	if dryRunStrategy == cmdutil.DryRunServer {
+		rolledBack, err = r.c.AppsV1().StatefulSets(sts.Namespace).Patch(context.TODO(), sts.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{DryRun: []string{metav1.DryRunAll}})
+	} else {
+		rolledBack, err = applyControllerRevisionPatch(sts, patch, &appsv1.StatefulSet{})
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	}
+	return sts, rolledBack, nil
+}
+
+// rollbackHistory returns the ControllerRevision of toRevision, or of the
+// previous revision when toRevision is 0.
+func rollbackHistory(toRevision int64, history []*appsv1.ControllerRevision) (*appsv1.ControllerRevision, error) {
+	if toRevision == 0 && len(history) <= 1 {
+		return nil, fmt.Errorf("no last revision to roll back to")
+	}
+	toHistory := findHistory(toRevision, history)
+	if toHistory == nil {
+		return nil, revisionNotFoundErr(toRevision)
+	}
+	return toHistory, nil
+}
+
+// controllerRevisionPatch returns the strategic merge patch restoring the
+// revision. As for Deployments, the change cause recorded with the revision
+// is restored along with the template.
+func controllerRevisionPatch(history *appsv1.ControllerRevision) ([]byte, error) {
+	changeCause, ok := history.Annotations[ChangeCauseAnnotation]
+	if !ok {
+		return history.Data.Raw, nil
+	}
+	patch := map[string]interface{}{}
+	if err := json.Unmarshal(history.Data.Raw, &patch); err != nil {
+		return nil, err
+	}
+	patch["metadata"] = map[string]interface{}{
+		"annotations": map[string]interface{}{ChangeCauseAnnotation: changeCause},
+	}
+	return json.Marshal(patch)
+}
+
+// applyControllerRevisionPatch applies the patch to obj locally, decoding
+// the result into into.
+func applyControllerRevisionPatch(obj runtime.Object, patch []byte, into runtime.Object) (runtime.Object, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := strategicpatch.StrategicMergePatch(data, patch, into)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patched, into); err != nil {
+		return nil, err
+	}
+	return into, nil
+}
+
 var appsCodec = scheme.Codecs.LegacyCodec(appsv1.SchemeGroupVersion)
 
 // applyRevision returns a new StatefulSet constructed by restoring the state in revision to set. If the returned error
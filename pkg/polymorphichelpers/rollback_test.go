@@ -17,6 +17,7 @@ limitations under the License.
 package polymorphichelpers
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -28,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/fake"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
 var rollbackTests = map[schema.GroupKind]reflect.Type{
@@ -186,3 +188,57 @@ func TestStatefulSetApplyRevision(t *testing.T) {
 		})
 	}
 }
+
+func TestDaemonSetPreviewRollback(t *testing.T) {
+	trueVar := true
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "moons", Namespace: "default", UID: "1993", Annotations: map[string]string{ChangeCauseAnnotation: "update to v2"}},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"foo": "bar"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "moon", Image: "moon:v2"}}},
+			},
+		},
+	}
+	revision := func(number int64, image, changeCause string) *appsv1.ControllerRevision {
+		previous := ds.DeepCopy()
+		previous.Spec.Template.Spec.Containers[0].Image = image
+		patch, err := getDaemonSetPatch(previous)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            fmt.Sprintf("moons-%d", number),
+				Namespace:       "default",
+				Labels:          map[string]string{"foo": "bar"},
+				Annotations:     map[string]string{ChangeCauseAnnotation: changeCause},
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "moons", UID: "1993", Controller: &trueVar}},
+			},
+			Data:     runtime.RawExtension{Raw: patch},
+			Revision: number,
+		}
+	}
+	fakeClientset := fake.NewSimpleClientset(ds, revision(1, "moon:v1", "create v1"), revision(2, "moon:v2", "update to v2"))
+	r := &DaemonSetRollbacker{fakeClientset}
+
+	live, rolledBack, err := r.PreviewRollback(ds, 0, cmdutil.DryRunClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image := live.(*appsv1.DaemonSet).Spec.Template.Spec.Containers[0].Image; image != "moon:v2" {
+		t.Errorf("expected the live image moon:v2, got %s", image)
+	}
+	rolledBackDS := rolledBack.(*appsv1.DaemonSet)
+	if image := rolledBackDS.Spec.Template.Spec.Containers[0].Image; image != "moon:v1" {
+		t.Errorf("expected the rolled back image moon:v1, got %s", image)
+	}
+	if changeCause := rolledBackDS.Annotations[ChangeCauseAnnotation]; changeCause != "create v1" {
+		t.Errorf("expected the change cause of revision 1 to be restored, got %q", changeCause)
+	}
+
+	if _, _, err := r.PreviewRollback(ds, 3, cmdutil.DryRunClient); err == nil || err.Error() != "unable to find specified revision 3 in history" {
+		t.Errorf("expected an error for a missing revision, got %v", err)
+	}
+}
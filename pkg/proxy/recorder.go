@@ -0,0 +1,524 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/klog/v2"
+)
+
+// redactedValue replaces sensitive values in recorded traffic.
+const redactedValue = "REDACTED"
+
+// sensitiveHeaders are never written to a recording.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization", "X-Remote-User"}
+
+// Record is a single proxied request and its response, as written to a
+// recording by RecordTo. Recordings contain one JSON encoded Record per line.
+type Record struct {
+	Time            time.Time   `json:"time"`
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	RequestHeaders  http.Header `json:"requestHeaders,omitempty"`
+	RequestBody     string      `json:"requestBody,omitempty"`
+	StatusCode      int         `json:"statusCode"`
+	ResponseHeaders http.Header `json:"responseHeaders,omitempty"`
+	ResponseBody    string      `json:"responseBody,omitempty"`
+	// Binary bodies, e.g. protobuf, are stored base64 encoded.
+	RequestBinaryBody  []byte `json:"requestBinaryBody,omitempty"`
+	ResponseBinaryBody []byte `json:"responseBinaryBody,omitempty"`
+	// Upgraded is true for exec, attach and port-forward requests, whose
+	// streams are not recorded.
+	Upgraded bool `json:"upgraded,omitempty"`
+	// Redacted is true when any part of the record was redacted.
+	Redacted bool `json:"redacted,omitempty"`
+	// Stream identifies a watch response. Watch events are recorded as
+	// they are received, each in a record with Event set and the Stream of
+	// the record of the watch request.
+	Stream uint64 `json:"stream,omitempty"`
+	Event  bool   `json:"event,omitempty"`
+}
+
+// RecordTo makes the server write every request and response it handles to w.
+// Authentication headers, Secret data and service account tokens are
+// redacted.
+func (s *Server) RecordTo(w io.Writer) {
+	s.handler = NewRecordingHandler(s.handler, w)
+}
+
+// recordingHandler passes requests to its delegate and records the exchange.
+type recordingHandler struct {
+	delegate http.Handler
+
+	lock    sync.Mutex
+	encoder *json.Encoder
+	streams uint64
+}
+
+// NewRecordingHandler returns a handler that records the requests passed to delegate, and their responses, to w.
+func NewRecordingHandler(delegate http.Handler, w io.Writer) http.Handler {
+	return &recordingHandler{delegate: delegate, encoder: json.NewEncoder(w)}
+}
+
+func (h *recordingHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	record := &Record{
+		Time:   time.Now(),
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+	}
+	record.RequestHeaders = redactHeaders(req.Header, record)
+
+	// upgraded connections are hijacked from the response writer, so they
+	// are passed through unwrapped and only the request line is recorded.
+	if httpstream.IsUpgradeRequest(req) {
+		record.Upgraded = true
+		h.delegate.ServeHTTP(rw, req)
+		h.write(record)
+		return
+	}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		record.RequestBody, record.RequestBinaryBody = recordBody(req.URL.Path, req.Header, body, record)
+	}
+
+	recorder := &recordingResponseWriter{ResponseWriter: rw, statusCode: http.StatusOK}
+	// watch responses may never end, their events are recorded as they
+	// are written instead of buffering the whole response
+	if isWatch(req) {
+		stream := &streamRecorder{handler: h, record: record, path: req.URL.Path, header: rw.Header()}
+		record.Stream = h.nextStream()
+		recorder.stream = stream
+		h.delegate.ServeHTTP(recorder, req)
+		stream.close(recorder.statusCode)
+		return
+	}
+	h.delegate.ServeHTTP(recorder, req)
+
+	record.StatusCode = recorder.statusCode
+	record.ResponseHeaders = redactHeaders(rw.Header(), record)
+	record.ResponseBody, record.ResponseBinaryBody = recordBody(req.URL.Path, rw.Header(), recorder.body.Bytes(), record)
+	h.write(record)
+}
+
+func (h *recordingHandler) nextStream() uint64 {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.streams++
+	return h.streams
+}
+
+func (h *recordingHandler) write(record *Record) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if err := h.encoder.Encode(record); err != nil {
+		klog.Errorf("Error recording request %s %s: %v", record.Method, record.URL, err)
+	}
+}
+
+// recordingResponseWriter captures the status code and body written to the
+// wrapped response writer.
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+	// stream records the body of watch responses instead of body
+	stream *streamRecorder
+}
+
+func (w *recordingResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *recordingResponseWriter) Write(data []byte) (int, error) {
+	if w.stream != nil {
+		w.stream.write(w.statusCode, data)
+	} else {
+		w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush is required to stream watch responses.
+func (w *recordingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// streamRecorder records a watch response. The record of the request is
+// written when the response starts, followed by a record for every event.
+// JSON events are split on newlines, other bodies are recorded as written.
+type streamRecorder struct {
+	handler *recordingHandler
+	record  *Record
+	path    string
+	header  http.Header
+
+	started bool
+	// pending holds the start of a JSON event that was not written completely
+	pending []byte
+}
+
+func (s *streamRecorder) start(statusCode int) {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.record.StatusCode = statusCode
+	s.record.ResponseHeaders = redactHeaders(s.header, s.record)
+	s.handler.write(s.record)
+}
+
+func (s *streamRecorder) write(statusCode int, data []byte) {
+	s.start(statusCode)
+	if !strings.HasPrefix(s.header.Get("Content-Type"), "application/json") {
+		s.writeChunk(data)
+		return
+	}
+	s.pending = append(s.pending, data...)
+	for {
+		i := bytes.IndexByte(s.pending, '\n')
+		if i < 0 {
+			return
+		}
+		s.writeEvent(s.pending[:i])
+		s.pending = s.pending[i+1:]
+	}
+}
+
+func (s *streamRecorder) newEvent() *Record {
+	return &Record{
+		Time:   time.Now(),
+		Method: s.record.Method,
+		URL:    s.record.URL,
+		Stream: s.record.Stream,
+		Event:  true,
+	}
+}
+
+// writeEvent records a JSON event.
+func (s *streamRecorder) writeEvent(data []byte) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return
+	}
+	event := s.newEvent()
+	event.ResponseBody, event.ResponseBinaryBody = recordBody(s.path, http.Header{}, data, event)
+	s.handler.write(event)
+}
+
+// writeChunk records data exactly as written, since events of other
+// encodings, e.g. protobuf, cannot be split or redacted.
+func (s *streamRecorder) writeChunk(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	event := s.newEvent()
+	if strings.Contains(s.path, "/secrets") {
+		event.Redacted = true
+	} else {
+		event.ResponseBinaryBody = data
+	}
+	s.handler.write(event)
+}
+
+func (s *streamRecorder) close(statusCode int) {
+	s.start(statusCode)
+	s.writeEvent(s.pending)
+	s.pending = nil
+}
+
+// isWatch returns true if req is a watch request.
+func isWatch(req *http.Request) bool {
+	switch req.URL.Query().Get("watch") {
+	case "true", "1":
+		return true
+	}
+	return strings.Contains(req.URL.Path, "/watch/")
+}
+
+func redactHeaders(header http.Header, record *Record) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	redacted := header.Clone()
+	for _, name := range sensitiveHeaders {
+		if _, ok := redacted[http.CanonicalHeaderKey(name)]; ok {
+			redacted.Set(name, redactedValue)
+			record.Redacted = true
+		}
+	}
+	return redacted
+}
+
+// recordBody returns the body as text if it is valid UTF-8 and as raw bytes
+// otherwise. Compressed bodies are recorded decompressed. JSON bodies have
+// Secret data and tokens redacted; non-JSON bodies of requests for secrets
+// and tokens cannot be inspected and are dropped.
+func recordBody(path string, header http.Header, body []byte, record *Record) (string, []byte) {
+	if len(body) == 0 {
+		return "", nil
+	}
+	if header.Get("Content-Encoding") == "gzip" {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return "", nil
+		}
+		if body, err = io.ReadAll(reader); err != nil {
+			return "", nil
+		}
+	}
+	if redacted, ok, changed := redactJSON(body, strings.Contains(path, "/secrets")); ok {
+		if changed {
+			record.Redacted = true
+		}
+		return string(redacted), nil
+	}
+	if strings.Contains(path, "/secrets") || isTokenRequestPath(path) {
+		record.Redacted = true
+		return "", nil
+	}
+	if utf8.Valid(body) {
+		return string(body), nil
+	}
+	return "", body
+}
+
+// isTokenRequestPath returns true if the path is the token subresource of a
+// service account.
+func isTokenRequestPath(path string) bool {
+	path, _, _ = strings.Cut(path, "?")
+	return strings.Contains(path, "/serviceaccounts/") && strings.HasSuffix(path, "/token")
+}
+
+// redactJSON redacts Secret data and tokens in a JSON document or a stream of JSON
+// documents, such as a watch response. It reports whether body was JSON and
+// whether anything was redacted. When secrets is true the body is a
+// response for secrets, whose metadata is redacted as well.
+func redactJSON(body []byte, secrets bool) ([]byte, bool, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false, false
+	}
+	var docs [][]byte
+	changed := false
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		obj := map[string]interface{}{}
+		if err := decoder.Decode(&obj); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, false, false
+		}
+		if redactObject(obj, secrets) {
+			changed = true
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, false, false
+		}
+		docs = append(docs, data)
+	}
+	// unchanged documents are recorded exactly as they were sent
+	if !changed {
+		return trimmed, true, false
+	}
+	return bytes.Join(docs, []byte("\n")), true, true
+}
+
+// redactObject replaces the values of Secrets and the tokens of TokenRequests
+// in obj, which may be a Secret, a TokenRequest, a list, a table or a watch
+// event. The metadata of the objects in a response for secrets is redacted as
+// well.
+func redactObject(obj map[string]interface{}, secrets bool) bool {
+	changed := false
+	kind, _ := obj["kind"].(string)
+	apiVersion, _ := obj["apiVersion"].(string)
+	if kind == "TokenRequest" && strings.HasPrefix(apiVersion, "authentication.k8s.io/") {
+		if status, ok := obj["status"].(map[string]interface{}); ok {
+			if _, ok := status["token"]; ok {
+				status["token"] = redactedValue
+				changed = true
+			}
+		}
+	}
+	if kind == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			data, ok := obj[field].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for key := range data {
+				data[key] = redactedValue
+				changed = true
+			}
+		}
+	}
+	if kind == "Secret" || (secrets && kind == "PartialObjectMetadata") {
+		// the last applied configuration contains the secret data as well
+		if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+			if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+				if _, ok := annotations["kubectl.kubernetes.io/last-applied-configuration"]; ok {
+					annotations["kubectl.kubernetes.io/last-applied-configuration"] = redactedValue
+					changed = true
+				}
+			}
+		}
+	}
+	// list items and table rows
+	for _, field := range []string{"items", "rows"} {
+		items, ok := obj[field].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range items {
+			if itemObj, ok := item.(map[string]interface{}); ok && redactObject(itemObj, secrets) {
+				changed = true
+			}
+		}
+	}
+	// watch events and table rows
+	if eventObj, ok := obj["object"].(map[string]interface{}); ok && redactObject(eventObj, secrets) {
+		changed = true
+	}
+	return changed
+}
+
+// ReadRecords reads a recording written by RecordTo.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var records []Record
+	decoder := json.NewDecoder(r)
+	for {
+		record := Record{}
+		if err := decoder.Decode(&record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid recording entry %d: %v", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+}
+
+// replayHandler serves recorded responses for requests matching the
+// method and URL of a record. Repeated requests are answered with the
+// recorded responses in order, repeating the last one once exhausted.
+type replayHandler struct {
+	lock      sync.Mutex
+	responses map[string][]Record
+}
+
+// NewReplayServer creates a Server that answers requests from records
+// instead of proxying them to an API server.
+func NewReplayServer(records []Record) *Server {
+	h := &replayHandler{responses: map[string][]Record{}}
+	events := map[uint64][]Record{}
+	for _, record := range records {
+		if record.Event {
+			events[record.Stream] = append(events[record.Stream], record)
+		}
+	}
+	for _, record := range records {
+		if record.Upgraded || record.Event {
+			continue
+		}
+		if record.Stream != 0 {
+			record.ResponseBody, record.ResponseBinaryBody = joinEvents(events[record.Stream])
+		}
+		key := replayKey(record.Method, record.URL)
+		h.responses[key] = append(h.responses[key], record)
+	}
+	return &Server{handler: h}
+}
+
+// joinEvents returns the body of a watch response from its events.
+func joinEvents(events []Record) (string, []byte) {
+	var body bytes.Buffer
+	binary := false
+	for _, event := range events {
+		if len(event.ResponseBinaryBody) > 0 {
+			binary = true
+			body.Write(event.ResponseBinaryBody)
+			continue
+		}
+		if event.ResponseBody != "" {
+			body.WriteString(event.ResponseBody)
+			body.WriteString("\n")
+		}
+	}
+	if binary {
+		return "", body.Bytes()
+	}
+	return body.String(), nil
+}
+
+func replayKey(method, url string) string {
+	return method + " " + url
+}
+
+func (h *replayHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	key := replayKey(req.Method, req.URL.RequestURI())
+
+	h.lock.Lock()
+	records := h.responses[key]
+	if len(records) == 0 {
+		h.lock.Unlock()
+		klog.V(3).Infof("No recorded response for %s", key)
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(rw, `{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure","message":%q,"reason":"NotFound","code":404}`, "no recorded response for "+key)
+		return
+	}
+	record := records[0]
+	if len(records) > 1 {
+		h.responses[key] = records[1:]
+	}
+	h.lock.Unlock()
+
+	for name, values := range record.ResponseHeaders {
+		// bodies are recorded decompressed and possibly redacted
+		if name == "Content-Length" || name == "Content-Encoding" {
+			continue
+		}
+		for _, value := range values {
+			rw.Header().Add(name, value)
+		}
+	}
+	rw.WriteHeader(record.StatusCode)
+	if len(record.ResponseBinaryBody) > 0 {
+		rw.Write(record.ResponseBinaryBody)
+		return
+	}
+	io.WriteString(rw, record.ResponseBody)
+}
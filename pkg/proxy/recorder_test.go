@@ -0,0 +1,383 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	backend := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/api/v1/namespaces/default/secrets/token":
+			io.WriteString(w, `{"kind":"Secret","apiVersion":"v1","metadata":{"name":"token"},"data":{"token":"c2VjcmV0"}}`)
+		case "/api/v1/namespaces/default/pods":
+			if req.Method == http.MethodPost {
+				body, _ := io.ReadAll(req.Body)
+				w.WriteHeader(http.StatusCreated)
+				w.Write(body)
+				return
+			}
+			io.WriteString(w, `{"kind":"PodList","apiVersion":"v1","items":[]}`)
+		default:
+			http.NotFound(w, req)
+		}
+	})
+
+	recording := &bytes.Buffer{}
+	recorder := httptest.NewServer(NewRecordingHandler(backend, recording))
+	defer recorder.Close()
+
+	requests := []struct {
+		method, path, body string
+	}{
+		{method: http.MethodGet, path: "/api/v1/namespaces/default/secrets/token"},
+		{method: http.MethodGet, path: "/api/v1/namespaces/default/pods?limit=500"},
+		{method: http.MethodPost, path: "/api/v1/namespaces/default/pods", body: `{"kind":"Pod","apiVersion":"v1","metadata":{"name":"foo"}}`},
+	}
+	for _, r := range requests {
+		req, err := http.NewRequest(r.method, recorder.URL+r.path, strings.NewReader(r.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer my-token")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if strings.Contains(recording.String(), "my-token") || strings.Contains(recording.String(), "c2VjcmV0") {
+		t.Errorf("recording contains credentials: %s", recording.String())
+	}
+
+	records, err := ReadRecords(recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(requests) {
+		t.Fatalf("expected %d records, got %d", len(requests), len(records))
+	}
+	if !records[0].Redacted || records[0].RequestHeaders.Get("Authorization") != redactedValue {
+		t.Errorf("expected the secret request to be redacted: %#v", records[0])
+	}
+	if records[2].StatusCode != http.StatusCreated || records[2].RequestBody != requests[2].body {
+		t.Errorf("unexpected record for create: %#v", records[2])
+	}
+
+	replay := httptest.NewServer(NewReplayServer(records).handler)
+	defer replay.Close()
+
+	tests := []struct {
+		method, path   string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			method:         http.MethodGet,
+			path:           "/api/v1/namespaces/default/secrets/token",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"token":"REDACTED"`,
+		},
+		{
+			method:         http.MethodGet,
+			path:           "/api/v1/namespaces/default/pods?limit=500",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"kind":"PodList"`,
+		},
+		{
+			method:         http.MethodPost,
+			path:           "/api/v1/namespaces/default/pods",
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"name":"foo"`,
+		},
+		{
+			method:         http.MethodGet,
+			path:           "/api/v1/namespaces/default/pods",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `no recorded response for GET /api/v1/namespaces/default/pods`,
+		},
+	}
+	for _, test := range tests {
+		req, err := http.NewRequest(test.method, replay.URL+test.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != test.expectedStatus {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.path, test.expectedStatus, resp.StatusCode)
+		}
+		if !strings.Contains(string(body), test.expectedBody) {
+			t.Errorf("%s %s: expected body to contain %q, got %q", test.method, test.path, test.expectedBody, string(body))
+		}
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		secrets         bool
+		expectedJSON    bool
+		expectedChanged bool
+		expected        string
+	}{
+		{
+			name:         "not json",
+			body:         "plain text",
+			expectedJSON: false,
+		},
+		{
+			name:         "no secrets",
+			body:         `{"kind":"ConfigMap","data":{"a":"b"}}`,
+			expectedJSON: true,
+			expected:     `{"kind":"ConfigMap","data":{"a":"b"}}`,
+		},
+		{
+			name:            "secret list",
+			body:            `{"kind":"SecretList","items":[{"kind":"Secret","stringData":{"a":"b"}}]}`,
+			expectedJSON:    true,
+			expectedChanged: true,
+			expected:        `{"items":[{"kind":"Secret","stringData":{"a":"REDACTED"}}],"kind":"SecretList"}`,
+		},
+		{
+			name:            "watch events",
+			body:            "{\"type\":\"ADDED\",\"object\":{\"kind\":\"Secret\",\"data\":{\"a\":\"Yg==\"}}}\n{\"type\":\"DELETED\",\"object\":{\"kind\":\"Pod\"}}\n",
+			expectedJSON:    true,
+			expectedChanged: true,
+			expected:        "{\"object\":{\"data\":{\"a\":\"REDACTED\"},\"kind\":\"Secret\"},\"type\":\"ADDED\"}\n{\"object\":{\"kind\":\"Pod\"},\"type\":\"DELETED\"}",
+		},
+		{
+			name:            "table rows",
+			body:            `{"kind":"Table","rows":[{"cells":["token"],"object":{"kind":"Secret","data":{"a":"Yg=="}}}]}`,
+			expectedJSON:    true,
+			expectedChanged: true,
+			expected:        `{"kind":"Table","rows":[{"cells":["token"],"object":{"data":{"a":"REDACTED"},"kind":"Secret"}}]}`,
+		},
+		{
+			name:            "table rows of secret metadata",
+			body:            `{"kind":"Table","rows":[{"object":{"kind":"PartialObjectMetadata","metadata":{"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}"}}}}]}`,
+			secrets:         true,
+			expectedJSON:    true,
+			expectedChanged: true,
+			expected:        `{"kind":"Table","rows":[{"object":{"kind":"PartialObjectMetadata","metadata":{"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"REDACTED"}}}}]}`,
+		},
+		{
+			name:            "token request",
+			body:            `{"kind":"TokenRequest","apiVersion":"authentication.k8s.io/v1","spec":{"audiences":["api"]},"status":{"token":"eyJhbGciOi","expirationTimestamp":"2024-01-01T00:00:00Z"}}`,
+			expectedJSON:    true,
+			expectedChanged: true,
+			expected:        `{"apiVersion":"authentication.k8s.io/v1","kind":"TokenRequest","spec":{"audiences":["api"]},"status":{"expirationTimestamp":"2024-01-01T00:00:00Z","token":"REDACTED"}}`,
+		},
+		{
+			name:         "metadata of other resources",
+			body:         `{"kind":"PartialObjectMetadata","metadata":{"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}"}}}`,
+			expectedJSON: true,
+			expected:     `{"kind":"PartialObjectMetadata","metadata":{"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}"}}}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			redacted, isJSON, changed := redactJSON([]byte(test.body), test.secrets)
+			if isJSON != test.expectedJSON || changed != test.expectedChanged {
+				t.Fatalf("expected json=%t changed=%t, got json=%t changed=%t", test.expectedJSON, test.expectedChanged, isJSON, changed)
+			}
+			if isJSON && string(redacted) != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, string(redacted))
+			}
+		})
+	}
+}
+
+func TestRecordWatch(t *testing.T) {
+	events := make(chan string)
+	backend := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for event := range events {
+			io.WriteString(w, event)
+			w.(http.Flusher).Flush()
+		}
+	})
+
+	recording := &syncBuffer{}
+	recorder := httptest.NewServer(NewRecordingHandler(backend, recording))
+	defer recorder.Close()
+
+	resp, err := http.Get(recorder.URL + "/api/v1/namespaces/default/secrets?watch=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// events are recorded as they are received, before the watch ends
+	events <- `{"type":"ADDED","object":{"kind":"Secret","data":{"a":"c2VjcmV0"}}}` + "\n"
+	events <- `{"type":"MODIFIED","object":{"kind":"Secret",`
+	events <- `"data":{"a":"c2VjcmV0"}}}` + "\n"
+	if err := waitForRecords(recording, 3); err != nil {
+		t.Fatal(err)
+	}
+	close(events)
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if strings.Contains(recording.String(), "c2VjcmV0") {
+		t.Errorf("recording contains secret data: %s", recording.String())
+	}
+	records, err := ReadRecords(strings.NewReader(recording.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d: %#v", len(records), records)
+	}
+	if records[0].Event || records[0].Stream == 0 || records[0].StatusCode != http.StatusOK {
+		t.Errorf("unexpected record for the watch request: %#v", records[0])
+	}
+	for _, event := range records[1:] {
+		if !event.Event || event.Stream != records[0].Stream || !event.Redacted {
+			t.Errorf("unexpected record for a watch event: %#v", event)
+		}
+	}
+
+	replay := httptest.NewServer(NewReplayServer(records).handler)
+	defer replay.Close()
+	resp, err = http.Get(replay.URL + "/api/v1/namespaces/default/secrets?watch=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	expected := `{"object":{"data":{"a":"REDACTED"},"kind":"Secret"},"type":"ADDED"}` + "\n" +
+		`{"object":{"data":{"a":"REDACTED"},"kind":"Secret"},"type":"MODIFIED"}` + "\n"
+	if string(body) != expected {
+		t.Errorf("expected replayed watch %q, got %q", expected, string(body))
+	}
+}
+
+// syncBuffer is a bytes.Buffer that can be read while it is written.
+type syncBuffer struct {
+	lock   sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *syncBuffer) Write(data []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buffer.Write(data)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buffer.String()
+}
+
+func waitForRecords(recording *syncBuffer, count int) error {
+	return wait.PollUntilContextTimeout(context.Background(), 10*time.Millisecond, wait.ForeverTestTimeout, true, func(context.Context) (bool, error) {
+		return strings.Count(recording.String(), "\n") >= count, nil
+	})
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer my-token")
+	header.Set("Proxy-Authorization", "Basic dXNlcjpwYXNz")
+	header.Set("Accept", "application/json")
+
+	record := &Record{}
+	redacted := redactHeaders(header, record)
+	if !record.Redacted {
+		t.Errorf("expected the record to be marked as redacted")
+	}
+	for _, name := range []string{"Authorization", "Proxy-Authorization"} {
+		if redacted.Get(name) != redactedValue {
+			t.Errorf("expected %s to be redacted, got %q", name, redacted.Get(name))
+		}
+	}
+	if redacted.Get("Accept") != "application/json" {
+		t.Errorf("expected Accept to be kept, got %q", redacted.Get("Accept"))
+	}
+	if header.Get("Authorization") != "Bearer my-token" {
+		t.Errorf("expected the original headers to be unchanged")
+	}
+}
+
+func TestRecordTokenRequest(t *testing.T) {
+	backend := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Accept") == "application/vnd.kubernetes.protobuf" {
+			w.Header().Set("Content-Type", "application/vnd.kubernetes.protobuf")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("k8s\x00\x0a\x05token"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"kind":"TokenRequest","apiVersion":"authentication.k8s.io/v1","status":{"token":"eyJhbGciOi"}}`)
+	})
+
+	recording := &bytes.Buffer{}
+	recorder := httptest.NewServer(NewRecordingHandler(backend, recording))
+	defer recorder.Close()
+
+	for _, accept := range []string{"application/json", "application/vnd.kubernetes.protobuf"} {
+		req, err := http.NewRequest(http.MethodPost, recorder.URL+"/api/v1/namespaces/default/serviceaccounts/default/token", strings.NewReader(`{"kind":"TokenRequest","apiVersion":"authentication.k8s.io/v1"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if strings.Contains(recording.String(), "eyJhbGciOi") {
+		t.Errorf("recording contains the token: %s", recording.String())
+	}
+	records, err := ReadRecords(recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		if !record.Redacted {
+			t.Errorf("expected the token response to be redacted: %#v", record)
+		}
+		if len(record.ResponseBinaryBody) > 0 {
+			t.Errorf("expected the token not to be recorded: %#v", record)
+		}
+	}
+}
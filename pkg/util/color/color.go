@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package color colors the output of kubectl, following the --color flag,
+// the NO_COLOR environment variable and the color theme of the kubeconfig
+// file.
+package color
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Auto colors the output written to a terminal, unless NO_COLOR is set.
+	Auto = "auto"
+	// Always colors the output.
+	Always = "always"
+	// Never colors the output.
+	Never = "never"
+)
+
+// The roles of the colored text, which the theme gives a color to.
+const (
+	// Plain is text colored as the rest of the output, so that it is as
+	// long as colored text.
+	Plain   = "plain"
+	Good    = "good"
+	Warning = "warning"
+	Bad     = "bad"
+	Info    = "info"
+	Added   = "added"
+	Removed = "removed"
+)
+
+// codes are the escape sequences of the colors. They have the same length,
+// so that colored columns stay aligned.
+var codes = map[string]string{
+	"default":        "\x1b[39m",
+	"black":          "\x1b[30m",
+	"red":            "\x1b[31m",
+	"green":          "\x1b[32m",
+	"yellow":         "\x1b[33m",
+	"blue":           "\x1b[34m",
+	"magenta":        "\x1b[35m",
+	"cyan":           "\x1b[36m",
+	"white":          "\x1b[37m",
+	"bright-black":   "\x1b[90m",
+	"bright-red":     "\x1b[91m",
+	"bright-green":   "\x1b[92m",
+	"bright-yellow":  "\x1b[93m",
+	"bright-blue":    "\x1b[94m",
+	"bright-magenta": "\x1b[95m",
+	"bright-cyan":    "\x1b[96m",
+	"bright-white":   "\x1b[97m",
+}
+
+const reset = "\x1b[0m"
+
+// Theme maps roles to the names of their colors.
+type Theme map[string]string
+
+// DefaultTheme returns the colors used when the kubeconfig file sets none.
+func DefaultTheme() Theme {
+	return Theme{
+		Plain:   "default",
+		Good:    "green",
+		Warning: "yellow",
+		Bad:     "red",
+		Info:    "cyan",
+		Added:   "green",
+		Removed: "red",
+	}
+}
+
+var (
+	mode = Auto
+
+	theme       = DefaultTheme()
+	themeLoader func() (Theme, error)
+	loadTheme   sync.Once
+)
+
+// SetMode sets when the output is colored.
+func SetMode(m string) error {
+	switch m {
+	case Auto, Always, Never:
+		mode = m
+		return nil
+	default:
+		return fmt.Errorf("invalid color mode %q, must be one of: %s, %s, %s", m, Auto, Always, Never)
+	}
+}
+
+// AddFlags adds the --color flag to the flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Var(modeValue{}, "color", "When to color the output. One of: auto, always, never. With auto, the output is colored when written to a terminal and the NO_COLOR environment variable is not set. The colors are set in the kubectl.kubernetes.io/colors preferences extension of the kubeconfig file.")
+}
+
+type modeValue struct{}
+
+func (modeValue) String() string     { return mode }
+func (modeValue) Set(s string) error { return SetMode(s) }
+func (modeValue) Type() string       { return "string" }
+
+// Enabled returns whether the output written to w is colored.
+func Enabled(w io.Writer) bool {
+	switch mode {
+	case Always:
+		return true
+	case Never:
+		return false
+	}
+	if len(os.Getenv("NO_COLOR")) > 0 {
+		return false
+	}
+	return printers.AllowsColorOutput(w)
+}
+
+// SetThemeLoader sets the function loading the theme, which is called the
+// first time some text is colored.
+func SetThemeLoader(loader func() (Theme, error)) {
+	themeLoader = loader
+	loadTheme = sync.Once{}
+}
+
+// SetTheme sets the colors of the roles of the theme, the other roles keep
+// their colors.
+func SetTheme(t Theme) error {
+	if err := ValidateTheme(t); err != nil {
+		return err
+	}
+	for role, name := range t {
+		theme[role] = name
+	}
+	return nil
+}
+
+// ValidateTheme checks the roles and colors of the theme.
+func ValidateTheme(t Theme) error {
+	defaults := DefaultTheme()
+	for role, name := range t {
+		if _, ok := defaults[role]; !ok {
+			return fmt.Errorf("unknown color role %q, must be one of: %s", role, strings.Join(sortedKeys(defaults), ", "))
+		}
+		if _, ok := codes[name]; !ok {
+			return fmt.Errorf("unknown color %q for %s, must be one of: %s", name, role, strings.Join(sortedKeys(codes), ", "))
+		}
+	}
+	return nil
+}
+
+// Colorize returns the text in the color of the role.
+func Colorize(role, text string) string {
+	loadTheme.Do(func() {
+		if themeLoader == nil {
+			return
+		}
+		t, err := themeLoader()
+		if err == nil {
+			err = SetTheme(t)
+		}
+		if err != nil {
+			klog.V(1).Infof("unable to load the color theme: %v", err)
+		}
+	})
+	code, ok := codes[theme[role]]
+	if !ok {
+		code = codes["default"]
+	}
+	return code + text + reset
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package color
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEnabled(t *testing.T) {
+	defer func(m string) { mode = m }(mode)
+
+	tests := []struct {
+		mode     string
+		noColor  string
+		expected bool
+	}{
+		{mode: Auto, expected: false},
+		{mode: Always, expected: true},
+		{mode: Always, noColor: "1", expected: true},
+		{mode: Never, expected: false},
+	}
+	for _, test := range tests {
+		t.Setenv("NO_COLOR", test.noColor)
+		if err := SetMode(test.mode); err != nil {
+			t.Fatal(err)
+		}
+		// a buffer is not a terminal
+		if actual := Enabled(&bytes.Buffer{}); actual != test.expected {
+			t.Errorf("%s with NO_COLOR=%q: expected %v, got %v", test.mode, test.noColor, test.expected, actual)
+		}
+	}
+	if err := SetMode("sometimes"); err == nil {
+		t.Errorf("expected an error for an invalid mode")
+	}
+}
+
+func TestTheme(t *testing.T) {
+	defer func() { theme = DefaultTheme() }()
+
+	if e, a := "\x1b[32mRunning\x1b[0m", Colorize(Good, "Running"); e != a {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+	if err := SetTheme(Theme{Good: "bright-blue"}); err != nil {
+		t.Fatal(err)
+	}
+	if e, a := "\x1b[94mRunning\x1b[0m", Colorize(Good, "Running"); e != a {
+		t.Errorf("expected %q, got %q", e, a)
+	}
+	if e, a := "\x1b[31mFailed\x1b[0m", Colorize(Bad, "Failed"); e != a {
+		t.Errorf("expected the other roles to keep their colors, got %q", a)
+	}
+
+	if err := SetTheme(Theme{Good: "pink"}); err == nil {
+		t.Errorf("expected an error for an unknown color")
+	}
+	if err := SetTheme(Theme{"excellent": "green"}); err == nil {
+		t.Errorf("expected an error for an unknown role")
+	}
+}
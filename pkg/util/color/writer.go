@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package color
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// LineWriter colors the lines written to it with a function. Lines are
+// colored once complete, Flush writes the last line when it is not.
+type LineWriter struct {
+	out       io.Writer
+	colorLine func(line string) string
+	buf       []byte
+}
+
+// Write colors the complete lines of p and keeps the rest for the next
+// write.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	i := bytes.LastIndexByte(w.buf, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+	lines := strings.SplitAfter(string(w.buf[:i+1]), "\n")
+	w.buf = append(w.buf[:0], w.buf[i+1:]...)
+	var colored strings.Builder
+	for _, line := range lines {
+		if len(line) > 0 {
+			colored.WriteString(w.colorLine(strings.TrimSuffix(line, "\n")) + "\n")
+		}
+	}
+	if _, err := io.WriteString(w.out, colored.String()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush colors and writes the last line when it is not complete.
+func (w *LineWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := w.colorLine(string(w.buf))
+	w.buf = w.buf[:0]
+	_, err := io.WriteString(w.out, line)
+	return err
+}
+
+// NewStatusWriter colors the statuses in the tables written to it, such as
+// Running or CrashLoopBackOff.
+func NewStatusWriter(out io.Writer) *LineWriter {
+	return &LineWriter{out: out, colorLine: colorStatuses}
+}
+
+var words = regexp.MustCompile(`\S+`)
+
+func colorStatuses(line string) string {
+	return words.ReplaceAllStringFunc(line, func(word string) string {
+		if role := StatusRole(word); len(role) > 0 {
+			return Colorize(role, word)
+		}
+		return word
+	})
+}
+
+var (
+	goodStatuses = map[string]bool{
+		"Running": true, "Completed": true, "Succeeded": true, "Ready": true, "Active": true,
+		"Bound": true, "Available": true, "Established": true, "Approved,Issued": true,
+		"Current": true,
+	}
+	warningStatuses = map[string]bool{
+		"Pending": true, "ContainerCreating": true, "PodInitializing": true, "Terminating": true,
+		"Unknown": true, "SchedulingDisabled": true, "Released": true, "Suspended": true,
+		"InProgress": true,
+	}
+	badStatuses = map[string]bool{
+		"CrashLoopBackOff": true, "Error": true, "Failed": true, "ImagePullBackOff": true,
+		"ErrImagePull": true, "InvalidImageName": true, "CreateContainerConfigError": true,
+		"CreateContainerError": true, "RunContainerError": true, "OOMKilled": true,
+		"Evicted": true, "NotReady": true, "Lost": true, "Denied": true, "ContainerStatusUnknown": true,
+		"NotFound": true,
+	}
+)
+
+// StatusRole returns the role of a status, or an empty string for words
+// that are not statuses. The status of containers being initialized, such
+// as Init:CrashLoopBackOff, has the role of the status of the container.
+func StatusRole(status string) string {
+	status = strings.TrimPrefix(status, "Init:")
+	switch {
+	case goodStatuses[status]:
+		return Good
+	case badStatuses[status]:
+		return Bad
+	case warningStatuses[status]:
+		return Warning
+	}
+	if strings.HasPrefix(status, "Ready,") {
+		return Warning
+	}
+	return ""
+}
+
+// NewDiffWriter colors the unified diffs written to it.
+func NewDiffWriter(out io.Writer) *LineWriter {
+	return &LineWriter{out: out, colorLine: colorDiff}
+}
+
+func colorDiff(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "@@"), strings.HasPrefix(line, "diff "):
+		return Colorize(Info, line)
+	case strings.HasPrefix(line, "+"):
+		return Colorize(Added, line)
+	case strings.HasPrefix(line, "-"):
+		return Colorize(Removed, line)
+	}
+	return line
+}
+
+// NewLogWriter colors the level of the log lines written to it, such as
+// ERROR or level=warn.
+func NewLogWriter(out io.Writer) *LineWriter {
+	return &LineWriter{out: out, colorLine: colorLogLevel}
+}
+
+var logLevel = regexp.MustCompile(`\b(FATAL|PANIC|ERROR|WARN|WARNING|INFO)\b|\blevel=(fatal|panic|error|warn|warning|info)\b`)
+
+func colorLogLevel(line string) string {
+	loc := logLevel.FindStringIndex(line)
+	if loc == nil {
+		return line
+	}
+	level := line[loc[0]:loc[1]]
+	role := Info
+	switch strings.ToLower(strings.TrimPrefix(level, "level=")) {
+	case "fatal", "panic", "error":
+		role = Bad
+	case "warn", "warning":
+		role = Warning
+	}
+	return line[:loc[0]] + Colorize(role, level) + line[loc[1]:]
+}
+
+// negativeConditions are the types of the conditions whose status is good
+// when False.
+var negativeConditions = map[string]bool{
+	"MemoryPressure": true, "DiskPressure": true, "PIDPressure": true, "NetworkUnavailable": true,
+	"ReplicaFailure": true, "Failed": true, "Stalled": true, "Degraded": true,
+}
+
+// Conditions colors the statuses of the conditions tables of the output of
+// describe.
+func Conditions(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	indent := -1
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		lineIndent := len(line) - len(trimmed)
+		if strings.TrimSpace(trimmed) == "Conditions:" {
+			indent = lineIndent
+			continue
+		}
+		if indent < 0 {
+			continue
+		}
+		fields := strings.Fields(line)
+		if lineIndent <= indent || len(fields) < 2 {
+			indent = -1
+			continue
+		}
+		role := ConditionRole(fields[0], fields[1])
+		if len(role) == 0 {
+			continue
+		}
+		// the status is the first occurrence of the field after the type
+		start := lineIndent + len(fields[0])
+		j := strings.Index(line[start:], fields[1])
+		lines[i] = line[:start+j] + Colorize(role, fields[1]) + line[start+j+len(fields[1]):]
+	}
+	return strings.Join(lines, "")
+}
+
+// ConditionRole returns the role of the status of a condition: Good when
+// the condition is in its healthy state, True for most types and False for
+// the types reporting problems such as MemoryPressure, Bad when it is not,
+// and Warning when it is Unknown.
+func ConditionRole(conditionType, status string) string {
+	good, bad := "True", "False"
+	if negativeConditions[conditionType] {
+		good, bad = bad, good
+	}
+	switch status {
+	case good:
+		return Good
+	case bad:
+		return Bad
+	case "Unknown":
+		return Warning
+	}
+	return ""
+}
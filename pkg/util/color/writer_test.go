@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package color
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStatusWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewStatusWriter(buf)
+	// the tab writer writes the lines in several parts
+	for _, part := range []string{"NAME   READY   STATUS", "             RESTARTS\n", "web    1/1     Running            0\n", "db     0/1     CrashLoopBackOff   7\n", "init   0/1     Init:Error"} {
+		if _, err := w.Write([]byte(part)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "NAME   READY   STATUS             RESTARTS\n" +
+		"web    1/1     \x1b[32mRunning\x1b[0m            0\n" +
+		"db     0/1     \x1b[31mCrashLoopBackOff\x1b[0m   7\n" +
+		"init   0/1     \x1b[31mInit:Error\x1b[0m"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, buf.String())
+	}
+}
+
+func TestDiffWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewDiffWriter(buf)
+	if _, err := w.Write([]byte("--- /tmp/LIVE/v1.ConfigMap.default.app\n+++ /tmp/MERGED/v1.ConfigMap.default.app\n@@ -1,3 +1,3 @@\n data:\n-  a: 1\n+  a: 2\n")); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\x1b[36m--- /tmp/LIVE/v1.ConfigMap.default.app\x1b[0m\n" +
+		"\x1b[36m+++ /tmp/MERGED/v1.ConfigMap.default.app\x1b[0m\n" +
+		"\x1b[36m@@ -1,3 +1,3 @@\x1b[0m\n" +
+		" data:\n" +
+		"\x1b[31m-  a: 1\x1b[0m\n" +
+		"\x1b[32m+  a: 2\x1b[0m\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, buf.String())
+	}
+}
+
+func TestLogWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewLogWriter(buf)
+	if _, err := w.Write([]byte("2024-03-01 ERROR connection refused\ntime=now level=warn msg=slow\nstarting INFORMATION ERRORS\n")); err != nil {
+		t.Fatal(err)
+	}
+	expected := "2024-03-01 \x1b[31mERROR\x1b[0m connection refused\n" +
+		"time=now \x1b[33mlevel=warn\x1b[0m msg=slow\n" +
+		"starting INFORMATION ERRORS\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, buf.String())
+	}
+}
+
+func TestConditions(t *testing.T) {
+	text := `Name:         node-1
+Conditions:
+  Type             Status  Reason
+  ----             ------  ------
+  MemoryPressure   False   KubeletHasSufficientMemory
+  Ready            True    KubeletReady
+  DiskPressure     True    KubeletHasDiskPressure
+Addresses:
+  InternalIP:  10.0.0.1
+`
+	expected := `Name:         node-1
+Conditions:
+  Type             Status  Reason
+  ----             ------  ------
+  MemoryPressure   ` + "\x1b[32mFalse\x1b[0m" + `   KubeletHasSufficientMemory
+  Ready            ` + "\x1b[32mTrue\x1b[0m" + `    KubeletReady
+  DiskPressure     ` + "\x1b[31mTrue\x1b[0m" + `    KubeletHasDiskPressure
+Addresses:
+  InternalIP:  10.0.0.1
+`
+	if actual := Conditions(text); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
@@ -23,8 +23,11 @@ import (
 
 	"github.com/spf13/cobra"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/resource"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/rest/fake"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
@@ -354,6 +357,34 @@ func TestPodResourceNameAndContainerCompletionFuncJointFormTooManyArgs(t *testin
 	checkCompletion(t, comps, []string{}, directive, cobra.ShellCompDirectiveNoFileComp)
 }
 
+func TestLabelSelectorCompletionFunc(t *testing.T) {
+	t.Setenv("KUBECACHEDIR", t.TempDir())
+	tf, _ := prepareCompletionTest()
+	tf.FakeDynamicClient = dynamicfake.NewSimpleDynamicClient(scheme.Scheme,
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "test", Labels: map[string]string{"app": "foo", "tier": "web"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "test", Labels: map[string]string{"app": "bar"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "baz", Namespace: "other", Labels: map[string]string{"team": "baz"}}},
+	)
+	compFunc := LabelSelectorCompletionFunc(tf)
+
+	comps, directive := compFunc(nil, []string{}, "")
+	checkCompletion(t, comps, []string{"app=", "tier="}, directive, cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace)
+
+	comps, directive = compFunc(nil, []string{"pods"}, "app=")
+	checkCompletion(t, comps, []string{"app=bar", "app=foo"}, directive, cobra.ShellCompDirectiveNoFileComp)
+
+	comps, directive = compFunc(nil, []string{"pod/foo"}, "tier=web,app!=f")
+	checkCompletion(t, comps, []string{"tier=web,app!=foo"}, directive, cobra.ShellCompDirectiveNoFileComp)
+
+	comps, directive = compFunc(nil, []string{}, "app==b")
+	checkCompletion(t, comps, []string{"app==bar"}, directive, cobra.ShellCompDirectiveNoFileComp)
+
+	// the sampled labels are cached
+	tf.FakeDynamicClient = dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	comps, directive = compFunc(nil, []string{}, "t")
+	checkCompletion(t, comps, []string{"tier="}, directive, cobra.ShellCompDirectiveNoFileComp|cobra.ShellCompDirectiveNoSpace)
+}
+
 func setMockFactory(config api.Config) {
 	clientConfig := clientcmd.NewDefaultClientConfig(config, nil)
 	testFactory := cmdtesting.NewTestFactory().WithClientConfig(clientConfig)
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/homedir"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	// labelSampleSize is the number of objects the labels are sampled from.
+	labelSampleSize = 500
+	// labelCacheTTL is how long the sampled labels are reused for.
+	labelCacheTTL = 5 * time.Minute
+)
+
+// LabelSelectorCompletionFunc Returns a completion function that completes the label keys, and the
+// values of the key before the '=', of the resources of the type specified by the first argument,
+// or of pods when there is no argument.  Only the last requirement of the selector is completed.
+// The labels are sampled from the cluster and cached for a few minutes.
+func LabelSelectorCompletionFunc(f cmdutil.Factory) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		resourceType := "pods"
+		if len(args) > 0 {
+			resourceType = strings.Split(args[0], "/")[0]
+		}
+		prefix, requirement := "", toComplete
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix, requirement = toComplete[:i+1], toComplete[i+1:]
+		}
+		labels := compGetLabels(f, resourceType)
+
+		var comps []string
+		if i := strings.Index(requirement, "="); i >= 0 {
+			key := strings.TrimSuffix(requirement[:i], "!")
+			value := strings.TrimLeft(requirement[i:], "=")
+			operator := requirement[len(key) : len(requirement)-len(value)]
+			for _, v := range labels[key] {
+				if strings.HasPrefix(v, value) {
+					comps = append(comps, prefix+key+operator+v)
+				}
+			}
+			return comps, cobra.ShellCompDirectiveNoFileComp
+		}
+		keys := make([]string, 0, len(labels))
+		for key := range labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if strings.HasPrefix(key, requirement) {
+				comps = append(comps, prefix+key+"=")
+			}
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// compGetLabels returns the values of each label key of the resources of the specified type,
+// sampled from the cluster, or read from the cache when it is recent enough.
+func compGetLabels(f cmdutil.Factory, resourceType string) map[string][]string {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return nil
+	}
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil
+	}
+	cacheFile := filepath.Join(completionCacheDir(), "labels", url.PathEscape(config.Host), namespace, resourceType+".json")
+	if labels, ok := readLabelCache(cacheFile); ok {
+		return labels
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil
+	}
+	gvk, err := mapper.KindFor(schema.ParseGroupResource(resourceType).WithVersion(""))
+	if err != nil {
+		return nil
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil
+	}
+	client, err := f.DynamicClient()
+	if err != nil {
+		return nil
+	}
+	resourceClient := client.Resource(mapping.Resource)
+	listOptions := metav1.ListOptions{Limit: labelSampleSize}
+	var list *unstructured.UnstructuredList
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		list, err = resourceClient.Namespace(namespace).List(context.TODO(), listOptions)
+	} else {
+		list, err = resourceClient.List(context.TODO(), listOptions)
+	}
+	if err != nil {
+		return nil
+	}
+
+	values := map[string]sets.Set[string]{}
+	for _, item := range list.Items {
+		for key, value := range item.GetLabels() {
+			if values[key] == nil {
+				values[key] = sets.New[string]()
+			}
+			values[key].Insert(value)
+		}
+	}
+	labels := map[string][]string{}
+	for key, set := range values {
+		labels[key] = sets.List(set)
+	}
+	writeLabelCache(cacheFile, labels)
+	return labels
+}
+
+// completionCacheDir returns the directory of the completion cache, in the
+// kubectl cache directory.
+func completionCacheDir() string {
+	if dir := os.Getenv("KUBECACHEDIR"); dir != "" {
+		return filepath.Join(dir, "completion")
+	}
+	return filepath.Join(homedir.HomeDir(), ".kube", "cache", "completion")
+}
+
+func readLabelCache(filename string) (map[string][]string, bool) {
+	info, err := os.Stat(filename)
+	if err != nil || time.Since(info.ModTime()) > labelCacheTTL {
+		return nil, false
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, false
+	}
+	labels := map[string][]string{}
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return nil, false
+	}
+	return labels, true
+}
+
+// writeLabelCache caches the labels. Failures are ignored, the labels are
+// then sampled again the next time.
+func writeLabelCache(filename string, labels map[string][]string) {
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil {
+		return
+	}
+	_ = os.WriteFile(filename, data, 0640)
+}
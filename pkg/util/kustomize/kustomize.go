@@ -0,0 +1,436 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kustomize holds the options of the kustomize builds of kubectl:
+// the inflation of Helm charts and the cache of remote bases.
+package kustomize
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// Options are the options of a kustomize build.
+type Options struct {
+	// EnableHelm inflates the helmCharts of the kustomizations with
+	// HelmCommand.
+	EnableHelm  bool
+	HelmCommand string
+	// CacheDir keeps the remote bases once fetched, so that the next builds
+	// use them instead of fetching them again. They are fetched on each build
+	// when empty.
+	CacheDir string
+	// Offline fails the builds needing remote bases missing from the cache.
+	Offline bool
+
+	// RunGit runs git with the arguments in a directory.
+	RunGit func(dir string, args ...string) error
+	// Client downloads the remote files.
+	Client *http.Client
+
+	// fetchErr is the first error fetching a remote base, which kustomize
+	// reports as a missing kustomization.
+	fetchErr error
+}
+
+// NewOptions returns the default options, which build like kustomize does.
+func NewOptions() *Options {
+	return &Options{
+		HelmCommand: "helm",
+		RunGit:      runGit,
+		Client:      http.DefaultClient,
+	}
+}
+
+// AddFlags adds the flags of the options to the command, leaving the flags
+// it already has, such as the ones of the kustomize build command.
+func (o *Options) AddFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	if flags.Lookup("enable-helm") == nil {
+		flags.BoolVar(&o.EnableHelm, "enable-helm", o.EnableHelm, "If true, inflate the Helm charts of kustomizations with the helm command.")
+	}
+	if flags.Lookup("helm-command") == nil {
+		flags.StringVar(&o.HelmCommand, "helm-command", o.HelmCommand, "The helm command (path to executable) inflating the Helm charts of kustomizations.")
+	}
+	flags.BoolVar(&o.Offline, "offline", o.Offline, "If true, build kustomizations without fetching remote bases, from the ones cached in --cache-dir only.")
+}
+
+// Complete caches the remote bases in the kustomize directory of --cache-dir
+// when the flag is set or the build is offline. Builds with unpinned refs
+// keep using the cached bases until the directory is removed.
+func (o *Options) Complete(cmd *cobra.Command) {
+	cacheDir := ""
+	if flag := cmd.Flag("cache-dir"); flag != nil && (flag.Changed || o.Offline) {
+		cacheDir = flag.Value.String()
+	} else if o.Offline {
+		cacheDir = filepath.Join(homedir.HomeDir(), ".kube", "cache")
+	}
+	if len(cacheDir) > 0 {
+		o.CacheDir = filepath.Join(cacheDir, "kustomize")
+	}
+}
+
+// Enabled returns whether the options differ from the builds of the
+// resource builder.
+func (o *Options) Enabled() bool {
+	return o.EnableHelm || len(o.CacheDir) > 0
+}
+
+// FileSystem returns the file system to build with, reading the
+// kustomizations with their remote bases replaced by the cached ones.
+func (o *Options) FileSystem(fSys filesys.FileSystem) filesys.FileSystem {
+	return &cachingFS{FileSystem: fSys, options: o}
+}
+
+// Root returns the kustomization root to build for the path, the cached one
+// for remote bases.
+func (o *Options) Root(path string) (string, error) {
+	if len(o.CacheDir) == 0 {
+		return path, nil
+	}
+	if cached, ok, err := o.resolve(path); err != nil || ok {
+		return cached, err
+	}
+	return path, nil
+}
+
+// Build builds the kustomization at path like the resource builder does,
+// with the options.
+func (o *Options) Build(path string) ([]byte, error) {
+	root, err := o.Root(path)
+	if err != nil {
+		return nil, err
+	}
+	kOpts := krusty.MakeDefaultOptions()
+	kOpts.Reorder = krusty.ReorderOptionLegacy
+	kOpts.PluginConfig.HelmConfig.Enabled = o.EnableHelm
+	kOpts.PluginConfig.HelmConfig.Command = o.HelmCommand
+	m, err := krusty.MakeKustomizer(kOpts).Run(o.FileSystem(filesys.MakeFsOnDisk()), root)
+	if err != nil {
+		return nil, o.Error(err)
+	}
+	return m.AsYaml()
+}
+
+// Error returns the error fetching a remote base of a failed build, or err.
+func (o *Options) Error(err error) error {
+	if err != nil && o.fetchErr != nil {
+		return o.fetchErr
+	}
+	return err
+}
+
+// BuildFilenameOptions returns the filename options to read the objects of
+// the kustomization of options from, when the options are enabled: a file
+// holding the output of the build, removed by the returned function.
+func (o *Options) BuildFilenameOptions(options *resource.FilenameOptions) (*resource.FilenameOptions, func(), error) {
+	if len(options.Kustomize) == 0 || !o.Enabled() {
+		return options, func() {}, nil
+	}
+	data, err := o.Build(options.Kustomize)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	f, err := os.CreateTemp("", "kubectl-kustomize-*.yaml")
+	if err != nil {
+		return nil, func() {}, err
+	}
+	cleanup := func() { os.Remove(f.Name()) }
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, func() {}, err
+	}
+	return &resource.FilenameOptions{Filenames: []string{f.Name()}}, cleanup, nil
+}
+
+// cachingFS replaces the remote bases of the kustomizations it reads with
+// the cached ones.
+type cachingFS struct {
+	filesys.FileSystem
+	options *Options
+}
+
+func (fs *cachingFS) ReadFile(name string) ([]byte, error) {
+	data, err := fs.FileSystem.ReadFile(name)
+	if err != nil || len(fs.options.CacheDir) == 0 || !isKustomization(filepath.Base(name)) {
+		return data, err
+	}
+	data, err = fs.options.rewrite(data, filepath.Dir(name))
+	if err != nil && fs.options.fetchErr == nil {
+		fs.options.fetchErr = err
+	}
+	return data, err
+}
+
+func isKustomization(name string) bool {
+	for _, kustomization := range konfig.RecognizedKustomizationFileNames() {
+		if name == kustomization {
+			return true
+		}
+	}
+	return false
+}
+
+// rewrite replaces the remote entries of the resources, bases and
+// components of the kustomization in dir with the cached ones, relative to
+// dir since kustomize does not load absolute roots.
+func (o *Options) rewrite(data []byte, dir string) ([]byte, error) {
+	kustomization := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &kustomization); err != nil {
+		// left to kustomize to report
+		return data, nil
+	}
+	changed := false
+	for _, field := range []string{"resources", "bases", "components"} {
+		entries, _ := kustomization[field].([]interface{})
+		for i, entry := range entries {
+			s, ok := entry.(string)
+			if !ok {
+				continue
+			}
+			cached, ok, err := o.resolve(s)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			if entries[i], err = relativePath(dir, cached); err != nil {
+				return nil, err
+			}
+			changed = true
+		}
+	}
+	if !changed {
+		return data, nil
+	}
+	return yaml.Marshal(kustomization)
+}
+
+func relativePath(dir, target string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(absDir, absTarget)
+}
+
+// resolve returns the cached directory of a remote base or file, fetching
+// it when missing from the cache.
+func (o *Options) resolve(s string) (string, bool, error) {
+	if file, ok := remoteFile(s); ok {
+		dir := o.cachePath("files", s)
+		if err := o.fetch(s, dir, func(tmp string) error { return o.download(file, tmp) }); err != nil {
+			return "", false, err
+		}
+		return dir, true, nil
+	}
+
+	base, ok := parseRemoteBase(s)
+	if !ok {
+		return "", false, nil
+	}
+	dir := o.cachePath("git", base.repository+"?ref="+base.ref)
+	if err := o.fetch(s, dir, func(tmp string) error { return o.clone(base, tmp) }); err != nil {
+		return "", false, err
+	}
+	root := filepath.Join(dir, filepath.FromSlash(base.path))
+	if rel, err := filepath.Rel(dir, root); err != nil || strings.HasPrefix(rel, "..") {
+		return "", false, fmt.Errorf("the path of %s is outside of its repository", s)
+	}
+	return root, true, nil
+}
+
+func (o *Options) cachePath(kind, key string) string {
+	return filepath.Join(o.CacheDir, kind, fmt.Sprintf("%x", sha256.Sum256([]byte(key)))[:32])
+}
+
+// fetch fetches s into dir unless it is cached. The fetched files are moved
+// into dir once complete, so that failed fetches are not cached.
+func (o *Options) fetch(s, dir string, fetch func(tmp string) error) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if o.Offline {
+		return fmt.Errorf("%s is not in the cache %s and --offline is set", s, o.CacheDir)
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0750); err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), filepath.Base(dir)+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := fetch(tmp); err != nil {
+		return fmt.Errorf("unable to fetch %s: %v", s, err)
+	}
+	return os.Rename(tmp, dir)
+}
+
+// clone checks out the ref of the repository like kustomize does.
+func (o *Options) clone(base remoteBase, dir string) error {
+	ref := base.ref
+	if len(ref) == 0 {
+		ref = "HEAD"
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth=1", base.repository, ref},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		if err := o.RunGit(dir, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// download writes the file into dir, with a kustomization listing it, so
+// that it can be loaded from outside of the kustomization root.
+func (o *Options) download(file *url.URL, dir string) error {
+	resp, err := o.Client.Get(file.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", file, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	name := path.Base(file.Path)
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0640); err != nil {
+		return err
+	}
+	kustomization := fmt.Sprintf("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n- %s\n", name)
+	return os.WriteFile(filepath.Join(dir, konfig.DefaultKustomizationFileName()), []byte(kustomization), 0640)
+}
+
+// remoteFile returns the URL of an entry of a YAML or JSON file served over
+// http.
+func remoteFile(s string) (*url.URL, bool) {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || strings.Contains(u.Path, "//") {
+		return nil, false
+	}
+	switch path.Ext(u.Path) {
+	case ".yaml", ".yml", ".json":
+		return u, true
+	}
+	return nil, false
+}
+
+// remoteBase is a directory of a git repository at a ref.
+type remoteBase struct {
+	repository string
+	ref        string
+	path       string
+}
+
+// parseRemoteBase parses the remote bases kustomize accepts, such as
+// https://github.com/org/repo//path?ref=v1.2, github.com/org/repo/path or
+// git@github.com:org/repo.git/path.
+func parseRemoteBase(s string) (remoteBase, bool) {
+	s, query, _ := strings.Cut(strings.TrimPrefix(s, "git::"), "?")
+	values, _ := url.ParseQuery(query)
+	base := remoteBase{ref: values.Get("ref")}
+	if len(base.ref) == 0 {
+		base.ref = values.Get("version")
+	}
+
+	host := ""
+	switch {
+	case strings.HasPrefix(s, "https://"), strings.HasPrefix(s, "http://"), strings.HasPrefix(s, "ssh://"):
+		start := strings.Index(s, "://") + len("://")
+		end := strings.Index(s[start:], "/")
+		if end < 0 {
+			return remoteBase{}, false
+		}
+		host, s = s[:start+end+1], s[start+end+1:]
+	case strings.HasPrefix(s, "git@"):
+		end := strings.Index(s, ":")
+		if end < 0 {
+			return remoteBase{}, false
+		}
+		host, s = s[:end+1], s[end+1:]
+	case strings.HasPrefix(s, "github.com/"):
+		host, s = "https://github.com/", strings.TrimPrefix(s, "github.com/")
+	default:
+		return remoteBase{}, false
+	}
+
+	repository := ""
+	switch {
+	case strings.Contains(s, "_git/"):
+		i := strings.Index(s, "_git/") + len("_git/")
+		name, rest, _ := strings.Cut(s[i:], "/")
+		repository, base.path = s[:i]+name, rest
+	case strings.Contains(s, "//"):
+		repository, base.path, _ = strings.Cut(s, "//")
+	case strings.Contains(s, ".git"):
+		i := strings.Index(s, ".git") + len(".git")
+		repository, base.path = s[:i], strings.TrimPrefix(s[i:], "/")
+	default:
+		segments := strings.Split(s, "/")
+		if len(segments) < 2 {
+			return remoteBase{}, false
+		}
+		repository, base.path = strings.Join(segments[:2], "/"), strings.Join(segments[2:], "/")
+	}
+	if len(repository) == 0 {
+		return remoteBase{}, false
+	}
+	base.repository = host + repository
+	return base, true
+}
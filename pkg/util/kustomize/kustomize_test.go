@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kustomize
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+func TestParseRemoteBase(t *testing.T) {
+	tests := []struct {
+		source string
+		want   remoteBase
+		wantOK bool
+	}{
+		{
+			source: "https://github.com/org/repo//deploy/base?ref=v1.2",
+			want:   remoteBase{repository: "https://github.com/org/repo", ref: "v1.2", path: "deploy/base"},
+			wantOK: true,
+		},
+		{
+			source: "github.com/org/repo/deploy/base?version=v1.2",
+			want:   remoteBase{repository: "https://github.com/org/repo", ref: "v1.2", path: "deploy/base"},
+			wantOK: true,
+		},
+		{
+			source: "git@github.com:org/repo.git/deploy",
+			want:   remoteBase{repository: "git@github.com:org/repo.git", path: "deploy"},
+			wantOK: true,
+		},
+		{
+			source: "https://dev.azure.com/org/project/_git/repo/deploy?ref=main",
+			want:   remoteBase{repository: "https://dev.azure.com/org/project/_git/repo", ref: "main", path: "deploy"},
+			wantOK: true,
+		},
+		{
+			source: "git::https://example.com/org/repo",
+			want:   remoteBase{repository: "https://example.com/org/repo"},
+			wantOK: true,
+		},
+		{source: "../base"},
+		{source: "deployment.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			got, ok := parseRemoteBase(tt.source)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("expected %+v, %v, got %+v, %v", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBuildCachesRemoteBases(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"kustomization.yaml": fmt.Sprintf("namePrefix: prod-\nresources:\n- https://github.com/org/repo//base?ref=v1\n- %s/service.yaml\n", server.URL),
+	})
+
+	clones := 0
+	o := NewOptions()
+	o.Client = server.Client()
+	o.CacheDir = filepath.Join(t.TempDir(), "kustomize")
+	o.RunGit = func(dir string, args ...string) error {
+		if args[0] == "fetch" {
+			if args[len(args)-2] != "https://github.com/org/repo" || args[len(args)-1] != "v1" {
+				return fmt.Errorf("unexpected fetch %v", args)
+			}
+			clones++
+		}
+		if args[0] == "checkout" {
+			writeFiles(t, dir, map[string]string{
+				"base/kustomization.yaml": "resources:\n- configmap.yaml\n",
+				"base/configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n",
+			})
+		}
+		return nil
+	}
+
+	for i := 0; i < 2; i++ {
+		data, err := o.Build(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"kind: ConfigMap", "name: prod-web", "kind: Service"} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("expected %q in the build, got:\n%s", want, data)
+			}
+		}
+	}
+	if clones != 1 {
+		t.Errorf("expected the base to be cloned once, got %d", clones)
+	}
+
+	o.Offline = true
+	if _, err := o.Build(dir); err != nil {
+		t.Errorf("expected the offline build to use the cache, got %v", err)
+	}
+	o.CacheDir = filepath.Join(t.TempDir(), "kustomize")
+	if _, err := o.Build(dir); err == nil || !strings.Contains(err.Error(), "--offline is set") {
+		t.Errorf("expected the offline build to fail without a cache, got %v", err)
+	}
+}
+
+func TestBuildFilenameOptions(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"kustomization.yaml": "resources:\n- configmap.yaml\n",
+		"configmap.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n",
+	})
+
+	o := NewOptions()
+	options := &resource.FilenameOptions{Kustomize: dir}
+	got, cleanup, err := o.BuildFilenameOptions(options)
+	cleanup()
+	if err != nil || got != options {
+		t.Errorf("expected the options to be left to the builder when disabled, got %v, %v", got, err)
+	}
+
+	o.CacheDir = t.TempDir()
+	got, cleanup, err = o.BuildFilenameOptions(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Kustomize) != 0 || len(got.Filenames) != 1 {
+		t.Fatalf("expected a single built file, got %+v", got)
+	}
+	data, err := os.ReadFile(got.Filenames[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "kind: ConfigMap") {
+		t.Errorf("unexpected build:\n%s", data)
+	}
+	cleanup()
+	if _, err := os.Stat(got.Filenames[0]); !os.IsNotExist(err) {
+		t.Errorf("expected the build to be removed, got %v", err)
+	}
+}
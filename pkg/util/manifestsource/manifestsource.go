@@ -0,0 +1,352 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package manifestsource fetches manifests from remote sources, such as OCI
+// artifacts and git repositories, so that they can be read by the resource
+// builder like local files.
+package manifestsource
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/distribution/reference"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/util/registry"
+)
+
+const (
+	// OCIPrefix prefixes the references of OCI artifacts, such as
+	// oci://registry.example.com/manifests/app:v1.2.
+	OCIPrefix = "oci://"
+	// GitPrefix prefixes the URLs of git repositories, such as
+	// git::https://github.com/org/repo//deploy?ref=v1.2.
+	GitPrefix = "git::"
+
+	ociTitleAnnotation = "org.opencontainers.image.title"
+)
+
+var ociManifestTypes = []string{
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Fetcher fetches the manifests of remote sources into local files.
+type Fetcher struct {
+	// Registry is used for the requests to registries and web servers, with
+	// the credentials of the docker config file. It is created when first
+	// needed if nil.
+	Registry *registry.Client
+	// RunGit runs git with the arguments.
+	RunGit func(args ...string) error
+}
+
+// NewFetcher returns a Fetcher using the credentials of the docker
+// configuration and the git command.
+func NewFetcher() *Fetcher {
+	return &Fetcher{RunGit: runGit}
+}
+
+// Fetch fetches the remote sources of the options with a new Fetcher.
+func Fetch(options *resource.FilenameOptions) (func(), error) {
+	return NewFetcher().Fetch(options)
+}
+
+// Fetch replaces the remote sources among the filenames of the options with
+// the local paths they are fetched to, in a temporary directory removed by the
+// returned function. https URLs are left to the resource builder, unless
+// credentials are found for their host. A failure to look the credentials up,
+// such as an invalid docker config or a missing credential helper, is treated
+// as no credentials.
+func (f *Fetcher) Fetch(options *resource.FilenameOptions) (func(), error) {
+	cleanup := func() {}
+	dir := ""
+	for i, filename := range options.Filenames {
+		if !strings.HasPrefix(filename, OCIPrefix) && !strings.HasPrefix(filename, GitPrefix) {
+			if !f.hasCredentials(filename) {
+				continue
+			}
+		}
+		if len(dir) == 0 {
+			var err error
+			if dir, err = os.MkdirTemp("", "kubectl-manifests-"); err != nil {
+				return cleanup, err
+			}
+			cleanup = func() { os.RemoveAll(dir) }
+		}
+
+		target := filepath.Join(dir, fmt.Sprint(i))
+		var err error
+		switch {
+		case strings.HasPrefix(filename, OCIPrefix):
+			err = f.fetchOCI(strings.TrimPrefix(filename, OCIPrefix), target)
+		case strings.HasPrefix(filename, GitPrefix):
+			target, err = f.fetchGit(strings.TrimPrefix(filename, GitPrefix), target)
+		default:
+			target, err = f.fetchURL(filename, target)
+		}
+		if err != nil {
+			cleanup()
+			return func() {}, fmt.Errorf("unable to fetch %s: %v", filename, err)
+		}
+		options.Filenames[i] = target
+	}
+	return cleanup, nil
+}
+
+// registry returns the registry client, creating it if needed.
+func (f *Fetcher) registry() (*registry.Client, error) {
+	if f.Registry == nil {
+		client, err := registry.NewClient()
+		if err != nil {
+			return nil, err
+		}
+		f.Registry = client
+	}
+	return f.Registry, nil
+}
+
+// hasCredentials returns true if the docker config holds credentials for the
+// host of the https URL.
+func (f *Fetcher) hasCredentials(filename string) bool {
+	if !strings.HasPrefix(filename, "https://") {
+		return false
+	}
+	u, err := url.Parse(filename)
+	if err != nil {
+		return false
+	}
+	client, err := f.registry()
+	if err != nil {
+		klog.V(2).Infof("Unable to load the docker config, fetching %s without credentials: %v", filename, err)
+		return false
+	}
+	_, _, found, err := client.Credentials(u.Host)
+	if err != nil {
+		klog.V(2).Infof("Unable to get the credentials of %s, fetching %s without credentials: %v", u.Host, filename, err)
+		return false
+	}
+	return found
+}
+
+// fetchURL downloads the file of the URL into dir with the credentials of its
+// host, and returns its path.
+func (f *Fetcher) fetchURL(rawURL, dir string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	username, password, _, err := f.Registry.Credentials(u.Host)
+	if err != nil {
+		return "", err
+	}
+	data, err := f.get(rawURL, username, password)
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		name = "manifest.yaml"
+	}
+	return writeFile(dir, name, data)
+}
+
+// fetchOCI writes the layers of the artifact into dir. Tar layers are
+// extracted, other layers are written to the file named by their title
+// annotation.
+func (f *Fetcher) fetchOCI(ref, dir string) error {
+	repository, tag, err := parseOCIReference(ref)
+	if err != nil {
+		return err
+	}
+	client, err := f.registry()
+	if err != nil {
+		return err
+	}
+	manifestData, err := client.Manifest(repository, tag, ociManifestTypes...)
+	if err != nil {
+		return err
+	}
+	manifest := struct {
+		Layers []struct {
+			MediaType   string            `json:"mediaType"`
+			Digest      string            `json:"digest"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"layers"`
+	}{}
+	if err := json.Unmarshal(manifestData.Data, &manifest); err != nil {
+		return fmt.Errorf("invalid manifest: %v", err)
+	}
+	if len(manifest.Layers) == 0 {
+		return fmt.Errorf("the artifact has no layers")
+	}
+
+	for _, layer := range manifest.Layers {
+		blob, err := client.Blob(repository, layer.Digest)
+		if err != nil {
+			return err
+		}
+		switch {
+		case strings.HasSuffix(layer.MediaType, "tar+gzip"), strings.HasSuffix(layer.MediaType, "tar.gzip"):
+			gz, err := gzip.NewReader(bytes.NewReader(blob))
+			if err != nil {
+				return err
+			}
+			err = extractTar(gz, dir)
+		case strings.HasSuffix(layer.MediaType, ".tar"):
+			err = extractTar(bytes.NewReader(blob), dir)
+		default:
+			name := layer.Annotations[ociTitleAnnotation]
+			if len(name) == 0 {
+				name = strings.TrimPrefix(layer.Digest, "sha256:") + ".yaml"
+			}
+			_, err = writeFile(dir, path.Base(name), blob)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseOCIReference splits a reference of the form host/repository[:tag] or
+// host/repository@digest into the repository and the tag or digest.
+func parseOCIReference(ref string) (reference.Named, string, error) {
+	named, err := reference.ParseNamed(ref)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid OCI reference %q, must be of the form oci://HOST/REPOSITORY[:TAG]: %v", ref, err)
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		return reference.TrimNamed(named), digested.Digest().String(), nil
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		return reference.TrimNamed(named), tagged.Tag(), nil
+	}
+	return named, "latest", nil
+}
+
+// fetchGit clones the repository into dir and returns the path of the
+// directory given after // in the URL.
+func (f *Fetcher) fetchGit(source, dir string) (string, error) {
+	repository, ref := source, ""
+	if i := strings.Index(repository, "?"); i >= 0 {
+		query, err := url.ParseQuery(repository[i+1:])
+		if err != nil {
+			return "", err
+		}
+		repository, ref = repository[:i], query.Get("ref")
+	}
+	subPath := ""
+	start := 0
+	if i := strings.Index(repository, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	if i := strings.Index(repository[start:], "//"); i >= 0 {
+		repository, subPath = repository[:start+i], repository[start+i+2:]
+	}
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if len(ref) > 0 {
+		args = append(args, "--branch", ref)
+	}
+	if err := f.RunGit(append(args, "--", repository, dir)...); err != nil {
+		return "", err
+	}
+	target := filepath.Join(dir, filepath.FromSlash(subPath))
+	if !within(dir, target) {
+		return "", fmt.Errorf("the path %q is outside of the repository", subPath)
+	}
+	return target, nil
+}
+
+func runGit(args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (f *Fetcher) get(rawURL, username, password string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(username, password)
+	resp, err := f.Registry.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// extractTar extracts the regular files of the archive into dir.
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !within(dir, target) {
+			return fmt.Errorf("the archive entry %q is outside of the archive", header.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if _, err := writeFile(filepath.Dir(target), filepath.Base(target), data); err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(dir, name string, data []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	target := filepath.Join(dir, name)
+	return target, os.WriteFile(target, data, 0600)
+}
+
+func within(dir, target string) bool {
+	rel, err := filepath.Rel(dir, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manifestsource
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kubectl/pkg/util/registry"
+)
+
+const deployment = "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n"
+
+func tarGzip(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func digest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+// registryServer serves an artifact with a layer of each kind, behind a bearer
+// token obtained with the credentials user:secret.
+func registryServer(t *testing.T, corrupt bool) *httptest.Server {
+	archive := tarGzip(t, map[string]string{"deploy/deployment.yaml": deployment})
+	service := []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n")
+	blobs := map[string][]byte{digest(archive): archive, digest(service): service}
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"layers": []map[string]interface{}{
+			{"mediaType": "application/vnd.oci.image.layer.v1.tar+gzip", "digest": digest(archive)},
+			{"mediaType": "application/yaml", "digest": digest(service), "annotations": map[string]string{ociTitleAnnotation: "service.yaml"}},
+		},
+	})
+	if corrupt {
+		blobs[digest(service)] = []byte("kind: Secret")
+	}
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if r.URL.Query().Get("scope") != "repository:manifests/web:pull" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"token":"t0k3n"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:manifests/web:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v2/manifests/web/manifests/v1":
+			w.Write(manifest)
+		case strings.HasPrefix(r.URL.Path, "/v2/manifests/web/blobs/"):
+			blob, ok := blobs[strings.TrimPrefix(r.URL.Path, "/v2/manifests/web/blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(blob)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+// newRegistry returns a registry client sending its requests to the server
+// with the credentials user:secret for it in the docker config.
+func newRegistry(t *testing.T, server *httptest.Server) *registry.Client {
+	dir := t.TempDir()
+	config := fmt.Sprintf(`{"auths": {%q: {"auth": "dXNlcjpzZWNyZXQ="}}}`, strings.TrimPrefix(server.URL, "https://"))
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_CONFIG", dir)
+	client, err := registry.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.HTTPClient = server.Client()
+	return client
+}
+
+func TestFetchOCI(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt bool
+		wantErr string
+	}{
+		{name: "artifact"},
+		{name: "corrupt layer", corrupt: true, wantErr: "has the digest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := registryServer(t, tt.corrupt)
+			defer server.Close()
+			host := strings.TrimPrefix(server.URL, "https://")
+			f := &Fetcher{Registry: newRegistry(t, server)}
+
+			options := &resource.FilenameOptions{Filenames: []string{"local.yaml", OCIPrefix + host + "/manifests/web:v1"}}
+			cleanup, err := f.Fetch(options)
+			defer cleanup()
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if options.Filenames[0] != "local.yaml" {
+				t.Errorf("expected the local file to be kept, got %q", options.Filenames[0])
+			}
+			for name, want := range map[string]string{"deploy/deployment.yaml": deployment, "service.yaml": "kind: Service"} {
+				data, err := os.ReadFile(filepath.Join(options.Filenames[1], name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.Contains(string(data), want) {
+					t.Errorf("unexpected content of %s: %q", name, data)
+				}
+			}
+
+			cleanup()
+			if _, err := os.Stat(options.Filenames[1]); !os.IsNotExist(err) {
+				t.Errorf("expected the fetched files to be removed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFetchGit(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		wantArgs []string
+		wantPath string
+		wantErr  string
+	}{
+		{
+			name:     "sub directory at a ref",
+			source:   "git::https://example.com/org/repo//deploy/prod?ref=v1.2",
+			wantArgs: []string{"clone", "--quiet", "--depth", "1", "--branch", "v1.2", "--", "https://example.com/org/repo"},
+			wantPath: "deploy/prod",
+		},
+		{
+			name:     "repository root",
+			source:   "git::https://example.com/org/repo",
+			wantArgs: []string{"clone", "--quiet", "--depth", "1", "--", "https://example.com/org/repo"},
+		},
+		{
+			name:    "path outside of the repository",
+			source:  "git::https://example.com/org/repo//../..",
+			wantErr: "outside of the repository",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []string
+			f := &Fetcher{RunGit: func(a ...string) error {
+				args = a
+				return os.MkdirAll(a[len(a)-1], 0700)
+			}}
+			options := &resource.FilenameOptions{Filenames: []string{tt.source}}
+			cleanup, err := f.Fetch(options)
+			defer cleanup()
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := args[len(args)-1]
+			if !reflect.DeepEqual(args[:len(args)-1], tt.wantArgs) {
+				t.Errorf("expected git %v, got %v", tt.wantArgs, args[:len(args)-1])
+			}
+			if want := filepath.Join(dir, tt.wantPath); options.Filenames[0] != want {
+				t.Errorf("expected %q, got %q", want, options.Filenames[0])
+			}
+		})
+	}
+}
+
+func TestFetchURL(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, deployment)
+	}))
+	defer server.Close()
+
+	f := &Fetcher{Registry: newRegistry(t, server)}
+	options := &resource.FilenameOptions{Filenames: []string{server.URL + "/manifests/deployment.yaml", "https://example.com/public.yaml"}}
+	cleanup, err := f.Fetch(options)
+	defer cleanup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(options.Filenames[0]) != "deployment.yaml" {
+		t.Errorf("expected a local deployment.yaml, got %q", options.Filenames[0])
+	}
+	data, err := os.ReadFile(options.Filenames[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != deployment {
+		t.Errorf("unexpected content: %q", data)
+	}
+	if options.Filenames[1] != "https://example.com/public.yaml" {
+		t.Errorf("expected the URL without credentials to be left to the builder, got %q", options.Filenames[1])
+	}
+}
+
+func TestFetchURLWithoutCredentials(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{name: "malformed docker config", config: `{"auths": `},
+		{name: "missing credential helper", config: `{"credsStore": "kubectl-test-missing"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(tt.config), 0600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("DOCKER_CONFIG", dir)
+
+			options := &resource.FilenameOptions{Filenames: []string{"https://example.com/public.yaml"}}
+			cleanup, err := NewFetcher().Fetch(options)
+			defer cleanup()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if options.Filenames[0] != "https://example.com/public.yaml" {
+				t.Errorf("expected the URL to be left to the builder, got %q", options.Filenames[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy evaluates client-side policies against objects before they
+// are mutated, such as forbidding images with the latest tag or requiring
+// labels.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	// ActionDeny fails the command when the policy is violated.
+	ActionDeny = "deny"
+	// ActionWarn prints a warning when the policy is violated.
+	ActionWarn = "warn"
+
+	// OperationApply is the operation of kubectl apply.
+	OperationApply = "apply"
+	// OperationDelete is the operation of kubectl delete.
+	OperationDelete = "delete"
+)
+
+// Policy is a guardrail evaluated against the objects before they are
+// mutated.
+type Policy struct {
+	Name string `json:"name"`
+	// Action is what to do when the policy is violated, deny by default.
+	Action string `json:"action,omitempty"`
+	// Kinds are the kinds of the objects the policy applies to, as KIND or
+	// KIND.GROUP, all kinds by default.
+	Kinds []string `json:"kinds,omitempty"`
+	// Operations are the operations the policy applies to, all by default.
+	Operations []string `json:"operations,omitempty"`
+	Rules      []Rule   `json:"rules"`
+
+	file string
+}
+
+// Rule checks the values found at a JSONPath of the objects.
+type Rule struct {
+	Path string `json:"path"`
+	// Required rules are violated when no value is found.
+	Required bool `json:"required,omitempty"`
+	// Pattern is a regular expression all the values must match.
+	Pattern string `json:"pattern,omitempty"`
+	// ForbiddenPattern is a regular expression no value may match.
+	ForbiddenPattern string `json:"forbiddenPattern,omitempty"`
+	// Message replaces the default message of the violations.
+	Message string `json:"message,omitempty"`
+
+	path             *jsonpath.JSONPath
+	pattern          *regexp.Regexp
+	forbiddenPattern *regexp.Regexp
+}
+
+// Violation is a violation of a policy by an object.
+type Violation struct {
+	Policy  string
+	Action  string
+	Message string
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("violates the policy %s: %s", v.Policy, v.Message)
+}
+
+// Load loads the policies of the YAML and JSON files of the directory, a
+// file holding one or more policies. An empty directory name loads no
+// policy.
+func Load(dir string) ([]*Policy, error) {
+	if len(dir) == 0 {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	policies := []*Policy{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+		case ".cel", ".rego":
+			// failing is safer than silently skipping the guardrails
+			return nil, fmt.Errorf("%s: CEL and Rego policies are not supported, the policies must be YAML or JSON rules", file)
+		default:
+			continue
+		}
+		filePolicies, err := loadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		policies = append(policies, filePolicies...)
+	}
+	return policies, nil
+}
+
+func loadFile(file string) ([]*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	policies := []*Policy{}
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		p := &Policy{}
+		if err := decoder.Decode(p); err != nil {
+			if errors.Is(err, io.EOF) {
+				return policies, nil
+			}
+			return nil, err
+		}
+		if len(p.Name) == 0 && len(p.Rules) == 0 {
+			// empty documents
+			continue
+		}
+		p.file = file
+		if err := p.compile(); err != nil {
+			return nil, fmt.Errorf("policy %q: %v", p.Name, err)
+		}
+		policies = append(policies, p)
+	}
+}
+
+func (p *Policy) compile() error {
+	if len(p.Name) == 0 {
+		return fmt.Errorf("a name is required")
+	}
+	switch p.Action {
+	case "":
+		p.Action = ActionDeny
+	case ActionDeny, ActionWarn:
+	default:
+		return fmt.Errorf("invalid action %q, must be one of: %s, %s", p.Action, ActionDeny, ActionWarn)
+	}
+	for _, operation := range p.Operations {
+		if operation != OperationApply && operation != OperationDelete {
+			return fmt.Errorf("invalid operation %q, must be one of: %s, %s", operation, OperationApply, OperationDelete)
+		}
+	}
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("at least one rule is required")
+	}
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		path := r.Path
+		if !strings.HasPrefix(path, "{") {
+			path = "{" + path + "}"
+		}
+		r.path = jsonpath.New(p.Name).AllowMissingKeys(true)
+		if err := r.path.Parse(path); err != nil {
+			return fmt.Errorf("invalid path %q: %v", r.Path, err)
+		}
+		var err error
+		if len(r.Pattern) > 0 {
+			if r.pattern, err = regexp.Compile(r.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern %q: %v", r.Pattern, err)
+			}
+		}
+		if len(r.ForbiddenPattern) > 0 {
+			if r.forbiddenPattern, err = regexp.Compile(r.ForbiddenPattern); err != nil {
+				return fmt.Errorf("invalid forbidden pattern %q: %v", r.ForbiddenPattern, err)
+			}
+		}
+		if !r.Required && r.pattern == nil && r.forbiddenPattern == nil {
+			return fmt.Errorf("the rule of path %q checks nothing, it must be required or have a pattern or a forbidden pattern", r.Path)
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the violations of the policies by the object for the
+// operation.
+func Evaluate(policies []*Policy, operation string, obj runtime.Object) ([]Violation, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	groupKind := obj.GetObjectKind().GroupVersionKind().GroupKind()
+
+	violations := []Violation{}
+	for _, p := range policies {
+		if !p.appliesTo(operation, groupKind) {
+			continue
+		}
+		for _, r := range p.Rules {
+			messages, err := r.evaluate(content)
+			if err != nil {
+				return nil, fmt.Errorf("policy %q of %s: %v", p.Name, p.file, err)
+			}
+			for _, message := range messages {
+				violations = append(violations, Violation{Policy: p.Name, Action: p.Action, Message: message})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// Check evaluates the policies against the objects for the operation. The
+// violations of the warning policies are printed to out, and an error
+// listing the violations of the denying policies is returned.
+func Check(policies []*Policy, operation string, infos []*resource.Info, out io.Writer) error {
+	if len(policies) == 0 {
+		return nil
+	}
+	errs := []error{}
+	for _, info := range infos {
+		violations, err := Evaluate(policies, operation, info.Object)
+		if err != nil {
+			return err
+		}
+		for _, v := range violations {
+			if v.Action == ActionWarn {
+				fmt.Fprintf(out, "Warning: %s %v\n", info.ObjectName(), v)
+			} else {
+				errs = append(errs, fmt.Errorf("%s %v", info.ObjectName(), v))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func (p *Policy) appliesTo(operation string, groupKind schema.GroupKind) bool {
+	if len(p.Operations) > 0 && !sets.New(p.Operations...).Has(operation) {
+		return false
+	}
+	if len(p.Kinds) == 0 {
+		return true
+	}
+	for _, kind := range p.Kinds {
+		// the kinds without group match the kinds of any group
+		k := schema.ParseGroupKind(kind)
+		if strings.EqualFold(k.Kind, groupKind.Kind) && (len(k.Group) == 0 || k.Group == groupKind.Group) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate returns the messages of the violations of the rule.
+func (r *Rule) evaluate(content map[string]interface{}) ([]string, error) {
+	results, err := r.path.FindResults(content)
+	if err != nil {
+		return nil, err
+	}
+	values := []string{}
+	for _, result := range results {
+		for _, value := range result {
+			if value.IsValid() && value.CanInterface() && value.Interface() != nil {
+				values = append(values, fmt.Sprint(value.Interface()))
+			}
+		}
+	}
+
+	messages := sets.New[string]()
+	message := func(format string, a ...interface{}) {
+		if len(r.Message) > 0 {
+			messages.Insert(r.Message)
+		} else {
+			messages.Insert(fmt.Sprintf(format, a...))
+		}
+	}
+	if r.Required && (len(values) == 0 || (len(values) == 1 && len(values[0]) == 0)) {
+		message("%s is required", r.Path)
+	}
+	for _, value := range values {
+		if r.pattern != nil && !r.pattern.MatchString(value) {
+			message("%s %q does not match %q", r.Path, value, r.Pattern)
+		}
+		if r.forbiddenPattern != nil && r.forbiddenPattern.MatchString(value) {
+			message("%s %q matches %q", r.Path, value, r.ForbiddenPattern)
+		}
+	}
+	return sets.List(messages), nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+const testPolicies = `
+name: no-latest-images
+kinds: [Pod, Deployment.apps]
+rules:
+- path: "{..containers[*].image}"
+  forbiddenPattern: ":latest$"
+---
+name: team-label
+action: warn
+operations: [apply]
+rules:
+- path: .metadata.labels.team
+  required: true
+  message: the team label is required
+- path: .metadata.labels.team
+  pattern: "^[a-z]+$"
+`
+
+func writePolicies(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func newDeployment(labels map[string]string, images ...string) *unstructured.Unstructured {
+	containers := []interface{}{}
+	for _, image := range images {
+		containers = append(containers, map[string]interface{}{"image": image})
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{"spec": map[string]interface{}{"containers": containers}},
+		},
+	}}
+	obj.SetLabels(labels)
+	return obj
+}
+
+func TestEvaluate(t *testing.T) {
+	policies, err := Load(writePolicies(t, map[string]string{"policies.yaml": testPolicies, "README.md": "ignored"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		operation string
+		obj       *unstructured.Unstructured
+		expected  []Violation
+	}{
+		{
+			name:      "compliant",
+			operation: OperationApply,
+			obj:       newDeployment(map[string]string{"team": "shop"}, "nginx:1.25"),
+			expected:  []Violation{},
+		},
+		{
+			name:      "violations",
+			operation: OperationApply,
+			obj:       newDeployment(map[string]string{"team": "Shop"}, "nginx:1.25", "sidecar:latest"),
+			expected: []Violation{
+				{Policy: "no-latest-images", Action: ActionDeny, Message: `{..containers[*].image} "sidecar:latest" matches ":latest$"`},
+				{Policy: "team-label", Action: ActionWarn, Message: `.metadata.labels.team "Shop" does not match "^[a-z]+$"`},
+			},
+		},
+		{
+			name:      "missing label",
+			operation: OperationApply,
+			obj:       newDeployment(nil, "nginx:1.25"),
+			expected:  []Violation{{Policy: "team-label", Action: ActionWarn, Message: "the team label is required"}},
+		},
+		{
+			name:      "other operation",
+			operation: OperationDelete,
+			obj:       newDeployment(nil, "nginx:latest"),
+			expected:  []Violation{{Policy: "no-latest-images", Action: ActionDeny, Message: `{..containers[*].image} "nginx:latest" matches ":latest$"`}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations, err := Evaluate(policies, test.operation, test.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(violations, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, violations)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := map[string]struct {
+		files       map[string]string
+		expectedErr string
+	}{
+		"rego": {
+			files:       map[string]string{"images.rego": "package kubernetes"},
+			expectedErr: "CEL and Rego policies are not supported",
+		},
+		"invalid action": {
+			files:       map[string]string{"a.yaml": "name: a\naction: block\nrules: [{path: .metadata.name, required: true}]"},
+			expectedErr: `policy "a": invalid action "block"`,
+		},
+		"rule checking nothing": {
+			files:       map[string]string{"a.yaml": "name: a\nrules: [{path: .metadata.name}]"},
+			expectedErr: "checks nothing",
+		},
+		"invalid pattern": {
+			files:       map[string]string{"a.json": `{"name": "a", "rules": [{"path": ".metadata.name", "pattern": "("}]}`},
+			expectedErr: "invalid pattern",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writePolicies(t, test.files))
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	policies, err := Load(writePolicies(t, map[string]string{"policies.yaml": testPolicies}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &resource.Info{
+		Name:    "web",
+		Object:  newDeployment(nil, "nginx:latest"),
+		Mapping: &meta.RESTMapping{Resource: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}},
+	}
+	out := &bytes.Buffer{}
+	err = Check(policies, OperationApply, []*resource.Info{info}, out)
+	expectedErr := `deployments/web violates the policy no-latest-images: {..containers[*].image} "nginx:latest" matches ":latest$"`
+	if err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error %q, got %v", expectedErr, err)
+	}
+	if expected := "Warning: deployments/web violates the policy team-label: the team label is required\n"; out.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, out.String())
+	}
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry reads manifests and blobs from container image registries.
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+	"k8s.io/client-go/util/homedir"
+)
+
+// ManifestMediaTypes are the manifest types accepted when resolving a tag.
+// Manifest lists and indexes come first so that multi-architecture images
+// are pinned to the list rather than to a single platform.
+var ManifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// maxBlobSize is the size of the largest manifest or blob read.
+const maxBlobSize = 16 << 20
+
+// ErrNotFound is returned when the registry does not have the manifest or
+// blob requested.
+var ErrNotFound = errors.New("not found")
+
+// registryCredentials are the credentials used to log in to a registry.
+type registryCredentials struct {
+	username string
+	password string
+}
+
+// dockerConfig is the part of the docker config file used to authenticate
+// to registries.
+type dockerConfig struct {
+	Auths       map[string]dockerConfigAuth `json:"auths"`
+	CredsStore  string                      `json:"credsStore"`
+	CredHelpers map[string]string           `json:"credHelpers"`
+}
+
+type dockerConfigAuth struct {
+	Auth     string `json:"auth"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Client reads from registries, authenticating with the credentials of the
+// docker config file.
+type Client struct {
+	HTTPClient *http.Client
+	config     *dockerConfig
+}
+
+// Manifest is a manifest read from a registry.
+type Manifest struct {
+	Data      []byte
+	MediaType string
+	Digest    string
+}
+
+// NewClient returns a client authenticating with the docker config file in
+// $DOCKER_CONFIG or ~/.docker.
+func NewClient() (*Client, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if len(dir) == 0 {
+		dir = filepath.Join(homedir.HomeDir(), ".docker")
+	}
+	config, err := loadDockerConfig(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		config:     config,
+	}, nil
+}
+
+func loadDockerConfig(path string) (*dockerConfig, error) {
+	config := &dockerConfig{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("error parsing docker config %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// Manifest returns the manifest of the repository with the given tag or
+// digest, accepting the given media types.
+func (c *Client) Manifest(repository reference.Named, tagOrDigest string, mediaTypes ...string) (*Manifest, error) {
+	return c.manifest(repository, "manifests/"+tagOrDigest, mediaTypes)
+}
+
+// Referrers returns the index of the artifacts referring to the manifest
+// with the given digest, such as signatures and SBOMs.
+func (c *Client) Referrers(repository reference.Named, digest string) (*Manifest, error) {
+	return c.manifest(repository, "referrers/"+digest, []string{"application/vnd.oci.image.index.v1+json"})
+}
+
+func (c *Client) manifest(repository reference.Named, resource string, mediaTypes []string) (*Manifest, error) {
+	resp, err := c.get(repository, resource, mediaTypes)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBlobSize))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{
+		Data:      data,
+		MediaType: resp.Header.Get("Content-Type"),
+		Digest:    resp.Header.Get("Docker-Content-Digest"),
+	}
+	// the digest is the hash of the manifest when the registry doesn't send it
+	if len(manifest.Digest) == 0 {
+		manifest.Digest = fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	}
+	return manifest, nil
+}
+
+// Blob returns the blob of the repository with the given digest, after
+// checking its content matches the digest.
+func (c *Client) Blob(repository reference.Named, digest string) ([]byte, error) {
+	resp, err := c.get(repository, "blobs/"+digest, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBlobSize))
+	if err != nil {
+		return nil, err
+	}
+	if actual := fmt.Sprintf("sha256:%x", sha256.Sum256(data)); strings.HasPrefix(digest, "sha256:") && actual != digest {
+		return nil, fmt.Errorf("blob %s of %s has the digest %s", digest, reference.FamiliarName(repository), actual)
+	}
+	return data, nil
+}
+
+// get requests the resource of the repository, authenticating when the
+// registry requires it.
+func (c *Client) get(repository reference.Named, resource string, accept []string) (*http.Response, error) {
+	domain, path := reference.Domain(repository), reference.Path(repository)
+	host := domain
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	resourceURL := fmt.Sprintf("https://%s/v2/%s/%s", host, path, resource)
+
+	resp, err := c.do(resourceURL, accept, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		authorization, err := c.authorize(domain, challenge, "repository:"+path+":pull")
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = c.do(resourceURL, accept, authorization); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry %s returned %s for %s/%s: %w", domain, resp.Status, path, resource, ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry %s returned %s for %s/%s", domain, resp.Status, path, resource)
+	}
+	return resp, nil
+}
+
+func (c *Client) do(resourceURL string, accept []string, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, resourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+	if len(authorization) > 0 {
+		req.Header.Set("Authorization", authorization)
+	}
+	return c.HTTPClient.Do(req)
+}
+
+// authorize answers a WWW-Authenticate challenge of the registry and returns
+// the value of the Authorization header to retry with.
+func (c *Client) authorize(domain, challenge, scope string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	creds, err := c.credentials(domain)
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if creds == nil {
+			return "", fmt.Errorf("registry %s requires credentials, log in with docker login", domain)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.username+":"+creds.password)), nil
+	case "bearer":
+		token, err := c.token(params, scope, creds)
+		if err != nil {
+			return "", fmt.Errorf("unable to authenticate to registry %s: %v", domain, err)
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("registry %s requires unsupported authentication %q", domain, challenge)
+	}
+}
+
+// token requests a bearer token from the realm of the challenge.
+func (c *Client) token(params map[string]string, scope string, creds *registryCredentials) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || len(params["realm"]) == 0 {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	if s, ok := params["scope"]; ok {
+		scope = s
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if creds != nil {
+		req.SetBasicAuth(creds.username, creds.password)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request returned %s", resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if len(body.Token) > 0 {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// Credentials returns the username and password the docker config holds
+// for the registry host, and false if it holds none.
+func (c *Client) Credentials(host string) (string, string, bool, error) {
+	creds, err := c.credentials(host)
+	if err != nil || creds == nil {
+		return "", "", false, err
+	}
+	return creds.username, creds.password, true, nil
+}
+
+// credentials returns the credentials the docker config holds for the
+// registry, or nil if there are none.
+func (c *Client) credentials(domain string) (*registryCredentials, error) {
+	keys := []string{domain}
+	if domain == "docker.io" {
+		keys = append(keys, "index.docker.io", "registry-1.docker.io")
+	}
+	for _, key := range keys {
+		if helper, ok := c.config.CredHelpers[key]; ok {
+			return credentialsFromHelper(helper, key)
+		}
+	}
+	for server, auth := range c.config.Auths {
+		if !matchesRegistry(server, keys) {
+			continue
+		}
+		if len(auth.Auth) > 0 {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth for %s in docker config: %v", server, err)
+			}
+			username, password, _ := strings.Cut(string(decoded), ":")
+			return &registryCredentials{username: username, password: password}, nil
+		}
+		if len(auth.Username) > 0 {
+			return &registryCredentials{username: auth.Username, password: auth.Password}, nil
+		}
+	}
+	if len(c.config.CredsStore) > 0 {
+		server := domain
+		if domain == "docker.io" {
+			server = "https://index.docker.io/v1/"
+		}
+		return credentialsFromHelper(c.config.CredsStore, server)
+	}
+	return nil, nil
+}
+
+// matchesRegistry returns true if the server key of the docker config, which
+// may be a URL, names one of the registry hosts.
+func matchesRegistry(server string, hosts []string) bool {
+	server = strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	server, _, _ = strings.Cut(server, "/")
+	for _, host := range hosts {
+		if server == host {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialsFromHelper runs the docker-credential-<helper> program to get
+// the credentials of the server.
+func credentialsFromHelper(helper, server string) (*registryCredentials, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(server)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(string(out), "credentials not found") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting credentials for %s from docker-credential-%s: %v %s", server, helper, err, stderr.String())
+	}
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return nil, fmt.Errorf("invalid output of docker-credential-%s: %v", helper, err)
+	}
+	return &registryCredentials{username: creds.Username, password: creds.Secret}, nil
+}
+
+// parseChallenge splits a WWW-Authenticate header such as
+// `Bearer realm="https://auth.example.com/token",service="registry"` into
+// its scheme and parameters.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for len(rest) > 0 {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		if len(key) > 0 {
+			params[strings.ToLower(strings.TrimSpace(key))] = value
+		}
+	}
+	return scheme, params
+}
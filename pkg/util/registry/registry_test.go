@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClient(t *testing.T) {
+	blob := []byte(`{"critical":{}}`)
+	blobDigest := fmt.Sprintf("sha256:%x", sha256.Sum256(blob))
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v2/team/app/manifests/v1":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			fmt.Fprint(w, `{"schemaVersion":2}`)
+		case "/v2/team/app/referrers/" + blobDigest:
+			if accept := req.Header.Get("Accept"); accept != "application/vnd.oci.image.index.v1+json" {
+				t.Errorf("unexpected Accept %q", accept)
+			}
+			w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+			fmt.Fprint(w, `{"manifests":[]}`)
+		case "/v2/team/app/blobs/" + blobDigest:
+			w.Write(blob)
+		case "/v2/team/app/blobs/sha256:0000":
+			w.Write(blob)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{HTTPClient: server.Client(), config: &dockerConfig{}}
+	repository, err := reference.ParseNormalizedNamed(strings.TrimPrefix(server.URL, "https://") + "/team/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := client.Manifest(repository, "v1", ManifestMediaTypes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Manifest{
+		Data:      []byte(`{"schemaVersion":2}`),
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Digest:    fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(`{"schemaVersion":2}`))),
+	}
+	if diff := cmp.Diff(expected, manifest); diff != "" {
+		t.Errorf("unexpected manifest (-want +got):\n%s", diff)
+	}
+
+	if referrers, err := client.Referrers(repository, blobDigest); err != nil || string(referrers.Data) != `{"manifests":[]}` {
+		t.Errorf("unexpected referrers %v: %v", referrers, err)
+	}
+	if data, err := client.Blob(repository, blobDigest); err != nil || string(data) != string(blob) {
+		t.Errorf("unexpected blob %q: %v", data, err)
+	}
+	if _, err := client.Blob(repository, "sha256:0000"); err == nil {
+		t.Errorf("expected a blob not matching its digest to be rejected")
+	}
+	if _, err := client.Manifest(repository, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:app:pull"`)
+	if scheme != "Bearer" {
+		t.Errorf("unexpected scheme %q", scheme)
+	}
+	expected := map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:app:pull",
+	}
+	if diff := cmp.Diff(expected, params); diff != "" {
+		t.Errorf("unexpected parameters (-want +got):\n%s", diff)
+	}
+
+	scheme, params = parseChallenge(`Basic realm=registry`)
+	if scheme != "Basic" || params["realm"] != "registry" {
+		t.Errorf("unexpected challenge %q %v", scheme, params)
+	}
+}
+
+func TestMatchesRegistry(t *testing.T) {
+	hosts := []string{"docker.io", "index.docker.io"}
+	for server, expected := range map[string]bool{
+		"https://index.docker.io/v1/": true,
+		"docker.io":                   true,
+		"quay.io":                     false,
+		"http://docker.io.example":    false,
+	} {
+		if got := matchesRegistry(server, hosts); got != expected {
+			t.Errorf("%s: expected %v, got %v", server, expected, got)
+		}
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	dir := t.TempDir()
+	config := `{"auths": {"registry.example.com": {"auth": "dXNlcjpzZWNyZXQ="}, "https://other.example.com/v1/": {"username": "bot", "password": "pw"}}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_CONFIG", dir)
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		host         string
+		wantUsername string
+		wantPassword string
+		wantFound    bool
+	}{
+		{host: "registry.example.com", wantUsername: "user", wantPassword: "secret", wantFound: true},
+		{host: "other.example.com", wantUsername: "bot", wantPassword: "pw", wantFound: true},
+		{host: "unknown.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			username, password, found, err := client.Credentials(tt.host)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if username != tt.wantUsername || password != tt.wantPassword || found != tt.wantFound {
+				t.Errorf("expected %q, %q, %v, got %q, %q, %v", tt.wantUsername, tt.wantPassword, tt.wantFound, username, password, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package relations
+
+import (
+	"context"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+)
+
+// ListObjects lists the objects of all the resources of the namespace, and
+// of the given cluster-scoped resources. All the namespaces and all the
+// cluster-scoped resources are listed when the namespace is empty. The
+// resources which can not be listed, such as forbidden ones, are skipped.
+func ListObjects(discoveryClient discovery.DiscoveryInterface, dynamicClient dynamic.Interface, namespace string, clusterResources ...schema.GroupVersionResource) ([]*unstructured.Unstructured, error) {
+	lists, err := discoveryClient.ServerPreferredResources()
+	if err != nil && len(lists) == 0 {
+		return nil, err
+	}
+
+	objects := []*unstructured.Unstructured{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if !sets.New(r.Verbs...).Has("list") || strings.Contains(r.Name, "/") {
+				continue
+			}
+			gvr := gv.WithResource(r.Name)
+			if len(namespace) > 0 && !r.Namespaced && !sets.New(clusterResources...).Has(gvr) {
+				continue
+			}
+			client := dynamicClient.Resource(gvr)
+			var items *unstructured.UnstructuredList
+			if r.Namespaced {
+				items, err = client.Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+			} else {
+				items, err = client.List(context.TODO(), metav1.ListOptions{})
+			}
+			if err != nil {
+				klog.V(2).Infof("Skipping %s: %v", gvr, err)
+				continue
+			}
+			for i := range items.Items {
+				objects = append(objects, &items.Items[i])
+			}
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package relations resolves the relationships between Kubernetes objects,
+// such as owner references, volumes and service selectors.
+package relations
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Type is the type of a relationship.
+type Type string
+
+const (
+	// Owns relates an owner to the objects referencing it as owner.
+	Owns Type = "owns"
+	// Mounts relates a pod, or the pod template of a workload, to the config
+	// maps, secrets and persistent volume claims of its volumes.
+	Mounts Type = "mounts"
+	// Selects relates a service to the pods matching its selector.
+	Selects Type = "selects"
+	// Routes relates an ingress to the services of its backends.
+	Routes Type = "routes"
+	// Binds relates a role binding to its role, and a persistent volume
+	// claim to its persistent volume.
+	Binds Type = "binds"
+	// Subject relates a role binding to its subjects.
+	Subject Type = "subject"
+)
+
+// Object identifies an object, which is not necessarily found.
+type Object struct {
+	schema.GroupKind
+	Namespace string
+	Name      string
+}
+
+// String returns the object as kind.group/name, prefixed by its namespace.
+func (o Object) String() string {
+	s := o.GroupKind.String() + "/" + o.Name
+	if len(o.Namespace) > 0 {
+		s = o.Namespace + "/" + s
+	}
+	return s
+}
+
+// ObjectOf returns the identity of the object.
+func ObjectOf(obj *unstructured.Unstructured) Object {
+	return Object{GroupKind: obj.GroupVersionKind().GroupKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+}
+
+// Relation is a relationship from an object to another.
+type Relation struct {
+	From Object
+	To   Object
+	Type Type
+}
+
+var (
+	podGroupKind     = schema.GroupKind{Kind: "Pod"}
+	serviceGroupKind = schema.GroupKind{Kind: "Service"}
+)
+
+// Resolve returns the relationships between the objects, sorted. The
+// objects the relationships point to may not be in the objects, such as the
+// cluster role of a role binding, except for the pods selected by services
+// which must be.
+func Resolve(objects []*unstructured.Unstructured) []Relation {
+	relations := []Relation{}
+	for _, obj := range objects {
+		relations = append(relations, Of(obj)...)
+		if obj.GroupVersionKind().GroupKind() != serviceGroupKind {
+			continue
+		}
+		selector, found, _ := unstructured.NestedStringMap(obj.Object, "spec", "selector")
+		if !found || len(selector) == 0 {
+			continue
+		}
+		for _, pod := range objects {
+			if pod.GroupVersionKind().GroupKind() == podGroupKind && pod.GetNamespace() == obj.GetNamespace() &&
+				labels.SelectorFromSet(selector).Matches(labels.Set(pod.GetLabels())) {
+				relations = append(relations, Relation{From: ObjectOf(obj), To: ObjectOf(pod), Type: Selects})
+			}
+		}
+	}
+	sort.Slice(relations, func(i, j int) bool {
+		a, b := relations[i], relations[j]
+		if a.From != b.From {
+			return a.From.String() < b.From.String()
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.To.String() < b.To.String()
+	})
+	// an ingress may route to a service several times, for instance
+	unique := relations[:0]
+	for i, r := range relations {
+		if i == 0 || r != relations[i-1] {
+			unique = append(unique, r)
+		}
+	}
+	return unique
+}
+
+// Of returns the relationships the object declares by itself: its owners,
+// volumes, ingress backends, role references, subjects and bound volumes.
+// The relationships between services and pods depend on other objects and
+// are only resolved by Resolve.
+func Of(obj *unstructured.Unstructured) []Relation {
+	self := ObjectOf(obj)
+	relations := []Relation{}
+	add := func(relationType Type, group, kind, namespace, name string) {
+		if len(name) > 0 {
+			to := Object{GroupKind: schema.GroupKind{Group: group, Kind: kind}, Namespace: namespace, Name: name}
+			relations = append(relations, Relation{From: self, To: to, Type: relationType})
+		}
+	}
+
+	for _, ref := range obj.GetOwnerReferences() {
+		gv, _ := schema.ParseGroupVersion(ref.APIVersion)
+		// the owner is the source of the relationship
+		owner := Object{GroupKind: schema.GroupKind{Group: gv.Group, Kind: ref.Kind}, Namespace: obj.GetNamespace(), Name: ref.Name}
+		relations = append(relations, Relation{From: owner, To: self, Type: Owns})
+	}
+
+	if podSpec := PodSpec(obj); podSpec != nil {
+		spec := &corev1.PodSpec{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podSpec, spec); err == nil {
+			relations = append(relations, Mounted(self, spec)...)
+		}
+	}
+
+	switch self.GroupKind {
+	case schema.GroupKind{Group: "networking.k8s.io", Kind: "Ingress"}:
+		name, _, _ := unstructured.NestedString(obj.Object, "spec", "defaultBackend", "service", "name")
+		add(Routes, "", "Service", obj.GetNamespace(), name)
+		rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rules")
+		for _, rule := range rules {
+			paths, _, _ := unstructured.NestedSlice(asMap(rule), "http", "paths")
+			for _, path := range paths {
+				name, _, _ := unstructured.NestedString(asMap(path), "backend", "service", "name")
+				add(Routes, "", "Service", obj.GetNamespace(), name)
+			}
+		}
+	case schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"},
+		schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:
+		kind, _, _ := unstructured.NestedString(obj.Object, "roleRef", "kind")
+		name, _, _ := unstructured.NestedString(obj.Object, "roleRef", "name")
+		namespace := ""
+		if kind == "Role" {
+			namespace = obj.GetNamespace()
+		}
+		add(Binds, "rbac.authorization.k8s.io", kind, namespace, name)
+		subjects, _, _ := unstructured.NestedSlice(obj.Object, "subjects")
+		for _, s := range subjects {
+			subject := asMap(s)
+			kind, _, _ := unstructured.NestedString(subject, "kind")
+			name, _, _ := unstructured.NestedString(subject, "name")
+			namespace, _, _ := unstructured.NestedString(subject, "namespace")
+			group := "rbac.authorization.k8s.io"
+			if kind == "ServiceAccount" {
+				group = ""
+			}
+			add(Subject, group, kind, namespace, name)
+		}
+	case schema.GroupKind{Kind: "PersistentVolumeClaim"}:
+		name, _, _ := unstructured.NestedString(obj.Object, "spec", "volumeName")
+		add(Binds, "", "PersistentVolume", "", name)
+	}
+	return relations
+}
+
+// podSpecFields are the fields of the pod spec of the workloads.
+var podSpecFields = map[schema.GroupKind][]string{
+	podGroupKind:                         {"spec"},
+	{Group: "apps", Kind: "Deployment"}:  {"spec", "template", "spec"},
+	{Group: "apps", Kind: "ReplicaSet"}:  {"spec", "template", "spec"},
+	{Group: "apps", Kind: "StatefulSet"}: {"spec", "template", "spec"},
+	{Group: "apps", Kind: "DaemonSet"}:   {"spec", "template", "spec"},
+	{Kind: "ReplicationController"}:      {"spec", "template", "spec"},
+	{Group: "batch", Kind: "Job"}:        {"spec", "template", "spec"},
+	{Group: "batch", Kind: "CronJob"}:    {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// PodSpec returns the pod spec of a pod or of the pod template of a
+// workload, or nil for other objects.
+func PodSpec(obj *unstructured.Unstructured) map[string]interface{} {
+	fields, ok := podSpecFields[obj.GroupVersionKind().GroupKind()]
+	if !ok {
+		return nil
+	}
+	spec, _, _ := unstructured.NestedMap(obj.Object, fields...)
+	return spec
+}
+
+// Mounted returns the relationships from the object to the config maps,
+// secrets and persistent volume claims of the volumes of its pod spec,
+// including the projected volumes.
+func Mounted(from Object, spec *corev1.PodSpec) []Relation {
+	relations := []Relation{}
+	add := func(kind, name string) {
+		to := Object{GroupKind: schema.GroupKind{Kind: kind}, Namespace: from.Namespace, Name: name}
+		relations = append(relations, Relation{From: from, To: to, Type: Mounts})
+	}
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			add("PersistentVolumeClaim", volume.PersistentVolumeClaim.ClaimName)
+		case volume.ConfigMap != nil:
+			add("ConfigMap", volume.ConfigMap.Name)
+		case volume.Secret != nil:
+			add("Secret", volume.Secret.SecretName)
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				switch {
+				case source.ConfigMap != nil:
+					add("ConfigMap", source.ConfigMap.Name)
+				case source.Secret != nil:
+					add("Secret", source.Secret.Name)
+				}
+			}
+		}
+	}
+	return relations
+}
+
+func asMap(value interface{}) map[string]interface{} {
+	m, _ := value.(map[string]interface{})
+	return m
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package relations
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestResolve(t *testing.T) {
+	manifests := []string{`
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: web-1
+  namespace: shop
+  ownerReferences:
+  - apiVersion: apps/v1
+    kind: Deployment
+    name: web
+    uid: "1"
+spec:
+  template:
+    spec:
+      volumes:
+      - name: config
+        configMap:
+          name: web-config
+      - name: all
+        projected:
+          sources:
+          - secret:
+              name: web-tls
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-1-a
+  namespace: shop
+  labels:
+    app: web
+spec:
+  volumes:
+  - name: data
+    persistentVolumeClaim:
+      claimName: data
+`, `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: db
+  namespace: shop
+  labels:
+    app: db
+`, `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: shop
+spec:
+  selector:
+    app: web
+`, `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+  namespace: shop
+spec:
+  rules:
+  - http:
+      paths:
+      - path: /
+        backend:
+          service:
+            name: web
+      - path: /api
+        backend:
+          service:
+            name: web
+`, `
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: web
+  namespace: shop
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+- kind: ServiceAccount
+  name: web
+  namespace: shop
+`, `
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+  namespace: shop
+spec:
+  volumeName: pv-1
+`}
+	objects := []*unstructured.Unstructured{}
+	for _, manifest := range manifests {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(manifest), &obj.Object); err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, obj)
+	}
+
+	expected := []string{
+		"shop/Deployment.apps/web owns shop/ReplicaSet.apps/web-1",
+		"shop/Ingress.networking.k8s.io/web routes shop/Service/web",
+		"shop/PersistentVolumeClaim/data binds PersistentVolume/pv-1",
+		"shop/Pod/web-1-a mounts shop/PersistentVolumeClaim/data",
+		"shop/ReplicaSet.apps/web-1 mounts shop/ConfigMap/web-config",
+		"shop/ReplicaSet.apps/web-1 mounts shop/Secret/web-tls",
+		"shop/RoleBinding.rbac.authorization.k8s.io/web binds ClusterRole.rbac.authorization.k8s.io/view",
+		"shop/RoleBinding.rbac.authorization.k8s.io/web subject shop/ServiceAccount/web",
+		"shop/Service/web selects shop/Pod/web-1-a",
+	}
+	relations := Resolve(objects)
+	if len(relations) != len(expected) {
+		t.Fatalf("expected %d relations, got %v", len(expected), relations)
+	}
+	for i, r := range relations {
+		if got := r.From.String() + " " + string(r.Type) + " " + r.To.String(); got != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package timeformat formats the times and durations printed in columns,
+// following the --time-format flag.
+package timeformat
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	// Relative prints the time elapsed since a time, such as 5d.
+	Relative = "relative"
+	// ISO prints times in RFC 3339 format, in UTC.
+	ISO = "iso"
+	// Unix prints times as seconds since the epoch.
+	Unix = "unix"
+)
+
+var current = Relative
+
+// now is replaced in tests.
+var now = time.Now
+
+// Set sets the format of the times printed.
+func Set(format string) error {
+	switch format {
+	case Relative, ISO, Unix:
+		current = format
+		return nil
+	default:
+		return fmt.Errorf("invalid time format %q, must be one of: %s, %s, %s", format, Relative, ISO, Unix)
+	}
+}
+
+// Current returns the format of the times printed.
+func Current() string {
+	return current
+}
+
+// AddFlags adds the --time-format flag to the flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Var(formatValue{}, "time-format", "Format of the ages and times printed in columns. One of: relative, iso, unix.")
+}
+
+type formatValue struct{}
+
+func (formatValue) String() string     { return current }
+func (formatValue) Set(s string) error { return Set(s) }
+func (formatValue) Type() string       { return "string" }
+
+// Timestamp formats t in the current format. Zero times are <unknown>.
+func Timestamp(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	switch current {
+	case ISO:
+		return t.UTC().Format(time.RFC3339)
+	case Unix:
+		return strconv.FormatInt(t.Unix(), 10)
+	default:
+		return Since(t)
+	}
+}
+
+// Since returns the time elapsed since t in human-readable approximation,
+// whatever the current format. Zero times are <unknown>.
+func Since(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now().Sub(t))
+}
+
+// Duration formats a value as a human-readable duration. The value is a
+// time, whose elapsed time is returned, a number of seconds, or a duration
+// such as 90s.
+func Duration(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return Since(v), nil
+	case int64:
+		return duration.HumanDuration(time.Duration(v) * time.Second), nil
+	case float64:
+		return duration.HumanDuration(time.Duration(v * float64(time.Second))), nil
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return Since(t), nil
+		}
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return duration.HumanDuration(time.Duration(seconds * float64(time.Second))), nil
+		}
+		if d, err := time.ParseDuration(v); err == nil {
+			return duration.HumanDuration(d), nil
+		}
+	}
+	return "", fmt.Errorf("%v is not a time or a duration", value)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package timeformat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimestamp(t *testing.T) {
+	defer func(format string, fn func() time.Time) { current, now = format, fn }(current, now)
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return at.Add(50 * time.Hour) }
+
+	tests := []struct {
+		format   string
+		time     time.Time
+		expected string
+	}{
+		{format: Relative, time: at, expected: "2d2h"},
+		{format: ISO, time: at.In(time.FixedZone("CET", 3600)), expected: "2024-03-01T12:00:00Z"},
+		{format: Unix, time: at, expected: "1709294400"},
+		{format: ISO, expected: "<unknown>"},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			if err := Set(test.format); err != nil {
+				t.Fatal(err)
+			}
+			if actual := Timestamp(test.time); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+	if err := Set("rfc822"); err == nil {
+		t.Errorf("expected an error for an invalid format")
+	}
+}
+
+func TestDuration(t *testing.T) {
+	defer func(fn func() time.Time) { now = fn }(now)
+	now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		value    interface{}
+		expected string
+		err      bool
+	}{
+		{value: "2024-03-01T11:00:00Z", expected: "60m"},
+		{value: time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC), expected: "29d"},
+		{value: int64(90), expected: "90s"},
+		{value: float64(7200), expected: "120m"},
+		{value: "300", expected: "5m"},
+		{value: "36h", expected: "36h"},
+		{value: "soon", err: true},
+		{value: true, err: true},
+	}
+	for _, test := range tests {
+		actual, err := Duration(test.value)
+		if test.err != (err != nil) {
+			t.Errorf("%v: unexpected error: %v", test.value, err)
+		}
+		if actual != test.expected {
+			t.Errorf("%v: expected %q, got %q", test.value, test.expected, actual)
+		}
+	}
+}
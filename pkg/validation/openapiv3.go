@@ -0,0 +1,320 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/openapi"
+	"k8s.io/klog/v2"
+	"k8s.io/kube-openapi/pkg/spec3"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// maxSchemaDepth bounds the recursion through self-referencing schemas,
+// such as the JSONSchemaProps of custom resource definitions.
+const maxSchemaDepth = 64
+
+// openAPIV3Validation validates objects against the OpenAPI V3 schemas
+// published by the server: field types, required fields, enum values and
+// unknown fields.
+type openAPIV3Validation struct {
+	client openapi.Client
+	// docs caches the OpenAPI document of each group version.
+	docs map[schema.GroupVersion]*spec3.OpenAPI
+}
+
+// NewOpenAPIV3Validation creates a new Schema that validates objects against
+// the OpenAPI V3 schemas of the server. Objects whose schema cannot be found
+// are not validated.
+func NewOpenAPIV3Validation(client openapi.Client) Schema {
+	return &openAPIV3Validation{
+		client: client,
+		docs:   map[schema.GroupVersion]*spec3.OpenAPI{},
+	}
+}
+
+// ValidateBytes validates the object, or the items of a list. The returned
+// aggregate holds a *field.Error for every invalid field.
+func (v *openAPIV3Validation) ValidateBytes(data []byte) error {
+	obj, err := parse(data)
+	if err != nil {
+		return err
+	}
+
+	gvk, errs := getObjectKind(obj)
+	if errs != nil {
+		return utilerrors.NewAggregate(errs)
+	}
+
+	if (gvk == schema.GroupVersionKind{Version: "v1", Kind: "List"}) {
+		items, ok := obj.(map[string]interface{})["items"].([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid object to validate")
+		}
+		allErrs := []error{}
+		for i, item := range items {
+			gvk, errs := getObjectKind(item)
+			if errs != nil {
+				allErrs = append(allErrs, errs...)
+				continue
+			}
+			for _, err := range v.validateResource(field.NewPath("items").Index(i), item, gvk) {
+				allErrs = append(allErrs, err)
+			}
+		}
+		return utilerrors.NewAggregate(allErrs)
+	}
+
+	allErrs := []error{}
+	for _, err := range v.validateResource(nil, obj, gvk) {
+		allErrs = append(allErrs, err)
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+func (v *openAPIV3Validation) validateResource(fldPath *field.Path, obj interface{}, gvk schema.GroupVersionKind) field.ErrorList {
+	doc, err := v.document(gvk.GroupVersion())
+	if err != nil {
+		// validation is best effort, the server validates the object anyway
+		klog.V(2).Infof("skipping OpenAPI V3 validation of %s: %v", gvk, err)
+		return nil
+	}
+	s := findSchemaForGVK(doc, gvk)
+	if s == nil {
+		// resource is not present, let's just skip validation.
+		return nil
+	}
+	validator := schemaValidator{schemas: doc.Components.Schemas}
+	return validator.validate(fldPath, obj, s, 0)
+}
+
+func (v *openAPIV3Validation) document(gv schema.GroupVersion) (*spec3.OpenAPI, error) {
+	if doc, ok := v.docs[gv]; ok {
+		return doc, nil
+	}
+	paths, err := v.client.Paths()
+	if err != nil {
+		return nil, err
+	}
+	path := "apis/" + gv.String()
+	if len(gv.Group) == 0 {
+		path = "api/" + gv.Version
+	}
+	groupVersion, ok := paths[path]
+	if !ok {
+		return nil, fmt.Errorf("couldn't find resource for %q", gv)
+	}
+	data, err := groupVersion.Schema(runtime.ContentTypeJSON)
+	if err != nil {
+		return nil, err
+	}
+	doc := &spec3.OpenAPI{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	if doc.Components == nil {
+		return nil, fmt.Errorf("no schemas found for %q", gv)
+	}
+	v.docs[gv] = doc
+	return doc, nil
+}
+
+func findSchemaForGVK(doc *spec3.OpenAPI, gvk schema.GroupVersionKind) *spec.Schema {
+	for _, s := range doc.Components.Schemas {
+		gvks, ok := s.Extensions["x-kubernetes-group-version-kind"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, item := range gvks {
+			m, ok := item.(map[string]interface{})
+			if ok && m["group"] == gvk.Group && m["version"] == gvk.Version && m["kind"] == gvk.Kind {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+type schemaValidator struct {
+	schemas map[string]*spec.Schema
+}
+
+func (v schemaValidator) resolve(s *spec.Schema) *spec.Schema {
+	for i := 0; i < maxSchemaDepth && s != nil; i++ {
+		ref := s.Ref.String()
+		if len(ref) == 0 {
+			return s
+		}
+		s = v.schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+func (v schemaValidator) validate(fldPath *field.Path, value interface{}, s *spec.Schema, depth int) field.ErrorList {
+	s = v.resolve(s)
+	// null is accepted for any field, the server drops it
+	if s == nil || value == nil || depth > maxSchemaDepth {
+		return nil
+	}
+
+	allErrs := field.ErrorList{}
+	// the schemas of fields referencing other types are wrapped in allOf
+	for i := range s.AllOf {
+		allErrs = append(allErrs, v.validate(fldPath, value, &s.AllOf[i], depth+1)...)
+	}
+
+	if isTrue(s.Extensions, "x-kubernetes-int-or-string") || s.Format == "int-or-string" {
+		switch value.(type) {
+		case string, int64:
+		default:
+			allErrs = append(allErrs, field.Invalid(fldPath, describe(value), "must be an integer or a string"))
+		}
+		return allErrs
+	}
+
+	if alternatives := append(append([]spec.Schema{}, s.OneOf...), s.AnyOf...); len(alternatives) > 0 {
+		matched := false
+		for i := range alternatives {
+			if len(v.validate(fldPath, value, &alternatives[i], depth+1)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			allErrs = append(allErrs, field.Invalid(fldPath, describe(value), "does not match any of the allowed schemas"))
+		}
+	}
+
+	switch {
+	case s.Type.Contains("object"):
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return append(allErrs, field.Invalid(fldPath, describe(value), "must be an object"))
+		}
+		allErrs = append(allErrs, v.validateObject(fldPath, fields, s, depth)...)
+	case s.Type.Contains("array"):
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(allErrs, field.Invalid(fldPath, describe(value), "must be an array"))
+		}
+		if s.Items != nil && s.Items.Schema != nil {
+			for i, item := range items {
+				allErrs = append(allErrs, v.validate(fldPath.Index(i), item, s.Items.Schema, depth+1)...)
+			}
+		}
+	case s.Type.Contains("string"):
+		if _, ok := value.(string); !ok {
+			return append(allErrs, field.Invalid(fldPath, describe(value), "must be a string"))
+		}
+	case s.Type.Contains("integer"):
+		if !isInteger(value) {
+			return append(allErrs, field.Invalid(fldPath, describe(value), "must be an integer"))
+		}
+	case s.Type.Contains("number"):
+		switch value.(type) {
+		case int64, float64:
+		default:
+			return append(allErrs, field.Invalid(fldPath, describe(value), "must be a number"))
+		}
+	case s.Type.Contains("boolean"):
+		if _, ok := value.(bool); !ok {
+			return append(allErrs, field.Invalid(fldPath, describe(value), "must be a boolean"))
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		allowed := []string{}
+		found := false
+		for _, e := range s.Enum {
+			if e == value {
+				found = true
+				break
+			}
+			allowed = append(allowed, fmt.Sprint(e))
+		}
+		if !found {
+			allErrs = append(allErrs, field.NotSupported(fldPath, value, allowed))
+		}
+	}
+	return allErrs
+}
+
+func (v schemaValidator) validateObject(fldPath *field.Path, fields map[string]interface{}, s *spec.Schema, depth int) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, name := range s.Required {
+		if _, ok := fields[name]; !ok {
+			allErrs = append(allErrs, field.Required(fldPath.Child(name), ""))
+		}
+	}
+
+	preserveUnknown := isTrue(s.Extensions, "x-kubernetes-preserve-unknown-fields")
+	for name, value := range fields {
+		if property, ok := s.Properties[name]; ok {
+			allErrs = append(allErrs, v.validate(fldPath.Child(name), value, &property, depth+1)...)
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			if s.AdditionalProperties.Schema != nil {
+				allErrs = append(allErrs, v.validate(fldPath.Key(name), value, s.AdditionalProperties.Schema, depth+1)...)
+				continue
+			}
+			if s.AdditionalProperties.Allows {
+				continue
+			}
+		}
+		// objects without declared properties accept any field
+		if preserveUnknown || len(s.Properties) == 0 {
+			continue
+		}
+		allErrs = append(allErrs, field.Invalid(fldPath.Child(name), name, "unknown field"))
+	}
+	return allErrs
+}
+
+func isTrue(extensions spec.Extensions, key string) bool {
+	b, _ := extensions.GetBool(key)
+	return b
+}
+
+func isInteger(value interface{}) bool {
+	switch v := value.(type) {
+	case int64:
+		return true
+	case float64:
+		return v == math.Trunc(v)
+	}
+	return false
+}
+
+// describe avoids printing whole objects and arrays in error messages.
+func describe(value interface{}) interface{} {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return value
+}
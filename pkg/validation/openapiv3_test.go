@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"sort"
+	"testing"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/openapi/openapitest"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+func TestOpenAPIV3Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []string
+	}{
+		{
+			name: "valid deployment",
+			data: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  labels:
+    app: nginx
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: nginx
+  strategy:
+    rollingUpdate:
+      maxSurge: 25%
+      maxUnavailable: 1
+  template:
+    metadata:
+      labels:
+        app: nginx
+    spec:
+      containers:
+      - name: nginx
+        image: nginx
+        imagePullPolicy: IfNotPresent
+        ports:
+        - containerPort: 80
+`,
+		},
+		{
+			name: "invalid deployment",
+			data: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+spec:
+  replicas: "2"
+  replicass: 3
+  template:
+    spec:
+      containers:
+      - name: nginx
+        ports:
+        - containerPort: 80.5
+      restartPolicy: Always
+`,
+			expected: []string{
+				`spec.replicas: Invalid value: "2": must be an integer`,
+				`spec.replicass: Invalid value: "replicass": unknown field`,
+				`spec.selector: Required value`,
+				`spec.template.spec.containers[0].ports[0].containerPort: Invalid value: 80.5: must be an integer`,
+			},
+		},
+		{
+			name: "invalid items of a list",
+			data: `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+  data:
+    key: 1
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: b
+  spec:
+    ports:
+    - port: 80
+      targetPort: true
+`,
+			expected: []string{
+				`items[0].data[key]: Invalid value: 1: must be a string`,
+				`items[1].spec.ports[0].targetPort: Invalid value: true: must be an integer or a string`,
+			},
+		},
+		{
+			name: "unknown group versions are not validated",
+			data: `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: a
+spec:
+  anything: 1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewOpenAPIV3Validation(openapitest.NewEmbeddedFileClient())
+			err := validator.ValidateBytes([]byte(tt.data))
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			agg, ok := err.(utilerrors.Aggregate)
+			if !ok {
+				t.Fatalf("expected an aggregate error, got %v", err)
+			}
+			actual := []string{}
+			for _, err := range agg.Errors() {
+				actual = append(actual, err.Error())
+			}
+			sort.Strings(actual)
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("expected errors:\n%v\ngot:\n%v", tt.expected, actual)
+			}
+			for i := range actual {
+				if actual[i] != tt.expected[i] {
+					t.Errorf("expected error %q, got %q", tt.expected[i], actual[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaValidatorEnum(t *testing.T) {
+	schemas := map[string]*spec.Schema{
+		"Policy": {SchemaProps: spec.SchemaProps{
+			Type: []string{"string"},
+			Enum: []interface{}{"Always", "IfNotPresent", "Never"},
+		}},
+		"Container": {SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: map[string]spec.Schema{
+				"imagePullPolicy": {SchemaProps: spec.SchemaProps{
+					AllOf: []spec.Schema{{SchemaProps: spec.SchemaProps{Ref: spec.MustCreateRef("#/components/schemas/Policy")}}},
+				}},
+			},
+		}},
+	}
+	validator := schemaValidator{schemas: schemas}
+
+	errs := validator.validate(field.NewPath("container"), map[string]interface{}{"imagePullPolicy": "Never"}, schemas["Container"], 0)
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+	errs = validator.validate(field.NewPath("container"), map[string]interface{}{"imagePullPolicy": "Sometimes"}, schemas["Container"], 0)
+	expected := `container.imagePullPolicy: Unsupported value: "Sometimes": supported values: "Always", "IfNotPresent", "Never"`
+	if len(errs) != 1 || errs[0].Error() != expected {
+		t.Errorf("expected %q, got %v", expected, errs)
+	}
+}